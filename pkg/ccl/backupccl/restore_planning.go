@@ -507,15 +507,18 @@ func allocateDescriptorRewrites(
 					// Create a rewrite entry for the type.
 					descriptorRewrites[typ.ID] = &jobspb.DescriptorRewrite{ParentID: parentID}
 
-					// Ensure that there isn't a collision with the array type name.
-					arrTyp := typesByID[typ.ArrayTypeID]
-					typeName := tree.NewUnqualifiedTypeName(arrTyp.GetName())
-					err = descs.CheckObjectNameCollision(ctx, col, txn.KV(), parentID, getParentSchemaID(typ), typeName)
-					if err != nil {
-						return errors.Wrapf(err, "name collision for %q's array type", typ.Name)
+					// Domains don't have array types.
+					if typ.ArrayTypeID != descpb.InvalidID {
+						// Ensure that there isn't a collision with the array type name.
+						arrTyp := typesByID[typ.ArrayTypeID]
+						typeName := tree.NewUnqualifiedTypeName(arrTyp.GetName())
+						err = descs.CheckObjectNameCollision(ctx, col, txn.KV(), parentID, getParentSchemaID(typ), typeName)
+						if err != nil {
+							return errors.Wrapf(err, "name collision for %q's array type", typ.Name)
+						}
+						// Create the rewrite entry for the array type as well.
+						descriptorRewrites[arrTyp.ID] = &jobspb.DescriptorRewrite{ParentID: parentID}
 					}
-					// Create the rewrite entry for the array type as well.
-					descriptorRewrites[arrTyp.ID] = &jobspb.DescriptorRewrite{ParentID: parentID}
 				} else {
 					// If there was a name collision, we'll try to see if we can remap
 					// this type to the type existing in the cluster.
@@ -556,7 +559,9 @@ func allocateDescriptorRewrites(
 					typ.GetParentSchemaID() == descpb.InvalidID {
 					publicSchemaID := parentDB.GetSchemaID(tree.PublicSchema)
 					descriptorRewrites[typ.ID].ParentSchemaID = publicSchemaID
-					descriptorRewrites[typ.ArrayTypeID].ParentSchemaID = publicSchemaID
+					if typ.ArrayTypeID != descpb.InvalidID {
+						descriptorRewrites[typ.ArrayTypeID].ParentSchemaID = publicSchemaID
+					}
 				}
 			}
 		}
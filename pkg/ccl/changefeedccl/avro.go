@@ -603,6 +603,28 @@ func typeToAvroSchema(typ *types.T) (*avroSchemaField, error) {
 				return tree.ParseDTSVector(x.(string))
 			},
 		)
+	case types.RangeFamily:
+		setNullable(
+			avroSchemaString,
+			func(d tree.Datum, _ interface{}) (interface{}, error) {
+				return tree.AsStringWithFlags(d, tree.FmtBareStrings), nil
+			},
+			func(x interface{}) (tree.Datum, error) {
+				d, _, err := tree.ParseDRangeFromString(nil /* ctx */, x.(string), typ)
+				return d, err
+			},
+		)
+	case types.MultirangeFamily:
+		setNullable(
+			avroSchemaString,
+			func(d tree.Datum, _ interface{}) (interface{}, error) {
+				return tree.AsStringWithFlags(d, tree.FmtBareStrings), nil
+			},
+			func(x interface{}) (tree.Datum, error) {
+				d, _, err := tree.ParseDMultirangeFromString(nil /* ctx */, x.(string), typ)
+				return d, err
+			},
+		)
 	case types.EnumFamily:
 		setNullable(
 			avroSchemaString,
@@ -0,0 +1,97 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdceval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rangefeedfilter"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// errNotPushable is returned when an expression can't be pushed down.
+var errNotPushable = errors.New("expression can't be pushed down")
+
+// RangefeedValueFilter returns a value filter which can be pushed down to the
+// rangefeeds of a changefeed over the specified table, so that KV doesn't send
+// the rows which the WHERE clause of the changefeed expression filters out.
+// Returns nil if the expression has no WHERE clause, or if it can't be
+// evaluated by KV: the WHERE clause may only reference stored columns of a
+// table with a single column family, and may only use immutable operators and
+// functions.
+//
+// Note that the changefeed still evaluates the WHERE clause itself, since
+// KV is free to send rows which don't match the pushed down filter.
+func RangefeedValueFilter(
+	ctx context.Context,
+	st *cluster.Settings,
+	codec keys.SQLCodec,
+	desc catalog.TableDescriptor,
+	sc *tree.SelectClause,
+) *kvpb.RangeFeedValueFilter {
+	if sc.Where == nil || desc.NumFamilies() > 1 {
+		return nil
+	}
+
+	// Replace the column references in the WHERE clause with ordinal references
+	// to the fetched columns.
+	var fetchedColumnIDs []descpb.ColumnID
+	ordinals := make(map[descpb.ColumnID]int)
+	predicate, err := tree.SimpleVisit(sc.Where.Expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch t := expr.(type) {
+		case *tree.UnresolvedName:
+			if t.Star || t.NumParts != 1 {
+				return false, nil, errNotPushable
+			}
+			col := catalog.FindColumnByTreeName(desc, tree.Name(t.Parts[0]))
+			if col == nil || !col.Public() || col.IsVirtual() {
+				// Either a virtual column, which is computed by the changefeed, or
+				// something that isn't a column of the table (e.g. cdc_prev).
+				return false, nil, errNotPushable
+			}
+			ord, ok := ordinals[col.GetID()]
+			if !ok {
+				ord = len(fetchedColumnIDs)
+				ordinals[col.GetID()] = ord
+				fetchedColumnIDs = append(fetchedColumnIDs, col.GetID())
+			}
+			return false, tree.NewOrdinalReference(ord), nil
+		case *tree.Subquery, tree.UnqualifiedStar, *tree.AllColumnsSelector:
+			return false, nil, errNotPushable
+		}
+		return true, expr, nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	filter := &kvpb.RangeFeedValueFilter{Predicate: tree.Serialize(predicate)}
+	if err := rowenc.InitIndexFetchSpec(
+		&filter.IndexFetchSpec, codec, desc, desc.GetPrimaryIndex(), fetchedColumnIDs,
+	); err != nil {
+		log.Warningf(ctx, "failed to push down changefeed filter %s: %v", tree.AsString(sc.Where.Expr), err)
+		return nil
+	}
+
+	// Make sure that KV will accept the filter. This rejects, among other
+	// things, expressions which aren't immutable.
+	if _, err := rangefeedfilter.New(ctx, st, filter); err != nil {
+		log.VEventf(ctx, 1, "not pushing down changefeed filter %s: %v", tree.AsString(sc.Where.Expr), err)
+		return nil
+	}
+	return filter
+}
@@ -0,0 +1,354 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdctest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// MockNATSServer is an in-process NATS server used in tests. It implements
+// enough of the NATS client protocol to accept connections and publishes, and
+// acknowledges messages published to subjects bound to a stream the way
+// JetStream does.
+type MockNATSServer struct {
+	ln             net.Listener
+	user, password string
+	streams        map[string]string
+	wg             sync.WaitGroup
+	mu             struct {
+		syncutil.Mutex
+		conns    map[net.Conn]struct{}
+		seq      map[string]uint64
+		messages []NATSMessage
+		// popped is the number of messages returned by Pop.
+		popped int
+		notify chan struct{}
+		// failNext is the number of publishes to reply to with a JetStream
+		// error, and dropNext the number of publishes to silently drop.
+		failNext int
+		dropNext int
+	}
+}
+
+// NATSMessage is a message persisted by the MockNATSServer.
+type NATSMessage struct {
+	Stream   string
+	Sequence uint64
+	Subject  string
+	Header   map[string]string
+	Data     []byte
+}
+
+// StartMockNATSServer starts a mock NATS server. Messages published to a subject
+// matching one of the keys of streams, which may contain the `*` and `>`
+// wildcards, are persisted in the corresponding stream.
+func StartMockNATSServer(streams map[string]string) (*MockNATSServer, error) {
+	return StartMockNATSServerWithAuth(streams, ``, ``)
+}
+
+// StartMockNATSServerWithAuth starts a mock NATS server that requires clients
+// to authenticate with the given username and password.
+func StartMockNATSServerWithAuth(
+	streams map[string]string, user, password string,
+) (*MockNATSServer, error) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if err != nil {
+		return nil, err
+	}
+	s := &MockNATSServer{ln: ln, user: user, password: password, streams: streams}
+	s.mu.conns = make(map[net.Conn]struct{})
+	s.mu.seq = make(map[string]uint64)
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// URL returns the nats:// URL of the server.
+func (s *MockNATSServer) URL() string {
+	return `nats://` + s.ln.Addr().String()
+}
+
+// Close closes all connections and stops the server.
+func (s *MockNATSServer) Close() {
+	_ = s.ln.Close()
+	s.mu.Lock()
+	for conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Messages returns the messages persisted so far.
+func (s *MockNATSServer) Messages() []NATSMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]NATSMessage(nil), s.mu.messages...)
+}
+
+// Pop returns the oldest message persisted so far that hasn't been popped
+// yet. Popped messages are still returned by Messages.
+func (s *MockNATSServer) Pop() (NATSMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.popped == len(s.mu.messages) {
+		return NATSMessage{}, false
+	}
+	s.mu.popped++
+	return s.mu.messages[s.mu.popped-1], true
+}
+
+// NotifyMessage arranges for channel to be closed when there is a message to
+// pop.
+func (s *MockNATSServer) NotifyMessage() chan struct{} {
+	c := make(chan struct{})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.popped < len(s.mu.messages) {
+		close(c)
+	} else {
+		s.mu.notify = c
+	}
+	return c
+}
+
+// FailNextPublishes causes the next n publishes to JetStream to be rejected.
+func (s *MockNATSServer) FailNextPublishes(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failNext = n
+}
+
+// DropNextPublishes causes the next n publishes to JetStream to be neither
+// persisted nor acknowledged.
+func (s *MockNATSServer) DropNextPublishes(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.dropNext = n
+}
+
+// CloseConnections closes all client connections.
+func (s *MockNATSServer) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *MockNATSServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.mu.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.mu.conns, conn)
+				s.mu.Unlock()
+				_ = conn.Close()
+			}()
+			if err := s.serve(conn); err != nil && !errors.Is(err, io.EOF) {
+				_, _ = fmt.Fprintf(conn, "-ERR '%s'\r\n", err)
+			}
+		}()
+	}
+}
+
+// mockNATSSub is a subscription of a client connection.
+type mockNATSSub struct {
+	subject, sid string
+}
+
+func (s *MockNATSServer) serve(conn net.Conn) error {
+	info, err := json.Marshal(map[string]interface{}{
+		`server_id`:     `mock`,
+		`version`:       `2.9.0`,
+		`headers`:       true,
+		`max_payload`:   1 << 20,
+		`auth_required`: s.user != ``,
+	})
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "INFO %s\r\n", info)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var subs []mockNATSSub
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		op, args, _ := strings.Cut(strings.TrimRight(line, "\r\n"), ` `)
+		fields := strings.Fields(args)
+		switch strings.ToUpper(op) {
+		case `CONNECT`:
+			var opts struct {
+				User string `json:"user"`
+				Pass string `json:"pass"`
+			}
+			if err := json.Unmarshal([]byte(args), &opts); err != nil {
+				return err
+			}
+			if opts.User != s.user || opts.Pass != s.password {
+				return errors.New(`Authorization Violation`)
+			}
+		case `PING`:
+			_, _ = w.WriteString("PONG\r\n")
+		case `PONG`:
+		case `SUB`:
+			if len(fields) < 2 {
+				return errors.New(`Unknown Protocol Operation`)
+			}
+			subs = append(subs, mockNATSSub{subject: fields[0], sid: fields[len(fields)-1]})
+		case `PUB`, `HPUB`:
+			msg, reply, err := readMockNATSPub(r, strings.ToUpper(op) == `HPUB`, fields)
+			if err != nil {
+				return err
+			}
+			s.handlePublish(w, subs, msg, reply)
+		default:
+			return errors.New(`Unknown Protocol Operation`)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func readMockNATSPub(
+	r *bufio.Reader, hasHeader bool, fields []string,
+) (msg NATSMessage, reply string, err error) {
+	// PUB <subject> [reply-to] <#bytes>
+	// HPUB <subject> [reply-to] <#header bytes> <#total bytes>
+	n := 2
+	if hasHeader {
+		n = 3
+	}
+	if len(fields) != n && len(fields) != n+1 {
+		return msg, ``, errors.New(`Unknown Protocol Operation`)
+	}
+	msg.Subject = fields[0]
+	if len(fields) == n+1 {
+		reply = fields[1]
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return msg, ``, err
+	}
+	hdrLen := 0
+	if hasHeader {
+		if hdrLen, err = strconv.Atoi(fields[len(fields)-2]); err != nil {
+			return msg, ``, err
+		}
+	}
+	buf := make([]byte, total+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return msg, ``, err
+	}
+	if hdrLen > 0 {
+		msg.Header = make(map[string]string)
+		lines := strings.Split(strings.TrimSpace(string(buf[:hdrLen])), "\r\n")
+		for _, line := range lines[1:] {
+			k, v, _ := strings.Cut(line, `:`)
+			msg.Header[k] = strings.TrimSpace(v)
+		}
+	}
+	msg.Data = buf[hdrLen:total]
+	return msg, reply, nil
+}
+
+func (s *MockNATSServer) handlePublish(
+	w *bufio.Writer, subs []mockNATSSub, msg NATSMessage, reply string,
+) {
+	var replySID string
+	for _, sub := range subs {
+		if matchNATSSubject(sub.subject, reply) {
+			replySID = sub.sid
+		}
+	}
+	for filter, stream := range s.streams {
+		if matchNATSSubject(filter, msg.Subject) {
+			msg.Stream = stream
+		}
+	}
+
+	var ack []byte
+	s.mu.Lock()
+	switch {
+	case msg.Stream == ``:
+		// There are no responders, which the server reports if the client
+		// supports headers.
+		if replySID != `` {
+			const status = "NATS/1.0 503\r\n\r\n"
+			fmt.Fprintf(w, "HMSG %s %s %d %d\r\n%s\r\n", reply, replySID, len(status), len(status), status)
+		}
+	case s.mu.dropNext > 0:
+		s.mu.dropNext--
+	case s.mu.failNext > 0:
+		s.mu.failNext--
+		ack, _ = json.Marshal(map[string]interface{}{
+			`error`: map[string]interface{}{`code`: 503, `err_code`: 10077, `description`: `insufficient resources`},
+		})
+	default:
+		s.mu.seq[msg.Stream]++
+		msg.Sequence = s.mu.seq[msg.Stream]
+		s.mu.messages = append(s.mu.messages, msg)
+		if s.mu.notify != nil {
+			close(s.mu.notify)
+			s.mu.notify = nil
+		}
+		ack, _ = json.Marshal(map[string]interface{}{`stream`: msg.Stream, `seq`: msg.Sequence})
+	}
+	s.mu.Unlock()
+
+	if ack != nil && replySID != `` {
+		fmt.Fprintf(w, "MSG %s %s %d\r\n%s\r\n", reply, replySID, len(ack), ack)
+	}
+}
+
+// matchNATSSubject returns whether subject matches the filter, which may
+// contain wildcards.
+func matchNATSSubject(filter, subject string) bool {
+	if subject == `` {
+		return false
+	}
+	ft, st := strings.Split(filter, `.`), strings.Split(subject, `.`)
+	for i, f := range ft {
+		if f == `>` {
+			return len(st) > i
+		}
+		if i >= len(st) || (f != `*` && f != st[i]) {
+			return false
+		}
+	}
+	return len(ft) == len(st)
+}
@@ -0,0 +1,355 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdctest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// MockRedisServer is an in-process, in-memory Redis server used in tests. It
+// implements enough of the Redis protocol to authenticate, select a database
+// and append to streams with XADD.
+type MockRedisServer struct {
+	ln             net.Listener
+	user, password string
+	wg             sync.WaitGroup
+	mu             struct {
+		syncutil.Mutex
+		conns map[net.Conn]struct{}
+		// streams holds the entries of the streams of every database.
+		streams  map[int]map[string][]RedisStreamEntry
+		nextID   uint64
+		commands [][]string
+		// failNext is the number of XADD commands to reply to with an error.
+		failNext int
+		// unpopped holds the entries appended to any stream that haven't been
+		// popped yet, in the order they were appended.
+		unpopped []RedisStreamEntry
+		notify   chan struct{}
+	}
+}
+
+// RedisStreamEntry is an entry of a stream of the MockRedisServer.
+type RedisStreamEntry struct {
+	// Key is the key of the stream the entry was appended to.
+	Key string
+	ID  string
+	// Fields are the field-value pairs of the entry.
+	Fields []string
+}
+
+// Field returns the value of the field of the entry, if it has it.
+func (e RedisStreamEntry) Field(name string) (string, bool) {
+	for i := 0; i+1 < len(e.Fields); i += 2 {
+		if e.Fields[i] == name {
+			return e.Fields[i+1], true
+		}
+	}
+	return ``, false
+}
+
+// StartMockRedisServer starts a mock Redis server that doesn't require
+// authentication.
+func StartMockRedisServer() (*MockRedisServer, error) {
+	return StartMockRedisServerWithAuth(``, ``)
+}
+
+// StartMockRedisServerWithAuth starts a mock Redis server that requires clients
+// to authenticate with the given password, and with the given username unless
+// it is empty.
+func StartMockRedisServerWithAuth(user, password string) (*MockRedisServer, error) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if err != nil {
+		return nil, err
+	}
+	s := &MockRedisServer{ln: ln, user: user, password: password}
+	s.mu.conns = make(map[net.Conn]struct{})
+	s.mu.streams = make(map[int]map[string][]RedisStreamEntry)
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// URL returns the redis:// URL of the server.
+func (s *MockRedisServer) URL() string {
+	return `redis://` + s.ln.Addr().String()
+}
+
+// Close closes all connections and stops the server.
+func (s *MockRedisServer) Close() {
+	_ = s.ln.Close()
+	s.CloseConnections()
+	s.wg.Wait()
+}
+
+// Entries returns the entries of the stream with the given key in the given
+// database.
+func (s *MockRedisServer) Entries(db int, key string) []RedisStreamEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RedisStreamEntry(nil), s.mu.streams[db][key]...)
+}
+
+// Commands returns all commands received so far, excluding AUTH.
+func (s *MockRedisServer) Commands() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.mu.commands...)
+}
+
+// Pop removes and returns the oldest entry appended to any stream of any
+// database that hasn't been popped yet. Trimming streams doesn't affect which
+// entries are popped.
+func (s *MockRedisServer) Pop() (RedisStreamEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mu.unpopped) == 0 {
+		return RedisStreamEntry{}, false
+	}
+	oldest := s.mu.unpopped[0]
+	s.mu.unpopped = s.mu.unpopped[1:]
+	return oldest, true
+}
+
+// NotifyEntry arranges for channel to be closed when there is an entry to pop.
+func (s *MockRedisServer) NotifyEntry() chan struct{} {
+	c := make(chan struct{})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mu.unpopped) > 0 {
+		close(c)
+	} else {
+		s.mu.notify = c
+	}
+	return c
+}
+
+// FailNextXAdds causes the next n XADD commands to fail with an out of memory
+// error, as they do when Redis reaches its memory limit.
+func (s *MockRedisServer) FailNextXAdds(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failNext = n
+}
+
+// CloseConnections closes all client connections.
+func (s *MockRedisServer) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *MockRedisServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.mu.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.mu.conns, conn)
+				s.mu.Unlock()
+				_ = conn.Close()
+			}()
+			_ = s.serve(conn)
+		}()
+	}
+}
+
+// mockRedisConn is the state of a client connection.
+type mockRedisConn struct {
+	authenticated bool
+	db            int
+}
+
+func (s *MockRedisServer) serve(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	c := mockRedisConn{authenticated: s.password == ``}
+	for {
+		args, err := readMockRedisCommand(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				_, _ = fmt.Fprintf(w, "-ERR Protocol error: %s\r\n", err)
+				_ = w.Flush()
+			}
+			return err
+		}
+		s.handleCommand(w, &c, args)
+		// Only flush once all pipelined commands have been handled.
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func readMockRedisCommand(r *bufio.Reader) ([]string, error) {
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return ``, err
+		}
+		if !strings.HasSuffix(line, "\r\n") {
+			return ``, errors.New(`expected CRLF`)
+		}
+		return strings.TrimSuffix(line, "\r\n"), nil
+	}
+	readLen := func(prefix byte) (int, error) {
+		line, err := readLine()
+		if err != nil {
+			return 0, err
+		}
+		if len(line) == 0 || line[0] != prefix {
+			return 0, errors.Newf(`expected '%c', got %q`, prefix, line)
+		}
+		return strconv.Atoi(line[1:])
+	}
+
+	n, err := readLen('*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		l, err := readLen('$')
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, l+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:l])
+	}
+	return args, nil
+}
+
+func (s *MockRedisServer) handleCommand(w *bufio.Writer, c *mockRedisConn, args []string) {
+	if len(args) == 0 {
+		_, _ = w.WriteString("-ERR empty command\r\n")
+		return
+	}
+	cmd := strings.ToUpper(args[0])
+	if cmd == `AUTH` {
+		user, password := `default`, args[len(args)-1]
+		if len(args) == 3 {
+			user = args[1]
+		}
+		if len(args) < 2 || len(args) > 3 || password != s.password ||
+			(s.user != `` && user != s.user) || (s.user == `` && user != `default`) {
+			_, _ = w.WriteString("-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+			return
+		}
+		c.authenticated = true
+		_, _ = w.WriteString("+OK\r\n")
+		return
+	}
+	if !c.authenticated {
+		_, _ = w.WriteString("-NOAUTH Authentication required.\r\n")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.commands = append(s.mu.commands, args)
+	switch cmd {
+	case `PING`:
+		_, _ = w.WriteString("+PONG\r\n")
+	case `SELECT`:
+		db, err := strconv.Atoi(args[len(args)-1])
+		if len(args) != 2 || err != nil || db < 0 || db > 15 {
+			_, _ = w.WriteString("-ERR DB index is out of range\r\n")
+			return
+		}
+		c.db = db
+		_, _ = w.WriteString("+OK\r\n")
+	case `XADD`:
+		if s.mu.failNext > 0 {
+			s.mu.failNext--
+			_, _ = w.WriteString("-OOM command not allowed when used memory > 'maxmemory'.\r\n")
+			return
+		}
+		id, err := s.xaddLocked(c.db, args[1:])
+		if err != nil {
+			_, _ = fmt.Fprintf(w, "-ERR %s\r\n", err)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(id), id)
+	default:
+		_, _ = fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// xaddLocked implements XADD key [MAXLEN [=|~] threshold] <* | id> field value
+// [field value ...]. Streams are always trimmed exactly.
+func (s *MockRedisServer) xaddLocked(db int, args []string) (string, error) {
+	if len(args) < 4 {
+		return ``, errors.New(`wrong number of arguments for 'xadd' command`)
+	}
+	key, args := args[0], args[1:]
+	maxLen := -1
+	if strings.ToUpper(args[0]) == `MAXLEN` {
+		args = args[1:]
+		if args[0] == `~` || args[0] == `=` {
+			args = args[1:]
+		}
+		var err error
+		if len(args) == 0 {
+			return ``, errors.New(`syntax error`)
+		} else if maxLen, err = strconv.Atoi(args[0]); err != nil || maxLen < 0 {
+			return ``, errors.New(`The MAXLEN argument must be >= 0.`)
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] != `*` {
+		return ``, errors.New(`only auto-generated IDs are supported`)
+	}
+	fields := args[1:]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return ``, errors.New(`wrong number of arguments for 'xadd' command`)
+	}
+
+	if s.mu.streams[db] == nil {
+		s.mu.streams[db] = make(map[string][]RedisStreamEntry)
+	}
+	s.mu.nextID++
+	id := fmt.Sprintf(`0-%d`, s.mu.nextID)
+	entry := RedisStreamEntry{Key: key, ID: id, Fields: fields}
+	entries := append(s.mu.streams[db][key], entry)
+	if maxLen >= 0 && len(entries) > maxLen {
+		entries = entries[len(entries)-maxLen:]
+	}
+	s.mu.streams[db][key] = entries
+	s.mu.unpopped = append(s.mu.unpopped, entry)
+	if s.mu.notify != nil {
+		close(s.mu.notify)
+		s.mu.notify = nil
+	}
+	return id, nil
+}
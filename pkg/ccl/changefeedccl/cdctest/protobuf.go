@@ -0,0 +1,270 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package cdctest
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufScalarTypes maps the scalar type keywords used in the .proto schemas
+// generated by changefeeds to their descriptor types.
+var protobufScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	`int64`:  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	`bool`:   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	`double`: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	`string`: descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	`bytes`:  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+// ParseProtobufSchema parses the .proto source of a schema generated by a
+// changefeed with format=protobuf into a file descriptor. It only understands
+// the subset of the language changefeeds generate: a package, messages, nested
+// messages, and scalar or message fields that may be proto3 optional. Message
+// field types must be unqualified names, which are resolved like protoc does
+// by searching the enclosing scopes from the innermost outwards.
+func ParseProtobufSchema(schema string) (*descriptorpb.FileDescriptorProto, error) {
+	p := protobufSchemaParser{
+		tokens:   tokenizeProtobufSchema(schema),
+		messages: make(map[string]struct{}),
+	}
+	file := &descriptorpb.FileDescriptorProto{}
+	for !p.done() {
+		switch tok := p.next(); tok {
+		case `syntax`:
+			if err := p.expect(`=`); err != nil {
+				return nil, err
+			}
+			syntax, err := strconv.Unquote(p.next())
+			if err != nil {
+				return nil, errors.Wrap(err, `parsing syntax`)
+			}
+			file.Syntax = proto.String(syntax)
+		case `package`:
+			file.Package = proto.String(p.next())
+		case `message`:
+			msg, err := p.message(`.` + file.GetPackage())
+			if err != nil {
+				return nil, err
+			}
+			file.MessageType = append(file.MessageType, msg)
+			continue
+		default:
+			return nil, errors.Errorf(`unexpected token %q`, tok)
+		}
+		if err := p.expect(`;`); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.resolveTypeNames(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func tokenizeProtobufSchema(schema string) []string {
+	for _, sep := range []string{`{`, `}`, `;`, `=`} {
+		schema = strings.ReplaceAll(schema, sep, ` `+sep+` `)
+	}
+	return strings.Fields(schema)
+}
+
+type protobufSchemaParser struct {
+	tokens []string
+	// messages is the set of fully qualified names of the parsed messages.
+	messages map[string]struct{}
+	// unresolved holds the message fields whose TypeName is still the name
+	// used in the schema, along with the scope the field was declared in.
+	unresolved []unresolvedProtobufField
+}
+
+type unresolvedProtobufField struct {
+	field *descriptorpb.FieldDescriptorProto
+	scope string
+}
+
+func (p *protobufSchemaParser) done() bool {
+	return len(p.tokens) == 0
+}
+
+func (p *protobufSchemaParser) next() string {
+	if p.done() {
+		return ``
+	}
+	tok := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return tok
+}
+
+func (p *protobufSchemaParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return errors.Errorf(`expected %q, got %q`, tok, got)
+	}
+	return nil
+}
+
+// message parses a message whose `message` keyword has already been consumed.
+// scope is the fully qualified name of the enclosing package or message.
+func (p *protobufSchemaParser) message(scope string) (*descriptorpb.DescriptorProto, error) {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(p.next())}
+	scope += `.` + msg.GetName()
+	p.messages[scope] = struct{}{}
+	if err := p.expect(`{`); err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.next()
+		switch tok {
+		case `}`:
+			return msg, nil
+		case ``:
+			return nil, errors.Errorf(`unterminated message %s`, msg.GetName())
+		case `message`:
+			nested, err := p.message(scope)
+			if err != nil {
+				return nil, err
+			}
+			msg.NestedType = append(msg.NestedType, nested)
+			continue
+		}
+
+		f := &descriptorpb.FieldDescriptorProto{
+			Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if tok == `optional` {
+			f.Proto3Optional = proto.Bool(true)
+			tok = p.next()
+		}
+		if typ, ok := protobufScalarTypes[tok]; ok {
+			f.Type = typ.Enum()
+		} else {
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			f.TypeName = proto.String(tok)
+			p.unresolved = append(p.unresolved, unresolvedProtobufField{field: f, scope: scope})
+		}
+		f.Name = proto.String(p.next())
+		f.JsonName = f.Name
+		if err := p.expect(`=`); err != nil {
+			return nil, err
+		}
+		num, err := strconv.ParseInt(p.next(), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, `parsing number of field %s`, f.GetName())
+		}
+		f.Number = proto.Int32(int32(num))
+		if err := p.expect(`;`); err != nil {
+			return nil, err
+		}
+		if f.GetProto3Optional() {
+			// proto3 optional fields are represented in descriptors as the only
+			// field of a synthetic oneof.
+			f.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{
+				Name: proto.String(`_` + f.GetName()),
+			})
+		}
+		msg.Field = append(msg.Field, f)
+	}
+}
+
+// resolveTypeNames replaces the TypeName of every message field with the fully
+// qualified name of the message it refers to.
+func (p *protobufSchemaParser) resolveTypeNames() error {
+	for _, u := range p.unresolved {
+		name := u.field.GetTypeName()
+		for scope := u.scope; ; scope = scope[:strings.LastIndexByte(scope, '.')] {
+			if _, ok := p.messages[scope+`.`+name]; ok {
+				u.field.TypeName = proto.String(scope + `.` + name)
+				break
+			}
+			if scope == `` {
+				return errors.Errorf(`field %s refers to unknown message %s`, u.field.GetName(), name)
+			}
+		}
+	}
+	return nil
+}
+
+// EncodedProtobufToNative decodes bytes that were previously encoded by the
+// protobuf encoder with a schema registry into a GO native representation.
+// Messages are represented as maps keyed by field name, and unset fields are
+// omitted.
+func (r *SchemaRegistry) EncodedProtobufToNative(b []byte) (map[string]interface{}, error) {
+	if len(b) == 0 || b[0] != changefeedbase.ConfluentAvroWireFormatMagic {
+		return nil, errors.Errorf(`bad magic byte`)
+	}
+	b = b[1:]
+	if len(b) < 4 {
+		return nil, errors.Errorf(`missing registry id`)
+	}
+	id := int32(binary.BigEndian.Uint32(b[:4]))
+	b = b[4:]
+	// Changefeeds only generate a single top-level message per schema, whose
+	// message index list is encoded as a single 0 byte.
+	if len(b) == 0 || b[0] != 0 {
+		return nil, errors.Errorf(`unexpected message indexes`)
+	}
+	b = b[1:]
+
+	r.mu.Lock()
+	schema := r.mu.schemas[id]
+	r.mu.Unlock()
+	file, err := ParseProtobufSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	file.Name = proto.String(fmt.Sprintf(`schema_%d.proto`, id))
+	fd, err := protodesc.NewFile(file, nil /* resolver */)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return nil, err
+	}
+	return protobufMessageToNative(msg), nil
+}
+
+func protobufMessageToNative(msg protoreflect.Message) map[string]interface{} {
+	native := make(map[string]interface{})
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.MessageKind {
+			native[string(fd.Name())] = protobufMessageToNative(v.Message())
+		} else {
+			native[string(fd.Name())] = v.Interface()
+		}
+		return true
+	})
+	return native
+}
+
+// ProtobufToJSON converts protobuf bytes to their JSON representation.
+func (r *SchemaRegistry) ProtobufToJSON(protobufBytes []byte) ([]byte, error) {
+	if len(protobufBytes) == 0 {
+		return nil, nil
+	}
+	native, err := r.EncodedProtobufToNative(protobufBytes)
+	if err != nil {
+		return nil, err
+	}
+	// As with AvroToJSON, json.Marshal sorts object keys, which makes the output
+	// deterministic.
+	return json.Marshal(native)
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdceval"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcutils"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
@@ -20,10 +21,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/schemafeed"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
@@ -416,9 +419,41 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		SchemaFeed:              sf,
 		Knobs:                   ca.knobs.FeedKnobs,
 		UseMux:                  changefeedbase.UseMuxRangeFeed.Get(&cfg.Settings.SV),
+		ValueFilter:             ca.makeRangefeedValueFilter(ctx, initialHighWater),
 	}, nil
 }
 
+// makeRangefeedValueFilter returns the filter which should be pushed down to
+// the rangefeeds of the changefeed, if any. Pushing down the filter is only an
+// optimization, so errors are logged and result in no filter being pushed down.
+func (ca *changeAggregator) makeRangefeedValueFilter(
+	ctx context.Context, schemaTS hlc.Timestamp,
+) *kvpb.RangeFeedValueFilter {
+	cfg := ca.flowCtx.Cfg
+	if ca.spec.Select.Expr == "" || !changefeedbase.PushdownRangefeedFilters.Get(&cfg.Settings.SV) {
+		return nil
+	}
+	targets := AllTargets(ca.spec.Feed)
+	if targets.NumUniqueTables() != 1 {
+		return nil
+	}
+	sc, err := cdceval.ParseChangefeedExpression(ca.spec.Select.Expr)
+	if err != nil {
+		log.Warningf(ctx, "failed to push down changefeed filter: %v", err)
+		return nil
+	}
+	if schemaTS.IsEmpty() {
+		schemaTS = ca.spec.Feed.StatementTime
+	}
+	execCfg := cfg.ExecutorConfig.(*sql.ExecutorConfig)
+	descs, err := fetchTableDescriptors(ctx, execCfg, targets, schemaTS)
+	if err != nil {
+		log.Warningf(ctx, "failed to push down changefeed filter: %v", err)
+		return nil
+	}
+	return cdceval.RangefeedValueFilter(ctx, cfg.Settings, cfg.Codec, descs[0], sc)
+}
+
 // setupSpans is called on start to extract the spans for this changefeed as a
 // slice and creates a span frontier with the initial resolved timestamps. This
 // SpanFrontier only tracks the spans being watched on this node. There is a
@@ -253,6 +253,8 @@ func TestChangefeedBasics(t *testing.T) {
 	cdcTest(t, testFn, feedTestForceSink("enterprise"))
 	cdcTest(t, testFn, feedTestForceSink("webhook"))
 	cdcTest(t, testFn, feedTestForceSink("pubsub"))
+	cdcTest(t, testFn, feedTestForceSink("redis"))
+	cdcTest(t, testFn, feedTestForceSink("nats"))
 	cdcTest(t, testFn, feedTestForceSink("sinkless"))
 	cdcTest(t, testFn, feedTestForceSink("cloudstorage"))
 
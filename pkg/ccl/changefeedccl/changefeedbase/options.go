@@ -145,10 +145,11 @@ const (
 	OptEnvelopeWrapped       EnvelopeType = `wrapped`
 	OptEnvelopeBare          EnvelopeType = `bare`
 
-	OptFormatJSON    FormatType = `json`
-	OptFormatAvro    FormatType = `avro`
-	OptFormatCSV     FormatType = `csv`
-	OptFormatParquet FormatType = `parquet`
+	OptFormatJSON     FormatType = `json`
+	OptFormatAvro     FormatType = `avro`
+	OptFormatCSV      FormatType = `csv`
+	OptFormatParquet  FormatType = `parquet`
+	OptFormatProtobuf FormatType = `protobuf`
 
 	OptOnErrorFail  OnErrorType = `fail`
 	OptOnErrorPause OnErrorType = `pause`
@@ -163,6 +164,8 @@ const (
 
 	// OptKafkaSinkConfig is a JSON configuration for kafka sink (kafkaSinkConfig).
 	OptKafkaSinkConfig   = `kafka_sink_config`
+	OptNATSSinkConfig    = `nats_sink_config`
+	OptRedisSinkConfig   = `redis_sink_config`
 	OptWebhookSinkConfig = `webhook_sink_config`
 
 	// OptSink allows users to alter the Sink URI of an existing changefeed.
@@ -175,6 +178,8 @@ const (
 	SinkParamFileSize               = `file_size`
 	SinkParamPartitionFormat        = `partition_format`
 	SinkParamSchemaTopic            = `schema_topic`
+	SinkParamStreamMaxLen           = `stream_max_len`
+	SinkParamStreamMaxLenApprox     = `stream_max_len_approximate`
 	SinkParamTLSEnabled             = `tls_enabled`
 	SinkParamSkipTLSVerify          = `insecure_tls_skip_verify`
 	SinkParamTopicPrefix            = `topic_prefix`
@@ -189,7 +194,9 @@ const (
 	SinkSchemeHTTP                  = `http`
 	SinkSchemeHTTPS                 = `https`
 	SinkSchemeKafka                 = `kafka`
+	SinkSchemeNATS                  = `nats`
 	SinkSchemeNull                  = `null`
+	SinkSchemeRedis                 = `redis`
 	SinkSchemeWebhookHTTP           = `webhook-http`
 	SinkSchemeWebhookHTTPS          = `webhook-https`
 	SinkSchemeExternalConnection    = `external`
@@ -311,7 +318,7 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptCursor:                   timestampOption,
 	OptEndTime:                  timestampOption,
 	OptEnvelope:                 enum("row", "key_only", "wrapped", "deprecated_row", "bare"),
-	OptFormat:                   enum("json", "avro", "csv", "experimental_avro", "parquet"),
+	OptFormat:                   enum("json", "avro", "csv", "experimental_avro", "parquet", "protobuf"),
 	OptFullTableName:            flagOption,
 	OptKeyInValue:               flagOption,
 	OptTopicInValue:             flagOption,
@@ -330,6 +337,8 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptProtectDataFromGCOnPause: flagOption,
 	OptExpirePTSAfter:           durationOption.thatCanBeZero(),
 	OptKafkaSinkConfig:          jsonOption,
+	OptNATSSinkConfig:           jsonOption,
+	OptRedisSinkConfig:          jsonOption,
 	OptWebhookSinkConfig:        jsonOption,
 	OptWebhookAuthHeader:        stringOption,
 	OptWebhookClientTimeout:     durationOption,
@@ -356,6 +365,12 @@ var SQLValidOptions map[string]struct{} = nil
 // KafkaValidOptions is options exclusive to Kafka sink
 var KafkaValidOptions = makeStringSet(OptAvroSchemaPrefix, OptConfluentSchemaRegistry, OptKafkaSinkConfig)
 
+// NATSValidOptions is options exclusive to NATS sink
+var NATSValidOptions = makeStringSet(OptNATSSinkConfig)
+
+// RedisValidOptions is options exclusive to Redis sink
+var RedisValidOptions = makeStringSet(OptRedisSinkConfig)
+
 // CloudStorageValidOptions is options exclusive to cloud storage sink
 var CloudStorageValidOptions = makeStringSet(OptCompression)
 
@@ -371,7 +386,7 @@ var PubsubValidOptions = makeStringSet()
 // TODO(adityamaru): Some of these options should be supported when creating the
 // external connection rather than when setting up the changefeed. Move them once
 // we support `CREATE EXTERNAL CONNECTION ... WITH <options>`.
-var ExternalConnectionValidOptions = unionStringSets(SQLValidOptions, KafkaValidOptions, CloudStorageValidOptions, WebhookValidOptions, PubsubValidOptions)
+var ExternalConnectionValidOptions = unionStringSets(SQLValidOptions, KafkaValidOptions, NATSValidOptions, RedisValidOptions, CloudStorageValidOptions, WebhookValidOptions, PubsubValidOptions)
 
 // CaseInsensitiveOpts options which supports case Insensitive value
 var CaseInsensitiveOpts = makeStringSet(OptFormat, OptEnvelope, OptCompression, OptSchemaChangeEvents,
@@ -760,7 +775,15 @@ func (e EncodingOptions) Validate() error {
 			OptEnvelope, OptEnvelopeRow, OptFormat, OptFormatAvro,
 		)
 	}
-	if e.Envelope != OptEnvelopeWrapped && e.Format != OptFormatJSON && e.Format != OptFormatParquet {
+	if e.Envelope == OptEnvelopeRow && e.Format == OptFormatProtobuf {
+		return errors.Errorf(`%s=%s is not supported with %s=%s`,
+			OptEnvelope, OptEnvelopeRow, OptFormat, OptFormatProtobuf,
+		)
+	}
+	// Like JSON, protobuf places metadata under a __crdb__ field in the bare
+	// envelope.
+	canEncodeBareMetadata := e.Format == OptFormatJSON || e.Format == OptFormatProtobuf
+	if e.Envelope != OptEnvelopeWrapped && !canEncodeBareMetadata && e.Format != OptFormatParquet {
 		requiresWrap := []struct {
 			k string
 			b bool
@@ -857,6 +880,18 @@ func (s StatementOptions) GetKafkaConfigJSON() SinkSpecificJSONConfig {
 	return s.getJSONValue(OptKafkaSinkConfig)
 }
 
+// GetNATSConfigJSON returns arbitrary json to be interpreted
+// by the NATS sink.
+func (s StatementOptions) GetNATSConfigJSON() SinkSpecificJSONConfig {
+	return s.getJSONValue(OptNATSSinkConfig)
+}
+
+// GetRedisConfigJSON returns arbitrary json to be interpreted
+// by the Redis sink.
+func (s StatementOptions) GetRedisConfigJSON() SinkSpecificJSONConfig {
+	return s.getJSONValue(OptRedisSinkConfig)
+}
+
 // GetResolvedTimestampInterval gets the best-effort interval at which resolved timestamps
 // should be emitted. Nil or 0 means emit as often as possible. False means do not emit at all.
 // Returns an error for negative or invalid duration value.
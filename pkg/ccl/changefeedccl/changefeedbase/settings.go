@@ -227,6 +227,16 @@ var UseMuxRangeFeed = settings.RegisterBoolSetting(
 	util.ConstantWithMetamorphicTestBool("changefeed.mux_rangefeed.enabled", false),
 )
 
+// PushdownRangefeedFilters enables pushing the WHERE clause of changefeed
+// expressions down to KV rangefeeds.
+var PushdownRangefeedFilters = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"changefeed.rangefeed_filter_pushdown.enabled",
+	"if true, changefeeds push down the filter of their expression to rangefeeds "+
+		"so that filtered out rows are not sent by KV",
+	util.ConstantWithMetamorphicTestBool("changefeed.rangefeed_filter_pushdown.enabled", true),
+)
+
 // EventConsumerWorkers specifies the maximum number of workers to use when
 // processing  events.
 var EventConsumerWorkers = settings.RegisterIntSetting(
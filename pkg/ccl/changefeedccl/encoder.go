@@ -54,6 +54,8 @@ func getEncoder(
 		return newConfluentAvroEncoder(opts, targets, p, sliMetrics)
 	case changefeedbase.OptFormatCSV:
 		return newCSVEncoder(opts), nil
+	case changefeedbase.OptFormatProtobuf:
+		return newProtobufEncoder(opts, targets, p, sliMetrics)
 	case changefeedbase.OptFormatParquet:
 		//We will return no encoder for parquet format because there is a separate
 		//sink implemented for parquet format for cloud storage, which does the job
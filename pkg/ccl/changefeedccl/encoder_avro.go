@@ -120,17 +120,26 @@ func newConfluentAvroEncoder(
 // Get the raw SQL-formatted string for a table name
 // and apply full_table_name and avro_schema_prefix options
 func (e *confluentAvroEncoder) rawTableName(eventMeta cdcevent.Metadata) (string, error) {
-	target, found := e.targets.FindByTableIDAndFamilyName(eventMeta.TableID, eventMeta.FamilyName)
+	return rawTableNameForTarget(e.targets, e.schemaPrefix, eventMeta)
+}
+
+// rawTableNameForTarget returns the raw SQL-formatted name of the target the
+// event belongs to, prefixed with schemaPrefix. It is shared by the encoders
+// which register schemas.
+func rawTableNameForTarget(
+	targets changefeedbase.Targets, schemaPrefix string, eventMeta cdcevent.Metadata,
+) (string, error) {
+	target, found := targets.FindByTableIDAndFamilyName(eventMeta.TableID, eventMeta.FamilyName)
 	if !found {
 		return eventMeta.TableName, errors.Newf("Could not find Target for %s", eventMeta)
 	}
 	switch target.Type {
 	case jobspb.ChangefeedTargetSpecification_PRIMARY_FAMILY_ONLY:
-		return e.schemaPrefix + string(target.StatementTimeName), nil
+		return schemaPrefix + string(target.StatementTimeName), nil
 	case jobspb.ChangefeedTargetSpecification_EACH_FAMILY:
-		return fmt.Sprintf("%s%s.%s", e.schemaPrefix, target.StatementTimeName, eventMeta.FamilyName), nil
+		return fmt.Sprintf("%s%s.%s", schemaPrefix, target.StatementTimeName, eventMeta.FamilyName), nil
 	case jobspb.ChangefeedTargetSpecification_COLUMN_FAMILY:
-		return fmt.Sprintf("%s%s.%s", e.schemaPrefix, target.StatementTimeName, target.FamilyName), nil
+		return fmt.Sprintf("%s%s.%s", schemaPrefix, target.StatementTimeName, target.FamilyName), nil
 	default:
 		return "", errors.AssertionFailedf("Found a matching target with unimplemented type %s", target.Type)
 	}
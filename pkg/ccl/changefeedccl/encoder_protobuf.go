@@ -0,0 +1,248 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// protobufEncoder encodes changefeed entries as protobuf messages whose
+// descriptors are derived from the table schema. Keys are the primary key
+// columns in a message. Values are all columns in a message, optionally
+// wrapped in an envelope message. See protobuf.go for the mapping.
+//
+// If a schema registry is configured, every generated descriptor is registered
+// as a PROTOBUF schema, and messages are prefixed with the Confluent wire format
+// header referencing it. Otherwise, messages are bare protobuf.
+type protobufEncoder struct {
+	schemaRegistry schemaRegistry
+	schemaPrefix   string
+	envelopeType   changefeedbase.EnvelopeType
+	targets        changefeedbase.Targets
+	opts           protobufEnvelopeOpts
+
+	keyCache   *cache.UnorderedCache // [tableIDAndVersion]protobufRegisteredKeySchema
+	valueCache *cache.UnorderedCache // [tableIDAndVersionPair]protobufRegisteredEnvelopeSchema
+
+	// resolvedCache doesn't need to be bounded like the other caches because the
+	// number of topics is fixed per changefeed.
+	resolvedCache map[string]int32
+
+	buf []byte
+}
+
+type protobufRegisteredKeySchema struct {
+	record     *protobufRecord
+	registryID int32
+}
+
+type protobufRegisteredEnvelopeSchema struct {
+	envelope   *protobufEnvelope
+	registryID int32
+}
+
+var _ Encoder = &protobufEncoder{}
+
+func newProtobufEncoder(
+	opts changefeedbase.EncodingOptions,
+	targets changefeedbase.Targets,
+	p externalConnectionProvider,
+	sliMetrics *sliMetrics,
+) (*protobufEncoder, error) {
+	e := &protobufEncoder{
+		schemaPrefix: opts.AvroSchemaPrefix,
+		envelopeType: opts.Envelope,
+		targets:      targets,
+		opts: protobufEnvelopeOpts{
+			// As with JSON, the previous row is not included in the bare
+			// envelope; it is available to changefeed expressions instead.
+			beforeField:        opts.Diff && opts.Envelope != changefeedbase.OptEnvelopeBare,
+			keyField:           opts.KeyInValue,
+			topicField:         opts.TopicInValue,
+			updatedField:       opts.UpdatedTimestamps,
+			mvccTimestampField: opts.MVCCTimestamps,
+		},
+		keyCache:      cache.NewUnorderedCache(encoderCacheConfig),
+		valueCache:    cache.NewUnorderedCache(encoderCacheConfig),
+		resolvedCache: make(map[string]int32),
+	}
+
+	switch e.envelopeType {
+	case changefeedbase.OptEnvelopeWrapped, changefeedbase.OptEnvelopeBare, changefeedbase.OptEnvelopeKeyOnly:
+	default:
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			changefeedbase.OptEnvelope, e.envelopeType, changefeedbase.OptFormat, changefeedbase.OptFormatProtobuf)
+	}
+
+	if len(opts.SchemaRegistryURI) != 0 {
+		reg, err := newConfluentSchemaRegistry(opts.SchemaRegistryURI, p, sliMetrics)
+		if err != nil {
+			return nil, err
+		}
+		e.schemaRegistry = reg
+	}
+	return e, nil
+}
+
+// tableName returns the name used for the messages of the given table,
+// including the schema prefix.
+func (e *protobufEncoder) tableName(eventMeta cdcevent.Metadata) (string, error) {
+	return rawTableNameForTarget(e.targets, e.schemaPrefix, eventMeta)
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *protobufEncoder) EncodeKey(ctx context.Context, row cdcevent.Row) ([]byte, error) {
+	// No familyID in the cache key for keys because it's the same schema for all
+	// families.
+	cacheKey := tableIDAndVersion{tableID: row.TableID, version: row.Version}
+
+	var registered protobufRegisteredKeySchema
+	if v, ok := e.keyCache.Get(cacheKey); ok {
+		registered = v.(protobufRegisteredKeySchema)
+	} else {
+		tableName, err := e.tableName(row.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		var file *descriptorpb.FileDescriptorProto
+		registered.record, file, err = newProtobufKeySchema(row, tableName)
+		if err != nil {
+			return nil, err
+		}
+		registered.registryID, err = e.register(ctx, file, SQLNameToKafkaName(tableName)+confluentSubjectSuffixKey)
+		if err != nil {
+			return nil, err
+		}
+		e.keyCache.Add(cacheKey, registered)
+	}
+
+	e.buf = e.appendHeader(e.buf[:0], registered.registryID)
+	return registered.record.appendRow(e.buf, row.ForEachKeyColumn())
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *protobufEncoder) EncodeValue(
+	ctx context.Context, evCtx eventContext, updatedRow cdcevent.Row, prevRow cdcevent.Row,
+) ([]byte, error) {
+	if e.envelopeType == changefeedbase.OptEnvelopeKeyOnly {
+		return nil, nil
+	}
+
+	var cacheKey tableIDAndVersionPair
+	if e.opts.beforeField && prevRow.IsInitialized() {
+		cacheKey[0] = tableIDAndVersion{
+			tableID: prevRow.TableID, version: prevRow.Version, familyID: prevRow.FamilyID,
+		}
+	}
+	cacheKey[1] = tableIDAndVersion{
+		tableID: updatedRow.TableID, version: updatedRow.Version, familyID: updatedRow.FamilyID,
+	}
+
+	var registered protobufRegisteredEnvelopeSchema
+	if v, ok := e.valueCache.Get(cacheKey); ok {
+		registered = v.(protobufRegisteredEnvelopeSchema)
+	} else {
+		name, err := e.tableName(updatedRow.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		after, err := tableToProtobufRecord(updatedRow, protobufAfterRecordName)
+		if err != nil {
+			return nil, err
+		}
+		var before, key *protobufRecord
+		if e.opts.beforeField && prevRow.IsInitialized() {
+			if before, err = tableToProtobufRecord(prevRow, protobufBeforeRecordName); err != nil {
+				return nil, err
+			}
+		}
+		if e.opts.keyField {
+			if key, err = keyToProtobufRecord(updatedRow, protobufKeyRecordName); err != nil {
+				return nil, err
+			}
+		}
+		bare := e.envelopeType == changefeedbase.OptEnvelopeBare
+		registered.envelope = newProtobufEnvelope(name, bare, e.opts, key, before, after)
+		registered.registryID, err = e.register(
+			ctx, registered.envelope.file, SQLNameToKafkaName(name)+confluentSubjectSuffixValue)
+		if err != nil {
+			return nil, err
+		}
+		e.valueCache.Add(cacheKey, registered)
+	}
+
+	var meta protobufEnvelopeMeta
+	if e.opts.updatedField {
+		meta.updated = timestampToString(evCtx.updated)
+	}
+	if e.opts.mvccTimestampField {
+		meta.mvccTimestamp = timestampToString(evCtx.mvcc)
+	}
+	meta.topic = evCtx.topic
+
+	e.buf = e.appendHeader(e.buf[:0], registered.registryID)
+	return registered.envelope.appendRow(e.buf, meta, updatedRow, prevRow)
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *protobufEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	registryID, ok := e.resolvedCache[topic]
+	if !ok {
+		var err error
+		registryID, err = e.register(ctx, newProtobufResolvedSchema(topic),
+			SQLNameToKafkaName(topic)+confluentSubjectSuffixValue)
+		if err != nil {
+			return nil, err
+		}
+		e.resolvedCache[topic] = registryID
+	}
+
+	e.buf = e.appendHeader(e.buf[:0], registryID)
+	e.buf = protowire.AppendTag(e.buf, protobufResolvedField, protowire.BytesType)
+	return protowire.AppendString(e.buf, eval.TimestampToDecimalDatum(resolved).Decimal.String()), nil
+}
+
+// register publishes the descriptor to the schema registry, if there is one,
+// and returns its schema ID.
+func (e *protobufEncoder) register(
+	ctx context.Context, file *descriptorpb.FileDescriptorProto, subject string,
+) (int32, error) {
+	if e.schemaRegistry == nil {
+		return 0, nil
+	}
+	return e.schemaRegistry.RegisterProtobufSchemaForSubject(ctx, subject, protobufSchemaText(file))
+}
+
+// appendHeader appends the Confluent wire format header for the given schema ID
+// if a schema registry is in use.
+//
+//	https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format
+func (e *protobufEncoder) appendHeader(buf []byte, registryID int32) []byte {
+	if e.schemaRegistry == nil {
+		return buf
+	}
+	buf = append(buf, changefeedbase.ConfluentAvroWireFormatMagic)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(registryID))
+	// The message indexes of the encoded message within the schema. The first
+	// message, which is the only top-level message we generate, is encoded as a
+	// single 0 byte.
+	return append(buf, 0)
+}
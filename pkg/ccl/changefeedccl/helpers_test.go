@@ -946,6 +946,12 @@ func makeFeedFactoryWithOptions(
 	case "pubsub":
 		f := makePubsubFeedFactory(srvOrCluster, db)
 		return f, func() {}
+	case "redis":
+		f := makeRedisFeedFactory(srvOrCluster, db)
+		return f, func() {}
+	case "nats":
+		f := makeNATSFeedFactory(srvOrCluster, db)
+		return f, func() {}
 	case "sinkless":
 		sink, cleanup := pgURLForUser(username.RootUser)
 		f := makeSinklessFeedFactory(s, sink, pgURLForUser)
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
@@ -75,6 +76,10 @@ type Config struct {
 
 	// UseMux enables MuxRangeFeed rpc
 	UseMux bool
+
+	// ValueFilter, if set, is pushed down to the rangefeeds in order to avoid
+	// receiving events which would be filtered out by the consumer anyway.
+	ValueFilter *kvpb.RangeFeedValueFilter
 }
 
 // Run will run the kvfeed. The feed runs synchronously and returns an
@@ -110,6 +115,7 @@ func Run(ctx context.Context, cfg Config) error {
 		cfg.SchemaFeed,
 		sc, pff, bf, cfg.UseMux, cfg.Targets, cfg.Knobs)
 	f.onBackfillCallback = cfg.OnBackfillCallback
+	f.valueFilter = cfg.ValueFilter
 
 	g := ctxgroup.WithContext(ctx)
 	g.GoCtx(cfg.SchemaFeed.Run)
@@ -181,7 +187,8 @@ type kvFeed struct {
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass
 	schemaChangePolicy changefeedbase.SchemaChangePolicy
 
-	useMux bool
+	useMux      bool
+	valueFilter *kvpb.RangeFeedValueFilter
 
 	targets changefeedbase.Targets
 
@@ -477,11 +484,12 @@ func (f *kvFeed) runUntilTableEvent(
 
 	g := ctxgroup.WithContext(ctx)
 	physicalCfg := rangeFeedConfig{
-		Spans:    stps,
-		Frontier: resumeFrontier.Frontier(),
-		WithDiff: f.withDiff,
-		Knobs:    f.knobs,
-		UseMux:   f.useMux,
+		Spans:       stps,
+		Frontier:    resumeFrontier.Frontier(),
+		WithDiff:    f.withDiff,
+		Knobs:       f.knobs,
+		UseMux:      f.useMux,
+		ValueFilter: f.valueFilter,
 	}
 
 	g.GoCtx(func(ctx context.Context) error {
@@ -27,11 +27,12 @@ type physicalFeedFactory interface {
 }
 
 type rangeFeedConfig struct {
-	Frontier hlc.Timestamp
-	Spans    []kvcoord.SpanTimePair
-	WithDiff bool
-	Knobs    TestingKnobs
-	UseMux   bool
+	Frontier    hlc.Timestamp
+	Spans       []kvcoord.SpanTimePair
+	WithDiff    bool
+	Knobs       TestingKnobs
+	UseMux      bool
+	ValueFilter *kvpb.RangeFeedValueFilter
 }
 
 type rangefeedFactory func(
@@ -79,6 +80,9 @@ func (p rangefeedFactory) Run(ctx context.Context, sink kvevent.Writer, cfg rang
 	if cfg.UseMux {
 		rfOpts = append(rfOpts, kvcoord.WithMuxRangeFeed())
 	}
+	if cfg.ValueFilter != nil {
+		rfOpts = append(rfOpts, kvcoord.WithValueFilter(cfg.ValueFilter))
+	}
 
 	g.GoCtx(func(ctx context.Context) error {
 		return p(ctx, cfg.Spans, cfg.WithDiff, feed.eventC, rfOpts...)
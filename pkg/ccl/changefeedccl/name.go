@@ -19,6 +19,7 @@ import (
 var escapeRE = regexp.MustCompile(`_u[0-9a-fA-F]{2,8}_`)
 var kafkaDisallowedRE = regexp.MustCompile(`[^a-zA-Z0-9\._\-]`)
 var avroDisallowedRE = regexp.MustCompile(`[^A-Za-z0-9_]`)
+var natsDisallowedRE = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
 
 func escapeRune(r rune) string {
 	if r <= 1<<16 {
@@ -55,6 +56,51 @@ func KafkaNameToSQLName(s string) string {
 	return unescapeSQLName(s)
 }
 
+// SQLNameToNATSSubject escapes a sql table name into a valid NATS subject.
+// Unlike in Kafka topic names, `.` separates the tokens of a subject, so the
+// components of a fully qualified table name and the column family name each
+// become a token that consumers can match with subject wildcards. This is
+// reversible by NATSSubjectToSQLName except for names with empty components.
+//
+// NATS allows subjects made of non-empty tokens separated by `.`, which are
+// further restricted here to `[a-zA-Z0-9_\-]` so that they never contain
+// whitespace or the `*` and `>` wildcards.
+//
+// Runes are escaped with _u<hex>_ in an attempt to look like U+0021. For
+// example `!` escapes to `_u0021_`.
+func SQLNameToNATSSubject(s string) string {
+	tokens := strings.Split(s, `.`)
+	for i, token := range tokens {
+		if token == `` {
+			tokens[i] = escapeRune('.')
+		} else {
+			tokens[i] = escapeSQLName(token, natsDisallowedRE)
+		}
+	}
+	return strings.Join(tokens, `.`)
+}
+
+// NATSSubjectToSQLName is the inverse of SQLNameToNATSSubject.
+func NATSSubjectToSQLName(s string) string {
+	return unescapeSQLName(s)
+}
+
+// isValidNATSSubjectPrefix returns whether the prefix can be prepended to a
+// subject returned by SQLNameToNATSSubject, either as part of its first token
+// or as a sequence of tokens ending with `.`.
+func isValidNATSSubjectPrefix(prefix string) bool {
+	tokens := strings.Split(prefix, `.`)
+	for i, token := range tokens {
+		if token == `` && i != len(tokens)-1 {
+			return false
+		}
+		if natsDisallowedRE.MatchString(token) {
+			return false
+		}
+	}
+	return true
+}
+
 // SQLNameToAvroName escapes a sql table name into a valid avro record or field
 // name. This is reversible by AvroNameToSQLName.
 //
@@ -80,6 +126,19 @@ func AvroNameToSQLName(s string) string {
 	return unescapeSQLName(s)
 }
 
+// SQLNameToProtobufName escapes a sql table or column name into a valid
+// protobuf message or field name. This is reversible by ProtobufNameToSQLName.
+//
+// Protobuf identifiers have the same restrictions as Avro names.
+func SQLNameToProtobufName(s string) string {
+	return SQLNameToAvroName(s)
+}
+
+// ProtobufNameToSQLName is the inverse of SQLNameToProtobufName.
+func ProtobufNameToSQLName(s string) string {
+	return unescapeSQLName(s)
+}
+
 func escapeSQLName(s string, disallowedRE *regexp.Regexp) string {
 	// First replace anything that looks like an escape, so we can roundtrip.
 	s = escapeRE.ReplaceAllStringFunc(s, func(match string) string {
@@ -91,3 +91,41 @@ func TestSQLNameToAvroName(t *testing.T) {
 	// We don't produce capital letters in escapes but check them anyway.
 	require.Equal(t, `/`, KafkaNameToSQLName(`_u2F_`))
 }
+
+func TestSQLNameToNATSSubject(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tests := []struct {
+		sql, nats string
+	}{
+		{`foo`, `foo`},
+		{`abcdefghijklmnopqrstuvwxyz`, `abcdefghijklmnopqrstuvwxyz`},
+		{`ABCDEFGHIJKLMNOPQRSTUVWXYZ`, `ABCDEFGHIJKLMNOPQRSTUVWXYZ`},
+		{`0123456789_-`, `0123456789_-`},
+		// `.` separates tokens, which can be matched by subscribers.
+		{`db.public.foo`, `db.public.foo`},
+		{`foo bar`, `foo_u0020_bar`},
+		{`*`, `_u002a_`},
+		{`foo.>`, `foo._u003e_`},
+		{`foo_u0021_bar`, `foo_u005f__u0075__u0030__u0030__u0032__u0031__u005f_bar`},
+		{`☃`, `_u2603_`},
+		{"\x00", `_u0000_`},
+		// NATS disallows empty tokens.
+		{`.`, `_u002e_._u002e_`},
+	}
+	for i, test := range tests {
+		if n := SQLNameToNATSSubject(test.sql); n != test.nats {
+			t.Errorf(`%d: %s did not escape to %s got %s`, i, test.sql, test.nats, n)
+		}
+	}
+	for _, test := range tests[:len(tests)-1] {
+		require.Equal(t, test.sql, NATSSubjectToSQLName(test.nats))
+	}
+
+	require.True(t, isValidNATSSubjectPrefix(`cdc.`))
+	require.True(t, isValidNATSSubjectPrefix(`cdc.events_`))
+	require.False(t, isValidNATSSubjectPrefix(`.cdc`))
+	require.False(t, isValidNATSSubjectPrefix(`cdc..`))
+	require.False(t, isValidNATSSubjectPrefix(`cdc.*.`))
+}
@@ -0,0 +1,502 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// natsConn is a minimal client for the NATS client protocol, implementing only
+// what the NATS sink needs: publishing messages (with headers) and receiving
+// the replies sent to a private inbox subject, which is how JetStream
+// acknowledges published messages.
+//
+//	https://docs.nats.io/reference/reference-protocols/nats-protocol
+//
+// Publishes are buffered until flush is called. Messages received on the inbox
+// are passed to onMsg from a reader goroutine; if the connection fails, onErr is
+// called once with the error and all further operations fail with it.
+type natsConn struct {
+	conn  net.Conn
+	info  natsServerInfo
+	inbox string
+
+	onMsg func(natsMsg)
+	onErr func(error)
+
+	readerDone chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		w      *bufio.Writer
+		err    error
+		closed bool
+	}
+}
+
+// natsServerInfo is the subset of the INFO message sent by the server upon
+// connection that the client cares about.
+type natsServerInfo struct {
+	ServerID     string `json:"server_id"`
+	Version      string `json:"version"`
+	Headers      bool   `json:"headers"`
+	MaxPayload   int64  `json:"max_payload"`
+	TLSRequired  bool   `json:"tls_required"`
+	AuthRequired bool   `json:"auth_required"`
+}
+
+// natsConnectOptions is the CONNECT message sent by the client.
+type natsConnectOptions struct {
+	Verbose      bool   `json:"verbose"`
+	Pedantic     bool   `json:"pedantic"`
+	TLSRequired  bool   `json:"tls_required"`
+	Name         string `json:"name,omitempty"`
+	Lang         string `json:"lang"`
+	Version      string `json:"version"`
+	Protocol     int    `json:"protocol"`
+	Headers      bool   `json:"headers"`
+	NoResponders bool   `json:"no_responders"`
+	User         string `json:"user,omitempty"`
+	Pass         string `json:"pass,omitempty"`
+	AuthToken    string `json:"auth_token,omitempty"`
+}
+
+// natsDialConfig configures how natsConn connects to and authenticates with
+// the server.
+type natsDialConfig struct {
+	tls      *tls.Config
+	user     string
+	password string
+	token    string
+	timeout  time.Duration
+}
+
+// natsHeader is the set of headers of a NATS message.
+type natsHeader map[string]string
+
+// natsHeaderVersion is the version line starting every encoded header block.
+const natsHeaderVersion = `NATS/1.0`
+
+// natsStatusNoResponders is the status of the message the server sends in
+// reply to a publish for which there were no subscribers, which for JetStream
+// means that no stream is bound to the subject.
+const natsStatusNoResponders = 503
+
+// natsMsg is a message received from the server.
+type natsMsg struct {
+	subject string
+	reply   string
+	header  natsHeader
+	data    []byte
+	// status and description are set if the message has an inline status, as
+	// is the case for "no responders" messages.
+	status      int
+	description string
+}
+
+// dialNATS connects to the NATS server at addr.
+func dialNATS(
+	ctx context.Context, addr string, cfg natsDialConfig, onMsg func(natsMsg), onErr func(error),
+) (*natsConn, error) {
+	d := net.Dialer{Timeout: cfg.timeout}
+	conn, err := d.DialContext(ctx, `tcp`, addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &natsConn{
+		conn:       conn,
+		onMsg:      onMsg,
+		onErr:      onErr,
+		readerDone: make(chan struct{}),
+	}
+	r, err := c.handshake(addr, cfg)
+	if err != nil {
+		_ = c.conn.Close()
+		return nil, err
+	}
+	go c.readLoop(r)
+	return c, nil
+}
+
+// handshake exchanges INFO and CONNECT with the server, upgrading the
+// connection to TLS if necessary, and subscribes to the inbox. It returns the
+// reader to use for the rest of the connection.
+func (c *natsConn) handshake(addr string, cfg natsDialConfig) (*bufio.Reader, error) {
+	if cfg.timeout != 0 {
+		if err := c.conn.SetDeadline(time.Now().Add(cfg.timeout)); err != nil {
+			return nil, err
+		}
+		defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+	}
+
+	r := bufio.NewReader(c.conn)
+	line, err := readNATSLine(r)
+	if err != nil {
+		return nil, errors.Wrap(err, `reading server info`)
+	}
+	op, args := splitNATSOp(line)
+	if op != `INFO` {
+		return nil, errors.Errorf(`expected INFO from server, got %q`, line)
+	}
+	if err := json.Unmarshal([]byte(args), &c.info); err != nil {
+		return nil, errors.Wrap(err, `parsing server info`)
+	}
+	if !c.info.Headers {
+		return nil, errors.Errorf(`NATS server %s does not support message headers`, c.info.Version)
+	}
+
+	if cfg.tls != nil || c.info.TLSRequired {
+		tlsCfg := cfg.tls
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		if tlsCfg.ServerName == `` && !tlsCfg.InsecureSkipVerify {
+			tlsCfg = tlsCfg.Clone()
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				tlsCfg.ServerName = host
+			}
+		}
+		tlsConn := tls.Client(c.conn, tlsCfg)
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, errors.Wrap(err, `TLS handshake`)
+		}
+		c.conn = tlsConn
+		r = bufio.NewReader(c.conn)
+	}
+
+	var inboxID [11]byte
+	if _, err := rand.Read(inboxID[:]); err != nil {
+		return nil, err
+	}
+	c.inbox = `_INBOX.` + hex.EncodeToString(inboxID[:])
+
+	connect, err := json.Marshal(natsConnectOptions{
+		TLSRequired:  cfg.tls != nil,
+		Name:         `CockroachDB`,
+		Lang:         `go`,
+		Version:      build.BinaryVersion(),
+		Protocol:     1,
+		Headers:      true,
+		NoResponders: true,
+		User:         cfg.user,
+		Pass:         cfg.password,
+		AuthToken:    cfg.token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.w = bufio.NewWriter(c.conn)
+	fmt.Fprintf(c.mu.w, "CONNECT %s\r\nPING\r\nSUB %s.* 1\r\n", connect, c.inbox)
+	if err := c.mu.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	// The server replies to the PING once it has processed the CONNECT, or
+	// responds with an error if, for instance, authentication failed.
+	for {
+		line, err := readNATSLine(r)
+		if err != nil {
+			return nil, errors.Wrap(err, `connecting to NATS`)
+		}
+		switch op, args := splitNATSOp(line); op {
+		case `PONG`:
+			return r, nil
+		case `-ERR`:
+			return nil, errors.Newf(`connecting to NATS: %s`, strings.Trim(args, `'`))
+		case `INFO`, `+OK`, `PING`:
+		default:
+			return nil, errors.Errorf(`unexpected message from NATS server: %q`, line)
+		}
+	}
+}
+
+// replySubject returns the subject on which the reply with the given id is to
+// be received.
+func (c *natsConn) replySubject(id uint64) string {
+	return c.inbox + `.` + strconv.FormatUint(id, 10)
+}
+
+// replyID is the inverse of replySubject.
+func (c *natsConn) replyID(subject string) (uint64, bool) {
+	if len(subject) <= len(c.inbox)+1 || subject[:len(c.inbox)] != c.inbox {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(subject[len(c.inbox)+1:], 10, 64)
+	return id, err == nil
+}
+
+// publish buffers a message to be published to subject, with replies sent to
+// reply. The message is only sent to the server once flush is called or the
+// buffer fills up.
+func (c *natsConn) publish(subject, reply string, header natsHeader, data []byte) error {
+	if c.info.MaxPayload > 0 && int64(len(data)) > c.info.MaxPayload {
+		return errors.Errorf(`message of %d bytes exceeds the maximum payload of %d bytes of the NATS server`,
+			len(data), c.info.MaxPayload)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mu.err != nil {
+		return c.mu.err
+	}
+	w := c.mu.w
+	if len(header) == 0 {
+		fmt.Fprintf(w, "PUB %s %s %d\r\n", subject, reply, len(data))
+	} else {
+		hdr := header.encode()
+		fmt.Fprintf(w, "HPUB %s %s %d %d\r\n", subject, reply, len(hdr), len(hdr)+len(data))
+		_, _ = w.Write(hdr)
+	}
+	_, _ = w.Write(data)
+	if _, err := w.WriteString("\r\n"); err != nil {
+		c.mu.err = errors.Wrap(err, `NATS connection failed`)
+		return c.mu.err
+	}
+	return nil
+}
+
+// flush sends all buffered messages to the server.
+func (c *natsConn) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mu.err != nil {
+		return c.mu.err
+	}
+	if err := c.mu.w.Flush(); err != nil {
+		c.mu.err = errors.Wrap(err, `NATS connection failed`)
+		return c.mu.err
+	}
+	return nil
+}
+
+// close closes the connection and waits for the reader goroutine to exit.
+func (c *natsConn) close() error {
+	c.mu.Lock()
+	c.mu.closed = true
+	if c.mu.err == nil {
+		c.mu.err = errors.New(`NATS connection closed`)
+	}
+	c.mu.Unlock()
+	err := c.conn.Close()
+	<-c.readerDone
+	return err
+}
+
+func (c *natsConn) readLoop(r *bufio.Reader) {
+	defer close(c.readerDone)
+	err := errors.Wrap(c.read(r), `NATS connection failed`)
+
+	c.mu.Lock()
+	closed := c.mu.closed
+	if c.mu.err == nil {
+		c.mu.err = err
+	}
+	c.mu.Unlock()
+	// Don't report errors caused by closing the connection ourselves.
+	if !closed {
+		c.onErr(err)
+	}
+}
+
+func (c *natsConn) read(r *bufio.Reader) error {
+	for {
+		line, err := readNATSLine(r)
+		if err != nil {
+			return err
+		}
+		op, args := splitNATSOp(line)
+		switch op {
+		case `MSG`, `HMSG`:
+			msg, err := readNATSMsg(r, op == `HMSG`, args)
+			if err != nil {
+				return err
+			}
+			c.onMsg(msg)
+		case `PING`:
+			c.mu.Lock()
+			_, _ = c.mu.w.WriteString("PONG\r\n")
+			err := c.mu.w.Flush()
+			c.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		case `PONG`, `+OK`, `INFO`:
+		case `-ERR`:
+			// The server closes the connection after almost all errors, so we
+			// treat all of them as fatal.
+			return errors.Newf(`NATS server error: %s`, strings.Trim(args, `'`))
+		default:
+			return errors.Errorf(`unexpected message from NATS server: %q`, line)
+		}
+	}
+}
+
+// readNATSMsg reads the payload of a MSG or HMSG message with the given
+// arguments.
+func readNATSMsg(r *bufio.Reader, hasHeader bool, args string) (natsMsg, error) {
+	var msg natsMsg
+	fields := strings.Fields(args)
+	// MSG <subject> <sid> [reply-to] <#bytes>
+	// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+	minFields := 3
+	if hasHeader {
+		minFields = 4
+	}
+	if len(fields) < minFields || len(fields) > minFields+1 {
+		return msg, errors.Errorf(`malformed NATS message arguments: %q`, args)
+	}
+	msg.subject = fields[0]
+	if len(fields) == minFields+1 {
+		msg.reply = fields[2]
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || total < 0 {
+		return msg, errors.Errorf(`malformed NATS message size: %q`, args)
+	}
+	hdrLen := 0
+	if hasHeader {
+		if hdrLen, err = strconv.Atoi(fields[len(fields)-2]); err != nil || hdrLen < 0 || hdrLen > total {
+			return msg, errors.Errorf(`malformed NATS message header size: %q`, args)
+		}
+	}
+
+	buf := make([]byte, total+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return msg, err
+	}
+	if !bytes.HasSuffix(buf, []byte("\r\n")) {
+		return msg, errors.New(`NATS message payload not terminated by CRLF`)
+	}
+	if hasHeader {
+		if err := msg.decodeHeader(buf[:hdrLen]); err != nil {
+			return msg, err
+		}
+	}
+	msg.data = buf[hdrLen:total]
+	return msg, nil
+}
+
+// encode returns the wire representation of the headers, with keys sorted so
+// that the encoding is deterministic.
+func (h natsHeader) encode() []byte {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString(natsHeaderVersion + "\r\n")
+	for _, k := range keys {
+		buf.WriteString(k)
+		buf.WriteString(`: `)
+		buf.WriteString(h[k])
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// decodeHeader parses an encoded header block, which starts with a version
+// line optionally followed by an inline status and description.
+func (m *natsMsg) decodeHeader(hdr []byte) error {
+	lines := strings.Split(strings.TrimSuffix(string(hdr), "\r\n\r\n"), "\r\n")
+	if !strings.HasPrefix(lines[0], natsHeaderVersion) {
+		return errors.Errorf(`malformed NATS message header: %q`, hdr)
+	}
+	if status := strings.TrimSpace(lines[0][len(natsHeaderVersion):]); status != `` {
+		code, description, _ := strings.Cut(status, ` `)
+		var err error
+		if m.status, err = strconv.Atoi(code); err != nil {
+			return errors.Errorf(`malformed NATS message status: %q`, lines[0])
+		}
+		m.description = description
+	}
+	for _, line := range lines[1:] {
+		k, v, ok := strings.Cut(line, `:`)
+		if !ok {
+			return errors.Errorf(`malformed NATS message header: %q`, line)
+		}
+		if m.header == nil {
+			m.header = make(natsHeader)
+		}
+		m.header[k] = strings.TrimSpace(v)
+	}
+	return nil
+}
+
+func readNATSLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return ``, err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// splitNATSOp splits a protocol line into its (upper-cased) operation and its
+// arguments.
+func splitNATSOp(line string) (op string, args string) {
+	op, args, _ = strings.Cut(line, ` `)
+	return strings.ToUpper(op), strings.TrimSpace(args)
+}
+
+// jetStreamPubAck is the reply JetStream sends to a published message.
+//
+//	https://docs.nats.io/reference/reference-protocols/nats_api_reference
+type jetStreamPubAck struct {
+	Stream    string             `json:"stream"`
+	Sequence  uint64             `json:"seq"`
+	Duplicate bool               `json:"duplicate,omitempty"`
+	Error     *jetStreamAPIError `json:"error,omitempty"`
+}
+
+type jetStreamAPIError struct {
+	Code        int    `json:"code"`
+	ErrCode     int    `json:"err_code,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// decodeJetStreamPubAck decodes the reply to a published message, returning
+// an error if the message was not persisted.
+func decodeJetStreamPubAck(msg natsMsg) (jetStreamPubAck, error) {
+	var ack jetStreamPubAck
+	if msg.status == natsStatusNoResponders {
+		return ack, errors.Newf(`no JetStream stream is bound to subject`)
+	}
+	if msg.status != 0 {
+		return ack, errors.Newf(`unexpected JetStream reply status %d: %s`, msg.status, msg.description)
+	}
+	if err := json.Unmarshal(msg.data, &ack); err != nil {
+		return ack, errors.Wrap(err, `decoding JetStream publish ack`)
+	}
+	if ack.Error != nil {
+		return ack, errors.Newf(`JetStream error %d: %s`, ack.Error.Code, ack.Error.Description)
+	}
+	if ack.Stream == `` {
+		return ack, errors.Errorf(`invalid JetStream publish ack: %q`, msg.data)
+	}
+	return ack, nil
+}
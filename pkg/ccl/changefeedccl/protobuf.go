@@ -0,0 +1,568 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// This file maps SQL table schemas to protobuf message descriptors and encodes
+// rows as messages conforming to them. Like avro.go, it is not intended to be a
+// general purpose protobuf utility.
+//
+// Each column is mapped to a proto3 `optional` field so that NULLs can be
+// distinguished from zero values. Whenever possible the field number of a
+// column is its column ID (pg_attribute.attnum), which is stable across schema
+// changes: adding or dropping a column does not renumber the remaining fields,
+// so every message descriptor generated for a table remains wire compatible
+// with the ones generated before it. Types without a natural protobuf
+// counterpart are encoded as their textual SQL representation.
+
+const (
+	// protobufPackage is the package of every generated .proto file.
+	protobufPackage = `cockroachdb.changefeed`
+
+	// protobufMetaFieldName is the name of the metadata field in the bare
+	// envelope. It matches the key used by the JSON encoder.
+	protobufMetaFieldName = jsonMetaSentinel
+	// protobufMetaFieldNumber is the field number of the metadata field in the
+	// bare envelope. It is the largest valid field number so that it never
+	// collides with a column ID.
+	protobufMetaFieldNumber = protowire.MaxValidNumber
+)
+
+// Field numbers of the messages that wrap rows. These are fixed regardless of
+// the changefeed options so that consumers can rely on them.
+const (
+	protobufEnvelopeAfterField         protowire.Number = 1
+	protobufEnvelopeBeforeField        protowire.Number = 2
+	protobufEnvelopeKeyField           protowire.Number = 3
+	protobufEnvelopeTopicField         protowire.Number = 4
+	protobufEnvelopeUpdatedField       protowire.Number = 5
+	protobufEnvelopeMVCCTimestampField protowire.Number = 6
+
+	protobufMetaUpdatedField       protowire.Number = 1
+	protobufMetaMVCCTimestampField protowire.Number = 2
+	protobufMetaKeyField           protowire.Number = 3
+	protobufMetaTopicField         protowire.Number = 4
+
+	protobufResolvedField protowire.Number = 1
+)
+
+// Names of the nested message types in generated descriptors.
+const (
+	protobufAfterRecordName  = `after_record`
+	protobufBeforeRecordName = `before_record`
+	protobufKeyRecordName    = `key_record`
+	protobufMetaRecordName   = `crdb_metadata`
+)
+
+// protobufEncodeFn appends the encoding of a non-NULL datum as the field with
+// the given number to buf.
+type protobufEncodeFn func(buf []byte, num protowire.Number, d tree.Datum) []byte
+
+// protobufField is the protobuf representation of a single column.
+type protobufField struct {
+	name     string
+	number   protowire.Number
+	typ      descriptorpb.FieldDescriptorProto_Type
+	encodeFn protobufEncodeFn
+}
+
+// protobufRecord is the protobuf representation of a set of columns, for
+// example the primary key or all the columns in a column family. Its fields are
+// in the order of the cdcevent.Iterator it was created from.
+type protobufRecord struct {
+	name   string
+	fields []protobufField
+}
+
+// protobufEnvelopeOpts controls which fields are included in a generated
+// envelope message.
+type protobufEnvelopeOpts struct {
+	beforeField, keyField, topicField, updatedField, mvccTimestampField bool
+}
+
+// protobufEnvelope is the message used as a changefeed value. In the wrapped
+// envelope the row is nested under `after`; in the bare envelope the columns
+// are at the top level, next to a `__crdb__` metadata field.
+type protobufEnvelope struct {
+	bare          bool
+	opts          protobufEnvelopeOpts
+	key           *protobufRecord
+	before, after *protobufRecord
+	file          *descriptorpb.FileDescriptorProto
+
+	// scratch is used to encode nested messages before they are appended to the
+	// output with their length prefix.
+	scratch []byte
+}
+
+func encodeProtobufInt(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.VarintType)
+	return protowire.AppendVarint(buf, uint64(tree.MustBeDInt(d)))
+}
+
+func encodeProtobufBool(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.VarintType)
+	return protowire.AppendVarint(buf, protowire.EncodeBool(bool(tree.MustBeDBool(d))))
+}
+
+func encodeProtobufFloat(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(buf, math.Float64bits(float64(tree.MustBeDFloat(d))))
+}
+
+func encodeProtobufString(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, string(tree.MustBeDString(d)))
+}
+
+func encodeProtobufCollatedString(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, tree.UnwrapDOidWrapper(d).(*tree.DCollatedString).Contents)
+}
+
+func encodeProtobufBytes(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, string(tree.MustBeDBytes(d)))
+}
+
+// encodeProtobufText encodes any datum using its textual SQL representation.
+func encodeProtobufText(buf []byte, num protowire.Number, d tree.Datum) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, tree.AsStringWithFlags(d, tree.FmtExport))
+}
+
+// typeToProtobufField returns the protobuf field type used for a SQL type and
+// the function encoding datums of that type.
+func typeToProtobufField(
+	typ *types.T,
+) (descriptorpb.FieldDescriptorProto_Type, protobufEncodeFn) {
+	switch typ.Family() {
+	case types.IntFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64, encodeProtobufInt
+	case types.BoolFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_BOOL, encodeProtobufBool
+	case types.FloatFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, encodeProtobufFloat
+	case types.StringFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, encodeProtobufString
+	case types.CollatedStringFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, encodeProtobufCollatedString
+	case types.BytesFamily:
+		return descriptorpb.FieldDescriptorProto_TYPE_BYTES, encodeProtobufBytes
+	default:
+		// Decimals, timestamps, intervals, UUIDs, arrays, enums, JSON and so on
+		// are encoded as text. This is lossless and matches what Postgres
+		// clients see, at the cost of requiring consumers to parse them.
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, encodeProtobufText
+	}
+}
+
+// newProtobufRecord returns the protobuf representation of the columns
+// produced by the given iterator.
+func newProtobufRecord(name string, it cdcevent.Iterator) (*protobufRecord, error) {
+	r := &protobufRecord{name: name}
+	useColumnIDs := true
+	seen := make(map[uint32]struct{})
+	if err := it.Col(func(col cdcevent.ResultColumn) error {
+		typ, encodeFn := typeToProtobufField(col.Typ)
+		num := protowire.Number(col.PGAttributeNum)
+		r.fields = append(r.fields, protobufField{
+			name:     SQLNameToProtobufName(col.Name),
+			number:   num,
+			typ:      typ,
+			encodeFn: encodeFn,
+		})
+		// Columns produced by changefeed expressions may not have a column ID.
+		// Fall back to positional field numbers if any of them lacks one.
+		if _, dup := seen[col.PGAttributeNum]; dup || !num.IsValid() || num == protobufMetaFieldNumber {
+			useColumnIDs = false
+		}
+		seen[col.PGAttributeNum] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if !useColumnIDs {
+		for i := range r.fields {
+			r.fields[i].number = protowire.Number(i + 1)
+		}
+	}
+	return r, nil
+}
+
+// appendRow appends the fields of the row produced by the iterator to buf.
+// NULL columns are omitted.
+func (r *protobufRecord) appendRow(buf []byte, it cdcevent.Iterator) ([]byte, error) {
+	i := 0
+	err := it.Datum(func(d tree.Datum, _ cdcevent.ResultColumn) error {
+		if i >= len(r.fields) {
+			return errors.AssertionFailedf("row has more columns than protobuf record %s", r.name)
+		}
+		f := &r.fields[i]
+		i++
+		if d == tree.DNull {
+			return nil
+		}
+		buf = f.encodeFn(buf, f.number, d)
+		return nil
+	})
+	return buf, err
+}
+
+// descriptor returns the message descriptor for the record. Every field is a
+// proto3 optional field, which is represented in descriptors as a field in a
+// synthetic oneof.
+func (r *protobufRecord) descriptor() *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(r.name)}
+	for _, f := range r.fields {
+		msg.Field = append(msg.Field, protobufOptionalField(msg, f.name, f.number, f.typ, ``))
+	}
+	return msg
+}
+
+// protobufOptionalField adds a synthetic oneof for a proto3 optional field to
+// msg and returns the field's descriptor. typeName must be set for message
+// fields.
+func protobufOptionalField(
+	msg *descriptorpb.DescriptorProto,
+	name string,
+	num protowire.Number,
+	typ descriptorpb.FieldDescriptorProto_Type,
+	typeName string,
+) *descriptorpb.FieldDescriptorProto {
+	oneofIdx := int32(len(msg.OneofDecl))
+	msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{
+		Name: proto.String(`_` + name),
+	})
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:           proto.String(name),
+		Number:         proto.Int32(int32(num)),
+		Label:          descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:           typ.Enum(),
+		JsonName:       proto.String(name),
+		OneofIndex:     proto.Int32(oneofIdx),
+		Proto3Optional: proto.Bool(true),
+	}
+	if typeName != `` {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// protobufMessageField returns the descriptor of a field holding a nested
+// message. Message fields always have presence in proto3, so they don't need
+// to be declared optional.
+func protobufMessageField(
+	name string, num protowire.Number, typeName string,
+) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(int32(num)),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(typeName),
+		JsonName: proto.String(name),
+	}
+}
+
+func protobufFileDescriptor(
+	name string, msg *descriptorpb.DescriptorProto,
+) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(name + `.proto`),
+		Package:     proto.String(protobufPackage),
+		Syntax:      proto.String(`proto3`),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}
+}
+
+// keyToProtobufRecord returns the protobuf record for the primary key of the
+// table the row belongs to.
+func keyToProtobufRecord(row cdcevent.Row, name string) (*protobufRecord, error) {
+	return newProtobufRecord(name, row.ForEachKeyColumn())
+}
+
+// tableToProtobufRecord returns the protobuf record for all the columns of the
+// column family the row belongs to.
+func tableToProtobufRecord(row cdcevent.Row, name string) (*protobufRecord, error) {
+	return newProtobufRecord(name, row.ForEachColumn())
+}
+
+// newProtobufKeySchema returns the message descriptor of a changefeed key.
+func newProtobufKeySchema(
+	row cdcevent.Row, tableName string,
+) (*protobufRecord, *descriptorpb.FileDescriptorProto, error) {
+	name := SQLNameToProtobufName(tableName) + `_key`
+	r, err := keyToProtobufRecord(row, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, protobufFileDescriptor(name, r.descriptor()), nil
+}
+
+// newProtobufEnvelope returns the protobuf representation of a changefeed
+// value. before may be nil if the previous row is not included, and key may be
+// nil if the key is not included.
+func newProtobufEnvelope(
+	tableName string, bare bool, opts protobufEnvelopeOpts, key, before, after *protobufRecord,
+) *protobufEnvelope {
+	e := &protobufEnvelope{bare: bare, opts: opts, key: key, before: before, after: after}
+
+	var msg *descriptorpb.DescriptorProto
+	msgName := SQLNameToProtobufName(tableName)
+	if bare {
+		msg = after.descriptor()
+		msg.Name = proto.String(msgName)
+	} else {
+		msgName += `_envelope`
+		msg = &descriptorpb.DescriptorProto{Name: proto.String(msgName)}
+	}
+	qualify := func(nested string) string {
+		return fmt.Sprintf(`.%s.%s.%s`, protobufPackage, msgName, nested)
+	}
+	addNested := func(r *protobufRecord, name string) {
+		d := r.descriptor()
+		d.Name = proto.String(name)
+		msg.NestedType = append(msg.NestedType, d)
+	}
+
+	if bare {
+		meta := &descriptorpb.DescriptorProto{Name: proto.String(protobufMetaRecordName)}
+		if opts.updatedField {
+			meta.Field = append(meta.Field, protobufOptionalField(meta, `updated`,
+				protobufMetaUpdatedField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+		if opts.mvccTimestampField {
+			meta.Field = append(meta.Field, protobufOptionalField(meta, `mvcc_timestamp`,
+				protobufMetaMVCCTimestampField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+		if opts.keyField {
+			addNested(key, protobufKeyRecordName)
+			meta.Field = append(meta.Field, protobufMessageField(`key`,
+				protobufMetaKeyField, qualify(protobufKeyRecordName)))
+		}
+		if opts.topicField {
+			meta.Field = append(meta.Field, protobufOptionalField(meta, `topic`,
+				protobufMetaTopicField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+		if len(meta.Field) > 0 {
+			msg.NestedType = append(msg.NestedType, meta)
+			msg.Field = append(msg.Field, protobufMessageField(protobufMetaFieldName,
+				protobufMetaFieldNumber, qualify(protobufMetaRecordName)))
+		}
+	} else {
+		addNested(after, protobufAfterRecordName)
+		msg.Field = append(msg.Field, protobufMessageField(`after`,
+			protobufEnvelopeAfterField, qualify(protobufAfterRecordName)))
+		if opts.beforeField {
+			// The previous row may have been written with a different version of
+			// the table, so it gets its own message type.
+			if before == nil {
+				before = after
+			}
+			addNested(before, protobufBeforeRecordName)
+			msg.Field = append(msg.Field, protobufMessageField(`before`,
+				protobufEnvelopeBeforeField, qualify(protobufBeforeRecordName)))
+		}
+		if opts.keyField {
+			addNested(key, protobufKeyRecordName)
+			msg.Field = append(msg.Field, protobufMessageField(`key`,
+				protobufEnvelopeKeyField, qualify(protobufKeyRecordName)))
+		}
+		if opts.topicField {
+			msg.Field = append(msg.Field, protobufOptionalField(msg, `topic`,
+				protobufEnvelopeTopicField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+		if opts.updatedField {
+			msg.Field = append(msg.Field, protobufOptionalField(msg, `updated`,
+				protobufEnvelopeUpdatedField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+		if opts.mvccTimestampField {
+			msg.Field = append(msg.Field, protobufOptionalField(msg, `mvcc_timestamp`,
+				protobufEnvelopeMVCCTimestampField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+		}
+	}
+	e.file = protobufFileDescriptor(msgName, msg)
+	return e
+}
+
+// appendNested appends a length-delimited nested message produced by encodeFn
+// as the field with the given number.
+func (e *protobufEnvelope) appendNested(
+	buf []byte, num protowire.Number, encodeFn func([]byte) ([]byte, error),
+) ([]byte, error) {
+	var err error
+	e.scratch, err = encodeFn(e.scratch[:0])
+	if err != nil {
+		return nil, err
+	}
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, e.scratch), nil
+}
+
+// protobufEnvelopeMeta holds the per-event metadata of an envelope. Fields are
+// only encoded if the corresponding protobufEnvelopeOpts field is set.
+type protobufEnvelopeMeta struct {
+	updated, mvccTimestamp, topic string
+}
+
+// appendRow appends the encoding of the given rows to buf. prev may be
+// uninitialized, and is ignored if the envelope has no before field.
+func (e *protobufEnvelope) appendRow(
+	buf []byte, meta protobufEnvelopeMeta, updated, prev cdcevent.Row,
+) ([]byte, error) {
+	appendString := func(buf []byte, num protowire.Number, s string) []byte {
+		buf = protowire.AppendTag(buf, num, protowire.BytesType)
+		return protowire.AppendString(buf, s)
+	}
+	appendKey := func(buf []byte) ([]byte, error) {
+		return e.key.appendRow(buf, updated.ForEachKeyColumn())
+	}
+
+	var err error
+	if e.bare {
+		if updated.HasValues() && !updated.IsDeleted() {
+			if buf, err = e.after.appendRow(buf, updated.ForEachColumn()); err != nil {
+				return nil, err
+			}
+		}
+		o := e.opts
+		if !(o.updatedField || o.mvccTimestampField || o.keyField || o.topicField) {
+			return buf, nil
+		}
+		// The key is nested inside the metadata message, so it must be encoded
+		// before the scratch buffer is used for the metadata.
+		var key []byte
+		if o.keyField {
+			if key, err = appendKey(nil); err != nil {
+				return nil, err
+			}
+		}
+		return e.appendNested(buf, protobufMetaFieldNumber, func(b []byte) ([]byte, error) {
+			if o.updatedField {
+				b = appendString(b, protobufMetaUpdatedField, meta.updated)
+			}
+			if o.mvccTimestampField {
+				b = appendString(b, protobufMetaMVCCTimestampField, meta.mvccTimestamp)
+			}
+			if o.keyField {
+				b = protowire.AppendTag(b, protobufMetaKeyField, protowire.BytesType)
+				b = protowire.AppendBytes(b, key)
+			}
+			if o.topicField {
+				b = appendString(b, protobufMetaTopicField, meta.topic)
+			}
+			return b, nil
+		})
+	}
+
+	// A deleted row has no after field, which consumers observe as an unset
+	// message field.
+	if updated.HasValues() && !updated.IsDeleted() {
+		if buf, err = e.appendNested(buf, protobufEnvelopeAfterField, func(b []byte) ([]byte, error) {
+			return e.after.appendRow(b, updated.ForEachColumn())
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if e.opts.beforeField && prev.IsInitialized() && !prev.IsDeleted() {
+		before := e.before
+		if before == nil {
+			before = e.after
+		}
+		if buf, err = e.appendNested(buf, protobufEnvelopeBeforeField, func(b []byte) ([]byte, error) {
+			return before.appendRow(b, prev.ForEachColumn())
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if e.opts.keyField {
+		if buf, err = e.appendNested(buf, protobufEnvelopeKeyField, appendKey); err != nil {
+			return nil, err
+		}
+	}
+	if e.opts.topicField {
+		buf = appendString(buf, protobufEnvelopeTopicField, meta.topic)
+	}
+	if e.opts.updatedField {
+		buf = appendString(buf, protobufEnvelopeUpdatedField, meta.updated)
+	}
+	if e.opts.mvccTimestampField {
+		buf = appendString(buf, protobufEnvelopeMVCCTimestampField, meta.mvccTimestamp)
+	}
+	return buf, nil
+}
+
+// newProtobufResolvedSchema returns the message descriptor of a resolved
+// timestamp payload for the given topic.
+func newProtobufResolvedSchema(topic string) *descriptorpb.FileDescriptorProto {
+	name := SQLNameToProtobufName(topic) + `_resolved`
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	msg.Field = append(msg.Field, protobufOptionalField(msg, `resolved`,
+		protobufResolvedField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``))
+	return protobufFileDescriptor(name, msg)
+}
+
+// protobufSchemaText renders a file descriptor generated by this file as
+// .proto source, which is the form schema registries accept.
+func protobufSchemaText(file *descriptorpb.FileDescriptorProto) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "syntax = %q;\n\npackage %s;\n", file.GetSyntax(), file.GetPackage())
+	for _, msg := range file.MessageType {
+		b.WriteString("\n")
+		writeProtobufMessage(&b, msg, 0)
+	}
+	return b.String()
+}
+
+var protobufTypeKeywords = map[descriptorpb.FieldDescriptorProto_Type]string{
+	descriptorpb.FieldDescriptorProto_TYPE_INT64:  `int64`,
+	descriptorpb.FieldDescriptorProto_TYPE_BOOL:   `bool`,
+	descriptorpb.FieldDescriptorProto_TYPE_DOUBLE: `double`,
+	descriptorpb.FieldDescriptorProto_TYPE_STRING: `string`,
+	descriptorpb.FieldDescriptorProto_TYPE_BYTES:  `bytes`,
+}
+
+func writeProtobufMessage(b *strings.Builder, msg *descriptorpb.DescriptorProto, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(b, "%smessage %s {\n", indent, msg.GetName())
+	for _, nested := range msg.NestedType {
+		writeProtobufMessage(b, nested, depth+1)
+	}
+	for _, f := range msg.Field {
+		var typ string
+		if f.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			// Nested types are always declared in the enclosing message, so the
+			// last component of the qualified name resolves to them.
+			typeName := f.GetTypeName()
+			typ = typeName[strings.LastIndexByte(typeName, '.')+1:]
+		} else {
+			typ = protobufTypeKeywords[f.GetType()]
+		}
+		label := ``
+		if f.GetProto3Optional() {
+			label = `optional `
+		}
+		fmt.Fprintf(b, "%s  %s%s %s = %d;\n", indent, label, typ, f.GetName(), f.GetNumber())
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
@@ -0,0 +1,341 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufToString decodes an encoded message using the given descriptor and
+// returns a deterministic string representation of it.
+func protobufToString(
+	t *testing.T, file *descriptorpb.FileDescriptorProto, encoded []byte,
+) string {
+	fd, err := protodesc.NewFile(file, nil /* resolver */)
+	require.NoError(t, err)
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	require.NoError(t, proto.Unmarshal(encoded, msg))
+	return protobufMessageString(msg)
+}
+
+func protobufMessageString(msg protoreflect.Message) string {
+	var fields []string
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		var s string
+		if fd.Kind() == protoreflect.MessageKind {
+			s = protobufMessageString(v.Message())
+		} else {
+			s = fmt.Sprintf("%v", v.Interface())
+		}
+		fields = append(fields, fmt.Sprintf("%s:%s", fd.Name(), s))
+		return true
+	})
+	sort.Strings(fields)
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+func TestProtobufSchema(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tableDesc, err := parseTableDesc(
+		`CREATE TABLE "foo bar" (a INT PRIMARY KEY, b STRING, c FLOAT, d DECIMAL, e BYTES, f BOOL)`)
+	require.NoError(t, err)
+	row := cdcevent.TestingMakeEventRow(tableDesc, 0, nil, false)
+
+	after, err := tableToProtobufRecord(row, protobufAfterRecordName)
+	require.NoError(t, err)
+	wrapped := newProtobufEnvelope(`foo bar`, false /* bare */, protobufEnvelopeOpts{
+		beforeField: true, updatedField: true,
+	}, nil /* key */, nil /* before */, after)
+	require.Equal(t, `syntax = "proto3";
+
+package cockroachdb.changefeed;
+
+message foo_u0020_bar_envelope {
+  message after_record {
+    optional int64 a = 1;
+    optional string b = 2;
+    optional double c = 3;
+    optional string d = 4;
+    optional bytes e = 5;
+    optional bool f = 6;
+  }
+  message before_record {
+    optional int64 a = 1;
+    optional string b = 2;
+    optional double c = 3;
+    optional string d = 4;
+    optional bytes e = 5;
+    optional bool f = 6;
+  }
+  after_record after = 1;
+  before_record before = 2;
+  optional string updated = 5;
+}
+`, protobufSchemaText(wrapped.file))
+	_, err = protodesc.NewFile(wrapped.file, nil /* resolver */)
+	require.NoError(t, err)
+	requireProtobufSchemaRoundtrips(t, wrapped.file)
+
+	bare := newProtobufEnvelope(`foo bar`, true /* bare */, protobufEnvelopeOpts{
+		mvccTimestampField: true,
+	}, nil /* key */, nil /* before */, after)
+	require.Equal(t, `syntax = "proto3";
+
+package cockroachdb.changefeed;
+
+message foo_u0020_bar {
+  message crdb_metadata {
+    optional string mvcc_timestamp = 2;
+  }
+  optional int64 a = 1;
+  optional string b = 2;
+  optional double c = 3;
+  optional string d = 4;
+  optional bytes e = 5;
+  optional bool f = 6;
+  crdb_metadata __crdb__ = 536870911;
+}
+`, protobufSchemaText(bare.file))
+	_, err = protodesc.NewFile(bare.file, nil /* resolver */)
+	require.NoError(t, err)
+	requireProtobufSchemaRoundtrips(t, bare.file)
+}
+
+// requireProtobufSchemaRoundtrips checks that the schema text generated for a
+// descriptor is parsed back into the same descriptor by the test schema
+// registry, which relies on it to decode messages.
+func requireProtobufSchemaRoundtrips(t *testing.T, file *descriptorpb.FileDescriptorProto) {
+	t.Helper()
+	parsed, err := cdctest.ParseProtobufSchema(protobufSchemaText(file))
+	require.NoError(t, err)
+	parsed.Name = file.Name
+	require.True(t, proto.Equal(file, parsed), "expected\n%v\ngot\n%v", file, parsed)
+}
+
+func TestProtobufEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tableDesc, err := parseTableDesc(
+		`CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c FLOAT, d DECIMAL, e BYTES)`)
+	require.NoError(t, err)
+	rows, err := parseValues(tableDesc, `VALUES (1, 'bar', 1.5, 2.50, 'baz'), (1, NULL, NULL, NULL, NULL)`)
+	require.NoError(t, err)
+	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
+
+	targets := changefeedbase.Targets{}
+	targets.Add(changefeedbase.Target{
+		Type:              jobspb.ChangefeedTargetSpecification_PRIMARY_FAMILY_ONLY,
+		TableID:           tableDesc.GetID(),
+		StatementTimeName: changefeedbase.StatementTimeName(tableDesc.GetName()),
+	})
+
+	tests := []struct {
+		name     string
+		opts     changefeedbase.EncodingOptions
+		insert   string
+		update   string
+		delete   string
+		resolved string
+	}{
+		{
+			name: `wrapped`,
+			opts: changefeedbase.EncodingOptions{Envelope: changefeedbase.OptEnvelopeWrapped},
+			insert: `{a:1}->` +
+				`{after:{a:1,b:bar,c:1.5,d:2.50,e:[98 97 122]}}`,
+			update: `{a:1}->` +
+				`{after:{a:1}}`,
+			delete:   `{a:1}->{}`,
+			resolved: `{resolved:1.0000000002}`,
+		},
+		{
+			name: `wrapped,diff,updated,topic_in_value`,
+			opts: changefeedbase.EncodingOptions{
+				Envelope: changefeedbase.OptEnvelopeWrapped, Diff: true, UpdatedTimestamps: true, TopicInValue: true,
+			},
+			insert: `{a:1}->` +
+				`{after:{a:1,b:bar,c:1.5,d:2.50,e:[98 97 122]},topic:foo,updated:1.0000000002}`,
+			update: `{a:1}->` +
+				`{after:{a:1},before:{a:1,b:bar,c:1.5,d:2.50,e:[98 97 122]},topic:foo,updated:1.0000000002}`,
+			delete: `{a:1}->` +
+				`{before:{a:1},topic:foo,updated:1.0000000002}`,
+			resolved: `{resolved:1.0000000002}`,
+		},
+		{
+			name: `bare,updated,key_in_value`,
+			opts: changefeedbase.EncodingOptions{
+				Envelope: changefeedbase.OptEnvelopeBare, UpdatedTimestamps: true, KeyInValue: true,
+			},
+			insert: `{a:1}->` +
+				`{__crdb__:{key:{a:1},updated:1.0000000002},a:1,b:bar,c:1.5,d:2.50,e:[98 97 122]}`,
+			update: `{a:1}->` +
+				`{__crdb__:{key:{a:1},updated:1.0000000002},a:1}`,
+			delete: `{a:1}->` +
+				`{__crdb__:{key:{a:1},updated:1.0000000002}}`,
+			resolved: `{resolved:1.0000000002}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := cdctest.StartTestSchemaRegistry()
+			defer reg.Close()
+
+			test.opts.Format = changefeedbase.OptFormatProtobuf
+			test.opts.SchemaRegistryURI = reg.URL()
+			require.NoError(t, test.opts.Validate())
+			e, err := getEncoder(test.opts, targets, nil, nil)
+			require.NoError(t, err)
+			pe := e.(*protobufEncoder)
+
+			// decode strips the Confluent header and decodes the message with the
+			// descriptor cached by the encoder for the schema ID in the header.
+			decode := func(encoded []byte, file func(id int32) *descriptorpb.FileDescriptorProto) string {
+				require.Equal(t, changefeedbase.ConfluentAvroWireFormatMagic, encoded[0])
+				id := int32(binary.BigEndian.Uint32(encoded[1:5]))
+				require.Equal(t, byte(0), encoded[5])
+				return protobufToString(t, file(id), encoded[6:])
+			}
+			keyFile := func(id int32) (file *descriptorpb.FileDescriptorProto) {
+				pe.keyCache.Do(func(entry *cache.Entry) {
+					if r := entry.Value.(protobufRegisteredKeySchema); r.registryID == id {
+						file = protobufFileDescriptor(`key`, r.record.descriptor())
+					}
+				})
+				require.NotNil(t, file, "no key schema with id %d", id)
+				return file
+			}
+			valueFile := func(id int32) (file *descriptorpb.FileDescriptorProto) {
+				pe.valueCache.Do(func(entry *cache.Entry) {
+					if r := entry.Value.(protobufRegisteredEnvelopeSchema); r.registryID == id {
+						file = r.envelope.file
+					}
+				})
+				require.NotNil(t, file, "no value schema with id %d", id)
+				return file
+			}
+			encode := func(updated, prev cdcevent.Row) string {
+				evCtx := eventContext{updated: ts, topic: `foo`}
+				k, err := e.EncodeKey(context.Background(), updated)
+				require.NoError(t, err)
+				k = append([]byte(nil), k...)
+				v, err := e.EncodeValue(context.Background(), evCtx, updated, prev)
+				require.NoError(t, err)
+				return decode(k, keyFile) + `->` + decode(v, valueFile)
+			}
+
+			insert := cdcevent.TestingMakeEventRow(tableDesc, 0, rows[0], false)
+			update := cdcevent.TestingMakeEventRow(tableDesc, 0, rows[1], false)
+			deleted := cdcevent.TestingMakeEventRow(tableDesc, 0, rows[1], true)
+			require.Equal(t, test.insert, encode(insert, cdcevent.Row{}))
+			require.Equal(t, test.update, encode(update, insert))
+			require.Equal(t, test.delete, encode(deleted, update))
+
+			resolved, err := e.EncodeResolvedTimestamp(context.Background(), `foo`, ts)
+			require.NoError(t, err)
+			require.Equal(t, test.resolved, decode(resolved, func(int32) *descriptorpb.FileDescriptorProto {
+				return newProtobufResolvedSchema(`foo`)
+			}))
+
+			for _, subject := range reg.Subjects() {
+				require.Equal(t, confluentSchemaTypeProtobuf, reg.SchemaTypeForSubject(subject))
+			}
+		})
+	}
+}
+
+func TestProtobufChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c DECIMAL)`)
+		var ts1 string
+		sqlDB.QueryRow(t,
+			`INSERT INTO foo VALUES (1, 'bar', 1.5), (2, NULL, NULL) RETURNING cluster_logical_timestamp()`,
+		).Scan(&ts1)
+
+		wrapped := feed(t, f, fmt.Sprintf(`CREATE CHANGEFEED FOR foo `+
+			`WITH format=%s, diff, resolved`, changefeedbase.OptFormatProtobuf))
+		defer closeFeed(t, wrapped)
+		assertPayloads(t, wrapped, []string{
+			`foo: {"a":1}->{"after":{"a":1,"b":"bar","c":"1.5"}}`,
+			`foo: {"a":2}->{"after":{"a":2}}`,
+		})
+		resolved, _ := expectResolvedTimestamp(t, wrapped)
+		if ts := parseTimeToHLC(t, ts1); resolved.LessEq(ts) {
+			t.Fatalf(`expected a resolved timestamp greater than %s got %s`, ts, resolved)
+		}
+
+		sqlDB.Exec(t, `UPDATE foo SET b = 'baz' WHERE a = 1`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 2`)
+		assertPayloads(t, wrapped, []string{
+			`foo: {"a":1}->{"after":{"a":1,"b":"baz","c":"1.5"},"before":{"a":1,"b":"bar","c":"1.5"}}`,
+			`foo: {"a":2}->{"before":{"a":2}}`,
+		})
+
+		// Every schema is registered as a PROTOBUF schema whose text is the
+		// generated .proto source.
+		reg := wrapped.(*kafkaFeed).registry
+		assertRegisteredSubjects(t, reg, []string{`foo-key`, `foo-value`})
+		for _, subject := range reg.Subjects() {
+			require.Equal(t, confluentSchemaTypeProtobuf, reg.SchemaTypeForSubject(subject))
+		}
+		require.Equal(t, `syntax = "proto3";
+
+package cockroachdb.changefeed;
+
+message foo_key {
+  optional int64 a = 1;
+}
+`, reg.SchemaForSubject(`foo-key`))
+
+		bare := feed(t, f, fmt.Sprintf(`CREATE CHANGEFEED FOR foo `+
+			`WITH format=%s, envelope=bare, diff, key_in_value, topic_in_value`,
+			changefeedbase.OptFormatProtobuf))
+		defer closeFeed(t, bare)
+		assertPayloads(t, bare, []string{
+			`foo: {"a":1}->{"__crdb__":{"key":{"a":1},"topic":"foo"},"a":1,"b":"baz","c":"1.5"}`,
+		})
+
+		// The bare envelope never includes the previous row, even with diff.
+		sqlDB.Exec(t, `UPDATE foo SET c = NULL WHERE a = 1`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, bare, []string{
+			`foo: {"a":1}->{"__crdb__":{"key":{"a":1},"topic":"foo"},"a":1,"b":"baz"}`,
+			`foo: {"a":1}->{"__crdb__":{"key":{"a":1},"topic":"foo"}}`,
+		})
+	}
+
+	cdcTest(t, testFn, feedTestForceSink("kafka"))
+}
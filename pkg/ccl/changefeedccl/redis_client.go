@@ -0,0 +1,215 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// redisConn is a minimal client for the Redis serialization protocol (RESP2),
+// implementing only what the Redis sink needs: pipelining commands and reading
+// their replies in order.
+//
+//	https://redis.io/docs/reference/protocol-spec/
+//
+// It is not concurrency-safe.
+type redisConn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	w       *bufio.Writer
+	timeout time.Duration
+}
+
+// redisDialConfig configures how redisConn connects to and authenticates with
+// the server.
+type redisDialConfig struct {
+	tls      *tls.Config
+	user     string
+	password string
+	db       int
+	timeout  time.Duration
+}
+
+// redisError is an error reply from the server. Unlike I/O errors, it leaves
+// the connection usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// dialRedis connects to the Redis server at addr, authenticates and selects
+// the configured database.
+func dialRedis(ctx context.Context, addr string, cfg redisDialConfig) (*redisConn, error) {
+	dialer := net.Dialer{Timeout: cfg.timeout}
+	conn, err := dialer.DialContext(ctx, `tcp`, addr)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.tls != nil {
+		tlsCfg := cfg.tls.Clone()
+		if tlsCfg.ServerName == `` {
+			tlsCfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		conn = tls.Client(conn, tlsCfg)
+	}
+	c := &redisConn{
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+		w:       bufio.NewWriter(conn),
+		timeout: cfg.timeout,
+	}
+
+	if cfg.password != `` {
+		args := []string{`AUTH`, cfg.password}
+		if cfg.user != `` {
+			args = []string{`AUTH`, cfg.user, cfg.password}
+		}
+		if _, err := c.do(args...); err != nil {
+			_ = c.close()
+			return nil, errors.Wrap(err, `authenticating`)
+		}
+	}
+	if cfg.db != 0 {
+		if _, err := c.do(`SELECT`, strconv.Itoa(cfg.db)); err != nil {
+			_ = c.close()
+			return nil, errors.Wrapf(err, `selecting database %d`, cfg.db)
+		}
+	}
+	return c, nil
+}
+
+// do sends a single command and returns its reply.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	cmd := make([][]byte, len(args))
+	for i := range args {
+		cmd[i] = []byte(args[i])
+	}
+	c.writeCommand(cmd...)
+	if err := c.flush(); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// writeCommand buffers a command, encoded as an array of bulk strings.
+func (c *redisConn) writeCommand(args ...[]byte) {
+	var scratch [20]byte
+	_ = c.w.WriteByte('*')
+	_, _ = c.w.Write(strconv.AppendInt(scratch[:0], int64(len(args)), 10))
+	_, _ = c.w.WriteString("\r\n")
+	for _, arg := range args {
+		_ = c.w.WriteByte('$')
+		_, _ = c.w.Write(strconv.AppendInt(scratch[:0], int64(len(arg)), 10))
+		_, _ = c.w.WriteString("\r\n")
+		_, _ = c.w.Write(arg)
+		_, _ = c.w.WriteString("\r\n")
+	}
+}
+
+// flush writes the buffered commands to the connection.
+func (c *redisConn) flush() error {
+	if err := c.conn.SetWriteDeadline(timeutil.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// readReply reads the reply to the oldest command whose reply hasn't been read
+// yet. Error replies are returned as a redisError; any other error means that
+// the connection is no longer usable.
+func (c *redisConn) readReply() (interface{}, error) {
+	if err := c.conn.SetReadDeadline(timeutil.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	return c.readValue()
+}
+
+func (c *redisConn) readValue() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New(`malformed Redis reply: empty line`)
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return nil, errors.Newf(`malformed Redis bulk string length %q`, line[1:])
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return nil, errors.Newf(`malformed Redis array length %q`, line[1:])
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			// An error nested in an array doesn't fail the whole reply.
+			v, err := c.readValue()
+			var redisErr redisError
+			if errors.As(err, &redisErr) {
+				v, err = redisErr, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	default:
+		return nil, errors.Newf(`malformed Redis reply %q`, line)
+	}
+}
+
+// readLine reads a line terminated by CRLF, without the terminator.
+func (c *redisConn) readLine() ([]byte, error) {
+	line, err := c.r.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, errors.New(`malformed Redis reply: line too long`)
+		}
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.Newf(`malformed Redis reply %q`, line)
+	}
+	return line[:len(line)-2], nil
+}
+
+func (c *redisConn) close() error {
+	return c.conn.Close()
+}
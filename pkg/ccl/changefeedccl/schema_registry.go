@@ -16,6 +16,7 @@ import (
 	"io"
 	"net/url"
 	"path"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
 )
 
 const confluentSchemaContentType = `application/vnd.schemaregistry.v1+json`
@@ -38,10 +40,22 @@ type schemaRegistry interface {
 	// be used in Avro wire messages or in other calls to the
 	// schema registry.
 	RegisterSchemaForSubject(ctx context.Context, subject string, schema string) (int32, error)
+
+	// RegisterProtobufSchemaForSubject is like RegisterSchemaForSubject,
+	// but the schema is protobuf source rather than an Avro schema.
+	RegisterProtobufSchemaForSubject(ctx context.Context, subject string, schema string) (int32, error)
 }
 
+// Schema types understood by the Confluent schema registry. An empty schema
+// type means AVRO.
+const (
+	confluentSchemaTypeAvro     = ``
+	confluentSchemaTypeProtobuf = `PROTOBUF`
+)
+
 type confluentSchemaVersionRequest struct {
-	Schema string `json:"schema"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
 }
 
 type confluentSchemaVersionResponse struct {
@@ -184,13 +198,27 @@ func (r *confluentSchemaRegistry) Ping(ctx context.Context) error {
 //	https://docs.confluent.io/platform/current/schema-registry/develop/api.html#post--subjects-(string-%20subject)-versions
 func (r *confluentSchemaRegistry) RegisterSchemaForSubject(
 	ctx context.Context, subject string, schema string,
+) (int32, error) {
+	return r.registerSchemaForSubject(ctx, subject, confluentSchemaTypeAvro, schema)
+}
+
+// RegisterProtobufSchemaForSubject registers the given protobuf schema for
+// the given subject.
+func (r *confluentSchemaRegistry) RegisterProtobufSchemaForSubject(
+	ctx context.Context, subject string, schema string,
+) (int32, error) {
+	return r.registerSchemaForSubject(ctx, subject, confluentSchemaTypeProtobuf, schema)
+}
+
+func (r *confluentSchemaRegistry) registerSchemaForSubject(
+	ctx context.Context, subject string, schemaType string, schema string,
 ) (int32, error) {
 	u := r.urlForPath(fmt.Sprintf("subjects/%s/versions", subject))
 	if log.V(1) {
-		log.Infof(ctx, "registering avro schema %s %s", u, schema)
+		log.Infof(ctx, "registering %s schema %s %s", redact.SafeString(schemaTypeName(schemaType)), u, schema)
 	}
 
-	req := confluentSchemaVersionRequest{Schema: schema}
+	req := confluentSchemaVersionRequest{Schema: schema, SchemaType: schemaType}
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(req); err != nil {
 		return 0, err
@@ -220,6 +248,13 @@ func (r *confluentSchemaRegistry) RegisterSchemaForSubject(
 	return id, nil
 }
 
+func schemaTypeName(schemaType string) string {
+	if schemaType == confluentSchemaTypeAvro {
+		return "avro"
+	}
+	return strings.ToLower(schemaType)
+}
+
 func (r *confluentSchemaRegistry) doWithRetry(ctx context.Context, fn func() error) error {
 	// Since network services are often a source of flakes, add a few retries here
 	// before we give up and return an error that will bubble up and tear down the
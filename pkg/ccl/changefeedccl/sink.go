@@ -10,6 +10,8 @@ package changefeedccl
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/url"
 	"strings"
 	"time"
@@ -45,6 +47,8 @@ const (
 	sinkTypeSinklessBuffer sinkType = iota
 	sinkTypeNull
 	sinkTypeKafka
+	sinkTypeNATS
+	sinkTypeRedis
 	sinkTypeWebhook
 	sinkTypePubsub
 	sinkTypeCloudstorage
@@ -202,6 +206,14 @@ func getSink(
 			return validateOptionsAndMakeSink(changefeedbase.KafkaValidOptions, func() (Sink, error) {
 				return makeKafkaSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), opts.GetKafkaConfigJSON(), serverCfg.Settings, metricsBuilder)
 			})
+		case isNATSSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.NATSValidOptions, func() (Sink, error) {
+				return makeNATSSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), opts.GetNATSConfigJSON(), metricsBuilder)
+			})
+		case isRedisSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.RedisValidOptions, func() (Sink, error) {
+				return makeRedisSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), opts.GetRedisConfigJSON(), metricsBuilder)
+			})
 		case isWebhookSink(u):
 			webhookOpts, err := opts.GetWebhookSinkOptions()
 			if err != nil {
@@ -322,6 +334,58 @@ func (u *sinkURL) decodeBase64(param string, dest *[]byte) error {
 	return nil
 }
 
+// consumeTLSConfig consumes the TLS parameters shared by the sinks that dial
+// their own connections. It returns a nil config unless tls_enabled is set.
+func (u *sinkURL) consumeTLSConfig() (*tls.Config, error) {
+	var tlsEnabled, tlsSkipVerify bool
+	var caCert, clientCert, clientKey []byte
+	if _, err := u.consumeBool(changefeedbase.SinkParamTLSEnabled, &tlsEnabled); err != nil {
+		return nil, err
+	}
+	if _, err := u.consumeBool(changefeedbase.SinkParamSkipTLSVerify, &tlsSkipVerify); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamCACert, &caCert); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamClientCert, &clientCert); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamClientKey, &clientKey); err != nil {
+		return nil, err
+	}
+
+	if !tlsEnabled {
+		if caCert != nil {
+			return nil, errors.Errorf(`%s requires %s=true`, changefeedbase.SinkParamCACert, changefeedbase.SinkParamTLSEnabled)
+		}
+		if clientCert != nil {
+			return nil, errors.Errorf(`%s requires %s=true`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamTLSEnabled)
+		}
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: tlsSkipVerify}
+	if caCert != nil {
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		cfg.RootCAs = caCertPool
+	}
+	if clientCert != nil && clientKey == nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+	} else if clientKey != nil && clientCert == nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+	if clientCert != nil {
+		cert, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, `invalid client certificate data provided`)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
 func (u *sinkURL) remainingQueryParams() (res []string) {
 	for p := range u.q {
 		res = append(res, p)
@@ -95,6 +95,8 @@ var supportedExternalConnectionTypes = map[string]connectionpb.ConnectionProvide
 	changefeedbase.SinkSchemeCloudStorageNodelocal: connectionpb.ConnectionProvider_nodelocal,
 	changefeedbase.SinkSchemeCloudStorageS3:        connectionpb.ConnectionProvider_s3,
 	changefeedbase.SinkSchemeKafka:                 connectionpb.ConnectionProvider_kafka,
+	changefeedbase.SinkSchemeNATS:                  connectionpb.ConnectionProvider_nats,
+	changefeedbase.SinkSchemeRedis:                 connectionpb.ConnectionProvider_redis,
 	changefeedbase.SinkSchemeWebhookHTTP:           connectionpb.ConnectionProvider_webhookhttp,
 	changefeedbase.SinkSchemeWebhookHTTPS:          connectionpb.ConnectionProvider_webhookhttps,
 	// TODO (zinger): Not including SinkSchemeExperimentalSQL for now because A: it's undocumented
@@ -0,0 +1,590 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// natsDefaultPort is the port used if the sink URI doesn't specify one.
+	natsDefaultPort = `4222`
+	// natsKeyHeader is the header containing the base64 encoded key of a row.
+	// Resolved timestamp messages do not have it.
+	natsKeyHeader = `Crdb-Key`
+	// natsDefaultAckTimeout is how long to wait for JetStream to acknowledge a
+	// message before attempting to publish it again.
+	natsDefaultAckTimeout = 30 * time.Second
+)
+
+func isNATSSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeNATS
+}
+
+// natsSink publishes to NATS JetStream asynchronously. Every topic is a NATS
+// subject, which must be bound to a JetStream stream. A message is only
+// considered emitted once JetStream acknowledges that it has persisted it, so
+// Flush waits for the acknowledgements of all messages emitted so far. Messages
+// for which JetStream replies with an error or which aren't acknowledged in
+// time are published again according to the retry configuration.
+//
+// Like for kafkaSink, all calls to Emit and Flush should be from the same
+// goroutine. The sink itself is concurrent though: a worker goroutine publishes
+// the retried messages and flushes the connection periodically, and the
+// connection's reader goroutine handles the acknowledgements. The state they
+// share is protected by mu.
+type natsSink struct {
+	ctx       context.Context
+	addr      string
+	dialCfg   natsDialConfig
+	topics    *TopicNamer
+	batchCfg  batchConfig
+	retryOpts retry.Options
+	// ackTimeout is natsDefaultAckTimeout outside of tests.
+	ackTimeout time.Duration
+	metrics    metricsRecorder
+
+	conn *natsConn
+
+	scratch bufalloc.ByteAllocator
+
+	// retryCh receives the messages whose retry backoff elapsed.
+	retryCh      chan *natsMessage
+	stopWorkerCh chan struct{}
+	worker       sync.WaitGroup
+
+	// Only synchronized between the client goroutine, the worker goroutine and
+	// the connection's reader goroutine.
+	mu struct {
+		syncutil.Mutex
+		nextID   uint64
+		inflight map[uint64]*natsMessage
+		// retrying counts the messages waiting to be published again.
+		retrying int
+		// buffered counts the messages and bytes published since the connection
+		// was last flushed.
+		bufferedMessages int
+		bufferedBytes    int
+		flushErr         error
+		flushCh          chan struct{}
+	}
+}
+
+// natsMessage is a message published to NATS that has yet to be acknowledged.
+type natsMessage struct {
+	subject string
+	header  natsHeader
+	data    []byte
+
+	alloc         kvevent.Alloc
+	updateMetrics recordOneMessageCallback
+	mvcc          hlc.Timestamp
+
+	sentAt time.Time
+	// retry is initialized on the first failure to publish the message.
+	retry *retry.Retry
+}
+
+var _ Sink = (*natsSink)(nil)
+
+func (s *natsSink) getConcreteType() sinkType {
+	return sinkTypeNATS
+}
+
+// proper JSON schema for NATS sink config, matching the Flush and Retry
+// settings of the Kafka and webhook sinks:
+//
+//	{
+//	  "Flush": {
+//		   "Messages":  ...,
+//		   "Bytes":     ...,
+//		   "Frequency": ...,
+//	  },
+//		 "Retry": {
+//		   "Max":     ...,
+//		   "Backoff": ...,
+//	  }
+//	}
+//
+// Messages are written to the connection once Flush.Messages or Flush.Bytes is
+// reached, every Flush.Frequency, and whenever the changefeed flushes the sink.
+type natsSinkConfig struct {
+	Flush batchConfig `json:",omitempty"`
+	Retry retryConfig `json:",omitempty"`
+}
+
+func getNATSSinkConfig(
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+) (batchCfg batchConfig, retryOpts retry.Options, err error) {
+	retryOpts = defaultRetryConfig()
+
+	var cfg natsSinkConfig
+	cfg.Retry.Max = jsonMaxRetries(retryOpts.MaxRetries)
+	cfg.Retry.Backoff = jsonDuration(retryOpts.InitialBackoff)
+	if jsonStr != `` {
+		if err = json.Unmarshal([]byte(jsonStr), &cfg); err != nil {
+			return batchCfg, retryOpts, errors.Wrapf(err,
+				"failed to parse NATS sink config; check %s option", changefeedbase.OptNATSSinkConfig)
+		}
+	}
+
+	if cfg.Flush.Messages < 0 || cfg.Flush.Bytes < 0 || cfg.Flush.Frequency < 0 ||
+		cfg.Retry.Max < 0 || cfg.Retry.Backoff < 0 {
+		return batchCfg, retryOpts, errors.Errorf(
+			"invalid option value %s, all config values must be non-negative", changefeedbase.OptNATSSinkConfig)
+	}
+	if (cfg.Flush.Messages > 0 || cfg.Flush.Bytes > 0) && cfg.Flush.Frequency == 0 {
+		return batchCfg, retryOpts, errors.Errorf(
+			"invalid option value %s, flush frequency is not set, messages may never be sent", changefeedbase.OptNATSSinkConfig)
+	}
+
+	retryOpts.MaxRetries = int(cfg.Retry.Max)
+	retryOpts.InitialBackoff = time.Duration(cfg.Retry.Backoff)
+	return cfg.Flush, retryOpts, nil
+}
+
+func buildNATSDialConfig(u sinkURL) (natsDialConfig, error) {
+	cfg := natsDialConfig{timeout: 10 * time.Second}
+
+	// As with NATS clients, the credentials are taken from the user info of the
+	// URI. A user without a password is an authentication token.
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			cfg.user, cfg.password = u.User.Username(), password
+		} else {
+			cfg.token = u.User.Username()
+		}
+	}
+
+	var err error
+	cfg.tls, err = u.consumeTLSConfig()
+	return cfg, err
+}
+
+func makeNATSSink(
+	ctx context.Context,
+	u sinkURL,
+	targets changefeedbase.Targets,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if u.Host == `` {
+		return nil, errors.Errorf(`NATS sink URI must specify a host`)
+	}
+	addr := u.Host
+	if u.Port() == `` {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+
+	subjectPrefix := u.consumeParam(changefeedbase.SinkParamTopicPrefix)
+	subjectName := u.consumeParam(changefeedbase.SinkParamTopicName)
+	if schemaTopic := u.consumeParam(changefeedbase.SinkParamSchemaTopic); schemaTopic != `` {
+		return nil, errors.Errorf(`%s is not yet supported`, changefeedbase.SinkParamSchemaTopic)
+	}
+
+	dialCfg, err := buildNATSDialConfig(u)
+	if err != nil {
+		return nil, err
+	}
+	batchCfg, retryOpts, err := getNATSSinkConfig(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The prefix is not escaped so that it can contain a hierarchy of tokens
+	// under which the table subjects are nested (e.g. `topic_prefix=cdc.`).
+	if subjectPrefix != `` && !isValidNATSSubjectPrefix(subjectPrefix) {
+		return nil, errors.Errorf(`invalid NATS subject prefix %q`, subjectPrefix)
+	}
+	topics, err := MakeTopicNamer(targets, WithSingleName(subjectName),
+		WithSanitizeFn(func(s string) string { return subjectPrefix + SQLNameToNATSSubject(s) }))
+	if err != nil {
+		return nil, err
+	}
+
+	if unknownParams := u.remainingQueryParams(); len(unknownParams) > 0 {
+		return nil, errors.Errorf(
+			`unknown NATS sink query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return &natsSink{
+		ctx:        ctx,
+		addr:       addr,
+		dialCfg:    dialCfg,
+		topics:     topics,
+		batchCfg:   batchCfg,
+		retryOpts:  retryOpts,
+		ackTimeout: natsDefaultAckTimeout,
+		metrics:    mb(requiresResourceAccounting),
+	}, nil
+}
+
+// Dial implements the Sink interface.
+func (s *natsSink) Dial() error {
+	s.mu.inflight = make(map[uint64]*natsMessage)
+	s.retryCh = make(chan *natsMessage)
+	s.stopWorkerCh = make(chan struct{})
+
+	conn, err := dialNATS(s.ctx, s.addr, s.dialCfg, s.handleReply, s.handleConnErr)
+	if err != nil {
+		return pgerror.Wrapf(err, pgcode.CannotConnectNow, `connecting to NATS: %s`, s.addr)
+	}
+	s.conn = conn
+
+	s.worker.Add(1)
+	go s.workerLoop()
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *natsSink) Close() error {
+	if s.stopWorkerCh != nil {
+		close(s.stopWorkerCh)
+		s.worker.Wait()
+	}
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.close()
+
+	// Release the memory of messages that will never be acknowledged.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, m := range s.mu.inflight {
+		m.alloc.Release(s.ctx)
+		delete(s.mu.inflight, id)
+	}
+	return err
+}
+
+// Topics gives the names of all subjects that have been initialized
+// and will receive resolved timestamps.
+func (s *natsSink) Topics() []string {
+	return s.topics.DisplayNamesSlice()
+}
+
+// EmitRow implements the Sink interface.
+func (s *natsSink) EmitRow(
+	ctx context.Context,
+	topicDescr TopicDescriptor,
+	key, value []byte,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	subject, err := s.topics.Name(topicDescr)
+	if err != nil {
+		return err
+	}
+	// The value is copied because the encoder reuses its buffers, and we may
+	// need to publish it again.
+	var data []byte
+	s.scratch, data = s.scratch.Copy(value, 0 /* extraCap */)
+	return s.publish(ctx, &natsMessage{
+		subject:       subject,
+		header:        natsHeader{natsKeyHeader: base64.StdEncoding.EncodeToString(key)},
+		data:          data,
+		alloc:         alloc,
+		updateMetrics: s.metrics.recordOneMessage(),
+		mvcc:          mvcc,
+	}, false /* retried */)
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *natsSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	defer s.metrics.recordResolvedCallback()()
+
+	return s.topics.Each(func(subject string) error {
+		payload, err := encoder.EncodeResolvedTimestamp(ctx, subject, resolved)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		s.scratch, data = s.scratch.Copy(payload, 0 /* extraCap */)
+		return s.publish(ctx, &natsMessage{subject: subject, data: data}, false /* retried */)
+	})
+}
+
+// Flush implements the Sink interface.
+func (s *natsSink) Flush(ctx context.Context) error {
+	defer s.metrics.recordFlushRequestCallback()()
+
+	flushCh := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	inflight := len(s.mu.inflight) + s.mu.retrying
+	flushErr := s.mu.flushErr
+	s.mu.flushErr = nil
+	immediateFlush := inflight == 0 || flushErr != nil
+	if !immediateFlush {
+		s.mu.flushCh = flushCh
+	}
+	s.mu.Unlock()
+
+	if immediateFlush {
+		return flushErr
+	}
+
+	if err := s.flushConn(); err != nil {
+		return err
+	}
+
+	if log.V(1) {
+		log.Infof(ctx, "flush waiting for %d inflight messages", inflight)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-flushCh:
+		s.mu.Lock()
+		flushErr := s.mu.flushErr
+		s.mu.flushErr = nil
+		s.mu.Unlock()
+		return flushErr
+	}
+}
+
+// publish assigns the message an id, registers it as inflight and publishes
+// it, with the reply sent to the inbox subject for the id. retried is set if
+// the message is published again by maybeRetry, in which case it stops being
+// counted as retrying in the same critical section in which it becomes
+// inflight, so that Flush can't miss it.
+//
+// If publish fails, the message is finished with the error, unless it was
+// already finished by handleConnErr; callers must not finish it again.
+func (s *natsSink) publish(ctx context.Context, m *natsMessage, retried bool) error {
+	s.mu.Lock()
+	if retried {
+		s.mu.retrying--
+	}
+	if err := s.mu.flushErr; err != nil {
+		s.mu.Unlock()
+		s.finishMessage(m, err)
+		return err
+	}
+	s.mu.nextID++
+	id := s.mu.nextID
+	s.mu.inflight[id] = m
+	m.sentAt = timeutil.Now()
+	s.mu.bufferedMessages++
+	s.mu.bufferedBytes += len(m.data)
+	flush := s.batchCfg.Frequency == 0 ||
+		(s.batchCfg.Messages > 0 && s.mu.bufferedMessages >= s.batchCfg.Messages) ||
+		(s.batchCfg.Bytes > 0 && s.mu.bufferedBytes >= s.batchCfg.Bytes)
+	if log.V(2) {
+		log.Infof(ctx, "emitting %d inflight records to NATS", len(s.mu.inflight))
+	}
+	s.mu.Unlock()
+
+	if err := s.conn.publish(m.subject, s.conn.replySubject(id), m.header, m.data); err != nil {
+		// The message won't be acknowledged so it is no longer inflight. If
+		// the connection failed, handleConnErr may have removed and finished
+		// it already.
+		s.mu.Lock()
+		_, ok := s.mu.inflight[id]
+		delete(s.mu.inflight, id)
+		s.mu.Unlock()
+		if ok {
+			s.finishMessage(m, err)
+		}
+		return err
+	}
+	if flush {
+		return s.flushConn()
+	}
+	return nil
+}
+
+// flushConn writes all buffered messages to the connection.
+func (s *natsSink) flushConn() error {
+	s.mu.Lock()
+	s.mu.bufferedMessages, s.mu.bufferedBytes = 0, 0
+	s.mu.Unlock()
+	return s.conn.flush()
+}
+
+// handleReply is called by the connection's reader goroutine for every message
+// received on the inbox, which are the replies to published messages. The
+// message is retried if JetStream failed to persist it.
+func (s *natsSink) handleReply(msg natsMsg) {
+	id, ok := s.conn.replyID(msg.subject)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	m, ok := s.mu.inflight[id]
+	if ok {
+		delete(s.mu.inflight, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		// The message has already been retried after its ack timed out.
+		return
+	}
+	if _, err := decodeJetStreamPubAck(msg); err != nil {
+		s.maybeRetry(m, err)
+		return
+	}
+	s.finishMessage(m, nil)
+}
+
+// handleConnErr is called by the connection's reader goroutine if the
+// connection fails. Messages are not retried since no acks can be received
+// anymore; the error is instead surfaced to the changefeed, which restarts
+// the sink.
+func (s *natsSink) handleConnErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.flushErr == nil {
+		s.mu.flushErr = err
+	}
+	for id, m := range s.mu.inflight {
+		m.alloc.Release(s.ctx)
+		delete(s.mu.inflight, id)
+	}
+	s.maybeNotifyFlushLocked()
+}
+
+func (s *natsSink) workerLoop() {
+	defer s.worker.Done()
+
+	var flushTicker *time.Ticker
+	var flushTickerCh <-chan time.Time
+	if s.batchCfg.Frequency > 0 {
+		flushTicker = time.NewTicker(time.Duration(s.batchCfg.Frequency))
+		defer flushTicker.Stop()
+		flushTickerCh = flushTicker.C
+	}
+	ackTimeoutTicker := time.NewTicker(s.ackTimeout / 2)
+	defer ackTimeoutTicker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.stopWorkerCh:
+			return
+		case m := <-s.retryCh:
+			// If publish fails, it finishes the message itself.
+			if err := s.publish(s.ctx, m, true /* retried */); err != nil {
+				continue
+			}
+			if err := s.flushConn(); err != nil {
+				log.Warningf(s.ctx, "error flushing NATS connection: %v", err)
+			}
+		case <-flushTickerCh:
+			if err := s.flushConn(); err != nil {
+				log.Warningf(s.ctx, "error flushing NATS connection: %v", err)
+			}
+		case <-ackTimeoutTicker.C:
+			s.expireAcks()
+		}
+	}
+}
+
+// expireAcks retries the messages that haven't been acknowledged within the
+// ack timeout.
+func (s *natsSink) expireAcks() {
+	var expired []*natsMessage
+	s.mu.Lock()
+	for id, m := range s.mu.inflight {
+		if timeutil.Since(m.sentAt) > s.ackTimeout {
+			expired = append(expired, m)
+			delete(s.mu.inflight, id)
+		}
+	}
+	s.mu.Unlock()
+	for _, m := range expired {
+		s.maybeRetry(m, errors.Newf(`timed out after %s waiting for JetStream to acknowledge message`, s.ackTimeout))
+	}
+}
+
+// maybeRetry schedules the message to be published again after backing off,
+// or fails it if it has been retried too many times.
+func (s *natsSink) maybeRetry(m *natsMessage, err error) {
+	if m.retry == nil {
+		r := retry.StartWithCtx(s.ctx, s.retryOpts)
+		m.retry = &r
+		m.retry.Next()
+	}
+	nextCh := m.retry.NextCh()
+	if nextCh == nil {
+		s.finishMessage(m, errors.Wrapf(err, `publishing to NATS subject %s`, m.subject))
+		return
+	}
+	log.VInfof(s.ctx, 1, "retrying message to NATS subject %s: %v", m.subject, err)
+	s.metrics.recordInternalRetry(1, false /* reducedBatchSize */)
+
+	// Flush keeps waiting for the message while it is waiting to be retried.
+	// Once the message is handed to the worker, publish stops counting it as
+	// retrying when it is inflight again.
+	s.mu.Lock()
+	s.mu.retrying++
+	s.mu.Unlock()
+
+	go func() {
+		select {
+		case <-nextCh:
+			select {
+			case s.retryCh <- m:
+				return
+			case <-s.stopWorkerCh:
+			}
+		case <-s.stopWorkerCh:
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.mu.retrying--
+		m.alloc.Release(s.ctx)
+		s.maybeNotifyFlushLocked()
+	}()
+}
+
+// finishMessage records the outcome of a message that won't be published
+// again.
+func (s *natsSink) finishMessage(m *natsMessage, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil && m.updateMetrics != nil {
+		m.updateMetrics(m.mvcc, len(m.data), sinkDoesNotCompress)
+	}
+	m.alloc.Release(s.ctx)
+	if s.mu.flushErr == nil && err != nil {
+		s.mu.flushErr = err
+	}
+	s.maybeNotifyFlushLocked()
+}
+
+func (s *natsSink) maybeNotifyFlushLocked() {
+	s.mu.AssertHeld()
+	if s.mu.flushCh != nil && (len(s.mu.inflight)+s.mu.retrying == 0 || s.mu.flushErr != nil) {
+		s.mu.flushCh <- struct{}{}
+		s.mu.flushCh = nil
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSSinkChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `CREATE TABLE "☃" (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a'), (2, 'b')`)
+		sqlDB.Exec(t, `INSERT INTO "☃" VALUES (1)`)
+
+		// The host of the URI is replaced with the address of the mock server.
+		cf := feed(t, f, `CREATE CHANGEFEED FOR foo, "☃" `+
+			`INTO 'nats://nats?topic_prefix=cdc.' WITH resolved`)
+		defer closeFeed(t, cf)
+		assertPayloads(t, cf, []string{
+			`cdc.foo: [1]->{"after": {"a": 1, "b": "a"}}`,
+			`cdc.foo: [2]->{"after": {"a": 2, "b": "b"}}`,
+			`cdc._u2603_: [1]->{"after": {"a": 1}}`,
+		})
+		// The rows are flushed before the resolved timestamp is published.
+		expectResolvedTimestamp(t, cf)
+
+		// Every message was acknowledged by the stream bound to its subject.
+		for _, msg := range cf.(*natsFeed).srv.Messages() {
+			require.Equal(t, `CDC`, msg.Stream)
+		}
+
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, cf, []string{
+			`cdc.foo: [1]->{"after": null}`,
+		})
+	}
+
+	cdcTest(t, testFn, feedTestForceSink("nats"), feedTestNoExternalConnection)
+}
+
+func TestNATSSinkErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, err := cdctest.StartMockNATSServer(map[string]string{`t`: `T`})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	t.Run(`retries rejected messages`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(),
+			`{"Retry": {"Max": 3, "Backoff": "5ms"}}`, nil /* configure */, `t`)
+		defer cleanup()
+		before := len(srv.Messages())
+		srv.FailNextPublishes(2)
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.NoError(t, sink.Flush(ctx))
+		require.Len(t, srv.Messages(), before+1)
+	})
+
+	t.Run(`retries exhausted`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(),
+			`{"Retry": {"Max": 1, "Backoff": "5ms"}}`, nil /* configure */, `t`)
+		defer cleanup()
+		srv.FailNextPublishes(2)
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.Regexp(t, `insufficient resources`, sink.Flush(ctx))
+		// The error is only returned once.
+		require.NoError(t, sink.Flush(ctx))
+	})
+
+	t.Run(`retries unacknowledged messages`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(),
+			`{"Retry": {"Backoff": "5ms"}}`, func(s Sink) {
+				s.(*natsSink).ackTimeout = 10 * time.Millisecond
+			}, `t`)
+		defer cleanup()
+		before := len(srv.Messages())
+		srv.DropNextPublishes(1)
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.NoError(t, sink.Flush(ctx))
+		require.Len(t, srv.Messages(), before+1)
+	})
+
+	t.Run(`no stream`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(), ``, nil /* configure */, `u`)
+		defer cleanup()
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`u`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.Regexp(t, `no JetStream stream is bound to subject`, sink.Flush(ctx))
+	})
+
+	t.Run(`connection failure`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(), ``, nil /* configure */, `t`)
+		defer cleanup()
+		srv.DropNextPublishes(1)
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		srv.CloseConnections()
+		require.Regexp(t, `NATS connection failed`, sink.Flush(ctx))
+	})
+
+	t.Run(`connection failure releases messages once`, func(t *testing.T) {
+		sink, _ := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(), ``, nil /* configure */, `t`)
+		var pool testAllocPool
+		srv.DropNextPublishes(100)
+		for i := 0; i < 200; i++ {
+			if i == 100 {
+				srv.CloseConnections()
+			}
+			// Messages that fail to be published race with the connection
+			// failure releasing the inflight messages.
+			_ = sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, pool.alloc())
+		}
+		// Closing the sink releases the messages that are still inflight.
+		_ = sink.Close()
+		require.Zero(t, pool.used())
+	})
+}
+
+func TestNATSSinkBatching(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, err := cdctest.StartMockNATSServer(map[string]string{`t`: `T`})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sink, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, srv.URL(),
+		`{"Flush": {"Messages": 2, "Frequency": "1h"}}`, nil /* configure */, `t`)
+	defer cleanup()
+
+	// The first message is buffered until the batch is full...
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k1`), []byte(`v1`), zeroTS, zeroTS, zeroAlloc))
+	require.Empty(t, srv.Messages())
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k2`), []byte(`v2`), zeroTS, zeroTS, zeroAlloc))
+	require.Eventually(t, func() bool {
+		return len(srv.Messages()) == 2
+	}, 10*time.Second, time.Millisecond)
+
+	// ... or the sink is flushed.
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k3`), []byte(`v3`), zeroTS, zeroTS, zeroAlloc))
+	require.NoError(t, sink.Flush(ctx))
+	require.Len(t, srv.Messages(), 3)
+}
+
+func TestNATSSinkAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	srv, err := cdctest.StartMockNATSServerWithAuth(map[string]string{`t`: `T`}, `user`, `pass`)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL())
+	require.NoError(t, err)
+
+	u.User = url.UserPassword(`user`, `pass`)
+	_, cleanup := makeTestMessageBrokerSink(t, makeNATSSink, u.String(), ``, nil /* configure */, `t`)
+	cleanup()
+
+	u.User = url.UserPassword(`user`, `wrong`)
+	sink, err := makeNATSSink(context.Background(), sinkURL{URL: u},
+		makeChangefeedTargets(`t`), ``, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	require.Regexp(t, `Authorization Violation`, sink.Dial())
+	require.NoError(t, sink.Close())
+}
+
+func TestNATSSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		config changefeedbase.SinkSpecificJSONConfig
+		err    string
+	}{
+		{config: ``},
+		{config: `{"Flush": {"Messages": 100, "Bytes": 1000, "Frequency": "1s"}, "Retry": {"Max": "inf", "Backoff": "1s"}}`},
+		{config: `{"Flush": {"Frequency": "1s"}}`},
+		{config: `{"Flush": {"Messages": 100}}`, err: `flush frequency is not set`},
+		{config: `{"Flush": {"Bytes": -1, "Frequency": "1s"}}`, err: `must be non-negative`},
+		{config: `{"Retry": {"Max": 0}}`, err: `max retry count must be a positive integer`},
+		{config: `{"Flush": {"Frequency": "forever"}}`, err: `check nats_sink_config option`},
+	} {
+		t.Run(string(tc.config), func(t *testing.T) {
+			_, _, err := getNATSSinkConfig(tc.config)
+			if tc.err == `` {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{uri: `nats://localhost:4222?topic_prefix=cdc.`},
+		{uri: `nats://localhost?topic_name=all`},
+		{uri: `nats://`, err: `must specify a host`},
+		{uri: `nats://localhost?topic_prefix=a..b`, err: `invalid NATS subject prefix`},
+		{uri: `nats://localhost?topic_prefix=a*`, err: `invalid NATS subject prefix`},
+		{uri: `nats://localhost?foo=bar`, err: `unknown NATS sink query parameters: foo`},
+		{uri: `nats://localhost?ca_cert=Zm9v`, err: `ca_cert requires tls_enabled=true`},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			u, err := url.Parse(tc.uri)
+			require.NoError(t, err)
+			_, err = makeNATSSink(context.Background(), sinkURL{URL: u},
+				makeChangefeedTargets(`t`), ``, nilMetricsRecorderBuilder)
+			if tc.err == `` {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+}
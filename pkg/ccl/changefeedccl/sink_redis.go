@@ -0,0 +1,583 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// redisDefaultPort is the port used if the sink URI doesn't specify one.
+	redisDefaultPort = `6379`
+	// The fields of the stream entries. Rows have a key and a value, resolved
+	// timestamps only have a resolved field.
+	redisKeyField      = `key`
+	redisValueField    = `value`
+	redisResolvedField = `resolved`
+	// redisEventChanSize is the number of entries that can be emitted before
+	// EmitRow blocks on the worker.
+	redisEventChanSize = 1024
+)
+
+func isRedisSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeRedis
+}
+
+// redisSink appends rows and resolved timestamps to Redis streams with XADD.
+// Every topic is a stream key. Entries are sent by a worker goroutine which
+// pipelines the XADD commands of a batch over a single connection, so entries
+// are appended in the order they were emitted. Failed commands are retried
+// along with the entries that follow them, which may duplicate entries but
+// preserves their order.
+//
+// Flush waits for Redis to have replied to every entry emitted so far, and
+// EmitResolvedTimestamp flushes before appending the resolved timestamps so
+// that they are never written ahead of the rows they resolve.
+type redisSink struct {
+	ctx     context.Context
+	addr    string
+	dialCfg redisDialConfig
+	topics  *TopicNamer
+	// xaddOpts are the options of every XADD command, which trim the streams
+	// if stream_max_len is set.
+	xaddOpts  [][]byte
+	batchCfg  batchConfig
+	retryOpts retry.Options
+	metrics   metricsRecorder
+
+	scratch bufalloc.ByteAllocator
+
+	// conn is only used by the worker goroutine, except in Dial and Close.
+	conn *redisConn
+
+	eventCh    chan redisEvent
+	stopWorker context.CancelFunc
+	workerDone chan struct{}
+	// workerErr is why the worker exited. It is set before workerDone is closed.
+	workerErr error
+}
+
+// redisEntry is an entry to append to a stream.
+type redisEntry struct {
+	stream string
+	// fields are the field-value pairs of the entry.
+	fields   [][]byte
+	resolved bool
+
+	alloc    kvevent.Alloc
+	emitTime time.Time
+	mvcc     hlc.Timestamp
+}
+
+func (e *redisEntry) size() (n int) {
+	for _, f := range e.fields {
+		n += len(f)
+	}
+	return n
+}
+
+// redisEvent is either an entry or, if flushDone is set, a flush request.
+type redisEvent struct {
+	entry     redisEntry
+	flushDone chan struct{}
+}
+
+var _ Sink = (*redisSink)(nil)
+
+func (s *redisSink) getConcreteType() sinkType {
+	return sinkTypeRedis
+}
+
+// proper JSON schema for Redis sink config, matching the Flush and Retry
+// settings of the webhook sink:
+//
+//	{
+//	  "Flush": {
+//		   "Messages":  ...,
+//		   "Bytes":     ...,
+//		   "Frequency": ...,
+//	  },
+//		 "Retry": {
+//		   "Max":     ...,
+//		   "Backoff": ...,
+//	  }
+//	}
+//
+// The entries of a batch are sent in a single pipeline once Flush.Messages or
+// Flush.Bytes is reached, every Flush.Frequency, and whenever the changefeed
+// flushes the sink. By default, every entry is sent as soon as it is emitted.
+type redisSinkConfig struct {
+	Flush batchConfig `json:",omitempty"`
+	Retry retryConfig `json:",omitempty"`
+}
+
+func getRedisSinkConfig(
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+) (batchCfg batchConfig, retryOpts retry.Options, err error) {
+	retryOpts = defaultRetryConfig()
+
+	var cfg redisSinkConfig
+	cfg.Retry.Max = jsonMaxRetries(retryOpts.MaxRetries)
+	cfg.Retry.Backoff = jsonDuration(retryOpts.InitialBackoff)
+	if jsonStr != `` {
+		if err = json.Unmarshal([]byte(jsonStr), &cfg); err != nil {
+			return batchCfg, retryOpts, errors.Wrapf(err,
+				"failed to parse Redis sink config; check %s option", changefeedbase.OptRedisSinkConfig)
+		}
+	}
+
+	if cfg.Flush.Messages < 0 || cfg.Flush.Bytes < 0 || cfg.Flush.Frequency < 0 ||
+		cfg.Retry.Max < 0 || cfg.Retry.Backoff < 0 {
+		return batchCfg, retryOpts, errors.Errorf(
+			"invalid option value %s, all config values must be non-negative", changefeedbase.OptRedisSinkConfig)
+	}
+	if (cfg.Flush.Messages > 0 || cfg.Flush.Bytes > 0) && cfg.Flush.Frequency == 0 {
+		return batchCfg, retryOpts, errors.Errorf(
+			"invalid option value %s, flush frequency is not set, messages may never be sent", changefeedbase.OptRedisSinkConfig)
+	}
+
+	retryOpts.MaxRetries = int(cfg.Retry.Max)
+	retryOpts.InitialBackoff = time.Duration(cfg.Retry.Backoff)
+	return cfg.Flush, retryOpts, nil
+}
+
+func buildRedisDialConfig(u sinkURL) (redisDialConfig, error) {
+	cfg := redisDialConfig{timeout: 10 * time.Second}
+
+	// As with Redis clients, the credentials are taken from the user info of
+	// the URI and the database from its path.
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			cfg.user, cfg.password = u.User.Username(), password
+		} else {
+			// A user without a password is the password of the default user.
+			cfg.password = u.User.Username()
+		}
+	}
+	if db := strings.TrimPrefix(u.Path, `/`); db != `` {
+		var err error
+		if cfg.db, err = strconv.Atoi(db); err != nil || cfg.db < 0 {
+			return cfg, errors.Errorf(`invalid Redis database %q`, db)
+		}
+	}
+
+	var err error
+	cfg.tls, err = u.consumeTLSConfig()
+	return cfg, err
+}
+
+func makeRedisSink(
+	ctx context.Context,
+	u sinkURL,
+	targets changefeedbase.Targets,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if u.Host == `` {
+		return nil, errors.Errorf(`Redis sink URI must specify a host`)
+	}
+	addr := u.Host
+	if u.Port() == `` {
+		addr = net.JoinHostPort(u.Hostname(), redisDefaultPort)
+	}
+
+	streamPrefix := u.consumeParam(changefeedbase.SinkParamTopicPrefix)
+	streamName := u.consumeParam(changefeedbase.SinkParamTopicName)
+	if schemaTopic := u.consumeParam(changefeedbase.SinkParamSchemaTopic); schemaTopic != `` {
+		return nil, errors.Errorf(`%s is not yet supported`, changefeedbase.SinkParamSchemaTopic)
+	}
+
+	var xaddOpts [][]byte
+	if maxLen := u.consumeParam(changefeedbase.SinkParamStreamMaxLen); maxLen != `` {
+		n, err := strconv.ParseInt(maxLen, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, errors.Errorf(`param %s must be a positive integer`, changefeedbase.SinkParamStreamMaxLen)
+		}
+		// Approximate trimming is much cheaper for Redis, which then only
+		// removes whole nodes of the stream, so it is the default.
+		approx := true
+		if _, err := u.consumeBool(changefeedbase.SinkParamStreamMaxLenApprox, &approx); err != nil {
+			return nil, err
+		}
+		xaddOpts = append(xaddOpts, []byte(`MAXLEN`))
+		if approx {
+			xaddOpts = append(xaddOpts, []byte(`~`))
+		}
+		xaddOpts = append(xaddOpts, []byte(maxLen))
+	} else if u.consumeParam(changefeedbase.SinkParamStreamMaxLenApprox) != `` {
+		return nil, errors.Errorf(`%s requires %s to be set`,
+			changefeedbase.SinkParamStreamMaxLenApprox, changefeedbase.SinkParamStreamMaxLen)
+	}
+
+	dialCfg, err := buildRedisDialConfig(u)
+	if err != nil {
+		return nil, err
+	}
+	batchCfg, retryOpts, err := getRedisSinkConfig(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := MakeTopicNamer(targets, WithPrefix(streamPrefix), WithSingleName(streamName))
+	if err != nil {
+		return nil, err
+	}
+
+	if unknownParams := u.remainingQueryParams(); len(unknownParams) > 0 {
+		return nil, errors.Errorf(
+			`unknown Redis sink query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return &redisSink{
+		ctx:       ctx,
+		addr:      addr,
+		dialCfg:   dialCfg,
+		topics:    topics,
+		xaddOpts:  xaddOpts,
+		batchCfg:  batchCfg,
+		retryOpts: retryOpts,
+		metrics:   mb(requiresResourceAccounting),
+	}, nil
+}
+
+// Dial implements the Sink interface.
+func (s *redisSink) Dial() error {
+	conn, err := dialRedis(s.ctx, s.addr, s.dialCfg)
+	if err != nil {
+		return pgerror.Wrapf(err, pgcode.CannotConnectNow, `connecting to Redis: %s`, s.addr)
+	}
+	s.conn = conn
+
+	workerCtx, stopWorker := context.WithCancel(s.ctx)
+	s.stopWorker = stopWorker
+	s.eventCh = make(chan redisEvent, redisEventChanSize)
+	s.workerDone = make(chan struct{})
+	go s.workerLoop(workerCtx)
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *redisSink) Close() error {
+	if s.workerDone != nil {
+		s.stopWorker()
+		<-s.workerDone
+		// Release the memory of the entries that will never be sent.
+		for done := false; !done; {
+			select {
+			case ev := <-s.eventCh:
+				ev.entry.alloc.Release(s.ctx)
+			default:
+				done = true
+			}
+		}
+	}
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.close()
+}
+
+// Topics gives the keys of all streams that have been initialized and will
+// receive resolved timestamps.
+func (s *redisSink) Topics() []string {
+	return s.topics.DisplayNamesSlice()
+}
+
+// EmitRow implements the Sink interface.
+func (s *redisSink) EmitRow(
+	ctx context.Context,
+	topicDescr TopicDescriptor,
+	key, value []byte,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	stream, err := s.topics.Name(topicDescr)
+	if err != nil {
+		return err
+	}
+	// The key and value are copied because the encoder reuses its buffers.
+	var k, v []byte
+	s.scratch, k = s.scratch.Copy(key, 0 /* extraCap */)
+	s.scratch, v = s.scratch.Copy(value, 0 /* extraCap */)
+	if err := s.enqueue(ctx, redisEvent{entry: redisEntry{
+		stream:   stream,
+		fields:   [][]byte{[]byte(redisKeyField), k, []byte(redisValueField), v},
+		alloc:    alloc,
+		emitTime: timeutil.Now(),
+		mvcc:     mvcc,
+	}}); err != nil {
+		return err
+	}
+	s.metrics.recordMessageSize(int64(len(key) + len(value)))
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *redisSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	defer s.metrics.recordResolvedCallback()()
+
+	// Consumers may act on a resolved timestamp as soon as they read it, so
+	// every row emitted before it must have been appended first.
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	if err := s.topics.Each(func(stream string) error {
+		payload, err := encoder.EncodeResolvedTimestamp(ctx, stream, resolved)
+		if err != nil {
+			return err
+		}
+		var data []byte
+		s.scratch, data = s.scratch.Copy(payload, 0 /* extraCap */)
+		return s.enqueue(ctx, redisEvent{entry: redisEntry{
+			stream:   stream,
+			fields:   [][]byte{[]byte(redisResolvedField), data},
+			resolved: true,
+		}})
+	}); err != nil {
+		return err
+	}
+	return s.Flush(ctx)
+}
+
+// Flush implements the Sink interface.
+func (s *redisSink) Flush(ctx context.Context) error {
+	defer s.metrics.recordFlushRequestCallback()()
+
+	flushDone := make(chan struct{})
+	if err := s.enqueue(ctx, redisEvent{flushDone: flushDone}); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.workerDone:
+		return s.workerErr
+	case <-flushDone:
+		return nil
+	}
+}
+
+// enqueue passes the event to the worker, failing if the worker has exited.
+func (s *redisSink) enqueue(ctx context.Context, ev redisEvent) error {
+	// Check whether the worker exited first, since the event could otherwise
+	// still be buffered in eventCh.
+	select {
+	case <-s.workerDone:
+		return s.workerErr
+	default:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.workerDone:
+		return s.workerErr
+	case s.eventCh <- ev:
+		return nil
+	}
+}
+
+func (s *redisSink) workerLoop(ctx context.Context) {
+	defer close(s.workerDone)
+
+	var batch []redisEntry
+	var batchBytes int
+	defer func() {
+		for i := range batch {
+			batch[i].alloc.Release(s.ctx)
+		}
+	}()
+	sendBatch := func() error {
+		err := s.sendBatch(ctx, batch)
+		batch, batchBytes = nil, 0
+		return err
+	}
+
+	batchTimer := timeutil.NewTimer()
+	defer batchTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.workerErr = ctx.Err()
+			return
+		case ev := <-s.eventCh:
+			if ev.flushDone != nil {
+				if err := sendBatch(); err != nil {
+					s.workerErr = err
+					return
+				}
+				close(ev.flushDone)
+				continue
+			}
+			batch = append(batch, ev.entry)
+			batchBytes += ev.entry.size()
+			if sendNow, sizeBased := s.shouldSendBatch(len(batch), batchBytes); sendNow {
+				if sizeBased {
+					s.metrics.recordSizeBasedFlush()
+				}
+				if err := sendBatch(); err != nil {
+					s.workerErr = err
+					return
+				}
+			} else if len(batch) == 1 {
+				// Only start the timer when the first entry of the batch appears.
+				batchTimer.Reset(time.Duration(s.batchCfg.Frequency))
+			}
+		case <-batchTimer.C:
+			batchTimer.Read = true
+			if len(batch) > 0 {
+				if err := sendBatch(); err != nil {
+					s.workerErr = err
+					return
+				}
+			}
+		}
+	}
+}
+
+// shouldSendBatch returns whether a batch of the given size should be sent,
+// and whether that is because it reached one of the configured thresholds.
+func (s *redisSink) shouldSendBatch(messages, bytes int) (sendNow, sizeBased bool) {
+	switch {
+	case s.batchCfg.Messages == 0 && s.batchCfg.Bytes == 0 && s.batchCfg.Frequency == 0:
+		return true, false
+	case s.batchCfg.Messages > 0 && messages >= s.batchCfg.Messages:
+		return true, true
+	case s.batchCfg.Bytes > 0 && bytes >= s.batchCfg.Bytes:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// sendBatch appends the entries of the batch to their streams, retrying
+// according to the retry configuration.
+func (s *redisSink) sendBatch(ctx context.Context, batch []redisEntry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	defer func() {
+		for i := range batch {
+			batch[i].alloc.Release(ctx)
+		}
+	}()
+
+	pending := batch
+	if err := retry.WithMaxAttempts(ctx, s.retryOpts, s.retryOpts.MaxRetries+1, func() error {
+		acked, err := s.xadd(ctx, pending)
+		pending = pending[acked:]
+		if err != nil {
+			log.VInfof(ctx, 1, "retrying %d entries to Redis: %v", len(pending), err)
+			s.metrics.recordInternalRetry(int64(len(pending)), false /* reducedBatchSize */)
+		}
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, `appending to Redis stream %s`, pending[0].stream)
+	}
+
+	var rows, bytes int
+	var emitTime time.Time
+	var mvcc hlc.Timestamp
+	for i := range batch {
+		e := &batch[i]
+		if e.resolved {
+			continue
+		}
+		rows++
+		bytes += e.size()
+		if emitTime.IsZero() || e.emitTime.Before(emitTime) {
+			emitTime = e.emitTime
+		}
+		if mvcc.IsEmpty() || e.mvcc.Less(mvcc) {
+			mvcc = e.mvcc
+		}
+	}
+	if rows > 0 {
+		s.metrics.recordEmittedBatch(emitTime, rows, mvcc, bytes, sinkDoesNotCompress)
+	}
+	return nil
+}
+
+// xadd pipelines an XADD command for every entry and returns the number of
+// leading entries that were appended. If an entry failed, it is returned along
+// with all following entries, even those that succeeded, so that a retry
+// doesn't reorder them.
+func (s *redisSink) xadd(ctx context.Context, entries []redisEntry) (acked int, _ error) {
+	if s.conn == nil {
+		conn, err := dialRedis(ctx, s.addr, s.dialCfg)
+		if err != nil {
+			return 0, err
+		}
+		s.conn = conn
+	}
+
+	args := make([][]byte, 0, 3+len(s.xaddOpts)+4)
+	for i := range entries {
+		e := &entries[i]
+		args = append(args[:0], []byte(`XADD`), []byte(e.stream))
+		args = append(args, s.xaddOpts...)
+		args = append(args, []byte(`*`))
+		args = append(args, e.fields...)
+		s.conn.writeCommand(args...)
+	}
+	if err := s.conn.flush(); err != nil {
+		s.resetConn()
+		return 0, err
+	}
+
+	// Every reply is read, even after an error reply, so that the connection
+	// can be reused.
+	var firstErr error
+	for range entries {
+		_, err := s.conn.readReply()
+		var redisErr redisError
+		switch {
+		case err == nil:
+			if firstErr == nil {
+				acked++
+			}
+		case errors.As(err, &redisErr):
+			if firstErr == nil {
+				firstErr = err
+			}
+		default:
+			s.resetConn()
+			return acked, err
+		}
+	}
+	return acked, firstErr
+}
+
+// resetConn closes a connection which can no longer be used, so that the next
+// attempt dials a new one.
+func (s *redisSink) resetConn() {
+	if err := s.conn.close(); err != nil {
+		log.VInfof(s.ctx, 1, "error closing Redis connection: %v", err)
+	}
+	s.conn = nil
+}
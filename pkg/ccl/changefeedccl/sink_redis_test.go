@@ -0,0 +1,230 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func requireRedisEntries(t *testing.T, entries []cdctest.RedisStreamEntry, expected ...string) {
+	t.Helper()
+	var actual []string
+	for _, e := range entries {
+		if v, ok := e.Field(redisValueField); ok {
+			k, _ := e.Field(redisKeyField)
+			actual = append(actual, k+`->`+v)
+		} else {
+			v, _ := e.Field(redisResolvedField)
+			actual = append(actual, v)
+		}
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestRedisSinkChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a'), (2, 'b'), (3, 'c')`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1)`)
+
+		// The host of the URI is replaced with the address of the mock server.
+		cf := feed(t, f, `CREATE CHANGEFEED FOR foo, bar `+
+			`INTO 'redis://redis/2?topic_prefix=cdc:&stream_max_len=2' WITH resolved`)
+		defer closeFeed(t, cf)
+		assertPayloads(t, cf, []string{
+			`cdc:foo: [1]->{"after": {"a": 1, "b": "a"}}`,
+			`cdc:foo: [2]->{"after": {"a": 2, "b": "b"}}`,
+			`cdc:foo: [3]->{"after": {"a": 3, "b": "c"}}`,
+			`cdc:bar: [1]->{"after": {"a": 1}}`,
+		})
+		// The rows are flushed before the resolved timestamp is appended.
+		expectResolvedTimestamp(t, cf)
+
+		srv := cf.(*redisFeed).srv
+		for _, stream := range []string{`cdc:foo`, `cdc:bar`} {
+			// Every stream is in the selected database and is trimmed.
+			entries := srv.Entries(2, stream)
+			require.NotEmpty(t, entries)
+			require.LessOrEqual(t, len(entries), 2)
+		}
+		for _, cmd := range srv.Commands() {
+			if cmd[0] == `XADD` {
+				require.Equal(t, []string{`MAXLEN`, `~`, `2`}, cmd[2:5])
+			}
+		}
+
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, cf, []string{
+			`cdc:foo: [1]->{"after": null}`,
+		})
+	}
+
+	cdcTest(t, testFn, feedTestForceSink("redis"), feedTestNoExternalConnection)
+}
+
+func TestRedisSinkErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, err := cdctest.StartMockRedisServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	t.Run(`retries failed entries in order`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeRedisSink, srv.URL()+`?topic_name=retry`,
+			`{"Flush": {"Messages": 3, "Frequency": "1h"}, "Retry": {"Max": 3, "Backoff": "5ms"}}`, nil /* configure */, `t`)
+		defer cleanup()
+		srv.FailNextXAdds(1)
+		for _, k := range []string{`k1`, `k2`, `k3`} {
+			require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(k), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		}
+		require.NoError(t, sink.Flush(ctx))
+		// The first entry failed, so the entries after it are appended again.
+		requireRedisEntries(t, srv.Entries(0, `retry`), `k2->v`, `k3->v`, `k1->v`, `k2->v`, `k3->v`)
+	})
+
+	t.Run(`retries exhausted`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeRedisSink, srv.URL()+`?topic_name=exhausted`,
+			`{"Retry": {"Max": 1, "Backoff": "5ms"}}`, nil /* configure */, `t`)
+		defer cleanup()
+		srv.FailNextXAdds(2)
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.Regexp(t, `OOM command not allowed`, sink.Flush(ctx))
+		// The sink can't be used anymore.
+		require.Regexp(t, `OOM command not allowed`,
+			sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+	})
+
+	t.Run(`reconnects`, func(t *testing.T) {
+		sink, cleanup := makeTestMessageBrokerSink(t, makeRedisSink, srv.URL()+`?topic_name=reconnect`,
+			`{"Retry": {"Backoff": "5ms"}}`, nil /* configure */, `t`)
+		defer cleanup()
+		srv.CloseConnections()
+		require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k`), []byte(`v`), zeroTS, zeroTS, zeroAlloc))
+		require.NoError(t, sink.Flush(ctx))
+		requireRedisEntries(t, srv.Entries(0, `reconnect`), `k->v`)
+	})
+}
+
+func TestRedisSinkBatching(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	srv, err := cdctest.StartMockRedisServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	sink, cleanup := makeTestMessageBrokerSink(t, makeRedisSink, srv.URL(),
+		`{"Flush": {"Messages": 2, "Frequency": "1h"}}`, nil /* configure */, `t`)
+	defer cleanup()
+
+	// The first entry is buffered until the batch is full...
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k1`), []byte(`v1`), zeroTS, zeroTS, zeroAlloc))
+	require.Empty(t, srv.Entries(0, `t`))
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k2`), []byte(`v2`), zeroTS, zeroTS, zeroAlloc))
+	require.Eventually(t, func() bool {
+		return len(srv.Entries(0, `t`)) == 2
+	}, 10*time.Second, time.Millisecond)
+
+	// ... or the sink is flushed.
+	require.NoError(t, sink.EmitRow(ctx, makeTopic(`t`), []byte(`k3`), []byte(`v3`), zeroTS, zeroTS, zeroAlloc))
+	require.NoError(t, sink.Flush(ctx))
+	requireRedisEntries(t, srv.Entries(0, `t`), `k1->v1`, `k2->v2`, `k3->v3`)
+}
+
+func TestRedisSinkAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	srv, err := cdctest.StartMockRedisServerWithAuth(`user`, `pass`)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL())
+	require.NoError(t, err)
+
+	u.User = url.UserPassword(`user`, `pass`)
+	_, cleanup := makeTestMessageBrokerSink(t, makeRedisSink, u.String(), ``, nil /* configure */, `t`)
+	cleanup()
+
+	u.User = url.UserPassword(`user`, `wrong`)
+	sink, err := makeRedisSink(context.Background(), sinkURL{URL: u},
+		makeChangefeedTargets(`t`), ``, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	require.Regexp(t, `WRONGPASS`, sink.Dial())
+	require.NoError(t, sink.Close())
+}
+
+func TestRedisSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		config changefeedbase.SinkSpecificJSONConfig
+		err    string
+	}{
+		{config: ``},
+		{config: `{"Flush": {"Messages": 100, "Bytes": 1000, "Frequency": "1s"}, "Retry": {"Max": "inf", "Backoff": "1s"}}`},
+		{config: `{"Flush": {"Messages": 100}}`, err: `flush frequency is not set`},
+		{config: `{"Flush": {"Bytes": -1, "Frequency": "1s"}}`, err: `must be non-negative`},
+		{config: `{"Flush": {"Frequency": "forever"}}`, err: `check redis_sink_config option`},
+	} {
+		t.Run(string(tc.config), func(t *testing.T) {
+			_, _, err := getRedisSinkConfig(tc.config)
+			if tc.err == `` {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{uri: `redis://localhost:6379/1?topic_prefix=cdc:&stream_max_len=1000`},
+		{uri: `redis://localhost?topic_name=all&stream_max_len=10&stream_max_len_approximate=false`},
+		{uri: `redis://`, err: `must specify a host`},
+		{uri: `redis://localhost/db`, err: `invalid Redis database "db"`},
+		{uri: `redis://localhost?stream_max_len=0`, err: `stream_max_len must be a positive integer`},
+		{uri: `redis://localhost?stream_max_len_approximate=true`, err: `requires stream_max_len to be set`},
+		{uri: `redis://localhost?foo=bar`, err: `unknown Redis sink query parameters: foo`},
+		{uri: `redis://localhost?ca_cert=Zm9v`, err: `ca_cert requires tls_enabled=true`},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			u, err := url.Parse(tc.uri)
+			require.NoError(t, err)
+			_, err = makeRedisSink(context.Background(), sinkURL{URL: u},
+				makeChangefeedTargets(`t`), ``, nilMetricsRecorderBuilder)
+			if tc.err == `` {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+}
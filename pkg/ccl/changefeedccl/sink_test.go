@@ -298,6 +298,35 @@ func makeChangefeedTargets(targetNames ...string) changefeedbase.Targets {
 	return targets
 }
 
+// makeTestMessageBrokerSink makes a sink for the given targets with the
+// constructor of a message broker sink, such as makeNATSSink or makeRedisSink.
+// The sink is dialed once configure, if set, has been applied to it. The
+// returned cleanup function closes the sink.
+func makeTestMessageBrokerSink(
+	t *testing.T,
+	makeSink func(
+		context.Context, sinkURL, changefeedbase.Targets,
+		changefeedbase.SinkSpecificJSONConfig, metricsRecorderBuilder,
+	) (Sink, error),
+	sinkURI string,
+	jsonConfig changefeedbase.SinkSpecificJSONConfig,
+	configure func(Sink),
+	targetNames ...string,
+) (sink Sink, cleanup func()) {
+	u, err := url.Parse(sinkURI)
+	require.NoError(t, err)
+	sink, err = makeSink(context.Background(), sinkURL{URL: u},
+		makeChangefeedTargets(targetNames...), jsonConfig, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	if configure != nil {
+		configure(sink)
+	}
+	require.NoError(t, sink.Dial())
+	return sink, func() {
+		require.NoError(t, sink.Close())
+	}
+}
+
 func TestKafkaSink(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -327,6 +327,29 @@ func setURI(
 	return nil
 }
 
+// setMockURI sets the sink URI of the changefeed to the URI of a mock server.
+// If the statement already has a sink URI with the same scheme, only its host
+// is replaced with the mock server's, so that tests can choose the rest of the
+// URI, such as its query parameters.
+func setMockURI(createStmt *tree.CreateChangefeed, mockURI string, args *[]interface{}) error {
+	if createStmt.SinkURI != nil {
+		u, err := url.Parse(tree.AsStringWithFlags(createStmt.SinkURI, tree.FmtBareStrings))
+		if err != nil {
+			return err
+		}
+		mock, err := url.Parse(mockURI)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == mock.Scheme {
+			u.Host = mock.Host
+			createStmt.SinkURI = tree.NewStrVal(u.String())
+			return nil
+		}
+	}
+	return setURI(createStmt, mockURI, false /* allowOverride */, args)
+}
+
 // reportErrorResumer is a job resumer which reports OnFailOrCancel events.
 type reportErrorResumer struct {
 	wrapped   jobs.Resumer
@@ -1796,13 +1819,15 @@ func (k *kafkaFeedFactory) Feed(create string, args ...interface{}) (cdctest.Tes
 	}
 
 	var registry *cdctest.SchemaRegistry
+	var format changefeedbase.FormatType
 	for _, opt := range createStmt.Options {
 		if opt.Key == changefeedbase.OptFormat {
-			format, err := exprAsString(opt.Value)
+			formatStr, err := exprAsString(opt.Value)
 			if err != nil {
 				return nil, err
 			}
-			if format == string(changefeedbase.OptFormatAvro) {
+			format = changefeedbase.FormatType(formatStr)
+			if format == changefeedbase.OptFormatAvro || format == changefeedbase.OptFormatProtobuf {
 				// Must use confluent schema registry so that we register our schema
 				// in order to be able to decode kafka messages.
 				registry = cdctest.StartTestSchemaRegistry()
@@ -1837,6 +1862,7 @@ func (k *kafkaFeedFactory) Feed(create string, args ...interface{}) (cdctest.Tes
 		source:         feedCh,
 		tg:             tg,
 		registry:       registry,
+		format:         format,
 	}
 
 	if err := k.startFeedJob(c.jobFeed, createStmt.String(), args...); err != nil {
@@ -1857,8 +1883,9 @@ type kafkaFeed struct {
 	source chan *sarama.ProducerMessage
 	tg     *teeGroup
 
-	// Registry is set if we're emitting avro.
+	// Registry is set if we're emitting avro or protobuf.
 	registry *cdctest.SchemaRegistry
+	format   changefeedbase.FormatType
 }
 
 var _ cdctest.TestFeed = (*kafkaFeed)(nil)
@@ -1906,14 +1933,18 @@ func (k *kafkaFeed) Next() (*cdctest.TestFeedMessage, error) {
 			}
 			if k.registry == nil {
 				*dest = decoded
-			} else {
-				// Convert avro record to json.
-				jsonBytes, err := k.registry.AvroToJSON(decoded)
-				if err != nil {
-					return err
-				}
-				*dest = jsonBytes
+				return nil
+			}
+			// Convert avro record or protobuf message to json.
+			toJSON := k.registry.AvroToJSON
+			if k.format == changefeedbase.OptFormatProtobuf {
+				toJSON = k.registry.ProtobufToJSON
 			}
+			jsonBytes, err := toJSON(decoded)
+			if err != nil {
+				return err
+			}
+			*dest = jsonBytes
 			return nil
 		}
 
@@ -2438,6 +2469,251 @@ func (p *pubsubFeed) Close() error {
 	return nil
 }
 
+type redisFeedFactory struct {
+	enterpriseFeedFactory
+}
+
+var _ cdctest.TestFeedFactory = (*redisFeedFactory)(nil)
+
+// makeRedisFeedFactory returns a TestFeedFactory implementation using the `redis` uri.
+func makeRedisFeedFactory(srvOrCluster interface{}, db *gosql.DB) cdctest.TestFeedFactory {
+	s, injectables := getInjectables(srvOrCluster)
+	return &redisFeedFactory{
+		enterpriseFeedFactory: enterpriseFeedFactory{
+			s:  s,
+			db: db,
+			di: newDepInjector(injectables...),
+		},
+	}
+}
+
+// Feed implements cdctest.TestFeedFactory
+func (f *redisFeedFactory) Feed(create string, args ...interface{}) (cdctest.TestFeed, error) {
+	parsed, err := parser.ParseOne(create)
+	if err != nil {
+		return nil, err
+	}
+	createStmt := parsed.AST.(*tree.CreateChangefeed)
+
+	srv, err := cdctest.StartMockRedisServer()
+	if err != nil {
+		return nil, err
+	}
+	if err := setMockURI(createStmt, srv.URL(), &args); err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	ss := &sinkSynchronizer{}
+	wrapSink := func(s Sink) Sink {
+		return &notifyFlushSink{Sink: s, sync: ss}
+	}
+
+	c := &redisFeed{
+		jobFeed:        newJobFeed(f.jobsTableConn(), wrapSink),
+		seenTrackerMap: make(map[string]struct{}),
+		ss:             ss,
+		srv:            srv,
+	}
+	if err := f.startFeedJob(c.jobFeed, createStmt.String(), args...); err != nil {
+		srv.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Server implements TestFeedFactory
+func (f *redisFeedFactory) Server() serverutils.TestTenantInterface {
+	return f.s
+}
+
+type redisFeed struct {
+	*jobFeed
+	seenTrackerMap
+	ss  *sinkSynchronizer
+	srv *cdctest.MockRedisServer
+}
+
+var _ cdctest.TestFeed = (*redisFeed)(nil)
+
+// Partitions implements TestFeed
+func (f *redisFeed) Partitions() []string {
+	return []string{``}
+}
+
+// Next implements TestFeed
+func (f *redisFeed) Next() (*cdctest.TestFeedMessage, error) {
+	for {
+		if entry, ok := f.srv.Pop(); ok {
+			m := &cdctest.TestFeedMessage{Topic: entry.Key}
+			if resolved, ok := entry.Field(redisResolvedField); ok {
+				m.Resolved = []byte(resolved)
+				return m, nil
+			}
+			key, _ := entry.Field(redisKeyField)
+			value, _ := entry.Field(redisValueField)
+			m.Key, m.Value = []byte(key), []byte(value)
+			if isNew := f.markSeen(m); !isNew {
+				continue
+			}
+			return m, nil
+		}
+
+		if err := contextutil.RunWithTimeout(
+			context.Background(), timeoutOp("redis.Next", f.jobID), timeout(),
+			func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-f.ss.eventReady():
+					return nil
+				case <-f.srv.NotifyEntry():
+					return nil
+				case <-f.shutdown:
+					return f.terminalJobError()
+				}
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close implements TestFeed
+func (f *redisFeed) Close() error {
+	err := f.jobFeed.Close()
+	if err != nil {
+		return err
+	}
+	f.srv.Close()
+	return nil
+}
+
+type natsFeedFactory struct {
+	enterpriseFeedFactory
+}
+
+var _ cdctest.TestFeedFactory = (*natsFeedFactory)(nil)
+
+// makeNATSFeedFactory returns a TestFeedFactory implementation using the `nats` uri.
+func makeNATSFeedFactory(srvOrCluster interface{}, db *gosql.DB) cdctest.TestFeedFactory {
+	s, injectables := getInjectables(srvOrCluster)
+	return &natsFeedFactory{
+		enterpriseFeedFactory: enterpriseFeedFactory{
+			s:  s,
+			db: db,
+			di: newDepInjector(injectables...),
+		},
+	}
+}
+
+// Feed implements cdctest.TestFeedFactory
+func (f *natsFeedFactory) Feed(create string, args ...interface{}) (cdctest.TestFeed, error) {
+	parsed, err := parser.ParseOne(create)
+	if err != nil {
+		return nil, err
+	}
+	createStmt := parsed.AST.(*tree.CreateChangefeed)
+
+	// Every subject is bound to a JetStream stream.
+	srv, err := cdctest.StartMockNATSServer(map[string]string{`>`: `CDC`})
+	if err != nil {
+		return nil, err
+	}
+	if err := setMockURI(createStmt, srv.URL(), &args); err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	ss := &sinkSynchronizer{}
+	wrapSink := func(s Sink) Sink {
+		return &notifyFlushSink{Sink: s, sync: ss}
+	}
+
+	c := &natsFeed{
+		jobFeed:        newJobFeed(f.jobsTableConn(), wrapSink),
+		seenTrackerMap: make(map[string]struct{}),
+		ss:             ss,
+		srv:            srv,
+	}
+	if err := f.startFeedJob(c.jobFeed, createStmt.String(), args...); err != nil {
+		srv.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Server implements TestFeedFactory
+func (f *natsFeedFactory) Server() serverutils.TestTenantInterface {
+	return f.s
+}
+
+type natsFeed struct {
+	*jobFeed
+	seenTrackerMap
+	ss  *sinkSynchronizer
+	srv *cdctest.MockNATSServer
+}
+
+var _ cdctest.TestFeed = (*natsFeed)(nil)
+
+// Partitions implements TestFeed
+func (f *natsFeed) Partitions() []string {
+	return []string{``}
+}
+
+// Next implements TestFeed
+func (f *natsFeed) Next() (*cdctest.TestFeedMessage, error) {
+	for {
+		if msg, ok := f.srv.Pop(); ok {
+			m := &cdctest.TestFeedMessage{Topic: msg.Subject}
+			encodedKey, ok := msg.Header[natsKeyHeader]
+			if !ok {
+				// Resolved timestamps are the only messages without a key.
+				m.Resolved = msg.Data
+				return m, nil
+			}
+			key, err := base64.StdEncoding.DecodeString(encodedKey)
+			if err != nil {
+				return nil, err
+			}
+			m.Key, m.Value = key, msg.Data
+			if isNew := f.markSeen(m); !isNew {
+				continue
+			}
+			return m, nil
+		}
+
+		if err := contextutil.RunWithTimeout(
+			context.Background(), timeoutOp("nats.Next", f.jobID), timeout(),
+			func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-f.ss.eventReady():
+					return nil
+				case <-f.srv.NotifyMessage():
+					return nil
+				case <-f.shutdown:
+					return f.terminalJobError()
+				}
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close implements TestFeed
+func (f *natsFeed) Close() error {
+	err := f.jobFeed.Close()
+	if err != nil {
+		return err
+	}
+	f.srv.Close()
+	return nil
+}
+
 // stopFeedWhenDone arranges for feed to stop when passed in context
 // is done. Returns cleanup function.
 func stopFeedWhenDone(ctx context.Context, f cdctest.TestFeed) func() {
@@ -34,6 +34,9 @@ Valid fields:
 * key     (required): path to the current key file, or "plain"
 * old-key (required): path to the previous key file, or "plain"
 * rotation-period   : amount of time after which data keys should be rotated
+* cipher-mode       : "ctr" (default) or "gcm"; "gcm" authenticates the encrypted
+                      data. Existing files keep their cipher mode until they are
+                      rewritten (e.g. by compactions)
 
 </PRE>
 example:
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/cliccl/cliflagsccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
 	"github.com/cockroachdb/cockroach/pkg/cli"
 	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
@@ -46,6 +47,7 @@ const (
 
 var encryptionStatusOpts struct {
 	activeStoreIDOnly bool
+	perFile           bool
 }
 
 func init() {
@@ -59,6 +61,9 @@ Encryption keys must be specified in the '--enterprise-encryption' flag.
 Displays all store and data keys as well as files encrypted with each.
 Specifying --active-store-key-id-only prints the key ID of the active store key
 and exits.
+Specifying --per-file prints the cipher and key ID of each file instead, along
+with whether the file is encrypted with the active key. Files encrypted with
+older keys or ciphers are re-encrypted as they are rewritten by compactions.
 `,
 		Args: cobra.ExactArgs(1),
 		RunE: clierrorplus.MaybeDecorateError(runEncryptionStatus),
@@ -116,6 +121,8 @@ with their env type and encryption settings (if applicable).
 	// And other flags.
 	f.BoolVar(&encryptionStatusOpts.activeStoreIDOnly, "active-store-key-id-only", false,
 		"print active store key ID and exit")
+	f.BoolVar(&encryptionStatusOpts.perFile, "per-file", false,
+		"print the cipher and key ID of each file")
 	// For the encryption-decrypt command.
 	f = encryptionDecryptCmd.Flags()
 	cliflagcfg.VarFlag(f, &storeEncryptionSpecs, cliflagsccl.EnterpriseEncryption)
@@ -187,6 +194,18 @@ type PrettyStoreKey struct {
 	DataKeys []PrettyDataKey `json:",omitempty"`
 }
 
+// PrettyFile is the final json-exportable struct for the encryption status of
+// a file.
+type PrettyFile struct {
+	Name   string
+	Env    string
+	Cipher string
+	KeyID  string
+	// OnInactiveKey is true if the file is not encrypted with the active key
+	// of its env, and will be re-encrypted when it is rewritten.
+	OnInactiveKey bool `json:",omitempty"`
+}
+
 func runEncryptionStatus(cmd *cobra.Command, args []string) error {
 	stopper := stop.NewStopper()
 	defer stopper.Stop(context.Background())
@@ -222,6 +241,10 @@ func runEncryptionStatus(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if encryptionStatusOpts.perFile {
+		return printFileEncryptionStatus(cmd, &fileRegistry, &keyRegistry)
+	}
+
 	// Build a map of 'key ID' -> list of files
 	fileKeyMap := make(map[string][]string)
 
@@ -317,6 +340,45 @@ func runEncryptionStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printFileEncryptionStatus prints the encryption status of each file in the
+// file registry.
+func printFileEncryptionStatus(
+	cmd *cobra.Command,
+	fileRegistry *enginepb.FileRegistry,
+	keyRegistry *enginepbccl.DataKeysRegistry,
+) error {
+	infos, err := engineccl.GetFileEncryptionInfos(fileRegistry, keyRegistry)
+	if err != nil {
+		return err
+	}
+
+	files := make([]PrettyFile, 0, len(infos))
+	var missingKeys []string
+	for _, info := range infos {
+		files = append(files, PrettyFile{
+			Name:          info.Filename,
+			Env:           info.EnvType.String(),
+			Cipher:        info.EncryptionType.String(),
+			KeyID:         info.KeyID,
+			OnInactiveKey: info.EnvType != enginepb.EnvType_Plaintext && !info.ActiveKey,
+		})
+		if info.Key == nil && info.KeyID != plaintextKeyID {
+			missingKeys = append(missingKeys, info.Filename)
+		}
+	}
+
+	j, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", j)
+
+	if len(missingKeys) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: could not find key info for some files: %+v\n", missingKeys)
+	}
+	return nil
+}
+
 func runEncryptionActiveKey(cmd *cobra.Command, args []string) error {
 	keyType, keyID, err := getActiveEncryptionkey(args[0])
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -203,6 +204,78 @@ marker.manifest.000001.MANIFEST-000001:
 	require.Equal(t, want, b.String())
 }
 
+func TestEncryptionStatusPerFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Generate two encryption keys to use.
+	oldKeyPath := filepath.Join(dir, "old.key")
+	err := cli.GenEncryptionKeyCmd.RunE(nil, []string{oldKeyPath})
+	require.NoError(t, err)
+	newKeyPath := filepath.Join(dir, "new.key")
+	err = cli.GenEncryptionKeyCmd.RunE(nil, []string{newKeyPath})
+	require.NoError(t, err)
+
+	// openStore opens the store with the given encryption spec, writes a key
+	// and flushes, creating a table encrypted with the active data key.
+	openStore := func(encSpecStr string, key string) {
+		encSpec, err := baseccl.NewStoreEncryptionSpec(encSpecStr)
+		require.NoError(t, err)
+		encOpts, err := encSpec.ToEncryptionOptions()
+		require.NoError(t, err)
+		p, err := storage.Open(ctx, storage.Filesystem(dir), cluster.MakeClusterSettings(), storage.EncryptionAtRest(encOpts))
+		require.NoError(t, err)
+		defer p.Close()
+		require.NoError(t, p.PutUnversioned([]byte(key), nil))
+		require.NoError(t, p.Flush())
+	}
+	openStore(fmt.Sprintf("path=%s,key=%s,old-key=plain", dir, oldKeyPath), "foo")
+	// Rotate the store key, which also rotates the data key. The table written
+	// above stays encrypted with the old data key.
+	encSpecStr := fmt.Sprintf("path=%s,key=%s,old-key=%s", dir, newKeyPath, oldKeyPath)
+	openStore(encSpecStr, "bar")
+
+	cmd := getTool(cli.DebugCmd, []string{"debug", "encryption-status"})
+	require.NotNil(t, cmd)
+	require.NoError(t, cmd.Flags().Set("enterprise-encryption", encSpecStr))
+	require.NoError(t, cmd.Flags().Set("per-file", "true"))
+	defer func() { encryptionStatusOpts.perFile = false }()
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err = runEncryptionStatus(cmd, []string{dir})
+	require.NoError(t, err)
+	require.Empty(t, stderr.String())
+
+	var files []PrettyFile
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &files))
+	var tables []PrettyFile
+	for i, f := range files {
+		if i > 0 {
+			require.Less(t, files[i-1].Name, f.Name)
+		}
+		require.NotEqual(t, plaintextKeyID, f.KeyID, "file %s", f.Name)
+		require.Equal(t, "AES128_CTR", f.Cipher, "file %s", f.Name)
+		switch {
+		case strings.HasPrefix(f.Name, keyRegistryFilename):
+			require.Equal(t, "Store", f.Env)
+			require.False(t, f.OnInactiveKey, "file %s", f.Name)
+		case strings.HasSuffix(f.Name, ".sst"):
+			require.Equal(t, "Data", f.Env)
+			tables = append(tables, f)
+		}
+	}
+	// The table written before the rotation is on the old data key, the one
+	// written after it on the new one.
+	require.Len(t, tables, 2)
+	require.True(t, tables[0].OnInactiveKey)
+	require.False(t, tables[1].OnInactiveKey)
+	require.NotEqual(t, tables[0].KeyID, tables[1].KeyID)
+}
+
 // getTool traverses the given cobra.Command recursively, searching for a tool
 // matching the given command.
 func getTool(cmd *cobra.Command, want []string) *cobra.Command {
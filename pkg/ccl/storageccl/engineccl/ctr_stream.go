@@ -43,6 +43,14 @@ func (c *FileCipherStreamCreator) CreateNew(
 	if err != nil {
 		return nil, nil, err
 	}
+	return createStream(key)
+}
+
+// createStream creates a FileStream for a new file using the provided key, which must not be an
+// AES-GCM key.
+func createStream(
+	key *enginepbccl.SecretKey,
+) (*enginepbccl.EncryptionSettings, FileStream, error) {
 	settings := &enginepbccl.EncryptionSettings{}
 	if key == nil || key.Info.EncryptionType == enginepbccl.EncryptionType_Plaintext {
 		settings.EncryptionType = enginepbccl.EncryptionType_Plaintext
@@ -52,7 +60,7 @@ func (c *FileCipherStreamCreator) CreateNew(
 	settings.EncryptionType = key.Info.EncryptionType
 	settings.KeyId = key.Info.KeyId
 	settings.Nonce = make([]byte, ctrNonceSize)
-	_, err = rand.Read(settings.Nonce)
+	_, err := rand.Read(settings.Nonce)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -62,7 +70,7 @@ func (c *FileCipherStreamCreator) CreateNew(
 	}
 	// Does not matter how we convert 4 random bytes into uint32
 	settings.Counter = binary.LittleEndian.Uint32(counterBytes)
-	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
+	ctrCS, err := newCTRBlockCipherStream(settings.EncryptionType, key, settings.Nonce, settings.Counter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -81,7 +89,7 @@ func (c *FileCipherStreamCreator) CreateExisting(
 	if err != nil {
 		return nil, err
 	}
-	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
+	ctrCS, err := newCTRBlockCipherStream(settings.EncryptionType, key, settings.Nonce, settings.Counter)
 	if err != nil {
 		return nil, err
 	}
@@ -161,15 +169,19 @@ type cTRBlockCipherStream struct {
 	cBlock cipher.Block
 }
 
+// newCTRBlockCipherStream returns a cTRBlockCipherStream for the given encryption type. Only the
+// bytes of the key are used: the encryption type of a key loaded from a store key file depends on
+// the cipher mode the store is currently configured with, while the encryption type passed here
+// is the one that was recorded for the file.
 func newCTRBlockCipherStream(
-	key *enginepbccl.SecretKey, nonce []byte, counter uint32,
+	encType enginepbccl.EncryptionType, key *enginepbccl.SecretKey, nonce []byte, counter uint32,
 ) (*cTRBlockCipherStream, error) {
-	switch key.Info.EncryptionType {
+	switch encType {
 	case enginepbccl.EncryptionType_AES128_CTR:
 	case enginepbccl.EncryptionType_AES192_CTR:
 	case enginepbccl.EncryptionType_AES256_CTR:
 	default:
-		return nil, fmt.Errorf("unknown EncryptionType: %d", key.Info.EncryptionType)
+		return nil, fmt.Errorf("unknown EncryptionType: %d", encType)
 	}
 	stream := &cTRBlockCipherStream{key: key, counter: counter}
 	// Copy the nonce since the caller may overwrite it in the future.
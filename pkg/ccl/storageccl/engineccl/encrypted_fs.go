@@ -9,8 +9,11 @@
 package engineccl
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
+	"os"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
@@ -18,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/pebble/vfs"
 )
 
@@ -104,11 +108,113 @@ func (f *encryptedFile) ReadAt(p []byte, off int64) (n int, err error) {
 	return n, err
 }
 
+// createFile wraps a new file so that it's encrypted using the currently active key. It returns
+// the settings used, so that the caller can record these in a file registry.
+func (c *FileCipherStreamCreator) createFile(
+	ctx context.Context, f vfs.File,
+) (*enginepbccl.EncryptionSettings, vfs.File, error) {
+	key, err := c.keyManager.ActiveKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || !isGCMEncryptionType(key.Info.EncryptionType) {
+		settings, stream, err := createStream(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return settings, &encryptedFile{File: f, stream: stream}, nil
+	}
+	settings := &enginepbccl.EncryptionSettings{
+		EncryptionType: key.Info.EncryptionType,
+		KeyId:          key.Info.KeyId,
+		Nonce:          make([]byte, gcmFileIDSize),
+	}
+	if _, err := rand.Read(settings.Nonce); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCMCipher(settings.EncryptionType, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gf, err := newGCMFile(f, aead, settings.Nonce, true /* writable */)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, gf, nil
+}
+
+// openFile wraps an existing file so that it's decrypted using the key described by settings.
+func (c *FileCipherStreamCreator) openFile(
+	f vfs.File, settings *enginepbccl.EncryptionSettings,
+) (vfs.File, error) {
+	if settings == nil || !isGCMEncryptionType(settings.EncryptionType) {
+		stream, err := c.CreateExisting(settings)
+		if err != nil {
+			return nil, err
+		}
+		return &encryptedFile{File: f, stream: stream}, nil
+	}
+	key, err := c.keyManager.GetKey(settings.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCMCipher(settings.EncryptionType, key)
+	if err != nil {
+		return nil, err
+	}
+	return newGCMFile(f, aead, settings.Nonce, false /* writable */)
+}
+
 // encryptedFS implements vfs.FS.
 type encryptedFS struct {
 	vfs.FS
 	fileRegistry  *storage.PebbleFileRegistry
 	streamCreator *FileCipherStreamCreator
+
+	// gcmSizes caches the logical sizes of the complete files encrypted with
+	// AES-GCM, which can otherwise only be determined by reading their last
+	// chunk. An entry is only valid for the file with the same file ID, since
+	// a name can be reused by a new file.
+	gcmSizes struct {
+		syncutil.Mutex
+		m map[string]gcmSize
+	}
+}
+
+// gcmSize is the logical size of the file with the given file ID.
+type gcmSize struct {
+	fileID [gcmFileIDSize]byte
+	size   int64
+}
+
+// cacheGCMSize records the logical size of a complete file.
+func (fs *encryptedFS) cacheGCMSize(name string, fileID []byte, size int64) {
+	e := gcmSize{size: size}
+	copy(e.fileID[:], fileID)
+	fs.gcmSizes.Lock()
+	defer fs.gcmSizes.Unlock()
+	if fs.gcmSizes.m == nil {
+		fs.gcmSizes.m = make(map[string]gcmSize)
+	}
+	fs.gcmSizes.m[name] = e
+}
+
+// getGCMSize returns the cached logical size of a file, if any.
+func (fs *encryptedFS) getGCMSize(name string, fileID []byte) (int64, bool) {
+	fs.gcmSizes.Lock()
+	defer fs.gcmSizes.Unlock()
+	e, ok := fs.gcmSizes.m[name]
+	if !ok || !bytes.Equal(e.fileID[:], fileID) {
+		return 0, false
+	}
+	return e.size, true
+}
+
+// evictGCMSize removes the cached logical size of a file, if any.
+func (fs *encryptedFS) evictGCMSize(name string) {
+	fs.gcmSizes.Lock()
+	defer fs.gcmSizes.Unlock()
+	delete(fs.gcmSizes.m, name)
 }
 
 // Create implements vfs.FS.Create.
@@ -118,7 +224,7 @@ func (fs *encryptedFS) Create(name string) (vfs.File, error) {
 		return f, err
 	}
 	// NB: f.Close() must be called except in the case of a successful return.
-	settings, stream, err := fs.streamCreator.CreateNew(context.TODO())
+	settings, ef, err := fs.streamCreator.createFile(context.TODO(), f)
 	if err != nil {
 		_ = f.Close()
 		return nil, err
@@ -143,7 +249,12 @@ func (fs *encryptedFS) Create(name string) (vfs.File, error) {
 			return nil, err
 		}
 	}
-	return &encryptedFile{File: f, stream: stream}, nil
+	if gf, ok := ef.(*gcmFile); ok {
+		gf.onClose = func(size int64) {
+			fs.cacheGCMSize(name, gf.fileID[:], size)
+		}
+	}
+	return ef, nil
 }
 
 // Link implements vfs.FS.Link.
@@ -160,26 +271,80 @@ func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, erro
 	if err != nil {
 		return f, err
 	}
+	settings, err := fs.getSettings(name)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	ef, err := fs.streamCreator.openFile(f, settings)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return ef, nil
+}
+
+// getSettings returns the encryption settings of the file from the file registry, or nil if
+// the file is not encrypted.
+func (fs *encryptedFS) getSettings(name string) (*enginepbccl.EncryptionSettings, error) {
 	fileEntry := fs.fileRegistry.GetFileEntry(name)
-	var settings *enginepbccl.EncryptionSettings
-	if fileEntry != nil {
-		if fileEntry.EnvType != fs.streamCreator.envType {
-			f.Close()
-			return nil, fmt.Errorf("filename: %s has env %d not equal to FS env %d",
-				name, fileEntry.EnvType, fs.streamCreator.envType)
-		}
-		settings = &enginepbccl.EncryptionSettings{}
-		if err := protoutil.Unmarshal(fileEntry.EncryptionSettings, settings); err != nil {
-			f.Close()
+	if fileEntry == nil {
+		return nil, nil
+	}
+	if fileEntry.EnvType != fs.streamCreator.envType {
+		return nil, fmt.Errorf("filename: %s has env %d not equal to FS env %d",
+			name, fileEntry.EnvType, fs.streamCreator.envType)
+	}
+	settings := &enginepbccl.EncryptionSettings{}
+	if err := protoutil.Unmarshal(fileEntry.EncryptionSettings, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Stat implements vfs.FS.Stat. Files encrypted with AES-GCM are larger than their plaintext,
+// so the size of their plaintext is cached once they are complete, and they are opened to
+// determine it otherwise.
+func (fs *encryptedFS) Stat(name string) (os.FileInfo, error) {
+	if fileEntry := fs.fileRegistry.GetFileEntry(name); fileEntry == nil ||
+		fileEntry.EnvType != fs.streamCreator.envType {
+		return fs.FS.Stat(name)
+	}
+	settings, err := fs.getSettings(name)
+	if err != nil {
+		return nil, err
+	}
+	if !isGCMEncryptionType(settings.EncryptionType) {
+		return fs.FS.Stat(name)
+	}
+	if size, ok := fs.getGCMSize(name, settings.Nonce); ok {
+		fi, err := fs.FS.Stat(name)
+		if err != nil {
 			return nil, err
 		}
+		return gcmFileInfo{FileInfo: fi, size: size}, nil
 	}
-	stream, err := fs.streamCreator.CreateExisting(settings)
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gf, ok := f.(*gcmFile)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected file type %T for %s", f, name)
+	}
+	fi, err := gf.File.Stat()
 	if err != nil {
-		f.Close()
 		return nil, err
 	}
-	return &encryptedFile{File: f, stream: stream}, nil
+	size, complete, err := gf.logicalSize()
+	if err != nil {
+		return nil, err
+	}
+	if complete {
+		fs.cacheGCMSize(name, settings.Nonce, size)
+	}
+	return gcmFileInfo{FileInfo: fi, size: size}, nil
 }
 
 // Remove implements vfs.FS.Remove.
@@ -187,6 +352,7 @@ func (fs *encryptedFS) Remove(name string) error {
 	if err := fs.FS.Remove(name); err != nil {
 		return err
 	}
+	fs.evictGCMSize(name)
 	return fs.fileRegistry.MaybeDeleteEntry(name)
 }
 
@@ -220,6 +386,8 @@ func (fs *encryptedFS) Rename(oldname, newname string) error {
 	if err := fs.FS.Rename(oldname, newname); err != nil {
 		return err
 	}
+	fs.evictGCMSize(oldname)
+	fs.evictGCMSize(newname)
 	// Remove the old name's metadata.
 	return fs.fileRegistry.MaybeDeleteEntry(oldname)
 }
@@ -315,6 +483,7 @@ func newEncryptedEnv(
 		fs:                fs,
 		activeKeyFilename: options.KeyFiles.CurrentKey,
 		oldKeyFilename:    options.KeyFiles.OldKey,
+		cipherMode:        options.CipherMode,
 	}
 	if err := storeKeyManager.Load(context.TODO()); err != nil {
 		return nil, err
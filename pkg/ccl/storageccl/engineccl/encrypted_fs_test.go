@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/vfs"
 	"github.com/stretchr/testify/require"
@@ -221,6 +222,163 @@ func TestEncryptedFSUnencryptedFiles(t *testing.T) {
 	}
 }
 
+// TestEncryptedFSMixedCipherModes verifies that files encrypted with AES-CTR
+// remain readable after the active key switches to AES-GCM, and vice versa.
+func TestEncryptedFSMixedCipherModes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	require.NoError(t, memFS.MkdirAll("/foo", os.ModeDir))
+
+	fileRegistry := &storage.PebbleFileRegistry{FS: memFS, DBDir: "/foo"}
+	require.NoError(t, fileRegistry.Load())
+
+	keyManager := &testKeyManager{keys: make(map[string]*enginepbccl.SecretKey)}
+	for id, encType := range map[string]enginepbccl.EncryptionType{
+		"ctr": enginepbccl.EncryptionType_AES128_CTR,
+		"gcm": enginepbccl.EncryptionType_AES128_GCM,
+	} {
+		key, err := generateKey(encType)
+		require.NoError(t, err)
+		key.Info.KeyId = id
+		keyManager.keys[id] = key
+	}
+	streamCreator := &FileCipherStreamCreator{keyManager: keyManager, envType: enginepb.EnvType_Store}
+	fs := &encryptedFS{FS: memFS, fileRegistry: fileRegistry, streamCreator: streamCreator}
+
+	type file struct {
+		keyID string
+		data  []byte
+	}
+	files := map[string]file{}
+	for i, keyID := range []string{"ctr", "gcm", "ctr", "gcm"} {
+		keyManager.activeID = keyID
+		name := fmt.Sprintf("/foo/file%d", i)
+		data := bytes.Repeat([]byte(name), 10000*(i+1))
+		f, err := fs.Create(name)
+		require.NoError(t, err)
+		// Files encrypted with AES-CTR are encrypted in place, so write a copy
+		// of the data.
+		_, err = f.Write(append([]byte(nil), data...))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		files[name] = file{keyID: keyID, data: data}
+
+		settings := &enginepbccl.EncryptionSettings{}
+		require.NoError(t, protoutil.Unmarshal(fileRegistry.GetFileEntry(name).EncryptionSettings, settings))
+		require.Equal(t, keyManager.keys[keyID].Info.EncryptionType, settings.EncryptionType)
+		require.Equal(t, keyID, settings.KeyId)
+	}
+
+	for name, file := range files {
+		data := file.data
+		fi, err := fs.Stat(name)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), fi.Size())
+
+		f, err := fs.Open(name)
+		require.NoError(t, err)
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, data, b)
+		require.NoError(t, f.Close())
+
+		// The ciphertext of the file doesn't contain the plaintext, and the
+		// ciphertext of files encrypted with AES-GCM is authenticated.
+		f, err = memFS.Open(name)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		require.False(t, bytes.Contains(raw, []byte(name)))
+		raw[len(raw)/2] ^= 1
+		f, err = memFS.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(raw)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		f, err = fs.Open(name)
+		require.NoError(t, err)
+		b, err = io.ReadAll(f)
+		require.NoError(t, f.Close())
+		if file.keyID == "gcm" {
+			require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+		} else {
+			require.NoError(t, err)
+			require.NotEqual(t, data, b)
+		}
+	}
+}
+
+// TestEncryptedFSGCMSizeCache verifies that the logical sizes of files
+// encrypted with AES-GCM are cached once they are complete, and that the
+// cached sizes don't outlive their files.
+func TestEncryptedFSGCMSizeCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	require.NoError(t, memFS.MkdirAll("/foo", os.ModeDir))
+	fileRegistry := &storage.PebbleFileRegistry{FS: memFS, DBDir: "/foo"}
+	require.NoError(t, fileRegistry.Load())
+	key, err := generateKey(enginepbccl.EncryptionType_AES128_GCM)
+	require.NoError(t, err)
+	keyManager := &testKeyManager{
+		keys:     map[string]*enginepbccl.SecretKey{key.Info.KeyId: key},
+		activeID: key.Info.KeyId,
+	}
+	streamCreator := &FileCipherStreamCreator{keyManager: keyManager, envType: enginepb.EnvType_Store}
+	fs := &encryptedFS{FS: memFS, fileRegistry: fileRegistry, streamCreator: streamCreator}
+
+	fileID := func(name string) []byte {
+		settings, err := fs.getSettings(name)
+		require.NoError(t, err)
+		return settings.Nonce
+	}
+	requireSize := func(name string, size int, cached bool) {
+		t.Helper()
+		_, ok := fs.getGCMSize(name, fileID(name))
+		require.Equal(t, cached, ok)
+		fi, err := fs.Stat(name)
+		require.NoError(t, err)
+		require.Equal(t, int64(size), fi.Size())
+	}
+	create := func(name string, size int, sync bool) vfs.File {
+		f, err := fs.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(make([]byte, size))
+		require.NoError(t, err)
+		if sync {
+			require.NoError(t, f.Sync())
+		}
+		return f
+	}
+
+	// The size of a file is cached once it's closed.
+	f := create("/foo/a", 100000, true /* sync */)
+	requireSize("/foo/a", 100000, false /* cached */)
+	require.NoError(t, f.Close())
+	requireSize("/foo/a", 100000, true /* cached */)
+
+	// The cached size is removed along with the file, and doesn't apply to a
+	// new file with the same name.
+	require.NoError(t, fs.Remove("/foo/a"))
+	_, ok := fs.getGCMSize("/foo/a", nil)
+	require.False(t, ok)
+	require.NoError(t, create("/foo/a", 10, false /* sync */).Close())
+	requireSize("/foo/a", 10, true /* cached */)
+	fs.gcmSizes.m["/foo/a"] = gcmSize{size: 20}
+	requireSize("/foo/a", 10, false /* cached */)
+	requireSize("/foo/a", 10, true /* cached */)
+
+	// A renamed file is cached under its new name once it's stat'ed.
+	require.NoError(t, fs.Rename("/foo/a", "/foo/b"))
+	_, ok = fs.getGCMSize("/foo/a", nil)
+	require.False(t, ok)
+	requireSize("/foo/b", 10, false /* cached */)
+	requireSize("/foo/b", 10, true /* cached */)
+}
+
 // Minimal test that creates an encrypted Pebble that exercises creation and reading of encrypted
 // files, rereading data after reopening the engine, and stats code.
 func TestPebbleEncryption(t *testing.T) {
@@ -417,6 +575,146 @@ func TestPebbleEncryption2(t *testing.T) {
 	addKeyAndValidate("d", "d", "plain", "16v2.key")
 }
 
+// TestPebbleEncryptionCipherModeChange verifies that a store can be reopened
+// with another cipher mode while keeping the same store key. The files
+// written under the previous cipher mode, including the data keys registry
+// which is encrypted with the store key, remain readable.
+func TestPebbleEncryptionCipherModeChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	writeToFile(t, memFS, "16.key", []byte("111111111111111111111111111111111234567890123456"))
+
+	var keys []string
+	openAndWrite := func(cipherMode baseccl.EncryptionCipherMode, key string) {
+		encOptions := baseccl.EncryptionOptions{
+			KeySource: baseccl.EncryptionKeySource_KeyFiles,
+			KeyFiles: &baseccl.EncryptionKeyFiles{
+				CurrentKey: "16.key",
+				OldKey:     "plain",
+			},
+			DataKeyRotationPeriod: 1000,
+			CipherMode:            cipherMode,
+		}
+		encOptionsBytes, err := protoutil.Marshal(&encOptions)
+		require.NoError(t, err)
+
+		opts := storage.DefaultPebbleOptions()
+		opts.FS = memFS
+		opts.Cache = pebble.NewCache(1 << 20)
+		defer opts.Cache.Unref()
+
+		db, err := storage.NewPebble(
+			context.Background(),
+			storage.PebbleConfig{
+				StorageConfig: base.StorageConfig{
+					Settings:          cluster.MakeTestingClusterSettings(),
+					Attrs:             roachpb.Attributes{},
+					MaxSize:           512 << 20,
+					UseFileRegistry:   true,
+					EncryptionOptions: encOptionsBytes,
+				},
+				Opts: opts,
+			})
+		require.NoError(t, err)
+		defer db.Close()
+
+		stats, err := db.GetEnvStats()
+		require.NoError(t, err)
+		expType := enginepbccl.EncryptionType_AES128_CTR
+		if cipherMode == baseccl.EncryptionCipherMode_GCM {
+			expType = enginepbccl.EncryptionType_AES128_GCM
+		}
+		require.Equal(t, int32(expType), stats.EncryptionType)
+
+		for _, k := range keys {
+			require.Equal(t, []byte(k), storageutils.MVCCGetRaw(t, db, storageutils.PointKey(k, 0)))
+		}
+		batch := db.NewUnindexedBatch(true /* writeOnly */)
+		defer batch.Close()
+		require.NoError(t, batch.PutUnversioned(roachpb.Key(key), []byte(key)))
+		require.NoError(t, batch.Commit(true))
+		require.NoError(t, db.Flush())
+		keys = append(keys, key)
+	}
+
+	openAndWrite(baseccl.EncryptionCipherMode_CTR, "a")
+	openAndWrite(baseccl.EncryptionCipherMode_GCM, "b")
+	openAndWrite(baseccl.EncryptionCipherMode_CTR, "c")
+	openAndWrite(baseccl.EncryptionCipherMode_GCM, "d")
+}
+
+// TestPebbleEncryptionGCMCrashRecovery verifies that a store whose files are
+// encrypted with AES-GCM can be reopened after a crash, even though its WAL
+// then doesn't end with a final chunk.
+func TestPebbleEncryptionGCMCrashRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewStrictMem()
+	f, err := memFS.Create("16.key")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(keyFile128))
+	require.NoError(t, err)
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+	dir, err := memFS.OpenDir("")
+	require.NoError(t, err)
+	require.NoError(t, dir.Sync())
+	require.NoError(t, dir.Close())
+
+	encOptions := baseccl.EncryptionOptions{
+		KeySource: baseccl.EncryptionKeySource_KeyFiles,
+		KeyFiles: &baseccl.EncryptionKeyFiles{
+			CurrentKey: "16.key",
+			OldKey:     "plain",
+		},
+		DataKeyRotationPeriod: 1000,
+		CipherMode:            baseccl.EncryptionCipherMode_GCM,
+	}
+	encOptionsBytes, err := protoutil.Marshal(&encOptions)
+	require.NoError(t, err)
+	open := func() storage.Engine {
+		opts := storage.DefaultPebbleOptions()
+		opts.FS = memFS
+		opts.Cache = pebble.NewCache(1 << 20)
+		defer opts.Cache.Unref()
+		db, err := storage.NewPebble(
+			context.Background(),
+			storage.PebbleConfig{
+				StorageConfig: base.StorageConfig{
+					Settings:          cluster.MakeTestingClusterSettings(),
+					Attrs:             roachpb.Attributes{},
+					MaxSize:           512 << 20,
+					UseFileRegistry:   true,
+					EncryptionOptions: encOptionsBytes,
+				},
+				Opts: opts,
+			})
+		require.NoError(t, err)
+		return db
+	}
+
+	db := open()
+	for _, k := range []string{"a", "b"} {
+		batch := db.NewUnindexedBatch(true /* writeOnly */)
+		require.NoError(t, batch.PutUnversioned(roachpb.Key(k), []byte(k)))
+		require.NoError(t, batch.Commit(true /* sync */))
+		batch.Close()
+	}
+	// Crash: nothing written from now on, including the final chunk of the WAL
+	// which is written when the store is closed, survives.
+	memFS.SetIgnoreSyncs(true)
+	db.Close()
+	memFS.ResetToSyncedState()
+	memFS.SetIgnoreSyncs(false)
+
+	db = open()
+	defer db.Close()
+	for _, k := range []string{"a", "b"} {
+		require.Equal(t, []byte(k), storageutils.MVCCGetRaw(t, db, storageutils.PointKey(k, 0)))
+	}
+}
+
 func TestCanRegistryElide(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -0,0 +1,615 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+// AES-GCM encryption.
+//
+// Unlike AES-CTR, AES-GCM can't transform the data of a file in place at
+// arbitrary offsets: every piece of ciphertext comes with an authentication
+// tag, which must be verified when the ciphertext is decrypted. Files
+// encrypted with AES-GCM are therefore split into chunks of up to
+// gcmChunkSize bytes of plaintext, each of which is stored as:
+//
+//   nonce (12 bytes) | flag and plaintext length (4 bytes) | ciphertext | tag (16 bytes)
+//
+// The nonce is randomly generated for every chunk. The additional
+// authenticated data of a chunk is made of the file ID (a random identifier
+// stored as the nonce of the file's EncryptionSettings), the logical offset of
+// the chunk in the file, its length, and whether it's the final chunk of the
+// file, so that chunks can't be modified, reordered, moved across files or
+// removed from the end of a file without being detected. As in the STREAM
+// construction, the final chunk is written when the file is closed, and may be
+// empty; it's also flagged in the header, so that it can be located.
+//
+// A file which doesn't end with its final chunk was either truncated or not
+// closed, e.g. because of a crash while it was being written. Reads past its
+// last complete chunk return io.ErrUnexpectedEOF rather than io.EOF, which is
+// also how Pebble detects the torn tail of a WAL or manifest. A crash can also
+// leave a zeroed or partially written tail after the last synced chunk, so the
+// file is considered to end before the first chunk with an invalid header, and
+// a last chunk which fails authentication is ignored as well. Other chunks
+// which fail authentication, and any data following the final chunk, are
+// reported as corruption.
+//
+// Files are append-only, so a chunk can't be rewritten once it's written.
+// Chunks are sealed and written once they are full, except when the file is
+// synced, in which case the partial chunk is written as is and writes continue
+// in a new chunk. Files which are only synced once they are fully written (as
+// is the case for sstables, since SyncTo only syncs the full chunks) have a
+// regular layout in which all the chunks but the final one are full, which
+// allows locating the chunk containing any logical offset without reading the
+// file. For other files (e.g. WALs), the first read which detects that the
+// layout is irregular builds an index of the file's chunks by scanning their
+// headers.
+//
+// With random 96-bit nonces, a key should not be used to seal more than 2^32
+// chunks (i.e. 128TiB of data); the rotation of data keys ensures that this
+// doesn't happen in practice.
+
+const (
+	// gcmChunkSize is the maximum size of the plaintext of a chunk.
+	gcmChunkSize = 32 << 10
+	// gcmFileIDSize is the size of the file ID.
+	gcmFileIDSize = 12
+	gcmNonceSize  = 12
+	gcmHeaderSize = gcmNonceSize + 4
+	gcmTagSize    = 16
+	// gcmFinalChunkFlag is set in the length of the header of the final chunk
+	// of a file.
+	gcmFinalChunkFlag = 1 << 31
+	// gcmChunkOverhead is the number of bytes added to the plaintext of a chunk.
+	gcmChunkOverhead = gcmHeaderSize + gcmTagSize
+	// gcmFullChunkSize is the physical size of a full chunk.
+	gcmFullChunkSize = gcmChunkSize + gcmChunkOverhead
+	// gcmAdditionalDataSize is the size of the additional authenticated data
+	// of a chunk: the file ID, the logical offset and the length of the chunk,
+	// and whether it's the final chunk.
+	gcmAdditionalDataSize = gcmFileIDSize + 8 + 4 + 1
+)
+
+// errGCMLayout is returned when a chunk doesn't have the expected length, or
+// isn't the final chunk when expected to be, or vice versa.
+var errGCMLayout = errors.New("unexpected AES-GCM chunk length")
+
+var gcmChunkPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, gcmFullChunkSize)
+		return &b
+	},
+}
+
+// isGCMEncryptionType returns whether the encryption type uses AES-GCM.
+func isGCMEncryptionType(encType enginepbccl.EncryptionType) bool {
+	switch encType {
+	case enginepbccl.EncryptionType_AES128_GCM,
+		enginepbccl.EncryptionType_AES192_GCM,
+		enginepbccl.EncryptionType_AES256_GCM:
+		return true
+	}
+	return false
+}
+
+// newGCMCipher returns the AEAD for the given AES-GCM encryption type. As with
+// newCTRBlockCipherStream, only the bytes of the key are used.
+func newGCMCipher(encType enginepbccl.EncryptionType, key *enginepbccl.SecretKey) (cipher.AEAD, error) {
+	if !isGCMEncryptionType(encType) {
+		return nil, errors.Newf("unknown EncryptionType: %d", encType)
+	}
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+}
+
+// gcmChunk describes a chunk of a file encrypted with AES-GCM.
+type gcmChunk struct {
+	logicalOffset  int64
+	physicalOffset int64
+	length         int
+	final          bool
+}
+
+func (c gcmChunk) logicalEnd() int64 {
+	return c.logicalOffset + int64(c.length)
+}
+
+// gcmFile implements vfs.File for files encrypted with AES-GCM. The data
+// written to a file is only visible to its reads once the chunk containing it
+// is written, e.g. once the file is synced.
+type gcmFile struct {
+	vfs.File
+	aead     cipher.AEAD
+	fileID   [gcmFileIDSize]byte
+	writable bool
+	// onClose, if set, is called with the logical size of a writable file once
+	// it's closed.
+	onClose func(size int64)
+
+	readMu struct {
+		syncutil.Mutex
+		// rOffset is the logical offset of the next Read.
+		rOffset int64
+	}
+
+	mu struct {
+		syncutil.Mutex
+
+		// Fields used when writing the file.
+		//
+		// sealedSize and physicalSealedSize are the logical and physical sizes
+		// of the chunks written so far.
+		sealedSize         int64
+		physicalSealedSize int64
+		// pending is the plaintext of the chunk being written.
+		pending []byte
+		// synced is set once the file was synced, in which case closing it
+		// also syncs its final chunk.
+		synced bool
+		// sealBuf is a scratch buffer used to seal the pending chunk.
+		sealBuf []byte
+
+		// Fields used when reading the file.
+		//
+		// physicalSize is the size of the underlying file. It's loaded by the
+		// first read of a file which isn't being written.
+		physicalSize       int64
+		physicalSizeLoaded bool
+		// indexed is set once the layout of the file was found to be irregular,
+		// in which case index contains all the chunks of the file.
+		indexed bool
+		index   []gcmChunk
+	}
+}
+
+var _ vfs.File = (*gcmFile)(nil)
+
+func newGCMFile(f vfs.File, aead cipher.AEAD, fileID []byte, writable bool) (*gcmFile, error) {
+	if len(fileID) != gcmFileIDSize {
+		return nil, errors.Newf("unexpected AES-GCM file ID length: %d", len(fileID))
+	}
+	gf := &gcmFile{File: f, aead: aead, writable: writable}
+	copy(gf.fileID[:], fileID)
+	return gf, nil
+}
+
+// additionalData returns the additional authenticated data of the chunk with
+// the specified logical offset and length.
+func (f *gcmFile) additionalData(buf *[gcmAdditionalDataSize]byte, c gcmChunk) []byte {
+	copy(buf[:gcmFileIDSize], f.fileID[:])
+	binary.BigEndian.PutUint64(buf[gcmFileIDSize:], uint64(c.logicalOffset))
+	binary.BigEndian.PutUint32(buf[gcmFileIDSize+8:], uint32(c.length))
+	buf[gcmFileIDSize+12] = 0
+	if c.final {
+		buf[gcmFileIDSize+12] = 1
+	}
+	return buf[:]
+}
+
+// chunkHeaderLength returns the length field of the header of the chunk.
+func chunkHeaderLength(c gcmChunk) uint32 {
+	if c.final {
+		return uint32(c.length) | gcmFinalChunkFlag
+	}
+	return uint32(c.length)
+}
+
+// Write implements io.Writer.
+func (f *gcmFile) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(p) > 0 {
+		k := gcmChunkSize - len(f.mu.pending)
+		if k > len(p) {
+			k = len(p)
+		}
+		f.mu.pending = append(f.mu.pending, p[:k]...)
+		p = p[k:]
+		if len(f.mu.pending) == gcmChunkSize {
+			if err := f.sealPendingLocked(false /* final */); err != nil {
+				return n, err
+			}
+		}
+		n += k
+	}
+	return n, nil
+}
+
+// sealPendingLocked seals the pending chunk and writes it. An empty pending
+// chunk is only written if it's the final chunk of the file.
+func (f *gcmFile) sealPendingLocked(final bool) error {
+	c := gcmChunk{
+		logicalOffset:  f.mu.sealedSize,
+		physicalOffset: f.mu.physicalSealedSize,
+		length:         len(f.mu.pending),
+		final:          final,
+	}
+	if c.length == 0 && !final {
+		return nil
+	}
+	if cap(f.mu.sealBuf) < gcmFullChunkSize {
+		f.mu.sealBuf = make([]byte, 0, gcmFullChunkSize)
+	}
+	buf := f.mu.sealBuf[:gcmHeaderSize]
+	if _, err := rand.Read(buf[:gcmNonceSize]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(buf[gcmNonceSize:], chunkHeaderLength(c))
+	var ad [gcmAdditionalDataSize]byte
+	buf = f.aead.Seal(buf, buf[:gcmNonceSize], f.mu.pending, f.additionalData(&ad, c))
+	if _, err := f.File.Write(buf); err != nil {
+		return err
+	}
+	if f.mu.indexed {
+		f.mu.index = append(f.mu.index, c)
+	}
+	f.mu.sealedSize += int64(c.length)
+	f.mu.physicalSealedSize += int64(len(buf))
+	f.mu.pending = f.mu.pending[:0]
+	return nil
+}
+
+// Sync implements vfs.File.Sync.
+func (f *gcmFile) Sync() error {
+	if err := f.sealPendingForSync(); err != nil {
+		return err
+	}
+	return f.File.Sync()
+}
+
+// SyncData implements vfs.File.SyncData.
+func (f *gcmFile) SyncData() error {
+	if err := f.sealPendingForSync(); err != nil {
+		return err
+	}
+	return f.File.SyncData()
+}
+
+// sealPendingForSync seals the pending chunk before the file is synced.
+func (f *gcmFile) sealPendingForSync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.synced = true
+	return f.sealPendingLocked(false /* final */)
+}
+
+// SyncTo implements vfs.File.SyncTo. Only the chunks which were already
+// written are synced: sealing the pending chunk early would make the layout of
+// the file irregular.
+func (f *gcmFile) SyncTo(length int64) (fullSync bool, err error) {
+	f.mu.Lock()
+	physicalSize := f.mu.physicalSealedSize
+	hasPending := len(f.mu.pending) > 0
+	f.mu.synced = true
+	f.mu.Unlock()
+	fullSync, err = f.File.SyncTo(physicalSize)
+	return fullSync && !hasPending, err
+}
+
+// Close implements io.Closer. Closing a writable file writes its final chunk,
+// which is synced if the file was synced before: files are commonly synced
+// before being closed, and would otherwise look truncated after a crash.
+func (f *gcmFile) Close() error {
+	if !f.writable {
+		return f.File.Close()
+	}
+	f.mu.Lock()
+	err := f.sealPendingLocked(true /* final */)
+	size, synced := f.mu.sealedSize, f.mu.synced
+	f.mu.Unlock()
+	if err == nil && synced {
+		err = f.File.Sync()
+	}
+	err = errors.CombineErrors(err, f.File.Close())
+	if err == nil && f.onClose != nil {
+		f.onClose(size)
+	}
+	return err
+}
+
+// Preallocate implements vfs.File.Preallocate.
+func (f *gcmFile) Preallocate(offset, length int64) error {
+	start, end := gcmPhysicalRange(offset, length)
+	return f.File.Preallocate(start, end-start)
+}
+
+// Prefetch implements vfs.File.Prefetch.
+func (f *gcmFile) Prefetch(offset, length int64) error {
+	start, end := gcmPhysicalRange(offset, length)
+	return f.File.Prefetch(start, end-start)
+}
+
+// gcmPhysicalRange returns the physical range of the chunks containing the
+// provided logical range, assuming a regular layout.
+func gcmPhysicalRange(offset, length int64) (start, end int64) {
+	start = offset / gcmChunkSize * gcmFullChunkSize
+	end = (offset + length + gcmChunkSize - 1) / gcmChunkSize * gcmFullChunkSize
+	return start, end
+}
+
+// gcmFileInfo overrides the size of the underlying file.
+type gcmFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+// Size implements os.FileInfo.
+func (fi gcmFileInfo) Size() int64 {
+	return fi.size
+}
+
+// Stat implements vfs.File.Stat. The size of the file is the size of its
+// plaintext.
+func (f *gcmFile) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size, _, err := f.logicalSize()
+	if err != nil {
+		return nil, err
+	}
+	return gcmFileInfo{FileInfo: fi, size: size}, nil
+}
+
+// logicalSize returns the size of the plaintext of the file, and whether the
+// file is complete, i.e. whether it ends with its final chunk. The size of a
+// file which isn't complete is the size of its complete chunks: such a file
+// can be a WAL which was being written when the process crashed, so its size
+// must be readable.
+func (f *gcmFile) logicalSize() (size int64, complete bool, _ error) {
+	if f.writable {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.mu.sealedSize + int64(len(f.mu.pending)), false, nil
+	}
+	// Reading past the end of the file returns its last complete chunk, which
+	// also verifies that the layout of the file is what it seems to be.
+	buf := gcmChunkPool.Get().(*[]byte)
+	defer gcmChunkPool.Put(buf)
+	c, _, err := f.readChunk(math.MaxInt64, *buf)
+	if err != nil {
+		return 0, false, err
+	}
+	return c.logicalEnd(), c.final, nil
+}
+
+// Read implements io.Reader.
+func (f *gcmFile) Read(p []byte) (n int, err error) {
+	f.readMu.Lock()
+	defer f.readMu.Unlock()
+	n, err = f.ReadAt(p, f.readMu.rOffset)
+	f.readMu.rOffset += int64(n)
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt. Reads past the end of a file which doesn't
+// end with its final chunk return io.ErrUnexpectedEOF.
+func (f *gcmFile) ReadAt(p []byte, off int64) (n int, err error) {
+	buf := gcmChunkPool.Get().(*[]byte)
+	defer gcmChunkPool.Put(buf)
+	for len(p) > 0 {
+		c, plaintext, err := f.readChunk(off, *buf)
+		if err != nil {
+			return n, err
+		}
+		if off >= c.logicalEnd() {
+			// The offset is past the end of the file, and c is its last complete
+			// chunk, if any. The error isn't wrapped since Pebble checks for it
+			// by equality.
+			if !c.final {
+				return n, io.ErrUnexpectedEOF
+			}
+			return n, io.EOF
+		}
+		k := copy(p, plaintext[off-c.logicalOffset:])
+		p = p[k:]
+		off += int64(k)
+		n += k
+	}
+	return n, nil
+}
+
+// readChunk reads and decrypts the chunk containing the provided logical
+// offset into buf. If the offset is past the end of the file, the last
+// complete chunk of the file is read instead, or an empty non-final chunk is
+// returned if there is none.
+func (f *gcmFile) readChunk(off int64, buf []byte) (c gcmChunk, plaintext []byte, err error) {
+	for {
+		var ok, indexed bool
+		c, ok, indexed, err = f.findChunk(off)
+		if err != nil || !ok {
+			return gcmChunk{}, nil, err
+		}
+		plaintext, err = f.openChunk(c, buf)
+		if err == nil {
+			return c, plaintext, nil
+		}
+		if indexed {
+			f.mu.Lock()
+			torn := f.dropTornTailLocked(c)
+			f.mu.Unlock()
+			if torn {
+				continue
+			}
+			return c, nil, errors.Mark(errors.Wrapf(err,
+				"failed to decrypt chunk at offset %d of %s", c.physicalOffset, f.name()),
+				pebble.ErrCorruption)
+		}
+		// The chunk which was located assuming a regular layout couldn't be
+		// decrypted, so the file must have an irregular layout.
+		f.mu.Lock()
+		err = f.buildIndexLocked()
+		f.mu.Unlock()
+		if err != nil {
+			return c, nil, err
+		}
+	}
+}
+
+// findChunk returns the chunk containing the provided logical offset or, if
+// the offset is past the end of the file, its last complete chunk. It returns
+// false if the file has no complete chunk. It also returns whether the chunk
+// was found in the index of the file, rather than by assuming a regular
+// layout.
+func (f *gcmFile) findChunk(off int64) (c gcmChunk, ok bool, indexed bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	physicalSize, err := f.physicalSizeLocked()
+	if err != nil {
+		return gcmChunk{}, false, false, err
+	}
+	if !f.mu.indexed {
+		if physicalSize == 0 {
+			return gcmChunk{}, false, false, nil
+		}
+		i := off / gcmChunkSize
+		if last := (physicalSize - 1) / gcmFullChunkSize; i > last {
+			i = last
+		}
+		c = gcmChunk{logicalOffset: i * gcmChunkSize, physicalOffset: i * gcmFullChunkSize}
+		switch remaining := physicalSize - c.physicalOffset; {
+		case remaining >= gcmFullChunkSize:
+			// All the chunks but the final one are full. A full chunk can also be
+			// the last one if the file isn't complete.
+			c.length = gcmChunkSize
+			return c, true, false, nil
+		case remaining >= gcmChunkOverhead:
+			c.length = int(remaining - gcmChunkOverhead)
+			c.final = true
+			return c, true, false, nil
+		}
+		// The last chunk is too small to be valid, so the file must have an
+		// irregular layout or be truncated.
+		if err := f.buildIndexLocked(); err != nil {
+			return gcmChunk{}, false, false, err
+		}
+	}
+	index := f.mu.index
+	if len(index) == 0 {
+		return gcmChunk{}, false, true, nil
+	}
+	i := sort.Search(len(index), func(i int) bool {
+		return index[i].logicalEnd() > off
+	})
+	if i == len(index) {
+		i--
+	}
+	return index[i], true, true, nil
+}
+
+// openChunk reads the chunk into buf and returns its plaintext.
+func (f *gcmFile) openChunk(c gcmChunk, buf []byte) ([]byte, error) {
+	buf = buf[:c.length+gcmChunkOverhead]
+	if _, err := f.File.ReadAt(buf, c.physicalOffset); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(buf[gcmNonceSize:gcmHeaderSize]) != chunkHeaderLength(c) {
+		return nil, errGCMLayout
+	}
+	var ad [gcmAdditionalDataSize]byte
+	ciphertext := buf[gcmHeaderSize:]
+	return f.aead.Open(ciphertext[:0], buf[:gcmNonceSize], ciphertext, f.additionalData(&ad, c))
+}
+
+func (f *gcmFile) physicalSizeLocked() (int64, error) {
+	if f.writable {
+		return f.mu.physicalSealedSize, nil
+	}
+	if !f.mu.physicalSizeLoaded {
+		fi, err := f.File.Stat()
+		if err != nil {
+			return 0, err
+		}
+		f.mu.physicalSize = fi.Size()
+		f.mu.physicalSizeLoaded = true
+	}
+	return f.mu.physicalSize, nil
+}
+
+// dropTornTailLocked removes the chunk from the index if it's the last
+// chunk of the file, in which case it failed authentication because it's the
+// torn tail of the file, and returns whether it did.
+func (f *gcmFile) dropTornTailLocked(c gcmChunk) bool {
+	index := f.mu.index
+	if len(index) == 0 || index[len(index)-1] != c {
+		return false
+	}
+	f.mu.index = index[:len(index)-1]
+	return true
+}
+
+// buildIndexLocked builds the index of the chunks of the file by scanning
+// their headers. The scan stops at a truncated chunk or a chunk with an
+// invalid header, e.g. because the tail of the file is zeroed, so that reads
+// past the preceding chunk return io.ErrUnexpectedEOF.
+func (f *gcmFile) buildIndexLocked() error {
+	if f.mu.indexed {
+		return nil
+	}
+	physicalSize, err := f.physicalSizeLocked()
+	if err != nil {
+		return err
+	}
+	var index []gcmChunk
+	var header [gcmHeaderSize]byte
+	var c gcmChunk
+	for c.physicalOffset < physicalSize {
+		if len(index) > 0 && index[len(index)-1].final {
+			return errors.Mark(errors.Newf("unexpected data after the final AES-GCM chunk at offset %d of %s",
+				c.physicalOffset, f.name()), pebble.ErrCorruption)
+		}
+		if physicalSize-c.physicalOffset < gcmChunkOverhead {
+			break
+		}
+		if _, err := f.File.ReadAt(header[:], c.physicalOffset); err != nil {
+			return err
+		}
+		length := binary.BigEndian.Uint32(header[gcmNonceSize:])
+		c.length = int(length &^ gcmFinalChunkFlag)
+		c.final = length&gcmFinalChunkFlag != 0
+		if c.length > gcmChunkSize || (c.length == 0 && !c.final) ||
+			c.physicalOffset+int64(c.length+gcmChunkOverhead) > physicalSize {
+			break
+		}
+		index = append(index, c)
+		c.logicalOffset += int64(c.length)
+		c.physicalOffset += int64(c.length + gcmChunkOverhead)
+	}
+	f.mu.index = index
+	f.mu.indexed = true
+	return nil
+}
+
+// name returns the name of the file, for error messages.
+func (f *gcmFile) name() string {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return "file"
+	}
+	return fi.Name()
+}
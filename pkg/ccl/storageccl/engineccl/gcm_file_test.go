@@ -0,0 +1,353 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCMFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewTestRand()
+	memFS := vfs.NewMem()
+	key, err := generateKey(enginepbccl.EncryptionType_AES256_GCM)
+	require.NoError(t, err)
+	aead, err := newGCMCipher(key.Info.EncryptionType, key)
+	require.NoError(t, err)
+
+	for i := 0; i < 30; i++ {
+		// Files which are only synced once they are closed have a regular layout,
+		// while files which are synced along the way don't.
+		syncProb := []float64{0, 0.2, 0.8}[i%3]
+		t.Run(fmt.Sprintf("%d/sync=%.1f", i, syncProb), func(t *testing.T) {
+			name := fmt.Sprintf("file%d", i)
+			fileID := make([]byte, gcmFileIDSize)
+			rng.Read(fileID)
+
+			open := func(fileID []byte) *gcmFile {
+				f, err := memFS.Open(name)
+				require.NoError(t, err)
+				r, err := newGCMFile(f, aead, fileID, false /* writable */)
+				require.NoError(t, err)
+				return r
+			}
+
+			f, err := memFS.Create(name)
+			require.NoError(t, err)
+			w, err := newGCMFile(f, aead, fileID, true /* writable */)
+			require.NoError(t, err)
+			var data []byte
+			for j, n := 0, rng.Intn(20); j < n; j++ {
+				p := make([]byte, rng.Intn(3*gcmChunkSize))
+				rng.Read(p)
+				data = append(data, p...)
+				_, err := w.Write(p)
+				require.NoError(t, err)
+				if rng.Float64() < syncProb {
+					require.NoError(t, w.Sync())
+					// The synced data is visible to readers of the file, which can't
+					// tell it apart from a truncated file until it's closed.
+					r := open(fileID)
+					b, err := io.ReadAll(r)
+					require.Equal(t, io.ErrUnexpectedEOF, err)
+					require.Equal(t, data, b)
+					require.NoError(t, r.Close())
+				}
+				fi, err := w.Stat()
+				require.NoError(t, err)
+				require.Equal(t, int64(len(data)), fi.Size())
+			}
+			require.NoError(t, w.Close())
+
+			r := open(fileID)
+			defer r.Close()
+			fi, err := r.Stat()
+			require.NoError(t, err)
+			require.Equal(t, int64(len(data)), fi.Size())
+			if syncProb == 0 {
+				require.False(t, r.mu.indexed)
+			}
+
+			// Random reads, which may extend past the end of the file.
+			for j := 0; j < 50 && len(data) > 0; j++ {
+				off := rng.Intn(len(data))
+				b := make([]byte, rng.Intn(len(data)-off+100))
+				n, err := r.ReadAt(b, int64(off))
+				exp := data[off:]
+				if len(b) < len(exp) {
+					exp = exp[:len(b)]
+				}
+				require.Equal(t, exp, b[:n])
+				if n < len(b) {
+					require.Equal(t, io.EOF, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+			b, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, b))
+
+			if len(data) == 0 {
+				return
+			}
+			// The file can't be read as another file.
+			otherID := make([]byte, gcmFileIDSize)
+			rng.Read(otherID)
+			other := open(otherID)
+			defer other.Close()
+			_, err = io.ReadAll(other)
+			require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+
+			// Tampering with any byte of the file is detected. Tampering with the
+			// header of a chunk can make the file look truncated, in which case the
+			// data which precedes the chunk can still be read.
+			f, err = memFS.Open(name)
+			require.NoError(t, err)
+			raw, err := io.ReadAll(f)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			raw[rng.Intn(len(raw))] ^= 1 << rng.Intn(8)
+			f, err = memFS.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write(raw)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			tampered := open(fileID)
+			defer tampered.Close()
+			b, err = io.ReadAll(tampered)
+			if err == io.ErrUnexpectedEOF {
+				require.Equal(t, data[:len(b)], b)
+			} else {
+				require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+			}
+		})
+	}
+}
+
+func TestGCMFileTruncated(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	key, err := generateKey(enginepbccl.EncryptionType_AES128_GCM)
+	require.NoError(t, err)
+	aead, err := newGCMCipher(key.Info.EncryptionType, key)
+	require.NoError(t, err)
+	fileID := make([]byte, gcmFileIDSize)
+
+	write := func(raw []byte) {
+		f, err := memFS.Create("file")
+		require.NoError(t, err)
+		_, err = f.Write(raw)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	open := func() *gcmFile {
+		f, err := memFS.Open("file")
+		require.NoError(t, err)
+		r, err := newGCMFile(f, aead, fileID, false /* writable */)
+		require.NoError(t, err)
+		return r
+	}
+	// writeGCM writes the data to an encrypted file and returns its ciphertext.
+	writeGCM := func(data []byte) []byte {
+		f, err := memFS.Create("file")
+		require.NoError(t, err)
+		w, err := newGCMFile(f, aead, fileID, true /* writable */)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		f, err = memFS.Open("file")
+		require.NoError(t, err)
+		raw, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		return raw
+	}
+
+	// Two full chunks and a partial final one.
+	data := bytes.Repeat([]byte("a"), 2*gcmChunkSize+100)
+	raw := writeGCM(data)
+	require.Equal(t, 2*gcmFullChunkSize+100+gcmChunkOverhead, len(raw))
+
+	for _, tc := range []struct {
+		name    string
+		length  int
+		expSize int
+	}{
+		{name: "final chunk", length: len(raw) - 10, expSize: 2 * gcmChunkSize},
+		{name: "final chunk header", length: 2*gcmFullChunkSize + 5, expSize: 2 * gcmChunkSize},
+		{name: "chunk boundary", length: 2 * gcmFullChunkSize, expSize: 2 * gcmChunkSize},
+		{name: "middle chunk", length: gcmFullChunkSize + 1000, expSize: gcmChunkSize},
+		{name: "first chunk boundary", length: gcmFullChunkSize, expSize: gcmChunkSize},
+		{name: "empty", length: 0, expSize: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			write(raw[:tc.length])
+			r := open()
+			defer r.Close()
+
+			// The size of the file is the size of its complete chunks, which can
+			// still be read, but reads past them fail.
+			size, complete, err := r.logicalSize()
+			require.NoError(t, err)
+			require.Equal(t, int64(tc.expSize), size)
+			require.False(t, complete)
+			b, err := io.ReadAll(r)
+			require.Equal(t, io.ErrUnexpectedEOF, err)
+			require.Equal(t, data[:tc.expSize], b)
+			_, err = r.ReadAt(make([]byte, 10), int64(len(data)))
+			require.Equal(t, io.ErrUnexpectedEOF, err)
+		})
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		write(raw)
+		r := open()
+		defer r.Close()
+		size, complete, err := r.logicalSize()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), size)
+		require.True(t, complete)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, b)
+		require.False(t, r.mu.indexed)
+	})
+
+	t.Run("data after final chunk", func(t *testing.T) {
+		for _, extra := range [][]byte{
+			raw[:5],
+			raw[:gcmChunkOverhead+10],
+			raw[2*gcmFullChunkSize:],
+		} {
+			write(append(append([]byte(nil), raw...), extra...))
+			r := open()
+			_, err := io.ReadAll(r)
+			require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+			require.NoError(t, r.Close())
+		}
+	})
+
+	t.Run("empty final chunk", func(t *testing.T) {
+		// A file made of full chunks ends with an empty final chunk.
+		data := bytes.Repeat([]byte("b"), 2*gcmChunkSize)
+		raw := writeGCM(data)
+		require.Equal(t, 2*gcmFullChunkSize+gcmChunkOverhead, len(raw))
+		r := open()
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, b)
+		require.False(t, r.mu.indexed)
+		require.NoError(t, r.Close())
+
+		// Without it, the file is truncated.
+		write(raw[:2*gcmFullChunkSize])
+		r = open()
+		b, err = io.ReadAll(r)
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+		require.Equal(t, data, b)
+		require.NoError(t, r.Close())
+	})
+}
+
+func TestGCMFileTornTail(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	memFS := vfs.NewMem()
+	key, err := generateKey(enginepbccl.EncryptionType_AES128_GCM)
+	require.NoError(t, err)
+	aead, err := newGCMCipher(key.Info.EncryptionType, key)
+	require.NoError(t, err)
+	fileID := make([]byte, gcmFileIDSize)
+
+	// Write two records, syncing after each of them as a WAL would, without
+	// closing the file.
+	records := [][]byte{[]byte("first record"), []byte("second record")}
+	f, err := memFS.Create("wal")
+	require.NoError(t, err)
+	w, err := newGCMFile(f, aead, fileID, true /* writable */)
+	require.NoError(t, err)
+	for _, rec := range records {
+		_, err = w.Write(rec)
+		require.NoError(t, err)
+		require.NoError(t, w.Sync())
+	}
+	f, err = memFS.Open("wal")
+	require.NoError(t, err)
+	raw, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, w.File.Close())
+	firstLen := len(records[0]) + gcmChunkOverhead
+	require.Equal(t, firstLen+len(records[1])+gcmChunkOverhead, len(raw))
+
+	read := func(raw []byte) ([]byte, error) {
+		f, err := memFS.Create("file")
+		require.NoError(t, err)
+		_, err = f.Write(raw)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		f, err = memFS.Open("file")
+		require.NoError(t, err)
+		r, err := newGCMFile(f, aead, fileID, false /* writable */)
+		require.NoError(t, err)
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	// corrupt returns a copy of raw with the byte at offset i modified.
+	corrupt := func(i int) []byte {
+		b := append([]byte(nil), raw...)
+		b[i] ^= 0xff
+		return b
+	}
+	all := concat(records...)
+
+	for _, tc := range []struct {
+		name string
+		raw  []byte
+		exp  []byte
+	}{
+		{name: "short zeroed tail", raw: concat(raw, make([]byte, 4)), exp: all},
+		{name: "zeroed tail", raw: concat(raw, make([]byte, 100)), exp: all},
+		{name: "zeroed full chunk", raw: concat(raw, make([]byte, gcmFullChunkSize+10)), exp: all},
+		{name: "zeroed last chunk", raw: concat(raw[:firstLen], make([]byte, len(raw)-firstLen)), exp: records[0]},
+		{name: "zeroed last chunk header", raw: concat(raw[:firstLen], make([]byte, gcmHeaderSize), raw[firstLen+gcmHeaderSize:]), exp: records[0]},
+		{name: "torn last chunk", raw: corrupt(len(raw) - 1), exp: records[0]},
+		{name: "torn last chunk with zeroed tail", raw: concat(corrupt(len(raw)-1), make([]byte, 50)), exp: records[0]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The records before the torn tail can be read.
+			b, err := read(tc.raw)
+			require.Equal(t, io.ErrUnexpectedEOF, err)
+			require.Equal(t, tc.exp, b)
+		})
+	}
+
+	t.Run("corrupt chunk before the tail", func(t *testing.T) {
+		_, err := read(corrupt(firstLen - 1))
+		require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+	})
+}
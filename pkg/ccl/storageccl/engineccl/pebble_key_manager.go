@@ -14,9 +14,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -77,6 +80,7 @@ type StoreKeyManager struct {
 	fs                vfs.FS
 	activeKeyFilename string
 	oldKeyFilename    string
+	cipherMode        baseccl.EncryptionCipherMode
 
 	// Implementation. Both are not nil after a successful call to Load().
 	activeKey *enginepbccl.SecretKey
@@ -86,11 +90,11 @@ type StoreKeyManager struct {
 // Load must be called before calling other functions.
 func (m *StoreKeyManager) Load(ctx context.Context) error {
 	var err error
-	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename)
+	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename, m.cipherMode)
 	if err != nil {
 		return err
 	}
-	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename)
+	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename, m.cipherMode)
 	if err != nil {
 		return err
 	}
@@ -115,7 +119,9 @@ func (m *StoreKeyManager) GetKey(id string) (*enginepbccl.SecretKey, error) {
 	return nil, fmt.Errorf("store key ID %s was not found", id)
 }
 
-func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error) {
+func loadKeyFromFile(
+	fs vfs.FS, filename string, cipherMode baseccl.EncryptionCipherMode,
+) (*enginepbccl.SecretKey, error) {
 	now := kmTimeNow().Unix()
 	key := &enginepbccl.SecretKey{}
 	key.Info = &enginepbccl.KeyInfo{}
@@ -138,12 +144,19 @@ func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error)
 	}
 	// keyIDLength bytes for the ID, followed by the key.
 	keyLength := len(b) - keyIDLength
-	switch keyLength {
-	case 16:
+	gcm := cipherMode == baseccl.EncryptionCipherMode_GCM
+	switch {
+	case keyLength == 16 && gcm:
+		key.Info.EncryptionType = enginepbccl.EncryptionType_AES128_GCM
+	case keyLength == 16:
 		key.Info.EncryptionType = enginepbccl.EncryptionType_AES128_CTR
-	case 24:
+	case keyLength == 24 && gcm:
+		key.Info.EncryptionType = enginepbccl.EncryptionType_AES192_GCM
+	case keyLength == 24:
 		key.Info.EncryptionType = enginepbccl.EncryptionType_AES192_CTR
-	case 32:
+	case keyLength == 32 && gcm:
+		key.Info.EncryptionType = enginepbccl.EncryptionType_AES256_GCM
+	case keyLength == 32:
 		key.Info.EncryptionType = enginepbccl.EncryptionType_AES256_CTR
 	default:
 		return nil, fmt.Errorf("store key of unsupported length: %d", keyLength)
@@ -290,6 +303,11 @@ func (m *DataKeyManager) GetKey(id string) (*enginepbccl.SecretKey, error) {
 // data key under the following conditions:
 // - there is no active data key.
 // - the active store key has changed.
+// - the encryption type of the active store key has changed.
+//
+// The encryption type of the store key changes when it's used with another cipher mode. Files
+// encrypted with the previous data keys remain readable, and are migrated to the new encryption
+// type as they are rewritten by compactions.
 //
 // This function should not be called for a read only store.
 func (m *DataKeyManager) SetActiveStoreKeyInfo(
@@ -303,13 +321,15 @@ func (m *DataKeyManager) SetActiveStoreKeyInfo(
 	// Enable data key rotation regardless of what case we go into.
 	m.mu.rotationEnabled = true
 	prevActiveStoreKey, found := m.mu.keyRegistry.StoreKeys[m.mu.keyRegistry.ActiveStoreKeyId]
-	if found && prevActiveStoreKey.KeyId == storeKeyInfo.KeyId && m.mu.activeKey != nil {
+	sameStoreKey := found && prevActiveStoreKey.KeyId == storeKeyInfo.KeyId
+	if sameStoreKey && prevActiveStoreKey.EncryptionType == storeKeyInfo.EncryptionType &&
+		m.mu.activeKey != nil {
 		// The active store key has not changed and we already have an active data key,
 		// so no need to do anything.
 		return nil
 	}
 	// For keys other than plaintext, make sure the user is not reusing inactive keys.
-	if storeKeyInfo.EncryptionType != enginepbccl.EncryptionType_Plaintext {
+	if storeKeyInfo.EncryptionType != enginepbccl.EncryptionType_Plaintext && !sameStoreKey {
 		if _, found := m.mu.keyRegistry.StoreKeys[storeKeyInfo.KeyId]; found {
 			return fmt.Errorf("new active store key ID %s already exists as an inactive key -- this"+
 				"is really dangerous", storeKeyInfo.KeyId)
@@ -376,11 +396,11 @@ func generateAndSetNewDataKey(
 	} else {
 		var keyLength int
 		switch activeStoreKey.EncryptionType {
-		case enginepbccl.EncryptionType_AES128_CTR:
+		case enginepbccl.EncryptionType_AES128_CTR, enginepbccl.EncryptionType_AES128_GCM:
 			keyLength = 16
-		case enginepbccl.EncryptionType_AES192_CTR:
+		case enginepbccl.EncryptionType_AES192_CTR, enginepbccl.EncryptionType_AES192_GCM:
 			keyLength = 24
-		case enginepbccl.EncryptionType_AES256_CTR:
+		case enginepbccl.EncryptionType_AES256_CTR, enginepbccl.EncryptionType_AES256_GCM:
 			keyLength = 32
 		default:
 			return nil, fmt.Errorf("unknown encryption type %d for key ID %s",
@@ -471,3 +491,64 @@ func (m *DataKeyManager) rotateDataKeyAndWrite(
 	}
 	return err
 }
+
+// FileEncryptionInfo describes the encryption of a file in the file registry.
+type FileEncryptionInfo struct {
+	// Filename is the name of the file in the file registry.
+	Filename string
+	EnvType  enginepb.EnvType
+	// EncryptionType is the cipher used to encrypt the file.
+	EncryptionType enginepbccl.EncryptionType
+	// KeyID is the ID of the store key (for files in the store env) or data key
+	// (for files in the data env) used to encrypt the file.
+	KeyID string
+	// Key is the information about the key, or nil if the key was not found in
+	// the key registry.
+	Key *enginepbccl.KeyInfo
+	// ActiveKey is true if the key is the active key of the file's env. Files
+	// encrypted with older keys are re-encrypted with the active key as they
+	// are rewritten (e.g. by compactions).
+	ActiveKey bool
+}
+
+// GetFileEncryptionInfos returns the encryption information of all the files
+// in the file registry, sorted by filename. The key registry is used to look up
+// the information about the keys; it may be scrubbed of the keys themselves.
+func GetFileEncryptionInfos(
+	fileRegistry *enginepb.FileRegistry, keyRegistry *enginepbccl.DataKeysRegistry,
+) ([]FileEncryptionInfo, error) {
+	infos := make([]FileEncryptionInfo, 0, len(fileRegistry.Files))
+	for name, entry := range fileRegistry.Files {
+		info := FileEncryptionInfo{
+			Filename:       name,
+			EnvType:        entry.EnvType,
+			EncryptionType: enginepbccl.EncryptionType_Plaintext,
+			KeyID:          plainKeyID,
+		}
+		if len(entry.EncryptionSettings) > 0 {
+			var settings enginepbccl.EncryptionSettings
+			if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+				return nil, errors.Wrapf(err, "could not unmarshal encryption settings for %s", name)
+			}
+			if settings.EncryptionType != enginepbccl.EncryptionType_Plaintext {
+				info.EncryptionType = settings.EncryptionType
+				info.KeyID = settings.KeyId
+			}
+		}
+		switch entry.EnvType {
+		case enginepb.EnvType_Store:
+			info.Key = keyRegistry.StoreKeys[info.KeyID]
+			info.ActiveKey = info.KeyID == keyRegistry.ActiveStoreKeyId
+		case enginepb.EnvType_Data:
+			if key := keyRegistry.DataKeys[info.KeyID]; key != nil {
+				info.Key = key.Info
+			}
+			info.ActiveKey = info.KeyID == keyRegistry.ActiveDataKeyId
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Filename < infos[j].Filename
+	})
+	return infos, nil
+}
@@ -20,7 +20,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils/datapathutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -196,6 +198,19 @@ func TestStoreKeyManager(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, keyPlain.String(), key.String())
 	}
+	{
+		// With the GCM cipher mode, the same key files result in AES-GCM keys.
+		skm := &StoreKeyManager{fs: memFS, activeKeyFilename: "16.key", oldKeyFilename: "24.key",
+			cipherMode: baseccl.EncryptionCipherMode_GCM}
+		require.NoError(t, skm.Load(context.Background()))
+		key, err := skm.ActiveKey(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, enginepbccl.EncryptionType_AES128_GCM, key.Info.EncryptionType)
+		require.Equal(t, keyID128, key.Info.KeyId)
+		key, err = skm.GetKey(keyID192)
+		require.NoError(t, err)
+		require.Equal(t, enginepbccl.EncryptionType_AES192_GCM, key.Info.EncryptionType)
+	}
 }
 
 func setActiveStoreKeyInProto(dkr *enginepbccl.DataKeysRegistry, id string) {
@@ -315,6 +330,10 @@ func TestDataKeyManager(t *testing.T) {
 				var id string
 				d.ScanArgs(t, "id", &id)
 				return setActiveStoreKey(dkm, id, enginepbccl.EncryptionType_AES128_CTR)
+			case "set-active-store-key-gcm":
+				var id string
+				d.ScanArgs(t, "id", &id)
+				return setActiveStoreKey(dkm, id, enginepbccl.EncryptionType_AES128_GCM)
 			case "set-active-store-key-plain":
 				var id string
 				d.ScanArgs(t, "id", &id)
@@ -394,6 +413,76 @@ func TestDataKeyManager(t *testing.T) {
 		})
 }
 
+func TestGetFileEncryptionInfos(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	keyRegistry := makeRegistryProto()
+	keyRegistry.StoreKeys["store"] = &enginepbccl.KeyInfo{
+		EncryptionType: enginepbccl.EncryptionType_AES128_GCM, KeyId: "store",
+	}
+	keyRegistry.ActiveStoreKeyId = "store"
+	for _, key := range []*enginepbccl.KeyInfo{
+		{EncryptionType: enginepbccl.EncryptionType_AES128_CTR, KeyId: "old"},
+		{EncryptionType: enginepbccl.EncryptionType_AES128_GCM, KeyId: "new"},
+	} {
+		keyRegistry.DataKeys[key.KeyId] = &enginepbccl.SecretKey{Info: key}
+	}
+	keyRegistry.ActiveDataKeyId = "new"
+
+	fileRegistry := &enginepb.FileRegistry{Files: make(map[string]*enginepb.FileEntry)}
+	addFile := func(name string, envType enginepb.EnvType, settings *enginepbccl.EncryptionSettings) {
+		b, err := protoutil.Marshal(settings)
+		require.NoError(t, err)
+		fileRegistry.Files[name] = &enginepb.FileEntry{EnvType: envType, EncryptionSettings: b}
+	}
+	addFile("000002.sst", enginepb.EnvType_Data, &enginepbccl.EncryptionSettings{
+		EncryptionType: enginepbccl.EncryptionType_AES128_GCM, KeyId: "new",
+	})
+	addFile("000001.sst", enginepb.EnvType_Data, &enginepbccl.EncryptionSettings{
+		EncryptionType: enginepbccl.EncryptionType_AES128_CTR, KeyId: "old",
+	})
+	addFile("COCKROACHDB_DATA_KEYS", enginepb.EnvType_Store, &enginepbccl.EncryptionSettings{
+		EncryptionType: enginepbccl.EncryptionType_AES128_GCM, KeyId: "store",
+	})
+	addFile("000003.sst", enginepb.EnvType_Data, &enginepbccl.EncryptionSettings{
+		EncryptionType: enginepbccl.EncryptionType_AES128_GCM, KeyId: "missing",
+	})
+
+	infos, err := GetFileEncryptionInfos(fileRegistry, keyRegistry)
+	require.NoError(t, err)
+	require.Equal(t, []FileEncryptionInfo{
+		{
+			Filename:       "000001.sst",
+			EnvType:        enginepb.EnvType_Data,
+			EncryptionType: enginepbccl.EncryptionType_AES128_CTR,
+			KeyID:          "old",
+			Key:            keyRegistry.DataKeys["old"].Info,
+		},
+		{
+			Filename:       "000002.sst",
+			EnvType:        enginepb.EnvType_Data,
+			EncryptionType: enginepbccl.EncryptionType_AES128_GCM,
+			KeyID:          "new",
+			Key:            keyRegistry.DataKeys["new"].Info,
+			ActiveKey:      true,
+		},
+		{
+			Filename:       "000003.sst",
+			EnvType:        enginepb.EnvType_Data,
+			EncryptionType: enginepbccl.EncryptionType_AES128_GCM,
+			KeyID:          "missing",
+		},
+		{
+			Filename:       "COCKROACHDB_DATA_KEYS",
+			EnvType:        enginepb.EnvType_Store,
+			EncryptionType: enginepbccl.EncryptionType_AES128_GCM,
+			KeyID:          "store",
+			Key:            keyRegistry.StoreKeys["store"],
+			ActiveKey:      true,
+		},
+	}, infos)
+}
+
 func TestDataKeyManagerIO(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1271,7 +1271,7 @@ func runDebugIntentCount(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		atomic.AddUint64(&keysCount, 1)
-		if key.IsLockTableKey() {
+		if key.IsLockTableIntentKey() {
 			intentCount++
 		}
 	}
@@ -126,6 +126,15 @@ func RegisterExternalStorageProvider(
 	}
 }
 
+// RegisterRedactedParams registers query parameters that are redacted from
+// URIs by SanitizeExternalStorageURI, for providers that aren't registered as
+// external storage providers, such as KMS implementations.
+func RegisterRedactedParams(redactedParams map[string]struct{}) {
+	for param := range redactedParams {
+		redactedQueryParams[param] = struct{}{}
+	}
+}
+
 // ExternalStorageConfFromURI generates an ExternalStorage config from a URI string.
 func ExternalStorageConfFromURI(
 	path string, user username.SQLUsername,
@@ -0,0 +1,625 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// The SFTP packet types and constants used by the client, from version 3 of
+// the protocol, which is the version implemented by OpenSSH:
+//
+//	https://datatracker.ietf.org/doc/html/draft-ietf-secsh-filexfer-02
+const (
+	sshFxpInit     = 1
+	sshFxpVersion  = 2
+	sshFxpOpen     = 3
+	sshFxpClose    = 4
+	sshFxpRead     = 5
+	sshFxpWrite    = 6
+	sshFxpFstat    = 8
+	sshFxpOpendir  = 11
+	sshFxpReaddir  = 12
+	sshFxpRemove   = 13
+	sshFxpMkdir    = 14
+	sshFxpStat     = 17
+	sshFxpRename   = 18
+	sshFxpStatus   = 101
+	sshFxpHandle   = 102
+	sshFxpData     = 103
+	sshFxpName     = 104
+	sshFxpAttrs    = 105
+	sshFxpExtended = 200
+
+	sshFxOK         = 0
+	sshFxEOF        = 1
+	sshFxNoSuchFile = 2
+
+	sshFxfRead  = 0x01
+	sshFxfWrite = 0x02
+	sshFxfCreat = 0x08
+	sshFxfTrunc = 0x10
+
+	sshFileXferAttrSize        = 0x01
+	sshFileXferAttrUIDGID      = 0x02
+	sshFileXferAttrPermissions = 0x04
+	sshFileXferAttrACModTime   = 0x08
+	sshFileXferAttrExtended    = 0x80000000
+
+	sftpProtocolVersion = 3
+
+	// posixRenameExtension is the OpenSSH extension that atomically replaces
+	// the target of a rename, which plain SSH_FXP_RENAME refuses to do.
+	posixRenameExtension = "posix-rename@openssh.com"
+
+	// sftpMaxPacket is the maximum size of the data of a read or write request.
+	// All servers must support packets of at least 32KiB.
+	sftpMaxPacket = 32 << 10
+)
+
+// sftpStatusError is an SSH_FXP_STATUS reply with a non-OK status.
+type sftpStatusError struct {
+	code uint32
+	msg  string
+}
+
+func (e *sftpStatusError) Error() string {
+	return fmt.Sprintf("sftp: %s (SSH_FX %d)", e.msg, e.code)
+}
+
+// isNotExist returns whether the error is a "no such file" status.
+func isNotExist(err error) bool {
+	var statusErr *sftpStatusError
+	return errors.As(err, &statusErr) && statusErr.code == sshFxNoSuchFile
+}
+
+// sftpAttrs are the file attributes the client cares about.
+type sftpAttrs struct {
+	size  uint64
+	perms uint32
+}
+
+func (a sftpAttrs) isDir() bool {
+	return os.FileMode(a.perms&0170000) == 0040000
+}
+
+// sftpDirEntry is an entry of a directory listing.
+type sftpDirEntry struct {
+	name  string
+	attrs sftpAttrs
+}
+
+// sftpClient is a minimal client for the SFTP protocol running as a subsystem
+// of an SSH session. It is safe for concurrent use: requests are sent with
+// unique ids and a reader goroutine dispatches the responses to the goroutines
+// waiting for them, so multiple requests can be outstanding at once.
+type sftpClient struct {
+	sshClient *ssh.Client
+	session   *ssh.Session
+	w         io.WriteCloser
+	r         io.Reader
+
+	extensions map[string]string
+
+	readerDone chan struct{}
+
+	// writeMu serializes writes so that packets aren't interleaved. It is
+	// separate from mu so that responses can be dispatched while a write is
+	// blocked on flow control.
+	writeMu syncutil.Mutex
+
+	mu struct {
+		syncutil.Mutex
+		nextID  uint32
+		pending map[uint32]chan sftpPacket
+		// err is set once the connection fails, after which all requests fail.
+		err error
+	}
+}
+
+// sftpPacket is a packet received from the server, without its length.
+type sftpPacket struct {
+	typ  byte
+	data []byte
+}
+
+// newSFTPClient starts the SFTP subsystem on a new session of the SSH client
+// and negotiates the protocol version.
+func newSFTPClient(sshClient *ssh.Client) (*sftpClient, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		_ = session.Close()
+		return nil, errors.Wrap(err, "starting sftp subsystem")
+	}
+
+	c := &sftpClient{
+		sshClient:  sshClient,
+		session:    session,
+		w:          w,
+		r:          r,
+		readerDone: make(chan struct{}),
+	}
+	c.mu.pending = make(map[uint32]chan sftpPacket)
+
+	// The version negotiation is the only exchange without request ids.
+	var b sftpBuffer
+	b.byte(sshFxpInit)
+	b.uint32(sftpProtocolVersion)
+	if err := c.writePacket(b); err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	p, err := readSFTPPacket(r)
+	if err != nil {
+		_ = session.Close()
+		return nil, errors.Wrap(err, "negotiating sftp version")
+	}
+	if p.typ != sshFxpVersion {
+		_ = session.Close()
+		return nil, errors.Errorf("sftp: unexpected packet type %d during version negotiation", p.typ)
+	}
+	d := sftpDecoder{data: p.data}
+	if version := d.uint32(); version != sftpProtocolVersion {
+		_ = session.Close()
+		return nil, errors.Errorf("sftp: unsupported protocol version %d", version)
+	}
+	c.extensions = make(map[string]string)
+	for len(d.data) > 0 && d.err == nil {
+		name, data := d.string(), d.string()
+		c.extensions[name] = data
+	}
+
+	go c.readLoop()
+	return c, nil
+}
+
+// close closes the SFTP session, but not the SSH client.
+func (c *sftpClient) close() error {
+	err := c.session.Close()
+	<-c.readerDone
+	return err
+}
+
+// broken returns whether the connection has failed.
+func (c *sftpClient) broken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mu.err != nil
+}
+
+func (c *sftpClient) readLoop() {
+	defer close(c.readerDone)
+	for {
+		p, err := readSFTPPacket(c.r)
+		if err == nil && len(p.data) < 4 {
+			err = errors.Errorf("sftp: short packet of type %d", p.typ)
+		}
+		c.mu.Lock()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("sftp: connection closed")
+			}
+			c.mu.err = err
+			for id, ch := range c.mu.pending {
+				close(ch)
+				delete(c.mu.pending, id)
+			}
+			c.mu.Unlock()
+			return
+		}
+		id := binary.BigEndian.Uint32(p.data)
+		ch, ok := c.mu.pending[id]
+		delete(c.mu.pending, id)
+		c.mu.Unlock()
+		if ok {
+			p.data = p.data[4:]
+			ch <- p
+		}
+	}
+}
+
+// sendRequest sends a request of the given type with the payload built by fn,
+// and returns a channel on which its response is delivered, or which is closed
+// if the connection fails.
+func (c *sftpClient) sendRequest(typ byte, fn func(b *sftpBuffer)) (<-chan sftpPacket, error) {
+	ch := make(chan sftpPacket, 1)
+	c.mu.Lock()
+	if c.mu.err != nil {
+		defer c.mu.Unlock()
+		return nil, c.mu.err
+	}
+	c.mu.nextID++
+	id := c.mu.nextID
+	c.mu.pending[id] = ch
+	c.mu.Unlock()
+
+	var b sftpBuffer
+	b.byte(typ)
+	b.uint32(id)
+	fn(&b)
+	c.writeMu.Lock()
+	err := c.writePacket(b)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.mu.pending, id)
+		if c.mu.err == nil {
+			c.mu.err = err
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+// awaitResponse waits for the response of a request.
+func (c *sftpClient) awaitResponse(ctx context.Context, ch <-chan sftpPacket) (sftpPacket, error) {
+	select {
+	case <-ctx.Done():
+		return sftpPacket{}, ctx.Err()
+	case p, ok := <-ch:
+		if !ok {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return sftpPacket{}, c.mu.err
+		}
+		return p, nil
+	}
+}
+
+// request sends a request and waits for its response.
+func (c *sftpClient) request(
+	ctx context.Context, typ byte, fn func(b *sftpBuffer),
+) (sftpPacket, error) {
+	ch, err := c.sendRequest(typ, fn)
+	if err != nil {
+		return sftpPacket{}, err
+	}
+	return c.awaitResponse(ctx, ch)
+}
+
+// statusError returns the error of an SSH_FXP_STATUS response, which is nil
+// if the status is OK.
+func statusError(p sftpPacket) error {
+	if p.typ != sshFxpStatus {
+		return errors.Errorf("sftp: unexpected packet type %d, expected status", p.typ)
+	}
+	d := sftpDecoder{data: p.data}
+	code, msg := d.uint32(), d.string()
+	if d.err != nil {
+		return d.err
+	}
+	if code == sshFxOK {
+		return nil
+	}
+	return &sftpStatusError{code: code, msg: msg}
+}
+
+// expect returns an error if the response isn't of the given type.
+func expect(p sftpPacket, typ byte) error {
+	if p.typ == typ {
+		return nil
+	}
+	if p.typ == sshFxpStatus {
+		if err := statusError(p); err != nil {
+			return err
+		}
+	}
+	return errors.Errorf("sftp: unexpected packet type %d, expected %d", p.typ, typ)
+}
+
+func (c *sftpClient) requestStatus(ctx context.Context, typ byte, fn func(b *sftpBuffer)) error {
+	p, err := c.request(ctx, typ, fn)
+	if err != nil {
+		return err
+	}
+	return statusError(p)
+}
+
+func (c *sftpClient) open(ctx context.Context, path string, flags uint32) (string, error) {
+	p, err := c.request(ctx, sshFxpOpen, func(b *sftpBuffer) {
+		b.string(path)
+		b.uint32(flags)
+		b.uint32(0) // attrs
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := expect(p, sshFxpHandle); err != nil {
+		return "", err
+	}
+	d := sftpDecoder{data: p.data}
+	handle := d.string()
+	return handle, d.err
+}
+
+func (c *sftpClient) closeHandle(ctx context.Context, handle string) error {
+	return c.requestStatus(ctx, sshFxpClose, func(b *sftpBuffer) { b.string(handle) })
+}
+
+// sendRead sends a request to read up to n bytes at the offset without
+// waiting for its response, which is decoded by readResponse.
+func (c *sftpClient) sendRead(handle string, offset uint64, n uint32) (<-chan sftpPacket, error) {
+	return c.sendRequest(sshFxpRead, func(b *sftpBuffer) {
+		b.string(handle)
+		b.uint64(offset)
+		b.uint32(n)
+	})
+}
+
+// readResponse returns the data of the response to a read request, or io.EOF
+// at the end of the file.
+func readResponse(p sftpPacket) ([]byte, error) {
+	if p.typ == sshFxpStatus {
+		var statusErr *sftpStatusError
+		if err := statusError(p); errors.As(err, &statusErr) && statusErr.code == sshFxEOF {
+			return nil, io.EOF
+		}
+	}
+	if err := expect(p, sshFxpData); err != nil {
+		return nil, err
+	}
+	d := sftpDecoder{data: p.data}
+	data := d.bytes()
+	return data, d.err
+}
+
+// sendWrite sends a write request without waiting for its response.
+func (c *sftpClient) sendWrite(handle string, offset uint64, data []byte) (<-chan sftpPacket, error) {
+	return c.sendRequest(sshFxpWrite, func(b *sftpBuffer) {
+		b.string(handle)
+		b.uint64(offset)
+		b.bytes(data)
+	})
+}
+
+func (c *sftpClient) fstat(ctx context.Context, handle string) (sftpAttrs, error) {
+	return c.attrsRequest(ctx, sshFxpFstat, handle)
+}
+
+func (c *sftpClient) stat(ctx context.Context, path string) (sftpAttrs, error) {
+	return c.attrsRequest(ctx, sshFxpStat, path)
+}
+
+func (c *sftpClient) attrsRequest(ctx context.Context, typ byte, arg string) (sftpAttrs, error) {
+	p, err := c.request(ctx, typ, func(b *sftpBuffer) { b.string(arg) })
+	if err != nil {
+		return sftpAttrs{}, err
+	}
+	if err := expect(p, sshFxpAttrs); err != nil {
+		return sftpAttrs{}, err
+	}
+	d := sftpDecoder{data: p.data}
+	attrs := d.attrs()
+	return attrs, d.err
+}
+
+// readDir returns the entries of the directory, excluding "." and "..".
+func (c *sftpClient) readDir(ctx context.Context, path string) ([]sftpDirEntry, error) {
+	p, err := c.request(ctx, sshFxpOpendir, func(b *sftpBuffer) { b.string(path) })
+	if err != nil {
+		return nil, err
+	}
+	if err := expect(p, sshFxpHandle); err != nil {
+		return nil, err
+	}
+	d := sftpDecoder{data: p.data}
+	handle := d.string()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var entries []sftpDirEntry
+	for {
+		p, err := c.request(ctx, sshFxpReaddir, func(b *sftpBuffer) { b.string(handle) })
+		if err == nil && p.typ == sshFxpStatus {
+			err = statusError(p)
+			var statusErr *sftpStatusError
+			if errors.As(err, &statusErr) && statusErr.code == sshFxEOF {
+				break
+			}
+		}
+		if err == nil {
+			err = expect(p, sshFxpName)
+		}
+		if err != nil {
+			_ = c.closeHandle(ctx, handle)
+			return nil, err
+		}
+		d := sftpDecoder{data: p.data}
+		for n := d.uint32(); n > 0 && d.err == nil; n-- {
+			name := d.string()
+			_ = d.string() // longname
+			attrs := d.attrs()
+			if name != "." && name != ".." {
+				entries = append(entries, sftpDirEntry{name: name, attrs: attrs})
+			}
+		}
+		if d.err != nil {
+			_ = c.closeHandle(ctx, handle)
+			return nil, d.err
+		}
+	}
+	return entries, c.closeHandle(ctx, handle)
+}
+
+func (c *sftpClient) remove(ctx context.Context, path string) error {
+	return c.requestStatus(ctx, sshFxpRemove, func(b *sftpBuffer) { b.string(path) })
+}
+
+func (c *sftpClient) mkdir(ctx context.Context, path string) error {
+	return c.requestStatus(ctx, sshFxpMkdir, func(b *sftpBuffer) {
+		b.string(path)
+		b.uint32(sshFileXferAttrPermissions)
+		b.uint32(0755)
+	})
+}
+
+// rename renames oldPath to newPath, replacing newPath if it exists.
+func (c *sftpClient) rename(ctx context.Context, oldPath, newPath string) error {
+	if _, ok := c.extensions[posixRenameExtension]; ok {
+		return c.requestStatus(ctx, sshFxpExtended, func(b *sftpBuffer) {
+			b.string(posixRenameExtension)
+			b.string(oldPath)
+			b.string(newPath)
+		})
+	}
+	// Without the extension, the target has to be removed first, so the
+	// replacement isn't atomic.
+	if err := c.remove(ctx, newPath); err != nil && !isNotExist(err) {
+		return err
+	}
+	return c.requestStatus(ctx, sshFxpRename, func(b *sftpBuffer) {
+		b.string(oldPath)
+		b.string(newPath)
+	})
+}
+
+func (c *sftpClient) writePacket(b sftpBuffer) error {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+	_, err := c.w.Write(append(length[:], b...))
+	return err
+}
+
+func readSFTPPacket(r io.Reader) (sftpPacket, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return sftpPacket{}, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n == 0 || n > 256<<10 {
+		return sftpPacket{}, errors.Errorf("sftp: invalid packet length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return sftpPacket{}, err
+	}
+	return sftpPacket{typ: buf[0], data: buf[1:]}, nil
+}
+
+// sftpBuffer encodes the fields of a packet.
+type sftpBuffer []byte
+
+func (b *sftpBuffer) byte(v byte) {
+	*b = append(*b, v)
+}
+
+func (b *sftpBuffer) uint32(v uint32) {
+	*b = binary.BigEndian.AppendUint32(*b, v)
+}
+
+func (b *sftpBuffer) uint64(v uint64) {
+	*b = binary.BigEndian.AppendUint64(*b, v)
+}
+
+func (b *sftpBuffer) string(s string) {
+	b.uint32(uint32(len(s)))
+	*b = append(*b, s...)
+}
+
+func (b *sftpBuffer) bytes(s []byte) {
+	b.uint32(uint32(len(s)))
+	*b = append(*b, s...)
+}
+
+// sftpDecoder decodes the fields of a packet. Once a field can't be decoded,
+// err is set and all subsequent fields are zero.
+type sftpDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *sftpDecoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("sftp: malformed packet")
+	}
+	d.data = nil
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if len(d.data) < 4 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	return v
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if len(d.data) < 8 {
+		d.fail()
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v
+}
+
+func (d *sftpDecoder) bytes() []byte {
+	n := d.uint32()
+	if uint32(len(d.data)) < n {
+		d.fail()
+		return nil
+	}
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *sftpDecoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *sftpDecoder) attrs() sftpAttrs {
+	var a sftpAttrs
+	flags := d.uint32()
+	if flags&sshFileXferAttrSize != 0 {
+		a.size = d.uint64()
+	}
+	if flags&sshFileXferAttrUIDGID != 0 {
+		_, _ = d.uint32(), d.uint32()
+	}
+	if flags&sshFileXferAttrPermissions != 0 {
+		a.perms = d.uint32()
+	}
+	if flags&sshFileXferAttrACModTime != 0 {
+		_, _ = d.uint32(), d.uint32()
+	}
+	if flags&sshFileXferAttrExtended != 0 {
+		for n := d.uint32(); n > 0 && d.err == nil; n-- {
+			_, _ = d.string(), d.string()
+		}
+	}
+	return a
+}
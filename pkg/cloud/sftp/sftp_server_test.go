@@ -0,0 +1,421 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testSFTPUser     = "roach"
+	testSFTPPassword = "s3cr3t"
+
+	sshFxFailure = 4
+)
+
+// testSFTPServer is an in-process SSH server that serves the SFTP subsystem
+// from the local filesystem, resolving relative paths against home.
+type testSFTPServer struct {
+	ln   net.Listener
+	home string
+	// hostKey is the public key of the server in the authorized_keys format.
+	hostKey string
+	// privateKey is the base64-encoded PEM private key the user can
+	// authenticate with, in addition to testSFTPPassword.
+	privateKey string
+	// posixRename is whether the server supports the posix-rename extension.
+	posixRename bool
+
+	wg sync.WaitGroup
+	mu struct {
+		syncutil.Mutex
+		conns map[net.Conn]struct{}
+	}
+}
+
+func startTestSFTPServer(t *testing.T, home string, posixRename bool) *testSFTPServer {
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	userPub, userPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authorizedKey, err := ssh.NewPublicKey(userPub)
+	require.NoError(t, err)
+	userPKCS8, err := x509.MarshalPKCS8PrivateKey(userPriv)
+	require.NoError(t, err)
+	userPEM := &pem.Block{Type: "PRIVATE KEY", Bytes: userPKCS8}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == testSFTPUser && string(password) == testSFTPPassword {
+				return nil, nil
+			}
+			return nil, errors.New("invalid password")
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == testSFTPUser && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown public key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &testSFTPServer{
+		ln:          ln,
+		home:        home,
+		hostKey:     strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey()))),
+		privateKey:  base64.StdEncoding.EncodeToString(pem.EncodeToMemory(userPEM)),
+		posixRename: posixRename,
+	}
+	s.mu.conns = make(map[net.Conn]struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.mu.conns[conn] = struct{}{}
+			s.mu.Unlock()
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serveConn(conn, config)
+				s.mu.Lock()
+				delete(s.mu.conns, conn)
+				s.mu.Unlock()
+			}()
+		}
+	}()
+	return s
+}
+
+func (s *testSFTPServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+// closeConnections closes all client connections.
+func (s *testSFTPServer) closeConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.mu.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *testSFTPServer) close() {
+	_ = s.ln.Close()
+	s.closeConnections()
+	s.wg.Wait()
+}
+
+func (s *testSFTPServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer ch.Close()
+			for req := range chReqs {
+				// The payload of a subsystem request is the name of the subsystem.
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					go ssh.DiscardRequests(chReqs)
+					_ = s.serveSFTP(ch)
+					return
+				}
+			}
+		}()
+	}
+}
+
+// testSFTPHandle is an open file or directory.
+type testSFTPHandle struct {
+	f *os.File
+	// listed is set once the entries of a directory have been returned.
+	listed bool
+}
+
+func (s *testSFTPServer) serveSFTP(rw io.ReadWriter) error {
+	writePacket := func(b sftpBuffer) error {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+		_, err := rw.Write(append(length[:], b...))
+		return err
+	}
+
+	p, err := readSFTPPacket(rw)
+	if err != nil {
+		return err
+	}
+	if p.typ != sshFxpInit {
+		return errors.Errorf("unexpected packet type %d", p.typ)
+	}
+	var b sftpBuffer
+	b.byte(sshFxpVersion)
+	b.uint32(sftpProtocolVersion)
+	if s.posixRename {
+		b.string(posixRenameExtension)
+		b.string("1")
+	}
+	if err := writePacket(b); err != nil {
+		return err
+	}
+
+	handles := make(map[string]*testSFTPHandle)
+	defer func() {
+		for _, h := range handles {
+			_ = h.f.Close()
+		}
+	}()
+	var nextHandle int
+	for {
+		p, err := readSFTPPacket(rw)
+		if err != nil {
+			return err
+		}
+		d := sftpDecoder{data: p.data}
+		id := d.uint32()
+		reply := func(typ byte, fn func(b *sftpBuffer)) sftpBuffer {
+			var b sftpBuffer
+			b.byte(typ)
+			b.uint32(id)
+			fn(&b)
+			return b
+		}
+		status := func(err error) sftpBuffer {
+			return reply(sshFxpStatus, func(b *sftpBuffer) {
+				switch {
+				case err == nil:
+					b.uint32(sshFxOK)
+				case errors.Is(err, io.EOF):
+					b.uint32(sshFxEOF)
+				case oserror.IsNotExist(err):
+					b.uint32(sshFxNoSuchFile)
+				default:
+					b.uint32(sshFxFailure)
+				}
+				if err != nil {
+					b.string(err.Error())
+				} else {
+					b.string("OK")
+				}
+				b.string("") // language tag
+			})
+		}
+		attrs := func(info os.FileInfo) func(b *sftpBuffer) {
+			return func(b *sftpBuffer) {
+				perms := uint32(info.Mode().Perm())
+				if info.IsDir() {
+					perms |= 0040000
+				} else {
+					perms |= 0100000
+				}
+				b.uint32(sshFileXferAttrSize | sshFileXferAttrPermissions)
+				b.uint64(uint64(info.Size()))
+				b.uint32(perms)
+			}
+		}
+		resolve := func(p string) string {
+			if filepath.IsAbs(p) {
+				return p
+			}
+			return filepath.Join(s.home, p)
+		}
+		newHandle := func(f *os.File) sftpBuffer {
+			nextHandle++
+			handle := strconv.Itoa(nextHandle)
+			handles[handle] = &testSFTPHandle{f: f}
+			return reply(sshFxpHandle, func(b *sftpBuffer) { b.string(handle) })
+		}
+		getHandle := func(handle string) (*testSFTPHandle, error) {
+			if h, ok := handles[handle]; ok {
+				return h, nil
+			}
+			return nil, errors.New("invalid handle")
+		}
+
+		var resp sftpBuffer
+		switch p.typ {
+		case sshFxpOpen:
+			path, pflags := resolve(d.string()), d.uint32()
+			var flags int
+			switch {
+			case pflags&sshFxfRead != 0 && pflags&sshFxfWrite != 0:
+				flags = os.O_RDWR
+			case pflags&sshFxfWrite != 0:
+				flags = os.O_WRONLY
+			}
+			if pflags&sshFxfCreat != 0 {
+				flags |= os.O_CREATE
+			}
+			if pflags&sshFxfTrunc != 0 {
+				flags |= os.O_TRUNC
+			}
+			f, err := os.OpenFile(path, flags, 0644)
+			if err != nil {
+				resp = status(err)
+			} else {
+				resp = newHandle(f)
+			}
+		case sshFxpOpendir:
+			f, err := os.Open(resolve(d.string()))
+			if err != nil {
+				resp = status(err)
+			} else {
+				resp = newHandle(f)
+			}
+		case sshFxpClose:
+			handle := d.string()
+			h, err := getHandle(handle)
+			if err == nil {
+				delete(handles, handle)
+				err = h.f.Close()
+			}
+			resp = status(err)
+		case sshFxpRead:
+			h, err := getHandle(d.string())
+			offset, n := d.uint64(), d.uint32()
+			if err != nil {
+				resp = status(err)
+				break
+			}
+			buf := make([]byte, n)
+			n2, err := h.f.ReadAt(buf, int64(offset))
+			if n2 == 0 && err != nil {
+				resp = status(err)
+			} else {
+				resp = reply(sshFxpData, func(b *sftpBuffer) { b.bytes(buf[:n2]) })
+			}
+		case sshFxpWrite:
+			h, err := getHandle(d.string())
+			offset, data := d.uint64(), d.bytes()
+			if err == nil {
+				_, err = h.f.WriteAt(data, int64(offset))
+			}
+			resp = status(err)
+		case sshFxpFstat:
+			h, err := getHandle(d.string())
+			var info os.FileInfo
+			if err == nil {
+				info, err = h.f.Stat()
+			}
+			if err != nil {
+				resp = status(err)
+			} else {
+				resp = reply(sshFxpAttrs, attrs(info))
+			}
+		case sshFxpStat:
+			info, err := os.Stat(resolve(d.string()))
+			if err != nil {
+				resp = status(err)
+			} else {
+				resp = reply(sshFxpAttrs, attrs(info))
+			}
+		case sshFxpReaddir:
+			h, err := getHandle(d.string())
+			var entries []os.DirEntry
+			if err == nil && h.listed {
+				err = io.EOF
+			} else if err == nil {
+				h.listed = true
+				entries, err = h.f.ReadDir(-1)
+			}
+			infos := make([]os.FileInfo, 0, len(entries))
+			for _, e := range entries {
+				if err == nil {
+					var info os.FileInfo
+					info, err = e.Info()
+					infos = append(infos, info)
+				}
+			}
+			if err != nil {
+				resp = status(err)
+				break
+			}
+			resp = reply(sshFxpName, func(b *sftpBuffer) {
+				b.uint32(uint32(len(infos)))
+				for _, info := range infos {
+					b.string(info.Name())
+					b.string(info.Name())
+					attrs(info)(b)
+				}
+			})
+		case sshFxpRemove:
+			resp = status(os.Remove(resolve(d.string())))
+		case sshFxpMkdir:
+			resp = status(os.Mkdir(resolve(d.string()), 0755))
+		case sshFxpRename:
+			// Like OpenSSH, plain renames don't replace existing files.
+			oldPath, newPath := resolve(d.string()), resolve(d.string())
+			if _, err := os.Stat(newPath); err == nil {
+				resp = status(errors.Newf("%s already exists", newPath))
+			} else {
+				resp = status(os.Rename(oldPath, newPath))
+			}
+		case sshFxpExtended:
+			if name := d.string(); name != posixRenameExtension || !s.posixRename {
+				resp = status(errors.Newf("unsupported extension %s", name))
+				break
+			}
+			resp = status(os.Rename(resolve(d.string()), resolve(d.string())))
+		default:
+			resp = status(errors.Newf("unsupported packet type %d", p.typ))
+		}
+		if err := writePacket(resp); err != nil {
+			return err
+		}
+	}
+}
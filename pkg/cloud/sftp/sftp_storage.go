@@ -0,0 +1,626 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sftp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudpb"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	scheme = "sftp"
+
+	defaultPort = "22"
+
+	// SFTPPasswordParam is the query parameter for the password of the user.
+	SFTPPasswordParam = "SFTP_PASSWORD"
+	// SFTPPrivateKeyParam is the query parameter for the base64-encoded PEM
+	// private key to authenticate the user with.
+	SFTPPrivateKeyParam = "SFTP_PRIVATE_KEY"
+	// SFTPPrivateKeyPassphraseParam is the query parameter for the passphrase of
+	// the private key, if it is encrypted.
+	SFTPPrivateKeyPassphraseParam = "SFTP_PRIVATE_KEY_PASSPHRASE"
+	// SFTPHostKeyParam is the query parameter for the public key the server is
+	// expected to present, in the authorized_keys format, e.g. as printed by
+	// ssh-keyscan. It is required, since connecting to a server whose identity
+	// isn't verified would send it the credentials and the data.
+	SFTPHostKeyParam = "SFTP_HOST_KEY"
+
+	// sftpReadAhead is the number of read requests a reader keeps outstanding,
+	// so that reading isn't bound by the round trip time to the server.
+	sftpReadAhead = 16
+	// sftpMaxPendingWrites is the number of write requests a writer keeps
+	// outstanding.
+	sftpMaxPendingWrites = 64
+)
+
+func parseSFTPURL(
+	_ cloud.ExternalStorageURIContext, uri *url.URL,
+) (cloudpb.ExternalStorage, error) {
+	sftpURL := cloud.ConsumeURL{URL: uri}
+	conf := cloudpb.ExternalStorage{}
+	if uri.Host == "" {
+		return conf, errors.Errorf("host component of sftp URI must be the SSH server: %s",
+			uri.Redacted())
+	}
+	if uri.User == nil || uri.User.Username() == "" {
+		return conf, errors.Errorf("sftp URI must specify a user: %s", uri.Redacted())
+	}
+	// A password in the user info would not be redacted from the URI wherever it
+	// is displayed, unlike the query parameters.
+	if _, ok := uri.User.Password(); ok {
+		return conf, errors.Errorf("sftp URI must not contain a password in the user info, use %s instead",
+			SFTPPasswordParam)
+	}
+
+	// Paths are absolute unless they start with /~, in which case they are
+	// relative to the home directory of the user, where the server resolves
+	// relative paths.
+	p := uri.Path
+	if p == "/~" || strings.HasPrefix(p, "/~/") {
+		p = strings.TrimPrefix(strings.TrimPrefix(p, "/~"), "/")
+	}
+
+	conf.Provider = cloudpb.ExternalStorageProvider_sftp
+	conf.SFTPConfig = &cloudpb.ExternalStorage_SFTP{
+		Host:                 uri.Host,
+		User:                 uri.User.Username(),
+		Path:                 p,
+		Password:             sftpURL.ConsumeParam(SFTPPasswordParam),
+		PrivateKey:           sftpURL.ConsumeParam(SFTPPrivateKeyParam),
+		PrivateKeyPassphrase: sftpURL.ConsumeParam(SFTPPrivateKeyPassphraseParam),
+		HostKey:              sftpURL.ConsumeParam(SFTPHostKeyParam),
+	}
+
+	// Validate that all the passed in parameters are supported.
+	if unknownParams := sftpURL.RemainingQueryParams(); len(unknownParams) > 0 {
+		return cloudpb.ExternalStorage{}, errors.Errorf(
+			`unknown SFTP query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return conf, nil
+}
+
+type sftpStorage struct {
+	conf      *cloudpb.ExternalStorage_SFTP
+	ioConf    base.ExternalIODirConfig
+	prefix    string
+	settings  *cluster.Settings
+	addr      string
+	sshConfig *ssh.ClientConfig
+
+	mu struct {
+		syncutil.Mutex
+		// client is the SFTP client on the SSH connection shared by all
+		// operations, which is established lazily and reestablished if it fails.
+		client *sftpClient
+		closed bool
+	}
+}
+
+var _ cloud.ExternalStorage = &sftpStorage{}
+
+// MakeSFTPStorage returns an instance of sftpStorage.
+func MakeSFTPStorage(
+	ctx context.Context, args cloud.ExternalStorageContext, dest cloudpb.ExternalStorage,
+) (cloud.ExternalStorage, error) {
+	telemetry.Count("external-io.sftp")
+	conf := dest.SFTPConfig
+	if conf == nil {
+		return nil, errors.Errorf("sftp storage requested but info missing")
+	}
+
+	var auth []ssh.AuthMethod
+	if conf.PrivateKey != "" {
+		signer, err := parsePrivateKey(conf.PrivateKey, conf.PrivateKeyPassphrase)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", SFTPPrivateKeyParam)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	} else if conf.PrivateKeyPassphrase != "" {
+		return nil, errors.Errorf("%s requires %s", SFTPPrivateKeyPassphraseParam, SFTPPrivateKeyParam)
+	}
+	if conf.Password != "" {
+		auth = append(auth, ssh.Password(conf.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.Errorf("%s or %s must be set", SFTPPasswordParam, SFTPPrivateKeyParam)
+	}
+
+	if conf.HostKey == "" {
+		return nil, errors.Errorf(
+			"%s must be set to the public key of the server, as printed by ssh-keyscan", SFTPHostKeyParam)
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(conf.HostKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", SFTPHostKeyParam)
+	}
+
+	addr := conf.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), defaultPort)
+	}
+
+	return &sftpStorage{
+		conf:     conf,
+		ioConf:   args.IOConf,
+		prefix:   conf.Path,
+		settings: args.Settings,
+		addr:     addr,
+		sshConfig: &ssh.ClientConfig{
+			User:            conf.User,
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			// The server must use the algorithm of the expected key, which it may
+			// not prefer if it has keys of several types.
+			HostKeyAlgorithms: hostKeyAlgorithms(hostKey),
+		},
+	}, nil
+}
+
+func hostKeyAlgorithms(key ssh.PublicKey) []string {
+	if key.Type() == ssh.KeyAlgoRSA {
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	}
+	return []string{key.Type()}
+}
+
+// parsePrivateKey parses a base64-encoded PEM private key, which is decrypted
+// with the passphrase if it isn't empty.
+func parsePrivateKey(encodedKey, passphrase string) (ssh.Signer, error) {
+	pemBytes, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	}
+	return ssh.ParsePrivateKey(pemBytes)
+}
+
+func (s *sftpStorage) Conf() cloudpb.ExternalStorage {
+	return cloudpb.ExternalStorage{
+		Provider:   cloudpb.ExternalStorageProvider_sftp,
+		SFTPConfig: s.conf,
+	}
+}
+
+func (s *sftpStorage) ExternalIOConf() base.ExternalIODirConfig {
+	return s.ioConf
+}
+
+func (s *sftpStorage) RequiresExternalIOAccounting() bool { return true }
+
+func (s *sftpStorage) Settings() *cluster.Settings {
+	return s.settings
+}
+
+func (s *sftpStorage) timeout() time.Duration {
+	return cloud.Timeout.Get(&s.settings.SV)
+}
+
+// getClient returns the SFTP client, connecting to the server if there is no
+// connection or it has failed.
+func (s *sftpStorage) getClient(ctx context.Context) (*sftpClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.closed {
+		return nil, errors.New("sftp storage is closed")
+	}
+	if s.mu.client != nil {
+		if !s.mu.client.broken() {
+			return s.mu.client, nil
+		}
+		s.closeClientLocked()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to sftp server %s", s.addr)
+	}
+	// The SSH handshake and the SFTP version negotiation don't take a context,
+	// so they are bounded by a deadline instead.
+	if err := conn.SetDeadline(timeutil.Now().Add(s.timeout())); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, s.addr, s.sshConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "failed to establish SSH connection to %s", s.addr)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := newSFTPClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, errors.Wrapf(err, "failed to start sftp session on %s", s.addr)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = sshClient.Close()
+		_ = client.close()
+		return nil, err
+	}
+	s.mu.client = client
+	return client, nil
+}
+
+func (s *sftpStorage) closeClientLocked() error {
+	if s.mu.client == nil {
+		return nil
+	}
+	// Closing the SSH connection first ensures closing the session doesn't block
+	// on a connection that has stopped responding.
+	err := s.mu.client.sshClient.Close()
+	_ = s.mu.client.close()
+	s.mu.client = nil
+	return err
+}
+
+func (s *sftpStorage) Writer(ctx context.Context, basename string) (io.WriteCloser, error) {
+	dest := path.Join(s.prefix, basename)
+	return cloud.BackgroundPipe(ctx, func(ctx context.Context, r io.Reader) error {
+		client, err := s.getClient(ctx)
+		if err != nil {
+			return err
+		}
+		if err := mkdirAll(ctx, client, path.Dir(dest)); err != nil {
+			return errors.Wrapf(err, "failed to create directory for sftp file %q", dest)
+		}
+
+		// The file is written under a temporary name and renamed once it is
+		// complete, so that it is never observed partially written.
+		tmp := fmt.Sprintf("%s.%s.tmp", dest, uuid.FastMakeV4())
+		if err := writeFile(ctx, client, tmp, r); err != nil {
+			s.removeTemporaryFile(client, tmp)
+			return errors.Wrapf(err, "failed to write sftp file %q", dest)
+		}
+		if err := client.rename(ctx, tmp, dest); err != nil {
+			s.removeTemporaryFile(client, tmp)
+			return errors.Wrapf(err, "failed to rename sftp file to %q", dest)
+		}
+		return nil
+	}), nil
+}
+
+// removeTemporaryFile removes the temporary file of a write that failed. It
+// doesn't use the context of the write, which may have been canceled.
+func (s *sftpStorage) removeTemporaryFile(client *sftpClient, tmp string) {
+	_ = contextutil.RunWithTimeout(context.Background(), "remove sftp temporary file", s.timeout(),
+		func(ctx context.Context) error {
+			return client.remove(ctx, tmp)
+		})
+}
+
+// mkdirAll creates the directory and any missing parents.
+func mkdirAll(ctx context.Context, client *sftpClient, dir string) error {
+	if dir == "" || dir == "." || dir == "/" {
+		return nil
+	}
+	attrs, err := client.stat(ctx, dir)
+	if err == nil {
+		if !attrs.isDir() {
+			return errors.Errorf("%q is not a directory", dir)
+		}
+		return nil
+	}
+	if !isNotExist(err) {
+		return err
+	}
+	if err := mkdirAll(ctx, client, path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := client.mkdir(ctx, dir); err != nil {
+		// The directory may have been created concurrently.
+		if attrs, statErr := client.stat(ctx, dir); statErr == nil && attrs.isDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// writeFile creates or truncates the file and writes the contents of the
+// reader to it.
+func writeFile(ctx context.Context, client *sftpClient, p string, r io.Reader) (retErr error) {
+	handle, err := client.open(ctx, p, sshFxfWrite|sshFxfCreat|sshFxfTrunc)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := client.closeHandle(ctx, handle); retErr == nil {
+			retErr = err
+		}
+	}()
+
+	// Writes are pipelined, up to sftpMaxPendingWrites at a time.
+	var pending []<-chan sftpPacket
+	awaitOldest := func() error {
+		p, err := client.awaitResponse(ctx, pending[0])
+		if err != nil {
+			return err
+		}
+		pending = pending[1:]
+		return statusError(p)
+	}
+	buf := make([]byte, sftpMaxPacket)
+	var offset uint64
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			if len(pending) == sftpMaxPendingWrites {
+				if err := awaitOldest(); err != nil {
+					return err
+				}
+			}
+			ch, err := client.sendWrite(handle, offset, buf[:n])
+			if err != nil {
+				return err
+			}
+			pending = append(pending, ch)
+			offset += uint64(n)
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	for len(pending) > 0 {
+		if err := awaitOldest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFile is shorthand for ReadFileAt with offset 0.
+func (s *sftpStorage) ReadFile(ctx context.Context, basename string) (ioctx.ReadCloserCtx, error) {
+	reader, _, err := s.ReadFileAt(ctx, basename, 0)
+	return reader, err
+}
+
+func (s *sftpStorage) ReadFileAt(
+	ctx context.Context, basename string, offset int64,
+) (ioctx.ReadCloserCtx, int64, error) {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	p := path.Join(s.prefix, basename)
+	handle, err := client.open(ctx, p, sshFxfRead)
+	if err != nil {
+		if isNotExist(err) {
+			// nolint:errwrap
+			err = errors.Wrapf(
+				errors.Wrapf(cloud.ErrFileDoesNotExist, "sftp file %q does not exist", p),
+				"%v",
+				err.Error(),
+			)
+		}
+		return nil, 0, err
+	}
+	attrs, err := client.fstat(ctx, handle)
+	if err != nil {
+		_ = client.closeHandle(ctx, handle)
+		return nil, 0, err
+	}
+	return &sftpReader{client: client, handle: handle, next: offset}, int64(attrs.size), nil
+}
+
+// sftpReader reads a file sequentially, keeping up to sftpReadAhead read
+// requests outstanding.
+type sftpReader struct {
+	client *sftpClient
+	handle string
+	// buf is the data read but not yet returned.
+	buf []byte
+	// pending are the outstanding read requests, in the order of their offsets.
+	pending []sftpReadRequest
+	// next is the offset of the next read request.
+	next int64
+	eof  bool
+}
+
+type sftpReadRequest struct {
+	offset int64
+	ch     <-chan sftpPacket
+}
+
+var _ ioctx.ReadCloserCtx = &sftpReader{}
+
+func (r *sftpReader) Read(ctx context.Context, p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.fill(ctx); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fill reads the response to the oldest outstanding read request into buf,
+// after sending requests to keep sftpReadAhead of them outstanding.
+func (r *sftpReader) fill(ctx context.Context) error {
+	for len(r.pending) < sftpReadAhead {
+		ch, err := r.client.sendRead(r.handle, uint64(r.next), sftpMaxPacket)
+		if err != nil {
+			return err
+		}
+		r.pending = append(r.pending, sftpReadRequest{offset: r.next, ch: ch})
+		r.next += sftpMaxPacket
+	}
+	p, err := r.client.awaitResponse(ctx, r.pending[0].ch)
+	if err != nil {
+		return err
+	}
+	req := r.pending[0]
+	r.pending = r.pending[1:]
+	data, err := readResponse(p)
+	if errors.Is(err, io.EOF) {
+		r.eof = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) < sftpMaxPacket {
+		// The read was short, either because it reached the end of the file or
+		// because the server returned less than requested, so the outstanding
+		// requests are discarded and reading resumes after the data.
+		r.pending = nil
+		r.next = req.offset + int64(len(data))
+	}
+	r.buf = data
+	return nil
+}
+
+func (r *sftpReader) Close(ctx context.Context) error {
+	return r.client.closeHandle(ctx, r.handle)
+}
+
+func (s *sftpStorage) List(ctx context.Context, prefix, delim string, fn cloud.ListingFn) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	dest := cloud.JoinPathPreservingTrailingSlash(s.prefix, prefix)
+	dir := path.Dir(dest)
+	if strings.HasSuffix(dest, "/") {
+		dir = path.Clean(dest)
+	}
+
+	var res []string
+	if err := walk(ctx, client, dir, dest, &res); err != nil {
+		return errors.Wrap(err, "unable to list files in sftp directory")
+	}
+
+	// Sort results so that we can group as we go.
+	sort.Strings(res)
+	var prevPrefix string
+	for _, f := range res {
+		f = strings.TrimPrefix(f, dest)
+		if delim != "" {
+			if i := strings.Index(f, delim); i >= 0 {
+				f = f[:i+len(delim)]
+			}
+			if f == prevPrefix {
+				continue
+			}
+			prevPrefix = f
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk appends the paths of the files in dir and its subdirectories that have
+// the prefix to res, skipping the subdirectories that can't contain any.
+func walk(ctx context.Context, client *sftpClient, dir, prefix string, res *[]string) error {
+	entries, err := client.readDir(ctx, dir)
+	if err != nil {
+		if isNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		p := path.Join(dir, e.name)
+		if e.attrs.isDir() {
+			if strings.HasPrefix(p+"/", prefix) || strings.HasPrefix(prefix, p+"/") {
+				if err := walk(ctx, client, p, prefix, res); err != nil {
+					return err
+				}
+			}
+		} else if strings.HasPrefix(p, prefix) {
+			*res = append(*res, p)
+		}
+	}
+	return nil
+}
+
+func (s *sftpStorage) Delete(ctx context.Context, basename string) error {
+	return contextutil.RunWithTimeout(ctx, "delete sftp file", s.timeout(),
+		func(ctx context.Context) error {
+			client, err := s.getClient(ctx)
+			if err != nil {
+				return err
+			}
+			return client.remove(ctx, path.Join(s.prefix, basename))
+		})
+}
+
+func (s *sftpStorage) Size(ctx context.Context, basename string) (int64, error) {
+	var attrs sftpAttrs
+	p := path.Join(s.prefix, basename)
+	if err := contextutil.RunWithTimeout(ctx, "size sftp file", s.timeout(),
+		func(ctx context.Context) error {
+			client, err := s.getClient(ctx)
+			if err != nil {
+				return err
+			}
+			attrs, err = client.stat(ctx, p)
+			return err
+		}); err != nil {
+		if isNotExist(err) {
+			// nolint:errwrap
+			err = errors.Wrapf(
+				errors.Wrapf(cloud.ErrFileDoesNotExist, "sftp file %q does not exist", p),
+				"%v",
+				err.Error(),
+			)
+		}
+		return 0, err
+	}
+	return int64(attrs.size), nil
+}
+
+func (s *sftpStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.closed = true
+	return s.closeClientLocked()
+}
+
+func init() {
+	cloud.RegisterExternalStorageProvider(cloudpb.ExternalStorageProvider_sftp,
+		parseSFTPURL, MakeSFTPStorage,
+		cloud.RedactedParams(SFTPPasswordParam, SFTPPrivateKeyParam, SFTPPrivateKeyPassphraseParam),
+		scheme)
+}
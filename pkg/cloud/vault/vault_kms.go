@@ -0,0 +1,419 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	kmsScheme = "vault-transit"
+
+	// VaultTokenParam is the query parameter for the Vault token to
+	// authenticate with.
+	VaultTokenParam = "VAULT_TOKEN"
+	// VaultRoleIDParam is the query parameter for the role ID to log in with
+	// using the AppRole auth method, instead of a token.
+	VaultRoleIDParam = "VAULT_ROLE_ID"
+	// VaultSecretIDParam is the query parameter for the secret ID to log in with
+	// using the AppRole auth method.
+	VaultSecretIDParam = "VAULT_SECRET_ID"
+	// VaultAppRoleMountParam is the query parameter for the path the AppRole
+	// auth method is mounted at, if it isn't the default "approle".
+	VaultAppRoleMountParam = "VAULT_APPROLE_MOUNT"
+	// VaultNamespaceParam is the query parameter for the Vault Enterprise
+	// namespace of the transit engine and the auth method.
+	VaultNamespaceParam = "VAULT_NAMESPACE"
+	// VaultKeyVersionParam is the query parameter for the version of the key to
+	// encrypt with. By default the latest version is used. Decryption always
+	// uses the version recorded in the ciphertext.
+	VaultKeyVersionParam = "VAULT_KEY_VERSION"
+	// VaultCACertParam is the query parameter for the base64-encoded PEM
+	// certificate of the CA to trust, in addition to the system's, when
+	// verifying the certificate of the Vault server.
+	VaultCACertParam = "VAULT_CA_CERT"
+
+	defaultTransitMount = "transit"
+	defaultAppRoleMount = "approle"
+)
+
+type vaultKMS struct {
+	client *http.Client
+	// addr is the base URL of the Vault server.
+	addr       string
+	namespace  string
+	mount      string
+	keyName    string
+	keyVersion int
+
+	// token is the static token to authenticate with, if the AppRole auth
+	// method isn't used.
+	token        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+
+	mu struct {
+		syncutil.Mutex
+		// token is the token obtained by logging in with AppRole, which is
+		// renewed by logging in again once it expires.
+		token string
+		// expiration is when token expires, or zero if it doesn't.
+		expiration time.Time
+	}
+}
+
+var _ cloud.KMS = &vaultKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeVaultKMS, kmsScheme)
+	cloud.RegisterRedactedParams(cloud.RedactedParams(VaultTokenParam, VaultSecretIDParam))
+}
+
+type kmsURIParams struct {
+	token        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+	namespace    string
+	keyVersion   string
+	caCert       string
+}
+
+// resolveKMSURIParams parses the `kmsURI` for all the supported KMS parameters.
+func resolveKMSURIParams(kmsURI cloud.ConsumeURL) (kmsURIParams, error) {
+	params := kmsURIParams{
+		token:        kmsURI.ConsumeParam(VaultTokenParam),
+		roleID:       kmsURI.ConsumeParam(VaultRoleIDParam),
+		secretID:     kmsURI.ConsumeParam(VaultSecretIDParam),
+		appRoleMount: kmsURI.ConsumeParam(VaultAppRoleMountParam),
+		namespace:    kmsURI.ConsumeParam(VaultNamespaceParam),
+		keyVersion:   kmsURI.ConsumeParam(VaultKeyVersionParam),
+		caCert:       kmsURI.ConsumeParam(VaultCACertParam),
+	}
+
+	// Validate that all the passed in parameters are supported.
+	if unknownParams := kmsURI.RemainingQueryParams(); len(unknownParams) > 0 {
+		return kmsURIParams{}, errors.Errorf(
+			`unknown KMS query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return params, nil
+}
+
+// MakeVaultKMS is the factory method which returns a configured, ready-to-use
+// Vault transit KMS object. The URI is of the form
+//
+//	vault-transit://host:port/[mount/]key?VAULT_TOKEN=...
+//
+// where mount is the path the transit secrets engine is mounted at, which
+// defaults to "transit", and key is the name of the transit key.
+func MakeVaultKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	if env.KMSConfig().DisableOutbound {
+		return nil, errors.New("external IO must be enabled to use Vault KMS")
+	}
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if kmsURI.Host == "" {
+		return nil, errors.New("host component of the Vault KMS URI must be the address of the Vault server")
+	}
+	keyPath := strings.Trim(kmsURI.Path, "/")
+	if keyPath == "" {
+		return nil, errors.Newf("path component of the KMS cannot be empty; must contain the transit key name")
+	}
+	mount, keyName := defaultTransitMount, keyPath
+	if i := strings.LastIndexByte(keyPath, '/'); i >= 0 {
+		mount, keyName = keyPath[:i], keyPath[i+1:]
+	}
+
+	// Extract the URI parameters required to setup the Vault KMS session.
+	kmsURIParams, err := resolveKMSURIParams(cloud.ConsumeURL{URL: kmsURI})
+	if err != nil {
+		return nil, err
+	}
+
+	k := &vaultKMS{
+		addr:         "https://" + kmsURI.Host,
+		namespace:    kmsURIParams.namespace,
+		mount:        mount,
+		keyName:      keyName,
+		token:        kmsURIParams.token,
+		roleID:       kmsURIParams.roleID,
+		secretID:     kmsURIParams.secretID,
+		appRoleMount: kmsURIParams.appRoleMount,
+	}
+	switch {
+	case k.token != "" && (k.roleID != "" || k.secretID != ""):
+		return nil, errors.Errorf("%s cannot be used together with %s and %s",
+			VaultTokenParam, VaultRoleIDParam, VaultSecretIDParam)
+	case k.roleID != "" && k.secretID != "":
+		if k.appRoleMount == "" {
+			k.appRoleMount = defaultAppRoleMount
+		}
+	case k.roleID != "" || k.secretID != "":
+		return nil, errors.Errorf("%s and %s must be set together", VaultRoleIDParam, VaultSecretIDParam)
+	case k.token == "":
+		return nil, errors.Errorf("%s or %s and %s must be set",
+			VaultTokenParam, VaultRoleIDParam, VaultSecretIDParam)
+	}
+	if k.appRoleMount != "" && k.roleID == "" {
+		return nil, errors.Errorf("%s requires %s and %s",
+			VaultAppRoleMountParam, VaultRoleIDParam, VaultSecretIDParam)
+	}
+
+	if kmsURIParams.keyVersion != "" {
+		k.keyVersion, err = strconv.Atoi(kmsURIParams.keyVersion)
+		if err != nil || k.keyVersion < 1 {
+			return nil, errors.Errorf("%s must be a positive integer, got %q",
+				VaultKeyVersionParam, kmsURIParams.keyVersion)
+		}
+	}
+
+	k.client, err = cloud.MakeHTTPClient(env.ClusterSettings())
+	if err != nil {
+		return nil, err
+	}
+	if kmsURIParams.caCert != "" {
+		if err := addCACert(k.client, kmsURIParams.caCert); err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", VaultCACertParam)
+		}
+	}
+	return k, nil
+}
+
+// addCACert configures the client to trust the base64-encoded PEM certificate.
+func addCACert(client *http.Client, encodedCert string) error {
+	pem, err := base64.StdEncoding.DecodeString(encodedCert)
+	if err != nil {
+		return err
+	}
+	t := client.Transport.(*http.Transport)
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	if t.TLSClientConfig.RootCAs == nil {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return errors.Wrap(err, "could not load system root CA pool")
+		}
+		t.TLSClientConfig.RootCAs = roots
+	}
+	if !t.TLSClientConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return errors.New("no certificates found")
+	}
+	return nil
+}
+
+// MasterKeyID implements the KMS interface. The ID identifies the transit key
+// rather than one of its versions, so that data keys encrypted before the key
+// is rotated can be found, and decrypted, afterwards.
+func (k *vaultKMS) MasterKeyID() (string, error) {
+	return strings.TrimPrefix(k.addr, "https://") + "/" +
+		path.Join(k.namespace, k.mount, "keys", k.keyName), nil
+}
+
+// Encrypt implements the KMS interface. The returned ciphertext is in Vault's
+// format, which records the version of the key used.
+func (k *vaultKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	req := struct {
+		Plaintext  string `json:"plaintext"`
+		KeyVersion int    `json:"key_version,omitempty"`
+	}{
+		Plaintext:  base64.StdEncoding.EncodeToString(data),
+		KeyVersion: k.keyVersion,
+	}
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := k.do(ctx, path.Join(k.mount, "encrypt", k.keyName), req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("vault: encrypt response is missing the ciphertext")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *vaultKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	req := struct {
+		Ciphertext string `json:"ciphertext"`
+	}{
+		Ciphertext: string(data),
+	}
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := k.do(ctx, path.Join(k.mount, "decrypt", k.keyName), req, &resp); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "vault: decoding plaintext")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *vaultKMS) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
+
+// vaultError is an error response from Vault.
+type vaultError struct {
+	statusCode int
+	errors     []string
+}
+
+func (e *vaultError) Error() string {
+	return fmt.Sprintf("vault: %d %s: %s",
+		e.statusCode, http.StatusText(e.statusCode), strings.Join(e.errors, "; "))
+}
+
+// do sends a request to the API endpoint and decodes the response into resp.
+// If the request is denied with a token obtained by logging in, which may have
+// been revoked, it logs in again and retries the request once.
+func (k *vaultKMS) do(ctx context.Context, endpoint string, req, resp interface{}) error {
+	token, err := k.getToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = k.send(ctx, endpoint, token, req, resp)
+	var vErr *vaultError
+	if k.roleID != "" && errors.As(err, &vErr) && vErr.statusCode == http.StatusForbidden {
+		k.invalidateToken(token)
+		if token, err = k.getToken(ctx); err != nil {
+			return err
+		}
+		err = k.send(ctx, endpoint, token, req, resp)
+	}
+	return err
+}
+
+// getToken returns the token to authenticate with, logging in with AppRole if
+// there is no valid token.
+func (k *vaultKMS) getToken(ctx context.Context) (string, error) {
+	if k.roleID == "" {
+		return k.token, nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.mu.token != "" && (k.mu.expiration.IsZero() || timeutil.Now().Before(k.mu.expiration)) {
+		return k.mu.token, nil
+	}
+
+	req := struct {
+		RoleID   string `json:"role_id"`
+		SecretID string `json:"secret_id"`
+	}{
+		RoleID:   k.roleID,
+		SecretID: k.secretID,
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := k.send(ctx, path.Join("auth", k.appRoleMount, "login"), "", req, &resp); err != nil {
+		return "", errors.Wrap(err, "vault: logging in with AppRole")
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault: AppRole login response is missing the token")
+	}
+	k.mu.token = resp.Auth.ClientToken
+	k.mu.expiration = time.Time{}
+	if lease := time.Duration(resp.Auth.LeaseDuration) * time.Second; lease > 0 {
+		// Log in again a little before the token expires rather than have a
+		// request fail.
+		k.mu.expiration = timeutil.Now().Add(lease * 9 / 10)
+	}
+	return k.mu.token, nil
+}
+
+// invalidateToken discards the token obtained by logging in, unless it has
+// already been replaced.
+func (k *vaultKMS) invalidateToken(token string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.mu.token == token {
+		k.mu.token = ""
+	}
+}
+
+// send POSTs the JSON encoding of req to the API endpoint and decodes the
+// response into resp.
+func (k *vaultKMS) send(ctx context.Context, endpoint, token string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, k.addr+"/v1/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("X-Vault-Token", token)
+	}
+	if k.namespace != "" {
+		httpReq.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	httpResp, err := k.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "vault")
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return errors.Wrap(err, "vault: reading response")
+	}
+	if httpResp.StatusCode/100 != 2 {
+		vErr := &vaultError{statusCode: httpResp.StatusCode}
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil {
+			vErr.errors = errResp.Errors
+		}
+		return vErr
+	}
+	if err := json.Unmarshal(respBody, resp); err != nil {
+		return errors.Wrap(err, "vault: decoding response")
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testVaultRootToken = "root-token"
+	testVaultRoleID    = "role-id"
+	testVaultSecretID  = "secret-id"
+)
+
+// fakeVault is a stand-in for a Vault server with the transit secrets engine
+// mounted at "transit" and the AppRole auth method mounted at "approle".
+type fakeVault struct {
+	srv *httptest.Server
+	// namespace is the namespace requests must be made in, if any.
+	namespace string
+
+	mu struct {
+		syncutil.Mutex
+		// tokens are the valid tokens.
+		tokens map[string]struct{}
+		logins int
+		// keys are the versions of the transit keys, by name.
+		keys map[string][]cipher.AEAD
+		// minDecryptionVersion is the minimum version of the keys that can be
+		// used to decrypt, by name.
+		minDecryptionVersion map[string]int
+	}
+}
+
+func startFakeVault(t *testing.T, namespace string) *fakeVault {
+	v := &fakeVault{namespace: namespace}
+	v.mu.tokens = map[string]struct{}{testVaultRootToken: {}}
+	v.mu.keys = make(map[string][]cipher.AEAD)
+	v.mu.minDecryptionVersion = make(map[string]int)
+	v.srv = httptest.NewTLSServer(http.HandlerFunc(v.handle))
+	return v
+}
+
+func (v *fakeVault) close() {
+	v.srv.Close()
+}
+
+// caCert returns the base64-encoded PEM certificate of the server.
+func (v *fakeVault) caCert() string {
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: v.srv.Certificate().Raw}))
+}
+
+func (v *fakeVault) uri(keyPath string, params ...string) string {
+	q := make(url.Values)
+	q.Set(VaultCACertParam, v.caCert())
+	for i := 0; i+1 < len(params); i += 2 {
+		q.Set(params[i], params[i+1])
+	}
+	return fmt.Sprintf("%s://%s/%s?%s", kmsScheme, v.srv.Listener.Addr(), keyPath, q.Encode())
+}
+
+// rotate adds a new version to the key, creating it if it doesn't exist.
+func (v *fakeVault) rotate(t *testing.T, name string) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.keys[name] = append(v.mu.keys[name], aead)
+}
+
+func (v *fakeVault) setMinDecryptionVersion(name string, version int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.minDecryptionVersion[name] = version
+}
+
+// revokeTokens revokes all tokens obtained by logging in.
+func (v *fakeVault) revokeTokens() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.tokens = map[string]struct{}{testVaultRootToken: {}}
+}
+
+func (v *fakeVault) logins() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.mu.logins
+}
+
+func (v *fakeVault) handle(w http.ResponseWriter, r *http.Request) {
+	reply := func(code int, resp interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	fail := func(code int, msg string) {
+		reply(code, map[string][]string{"errors": {msg}})
+	}
+	if r.Method != http.MethodPost {
+		fail(http.StatusMethodNotAllowed, "unsupported method")
+		return
+	}
+	if r.Header.Get("X-Vault-Namespace") != v.namespace {
+		fail(http.StatusNotFound, "no handler for route")
+		return
+	}
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	str := func(name string) string {
+		s, _ := req[name].(string)
+		return s
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if r.URL.Path == "/v1/auth/approle/login" {
+		if str("role_id") != testVaultRoleID || str("secret_id") != testVaultSecretID {
+			fail(http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		v.mu.logins++
+		token := fmt.Sprintf("approle-token-%d", v.mu.logins)
+		v.mu.tokens[token] = struct{}{}
+		reply(http.StatusOK, map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": token, "lease_duration": 3600},
+		})
+		return
+	}
+	if _, ok := v.mu.tokens[r.Header.Get("X-Vault-Token")]; !ok {
+		fail(http.StatusForbidden, "permission denied")
+		return
+	}
+
+	op, name := "", ""
+	if parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"), "/"); len(parts) == 2 {
+		op, name = parts[0], parts[1]
+	}
+	versions := v.mu.keys[name]
+	switch {
+	case op != "encrypt" && op != "decrypt":
+		fail(http.StatusNotFound, "no handler for route")
+	case len(versions) == 0:
+		fail(http.StatusBadRequest, "encryption key not found")
+	case op == "encrypt":
+		version := len(versions)
+		if kv, ok := req["key_version"].(float64); ok {
+			version = int(kv)
+		}
+		if version < 1 || version > len(versions) {
+			fail(http.StatusBadRequest, "invalid key version")
+			return
+		}
+		plaintext, err := base64.StdEncoding.DecodeString(str("plaintext"))
+		if err != nil {
+			fail(http.StatusBadRequest, "failed to base64-decode plaintext")
+			return
+		}
+		aead := versions[version-1]
+		nonce := make([]byte, aead.NonceSize())
+		_, _ = rand.Read(nonce)
+		ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
+		reply(http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"ciphertext":  fmt.Sprintf("vault:v%d:%s", version, base64.StdEncoding.EncodeToString(ciphertext)),
+			"key_version": version,
+		}})
+	case op == "decrypt":
+		parts := strings.SplitN(str("ciphertext"), ":", 3)
+		if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+			fail(http.StatusBadRequest, "invalid ciphertext: no prefix")
+			return
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+		if err != nil || version < 1 || version > len(versions) {
+			fail(http.StatusBadRequest, "invalid ciphertext: invalid key version")
+			return
+		}
+		if version < v.mu.minDecryptionVersion[name] {
+			fail(http.StatusBadRequest, "cannot decrypt using archived key version")
+			return
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+		aead := versions[version-1]
+		if err != nil || len(ciphertext) < aead.NonceSize() {
+			fail(http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+		if err != nil {
+			fail(http.StatusBadRequest, "cipher: message authentication failed")
+			return
+		}
+		reply(http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+		}})
+	}
+}
+
+func testKMSEnv() cloud.KMSEnv {
+	return &cloud.TestKMSEnv{
+		Settings:         cluster.MakeTestingClusterSettings(),
+		ExternalIOConfig: &base.ExternalIODirConfig{},
+	}
+}
+
+func TestEncryptDecryptVault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	v := startFakeVault(t, "")
+	defer v.close()
+	v.rotate(t, "backup-key")
+
+	t.Run("token", func(t *testing.T) {
+		cloud.KMSEncryptDecrypt(t, v.uri("backup-key", VaultTokenParam, testVaultRootToken), testKMSEnv())
+	})
+
+	t.Run("explicit-mount", func(t *testing.T) {
+		cloud.KMSEncryptDecrypt(t, v.uri("transit/backup-key", VaultTokenParam, testVaultRootToken),
+			testKMSEnv())
+	})
+
+	t.Run("approle", func(t *testing.T) {
+		cloud.KMSEncryptDecrypt(t, v.uri("backup-key",
+			VaultRoleIDParam, testVaultRoleID, VaultSecretIDParam, testVaultSecretID), testKMSEnv())
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		nsVault := startFakeVault(t, "team-a")
+		defer nsVault.close()
+		nsVault.rotate(t, "backup-key")
+		cloud.KMSEncryptDecrypt(t, nsVault.uri("backup-key",
+			VaultTokenParam, testVaultRootToken, VaultNamespaceParam, "team-a"), testKMSEnv())
+
+		kms, err := cloud.KMSFromURI(context.Background(),
+			nsVault.uri("backup-key", VaultTokenParam, testVaultRootToken), testKMSEnv())
+		require.NoError(t, err)
+		defer kms.Close()
+		_, err = kms.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "no handler for route")
+	})
+
+	t.Run("wrong-token", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(context.Background(),
+			v.uri("backup-key", VaultTokenParam, "wrong"), testKMSEnv())
+		require.NoError(t, err)
+		defer kms.Close()
+		_, err = kms.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "permission denied")
+	})
+
+	t.Run("missing-key", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(context.Background(),
+			v.uri("missing-key", VaultTokenParam, testVaultRootToken), testKMSEnv())
+		require.NoError(t, err)
+		defer kms.Close()
+		_, err = kms.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "encryption key not found")
+	})
+}
+
+func TestVaultKMSKeyRotation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	v := startFakeVault(t, "")
+	defer v.close()
+	v.rotate(t, "backup-key")
+
+	kms, err := cloud.KMSFromURI(ctx, v.uri("backup-key", VaultTokenParam, testVaultRootToken),
+		testKMSEnv())
+	require.NoError(t, err)
+	defer kms.Close()
+	keyIDBefore, err := kms.MasterKeyID()
+	require.NoError(t, err)
+	v1, err := kms.Encrypt(ctx, []byte("first"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(v1), "vault:v1:"), string(v1))
+
+	v.rotate(t, "backup-key")
+	v2, err := kms.Encrypt(ctx, []byte("second"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(v2), "vault:v2:"), string(v2))
+
+	// The master key ID doesn't depend on the version, and data encrypted with
+	// either version can be decrypted.
+	keyIDAfter, err := kms.MasterKeyID()
+	require.NoError(t, err)
+	require.Equal(t, keyIDBefore, keyIDAfter)
+	for ciphertext, expected := range map[string]string{string(v1): "first", string(v2): "second"} {
+		plaintext, err := kms.Decrypt(ctx, []byte(ciphertext))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(plaintext))
+	}
+
+	// Encryption can be pinned to a version.
+	pinned, err := cloud.KMSFromURI(ctx, v.uri("backup-key",
+		VaultTokenParam, testVaultRootToken, VaultKeyVersionParam, "1"), testKMSEnv())
+	require.NoError(t, err)
+	defer pinned.Close()
+	ciphertext, err := pinned.Encrypt(ctx, []byte("pinned"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(ciphertext), "vault:v1:"), string(ciphertext))
+
+	// Versions that have been archived can't be used to decrypt.
+	v.setMinDecryptionVersion("backup-key", 2)
+	_, err = kms.Decrypt(ctx, v1)
+	require.ErrorContains(t, err, "archived key version")
+}
+
+func TestVaultKMSAppRoleRelogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	v := startFakeVault(t, "")
+	defer v.close()
+	v.rotate(t, "backup-key")
+
+	kms, err := cloud.KMSFromURI(ctx, v.uri("backup-key",
+		VaultRoleIDParam, testVaultRoleID, VaultSecretIDParam, testVaultSecretID), testKMSEnv())
+	require.NoError(t, err)
+	defer kms.Close()
+
+	ciphertext, err := kms.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	_, err = kms.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, 1, v.logins())
+
+	// Once the token is revoked, the KMS logs in again.
+	v.revokeTokens()
+	plaintext, err := kms.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "data key", string(plaintext))
+	require.Equal(t, 2, v.logins())
+
+	wrongSecret, err := cloud.KMSFromURI(ctx, v.uri("backup-key",
+		VaultRoleIDParam, testVaultRoleID, VaultSecretIDParam, "wrong"), testKMSEnv())
+	require.NoError(t, err)
+	defer wrongSecret.Close()
+	_, err = wrongSecret.Encrypt(ctx, []byte("data key"))
+	require.ErrorContains(t, err, "logging in with AppRole")
+}
+
+func TestMakeVaultKMSErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{"vault-transit:///key?VAULT_TOKEN=t", "must be the address of the Vault server"},
+		{"vault-transit://vault:8200/?VAULT_TOKEN=t", "must contain the transit key name"},
+		{"vault-transit://vault:8200/key", "VAULT_TOKEN or VAULT_ROLE_ID and VAULT_SECRET_ID must be set"},
+		{"vault-transit://vault:8200/key?VAULT_ROLE_ID=r", "must be set together"},
+		{"vault-transit://vault:8200/key?VAULT_TOKEN=t&VAULT_ROLE_ID=r&VAULT_SECRET_ID=s",
+			"cannot be used together"},
+		{"vault-transit://vault:8200/key?VAULT_TOKEN=t&VAULT_APPROLE_MOUNT=m", "VAULT_APPROLE_MOUNT requires"},
+		{"vault-transit://vault:8200/key?VAULT_TOKEN=t&VAULT_KEY_VERSION=0", "must be a positive integer"},
+		{"vault-transit://vault:8200/key?VAULT_TOKEN=t&VAULT_CA_CERT=Zm9v", "error parsing VAULT_CA_CERT"},
+		{"vault-transit://vault:8200/key?VAULT_TOKEN=t&FOO=bar", "unknown KMS query parameters: FOO"},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			_, err := cloud.KMSFromURI(context.Background(), tc.uri, testKMSEnv())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("disable-outbound", func(t *testing.T) {
+		_, err := cloud.KMSFromURI(context.Background(), "vault-transit://vault:8200/key?VAULT_TOKEN=t",
+			&cloud.TestKMSEnv{
+				Settings:         cluster.MakeTestingClusterSettings(),
+				ExternalIOConfig: &base.ExternalIODirConfig{DisableOutbound: true},
+			})
+		require.ErrorContains(t, err, "external IO must be enabled")
+	})
+
+	t.Run("master-key-id", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(context.Background(),
+			"vault-transit://vault:8200/secret/transit/key?VAULT_TOKEN=t&VAULT_NAMESPACE=ns", testKMSEnv())
+		require.NoError(t, err)
+		defer kms.Close()
+		id, err := kms.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "vault:8200/ns/secret/transit/keys/key", id)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		redacted, err := cloud.RedactKMSURI(
+			"vault-transit://vault:8200/key?VAULT_TOKEN=t&VAULT_ROLE_ID=r&VAULT_SECRET_ID=s")
+		require.NoError(t, err)
+		require.Equal(t,
+			"vault-transit://vault:8200/redacted?VAULT_ROLE_ID=r&VAULT_SECRET_ID=redacted&VAULT_TOKEN=redacted",
+			redacted)
+	})
+}
@@ -484,6 +484,29 @@ const (
 	// progress of each job in the system.jobs table.
 	V23_1JobInfoTableIsBackfilled
 
+	// V23_1NotificationsTable adds the system.notifications table, which is
+	// used to deliver notifications published with NOTIFY to listening
+	// sessions.
+	V23_1NotificationsTable
+
+	// V23_1RangeTypes is the version where the built-in range and multirange
+	// types can be used as column types.
+	V23_1RangeTypes
+
+	// V23_1ReadCommittedIsolation is the version where transactions can run
+	// with READ COMMITTED isolation. Before this version, READ COMMITTED is
+	// upgraded to SERIALIZABLE.
+	V23_1ReadCommittedIsolation
+
+	// V23_1ReplicatedLocks is the version where locking reads can acquire
+	// replicated locks which are persisted in the lock table keyspace.
+	V23_1ReplicatedLocks
+
+	// V23_1RangefeedValueFilters is the version where rangefeed registrations
+	// can carry a predicate and projection which is evaluated on the server
+	// before events are sent to the client.
+	V23_1RangefeedValueFilters
+
 	// *************************************************
 	// Step (1): Add new versions here.
 	// Do not add new versions to a patch release.
@@ -837,6 +860,26 @@ var rawVersionsSingleton = keyedVersions{
 		Key:     V23_1JobInfoTableIsBackfilled,
 		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 80},
 	},
+	{
+		Key:     V23_1NotificationsTable,
+		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 82},
+	},
+	{
+		Key:     V23_1RangeTypes,
+		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 84},
+	},
+	{
+		Key:     V23_1ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 86},
+	},
+	{
+		Key:     V23_1ReplicatedLocks,
+		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 88},
+	},
+	{
+		Key:     V23_1RangefeedValueFilters,
+		Version: roachpb.Version{Major: 22, Minor: 2, Internal: 90},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
@@ -88,6 +88,7 @@ func maxConcurrentCatchupScans(sv *settings.Values) int {
 type rangeFeedConfig struct {
 	useMuxRangeFeed bool
 	overSystemTable bool
+	valueFilter     *kvpb.RangeFeedValueFilter
 }
 
 // RangeFeedOption configures a RangeFeed.
@@ -114,6 +115,17 @@ func WithSystemTablePriority() RangeFeedOption {
 	})
 }
 
+// WithValueFilter configures range feed to push down the provided value filter
+// to the servers, which then avoid sending events that don't match it. The
+// filter is only pushed down once the cluster version allows it, and servers
+// are free to send events that don't match it, so the caller must still be
+// prepared to filter events itself.
+func WithValueFilter(filter *kvpb.RangeFeedValueFilter) RangeFeedOption {
+	return optionFunc(func(c *rangeFeedConfig) {
+		c.valueFilter = filter
+	})
+}
+
 // A "kill switch" to disable multiplexing rangefeed if severe issues discovered with new implementation.
 var enableMuxRangeFeed = envutil.EnvOrDefaultBool("COCKROACH_ENABLE_MULTIPLEXING_RANGEFEED", true)
 
@@ -542,6 +554,10 @@ func (ds *DistSender) singleRangeFeed(
 			NoMemoryReservedAtSource: true,
 		},
 	}
+	if cfg.valueFilter != nil &&
+		ds.st.Version.IsActive(ctx, clusterversion.V23_1RangefeedValueFilters) {
+		args.ValueFilter = cfg.valueFilter
+	}
 
 	var latencyFn LatencyFunc
 	if ds.rpcContext != nil {
@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -769,7 +770,8 @@ func (tc *TxnCoordSender) UpdateStateOnRemoteRetryableErr(
 // the TxnCoordSender's state. Depending on the error, the TxnCoordSender might
 // not be usable afterwards (in case of TransactionAbortedError). The caller is
 // expected to check the ID of the resulting transaction. If the TxnCoordSender
-// can still be used, it will have been prepared for a new epoch.
+// can still be used, it will have been prepared for a new epoch, unless the
+// epoch bump was deferred to allow for a partial retry.
 func (tc *TxnCoordSender) handleRetryableErrLocked(
 	ctx context.Context, pErr *kvpb.Error,
 ) *kvpb.TransactionRetryWithProtoRefreshError {
@@ -816,6 +818,15 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		errTxnID, // the id of the transaction that encountered the error
 		newTxn)
 
+	// A transaction which establishes a new read snapshot for each statement
+	// may be able to retry only the statement that ran into the error, if the
+	// error is caused by a conflict between that statement and a concurrent
+	// writer. Whether it does so is up to the client, so the epoch bump is
+	// deferred until the client either clears the error to retry the whole
+	// transaction or prepares for a partial retry. See PrepareForPartialRetry.
+	deferEpochBump := tc.mu.txnState == txnPending &&
+		tc.mu.txn.IsoLevel.PerStatementReadSnapshot() && canRetryStatementOnErr(pErr)
+
 	// Move to a retryable error state, where all Send() calls fail until the
 	// state is cleared.
 	tc.mu.txnState = txnRetryableError
@@ -835,8 +846,33 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		return retErr
 	}
 
+	if deferEpochBump {
+		log.VEventf(ctx, 2, "deferring epoch bump on retry")
+		return retErr
+	}
+	tc.bumpEpochLocked(ctx, &newTxn)
+	return retErr
+}
+
+// canRetryStatementOnErr returns whether the retryable error was caused by a
+// conflict between the request that returned it and a concurrent writer. Such
+// an error does not invalidate any of the transaction's earlier operations.
+func canRetryStatementOnErr(pErr *kvpb.Error) bool {
+	switch tErr := pErr.GetDetail().(type) {
+	case *kvpb.TransactionRetryError:
+		return tErr.Reason == kvpb.RETRY_WRITE_TOO_OLD
+	case *kvpb.WriteTooOldError, *kvpb.ReadWithinUncertaintyIntervalError:
+		return true
+	default:
+		return false
+	}
+}
+
+// bumpEpochLocked moves the transaction to the new epoch that newTxn was
+// prepared for by handleRetryableErrLocked.
+func (tc *TxnCoordSender) bumpEpochLocked(ctx context.Context, newTxn *roachpb.Transaction) {
 	// This is where we get a new epoch.
-	tc.mu.txn.Update(&newTxn)
+	tc.mu.txn.Update(newTxn)
 
 	// Reset state as this is a retryable txn error that is incrementing
 	// the transaction's epoch.
@@ -844,7 +880,25 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 	for _, reqInt := range tc.interceptorStack {
 		reqInt.epochBumpedLocked()
 	}
-	return retErr
+}
+
+// epochBumpDeferredLocked returns whether handleRetryableErrLocked deferred
+// the epoch bump for the stored retryable error.
+func (tc *TxnCoordSender) epochBumpDeferredLocked() bool {
+	if tc.mu.txnState != txnRetryableError {
+		return false
+	}
+	newTxn := &tc.mu.storedRetryableErr.Transaction
+	return newTxn.ID == tc.mu.txn.ID && tc.mu.txn.Epoch < newTxn.Epoch
+}
+
+// maybeApplyDeferredEpochBumpLocked moves the transaction to its next epoch if
+// handleRetryableErrLocked deferred the epoch bump for the stored retryable
+// error.
+func (tc *TxnCoordSender) maybeApplyDeferredEpochBumpLocked(ctx context.Context) {
+	if tc.epochBumpDeferredLocked() {
+		tc.bumpEpochLocked(ctx, &tc.mu.storedRetryableErr.Transaction)
+	}
 }
 
 // updateStateLocked updates the transaction state in both the success and error
@@ -993,6 +1047,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsoLevel(isoLevel isolation.Level) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && isoLevel != tc.mu.txn.IsoLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsoLevel() isolation.Level {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsoLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -1107,6 +1179,11 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	// Transactions which tolerate write skew can commit at a pushed timestamp
+	// without refreshing their reads.
+	if tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1288,6 +1365,40 @@ func (tc *TxnCoordSender) Step(ctx context.Context) error {
 	return tc.interceptorAlloc.txnSeqNumAllocator.stepLocked(ctx)
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (tc *TxnCoordSender) StepReadTimestamp(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !tc.mu.txn.IsoLevel.PerStatementReadSnapshot() {
+		return nil
+	}
+	// A transaction whose commit timestamp is fixed cannot move its read
+	// timestamp, so it keeps reading from its original snapshot. A transaction
+	// which is not pending will reject the statement's first request, so there
+	// is no snapshot to establish.
+	if tc.mu.txn.CommitTimestampFixed || tc.mu.txnState != txnPending {
+		return nil
+	}
+
+	// Move the read timestamp forward to the present so that the statement
+	// observes all writes which committed before it began. The write timestamp
+	// is forwarded along with it. Because the transaction tolerates write skew,
+	// the reads performed by earlier statements at lower timestamps do not need
+	// to be refreshed. Unlike a refresh, this does not clear the WriteTooOld
+	// flag, which must still force a retry if an earlier statement's write was
+	// moved above a conflicting committed value.
+	now := tc.clock.Now()
+	tc.mu.txn.WriteTimestamp.Forward(now)
+	tc.mu.txn.ReadTimestamp.Forward(tc.mu.txn.WriteTimestamp)
+	// The observed timestamps were captured before the new read timestamp, so
+	// they cannot be used to limit the uncertainty interval of the new snapshot.
+	tc.mu.txn.ResetObservedTimestamps()
+	tc.mu.txn.GlobalUncertaintyLimit.Forward(now.Add(tc.clock.MaxOffset().Nanoseconds(), 0))
+	tc.interceptorAlloc.txnSpanRefresher.resetRefreshSpansLocked(tc.mu.txn.ReadTimestamp)
+	log.VEventf(ctx, 2, "stepped read timestamp to %s", tc.mu.txn.ReadTimestamp)
+	return nil
+}
+
 // SetReadSeqNum is part of the TxnSender interface.
 func (tc *TxnCoordSender) SetReadSeqNum(seq enginepb.TxnSeq) error {
 	tc.mu.Lock()
@@ -1376,11 +1487,35 @@ func (tc *TxnCoordSender) ClearTxnRetryableErr(ctx context.Context) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 	if tc.mu.txnState == txnRetryableError {
+		tc.maybeApplyDeferredEpochBumpLocked(ctx)
 		tc.mu.storedRetryableErr = nil
 		tc.mu.txnState = txnPending
 	}
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (tc *TxnCoordSender) PrepareForPartialRetry(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !tc.epochBumpDeferredLocked() {
+		return errors.Newf(
+			"cannot prepare for partial retry in state %s; the transaction must be retried from the beginning",
+			tc.mu.txnState)
+	}
+	// The transaction stays in its current epoch, so it keeps the locks that it
+	// acquired before the failed operation. The client is expected to roll back
+	// to a savepoint to discard the failed operation's writes. The write
+	// timestamp is moved past the conflict that caused the error, so that the
+	// retried operation does not run into the same conflict again.
+	newTxn := &tc.mu.storedRetryableErr.Transaction
+	tc.mu.txn.WriteTimestamp.Forward(newTxn.ReadTimestamp)
+	tc.mu.txn.UpgradePriority(newTxn.Priority)
+	log.VEventf(ctx, 2, "preparing for partial retry after: %s", tc.mu.storedRetryableErr)
+	tc.mu.storedRetryableErr = nil
+	tc.mu.txnState = txnPending
+	return nil
+}
+
 // HasPerformedReads is part of the TxnSender interface.
 func (tc *TxnCoordSender) HasPerformedReads() bool {
 	tc.mu.Lock()
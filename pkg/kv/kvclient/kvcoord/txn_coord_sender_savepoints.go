@@ -120,6 +120,12 @@ func (tc *TxnCoordSender) RollbackToSavepoint(ctx context.Context, s kv.Savepoin
 		return unimplemented.New("rollback_error", "cannot rollback to savepoint after error")
 	}
 
+	// A rollback after a retryable error retries the transaction from the
+	// savepoint onwards, so it needs the epoch bump that was deferred in case
+	// of a partial retry. A client that wants a partial retry calls
+	// PrepareForPartialRetry before rolling back instead.
+	tc.maybeApplyDeferredEpochBumpLocked(ctx)
+
 	sp := s.(*savepoint)
 	err := tc.checkSavepointLocked(sp)
 	if err != nil {
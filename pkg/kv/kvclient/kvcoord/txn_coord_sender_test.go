@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -2039,6 +2040,65 @@ func TestAbortReadOnlyTransaction(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderPartialRetry verifies that a transaction with
+// per-statement read snapshots does not move to a new epoch when it runs into
+// a WriteTooOld conflict, and that it can roll back to a savepoint and retry
+// the conflicting operation while keeping its earlier writes. Other
+// transactions must be retried from the beginning.
+func TestTxnCoordSenderPartialRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	for _, isoLevel := range []isolation.Level{isolation.Serializable, isolation.ReadCommitted} {
+		t.Run(isoLevel.String(), func(t *testing.T) {
+			keyA := roachpb.Key("a-" + isoLevel.String())
+			keyB := roachpb.Key("b-" + isoLevel.String())
+
+			txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+			require.NoError(t, txn.SetIsoLevel(isoLevel))
+			require.NoError(t, txn.Put(ctx, keyA, "a"))
+			sp, err := txn.CreateSavepoint(ctx)
+			require.NoError(t, err)
+
+			// Read keyB, then let a concurrent writer write to it, so that the
+			// transaction's write to keyB can neither be performed at its
+			// timestamp nor refreshed.
+			_, err = txn.Get(ctx, keyB)
+			require.NoError(t, err)
+			require.NoError(t, s.DB.Put(ctx, keyB, "other"))
+			err = txn.Put(ctx, keyB, "b")
+			require.True(t, errors.HasType(err, (*kvpb.TransactionRetryWithProtoRefreshError)(nil)), "%+v", err)
+
+			if isoLevel == isolation.Serializable {
+				require.Equal(t, enginepb.TxnEpoch(1), txn.Epoch())
+				require.Error(t, txn.PrepareForPartialRetry(ctx))
+				txn.PrepareForRetry(ctx)
+				require.NoError(t, txn.Rollback(ctx))
+				return
+			}
+
+			require.Equal(t, enginepb.TxnEpoch(0), txn.Epoch())
+			require.NoError(t, txn.PrepareForPartialRetry(ctx))
+			require.Equal(t, enginepb.TxnEpoch(0), txn.Epoch())
+			require.NoError(t, txn.RollbackToSavepoint(ctx, sp))
+			require.NoError(t, txn.StepReadTimestamp(ctx))
+			require.NoError(t, txn.Put(ctx, keyB, "b"))
+			require.NoError(t, txn.Commit(ctx))
+
+			for key, exp := range map[string]string{string(keyA): "a", string(keyB): "b"} {
+				res, err := s.DB.Get(ctx, key)
+				require.NoError(t, err)
+				v, err := res.Value.GetBytes()
+				require.NoError(t, err)
+				require.Equal(t, exp, string(v))
+			}
+		})
+	}
+}
+
 // TestEndWriteRestartReadOnlyTransaction verifies that if
 // a transaction writes, then restarts and turns read-only,
 // an explicit EndTxn call is still sent if retry- able
@@ -104,6 +104,14 @@ var MaxTxnRefreshSpansBytes = settings.RegisterIntSetting(
 // the timestamp cache entries for these reads are updated and the transaction
 // is free to update its provisional commit timestamp without needing to
 // restart.
+//
+// Transactions running at isolation levels which tolerate write skew (see
+// isolation.Level.ToleratesWriteSkew) do not need to validate their reads
+// before committing at a pushed timestamp, so the txnSpanRefresher never
+// refreshes them in preparation for a commit. Transactions with per-statement
+// read snapshots discard their refresh spans whenever a new statement begins
+// (see resetRefreshSpansLocked), so refreshes are only used to keep the reads
+// of a single statement consistent.
 type txnSpanRefresher struct {
 	st      *cluster.Settings
 	knobs   *ClientTestingKnobs
@@ -437,7 +445,11 @@ func (sr *txnSpanRefresher) maybeRefreshPreemptivelyLocked(
 
 	// If true, this batch is guaranteed to fail without a refresh.
 	args, hasET := ba.GetArg(kvpb.EndTxn)
-	refreshInevitable := hasET && args.(*kvpb.EndTxnRequest).Commit
+	// Transactions which tolerate write skew are allowed to commit at a pushed
+	// write timestamp without refreshing their reads, so they never need a
+	// refresh to commit.
+	refreshInevitable := hasET && args.(*kvpb.EndTxnRequest).Commit &&
+		!ba.Txn.IsoLevel.ToleratesWriteSkew()
 
 	// If neither condition is true, defer the refresh.
 	if !refreshFree && !refreshInevitable && !force {
@@ -700,6 +712,17 @@ func (sr *txnSpanRefresher) importLeafFinalState(
 	}
 }
 
+// resetRefreshSpansLocked discards the refresh spans accumulated so far and
+// records that the transaction's reads are now valid up to the provided read
+// timestamp. It is used by transactions with per-statement read snapshots when
+// they establish a new snapshot: the reads of earlier statements need not be
+// refreshed, so the refresh spans are scoped to the current statement.
+func (sr *txnSpanRefresher) resetRefreshSpansLocked(readTimestamp hlc.Timestamp) {
+	sr.refreshFootprint.clear()
+	sr.refreshInvalid = false
+	sr.refreshedTimestamp.Forward(readTimestamp)
+}
+
 // epochBumpedLocked implements the txnInterceptor interface.
 func (sr *txnSpanRefresher) epochBumpedLocked() {
 	sr.refreshFootprint.clear()
@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -539,6 +540,72 @@ func TestTxnSpanRefresherPreemptiveRefresh(t *testing.T) {
 	require.False(t, tsr.refreshInvalid)
 }
 
+// TestTxnSpanRefresherWeakIsolation tests that the txnSpanRefresher does not
+// refresh transactions which tolerate write skew before they commit, and that
+// resetting the refresh spans scopes them to the current statement.
+func TestTxnSpanRefresherWeakIsolation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	tsr, mockSender := makeMockTxnSpanRefresher()
+
+	txn := makeTxnProto()
+	txn.IsoLevel = isolation.ReadCommitted
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	// Send a Scan request to accumulate refresh spans.
+	ba := &kvpb.BatchRequest{}
+	ba.Header = kvpb.Header{Txn: &txn}
+	scanArgs := kvpb.ScanRequest{RequestHeader: kvpb.RequestHeader{Key: keyA, EndKey: keyB}}
+	ba.Add(&scanArgs)
+
+	mockSender.MockSend(func(ba *kvpb.BatchRequest) (*kvpb.BatchResponse, *kvpb.Error) {
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		return br, nil
+	})
+
+	br, pErr := tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, []roachpb.Span{scanArgs.Span()}, tsr.refreshFootprint.asSlice())
+
+	// Push the txn and send an EndTxn request. No refresh should be performed
+	// before the EndTxn is issued at the pushed timestamp.
+	txn.WriteTimestamp = txn.WriteTimestamp.Add(1, 0)
+	origReadTs := txn.ReadTimestamp
+	pushedWriteTs := txn.WriteTimestamp
+
+	ba.Requests = nil
+	ba.Add(&kvpb.EndTxnRequest{Commit: true})
+
+	mockSender.MockSend(func(ba *kvpb.BatchRequest) (*kvpb.BatchResponse, *kvpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		require.IsType(t, &kvpb.EndTxnRequest{}, ba.Requests[0].GetInner())
+
+		// The transaction should not be refreshed.
+		require.Equal(t, origReadTs, ba.Txn.ReadTimestamp)
+		require.Equal(t, pushedWriteTs, ba.Txn.WriteTimestamp)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+
+	br, pErr = tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, int64(0), tsr.refreshSuccess.Count())
+	require.Equal(t, int64(0), tsr.refreshFail.Count())
+
+	// Resetting the refresh spans discards the spans of earlier statements.
+	tsr.resetRefreshSpansLocked(pushedWriteTs)
+	require.True(t, tsr.refreshFootprint.empty())
+	require.False(t, tsr.refreshInvalid)
+	require.Equal(t, pushedWriteTs, tsr.refreshedTimestamp)
+}
+
 // TestTxnSpanRefresherSplitEndTxnOnAutoRetry tests that EndTxn requests are
 // split into their own sub-batch on auto-retries after a successful refresh.
 // This is done to avoid starvation.
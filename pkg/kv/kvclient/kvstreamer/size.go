@@ -24,8 +24,10 @@ const (
 	int32Size                 = int64(unsafe.Sizeof(int32(0)))
 	requestUnionSliceOverhead = int64(unsafe.Sizeof([]kvpb.RequestUnion{}))
 	requestUnionOverhead      = int64(unsafe.Sizeof(kvpb.RequestUnion{}))
-	requestOverhead           = int64(unsafe.Sizeof(kvpb.RequestUnion_Get{}) +
+	getRequestOverhead        = int64(unsafe.Sizeof(kvpb.RequestUnion_Get{}) +
 		unsafe.Sizeof(kvpb.GetRequest{}))
+	scanRequestOverhead = int64(unsafe.Sizeof(kvpb.RequestUnion_Scan{}) +
+		unsafe.Sizeof(kvpb.ScanRequest{}))
 	responseUnionOverhead = int64(unsafe.Sizeof(kvpb.ResponseUnion_Get{}))
 	getResponseOverhead   = int64(unsafe.Sizeof(kvpb.GetResponse{}))
 	scanResponseOverhead  = int64(unsafe.Sizeof(kvpb.ScanResponse{}))
@@ -33,11 +35,14 @@ const (
 
 var zeroInt32Slice []int32
 
+// requestOverhead is the overhead of a single request. GetRequest and
+// ScanRequest have slightly different overheads, so the larger of the two is
+// used for both.
+var requestOverhead = getRequestOverhead
+
 func init() {
-	scanRequestOverhead := int64(unsafe.Sizeof(kvpb.RequestUnion_Scan{}) +
-		unsafe.Sizeof(kvpb.ScanRequest{}))
-	if requestOverhead != scanRequestOverhead {
-		panic("GetRequest and ScanRequest have different overheads")
+	if scanRequestOverhead > requestOverhead {
+		requestOverhead = scanRequestOverhead
 	}
 	scanResponseUnionOverhead := int64(unsafe.Sizeof(kvpb.ResponseUnion_Scan{}))
 	if responseUnionOverhead != scanResponseUnionOverhead {
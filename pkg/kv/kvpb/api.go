@@ -1329,9 +1329,45 @@ func flagForLockStrength(l lock.Strength) flag {
 	return 0
 }
 
+// flagForLockDurability returns the flags that a locking request must carry
+// when acquiring locks with the provided durability. Acquiring replicated
+// locks writes to the lock table keyspace, so it must go through replication.
+func flagForLockDurability(l lock.Strength, replicated bool) flag {
+	if l != lock.None && replicated {
+		return isWrite
+	}
+	return 0
+}
+
+// KeyLockingDurability returns the durability of the locks acquired by the
+// request, if any.
+func (gr *GetRequest) KeyLockingDurability() lock.Durability {
+	return keyLockingDurability(gr.KeyLockingReplicated)
+}
+
+// KeyLockingDurability returns the durability of the locks acquired by the
+// request, if any.
+func (sr *ScanRequest) KeyLockingDurability() lock.Durability {
+	return keyLockingDurability(sr.KeyLockingReplicated)
+}
+
+// KeyLockingDurability returns the durability of the locks acquired by the
+// request, if any.
+func (rsr *ReverseScanRequest) KeyLockingDurability() lock.Durability {
+	return keyLockingDurability(rsr.KeyLockingReplicated)
+}
+
+func keyLockingDurability(replicated bool) lock.Durability {
+	if replicated {
+		return lock.Replicated
+	}
+	return lock.Unreplicated
+}
+
 func (gr *GetRequest) flags() flag {
 	maybeLocking := flagForLockStrength(gr.KeyLocking)
-	return isRead | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
+	maybeWrite := flagForLockDurability(gr.KeyLocking, gr.KeyLockingReplicated)
+	return isRead | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 func (*PutRequest) flags() flag {
@@ -1421,12 +1457,14 @@ func (*RevertRangeRequest) flags() flag {
 
 func (sr *ScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(sr.KeyLocking)
-	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
+	maybeWrite := flagForLockDurability(sr.KeyLocking, sr.KeyLockingReplicated)
+	return isRead | isRange | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 func (rsr *ReverseScanRequest) flags() flag {
 	maybeLocking := flagForLockStrength(rsr.KeyLocking)
-	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh | canSkipLocked
+	maybeWrite := flagForLockDurability(rsr.KeyLocking, rsr.KeyLockingReplicated)
+	return isRead | isRange | isReverse | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh | canSkipLocked
 }
 
 // EndTxn updates the timestamp cache to prevent replays.
@@ -53,6 +53,7 @@ func PrepareTransactionForRetry(
 		// TODO(andrei): Should we preserve the ObservedTimestamps across the
 		// restart?
 		errTxnPri := txn.Priority
+		errTxnIsoLevel := txn.IsoLevel
 		// Start the new transaction at the current time from the local clock.
 		// The local hlc should have been advanced to at least the error's
 		// timestamp already.
@@ -69,6 +70,8 @@ func PrepareTransactionForRetry(
 		)
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+		// The new transaction keeps the isolation level of the aborted one.
+		txn.IsoLevel = errTxnIsoLevel
 	case *ReadWithinUncertaintyIntervalError:
 		txn.WriteTimestamp.Forward(tErr.RetryTimestamp())
 	case *TransactionPushError:
@@ -117,6 +117,13 @@ func ClearRange(
 	} else if len(intents) > 0 {
 		return result.Result{}, &kvpb.WriteIntentError{Intents: intents}
 	}
+	// Replicated locks that are not intents are not removed by the clear below
+	// either, so they are returned for the caller to resolve as well.
+	if err := storage.MVCCCheckForReplicatedLockConflicts(
+		ctx, readWriter, nil /* txn */, roachpb.Span{Key: from, EndKey: to}, maxIntents,
+	); err != nil {
+		return result.Result{}, err
+	}
 
 	// Before clearing, compute the delta in MVCCStats.
 	statsDelta, err := computeStatsDelta(ctx, readWriter, cArgs, from, to)
@@ -377,6 +377,20 @@ func EndTxn(
 				return result.Result{}, err
 			}
 
+			// A transaction which tolerates write skew can get here with a
+			// provisional commit timestamp above the one in the batch header, if it
+			// was pushed. It must not stage its record at that timestamp. The
+			// QueryIntent requests that verify its in-flight writes compare against
+			// the header's timestamp, so the coordinator could find an in-flight
+			// write that was pushed along with the transaction and consider the
+			// parallel commit failed, while transaction recovery finds the same
+			// write at the staging timestamp and considers the transaction
+			// committed. Instead, let the coordinator retry at the new timestamp.
+			if h.Txn.WriteTimestamp.Less(reply.Txn.WriteTimestamp) {
+				return result.Result{}, kvpb.NewTransactionRetryError(
+					kvpb.RETRY_SERIALIZABLE, "txn pushed before staging")
+			}
+
 			reply.Txn.Status = roachpb.STAGING
 			reply.StagingTimestamp = reply.Txn.WriteTimestamp
 			if err := updateStagingTxn(ctx, readWriter, ms, key, args, reply.Txn); err != nil {
@@ -505,8 +519,9 @@ func IsEndTxnTriggeringRetryError(
 		isTxnPushed := txn.WriteTimestamp != readTimestamp
 
 		// Return a transaction retry error if the commit timestamp isn't equal to
-		// the txn timestamp.
-		if isTxnPushed {
+		// the txn timestamp. Transactions which tolerate write skew may commit at
+		// a pushed timestamp without validating their reads.
+		if isTxnPushed && !txn.IsoLevel.ToleratesWriteSkew() {
 			retry, reason = true, kvpb.RETRY_SERIALIZABLE
 		}
 	}
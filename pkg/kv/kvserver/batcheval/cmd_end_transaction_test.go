@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/abortspan"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -1081,6 +1082,101 @@ func TestEndTxnUpdatesTransactionRecord(t *testing.T) {
 	}
 }
 
+// TestIsEndTxnTriggeringRetryErrorIsoLevel tests that a pushed transaction is
+// only forced to retry by its EndTxn request if its isolation level does not
+// tolerate write skew.
+func TestIsEndTxnTriggeringRetryErrorIsoLevel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, ts2 := hlc.Timestamp{WallTime: 1}, hlc.Timestamp{WallTime: 2}
+	for _, isoLevel := range []isolation.Level{
+		isolation.Serializable, isolation.Snapshot, isolation.ReadCommitted,
+	} {
+		t.Run(isoLevel.String(), func(t *testing.T) {
+			txn := roachpb.MakeTransaction("test", roachpb.Key("a"), 0, ts, 0, 1)
+			txn.IsoLevel = isoLevel
+			args := &kvpb.EndTxnRequest{Commit: true}
+
+			// An unpushed transaction never needs to retry.
+			retry, _, _ := IsEndTxnTriggeringRetryError(&txn, args)
+			require.False(t, retry)
+
+			// A pushed transaction needs to retry unless it tolerates write skew.
+			txn.WriteTimestamp = ts2
+			retry, reason, _ := IsEndTxnTriggeringRetryError(&txn, args)
+			require.Equal(t, !isoLevel.ToleratesWriteSkew(), retry)
+			if retry {
+				require.Equal(t, kvpb.RETRY_SERIALIZABLE, reason)
+			}
+
+			// A transaction which saw a WriteTooOld error always needs to retry.
+			txn.WriteTooOld = true
+			retry, reason, _ = IsEndTxnTriggeringRetryError(&txn, args)
+			require.True(t, retry)
+			require.Equal(t, kvpb.RETRY_WRITE_TOO_OLD, reason)
+		})
+	}
+}
+
+// TestEndTxnWeakIsolationPushedBeforeStaging tests that a transaction which
+// tolerates write skew and was pushed can commit at the pushed timestamp, but
+// does not stage its record there during a parallel commit.
+func TestEndTxnWeakIsolationPushedBeforeStaging(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	k := roachpb.Key("a")
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey(k),
+		EndKey:   roachpb.RKey("z"),
+	}
+	ts, ts2 := hlc.Timestamp{WallTime: 1}, hlc.Timestamp{WallTime: 2}
+	txn := roachpb.MakeTransaction("test", k, 0, ts, 0, 1)
+	txn.IsoLevel = isolation.ReadCommitted
+
+	testutils.RunTrueAndFalse(t, "parallelCommit", func(t *testing.T, parallelCommit bool) {
+		db := storage.NewDefaultInMemForTesting()
+		defer db.Close()
+		batch := db.NewBatch()
+		defer batch.Close()
+
+		req := kvpb.EndTxnRequest{
+			RequestHeader: kvpb.RequestHeader{Key: txn.Key},
+			Commit:        true,
+			LockSpans:     []roachpb.Span{{Key: roachpb.Key("b")}},
+		}
+		if parallelCommit {
+			req.InFlightWrites = []roachpb.SequencedWrite{{Key: k, Sequence: 1}}
+		}
+		var resp kvpb.EndTxnResponse
+		_, err := EndTxn(ctx, batch, CommandArgs{
+			EvalCtx: (&MockEvalCtx{
+				Desc: &desc,
+				// A PushTxn(TIMESTAMP) request bumped the minimum timestamp that the
+				// transaction can be committed with.
+				MinTxnCommitTSFn: func() hlc.Timestamp { return ts2 },
+			}).EvalContext(),
+			Args: &req,
+			Header: kvpb.Header{
+				Timestamp: ts,
+				Txn:       txn.Clone(),
+			},
+		}, &resp)
+
+		if parallelCommit {
+			require.Regexp(t, `TransactionRetryError: retry txn \(RETRY_SERIALIZABLE - txn pushed before staging\)`, err)
+			require.Equal(t, ts2, resp.Txn.WriteTimestamp)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, roachpb.COMMITTED, resp.Txn.Status)
+		require.Equal(t, ts2, resp.Txn.WriteTimestamp)
+	})
+}
+
 // TestPartialRollbackOnEndTransaction verifies that the intent
 // resolution performed synchronously as a side effect of
 // EndTransaction request properly takes into account the ignored
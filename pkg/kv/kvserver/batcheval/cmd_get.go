@@ -22,12 +22,30 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(kvpb.Get, DefaultDeclareIsolatedKeys, Get)
+	RegisterReadOnlyOrReadWriteCommand(kvpb.Get, DefaultDeclareIsolatedKeys, Get, GetWithReplicatedLocks)
 }
 
 // Get returns the value for a specified key.
 func Get(
 	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return get(ctx, reader, nil /* readWriter */, cArgs, resp)
+}
+
+// GetWithReplicatedLocks is like Get, but for requests that acquire replicated
+// locks, which are written through the provided ReadWriter.
+func GetWithReplicatedLocks(
+	ctx context.Context, readWriter storage.ReadWriter, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return get(ctx, readWriter, readWriter, cArgs, resp)
+}
+
+func get(
+	ctx context.Context,
+	reader storage.Reader,
+	readWriter storage.ReadWriter,
+	cArgs CommandArgs,
+	resp kvpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*kvpb.GetRequest)
 	h := cArgs.Header
@@ -84,8 +102,15 @@ func Get(
 
 	var res result.Result
 	if args.KeyLocking != lock.None && h.Txn != nil && getRes.Value != nil {
-		acq := roachpb.MakeLockAcquisition(h.Txn, args.Key, lock.Unreplicated)
+		dur := args.KeyLockingDurability()
+		acq := roachpb.MakeLockAcquisition(h.Txn, args.Key, dur, args.KeyLocking)
 		res.Local.AcquiredLocks = []roachpb.LockAcquisition{acq}
+		if err := acquireReplicatedLocksOrCheckConflicts(
+			ctx, reader, readWriter, cArgs, h.Txn, args.KeyLocking, dur,
+			res.Local.AcquiredLocks,
+		); err != nil {
+			return result.Result{}, err
+		}
 	}
 	res.Local.EncounteredIntents = intents
 	return res, err
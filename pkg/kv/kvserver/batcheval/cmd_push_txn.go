@@ -258,6 +258,11 @@ func PushTxn(
 	case txnwait.CanPushWithPriority(args.PusherTxn.Priority, reply.PusheeTxn.Priority):
 		reason = "pusher has priority"
 		pusherWins = true
+	case pushType == kvpb.PUSH_TIMESTAMP && reply.PusheeTxn.IsoLevel.ToleratesWriteSkew():
+		// Pushing the timestamp of a transaction which tolerates write skew does
+		// not force it to retry, so the pusher always wins.
+		reason = "pushee tolerates write skew"
+		pusherWins = true
 	case args.Force:
 		reason = "forced push"
 		pusherWins = true
@@ -121,6 +121,11 @@ func computeMinIntentTimestamp(
 		if err != nil {
 			return hlc.Timestamp{}, nil, err
 		}
+		if !engineKey.IsLockTableIntentKey() {
+			// Replicated locks that are not intents have no provisional value
+			// that could later commit below the resolved timestamp.
+			continue
+		}
 		lockedKey, err := keys.DecodeLockTableSingleKey(engineKey.Key)
 		if err != nil {
 			return hlc.Timestamp{}, nil, errors.Wrapf(err, "decoding LockTable key: %v", lockedKey)
@@ -21,7 +21,9 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(kvpb.ReverseScan, DefaultDeclareIsolatedKeys, ReverseScan)
+	RegisterReadOnlyOrReadWriteCommand(
+		kvpb.ReverseScan, DefaultDeclareIsolatedKeys, ReverseScan, ReverseScanWithReplicatedLocks,
+	)
 }
 
 // ReverseScan scans the key range specified by start key through
@@ -30,6 +32,24 @@ func init() {
 // (MaxInt64 for no limit).
 func ReverseScan(
 	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return reverseScan(ctx, reader, nil /* readWriter */, cArgs, resp)
+}
+
+// ReverseScanWithReplicatedLocks is like ReverseScan, but for requests that acquire
+// replicated locks, which are written through the provided ReadWriter.
+func ReverseScanWithReplicatedLocks(
+	ctx context.Context, readWriter storage.ReadWriter, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return reverseScan(ctx, readWriter, readWriter, cArgs, resp)
+}
+
+func reverseScan(
+	ctx context.Context,
+	reader storage.Reader,
+	readWriter storage.ReadWriter,
+	cArgs CommandArgs,
+	resp kvpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*kvpb.ReverseScanRequest)
 	h := cArgs.Header
@@ -110,7 +130,10 @@ func ReverseScan(
 	}
 
 	if args.KeyLocking != lock.None && h.Txn != nil {
-		err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+		err = acquireLocksOnKeys(
+			ctx, reader, readWriter, cArgs, &res, h.Txn,
+			args.KeyLocking, args.KeyLockingDurability(), args.ScanFormat, &scanRes,
+		)
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -21,7 +21,9 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(kvpb.Scan, DefaultDeclareIsolatedKeys, Scan)
+	RegisterReadOnlyOrReadWriteCommand(
+		kvpb.Scan, DefaultDeclareIsolatedKeys, Scan, ScanWithReplicatedLocks,
+	)
 }
 
 // Scan scans the key range specified by start key through end key
@@ -30,6 +32,24 @@ func init() {
 // (MaxInt64 for no limit).
 func Scan(
 	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return scan(ctx, reader, nil /* readWriter */, cArgs, resp)
+}
+
+// ScanWithReplicatedLocks is like Scan, but for requests that acquire
+// replicated locks, which are written through the provided ReadWriter.
+func ScanWithReplicatedLocks(
+	ctx context.Context, readWriter storage.ReadWriter, cArgs CommandArgs, resp kvpb.Response,
+) (result.Result, error) {
+	return scan(ctx, readWriter, readWriter, cArgs, resp)
+}
+
+func scan(
+	ctx context.Context,
+	reader storage.Reader,
+	readWriter storage.ReadWriter,
+	cArgs CommandArgs,
+	resp kvpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*kvpb.ScanRequest)
 	h := cArgs.Header
@@ -110,7 +130,10 @@ func Scan(
 	}
 
 	if args.KeyLocking != lock.None && h.Txn != nil {
-		err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+		err = acquireLocksOnKeys(
+			ctx, reader, readWriter, cArgs, &res, h.Txn,
+			args.KeyLocking, args.KeyLockingDurability(), args.ScanFormat, &scanRes,
+		)
 		if err != nil {
 			return result.Result{}, err
 		}
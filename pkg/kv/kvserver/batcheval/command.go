@@ -65,7 +65,9 @@ type Command struct {
 	// it writes to the engine it should also update *CommandArgs.Stats. It
 	// should treat the provided request as immutable.
 	//
-	// Only one of these is ever set at a time.
+	// Usually only one of these is set. Commands registered through
+	// RegisterReadOnlyOrReadWriteCommand set both, and EvalRW is used for the
+	// requests that are writes (see kvpb.IsReadOnly).
 	EvalRW func(context.Context, storage.ReadWriter, CommandArgs, kvpb.Response) (result.Result, error)
 	EvalRO func(context.Context, storage.Reader, CommandArgs, kvpb.Response) (result.Result, error)
 }
@@ -102,6 +104,23 @@ func RegisterReadOnlyCommand(
 	})
 }
 
+// RegisterReadOnlyOrReadWriteCommand makes a command available for execution
+// that is read-only for most requests, but is a write for some, e.g. locking
+// reads that acquire replicated locks. The read-write implementation is used
+// for the latter. It must only be called before any evaluation takes place.
+func RegisterReadOnlyOrReadWriteCommand(
+	method kvpb.Method,
+	declare DeclareKeysFunc,
+	implRO func(context.Context, storage.Reader, CommandArgs, kvpb.Response) (result.Result, error),
+	implRW func(context.Context, storage.ReadWriter, CommandArgs, kvpb.Response) (result.Result, error),
+) {
+	register(method, Command{
+		DeclareKeys: declare,
+		EvalRW:      implRW,
+		EvalRO:      implRO,
+	})
+}
+
 func register(method kvpb.Method, command Command) {
 	if !cmds[method].isEmpty() {
 		log.Fatalf(context.TODO(), "cannot overwrite previously registered method %v", method)
@@ -13,6 +13,7 @@ package batcheval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
@@ -104,11 +105,25 @@ func readProvisionalVal(
 
 }
 
-// acquireUnreplicatedLocksOnKeys adds an unreplicated lock acquisition by the
-// transaction to the provided result.Result for each key in the scan result.
-func acquireUnreplicatedLocksOnKeys(
+// acquireLocksOnKeys acquires locks with the provided strength and durability
+// by the transaction on each key in the scan result, and adds the lock
+// acquisitions to the provided result.Result.
+//
+// Replicated locks are written to the lock table keyspace through the
+// provided ReadWriter, which is nil when the request is evaluated as a
+// read-only command. Unreplicated locks are only tracked in the in-memory lock
+// table, but the keys are still checked for conflicting replicated locks held
+// by other transactions, which are not surfaced by the scan itself. In either
+// case, conflicts are returned as a WriteIntentError.
+func acquireLocksOnKeys(
+	ctx context.Context,
+	reader storage.Reader,
+	readWriter storage.ReadWriter,
+	cArgs CommandArgs,
 	res *result.Result,
 	txn *roachpb.Transaction,
+	str lock.Strength,
+	dur lock.Durability,
 	scanFmt kvpb.ScanFormat,
 	scanRes *storage.MVCCScanResult,
 ) error {
@@ -116,25 +131,75 @@ func acquireUnreplicatedLocksOnKeys(
 	switch scanFmt {
 	case kvpb.BATCH_RESPONSE:
 		var i int
-		return storage.MVCCScanDecodeKeyValues(scanRes.KVData, func(key storage.MVCCKey, _ []byte) error {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(key.Key), lock.Unreplicated)
+		err := storage.MVCCScanDecodeKeyValues(scanRes.KVData, func(key storage.MVCCKey, _ []byte) error {
+			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(key.Key), dur, str)
 			i++
 			return nil
 		})
+		if err != nil {
+			return err
+		}
 	case kvpb.KEY_VALUES:
 		for i, row := range scanRes.KVs {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(row.Key), lock.Unreplicated)
+			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, copyKey(row.Key), dur, str)
 		}
-		return nil
 	case kvpb.COL_BATCH_RESPONSE:
-		return errors.AssertionFailedf("unexpectedly acquiring unreplicated locks with COL_BATCH_RESPONSE scan format")
+		return errors.AssertionFailedf("unexpectedly acquiring locks with COL_BATCH_RESPONSE scan format")
 	default:
 		panic("unexpected scanFormat")
 	}
+	return acquireReplicatedLocksOrCheckConflicts(
+		ctx, reader, readWriter, cArgs, txn, str, dur, res.Local.AcquiredLocks)
+}
+
+// acquireReplicatedLocksOrCheckConflicts persists the provided lock
+// acquisitions if they are replicated, accounting for them in cArgs.Stats.
+// Otherwise, it checks the keys of the acquisitions for conflicting replicated
+// locks held by other transactions, unless replicated locks cannot exist yet
+// because the cluster version that introduced them is not active.
+func acquireReplicatedLocksOrCheckConflicts(
+	ctx context.Context,
+	reader storage.Reader,
+	readWriter storage.ReadWriter,
+	cArgs CommandArgs,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	dur lock.Durability,
+	acqs []roachpb.LockAcquisition,
+) error {
+	if len(acqs) == 0 {
+		return nil
+	}
+	st := cArgs.EvalCtx.ClusterSettings()
+	maxConflicts := storage.MaxIntentsPerWriteIntentError.Get(&st.SV)
+	if dur == lock.Replicated {
+		if readWriter == nil {
+			return errors.AssertionFailedf("replicated lock acquisition evaluated as a read-only command")
+		}
+		for i := range acqs {
+			if err := storage.MVCCAcquireLock(
+				ctx, readWriter, txn, str, acqs[i].Key, cArgs.Stats, maxConflicts,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !st.Version.IsActive(ctx, clusterversion.V23_1ReplicatedLocks) {
+		return nil
+	}
+	// The keys are sorted, in either direction, so a single scan over the lock
+	// table between the first and last key covers all of them.
+	span := roachpb.Span{Key: acqs[0].Key, EndKey: acqs[len(acqs)-1].Key}
+	if span.EndKey.Compare(span.Key) < 0 {
+		span.Key, span.EndKey = span.EndKey, span.Key
+	}
+	span.EndKey = span.EndKey.Next()
+	return storage.MVCCCheckForReplicatedLockConflicts(ctx, reader, txn, span, maxConflicts)
 }
 
 // copyKey copies the provided roachpb.Key into a new byte slice, returning the
-// copy. It is used in acquireUnreplicatedLocksOnKeys for two reasons:
+// copy. It is used in acquireLocksOnKeys for two reasons:
 //  1. the keys in an MVCCScanResult, regardless of the scan format used, point
 //     to a small number of large, contiguous byte slices. These "MVCCScan
 //     batches" contain keys and their associated values in the same backing
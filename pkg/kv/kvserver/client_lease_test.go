@@ -30,12 +30,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/allocator/storepool"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
@@ -1582,3 +1585,146 @@ func TestLeaseUpgradeVersionGate(t *testing.T) {
 	})
 	tc.WaitForLeaseUpgrade(ctx, t, desc)
 }
+
+// TestReplicatedLocksSurviveLeaseTransferAndRestart verifies that replicated
+// locks acquired by locking reads, unlike unreplicated locks, are retained
+// across a lease transfer and a restart of the leaseholder, and that they are
+// released once the transaction that holds them commits.
+func TestReplicatedLocksSurviveLeaseTransferAndRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// Prevent the conflicting writes below from aborting the lock holder if its
+	// heartbeats are delayed while the leaseholder restarts.
+	defer txnwait.TestingOverrideTxnLivenessThreshold(time.Hour)()
+
+	ctx := context.Background()
+	stickyRegistry := server.NewStickyInMemEnginesRegistry()
+	defer stickyRegistry.CloseAllStickyInMemEngines()
+
+	const numNodes = 3
+	serverArgs := make(map[int]base.TestServerArgs)
+	for i := 0; i < numNodes; i++ {
+		serverArgs[i] = base.TestServerArgs{
+			Knobs: base.TestingKnobs{
+				Server: &server.TestingKnobs{
+					StickyEngineRegistry: stickyRegistry,
+				},
+			},
+			StoreSpecs: []base.StoreSpec{
+				{
+					InMemory:               true,
+					StickyInMemoryEngineID: strconv.FormatInt(int64(i), 10),
+				},
+			},
+		}
+	}
+	tc := testcluster.StartTestCluster(t, numNodes,
+		base.TestClusterArgs{
+			ReplicationMode:   base.ReplicationManual,
+			ServerArgsPerNode: serverArgs,
+		})
+	defer tc.Stopper().Stop(ctx)
+
+	key := tc.ScratchRange(t)
+	desc := tc.AddVotersOrFatal(t, key, tc.Targets(1, 2)...)
+	db := tc.Server(0).DB()
+	require.NoError(t, db.Put(ctx, key, "val"))
+
+	// Acquire a replicated lock on the key through a locking read. The
+	// transaction's coordinator lives on n1, which is never restarted.
+	txn := db.NewTxn(ctx, "lock holder")
+	b := txn.NewBatch()
+	b.AddRawRequest(&kvpb.GetRequest{
+		RequestHeader:        kvpb.RequestHeader{Key: key},
+		KeyLocking:           lock.Exclusive,
+		KeyLockingReplicated: true,
+	})
+	require.NoError(t, txn.Run(ctx, b))
+
+	// Wait for the heartbeat loop to write the transaction record. Otherwise, a
+	// push on the new leaseholder after the lease transfer finds no record that
+	// could still be written and considers the transaction aborted.
+	testutils.SucceedsSoon(t, func() error {
+		kv, err := db.Get(ctx, keys.TransactionKey(key, txn.ID()))
+		if err != nil {
+			return err
+		}
+		if !kv.Exists() {
+			return errors.New("transaction record not written yet")
+		}
+		return nil
+	})
+
+	requireConflict := func(err error) error {
+		var wiErr *kvpb.WriteIntentError
+		if !errors.As(err, &wiErr) {
+			return errors.Errorf("expected WriteIntentError, found %v", err)
+		}
+		if len(wiErr.Intents) != 1 || wiErr.Intents[0].Txn.ID != txn.ID() {
+			return errors.Errorf("expected conflict with %s, found %v", txn.ID(), wiErr)
+		}
+		return nil
+	}
+
+	// requireLocked checks that the leaseholder's replica holds the lock and
+	// accounts for it in its stats, and that a conflicting write runs into it.
+	requireLocked := func() {
+		t.Helper()
+		testutils.SucceedsSoon(t, func() error {
+			leaseHolder, err := tc.FindRangeLeaseHolder(desc, nil)
+			if err != nil {
+				return err
+			}
+			srv, err := tc.FindMemberServer(leaseHolder.StoreID)
+			if err != nil {
+				return err
+			}
+			store, err := srv.Stores().GetStore(leaseHolder.StoreID)
+			if err != nil {
+				return err
+			}
+			if lockCount := store.LookupReplica(roachpb.RKey(key)).GetMVCCStats().LockCount; lockCount != 1 {
+				return errors.Errorf("expected 1 lock in the stats, found %d", lockCount)
+			}
+			return requireConflict(storage.MVCCCheckForReplicatedLockConflicts(
+				ctx, store.TODOEngine(), nil /* txn */, roachpb.Span{Key: key}, 0, /* maxConflicts */
+			))
+		})
+		otherTxn := db.NewTxn(ctx, "conflicting writer")
+		b := otherTxn.NewBatch()
+		b.Header.WaitPolicy = lock.WaitPolicy_Error
+		b.Put(key, "other")
+		require.NoError(t, requireConflict(otherTxn.Run(ctx, b)))
+		require.NoError(t, otherTxn.Rollback(ctx))
+	}
+	requireLocked()
+
+	// The lock survives a lease transfer. The new leaseholder's lock table has
+	// no record of the lock, so it is discovered in the replicated state.
+	tc.TransferRangeLeaseOrFatal(t, desc, tc.Target(1))
+	requireLocked()
+
+	// The lock survives a restart of the leaseholder.
+	tc.StopServer(1)
+	require.NoError(t, tc.RestartServer(1))
+	requireLocked()
+
+	// Committing the transaction releases the lock on all replicas.
+	require.NoError(t, txn.Commit(ctx))
+	testutils.SucceedsSoon(t, func() error {
+		for i := 0; i < numNodes; i++ {
+			store := tc.GetFirstStoreFromServer(t, i)
+			if err := storage.MVCCCheckForReplicatedLockConflicts(
+				ctx, store.TODOEngine(), nil /* txn */, roachpb.Span{Key: key}, 0, /* maxConflicts */
+			); err != nil {
+				return errors.Wrapf(err, "n%d", i+1)
+			}
+			if lockCount := store.LookupReplica(roachpb.RKey(key)).GetMVCCStats().LockCount; lockCount != 0 {
+				return errors.Errorf("n%d: expected no locks in the stats, found %d", i+1, lockCount)
+			}
+		}
+		return nil
+	})
+	require.NoError(t, db.Put(ctx, key, "other"))
+}
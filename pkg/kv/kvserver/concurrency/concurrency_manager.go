@@ -513,7 +513,7 @@ func (m *managerImpl) HandleTransactionPushError(
 
 // OnLockAcquired implements the LockManager interface.
 func (m *managerImpl) OnLockAcquired(ctx context.Context, acq *roachpb.LockAcquisition) {
-	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, lock.Exclusive, acq.Durability); err != nil {
+	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, acq.Strength, acq.Durability); err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
 }
@@ -631,6 +631,34 @@ func (r *Request) txnMeta() *enginepb.TxnMeta {
 	return &r.Txn.TxnMeta
 }
 
+// lockStrength returns the strength with which the request accesses the keys
+// in its read-write lock spans. Locking reads and writes both declare their
+// keys with read-write access, so the strength is only Shared if every locking
+// request in the batch is a read that acquires Shared locks. Otherwise, it is
+// conservatively Exclusive.
+func (r *Request) lockStrength() lock.Strength {
+	str := lock.Exclusive
+	if r.Txn == nil {
+		// Non-transactional requests do not acquire locks.
+		return str
+	}
+	for _, ru := range r.Requests {
+		req := ru.GetInner()
+		if !kvpb.IsReadOnly(req) {
+			return lock.Exclusive
+		}
+		if !kvpb.IsLocking(req) {
+			continue
+		}
+		lr, ok := req.(kvpb.LockingReadRequest)
+		if !ok || lr.KeyLockingStrength() != lock.Shared {
+			return lock.Exclusive
+		}
+		str = lock.Shared
+	}
+	return str
+}
+
 func (r *Request) isSingle(m kvpb.Method) bool {
 	if len(r.Requests) != 1 {
 		return false
@@ -397,6 +397,11 @@ func TestConcurrencyManagerBasic(t *testing.T) {
 					dur = scanLockDurability(t, d)
 				}
 
+				str := lock.Exclusive
+				if d.HasArg("strength") {
+					str = concurrency.ScanLockStrength(t, d)
+				}
+
 				// Confirm that the request has a corresponding write request.
 				found := false
 				for _, ru := range guard.Req.Requests {
@@ -418,7 +423,7 @@ func TestConcurrencyManagerBasic(t *testing.T) {
 
 				mon.runSync("acquire lock", func(ctx context.Context) {
 					log.Eventf(ctx, "txn %s @ %s", txn.ID.Short(), key)
-					acq := roachpb.MakeLockAcquisition(txnAcquire, roachpb.Key(key), dur)
+					acq := roachpb.MakeLockAcquisition(txnAcquire, roachpb.Key(key), dur, str)
 					m.OnLockAcquired(ctx, &acq)
 				})
 				return c.waitAndCollect(t, mon)
@@ -0,0 +1,38 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package isolation provides type definitions for isolation level-related
+// concepts used by concurrency control in the key-value layer.
+package isolation
+
+// WeakerThan returns whether the receiver's isolation level is weaker than the
+// parameter's isolation level.
+func (l Level) WeakerThan(l2 Level) bool {
+	// The enum's values increase as the isolation levels get weaker.
+	return l > l2
+}
+
+// ToleratesWriteSkew returns whether the isolation level permits write skew.
+// Transactions running at such isolation levels may commit at a later
+// timestamp than the one at which they performed their reads without first
+// refreshing them, so pushing their timestamp does not force them to retry.
+func (l Level) ToleratesWriteSkew() bool {
+	return l.WeakerThan(Serializable)
+}
+
+// PerStatementReadSnapshot returns whether the isolation level establishes a
+// new read snapshot for each statement in a transaction, rather than a single
+// snapshot for the entire transaction.
+func (l Level) PerStatementReadSnapshot() bool {
+	return l == ReadCommitted
+}
+
+// SafeValue implements redact.SafeValue.
+func (Level) SafeValue() {}
@@ -0,0 +1,67 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package isolation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var all = []Level{Serializable, Snapshot, ReadCommitted}
+
+func TestWeakerThan(t *testing.T) {
+	exp := map[[2]Level]bool{
+		{Serializable, Serializable}:   false,
+		{Serializable, Snapshot}:       false,
+		{Serializable, ReadCommitted}:  false,
+		{Snapshot, Serializable}:       true,
+		{Snapshot, Snapshot}:           false,
+		{Snapshot, ReadCommitted}:      false,
+		{ReadCommitted, Serializable}:  true,
+		{ReadCommitted, Snapshot}:      true,
+		{ReadCommitted, ReadCommitted}: false,
+	}
+	for _, l1 := range all {
+		for _, l2 := range all {
+			t.Run(fmt.Sprintf("%s-%s", l1, l2), func(t *testing.T) {
+				require.Equal(t, exp[[2]Level{l1, l2}], l1.WeakerThan(l2))
+			})
+		}
+	}
+}
+
+func TestToleratesWriteSkew(t *testing.T) {
+	exp := map[Level]bool{
+		Serializable:  false,
+		Snapshot:      true,
+		ReadCommitted: true,
+	}
+	for _, l := range all {
+		t.Run(l.String(), func(t *testing.T) {
+			require.Equal(t, exp[l], l.ToleratesWriteSkew())
+		})
+	}
+}
+
+func TestPerStatementReadSnapshot(t *testing.T) {
+	exp := map[Level]bool{
+		Serializable:  false,
+		Snapshot:      false,
+		ReadCommitted: true,
+	}
+	for _, l := range all {
+		t.Run(l.String(), func(t *testing.T) {
+			require.Equal(t, exp[l], l.PerStatementReadSnapshot())
+		})
+	}
+}
@@ -368,6 +368,11 @@ type lockTableGuardImpl struct {
 	spans              *spanset.SpanSet
 	waitPolicy         lock.WaitPolicy
 	maxWaitQueueLength int
+	// The strength with which the request accesses the keys in its
+	// SpanReadWrite spans. Either Exclusive or Shared. Requests with Shared
+	// strength are compatible with locks held with Shared strength by other
+	// transactions. See Request.lockStrength.
+	str lock.Strength
 
 	// Snapshots of the trees for which this request has some spans. Note that
 	// the lockStates in these snapshots may have been removed from
@@ -597,6 +602,19 @@ func (g *lockTableGuardImpl) IsKeyLockedByConflictingTxn(
 		// The lock is empty but has not yet been deleted.
 		return false, nil
 	}
+	if l.isHeldShared() {
+		// Key locked with Shared strength.
+		if strength == lock.None || strength == lock.Shared {
+			// Non-locking reads and shared locking reads are compatible with
+			// shared locks.
+			return false, nil
+		}
+		if h := l.conflictingSharedHolder(g); h != nil {
+			return true, h.txn
+		}
+		// Only locked by this txn.
+		return false, nil
+	}
 	if !l.holder.locked {
 		// Key reserved.
 		if strength == lock.None {
@@ -656,6 +674,14 @@ func (g *lockTableGuardImpl) isSameTxnAsReservation(ws waitingState) bool {
 	return !ws.held && g.isSameTxn(ws.txn)
 }
 
+// isWaitingOnSelf returns true iff the request is in the waitSelf state.
+// Acquires g.mu.
+func (g *lockTableGuardImpl) isWaitingOnSelf() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.mu.state.kind == waitSelf
+}
+
 // Finds the next lock, after the current one, to actively wait at. If it
 // finds the next lock the request starts actively waiting there, else it is
 // told that it is done waiting. lockTableImpl.finalizedTxnCache is used to
@@ -820,10 +846,14 @@ type lockState struct {
 
 	// Invariant summary (see detailed comments below):
 	// - both holder.locked and waitQ.reservation != nil cannot be true.
+	// - both len(holder.shared) > 0 and waitQ.reservation != nil cannot be
+	//   true.
+	// - both holder.locked and len(holder.shared) > 0 cannot be true.
 	// - if holder.locked and multiple holderInfos have txn != nil: all the
 	//   txns must have the same txn.ID.
 	// - !holder.locked => waitingReaders.Len() == 0. That is, readers wait
-	//   only if the lock is held. They do not wait for a reservation.
+	//   only if the lock is held with Exclusive strength. They do not wait for
+	//   a reservation or for shared lock holders.
 	// - If reservation != nil, that request is not in queuedWriters.
 
 	// Information about whether the lock is held and the holder. We track
@@ -831,10 +861,19 @@ type lockState struct {
 	// go through multiple epochs and TxnSeq and may acquire the same lock in
 	// replicated and unreplicated mode at different stages.
 	holder struct {
+		// locked is true iff the lock is held with Exclusive strength.
 		locked bool
-		// LockStrength is always Exclusive
 		holder [lock.MaxDurability + 1]lockHolderInfo
 
+		// The transactions holding the lock with Shared strength, in the order
+		// in which they acquired it. Shared locks are compatible with each
+		// other, so multiple transactions can hold the lock concurrently, but
+		// they are incompatible with Exclusive locks, so shared holders and an
+		// exclusive holder never coexist. Shared locks are only acquired with
+		// Unreplicated durability, so the lock table is the source of truth for
+		// them.
+		shared []lockHolderInfo
+
 		// The start time of the lockholder being marked as held in the lock table.
 		// NB: In the case of a replicated lock that is held by a transaction, if
 		// there is no wait-queue, the lock is not tracked by the in-memory lock
@@ -949,7 +988,20 @@ type lockWaitQueue struct {
 	//   This is a deadlock caused by the lock table unless req2 partially
 	//   breaks the reservation at A.
 	//
-	// Extension for Shared and Update locks:
+	// Shared locks:
+	// A lock can be held with Shared strength by multiple transactions at once.
+	// Non-locking reads never wait for shared lock holders. Writers and
+	// Exclusive lockers wait in queuedWriters for a shared lock holder from a
+	// different transaction. Shared lockers are compatible with the holders,
+	// so they only enter queuedWriters if there is a transactional waiter with
+	// a lower seqNum that wants an incompatible lock, in which case they wait
+	// for that waiter's transaction just like they would wait for a
+	// reservation holder. Reservations are not yet shared: when the lock is
+	// released, the first waiter gets the reservation irrespective of its
+	// strength, and the shared lockers queued behind a shared reservation
+	// holder are released once it acquires the lock.
+	//
+	// Extension for Update locks and joint Shared reservations:
 	// There are 3 aspects to consider: holders; reservers; the dependencies
 	// that need to be captured when waiting.
 	//
@@ -1064,6 +1116,26 @@ func (l *lockState) safeFormat(sb *redact.StringBuilder, finalizedTxnCache *txnC
 		sb.Printf("txn: %v, ts: %v, seq: %v\n",
 			redact.Safe(txn.ID), redact.Safe(ts), redact.Safe(txn.Sequence))
 	}
+	writeLockHolderInfo := func(sb *redact.StringBuilder, h *lockHolderInfo) {
+		if finalizedTxnCache != nil {
+			finalizedTxn, ok := finalizedTxnCache.get(h.txn.ID)
+			if ok {
+				var statusStr string
+				switch finalizedTxn.Status {
+				case roachpb.COMMITTED:
+					statusStr = "committed"
+				case roachpb.ABORTED:
+					statusStr = "aborted"
+				}
+				sb.Printf("[holder finalized: %s] ", redact.Safe(statusStr))
+			}
+		}
+		sb.Printf("epoch: %d, seqs: [%d", redact.Safe(h.txn.Epoch), redact.Safe(h.seqs[0]))
+		for j := 1; j < len(h.seqs); j++ {
+			sb.Printf(", %d", redact.Safe(h.seqs[j]))
+		}
+		sb.SafeString("]")
+	}
 	writeHolderInfo := func(sb *redact.StringBuilder, txn *enginepb.TxnMeta, ts hlc.Timestamp) {
 		sb.Printf("  holder: txn: %v, ts: %v, info: ", redact.Safe(txn.ID), redact.Safe(ts))
 		first := true
@@ -1081,33 +1153,26 @@ func (l *lockState) safeFormat(sb *redact.StringBuilder, finalizedTxnCache *txnC
 			} else {
 				sb.SafeString("unrepl ")
 			}
-			if finalizedTxnCache != nil {
-				finalizedTxn, ok := finalizedTxnCache.get(h.txn.ID)
-				if ok {
-					var statusStr string
-					switch finalizedTxn.Status {
-					case roachpb.COMMITTED:
-						statusStr = "committed"
-					case roachpb.ABORTED:
-						statusStr = "aborted"
-					}
-					sb.Printf("[holder finalized: %s] ", redact.Safe(statusStr))
-				}
-			}
-			sb.Printf("epoch: %d, seqs: [%d", redact.Safe(h.txn.Epoch), redact.Safe(h.seqs[0]))
-			for j := 1; j < len(h.seqs); j++ {
-				sb.Printf(", %d", redact.Safe(h.seqs[j]))
-			}
-			sb.SafeString("]")
+			writeLockHolderInfo(sb, h)
 		}
 		sb.SafeString("\n")
 	}
-	txn, ts := l.getLockHolder()
-	if txn == nil {
+	writeSharedHolderInfo := func(sb *redact.StringBuilder) {
+		for i := range l.holder.shared {
+			h := &l.holder.shared[i]
+			sb.Printf("  shared holder: txn: %v, ts: %v, info: unrepl ",
+				redact.Safe(h.txn.ID), redact.Safe(h.ts))
+			writeLockHolderInfo(sb, h)
+			sb.SafeString("\n")
+		}
+	}
+	if txn, ts := l.getLockHolder(); txn != nil {
+		writeHolderInfo(sb, txn, ts)
+	} else if l.isHeldShared() {
+		writeSharedHolderInfo(sb)
+	} else {
 		sb.Printf("  res: req: %d, ", l.reservation.seqNum)
 		writeResInfo(sb, l.reservation.txn, l.reservation.ts)
-	} else {
-		writeHolderInfo(sb, txn, ts)
 	}
 	// TODO(sumeer): Add an optional `description string` field to Request and
 	// lockTableGuardImpl that tests can set to avoid relying on the seqNum to
@@ -1172,13 +1237,19 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 	var txnHolder *enginepb.TxnMeta
 
 	durability := lock.Unreplicated
+	strength := lock.None
 	if l.holder.locked {
+		strength = lock.Exclusive
 		if l.holder.holder[lock.Replicated].txn != nil {
 			durability = lock.Replicated
 			txnHolder = l.holder.holder[lock.Replicated].txn
 		} else if l.holder.holder[lock.Unreplicated].txn != nil {
 			txnHolder = l.holder.holder[lock.Unreplicated].txn
 		}
+	} else if l.isHeldShared() {
+		// Report the longest-standing shared lock holder.
+		strength = lock.Shared
+		txnHolder = l.holder.shared[0].txn
 	}
 
 	waiterCount := l.waitingReaders.Len() + l.queuedWriters.Len()
@@ -1194,7 +1265,7 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 		lockWaiters = append(lockWaiters, lock.Waiter{
 			WaitingTxn:   l.reservation.txn,
 			ActiveWaiter: true,
-			Strength:     lock.Exclusive,
+			Strength:     l.reservation.str,
 			WaitDuration: now.Sub(l.reservation.mu.curLockWaitStart),
 		})
 		l.reservation.mu.Unlock()
@@ -1221,7 +1292,7 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 		lockWaiters = append(lockWaiters, lock.Waiter{
 			WaitingTxn:   writerGuard.txn,
 			ActiveWaiter: qg.active,
-			Strength:     lock.Exclusive,
+			Strength:     writerGuard.str,
 			WaitDuration: now.Sub(writerGuard.mu.curLockWaitStart),
 		})
 		writerGuard.mu.Unlock()
@@ -1231,6 +1302,7 @@ func (l *lockState) lockStateInfo(now time.Time) roachpb.LockStateInfo {
 		Key:          l.key,
 		LockHolder:   txnHolder,
 		Durability:   durability,
+		Strength:     strength,
 		HoldDuration: l.lockHeldDuration(now),
 		Waiters:      lockWaiters,
 	}
@@ -1246,7 +1318,7 @@ func (l *lockState) addToMetrics(m *LockTableMetrics, now time.Time) {
 	totalWaitDuration, maxWaitDuration := l.totalAndMaxWaitDuration(now)
 	lm := LockMetrics{
 		Key:                  l.key,
-		Held:                 l.holder.locked,
+		Held:                 l.isHeld(),
 		HoldDurationNanos:    l.lockHeldDuration(now).Nanoseconds(),
 		WaitingReaders:       int64(l.waitingReaders.Len()),
 		WaitingWriters:       int64(l.queuedWriters.Len()),
@@ -1275,7 +1347,10 @@ func (l *lockState) tryBreakReservation(seqNum uint64) bool {
 
 // Informs active waiters about reservation or lock holder. The reservation
 // may have changed so this needs to fix any inconsistencies wrt waitSelf and
-// waitForDistinguished states.
+// waitForDistinguished states. If the lock is held with Shared strength, the
+// set of shared holders may have changed, so active waiters that no longer
+// conflict with the holders or with the waiters ahead of them are told that
+// they are done waiting.
 // REQUIRES: l.mu is locked.
 func (l *lockState) informActiveWaiters() {
 	waitForState := waitingState{
@@ -1285,10 +1360,11 @@ func (l *lockState) informActiveWaiters() {
 		queuedReaders: l.waitingReaders.Len(),
 	}
 	findDistinguished := l.distinguishedWaiter == nil
+	heldShared := l.isHeldShared()
 	if lockHolderTxn, _ := l.getLockHolder(); lockHolderTxn != nil {
 		waitForState.txn = lockHolderTxn
 		waitForState.held = true
-	} else {
+	} else if !heldShared {
 		waitForState.txn = l.reservation.txn
 		if !findDistinguished && l.distinguishedWaiter.isSameTxnAsReservation(waitForState) {
 			findDistinguished = true
@@ -1315,15 +1391,37 @@ func (l *lockState) informActiveWaiters() {
 		g.notify()
 		g.mu.Unlock()
 	}
-	for e := l.queuedWriters.Front(); e != nil; e = e.Next() {
+	distinguishedRemoved := false
+	for e := l.queuedWriters.Front(); e != nil; {
 		qg := e.Value.(*queuedGuard)
+		curr := e
+		e = e.Next()
 		if !qg.active {
 			continue
 		}
 		g := qg.guard
 		state := waitForState
+		if heldShared {
+			var conflicts bool
+			state, conflicts = l.sharedLockWaitingState(g, state)
+			if !conflicts {
+				l.queuedWriters.Remove(curr)
+				if g == l.distinguishedWaiter {
+					distinguishedRemoved = true
+					l.distinguishedWaiter = nil
+				}
+				g.doneWaitingAtLock(false, l)
+				continue
+			}
+		}
 		if g.isSameTxnAsReservation(state) {
 			state.kind = waitSelf
+			if g == l.distinguishedWaiter {
+				// Only possible if the lock is held with Shared strength and g is
+				// now queued behind a request from its own transaction.
+				distinguishedRemoved = true
+				l.distinguishedWaiter = nil
+			}
 		} else {
 			state.guardAccess = spanset.SpanReadWrite
 			if findDistinguished {
@@ -1339,6 +1437,64 @@ func (l *lockState) informActiveWaiters() {
 		g.notify()
 		g.mu.Unlock()
 	}
+	if distinguishedRemoved && l.distinguishedWaiter == nil {
+		l.tryMakeNewDistinguished()
+	}
+}
+
+// sharedLockWaitingState returns the waitingState of the active waiter g in
+// queuedWriters when the lock is held with Shared strength, along with whether
+// g needs to continue waiting.
+//
+// Requests that want to write or acquire an Exclusive lock conflict with every
+// shared holder other than their own transaction, so they wait for one of them
+// to release the lock. Requests that want to acquire a Shared lock are
+// compatible with the holders, but must not jump ahead of a transactional
+// request waiting to acquire an incompatible lock with a lower sequence number,
+// as doing so could starve that request and cause lockTable induced deadlocks.
+// Such requests wait for the request ahead of them in the queue, in the same
+// way that requests wait for a reservation holder.
+// REQUIRES: l.mu is locked and l.isHeldShared().
+func (l *lockState) sharedLockWaitingState(
+	g *lockTableGuardImpl, ws waitingState,
+) (_ waitingState, conflicts bool) {
+	if g.str == lock.Shared {
+		if g.txn != nil && l.findSharedHolder(g.txn.ID) >= 0 {
+			// Already locked by this txn.
+			return ws, false
+		}
+		if qg := l.firstIncompatibleWaiterAhead(g); qg != nil {
+			ws.txn = qg.guard.txn
+			ws.held = false
+			return ws, true
+		}
+		return ws, false
+	}
+	if h := l.conflictingSharedHolder(g); h != nil {
+		ws.txn = h.txn
+		ws.held = true
+		return ws, true
+	}
+	// Only locked by this txn, which can upgrade its lock.
+	return ws, false
+}
+
+// firstIncompatibleWaiterAhead returns the first transactional request in
+// queuedWriters that has a lower sequence number than g and wants to write or
+// acquire an Exclusive lock, or nil if there is no such request. Such requests
+// may be inactive waiters.
+// REQUIRES: l.mu is locked.
+func (l *lockState) firstIncompatibleWaiterAhead(g *lockTableGuardImpl) *queuedGuard {
+	for e := l.queuedWriters.Front(); e != nil; e = e.Next() {
+		qg := e.Value.(*queuedGuard)
+		if qg.guard == g || qg.guard.seqNum > g.seqNum {
+			continue
+		}
+		if qg.guard.txn != nil && qg.guard.str != lock.Shared {
+			return qg
+		}
+	}
+	return nil
 }
 
 // releaseWritersFromTxn removes all waiting writers for the lockState that are
@@ -1377,7 +1533,8 @@ func (l *lockState) tryMakeNewDistinguished() {
 	} else if l.queuedWriters.Len() > 0 {
 		for e := l.queuedWriters.Front(); e != nil; e = e.Next() {
 			qg := e.Value.(*queuedGuard)
-			if qg.active && (l.reservation == nil || !qg.guard.isSameTxn(l.reservation.txn)) {
+			if qg.active && (l.reservation == nil || !qg.guard.isSameTxn(l.reservation.txn)) &&
+				!qg.guard.isWaitingOnSelf() {
 				g = qg.guard
 				break
 			}
@@ -1397,7 +1554,7 @@ func (l *lockState) tryMakeNewDistinguished() {
 // reservation.
 // REQUIRES: l.mu is locked.
 func (l *lockState) isEmptyLock() bool {
-	if !l.holder.locked && l.reservation == nil {
+	if !l.isHeld() && l.reservation == nil {
 		for i := range l.holder.holder {
 			if !l.holder.holder[i].isEmpty() {
 				panic("lockState with !locked but non-zero lockHolderInfo")
@@ -1414,7 +1571,7 @@ func (l *lockState) isEmptyLock() bool {
 // Returns the duration of time the lock has been tracked as held in the lock table.
 // REQUIRES: l.mu is locked.
 func (l *lockState) lockHeldDuration(now time.Time) time.Duration {
-	if !l.holder.locked {
+	if !l.isHeld() {
 		return time.Duration(0)
 	}
 
@@ -1451,8 +1608,8 @@ func (l *lockState) totalAndMaxWaitDuration(now time.Time) (time.Duration, time.
 	return totalWaitDuration, maxWaitDuration
 }
 
-// Returns true iff the lock is currently held by the transaction with the
-// given id.
+// Returns true iff the lock is currently held with Exclusive strength by the
+// transaction with the given id.
 // REQUIRES: l.mu is locked.
 func (l *lockState) isLockedBy(id uuid.UUID) bool {
 	if l.holder.locked {
@@ -1467,8 +1624,67 @@ func (l *lockState) isLockedBy(id uuid.UUID) bool {
 	return false
 }
 
-// Returns information about the current lock holder if the lock is held, else
-// returns nil.
+// Returns true iff the lock is held, with any strength.
+// REQUIRES: l.mu is locked.
+func (l *lockState) isHeld() bool {
+	return l.holder.locked || l.isHeldShared()
+}
+
+// Returns true iff the lock is held with Shared strength by one or more
+// transactions.
+// REQUIRES: l.mu is locked.
+func (l *lockState) isHeldShared() bool {
+	return len(l.holder.shared) > 0
+}
+
+// Returns the index of the shared lock holder with the given transaction id,
+// or -1 if the transaction does not hold the lock with Shared strength.
+// REQUIRES: l.mu is locked.
+func (l *lockState) findSharedHolder(id uuid.UUID) int {
+	for i := range l.holder.shared {
+		if l.holder.shared[i].txn.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the first shared lock holder that belongs to a different transaction
+// than the request g, or nil if there is no such holder.
+// REQUIRES: l.mu is locked.
+func (l *lockState) conflictingSharedHolder(g *lockTableGuardImpl) *lockHolderInfo {
+	for i := range l.holder.shared {
+		if h := &l.holder.shared[i]; !g.isSameTxn(h.txn) {
+			return h
+		}
+	}
+	return nil
+}
+
+// Returns true iff the lock is held with Shared strength and the transaction
+// with the given id is the only shared lock holder.
+// REQUIRES: l.mu is locked.
+func (l *lockState) isOnlySharedHolder(id uuid.UUID) bool {
+	return len(l.holder.shared) == 1 && l.holder.shared[0].txn.ID == id
+}
+
+// Removes the shared lock holder at index i. Returns whether the lockState can
+// be garbage collected.
+// REQUIRES: l.mu is locked.
+func (l *lockState) releaseSharedHolder(i int) (gc bool) {
+	l.holder.shared = append(l.holder.shared[:i], l.holder.shared[i+1:]...)
+	if !l.isHeldShared() {
+		l.clearLockHolder()
+		return l.lockIsFree()
+	}
+	// Some of the waiters may no longer conflict with the remaining holders,
+	// and the others may need to be told about who they are waiting for.
+	l.informActiveWaiters()
+	return false
+}
+
+// Returns information about the current lock holder if the lock is held with
+// Exclusive strength, else returns nil.
 // REQUIRES: l.mu is locked.
 func (l *lockState) getLockHolder() (*enginepb.TxnMeta, hlc.Timestamp) {
 	if !l.holder.locked {
@@ -1500,6 +1716,7 @@ func (l *lockState) clearLockHolder() {
 	for i := range l.holder.holder {
 		l.holder.holder[i] = lockHolderInfo{}
 	}
+	l.holder.shared = nil
 }
 
 // Decides whether the request g with access sa should actively wait at this
@@ -1598,16 +1815,38 @@ func (l *lockState) tryActiveWait(
 			}
 		}
 	}
+	if l.isHeldShared() {
+		// Shared locks are only held unreplicated, so those held by finalized
+		// transactions can be released immediately.
+		for i := 0; i < len(l.holder.shared); {
+			if _, ok := g.lt.finalizedTxnCache.get(l.holder.shared[i].txn.ID); !ok {
+				i++
+				continue
+			}
+			if l.releaseSharedHolder(i) {
+				// Empty lock.
+				return false, true
+			}
+		}
+		// If the lock is no longer held, there is a reservation holder, which
+		// may be the caller itself, so fall through to the processing below.
+	}
 
 	if sa == spanset.SpanReadOnly {
 		if lockHolderTxn == nil {
-			// Reads only care about locker, not a reservation.
+			// Reads only care about an Exclusive locker, not a reservation or
+			// Shared lockers.
 			return false, false
 		}
 		// Locked by some other txn.
 		if g.ts.Less(lockHolderTS) {
 			return false, false
 		}
+		// Non-locking reads from transactions which tolerate write skew do not
+		// conflict with locks that are only held unreplicated.
+		if l.isIgnorableByWeakIsolationReadLocked(g) {
+			return false, false
+		}
 		g.mu.Lock()
 		_, alsoHasStrongerAccess := g.mu.locks[l]
 		g.mu.Unlock()
@@ -1639,6 +1878,12 @@ func (l *lockState) tryActiveWait(
 	if lockHolderTxn != nil {
 		waitForState.txn = lockHolderTxn
 		waitForState.held = true
+	} else if l.isHeldShared() {
+		var conflicts bool
+		waitForState, conflicts = l.sharedLockWaitingState(g, waitForState)
+		if !conflicts {
+			return false, false
+		}
 	} else {
 		if l.reservation == g {
 			// Already reserved by this request.
@@ -1787,6 +2032,13 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 		return true
 	}
 	// Lock is not empty.
+	if l.isHeldShared() {
+		// Non-locking reads and shared locking reads are compatible with shared
+		// locks. Like reservations, waiters ahead of a shared locking read are
+		// not considered here. Otherwise, the request only conflicts with shared
+		// holders from other transactions.
+		return sa == spanset.SpanReadOnly || g.str == lock.Shared || l.conflictingSharedHolder(g) == nil
+	}
 	lockHolderTxn, lockHolderTS := l.getLockHolder()
 	if lockHolderTxn == nil {
 		// Reservation holders are non-conflicting.
@@ -1810,19 +2062,38 @@ func (l *lockState) isNonConflictingLock(g *lockTableGuardImpl, sa spanset.SpanA
 	// path. A conflict with a finalized txn will be noticed when retrying
 	// pessimistically.
 
-	if sa == spanset.SpanReadOnly && g.ts.Less(lockHolderTS) {
-		return true
+	if sa == spanset.SpanReadOnly {
+		if g.ts.Less(lockHolderTS) || l.isIgnorableByWeakIsolationReadLocked(g) {
+			return true
+		}
 	}
 	// Conflicts.
 	return false
 }
 
+// isIgnorableByWeakIsolationReadLocked returns true if the lock can be ignored
+// by a non-locking read performed by the request. This is the case when the
+// request's transaction tolerates write skew and the lock is only held
+// unreplicated (e.g. by SELECT ... FOR UPDATE), so the key has no intent
+// beneath it and the read observes the latest committed value at its
+// timestamp. Such transactions do not need their reads to be ordered with
+// respect to the lock holder's later writes, because they do not validate
+// their reads before committing.
+//
+// REQUIRES: l.mu is locked.
+func (l *lockState) isIgnorableByWeakIsolationReadLocked(g *lockTableGuardImpl) bool {
+	if g.txn == nil || !g.txn.IsoLevel.ToleratesWriteSkew() {
+		return false
+	}
+	return l.holder.locked && l.holder.holder[lock.Replicated].txn == nil
+}
+
 // Acquires this lock. Returns the list of guards that are done actively
 // waiting at this key -- these will be requests from the same transaction
 // that is acquiring the lock.
 // Acquires l.mu.
 func (l *lockState) acquireLock(
-	_ lock.Strength,
+	str lock.Strength,
 	durability lock.Durability,
 	txn *enginepb.TxnMeta,
 	ts hlc.Timestamp,
@@ -1830,6 +2101,18 @@ func (l *lockState) acquireLock(
 ) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if str == lock.Shared {
+		return l.acquireSharedLock(durability, txn, ts, clock)
+	}
+	upgrade := false
+	if l.isHeldShared() {
+		if !l.isOnlySharedHolder(txn.ID) {
+			return errors.AssertionFailedf("existing lock cannot be acquired by different transaction")
+		}
+		// The transaction is upgrading its Shared lock, which is subsumed by the
+		// Exclusive lock.
+		upgrade = true
+	}
 	if l.holder.locked {
 		// Already held.
 		beforeTxn, beforeTs := l.getLockHolder()
@@ -1933,7 +2216,7 @@ func (l *lockState) acquireLock(
 		if l.waitingReaders.Len() > 0 {
 			panic("lockTable bug")
 		}
-	} else {
+	} else if !upgrade {
 		if l.queuedWriters.Len() > 0 || l.waitingReaders.Len() > 0 {
 			panic("lockTable bug")
 		}
@@ -1943,7 +2226,10 @@ func (l *lockState) acquireLock(
 	l.holder.holder[durability].txn = txn
 	l.holder.holder[durability].ts = ts
 	l.holder.holder[durability].seqs = append([]enginepb.TxnSeq(nil), txn.Sequence)
-	l.holder.startTime = clock.PhysicalTime()
+	l.holder.shared = nil
+	if !upgrade {
+		l.holder.startTime = clock.PhysicalTime()
+	}
 
 	// If there are waiting requests from the same txn, they no longer need to wait.
 	l.releaseWritersFromTxn(txn)
@@ -1953,6 +2239,90 @@ func (l *lockState) acquireLock(
 	return nil
 }
 
+// acquireSharedLock is the variant of acquireLock for locks acquired with
+// Shared strength.
+// REQUIRES: l.mu is locked.
+func (l *lockState) acquireSharedLock(
+	durability lock.Durability, txn *enginepb.TxnMeta, ts hlc.Timestamp, clock *hlc.Clock,
+) error {
+	if durability != lock.Unreplicated {
+		return errors.AssertionFailedf("shared locks can only be acquired with Unreplicated durability")
+	}
+	if l.holder.locked {
+		if !l.isLockedBy(txn.ID) {
+			return errors.AssertionFailedf("existing lock cannot be acquired by different transaction")
+		}
+		// Already held with Exclusive strength by this txn, which subsumes the
+		// Shared lock.
+		return nil
+	}
+	if i := l.findSharedHolder(txn.ID); i >= 0 {
+		// Already held. See acquireLock for a discussion of idempotent lock
+		// acquisitions and of why the lock's timestamp is forwarded.
+		h := &l.holder.shared[i]
+		seqs := h.seqs
+		if h.txn.Epoch < txn.Epoch {
+			// Clear the sequences for the older epoch.
+			seqs = seqs[:0]
+		}
+		if len(seqs) > 0 && seqs[len(seqs)-1] >= txn.Sequence {
+			if i := sort.Search(len(seqs), func(i int) bool {
+				return seqs[i] >= txn.Sequence
+			}); seqs[i] != txn.Sequence {
+				seqs = append(seqs, 0)
+				copy(seqs[i+1:], seqs[i:])
+				seqs[i] = txn.Sequence
+				h.seqs = seqs
+			}
+			return nil
+		}
+		h.txn = txn
+		h.ts.Forward(ts)
+		h.seqs = append(seqs, txn.Sequence)
+		return nil
+	}
+	// Not already held by this transaction. The lock may be held with Shared
+	// strength by other transactions, which is compatible, or it may have been
+	// reserved by this request. As in acquireLock, the reservation may also have
+	// been broken by some other request.
+	if l.reservation != nil {
+		if l.reservation.txn.ID != txn.ID {
+			// Reservation is broken.
+			qg := &queuedGuard{
+				guard:  l.reservation,
+				active: false,
+			}
+			l.queuedWriters.PushFront(qg)
+		} else {
+			l.reservation.mu.Lock()
+			delete(l.reservation.mu.locks, l)
+			l.reservation.mu.Unlock()
+		}
+		if l.waitingReaders.Len() > 0 {
+			panic("lockTable bug")
+		}
+		l.reservation = nil
+	} else if !l.isHeldShared() {
+		if l.queuedWriters.Len() > 0 || l.waitingReaders.Len() > 0 {
+			panic("lockTable bug")
+		}
+	}
+	if !l.isHeldShared() {
+		l.holder.startTime = clock.PhysicalTime()
+	}
+	l.holder.shared = append(l.holder.shared, lockHolderInfo{
+		txn:  txn,
+		ts:   ts,
+		seqs: []enginepb.TxnSeq{txn.Sequence},
+	})
+
+	// Inform active waiters since the set of lock holders has changed. Waiters
+	// that are compatible with the new holder, including those from the same
+	// transaction, may no longer need to wait.
+	l.informActiveWaiters()
+	return nil
+}
+
 // A replicated lock held by txn with timestamp ts was discovered by guard g
 // where g is trying to access this key with access sa.
 // Acquires l.mu.
@@ -1970,7 +2340,16 @@ func (l *lockState) discoveredLock(
 	if notRemovable {
 		l.notRemovable++
 	}
-	if l.holder.locked {
+	if l.isHeldShared() {
+		if !l.isOnlySharedHolder(txn.ID) {
+			return errors.AssertionFailedf(
+				"discovered lock by different transaction (%s) than existing shared lock: %s",
+				txn, l)
+		}
+		// The transaction's Shared lock is subsumed by its replicated lock.
+		l.holder.shared = nil
+		l.holder.locked = true
+	} else if l.holder.locked {
 		if !l.isLockedBy(txn.ID) {
 			return errors.AssertionFailedf(
 				"discovered lock by different transaction (%s) than existing lock (see issue #63592): %s",
@@ -2165,6 +2544,9 @@ func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (heldByTxn, gc bool) {
 		// tryActiveWait due to the txn being in the finalizedTxnCache.
 		return false, true
 	}
+	if l.isHeldShared() {
+		return l.tryUpdateSharedLock(up)
+	}
 	if !l.isLockedBy(up.Txn.ID) {
 		return false, false
 	}
@@ -2241,6 +2623,40 @@ func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (heldByTxn, gc bool) {
 	return true, false
 }
 
+// tryUpdateSharedLock is the variant of tryUpdateLock for locks held with
+// Shared strength. Since shared locks are only held unreplicated, it mirrors
+// the handling of unreplicated locks in tryUpdateLock.
+// REQUIRES: l.mu is locked.
+func (l *lockState) tryUpdateSharedLock(up *roachpb.LockUpdate) (heldByTxn, gc bool) {
+	i := l.findSharedHolder(up.Txn.ID)
+	if i < 0 {
+		return false, false
+	}
+	holder := &l.holder.shared[i]
+	txn := &up.Txn
+	ts := up.Txn.WriteTimestamp
+	released := up.Status.IsFinalized() || txn.Epoch > holder.txn.Epoch
+	if !released {
+		// Shared locks do not conflict with non-locking reads, so advancing the
+		// lock's timestamp does not affect any waiters.
+		advancedTs := holder.ts.Less(ts)
+		if advancedTs {
+			holder.ts = ts
+		}
+		if txn.Epoch == holder.txn.Epoch {
+			holder.seqs = removeIgnored(holder.seqs, up.IgnoredSeqNums)
+			released = len(holder.seqs) == 0
+			if advancedTs {
+				holder.txn = txn
+			}
+		}
+	}
+	if !released {
+		return true, false
+	}
+	return true, l.releaseSharedHolder(i)
+}
+
 // The lock holder timestamp has increased. Some of the waiters may no longer
 // need to wait.
 // REQUIRES: l.mu is locked.
@@ -2321,6 +2737,13 @@ func (l *lockState) requestDone(g *lockTableGuardImpl) (gc bool) {
 	if !doneRemoval {
 		panic("lockTable bug")
 	}
+	if l.isHeldShared() {
+		// The request may have been an incompatible waiter ahead of shared
+		// locking requests, which may no longer need to wait. This also finds a
+		// new distinguished waiter, if necessary.
+		l.informActiveWaiters()
+		return false
+	}
 	if distinguishedRemoved {
 		l.tryMakeNewDistinguished()
 	}
@@ -2371,7 +2794,7 @@ func (l *lockState) tryFreeLockOnReplicatedAcquire() bool {
 // waiters, but there cannot be a reservation.
 // REQUIRES: l.mu is locked.
 func (l *lockState) lockIsFree() (gc bool) {
-	if l.holder.locked {
+	if l.isHeld() {
 		panic("called lockIsFree on lock with holder")
 	}
 	if l.reservation != nil {
@@ -2502,6 +2925,7 @@ func (t *lockTableImpl) newGuardForReq(req Request) *lockTableGuardImpl {
 	g.spans = req.LockSpans
 	g.waitPolicy = req.WaitPolicy
 	g.maxWaitQueueLength = req.MaxLockWaitQueueLength
+	g.str = req.lockStrength()
 	g.sa = spanset.NumSpanAccess - 1
 	g.index = -1
 	return g
@@ -2662,8 +3086,8 @@ func (t *lockTableImpl) AcquireLock(
 		// If not enabled, don't track any locks.
 		return nil
 	}
-	if strength != lock.Exclusive {
-		return errors.AssertionFailedf("lock strength not Exclusive")
+	if strength != lock.Exclusive && strength != lock.Shared {
+		return errors.AssertionFailedf("lock strength not Exclusive or Shared")
 	}
 	ss := spanset.SpanGlobal
 	if keys.IsLocal(key) {
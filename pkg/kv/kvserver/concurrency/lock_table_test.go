@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/poison"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanlatch"
@@ -63,10 +64,11 @@ new-txn txn=<name> ts=<int>[,<int>] epoch=<int> [seq=<int>]
 
  Creates a TxnMeta.
 
-new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+... [skip-locked] [max-lock-wait-queue-length=<int>]
+new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+... [skip-locked] [max-lock-wait-queue-length=<int>] [strength=<strength>]
 ----
 
- Creates a Request.
+ Creates a Request. If strength is provided, the request is a locking read
+ with that strength.
 
 scan r=<name>
 ----
@@ -82,11 +84,12 @@ start-waiting: <bool>
  Calls lockTable.ScanOptimistic. The request must not have an existing guard.
  If a guard is returned, stores it for later use.
 
-acquire r=<name> k=<key> durability=r|u
+acquire r=<name> k=<key> durability=r|u [strength=<strength>]
 ----
 <error string>
 
  Acquires lock for the request, using the existing guard for that request.
+ The lock is acquired with Exclusive strength unless otherwise specified.
 
 release txn=<name> span=<start>[,<end>]
 ----
@@ -313,6 +316,11 @@ func TestLockTableBasic(t *testing.T) {
 					LatchSpans:             spans,
 					LockSpans:              spans,
 				}
+				if d.HasArg("strength") {
+					req.Requests = []kvpb.RequestUnion{{Value: &kvpb.RequestUnion_Scan{
+						Scan: &kvpb.ScanRequest{KeyLocking: ScanLockStrength(t, d)},
+					}}}
+				}
 				if txnMeta != nil {
 					// Update the transaction's timestamp, if necessary. The transaction
 					// may have needed to move its timestamp for any number of reasons.
@@ -370,7 +378,11 @@ func TestLockTableBasic(t *testing.T) {
 				if s[0] == 'r' {
 					durability = lock.Replicated
 				}
-				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), lock.Exclusive, durability); err != nil {
+				str := lock.Exclusive
+				if d.HasArg("strength") {
+					str = ScanLockStrength(t, d)
+				}
+				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), str, durability); err != nil {
 					return err.Error()
 				}
 				return lt.String()
@@ -205,7 +205,10 @@ func (w *lockTableWaiterImpl) WaitOn(
 				// If the conflict is a reservation holder and not a held lock then
 				// there's no need to perform a liveness push - the request must be
 				// alive or its context would have been canceled and it would have
-				// exited its lock wait-queues.
+				// exited its lock wait-queues. The same is true when a shared
+				// locking request is queued behind an incompatible request on a
+				// lock that is held with Shared strength, as the request ahead of
+				// it is also still in the lock wait-queue.
 				if !state.held {
 					livenessPush = false
 				}
@@ -228,9 +231,15 @@ func (w *lockTableWaiterImpl) WaitOn(
 				// The push should succeed without entering the txn wait-queue.
 				priorityPush := canPushWithPriority(req, state)
 
+				// If the request is a reader and the lock holder tolerates write
+				// skew, push immediately. The reader's timestamp push should
+				// succeed without entering the txn wait-queue, because pushing
+				// the lock holder's timestamp does not force it to retry.
+				isoLevelPush := canPushReadWithIsoLevel(state)
+
 				// If the request doesn't want to perform a delayed push for any
 				// reason, continue waiting without a timer.
-				if !(livenessPush || deadlockPush || timeoutPush || priorityPush) {
+				if !(livenessPush || deadlockPush || timeoutPush || priorityPush || isoLevelPush) {
 					log.Eventf(ctx, "not pushing")
 					continue
 				}
@@ -258,14 +267,15 @@ func (w *lockTableWaiterImpl) WaitOn(
 					}
 					delay = minDuration(delay, w.timeUntilDeadline(lockDeadline))
 				}
-				if priorityPush {
+				if priorityPush || isoLevelPush {
 					delay = 0
 				}
 
 				log.Eventf(ctx, "pushing after %s for: "+
 					"liveness detection = %t, deadlock detection = %t, "+
-					"timeout enforcement = %t, priority enforcement = %t",
-					delay, livenessPush, deadlockPush, timeoutPush, priorityPush)
+					"timeout enforcement = %t, priority enforcement = %t, "+
+					"isolation level enforcement = %t",
+					delay, livenessPush, deadlockPush, timeoutPush, priorityPush, isoLevelPush)
 
 				if delay > 0 {
 					if timer == nil {
@@ -475,7 +485,9 @@ func (w *lockTableWaiterImpl) pushLockTxn(
 		// the lock holder's timestamp forward so the read request can read
 		// under the lock. For write-write conflicts, try to abort the lock
 		// holder entirely so the write request can revoke and replace the lock
-		// with its own lock.
+		// with its own lock. Locking reads, including those that acquire Shared
+		// locks, are treated like writes, because pushing the timestamp of an
+		// incompatible lock holder does not allow them to acquire their lock.
 		switch ws.guardAccess {
 		case spanset.SpanReadOnly:
 			pushType = kvpb.PUSH_TIMESTAMP
@@ -1245,6 +1257,17 @@ func canPushWithPriority(req Request, s waitingState) bool {
 	return txnwait.CanPushWithPriority(pusher, pushee)
 }
 
+// canPushReadWithIsoLevel returns true if the waiting request is a reader
+// that is blocked on a held lock whose holder tolerates write skew. The
+// timestamp of such a lock holder can be pushed without waiting for it, since
+// doing so does not force it to retry.
+func canPushReadWithIsoLevel(s waitingState) bool {
+	if s.guardAccess != spanset.SpanReadOnly || !s.held || s.txn == nil {
+		return false
+	}
+	return s.txn.IsoLevel.ToleratesWriteSkew()
+}
+
 func minDuration(a, b time.Duration) time.Duration {
 	if a < b {
 		return a
@@ -16,6 +16,7 @@
 package gc
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
@@ -27,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/abortspan"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -219,8 +221,8 @@ type Info struct {
 	// GCTTL is the TTL this garbage collection cycle.
 	GCTTL time.Duration
 	// Stats about the userspace key-values considered, namely the number of
-	// keys with GC'able data, the number of "old" intents and the number of
-	// associated distinct transactions.
+	// keys with GC'able data, the number of "old" intents and other replicated
+	// locks and the number of associated distinct transactions.
 	NumKeysAffected, NumRangeKeysAffected, IntentsConsidered, IntentTxns int
 	// TransactionSpanTotal is the total number of entries in the transaction span.
 	TransactionSpanTotal int
@@ -352,18 +354,26 @@ func Run(
 		Threshold: newThreshold,
 	}
 
+	intentOptions := intentBatcherOptions{
+		maxIntentsPerIntentCleanupBatch:        options.MaxIntentsPerIntentCleanupBatch,
+		maxIntentKeyBytesPerIntentCleanupBatch: options.MaxIntentKeyBytesPerIntentCleanupBatch,
+		maxTxnsPerIntentCleanupBatch:           options.MaxTxnsPerIntentCleanupBatch,
+		intentCleanupBatchTimeout:              options.IntentCleanupBatchTimeout,
+	}
 	fastPath, err := processReplicatedKeyRange(ctx, desc, snap, now, newThreshold, options.IntentAgeThreshold,
 		populateBatcherOptions(options),
 		gcer,
-		intentBatcherOptions{
-			maxIntentsPerIntentCleanupBatch:        options.MaxIntentsPerIntentCleanupBatch,
-			maxIntentKeyBytesPerIntentCleanupBatch: options.MaxIntentKeyBytesPerIntentCleanupBatch,
-			maxTxnsPerIntentCleanupBatch:           options.MaxTxnsPerIntentCleanupBatch,
-			intentCleanupBatchTimeout:              options.IntentCleanupBatchTimeout,
-		}, cleanupIntentsFn, &info)
+		intentOptions, cleanupIntentsFn, &info)
 	if err != nil {
 		return Info{}, err
 	}
+	if err := processReplicatedLocks(ctx, desc, snap, now, options.IntentAgeThreshold,
+		intentOptions, cleanupIntentsFn, &info); err != nil {
+		if errors.Is(err, ctx.Err()) {
+			return Info{}, err
+		}
+		log.Warningf(ctx, "while gc'ing replicated locks: %s", err)
+	}
 	err = processReplicatedRangeTombstones(ctx, desc, snap, fastPath, now, newThreshold, gcer, &info)
 	if err != nil {
 		return Info{}, err
@@ -564,6 +574,95 @@ func processReplicatedKeyRange(
 	})
 }
 
+// processReplicatedLocks resolves the replicated locks that are not intents and
+// are older than the intent age threshold, like processReplicatedKeyRange does
+// for intents. Unlike intents, these locks are not interleaved with the MVCC
+// keys they protect, so they are found by scanning the lock table key spans of
+// the range. Abandoned locks would otherwise only be resolved once a
+// conflicting request discovers them.
+func processReplicatedLocks(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	snap storage.Reader,
+	now hlc.Timestamp,
+	intentAgeThreshold time.Duration,
+	options intentBatcherOptions,
+	cleanupIntentsFn CleanupIntentsFunc,
+	info *Info,
+) error {
+	// Compute lock expiration (lock age at which we attempt to resolve).
+	lockExp := now.Add(-intentAgeThreshold.Nanoseconds(), 0)
+	intentBatcher := newIntentBatcher(cleanupIntentsFn, options, info)
+
+	handleLock := func(iter storage.EngineIterator) error {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return err
+		}
+		ltKey, err := engineKey.ToLockTableKey()
+		if err != nil {
+			return err
+		}
+		if ltKey.Strength == lock.Intent {
+			// Intents are handled by processReplicatedKeyRange.
+			return nil
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		meta := &enginepb.MVCCMetadata{}
+		if err := protoutil.Unmarshal(v, meta); err != nil {
+			log.Errorf(ctx, "unable to unmarshal MVCC metadata for lock on key %q: %+v", ltKey.Key, err)
+			return nil
+		}
+		if meta.Txn != nil && meta.Timestamp.ToTimestamp().Less(lockExp) {
+			info.IntentsConsidered++
+			if err := intentBatcher.addAndMaybeFlushIntents(ctx, ltKey.Key, meta); err != nil {
+				if errors.Is(err, ctx.Err()) {
+					return err
+				}
+				log.Warningf(ctx, "failed to cleanup intents batch: %v", err)
+			}
+		}
+		return nil
+	}
+
+	for _, span := range rditer.Select(desc.RangeID, rditer.SelectOpts{
+		ReplicatedBySpan: desc.RSpan(),
+	}) {
+		if !bytes.HasPrefix(span.Key, keys.LocalRangeLockTablePrefix) {
+			continue
+		}
+		if err := func() error {
+			iter := snap.NewEngineIterator(storage.IterOptions{
+				LowerBound: span.Key,
+				UpperBound: span.EndKey,
+			})
+			defer iter.Close()
+			var ok bool
+			var err error
+			for ok, err = iter.SeekEngineKeyGE(storage.EngineKey{Key: span.Key}); ok; ok, err = iter.NextEngineKey() {
+				if err := handleLock(iter); err != nil {
+					return err
+				}
+			}
+			return err
+		}(); err != nil {
+			return err
+		}
+	}
+
+	// We need to send out last lock cleanup batch.
+	if err := intentBatcher.maybeFlushPendingIntents(ctx); err != nil {
+		if errors.Is(err, ctx.Err()) {
+			return err
+		}
+		log.Warningf(ctx, "failed to cleanup intents batch: %v", err)
+	}
+	return nil
+}
+
 // gcBatchCounters contain statistics about garbage that is collected for the
 // range of keys.
 type gcBatchCounters struct {
@@ -25,6 +25,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -173,6 +174,71 @@ func TestIntentAgeThresholdSetting(t *testing.T) {
 		"Expected 1 intents considered by GC with short threshold")
 }
 
+// TestReplicatedLockAgeThreshold verifies that GC resolves replicated locks
+// that are not intents once they are older than the intent age threshold, just
+// like it does for intents.
+func TestReplicatedLockAgeThreshold(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+
+	// Test event timeline.
+	now := 3 * time.Hour
+	lockShortThreshold := 5 * time.Minute
+	lockLongThreshold := 2 * time.Hour
+	lockTs := now - (lockShortThreshold+lockLongThreshold)/2
+
+	// Prepare a committed value and a replicated lock on it.
+	key := []byte("a")
+	value := roachpb.Value{RawBytes: []byte("0123456789")}
+	lockHlc := hlc.Timestamp{
+		WallTime: lockTs.Nanoseconds(),
+	}
+	require.NoError(t, storage.MVCCPut(ctx, eng, nil, key, hlc.Timestamp{WallTime: 1}, hlc.ClockTimestamp{}, value, nil))
+	txn := roachpb.MakeTransaction("txn", key, roachpb.NormalUserPriority, lockHlc, 1000, 0)
+	require.NoError(t, storage.MVCCAcquireLock(ctx, eng, &txn, lock.Exclusive, key, nil, 0))
+	require.NoError(t, eng.Flush())
+
+	// Prepare test fixtures for GC run.
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKey(key),
+		EndKey:   roachpb.RKey("b"),
+	}
+	gcTTL := time.Second
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+	nowTs := hlc.Timestamp{
+		WallTime: now.Nanoseconds(),
+	}
+	gcer := makeFakeGCer()
+
+	info, err := Run(ctx, &desc, snap, nowTs, nowTs,
+		RunOptions{
+			IntentAgeThreshold:  lockLongThreshold,
+			TxnCleanupThreshold: txnCleanupThreshold,
+		}, gcTTL, &gcer, gcer.resolveIntents,
+		gcer.resolveIntentsAsync)
+	require.NoError(t, err, "GC Run shouldn't fail")
+	assert.Zero(t, info.IntentsConsidered,
+		"Expected no locks considered by GC with default threshold")
+	assert.Empty(t, gcer.intents)
+
+	info, err = Run(ctx, &desc, snap, nowTs, nowTs,
+		RunOptions{
+			IntentAgeThreshold:  lockShortThreshold,
+			TxnCleanupThreshold: txnCleanupThreshold,
+		}, gcTTL, &gcer, gcer.resolveIntents,
+		gcer.resolveIntentsAsync)
+	require.NoError(t, err, "GC Run shouldn't fail")
+	assert.Equal(t, 1, info.IntentsConsidered,
+		"Expected 1 lock considered by GC with short threshold")
+	require.Len(t, gcer.intents, 1)
+	assert.Equal(t, roachpb.Key(key), gcer.intents[0].Key)
+	assert.Equal(t, txn.ID, gcer.intents[0].Txn.ID)
+}
+
 func TestIntentCleanupBatching(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
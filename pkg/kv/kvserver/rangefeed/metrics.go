@@ -42,6 +42,12 @@ var (
 		Measurement: "Registrations",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaRangeFeedFilteredEvents = metric.Metadata{
+		Name:        "kv.rangefeed.filtered_events",
+		Help:        "Number of RangeFeed value events not sent because they did not match the registration's value filter",
+		Measurement: "Events",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // Metrics are for production monitoring of RangeFeeds.
@@ -50,6 +56,7 @@ type Metrics struct {
 	RangeFeedBudgetExhausted         *metric.Counter
 	RangeFeedBudgetBlocked           *metric.Counter
 	RangeFeedRegistrations           *metric.Gauge
+	RangeFeedFilteredEvents          *metric.Counter
 	RangeFeedSlowClosedTimestampLogN log.EveryN
 	// RangeFeedSlowClosedTimestampNudgeSem bounds the amount of work that can be
 	// spun up on behalf of the RangeFeed nudger. We don't expect to hit this
@@ -68,6 +75,7 @@ func NewMetrics() *Metrics {
 		RangeFeedBudgetExhausted:             metric.NewCounter(metaRangeFeedExhausted),
 		RangeFeedBudgetBlocked:               metric.NewCounter(metaRangeFeedBudgetBlocked),
 		RangeFeedRegistrations:               metric.NewGauge(metaRangeFeedRegistrations),
+		RangeFeedFilteredEvents:              metric.NewCounter(metaRangeFeedFilteredEvents),
 		RangeFeedSlowClosedTimestampLogN:     log.Every(5 * time.Second),
 		RangeFeedSlowClosedTimestampNudgeSem: make(chan struct{}, 1024),
 	}
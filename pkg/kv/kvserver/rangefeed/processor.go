@@ -477,6 +477,7 @@ func (p *Processor) Register(
 	startTS hlc.Timestamp,
 	catchUpIterConstructor CatchUpIteratorConstructor,
 	withDiff bool,
+	valueFilter ValueFilter,
 	stream Stream,
 	disconnectFn func(),
 	done *future.ErrorFuture,
@@ -487,7 +488,7 @@ func (p *Processor) Register(
 	p.syncEventC()
 
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchUpIterConstructor, withDiff,
+		span.AsRawSpanWithNoLocals(), startTS, catchUpIterConstructor, withDiff, valueFilter,
 		p.Config.EventChanCap, p.Metrics, stream, disconnectFn, done,
 	)
 	select {
@@ -217,6 +217,7 @@ func TestProcessorBasic(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -339,6 +340,7 @@ func TestProcessorBasic(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,  /* catchUpIter */
 		true, /* withDiff */
+		nil,  /* valueFilter */
 		r2Stream,
 		func() {},
 		&r2Done,
@@ -423,6 +425,7 @@ func TestProcessorBasic(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r3Stream,
 		func() {},
 		&r3Done,
@@ -451,7 +454,7 @@ func TestNilProcessor(t *testing.T) {
 	require.Panics(t, func() { _ = p.Start(stopper, nil) })
 	require.Panics(t, func() {
 		var done future.ErrorFuture
-		p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, false, nil,
+		p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, false, nil, nil,
 			func() {}, &done,
 		)
 	})
@@ -471,6 +474,7 @@ func TestProcessorSlowConsumer(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -482,6 +486,7 @@ func TestProcessorSlowConsumer(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r2Stream,
 		func() {},
 		&r2Done,
@@ -585,6 +590,7 @@ func TestProcessorMemoryBudgetExceeded(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -655,6 +661,7 @@ func TestProcessorMemoryBudgetReleased(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -730,6 +737,7 @@ func TestProcessorInitializeResolvedTimestamp(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -974,7 +982,7 @@ func TestProcessorConcurrentStop(t *testing.T) {
 			runtime.Gosched()
 			s := newTestStream()
 			var done future.ErrorFuture
-			p.Register(p.Span, hlc.Timestamp{}, nil, false, s,
+			p.Register(p.Span, hlc.Timestamp{}, nil, false, nil, s,
 				func() {}, &done)
 		}()
 		go func() {
@@ -1044,7 +1052,7 @@ func TestProcessorRegistrationObservesOnlyNewEvents(t *testing.T) {
 			s := newTestStream()
 			regs[s] = firstIdx
 			var done future.ErrorFuture
-			p.Register(p.Span, hlc.Timestamp{}, nil, false,
+			p.Register(p.Span, hlc.Timestamp{}, nil, false, nil,
 				s, func() {}, &done)
 			regDone <- struct{}{}
 		}
@@ -1144,6 +1152,7 @@ func TestBudgetReleaseOnProcessorStop(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		rStream,
 		func() {},
 		&done,
@@ -1235,6 +1244,7 @@ func TestBudgetReleaseOnLastStreamError(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		rStream,
 		func() {},
 		&done,
@@ -1315,6 +1325,7 @@ func TestBudgetReleaseOnOneStreamError(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -1329,6 +1340,7 @@ func TestBudgetReleaseOnOneStreamError(t *testing.T) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r2Stream,
 		func() {},
 		&r2Done,
@@ -1488,6 +1500,7 @@ func BenchmarkProcessorWithBudget(b *testing.B) {
 		hlc.Timestamp{WallTime: 1},
 		nil,   /* catchUpIter */
 		false, /* withDiff */
+		nil,   /* valueFilter */
 		r1Stream,
 		func() {},
 		&r1Done,
@@ -79,6 +79,7 @@ type registration struct {
 	span             roachpb.Span
 	catchUpTimestamp hlc.Timestamp // exclusive
 	withDiff         bool
+	valueFilter      ValueFilter // optional
 	metrics          *Metrics
 
 	// catchUpIterConstructor is used to construct the catchUpIter if necessary.
@@ -105,6 +106,11 @@ type registration struct {
 		// This will cause the registration to exit with an error once the buffer
 		// has been emptied.
 		overflowed bool
+		// Set if the registration's value filter failed to apply to a live
+		// event. Like an overflow, this causes all later live events to be
+		// dropped and the registration to exit with the error once the buffer
+		// has been emptied.
+		filterErr error
 		// Boolean indicating if all events have been output to stream. Used only
 		// for testing.
 		caughtUp bool
@@ -124,6 +130,7 @@ func newRegistration(
 	startTS hlc.Timestamp,
 	catchUpIterConstructor CatchUpIteratorConstructor,
 	withDiff bool,
+	valueFilter ValueFilter,
 	bufferSz int,
 	metrics *Metrics,
 	stream Stream,
@@ -135,6 +142,7 @@ func newRegistration(
 		catchUpTimestamp:       startTS,
 		catchUpIterConstructor: catchUpIterConstructor,
 		withDiff:               withDiff,
+		valueFilter:            valueFilter,
 		metrics:                metrics,
 		stream:                 stream,
 		done:                   done,
@@ -150,18 +158,28 @@ func newRegistration(
 // registration. If the output buffer is full, the overflowed flag is set,
 // indicating that live events were lost and a catch-up scan should be initiated.
 // If overflowed is already set, events are ignored and not written to the
-// buffer.
+// buffer. Events which don't match the registration's value filter are dropped
+// before they are written to the buffer; if the filter fails to apply, the
+// registration stops accepting events and exits with the error instead.
 func (r *registration) publish(
 	ctx context.Context, event *kvpb.RangeFeedEvent, alloc *SharedBudgetAllocation,
 ) {
 	r.validateEvent(event)
-	e := getPooledSharedEvent(sharedEvent{event: r.maybeStripEvent(event), alloc: alloc})
+	event, err := r.applyValueFilter(ctx, r.maybeStripEvent(event))
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.mu.overflowed {
+	if r.mu.overflowed || r.mu.filterErr != nil {
+		return
+	}
+	if err != nil {
+		r.mu.filterErr = err
+		return
+	}
+	if event == nil {
 		return
 	}
+	e := getPooledSharedEvent(sharedEvent{event: event, alloc: alloc})
 	alloc.Use()
 	select {
 	case r.buf <- e:
@@ -297,11 +315,11 @@ func (r *registration) disconnect(pErr *kvpb.Error) {
 // output loop.
 // 2. After catch-up is complete, begin reading from the registration buffer
 // channel and writing to the output stream until the buffer is empty *and*
-// the overflow flag has been set.
+// the overflow flag has been set or the value filter has failed.
 //
 // The loop exits with any error encountered, if the provided context is
-// canceled, or when the buffer has overflowed and all pre-overflow entries
-// have been emitted.
+// canceled, or when the buffer has overflowed or the value filter has failed
+// and all the preceding entries have been emitted.
 func (r *registration) outputLoop(ctx context.Context) error {
 	// If the registration has a catch-up scan, run it.
 	if err := r.maybeRunCatchUpScan(ctx); err != nil {
@@ -313,15 +331,20 @@ func (r *registration) outputLoop(ctx context.Context) error {
 	// Normal buffered output loop.
 	for {
 		overflowed := false
+		var filterErr error
 		r.mu.Lock()
 		if len(r.buf) == 0 {
 			overflowed = r.mu.overflowed
+			filterErr = r.mu.filterErr
 			r.mu.caughtUp = true
 		}
 		r.mu.Unlock()
 		if overflowed {
 			return newErrBufferCapacityExceeded().GoError()
 		}
+		if filterErr != nil {
+			return filterErr
+		}
 
 		select {
 		case nextEvent := <-r.buf:
@@ -387,7 +410,17 @@ func (r *registration) maybeRunCatchUpScan(ctx context.Context) error {
 		r.metrics.RangeFeedCatchUpScanNanos.Inc(timeutil.Since(start).Nanoseconds())
 	}()
 
-	return catchUpIter.CatchUpScan(ctx, r.stream.Send, r.withDiff)
+	outputFn := r.stream.Send
+	if r.valueFilter != nil {
+		outputFn = func(event *kvpb.RangeFeedEvent) error {
+			event, err := r.applyValueFilter(ctx, event)
+			if err != nil || event == nil {
+				return err
+			}
+			return r.stream.Send(event)
+		}
+	}
+	return catchUpIter.CatchUpScan(ctx, outputFn, r.withDiff)
 }
 
 // ID implements interval.Interface.
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -118,6 +119,7 @@ func newTestRegistration(
 		ts,
 		makeCatchUpIteratorConstructor(catchup),
 		withDiff,
+		nil, /* valueFilter */
 		5,
 		NewMetrics(),
 		s,
@@ -323,6 +325,120 @@ func TestRegistrationCatchUpScan(t *testing.T) {
 	require.Equal(t, expEvents, r.Events())
 }
 
+// testValueFilter is a ValueFilter which drops values equal to "drop",
+// projects values equal to "long" to "short" and fails on values equal to
+// "fail".
+type testValueFilter struct{}
+
+func (testValueFilter) Apply(
+	_ context.Context, value *kvpb.RangeFeedValue,
+) (*kvpb.RangeFeedValue, bool, error) {
+	b, err := value.Value.GetBytes()
+	if err != nil {
+		return nil, false, err
+	}
+	switch string(b) {
+	case "drop":
+		return nil, false, nil
+	case "long":
+		cpy := *value
+		cpy.Value = makeValWithTs("short", value.Value.Timestamp.WallTime)
+		return &cpy, true, nil
+	case "fail":
+		return nil, false, errors.New("boom")
+	}
+	return value, true, nil
+}
+
+func TestRegistrationValueFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	r := newTestRegistration(spAC, hlc.Timestamp{WallTime: 1},
+		newTestIterator([]storage.MVCCKeyValue{
+			makeKV("a", "keep", 10),
+			makeKV("b", "drop", 11),
+			makeKV("bb", "long", 12),
+		}, nil), false)
+	r.valueFilter = testValueFilter{}
+
+	// Publish live events. The dropped event must not occupy the buffer.
+	evKeep, evDrop, evLong := new(kvpb.RangeFeedEvent), new(kvpb.RangeFeedEvent), new(kvpb.RangeFeedEvent)
+	evKeep.MustSetValue(&kvpb.RangeFeedValue{Key: keyA, Value: makeValWithTs("keep", 20)})
+	evDrop.MustSetValue(&kvpb.RangeFeedValue{Key: keyB, Value: makeValWithTs("drop", 21)})
+	evLong.MustSetValue(&kvpb.RangeFeedValue{Key: keyB, Value: makeValWithTs("long", 22)})
+	r.publish(ctx, evKeep, nil /* alloc */)
+	r.publish(ctx, evDrop, nil /* alloc */)
+	r.publish(ctx, evLong, nil /* alloc */)
+	require.Equal(t, 2, len(r.buf))
+
+	// Checkpoints are never filtered.
+	evCheckpoint := rangeFeedCheckpoint(spAC, hlc.Timestamp{WallTime: 22})
+	r.publish(ctx, evCheckpoint, nil /* alloc */)
+
+	go r.runOutputLoop(ctx, 0)
+	require.NoError(t, r.waitForCaughtUp())
+	require.Equal(t, []*kvpb.RangeFeedEvent{
+		// Catch-up scan.
+		rangeFeedValue(keyA, makeValWithTs("keep", 10)),
+		rangeFeedValue(roachpb.Key("bb"), makeValWithTs("short", 12)),
+		// Live events.
+		evKeep,
+		rangeFeedValue(keyB, makeValWithTs("short", 22)),
+		evCheckpoint,
+	}, r.Events())
+	require.Equal(t, int64(2), r.metrics.RangeFeedFilteredEvents.Count())
+
+	// The published event must not have been modified by the projection.
+	b, err := evLong.Val.Value.GetBytes()
+	require.NoError(t, err)
+	require.Equal(t, "long", string(b))
+	r.disconnect(nil)
+}
+
+func TestRegistrationValueFilterError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	t.Run("live", func(t *testing.T) {
+		r := newTestRegistration(spAC, hlc.Timestamp{WallTime: 1}, nil, false)
+		r.valueFilter = testValueFilter{}
+
+		// The events published after the failed one are dropped, even if the
+		// filter applies to them, and the registration exits with the error once
+		// the preceding events have been sent.
+		evKeep, evFail, evAfter := new(kvpb.RangeFeedEvent), new(kvpb.RangeFeedEvent), new(kvpb.RangeFeedEvent)
+		evKeep.MustSetValue(&kvpb.RangeFeedValue{Key: keyA, Value: makeValWithTs("keep", 20)})
+		evFail.MustSetValue(&kvpb.RangeFeedValue{Key: keyB, Value: makeValWithTs("fail", 21)})
+		evAfter.MustSetValue(&kvpb.RangeFeedValue{Key: keyB, Value: makeValWithTs("keep", 22)})
+		r.publish(ctx, evKeep, nil /* alloc */)
+		r.publish(ctx, evFail, nil /* alloc */)
+		r.publish(ctx, evAfter, nil /* alloc */)
+		r.publish(ctx, rangeFeedCheckpoint(spAC, hlc.Timestamp{WallTime: 22}), nil /* alloc */)
+		require.Equal(t, 1, len(r.buf))
+
+		go r.runOutputLoop(ctx, 0)
+		require.ErrorContains(t, r.Err(), "boom")
+		require.Equal(t, []*kvpb.RangeFeedEvent{evKeep}, r.Events())
+	})
+
+	t.Run("catch-up", func(t *testing.T) {
+		r := newTestRegistration(spAC, hlc.Timestamp{WallTime: 1},
+			newTestIterator([]storage.MVCCKeyValue{
+				makeKV("a", "keep", 10),
+				makeKV("b", "fail", 11),
+				makeKV("bb", "keep", 12),
+			}, nil), false)
+		r.valueFilter = testValueFilter{}
+
+		go r.runOutputLoop(ctx, 0)
+		require.ErrorContains(t, r.Err(), "boom")
+		require.Equal(t, []*kvpb.RangeFeedEvent{
+			rangeFeedValue(keyA, makeValWithTs("keep", 10)),
+		}, r.Events())
+	})
+}
+
 func TestRegistryBasic(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	ctx := context.Background()
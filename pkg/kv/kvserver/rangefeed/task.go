@@ -121,6 +121,11 @@ func (s *SeparatedIntentScanner) ConsumeIntents(
 		if err != nil {
 			return err
 		}
+		if !engineKey.IsLockTableIntentKey() {
+			// Replicated locks that are not intents do not write values, so they
+			// are not tracked by the resolved timestamp.
+			continue
+		}
 		lockedKey, err := keys.DecodeLockTableSingleKey(engineKey.Key)
 		if err != nil {
 			return errors.Wrapf(err, "decoding LockTable key: %s", lockedKey)
@@ -0,0 +1,73 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeed
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/errors"
+)
+
+// ValueFilter is a predicate and projection over the RangeFeedValue events of
+// a single registration. It is constructed from the kvpb.RangeFeedValueFilter
+// provided by the client and is applied to the registration's events before
+// they are buffered (for live events) or sent (for catch-up scan events).
+//
+// Whenever a ValueFilter can't make a decision about an event, it returns an
+// error rather than letting the event through, since the client relies on the
+// projection to not receive the columns it excluded. The error terminates the
+// registration.
+type ValueFilter interface {
+	// Apply returns the event that should be sent in place of the provided one,
+	// or false if the event should be dropped. The provided event must not be
+	// modified; if its values need to be projected, a copy is returned instead.
+	// An error is returned if the event can't be filtered or projected.
+	//
+	// Apply is called concurrently by the Processor goroutine and by the
+	// registration's catch-up scan, so implementations must be safe for
+	// concurrent use.
+	Apply(ctx context.Context, value *kvpb.RangeFeedValue) (*kvpb.RangeFeedValue, bool, error)
+}
+
+// NewValueFilter returns a ValueFilter for the provided filter. It's injected
+// from pkg/sql/rangefeedfilter to avoid circular dependencies since kvserver
+// can't depend on higher levels of the system. If it is not set, value filters
+// provided by clients are ignored.
+var NewValueFilter func(
+	ctx context.Context, st *cluster.Settings, filter *kvpb.RangeFeedValueFilter,
+) (ValueFilter, error)
+
+// applyValueFilter applies the registration's value filter, if any, to the
+// provided event. It returns the event to send in place of the provided one,
+// or nil if the event should be dropped.
+func (r *registration) applyValueFilter(
+	ctx context.Context, event *kvpb.RangeFeedEvent,
+) (*kvpb.RangeFeedEvent, error) {
+	if r.valueFilter == nil || event.Val == nil {
+		return event, nil
+	}
+	val, ok, err := r.valueFilter.Apply(ctx, event.Val)
+	if err != nil {
+		return nil, errors.Wrapf(err, "applying rangefeed value filter to %s", event.Val.Key)
+	}
+	if !ok {
+		r.metrics.RangeFeedFilteredEvents.Inc(1)
+		return nil, nil
+	}
+	if val != event.Val {
+		cpy := *event
+		cpy.Val = val
+		event = &cpy
+	}
+	return event, nil
+}
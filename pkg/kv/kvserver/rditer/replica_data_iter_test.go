@@ -110,11 +110,11 @@ func createRangeData(
 	locks := []storage.LockTableKey{
 		{
 			Key:      keys.RangeDescriptorKey(desc.StartKey), // mark [1] above as intent
-			Strength: lock.Exclusive,
+			Strength: lock.Intent,
 			TxnUUID:  testTxnID.GetBytes(),
 		}, {
 			Key:      desc.StartKey.AsRawKey(), // mark [2] above as intent
-			Strength: lock.Exclusive,
+			Strength: lock.Intent,
 			TxnUUID:  testTxnID.GetBytes(),
 		},
 	}
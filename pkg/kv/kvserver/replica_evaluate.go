@@ -522,10 +522,10 @@ func evaluateCommand(
 			DontInterleaveIntents: evalPath == readOnlyWithoutInterleavedIntents,
 		}
 
-		if cmd.EvalRW != nil {
-			pd, err = cmd.EvalRW(ctx, readWriter, cArgs, reply)
-		} else {
+		if cmd.EvalRO != nil && (cmd.EvalRW == nil || kvpb.IsReadOnly(args)) {
 			pd, err = cmd.EvalRO(ctx, readWriter, cArgs, reply)
+		} else {
+			pd, err = cmd.EvalRW(ctx, readWriter, cArgs, reply)
 		}
 	} else {
 		return result.Result{}, errors.Errorf("unrecognized command %s", args.Method())
@@ -145,6 +145,17 @@ func (r *Replica) RangeFeed(
 		return future.MakeCompletedErrorFuture(err)
 	}
 
+	// If the client pushed down a value filter, construct it before we lock
+	// the raftMu. The filter is an optimization, so it is ignored if this
+	// binary can't evaluate it.
+	var valueFilter rangefeed.ValueFilter
+	if args.ValueFilter != nil && rangefeed.NewValueFilter != nil {
+		valueFilter, err = rangefeed.NewValueFilter(ctx, r.ClusterSettings(), args.ValueFilter)
+		if err != nil {
+			return future.MakeCompletedErrorFuture(err)
+		}
+	}
+
 	if err := r.ensureClosedTimestampStarted(ctx); err != nil {
 		if err := stream.Send(&kvpb.RangeFeedEvent{Error: &kvpb.RangeFeedError{
 			Error: *err,
@@ -222,7 +233,7 @@ func (r *Replica) RangeFeed(
 	}
 	var done future.ErrorFuture
 	p := r.registerWithRangefeedRaftMuLocked(
-		ctx, rSpan, args.Timestamp, catchUpIterFunc, args.WithDiff, lockedStream, &done,
+		ctx, rSpan, args.Timestamp, catchUpIterFunc, args.WithDiff, valueFilter, lockedStream, &done,
 	)
 	r.raftMu.Unlock()
 
@@ -323,6 +334,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	startTS hlc.Timestamp, // exclusive
 	catchUpIter rangefeed.CatchUpIteratorConstructor,
 	withDiff bool,
+	valueFilter rangefeed.ValueFilter,
 	stream rangefeed.Stream,
 	done *future.ErrorFuture,
 ) *rangefeed.Processor {
@@ -334,7 +346,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	r.rangefeedMu.Lock()
 	p := r.rangefeedMu.proc
 	if p != nil {
-		reg, filter := p.Register(span, startTS, catchUpIter, withDiff, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
+		reg, filter := p.Register(span, startTS, catchUpIter, withDiff, valueFilter, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
 		if reg {
 			// Registered successfully with an existing processor.
 			// Update the rangefeed filter to avoid filtering ops
@@ -403,7 +415,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	// any other goroutines are able to stop the processor. In other words,
 	// this ensures that the only time the registration fails is during
 	// server shutdown.
-	reg, filter := p.Register(span, startTS, catchUpIter, withDiff, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
+	reg, filter := p.Register(span, startTS, catchUpIter, withDiff, valueFilter, stream, func() { r.maybeDisconnectEmptyRangefeed(p) }, done)
 	if !reg {
 		select {
 		case <-r.store.Stopper().ShouldQuiesce():
@@ -238,7 +238,8 @@ func (r *Replica) maybeCommitWaitBeforeCommitTrigger(
 		return nil
 	}
 	txn := ba.Txn
-	if txn.ReadTimestamp != txn.WriteTimestamp && !ba.CanForwardReadTimestamp {
+	if txn.ReadTimestamp != txn.WriteTimestamp && !ba.CanForwardReadTimestamp &&
+		!txn.IsoLevel.ToleratesWriteSkew() {
 		// The commit can not succeed.
 		return nil
 	}
@@ -245,6 +245,11 @@ func (i *MVCCIterator) IsPrefix() bool {
 	return i.i.IsPrefix()
 }
 
+// ReplicatedLocks is part of the storage.MVCCIterator interface.
+func (i *MVCCIterator) ReplicatedLocks() []storage.ReplicatedLock {
+	return i.i.ReplicatedLocks()
+}
+
 // UnsafeLazyValue is part of the storage.MVCCIterator interface.
 func (i *MVCCIterator) UnsafeLazyValue() pebble.LazyValue {
 	return i.i.UnsafeLazyValue()
@@ -68,7 +68,8 @@ func TestingOverrideTxnLivenessThreshold(t time.Duration) func() {
 // ShouldPushImmediately returns whether the PushTxn request should
 // proceed without queueing. This is true for pushes which are neither
 // ABORT nor TIMESTAMP, but also for ABORT and TIMESTAMP pushes where
-// the pushee has min priority or pusher has max priority.
+// the pushee has min priority or pusher has max priority, and for TIMESTAMP
+// pushes where the pushee tolerates write skew.
 func ShouldPushImmediately(req *kvpb.PushTxnRequest) bool {
 	if req.Force {
 		return true
@@ -79,6 +80,11 @@ func ShouldPushImmediately(req *kvpb.PushTxnRequest) bool {
 	if CanPushWithPriority(req.PusherTxn.Priority, req.PusheeTxn.Priority) {
 		return true
 	}
+	if req.PushType == kvpb.PUSH_TIMESTAMP && req.PusheeTxn.IsoLevel.ToleratesWriteSkew() {
+		// A pushee which tolerates write skew can have its timestamp pushed
+		// without being forced to retry, so there is no need to wait for it.
+		return true
+	}
 	return false
 }
 
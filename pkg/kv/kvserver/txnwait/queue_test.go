@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -162,6 +163,37 @@ func TestShouldPushImmediately(t *testing.T) {
 	}
 }
 
+// TestShouldPushImmediatelyIsoLevel tests that timestamp pushes of pushees
+// which tolerate write skew proceed without queueing.
+func TestShouldPushImmediatelyIsoLevel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		typ        kvpb.PushTxnType
+		isoLevel   isolation.Level
+		shouldPush bool
+	}{
+		{kvpb.PUSH_ABORT, isolation.Serializable, false},
+		{kvpb.PUSH_ABORT, isolation.Snapshot, false},
+		{kvpb.PUSH_ABORT, isolation.ReadCommitted, false},
+		{kvpb.PUSH_TIMESTAMP, isolation.Serializable, false},
+		{kvpb.PUSH_TIMESTAMP, isolation.Snapshot, true},
+		{kvpb.PUSH_TIMESTAMP, isolation.ReadCommitted, true},
+	}
+	for _, test := range testCases {
+		t.Run("", func(t *testing.T) {
+			req := kvpb.PushTxnRequest{
+				PushType: test.typ,
+				PusheeTxn: enginepb.TxnMeta{
+					IsoLevel: test.isoLevel,
+				},
+			}
+			shouldPush := ShouldPushImmediately(&req)
+			require.Equal(t, test.shouldPush, shouldPush)
+		})
+	}
+}
+
 func TestCanPushWithPriority(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
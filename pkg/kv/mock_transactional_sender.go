@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -78,6 +79,17 @@ func (m *MockTransactionalSender) SetUserPriority(pri roachpb.UserPriority) erro
 	return nil
 }
 
+// SetIsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsoLevel(isoLevel isolation.Level) error {
+	m.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) IsoLevel() isolation.Level {
+	return m.txn.IsoLevel
+}
+
 // SetDebugName is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
@@ -194,6 +206,9 @@ func (m *MockTransactionalSender) Step(_ context.Context) error {
 	return nil
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) StepReadTimestamp(context.Context) error { return nil }
+
 // SetReadSeqNum is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetReadSeqNum(_ enginepb.TxnSeq) error { return nil }
 
@@ -229,6 +244,11 @@ func (m *MockTransactionalSender) GetTxnRetryableErr(
 func (m *MockTransactionalSender) ClearTxnRetryableErr(ctx context.Context) {
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (m *MockTransactionalSender) PrepareForPartialRetry(ctx context.Context) error {
+	panic("unimplemented")
+}
+
 // HasPerformedReads is part of TxnSenderFactory.
 func (m *MockTransactionalSender) HasPerformedReads() bool {
 	panic("unimplemented")
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -117,6 +118,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsoLevel sets the txn's isolation level. The isolation level must be
+	// set before the txn performs any operations.
+	SetIsoLevel(isolation.Level) error
+
+	// IsoLevel returns the txn's isolation level.
+	IsoLevel() isolation.Level
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -276,6 +284,17 @@ type TxnSender interface {
 	// The method is idempotent.
 	Step(context.Context) error
 
+	// StepReadTimestamp establishes a new read snapshot for the subsequent
+	// operations of a transaction running at an isolation level with
+	// per-statement read snapshots (see isolation.Level.PerStatementReadSnapshot),
+	// by moving its read timestamp forward to the current time. It is a no-op
+	// for transactions running at other isolation levels, and for transactions
+	// whose commit timestamp is fixed.
+	//
+	// StepReadTimestamp is meant to be called at the start of each SQL
+	// statement, alongside Step.
+	StepReadTimestamp(context.Context) error
+
 	// SetReadSeqNum sets the read sequence point for the current transaction.
 	SetReadSeqNum(seq enginepb.TxnSeq) error
 
@@ -328,6 +347,15 @@ type TxnSender interface {
 	// ClearTxnRetryableErr clears the retryable error, if any.
 	ClearTxnRetryableErr(ctx context.Context)
 
+	// PrepareForPartialRetry clears the retryable error without moving the
+	// transaction to a new epoch, so that the client can roll back to a
+	// savepoint and retry only the operations performed since then. This is
+	// only possible for transactions which establish a new read snapshot for
+	// each statement, and only for errors that do not invalidate the
+	// operations performed before the savepoint. Otherwise, an error is
+	// returned and the transaction must be retried from the beginning.
+	PrepareForPartialRetry(ctx context.Context) error
+
 	// HasPerformedReads returns true if a read has been performed.
 	HasPerformedReads() bool
 
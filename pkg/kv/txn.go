@@ -17,6 +17,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -402,6 +403,26 @@ func (txn *Txn) RequiredFrontier() hlc.Timestamp {
 	return txn.mu.sender.RequiredFrontier()
 }
 
+// SetIsoLevel sets the transaction's isolation level. Transactions run with
+// Serializable isolation by default. A transaction's isolation level cannot be
+// changed once it has performed any operations.
+func (txn *Txn) SetIsoLevel(isoLevel isolation.Level) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("SetIsoLevel() called on leaf txn")
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetIsoLevel(isoLevel)
+}
+
+// IsoLevel returns the transaction's isolation level.
+func (txn *Txn) IsoLevel() isolation.Level {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.IsoLevel()
+}
+
 // DisablePipelining instructs the transaction not to pipeline requests. It
 // should rarely be necessary to call this method.
 //
@@ -996,6 +1017,21 @@ func (txn *Txn) PrepareForRetry(ctx context.Context) {
 	txn.handleRetryableErrLocked(ctx, retryErr)
 }
 
+// PrepareForPartialRetry is like PrepareForRetry, except that the transaction
+// is not moved to a new epoch and keeps the locks it acquired so far. The
+// caller is expected to roll back to a savepoint and retry the operations
+// performed since then. An error is returned if the retryable error requires
+// the transaction to be retried from the beginning, in which case the
+// transaction is left untouched.
+func (txn *Txn) PrepareForPartialRetry(ctx context.Context) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("PrepareForPartialRetry() called on leaf txn")
+	}
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.PrepareForPartialRetry(ctx)
+}
+
 // IsRetryableErrMeantForTxn returns true if err is a retryable
 // error meant to restart this client transaction.
 func (txn *Txn) IsRetryableErrMeantForTxn(
@@ -1492,6 +1528,15 @@ func (txn *Txn) Step(ctx context.Context) error {
 	return txn.mu.sender.Step(ctx)
 }
 
+// StepReadTimestamp establishes a new read snapshot for the transaction if
+// its isolation level uses per-statement read snapshots. It is a no-op for
+// other transactions. See TxnSender.StepReadTimestamp.
+func (txn *Txn) StepReadTimestamp(ctx context.Context) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.StepReadTimestamp(ctx)
+}
+
 // SetReadSeqNum sets the read sequence number for this transaction.
 func (txn *Txn) SetReadSeqNum(seq enginepb.TxnSeq) error {
 	txn.mu.Lock()
@@ -0,0 +1,216 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type alterDomainNode struct {
+	n      *tree.AlterDomain
+	prefix catalog.ResolvedObjectPrefix
+	desc   *typedesc.Mutable
+}
+
+// alterDomainNode implements planNode. We set n here to satisfy the linter.
+var _ planNode = &alterDomainNode{n: nil}
+
+// AlterDomain alters a domain.
+// Privileges: ownership of the domain.
+func (p *planner) AlterDomain(ctx context.Context, n *tree.AlterDomain) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"ALTER DOMAIN",
+	); err != nil {
+		return nil, err
+	}
+
+	// Resolve the domain.
+	prefix, desc, err := p.ResolveMutableTypeDescriptor(ctx, n.Domain, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	if desc.Kind != descpb.TypeDescriptor_DOMAIN {
+		return nil, pgerror.Newf(pgcode.WrongObjectType,
+			"%q is not a domain", tree.AsStringWithFQNames(n.Domain, &p.semaCtx.Annotations))
+	}
+
+	// The user needs ownership privilege to alter the domain.
+	if err := p.canModifyType(ctx, desc); err != nil {
+		return nil, err
+	}
+
+	return &alterDomainNode{
+		n:      n,
+		prefix: prefix,
+		desc:   desc,
+	}, nil
+}
+
+func (n *alterDomainNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounterWithExtra("domain", n.n.Cmd.TelemetryName()))
+
+	typeName := tree.AsStringWithFQNames(n.n.Domain, params.p.Ann())
+	jobDesc := tree.AsStringWithFQNames(n.n, params.p.Ann())
+	domain := n.desc.Domain
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterDomainSetDefault:
+		if t.Default == nil {
+			domain.DefaultExpr = nil
+			break
+		}
+		defaultExpr, err := schemaexpr.ValidateDomainDefaultExpr(
+			params.ctx, t.Default, domain.BaseType, params.p.SemaCtx(),
+			params.ExecCfg().Settings.Version.ActiveVersion(params.ctx),
+		)
+		if err != nil {
+			return err
+		}
+		domain.DefaultExpr = &defaultExpr
+	case *tree.AlterDomainSetNotNull:
+		// A new NOT NULL constraint is enforced on writes right away, and the
+		// existing values are validated by the type schema change job once all
+		// the leases on the old version of the domain have been released.
+		if t.NotNull && !domain.NotNull {
+			domain.NotNullValidating = true
+		}
+		if !t.NotNull {
+			domain.NotNullValidating = false
+		}
+		domain.NotNull = t.NotNull
+	case *tree.AlterDomainAddConstraint:
+		check, err := params.p.makeDomainCheck(params.ctx, n.desc.Name, domain, t.Name, t.Check)
+		if err != nil {
+			return err
+		}
+		// Like a NOT NULL constraint, the new CHECK constraint is validated by
+		// the type schema change job.
+		check.Validating = true
+		domain.Checks = append(domain.Checks, check)
+	case *tree.AlterDomainDropConstraint:
+		idx := -1
+		for i := range domain.Checks {
+			if domain.Checks[i].Name == string(t.Name) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			if t.IfExists {
+				params.p.BufferClientNotice(
+					params.ctx,
+					pgnotice.Newf("constraint %q of domain %q does not exist, skipping", t.Name, n.desc.Name),
+				)
+				return nil
+			}
+			return pgerror.Newf(pgcode.UndefinedObject,
+				"constraint %q of domain %q does not exist", t.Name, n.desc.Name)
+		}
+		domain.Checks = append(domain.Checks[:idx], domain.Checks[idx+1:]...)
+	case *tree.AlterDomainRename:
+		if err := params.p.renameType(params.ctx, &alterTypeNode{
+			n:      &tree.AlterType{Type: n.n.Domain, Cmd: &tree.AlterTypeRename{NewName: t.NewName}},
+			prefix: n.prefix,
+			desc:   n.desc,
+		}, string(t.NewName)); err != nil {
+			return err
+		}
+		return params.p.logEvent(params.ctx, n.desc.ID, &eventpb.RenameType{
+			TypeName:    typeName,
+			NewTypeName: string(t.NewName),
+		})
+	default:
+		return errors.AssertionFailedf("unknown alter domain cmd %s", t)
+	}
+
+	if err := params.p.writeTypeSchemaChange(params.ctx, n.desc, jobDesc); err != nil {
+		return err
+	}
+	// Write a log event.
+	return params.p.logEvent(params.ctx,
+		n.desc.ID,
+		&eventpb.AlterType{
+			TypeName: typeName,
+		})
+}
+
+// domainNotNullCheckExpr is the expression with which the NOT NULL constraint
+// of a domain is validated, in the same form as the domain's CHECK constraints.
+var domainNotNullCheckExpr = fmt.Sprintf("%s IS NOT NULL", schemaexpr.DomainValueName)
+
+// validateDomainConstraint verifies that all the values of the columns using
+// the given domain satisfy the given check expression. If a value doesn't, an
+// error with the given code and message is returned.
+func validateDomainConstraint(
+	ctx context.Context,
+	txn descs.Txn,
+	desc catalog.TypeDescriptor,
+	checkExpr string,
+	code pgcode.Code,
+	msg string,
+) error {
+	for _, id := range desc.GetReferencingDescriptorIDs() {
+		refDesc, err := txn.Descriptors().ByID(txn.KV()).Get().Desc(ctx, id)
+		if err != nil {
+			return err
+		}
+		tbl, ok := refDesc.(catalog.TableDescriptor)
+		if !ok || !tbl.IsTable() || !tbl.Public() {
+			continue
+		}
+		for _, col := range tbl.PublicColumns() {
+			typ := col.GetType()
+			if !typ.IsDomain() || typedesc.GetUserDefinedTypeDescID(typ) != desc.GetID() {
+				continue
+			}
+			expr, err := schemaexpr.DomainCheckExprForColumn(checkExpr, col.ColName())
+			if err != nil {
+				return err
+			}
+			row, err := txn.QueryRowEx(
+				ctx,
+				"validate domain constraint",
+				txn.KV(),
+				sessiondata.RootUserSessionDataOverride,
+				fmt.Sprintf(`SELECT 1 FROM [%d AS t] WHERE NOT (%s) LIMIT 1`, tbl.GetID(), expr),
+			)
+			if err != nil {
+				return err
+			}
+			if row != nil {
+				return pgerror.Newf(code, "column %q of table %q %s", col.GetName(), tbl.GetName(), msg)
+			}
+		}
+	}
+	return nil
+}
+
+func (n *alterDomainNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterDomainNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterDomainNode) Close(ctx context.Context)           {}
+func (n *alterDomainNode) ReadingOwnWrites()                   {}
@@ -85,7 +85,7 @@ func (n *alterFunctionOptionsNode) startExec(params runParams) error {
 		if err := maybeValidateNewFuncVolatility(params, fnDesc, option); err != nil {
 			return err
 		}
-		if err := setFuncOption(params, fnDesc, option); err != nil {
+		if err := setFuncOption(params, fnDesc, option, tree.GetFuncLanguage(n.n.Options)); err != nil {
 			return err
 		}
 	}
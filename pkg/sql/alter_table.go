@@ -178,6 +178,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 		if !n.tableDesc.HasPrimaryKey() && !isAlterCmdValidWithoutPrimaryKey(cmd) {
 			return errors.Newf("table %q does not have a primary key, cannot perform%s", n.tableDesc.Name, tree.AsString(cmd))
 		}
+		if err := checkAlterTableCmdForInheritance(n.tableDesc, cmd); err != nil {
+			return err
+		}
 
 		switch t := cmd.(type) {
 		case *tree.AlterTableAddColumn:
@@ -212,6 +215,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 			}
 			switch d := t.ConstraintDef.(type) {
 			case *tree.UniqueConstraintTableDef:
+				if err := checkUniqueConstraintDeferrability(d); err != nil {
+					return err
+				}
 				if d.WithoutIndex {
 					if err := addUniqueWithoutIndexTableDef(
 						params.ctx,
@@ -355,6 +361,32 @@ func (n *alterTableNode) startExec(params runParams) error {
 						return err
 					}
 				}
+			case *tree.ExclusionConstraintTableDef:
+				if t.ValidationBehavior == tree.ValidationSkip {
+					return sqlerrors.NewUnsupportedUnvalidatedConstraintError(catconstants.ConstraintTypeExclusion)
+				}
+				idx, err := makeExclusionConstraintIndex(
+					params.ctx,
+					params.ExecCfg().Settings,
+					n.tableDesc,
+					tn,
+					d,
+					params.p.SemaCtx(),
+					params.ExecCfg().Settings.Version.ActiveVersion(params.ctx),
+				)
+				if err != nil {
+					return err
+				}
+				idx.CreatedAtNanos = params.EvalContext().GetTxnTimestamp(time.Microsecond).UnixNano()
+				if err := n.tableDesc.AddIndexMutationMaybeWithTempIndex(
+					&idx, descpb.DescriptorMutation_ADD,
+				); err != nil {
+					return err
+				}
+				version := params.ExecCfg().Settings.Version.ActiveVersion(params.ctx)
+				if err := n.tableDesc.AllocateIDs(params.ctx, version); err != nil {
+					return err
+				}
 			case *tree.CheckConstraintTableDef:
 				var err error
 				params.p.runWithOptions(resolveFlags{contextDatabaseID: n.tableDesc.ParentID}, func() {
@@ -511,6 +543,11 @@ func (n *alterTableNode) startExec(params runParams) error {
 			name := string(t.Constraint)
 			c := catalog.FindConstraintByName(n.tableDesc, name)
 			if c == nil {
+				if idx := catalog.FindIndexByName(n.tableDesc, name); idx != nil && idx.IsExclusion() {
+					return unimplemented.NewWithIssueDetailf(46657, "drop-constraint-exclude",
+						"cannot drop exclusion constraint %q using ALTER TABLE DROP CONSTRAINT, use DROP INDEX instead",
+						tree.ErrNameString(name))
+				}
 				if t.IfExists {
 					continue
 				}
@@ -812,6 +849,13 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return err
 			}
 			descriptorChanged = true
+
+		case *tree.AlterTableInherit:
+			if err := params.p.alterTableInherit(params.ctx, n.tableDesc, t); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
 		default:
 			return errors.AssertionFailedf("unsupported alter command: %T", cmd)
 		}
@@ -1483,6 +1527,23 @@ func validateConstraintNameIsNotUsed(
 		}
 		return false, pgerror.Newf(pgcode.DuplicateRelation, "constraint with name %q already exists", name)
 
+	case *tree.ExclusionConstraintTableDef:
+		name = d.Name
+		hasIfNotExists = d.IfNotExists
+		// Exclusion constraints are backed by an index with the same name.
+		if name == "" {
+			return false, nil
+		}
+		if idx := catalog.FindIndexByName(tableDesc, string(name)); idx != nil {
+			if d.IfNotExists {
+				return true, nil
+			}
+			if idx.Dropped() {
+				return false, pgerror.Newf(pgcode.DuplicateObject, "constraint with name %q already exists and is being dropped, try again later", name)
+			}
+			return false, pgerror.Newf(pgcode.DuplicateRelation, "constraint with name %q already exists", name)
+		}
+
 	default:
 		return false, errors.AssertionFailedf(
 			"unsupported constraint: %T", cmd.ConstraintDef)
@@ -84,6 +84,15 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 			"%q is a table's record type and cannot be modified",
 			tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
 		)
+	case descpb.TypeDescriptor_DOMAIN, descpb.TypeDescriptor_RANGE, descpb.TypeDescriptor_MULTIRANGE:
+		switch n.Cmd.(type) {
+		case *tree.AlterTypeAddValue, *tree.AlterTypeRenameValue, *tree.AlterTypeDropValue:
+			return nil, pgerror.Newf(
+				pgcode.WrongObjectType,
+				"%q is not an enum",
+				tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
+			)
+		}
 	}
 
 	return &alterTypeNode{
@@ -241,6 +250,11 @@ func (p *planner) renameType(ctx context.Context, n *alterTypeNode, newName stri
 		return err
 	}
 
+	// Domains don't have an array type.
+	if n.desc.Kind == descpb.TypeDescriptor_DOMAIN {
+		return nil
+	}
+
 	// Now rename the array type.
 	newArrayName, err := findFreeArrayTypeName(
 		ctx,
@@ -373,15 +387,18 @@ func (p *planner) setTypeSchema(ctx context.Context, n *alterTypeNode, schema st
 		return err
 	}
 
-	arrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, n.desc.ArrayTypeID)
-	if err != nil {
-		return err
-	}
+	// Domains don't have an array type.
+	if n.desc.Kind != descpb.TypeDescriptor_DOMAIN {
+		arrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, n.desc.ArrayTypeID)
+		if err != nil {
+			return err
+		}
 
-	if err := p.performRenameTypeDesc(
-		ctx, arrayDesc, arrayDesc.Name, desiredSchemaID, tree.AsStringWithFQNames(n.n, p.Ann()),
-	); err != nil {
-		return err
+		if err := p.performRenameTypeDesc(
+			ctx, arrayDesc, arrayDesc.Name, desiredSchemaID, tree.AsStringWithFQNames(n.n, p.Ann()),
+		); err != nil {
+			return err
+		}
 	}
 
 	newName, err := p.getQualifiedTypeName(ctx, typeDesc)
@@ -405,9 +422,13 @@ func (p *planner) alterTypeOwner(
 	typeDesc := n.desc
 	oldOwner := typeDesc.GetPrivileges().Owner()
 
-	arrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, typeDesc.ArrayTypeID)
-	if err != nil {
-		return err
+	var arrayDesc *typedesc.Mutable
+	if typeDesc.ArrayTypeID != descpb.InvalidID {
+		var err error
+		arrayDesc, err = p.Descriptors().MutableByID(p.txn).Type(ctx, typeDesc.ArrayTypeID)
+		if err != nil {
+			return err
+		}
 	}
 
 	if err := p.checkCanAlterToNewOwner(ctx, typeDesc, newOwner); err != nil {
@@ -422,7 +443,10 @@ func (p *planner) alterTypeOwner(
 
 	typeNameWithPrefix := tree.MakeTypeNameWithPrefix(n.prefix.NamePrefix(), typeDesc.GetName())
 
-	arrayTypeNameWithPrefix := tree.MakeTypeNameWithPrefix(n.prefix.NamePrefix(), arrayDesc.GetName())
+	var arrayTypeNameWithPrefix tree.TypeName
+	if arrayDesc != nil {
+		arrayTypeNameWithPrefix = tree.MakeTypeNameWithPrefix(n.prefix.NamePrefix(), arrayDesc.GetName())
+	}
 
 	if err := p.setNewTypeOwner(ctx, typeDesc, arrayDesc, typeNameWithPrefix,
 		arrayTypeNameWithPrefix, newOwner); err != nil {
@@ -439,6 +463,9 @@ func (p *planner) alterTypeOwner(
 	); err != nil {
 		return err
 	}
+	if arrayDesc == nil {
+		return nil
+	}
 
 	return p.writeTypeSchemaChange(
 		ctx, arrayDesc, tree.AsStringWithFQNames(n.n, p.Ann()),
@@ -446,7 +473,8 @@ func (p *planner) alterTypeOwner(
 }
 
 // setNewTypeOwner handles setting a new type owner.
-// Called in ALTER TYPE and REASSIGN OWNED BY.
+// Called in ALTER TYPE and REASSIGN OWNED BY. arrayTypeDesc is nil for types
+// without an implicit array type, such as domains.
 func (p *planner) setNewTypeOwner(
 	ctx context.Context,
 	typeDesc *typedesc.Mutable,
@@ -458,9 +486,6 @@ func (p *planner) setNewTypeOwner(
 	privs := typeDesc.GetPrivileges()
 	privs.SetOwner(newOwner)
 
-	// Also have to change the owner of the implicit array type.
-	arrayTypeDesc.Privileges.SetOwner(newOwner)
-
 	if err := p.logEvent(ctx,
 		typeDesc.GetID(),
 		&eventpb.AlterTypeOwner{
@@ -469,6 +494,13 @@ func (p *planner) setNewTypeOwner(
 		}); err != nil {
 		return err
 	}
+	if arrayTypeDesc == nil {
+		return nil
+	}
+
+	// Also have to change the owner of the implicit array type.
+	arrayTypeDesc.Privileges.SetOwner(newOwner)
+
 	return p.logEvent(ctx,
 		arrayTypeDesc.GetID(),
 		&eventpb.AlterTypeOwner{
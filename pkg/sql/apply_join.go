@@ -340,6 +340,22 @@ func runPlanInsidePlan(
 	execCfg.DistSQLPlanner.PlanAndRun(
 		ctx, evalCtx, planCtx, plannerCopy.Txn(), plan.main, recv, finishedSetupFn,
 	)
+	if err := resultWriter.Err(); err != nil {
+		return err
+	}
+
+	// Mutations planned inside routines, such as the statements executed by
+	// triggers, may have their own cascades and checks. The "inner" plan is
+	// never allowed to commit the transaction.
+	if len(plan.cascades) != 0 || len(plan.checkPlans) != 0 {
+		plannerCopy.autoCommit = false
+		evalCtxFactory := func(bool) *extendedEvalContext {
+			return params.p.ExtendedEvalContextCopy()
+		}
+		execCfg.DistSQLPlanner.PlanAndRunCascadesAndChecks(
+			ctx, &plannerCopy, evalCtxFactory, plan, recv,
+		)
+	}
 	return resultWriter.Err()
 }
 
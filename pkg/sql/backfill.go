@@ -1468,7 +1468,7 @@ func (sc *SchemaChanger) validateIndexes(ctx context.Context) error {
 		return err
 	}
 
-	var forwardIndexes, invertedIndexes []catalog.Index
+	var forwardIndexes, invertedIndexes, exclusionIndexes []catalog.Index
 
 	for _, m := range tableDesc.AllMutations() {
 		if sc.mutationID != m.MutationID() {
@@ -1485,6 +1485,9 @@ func (sc *SchemaChanger) validateIndexes(ctx context.Context) error {
 		case descpb.IndexDescriptor_INVERTED:
 			invertedIndexes = append(invertedIndexes, idx)
 		}
+		if idx.IsExclusion() {
+			exclusionIndexes = append(exclusionIndexes, idx)
+		}
 	}
 	if len(forwardIndexes) == 0 && len(invertedIndexes) == 0 {
 		return nil
@@ -1527,6 +1530,19 @@ func (sc *SchemaChanger) validateIndexes(ctx context.Context) error {
 	if err := grp.Wait(); err != nil {
 		return err
 	}
+	// Exclusion constraints are validated once their indexes are known to be
+	// complete, since the validation query may use them.
+	if len(exclusionIndexes) > 0 {
+		if err := validateExclusionConstraints(
+			ctx,
+			tableDesc,
+			exclusionIndexes,
+			runHistoricalTxn,
+			sessiondata.NoSessionDataOverride,
+		); err != nil {
+			return err
+		}
+	}
 	log.Info(ctx, "finished validating new indexes")
 	return nil
 }
@@ -0,0 +1,152 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// storedProcTxnState describes a COMMIT or ROLLBACK statement executed by a
+// stored procedure. After the CALL statement finishes, the connExecutor
+// commits or aborts the transaction, and then executes resumeProc in a new
+// transaction to run the remainder of the procedure body.
+type storedProcTxnState struct {
+	txnOp      tree.StoredProcTxnOp
+	resumeProc *tree.Call
+}
+
+// callNode represents a CALL statement. It executes the statements of the
+// procedure body up to the next COMMIT or ROLLBACK statement, if any.
+type callNode struct {
+	// proc is the routine which executes the procedure body statements.
+	proc *tree.RoutineExpr
+
+	// columns are the output columns of the procedure, one for each OUT and
+	// INOUT parameter.
+	columns colinfo.ResultColumns
+
+	// procOID is the OID of the procedure, which is used to resume it after
+	// the transaction is committed or aborted.
+	procOID oid.Oid
+
+	// txnOp is set if the procedure body statements executed by proc are
+	// followed by a COMMIT or ROLLBACK statement.
+	txnOp tree.StoredProcTxnOp
+
+	// resumeAt is the index of the body statement after the COMMIT or
+	// ROLLBACK statement.
+	resumeAt int
+
+	run struct {
+		// row is the result of the procedure, if it has any OUT parameters.
+		row  tree.Datums
+		done bool
+	}
+}
+
+var _ planNode = &callNode{}
+
+func (n *callNode) startExec(params runParams) error {
+	if n.txnOp != tree.StoredProcTxnNoOp {
+		if err := checkStoredProcTxnControl(params.p); err != nil {
+			return err
+		}
+	}
+
+	// Evaluate the arguments once so that they can be passed to the remainder
+	// of the procedure if it is resumed in a new transaction.
+	evalCtx := params.EvalContext()
+	args := make(tree.Datums, len(n.proc.Args))
+	for i := range n.proc.Args {
+		var err error
+		args[i], err = eval.Expr(params.ctx, evalCtx, n.proc.Args[i])
+		if err != nil {
+			return err
+		}
+	}
+	res, err := params.p.EvalRoutineExpr(params.ctx, n.proc, args)
+	if err != nil {
+		return err
+	}
+
+	if n.txnOp != tree.StoredProcTxnNoOp {
+		exprs := make(tree.Exprs, len(args))
+		for i := range args {
+			exprs[i] = args[i]
+		}
+		params.p.storedProcTxnState = storedProcTxnState{
+			txnOp: n.txnOp,
+			resumeProc: &tree.Call{
+				Proc: &tree.FuncExpr{
+					Func: tree.ResolvableFunctionReference{
+						FunctionReference: &tree.FunctionOID{OID: n.procOID},
+					},
+					Exprs: exprs,
+				},
+				ResumeAt: n.resumeAt,
+			},
+		}
+		return nil
+	}
+
+	if len(n.columns) > 0 {
+		n.run.row = make(tree.Datums, len(n.columns))
+		if res == tree.DNull {
+			for i := range n.run.row {
+				n.run.row[i] = tree.DNull
+			}
+		} else {
+			tup, ok := tree.AsDTuple(res)
+			if !ok {
+				return errors.AssertionFailedf("expected tuple result of procedure, found %T", res)
+			}
+			copy(n.run.row, tup.D)
+		}
+	}
+	return nil
+}
+
+// checkStoredProcTxnControl returns an error if a stored procedure cannot
+// commit or abort the current transaction. This is only possible for a
+// top-level CALL statement which is the only statement in an implicit
+// transaction.
+func checkStoredProcTxnControl(p *planner) error {
+	if _, ok := p.stmt.AST.(*tree.Call); !ok ||
+		!p.extendedEvalCtx.TxnImplicit || !p.extendedEvalCtx.TxnIsSingleStmt {
+		return pgerror.New(pgcode.InvalidTransactionTermination, "invalid transaction termination")
+	}
+	if p.stmt.Prepared != nil {
+		return unimplemented.New("procedure transaction control",
+			"COMMIT and ROLLBACK in procedures are not supported with the extended protocol")
+	}
+	return nil
+}
+
+func (n *callNode) Next(params runParams) (bool, error) {
+	if n.run.done || n.run.row == nil {
+		return false, nil
+	}
+	n.run.done = true
+	return true, nil
+}
+
+func (n *callNode) Values() tree.Datums { return n.run.row }
+
+func (n *callNode) Close(ctx context.Context) {}
@@ -455,6 +455,7 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	target.AddDescriptor(systemschema.SpanStatsBucketsTable)
 	target.AddDescriptor(systemschema.SpanStatsSamplesTable)
 	target.AddDescriptor(systemschema.SpanStatsTenantBoundariesTable)
+	target.AddDescriptor(systemschema.NotificationsTable)
 
 	// Adding a new system table? It should be added here to the metadata schema,
 	// and also created as a migration for older clusters.
@@ -466,7 +467,7 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 // NumSystemTablesForSystemTenant is the number of system tables defined on
 // the system tenant. This constant is only defined to avoid having to manually
 // update auto stats tests every time a new system table is added.
-const NumSystemTablesForSystemTenant = 46
+const NumSystemTablesForSystemTenant = 47
 
 // addSplitIDs adds a split point for each of the PseudoTableIDs to the supplied
 // MetadataSchema.
@@ -95,6 +95,15 @@ func indexForDisplay(
 	}
 
 	f := tree.NewFmtCtx(formatFlags)
+	if len(index.ExclusionOperators) > 0 && displayMode == IndexDisplayDefOnly &&
+		!f.HasFlags(tree.FmtPGCatalog) {
+		// An index backing an exclusion constraint is displayed as the
+		// constraint, which recreates the index.
+		if err := formatExclusionConstraint(ctx, table, index, f, semaCtx, sessionData); err != nil {
+			return "", err
+		}
+		return f.CloseAndGetString(), nil
+	}
 	if displayMode == IndexDisplayShowCreate {
 		f.WriteString("CREATE ")
 	}
@@ -181,6 +190,52 @@ func indexForDisplay(
 	return f.CloseAndGetString(), nil
 }
 
+// formatExclusionConstraint formats the exclusion constraint backed by the
+// given index, in the form:
+//
+//	CONSTRAINT name EXCLUDE USING gist (a WITH =, b WITH &&) WHERE pred
+func formatExclusionConstraint(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	index *descpb.IndexDescriptor,
+	f *tree.FmtCtx,
+	semaCtx *tree.SemaContext,
+	sessionData *sessiondata.SessionData,
+) error {
+	f.WriteString("CONSTRAINT ")
+	f.FormatNameP(&index.Name)
+	f.WriteString(" EXCLUDE ")
+	// The && operator requires USING gist, even if the constraint is backed by
+	// a forward index.
+	for _, op := range index.ExclusionOperators {
+		if op == "&&" {
+			f.WriteString("USING gist ")
+			break
+		}
+	}
+	f.WriteByte('(')
+	for i := range index.KeyColumnNames {
+		if i > 0 {
+			f.WriteString(", ")
+		}
+		f.FormatNameP(&index.KeyColumnNames[i])
+		f.WriteString(" WITH ")
+		f.WriteString(index.ExclusionOperators[i])
+	}
+	f.WriteByte(')')
+	if index.IsPartial() {
+		pred, err := schemaexpr.FormatExprForDisplay(
+			ctx, table, index.Predicate, semaCtx, sessionData, tree.FmtParsable,
+		)
+		if err != nil {
+			return err
+		}
+		f.WriteString(" WHERE ")
+		f.WriteString(pred)
+	}
+	return nil
+}
+
 // FormatIndexElements formats the key columns an index. If the column is an
 // inaccessible computed column, the computed column expression is formatted.
 // Otherwise, the column name is formatted. Each column is separated by commas
@@ -69,6 +69,7 @@ var (
 		catconstants.SpanStatsBuckets,
 		catconstants.SpanStatsSamples,
 		catconstants.SpanStatsTenantBoundaries,
+		catconstants.NotificationsTableName,
 	}
 
 	readWriteSystemSequences = []catconstants.SystemTableName{
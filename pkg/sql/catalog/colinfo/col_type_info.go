@@ -124,6 +124,12 @@ func ValidateColumnDefType(ctx context.Context, version clusterversion.Handle, t
 				"TSVector/TSQuery not supported until version 23.1")
 		}
 
+	case types.RangeFamily, types.MultirangeFamily:
+		if !version.IsActive(ctx, clusterversion.V23_1RangeTypes) {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"range types are not supported until version 23.1")
+		}
+
 	default:
 		return pgerror.Newf(pgcode.InvalidTableDefinition,
 			"value type %s cannot be used for table columns", t.String())
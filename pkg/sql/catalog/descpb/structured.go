@@ -74,6 +74,9 @@ type IndexID = catid.IndexID
 // ConstraintID is a custom type for TableDescriptor constraint IDs.
 type ConstraintID = catid.ConstraintID
 
+// TriggerID is a custom type for TableDescriptor trigger IDs.
+type TriggerID = catid.TriggerID
+
 // DescriptorVersion is a custom type for TableDescriptor Versions.
 type DescriptorVersion uint64
 
@@ -528,6 +528,11 @@ type TableDescriptor interface {
 	// GetNextConstraintID returns the next unused constraint ID for this table.
 	// Constraint IDs are unique per table, but not unique globally.
 	GetNextConstraintID() descpb.ConstraintID
+	// GetTriggers returns the triggers defined on this table.
+	GetTriggers() []descpb.TriggerDescriptor
+	// GetNextTriggerID returns the next unused trigger ID for this table.
+	// Trigger IDs are unique per table, but not unique globally.
+	GetNextTriggerID() descpb.TriggerID
 	// IsShardColumn returns true if col corresponds to a non-dropped hash sharded
 	// index. This method assumes that col is currently a member of desc.
 	IsShardColumn(col Column) bool
@@ -596,6 +601,12 @@ type TableDescriptor interface {
 		colID descpb.ColumnID,
 	) (DescriptorIDSet, error)
 
+	// GetAllReferencedFunctionIDsInTrigger returns descriptor IDs of all user
+	// defined functions referenced by this trigger.
+	GetAllReferencedFunctionIDsInTrigger(
+		triggerID descpb.TriggerID,
+	) (DescriptorIDSet, error)
+
 	// ForeachDependedOnBy runs a function on all indexes, including those being
 	// added in the mutations.
 	ForeachDependedOnBy(f func(dep *descpb.TableDescriptor_Reference) error) error
@@ -610,6 +621,11 @@ type TableDescriptor interface {
 	// GetDependsOnFunctions returns the IDs of all functions that this view
 	// depends on. It's only non-nil if IsView is true.
 	GetDependsOnFunctions() []descpb.ID
+	// GetInheritsFrom returns the IDs of the tables this table inherits from.
+	GetInheritsFrom() []descpb.ID
+	// GetInheritedBy returns the IDs of the tables which inherit from this
+	// table.
+	GetInheritedBy() []descpb.ID
 
 	// AllConstraints returns all constraints in this table, regardless if
 	// they're enforced yet or not. The ordering of the constraints within this
@@ -787,6 +803,16 @@ type TypeDescriptor interface {
 	// nil otherwise.
 	AsCompositeTypeDescriptor() CompositeTypeDescriptor
 
+	// AsDomainTypeDescriptor returns this instance cast to
+	// DomainTypeDescriptor if this type is a domain type,
+	// nil otherwise.
+	AsDomainTypeDescriptor() DomainTypeDescriptor
+
+	// AsRangeTypeDescriptor returns this instance cast to
+	// RangeTypeDescriptor if this type is a range or multirange type,
+	// nil otherwise.
+	AsRangeTypeDescriptor() RangeTypeDescriptor
+
 	// AsTableImplicitRecordTypeDescriptor returns this instance cast to
 	// TableImplicitRecordTypeDescriptor if this type is an implicit table record
 	// type, nil otherwise.
@@ -874,6 +900,47 @@ type CompositeTypeDescriptor interface {
 	GetElementType(ordinal int) *types.T
 }
 
+// DomainTypeDescriptor is the TypeDescriptor subtype for domain types, which
+// are base types with optional constraints.
+type DomainTypeDescriptor interface {
+	NonAliasTypeDescriptor
+
+	// DomainBaseType returns the type which the domain is defined over.
+	DomainBaseType() *types.T
+
+	// DomainNotNull returns whether the domain disallows NULL values.
+	DomainNotNull() bool
+
+	// DomainDefaultExpr returns the serialized default expression of the
+	// domain, or nil if it has none.
+	DomainDefaultExpr() *string
+
+	// NumDomainChecks returns the number of CHECK constraints of the domain.
+	NumDomainChecks() int
+
+	// GetDomainCheck returns the CHECK constraint of the domain at the given
+	// ordinal.
+	GetDomainCheck(ordinal int) descpb.TypeDescriptor_Domain_Check
+}
+
+// RangeTypeDescriptor is the TypeDescriptor subtype for user-defined range
+// types and the multirange types which are implicitly created along with
+// them.
+type RangeTypeDescriptor interface {
+	NonAliasTypeDescriptor
+
+	// RangeSubtype returns the type of the values in the range.
+	RangeSubtype() *types.T
+
+	// GetMultirangeTypeID returns the ID of the multirange type of a range
+	// type, or descpb.InvalidID if this is a multirange type.
+	GetMultirangeTypeID() descpb.ID
+
+	// GetRangeTypeID returns the ID of the range type of a multirange type, or
+	// descpb.InvalidID if this is a range type.
+	GetRangeTypeID() descpb.ID
+}
+
 // TableImplicitRecordTypeDescriptor is the TypeDescriptor subtype for the
 // record type implicitly defined by a table.
 type TableImplicitRecordTypeDescriptor interface {
@@ -946,6 +1013,10 @@ type FunctionDescriptor interface {
 	// GetLanguage returns the language of this function.
 	GetLanguage() catpb.Function_Language
 
+	// IsProcedure returns true if the descriptor describes a procedure rather
+	// than a function.
+	IsProcedure() bool
+
 	// ToCreateExpr converts a function descriptor back to a CREATE FUNCTION
 	// statement. This is mainly used for formatting, e.g. SHOW CREATE FUNCTION.
 	ToCreateExpr() (*tree.CreateFunction, error)
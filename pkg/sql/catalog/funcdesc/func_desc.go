@@ -369,6 +369,21 @@ func (desc *immutable) validateInboundTableRef(
 			cstID, backRefTbl.GetName(), backRefTbl.GetID(), desc.GetName(), desc.GetID(),
 		)
 	}
+
+	for _, triggerID := range by.TriggerIDs {
+		fnIDs, err := backRefTbl.GetAllReferencedFunctionIDsInTrigger(triggerID)
+		if err != nil {
+			return err
+		}
+		if fnIDs.Contains(desc.GetID()) {
+			foundInTable = true
+			continue
+		}
+		return errors.AssertionFailedf(
+			"trigger %d in depended-on-by relation %q (%d) does not have reference to function %q (%d)",
+			triggerID, backRefTbl.GetName(), backRefTbl.GetID(), desc.GetName(), desc.GetID(),
+		)
+	}
 	if foundInTable {
 		return nil
 	}
@@ -522,6 +537,11 @@ func (desc *Mutable) SetLang(v catpb.Function_Language) {
 	desc.Lang = v
 }
 
+// SetIsProcedure sets whether the descriptor describes a procedure.
+func (desc *Mutable) SetIsProcedure(v bool) {
+	desc.FunctionDescriptor.IsProcedure = v
+}
+
 // SetFuncBody sets the function body.
 func (desc *Mutable) SetFuncBody(v string) {
 	desc.FunctionBody = v
@@ -611,6 +631,63 @@ func (desc *Mutable) AddColumnReference(id descpb.ID, colID descpb.ColumnID) err
 	return nil
 }
 
+// AddTriggerReference adds back reference to a trigger to the function.
+func (desc *Mutable) AddTriggerReference(id descpb.ID, triggerID descpb.TriggerID) error {
+	for _, dep := range desc.DependsOn {
+		if dep == id {
+			return errors.Errorf(
+				"cannot add dependency from descriptor %d to function %s (%d) because there will be a dependency cycle", id, desc.GetName(), desc.GetID(),
+			)
+		}
+	}
+	for i := range desc.DependedOnBy {
+		if desc.DependedOnBy[i].ID == id {
+			for _, existing := range desc.DependedOnBy[i].TriggerIDs {
+				if existing == triggerID {
+					return nil
+				}
+			}
+			desc.DependedOnBy[i].TriggerIDs = append(desc.DependedOnBy[i].TriggerIDs, triggerID)
+			sort.Slice(desc.DependedOnBy[i].TriggerIDs, func(a, b int) bool {
+				return desc.DependedOnBy[i].TriggerIDs[a] < desc.DependedOnBy[i].TriggerIDs[b]
+			})
+			return nil
+		}
+	}
+	desc.DependedOnBy = append(
+		desc.DependedOnBy,
+		descpb.FunctionDescriptor_Reference{
+			ID:         id,
+			TriggerIDs: []descpb.TriggerID{triggerID},
+		},
+	)
+	sort.Slice(desc.DependedOnBy, func(i, j int) bool {
+		return desc.DependedOnBy[i].ID < desc.DependedOnBy[j].ID
+	})
+	return nil
+}
+
+// RemoveTriggerReference removes back reference to a trigger from the
+// function.
+func (desc *Mutable) RemoveTriggerReference(id descpb.ID, triggerID descpb.TriggerID) {
+	for i := range desc.DependedOnBy {
+		if desc.DependedOnBy[i].ID == id {
+			ids := desc.DependedOnBy[i].TriggerIDs[:0]
+			for _, existing := range desc.DependedOnBy[i].TriggerIDs {
+				if existing != triggerID {
+					ids = append(ids, existing)
+				}
+			}
+			desc.DependedOnBy[i].TriggerIDs = ids
+			if len(ids) == 0 {
+				desc.DependedOnBy[i].TriggerIDs = nil
+			}
+			desc.maybeRemoveTableReference(id)
+			return
+		}
+	}
+}
+
 // RemoveColumnReference removes back reference to a column from the function.
 func (desc *Mutable) RemoveColumnReference(id descpb.ID, colID descpb.ColumnID) {
 	for i := range desc.DependedOnBy {
@@ -631,7 +708,8 @@ func (desc *Mutable) RemoveColumnReference(id descpb.ID, colID descpb.ColumnID)
 func (desc *Mutable) maybeRemoveTableReference(id descpb.ID) {
 	var ret []descpb.FunctionDescriptor_Reference
 	for _, ref := range desc.DependedOnBy {
-		if ref.ID == id && len(ref.ColumnIDs) == 0 && len(ref.IndexIDs) == 0 && len(ref.ConstraintIDs) == 0 {
+		if ref.ID == id && len(ref.ColumnIDs) == 0 && len(ref.IndexIDs) == 0 &&
+			len(ref.ConstraintIDs) == 0 && len(ref.TriggerIDs) == 0 {
 			continue
 		}
 		ret = append(ret, ref)
@@ -678,13 +756,20 @@ func (desc *immutable) GetLanguage() catpb.Function_Language {
 	return desc.Lang
 }
 
+// IsProcedure implements the FunctionDescriptor interface.
+func (desc *immutable) IsProcedure() bool {
+	return desc.FunctionDescriptor.IsProcedure
+}
+
 func (desc *immutable) ToOverload() (ret *tree.Overload, err error) {
 	ret = &tree.Overload{
-		Oid:        catid.FuncIDToOID(desc.ID),
-		ReturnType: tree.FixedReturnType(desc.ReturnType.Type),
-		ReturnSet:  desc.ReturnType.ReturnSet,
-		Body:       desc.FunctionBody,
-		IsUDF:      true,
+		Oid:         catid.FuncIDToOID(desc.ID),
+		ReturnType:  tree.FixedReturnType(desc.ReturnType.Type),
+		ReturnSet:   desc.ReturnType.ReturnSet,
+		Body:        desc.FunctionBody,
+		IsUDF:       true,
+		IsProcedure: desc.IsProcedure(),
+		Language:    desc.getCreateExprLang(),
 	}
 
 	argTypes := make(tree.ParamTypes, 0, len(desc.Params))
@@ -748,7 +833,8 @@ func (desc *immutable) calledOnNullInput() (bool, error) {
 // ToCreateExpr implements the FunctionDescriptor interface.
 func (desc *immutable) ToCreateExpr() (ret *tree.CreateFunction, err error) {
 	ret = &tree.CreateFunction{
-		FuncName: tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(desc.Name)),
+		IsProcedure: desc.IsProcedure(),
+		FuncName:    tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(desc.Name)),
 		ReturnType: tree.FuncReturnType{
 			Type:  desc.ReturnType.Type,
 			IsSet: desc.ReturnType.ReturnSet,
@@ -768,6 +854,14 @@ func (desc *immutable) ToCreateExpr() (ret *tree.CreateFunction, err error) {
 			}
 		}
 	}
+	if desc.IsProcedure() {
+		// Procedures only have a body and a language.
+		ret.Options = tree.FunctionOptions{
+			tree.FunctionBodyStr(desc.FunctionBody),
+			desc.getCreateExprLang(),
+		}
+		return ret, nil
+	}
 	// We only store 5 function attributes at the moment. We may extend the
 	// pre-allocated capacity in the future.
 	ret.Options = make(tree.FunctionOptions, 0, 5)
@@ -783,6 +877,8 @@ func (desc *immutable) getCreateExprLang() tree.FunctionLanguage {
 	switch desc.Lang {
 	case catpb.Function_SQL:
 		return tree.FunctionLangSQL
+	case catpb.Function_PLPGSQL:
+		return tree.FunctionLangPlPgSQL
 	}
 	return tree.FunctionLangUnknown
 }
@@ -11,11 +11,13 @@
 package funcinfo
 
 import (
+	"fmt"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
 
@@ -109,7 +111,7 @@ func FunctionLangToProto(v tree.FunctionLanguage) (catpb.Function_Language, erro
 	case tree.FunctionLangSQL:
 		return catpb.Function_SQL, nil
 	case tree.FunctionLangPlPgSQL:
-		return -1, unimplemented.NewWithIssue(91569, "PL/pgSQL is not yet supported")
+		return catpb.Function_PLPGSQL, nil
 	}
 
 	return -1, pgerror.Newf(pgcode.UndefinedObject, "language %q does not exist", v)
@@ -131,3 +133,34 @@ func ParamClassToProto(v tree.FuncParamClass) (catpb.Function_Param_Class, error
 
 	return -1, errors.AssertionFailedf("unknown function parameter class %q", v)
 }
+
+// ProcedureReturnType returns the type of the result of a procedure with the
+// given parameters. It is a labeled tuple of the OUT and INOUT parameter types,
+// or VOID if there are no such parameters. Parameter types are resolved with
+// the given function.
+func ProcedureReturnType(
+	params tree.FuncParams, resolve func(tree.ResolvableTypeReference) (*types.T, error),
+) (*types.T, error) {
+	var outTypes []*types.T
+	var outLabels []string
+	for i := range params {
+		param := &params[i]
+		if param.Class != tree.FunctionParamOut && param.Class != tree.FunctionParamInOut {
+			continue
+		}
+		typ, err := resolve(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		label := string(param.Name)
+		if label == "" {
+			label = fmt.Sprintf("column%d", len(outTypes)+1)
+		}
+		outTypes = append(outTypes, typ)
+		outLabels = append(outLabels, label)
+	}
+	if len(outTypes) == 0 {
+		return types.Void, nil
+	}
+	return types.MakeLabeledTuple(outTypes, outLabels), nil
+}
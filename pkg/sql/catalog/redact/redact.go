@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/errors"
@@ -168,7 +169,7 @@ func redactElement(element scpb.Element) error {
 			return redactExpr(&e.ComputeExpr.Expr)
 		}
 	case *scpb.FunctionBody:
-		return redactFunctionBodyStr(&e.Body)
+		return redactFunctionBodyStr(&e.Body, e.Lang.Lang)
 	}
 	return nil
 }
@@ -217,7 +218,7 @@ func redactFunctionDescriptor(desc *descpb.FunctionDescriptor) (errs []error) {
 		}
 	}
 
-	if err := redactFunctionBodyStr(&desc.FunctionBody); err != nil {
+	if err := redactFunctionBodyStr(&desc.FunctionBody, desc.Lang); err != nil {
 		return []error{err}
 	}
 	if scs := desc.DeclarativeSchemaChangerState; scs != nil {
@@ -233,7 +234,15 @@ func redactFunctionDescriptor(desc *descpb.FunctionDescriptor) (errs []error) {
 	return nil
 }
 
-func redactFunctionBodyStr(body *string) error {
+func redactFunctionBodyStr(body *string, lang catpb.Function_Language) error {
+	if lang == catpb.Function_PLPGSQL {
+		block, err := plpgsqlparser.Parse(*body)
+		if err != nil {
+			return err
+		}
+		*body = tree.AsStringWithFlags(block, tree.FmtHideConstants)
+		return nil
+	}
 	stmts, err := parser.Parse(*body)
 	if err != nil {
 		return err
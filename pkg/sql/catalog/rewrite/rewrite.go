@@ -571,6 +571,25 @@ func TypeDescs(types []*typedesc.Mutable, descriptorRewrites jobspb.DescRewriteM
 			if err := rewriteIDsInTypesT(typ.Alias, descriptorRewrites); err != nil {
 				return err
 			}
+		case descpb.TypeDescriptor_DOMAIN:
+			// Domains don't reference any other descriptors: they have no array
+			// type, and neither their base type nor their expressions may
+			// reference user-defined types.
+		case descpb.TypeDescriptor_RANGE, descpb.TypeDescriptor_MULTIRANGE:
+			if rw, ok := descriptorRewrites[typ.ArrayTypeID]; ok {
+				typ.ArrayTypeID = rw.ID
+			}
+			// A range type refers to its multirange type, and a multirange type
+			// refers to its range type.
+			if rw, ok := descriptorRewrites[typ.Range.MultirangeTypeID]; ok &&
+				typ.Range.MultirangeTypeID != descpb.InvalidID {
+				typ.Range.MultirangeTypeID = rw.ID
+			}
+			if typ.Range.RangeType != nil {
+				if err := rewriteIDsInTypesT(typ.Range.RangeType, descriptorRewrites); err != nil {
+					return err
+				}
+			}
 		default:
 			return errors.AssertionFailedf("unknown type kind %s", t.String())
 		}
@@ -898,20 +917,23 @@ func FunctionDescs(
 		fnDesc.ParentSchemaID = fnRewrite.ParentSchemaID
 		fnDesc.ParentID = fnRewrite.ParentID
 
-		// Rewrite function body.
-		fnBody := fnDesc.FunctionBody
-		if overrideDB != "" {
-			dbNameReplaced, err := rewriteFunctionBodyDBNames(fnDesc.FunctionBody, overrideDB)
+		// Rewrite function body. The body of a PL/pgSQL function refers to
+		// objects by name, so it is not rewritten.
+		if fnDesc.Lang != catpb.Function_PLPGSQL {
+			fnBody := fnDesc.FunctionBody
+			if overrideDB != "" {
+				dbNameReplaced, err := rewriteFunctionBodyDBNames(fnDesc.FunctionBody, overrideDB)
+				if err != nil {
+					return err
+				}
+				fnBody = dbNameReplaced
+			}
+			fnBody, err := rewriteSequencesInFunction(fnBody, descriptorRewrites)
 			if err != nil {
 				return err
 			}
-			fnBody = dbNameReplaced
-		}
-		fnBody, err := rewriteSequencesInFunction(fnBody, descriptorRewrites)
-		if err != nil {
-			return err
+			fnDesc.FunctionBody = fnBody
 		}
-		fnDesc.FunctionBody = fnBody
 
 		// Rewrite type IDs.
 		for _, param := range fnDesc.Params {
@@ -0,0 +1,130 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schemaexpr
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// DomainValueName is the name by which the CHECK constraints of a domain refer
+// to the value being checked.
+const DomainValueName = tree.Name("value")
+
+// ReplaceDomainValue returns a copy of the given domain CHECK expression in
+// which all references to the VALUE keyword are replaced with repl.
+func ReplaceDomainValue(expr tree.Expr, repl tree.Expr) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		switch t := expr.(type) {
+		case *tree.UnresolvedName:
+			if t.NumParts == 1 && !t.Star && tree.Name(t.Parts[0]) == DomainValueName {
+				return false, repl, nil
+			}
+		case *tree.ColumnItem:
+			if t.TableName == nil && t.ColumnName == DomainValueName {
+				return false, repl, nil
+			}
+		}
+		return true, expr, nil
+	})
+}
+
+// DomainCheckExprForColumn returns the serialized CHECK expression of a domain
+// rewritten to apply to the column with the given name.
+func DomainCheckExprForColumn(checkExpr string, colName tree.Name) (string, error) {
+	expr, err := parser.ParseExpr(checkExpr)
+	if err != nil {
+		return "", err
+	}
+	expr, err = ReplaceDomainValue(expr, &tree.ColumnItem{ColumnName: colName})
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
+// ValidateDomainDefaultExpr validates the DEFAULT expression of a domain with
+// the given base type and returns it serialized.
+func ValidateDomainDefaultExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	baseType *types.T,
+	semaCtx *tree.SemaContext,
+	version clusterversion.ClusterVersion,
+) (string, error) {
+	typedExpr, err := SanitizeVarFreeExpr(
+		ctx, expr, baseType, tree.DomainDefaultExpr, semaCtx, volatility.Volatile,
+		true, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := validateDomainExpr(typedExpr, tree.DomainDefaultExpr, version); err != nil {
+		return "", err
+	}
+	return tree.Serialize(typedExpr), nil
+}
+
+// ValidateDomainCheckExpr validates a CHECK expression of a domain with the
+// given base type and returns it serialized. The expression may only refer to
+// the value being checked, using the VALUE keyword.
+func ValidateDomainCheckExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	baseType *types.T,
+	semaCtx *tree.SemaContext,
+	version clusterversion.ClusterVersion,
+) (string, error) {
+	// Replace VALUE with a dummyColumn of the base type so that the expression
+	// can be type-checked.
+	replacedExpr, err := ReplaceDomainValue(expr, &dummyColumn{typ: baseType, name: DomainValueName})
+	if err != nil {
+		return "", err
+	}
+	typedExpr, err := SanitizeVarFreeExpr(
+		ctx, replacedExpr, types.Bool, tree.DomainCheckExpr, semaCtx, volatility.Volatile,
+		false, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := validateDomainExpr(typedExpr, tree.DomainCheckExpr, version); err != nil {
+		return "", err
+	}
+	return tree.Serialize(typedExpr), nil
+}
+
+// validateDomainExpr returns an error if the given typed expression references
+// user-defined functions or user-defined types. Domains do not track back
+// references to other descriptors, so their expressions may only reference
+// built-in objects.
+func validateDomainExpr(
+	typedExpr tree.TypedExpr, context tree.SchemaExprContext, version clusterversion.ClusterVersion,
+) error {
+	if err := funcdesc.MaybeFailOnUDFUsage(typedExpr, context, version); err != nil {
+		return err
+	}
+	_, err := tree.SimpleVisit(typedExpr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if t, ok := expr.(tree.TypedExpr); ok && t.ResolvedType().UserDefined() {
+			return false, nil, unimplemented.NewWithIssuef(27796,
+				"%s expressions referencing user-defined types are not supported", context)
+		}
+		return true, expr, nil
+	})
+	return err
+}
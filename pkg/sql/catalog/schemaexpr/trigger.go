@@ -0,0 +1,108 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schemaexpr
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+const (
+	// TriggerNewName is the name by which triggers refer to the new version of
+	// the row being inserted or updated.
+	TriggerNewName = tree.Name("new")
+	// TriggerOldName is the name by which triggers refer to the old version of
+	// the row being updated or deleted.
+	TriggerOldName = tree.Name("old")
+)
+
+// ValidateTriggerWhenExpr validates the WHEN condition of a trigger and
+// returns it serialized. The condition may only reference columns of the
+// trigger's table qualified with NEW (if allowNew is true) or OLD (if allowOld
+// is true). The column lookup function allows looking up columns both in the
+// descriptor or in declarative schema changer elements.
+func ValidateTriggerWhenExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	allowNew, allowOld bool,
+	semaCtx *tree.SemaContext,
+	version clusterversion.ClusterVersion,
+	columnLookupFn func(columnName tree.Name) (exists bool, accessible bool, id catid.ColumnID, typ *types.T),
+) (string, error) {
+	replacedExpr, err := tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if _, ok := e.(*tree.Subquery); ok {
+			return false, nil, pgerror.New(pgcode.FeatureNotSupported,
+				"cannot use subquery in trigger WHEN condition")
+		}
+		vBase, ok := e.(tree.VarName)
+		if !ok {
+			return true, e, nil
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+		c, ok := v.(*tree.ColumnItem)
+		if !ok || c.TableName == nil || c.TableName.NumParts != 1 {
+			// Unqualified column references are rejected below as variable
+			// sub-expressions.
+			return true, e, nil
+		}
+		switch tree.Name(c.TableName.Parts[0]) {
+		case TriggerNewName:
+			if !allowNew {
+				return false, nil, pgerror.New(pgcode.InvalidObjectDefinition,
+					"trigger WHEN condition cannot reference NEW values")
+			}
+		case TriggerOldName:
+			if !allowOld {
+				return false, nil, pgerror.New(pgcode.InvalidObjectDefinition,
+					"trigger WHEN condition cannot reference OLD values")
+			}
+		default:
+			return true, e, nil
+		}
+		colExists, colIsAccessible, _, colType := columnLookupFn(c.ColumnName)
+		if !colExists {
+			return false, nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q does not exist, referenced in %q", c.ColumnName, expr.String())
+		}
+		if !colIsAccessible {
+			return false, nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q is inaccessible and cannot be referenced", c.ColumnName)
+		}
+		return false, &dummyColumn{typ: colType, name: c.ColumnName}, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	typedExpr, err := SanitizeVarFreeExpr(
+		ctx, replacedExpr, types.Bool, tree.TriggerWhenExpr, semaCtx, volatility.Volatile,
+		false, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := funcdesc.MaybeFailOnUDFUsage(typedExpr, tree.TriggerWhenExpr, version); err != nil {
+		return "", err
+	}
+	// The type-checked expression no longer contains the NEW and OLD prefixes,
+	// so serialize the original expression instead.
+	return tree.Serialize(expr), nil
+}
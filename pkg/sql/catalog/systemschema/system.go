@@ -828,6 +828,21 @@ CREATE TABLE system.span_stats_tenant_boundaries (
 	CONSTRAINT "primary" PRIMARY KEY (tenant_id),
 	FAMILY "primary" (tenant_id, boundaries)
 );`
+
+	// NotificationsTableSchema stores the notifications published with NOTIFY
+	// and pg_notify(). Rows are written by the publishing transaction, and are
+	// delivered to listening sessions through a rangefeed on the table.
+	NotificationsTableSchema = `
+CREATE TABLE system.notifications (
+	id         INT8 NOT NULL DEFAULT unique_rowid(),
+	channel    STRING NOT NULL,
+	payload    STRING NOT NULL,
+	pid        INT4 NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT "primary" PRIMARY KEY (id),
+	INDEX notifications_created_at_idx (created_at ASC),
+	FAMILY "primary" (id, channel, payload, pid, created_at)
+);`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1032,6 +1047,7 @@ func MakeSystemTables() []SystemTable {
 		SpanStatsUniqueKeysTable,
 		SpanStatsBucketsTable,
 		SpanStatsSamplesTable,
+		NotificationsTable,
 	}
 }
 
@@ -3134,6 +3150,40 @@ var (
 			},
 		),
 	)
+
+	// NotificationsTable is the descriptor for the notifications table.
+	NotificationsTable = makeSystemTable(
+		NotificationsTableSchema,
+		systemTable(
+			catconstants.NotificationsTableName,
+			descpb.InvalidID, // dynamically assigned table ID
+			[]descpb.ColumnDescriptor{
+				{Name: "id", ID: 1, Type: types.Int, DefaultExpr: &uniqueRowIDString},
+				{Name: "channel", ID: 2, Type: types.String},
+				{Name: "payload", ID: 3, Type: types.String},
+				{Name: "pid", ID: 4, Type: types.Int4},
+				{Name: "created_at", ID: 5, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"id", "channel", "payload", "pid", "created_at"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+				},
+			},
+			pk("id"),
+			descpb.IndexDescriptor{
+				Name:                "notifications_created_at_idx",
+				ID:                  2,
+				KeyColumnNames:      []string{"created_at"},
+				KeyColumnDirections: []catenumpb.IndexColumn_Direction{catenumpb.IndexColumn_ASC},
+				KeyColumnIDs:        []descpb.ColumnID{5},
+				KeySuffixColumnIDs:  []descpb.ColumnID{1},
+				Version:             descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		),
+	)
 )
 
 // SpanConfigurationsTableName represents system.span_configurations.
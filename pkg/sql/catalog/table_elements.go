@@ -174,6 +174,8 @@ type Index interface {
 	IsSharded() bool
 	IsNotVisible() bool
 	IsCreatedExplicitly() bool
+	// IsExclusion returns true iff the index backs an exclusion constraint.
+	IsExclusion() bool
 	GetPredicate() string
 	GetType() descpb.IndexDescriptor_Type
 	GetGeoConfig() geoindex.Config
@@ -197,6 +199,12 @@ type Index interface {
 	GetKeyColumnID(columnOrdinal int) descpb.ColumnID
 	GetKeyColumnName(columnOrdinal int) string
 	GetKeyColumnDirection(columnOrdinal int) catenumpb.IndexColumn_Direction
+	// GetExclusionOperator returns the operator with which the key column at
+	// the given ordinal is compared by the exclusion constraint backed by the
+	// index.
+	//
+	// Panics if the index is not an exclusion constraint index.
+	GetExclusionOperator(columnOrdinal int) string
 
 	CollectKeyColumnIDs() TableColSet
 	CollectKeySuffixColumnIDs() TableColSet
@@ -585,6 +593,13 @@ type ForeignKeyConstraint interface {
 
 	// Match returns the type of algorithm used to match composite keys.
 	Match() semenumpb.Match
+
+	// IsDeferrable returns true iff the foreign key was declared DEFERRABLE.
+	IsDeferrable() bool
+
+	// IsInitiallyDeferred returns true iff the foreign key was declared
+	// INITIALLY DEFERRED.
+	IsInitiallyDeferred() bool
 }
 
 // UniqueWithoutIndexConstraint is an interface around a unique constraint
@@ -598,6 +613,13 @@ type UniqueWithoutIndexConstraint interface {
 
 	// ParentTableID returns the ID of the table this constraint applies to.
 	ParentTableID() descpb.ID
+
+	// IsDeferrable returns true iff the constraint was declared DEFERRABLE.
+	IsDeferrable() bool
+
+	// IsInitiallyDeferred returns true iff the constraint was declared
+	// INITIALLY DEFERRED.
+	IsInitiallyDeferred() bool
 }
 
 // PrimaryKeySwap is an interface around a primary key swap mutation.
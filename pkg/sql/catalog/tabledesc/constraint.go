@@ -180,6 +180,17 @@ func (c uniqueWithoutIndexConstraint) GetPredicate() string {
 	return c.desc.Predicate
 }
 
+// IsDeferrable implements the catalog.UniqueWithoutIndexConstraint interface.
+func (c uniqueWithoutIndexConstraint) IsDeferrable() bool {
+	return c.desc.Deferrable
+}
+
+// IsInitiallyDeferred implements the catalog.UniqueWithoutIndexConstraint
+// interface.
+func (c uniqueWithoutIndexConstraint) IsInitiallyDeferred() bool {
+	return c.desc.InitiallyDeferred
+}
+
 // GetConstraintID implements the catalog.Constraint interface.
 func (c uniqueWithoutIndexConstraint) GetConstraintID() descpb.ConstraintID {
 	return c.desc.ConstraintID
@@ -292,6 +303,16 @@ func (c foreignKeyConstraint) Match() semenumpb.Match {
 	return c.desc.Match
 }
 
+// IsDeferrable implements the catalog.ForeignKeyConstraint interface.
+func (c foreignKeyConstraint) IsDeferrable() bool {
+	return c.desc.Deferrable
+}
+
+// IsInitiallyDeferred implements the catalog.ForeignKeyConstraint interface.
+func (c foreignKeyConstraint) IsInitiallyDeferred() bool {
+	return c.desc.InitiallyDeferred
+}
+
 // GetConstraintID implements the catalog.Constraint interface.
 func (c foreignKeyConstraint) GetConstraintID() descpb.ConstraintID {
 	return c.desc.ConstraintID
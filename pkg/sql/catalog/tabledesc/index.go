@@ -118,6 +118,11 @@ func (w index) IsCreatedExplicitly() bool {
 	return w.desc.CreatedExplicitly
 }
 
+// IsExclusion returns true iff the index backs an exclusion constraint.
+func (w index) IsExclusion() bool {
+	return len(w.desc.ExclusionOperators) > 0
+}
+
 // GetPredicate returns the empty string when the index is not partial,
 // otherwise it returns the corresponding expression of the partial index.
 // Columns are referred to in the expression by their name.
@@ -314,6 +319,12 @@ func (w index) GetKeyColumnDirection(columnOrdinal int) catenumpb.IndexColumn_Di
 	return w.desc.KeyColumnDirections[columnOrdinal]
 }
 
+// GetExclusionOperator returns the operator with which the key column at the
+// given ordinal is compared by the exclusion constraint backed by the index.
+func (w index) GetExclusionOperator(columnOrdinal int) string {
+	return w.desc.ExclusionOperators[columnOrdinal]
+}
+
 // NumPrimaryStoredColumns returns the number of columns which the index
 // stores in addition to the columns which are part of the primary key.
 // Returns 0 if the index isn't primary.
@@ -179,6 +179,8 @@ func BuildIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) (string, er
 	// Add the final segment.
 	if idx.Unique {
 		segments = append(segments, "key")
+	} else if len(idx.ExclusionOperators) > 0 {
+		segments = append(segments, "excl")
 	} else {
 		segments = append(segments, "idx")
 	}
@@ -314,6 +316,15 @@ func ForEachExprStringInTableDesc(descI catalog.TableDescriptor, f func(expr *st
 		}
 	}
 
+	// Process trigger conditions.
+	for i := range desc.Triggers {
+		if desc.Triggers[i].WhenExpr != "" {
+			if err := f(&desc.Triggers[i].WhenExpr); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Process all non-index mutations.
 	for _, mut := range desc.Mutations {
 		if c := mut.GetColumn(); c != nil {
@@ -381,11 +392,54 @@ func (desc *wrapper) GetAllReferencedFunctionIDs() (catalog.DescriptorIDSet, err
 			ret.Add(id)
 		}
 	}
+	for i := range desc.Triggers {
+		ids, err := desc.GetAllReferencedFunctionIDsInTrigger(desc.Triggers[i].ID)
+		if err != nil {
+			return catalog.DescriptorIDSet{}, err
+		}
+		ret = ret.Union(ids)
+	}
 	// TODO(chengxiong): add logic to extract references from indexes when UDFs
 	// are allowed in them.
 	return ret.Union(catalog.MakeDescriptorIDSet(desc.DependsOnFunctions...)), nil
 }
 
+// GetAllReferencedFunctionIDsInTrigger implements the TableDescriptor
+// interface.
+func (desc *wrapper) GetAllReferencedFunctionIDsInTrigger(
+	triggerID descpb.TriggerID,
+) (fnIDs catalog.DescriptorIDSet, err error) {
+	trigger := desc.FindTriggerByID(triggerID)
+	if trigger == nil {
+		return catalog.DescriptorIDSet{}, nil
+	}
+	// User-defined functions are not allowed in the WHEN condition, so the
+	// trigger function is the only reference.
+	return catalog.MakeDescriptorIDSet(trigger.FuncID), nil
+}
+
+// FindTriggerByID returns the trigger with the given ID, or nil if no such
+// trigger exists.
+func (desc *wrapper) FindTriggerByID(id descpb.TriggerID) *descpb.TriggerDescriptor {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].ID == id {
+			return &desc.Triggers[i]
+		}
+	}
+	return nil
+}
+
+// FindTriggerByName returns the trigger with the given name, or nil if no
+// such trigger exists.
+func (desc *wrapper) FindTriggerByName(name string) *descpb.TriggerDescriptor {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			return &desc.Triggers[i]
+		}
+	}
+	return nil
+}
+
 // GetAllReferencedFunctionIDsInConstraint implements the TableDescriptor
 // interface.
 func (desc *wrapper) GetAllReferencedFunctionIDsInConstraint(
@@ -621,7 +675,7 @@ func (desc *Mutable) allocateIndexIDs(columnNames map[string]descpb.ColumnID) er
 			}
 			idx.IndexDesc().Name = name
 		}
-		if idx.GetConstraintID() == 0 && idx.IsUnique() {
+		if idx.GetConstraintID() == 0 && (idx.IsUnique() || idx.IsExclusion()) {
 			idx.IndexDesc().ConstraintID = desc.NextConstraintID
 			desc.NextConstraintID++
 		}
@@ -1629,6 +1683,14 @@ func (desc *Mutable) MakeMutationComplete(m descpb.DescriptorMutation) error {
 			// indexes with the new indexes that have been backfilled already.
 			desc.SetPrimaryIndex(t.MaterializedViewRefresh.NewPrimaryIndex)
 			desc.SetPublicNonPrimaryIndexes(t.MaterializedViewRefresh.NewIndexes)
+			// The new indexes contain the result of the view query as of the
+			// refresh timestamp, unless the view was refreshed WITH NO DATA.
+			if desc.IncrementalRefresh != nil {
+				desc.IncrementalRefresh.LastRefresh = hlc.Timestamp{}
+				if t.MaterializedViewRefresh.ShouldBackfill {
+					desc.IncrementalRefresh.LastRefresh = t.MaterializedViewRefresh.AsOf
+				}
+			}
 		}
 
 	case descpb.DescriptorMutation_DROP:
@@ -2248,6 +2310,13 @@ func (desc *wrapper) FindAllReferences() (map[descpb.ID]struct{}, error) {
 	for _, c := range desc.DependedOnBy {
 		refs[c.ID] = struct{}{}
 	}
+
+	for _, id := range desc.InheritsFrom {
+		refs[id] = struct{}{}
+	}
+	for _, id := range desc.InheritedBy {
+		refs[id] = struct{}{}
+	}
 	return refs, nil
 }
 
@@ -117,6 +117,13 @@ func (desc *wrapper) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
 	for _, ref := range desc.GetDependedOnBy() {
 		ids.Add(ref.ID)
 	}
+	// Add inheritance parents and children.
+	for _, id := range desc.GetInheritsFrom() {
+		ids.Add(id)
+	}
+	for _, id := range desc.GetInheritedBy() {
+		ids.Add(id)
+	}
 	// Add sequence dependencies
 	return ids, nil
 }
@@ -198,6 +205,17 @@ func (desc *wrapper) ValidateForwardReferences(
 		}
 	}
 
+	// Check all functions referenced by triggers exists.
+	for i := range desc.Triggers {
+		fnIDs, err := desc.GetAllReferencedFunctionIDsInTrigger(desc.Triggers[i].ID)
+		if err != nil {
+			vea.Report(errors.Wrap(err, "invalid referenced functions IDs in trigger"))
+		}
+		for _, fnID := range fnIDs.Ordered() {
+			vea.Report(desc.validateOutboundFuncRef(fnID, vdg))
+		}
+	}
+
 	// Row-level TTL is not compatible with foreign keys.
 	// This check should be in ValidateSelf but interferes with AllocateIDs.
 	if desc.HasRowLevelTTL() {
@@ -253,6 +271,14 @@ func (desc *wrapper) ValidateBackReferences(
 		vea.Report(desc.validateInboundFK(&desc.InboundFKs[i], vdg))
 	}
 
+	// Check that inheritance parents and children reference this table.
+	for _, id := range desc.InheritsFrom {
+		vea.Report(desc.validateInheritanceRef(id, vdg, true /* parent */))
+	}
+	for _, id := range desc.InheritedBy {
+		vea.Report(desc.validateInheritanceRef(id, vdg, false /* parent */))
+	}
+
 	// Check all functions referenced by constraint exists.
 	for _, cst := range desc.Checks {
 		fnIDs, err := desc.GetAllReferencedFunctionIDsInConstraint(cst.ConstraintID)
@@ -281,6 +307,23 @@ func (desc *wrapper) ValidateBackReferences(
 		}
 	}
 
+	// Check back-references in functions referenced by triggers.
+	for i := range desc.Triggers {
+		trigger := &desc.Triggers[i]
+		fnIDs, err := desc.GetAllReferencedFunctionIDsInTrigger(trigger.ID)
+		if err != nil {
+			vea.Report(errors.Wrap(err, "invalid referenced functions IDs in trigger"))
+		}
+		for _, fnID := range fnIDs.Ordered() {
+			fn, err := vdg.GetFunctionDescriptor(fnID)
+			if err != nil {
+				vea.Report(err)
+				continue
+			}
+			vea.Report(desc.validateOutboundFuncRefBackReferenceForTrigger(fn, trigger.ID))
+		}
+	}
+
 	// For views, check dependent relations.
 	if desc.IsView() {
 		for _, id := range desc.DependsOnTypes {
@@ -394,6 +437,23 @@ func (desc *wrapper) validateOutboundFuncRefBackReferenceForColumn(
 		ref.GetName(), ref.GetID())
 }
 
+func (desc *wrapper) validateOutboundFuncRefBackReferenceForTrigger(
+	ref catalog.FunctionDescriptor, triggerID descpb.TriggerID,
+) error {
+	for _, dep := range ref.GetDependedOnBy() {
+		if dep.ID != desc.GetID() {
+			continue
+		}
+		for _, id := range dep.TriggerIDs {
+			if id == triggerID {
+				return nil
+			}
+		}
+	}
+	return errors.AssertionFailedf("depends-on function %q (%d) has no corresponding depended-on-by back reference",
+		ref.GetName(), ref.GetID())
+}
+
 func (desc *wrapper) validateInboundFunctionRef(
 	by descpb.TableDescriptor_Reference, vdg catalog.ValidationDescGetter,
 ) error {
@@ -485,6 +545,9 @@ func (desc *wrapper) validateOutboundFK(
 		return errors.AssertionFailedf("referenced table %q (%d) is dropped",
 			referencedTable.GetName(), referencedTable.GetID())
 	}
+	if fk.InitiallyDeferred && !fk.Deferrable {
+		return errors.AssertionFailedf("foreign key %q is initially deferred but not deferrable", fk.Name)
+	}
 	return nil
 }
 
@@ -528,6 +591,36 @@ func (desc *wrapper) validateInboundFK(
 		backref.Name, desc.Name, originTable.GetName())
 }
 
+// validateInheritanceRef checks that the inheritance parent (or child) with
+// the given ID exists and has a matching reference back to this table.
+func (desc *wrapper) validateInheritanceRef(
+	id descpb.ID, vdg catalog.ValidationDescGetter, parent bool,
+) error {
+	kind := "child"
+	if parent {
+		kind = "parent"
+	}
+	other, err := vdg.GetTableDescriptor(id)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "invalid inheritance %s reference", kind)
+	}
+	if other.Dropped() {
+		// Dropped tables have their references removed lazily.
+		return nil
+	}
+	refs := other.GetInheritedBy()
+	if !parent {
+		refs = other.GetInheritsFrom()
+	}
+	for _, ref := range refs {
+		if ref == desc.ID {
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("inheritance %s %q (%d) has no corresponding reference to %q",
+		kind, other.GetName(), other.GetID(), desc.Name)
+}
+
 func (desc *wrapper) matchingPartitionbyAll(indexI catalog.Index) bool {
 	primaryIndexPartitioning := desc.PrimaryIndex.KeyColumnIDs[:desc.PrimaryIndex.Partitioning.NumColumns]
 	indexPartitioning := indexI.IndexDesc().KeyColumnIDs[:indexI.PartitioningColumnCount()]
@@ -667,6 +760,10 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 				"has depends-on-types references despite not being a view"))
 		}
 	}
+	if desc.IncrementalRefresh != nil && !desc.MaterializedView() {
+		vea.Report(errors.AssertionFailedf(
+			"has incremental refresh state despite not being a materialized view"))
+	}
 
 	desc.validateAutoStatsSettings(vea)
 
@@ -774,6 +871,7 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 			desc.validateUniqueWithoutIndexConstraints(columnsByID),
 			desc.validateTableIndexes(columnsByID),
 			desc.validatePartitioning(),
+			desc.validateTriggers(columnsByID),
 		}
 		hasErrs := false
 		for _, err := range newErrs {
@@ -1229,6 +1327,42 @@ func (desc *wrapper) validateCheckConstraints(
 	return nil
 }
 
+// validateTriggers validates that triggers are well formed. Checks include
+// validating the trigger IDs and names, and the column IDs of UPDATE OF
+// events.
+func (desc *wrapper) validateTriggers(columnsByID map[descpb.ColumnID]catalog.Column) error {
+	ids := make(map[descpb.TriggerID]struct{}, len(desc.Triggers))
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		trigger := &desc.Triggers[i]
+		if trigger.ID == 0 || trigger.ID >= desc.NextTriggerID {
+			return errors.AssertionFailedf("trigger %q has invalid ID %d", trigger.Name, trigger.ID)
+		}
+		if _, ok := ids[trigger.ID]; ok {
+			return errors.AssertionFailedf("duplicate trigger ID %d", trigger.ID)
+		}
+		ids[trigger.ID] = struct{}{}
+		if _, ok := names[trigger.Name]; ok {
+			return errors.AssertionFailedf("duplicate trigger name %q", trigger.Name)
+		}
+		names[trigger.Name] = struct{}{}
+		if len(trigger.Events) == 0 {
+			return errors.AssertionFailedf("trigger %q has no events", trigger.Name)
+		}
+		for _, ev := range trigger.Events {
+			for _, colID := range ev.ColumnIDs {
+				if _, ok := columnsByID[colID]; !ok {
+					return errors.Newf("trigger %q contains unknown column \"%d\"", trigger.Name, colID)
+				}
+			}
+		}
+		if trigger.FuncID == descpb.InvalidID {
+			return errors.AssertionFailedf("trigger %q has invalid function ID", trigger.Name)
+		}
+	}
+	return nil
+}
+
 // validateUniqueWithoutIndexConstraints validates that unique without index
 // constraints are well formed. Checks include validating the column IDs and
 // column names.
@@ -1266,6 +1400,12 @@ func (desc *wrapper) validateUniqueWithoutIndexConstraints(
 			seen.Add(int(colID))
 		}
 
+		if c.IsInitiallyDeferred() && !c.IsDeferrable() {
+			return errors.Newf(
+				"unique without index constraint %q is initially deferred but not deferrable", c.GetName(),
+			)
+		}
+
 		if c.IsPartial() {
 			expr, err := parser.ParseExpr(c.GetPredicate())
 			if err != nil {
@@ -1438,6 +1578,11 @@ func (desc *wrapper) validateTableIndexes(columnsByID map[descpb.ColumnID]catalo
 					idx.GetName(), idx.GetPredicate())
 			}
 		}
+		if idx.IsExclusion() {
+			if err := validateExclusionOperators(idx); err != nil {
+				return err
+			}
+		}
 
 		if !idx.IsMutation() {
 			if idx.IndexDesc().UseDeletePreservingEncoding {
@@ -1574,6 +1719,34 @@ func (desc *wrapper) validateTableIndexes(columnsByID map[descpb.ColumnID]catalo
 // column. This is because the sharded index is based on a hidden computed shard column
 // under the hood and we don't support transitively computed columns (computed column A
 // based on another computed column B).
+// validateExclusionOperators validates the operators of an index backing an
+// exclusion constraint.
+func validateExclusionOperators(idx catalog.Index) error {
+	if idx.Primary() {
+		return errors.Newf("primary index %q cannot back an exclusion constraint", idx.GetName())
+	}
+	if idx.IsUnique() {
+		return errors.Newf("unique index %q cannot back an exclusion constraint", idx.GetName())
+	}
+	if n := len(idx.IndexDesc().ExclusionOperators); n != idx.NumKeyColumns() {
+		return errors.Newf("mismatched column IDs (%d) and exclusion operators (%d)",
+			idx.NumKeyColumns(), n)
+	}
+	for i, op := range idx.IndexDesc().ExclusionOperators {
+		switch op {
+		case "=":
+		case "&&":
+			if i != idx.NumKeyColumns()-1 {
+				return errors.Newf("exclusion constraint %q can only use && on its last column",
+					idx.GetName())
+			}
+		default:
+			return errors.Newf("exclusion constraint %q has invalid operator %q", idx.GetName(), op)
+		}
+	}
+	return nil
+}
+
 func (desc *wrapper) ensureShardedIndexNotComputed(index *descpb.IndexDescriptor) error {
 	for _, colName := range index.Sharded.ColumnNames {
 		col, err := catalog.MustFindColumnByName(desc, colName)
@@ -84,6 +84,12 @@ func ensureTypeIsHydratedRecursive(
 				return err
 			}
 		}
+	case types.MultirangeFamily:
+		if e := t.MultirangeContents(); e.UserDefined() {
+			if err := ensureTypeIsHydratedRecursive(ctx, e, maybeName, maybeDesc, res); err != nil {
+				return err
+			}
+		}
 	}
 	// Ensure that we have the descriptor for a user-defined type.
 	// Note that non-user-defined types may or may not have descriptors
@@ -149,4 +155,16 @@ func ensureTypeMetadataIsHydrated(
 			tm.EnumData.IsMemberReadOnly[i] = e.IsMemberReadOnly(i)
 		}
 	}
+	if d := maybeDesc.AsDomainTypeDescriptor(); d != nil {
+		n := d.NumDomainChecks()
+		tm.DomainData = &types.DomainMetadata{
+			NotNull:     d.DomainNotNull(),
+			DefaultExpr: d.DomainDefaultExpr(),
+			Checks:      make([]types.DomainCheck, n),
+		}
+		for i := 0; i < n; i++ {
+			c := d.GetDomainCheck(i)
+			tm.DomainData.Checks[i] = types.DomainCheck{Name: c.Name, Expr: c.Expr}
+		}
+	}
 }
@@ -326,6 +326,16 @@ func (v *tableImplicitRecordType) AsCompositeTypeDescriptor() catalog.CompositeT
 	return nil
 }
 
+// AsDomainTypeDescriptor implements the catalog.TypeDescriptor interface.
+func (v *tableImplicitRecordType) AsDomainTypeDescriptor() catalog.DomainTypeDescriptor {
+	return nil
+}
+
+// AsRangeTypeDescriptor implements the catalog.TypeDescriptor interface.
+func (v *tableImplicitRecordType) AsRangeTypeDescriptor() catalog.RangeTypeDescriptor {
+	return nil
+}
+
 // AsTableImplicitRecordTypeDescriptor implements the catalog.TypeDescriptor
 // interface.
 func (v *tableImplicitRecordType) AsTableImplicitRecordTypeDescriptor() catalog.TableImplicitRecordTypeDescriptor {
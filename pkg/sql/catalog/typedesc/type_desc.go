@@ -146,7 +146,7 @@ func UserDefinedTypeOIDToID(oid oid.Oid) descpb.ID {
 
 // GetUserDefinedTypeDescID gets the type descriptor ID from a user defined type.
 func GetUserDefinedTypeDescID(t *types.T) descpb.ID {
-	return UserDefinedTypeOIDToID(t.Oid())
+	return UserDefinedTypeOIDToID(t.UserDefinedOID())
 }
 
 // GetUserDefinedArrayTypeDescID gets the ID of the array type descriptor from a user
@@ -508,6 +508,27 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		if desc.Composite == nil {
 			vea.Report(errors.AssertionFailedf("COMPOSITE type desc has nil composite type"))
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if desc.Domain == nil || desc.Domain.BaseType == nil {
+			vea.Report(errors.AssertionFailedf("DOMAIN type desc has nil base type"))
+		}
+		if desc.ArrayTypeID != descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("DOMAIN type desc has array type ID %d", desc.ArrayTypeID))
+		}
+	case descpb.TypeDescriptor_RANGE:
+		if desc.Range == nil || desc.Range.Subtype == nil {
+			vea.Report(errors.AssertionFailedf("RANGE type desc has nil subtype"))
+		} else if desc.Range.MultirangeTypeID == descpb.InvalidID {
+			vea.Report(errors.AssertionFailedf("RANGE type desc has no multirange type ID"))
+		}
+	case descpb.TypeDescriptor_MULTIRANGE:
+		if desc.Range == nil || desc.Range.Subtype == nil || desc.Range.RangeType == nil {
+			vea.Report(errors.AssertionFailedf("MULTIRANGE type desc has nil range type"))
+		} else if desc.Range.RangeType.Family() != types.RangeFamily ||
+			!desc.Range.RangeType.RangeSubtype().Identical(desc.Range.Subtype) {
+			vea.Report(errors.AssertionFailedf("MULTIRANGE type desc has invalid range type %s",
+				desc.Range.RangeType.DebugString()))
+		}
 	case descpb.TypeDescriptor_TABLE_IMPLICIT_RECORD_TYPE:
 		vea.Report(errors.AssertionFailedf("invalid type descriptor: kind %s should never be serialized or validated", desc.Kind.String()))
 	default:
@@ -567,6 +588,9 @@ func (desc *immutable) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
 		ids.Add(desc.GetParentSchemaID())
 	}
 	desc.GetIDClosure().ForEach(ids.Add)
+	if r := desc.AsRangeTypeDescriptor(); r != nil && r.GetMultirangeTypeID() != descpb.InvalidID {
+		ids.Add(r.GetMultirangeTypeID())
+	}
 	return ids, nil
 }
 
@@ -625,6 +649,37 @@ func (desc *immutable) ValidateForwardReferences(
 			}
 		}
 	}
+
+	if d := desc.AsDomainTypeDescriptor(); d != nil && d.DomainBaseType().UserDefined() {
+		// Domains over user-defined types are not supported.
+		vea.Report(errors.AssertionFailedf("invalid reference to user-defined type %q from domain %q",
+			d.DomainBaseType().String(), desc.GetName(),
+		))
+	}
+
+	if r := desc.AsRangeTypeDescriptor(); r != nil {
+		if r.RangeSubtype().UserDefined() {
+			// Ranges over user-defined types are not supported.
+			vea.Report(errors.AssertionFailedf("invalid reference to user-defined type %q from range type %q",
+				r.RangeSubtype().String(), desc.GetName(),
+			))
+		}
+		// A range type and its multirange type refer to each other.
+		otherID := r.GetMultirangeTypeID()
+		if otherID == descpb.InvalidID {
+			otherID = r.GetRangeTypeID()
+		}
+		if typ, err := vdg.GetTypeDescriptor(otherID); err != nil {
+			vea.Report(errors.Wrapf(err, "range or multirange type %d does not exist", otherID))
+		} else if typ.Dropped() {
+			vea.Report(errors.AssertionFailedf("range or multirange type %q (%d) is dropped",
+				typ.GetName(), typ.GetID()))
+		} else if other := typ.AsRangeTypeDescriptor(); other == nil ||
+			(other.GetMultirangeTypeID() != desc.GetID() && other.GetRangeTypeID() != desc.GetID()) {
+			vea.Report(errors.AssertionFailedf("type %q (%d) is not the range or multirange type of %q",
+				typ.GetName(), typ.GetID(), desc.GetName()))
+		}
+	}
 }
 
 // ValidateBackReferences implements the catalog.Descriptor interface.
@@ -768,6 +823,20 @@ func (desc *immutable) AsTypesT() *types.T {
 			contents,
 			labels,
 		)
+	case descpb.TypeDescriptor_DOMAIN:
+		return types.MakeDomain(desc.Domain.BaseType, catid.TypeIDToOID(desc.GetID()))
+	case descpb.TypeDescriptor_RANGE:
+		return types.MakeRange(
+			catid.TypeIDToOID(desc.GetID()),
+			catid.TypeIDToOID(desc.ArrayTypeID),
+			desc.Range.Subtype,
+		)
+	case descpb.TypeDescriptor_MULTIRANGE:
+		return types.MakeMultirange(
+			catid.TypeIDToOID(desc.GetID()),
+			catid.TypeIDToOID(desc.ArrayTypeID),
+			desc.Range.RangeType,
+		)
 	}
 	panic(errors.AssertionFailedf("unsupported descriptor kind %s", desc.Kind.String()))
 }
@@ -889,6 +958,11 @@ func (desc *immutable) ForEachUDTDependentForHydration(fn func(t *types.T) error
 			return iterutil.Map(err)
 		}
 	}
+	if desc.Range != nil && desc.Range.RangeType != nil {
+		if err := fn(desc.Range.RangeType); err != nil {
+			return iterutil.Map(err)
+		}
+	}
 	if desc.Composite == nil {
 		return nil
 	}
@@ -916,6 +990,14 @@ func (desc *immutable) GetIDClosure() (ret catalog.DescriptorIDSet) {
 		for _, e := range desc.Composite.Elements {
 			GetTypeDescriptorClosure(e.ElementType).ForEach(ret.Add)
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		// Domains don't have array types, and their base types are never
+		// user-defined.
+	case descpb.TypeDescriptor_MULTIRANGE:
+		// A multirange type refers to its range type, whose subtype is never
+		// user-defined.
+		ret.Add(desc.ArrayTypeID)
+		GetTypeDescriptorClosure(desc.Range.RangeType).ForEach(ret.Add)
 	default:
 		// Otherwise, take the array type ID.
 		ret.Add(desc.ArrayTypeID)
@@ -936,6 +1018,11 @@ func GetTypeDescriptorClosure(typ *types.T) (ret catalog.DescriptorIDSet) {
 	}
 	// Collect the type's descriptor ID.
 	ret.Add(GetUserDefinedTypeDescID(typ))
+	if typ.IsDomain() {
+		// Domains don't have array types, and their base types are never
+		// user-defined.
+		return ret
+	}
 	switch typ.Family() {
 	case types.ArrayFamily:
 		// If we have an array type, then collect all types in the contents.
@@ -945,6 +1032,11 @@ func GetTypeDescriptorClosure(typ *types.T) (ret catalog.DescriptorIDSet) {
 		for _, elt := range typ.TupleContents() {
 			GetTypeDescriptorClosure(elt).ForEach(ret.Add)
 		}
+	case types.MultirangeFamily:
+		// If we have a multirange type, take the array type ID and collect the
+		// range type.
+		ret.Add(GetUserDefinedArrayTypeDescID(typ))
+		GetTypeDescriptorClosure(typ.MultirangeContents()).ForEach(ret.Add)
 	default:
 		// Otherwise, take the array type ID.
 		ret.Add(GetUserDefinedArrayTypeDescID(typ))
@@ -985,6 +1077,23 @@ func (desc *immutable) AsCompositeTypeDescriptor() catalog.CompositeTypeDescript
 	return nil
 }
 
+// AsDomainTypeDescriptor implements the catalog.TypeDescriptor interface.
+func (desc *immutable) AsDomainTypeDescriptor() catalog.DomainTypeDescriptor {
+	if desc.Kind == descpb.TypeDescriptor_DOMAIN {
+		return desc
+	}
+	return nil
+}
+
+// AsRangeTypeDescriptor implements the catalog.TypeDescriptor interface.
+func (desc *immutable) AsRangeTypeDescriptor() catalog.RangeTypeDescriptor {
+	if desc.Kind == descpb.TypeDescriptor_RANGE ||
+		desc.Kind == descpb.TypeDescriptor_MULTIRANGE {
+		return desc
+	}
+	return nil
+}
+
 // AsTableImplicitRecordTypeDescriptor implements the catalog.TypeDescriptor
 // interface.
 func (desc *immutable) AsTableImplicitRecordTypeDescriptor() catalog.TableImplicitRecordTypeDescriptor {
@@ -1011,6 +1120,49 @@ func (desc *immutable) GetElementType(ordinal int) *types.T {
 	return desc.Composite.Elements[ordinal].ElementType
 }
 
+// DomainBaseType implements the catalog.DomainTypeDescriptor interface.
+func (desc *immutable) DomainBaseType() *types.T {
+	return desc.Domain.BaseType
+}
+
+// DomainNotNull implements the catalog.DomainTypeDescriptor interface.
+func (desc *immutable) DomainNotNull() bool {
+	return desc.Domain.NotNull
+}
+
+// DomainDefaultExpr implements the catalog.DomainTypeDescriptor interface.
+func (desc *immutable) DomainDefaultExpr() *string {
+	return desc.Domain.DefaultExpr
+}
+
+// NumDomainChecks implements the catalog.DomainTypeDescriptor interface.
+func (desc *immutable) NumDomainChecks() int {
+	return len(desc.Domain.Checks)
+}
+
+// GetDomainCheck implements the catalog.DomainTypeDescriptor interface.
+func (desc *immutable) GetDomainCheck(ordinal int) descpb.TypeDescriptor_Domain_Check {
+	return desc.Domain.Checks[ordinal]
+}
+
+// RangeSubtype implements the catalog.RangeTypeDescriptor interface.
+func (desc *immutable) RangeSubtype() *types.T {
+	return desc.Range.Subtype
+}
+
+// GetMultirangeTypeID implements the catalog.RangeTypeDescriptor interface.
+func (desc *immutable) GetMultirangeTypeID() descpb.ID {
+	return desc.Range.MultirangeTypeID
+}
+
+// GetRangeTypeID implements the catalog.RangeTypeDescriptor interface.
+func (desc *immutable) GetRangeTypeID() descpb.ID {
+	if desc.Range.RangeType == nil {
+		return descpb.InvalidID
+	}
+	return GetUserDefinedTypeDescID(desc.Range.RangeType)
+}
+
 // ForEachRegionInSuperRegion implements the catalog.RegionEnumTypeDescriptor
 // interface.
 func (desc *immutable) ForEachRegionInSuperRegion(
@@ -868,6 +868,24 @@ func checkMutationInput(
 		}
 		colIdx++
 	}
+
+	// The remaining check columns belong to the check constraints synthesized
+	// for columns of user-defined types, such as the constraints of domains.
+	for ord, ok := checkOrds.Next(len(checks)); ok; ord, ok = checkOrds.Next(ord + 1) {
+		if res, err := tree.GetBool(checkVals[colIdx]); err != nil {
+			return err
+		} else if !res && checkVals[colIdx] != tree.DNull {
+			synthesizedChecks, err := synthesizeCheckConstraints(tabDesc)
+			if err != nil {
+				return err
+			}
+			check := &synthesizedChecks[ord-len(checks)]
+			return pgerror.WithConstraintName(pgerror.Newf(
+				pgcode.CheckViolation, "failed to satisfy CHECK constraint (%s)", check.Constraint,
+			), check.name)
+		}
+		colIdx++
+	}
 	return nil
 }
 
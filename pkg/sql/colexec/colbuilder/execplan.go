@@ -201,6 +201,9 @@ func supportedNatively(core *execinfrapb.ProcessorCoreUnion) error {
 	case core.Ordinality != nil:
 		return nil
 
+	case core.Expand != nil:
+		return nil
+
 	case core.HashJoiner != nil:
 		if !core.HashJoiner.OnExpr.Empty() && core.HashJoiner.Type != descpb.InnerJoin {
 			return errNonInnerHashJoinWithOnExpr
@@ -301,6 +304,7 @@ func canWrap(mode sessiondatapb.VectorizeExecMode, core *execinfrapb.ProcessorCo
 		// (#55408), so we fallback to the row-by-row engine.
 		return errChangeFrontierWrap
 	case core.Ordinality != nil:
+	case core.Expand != nil:
 	case core.BulkRowWriter != nil:
 	case core.InvertedFilterer != nil:
 	case core.InvertedJoiner != nil:
@@ -1178,6 +1182,31 @@ func NewColOperator(
 			)
 			result.ColumnTypes = appendOneType(spec.Input[0].ColumnTypes, types.Int)
 
+		case core.Expand != nil:
+			if err := checkNumIn(inputs, 1); err != nil {
+				return r, err
+			}
+			inputTypes := spec.Input[0].ColumnTypes
+			groupingSets := make([][]uint32, len(core.Expand.GroupingSets))
+			hasEmptySet := false
+			for i := range core.Expand.GroupingSets {
+				groupingSets[i] = core.Expand.GroupingSets[i].Cols
+				hasEmptySet = hasEmptySet || len(groupingSets[i]) == 0
+			}
+			result.ColumnTypes = make([]*types.T, 0, len(inputTypes)+len(core.Expand.GroupingCols)+2)
+			result.ColumnTypes = append(result.ColumnTypes, inputTypes...)
+			for _, col := range core.Expand.GroupingCols {
+				result.ColumnTypes = append(result.ColumnTypes, inputTypes[col])
+			}
+			result.ColumnTypes = append(result.ColumnTypes, types.Int)
+			if hasEmptySet {
+				result.ColumnTypes = append(result.ColumnTypes, types.Bool)
+			}
+			result.Root = colexecbase.NewExpandOp(
+				getStreamingAllocator(ctx, args), inputs[0].Root, len(inputTypes),
+				result.ColumnTypes, core.Expand.GroupingCols, groupingSets,
+			)
+
 		case core.HashJoiner != nil:
 			if err := checkNumIn(inputs, 2); err != nil {
 				return r, err
@@ -0,0 +1,177 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexecbase
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// expandOp is an operator that emits every input tuple once per grouping set,
+// in order to compute aggregations over multiple grouping sets (GROUPING SETS,
+// ROLLUP and CUBE). See execinfrapb.ExpandSpec for the format of its output.
+//
+// Every input batch is emitted once for each grouping set, with the columns
+// appended after the input columns set according to the grouping set.
+type expandOp struct {
+	colexecop.OneInputHelper
+
+	allocator    *colmem.Allocator
+	outputTypes  []*types.T
+	numInputCols int
+	groupingCols []uint32
+	// inSet[i][j] is true if the j-th grouping column is part of the i-th
+	// grouping set.
+	inSet [][]bool
+	// emptySets are the ordinals of the empty grouping sets. If there are any,
+	// the output contains a column which is true for the tuples that
+	// correspond to input tuples.
+	emptySets []int
+
+	// batch is the current input batch, and nextSet is the ordinal of the next
+	// grouping set to emit it for.
+	batch   coldata.Batch
+	nextSet int
+	// sawInputTuple is true once a non-empty batch has been read from the
+	// input.
+	sawInputTuple bool
+	done          bool
+}
+
+var _ colexecop.Operator = &expandOp{}
+
+// NewExpandOp returns a new expand operator. groupingCols are the ordinals of
+// the input columns that are part of at least one grouping set, and each of
+// the groupingSets contains indexes into groupingCols. outputTypes are the
+// input types followed by the types of the columns appended by the operator.
+func NewExpandOp(
+	allocator *colmem.Allocator,
+	input colexecop.Operator,
+	numInputCols int,
+	outputTypes []*types.T,
+	groupingCols []uint32,
+	groupingSets [][]uint32,
+) colexecop.Operator {
+	input = colexecutils.NewBatchSchemaSubsetEnforcer(
+		allocator, input, outputTypes, numInputCols, len(outputTypes),
+	)
+	e := &expandOp{
+		OneInputHelper: colexecop.MakeOneInputHelper(input),
+		allocator:      allocator,
+		outputTypes:    outputTypes,
+		numInputCols:   numInputCols,
+		groupingCols:   groupingCols,
+		inSet:          make([][]bool, len(groupingSets)),
+		nextSet:        len(groupingSets),
+	}
+	for i, set := range groupingSets {
+		e.inSet[i] = make([]bool, len(groupingCols))
+		for _, j := range set {
+			e.inSet[i][j] = true
+		}
+		if len(set) == 0 {
+			e.emptySets = append(e.emptySets, i)
+		}
+	}
+	return e
+}
+
+// Next implements the colexecop.Operator interface.
+func (e *expandOp) Next() coldata.Batch {
+	if e.done {
+		return coldata.ZeroBatch
+	}
+	if e.nextSet == len(e.inSet) {
+		e.batch = e.Input.Next()
+		e.nextSet = 0
+		if e.batch.Length() == 0 {
+			e.done = true
+			if len(e.emptySets) > 0 && !e.sawInputTuple {
+				// The input is empty, so emit a tuple for each empty grouping
+				// set.
+				return e.emptyInputBatch()
+			}
+			return coldata.ZeroBatch
+		}
+		e.sawInputTuple = true
+	}
+	set := e.nextSet
+	e.nextSet++
+	e.setExpandedCols(set)
+	return e.batch
+}
+
+// setExpandedCols sets the columns appended to the current input batch for the
+// given grouping set.
+func (e *expandOp) setExpandedCols(set int) {
+	n := e.batch.Length()
+	sel := e.batch.Selection()
+	// The appended columns are set for all the tuples up to the last selected
+	// one, so that the values are at the same positions as in the input
+	// columns.
+	end := n
+	if sel != nil {
+		end = 0
+		for _, i := range sel[:n] {
+			if i >= end {
+				end = i + 1
+			}
+		}
+	}
+	vecs := e.batch.ColVecs()
+	e.allocator.PerformOperation(vecs[e.numInputCols:], func() {
+		for j, col := range e.groupingCols {
+			out := vecs[e.numInputCols+j]
+			if e.inSet[set][j] {
+				out.Copy(coldata.SliceArgs{Src: vecs[col], SrcEndIdx: end})
+			} else {
+				out.Nulls().SetNullRange(0, end)
+			}
+		}
+		ids := vecs[e.numInputCols+len(e.groupingCols)].Int64()[:end]
+		for i := range ids {
+			ids[i] = int64(set)
+		}
+		if len(e.emptySets) > 0 {
+			inputTuple := vecs[len(vecs)-1].Bool()[:end]
+			for i := range inputTuple {
+				inputTuple[i] = true
+			}
+		}
+	})
+}
+
+// emptyInputBatch returns the batch emitted when the input is empty, which
+// contains a tuple for each empty grouping set in which all the columns except
+// the grouping set ordinal are NULL, and the input tuple column is false.
+func (e *expandOp) emptyInputBatch() coldata.Batch {
+	n := len(e.emptySets)
+	b := e.allocator.NewMemBatchWithFixedCapacity(e.outputTypes, n)
+	e.allocator.PerformOperation(b.ColVecs(), func() {
+		idIdx := e.numInputCols + len(e.groupingCols)
+		for i := 0; i < idIdx; i++ {
+			b.ColVec(i).Nulls().SetNullRange(0, n)
+		}
+		ids := b.ColVec(idIdx).Int64()
+		for i, set := range e.emptySets {
+			ids[i] = int64(set)
+		}
+		inputTuple := b.ColVec(len(e.outputTypes) - 1).Bool()
+		for i := 0; i < n; i++ {
+			inputTuple[i] = false
+		}
+	})
+	b.SetLength(n)
+	return b
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexecbase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecargs"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexectestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestExpand(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := eval.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Mon:     evalCtx.TestingMon,
+		Cfg: &execinfra.ServerConfig{
+			Settings: st,
+		},
+	}
+	rollup := execinfrapb.ExpandSpec{
+		GroupingCols: []uint32{0, 1},
+		GroupingSets: []execinfrapb.ExpandSpec_GroupingSet{
+			{Cols: []uint32{0, 1}}, {Cols: []uint32{0}}, {},
+		},
+	}
+	noEmptySet := execinfrapb.ExpandSpec{
+		GroupingCols: []uint32{1, 0},
+		GroupingSets: []execinfrapb.ExpandSpec_GroupingSet{
+			{Cols: []uint32{0}}, {Cols: []uint32{1}}, {Cols: []uint32{0}},
+		},
+	}
+	tcs := []struct {
+		spec       execinfrapb.ExpandSpec
+		tuples     colexectestutils.Tuples
+		expected   colexectestutils.Tuples
+		inputTypes []*types.T
+	}{
+		{
+			spec:   rollup,
+			tuples: colexectestutils.Tuples{{1, "a", 10}, {2, "b", 20}},
+			expected: colexectestutils.Tuples{
+				{1, "a", 10, 1, "a", 0, true},
+				{1, "a", 10, 1, nil, 1, true},
+				{1, "a", 10, nil, nil, 2, true},
+				{2, "b", 20, 2, "b", 0, true},
+				{2, "b", 20, 2, nil, 1, true},
+				{2, "b", 20, nil, nil, 2, true},
+			},
+			inputTypes: []*types.T{types.Int, types.String, types.Int},
+		},
+		{
+			spec:   noEmptySet,
+			tuples: colexectestutils.Tuples{{1, nil}, {2, 3}},
+			expected: colexectestutils.Tuples{
+				{1, nil, nil, nil, 0},
+				{1, nil, nil, 1, 1},
+				{1, nil, nil, nil, 2},
+				{2, 3, 3, nil, 0},
+				{2, 3, nil, 2, 1},
+				{2, 3, 3, nil, 2},
+			},
+			inputTypes: []*types.T{types.Int, types.Int},
+		},
+	}
+
+	for _, tc := range tcs {
+		colexectestutils.RunTestsWithTyps(t, testAllocator, []colexectestutils.Tuples{tc.tuples}, [][]*types.T{tc.inputTypes}, tc.expected, colexectestutils.UnorderedVerifier,
+			func(input []colexecop.Operator) (colexecop.Operator, error) {
+				return createTestExpandOperator(ctx, flowCtx, input[0], tc.inputTypes, tc.spec)
+			})
+	}
+
+	// If the input is empty, a tuple is emitted for each empty grouping set,
+	// and nothing is emitted if there are none. The all nulls injection doesn't
+	// apply since there are no input tuples.
+	emptyInputSpec := execinfrapb.ExpandSpec{
+		GroupingCols: []uint32{0},
+		GroupingSets: []execinfrapb.ExpandSpec_GroupingSet{{}, {Cols: []uint32{0}}, {}},
+	}
+	colexectestutils.RunTestsWithoutAllNullsInjection(t, testAllocator, []colexectestutils.Tuples{{}}, [][]*types.T{{types.Int}},
+		colexectestutils.Tuples{{nil, nil, 0, false}, {nil, nil, 2, false}}, colexectestutils.UnorderedVerifier,
+		func(input []colexecop.Operator) (colexecop.Operator, error) {
+			return createTestExpandOperator(ctx, flowCtx, input[0], []*types.T{types.Int}, emptyInputSpec)
+		})
+	colexectestutils.RunTestsWithoutAllNullsInjection(t, testAllocator, []colexectestutils.Tuples{{}}, [][]*types.T{{types.Int, types.Int}},
+		colexectestutils.Tuples{}, colexectestutils.UnorderedVerifier,
+		func(input []colexecop.Operator) (colexecop.Operator, error) {
+			return createTestExpandOperator(ctx, flowCtx, input[0], []*types.T{types.Int, types.Int}, noEmptySet)
+		})
+}
+
+func createTestExpandOperator(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	input colexecop.Operator,
+	inputTypes []*types.T,
+	expandSpec execinfrapb.ExpandSpec,
+) (colexecop.Operator, error) {
+	resultTypes := append([]*types.T(nil), inputTypes...)
+	for _, col := range expandSpec.GroupingCols {
+		resultTypes = append(resultTypes, inputTypes[col])
+	}
+	resultTypes = append(resultTypes, types.Int)
+	for _, set := range expandSpec.GroupingSets {
+		if len(set.Cols) == 0 {
+			resultTypes = append(resultTypes, types.Bool)
+			break
+		}
+	}
+	spec := &execinfrapb.ProcessorSpec{
+		Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+		Core: execinfrapb.ProcessorCoreUnion{
+			Expand: &expandSpec,
+		},
+		ResultTypes: resultTypes,
+	}
+	args := &colexecargs.NewColOperatorArgs{
+		Spec:                spec,
+		Inputs:              []colexecargs.OpWithMetaInfo{{Root: input}},
+		StreamingMemAccount: testMemAcc,
+	}
+	result, err := colexecargs.TestNewColOperator(ctx, flowCtx, args)
+	if err != nil {
+		return nil, err
+	}
+	return result.Root, nil
+}
@@ -208,6 +208,7 @@ func NewColBatchDirectScan(
 		spec.LockingStrength,
 		spec.LockingWaitPolicy,
 		flowCtx.EvalCtx.SessionData().LockTimeout,
+		flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 		kvFetcherMemAcc,
 		flowCtx.EvalCtx.TestingKnobs.ForceProductionValues,
 	)
@@ -322,6 +322,7 @@ func NewColBatchScan(
 		spec.LockingStrength,
 		spec.LockingWaitPolicy,
 		flowCtx.EvalCtx.SessionData().LockTimeout,
+		flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 		kvFetcherMemAcc,
 		flowCtx.EvalCtx.TestingKnobs.ForceProductionValues,
 	)
@@ -554,6 +554,7 @@ func NewColIndexJoin(
 			spec.LockingStrength,
 			spec.LockingWaitPolicy,
 			flowCtx.EvalCtx.SessionData().LockTimeout,
+			flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 			kvFetcherMemAcc,
 			flowCtx.EvalCtx.TestingKnobs.ForceProductionValues,
 		)
@@ -22,9 +22,11 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/multitenant"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/multitenantcpu"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -44,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxrecommendations"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -1054,6 +1057,7 @@ func (s *Server) newConnExecutor(
 		portals:   make(map[string]PreparedPortal),
 	}
 	ex.extraTxnState.prepStmtsNamespaceMemAcc = ex.sessionMon.MakeBoundAccount()
+	ex.extraTxnState.sqlCursors.mon = ex.sessionMon
 	dsdp := catsessiondata.NewDescriptorSessionDataStackProvider(sdMutIterator.sds)
 	ex.extraTxnState.descCollection = s.cfg.CollectionFactory.NewCollection(
 		ctx, descs.WithDescriptorSessionDataProvider(dsdp), descs.WithMonitor(ex.sessionMon),
@@ -1192,7 +1196,7 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 			ctx, &ex.extraTxnState.prepStmtsNamespaceMemAcc,
 		)
 		ex.extraTxnState.prepStmtsNamespaceMemAcc.Close(ctx)
-		if err := ex.extraTxnState.sqlCursors.closeAll(false /* errorOnWithHold */); err != nil {
+		if err := ex.extraTxnState.sqlCursors.closeAll(ctx, closeAllCursors); err != nil {
 			log.Warningf(ctx, "error closing cursors: %v", err)
 		}
 	}
@@ -1208,6 +1212,11 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.eventLog = nil
 	}
 
+	if ex.notifications != nil {
+		ex.notifications.Close()
+		ex.notifications = nil
+	}
+
 	// Stop idle timer if the connExecutor is closed to ensure cancel session
 	// is not called.
 	ex.mu.IdleInSessionTimeout.Stop()
@@ -1382,8 +1391,9 @@ type connExecutor struct {
 
 		// sqlCursors contains the list of SQL CURSORs the session currently has
 		// access to.
-		// Cursors are bound to an explicit transaction and they're all destroyed
-		// once the transaction finishes.
+		// Cursors are bound to a transaction and they're destroyed once the
+		// transaction finishes, except for cursors declared WITH HOLD, which
+		// outlive the transaction if it commits.
 		sqlCursors cursorMap
 
 		// shouldExecuteOnTxnFinish indicates that ex.onTxnFinish will be called
@@ -1472,6 +1482,11 @@ type connExecutor struct {
 		// createdSequences keeps track of sequences created in the current transaction.
 		// The map key is the sequence descpb.ID.
 		createdSequences map[descpb.ID]struct{}
+
+		// deferredConstraints keeps track of the modes set by SET CONSTRAINTS
+		// and of the potential violations of deferred constraints, which are
+		// rechecked when the transaction commits.
+		deferredConstraints deferredConstraintsState
 	}
 
 	// sessionDataStack contains the user-configurable connection variables.
@@ -1552,6 +1567,12 @@ type connExecutor struct {
 	// any. This is printed by high-level panic recovery.
 	curStmtAST tree.Statement
 
+	// resumeProc, if set, is executed in place of the current CALL statement.
+	// It resumes a stored procedure after the procedure committed or aborted
+	// the previous transaction, and is cleared once the statement buffer moves
+	// past the CALL statement.
+	resumeProc *tree.Call
+
 	// queryCancelKey is a 64-bit identifier for the session used by the
 	// pgwire cancellation protocol.
 	queryCancelKey pgwirecancel.BackendKeyData
@@ -1574,6 +1595,11 @@ type connExecutor struct {
 	// temporary schema, which requires special cleanup on close.
 	hasCreatedTemporarySchema bool
 
+	// notifications is the listener of the channels the session is listening
+	// on. It is created by the first LISTEN statement of the session, and nil
+	// until then.
+	notifications *notify.Listener
+
 	// stmtDiagnosticsRecorder is used to track which queries need to have
 	// information collected.
 	stmtDiagnosticsRecorder *stmtdiagnostics.Registry
@@ -1759,12 +1785,18 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) {
 		delete(ex.extraTxnState.prepStmtsNamespace.portals, name)
 	}
 
-	// Close all cursors.
-	if err := ex.extraTxnState.sqlCursors.closeAll(false /* errorOnWithHold */); err != nil {
+	// Close the cursors that were declared in the transaction. Held cursors
+	// survive if it committed.
+	closeMode := closeTxnCursors
+	if ev.eventType == txnCommit {
+		closeMode = commitTxnCursors
+	}
+	if err := ex.extraTxnState.sqlCursors.closeAll(ctx, closeMode); err != nil {
 		log.Warningf(ctx, "error closing cursors: %v", err)
 	}
 
 	ex.extraTxnState.createdSequences = make(map[descpb.ID]struct{})
+	ex.extraTxnState.deferredConstraints = deferredConstraintsState{}
 
 	switch ev.eventType {
 	case txnCommit, txnRollback:
@@ -2177,6 +2209,14 @@ func (ex *connExecutor) execCmd() (retErr error) {
 	case Flush:
 		// Closing the res will flush the connection's buffer.
 		res = ex.clientComm.CreateFlushResult(pos)
+	case DeliverNotifications:
+		// Closing the res will flush the notifications to the client. If the
+		// session is in a transaction, they stay queued until it is idle again.
+		flushRes := ex.clientComm.CreateFlushResult(pos)
+		res = flushRes
+		if ex.idleConn() {
+			ex.bufferNotifications(flushRes)
+		}
 	default:
 		panic(errors.AssertionFailedf("unsupported command type: %T", cmd))
 	}
@@ -2221,6 +2261,12 @@ func (ex *connExecutor) execCmd() (retErr error) {
 		advInfo = advanceInfo{code: advanceOne}
 	}
 
+	// Deliver the notifications queued while the session was busy before the
+	// ReadyForQuery message, if the Sync left the session idle.
+	if _, ok := cmd.(Sync); ok && advInfo.code == advanceOne && ex.idleConn() {
+		ex.bufferNotifications(res.(SyncResult))
+	}
+
 	// Decide if we need to close the result or not. We don't need to do it if
 	// we're staying in place or rewinding - the statement will be executed
 	// again.
@@ -2241,6 +2287,12 @@ func (ex *connExecutor) execCmd() (retErr error) {
 		res.Discard()
 	}
 
+	// A stored procedure is only resumed by re-executing the same CALL
+	// statement.
+	if advInfo.code != stayInPlace && advInfo.code != rewind {
+		ex.resumeProc = nil
+	}
+
 	// Move the cursor according to what the state transition told us to do.
 	switch advInfo.code {
 	case advanceOne:
@@ -2319,6 +2371,12 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 		switch advInfo.code {
 		case stayInPlace:
 			nextPos = pos
+			// A CALL statement which is resumed after its stored procedure
+			// committed or aborted the previous transaction starts the new
+			// transaction at the same position.
+			if ex.resumeProc != nil && nextPos == ex.extraTxnState.txnRewindPos {
+				return nil
+			}
 		case advanceOne:
 			// Future rewinds will refer to the next position; the statement that
 			// started the transaction (i.e. BEGIN) will not be itself be executed
@@ -2394,6 +2452,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(errors.AssertionFailedf("unsupported cmd: %T", cmd))
 			}
@@ -3061,9 +3121,11 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		level := ex.txnIsolationLevelToKV(ctx, modes.Isolation)
+		if err := ex.state.setIsolationLevel(level); err != nil {
+			return pgerror.WithCandidateCode(err, pgcode.ActiveSQLTransaction)
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -3107,6 +3169,46 @@ func (ex *connExecutor) txnPriorityWithSessionDefault(mode tree.UserPriority) ro
 	return txnPriorityToProto(mode)
 }
 
+// txnIsolationLevelToKV maps a SQL isolation level to the isolation level of
+// the KV transaction that implements it. READ COMMITTED is upgraded to
+// SERIALIZABLE until the cluster version that supports it is active.
+func (ex *connExecutor) txnIsolationLevelToKV(
+	ctx context.Context, level tree.IsolationLevel,
+) isolation.Level {
+	switch level {
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		return isolation.Serializable
+	case tree.ReadCommittedIsolation:
+		if !ex.server.cfg.Settings.Version.IsActive(ctx, clusterversion.V23_1ReadCommittedIsolation) {
+			return isolation.Serializable
+		}
+		return isolation.ReadCommitted
+	default:
+		log.Fatalf(ctx, "unknown isolation level: %s", level)
+	}
+	return isolation.Serializable
+}
+
+// kvTxnIsolationLevelToTree maps the isolation level of a KV transaction to
+// the SQL isolation level that it implements.
+func kvTxnIsolationLevelToTree(level isolation.Level) tree.IsolationLevel {
+	switch level {
+	case isolation.ReadCommitted:
+		return tree.ReadCommittedIsolation
+	default:
+		return tree.SerializableIsolation
+	}
+}
+
+func (ex *connExecutor) txnIsolationLevelWithSessionDefault(
+	ctx context.Context, level tree.IsolationLevel,
+) isolation.Level {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData().DefaultTxnIsolationLevel)
+	}
+	return ex.txnIsolationLevelToKV(ctx, level)
+}
+
 // QualityOfService returns the QoSLevel session setting if the session
 // settings are populated, otherwise the default QoSLevel.
 func (ex *connExecutor) QualityOfService() sessiondatapb.QoSLevel {
@@ -3205,6 +3307,10 @@ func (ex *connExecutor) resetEvalCtx(evalCtx *extendedEvalContext, txn *kv.Txn,
 	evalCtx.TxnState = ex.getTransactionState()
 	evalCtx.TxnReadOnly = ex.state.readOnly
 	evalCtx.TxnImplicit = ex.implicitTxn()
+	evalCtx.TxnIsoLevel = isolation.Serializable
+	if txn != nil {
+		evalCtx.TxnIsoLevel = txn.IsoLevel()
+	}
 	evalCtx.TxnIsSingleStmt = false
 	if newTxn || !ex.implicitTxn() {
 		// Only update the stmt timestamp if in a new txn or an explicit txn. This is because this gets
@@ -3262,6 +3368,8 @@ func (ex *connExecutor) initPlanner(ctx context.Context, p *planner) {
 	p.preparedStatements = ex.getPrepStmtsAccessor()
 	p.sqlCursors = ex.getCursorAccessor()
 	p.createdSequences = ex.getCreatedSequencesAccessor()
+	p.deferredConstraints = ex.getDeferredConstraintsAccessor()
+	p.notifications = ex.getNotificationsAccessor()
 
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
@@ -3744,6 +3852,18 @@ func (ex *connExecutor) getCreatedSequencesAccessor() createdSequences {
 	}
 }
 
+func (ex *connExecutor) getDeferredConstraintsAccessor() deferredConstraints {
+	return connExDeferredConstraintsAccessor{
+		ex: ex,
+	}
+}
+
+func (ex *connExecutor) getNotificationsAccessor() notifications {
+	return connExNotificationsAccessor{
+		ex: ex,
+	}
+}
+
 // sessionEventf logs a message to the session event log (if any).
 func (ex *connExecutor) sessionEventf(ctx context.Context, format string, args ...interface{}) {
 	if log.ExpensiveLogEnabled(ctx, 2) {
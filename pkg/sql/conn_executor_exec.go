@@ -21,6 +21,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
 	"github.com/cockroachdb/cockroach/pkg/multitenant"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/multitenantcpu"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -268,6 +269,11 @@ func (ex *connExecutor) execStmtInOpenState(
 	// TODO(andrei): Consider adding the placeholders as tags too.
 	sp.SetTag("statement", attribute.StringValue(parserStmt.SQL))
 	defer sp.Finish()
+	if _, ok := parserStmt.AST.(*tree.Call); ok && ex.resumeProc != nil {
+		// The stored procedure invoked by this CALL statement committed or
+		// aborted the previous transaction, and is resumed in this one.
+		parserStmt.AST = ex.resumeProc
+	}
 	ast := parserStmt.AST
 	ctx = withStatement(ctx, ast)
 
@@ -565,7 +571,11 @@ func (ex *connExecutor) execStmtInOpenState(
 			return
 		}
 		if canAutoCommit && !isExtendedProtocol {
-			retEv, retPayload = ex.handleAutoCommit(ctx, ast)
+			if txnOp := ex.planner.storedProcTxnState.txnOp; txnOp != tree.StoredProcTxnNoOp {
+				retEv, retPayload = ex.execStoredProcTxnOp(ctx, ast, txnOp)
+			} else {
+				retEv, retPayload = ex.handleAutoCommit(ctx, ast)
+			}
 		}
 	}(ctx)
 
@@ -718,6 +728,13 @@ func (ex *connExecutor) execStmtInOpenState(
 		return makeErrEvent(err)
 	}
 
+	// Transactions running with READ COMMITTED isolation establish a new read
+	// snapshot for each statement, so that the statement observes all writes
+	// committed before it began. This is a no-op for other transactions.
+	if err := ex.state.mu.txn.StepReadTimestamp(ctx); err != nil {
+		return makeErrEvent(err)
+	}
+
 	if err := p.semaCtx.Placeholders.Assign(pinfo, stmt.NumPlaceholders); err != nil {
 		return makeErrEvent(err)
 	}
@@ -782,7 +799,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		}()
 	}
 
-	if err = ex.dispatchToExecutionEngine(stmtCtx, p, res); err != nil {
+	if ex.executorType == executorTypeExec && ex.state.mu.txn.IsoLevel().PerStatementReadSnapshot() {
+		err = ex.dispatchReadCommittedStmtToExecutionEngine(stmtCtx, p, res)
+	} else {
+		err = ex.dispatchToExecutionEngine(stmtCtx, p, res)
+	}
+	if err != nil {
 		stmtThresholdSpan.Finish()
 		return nil, nil, err
 	}
@@ -1114,7 +1136,7 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 	ctx, sp := tracing.EnsureChildSpan(ctx, ex.server.cfg.AmbientCtx.Tracer, "commit sql txn")
 	defer sp.Finish()
 
-	if err := ex.extraTxnState.sqlCursors.closeAll(true /* errorOnWithHold */); err != nil {
+	if err := ex.extraTxnState.sqlCursors.persistHeldCursors(ctx); err != nil {
 		return err
 	}
 
@@ -1130,6 +1152,16 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 		ex.state.mu.txn.ConfigureStepping(ctx, prevSteppingMode)
 	}
 
+	// Recheck the potential violations of deferred constraints now that all
+	// the writes of the transaction are visible.
+	if violations := ex.planner.deferredConstraints.takeViolations(true /* all */); len(violations) > 0 {
+		if err := checkDeferredViolations(
+			ctx, ex.planner.InternalSQLTxn(), ex.state.mu.txn, violations,
+		); err != nil {
+			return err
+		}
+	}
+
 	if err := ex.createJobs(ctx); err != nil {
 		return err
 	}
@@ -1203,7 +1235,7 @@ func (ex *connExecutor) createJobs(ctx context.Context) error {
 func (ex *connExecutor) rollbackSQLTransaction(
 	ctx context.Context, stmt tree.Statement,
 ) (fsm.Event, fsm.EventPayload) {
-	if err := ex.extraTxnState.sqlCursors.closeAll(false /* errorOnWithHold */); err != nil {
+	if err := ex.extraTxnState.sqlCursors.closeAll(ctx, closeTxnCursors); err != nil {
 		return ex.makeErrEvent(err, stmt)
 	}
 	if err := ex.state.mu.txn.Rollback(ctx); err != nil {
@@ -1219,6 +1251,77 @@ func (ex *connExecutor) rollbackSQLTransaction(
 	return eventTxnFinishAborted{}, nil
 }
 
+// maxReadCommittedStmtRetries is the number of times a statement in a READ
+// COMMITTED transaction is retried after a conflict with a concurrent writer,
+// before the whole transaction is retried instead.
+const maxReadCommittedStmtRetries = 10
+
+// dispatchReadCommittedStmtToExecutionEngine is like dispatchToExecutionEngine,
+// for transactions which establish a new read snapshot for each statement. If
+// the statement runs into a conflict with a concurrent writer, the transaction
+// is rolled back to a savepoint taken before the statement, and the statement
+// is retried with a new read snapshot. This is only possible as long as none of
+// the statement's results have been produced. Otherwise, the retryable error is
+// left in res and the whole transaction is retried.
+func (ex *connExecutor) dispatchReadCommittedStmtToExecutionEngine(
+	ctx context.Context, planner *planner, res RestrictedCommandResult,
+) error {
+	txn := ex.state.mu.txn
+	savepoint, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		numDDL := ex.extraTxnState.numDDL
+		if err := ex.dispatchToExecutionEngine(ctx, planner, res); err != nil {
+			return err
+		}
+		retryErr := res.Err()
+		if retryErr == nil ||
+			!errors.HasType(retryErr, (*kvpb.TransactionRetryWithProtoRefreshError)(nil)) ||
+			attempt > maxReadCommittedStmtRetries {
+			return nil
+		}
+		// Rows that were produced cannot be taken back, and savepoint rollbacks
+		// do not roll back schema changes.
+		if res.RowsAffected() != 0 || ex.extraTxnState.numDDL != numDDL {
+			return nil
+		}
+		_, pos, err := ex.stmtBuf.CurCmd()
+		if err != nil {
+			return err
+		}
+		cl := ex.clientComm.LockCommunication()
+		if cl.ClientPos() >= pos {
+			cl.Close()
+			return nil
+		}
+		if err := txn.PrepareForPartialRetry(ctx); err != nil {
+			// The transaction has to be retried from the beginning.
+			log.VEventf(ctx, 2, "cannot retry statement: %v", err)
+			cl.Close()
+			return nil
+		}
+		// Discard the row description, if one was sent for the failed attempt.
+		cl.RTrim(ctx, pos)
+		cl.Close()
+		log.VEventf(ctx, 2, "retrying statement after: %v", retryErr)
+		res.SetError(nil)
+		if err := txn.RollbackToSavepoint(ctx, savepoint); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		if err := txn.Step(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		if err := txn.StepReadTimestamp(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+	}
+}
+
 // dispatchToExecutionEngine executes the statement, writes the result to res
 // and returns an event for the connection's state machine.
 //
@@ -1299,13 +1402,18 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		return nil
 	}
 
-	var cols colinfo.ResultColumns
-	if stmt.AST.StatementReturnType() == tree.Rows {
-		cols = planner.curPlan.main.planColumns()
-	}
-	if err := ex.initStatementResult(ctx, res, stmt.AST, cols); err != nil {
-		res.SetError(err)
-		return nil
+	// A CALL statement whose procedure commits or aborts the transaction is
+	// executed again in a new transaction, and only that execution describes
+	// its result columns.
+	if c, ok := planner.curPlan.main.planNode.(*callNode); !ok || c.txnOp == tree.StoredProcTxnNoOp {
+		var cols colinfo.ResultColumns
+		if stmt.AST.StatementReturnType() == tree.Rows {
+			cols = planner.curPlan.main.planColumns()
+		}
+		if err := ex.initStatementResult(ctx, res, stmt.AST, cols); err != nil {
+			res.SetError(err)
+			return nil
+		}
 	}
 
 	ex.sessionTracing.TracePlanCheckStart(ctx)
@@ -1877,6 +1985,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartExplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, s.Modes.Isolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1900,6 +2009,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartImplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1930,6 +2040,7 @@ func (ex *connExecutor) beginImplicitTxn(
 	return eventStartImplicitTxn,
 		makeEventTxnStartPayload(
 			ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+			ex.txnIsolationLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 			mode,
 			sqlTs,
 			historicalTs,
@@ -2424,6 +2535,35 @@ func (ex *connExecutor) handleAutoCommit(
 	return ev, payload
 }
 
+// execStoredProcTxnOp commits or aborts the current implicit transaction on
+// behalf of a stored procedure which executed a COMMIT or ROLLBACK statement.
+// If successful, the CALL statement is executed again in a new transaction to
+// resume the procedure.
+func (ex *connExecutor) execStoredProcTxnOp(
+	ctx context.Context, stmt tree.Statement, txnOp tree.StoredProcTxnOp,
+) (fsm.Event, fsm.EventPayload) {
+	var ev fsm.Event
+	var payload fsm.EventPayload
+	switch txnOp {
+	case tree.StoredProcTxnCommit:
+		ev, payload = ex.handleAutoCommit(ctx, stmt)
+		if _, ok := ev.(eventTxnFinishCommitted); ok {
+			ev = eventTxnFinishCommittedStoredProc{}
+		}
+	case tree.StoredProcTxnRollback:
+		ev, payload = ex.rollbackSQLTransaction(ctx, stmt)
+		if _, ok := ev.(eventTxnFinishAborted); ok {
+			ev = eventTxnFinishAbortedStoredProc{}
+		}
+	default:
+		return ex.makeErrEvent(errors.AssertionFailedf("unexpected txn op %s", txnOp), stmt)
+	}
+	if payload == nil {
+		ex.resumeProc = ex.planner.storedProcTxnState.resumeProc
+	}
+	return ev, payload
+}
+
 // incrementStartedStmtCounter increments the appropriate started
 // statement counter for stmt's type.
 func (ex *connExecutor) incrementStartedStmtCounter(ast tree.Statement) {
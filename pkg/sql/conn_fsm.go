@@ -19,6 +19,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -112,7 +113,8 @@ type eventTxnStart struct {
 type eventTxnStartPayload struct {
 	tranCtx transitionCtx
 
-	pri roachpb.UserPriority
+	pri      roachpb.UserPriority
+	isoLevel isolation.Level
 	// txnSQLTimestamp is the timestamp that statements executed in the
 	// transaction that is started by this event will report for now(),
 	// current_timestamp(), transaction_timestamp().
@@ -127,6 +129,7 @@ type eventTxnStartPayload struct {
 // makeEventTxnStartPayload creates an eventTxnStartPayload.
 func makeEventTxnStartPayload(
 	pri roachpb.UserPriority,
+	isoLevel isolation.Level,
 	readOnly tree.ReadWriteMode,
 	txnSQLTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
@@ -135,6 +138,7 @@ func makeEventTxnStartPayload(
 ) eventTxnStartPayload {
 	return eventTxnStartPayload{
 		pri:                 pri,
+		isoLevel:            isoLevel,
 		readOnly:            readOnly,
 		txnSQLTimestamp:     txnSQLTimestamp,
 		historicalTimestamp: historicalTimestamp,
@@ -148,6 +152,13 @@ type eventTxnUpgradeToExplicit struct{}
 type eventTxnFinishCommitted struct{}
 type eventTxnFinishAborted struct{}
 
+// eventTxnFinishCommittedStoredProc and eventTxnFinishAbortedStoredProc are
+// generated when a stored procedure executes a COMMIT or ROLLBACK statement.
+// They finish the transaction without advancing past the CALL statement, which
+// is executed again in a new transaction to resume the procedure.
+type eventTxnFinishCommittedStoredProc struct{}
+type eventTxnFinishAbortedStoredProc struct{}
+
 // eventSavepointRollback is generated when we want to move from Aborted to Open
 // through a ROLLBACK TO SAVEPOINT <not cockroach_restart>. Note that it is not
 // generated when such a savepoint is rolled back to from the Open state. In
@@ -216,6 +227,8 @@ type payloadWithError interface {
 func (eventTxnStart) Event()                            {}
 func (eventTxnFinishCommitted) Event()                  {}
 func (eventTxnFinishAborted) Event()                    {}
+func (eventTxnFinishCommittedStoredProc) Event()        {}
+func (eventTxnFinishAbortedStoredProc) Event()          {}
 func (eventSavepointRollback) Event()                   {}
 func (eventNonRetriableErr) Event()                     {}
 func (eventRetriableErr) Event()                        {}
@@ -311,6 +324,22 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next:   stateNoTxn{},
 			Action: cleanupAndFinishOnError,
 		},
+		// Handle a COMMIT or ROLLBACK executed by a stored procedure. The CALL
+		// statement is executed again in a new implicit txn.
+		eventTxnFinishCommittedStoredProc{}: {
+			Description: "COMMIT in a stored procedure",
+			Next:        stateNoTxn{},
+			Action: func(args fsm.Args) error {
+				return args.Extended.(*txnState).finishTxnStoredProc(txnCommit)
+			},
+		},
+		eventTxnFinishAbortedStoredProc{}: {
+			Description: "ROLLBACK in a stored procedure",
+			Next:        stateNoTxn{},
+			Action: func(args fsm.Args) error {
+				return args.Extended.(*txnState).finishTxnStoredProc(txnRollback)
+			},
+		},
 		// Handle a txn getting upgraded to an explicit txn.
 		eventTxnUpgradeToExplicit{}: {
 			Next: stateOpen{ImplicitTxn: fsm.False, WasUpgraded: fsm.True},
@@ -505,6 +534,7 @@ func noTxnToOpen(args fsm.Args) error {
 		payload.txnSQLTimestamp,
 		payload.historicalTimestamp,
 		payload.pri,
+		payload.isoLevel,
 		payload.readOnly,
 		nil, /* txn */
 		payload.tranCtx,
@@ -528,6 +558,17 @@ func (ts *txnState) finishTxn(ev txnEventType) error {
 	return nil
 }
 
+// finishTxnStoredProc finishes the transaction after a stored procedure
+// executed a COMMIT or ROLLBACK statement. Unlike finishTxn, the statement
+// which produced the event is executed again in order to resume the procedure.
+func (ts *txnState) finishTxnStoredProc(ev txnEventType) error {
+	finishedTxnID, commitTimestamp := ts.finishSQLTxn()
+	ts.setAdvanceInfo(stayInPlace, noRewind, txnEvent{
+		eventType: ev, txnID: finishedTxnID, commitTimestamp: commitTimestamp,
+	})
+	return nil
+}
+
 // cleanupAndFinishOnError rolls back the KV txn and finishes the SQL txn.
 func cleanupAndFinishOnError(args fsm.Args) error {
 	ts := args.Extended.(*txnState)
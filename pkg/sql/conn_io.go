@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -404,6 +405,25 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications is a Command asking for the notifications queued for a
+// session listening on channels to be delivered to the client. It is pushed
+// by the session's notification listener when a notification arrives, so that
+// an idle connection receives it without waiting for the client to send a
+// query. The notifications are only delivered if the session is not inside a
+// transaction; otherwise, they are delivered at the end of the transaction.
+//
+// DeliverNotifications commands produce a FlushResult.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() string { return "deliver notifications" }
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -855,12 +875,26 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+	NotificationResult
 }
 
 // FlushResult represents the result of a Flush command. When this result is
 // closed, all previously accumulated results are flushed to the client.
 type FlushResult interface {
 	ResultBase
+	NotificationResult
+}
+
+// NotificationResult is implemented by the results on which the notifications
+// queued for a listening session can be delivered.
+type NotificationResult interface {
+	// BufferNotice appends a notice to the result.
+	BufferNotice(notice pgnotice.Notice)
+
+	// BufferNotification appends a notification to the result. Notifications
+	// are sent to the client when the result is closed, after the notices and
+	// before the completion message.
+	BufferNotification(notification notify.Notification)
 }
 
 // DrainResult represents the result of a Drain command. Closing this result
@@ -984,6 +1018,11 @@ func (r *streamingCommandResult) BufferNotice(notice pgnotice.Notice) {
 	// Unimplemented: the internal executor does not support notices.
 }
 
+// BufferNotification is part of the NotificationResult interface.
+func (r *streamingCommandResult) BufferNotification(notification notify.Notification) {
+	// Unimplemented: the internal executor does not support notifications.
+}
+
 // ResetStmtType is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
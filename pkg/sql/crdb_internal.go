@@ -34,7 +34,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
-	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantcapabilities"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -3001,6 +3000,85 @@ func writeCreateTypeDescRow(
 			tree.DNull,                           // enum_members
 		)
 	}
+	if d := typeDesc.AsDomainTypeDescriptor(); d != nil {
+		name, err := tree.NewUnresolvedObjectName(2, [3]string{d.GetName(), sc.GetName()}, 0)
+		if err != nil {
+			return false, err
+		}
+		var constraints []tree.DomainConstraint
+		if defaultExpr := d.DomainDefaultExpr(); defaultExpr != nil {
+			expr, err := parser.ParseExpr(*defaultExpr)
+			if err != nil {
+				return false, err
+			}
+			constraints = append(constraints, tree.DomainConstraint{Default: expr})
+		}
+		if d.DomainNotNull() {
+			constraints = append(constraints, tree.DomainConstraint{NotNull: true})
+		}
+		for i := 0; i < d.NumDomainChecks(); i++ {
+			c := d.GetDomainCheck(i)
+			expr, err := parser.ParseExpr(c.Expr)
+			if err != nil {
+				return false, err
+			}
+			constraints = append(constraints, tree.DomainConstraint{Name: tree.Name(c.Name), Check: expr})
+		}
+		node := &tree.CreateDomain{
+			TypeName:    name,
+			BaseType:    d.DomainBaseType(),
+			Constraints: constraints,
+		}
+		return true, addRow(
+			tree.NewDInt(tree.DInt(db.GetID())),  // database_id
+			tree.NewDString(db.GetName()),        // database_name
+			tree.NewDString(sc.GetName()),        // schema_name
+			tree.NewDInt(tree.DInt(d.GetID())),   // descriptor_id
+			tree.NewDString(d.GetName()),         // descriptor_name
+			tree.NewDString(tree.AsString(node)), // create_statement
+			tree.DNull,                           // enum_members
+		)
+	}
+	if r := typeDesc.AsRangeTypeDescriptor(); r != nil {
+		if r.GetMultirangeTypeID() == descpb.InvalidID {
+			// Multirange types are created implicitly, so we don't have create
+			// statements for them.
+			return false, nil
+		}
+		name, err := tree.NewUnresolvedObjectName(2, [3]string{r.GetName(), sc.GetName()}, 0)
+		if err != nil {
+			return false, err
+		}
+		multirangeTypeName, _, err := resolver.(catalog.TypeDescriptorResolver).GetTypeDescriptor(
+			ctx, r.GetMultirangeTypeID(),
+		)
+		if err != nil {
+			return false, err
+		}
+		multirangeName, err := tree.NewUnresolvedObjectName(
+			2, [3]string{multirangeTypeName.Object(), multirangeTypeName.Schema()}, 0,
+		)
+		if err != nil {
+			return false, err
+		}
+		node := &tree.CreateType{
+			Variety:  tree.Range,
+			TypeName: name,
+			RangeParams: []tree.RangeTypeParam{
+				{Name: "subtype", Value: r.RangeSubtype()},
+				{Name: "multirange_type_name", Value: multirangeName},
+			},
+		}
+		return true, addRow(
+			tree.NewDInt(tree.DInt(db.GetID())),  // database_id
+			tree.NewDString(db.GetName()),        // database_name
+			tree.NewDString(sc.GetName()),        // schema_name
+			tree.NewDInt(tree.DInt(r.GetID())),   // descriptor_id
+			tree.NewDString(r.GetName()),         // descriptor_name
+			tree.NewDString(tree.AsString(node)), // create_statement
+			tree.DNull,                           // enum_members
+		)
+	}
 	return false, errors.AssertionFailedf("unknown type descriptor kind %s", typeDesc.GetKind())
 }
 
@@ -3158,13 +3236,18 @@ CREATE TABLE crdb_internal.create_function_statements (
 			}
 			for i := range treeNode.Options {
 				if body, ok := treeNode.Options[i].(tree.FunctionBodyStr); ok {
-					typeReplacedBody, err := formatFunctionQueryTypesForDisplay(ctx, &p.semaCtx, p.SessionData(), string(body))
-					if err != nil {
-						return err
-					}
-					seqReplacedBody, err := formatQuerySequencesForDisplay(ctx, &p.semaCtx, typeReplacedBody, true /* multiStmt */)
-					if err != nil {
-						return err
+					seqReplacedBody := string(body)
+					// The body of a PL/pgSQL function refers to types and
+					// sequences by name, so it is displayed as is.
+					if tree.GetFuncLanguage(treeNode.Options) != tree.FunctionLangPlPgSQL {
+						typeReplacedBody, err := formatFunctionQueryTypesForDisplay(ctx, &p.semaCtx, p.SessionData(), seqReplacedBody)
+						if err != nil {
+							return err
+						}
+						seqReplacedBody, err = formatQuerySequencesForDisplay(ctx, &p.semaCtx, typeReplacedBody, true /* multiStmt */)
+						if err != nil {
+							return err
+						}
 					}
 					stmtStrs := strings.Split(seqReplacedBody, "\n")
 					for i := range stmtStrs {
@@ -7136,7 +7219,7 @@ func genClusterLocksGenerator(
 				if curLock.LockHolder != nil {
 					txnIDDatum = tree.NewDUuid(tree.DUuid{UUID: curLock.LockHolder.ID})
 					tsDatum = eval.TimestampToInexactDTimestamp(curLock.LockHolder.WriteTimestamp)
-					strengthDatum = tree.NewDString(lock.Exclusive.String())
+					strengthDatum = tree.NewDString(curLock.Strength.String())
 					durationDatum = tree.NewDInterval(
 						duration.MakeDuration(curLock.HoldDuration.Nanoseconds(), 0 /* days */, 0 /* months */),
 						types.DefaultIntervalTypeMetadata,
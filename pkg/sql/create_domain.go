@@ -0,0 +1,202 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createDomainNode struct {
+	n        *tree.CreateDomain
+	typeName *tree.TypeName
+	dbDesc   catalog.DatabaseDescriptor
+}
+
+// Use to satisfy the linter.
+var _ planNode = &createDomainNode{n: nil}
+
+// CreateDomain creates a domain.
+// Privileges: CREATE on database.
+func (p *planner) CreateDomain(ctx context.Context, n *tree.CreateDomain) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE DOMAIN",
+	); err != nil {
+		return nil, err
+	}
+
+	// Resolve the desired new type name.
+	typeName, db, err := resolveNewTypeName(p.RunParams(ctx), n.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	n.TypeName.SetAnnotation(&p.semaCtx.Annotations, typeName)
+	return &createDomainNode{
+		n:        n,
+		typeName: typeName,
+		dbDesc:   db,
+	}, nil
+}
+
+func (n *createDomainNode) startExec(params runParams) error {
+	if !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.V23_1) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to create domains",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("domain"))
+
+	baseType, err := tree.ResolveType(params.ctx, n.n.BaseType, params.p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return err
+	}
+	if baseType.UserDefined() {
+		return unimplemented.NewWithIssue(27796,
+			"domains over user-defined types are not supported")
+	}
+	if err := colinfo.ValidateColumnDefType(params.ctx, params.ExecCfg().Settings.Version, baseType); err != nil {
+		return err
+	}
+
+	schema, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+
+	domain := &descpb.TypeDescriptor_Domain{BaseType: baseType}
+	var sawNull bool
+	for i := range n.n.Constraints {
+		c := &n.n.Constraints[i]
+		switch {
+		case c.Default != nil:
+			if domain.DefaultExpr != nil {
+				return pgerror.New(pgcode.Syntax, "multiple default expressions")
+			}
+			defaultExpr, err := schemaexpr.ValidateDomainDefaultExpr(
+				params.ctx, c.Default, baseType, params.p.SemaCtx(),
+				params.ExecCfg().Settings.Version.ActiveVersion(params.ctx),
+			)
+			if err != nil {
+				return err
+			}
+			domain.DefaultExpr = &defaultExpr
+		case c.NotNull, c.Null:
+			if (c.NotNull && sawNull) || (c.Null && domain.NotNull) {
+				return pgerror.New(pgcode.Syntax, "conflicting NULL/NOT NULL constraints")
+			}
+			domain.NotNull = domain.NotNull || c.NotNull
+			sawNull = sawNull || c.Null
+		case c.Check != nil:
+			check, err := params.p.makeDomainCheck(
+				params.ctx, n.typeName.Object(), domain, c.Name, c.Check,
+			)
+			if err != nil {
+				return err
+			}
+			domain.Checks = append(domain.Checks, check)
+		}
+	}
+
+	// Generate a stable ID for the new type.
+	id, err := params.EvalContext().DescIDGenerator.GenerateUniqueDescID(params.ctx)
+	if err != nil {
+		return err
+	}
+	privs := catprivilege.CreatePrivilegesFromDefaultPrivileges(
+		n.dbDesc.GetDefaultPrivilegeDescriptor(),
+		schema.GetDefaultPrivilegeDescriptor(),
+		n.dbDesc.GetID(),
+		params.SessionData().User(),
+		privilege.Types,
+	)
+	// Unlike other user-defined types, domains don't have an implicit array
+	// type.
+	typeDesc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:           n.typeName.Type(),
+		ID:             id,
+		ParentID:       n.dbDesc.GetID(),
+		ParentSchemaID: schema.GetID(),
+		Kind:           descpb.TypeDescriptor_DOMAIN,
+		Domain:         domain,
+		Version:        1,
+		Privileges:     privs,
+	}).BuildCreatedMutableType()
+	if err := params.p.createDescriptor(params.ctx, typeDesc, n.typeName.String()); err != nil {
+		return err
+	}
+
+	// Log the event.
+	return params.p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// makeDomainCheck validates the given CHECK constraint for the domain with the
+// given name and definition. If no constraint name is given, a name which is
+// not used by the domain's other constraints is generated like in postgres.
+func (p *planner) makeDomainCheck(
+	ctx context.Context,
+	domainName string,
+	domain *descpb.TypeDescriptor_Domain,
+	name tree.Name,
+	expr tree.Expr,
+) (descpb.TypeDescriptor_Domain_Check, error) {
+	inUse := func(name string) bool {
+		for i := range domain.Checks {
+			if domain.Checks[i].Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	if name == "" {
+		name = tree.Name(domainName + "_check")
+		for i := 1; inUse(string(name)); i++ {
+			name = tree.Name(fmt.Sprintf("%s_check%d", domainName, i))
+		}
+	} else if inUse(string(name)) {
+		return descpb.TypeDescriptor_Domain_Check{}, pgerror.Newf(pgcode.DuplicateObject,
+			"constraint %q for domain %q already exists", name, domainName)
+	}
+	checkExpr, err := schemaexpr.ValidateDomainCheckExpr(
+		ctx, expr, domain.BaseType, p.SemaCtx(),
+		p.ExecCfg().Settings.Version.ActiveVersion(ctx),
+	)
+	if err != nil {
+		return descpb.TypeDescriptor_Domain_Check{}, err
+	}
+	return descpb.TypeDescriptor_Domain_Check{Name: string(name), Expr: checkExpr}, nil
+}
+
+func (n *createDomainNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *createDomainNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *createDomainNode) Close(ctx context.Context)           {}
+func (n *createDomainNode) ReadingOwnWrites()                   {}
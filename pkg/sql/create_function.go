@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
 )
 
 type createFunctionNode struct {
@@ -48,6 +50,11 @@ func (n *createFunctionNode) startExec(params runParams) error {
 	if n.cf.RoutineBody != nil {
 		return unimplemented.NewWithIssue(85144, "CREATE FUNCTION...sql_body unimplemented")
 	}
+	if n.cf.IsProcedure && !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.V23_1) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to create procedures",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
 
 	if err := params.p.canCreateOnSchema(
 		params.ctx, n.scDesc.GetID(), n.dbDesc.GetID(), params.p.User(), skipCheckPublicSchema,
@@ -106,7 +113,7 @@ func (n *createFunctionNode) createNewFunction(
 	}
 
 	for _, option := range n.cf.Options {
-		err := setFuncOption(params, udfDesc, option)
+		err := setFuncOption(params, udfDesc, option, tree.GetFuncLanguage(n.cf.Options))
 		if err != nil {
 			return err
 		}
@@ -183,7 +190,7 @@ func (n *createFunctionNode) replaceFunction(udfDesc *funcdesc.Mutable, params r
 		return err
 	}
 	for _, option := range n.cf.Options {
-		err := setFuncOption(params, udfDesc, option)
+		err := setFuncOption(params, udfDesc, option, tree.GetFuncLanguage(n.cf.Options))
 		if err != nil {
 			return err
 		}
@@ -265,6 +272,16 @@ func (n *createFunctionNode) getMutableFuncDesc(
 		if err != nil {
 			return nil, false, err
 		}
+		if fnDesc.IsProcedure() != n.cf.IsProcedure {
+			kind := "function"
+			if fnDesc.IsProcedure() {
+				kind = "procedure"
+			}
+			return nil, false, errors.WithDetailf(
+				pgerror.New(pgcode.WrongObjectType, "cannot change routine kind"),
+				"%q is a %s.", fnDesc.GetName(), kind,
+			)
+		}
 		return fnDesc, false, nil
 	}
 
@@ -296,6 +313,7 @@ func (n *createFunctionNode) getMutableFuncDesc(
 		n.cf.ReturnType.IsSet,
 		privileges,
 	)
+	newUdfDesc.SetIsProcedure(n.cf.IsProcedure)
 
 	return &newUdfDesc, true, nil
 }
@@ -389,7 +407,12 @@ func (n *createFunctionNode) addUDFReferences(udfDesc *funcdesc.Mutable, params
 	return nil
 }
 
-func setFuncOption(params runParams, udfDesc *funcdesc.Mutable, option tree.FunctionOption) error {
+func setFuncOption(
+	params runParams,
+	udfDesc *funcdesc.Mutable,
+	option tree.FunctionOption,
+	lang tree.FunctionLanguage,
+) error {
 	switch t := option.(type) {
 	case tree.FunctionVolatility:
 		v, err := funcinfo.VolatilityToProto(t)
@@ -412,6 +435,12 @@ func setFuncOption(params runParams, udfDesc *funcdesc.Mutable, option tree.Func
 		}
 		udfDesc.SetLang(v)
 	case tree.FunctionBodyStr:
+		if lang == tree.FunctionLangPlPgSQL {
+			// The body of a PL/pgSQL function is not a list of SQL statements,
+			// so references within it are kept by name.
+			udfDesc.SetFuncBody(string(t))
+			break
+		}
 		// Replace any sequence names in the function body with IDs.
 		seqReplacedFuncBody, err := replaceSeqNamesWithIDs(params.ctx, params.p, string(t), true)
 		if err != nil {
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -350,6 +351,7 @@ func (n *createTableNode) startExec(params runParams) error {
 
 	var desc *tabledesc.Mutable
 	var affected map[descpb.ID]*tabledesc.Mutable
+	var parents []*tabledesc.Mutable
 	// creationTime is initialized to a zero value and populated at read time.
 	// See the comment in desc.MaybeIncrementVersion.
 	//
@@ -385,11 +387,22 @@ func (n *createTableNode) startExec(params runParams) error {
 			desc.State = descpb.DescriptorState_ADD
 		}
 	} else {
+		if len(n.n.Inherits) > 0 {
+			if parents, err = params.p.resolveInheritanceParents(params.ctx, n.n, n.dbDesc); err != nil {
+				return err
+			}
+			if err := params.p.addInheritedTableDefs(params.ctx, n.n, parents); err != nil {
+				return err
+			}
+		}
 		affected = make(map[descpb.ID]*tabledesc.Mutable)
 		desc, err = newTableDesc(params, n.n, n.dbDesc, schema, id, creationTime, privs, affected)
 		if err != nil {
 			return err
 		}
+		for _, parent := range parents {
+			addInheritance(desc, parent)
+		}
 
 		if desc.Adding() {
 			// if this table and all its references are created in the same
@@ -449,6 +462,17 @@ func (n *createTableNode) startExec(params runParams) error {
 		}
 	}
 
+	for _, parent := range parents {
+		if err := params.p.writeSchemaChange(
+			params.ctx, parent, descpb.InvalidMutationID,
+			fmt.Sprintf("updating parent table %s(%d) for inheriting table %s(%d)",
+				parent.Name, parent.ID, desc.Name, desc.ID,
+			),
+		); err != nil {
+			return err
+		}
+	}
+
 	// Install back references to types used by this table.
 	if err := params.p.addBackRefsFromAllTypesInTable(params.ctx, desc); err != nil {
 		return err
@@ -665,6 +689,7 @@ func addUniqueWithoutIndexColumnTableDef(
 		string(d.Unique.ConstraintName),
 		[]string{string(d.Name)},
 		"", /* predicate */
+		tree.ConstraintNotDeferrable,
 		ts,
 		validationBehavior,
 	); err != nil {
@@ -673,6 +698,35 @@ func addUniqueWithoutIndexColumnTableDef(
 	return nil
 }
 
+// checkUniqueConstraintDeferrability returns an error if the given unique
+// constraint is DEFERRABLE but backed by an index. Such an index enforces
+// uniqueness as soon as rows are written, so only UNIQUE WITHOUT INDEX
+// constraints, which are enforced by checks run after each statement, can be
+// deferred.
+func checkUniqueConstraintDeferrability(d *tree.UniqueConstraintTableDef) error {
+	if d.Deferrable.IsDeferrable() && !d.WithoutIndex {
+		return errors.WithHint(
+			unimplemented.NewWithIssue(31632, "DEFERRABLE unique constraints backed by an index"),
+			"use UNIQUE WITHOUT INDEX to declare a DEFERRABLE unique constraint",
+		)
+	}
+	return nil
+}
+
+// checkDeferrabilityIsSupported returns an error if the given constraint is
+// DEFERRABLE and the cluster has not yet been upgraded to a version that
+// knows how to defer constraint checks.
+func checkDeferrabilityIsSupported(
+	ctx context.Context, version clusterversion.Handle, deferrability tree.ConstraintDeferrability,
+) error {
+	if deferrability.IsDeferrable() && !version.IsActive(ctx, clusterversion.V23_1) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to create DEFERRABLE constraints",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
+	return nil
+}
+
 // addUniqueWithoutIndexTableDef runs various checks on the given
 // UniqueConstraintTableDef before adding it as a UNIQUE WITHOUT INDEX
 // constraint to the given table descriptor.
@@ -709,6 +763,9 @@ func addUniqueWithoutIndexTableDef(
 			"creating a unique constraint using UNIQUE WITH NOT VISIBLE INDEX is not supported",
 		)
 	}
+	if err := checkDeferrabilityIsSupported(ctx, evalCtx.Settings.Version, d.Deferrable); err != nil {
+		return err
+	}
 
 	// If there is a predicate, validate it.
 	var predicate string
@@ -728,7 +785,7 @@ func addUniqueWithoutIndexTableDef(
 		colNames[i] = string(d.Columns[i].Column)
 	}
 	if err := ResolveUniqueWithoutIndexConstraint(
-		ctx, desc, string(d.Name), colNames, predicate, ts, validationBehavior,
+		ctx, desc, string(d.Name), colNames, predicate, d.Deferrable, ts, validationBehavior,
 	); err != nil {
 		return err
 	}
@@ -748,6 +805,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	constraintName string,
 	colNames []string,
 	predicate string,
+	deferrability tree.ConstraintDeferrability,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
 ) error {
@@ -796,12 +854,14 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:         constraintName,
-		TableID:      tbl.ID,
-		ColumnIDs:    columnIDs,
-		Predicate:    predicate,
-		Validity:     validity,
-		ConstraintID: tbl.NextConstraintID,
+		Name:              constraintName,
+		TableID:           tbl.ID,
+		ColumnIDs:         columnIDs,
+		Predicate:         predicate,
+		Validity:          validity,
+		ConstraintID:      tbl.NextConstraintID,
+		Deferrable:        deferrability.IsDeferrable(),
+		InitiallyDeferred: deferrability.IsInitiallyDeferred(),
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -851,6 +911,9 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *eval.Context,
 ) error {
+	if err := checkDeferrabilityIsSupported(ctx, evalCtx.Settings.Version, d.Deferrable); err != nil {
+		return err
+	}
 	var originColSet catalog.TableColSet
 	originCols := make([]catalog.Column, len(d.FromCols))
 	for i, fromCol := range d.FromCols {
@@ -1046,6 +1109,8 @@ func ResolveFK(
 		OnUpdate:            tree.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               tree.CompositeKeyMatchMethodValue[d.Match],
 		ConstraintID:        tbl.NextConstraintID,
+		Deferrable:          d.Deferrable.IsDeferrable(),
+		InitiallyDeferred:   d.Deferrable.IsInitiallyDeferred(),
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -1870,6 +1935,9 @@ func NewTableDesc(
 				return nil, err
 			}
 		case *tree.UniqueConstraintTableDef:
+			if err := checkUniqueConstraintDeferrability(d); err != nil {
+				return nil, err
+			}
 			if d.WithoutIndex {
 				// We will add the unique constraint below.
 				break
@@ -1984,6 +2052,19 @@ func NewTableDesc(
 					return nil, err
 				}
 			}
+		case *tree.ExclusionConstraintTableDef:
+			if d.Name != "" {
+				if idx := catalog.FindIndexByName(&desc, d.Name.String()); idx != nil {
+					return nil, pgerror.Newf(pgcode.DuplicateRelation, "duplicate index name: %q", d.Name)
+				}
+			}
+			idx, err := makeExclusionConstraintIndex(ctx, st, &desc, &n.Table, d, semaCtx, version)
+			if err != nil {
+				return nil, err
+			}
+			if err := desc.AddSecondaryIndex(idx); err != nil {
+				return nil, err
+			}
 		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef:
 			// pass, handled below.
 
@@ -2152,7 +2233,7 @@ func NewTableDesc(
 				}
 			}
 
-		case *tree.IndexTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
+		case *tree.IndexTableDef, *tree.ExclusionConstraintTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
 			// Pass, handled above.
 
 		case *tree.CheckConstraintTableDef:
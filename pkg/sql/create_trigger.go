@@ -0,0 +1,197 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+type createTriggerNode struct {
+	n *tree.CreateTrigger
+}
+
+// Use to satisfy the linter.
+var _ planNode = &createTriggerNode{n: nil}
+
+// CreateTrigger creates a trigger.
+// Privileges: CREATE on table, EXECUTE on the trigger function.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+	return &createTriggerNode{n: n}, nil
+}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("trigger"))
+	p := params.p
+
+	tn := n.n.Table.ToTableName()
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		params.ctx, &tn, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return err
+	}
+	if err := p.CheckPrivilege(params.ctx, tableDesc, privilege.CREATE); err != nil {
+		return err
+	}
+
+	if existing := tableDesc.FindTriggerByName(string(n.n.Name)); existing != nil {
+		if !n.n.Replace {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"trigger %q for relation %q already exists", n.n.Name, tableDesc.GetName())
+		}
+		if err := p.removeTrigger(params.ctx, tableDesc, existing.ID); err != nil {
+			return err
+		}
+	}
+
+	fnDesc, err := p.resolveTriggerFunction(params.ctx, &n.n.FuncName)
+	if err != nil {
+		return err
+	}
+
+	// Trigger IDs start at 1, and tables created without triggers have no
+	// next trigger ID.
+	if tableDesc.NextTriggerID == 0 {
+		tableDesc.NextTriggerID = 1
+	}
+	trigger := descpb.TriggerDescriptor{
+		ID:         tableDesc.NextTriggerID,
+		Name:       string(n.n.Name),
+		ActionTime: descpb.TriggerDescriptor_ActionTime(n.n.ActionTime),
+		ForEachRow: n.n.ForEachRow,
+		FuncID:     fnDesc.GetID(),
+		FuncArgs:   n.n.FuncArgs,
+	}
+	var hasInsert, hasDelete bool
+	for _, ev := range n.n.Events {
+		event := descpb.TriggerDescriptor_Event{
+			Type: descpb.TriggerDescriptor_Event_Type(ev.EventType),
+		}
+		hasInsert = hasInsert || ev.EventType == tree.TriggerEventInsert
+		hasDelete = hasDelete || ev.EventType == tree.TriggerEventDelete
+		for _, colName := range ev.Columns {
+			col, err := catalog.MustFindColumnByTreeName(tableDesc, colName)
+			if err != nil {
+				return err
+			}
+			event.ColumnIDs = append(event.ColumnIDs, col.GetID())
+		}
+		trigger.Events = append(trigger.Events, event)
+	}
+
+	if n.n.When != nil {
+		trigger.WhenExpr, err = schemaexpr.ValidateTriggerWhenExpr(
+			params.ctx, n.n.When,
+			n.n.ForEachRow && !hasDelete, /* allowNew */
+			n.n.ForEachRow && !hasInsert, /* allowOld */
+			p.SemaCtx(), params.ExecCfg().Settings.Version.ActiveVersion(params.ctx),
+			func(columnName tree.Name) (exists bool, accessible bool, id catid.ColumnID, typ *types.T) {
+				col, err := catalog.MustFindColumnByTreeName(tableDesc, columnName)
+				if err != nil || col.Dropped() {
+					return false, false, 0, nil
+				}
+				return true, !col.IsInaccessible(), col.GetID(), col.GetType()
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	tableDesc.NextTriggerID++
+	tableDesc.Triggers = append(tableDesc.Triggers, trigger)
+	if err := fnDesc.AddTriggerReference(tableDesc.GetID(), trigger.ID); err != nil {
+		return err
+	}
+	if err := p.writeFuncSchemaChange(params.ctx, fnDesc); err != nil {
+		return err
+	}
+	return p.writeSchemaChange(
+		params.ctx, tableDesc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+}
+
+// resolveTriggerFunction resolves the function with the given name, which
+// must be a user-defined function returning type trigger with no parameters.
+func (p *planner) resolveTriggerFunction(
+	ctx context.Context, name *tree.FunctionName,
+) (*funcdesc.Mutable, error) {
+	path := p.CurrentSearchPath()
+	fnDef, err := p.ResolveFunction(ctx, name.ToUnresolvedObjectName().ToUnresolvedName(), &path)
+	if err != nil {
+		return nil, err
+	}
+	ol, err := fnDef.MatchOverload([]*types.T{}, name.Schema(), &path)
+	if err != nil {
+		return nil, err
+	}
+	if !ol.IsUDF || ol.FixedReturnType().Family() != types.TriggerFamily {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type trigger", name.Object())
+	}
+	fnDesc, err := p.Descriptors().MutableByID(p.Txn()).Function(ctx, funcdesc.UserDefinedFunctionOIDToID(ol.Oid))
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, fnDesc, privilege.EXECUTE); err != nil {
+		return nil, err
+	}
+	return fnDesc, nil
+}
+
+// removeTrigger removes the trigger with the given ID from the table, along
+// with the back-reference from its function.
+func (p *planner) removeTrigger(
+	ctx context.Context, tableDesc *tabledesc.Mutable, triggerID descpb.TriggerID,
+) error {
+	for i := range tableDesc.Triggers {
+		if tableDesc.Triggers[i].ID != triggerID {
+			continue
+		}
+		fnDesc, err := p.Descriptors().MutableByID(p.Txn()).Function(ctx, tableDesc.Triggers[i].FuncID)
+		if err != nil {
+			return err
+		}
+		fnDesc.RemoveTriggerReference(tableDesc.GetID(), triggerID)
+		if err := p.writeFuncSchemaChange(ctx, fnDesc); err != nil {
+			return err
+		}
+		tableDesc.Triggers = append(tableDesc.Triggers[:i], tableDesc.Triggers[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (n *createTriggerNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *createTriggerNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *createTriggerNode) Close(ctx context.Context)           {}
+func (n *createTriggerNode) ReadingOwnWrites()                   {}
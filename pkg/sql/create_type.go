@@ -13,12 +13,14 @@ package sql
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -234,6 +236,10 @@ func CreateUserDefinedArrayTypeDesc(
 			labels[i] = e.ElementLabel
 		}
 		elemTyp = types.NewCompositeType(catid.TypeIDToOID(typDesc.GetID()), catid.TypeIDToOID(id), contents, labels)
+	case descpb.TypeDescriptor_RANGE:
+		elemTyp = types.MakeRange(catid.TypeIDToOID(typDesc.GetID()), catid.TypeIDToOID(id), typDesc.Range.Subtype)
+	case descpb.TypeDescriptor_MULTIRANGE:
+		elemTyp = types.MakeMultirange(catid.TypeIDToOID(typDesc.GetID()), catid.TypeIDToOID(id), typDesc.Range.RangeType)
 	default:
 		return nil, errors.AssertionFailedf("cannot make array type for kind %s", t.String())
 	}
@@ -320,6 +326,15 @@ func (p *planner) createUserDefinedType(params runParams, n *createTypeNode) err
 		return params.p.createCompositeWithID(
 			params, id, n.n.CompositeTypeList, n.dbDesc, n.typeName,
 		)
+	case tree.Range:
+		if !p.execCfg.Settings.Version.IsActive(params.ctx, clusterversion.V23_1RangeTypes) {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"version %v must be finalized to create range types",
+				clusterversion.ByKey(clusterversion.V23_1RangeTypes))
+		}
+		return params.p.createRangeWithID(
+			params, id, n.n.RangeParams, n.dbDesc, n.typeName,
+		)
 	}
 	return unimplemented.NewWithIssue(25123, "CREATE TYPE")
 }
@@ -503,6 +518,208 @@ func (p *planner) createCompositeWithID(
 	return nil
 }
 
+// rangeTypeDef is the resolved definition of a CREATE TYPE ... AS RANGE
+// statement.
+type rangeTypeDef struct {
+	subtype *types.T
+	// multirangeName is the name of the multirange type, or nil if it should
+	// be derived from the name of the range type.
+	multirangeName *tree.UnresolvedObjectName
+}
+
+// resolveRangeTypeParams resolves the parameters of a CREATE TYPE ... AS RANGE
+// statement. Only the SUBTYPE and MULTIRANGE_TYPE_NAME parameters are
+// supported.
+func resolveRangeTypeParams(
+	params runParams, rangeParams []tree.RangeTypeParam,
+) (rangeTypeDef, error) {
+	var def rangeTypeDef
+	seen := make(map[tree.Name]struct{})
+	for _, param := range rangeParams {
+		if _, ok := seen[param.Name]; ok {
+			return rangeTypeDef{}, pgerror.Newf(pgcode.Syntax,
+				"conflicting or redundant options")
+		}
+		seen[param.Name] = struct{}{}
+		switch param.Name {
+		case "subtype":
+			typ, err := tree.ResolveType(params.ctx, param.Value, params.p.semaCtx.TypeResolver)
+			if err != nil {
+				return rangeTypeDef{}, err
+			}
+			if typ.UserDefined() {
+				return rangeTypeDef{}, unimplemented.NewWithIssue(27791,
+					"range types over user-defined types not yet supported")
+			}
+			if !colinfo.ColumnTypeIsIndexable(typ) {
+				return rangeTypeDef{}, pgerror.Newf(pgcode.UndefinedObject,
+					"data type %s has no default operator class for access method \"btree\"",
+					typ.SQLString())
+			}
+			def.subtype = typ
+		case "multirange_type_name":
+			name, ok := param.Value.(*tree.UnresolvedObjectName)
+			if !ok {
+				return rangeTypeDef{}, pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid multirange type name %s", param.Value.SQLString())
+			}
+			def.multirangeName = name
+		default:
+			return rangeTypeDef{}, unimplemented.NewWithIssuef(27791,
+				"range type attribute %q not yet supported", param.Name)
+		}
+	}
+	if def.subtype == nil {
+		return rangeTypeDef{}, pgerror.New(pgcode.InvalidObjectDefinition,
+			"type attribute \"subtype\" is required")
+	}
+	return def, nil
+}
+
+// defaultMultirangeTypeName returns the name of the multirange type which is
+// implicitly created along with the range type of the given name. Like in
+// Postgres, it is the range type's name with "range" replaced by
+// "multirange", or with "_multirange" appended if it does not contain "range".
+func defaultMultirangeTypeName(rangeName string) string {
+	if strings.Contains(rangeName, "range") {
+		return strings.Replace(rangeName, "range", "multirange", 1)
+	}
+	return rangeName + "_multirange"
+}
+
+// CreateRangeTypeDesc creates a new range type descriptor.
+func CreateRangeTypeDesc(
+	params runParams,
+	id, multirangeID descpb.ID,
+	subtype *types.T,
+	dbDesc catalog.DatabaseDescriptor,
+	schema catalog.SchemaDescriptor,
+	typeName *tree.TypeName,
+) *typedesc.Mutable {
+	privs := catprivilege.CreatePrivilegesFromDefaultPrivileges(
+		dbDesc.GetDefaultPrivilegeDescriptor(),
+		schema.GetDefaultPrivilegeDescriptor(),
+		dbDesc.GetID(),
+		params.SessionData().User(),
+		privilege.Types,
+	)
+
+	return typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:           typeName.Type(),
+		ID:             id,
+		ParentID:       dbDesc.GetID(),
+		ParentSchemaID: schema.GetID(),
+		Kind:           descpb.TypeDescriptor_RANGE,
+		Range: &descpb.TypeDescriptor_Range{
+			Subtype:          subtype,
+			MultirangeTypeID: multirangeID,
+		},
+		Version:    1,
+		Privileges: privs,
+	}).BuildCreatedMutableType()
+}
+
+// CreateMultirangeTypeDesc creates the type descriptor of the multirange type
+// of the given range type.
+func CreateMultirangeTypeDesc(
+	params runParams,
+	id descpb.ID,
+	rangeTyp *types.T,
+	dbDesc catalog.DatabaseDescriptor,
+	schema catalog.SchemaDescriptor,
+	typeName *tree.TypeName,
+) *typedesc.Mutable {
+	privs := catprivilege.CreatePrivilegesFromDefaultPrivileges(
+		dbDesc.GetDefaultPrivilegeDescriptor(),
+		schema.GetDefaultPrivilegeDescriptor(),
+		dbDesc.GetID(),
+		params.SessionData().User(),
+		privilege.Types,
+	)
+
+	return typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:           typeName.Type(),
+		ID:             id,
+		ParentID:       dbDesc.GetID(),
+		ParentSchemaID: schema.GetID(),
+		Kind:           descpb.TypeDescriptor_MULTIRANGE,
+		Range: &descpb.TypeDescriptor_Range{
+			Subtype:   rangeTyp.RangeSubtype(),
+			RangeType: rangeTyp,
+		},
+		Version:    1,
+		Privileges: privs,
+	}).BuildCreatedMutableType()
+}
+
+func (p *planner) createRangeWithID(
+	params runParams,
+	id descpb.ID,
+	rangeParams []tree.RangeTypeParam,
+	dbDesc catalog.DatabaseDescriptor,
+	typeName *tree.TypeName,
+) error {
+	def, err := resolveRangeTypeParams(params, rangeParams)
+	if err != nil {
+		return err
+	}
+
+	schema, err := getCreateTypeParams(params, typeName, dbDesc)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the name of the multirange type, which defaults to a name in the
+	// schema of the range type.
+	var multirangeTypeName *tree.TypeName
+	if def.multirangeName != nil {
+		var multirangeDB catalog.DatabaseDescriptor
+		multirangeTypeName, multirangeDB, err = resolveNewTypeName(params, def.multirangeName)
+		if err != nil {
+			return err
+		}
+		if multirangeDB.GetID() != dbDesc.GetID() {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cross database type references are not supported: %s",
+				multirangeTypeName.String())
+		}
+	} else {
+		name := tree.MakeTypeNameWithPrefix(
+			typeName.ObjectNamePrefix, defaultMultirangeTypeName(typeName.Type()),
+		)
+		multirangeTypeName = &name
+	}
+	if multirangeTypeName.Schema() == typeName.Schema() &&
+		multirangeTypeName.Type() == typeName.Type() {
+		return sqlerrors.NewTypeAlreadyExistsError(multirangeTypeName.String())
+	}
+	multirangeSchema, err := getCreateTypeParams(params, multirangeTypeName, dbDesc)
+	if err != nil {
+		return err
+	}
+
+	multirangeID, err := params.EvalContext().DescIDGenerator.GenerateUniqueDescID(params.ctx)
+	if err != nil {
+		return err
+	}
+
+	// The range type of the multirange refers to the range's array type, so
+	// the range type needs to be created first.
+	rangeDesc := CreateRangeTypeDesc(params, id, multirangeID, def.subtype, dbDesc, schema, typeName)
+	if err := p.finishCreateType(params, id, typeName, rangeDesc, dbDesc, schema); err != nil {
+		return err
+	}
+	rangeTyp := types.MakeRange(
+		catid.TypeIDToOID(id), catid.TypeIDToOID(rangeDesc.ArrayTypeID), def.subtype,
+	)
+	multirangeDesc := CreateMultirangeTypeDesc(
+		params, multirangeID, rangeTyp, dbDesc, multirangeSchema, multirangeTypeName,
+	)
+	return p.finishCreateType(
+		params, multirangeID, multirangeTypeName, multirangeDesc, dbDesc, multirangeSchema,
+	)
+}
+
 func (p *planner) finishCreateType(
 	params runParams,
 	id descpb.ID,
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
@@ -68,6 +69,8 @@ type createViewNode struct {
 	// withData indicates if a materialized view should be populated
 	// with data by executing the underlying query.
 	withData bool
+	// incremental indicates if a materialized view is refreshed incrementally.
+	incremental bool
 }
 
 // ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
@@ -85,6 +88,12 @@ func (n *createViewNode) startExec(params runParams) error {
 		telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter(tableType))
 	}
 
+	if n.incremental && !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.V23_1) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to create incrementally refreshed materialized views",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
+
 	viewName := n.viewName.Object()
 	log.VEventf(params.ctx, 2, "dependencies for view %s:\n%s", viewName, n.planDeps.String())
 
@@ -252,6 +261,19 @@ func (n *createViewNode) startExec(params runParams) error {
 					// should only be accessed after a REFRESH VIEW operation has been called
 					// on it.
 					desc.RefreshViewRequired = !n.withData
+					// If the view is refreshed incrementally, check that its query is
+					// supported and index the columns which identify the rows affected
+					// by changes to the base tables.
+					if n.incremental {
+						q, err := params.p.analyzeIncrementalView(params.ctx, n.viewQuery)
+						if err != nil {
+							return err
+						}
+						if err := q.addKeyIndex(&desc); err != nil {
+							return err
+						}
+						desc.IncrementalRefresh = &descpb.MaterializedViewIncrementalRefresh{}
+					}
 					desc.State = descpb.DescriptorState_ADD
 					version := params.ExecCfg().Settings.Version.ActiveVersion(params.ctx)
 					if err := desc.AllocateIDs(params.ctx, version); err != nil {
@@ -0,0 +1,212 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+)
+
+// deferredConstraints tracks the checking mode of deferrable constraints and
+// the potential violations of deferred constraints in the current transaction.
+type deferredConstraints interface {
+	// isDeferred returns whether the checking of the given constraint is
+	// deferred until the end of the current transaction.
+	isDeferred(c *exec.DeferrableConstraint) bool
+	// deferViolation records a potential violation of the given deferred
+	// constraint, identified by its key values, to be rechecked later.
+	deferViolation(c *exec.DeferrableConstraint, keyVals tree.Datums) error
+	// setMode implements SET CONSTRAINTS. If all is true, the mode applies to
+	// all deferrable constraints, otherwise only to the named ones.
+	setMode(all bool, names tree.NameList, deferred bool) error
+	// takeViolations removes and returns the recorded violations of
+	// constraints which are no longer deferred, or of all constraints if all is
+	// true.
+	takeViolations(all bool) []deferredViolation
+}
+
+// deferredViolation is a potential violation of a deferred constraint.
+type deferredViolation struct {
+	c       *exec.DeferrableConstraint
+	keyVals tree.Datums
+}
+
+// violationKey returns the key used to deduplicate the potential violations
+// of a deferred constraint.
+func violationKey(c *exec.DeferrableConstraint, keyVals tree.Datums) string {
+	return fmt.Sprintf("%d/%s/%s", c.TableID, c.Name, keyVals.String())
+}
+
+// constraintMode is the checking mode of a constraint set by SET CONSTRAINTS.
+type constraintMode int
+
+const (
+	// constraintModeUnset means that the constraint uses its initial mode.
+	constraintModeUnset constraintMode = iota
+	constraintModeImmediate
+	constraintModeDeferred
+)
+
+func makeConstraintMode(deferred bool) constraintMode {
+	if deferred {
+		return constraintModeDeferred
+	}
+	return constraintModeImmediate
+}
+
+// deferredConstraintsState is the per-transaction state behind
+// deferredConstraints.
+type deferredConstraintsState struct {
+	// allMode is set by SET CONSTRAINTS ALL and overrides the initial mode of
+	// all deferrable constraints.
+	allMode constraintMode
+	// modes contains the modes set by SET CONSTRAINTS for individual
+	// constraints, keyed by constraint name. They override allMode.
+	modes map[string]constraintMode
+	// violations are the recorded potential violations, deduplicated by seen.
+	violations []deferredViolation
+	seen       map[string]struct{}
+}
+
+func (s *deferredConstraintsState) isDeferred(c *exec.DeferrableConstraint) bool {
+	mode := s.modes[c.Name]
+	if mode == constraintModeUnset {
+		mode = s.allMode
+	}
+	if mode == constraintModeUnset {
+		return c.InitiallyDeferred
+	}
+	return mode == constraintModeDeferred
+}
+
+type connExDeferredConstraintsAccessor struct {
+	ex *connExecutor
+}
+
+func (c connExDeferredConstraintsAccessor) isDeferred(dc *exec.DeferrableConstraint) bool {
+	if c.ex.extraTxnState.fromOuterTxn {
+		// Statements run by an internal executor on behalf of an outer
+		// transaction cannot defer any checks, since they don't commit the
+		// transaction.
+		return false
+	}
+	return c.ex.extraTxnState.deferredConstraints.isDeferred(dc)
+}
+
+func (c connExDeferredConstraintsAccessor) deferViolation(
+	dc *exec.DeferrableConstraint, keyVals tree.Datums,
+) error {
+	s := &c.ex.extraTxnState.deferredConstraints
+	key := violationKey(dc, keyVals)
+	if _, ok := s.seen[key]; ok {
+		return nil
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]struct{})
+	}
+	s.seen[key] = struct{}{}
+	s.violations = append(s.violations, deferredViolation{c: dc, keyVals: keyVals})
+	return nil
+}
+
+func (c connExDeferredConstraintsAccessor) setMode(
+	all bool, names tree.NameList, deferred bool,
+) error {
+	s := &c.ex.extraTxnState.deferredConstraints
+	mode := makeConstraintMode(deferred)
+	if all {
+		s.allMode = mode
+		s.modes = nil
+		return nil
+	}
+	if s.modes == nil {
+		s.modes = make(map[string]constraintMode)
+	}
+	for _, name := range names {
+		s.modes[string(name)] = mode
+	}
+	return nil
+}
+
+func (c connExDeferredConstraintsAccessor) takeViolations(all bool) []deferredViolation {
+	s := &c.ex.extraTxnState.deferredConstraints
+	var taken []deferredViolation
+	remaining := s.violations[:0]
+	for _, v := range s.violations {
+		if all || !s.isDeferred(v.c) {
+			taken = append(taken, v)
+		} else {
+			remaining = append(remaining, v)
+		}
+	}
+	s.violations = remaining
+	if len(s.violations) == 0 {
+		s.seen = nil
+	} else {
+		for _, v := range taken {
+			delete(s.seen, violationKey(v.c, v.keyVals))
+		}
+	}
+	return taken
+}
+
+// emptyDeferredConstraints is the default impl used by the planner when the
+// connExecutor is not available. All constraints are checked immediately.
+type emptyDeferredConstraints struct{}
+
+func (emptyDeferredConstraints) isDeferred(*exec.DeferrableConstraint) bool {
+	return false
+}
+
+func (emptyDeferredConstraints) deferViolation(*exec.DeferrableConstraint, tree.Datums) error {
+	return errors.AssertionFailedf("deferViolation not supported in emptyDeferredConstraints")
+}
+
+func (emptyDeferredConstraints) setMode(bool, tree.NameList, bool) error {
+	return errors.AssertionFailedf("setMode not supported in emptyDeferredConstraints")
+}
+
+func (emptyDeferredConstraints) takeViolations(bool) []deferredViolation {
+	return nil
+}
+
+// checkDeferredViolations rechecks the given potential violations of deferred
+// constraints using the given transaction, and returns an error for the first
+// one which still violates its constraint.
+func checkDeferredViolations(
+	ctx context.Context, txn isql.Txn, kvTxn *kv.Txn, violations []deferredViolation,
+) error {
+	for _, v := range violations {
+		args := make([]interface{}, len(v.keyVals))
+		for i := range v.keyVals {
+			args[i] = v.keyVals[i]
+		}
+		row, err := txn.QueryRowEx(
+			ctx, "check-deferred-constraint", kvTxn,
+			sessiondata.NodeUserSessionDataOverride,
+			v.c.Recheck, args...,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "checking deferred constraint %q", v.c.Name)
+		}
+		if row != nil {
+			return v.c.MkErr(v.keyVals)
+		}
+	}
+	return nil
+}
@@ -12,8 +12,11 @@ package delegate
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/errors"
 )
@@ -25,7 +28,7 @@ func (d *delegator) delegateShowCreateFunction(n *tree.ShowCreateFunction) (tree
 SELECT function_name, create_statement
 FROM crdb_internal.create_function_statements
 WHERE schema_name = %[1]s
-AND function_name = %[2]s
+AND function_id IN (%[2]s)
 `
 	un, ok := n.Name.FunctionReference.(*tree.UnresolvedName)
 	if !ok {
@@ -37,16 +40,31 @@ AND function_name = %[2]s
 		return nil, err
 	}
 
+	// Only show the overloads that are procedures for SHOW CREATE PROCEDURE,
+	// and those that are functions for SHOW CREATE FUNCTION.
 	var udfSchema string
+	var fnIDs []string
 	for _, o := range fn.Overloads {
-		if o.IsUDF {
-			udfSchema = o.Schema
+		if !o.IsUDF {
+			continue
 		}
+		_, ol, err := d.catalog.ResolveFunctionByOID(d.ctx, o.Oid)
+		if err != nil {
+			return nil, err
+		}
+		if ol.IsProcedure != n.IsProcedure {
+			continue
+		}
+		udfSchema = o.Schema
+		fnIDs = append(fnIDs, strconv.Itoa(int(catid.UserDefinedOIDToID(o.Oid))))
 	}
 	if udfSchema == "" {
+		if n.IsProcedure {
+			return nil, errors.Errorf("procedure %s does not exist", tree.AsString(un))
+		}
 		return nil, errors.Errorf("function %s does not exist", tree.AsString(un))
 	}
 
-	fullQuery := fmt.Sprintf(query, lexbase.EscapeSQLString(udfSchema), lexbase.EscapeSQLString(un.Parts[0]))
+	fullQuery := fmt.Sprintf(query, lexbase.EscapeSQLString(udfSchema), strings.Join(fnIDs, ", "))
 	return parse(fullQuery)
 }
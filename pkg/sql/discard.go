@@ -52,9 +52,17 @@ func (n *discardNode) startExec(params runParams) error {
 			return err
 		}
 
+		// CLOSE ALL
+		if err := params.p.sqlCursors.closeAll(params.ctx, closeAllCursors); err != nil {
+			return err
+		}
+
 		// DEALLOCATE ALL
 		params.p.preparedStatements.DeleteAll(params.ctx)
 
+		// UNLISTEN *
+		params.p.notifications.unlistenAll()
+
 		// DISCARD SEQUENCES
 		params.p.sessionDataMutatorIterator.applyOnEachMutator(func(m sessionDataMutator) {
 			m.data.SequenceState = sessiondata.NewSequenceState()
@@ -436,6 +436,7 @@ func (dsp *DistSQLPlanner) mustWrapNode(planCtx *PlanningCtx, node planNode) boo
 	switch n := node.(type) {
 	// Keep these cases alphabetized, please!
 	case *distinctNode:
+	case *expandNode:
 	case *exportNode:
 	case *filterNode:
 	case *groupNode:
@@ -501,6 +502,9 @@ func checkSupportForPlanNode(node planNode) (distRecommendation, error) {
 	case *distinctNode:
 		return checkSupportForPlanNode(n.plan)
 
+	case *expandNode:
+		return checkSupportForPlanNode(n.source)
+
 	case *exportNode:
 		return checkSupportForPlanNode(n.source)
 
@@ -3320,6 +3324,9 @@ func (dsp *DistSQLPlanner) createPhysPlanForPlanNode(
 	case *distinctNode:
 		plan, err = dsp.createPlanForDistinct(ctx, planCtx, n)
 
+	case *expandNode:
+		plan, err = dsp.createPlanForExpand(ctx, planCtx, n)
+
 	case *exportNode:
 		plan, err = dsp.createPlanForExport(ctx, planCtx, n)
 
@@ -3762,6 +3769,57 @@ func (dsp *DistSQLPlanner) createPlanForOrdinality(
 	return plan, nil
 }
 
+func (dsp *DistSQLPlanner) createPlanForExpand(
+	ctx context.Context, planCtx *PlanningCtx, n *expandNode,
+) (*PhysicalPlan, error) {
+	plan, err := dsp.createPhysPlanForPlanNode(ctx, planCtx, n.source)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &execinfrapb.ExpandSpec{
+		GroupingCols: make([]uint32, len(n.groupingCols)),
+		GroupingSets: make([]execinfrapb.ExpandSpec_GroupingSet, len(n.groupingSets)),
+	}
+	for i, col := range n.groupingCols {
+		spec.GroupingCols[i] = uint32(plan.PlanToStreamColMap[col])
+	}
+	for i := range n.groupingSets {
+		for j, col := range n.groupingCols {
+			if n.groupingSets[i].Contains(int(col)) {
+				spec.GroupingSets[i].Cols = append(spec.GroupingSets[i].Cols, uint32(j))
+			}
+		}
+	}
+
+	inputTypes := plan.GetResultTypes()
+	outputTypes := make([]*types.T, 0, len(inputTypes)+len(spec.GroupingCols)+2)
+	outputTypes = append(outputTypes, inputTypes...)
+	for _, col := range spec.GroupingCols {
+		outputTypes = append(outputTypes, inputTypes[col])
+	}
+	outputTypes = append(outputTypes, types.Int)
+	if n.hasEmptyGroupingSet() {
+		outputTypes = append(outputTypes, types.Bool)
+	}
+	for i := len(inputTypes); i < len(outputTypes); i++ {
+		plan.PlanToStreamColMap = append(plan.PlanToStreamColMap, i)
+	}
+
+	core := execinfrapb.ProcessorCoreUnion{Expand: spec}
+	if n.hasEmptyGroupingSet() {
+		// When the input is empty, the rows for the empty grouping sets must be
+		// emitted exactly once, so the expansion is performed on the gateway.
+		plan.AddSingleGroupStage(ctx, dsp.gatewaySQLInstanceID, core, execinfrapb.PostProcessSpec{}, outputTypes)
+	} else {
+		// The expansion of each row is independent of the other rows, so it can
+		// be performed wherever the input is produced. The rows produced for
+		// each input row are adjacent, so the ordering is preserved.
+		plan.AddNoGroupingStage(core, execinfrapb.PostProcessSpec{}, outputTypes, plan.MergeOrdering)
+	}
+	return plan, nil
+}
+
 func createProjectSetSpec(
 	ctx context.Context, planCtx *PlanningCtx, n *projectSetPlanningInfo, indexVarMap []int,
 ) (*execinfrapb.ProjectSetSpec, error) {
@@ -4362,6 +4420,8 @@ func (c *localScanParallelizationChecker) enterNode(
 	switch n := plan.(type) {
 	case *distinctNode:
 		return true, nil
+	case *expandNode:
+		return true, nil
 	case *explainPlanNode:
 		// walkPlan doesn't recurse into explainPlanNode, so we have to manually
 		// walk over the wrapped plan.
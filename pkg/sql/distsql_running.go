@@ -2097,6 +2097,9 @@ func (dsp *DistSQLPlanner) planAndRunPostquery(
 	postqueryResultWriter := &errOnlyResultWriter{}
 	postqueryRecv.resultWriterMu.row = postqueryResultWriter
 	postqueryRecv.resultWriterMu.batch = postqueryResultWriter
+	// Postqueries run only for their side effects. Trigger invocations, unlike
+	// FK cascades and checks, can produce rows which must be ignored.
+	postqueryRecv.discardRows = true
 	// Postqueries cannot have "inner" plans, so we use nil finishedSetupFn.
 	dsp.Run(ctx, postqueryPlanCtx, planner.txn, postqueryPhysPlan, postqueryRecv, evalCtx, nil /* finishedSetupFn */)
 	return postqueryRecv.getError()
@@ -646,6 +646,14 @@ func (e *distSQLSpecExecFactory) ConstructOrdinality(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: ordinality")
 }
 
+func (e *distSQLSpecExecFactory) ConstructExpand(
+	input exec.Node,
+	groupingCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: expand")
+}
+
 func (e *distSQLSpecExecFactory) ConstructIndexJoin(
 	input exec.Node,
 	table cat.Table,
@@ -1009,6 +1017,21 @@ func (e *distSQLSpecExecFactory) ConstructUpsert(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: upsert")
 }
 
+func (e *distSQLSpecExecFactory) ConstructMerge(
+	input exec.Node,
+	table cat.Table,
+	actionCol exec.NodeColumnOrdinal,
+	insertCols exec.TableColumnOrdinalSet,
+	fetchCols exec.TableColumnOrdinalSet,
+	updateCols exec.TableColumnOrdinalSet,
+	returnCols exec.TableColumnOrdinalSet,
+	checks exec.CheckOrdinalSet,
+	passthrough colinfo.ResultColumns,
+	autoCommit bool,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: merge")
+}
+
 func (e *distSQLSpecExecFactory) ConstructDelete(
 	input exec.Node,
 	table cat.Table,
@@ -1053,6 +1076,7 @@ func (e *distSQLSpecExecFactory) ConstructCreateView(
 	deps opt.SchemaDeps,
 	typeDeps opt.SchemaTypeDeps,
 	withData bool,
+	incremental bool,
 ) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create view")
 }
@@ -1063,6 +1087,16 @@ func (e *distSQLSpecExecFactory) ConstructCreateFunction(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: create function")
 }
 
+func (e *distSQLSpecExecFactory) ConstructCall(
+	proc tree.TypedExpr,
+	cols colinfo.ResultColumns,
+	overload *tree.Overload,
+	txnOp tree.StoredProcTxnOp,
+	resumeAt int,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: call")
+}
+
 func (e *distSQLSpecExecFactory) ConstructSequenceSelect(sequence cat.Sequence) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: sequence select")
 }
@@ -1074,7 +1108,7 @@ func (e *distSQLSpecExecFactory) ConstructSaveTable(
 }
 
 func (e *distSQLSpecExecFactory) ConstructErrorIfRows(
-	input exec.Node, mkErr exec.MkErrFn,
+	input exec.Node, mkErr exec.MkErrFn, deferrable *exec.DeferrableConstraint,
 ) (exec.Node, error) {
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: error if rows")
 }
@@ -74,6 +74,9 @@ func (p *planner) DropFunction(
 		if err != nil {
 			return nil, err
 		}
+		if err := checkRoutineKind(mut, n.IsProcedure); err != nil {
+			return nil, err
+		}
 		if n.DropBehavior != tree.DropCascade && len(mut.DependedOnBy) > 0 {
 			dependedOnByIDs := make([]descpb.ID, 0, len(mut.DependedOnBy))
 			for _, ref := range mut.DependedOnBy {
@@ -151,6 +154,24 @@ func (p *planner) matchUDF(
 	return &ol, nil
 }
 
+// checkRoutineKind returns an error if the given routine is a function but a
+// procedure is expected, or vice versa.
+func checkRoutineKind(fnDesc catalog.FunctionDescriptor, isProcedure bool) error {
+	if fnDesc.IsProcedure() == isProcedure {
+		return nil
+	}
+	if isProcedure {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.WrongObjectType, "%q is not a procedure", fnDesc.GetName()),
+			"Use DROP FUNCTION to drop functions.",
+		)
+	}
+	return errors.WithHint(
+		pgerror.Newf(pgcode.WrongObjectType, "%q is not a function", fnDesc.GetName()),
+		"Use DROP PROCEDURE to drop procedures.",
+	)
+}
+
 func (p *planner) checkPrivilegesForDropFunction(
 	ctx context.Context, fnID descpb.ID,
 ) (*funcdesc.Mutable, error) {
@@ -74,6 +74,35 @@ func (p *planner) DropTable(ctx context.Context, n *tree.DropTable) (planNode, e
 		td[droppedDesc.ID] = toDelete{tn, droppedDesc}
 	}
 
+	// Tables which inherit from a dropped table are dropped along with it,
+	// which requires CASCADE.
+	queue := make([]*tabledesc.Mutable, 0, len(td))
+	for _, toDel := range td {
+		queue = append(queue, toDel.desc)
+	}
+	for len(queue) > 0 {
+		droppedDesc := queue[0]
+		queue = queue[1:]
+		for _, id := range droppedDesc.InheritedBy {
+			if _, ok := td[id]; ok {
+				continue
+			}
+			child, err := p.Descriptors().MutableByID(p.txn).Table(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.canRemoveInheritanceChild(ctx, droppedDesc.Name, child, n.DropBehavior); err != nil {
+				return nil, err
+			}
+			childName, err := p.getQualifiedTableName(ctx, child)
+			if err != nil {
+				return nil, err
+			}
+			td[child.ID] = toDelete{childName, child}
+			queue = append(queue, child)
+		}
+	}
+
 	for _, toDel := range td {
 		droppedDesc := toDel.desc
 		for _, fk := range droppedDesc.InboundForeignKeys() {
@@ -252,6 +281,12 @@ func (p *planner) dropTableImpl(
 	}
 	tableDesc.InboundFKs = nil
 
+	// Remove the inheritance references from the parents and children of the
+	// table which are not being dropped.
+	if err := p.removeInheritanceForDrop(ctx, tableDesc); err != nil {
+		return droppedViews, err
+	}
+
 	// Remove sequence dependencies.
 	for _, col := range tableDesc.PublicColumns() {
 		if err := p.removeSequenceDependencies(ctx, tableDesc, col); err != nil {
@@ -0,0 +1,90 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+type dropTriggerNode struct {
+	n *tree.DropTrigger
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropTriggerNode{n: nil}
+
+// DropTrigger drops a trigger.
+// Privileges: CREATE on table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+	if n.DropBehavior == tree.DropCascade {
+		return nil, unimplemented.New("DROP TRIGGER...CASCADE", "drop trigger cascade not supported")
+	}
+	return &dropTriggerNode{n: n}, nil
+}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("trigger"))
+	p := params.p
+
+	tn := n.n.Table.ToTableName()
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		params.ctx, &tn, !n.n.IfExists, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return err
+	}
+	if tableDesc == nil {
+		return nil
+	}
+	if err := p.CheckPrivilege(params.ctx, tableDesc, privilege.CREATE); err != nil {
+		return err
+	}
+
+	trigger := tableDesc.FindTriggerByName(string(n.n.Trigger))
+	if trigger == nil {
+		if !n.n.IfExists {
+			return pgerror.Newf(pgcode.UndefinedObject,
+				"trigger %q for table %q does not exist", n.n.Trigger, tableDesc.GetName())
+		}
+		p.BufferClientNotice(params.ctx, pgnotice.Newf(
+			"trigger %q for relation %q does not exist, skipping", n.n.Trigger, tableDesc.GetName()))
+		return nil
+	}
+
+	if err := p.removeTrigger(params.ctx, tableDesc, trigger.ID); err != nil {
+		return err
+	}
+	return p.writeSchemaChange(
+		params.ctx, tableDesc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+}
+
+func (n *dropTriggerNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropTriggerNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropTriggerNode) Close(ctx context.Context)           {}
+func (n *dropTriggerNode) ReadingOwnWrites()                   {}
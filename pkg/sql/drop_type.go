@@ -45,7 +45,30 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 	); err != nil {
 		return nil, err
 	}
+	return p.dropTypes(ctx, n, false /* domainsOnly */)
+}
+
+// DropDomain implements DROP DOMAIN.
+func (p *planner) DropDomain(ctx context.Context, n *tree.DropDomain) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP DOMAIN",
+	); err != nil {
+		return nil, err
+	}
+	return p.dropTypes(ctx, &tree.DropType{
+		Names:        n.Names,
+		IfExists:     n.IfExists,
+		DropBehavior: n.DropBehavior,
+	}, true /* domainsOnly */)
+}
 
+// dropTypes plans the dropping of the given types. If domainsOnly is set, all
+// the types must be domains.
+func (p *planner) dropTypes(
+	ctx context.Context, n *tree.DropType, domainsOnly bool,
+) (planNode, error) {
 	node := &dropTypeNode{
 		n:      n,
 		toDrop: make(map[descpb.ID]*typedesc.Mutable),
@@ -66,6 +89,9 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if _, ok := node.toDrop[typeDesc.ID]; ok {
 			continue
 		}
+		if domainsOnly && typeDesc.Kind != descpb.TypeDescriptor_DOMAIN {
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", name)
+		}
 		switch typeDesc.Kind {
 		case descpb.TypeDescriptor_ALIAS:
 			// The implicit array types are not directly droppable.
@@ -91,6 +117,21 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 				"cannot drop type %q because table %q requires it",
 				name, name,
 			)
+		case descpb.TypeDescriptor_MULTIRANGE:
+			// Multirange types are dropped along with their range type.
+			rangeDesc, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Type(
+				ctx, typeDesc.AsRangeTypeDescriptor().GetRangeTypeID(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			return nil, errors.WithHintf(
+				pgerror.Newf(
+					pgcode.DependentObjectsStillExist,
+					"cannot drop type %q because type %q requires it",
+					name, rangeDesc.GetName(),
+				),
+				"You can drop type %s instead.", rangeDesc.GetName())
 		}
 
 		// Check if we can drop the type.
@@ -98,6 +139,14 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 			return nil, err
 		}
 
+		// Record this descriptor for deletion.
+		node.toDrop[typeDesc.ID] = typeDesc
+
+		// Domains don't have an array type.
+		if typeDesc.Kind == descpb.TypeDescriptor_DOMAIN {
+			continue
+		}
+
 		// Get the array type that needs to be dropped as well.
 		mutArrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, typeDesc.ArrayTypeID)
 		if err != nil {
@@ -107,9 +156,26 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if err := p.canDropTypeDesc(ctx, mutArrayDesc, n.DropBehavior); err != nil {
 			return nil, err
 		}
-		// Record these descriptors for deletion.
-		node.toDrop[typeDesc.ID] = typeDesc
 		node.toDrop[mutArrayDesc.ID] = mutArrayDesc
+
+		// Range types are dropped along with their multirange type and its
+		// array type.
+		if typeDesc.Kind == descpb.TypeDescriptor_RANGE {
+			mutMultirangeDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, typeDesc.Range.MultirangeTypeID)
+			if err != nil {
+				return nil, err
+			}
+			mutMultirangeArrayDesc, err := p.Descriptors().MutableByID(p.txn).Type(ctx, mutMultirangeDesc.ArrayTypeID)
+			if err != nil {
+				return nil, err
+			}
+			for _, desc := range []*typedesc.Mutable{mutMultirangeDesc, mutMultirangeArrayDesc} {
+				if err := p.canDropTypeDesc(ctx, desc, n.DropBehavior); err != nil {
+					return nil, err
+				}
+				node.toDrop[desc.ID] = desc
+			}
+		}
 	}
 	return node, nil
 }
@@ -26,6 +26,12 @@ type errorIfRowsNode struct {
 	// produced.
 	mkErr exec.MkErrFn
 
+	// deferrable is set if the node enforces a deferrable constraint. If the
+	// constraint is deferred in the current transaction, the rows produced are
+	// recorded as potential violations to be rechecked at commit time instead
+	// of causing an error.
+	deferrable *exec.DeferrableConstraint
+
 	nexted bool
 }
 
@@ -39,6 +45,10 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	}
 	n.nexted = true
 
+	if n.deferrable != nil && params.p.deferredConstraints.isDeferred(n.deferrable) {
+		return false, n.deferViolations(params)
+	}
+
 	ok, err := n.plan.Next(params)
 	if err != nil {
 		return false, err
@@ -49,6 +59,30 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	return false, nil
 }
 
+// deferViolations records all the rows produced by the wrapped node as
+// potential violations of the deferred constraint.
+func (n *errorIfRowsNode) deferViolations(params runParams) error {
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return err
+		}
+		row := n.plan.Values()
+		keyVals := make(tree.Datums, len(n.deferrable.KeyCols))
+		for i, ord := range n.deferrable.KeyCols {
+			keyVals[i] = row[ord]
+			if keyVals[i] == tree.DNull {
+				// A NULL in the key can only be produced by a MATCH FULL
+				// violation, which no later statement can fix.
+				return n.mkErr(row)
+			}
+		}
+		if err := params.p.deferredConstraints.deferViolation(n.deferrable, keyVals); err != nil {
+			return err
+		}
+	}
+}
+
 func (n *errorIfRowsNode) Values() tree.Datums {
 	return nil
 }
@@ -0,0 +1,315 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// makeExclusionConstraintIndex returns the descriptor of the index which backs
+// the exclusion constraint d on desc.
+//
+// An exclusion constraint is stored as a non-unique secondary index whose
+// ExclusionOperators hold the operator of each key column. Columns compared
+// with = form the prefix of the index. A column compared with && is the last
+// column of the index. For a range or multirange column, which can be key
+// encoded, the index is a forward index. Otherwise, the column is the inverted
+// column of the index, which lets writes look up overlapping values with an
+// inverted join. The constraint is enforced by the optimizer with a
+// uniqueness-style check on every mutation of the table, and validated by the
+// schema changer after the index is backfilled.
+func makeExclusionConstraintIndex(
+	ctx context.Context,
+	st *cluster.Settings,
+	desc *tabledesc.Mutable,
+	tn *tree.TableName,
+	d *tree.ExclusionConstraintTableDef,
+	semaCtx *tree.SemaContext,
+	version clusterversion.ClusterVersion,
+) (descpb.IndexDescriptor, error) {
+	if !st.Version.IsActive(ctx, clusterversion.V23_1) {
+		return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to create exclusion constraints",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
+	if desc.PartitionAllBy {
+		return descpb.IndexDescriptor{}, pgerror.New(pgcode.FeatureNotSupported,
+			"exclusion constraints are not supported on tables which are implicitly partitioned with PARTITION ALL BY or LOCALITY REGIONAL BY ROW definition",
+		)
+	}
+
+	// Order the elements so that the element compared with &&, if any, is the
+	// last column of the index.
+	elems := make(tree.ExclusionConstraintElems, 0, len(d.Elems))
+	var overlaps *tree.ExclusionConstraintElem
+	for i := range d.Elems {
+		elem := &d.Elems[i]
+		if elem.Expr != nil {
+			return descpb.IndexDescriptor{}, pgerror.New(pgcode.FeatureNotSupported,
+				"expressions are not supported in exclusion constraints",
+			)
+		}
+		switch elem.Operator.Symbol {
+		case treecmp.EQ:
+			elems = append(elems, *elem)
+		case treecmp.Overlaps:
+			if overlaps != nil {
+				return descpb.IndexDescriptor{}, pgerror.New(pgcode.FeatureNotSupported,
+					"exclusion constraints may only use the && operator once",
+				)
+			}
+			if !d.Inverted {
+				return descpb.IndexDescriptor{}, pgerror.New(pgcode.WrongObjectType,
+					"the && operator in an exclusion constraint requires USING gist",
+				)
+			}
+			overlaps = elem
+		default:
+			return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.FeatureNotSupported,
+				"operator %s is not supported in exclusion constraints", elem.Operator,
+			)
+		}
+	}
+	if overlaps != nil {
+		elems = append(elems, *overlaps)
+	}
+
+	columns := make(tree.IndexElemList, len(elems))
+	operators := make([]string, len(elems))
+	for i := range elems {
+		columns[i] = elems[i].IndexElem
+		operators[i] = elems[i].Operator.String()
+	}
+	if err := validateColumnsAreAccessible(desc, columns); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+	var overlapsCol catalog.Column
+	if overlaps != nil {
+		var err error
+		if overlapsCol, err = catalog.MustFindColumnByTreeName(desc, overlaps.Column); err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		if _, ok := tree.CmpOps[treecmp.Overlaps].LookupImpl(
+			overlapsCol.GetType(), overlapsCol.GetType(),
+		); !ok {
+			return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.UndefinedFunction,
+				"operator does not exist: %s && %s", overlapsCol.GetType(), overlapsCol.GetType(),
+			)
+		}
+	}
+	inverted := overlapsCol != nil && !isForwardExclusionType(overlapsCol.GetType())
+	if err := checkIndexColumns(desc, columns, nil /* storing */, inverted); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+
+	idx := descpb.IndexDescriptor{
+		Name:               string(d.Name),
+		Version:            descpb.StrictIndexColumnIDGuaranteesVersion,
+		ExclusionOperators: operators,
+	}
+	if err := idx.FillColumns(columns); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+	if inverted {
+		idx.Type = descpb.IndexDescriptor_INVERTED
+		if err := populateInvertedIndexDescriptor(
+			ctx, st, overlapsCol, &idx, overlaps.IndexElem,
+		); err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+	}
+
+	if d.Predicate != nil {
+		expr, err := schemaexpr.ValidatePartialIndexPredicate(
+			ctx, desc, d.Predicate, tn, semaCtx, version,
+		)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		idx.Predicate = expr
+	}
+	return idx, nil
+}
+
+// isForwardExclusionType returns true if an exclusion constraint which compares
+// a column of type t with && is backed by a forward index rather than an
+// inverted index.
+func isForwardExclusionType(t *types.T) bool {
+	switch t.Family() {
+	case types.RangeFamily, types.MultirangeFamily:
+		return true
+	}
+	return false
+}
+
+// validateExclusionConstraints verifies that no two rows of tableDesc conflict
+// under the exclusion constraints backed by the given mutation indexes. The
+// indexes must have been backfilled, so that the validation query can use
+// them.
+func validateExclusionConstraints(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	indexes []catalog.Index,
+	runHistoricalTxn descs.HistoricalInternalExecTxnRunner,
+	execOverride sessiondata.InternalExecutorOverride,
+) error {
+	desc, err := tableDesc.MakeFirstMutationPublic(catalog.IgnoreConstraints)
+	if err != nil {
+		return err
+	}
+	return runHistoricalTxn.Exec(ctx, func(ctx context.Context, txn descs.Txn) error {
+		return txn.WithSyntheticDescriptors([]catalog.Descriptor{desc}, func() error {
+			for _, idx := range indexes {
+				if err := validateExclusionConstraint(ctx, desc, idx, txn, execOverride); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// validateExclusionConstraint verifies that no two rows of srcTable conflict
+// under the exclusion constraint backed by idx.
+func validateExclusionConstraint(
+	ctx context.Context,
+	srcTable catalog.TableDescriptor,
+	idx catalog.Index,
+	txn isql.Txn,
+	execOverride sessiondata.InternalExecutorOverride,
+) error {
+	query, colNames, err := exclusionConflictQuery(srcTable, idx)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "validating exclusion constraint %q (%q [%v]) with query %q",
+		idx.GetName(),
+		srcTable.GetName(),
+		colNames,
+		query,
+	)
+	values, err := txn.QueryRowEx(ctx, "validate exclusion constraint", txn.KV(), execOverride, query)
+	if err != nil {
+		return err
+	}
+	if values.Len() == 0 {
+		return nil
+	}
+	valuesStr := make([]string, len(values))
+	for i := range values {
+		valuesStr[i] = values[i].String()
+	}
+	n := len(colNames)
+	// Note: this error message mirrors the message produced by Postgres when it
+	// fails to add an exclusion constraint due to conflicting rows.
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(
+				pgcode.ExclusionViolation, "could not create exclusion constraint %q", idx.GetName(),
+			),
+			idx.GetName(),
+		),
+		fmt.Sprintf(
+			"Key (%[1]s)=(%[2]s) conflicts with key (%[1]s)=(%[3]s).",
+			strings.Join(colNames, ", "),
+			strings.Join(valuesStr[:n], ", "),
+			strings.Join(valuesStr[n:], ", "),
+		),
+	)
+}
+
+// exclusionConflictQuery returns a query which finds a pair of distinct rows
+// of srcTbl which conflict under the exclusion constraint backed by idx, and
+// the names of the constraint's columns. The query returns the values of the
+// constraint's columns in the first row, followed by their values in the
+// second row.
+func exclusionConflictQuery(
+	srcTbl catalog.TableDescriptor, idx catalog.Index,
+) (sql string, colNames []string, _ error) {
+	keyColIDs := make([]descpb.ColumnID, idx.NumKeyColumns())
+	for i := range keyColIDs {
+		keyColIDs[i] = idx.GetKeyColumnID(i)
+	}
+	colNames, err := catalog.ColumnNamesForIDs(srcTbl, keyColIDs)
+	if err != nil {
+		return "", nil, err
+	}
+	pkColIDs := srcTbl.GetPrimaryIndex().IndexDesc().KeyColumnIDs
+	pkColNames, err := catalog.ColumnNamesForIDs(srcTbl, pkColIDs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Both sides of the self-join project the key columns followed by the
+	// primary key columns, so that the predicate, if any, is applied before the
+	// columns are qualified.
+	var srcCols []string
+	seen := make(map[string]struct{})
+	for _, names := range [][]string{colNames, pkColNames} {
+		for _, n := range names {
+			if _, ok := seen[n]; !ok {
+				seen[n] = struct{}{}
+				srcCols = append(srcCols, tree.NameString(n))
+			}
+		}
+	}
+	src := fmt.Sprintf("SELECT %s FROM [%d AS tbl]", strings.Join(srcCols, ", "), srcTbl.GetID())
+	if idx.IsPartial() {
+		src = fmt.Sprintf("%s WHERE (%s)", src, idx.GetPredicate())
+	}
+
+	selectCols := make([]string, 0, 2*len(colNames))
+	for _, side := range []string{"a", "b"} {
+		for _, n := range colNames {
+			selectCols = append(selectCols, fmt.Sprintf("%s.%s", side, tree.NameString(n)))
+		}
+	}
+	on := make([]string, 0, len(colNames)+1)
+	for i, n := range colNames {
+		on = append(on, fmt.Sprintf("a.%[1]s %[2]s b.%[1]s",
+			tree.NameString(n), idx.GetExclusionOperator(i),
+		))
+	}
+	aPK := make([]string, len(pkColNames))
+	bPK := make([]string, len(pkColNames))
+	for i, n := range pkColNames {
+		aPK[i] = "a." + tree.NameString(n)
+		bPK[i] = "b." + tree.NameString(n)
+	}
+	on = append(on, fmt.Sprintf("(%s) != (%s)", strings.Join(aPK, ", "), strings.Join(bPK, ", ")))
+
+	query := fmt.Sprintf(
+		`SELECT %[1]s FROM (%[2]s) AS a JOIN (%[2]s) AS b ON %[3]s LIMIT 1`,
+		strings.Join(selectCols, ", "), // 1
+		src,                            // 2
+		strings.Join(on, " AND "),      // 3
+	)
+	return query, colNames, nil
+}
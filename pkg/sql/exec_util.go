@@ -72,6 +72,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -1299,6 +1300,10 @@ type ExecutorConfig struct {
 	// StmtDiagnosticsRecorder deals with recording statement diagnostics.
 	StmtDiagnosticsRecorder *stmtdiagnostics.Registry
 
+	// NotificationRegistry delivers the notifications published with NOTIFY
+	// to the sessions listening on their channels.
+	NotificationRegistry *notify.Registry
+
 	ExternalIODirConfig base.ExternalIODirConfig
 
 	GCJobNotifier *gcjobnotifier.Notifier
@@ -1911,6 +1916,8 @@ func checkResultType(typ *types.T) error {
 	case types.TimestampTZFamily:
 	case types.TSQueryFamily:
 	case types.TSVectorFamily:
+	case types.RangeFamily:
+	case types.MultirangeFamily:
 	case types.IntervalFamily:
 	case types.JsonFamily:
 	case types.UuidFamily:
@@ -3024,6 +3031,10 @@ func (m *sessionDataMutator) SetDefaultTransactionPriority(val tree.UserPriority
 	m.data.DefaultTxnPriority = int64(val)
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int64(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
@@ -3402,6 +3413,10 @@ func (m *sessionDataMutator) SetTrigramSimilarityThreshold(val float64) {
 	m.data.TrigramSimilarityThreshold = val
 }
 
+func (m *sessionDataMutator) SetDefaultTextSearchConfig(val string) {
+	m.data.DefaultTextSearchConfig = val
+}
+
 func (m *sessionDataMutator) SetUnconstrainedNonCoveringIndexScanEnabled(val bool) {
 	m.data.UnconstrainedNonCoveringIndexScanEnabled = val
 }
@@ -3478,6 +3493,10 @@ func (m *sessionDataMutator) SetAllowRoleMembershipsToChangeDuringTransaction(va
 	m.data.AllowRoleMembershipsToChangeDuringTransaction = val
 }
 
+func (m *sessionDataMutator) SetDurableLockingForUpdate(val bool) {
+	m.data.DurableLockingForUpdate = val
+}
+
 // Utility functions related to scrubbing sensitive information on SQL Stats.
 
 // quantizeCounts ensures that the Count field in the
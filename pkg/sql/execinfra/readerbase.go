@@ -15,12 +15,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangecache"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -210,6 +212,17 @@ func CanUseStreamer(settings *cluster.Settings) bool {
 	return useStreamerEnabled.Get(&settings.SV)
 }
 
+// UseDurableLocking returns whether the row-level locks with the provided
+// strength acquired by the flow should be replicated, which is controlled by
+// the durable_locking_for_update session variable.
+func (flowCtx *FlowCtx) UseDurableLocking(
+	ctx context.Context, lockingStrength descpb.ScanLockingStrength,
+) bool {
+	return lockingStrength != descpb.ScanLockingStrength_FOR_NONE &&
+		flowCtx.EvalCtx.SessionData().DurableLockingForUpdate &&
+		flowCtx.EvalCtx.Settings.Version.IsActive(ctx, clusterversion.V23_1ReplicatedLocks)
+}
+
 // UseStreamer returns whether the kvstreamer.Streamer API should be used as
 // well as the txn that should be used (regardless of the boolean return value).
 func (flowCtx *FlowCtx) UseStreamer() (bool, *kv.Txn, error) {
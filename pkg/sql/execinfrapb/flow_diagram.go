@@ -390,6 +390,19 @@ func (d *ProjectSetSpec) summary() (string, []string) {
 	return "ProjectSet", details
 }
 
+// summary implements the diagramCellType interface.
+func (e *ExpandSpec) summary() (string, []string) {
+	details := make([]string, 0, len(e.GroupingSets))
+	for _, set := range e.GroupingSets {
+		cols := make([]uint32, len(set.Cols))
+		for i, c := range set.Cols {
+			cols[i] = e.GroupingCols[c]
+		}
+		details = append(details, fmt.Sprintf("(%s)", colListStr(cols)))
+	}
+	return "Expand", details
+}
+
 // summary implements the diagramCellType interface.
 func (s *SamplerSpec) summary() (string, []string) {
 	details := []string{fmt.Sprintf("SampleSize: %d", s.SampleSize)}
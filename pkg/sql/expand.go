@@ -0,0 +1,58 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// expandNode emits one row per grouping set for every row of its source, in
+// order to compute aggregations over multiple grouping sets (GROUPING SETS,
+// ROLLUP and CUBE). See execinfrapb.ExpandSpec for the format of its output.
+type expandNode struct {
+	source  planNode
+	columns colinfo.ResultColumns
+
+	// groupingCols are the source columns that are part of at least one
+	// grouping set.
+	groupingCols []exec.NodeColumnOrdinal
+
+	// groupingSets are the grouping sets, as sets of source columns.
+	groupingSets []exec.NodeColumnOrdinalSet
+}
+
+func (n *expandNode) startExec(runParams) error {
+	panic("expandNode can't be run in local mode")
+}
+
+func (n *expandNode) Next(params runParams) (bool, error) {
+	panic("expandNode can't be run in local mode")
+}
+
+func (n *expandNode) Values() tree.Datums {
+	panic("expandNode can't be run in local mode")
+}
+
+func (n *expandNode) Close(ctx context.Context) { n.source.Close(ctx) }
+
+// hasEmptyGroupingSet returns true if one of the grouping sets is empty.
+func (n *expandNode) hasEmptyGroupingSet() bool {
+	for i := range n.groupingSets {
+		if n.groupingSets[i].Empty() {
+			return true
+		}
+	}
+	return false
+}
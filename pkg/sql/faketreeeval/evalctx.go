@@ -315,6 +315,11 @@ func (*DummyEvalPlanner) RepairTTLScheduledJobForTable(ctx context.Context, tabl
 	return errors.WithStack(errEvalPlanner)
 }
 
+// PublishNotification is part of the Planner interface.
+func (*DummyEvalPlanner) PublishNotification(ctx context.Context, channel, payload string) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
 // Mon is part of the eval.Planner interface.
 func (ep *DummyEvalPlanner) Mon() *mon.BytesMonitor {
 	return ep.Monitor
@@ -0,0 +1,1552 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catenumpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/memsize"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treebin"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// A materialized view created WITH (incremental) is refreshed by applying the
+// changes made to its base tables since the last refresh, rather than by
+// recomputing the whole view:
+//
+//  1. The primary keys of the rows of each base table which changed between
+//     the last refresh and the refresh timestamp are collected with
+//     incremental ExportRequests, which are served by the MVCC incremental
+//     iterator and only visit the data written in that time interval.
+//
+//  2. The view query is evaluated only over the changed rows, by restricting
+//     each base table in turn to batches of its changed rows. The rows of the
+//     view derived from the changed rows are replaced, and the aggregates of
+//     the groups affected by the changed rows are adjusted by the difference
+//     between the contributions of the changed rows before and after the
+//     changes.
+//
+// The cost of a refresh is therefore proportional to the number of changed
+// rows, provided that the join conditions of the view query can be evaluated
+// with lookups into the base tables.
+//
+// The view query must be a single SELECT over base tables, combined with inner
+// joins, and either:
+//
+//   - return the primary key columns of all base tables, so that each row of
+//     the view can be identified by the base table rows it is derived from, or
+//   - compute COUNT, SUM, MIN and MAX aggregates, grouped by columns which are
+//     also returned by the view together with COUNT(*).
+
+// incrementalViewOutputKind describes how a column of an incrementally
+// refreshed materialized view is computed.
+type incrementalViewOutputKind int
+
+const (
+	// incrementalViewScalar is a column which is not an aggregate, i.e. a
+	// projection of the joined base tables or a grouping column.
+	incrementalViewScalar incrementalViewOutputKind = iota
+	incrementalViewCountRows
+	incrementalViewCount
+	incrementalViewSum
+	incrementalViewMin
+	incrementalViewMax
+)
+
+// incrementalViewSource is a base table referenced in the FROM clause of the
+// query of an incrementally refreshed materialized view.
+type incrementalViewSource struct {
+	table catalog.TableDescriptor
+	// name is the name used to refer to the columns of the table in the query;
+	// either its alias or its (qualified) name.
+	name tree.TableName
+	// aliased is true if name is an alias.
+	aliased bool
+	// keyCols are the primary key columns of the table.
+	keyCols []catalog.Column
+	// keyOutputs are the ordinals of the view columns which contain the primary
+	// key columns of the table, for views without aggregation.
+	keyOutputs []int
+}
+
+// incrementalViewOutput describes a column of an incrementally refreshed
+// materialized view.
+type incrementalViewOutput struct {
+	kind incrementalViewOutputKind
+	// arg is the argument of the aggregate function computing the column, if
+	// any.
+	arg tree.Expr
+	// partial is the ordinal of the first column computing the contributions
+	// to the aggregate in a delta query. See aggregateDeltaExprs.
+	partial int
+}
+
+// incrementalViewQuery is the analyzed query of an incrementally refreshed
+// materialized view.
+type incrementalViewQuery struct {
+	sel     *tree.SelectClause
+	sources []incrementalViewSource
+	outputs []incrementalViewOutput
+	// aggregated is true if the view query computes aggregates.
+	aggregated bool
+	// keyOutputs are the ordinals of the grouping columns of an aggregated view.
+	keyOutputs []int
+	// countRowsOutput is the ordinal of the COUNT(*) column of an aggregated
+	// view, or -1 if there is none.
+	countRowsOutput int
+}
+
+func unsupportedIncrementalViewErr(format string, args ...interface{}) error {
+	return errors.WithHint(
+		pgerror.Newf(pgcode.FeatureNotSupported,
+			"materialized view cannot be refreshed incrementally: "+format, args...),
+		"Incrementally refreshed materialized views support SELECT statements "+
+			"over tables combined with inner joins, optionally computing COUNT, "+
+			"SUM, MIN and MAX aggregates.",
+	)
+}
+
+// analyzeIncrementalView checks that the given materialized view query can be
+// refreshed incrementally, and returns its analysis.
+func (p *planner) analyzeIncrementalView(
+	ctx context.Context, query string,
+) (*incrementalViewQuery, error) {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected view query %T", stmt.AST)
+	}
+	for {
+		paren, ok := sel.Select.(*tree.ParenSelect)
+		if !ok {
+			break
+		}
+		sel = paren.Select
+	}
+	switch {
+	case sel.With != nil:
+		return nil, unsupportedIncrementalViewErr("WITH is not supported")
+	case sel.OrderBy != nil:
+		return nil, unsupportedIncrementalViewErr("ORDER BY is not supported")
+	case sel.Limit != nil:
+		return nil, unsupportedIncrementalViewErr("LIMIT is not supported")
+	case sel.Locking != nil:
+		return nil, unsupportedIncrementalViewErr("locking clauses are not supported")
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, unsupportedIncrementalViewErr("%s is not supported", sel.Select.StatementTag())
+	}
+	switch {
+	case clause.Distinct || clause.DistinctOn != nil:
+		return nil, unsupportedIncrementalViewErr("DISTINCT is not supported")
+	case clause.Having != nil:
+		return nil, unsupportedIncrementalViewErr("HAVING is not supported")
+	case clause.Window != nil:
+		return nil, unsupportedIncrementalViewErr("window functions are not supported")
+	case clause.From.AsOf.Expr != nil:
+		return nil, unsupportedIncrementalViewErr("AS OF SYSTEM TIME is not supported")
+	case len(clause.From.Tables) == 0:
+		return nil, unsupportedIncrementalViewErr("the view query must select from a table")
+	}
+
+	q := &incrementalViewQuery{sel: clause, countRowsOutput: -1}
+	for _, t := range clause.From.Tables {
+		if err := p.addIncrementalViewSources(ctx, q, t); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check the expressions of the query. Aggregate functions are only allowed
+	// at the top level of the select list.
+	checkExpr := func(expr tree.Expr, allowAggregate bool) (aggregate string, _ error) {
+		_, err := tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+			switch t := e.(type) {
+			case *tree.Subquery:
+				return false, e, unsupportedIncrementalViewErr("subqueries are not supported")
+			case *tree.FuncExpr:
+				if t.WindowDef != nil {
+					return false, e, unsupportedIncrementalViewErr("window functions are not supported")
+				}
+				def, err := t.Func.Resolve(ctx, tree.EmptySearchPath, nil /* resolver */)
+				if err != nil {
+					return false, e, err
+				}
+				class, err := def.GetClass()
+				if err != nil {
+					return false, e, err
+				}
+				if class != tree.AggregateClass {
+					return true, e, nil
+				}
+				if !allowAggregate || e != expr {
+					return false, e, unsupportedIncrementalViewErr(
+						"aggregate function %s must appear directly in the select list", def.Name)
+				}
+				aggregate = def.Name
+				return false, e, nil
+			}
+			return true, e, nil
+		})
+		return aggregate, err
+	}
+
+	q.outputs = make([]incrementalViewOutput, len(clause.Exprs))
+	for i := range clause.Exprs {
+		e := clause.Exprs[i].Expr
+		name, err := checkExpr(e, true /* allowAggregate */)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		q.aggregated = true
+		f := e.(*tree.FuncExpr)
+		if f.Type == tree.DistinctFuncType || f.Filter != nil || f.OrderBy != nil || len(f.Exprs) != 1 {
+			return nil, unsupportedIncrementalViewErr(
+				"aggregate function %s must have a single argument without DISTINCT, FILTER or ORDER BY",
+				tree.AsString(f))
+		}
+		out := &q.outputs[i]
+		out.arg = f.Exprs[0]
+		if _, err := checkExpr(out.arg, false /* allowAggregate */); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "count":
+			out.kind = incrementalViewCount
+			if _, ok := out.arg.(tree.UnqualifiedStar); ok {
+				out.kind = incrementalViewCountRows
+				out.arg = nil
+				q.countRowsOutput = i
+			}
+		case "sum":
+			out.kind = incrementalViewSum
+		case "min":
+			out.kind = incrementalViewMin
+		case "max":
+			out.kind = incrementalViewMax
+		default:
+			return nil, unsupportedIncrementalViewErr("aggregate function %s is not supported", name)
+		}
+	}
+	if clause.Where != nil {
+		if _, err := checkExpr(clause.Where.Expr, false /* allowAggregate */); err != nil {
+			return nil, err
+		}
+	}
+	for _, src := range clause.From.Tables {
+		if join, ok := src.(*tree.JoinTableExpr); ok {
+			if on, ok := join.Cond.(*tree.OnJoinCond); ok {
+				if _, err := checkExpr(on.Expr, false /* allowAggregate */); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if q.aggregated || len(clause.GroupBy) > 0 {
+		return q, p.analyzeIncrementalAggregation(q)
+	}
+	return q, p.analyzeIncrementalProjection(q)
+}
+
+// addIncrementalViewSources adds the base tables referenced by the given
+// table expression to the sources of the view query.
+func (p *planner) addIncrementalViewSources(
+	ctx context.Context, q *incrementalViewQuery, expr tree.TableExpr,
+) error {
+	switch t := expr.(type) {
+	case *tree.ParenTableExpr:
+		return p.addIncrementalViewSources(ctx, q, t.Expr)
+
+	case *tree.JoinTableExpr:
+		switch t.JoinType {
+		case "", tree.AstInner, tree.AstCross:
+		default:
+			return unsupportedIncrementalViewErr("%s JOIN is not supported", t.JoinType)
+		}
+		if err := p.addIncrementalViewSources(ctx, q, t.Left); err != nil {
+			return err
+		}
+		return p.addIncrementalViewSources(ctx, q, t.Right)
+
+	case *tree.AliasedTableExpr:
+		if t.Ordinality || t.Lateral || len(t.As.Cols) > 0 {
+			return unsupportedIncrementalViewErr("%s is not supported", tree.AsString(t))
+		}
+		tn, ok := t.Expr.(*tree.TableName)
+		if !ok {
+			return unsupportedIncrementalViewErr("%s is not supported as a data source", tree.AsString(t.Expr))
+		}
+		// Resolve a copy of the name, so that the name of the source is left as
+		// written in the query.
+		resolvedName := *tn
+		_, table, err := resolver.ResolveExistingTableObject(ctx, p, &resolvedName, tree.ObjectLookupFlags{
+			Required:             true,
+			DesiredObjectKind:    tree.TableObject,
+			DesiredTableDescKind: tree.ResolveRequireTableDesc,
+		})
+		if err != nil {
+			return err
+		}
+		if table.IsVirtualTable() {
+			return unsupportedIncrementalViewErr("virtual table %s is not supported", tn)
+		}
+		src := incrementalViewSource{table: table, name: *tn}
+		if t.As.Alias != "" {
+			src.name = tree.MakeUnqualifiedTableName(t.As.Alias)
+			src.aliased = true
+		}
+		idx := table.GetPrimaryIndex()
+		src.keyCols = make([]catalog.Column, idx.NumKeyColumns())
+		for i := range src.keyCols {
+			col, err := catalog.MustFindColumnByID(table, idx.GetKeyColumnID(i))
+			if err != nil {
+				return err
+			}
+			// The values of collated strings cannot be decoded from the keys of
+			// the changed rows.
+			if col.GetType().Family() == types.CollatedStringFamily {
+				return unsupportedIncrementalViewErr(
+					"primary key column %q of table %s has a collated string type", col.GetName(), tn)
+			}
+			src.keyCols[i] = col
+		}
+		q.sources = append(q.sources, src)
+		return nil
+
+	default:
+		return unsupportedIncrementalViewErr("%s is not supported as a data source", tree.AsString(expr))
+	}
+}
+
+// resolveColumn returns the source and the column that the
+// given expression refers to, if it is a column reference.
+func (q *incrementalViewQuery) resolveColumn(expr tree.Expr) (*incrementalViewSource, catalog.Column) {
+	name, ok := expr.(*tree.UnresolvedName)
+	if !ok || name.Star {
+		return nil, nil
+	}
+	v, err := name.NormalizeVarName()
+	if err != nil {
+		return nil, nil
+	}
+	item, ok := v.(*tree.ColumnItem)
+	if !ok {
+		return nil, nil
+	}
+	var res *incrementalViewSource
+	var resCol catalog.Column
+	for i := range q.sources {
+		src := &q.sources[i]
+		if item.TableName != nil {
+			tn := item.TableName.ToTableName()
+			if tn.ObjectName != src.name.ObjectName {
+				continue
+			}
+			if tn.ExplicitSchema && (src.aliased || tn.SchemaName != src.name.SchemaName) {
+				continue
+			}
+			if tn.ExplicitCatalog && tn.CatalogName != src.name.CatalogName {
+				continue
+			}
+		}
+		col := catalog.FindColumnByTreeName(src.table, item.ColumnName)
+		if col == nil {
+			continue
+		}
+		if res != nil {
+			// The reference is ambiguous.
+			return nil, nil
+		}
+		res, resCol = src, col
+	}
+	return res, resCol
+}
+
+// analyzeIncrementalProjection checks that the rows of a view without
+// aggregation can be identified by the primary keys of the base table rows
+// they are derived from.
+func (p *planner) analyzeIncrementalProjection(q *incrementalViewQuery) error {
+	for i := range q.sources {
+		src := &q.sources[i]
+		src.keyOutputs = make([]int, len(src.keyCols))
+		for j, col := range src.keyCols {
+			src.keyOutputs[j] = -1
+			for k := range q.sel.Exprs {
+				s, c := q.resolveColumn(q.sel.Exprs[k].Expr)
+				if s == src && c.GetID() == col.GetID() {
+					src.keyOutputs[j] = k
+					break
+				}
+			}
+			if src.keyOutputs[j] == -1 {
+				return unsupportedIncrementalViewErr(
+					"primary key column %q of %s must be included in the select list",
+					col.GetName(), tree.ErrString(&src.name))
+			}
+		}
+	}
+	return nil
+}
+
+// analyzeIncrementalAggregation checks that the groups of an aggregated view
+// can be identified by its columns.
+func (p *planner) analyzeIncrementalAggregation(q *incrementalViewQuery) error {
+	// Every grouping expression must be a column of the view.
+	for _, g := range q.sel.GroupBy {
+		ord := -1
+		if n, ok := g.(*tree.NumVal); ok {
+			if i, err := n.AsInt64(); err == nil && i >= 1 && int(i) <= len(q.sel.Exprs) {
+				ord = int(i) - 1
+			}
+		} else {
+			gStr := tree.AsStringWithFlags(g, tree.FmtParsable)
+			for i := range q.sel.Exprs {
+				e := &q.sel.Exprs[i]
+				if tree.AsStringWithFlags(e.Expr, tree.FmtParsable) == gStr ||
+					(e.As != "" && gStr == tree.AsStringWithFlags(&e.As, tree.FmtParsable)) {
+					ord = i
+					break
+				}
+			}
+		}
+		if ord == -1 || q.outputs[ord].kind != incrementalViewScalar {
+			return unsupportedIncrementalViewErr(
+				"grouping expression %s must be included in the select list", tree.AsString(g))
+		}
+		q.keyOutputs = append(q.keyOutputs, ord)
+	}
+	for i := range q.outputs {
+		if q.outputs[i].kind != incrementalViewScalar {
+			continue
+		}
+		found := false
+		for _, ord := range q.keyOutputs {
+			found = found || ord == i
+		}
+		if !found {
+			return unsupportedIncrementalViewErr(
+				"column %s must be an aggregate or appear in the GROUP BY clause",
+				tree.AsString(q.sel.Exprs[i].Expr))
+		}
+	}
+	if len(q.sel.GroupBy) > 0 && q.countRowsOutput == -1 {
+		// COUNT(*) determines when a group becomes empty.
+		return unsupportedIncrementalViewErr("count(*) must be included in the select list")
+	}
+	return nil
+}
+
+// addKeyIndex adds an index to the descriptor of a new incrementally refreshed
+// view on the columns which identify the rows of the view affected by changes
+// to the base tables, so that a refresh does not need to scan the view.
+func (q *incrementalViewQuery) addKeyIndex(desc *tabledesc.Mutable) error {
+	var ords []int
+	if q.aggregated {
+		ords = q.keyOutputs
+	} else {
+		seen := make(map[int]struct{})
+		for _, src := range q.sources {
+			for _, ord := range src.keyOutputs {
+				if _, ok := seen[ord]; !ok {
+					seen[ord] = struct{}{}
+					ords = append(ords, ord)
+				}
+			}
+		}
+	}
+	if len(ords) == 0 {
+		return nil
+	}
+	idx := descpb.IndexDescriptor{
+		Version: descpb.StrictIndexColumnIDGuaranteesVersion,
+	}
+	for _, ord := range ords {
+		col := &desc.Columns[ord]
+		if !colinfo.ColumnTypeIsIndexable(col.Type) {
+			return nil
+		}
+		idx.KeyColumnNames = append(idx.KeyColumnNames, col.Name)
+		idx.KeyColumnDirections = append(idx.KeyColumnDirections, catenumpb.IndexColumn_ASC)
+	}
+	var err error
+	if idx.Name, err = tabledesc.BuildIndexName(desc, &idx); err != nil {
+		return err
+	}
+	return desc.AddSecondaryIndex(idx)
+}
+
+// errIncrementalRefreshUnavailable is returned if the changes to a base table
+// of an incrementally refreshed view cannot be determined.
+var errIncrementalRefreshUnavailable = errors.New("changes to the base tables are not available")
+
+// incrementalViewBatchSize is the maximum number of changed rows of a base
+// table which are looked up by a single query during an incremental refresh.
+var incrementalViewBatchSize = util.ConstantWithMetamorphicTestRange(
+	"incremental-view-batch-size",
+	1000, /* defaultValue */
+	1,    /* min */
+	1000, /* max */
+)
+
+// changedKeys are the primary keys of the changed rows of a base table.
+type changedKeys struct {
+	pks []tree.Datums
+	// set contains the encodings of the primary keys. See encodeDatumsKey.
+	set map[string]struct{}
+}
+
+// add adds a primary key, accounting for its memory usage with acc.
+func (c *changedKeys) add(ctx context.Context, acc *mon.BoundAccount, pk tree.Datums) error {
+	k, err := encodeDatumsKey(pk)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.set[k]; ok {
+		return nil
+	}
+	size := int64(len(k)) + int64(cap(pk))*memsize.DatumOverhead
+	for _, d := range pk {
+		size += int64(d.Size())
+	}
+	if err := acc.Grow(ctx, size); err != nil {
+		return err
+	}
+	if c.set == nil {
+		c.set = make(map[string]struct{})
+	}
+	c.set[k] = struct{}{}
+	c.pks = append(c.pks, pk)
+	return nil
+}
+
+// contains returns whether the given primary key is one of the changed keys.
+func (c *changedKeys) contains(pk tree.Datums) (bool, error) {
+	k, err := encodeDatumsKey(pk)
+	if err != nil {
+		return false, err
+	}
+	_, ok := c.set[k]
+	return ok, nil
+}
+
+// forEachBatch calls fn with consecutive batches of at most
+// incrementalViewBatchSize of the changed keys.
+func (c *changedKeys) forEachBatch(fn func(pks []tree.Datums) error) error {
+	for start := 0; start < len(c.pks); start += incrementalViewBatchSize {
+		end := start + incrementalViewBatchSize
+		if end > len(c.pks) {
+			end = len(c.pks)
+		}
+		if err := fn(c.pks[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// primaryIndexReplacedSince returns whether the primary index of the given
+// table was replaced after the given timestamp.
+func (p *planner) primaryIndexReplacedSince(
+	ctx context.Context, table catalog.TableDescriptor, ts hlc.Timestamp,
+) (bool, error) {
+	// Most changes to the table descriptor, such as the addition of the
+	// back-references of views, don't replace the primary index, so the
+	// descriptor as of the given timestamp is only read if the table was
+	// modified since.
+	if !ts.Less(table.GetModificationTime()) {
+		return false, nil
+	}
+	var prevIndexID descpb.IndexID
+	if err := DescsTxn(ctx, p.ExecCfg(), func(
+		ctx context.Context, txn isql.Txn, col *descs.Collection,
+	) error {
+		if err := txn.KV().SetFixedTimestamp(ctx, ts); err != nil {
+			return err
+		}
+		prev, err := col.ByID(txn.KV()).Get().Table(ctx, table.GetID())
+		if err != nil {
+			return err
+		}
+		prevIndexID = prev.GetPrimaryIndexID()
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	return prevIndexID != table.GetPrimaryIndexID(), nil
+}
+
+// changedPrimaryKeys returns the primary keys of the rows of the given table
+// which were written in the time interval (start, end]. The memory used by the
+// keys is accounted for with acc.
+func (p *planner) changedPrimaryKeys(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	start, end hlc.Timestamp,
+	acc *mon.BoundAccount,
+) (*changedKeys, error) {
+	codec := p.ExecCfg().Codec
+	idx := table.GetPrimaryIndex()
+	keyTypes := make([]*types.T, idx.NumKeyColumns())
+	for i := range keyTypes {
+		col, err := catalog.MustFindColumnByID(table, idx.GetKeyColumnID(i))
+		if err != nil {
+			return nil, err
+		}
+		keyTypes[i] = col.GetType()
+	}
+	dirs := idx.IndexDesc().KeyColumnDirections
+
+	res := &changedKeys{}
+	// The versions of a row are consecutive, so only the last row key needs to
+	// be remembered to skip them.
+	var lastRowKey roachpb.Key
+	var alloc tree.DatumAlloc
+	addKey := func(key roachpb.Key) error {
+		n, err := keys.GetRowPrefixLength(key)
+		if err != nil {
+			return err
+		}
+		rowKey := key[:n]
+		if rowKey.Equal(lastRowKey) {
+			return nil
+		}
+		lastRowKey = append(lastRowKey[:0], rowKey...)
+		vals := make([]rowenc.EncDatum, len(keyTypes))
+		if _, _, err := rowenc.DecodeIndexKey(codec, keyTypes, vals, dirs, rowKey); err != nil {
+			return err
+		}
+		datums := make(tree.Datums, len(vals))
+		for i := range vals {
+			if err := vals[i].EnsureDecoded(keyTypes[i], &alloc); err != nil {
+				return err
+			}
+			datums[i] = vals[i].Datum
+		}
+		return res.add(ctx, acc, datums)
+	}
+
+	span := table.IndexSpan(codec, idx.GetID())
+	for span.Key != nil {
+		header := kvpb.Header{
+			Timestamp:                   end,
+			ReturnElasticCPUResumeSpans: true,
+		}
+		req := &kvpb.ExportRequest{
+			RequestHeader: kvpb.RequestHeaderFromSpan(span),
+			StartTime:     start,
+			MVCCFilter:    kvpb.MVCCFilter_All,
+		}
+		resp, pErr := kv.SendWrappedWith(ctx, p.ExecCfg().DB.NonTransactionalSender(), header, req)
+		if pErr != nil {
+			err := pErr.GoError()
+			if errors.HasType(err, (*kvpb.BatchTimestampBeforeGCError)(nil)) {
+				return nil, errors.Mark(err, errIncrementalRefreshUnavailable)
+			}
+			return nil, err
+		}
+		exportResp := resp.(*kvpb.ExportResponse)
+		for _, file := range exportResp.Files {
+			if err := func() error {
+				iter, err := storage.NewMemSSTIterator(file.SST, false /* verify */, storage.IterOptions{
+					KeyTypes:   storage.IterKeyTypePointsAndRanges,
+					LowerBound: file.Span.Key,
+					UpperBound: file.Span.EndKey,
+				})
+				if err != nil {
+					return err
+				}
+				defer iter.Close()
+				for iter.SeekGE(storage.MVCCKey{Key: file.Span.Key}); ; iter.Next() {
+					if ok, err := iter.Valid(); err != nil || !ok {
+						return err
+					}
+					hasPoint, hasRange := iter.HasPointAndRange()
+					if hasRange {
+						// MVCC range tombstones delete spans of rows without
+						// identifying them.
+						return errors.Mark(
+							errors.Newf("table %q has MVCC range tombstones", table.GetName()),
+							errIncrementalRefreshUnavailable,
+						)
+					}
+					if hasPoint {
+						if err := addKey(iter.UnsafeKey().Key); err != nil {
+							return err
+						}
+					}
+				}
+			}(); err != nil {
+				return nil, err
+			}
+		}
+		span = roachpb.Span{}
+		if exportResp.ResumeSpan != nil {
+			span = *exportResp.ResumeSpan
+		}
+	}
+	return res, nil
+}
+
+// keyColumnItems returns references to the primary key columns of the source.
+func (src *incrementalViewSource) keyColumnItems() tree.Exprs {
+	cols := make(tree.Exprs, len(src.keyCols))
+	for i, col := range src.keyCols {
+		cols[i] = tree.NewColumnItem(&src.name, col.ColName())
+	}
+	return cols
+}
+
+// keysInExpr returns an expression which is true for the rows of the source
+// with the given primary keys.
+func (src *incrementalViewSource) keysInExpr(pks []tree.Datums) tree.Expr {
+	return keysInExpr(src.keyColumnItems(), pks)
+}
+
+// keysInExpr returns an expression which is true if the given columns are
+// equal to one of the given tuples of values.
+func keysInExpr(cols tree.Exprs, vals []tree.Datums) tree.Expr {
+	tuples := make(tree.Exprs, len(vals))
+	for i, v := range vals {
+		t := &tree.Tuple{Exprs: make(tree.Exprs, len(v))}
+		for j := range v {
+			t.Exprs[j] = v[j]
+		}
+		tuples[i] = t
+	}
+	return &tree.ComparisonExpr{
+		Operator: treecmp.MakeComparisonOperator(treecmp.In),
+		Left:     &tree.Tuple{Exprs: cols},
+		Right:    &tree.Tuple{Exprs: tuples},
+	}
+}
+
+// notDistinctExpr returns an expression which is true if the given
+// expressions are not distinct from the given values.
+func notDistinctExpr(exprs tree.Exprs, vals tree.Datums) tree.Expr {
+	var res tree.Expr = tree.DBoolTrue
+	for i := range exprs {
+		cmp := &tree.ComparisonExpr{
+			Operator: treecmp.MakeComparisonOperator(treecmp.IsNotDistinctFrom),
+			Left:     &tree.ParenExpr{Expr: exprs[i]},
+			Right:    vals[i],
+		}
+		if i == 0 {
+			res = cmp
+		} else {
+			res = &tree.AndExpr{Left: res, Right: cmp}
+		}
+	}
+	return res
+}
+
+// changedInEarlierSource returns whether a row produced by restricting the
+// i-th source of the view query to its changed rows is also derived from a
+// changed row of one of the sources before it. Such rows are only taken into
+// account for the first of these sources, so that the results for all sources
+// can be combined without double counting. pkOf returns the primary key of the
+// row of the j-th source the row is derived from.
+func (r *incrementalViewRefresher) changedInEarlierSource(
+	i int, pkOf func(j int) tree.Datums,
+) (bool, error) {
+	for j := 0; j < i; j++ {
+		if len(r.changes[j].pks) == 0 {
+			continue
+		}
+		if changed, err := r.changes[j].contains(pkOf(j)); changed || err != nil {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// deltaQuery returns the view query with the given select list, grouping and
+// additional filter, evaluated as of the given timestamp.
+func (q *incrementalViewQuery) deltaQuery(
+	exprs tree.SelectExprs, groupBy tree.GroupBy, filter tree.Expr, asOf hlc.Timestamp,
+) string {
+	sel := *q.sel
+	sel.Exprs = exprs
+	sel.GroupBy = groupBy
+	sel.From.AsOf = tree.AsOfClause{Expr: tree.NewStrVal(asOf.AsOfSystemTime())}
+	if filter != nil {
+		if q.sel.Where != nil {
+			filter = &tree.AndExpr{Left: &tree.ParenExpr{Expr: q.sel.Where.Expr}, Right: filter}
+		}
+		sel.Where = tree.NewWhere(tree.AstWhere, filter)
+	}
+	return tree.AsStringWithFlags(&sel, tree.FmtParsable)
+}
+
+// incrementalViewRefresher computes and applies the changes to the rows of
+// an incrementally refreshed materialized view.
+type incrementalViewRefresher struct {
+	p    *planner
+	desc *tabledesc.Mutable
+	q    *incrementalViewQuery
+
+	// lastRefresh and asOf delimit the changes to the base tables.
+	lastRefresh, asOf hlc.Timestamp
+	// changes are the primary keys of the changed rows of each source. Their
+	// memory usage is accounted for with acc.
+	changes []*changedKeys
+	acc     mon.BoundAccount
+
+	// cols are the columns of the view, in the order in which rows are
+	// passed to the row writers. rowIDOrd is the ordinal of the hidden rowid
+	// column, and outputOrds maps the columns of the view query to their
+	// ordinals.
+	cols       []catalog.Column
+	rowIDOrd   int
+	outputOrds []int
+
+	deletes, inserts []tree.Datums
+}
+
+// refreshMaterializedViewIncrementally refreshes the given materialized view by
+// applying the changes to its base tables since its last refresh. It returns
+// false if the view cannot be refreshed incrementally, in which case it must
+// be recomputed.
+func (p *planner) refreshMaterializedViewIncrementally(
+	ctx context.Context, desc *tabledesc.Mutable,
+) (bool, error) {
+	r := incrementalViewRefresher{
+		p:           p,
+		desc:        desc,
+		lastRefresh: desc.IncrementalRefresh.LastRefresh,
+		asOf:        p.Txn().ReadTimestamp(),
+		acc:         p.Mon().MakeBoundAccount(),
+	}
+	defer r.acc.Close(ctx)
+	if r.lastRefresh.IsEmpty() {
+		return false, nil
+	}
+	var err error
+	if r.q, err = p.analyzeIncrementalView(ctx, desc.GetViewQuery()); err != nil {
+		// The base tables may have been altered so that the view query is no
+		// longer supported, in which case the view is recomputed.
+		if pgerror.GetPGCode(err) == pgcode.FeatureNotSupported {
+			return false, nil
+		}
+		return false, err
+	}
+	if !r.initColumns() {
+		return false, nil
+	}
+	ok, err := r.computeChanges(ctx)
+	if err != nil {
+		// The view is recomputed if the changes are not available, or if there
+		// are too many of them to buffer their primary keys.
+		if errors.Is(err, errIncrementalRefreshUnavailable) ||
+			errors.HasType(err, (*kvpb.BatchTimestampBeforeGCError)(nil)) ||
+			sqlerrors.IsOutOfMemoryError(err) {
+			p.BufferClientNotice(ctx, pgnotice.Newf(
+				"cannot refresh materialized view %q incrementally: %v; recomputing the view",
+				desc.GetName(), err,
+			))
+			return false, nil
+		}
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+	if err := r.write(ctx); err != nil {
+		return false, err
+	}
+	desc.IncrementalRefresh.LastRefresh = r.asOf
+	return true, p.writeTableDesc(ctx, desc)
+}
+
+// initColumns determines the layout of the rows of the view. It returns false
+// if the view has columns or indexes which the refresher does not maintain.
+func (r *incrementalViewRefresher) initColumns() bool {
+	for _, idx := range r.desc.PublicNonPrimaryIndexes() {
+		if idx.IsPartial() {
+			return false
+		}
+	}
+	pk := r.desc.GetPrimaryIndex()
+	if pk.NumKeyColumns() != 1 {
+		return false
+	}
+	r.cols = r.desc.PublicColumns()
+	r.rowIDOrd = -1
+	for i, col := range r.cols {
+		if col.GetID() == pk.GetKeyColumnID(0) {
+			r.rowIDOrd = i
+		} else if col.IsHidden() || col.IsComputed() {
+			return false
+		} else {
+			r.outputOrds = append(r.outputOrds, i)
+		}
+	}
+	return r.rowIDOrd != -1 && len(r.outputOrds) == len(r.q.outputs)
+}
+
+// computeChanges collects the changes to the base tables and computes the rows
+// to delete from and insert into the view. It returns false if the view must
+// be recomputed.
+func (r *incrementalViewRefresher) computeChanges(ctx context.Context) (bool, error) {
+	r.changes = make([]*changedKeys, len(r.q.sources))
+	changesByID := make(map[int64]*changedKeys)
+	for i, src := range r.q.sources {
+		id := int64(src.table.GetID())
+		pks, ok := changesByID[id]
+		if !ok {
+			// A schema change to a base table or TRUNCATE can replace its
+			// primary index, in which case the changes made before are not
+			// visible in the MVCC history of the new primary index.
+			replaced, err := r.p.primaryIndexReplacedSince(ctx, src.table, r.lastRefresh)
+			if err != nil || replaced {
+				return false, err
+			}
+			if pks, err = r.p.changedPrimaryKeys(ctx, src.table, r.lastRefresh, r.asOf, &r.acc); err != nil {
+				return false, err
+			}
+			changesByID[id] = pks
+		}
+		r.changes[i] = pks
+	}
+	if r.q.aggregated {
+		return r.computeAggregateChanges(ctx)
+	}
+	return true, r.computeProjectionChanges(ctx)
+}
+
+// queryView returns the rows of the view which satisfy the given filter, in
+// the layout of the row writers.
+func (r *incrementalViewRefresher) queryView(
+	ctx context.Context, filter tree.Expr,
+) ([]tree.Datums, error) {
+	exprs := make(tree.SelectExprs, len(r.cols))
+	for i, col := range r.cols {
+		exprs[i].Expr = tree.NewUnresolvedName(col.GetName())
+	}
+	sel := &tree.SelectClause{
+		Exprs: exprs,
+		From: tree.From{Tables: tree.TableExprs{&tree.TableRef{
+			TableID: int64(r.desc.GetID()),
+			As:      tree.AliasClause{Alias: "v"},
+		}}},
+	}
+	if filter != nil {
+		sel.Where = tree.NewWhere(tree.AstWhere, filter)
+	}
+	return r.p.QueryBufferedEx(
+		ctx, "refresh-view-read", sessiondata.RootUserSessionDataOverride,
+		tree.AsStringWithFlags(sel, tree.FmtParsable),
+	)
+}
+
+// queryBaseTables evaluates the given query over the base tables of the view,
+// which reads them at a fixed timestamp.
+func (r *incrementalViewRefresher) queryBaseTables(
+	ctx context.Context, query string,
+) ([]tree.Datums, error) {
+	return r.p.ExecCfg().InternalDB.Executor().QueryBufferedEx(
+		ctx, "refresh-view-delta", nil /* txn */, sessiondata.RootUserSessionDataOverride, query,
+	)
+}
+
+// viewColumn returns a reference to the column of the view with the given
+// output ordinal.
+func (r *incrementalViewRefresher) viewColumn(ord int) tree.Expr {
+	return tree.NewUnresolvedName(r.cols[r.outputOrds[ord]].GetName())
+}
+
+// newRow returns a row of the view with the given output values and a new
+// rowid.
+func (r *incrementalViewRefresher) newRow(outputs tree.Datums) tree.Datums {
+	res := make(tree.Datums, len(r.cols))
+	for i, ord := range r.outputOrds {
+		res[ord] = outputs[i]
+	}
+	res[r.rowIDOrd] = tree.NewDInt(builtins.GenerateUniqueInt(
+		builtins.ProcessUniqueID(r.p.EvalContext().NodeID.SQLInstanceID()),
+	))
+	return res
+}
+
+// computeProjectionChanges replaces the rows of a view without aggregation
+// which are derived from changed rows of the base tables.
+func (r *incrementalViewRefresher) computeProjectionChanges(ctx context.Context) error {
+	deleted := make(map[tree.DInt]struct{})
+	for i := range r.q.sources {
+		src := &r.q.sources[i]
+		cols := make(tree.Exprs, len(src.keyOutputs))
+		for j, ord := range src.keyOutputs {
+			cols[j] = r.viewColumn(ord)
+		}
+		if err := r.changes[i].forEachBatch(func(pks []tree.Datums) error {
+			rows, err := r.queryView(ctx, keysInExpr(cols, pks))
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				rowID := tree.MustBeDInt(row[r.rowIDOrd])
+				if _, ok := deleted[rowID]; !ok {
+					deleted[rowID] = struct{}{}
+					r.deletes = append(r.deletes, row)
+				}
+			}
+
+			rows, err = r.queryBaseTables(ctx, r.q.deltaQuery(
+				r.q.sel.Exprs, nil /* groupBy */, src.keysInExpr(pks), r.asOf,
+			))
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				skip, err := r.changedInEarlierSource(i, func(j int) tree.Datums {
+					keyOutputs := r.q.sources[j].keyOutputs
+					pk := make(tree.Datums, len(keyOutputs))
+					for k, ord := range keyOutputs {
+						pk[k] = row[ord]
+					}
+					return pk
+				})
+				if err != nil {
+					return err
+				}
+				if !skip {
+					r.inserts = append(r.inserts, r.newRow(row))
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// incrementalViewGroup accumulates the contributions of the changed rows to a
+// group of an aggregated view, before and after the changes.
+type incrementalViewGroup struct {
+	key           tree.Datums
+	before, after tree.Datums
+}
+
+// aggregateDeltaExprs returns the select list of the queries computing the
+// contributions of changed rows to the groups of an aggregated view: the
+// grouping columns, followed by COUNT(*), followed by the partial aggregates
+// of each aggregate column. SUM columns have an additional COUNT of the
+// non-NULL values, which determines whether the sum is NULL.
+func (q *incrementalViewQuery) aggregateDeltaExprs() tree.SelectExprs {
+	var exprs tree.SelectExprs
+	for _, ord := range q.keyOutputs {
+		exprs = append(exprs, tree.SelectExpr{Expr: q.sel.Exprs[ord].Expr})
+	}
+	agg := func(name string, arg tree.Expr) {
+		exprs = append(exprs, tree.SelectExpr{Expr: &tree.FuncExpr{
+			Func:  tree.WrapFunction(name),
+			Exprs: tree.Exprs{arg},
+		}})
+	}
+	agg("count", tree.UnqualifiedStar{})
+	for i := range q.outputs {
+		out := &q.outputs[i]
+		out.partial = len(exprs)
+		switch out.kind {
+		case incrementalViewCount:
+			agg("count", out.arg)
+		case incrementalViewSum:
+			agg("sum", out.arg)
+			agg("count", out.arg)
+		case incrementalViewMin:
+			agg("min", out.arg)
+		case incrementalViewMax:
+			agg("max", out.arg)
+		}
+	}
+	return exprs
+}
+
+// encodeDatumsKey encodes the given values, e.g. the values of the grouping
+// columns of a group, so that equal values have equal encodings.
+func encodeDatumsKey(key tree.Datums) (string, error) {
+	var buf []byte
+	for _, d := range key {
+		var err error
+		if buf, err = keyside.Encode(buf, d, encoding.Ascending); err != nil {
+			return "", err
+		}
+	}
+	return string(buf), nil
+}
+
+// computeAggregateChanges updates the groups of an aggregated view which are
+// affected by changed rows of the base tables.
+func (r *incrementalViewRefresher) computeAggregateChanges(ctx context.Context) (bool, error) {
+	q := r.q
+	exprs := q.aggregateDeltaExprs()
+	var groupBy tree.GroupBy
+	for _, ord := range q.keyOutputs {
+		groupBy = append(groupBy, q.sel.Exprs[ord].Expr)
+	}
+	numKeys := len(q.keyOutputs)
+	numPartials := len(exprs) - numKeys
+
+	// Compute the contributions of the changed rows before and after the
+	// changes.
+	groups := make(map[string]*incrementalViewGroup)
+	var order []string
+	for i := range q.sources {
+		if len(r.changes[i].pks) == 0 {
+			continue
+		}
+		// The contributions are also grouped by the primary keys of the
+		// sources before the i-th one, which are returned after the partial
+		// aggregates, so that contributions of rows which were already taken
+		// into account for these sources can be skipped.
+		deltaExprs, deltaGroupBy := exprs, groupBy
+		pkOrds := make([][]int, i)
+		for j := 0; j < i; j++ {
+			for _, col := range q.sources[j].keyColumnItems() {
+				pkOrds[j] = append(pkOrds[j], len(deltaExprs))
+				deltaExprs = append(deltaExprs[:len(deltaExprs):len(deltaExprs)], tree.SelectExpr{Expr: col})
+				deltaGroupBy = append(deltaGroupBy[:len(deltaGroupBy):len(deltaGroupBy)], col)
+			}
+		}
+		if err := r.changes[i].forEachBatch(func(pks []tree.Datums) error {
+			filter := q.sources[i].keysInExpr(pks)
+			for _, isNew := range []bool{false, true} {
+				ts := r.lastRefresh
+				if isNew {
+					ts = r.asOf
+				}
+				rows, err := r.queryBaseTables(ctx, q.deltaQuery(deltaExprs, deltaGroupBy, filter, ts))
+				if err != nil {
+					return err
+				}
+				for _, row := range rows {
+					// A scalar aggregation produces a row even without input rows.
+					if tree.MustBeDInt(row[numKeys]) == 0 {
+						continue
+					}
+					skip, err := r.changedInEarlierSource(i, func(j int) tree.Datums {
+						pk := make(tree.Datums, len(pkOrds[j]))
+						for k, ord := range pkOrds[j] {
+							pk[k] = row[ord]
+						}
+						return pk
+					})
+					if err != nil {
+						return err
+					}
+					if skip {
+						continue
+					}
+					k, err := encodeDatumsKey(row[:numKeys])
+					if err != nil {
+						return err
+					}
+					g, ok := groups[k]
+					if !ok {
+						g = &incrementalViewGroup{key: row[:numKeys]}
+						groups[k] = g
+						order = append(order, k)
+					}
+					partials := &g.before
+					if isNew {
+						partials = &g.after
+					}
+					if *partials == nil {
+						*partials = row[numKeys : numKeys+numPartials]
+						continue
+					}
+					if err := r.mergePartials(ctx, *partials, row[numKeys:numKeys+numPartials]); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return false, err
+		}
+	}
+	if len(groups) == 0 {
+		return true, nil
+	}
+
+	// Read the current rows of the affected groups.
+	current := make(map[string]tree.Datums, len(groups))
+	keyCols := make(tree.Exprs, numKeys)
+	for i, ord := range q.keyOutputs {
+		keyCols[i] = r.viewColumn(ord)
+	}
+	const groupBatchSize = 100
+	for start := 0; start < len(order); start += groupBatchSize {
+		var filter tree.Expr
+		if numKeys > 0 {
+			end := start + groupBatchSize
+			if end > len(order) {
+				end = len(order)
+			}
+			for _, k := range order[start:end] {
+				e := notDistinctExpr(keyCols, groups[k].key)
+				if filter == nil {
+					filter = e
+				} else {
+					filter = &tree.OrExpr{Left: filter, Right: e}
+				}
+			}
+		}
+		rows, err := r.queryView(ctx, filter)
+		if err != nil {
+			return false, err
+		}
+		for _, row := range rows {
+			key := make(tree.Datums, numKeys)
+			for i, ord := range q.keyOutputs {
+				key[i] = row[r.outputOrds[ord]]
+			}
+			k, err := encodeDatumsKey(key)
+			if err != nil {
+				return false, err
+			}
+			current[k] = row
+		}
+	}
+
+	for _, k := range order {
+		g := groups[k]
+		cur := current[k]
+		outputs, ok, err := r.updateGroup(ctx, g, cur)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// The group must be recomputed.
+			if outputs, err = r.recomputeGroup(ctx, g); err != nil {
+				return false, err
+			}
+		}
+		if cur != nil {
+			r.deletes = append(r.deletes, cur)
+		}
+		if outputs != nil {
+			row := r.newRow(outputs)
+			if cur != nil {
+				row[r.rowIDOrd] = cur[r.rowIDOrd]
+			}
+			r.inserts = append(r.inserts, row)
+		}
+	}
+	return true, nil
+}
+
+// binaryOp evaluates the given binary operator on two values of the same
+// type.
+func (r *incrementalViewRefresher) binaryOp(
+	ctx context.Context, op treebin.BinaryOperatorSymbol, left, right tree.Datum,
+) (tree.Datum, error) {
+	o, ok := tree.BinOps[op].LookupImpl(left.ResolvedType(), right.ResolvedType())
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			"no %s operator for %s", op, left.ResolvedType().SQLString())
+	}
+	return eval.BinaryOp(ctx, r.p.EvalContext(), o.EvalOp, left, right)
+}
+
+// mergePartials adds the contributions of src to dst.
+func (r *incrementalViewRefresher) mergePartials(
+	ctx context.Context, dst, src tree.Datums,
+) error {
+	numKeys := len(r.q.keyOutputs)
+	dst[0] = tree.NewDInt(tree.MustBeDInt(dst[0]) + tree.MustBeDInt(src[0]))
+	for i := range r.q.outputs {
+		out := &r.q.outputs[i]
+		j := out.partial - numKeys
+		switch out.kind {
+		case incrementalViewCount:
+			dst[j] = tree.NewDInt(tree.MustBeDInt(dst[j]) + tree.MustBeDInt(src[j]))
+		case incrementalViewSum:
+			if dst[j] == tree.DNull {
+				dst[j] = src[j]
+			} else if src[j] != tree.DNull {
+				sum, err := r.binaryOp(ctx, treebin.Plus, dst[j], src[j])
+				if err != nil {
+					return err
+				}
+				dst[j] = sum
+			}
+			dst[j+1] = tree.NewDInt(tree.MustBeDInt(dst[j+1]) + tree.MustBeDInt(src[j+1]))
+		case incrementalViewMin, incrementalViewMax:
+			if dst[j] == tree.DNull {
+				dst[j] = src[j]
+			} else if src[j] != tree.DNull {
+				c := src[j].Compare(r.p.EvalContext(), dst[j])
+				if (out.kind == incrementalViewMin && c < 0) || (out.kind == incrementalViewMax && c > 0) {
+					dst[j] = src[j]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// updateGroup computes the new values of the columns of a group from its
+// current row and the contributions of the changed rows. It returns nil
+// outputs if the group no longer exists, and false if the new values cannot be
+// derived from the current values, e.g. if a row with the minimum value of a
+// MIN column was deleted.
+func (r *incrementalViewRefresher) updateGroup(
+	ctx context.Context, g *incrementalViewGroup, cur tree.Datums,
+) (_ tree.Datums, ok bool, _ error) {
+	q := r.q
+	numKeys := len(q.keyOutputs)
+	before, after := g.before, g.after
+	partial := func(partials tree.Datums, ord int) tree.Datum {
+		if partials == nil {
+			if q.outputs[ord].kind == incrementalViewCount ||
+				q.outputs[ord].kind == incrementalViewSum {
+				return tree.DZero
+			}
+			return tree.DNull
+		}
+		return partials[q.outputs[ord].partial-numKeys]
+	}
+	count := func(partials tree.Datums, j int) tree.DInt {
+		if partials == nil {
+			return 0
+		}
+		return tree.MustBeDInt(partials[j])
+	}
+	curValue := func(ord int) tree.Datum {
+		if cur == nil {
+			return tree.DNull
+		}
+		return cur[r.outputOrds[ord]]
+	}
+
+	if cur == nil && (numKeys == 0 || before != nil) {
+		// The view is missing a row for a group which existed before the
+		// changes.
+		return nil, false, nil
+	}
+	var total tree.DInt
+	if q.countRowsOutput != -1 {
+		if c := curValue(q.countRowsOutput); c != tree.DNull {
+			total = tree.MustBeDInt(c)
+		}
+	}
+	total += count(after, 0) - count(before, 0)
+	if numKeys > 0 && total <= 0 {
+		if total < 0 {
+			return nil, false, nil
+		}
+		return nil, true, nil
+	}
+
+	outputs := make(tree.Datums, len(q.outputs))
+	for i := range q.outputs {
+		out := &q.outputs[i]
+		cv := curValue(i)
+		switch out.kind {
+		case incrementalViewScalar:
+			for k, ord := range q.keyOutputs {
+				if ord == i {
+					outputs[i] = g.key[k]
+				}
+			}
+
+		case incrementalViewCountRows:
+			outputs[i] = tree.NewDInt(total)
+
+		case incrementalViewCount:
+			c := tree.DInt(0)
+			if cv != tree.DNull {
+				c = tree.MustBeDInt(cv)
+			}
+			outputs[i] = tree.NewDInt(c + tree.MustBeDInt(partial(after, i)) - tree.MustBeDInt(partial(before, i)))
+
+		case incrementalViewSum:
+			j := out.partial - numKeys
+			oldCount, newCount := count(before, j+1), count(after, j+1)
+			res := cv
+			if oldCount > 0 {
+				if res == tree.DNull {
+					return nil, false, nil
+				}
+				var err error
+				if res, err = r.binaryOp(ctx, treebin.Minus, res, before[j]); err != nil {
+					return nil, false, err
+				}
+				if newCount == 0 {
+					// The sum is NULL if no non-NULL values remain, but a zero
+					// sum does not tell whether any remain.
+					zero, err := r.binaryOp(ctx, treebin.Minus, before[j], before[j])
+					if err != nil {
+						return nil, false, err
+					}
+					if res.Compare(r.p.EvalContext(), zero) == 0 {
+						return nil, false, nil
+					}
+				}
+			}
+			if newCount > 0 {
+				if res == tree.DNull {
+					res = after[j]
+				} else {
+					var err error
+					if res, err = r.binaryOp(ctx, treebin.Plus, res, after[j]); err != nil {
+						return nil, false, err
+					}
+				}
+			}
+			outputs[i] = res
+
+		case incrementalViewMin, incrementalViewMax:
+			oldVal, newVal := partial(before, i), partial(after, i)
+			if oldVal != tree.DNull {
+				// If a changed row had the current minimum (or maximum) value,
+				// the new value cannot be determined from the current one.
+				if cv == tree.DNull {
+					return nil, false, nil
+				}
+				c := oldVal.Compare(r.p.EvalContext(), cv)
+				if (out.kind == incrementalViewMin && c <= 0) || (out.kind == incrementalViewMax && c >= 0) {
+					return nil, false, nil
+				}
+			}
+			res := cv
+			if newVal != tree.DNull {
+				if res == tree.DNull {
+					res = newVal
+				} else {
+					c := newVal.Compare(r.p.EvalContext(), res)
+					if (out.kind == incrementalViewMin && c < 0) || (out.kind == incrementalViewMax && c > 0) {
+						res = newVal
+					}
+				}
+			}
+			outputs[i] = res
+		}
+	}
+	return outputs, true, nil
+}
+
+// recomputeGroup evaluates the view query for a single group. It returns nil
+// if the group no longer exists.
+func (r *incrementalViewRefresher) recomputeGroup(
+	ctx context.Context, g *incrementalViewGroup,
+) (tree.Datums, error) {
+	var filter tree.Expr
+	if len(r.q.keyOutputs) > 0 {
+		exprs := make(tree.Exprs, len(r.q.keyOutputs))
+		for i, ord := range r.q.keyOutputs {
+			exprs[i] = r.q.sel.Exprs[ord].Expr
+		}
+		filter = notDistinctExpr(exprs, g.key)
+	}
+	rows, err := r.queryBaseTables(ctx, r.q.deltaQuery(r.q.sel.Exprs, r.q.sel.GroupBy, filter, r.asOf))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// write applies the computed changes to the rows of the view, in the
+// transaction of the planner.
+func (r *incrementalViewRefresher) write(ctx context.Context) error {
+	p := r.p
+	table := r.desc.ImmutableCopy().(catalog.TableDescriptor)
+	sv := &p.ExecCfg().Settings.SV
+	internal := p.SessionData().Internal
+	traceKV := p.ExtendedEvalContext().Tracing.KVTracingEnabled()
+
+	writeRows := func(tw tableWriter, base *tableWriterBase, rows []tree.Datums) error {
+		defer tw.close(ctx)
+		if err := tw.init(ctx, p.txn, p.EvalContext(), sv); err != nil {
+			return err
+		}
+		for _, values := range rows {
+			if base.currentBatchSize >= base.maxBatchSize ||
+				base.b.ApproximateMutationBytes() >= base.maxBatchByteSize {
+				if err := tw.flushAndStartNewBatch(ctx); err != nil {
+					return err
+				}
+			}
+			var pm row.PartialIndexUpdateHelper
+			if err := tw.row(ctx, values, pm, traceKV); err != nil {
+				return err
+			}
+		}
+		return tw.finalize(ctx)
+	}
+
+	// The deletes are applied before the inserts, since updated groups of an
+	// aggregated view keep their rowid.
+	if len(r.deletes) > 0 {
+		td := &tableDeleter{
+			rd: row.MakeDeleter(
+				p.ExecCfg().Codec, table, r.cols, sv, internal, p.ExecCfg().GetRowMetrics(internal),
+			),
+			alloc: &tree.DatumAlloc{},
+		}
+		if err := writeRows(td, &td.tableWriterBase, r.deletes); err != nil {
+			return err
+		}
+	}
+	if len(r.inserts) > 0 {
+		ri, err := row.MakeInserter(
+			ctx, p.txn, p.ExecCfg().Codec, table, r.cols, &tree.DatumAlloc{}, sv, internal,
+			p.ExecCfg().GetRowMetrics(internal),
+		)
+		if err != nil {
+			return err
+		}
+		ti := &tableInserter{ri: ri}
+		if err := writeRows(ti, &ti.tableWriterBase, r.inserts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
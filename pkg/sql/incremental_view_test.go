@@ -0,0 +1,266 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIncrementalViewRefreshBatches verifies that incrementally refreshed
+// views are correct when the changes to the base tables span multiple batches,
+// and that the rows of a view which are not affected by the changes are kept.
+func TestIncrementalViewRefreshBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer func(batchSize int) { incrementalViewBatchSize = batchSize }(incrementalViewBatchSize)
+	incrementalViewBatchSize = 3
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	r := sqlutils.MakeSQLRunner(db)
+
+	const joinQuery = `
+SELECT o.id AS order_id, c.id AS customer_id, c.region, o.amount
+FROM t.orders AS o JOIN t.customers AS c ON o.customer = c.id`
+	const aggQuery = `
+SELECT c.region, count(*) AS n, sum(o.amount) AS total, max(o.amount) AS hi
+FROM t.orders AS o JOIN t.customers AS c ON o.customer = c.id
+GROUP BY c.region`
+	r.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.customers (id INT PRIMARY KEY, region STRING);
+CREATE TABLE t.orders (id INT PRIMARY KEY, customer INT, amount INT);
+INSERT INTO t.customers SELECT i, 'r' || (i % 4)::STRING FROM generate_series(1, 20) AS g(i);
+INSERT INTO t.orders SELECT i, i % 20 + 1, i FROM generate_series(1, 100) AS g(i)`)
+	r.Exec(t, `CREATE MATERIALIZED VIEW t.join_view WITH (incremental) AS `+joinQuery)
+	r.Exec(t, `CREATE MATERIALIZED VIEW t.agg_view WITH (incremental) AS `+aggQuery)
+
+	// The order with ID 100 belongs to customer 1, neither of which changes.
+	var rowID int
+	r.QueryRow(t, `SELECT rowid FROM t.join_view WHERE order_id = 100`).Scan(&rowID)
+
+	// Change both base tables, including rows of the orders table which join
+	// with changed rows of the customers table, so that the changes to both
+	// tables affect the same rows of the views.
+	r.Exec(t, `
+UPDATE t.customers SET region = 'x' WHERE id BETWEEN 2 AND 8;
+UPDATE t.orders SET amount = amount * 2 WHERE id % 3 = 0;
+DELETE FROM t.orders WHERE id % 7 = 0;
+INSERT INTO t.orders SELECT i, i % 20 + 1, i FROM generate_series(101, 120) AS g(i);
+DELETE FROM t.customers WHERE id = 9`)
+	r.Exec(t, `REFRESH MATERIALIZED VIEW t.join_view`)
+	r.Exec(t, `REFRESH MATERIALIZED VIEW t.agg_view`)
+
+	r.CheckQueryResults(t,
+		`SELECT order_id, customer_id, region, amount FROM t.join_view ORDER BY order_id`,
+		r.QueryStr(t, joinQuery+` ORDER BY order_id`),
+	)
+	r.CheckQueryResults(t,
+		`SELECT region, n, total, hi FROM t.agg_view ORDER BY region`,
+		r.QueryStr(t, aggQuery+` ORDER BY region`),
+	)
+	r.CheckQueryResults(t,
+		`SELECT rowid FROM t.join_view WHERE order_id = 100`,
+		[][]string{{tree.NewDInt(tree.DInt(rowID)).String()}},
+	)
+
+	// TRUNCATE replaces the primary index of the table, so the deleted rows
+	// can't be found in its MVCC history and the views are recomputed.
+	r.Exec(t, `TRUNCATE t.orders; INSERT INTO t.orders VALUES (1, 1, 1)`)
+	r.Exec(t, `REFRESH MATERIALIZED VIEW t.join_view`)
+	r.Exec(t, `REFRESH MATERIALIZED VIEW t.agg_view`)
+	r.CheckQueryResults(t,
+		`SELECT order_id, customer_id, region, amount FROM t.join_view`,
+		[][]string{{"1", "1", "r1", "1"}},
+	)
+	r.CheckQueryResults(t,
+		`SELECT region, n, total, hi FROM t.agg_view`,
+		[][]string{{"r1", "1", "1", "1"}},
+	)
+}
+
+// TestIncrementalViewRefreshFallback verifies that an incrementally refreshed
+// view is recomputed when the changes to its base tables since the last
+// refresh can't be determined from their MVCC history, either because that
+// history was garbage collected or because rows were deleted with MVCC range
+// tombstones.
+func TestIncrementalViewRefreshFallback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	r := sqlutils.MakeSQLRunner(db)
+
+	const query = `SELECT id, amount FROM t.orders`
+	r.Exec(t, `
+CREATE DATABASE t;
+CREATE TABLE t.orders (id INT PRIMARY KEY, amount INT);
+INSERT INTO t.orders SELECT i, i FROM generate_series(1, 10) AS g(i)`)
+	r.Exec(t, `CREATE MATERIALIZED VIEW t.v WITH (incremental) AS `+query)
+	r.Exec(t, `REFRESH MATERIALIZED VIEW t.v`)
+
+	desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, s.Codec(), "t", "orders")
+	span := desc.TableSpan(s.Codec())
+	// Keep the table in its own range, so that its GC threshold can be bumped
+	// without affecting other tables.
+	for _, key := range []roachpb.Key{span.Key, span.EndKey} {
+		_, _, err := s.SplitRange(key)
+		require.NoError(t, err)
+	}
+
+	checkView := func(t *testing.T) {
+		r.CheckQueryResults(t,
+			`SELECT id, amount FROM t.v ORDER BY id`,
+			r.QueryStr(t, query+` ORDER BY id`),
+		)
+	}
+
+	t.Run("range tombstones", func(t *testing.T) {
+		// Deleting rows with an MVCC range tombstone doesn't leave a trace of
+		// the individual deleted rows.
+		require.NoError(t, kvDB.DelRangeUsingTombstone(ctx, span.Key, span.EndKey))
+		r.Exec(t, `INSERT INTO t.orders VALUES (11, 11)`)
+		r.Exec(t, `REFRESH MATERIALIZED VIEW t.v`)
+		checkView(t)
+	})
+
+	t.Run("gc", func(t *testing.T) {
+		r.Exec(t, `REFRESH MATERIALIZED VIEW t.v`)
+		r.Exec(t, `UPDATE t.orders SET amount = amount * 2`)
+		// Garbage collect the MVCC history of the table up to the present, so
+		// that the changes since the last refresh can't be exported anymore.
+		gcr := kvpb.GCRequest{
+			RequestHeader: kvpb.RequestHeaderFromSpan(span),
+			Threshold:     s.Clock().Now(),
+		}
+		_, pErr := kv.SendWrapped(ctx, kvDB.NonTransactionalSender(), &gcr)
+		require.NoError(t, pErr.GoError())
+		r.Exec(t, `REFRESH MATERIALIZED VIEW t.v`)
+		checkView(t)
+	})
+}
+
+// TestChangedKeysMemoryAccounting verifies that the primary keys of the changed
+// rows of a base table are accounted for, and that an out of memory error is
+// returned once the budget is exhausted.
+func TestChangedKeysMemoryAccounting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	monitor := startTestMonitor(ctx, 1<<10 /* limit */)
+	defer monitor.Stop(ctx)
+	acc := monitor.MakeBoundAccount()
+	defer acc.Close(ctx)
+
+	var c changedKeys
+	pk := func(i int) tree.Datums {
+		return tree.Datums{tree.NewDInt(tree.DInt(i)), tree.NewDString("a")}
+	}
+	require.NoError(t, c.add(ctx, &acc, pk(1)))
+	used := acc.Used()
+	require.Greater(t, used, int64(0))
+
+	// Duplicate keys are neither buffered nor accounted for again.
+	require.NoError(t, c.add(ctx, &acc, pk(1)))
+	require.Len(t, c.pks, 1)
+	require.Equal(t, used, acc.Used())
+	ok, err := c.contains(pk(1))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.contains(pk(2))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	var i int
+	for i = 2; err == nil; i++ {
+		err = c.add(ctx, &acc, pk(i))
+	}
+	require.True(t, sqlerrors.IsOutOfMemoryError(err), "%v", err)
+	require.Len(t, c.pks, i-2)
+	require.LessOrEqual(t, acc.Used(), int64(1<<10))
+}
+
+// TestChangedKeysBatches verifies that the changed keys are processed in
+// batches of at most incrementalViewBatchSize keys.
+func TestChangedKeysBatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	defer func(batchSize int) { incrementalViewBatchSize = batchSize }(incrementalViewBatchSize)
+	incrementalViewBatchSize = 4
+
+	ctx := context.Background()
+	monitor := startTestMonitor(ctx, math.MaxInt64 /* limit */)
+	defer monitor.Stop(ctx)
+	acc := monitor.MakeBoundAccount()
+	defer acc.Close(ctx)
+	for _, n := range []int{0, 1, 4, 5, 11} {
+		var c changedKeys
+		for i := 0; i < n; i++ {
+			require.NoError(t, c.add(ctx, &acc, tree.Datums{tree.NewDInt(tree.DInt(i))}))
+		}
+		var sizes []int
+		var next int
+		require.NoError(t, c.forEachBatch(func(pks []tree.Datums) error {
+			sizes = append(sizes, len(pks))
+			for _, pk := range pks {
+				require.Equal(t, tree.DInt(next), tree.MustBeDInt(pk[0]))
+				next++
+			}
+			return nil
+		}))
+		require.Equal(t, n, next)
+		for i, size := range sizes {
+			require.LessOrEqual(t, size, 4)
+			if i < len(sizes)-1 {
+				require.Equal(t, 4, size)
+			}
+		}
+	}
+}
+
+// startTestMonitor starts a memory monitor with the given limit.
+func startTestMonitor(ctx context.Context, limit int64) *mon.BytesMonitor {
+	monitor := mon.NewMonitorWithLimit(
+		"test-monitor",
+		mon.MemoryResource,
+		limit,
+		nil, /* curCount */
+		nil, /* maxHist */
+		1,   /* increment */
+		math.MaxInt64,
+		cluster.MakeTestingClusterSettings(),
+	)
+	monitor.Start(ctx, nil, mon.NewStandaloneBudget(math.MaxInt64))
+	return monitor
+}
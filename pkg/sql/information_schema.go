@@ -1289,16 +1289,17 @@ https://www.postgresql.org/docs/9.5/infoschema-table-constraints.html`,
 					} else if u := c.AsUniqueWithIndex(); u != nil && u.Primary() {
 						kind = catconstants.ConstraintTypePK
 					}
+					deferrable, initiallyDeferred := constraintDeferrability(c)
 					if err := addRow(
-						dbNameStr,                     // constraint_catalog
-						scNameStr,                     // constraint_schema
-						tree.NewDString(c.GetName()),  // constraint_name
-						dbNameStr,                     // table_catalog
-						scNameStr,                     // table_schema
-						tbNameStr,                     // table_name
-						tree.NewDString(string(kind)), // constraint_type
-						yesOrNoDatum(false),           // is_deferrable
-						yesOrNoDatum(false),           // initially_deferred
+						dbNameStr,                       // constraint_catalog
+						scNameStr,                       // constraint_schema
+						tree.NewDString(c.GetName()),    // constraint_name
+						dbNameStr,                       // table_catalog
+						scNameStr,                       // table_schema
+						tbNameStr,                       // table_name
+						tree.NewDString(string(kind)),   // constraint_type
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(initiallyDeferred), // initially_deferred
 					); err != nil {
 						return err
 					}
@@ -0,0 +1,445 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// resolveInheritanceParents resolves the tables named in the INHERITS clause
+// of a CREATE TABLE statement, and checks that the new table may inherit from
+// them.
+func (p *planner) resolveInheritanceParents(
+	ctx context.Context, n *tree.CreateTable, db catalog.DatabaseDescriptor,
+) ([]*tabledesc.Mutable, error) {
+	parents := make([]*tabledesc.Mutable, 0, len(n.Inherits))
+	for i := range n.Inherits {
+		_, parent, err := p.ResolveMutableTableDescriptor(
+			ctx, &n.Inherits[i], true /* required */, tree.ResolveRequireTableDesc,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range parents {
+			if other.ID == parent.ID {
+				return nil, pgerror.Newf(pgcode.DuplicateRelation,
+					"relation %q would be inherited from more than once", parent.Name)
+			}
+		}
+		if err := p.checkInheritanceParent(ctx, parent, db.GetID(), n.Persistence); err != nil {
+			return nil, err
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+// checkInheritanceParent returns an error if a table in the given database
+// and with the given persistence cannot inherit from the parent table.
+func (p *planner) checkInheritanceParent(
+	ctx context.Context, parent *tabledesc.Mutable, dbID descpb.ID, persistence tree.Persistence,
+) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.V23_1) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to use table inheritance",
+			clusterversion.ByKey(clusterversion.V23_1))
+	}
+	if parent.ParentID != dbID {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cross-database inheritance is not supported: %q is in a different database",
+			parent.Name)
+	}
+	if parent.IsTemporary() && !persistence.IsTemporary() {
+		return pgerror.Newf(pgcode.WrongObjectType,
+			"cannot inherit from temporary relation %q", parent.Name)
+	}
+	if parent.IsLocalityRegionalByRow() {
+		return unimplemented.NewWithIssueDetailf(22456, "regional by row",
+			"cannot inherit from REGIONAL BY ROW table %q", parent.Name)
+	}
+	if err := p.CheckPrivilege(ctx, parent, privilege.CREATE); err != nil {
+		return pgerror.Wrapf(err, pgcode.InsufficientPrivilege,
+			"must be owner of table %s or have CREATE privilege on table %s",
+			tree.Name(parent.GetName()), tree.Name(parent.GetName()))
+	}
+	return nil
+}
+
+// addInheritedTableDefs adds the columns and check constraints of the parent
+// tables to the definitions of a table created with an INHERITS clause. The
+// inherited columns come first, in the order of the parents. A column defined
+// by more than one parent, or by a parent and the new table itself, is merged
+// into a single column, and must have the same type in each definition. The
+// merged column is NOT NULL if any of its definitions is.
+func (p *planner) addInheritedTableDefs(
+	ctx context.Context, n *tree.CreateTable, parents []*tabledesc.Mutable,
+) error {
+	localCols := make(map[tree.Name]*tree.ColumnTableDef)
+	localChecks := make(map[tree.Name]*tree.CheckConstraintTableDef)
+	for _, def := range n.Defs {
+		switch d := def.(type) {
+		case *tree.ColumnTableDef:
+			localCols[d.Name] = d
+		case *tree.CheckConstraintTableDef:
+			if d.Name != "" {
+				localChecks[d.Name] = d
+			}
+		}
+	}
+
+	var defs tree.TableDefs
+	inheritedCols := make(map[tree.Name]*tree.ColumnTableDef)
+	inheritedTypes := make(map[tree.Name]*types.T)
+	inheritedChecks := make(map[tree.Name]*tree.CheckConstraintTableDef)
+	for _, parent := range parents {
+		for i := range parent.Columns {
+			c := &parent.Columns[i]
+			implicit, err := isImplicitlyCreatedBySystem(parent, c)
+			if err != nil {
+				return err
+			}
+			if implicit {
+				continue
+			}
+			name := tree.Name(c.Name)
+			if prev, ok := inheritedCols[name]; ok {
+				p.BufferClientNotice(ctx,
+					pgnotice.Newf("merging multiple inherited definitions of column %q", c.Name))
+				if !inheritedTypes[name].Identical(c.Type) {
+					return pgerror.Newf(pgcode.DatatypeMismatch,
+						"inherited column %q has a type conflict", c.Name)
+				}
+				if !c.Nullable {
+					prev.Nullable.Nullability = tree.NotNull
+				}
+				continue
+			}
+			def, err := inheritedColumnDef(c)
+			if err != nil {
+				return err
+			}
+			if local, ok := localCols[name]; ok {
+				p.BufferClientNotice(ctx,
+					pgnotice.Newf("merging column %q with inherited definition", c.Name))
+				typ, err := tree.ResolveType(ctx, local.Type, p.semaCtx.GetTypeResolver())
+				if err != nil {
+					return err
+				}
+				if !typ.Identical(c.Type) {
+					return pgerror.Newf(pgcode.DatatypeMismatch,
+						"column %q has a type conflict", c.Name)
+				}
+				// Copy the local definition rather than modifying the statement.
+				merged := *local
+				if !c.Nullable {
+					merged.Nullable.Nullability = tree.NotNull
+				}
+				def = &merged
+			}
+			inheritedCols[name] = def
+			inheritedTypes[name] = c.Type
+			defs = append(defs, def)
+		}
+
+		for _, c := range parent.Checks {
+			if c.FromHashShardedColumn {
+				continue
+			}
+			name := tree.Name(c.Name)
+			def := &tree.CheckConstraintTableDef{Name: name}
+			var err error
+			if def.Expr, err = parser.ParseExpr(c.Expr); err != nil {
+				return err
+			}
+			other, ok := inheritedChecks[name]
+			if !ok {
+				other, ok = localChecks[name]
+			}
+			if ok {
+				if tree.Serialize(other.Expr) != tree.Serialize(def.Expr) {
+					return pgerror.Newf(pgcode.DuplicateObject,
+						"check constraint %q conflicts with inherited constraint", c.Name)
+				}
+				continue
+			}
+			inheritedChecks[name] = def
+			defs = append(defs, def)
+		}
+	}
+
+	// Add the remaining local definitions after the inherited ones.
+	for _, def := range n.Defs {
+		if d, ok := def.(*tree.ColumnTableDef); ok {
+			if _, ok := inheritedCols[d.Name]; ok {
+				continue
+			}
+		}
+		defs = append(defs, def)
+	}
+	n.Defs = defs
+	return nil
+}
+
+// inheritedColumnDef returns the definition of a column inherited from the
+// given column of a parent table, including its default, ON UPDATE and
+// computed expressions.
+func inheritedColumnDef(c *descpb.ColumnDescriptor) (*tree.ColumnTableDef, error) {
+	def := &tree.ColumnTableDef{
+		Name:   tree.Name(c.Name),
+		Type:   c.Type,
+		Hidden: c.Hidden,
+	}
+	if c.Nullable {
+		def.Nullable.Nullability = tree.Null
+	} else {
+		def.Nullable.Nullability = tree.NotNull
+	}
+	var err error
+	if c.DefaultExpr != nil {
+		if def.DefaultExpr.Expr, err = parser.ParseExpr(*c.DefaultExpr); err != nil {
+			return nil, err
+		}
+	}
+	if c.OnUpdateExpr != nil {
+		if def.OnUpdateExpr.Expr, err = parser.ParseExpr(*c.OnUpdateExpr); err != nil {
+			return nil, err
+		}
+	}
+	if c.ComputeExpr != nil {
+		def.Computed.Computed = true
+		def.Computed.Virtual = c.Virtual
+		if def.Computed.Expr, err = parser.ParseExpr(*c.ComputeExpr); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// addInheritance records that the child table inherits from the parent table.
+func addInheritance(child, parent *tabledesc.Mutable) {
+	child.InheritsFrom = append(child.InheritsFrom, parent.ID)
+	parent.InheritedBy = append(parent.InheritedBy, child.ID)
+}
+
+// removeInheritance removes the inheritance relationship between the child
+// and the parent table. It returns false if the child does not inherit from
+// the parent.
+func removeInheritance(child, parent *tabledesc.Mutable) bool {
+	var found bool
+	child.InheritsFrom, found = removeID(child.InheritsFrom, parent.ID)
+	parent.InheritedBy, _ = removeID(parent.InheritedBy, child.ID)
+	return found
+}
+
+// removeID removes the given ID from the list, and returns whether it was
+// found.
+func removeID(ids []descpb.ID, id descpb.ID) ([]descpb.ID, bool) {
+	for i := range ids {
+		if ids[i] == id {
+			return append(ids[:i], ids[i+1:]...), true
+		}
+	}
+	return ids, false
+}
+
+// alterTableInherit implements ALTER TABLE ... INHERIT and ALTER TABLE ... NO
+// INHERIT. The table must already contain all the columns and check
+// constraints of the new parent, with matching types and definitions. The
+// parent descriptor is written, while the table descriptor is left to the
+// caller.
+func (p *planner) alterTableInherit(
+	ctx context.Context, tableDesc *tabledesc.Mutable, t *tree.AlterTableInherit,
+) error {
+	_, parent, err := p.ResolveMutableTableDescriptor(
+		ctx, &t.Parent, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return err
+	}
+	if t.NoInherit {
+		if !removeInheritance(tableDesc, parent) {
+			return pgerror.Newf(pgcode.UndefinedTable,
+				"relation %q is not a parent of relation %q", parent.Name, tableDesc.Name)
+		}
+		return p.writeSchemaChange(ctx, parent, descpb.InvalidMutationID, fmt.Sprintf(
+			"updating table %q after removing inheriting table %q", parent.Name, tableDesc.Name,
+		))
+	}
+
+	if parent.ID == tableDesc.ID {
+		return pgerror.Newf(pgcode.WrongObjectType,
+			"cannot inherit from relation %q to itself", tableDesc.Name)
+	}
+	for _, id := range tableDesc.InheritsFrom {
+		if id == parent.ID {
+			return pgerror.Newf(pgcode.DuplicateRelation,
+				"relation %q would be inherited from more than once", parent.Name)
+		}
+	}
+	if err := p.checkInheritanceCycle(ctx, tableDesc.ID, parent); err != nil {
+		return err
+	}
+	persistence := tree.PersistencePermanent
+	if tableDesc.IsTemporary() {
+		persistence = tree.PersistenceTemporary
+	}
+	if err := p.checkInheritanceParent(ctx, parent, tableDesc.ParentID, persistence); err != nil {
+		return err
+	}
+
+	for i := range parent.Columns {
+		c := &parent.Columns[i]
+		implicit, err := isImplicitlyCreatedBySystem(parent, c)
+		if err != nil {
+			return err
+		}
+		if implicit {
+			continue
+		}
+		col := catalog.FindColumnByName(tableDesc, c.Name)
+		if col == nil || !col.Public() {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"child table is missing column %q", c.Name)
+		}
+		if !col.GetType().Identical(c.Type) {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"child table %q has different type for column %q", tableDesc.Name, c.Name)
+		}
+		if !c.Nullable && col.IsNullable() {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"column %q in child table must be marked NOT NULL", c.Name)
+		}
+	}
+	for _, c := range parent.Checks {
+		if c.FromHashShardedColumn {
+			continue
+		}
+		ck := catalog.FindConstraintByName(tableDesc, c.Name)
+		if ck == nil || ck.AsCheck() == nil {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"child table is missing constraint %q", c.Name)
+		}
+		if ck.AsCheck().GetExpr() != c.Expr {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"child table %q has different definition for check constraint %q",
+				tableDesc.Name, c.Name)
+		}
+	}
+
+	addInheritance(tableDesc, parent)
+	return p.writeSchemaChange(ctx, parent, descpb.InvalidMutationID, fmt.Sprintf(
+		"updating table %q after adding inheriting table %q", parent.Name, tableDesc.Name,
+	))
+}
+
+// checkInheritanceCycle returns an error if the table with the given ID is an
+// ancestor of the parent table, in which case making it inherit from the
+// parent would create a cycle.
+func (p *planner) checkInheritanceCycle(
+	ctx context.Context, id descpb.ID, parent catalog.TableDescriptor,
+) error {
+	for _, ancestorID := range parent.GetInheritsFrom() {
+		if ancestorID == id {
+			return pgerror.Newf(pgcode.DuplicateRelation, "circular inheritance not allowed")
+		}
+		ancestor, err := p.Descriptors().ByIDWithLeased(p.txn).WithoutNonPublic().Get().Table(ctx, ancestorID)
+		if err != nil {
+			return err
+		}
+		if err := p.checkInheritanceCycle(ctx, id, ancestor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canRemoveInheritanceChild returns an error if the table inheriting from a
+// table that is being dropped cannot be dropped along with it. The children
+// of a table are only dropped with DROP ... CASCADE.
+func (p *planner) canRemoveInheritanceChild(
+	ctx context.Context, from string, child *tabledesc.Mutable, behavior tree.DropBehavior,
+) error {
+	if behavior != tree.DropCascade {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"cannot drop table %s because other objects depend on it", from),
+			fmt.Sprintf("table %s inherits from table %s; use DROP ... CASCADE to drop the dependent objects too",
+				child.Name, from),
+		)
+	}
+	return p.canDropTable(ctx, child, true /* checkOwnership */)
+}
+
+// removeInheritanceForDrop removes the references between a table that is
+// being dropped and its inheritance parents and children which are not
+// themselves being dropped.
+func (p *planner) removeInheritanceForDrop(ctx context.Context, tableDesc *tabledesc.Mutable) error {
+	ids := make([]descpb.ID, 0, len(tableDesc.InheritsFrom)+len(tableDesc.InheritedBy))
+	ids = append(ids, tableDesc.InheritsFrom...)
+	ids = append(ids, tableDesc.InheritedBy...)
+	for _, id := range ids {
+		other, err := p.Descriptors().MutableByID(p.txn).Table(ctx, id)
+		if err != nil {
+			return err
+		}
+		if other.Dropped() {
+			continue
+		}
+		if !removeInheritance(tableDesc, other) {
+			removeInheritance(other, tableDesc)
+		}
+		if err := p.writeSchemaChange(ctx, other, descpb.InvalidMutationID, fmt.Sprintf(
+			"updating table %q after dropping table %q", other.Name, tableDesc.Name,
+		)); err != nil {
+			return err
+		}
+	}
+	tableDesc.InheritsFrom = nil
+	tableDesc.InheritedBy = nil
+	return nil
+}
+
+// checkAlterTableCmdForInheritance returns an error for the ALTER TABLE
+// commands which would have to be propagated to the tables inheriting from the
+// table, or which would change the columns a table shares with its parents.
+// Neither is supported yet.
+func checkAlterTableCmdForInheritance(tableDesc *tabledesc.Mutable, cmd tree.AlterTableCmd) error {
+	switch cmd.(type) {
+	case *tree.AlterTableAddColumn:
+		if len(tableDesc.InheritedBy) == 0 {
+			return nil
+		}
+	case *tree.AlterTableDropColumn, *tree.AlterTableAlterColumnType, *tree.AlterTableRenameColumn:
+		if len(tableDesc.InheritsFrom) == 0 && len(tableDesc.InheritedBy) == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return unimplemented.NewWithIssueDetailf(22456, cmd.TelemetryName(),
+		"cannot alter the columns of table %q, which is part of an inheritance hierarchy",
+		tableDesc.Name)
+}
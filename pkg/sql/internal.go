@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -335,6 +336,7 @@ func (ie *InternalExecutor) newConnExecutorWithTxn(
 		txn.ReadTimestamp().GoTime(),
 		nil, /* historicalTimestamp */
 		roachpb.UnspecifiedUserPriority,
+		isolation.Serializable, /* isoLevel */
 		tree.ReadWrite,
 		txn,
 		ex.transitionCtx,
@@ -0,0 +1,132 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// notifications gives access to the channels the session is listening on.
+//
+// Unlike in Postgres, LISTEN and UNLISTEN take effect immediately rather than
+// when the current transaction commits, and they are not undone if it rolls
+// back.
+type notifications interface {
+	// listen starts listening on the given channel.
+	listen(channel string) error
+	// unlisten stops listening on the given channel.
+	unlisten(channel string)
+	// unlistenAll stops listening on all channels.
+	unlistenAll()
+}
+
+type connExNotificationsAccessor struct {
+	ex *connExecutor
+}
+
+func (c connExNotificationsAccessor) listen(channel string) error {
+	l, err := c.ex.getNotificationListener()
+	if err != nil {
+		return err
+	}
+	return l.Listen(channel)
+}
+
+func (c connExNotificationsAccessor) unlisten(channel string) {
+	if c.ex.notifications != nil {
+		c.ex.notifications.Unlisten(channel)
+	}
+}
+
+func (c connExNotificationsAccessor) unlistenAll() {
+	if c.ex.notifications != nil {
+		c.ex.notifications.UnlistenAll()
+	}
+}
+
+// emptyNotifications is the default impl used by the planner when the
+// connExecutor is not available. The session cannot listen on any channel.
+type emptyNotifications struct{}
+
+func (emptyNotifications) listen(string) error {
+	return pgerror.New(pgcode.FeatureNotSupported, "LISTEN is not supported in this context")
+}
+
+func (emptyNotifications) unlisten(string) {}
+
+func (emptyNotifications) unlistenAll() {}
+
+// getNotificationListener returns the listener of the session, creating it if
+// the session is not listening on any channel yet.
+func (ex *connExecutor) getNotificationListener() (*notify.Listener, error) {
+	if ex.notifications != nil {
+		return ex.notifications, nil
+	}
+	registry := ex.server.cfg.NotificationRegistry
+	if registry == nil || ex.executorType == executorTypeInternal {
+		return nil, pgerror.New(pgcode.FeatureNotSupported, "LISTEN is not supported in this context")
+	}
+	stmtBuf := ex.stmtBuf
+	ex.notifications = registry.NewListener(func() {
+		// Ask the connExecutor to deliver the notifications as soon as the
+		// session is idle. The error is ignored: it is only returned once the
+		// connection is closed, at which point the notifications are moot.
+		_ = stmtBuf.Push(context.Background(), DeliverNotifications{})
+	})
+	return ex.notifications, nil
+}
+
+// bufferNotifications appends the notifications queued for the session to the
+// given result, along with notices reporting the notifications which were
+// discarded because the queue was full.
+func (ex *connExecutor) bufferNotifications(res NotificationResult) {
+	if ex.notifications == nil {
+		return
+	}
+	queued, dropped, overflowed := ex.notifications.Drain()
+	if dropped > 0 {
+		res.BufferNotice(pgnotice.Newf(
+			"%d notifications were dropped because the notification queue was full", dropped,
+		))
+	}
+	if overflowed {
+		res.BufferNotice(pgnotice.Newf(
+			"stopped listening on all channels because the notification queue was full",
+		))
+	}
+	for _, n := range queued {
+		res.BufferNotification(n)
+	}
+}
+
+type listenNode struct {
+	n *tree.Listen
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	return &listenNode{n: n}, nil
+}
+
+func (n *listenNode) startExec(params runParams) error {
+	return params.p.notifications.listen(string(n.n.ChannelName))
+}
+
+func (n *listenNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *listenNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *listenNode) Close(ctx context.Context)           {}
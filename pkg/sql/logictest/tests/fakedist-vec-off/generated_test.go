@@ -534,6 +534,13 @@ func TestLogic_default(
 	runLogicTest(t, "default")
 }
 
+func TestLogic_deferrable_constraints(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "deferrable_constraints")
+}
+
 func TestLogic_delete(
 	t *testing.T,
 ) {
@@ -611,6 +618,13 @@ func TestLogic_distsql_srfs(
 	runLogicTest(t, "distsql_srfs")
 }
 
+func TestLogic_domain(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "domain")
+}
+
 func TestLogic_drop_database(
 	t *testing.T,
 ) {
@@ -730,6 +744,13 @@ func TestLogic_exclude_data_from_backup(
 	runLogicTest(t, "exclude_data_from_backup")
 }
 
+func TestLogic_exclusion_constraint(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "exclusion_constraint")
+}
+
 func TestLogic_experimental_distsql_planning(
 	t *testing.T,
 ) {
@@ -884,6 +905,13 @@ func TestLogic_group_join(
 	runLogicTest(t, "group_join")
 }
 
+func TestLogic_grouping_sets(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "grouping_sets")
+}
+
 func TestLogic_hash_join(
 	t *testing.T,
 ) {
@@ -940,6 +968,13 @@ func TestLogic_information_schema(
 	runLogicTest(t, "information_schema")
 }
 
+func TestLogic_inheritance(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "inheritance")
+}
+
 func TestLogic_inner_join(
 	t *testing.T,
 ) {
@@ -1066,6 +1101,13 @@ func TestLogic_limit(
 	runLogicTest(t, "limit")
 }
 
+func TestLogic_listen_notify(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "listen_notify")
+}
+
 func TestLogic_locality(
 	t *testing.T,
 ) {
@@ -1108,6 +1150,13 @@ func TestLogic_materialized_view(
 	runLogicTest(t, "materialized_view")
 }
 
+func TestLogic_merge(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "merge")
+}
+
 func TestLogic_merge_join(
 	t *testing.T,
 ) {
@@ -1297,6 +1346,13 @@ func TestLogic_pgoidtype(
 	runLogicTest(t, "pgoidtype")
 }
 
+func TestLogic_plpgsql(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "plpgsql")
+}
+
 func TestLogic_poison_after_push(
 	t *testing.T,
 ) {
@@ -1339,6 +1395,13 @@ func TestLogic_privileges_table(
 	runLogicTest(t, "privileges_table")
 }
 
+func TestLogic_procedure(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "procedure")
+}
+
 func TestLogic_propagate_input_ordering(
 	t *testing.T,
 ) {
@@ -1346,6 +1409,20 @@ func TestLogic_propagate_input_ordering(
 	runLogicTest(t, "propagate_input_ordering")
 }
 
+func TestLogic_range(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "range")
+}
+
+func TestLogic_read_committed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "read_committed")
+}
+
 func TestLogic_reassign_owned_by(
 	t *testing.T,
 ) {
@@ -1948,6 +2025,13 @@ func TestLogic_trigram_indexes(
 	runLogicTest(t, "trigram_indexes")
 }
 
+func TestLogic_triggers(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "triggers")
+}
+
 func TestLogic_truncate(
 	t *testing.T,
 ) {
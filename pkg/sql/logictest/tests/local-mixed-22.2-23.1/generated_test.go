@@ -86,6 +86,20 @@ func TestLogic_create_index(
 	runLogicTest(t, "create_index")
 }
 
+func TestLogic_deferrable_constraints_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "deferrable_constraints_mixed")
+}
+
+func TestLogic_domain_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "domain_mixed")
+}
+
 func TestLogic_drop_database(
 	t *testing.T,
 ) {
@@ -135,9 +149,37 @@ func TestLogic_drop_view(
 	runLogicTest(t, "drop_view")
 }
 
+func TestLogic_exclusion_constraint_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "exclusion_constraint_mixed")
+}
+
 func TestLogic_gc_job_mixed(
 	t *testing.T,
 ) {
 	defer leaktest.AfterTest(t)()
 	runLogicTest(t, "gc_job_mixed")
 }
+
+func TestLogic_inheritance_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "inheritance_mixed")
+}
+
+func TestLogic_materialized_view_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "materialized_view_mixed")
+}
+
+func TestLogic_procedure_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "procedure_mixed")
+}
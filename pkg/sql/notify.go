@@ -0,0 +1,49 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type notifyNode struct {
+	n *tree.Notify
+}
+
+// Notify implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	return &notifyNode{n: n}, nil
+}
+
+func (n *notifyNode) startExec(params runParams) error {
+	var payload string
+	if n.n.Payload != nil {
+		payload = n.n.Payload.RawString()
+	}
+	return params.p.PublishNotification(params.ctx, string(n.n.ChannelName), payload)
+}
+
+func (n *notifyNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *notifyNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *notifyNode) Close(ctx context.Context)           {}
+
+// PublishNotification is part of the eval.Planner interface.
+func (p *planner) PublishNotification(ctx context.Context, channel, payload string) error {
+	return notify.Publish(ctx, p.InternalSQLTxn(), p.ExecCfg().Settings, notify.Notification{
+		Channel: channel,
+		Payload: payload,
+		PID:     int32(p.EvalContext().QueryCancelKey.GetPGBackendPID()),
+	})
+}
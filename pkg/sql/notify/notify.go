@@ -0,0 +1,564 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package notify implements the delivery of notifications published with
+// NOTIFY and pg_notify() to the sessions listening on their channels.
+//
+// A notification is published by inserting a row into system.notifications
+// in the publishing transaction, so that it becomes visible if and only if
+// the transaction commits. Every SQL server runs a Registry, which watches
+// the table with a rangefeed and hands new notifications to the Listeners of
+// the local sessions listening on their channel. As in Postgres, notifications
+// are delivered once, in commit order: they are buffered until the frontier
+// of the rangefeed passes their commit timestamp. Published notifications are
+// deleted from the table once they are older than
+// sql.notifications.retention.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed/rangefeedbuffer"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/multitenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/isql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/valueside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// MaxPayloadSize is the maximum size of the payload of a notification.
+var MaxPayloadSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"sql.notifications.max_payload_size",
+	"maximum size of the payload of a notification published with NOTIFY or pg_notify()",
+	8000,
+).WithPublic()
+
+var maxQueueSize = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.notifications.max_queue_size",
+	"maximum number of notifications queued for delivery to a single listening session",
+	10000,
+	settings.PositiveInt,
+).WithPublic()
+
+// OverflowBehavior describes what happens when a notification arrives for a
+// listening session whose queue is full.
+type OverflowBehavior int64
+
+const (
+	// OverflowDrop discards the notification.
+	OverflowDrop OverflowBehavior = iota
+	// OverflowUnlisten discards the notification, and stops the session from
+	// listening on any channel.
+	OverflowUnlisten
+)
+
+var overflowBehavior = settings.RegisterEnumSetting(
+	settings.TenantWritable,
+	"sql.notifications.queue_overflow_behavior",
+	"action taken when a notification arrives for a listening session whose queue is full: "+
+		"drop discards the notification, unlisten stops the session from listening on any channel",
+	"drop",
+	map[int64]string{
+		int64(OverflowDrop):     "drop",
+		int64(OverflowUnlisten): "unlisten",
+	},
+).WithPublic()
+
+var retention = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.notifications.retention",
+	"how long published notifications are kept in system.notifications before they are deleted",
+	10*time.Minute,
+	settings.PositiveDuration,
+)
+
+// maxChannelNameLength is the maximum length of a channel name. It matches
+// the maximum length of an identifier in Postgres.
+const maxChannelNameLength = 63
+
+// cleanupBatchSize is the maximum number of expired notifications deleted by
+// a single statement.
+const cleanupBatchSize = 1000
+
+// maxBufferedNotifications is the maximum number of notifications buffered
+// until the frontier of the rangefeed on system.notifications passes them.
+const maxBufferedNotifications = 100000
+
+// Notification is a notification published on a channel.
+type Notification struct {
+	// Channel is the name of the channel the notification was published on.
+	Channel string
+	// Payload is the payload of the notification. It is empty if none was
+	// specified.
+	Payload string
+	// PID is the backend process ID of the session which published the
+	// notification, as returned by pg_backend_pid().
+	PID int32
+}
+
+// Publish publishes a notification in the given transaction. The notification
+// is delivered to the listening sessions only if the transaction commits.
+func Publish(ctx context.Context, txn isql.Txn, st *cluster.Settings, n Notification) error {
+	if !st.Version.IsActive(ctx, clusterversion.V23_1NotificationsTable) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"NOTIFY is not supported until upgrade to version %s is finalized",
+			clusterversion.ByKey(clusterversion.V23_1NotificationsTable))
+	}
+	if err := ValidateChannelName(n.Channel); err != nil {
+		return err
+	}
+	if int64(len(n.Payload)) >= MaxPayloadSize.Get(&st.SV) {
+		return pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	_, err := txn.ExecEx(
+		ctx, "publish-notification", txn.KV(), sessiondata.NodeUserSessionDataOverride,
+		`INSERT INTO system.notifications (channel, payload, pid) VALUES ($1, $2, $3)`,
+		n.Channel, n.Payload, n.PID,
+	)
+	return err
+}
+
+// ValidateChannelName returns an error if the given channel name is invalid.
+func ValidateChannelName(channel string) error {
+	if channel == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	if len(channel) > maxChannelNameLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name too long")
+	}
+	return nil
+}
+
+// Registry maintains the set of listening sessions on a SQL server, and
+// delivers them the notifications published on their channels.
+type Registry struct {
+	db       isql.DB
+	codec    keys.SQLCodec
+	st       *cluster.Settings
+	rf       *rangefeed.Factory
+	resolver catalog.SystemTableIDResolver
+
+	mu struct {
+		syncutil.RWMutex
+		// listeners maps each channel to the set of listeners listening on it.
+		listeners map[string]map[*Listener]struct{}
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(
+	db isql.DB,
+	codec keys.SQLCodec,
+	st *cluster.Settings,
+	rf *rangefeed.Factory,
+	resolver catalog.SystemTableIDResolver,
+) *Registry {
+	r := &Registry{
+		db:       db,
+		codec:    codec,
+		st:       st,
+		rf:       rf,
+		resolver: resolver,
+	}
+	r.mu.listeners = make(map[string]map[*Listener]struct{})
+	return r
+}
+
+// Start starts watching system.notifications for new notifications, and
+// periodically deletes expired notifications. If the cluster has not been
+// upgraded to a version with system.notifications yet, the Registry waits
+// for the upgrade.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) error {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+
+	// Deleting expired notifications is not under user control, so exclude it
+	// from cost accounting and control.
+	ctx = multitenant.WithTenantCostControlExemption(ctx)
+
+	return stopper.RunAsyncTask(ctx, "notifications", func(ctx context.Context) {
+		if err := r.waitForUpgrade(ctx); err != nil {
+			return
+		}
+		if err := r.startRangeFeed(ctx); err != nil {
+			log.Warningf(ctx, "unable to watch system.notifications: %v", err)
+			return
+		}
+		r.cleanupLoop(ctx)
+	})
+}
+
+// waitForUpgrade blocks until the cluster version which introduces
+// system.notifications is active.
+func (r *Registry) waitForUpgrade(ctx context.Context) error {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for !r.st.Version.IsActive(ctx, clusterversion.V23_1NotificationsTable) {
+		timer.Reset(10 * time.Second)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// startRangeFeed starts the rangefeed on system.notifications.
+func (r *Registry) startRangeFeed(ctx context.Context) error {
+	tableID, err := r.resolver.LookupSystemTableID(ctx, systemschema.NotificationsTable.GetName())
+	if err != nil {
+		return err
+	}
+	indexPrefix := r.codec.IndexPrefix(uint32(tableID), uint32(systemschema.NotificationsTable.GetPrimaryIndexID()))
+	indexSpan := roachpb.Span{
+		Key:    indexPrefix,
+		EndKey: indexPrefix.PrefixEnd(),
+	}
+	decoder := valueside.MakeDecoder(systemschema.NotificationsTable.PublicColumns())
+	buf := newNotificationBuffer(maxBufferedNotifications)
+	_, err = r.rf.RangeFeed(
+		ctx,
+		"notifications-watcher",
+		[]roachpb.Span{indexSpan},
+		r.db.KV().Clock().Now(),
+		func(ctx context.Context, kv *kvpb.RangeFeedValue) {
+			// Deletions of expired notifications are ignored.
+			if !kv.Value.IsPresent() {
+				return
+			}
+			ev, err := decodeNotification(&decoder, indexPrefix, kv)
+			if err != nil {
+				log.Warningf(ctx, "failed to decode notification: %v", err)
+				return
+			}
+			if err := buf.add(ev); err != nil {
+				log.Warningf(ctx, "dropping notification on channel %q: %v", ev.n.Channel, err)
+			}
+		},
+		rangefeed.WithSystemTablePriority(),
+		rangefeed.WithOnFrontierAdvance(func(ctx context.Context, frontier hlc.Timestamp) {
+			for _, n := range buf.flush(ctx, frontier) {
+				r.dispatch(n)
+			}
+		}),
+	)
+	return err
+}
+
+// decodeNotification decodes a row of the primary index of
+// system.notifications.
+func decodeNotification(
+	decoder *valueside.Decoder, indexPrefix roachpb.Key, kv *kvpb.RangeFeedValue,
+) (notificationEvent, error) {
+	if !bytes.HasPrefix(kv.Key, indexPrefix) {
+		return notificationEvent{}, errors.AssertionFailedf("unexpected key %s", kv.Key)
+	}
+	_, id, err := encoding.DecodeVarintAscending(kv.Key[len(indexPrefix):])
+	if err != nil {
+		return notificationEvent{}, err
+	}
+	tuple, err := kv.Value.GetTuple()
+	if err != nil {
+		return notificationEvent{}, err
+	}
+	var alloc tree.DatumAlloc
+	datums, err := decoder.Decode(&alloc, tuple)
+	if err != nil {
+		return notificationEvent{}, err
+	}
+	return notificationEvent{
+		timestamp: kv.Value.Timestamp,
+		id:        id,
+		n: Notification{
+			Channel: string(tree.MustBeDString(datums[1])),
+			Payload: string(tree.MustBeDString(datums[2])),
+			PID:     int32(tree.MustBeDInt(datums[3])),
+		},
+	}, nil
+}
+
+// notificationEvent is a notification observed by the rangefeed on
+// system.notifications. It implements the rangefeedbuffer.Event interface.
+type notificationEvent struct {
+	// timestamp is the commit timestamp of the notification.
+	timestamp hlc.Timestamp
+	// id is the primary key of the notification.
+	id int64
+	n  Notification
+}
+
+// Timestamp implements the rangefeedbuffer.Event interface.
+func (e notificationEvent) Timestamp() hlc.Timestamp {
+	return e.timestamp
+}
+
+// notificationBuffer buffers the notifications observed by the rangefeed on
+// system.notifications until its frontier passes their commit timestamp, at
+// which point no notification can be committed before them anymore.
+type notificationBuffer struct {
+	buf *rangefeedbuffer.Buffer
+}
+
+func newNotificationBuffer(limit int) *notificationBuffer {
+	return &notificationBuffer{buf: rangefeedbuffer.New(limit)}
+}
+
+// add buffers the notification. It returns an error if the buffer is full.
+func (b *notificationBuffer) add(ev notificationEvent) error {
+	return b.buf.Add(ev)
+}
+
+// flush returns the notifications committed at or before the frontier, in
+// commit order. Notifications committed at the same timestamp are ordered by
+// id. The rangefeed delivers events at least once: the buffer discards the
+// events at or below a frontier it was already flushed to, and duplicate
+// events which were buffered are skipped here.
+func (b *notificationBuffer) flush(ctx context.Context, frontier hlc.Timestamp) []Notification {
+	events := b.buf.Flush(ctx, frontier)
+	if len(events) == 0 {
+		return nil
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i].(notificationEvent), events[j].(notificationEvent)
+		if a.timestamp != b.timestamp {
+			return a.timestamp.Less(b.timestamp)
+		}
+		return a.id < b.id
+	})
+	ns := make([]Notification, 0, len(events))
+	seen := make(map[int64]struct{}, len(events))
+	for _, ev := range events {
+		ev := ev.(notificationEvent)
+		if _, ok := seen[ev.id]; ok {
+			continue
+		}
+		seen[ev.id] = struct{}{}
+		ns = append(ns, ev.n)
+	}
+	return ns
+}
+
+// cleanupLoop periodically deletes the notifications which are older than
+// sql.notifications.retention. All notifications have been delivered by then,
+// since they are delivered as soon as the rangefeed frontier passes them. The
+// expired notifications are found through the index on created_at.
+func (r *Registry) cleanupLoop(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		timer.Reset(retention.Get(&r.st.SV) / 2)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		cutoff := timeutil.Now().Add(-retention.Get(&r.st.SV))
+		for {
+			n, err := r.db.Executor().ExecEx(
+				ctx, "delete-expired-notifications", nil, /* txn */
+				sessiondata.NodeUserSessionDataOverride,
+				`DELETE FROM system.notifications WHERE created_at < $1 LIMIT $2`,
+				cutoff, cleanupBatchSize,
+			)
+			if err != nil {
+				log.Warningf(ctx, "failed to delete expired notifications: %v", err)
+				break
+			}
+			if n < cleanupBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// dispatch hands the notification to the listeners of its channel.
+func (r *Registry) dispatch(n Notification) {
+	queueSize := int(maxQueueSize.Get(&r.st.SV))
+	behavior := OverflowBehavior(overflowBehavior.Get(&r.st.SV))
+	var overflowed []*Listener
+	func() {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		for l := range r.mu.listeners[n.Channel] {
+			if !l.enqueue(n, queueSize, behavior) {
+				overflowed = append(overflowed, l)
+			}
+		}
+	}()
+	for _, l := range overflowed {
+		l.UnlistenAll()
+	}
+}
+
+// NewListener returns a new Listener which does not listen on any channel
+// yet. The wake function is called, without any locks held, whenever a
+// notification is queued for delivery on a listener with an empty queue.
+func (r *Registry) NewListener(wake func()) *Listener {
+	l := &Listener{r: r, wake: wake}
+	l.mu.channels = make(map[string]struct{})
+	return l
+}
+
+// Listener queues the notifications published on the channels a session
+// listens on, until they are delivered to the client.
+type Listener struct {
+	r    *Registry
+	wake func()
+
+	mu struct {
+		syncutil.Mutex
+		channels map[string]struct{}
+		queue    []Notification
+		// dropped is the number of notifications discarded because the queue
+		// was full.
+		dropped int
+		// overflowed is set if the listener stopped listening because the queue
+		// was full.
+		overflowed bool
+	}
+}
+
+// Listen starts listening on the given channel. It is a no-op if the listener
+// is already listening on the channel.
+func (l *Listener) Listen(channel string) error {
+	if err := ValidateChannelName(channel); err != nil {
+		return err
+	}
+	// Locks are always acquired in the order Registry, then Listener.
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.channels[channel] = struct{}{}
+	listeners, ok := l.r.mu.listeners[channel]
+	if !ok {
+		listeners = make(map[*Listener]struct{})
+		l.r.mu.listeners[channel] = listeners
+	}
+	listeners[l] = struct{}{}
+	return nil
+}
+
+// Unlisten stops listening on the given channel. It is a no-op if the
+// listener is not listening on the channel.
+func (l *Listener) Unlisten(channel string) {
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.unlistenLocked(channel)
+}
+
+// UnlistenAll stops listening on all channels.
+func (l *Listener) UnlistenAll() {
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for channel := range l.mu.channels {
+		l.unlistenLocked(channel)
+	}
+}
+
+func (l *Listener) unlistenLocked(channel string) {
+	delete(l.mu.channels, channel)
+	if listeners, ok := l.r.mu.listeners[channel]; ok {
+		delete(listeners, l)
+		if len(listeners) == 0 {
+			delete(l.r.mu.listeners, channel)
+		}
+	}
+}
+
+// Channels returns the channels the listener is listening on.
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	channels := make([]string, 0, len(l.mu.channels))
+	for channel := range l.mu.channels {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// Close stops listening on all channels and discards the queued
+// notifications. The listener must not be used afterwards.
+func (l *Listener) Close() {
+	l.UnlistenAll()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.queue = nil
+}
+
+// enqueue queues the notification for delivery. It returns false if the queue
+// overflowed and the listener must stop listening.
+func (l *Listener) enqueue(n Notification, queueSize int, behavior OverflowBehavior) bool {
+	wake := false
+	defer func() {
+		if wake && l.wake != nil {
+			l.wake()
+		}
+	}()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.mu.channels[n.Channel]; !ok {
+		// The listener stopped listening on the channel concurrently.
+		return true
+	}
+	if len(l.mu.queue) >= queueSize {
+		l.mu.dropped++
+		if behavior == OverflowUnlisten {
+			l.mu.overflowed = true
+			return false
+		}
+		return true
+	}
+	wake = len(l.mu.queue) == 0
+	l.mu.queue = append(l.mu.queue, n)
+	return true
+}
+
+// Drain returns the queued notifications and empties the queue. It also
+// returns the number of notifications which were discarded because the queue
+// was full, and whether the listener stopped listening for that reason, since
+// the last call.
+func (l *Listener) Drain() (_ []Notification, dropped int, overflowed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queue := l.mu.queue
+	dropped, overflowed = l.mu.dropped, l.mu.overflowed
+	l.mu.queue, l.mu.dropped, l.mu.overflowed = nil, 0, false
+	return queue, dropped, overflowed
+}
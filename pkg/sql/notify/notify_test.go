@@ -0,0 +1,65 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNotificationBuffer checks that notifications are flushed once the
+// frontier passes them, in commit order, and exactly once.
+func TestNotificationBuffer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	ev := func(wallTime, id int64) notificationEvent {
+		return notificationEvent{
+			timestamp: hlc.Timestamp{WallTime: wallTime},
+			id:        id,
+			n:         Notification{Channel: "c", Payload: fmt.Sprint(id)},
+		}
+	}
+	payloads := func(ns []Notification) []string {
+		var res []string
+		for _, n := range ns {
+			res = append(res, n.Payload)
+		}
+		return res
+	}
+
+	b := newNotificationBuffer(10)
+	// Events of different keys arrive out of order, and some are redelivered.
+	for _, e := range []notificationEvent{
+		ev(30, 3), ev(10, 1), ev(20, 5), ev(20, 2), ev(10, 1), ev(40, 4),
+	} {
+		require.NoError(t, b.add(e))
+	}
+	require.Empty(t, b.flush(ctx, hlc.Timestamp{WallTime: 5}))
+	require.Equal(t, []string{"1", "2", "5"}, payloads(b.flush(ctx, hlc.Timestamp{WallTime: 20})))
+
+	// Events redelivered after the frontier passed them are dropped.
+	require.NoError(t, b.add(ev(20, 5)))
+	require.NoError(t, b.add(ev(30, 3)))
+	require.Equal(t, []string{"3", "4"}, payloads(b.flush(ctx, hlc.Timestamp{WallTime: 50})))
+	require.Empty(t, b.flush(ctx, hlc.Timestamp{WallTime: 60}))
+
+	// The buffer is bounded.
+	for i := int64(0); i < 10; i++ {
+		require.NoError(t, b.add(ev(100+i, 100+i)))
+	}
+	require.Error(t, b.add(ev(200, 200)))
+}
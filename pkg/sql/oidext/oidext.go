@@ -36,8 +36,26 @@ const (
 	T__box2d     = oid.Oid(90005)
 )
 
-// ExtensionTypeName returns a mapping from extension oids
-// to their type name.
+// OIDs in this block are the Postgres OIDs of the built-in multirange types,
+// which were added in Postgres 14 and are not in `github.com/lib/pq/oid`.
+const (
+	T_int4multirange  = oid.Oid(4451)
+	T_nummultirange   = oid.Oid(4532)
+	T_tsmultirange    = oid.Oid(4533)
+	T_tstzmultirange  = oid.Oid(4534)
+	T_datemultirange  = oid.Oid(4535)
+	T_int8multirange  = oid.Oid(4536)
+	T__int4multirange = oid.Oid(6150)
+	T__nummultirange  = oid.Oid(6151)
+	T__tsmultirange   = oid.Oid(6152)
+	T__tstzmultirange = oid.Oid(6153)
+	T__datemultirange = oid.Oid(6155)
+	T__int8multirange = oid.Oid(6157)
+	T_anymultirange   = oid.Oid(4537)
+)
+
+// ExtensionTypeName returns a mapping from extension oids, and from the oids
+// of types missing from `github.com/lib/pq/oid`, to their type name.
 var ExtensionTypeName = map[oid.Oid]string{
 	T_geometry:   "GEOMETRY",
 	T__geometry:  "_GEOMETRY",
@@ -45,6 +63,20 @@ var ExtensionTypeName = map[oid.Oid]string{
 	T__geography: "_GEOGRAPHY",
 	T_box2d:      "BOX2D",
 	T__box2d:     "_BOX2D",
+
+	T_int4multirange:  "INT4MULTIRANGE",
+	T__int4multirange: "_INT4MULTIRANGE",
+	T_int8multirange:  "INT8MULTIRANGE",
+	T__int8multirange: "_INT8MULTIRANGE",
+	T_nummultirange:   "NUMMULTIRANGE",
+	T__nummultirange:  "_NUMMULTIRANGE",
+	T_tsmultirange:    "TSMULTIRANGE",
+	T__tsmultirange:   "_TSMULTIRANGE",
+	T_tstzmultirange:  "TSTZMULTIRANGE",
+	T__tstzmultirange: "_TSTZMULTIRANGE",
+	T_datemultirange:  "DATEMULTIRANGE",
+	T__datemultirange: "_DATEMULTIRANGE",
+	T_anymultirange:   "ANYMULTIRANGE",
 }
 
 // TypeName checks the name for a given type by first looking up oid.TypeName
@@ -120,6 +120,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.AlterIndexVisible(ctx, n)
 	case *tree.AlterSchema:
 		return p.AlterSchema(ctx, n)
+	case *tree.AlterDomain:
+		return p.AlterDomain(ctx, n)
 	case *tree.AlterTable:
 		return p.AlterTable(ctx, n)
 	case *tree.AlterTableLocality:
@@ -160,10 +162,14 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.CommentOnTable(ctx, n)
 	case *tree.CreateDatabase:
 		return p.CreateDatabase(ctx, n)
+	case *tree.CreateDomain:
+		return p.CreateDomain(ctx, n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
 	case *tree.CreateSchema:
 		return p.CreateSchema(ctx, n)
+	case *tree.CreateTrigger:
+		return p.CreateTrigger(ctx, n)
 	case *tree.CreateType:
 		return p.CreateType(ctx, n)
 	case *tree.CreateRole:
@@ -186,6 +192,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.Discard(ctx, n)
 	case *tree.DropDatabase:
 		return p.DropDatabase(ctx, n)
+	case *tree.DropDomain:
+		return p.DropDomain(ctx, n)
 	case *tree.DropFunction:
 		return p.DropFunction(ctx, n)
 	case *tree.DropIndex:
@@ -202,6 +210,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.DropTable(ctx, n)
 	case *tree.DropTenant:
 		return p.DropTenant(ctx, n)
+	case *tree.DropTrigger:
+		return p.DropTrigger(ctx, n)
 	case *tree.DropType:
 		return p.DropType(ctx, n)
 	case *tree.DropView:
@@ -212,8 +222,12 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.Grant(ctx, n)
 	case *tree.GrantRole:
 		return p.GrantRole(ctx, n)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
 	case *tree.MoveCursor:
 		return p.FetchCursor(ctx, &n.CursorStmt, true /* isMove */)
+	case *tree.Notify:
+		return p.Notify(ctx, n)
 	case *tree.ReassignOwnedBy:
 		return p.ReassignOwnedBy(ctx, n)
 	case *tree.RefreshMaterializedView:
@@ -244,6 +258,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.SetVar(ctx, n)
 	case *tree.SetTransaction:
 		return p.SetTransaction(ctx, n)
+	case *tree.SetConstraints:
+		return p.SetConstraints(ctx, n)
 	case *tree.SetSessionAuthorizationDefault:
 		return p.SetSessionAuthorizationDefault()
 	case *tree.SetSessionCharacteristics:
@@ -312,6 +328,7 @@ func init() {
 		&tree.AlterIndex{},
 		&tree.AlterIndexVisible{},
 		&tree.AlterSchema{},
+		&tree.AlterDomain{},
 		&tree.AlterTable{},
 		&tree.AlterTableLocality{},
 		&tree.AlterTableOwner{},
@@ -332,18 +349,21 @@ func init() {
 		&tree.CommentOnConstraint{},
 		&tree.CommentOnTable{},
 		&tree.CreateDatabase{},
+		&tree.CreateDomain{},
 		&tree.CreateExtension{},
 		&tree.CreateExternalConnection{},
 		&tree.CreateTenant{},
 		&tree.CreateIndex{},
 		&tree.CreateSchema{},
 		&tree.CreateSequence{},
+		&tree.CreateTrigger{},
 		&tree.CreateType{},
 		&tree.CreateRole{},
 		&tree.Deallocate{},
 		&tree.DeclareCursor{},
 		&tree.Discard{},
 		&tree.DropDatabase{},
+		&tree.DropDomain{},
 		&tree.DropExternalConnection{},
 		&tree.DropFunction{},
 		&tree.DropIndex{},
@@ -353,12 +373,15 @@ func init() {
 		&tree.DropSequence{},
 		&tree.DropTable{},
 		&tree.DropTenant{},
+		&tree.DropTrigger{},
 		&tree.DropType{},
 		&tree.DropView{},
 		&tree.FetchCursor{},
 		&tree.Grant{},
 		&tree.GrantRole{},
+		&tree.Listen{},
 		&tree.MoveCursor{},
+		&tree.Notify{},
 		&tree.ReassignOwnedBy{},
 		&tree.RefreshMaterializedView{},
 		&tree.RenameColumn{},
@@ -374,6 +397,7 @@ func init() {
 		&tree.SetZoneConfig{},
 		&tree.SetVar{},
 		&tree.SetTransaction{},
+		&tree.SetConstraints{},
 		&tree.SetSessionAuthorizationDefault{},
 		&tree.SetSessionCharacteristics{},
 		&tree.ShowClusterSetting{},
@@ -15,7 +15,9 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/lib/pq/oid"
 )
 
 // Table is an interface to a database table, exposing only the information
@@ -106,6 +108,12 @@ type Table interface {
 	// Check returns the ith check constraint, where i < CheckCount.
 	Check(i int) CheckConstraint
 
+	// TriggerCount returns the number of triggers defined on the table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger, where i < TriggerCount.
+	Trigger(i int) Trigger
+
 	// FamilyCount returns the number of column families present on the table.
 	// There is always at least one primary family (always family 0) where columns
 	// go if they are not explicitly assigned to another family. The primary
@@ -139,6 +147,27 @@ type Table interface {
 	// i < UniqueCount.
 	Unique(i UniqueOrdinal) UniqueConstraint
 
+	// ExclusionConstraintCount returns the number of exclusion constraints
+	// defined on this table.
+	ExclusionConstraintCount() int
+
+	// ExclusionConstraint returns the ith exclusion constraint defined on this
+	// table, where i < ExclusionConstraintCount.
+	ExclusionConstraint(i int) ExclusionConstraint
+
+	// InheritanceParentCount returns the number of tables this table inherits
+	// from.
+	InheritanceParentCount() int
+
+	// InheritanceChildCount returns the number of tables which inherit from
+	// this table. Scans of the table include the rows of these tables, unless
+	// ONLY is specified.
+	InheritanceChildCount() int
+
+	// InheritanceChild returns the StableID of the ith table which inherits
+	// from this table, where i < InheritanceChildCount.
+	InheritanceChild(i int) StableID
+
 	// Zone returns a table's zone.
 	Zone() Zone
 
@@ -189,6 +218,45 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger describes a trigger defined on a table. The trigger function is
+// invoked before or after each row or statement affected by one of the trigger
+// events. For example:
+//
+//	CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW EXECUTE FUNCTION f()
+type Trigger struct {
+	Name       tree.Name
+	ActionTime tree.TriggerActionTime
+	Events     []TriggerEvent
+	ForEachRow bool
+
+	// WhenExpr is the serialized WHEN condition of the trigger, or the empty
+	// string if there is none. It refers to the row values using the NEW and
+	// OLD prefixes.
+	WhenExpr string
+
+	// FuncOID is the OID of the trigger function.
+	FuncOID oid.Oid
+}
+
+// TriggerEvent is an event which fires a trigger.
+type TriggerEvent struct {
+	EventType tree.TriggerEventType
+
+	// ColumnOrdinals lists the ordinals of the columns of an UPDATE OF event.
+	// It is empty if the trigger fires for updates of any column.
+	ColumnOrdinals []int
+}
+
+// HasEvent returns true if the trigger fires for the given event type.
+func (t *Trigger) HasEvent(typ tree.TriggerEventType) bool {
+	for i := range t.Events {
+		if t.Events[i].EventType == typ {
+			return true
+		}
+	}
+	return false
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -314,6 +382,10 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrability returns whether the checking of the foreign key can be
+	// deferred until the end of the transaction.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -355,6 +427,11 @@ type UniqueConstraint interface {
 	// needs to be enforced on new mutations.
 	Validated() bool
 
+	// Deferrability returns whether the checking of the constraint can be
+	// deferred until the end of the transaction. Only constraints that are not
+	// enforced by an index can be deferrable.
+	Deferrability() tree.ConstraintDeferrability
+
 	// UniquenessGuaranteedByAnotherIndex returns true when WithoutIndex() returns
 	// true and the uniqueness of the constraint is guaranteed by another index.
 	// When true, the optimizer will always consider the constraint to be
@@ -363,6 +440,40 @@ type UniqueConstraint interface {
 	UniquenessGuaranteedByAnotherIndex() bool
 }
 
+// ExclusionConstraint represents an exclusion constraint, which guarantees
+// that no two rows of a table return true for all of the constraint's
+// operators when compared on the constraint's columns. For example, the
+// following statement prevents two reservations of the same room from
+// overlapping:
+//
+//	ALTER TABLE r ADD CONSTRAINT c EXCLUDE USING gist (room WITH =, during WITH &&);
+//
+// Exclusion constraints are backed by an index, which makes it possible to
+// find conflicting rows efficiently, but the index does not enforce the
+// constraint by itself. In order to enforce the constraint, the optimizer must
+// add a check as a postquery to any query that inserts into or updates its
+// columns.
+type ExclusionConstraint interface {
+	// Name of the exclusion constraint.
+	Name() string
+
+	// ColumnCount returns the number of columns in this constraint.
+	ColumnCount() int
+
+	// ColumnOrdinal returns the table column ordinal of the ith column in this
+	// constraint.
+	ColumnOrdinal(tab Table, i int) int
+
+	// Operator returns the operator with which the values of the ith column of
+	// two rows are compared. It is either treecmp.EQ or treecmp.Overlaps.
+	Operator(i int) treecmp.ComparisonOperatorSymbol
+
+	// Predicate returns the partial predicate expression and true if the
+	// constraint only applies to the rows which satisfy a predicate. If it does
+	// not, the empty string and false are returned.
+	Predicate() (string, bool)
+}
+
 // UniqueOrdinal identifies a unique constraint (in the context of a Table).
 type UniqueOrdinal = int
 
@@ -226,6 +226,10 @@ func formatCatalogFKRef(
 		fmt.Fprintf(&extra, " ON DELETE %s", action.String())
 	}
 
+	if d := fkRef.Deferrability(); d.IsDeferrable() {
+		fmt.Fprintf(&extra, " %s", d.String())
+	}
+
 	tp.Childf(
 		"%s %s FOREIGN KEY %v %s REFERENCES %v %s%s",
 		title,
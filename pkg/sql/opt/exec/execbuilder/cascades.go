@@ -272,7 +272,16 @@ func (cb *cascadeBuilder) planCascade(
 		}
 	}
 
-	o.Memo().SetRoot(relExpr, &physical.Required{})
+	// Trigger invocations, unlike FK cascades, produce output columns. They
+	// must be part of the presentation, or the invocations would be pruned.
+	var required physical.Required
+	relExpr.Relational().OutputCols.ForEach(func(col opt.ColumnID) {
+		required.Presentation = append(required.Presentation, opt.AliasedColumn{
+			Alias: md.ColumnMeta(col).Alias,
+			ID:    col,
+		})
+	})
+	o.Memo().SetRoot(relExpr, &required)
 
 	// 3. Assign placeholders if they exist.
 	if factory.Memo().HasPlaceholders() {
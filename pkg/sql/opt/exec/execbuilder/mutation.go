@@ -123,6 +123,10 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -159,11 +163,19 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	// We cannot use the fast path if there are triggers to run after the
+	// insert.
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	md := b.mem.Metadata()
 	tab := md.Table(ins.Table)
 
 	//  - there are no self-referencing foreign keys;
-	//  - all FK checks can be performed using direct lookups into unique indexes.
+	//  - there are no deferrable foreign keys;
+	//  - all FK checks can be performed using direct lookups into unique indexes;
+	//  - the FK checks do not lock the referenced rows.
 	fkChecks := make([]exec.InsertFastPathFKCheck, len(ins.FKChecks))
 	for i := range ins.FKChecks {
 		c := &ins.FKChecks[i]
@@ -172,11 +184,20 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			return execPlan{}, false, nil
 		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
+		if fk.Deferrability().IsDeferrable() {
+			// The fast path cannot defer the check until the end of the
+			// transaction.
+			return execPlan{}, false, nil
+		}
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
 			return execPlan{}, false, nil
 		}
+		if lookupJoin.Locking.IsLocking() {
+			// The fast path cannot lock the referenced rows.
+			return execPlan{}, false, nil
+		}
 		// TODO(rytaft): see if we can remove the requirement that LookupExpr is
 		// empty.
 		if len(lookupJoin.On) > 0 || len(lookupJoin.LookupExpr) > 0 ||
@@ -483,6 +504,89 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	return ep, nil
 }
 
+func (b *Builder) buildMerge(mrg *memo.MergeExpr) (execPlan, error) {
+	// Currently, the execution engine requires one input column for each insert,
+	// fetch, and update expression, so use ensureColumns to map and reorder
+	// columns so that they correspond to target table columns, as for Upsert.
+	// The action column follows the update columns.
+	cnt := len(mrg.InsertCols) + len(mrg.FetchCols) + len(mrg.UpdateCols) + 1 +
+		len(mrg.PassthroughCols) + len(mrg.CheckCols) + len(mrg.PartialIndexPutCols) +
+		len(mrg.PartialIndexDelCols)
+	colList := make(opt.ColList, 0, cnt)
+	colList = appendColsWhenPresent(colList, mrg.InsertCols)
+	colList = appendColsWhenPresent(colList, mrg.FetchCols)
+	colList = appendColsWhenPresent(colList, mrg.UpdateCols)
+	colList = append(colList, mrg.MergeActionCol)
+	// The RETURNING clause of the Merge can refer to the columns of the source
+	// relation, so the Merge may need to pass those columns through.
+	if mrg.NeedResults() {
+		colList = append(colList, mrg.PassthroughCols...)
+	}
+	colList = appendColsWhenPresent(colList, mrg.CheckCols)
+	colList = appendColsWhenPresent(colList, mrg.PartialIndexPutCols)
+	colList = appendColsWhenPresent(colList, mrg.PartialIndexDelCols)
+
+	input, err := b.buildMutationInput(mrg, mrg.Input, colList, &mrg.MutationPrivate)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// Construct the Merge node.
+	md := b.mem.Metadata()
+	tab := md.Table(mrg.Table)
+	actionCol := input.getNodeColumnOrdinal(mrg.MergeActionCol)
+	insertColOrds := ordinalSetFromColList(mrg.InsertCols)
+	fetchColOrds := ordinalSetFromColList(mrg.FetchCols)
+	updateColOrds := ordinalSetFromColList(mrg.UpdateCols)
+	returnColOrds := ordinalSetFromColList(mrg.ReturnCols)
+	checkOrds := ordinalSetFromColList(mrg.CheckCols)
+
+	// Construct the result columns for the passthrough set.
+	var passthroughCols colinfo.ResultColumns
+	if mrg.NeedResults() {
+		for _, passthroughCol := range mrg.PassthroughCols {
+			colMeta := md.ColumnMeta(passthroughCol)
+			passthroughCols = append(passthroughCols, colinfo.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type})
+		}
+	}
+
+	node, err := b.factory.ConstructMerge(
+		input.root,
+		tab,
+		actionCol,
+		insertColOrds,
+		fetchColOrds,
+		updateColOrds,
+		returnColOrds,
+		checkOrds,
+		passthroughCols,
+		b.allowAutoCommit && len(mrg.UniqueChecks) == 0 &&
+			len(mrg.FKChecks) == 0 && len(mrg.FKCascades) == 0,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	if err := b.buildUniqueChecks(mrg.UniqueChecks); err != nil {
+		return execPlan{}, err
+	}
+
+	if err := b.buildFKChecks(mrg.FKChecks); err != nil {
+		return execPlan{}, err
+	}
+
+	if err := b.buildFKCascades(mrg.WithID, mrg.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
+	// Construct the output column map.
+	ep := execPlan{root: node}
+	if mrg.NeedResults() {
+		ep.outputCols = mutationOutputColMap(mrg)
+	}
+	return ep, nil
+}
+
 func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	// Check for the fast-path delete case that can use a range delete.
 	if ep, ok, err := b.tryBuildDeleteRange(del); err != nil || ok {
@@ -714,9 +818,16 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
+			if c.Exclusion {
+				return mkExclusionCheckErr(md, c, keyVals)
+			}
 			return mkUniqueCheckErr(md, c, keyVals)
 		}
-		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
+		var deferrable *exec.DeferrableConstraint
+		if !c.Exclusion {
+			deferrable = makeDeferrableUniqueCheck(md, c, &query)
+		}
+		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr, deferrable)
 		if err != nil {
 			return err
 		}
@@ -742,7 +853,8 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			}
 			return mkFKCheckErr(md, c, keyVals)
 		}
-		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
+		deferrable := makeDeferrableFKCheck(md, c, &query)
+		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr, deferrable)
 		if err != nil {
 			return err
 		}
@@ -751,6 +863,165 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// makeDeferrableUniqueCheck returns the information needed to defer the given
+// uniqueness check until the end of the transaction, or nil if the constraint
+// is not deferrable.
+func makeDeferrableUniqueCheck(
+	md *opt.Metadata, c *memo.UniqueChecksItem, query *execPlan,
+) *exec.DeferrableConstraint {
+	tabMeta := md.TableMeta(c.Table)
+	uc := tabMeta.Table.Unique(c.CheckOrdinal)
+	deferrability := uc.Deferrability()
+	if !deferrability.IsDeferrable() {
+		return nil
+	}
+
+	// Build a query of the form:
+	//   SELECT 1 FROM [<id> AS t] WHERE t.a = $1 AND t.b = $2 [AND (<pred>)]
+	//   LIMIT 1 OFFSET 1
+	// which returns a row if there are still duplicates of the key.
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "SELECT 1 FROM [%d AS t] WHERE ", tabMeta.Table.ID())
+	for i := 0; i < uc.ColumnCount(); i++ {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		col := tabMeta.Table.Column(uc.ColumnOrdinal(tabMeta.Table, i))
+		fmt.Fprintf(&buf, "t.%s = $%d", tree.NameString(string(col.ColName())), i+1)
+	}
+	if pred, isPartial := uc.Predicate(); isPartial {
+		fmt.Fprintf(&buf, " AND (%s)", pred)
+	}
+	buf.WriteString(" LIMIT 1 OFFSET 1")
+
+	return &exec.DeferrableConstraint{
+		TableID:           tabMeta.Table.ID(),
+		Name:              uc.Name(),
+		InitiallyDeferred: deferrability.IsInitiallyDeferred(),
+		KeyCols:           makeKeyColOrdinals(c.KeyCols, query),
+		Recheck:           buf.String(),
+		MkErr: func(keyVals tree.Datums) error {
+			return mkUniqueCheckErr(md, c, keyVals)
+		},
+	}
+}
+
+// makeDeferrableFKCheck returns the information needed to defer the given
+// foreign key check until the end of the transaction, or nil if the constraint
+// is not deferrable. Checks for ON DELETE or ON UPDATE RESTRICT are never
+// deferred, matching Postgres.
+func makeDeferrableFKCheck(
+	md *opt.Metadata, c *memo.FKChecksItem, query *execPlan,
+) *exec.DeferrableConstraint {
+	origin := md.TableMeta(c.OriginTable)
+	referenced := md.TableMeta(c.ReferencedTable)
+
+	var fk cat.ForeignKeyConstraint
+	if c.FKOutbound {
+		fk = origin.Table.OutboundForeignKey(c.FKOrdinal)
+	} else {
+		fk = referenced.Table.InboundForeignKey(c.FKOrdinal)
+		action := fk.UpdateReferenceAction()
+		if c.OpName == "delete" {
+			action = fk.DeleteReferenceAction()
+		}
+		if action == tree.Restrict {
+			return nil
+		}
+	}
+	deferrability := fk.Deferrability()
+	if !deferrability.IsDeferrable() {
+		return nil
+	}
+
+	// Both outbound and inbound checks are violated for a key if some row in
+	// the origin table still refers to it and no row in the referenced table
+	// has it. Build a query of the form:
+	//   SELECT 1 FROM [<origin id> AS o] WHERE o.a = $1 AND o.b = $2
+	//   AND NOT EXISTS (
+	//     SELECT 1 FROM [<referenced id> AS r] WHERE r.x = $1 AND r.y = $2
+	//   ) LIMIT 1
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "SELECT 1 FROM [%d AS o] WHERE ", origin.Table.ID())
+	for i := 0; i < fk.ColumnCount(); i++ {
+		col := origin.Table.Column(fk.OriginColumnOrdinal(origin.Table, i))
+		fmt.Fprintf(&buf, "o.%s = $%d AND ", tree.NameString(string(col.ColName())), i+1)
+	}
+	fmt.Fprintf(&buf, "NOT EXISTS (SELECT 1 FROM [%d AS r] WHERE ", referenced.Table.ID())
+	for i := 0; i < fk.ColumnCount(); i++ {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		col := referenced.Table.Column(fk.ReferencedColumnOrdinal(referenced.Table, i))
+		fmt.Fprintf(&buf, "r.%s = $%d", tree.NameString(string(col.ColName())), i+1)
+	}
+	buf.WriteString(") LIMIT 1")
+
+	return &exec.DeferrableConstraint{
+		TableID:           fk.OriginTableID(),
+		Name:              fk.Name(),
+		InitiallyDeferred: deferrability.IsInitiallyDeferred(),
+		KeyCols:           makeKeyColOrdinals(c.KeyCols, query),
+		Recheck:           buf.String(),
+		MkErr: func(keyVals tree.Datums) error {
+			return mkFKCheckErr(md, c, keyVals)
+		},
+	}
+}
+
+// makeKeyColOrdinals returns the ordinals of the given key columns in the
+// output of the check query.
+func makeKeyColOrdinals(keyCols opt.ColList, query *execPlan) []exec.NodeColumnOrdinal {
+	ords := make([]exec.NodeColumnOrdinal, len(keyCols))
+	for i, col := range keyCols {
+		ords[i] = query.getNodeColumnOrdinal(col)
+	}
+	return ords
+}
+
+// mkExclusionCheckErr generates a user-friendly error describing an exclusion
+// constraint violation. The keyVals are the values that correspond to the
+// cat.ExclusionConstraint columns.
+func mkExclusionCheckErr(
+	md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums,
+) error {
+	tabMeta := md.TableMeta(c.Table)
+	ec := tabMeta.Table.ExclusionConstraint(c.CheckOrdinal)
+	constraintName := ec.Name()
+	var msg, details bytes.Buffer
+
+	// Generate an error of the form:
+	//   ERROR:  conflicting key value violates exclusion constraint "foo"
+	//   DETAIL: Key (k, r)=(2, ...) conflicts with existing key.
+	msg.WriteString("conflicting key value violates exclusion constraint ")
+	lexbase.EncodeEscapedSQLIdent(&msg, constraintName)
+
+	details.WriteString("Key (")
+	for i := 0; i < ec.ColumnCount(); i++ {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		col := tabMeta.Table.Column(ec.ColumnOrdinal(tabMeta.Table, i))
+		details.WriteString(string(col.ColName()))
+	}
+	details.WriteString(")=(")
+	for i, d := range keyVals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(d.String())
+	}
+	details.WriteString(") conflicts with existing key.")
+
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(pgcode.ExclusionViolation, "%s", msg.String()),
+			constraintName,
+		),
+		details.String(),
+	)
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -894,7 +1165,21 @@ func (b *Builder) buildFKCascades(withID opt.WithID, cascades memo.FKCascades) e
 	if err != nil {
 		return err
 	}
+	var unbufferedCB *cascadeBuilder
 	for i := range cascades {
+		if withID != 0 && cascades[i].WithID == 0 &&
+			len(cascades[i].OldValues) == 0 && len(cascades[i].NewValues) == 0 {
+			// Statement-level triggers do not refer to the mutation input, and
+			// must be run even if no rows were modified. Fast path FK cascades
+			// do not refer to the buffer either, but are skipped in that case.
+			if unbufferedCB == nil {
+				if unbufferedCB, err = makeCascadeBuilder(b, 0 /* mutationWithID */); err != nil {
+					return err
+				}
+			}
+			b.cascades = append(b.cascades, unbufferedCB.setupCascade(&cascades[i]))
+			continue
+		}
 		b.cascades = append(b.cascades, cb.setupCascade(&cascades[i]))
 	}
 	return nil
@@ -927,7 +1212,7 @@ func (b *Builder) canAutoCommit(rel memo.RelExpr) bool {
 	}
 
 	switch rel.Op() {
-	case opt.InsertOp, opt.UpsertOp, opt.UpdateOp, opt.DeleteOp:
+	case opt.InsertOp, opt.UpsertOp, opt.MergeOp, opt.UpdateOp, opt.DeleteOp:
 		// Check that there aren't any more mutations in the input.
 		// TODO(radu): this can go away when all mutations are under top-level
 		// With ops.
@@ -976,6 +1261,11 @@ func (b *Builder) shouldApplyImplicitLockingToMutationInput(mutExpr memo.RelExpr
 	case *memo.UpsertExpr:
 		return b.shouldApplyImplicitLockingToUpsertInput(t)
 
+	case *memo.MergeExpr:
+		// The target rows of a MERGE are found by an arbitrary join with the
+		// source relation, so implicit row-level locking is not applied.
+		return false
+
 	case *memo.DeleteExpr:
 		return b.shouldApplyImplicitLockingToDeleteInput(t)
 
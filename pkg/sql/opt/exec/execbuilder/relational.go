@@ -250,6 +250,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.OrdinalityExpr:
 		ep, err = b.buildOrdinality(t)
 
+	case *memo.ExpandExpr:
+		ep, err = b.buildExpand(t)
+
 	case *memo.MergeJoinExpr:
 		ep, err = b.buildMergeJoin(t)
 
@@ -277,6 +280,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.UpsertExpr:
 		ep, err = b.buildUpsert(t)
 
+	case *memo.MergeExpr:
+		ep, err = b.buildMerge(t)
+
 	case *memo.DeleteExpr:
 		ep, err = b.buildDelete(t)
 
@@ -289,6 +295,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.CreateFunctionExpr:
 		ep, err = b.buildCreateFunction(t)
 
+	case *memo.CallExpr:
+		ep, err = b.buildCall(t)
+
 	case *memo.WithExpr:
 		ep, err = b.buildWith(t)
 
@@ -2033,6 +2042,42 @@ func (b *Builder) buildOrdinality(ord *memo.OrdinalityExpr) (execPlan, error) {
 	return execPlan{root: node, outputCols: outputCols}, nil
 }
 
+func (b *Builder) buildExpand(expand *memo.ExpandExpr) (execPlan, error) {
+	input, err := b.buildRelational(expand.Input)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	groupingCols := make([]exec.NodeColumnOrdinal, len(expand.GroupingCols))
+	for i, col := range expand.GroupingCols {
+		groupingCols[i] = input.getNodeColumnOrdinal(col)
+	}
+	groupingSets := make([]exec.NodeColumnOrdinalSet, len(expand.GroupingSets))
+	for i := range expand.GroupingSets {
+		groupingSets[i] = input.getNodeColumnOrdinalSet(expand.GroupingSets[i])
+	}
+
+	node, err := b.factory.ConstructExpand(input.root, groupingCols, groupingSets)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The copies of the grouping columns, the grouping ID column and the input
+	// row column (if any) are ordered at the end of the list.
+	outputCols := input.outputCols.Copy()
+	numCols := input.numOutputCols()
+	for _, col := range expand.OutCols {
+		outputCols.Set(int(col), numCols)
+		numCols++
+	}
+	outputCols.Set(int(expand.GroupingIDCol), numCols)
+	if expand.InputRowCol != 0 {
+		outputCols.Set(int(expand.InputRowCol), numCols+1)
+	}
+
+	return execPlan{root: node, outputCols: outputCols}, nil
+}
+
 func (b *Builder) buildIndexJoin(join *memo.IndexJoinExpr) (execPlan, error) {
 	input, err := b.buildRelational(join.Input)
 	if err != nil {
@@ -3369,6 +3414,7 @@ var boundedStalenessAllowList = map[opt.Operator]struct{}{
 	opt.OffsetOp:           {},
 	opt.SortOp:             {},
 	opt.OrdinalityOp:       {},
+	opt.ExpandOp:           {},
 	opt.Max1RowOp:          {},
 	opt.ProjectSetOp:       {},
 	opt.WindowOp:           {},
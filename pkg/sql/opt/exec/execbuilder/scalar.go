@@ -784,12 +784,8 @@ func (b *Builder) buildSubquery(
 			if len(eb.subqueries) > 0 {
 				return expectedLazyRoutineError("subquery")
 			}
-			if len(eb.cascades) > 0 {
-				return expectedLazyRoutineError("cascade")
-			}
-			if len(eb.checks) > 0 {
-				return expectedLazyRoutineError("check")
-			}
+			// Cascades and checks of mutations in the routine body are part of
+			// the plan and are executed along with it.
 			plan, err := b.factory.ConstructPlan(
 				ePlan.root, nil /* subqueries */, nil /* cascades */, nil /* checks */, inputRowCount,
 			)
@@ -870,6 +866,15 @@ func (b *Builder) buildUDF(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Typ
 		}
 	}
 
+	// Enable stepping for volatile functions so that statements within the UDF
+	// see mutations made by the invoking statement and by previous executed
+	// statements.
+	enableStepping := udf.Volatility == volatility.Volatile
+
+	if udf.Program != nil {
+		return b.buildPLpgSQLRoutine(udf, args, enableStepping), nil
+	}
+
 	// Create a tree.RoutinePlanFn that can plan the statements in the UDF body.
 	// TODO(mgartner): Add support for WITH expressions inside UDF bodies.
 	planGen := b.buildRoutinePlanGenerator(
@@ -879,11 +884,6 @@ func (b *Builder) buildUDF(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Typ
 		nil,   /* wrapRootExpr */
 	)
 
-	// Enable stepping for volatile functions so that statements within the UDF
-	// see mutations made by the invoking statement and by previous executed
-	// statements.
-	enableStepping := udf.Volatility == volatility.Volatile
-
 	return tree.NewTypedRoutineExpr(
 		udf.Name,
 		args,
@@ -894,6 +894,46 @@ func (b *Builder) buildUDF(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Typ
 	), nil
 }
 
+// buildPLpgSQLRoutine builds a UDF with a PL/pgSQL body. Each expression of
+// the program is built into a separate routine, which the interpreter invokes
+// with the current values of the variables of the program as arguments.
+func (b *Builder) buildPLpgSQLRoutine(
+	udf *memo.UDFExpr, args tree.TypedExprs, enableStepping bool,
+) *tree.RoutineExpr {
+	exprs := make([]*tree.RoutineExpr, len(udf.Body))
+	for i := range udf.Body {
+		planGen := b.buildRoutinePlanGenerator(
+			udf.Params,
+			udf.Body[i:i+1],
+			false, /* allowOuterWithRefs */
+			nil,   /* wrapRootExpr */
+		)
+		typ := types.Void
+		if pres := udf.Body[i].PhysProps.Presentation; len(pres) > 0 {
+			typ = b.mem.Metadata().ColumnMeta(pres[0].ID).Type
+		}
+		exprs[i] = tree.NewTypedRoutineExpr(
+			udf.Name,
+			nil, /* args */
+			planGen,
+			typ,
+			enableStepping,
+			true, /* calledOnNullInput */
+		)
+	}
+	routine := tree.NewTypedRoutineExpr(
+		udf.Name,
+		args,
+		nil, /* gen */
+		udf.Typ,
+		enableStepping,
+		udf.CalledOnNullInput,
+	)
+	routine.Program = udf.Program
+	routine.ProgramExprs = exprs
+	return routine
+}
+
 type wrapRootExprFn func(f *norm.Factory, e memo.RelExpr) opt.Expr
 
 // buildRoutinePlanGenerator returns a tree.RoutinePlanFn that can plan the
@@ -1018,12 +1058,9 @@ func (b *Builder) buildRoutinePlanGenerator(
 			if len(eb.subqueries) > 0 {
 				return expectedLazyRoutineError("subquery")
 			}
-			if len(eb.cascades) > 0 {
-				return expectedLazyRoutineError("cascade")
-			}
-			if len(eb.checks) > 0 {
-				return expectedLazyRoutineError("check")
-			}
+			// Cascades and checks of mutations in the routine body, e.g. in
+			// the body of a procedure, are part of the plan and are executed
+			// along with it.
 			isFinalPlan := i == len(stmts)-1
 			err = fn(plan, isFinalPlan)
 			if err != nil {
@@ -70,6 +70,7 @@ func (b *Builder) buildCreateView(cv *memo.CreateViewExpr) (execPlan, error) {
 		cv.Deps,
 		cv.TypeDeps,
 		cv.WithData,
+		cv.Incremental,
 	)
 	return execPlan{root: root}, err
 }
@@ -86,6 +87,23 @@ func (b *Builder) buildCreateFunction(cf *memo.CreateFunctionExpr) (execPlan, er
 	return execPlan{root: root}, err
 }
 
+func (b *Builder) buildCall(c *memo.CallExpr) (execPlan, error) {
+	scalarCtx := buildScalarCtx{}
+	proc, err := b.buildScalar(&scalarCtx, c.Proc)
+	if err != nil {
+		return execPlan{}, err
+	}
+	cols := make(colinfo.ResultColumns, len(c.Columns))
+	for i, col := range c.Columns {
+		cols[i] = b.resultColumn(col)
+	}
+	node, err := b.factory.ConstructCall(proc, cols, c.Overload, c.TxnOp, c.ResumeAt)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return planWithColumns(node, c.Columns), nil
+}
+
 func (b *Builder) buildExplainOpt(explain *memo.ExplainExpr) (execPlan, error) {
 	fmtFlags := memo.ExprFmtHideAll
 	switch {
@@ -286,6 +286,7 @@ var nodeNames = [...]string{
 	alterTableUnsplitOp:    "unsplit",
 	applyJoinOp:            "", // This node does not have a fixed name.
 	bufferOp:               "buffer",
+	callOp:                 "call",
 	cancelQueriesOp:        "cancel queries",
 	cancelSessionsOp:       "cancel sessions",
 	controlJobsOp:          "control jobs",
@@ -301,6 +302,7 @@ var nodeNames = [...]string{
 	errorIfRowsOp:          "error if rows",
 	explainOp:              "explain",
 	explainOptOp:           "explain",
+	expandOp:               "expand",
 	exportOp:               "export",
 	filterOp:               "filter",
 	groupByOp:              "", // This node does not have a fixed name.
@@ -313,6 +315,7 @@ var nodeNames = [...]string{
 	limitOp:                "limit",
 	lookupJoinOp:           "", // This node does not have a fixed name.
 	max1RowOp:              "max1row",
+	mergeOp:                "merge",
 	mergeJoinOp:            "", // This node does not have a fixed name.
 	opaqueOp:               "", // This node does not have a fixed name.
 	ordinalityOp:           "ordinality",
@@ -674,6 +677,15 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			ob.Attr("order key", printColumnSet(inputCols, a.OrderedCols))
 		}
 
+	case expandOp:
+		a := n.args.(*expandArgs)
+		inputCols := a.Input.Columns()
+		sets := make([]string, len(a.GroupingSets))
+		for i := range a.GroupingSets {
+			sets[i] = fmt.Sprintf("(%s)", printColumnSet(inputCols, a.GroupingSets[i]))
+		}
+		ob.Attr("grouping sets", strings.Join(sets, ", "))
+
 	case hashJoinOp:
 		a := n.args.(*hashJoinArgs)
 		e.emitJoinAttributes(
@@ -885,6 +897,19 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			ob.Attr("arbiter constraints", sb.String())
 		}
 
+	case mergeOp:
+		a := n.args.(*mergeArgs)
+		ob.Attrf("into", "%s", a.Table.Name())
+		if !a.InsertCols.Empty() {
+			ob.Attr("insert", printColumns(tableColumns(a.Table, a.InsertCols)))
+		}
+		if !a.UpdateCols.Empty() {
+			ob.Attr("set", printColumns(tableColumns(a.Table, a.UpdateCols)))
+		}
+		if a.AutoCommit {
+			ob.Attr("auto commit", "")
+		}
+
 	case updateOp:
 		a := n.args.(*updateArgs)
 		ob.Attrf("table", "%s", a.Table.Name())
@@ -943,6 +968,13 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			ob.Attrf("deduplicate", "")
 		}
 
+	case callOp:
+		a := n.args.(*callArgs)
+		ob.Expr("procedure", a.Proc, nil /* columns */)
+		if a.TxnOp != tree.StoredProcTxnNoOp {
+			ob.Attr("transaction", a.TxnOp.String())
+		}
+
 	case alterRangeRelocateOp:
 		a := n.args.(*alterRangeRelocateArgs)
 		ob.Attr("replicas", a.subjectReplicas)
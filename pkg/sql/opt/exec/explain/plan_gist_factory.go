@@ -36,7 +36,7 @@ import (
 )
 
 func init() {
-	if numOperators != 61 {
+	if numOperators != 64 {
 		// This error occurs when an operator has been added or removed in
 		// pkg/sql/opt/exec/explain/factory.opt. If an operator is added at the
 		// end of factory.opt, simply adjust the hardcoded value above. If an
@@ -543,6 +543,14 @@ func (u *unknownTable) Check(i int) cat.CheckConstraint {
 	panic(errors.AssertionFailedf("not implemented"))
 }
 
+func (u *unknownTable) TriggerCount() int {
+	return 0
+}
+
+func (u *unknownTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
 func (u *unknownTable) FamilyCount() int {
 	return 0
 }
@@ -575,6 +583,26 @@ func (u *unknownTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("not implemented"))
 }
 
+func (u *unknownTable) ExclusionConstraintCount() int {
+	return 0
+}
+
+func (u *unknownTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
+func (u *unknownTable) InheritanceParentCount() int {
+	return 0
+}
+
+func (u *unknownTable) InheritanceChildCount() int {
+	return 0
+}
+
+func (u *unknownTable) InheritanceChild(i int) cat.StableID {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
 func (u *unknownTable) Zone() cat.Zone {
 	return cat.EmptyZone()
 }
@@ -98,6 +98,10 @@ func getResultColumns(
 		a := args.(*groupByArgs)
 		return groupByColumns(inputs[0], a.GroupCols, a.Aggregations), nil
 
+	case expandOp:
+		a := args.(*expandArgs)
+		return expandColumns(inputs[0], a.GroupingCols, a.GroupingSets), nil
+
 	case scalarGroupByOp:
 		a := args.(*scalarGroupByArgs)
 		return groupByColumns(inputs[0], nil /* groupCols */, a.Aggregations), nil
@@ -148,6 +152,13 @@ func getResultColumns(
 		a := args.(*upsertArgs)
 		return tableColumns(a.Table, a.ReturnCols), nil
 
+	case mergeOp:
+		a := args.(*mergeArgs)
+		return appendColumns(
+			tableColumns(a.Table, a.ReturnCols),
+			a.Passthrough...,
+		), nil
+
 	case deleteOp:
 		a := args.(*deleteArgs)
 		return tableColumns(a.Table, a.ReturnCols), nil
@@ -176,6 +187,9 @@ func getResultColumns(
 	case exportOp:
 		return colinfo.ExportColumns, nil
 
+	case callOp:
+		return args.(*callArgs).Columns, nil
+
 	case sequenceSelectOp:
 		return colinfo.SequenceSelectColumns, nil
 
@@ -267,6 +281,26 @@ func groupByColumns(
 	return columns
 }
 
+func expandColumns(
+	inputCols colinfo.ResultColumns,
+	groupingCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+) colinfo.ResultColumns {
+	columns := make(colinfo.ResultColumns, 0, len(inputCols)+len(groupingCols)+2)
+	columns = append(columns, inputCols...)
+	for _, col := range groupingCols {
+		columns = append(columns, colinfo.ResultColumn{Name: inputCols[col].Name, Typ: inputCols[col].Typ})
+	}
+	columns = append(columns, colinfo.ResultColumn{Name: "grouping_id", Typ: types.Int})
+	for i := range groupingSets {
+		if groupingSets[i].Empty() {
+			columns = append(columns, colinfo.ResultColumn{Name: "input_row", Typ: types.Bool})
+			break
+		}
+	}
+	return columns
+}
+
 func appendColumns(
 	input colinfo.ResultColumns, others ...colinfo.ResultColumn,
 ) colinfo.ResultColumns {
@@ -271,6 +271,34 @@ type InsertFastPathFKCheck struct {
 // relevant row.
 type MkErrFn func(tree.Datums) error
 
+// DeferrableConstraint contains information about a constraint check which
+// can be deferred until the end of the transaction (see ConstructErrorIfRows).
+// Whether the check is actually deferred is decided at execution time, based
+// on the constraint's initial mode and any SET CONSTRAINTS statements in the
+// transaction.
+type DeferrableConstraint struct {
+	// TableID is the ID of the table that owns the constraint.
+	TableID cat.StableID
+
+	// Name is the name of the constraint.
+	Name string
+
+	// InitiallyDeferred is true if the constraint was declared INITIALLY
+	// DEFERRED.
+	InitiallyDeferred bool
+
+	// KeyCols are the columns of the check query's rows that contain the key
+	// values of a potential violation, in the order of the constraint columns.
+	KeyCols []NodeColumnOrdinal
+
+	// Recheck is a query that takes the key values as placeholders ($1, $2,
+	// ...) and returns a row if the constraint is still violated for them.
+	Recheck string
+
+	// MkErr generates the violation error; it is passed the key values.
+	MkErr MkErrFn
+}
+
 // ExplainFactory is an extension of Factory used when constructing a plan that
 // can be explained. It allows annotation of nodes with extra information.
 type ExplainFactory interface {
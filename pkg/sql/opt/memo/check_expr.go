@@ -203,6 +203,24 @@ func (m *Memo) CheckExpr(e opt.Expr) {
 			}
 		}
 
+	case *ExpandExpr:
+		if len(t.GroupingCols) != len(t.OutCols) {
+			panic(errors.AssertionFailedf("expand grouping and output columns have different lengths"))
+		}
+		groupingCols := t.GroupingCols.ToSet()
+		for i := range t.GroupingSets {
+			if !t.GroupingSets[i].SubsetOf(groupingCols) {
+				panic(errors.AssertionFailedf(
+					"grouping set %s is not a subset of the grouping columns", t.GroupingSets[i]))
+			}
+		}
+		if t.GroupingIDCol == 0 {
+			panic(errors.AssertionFailedf("expand grouping ID column cannot have id of 0"))
+		}
+		if t.GroupingSets.HasEmptySet() != (t.InputRowCol != 0) {
+			panic(errors.AssertionFailedf("expand input row column must be set iff there is an empty grouping set"))
+		}
+
 	case *IndexJoinExpr:
 		if t.Cols.Empty() {
 			panic(errors.AssertionFailedf("index join with no columns"))
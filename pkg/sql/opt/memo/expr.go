@@ -1147,9 +1147,9 @@ func ExprIsNeverNull(e opt.ScalarExpr, notNullCols opt.ColSet) bool {
 	case *AndExpr, *OrExpr, *GeExpr, *GtExpr, *NeExpr, *EqExpr, *LeExpr, *LtExpr, *LikeExpr,
 		*NotLikeExpr, *ILikeExpr, *NotILikeExpr, *SimilarToExpr, *NotSimilarToExpr, *RegMatchExpr,
 		*NotRegMatchExpr, *RegIMatchExpr, *NotRegIMatchExpr, *ContainsExpr, *ContainedByExpr, *JsonExistsExpr,
-		*JsonAllExistsExpr, *JsonSomeExistsExpr, *AnyScalarExpr, *BitandExpr, *BitorExpr, *BitxorExpr,
-		*PlusExpr, *MinusExpr, *MultExpr, *DivExpr, *FloorDivExpr, *ModExpr, *PowExpr, *ConcatExpr,
-		*LShiftExpr, *RShiftExpr, *WhenExpr:
+		*JsonAllExistsExpr, *JsonSomeExistsExpr, *AdjacentExpr, *AnyScalarExpr, *BitandExpr, *BitorExpr,
+		*BitxorExpr, *PlusExpr, *MinusExpr, *MultExpr, *DivExpr, *FloorDivExpr, *ModExpr, *PowExpr,
+		*ConcatExpr, *LShiftExpr, *RShiftExpr, *WhenExpr:
 		return ExprIsNeverNull(t.Child(0).(opt.ScalarExpr), notNullCols) &&
 			ExprIsNeverNull(t.Child(1).(opt.ScalarExpr), notNullCols)
 
@@ -1286,6 +1286,20 @@ type CascadeBuilder interface {
 	) (RelExpr, error)
 }
 
+// GroupingSets stores the grouping sets of an Expand operator. Each grouping set
+// is a subset of the grouping columns of the operator.
+type GroupingSets []opt.ColSet
+
+// HasEmptySet returns true if one of the grouping sets is empty.
+func (s GroupingSets) HasEmptySet() bool {
+	for i := range s {
+		if s[i].Empty() {
+			return true
+		}
+	}
+	return false
+}
+
 // GroupingOrderType is the grouping column order type for group by and distinct
 // operations in the memo.
 type GroupingOrderType int
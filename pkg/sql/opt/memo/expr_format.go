@@ -269,11 +269,11 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		f.Buffer.WriteByte(')')
 
 	case *ScanExpr, *PlaceholderScanExpr, *IndexJoinExpr, *ShowTraceForSessionExpr,
-		*InsertExpr, *UpdateExpr, *UpsertExpr, *DeleteExpr, *SequenceSelectExpr,
+		*InsertExpr, *UpdateExpr, *UpsertExpr, *MergeExpr, *DeleteExpr, *SequenceSelectExpr,
 		*WindowExpr, *OpaqueRelExpr, *OpaqueMutationExpr, *OpaqueDDLExpr,
 		*AlterTableSplitExpr, *AlterTableUnsplitExpr, *AlterTableUnsplitAllExpr,
 		*AlterTableRelocateExpr, *AlterRangeRelocateExpr, *ControlJobsExpr, *CancelQueriesExpr,
-		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr, *ShowCompletionsExpr:
+		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr, *ShowCompletionsExpr, *CallExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -383,6 +383,20 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			tp.Childf("error: \"%s\"", private.ErrorOnDup)
 		}
 
+	case *ExpandExpr:
+		if !f.HasFlags(ExprFmtHideColumns) {
+			f.formatColList(tp, "grouping columns:", t.GroupingCols, t.Input.Relational().NotNullCols)
+			f.formatRelColList(e, tp, "output columns:", t.OutCols)
+		}
+		if !f.HasFlags(ExprFmtHideMiscProps) {
+			f.Buffer.Reset()
+			f.Buffer.WriteString("grouping sets:")
+			for i := range t.GroupingSets {
+				fmt.Fprintf(f.Buffer, " %s", t.GroupingSets[i])
+			}
+			tp.Child(f.Buffer.String())
+		}
+
 	case *TopKExpr:
 		if !f.HasFlags(ExprFmtHidePhysProps) && !t.Ordering.Any() {
 			tp.Childf("internal-ordering: %s", t.Ordering)
@@ -677,6 +691,23 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			f.formatMutationCommon(tp, &t.MutationPrivate)
 		}
 
+	case *MergeExpr:
+		if !f.HasFlags(ExprFmtHideColumns) {
+			if len(colList) == 0 {
+				tp.Child("columns: <none>")
+			}
+			f.formatRelColList(e, tp, "merge action column:", opt.ColList{t.MergeActionCol})
+			f.formatOptionalColList(e, tp, "fetch columns:", t.FetchCols)
+			f.formatMutationCols(e, tp, "insert-mapping:", t.InsertCols, t.Table)
+			f.formatMutationCols(e, tp, "update-mapping:", t.UpdateCols, t.Table)
+			f.formatMutationCols(e, tp, "return-mapping:", t.ReturnCols, t.Table)
+			f.formatOptionalColList(e, tp, "passthrough columns:", opt.OptionalColList(t.PassthroughCols))
+			f.formatOptionalColList(e, tp, "check columns:", t.CheckCols)
+			f.formatOptionalColList(e, tp, "partial index put columns:", t.PartialIndexPutCols)
+			f.formatOptionalColList(e, tp, "partial index del columns:", t.PartialIndexDelCols)
+			f.formatMutationCommon(tp, &t.MutationPrivate)
+		}
+
 	case *DeleteExpr:
 		if !f.HasFlags(ExprFmtHideColumns) {
 			if len(colList) == 0 {
@@ -1189,13 +1220,24 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *UniqueChecksItem:
 		tab := f.Memo.metadata.TableMeta(t.Table)
-		constraint := tab.Table.Unique(t.CheckOrdinal)
-		fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
-		for i := 0; i < constraint.ColumnCount(); i++ {
+		var colCount int
+		var colOrdinal func(i int) int
+		if t.Exclusion {
+			constraint := tab.Table.ExclusionConstraint(t.CheckOrdinal)
+			colCount = constraint.ColumnCount()
+			colOrdinal = func(i int) int { return constraint.ColumnOrdinal(tab.Table, i) }
+			fmt.Fprintf(f.Buffer, ": exclusion %s(", tab.Alias.ObjectName)
+		} else {
+			constraint := tab.Table.Unique(t.CheckOrdinal)
+			colCount = constraint.ColumnCount()
+			colOrdinal = func(i int) int { return constraint.ColumnOrdinal(tab.Table, i) }
+			fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
+		}
+		for i := 0; i < colCount; i++ {
 			if i > 0 {
 				f.Buffer.WriteByte(',')
 			}
-			col := tab.Table.Column(constraint.ColumnOrdinal(tab.Table, i))
+			col := tab.Table.Column(colOrdinal(i))
 			f.Buffer.WriteString(string(col.ColName()))
 		}
 		f.Buffer.WriteByte(')')
@@ -1699,6 +1741,11 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 	case *JoinPrivate:
 		// Nothing to show; flags are shown separately.
 
+	case *CallPrivate:
+		if t.TxnOp != tree.StoredProcTxnNoOp {
+			fmt.Fprintf(f.Buffer, " [%s]", t.TxnOp)
+		}
+
 	case *ExplainPrivate, *opt.ColSet, *types.T, *ExportPrivate:
 		// Don't show anything, because it's mostly redundant.
 
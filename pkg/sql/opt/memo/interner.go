@@ -531,6 +531,16 @@ func (h *hasher) HashJoinFlags(val JoinFlags) {
 	h.HashUint64(uint64(val))
 }
 
+func (h *hasher) HashGroupingSets(val GroupingSets) {
+	h.HashInt(len(val))
+	for i := range val {
+		// Hash the size of each set, so that the position of empty sets is
+		// reflected in the hash.
+		h.HashInt(val[i].Len())
+		h.HashColSet(val[i])
+	}
+}
+
 func (h *hasher) HashFKCascades(val FKCascades) {
 	for i := range val {
 		h.HashUint64(uint64(reflect.ValueOf(val[i].Builder).Pointer()))
@@ -557,6 +567,10 @@ func (h *hasher) HashShowTraceType(val tree.ShowTraceType) {
 	h.HashString(string(val))
 }
 
+func (h *hasher) HashStoredProcTxnOp(val tree.StoredProcTxnOp) {
+	h.HashUint64(uint64(val))
+}
+
 func (h *hasher) HashJobCommand(val tree.JobCommand) {
 	h.HashInt(int(val))
 }
@@ -965,6 +979,18 @@ func (h *hasher) IsJoinFlagsEqual(l, r JoinFlags) bool {
 	return l == r
 }
 
+func (h *hasher) IsGroupingSetsEqual(l, r GroupingSets) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if !l[i].Equals(r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *hasher) IsFKCascadesEqual(l, r FKCascades) bool {
 	if len(l) != len(r) {
 		return false
@@ -990,6 +1016,10 @@ func (h *hasher) IsShowTraceTypeEqual(l, r tree.ShowTraceType) bool {
 	return l == r
 }
 
+func (h *hasher) IsStoredProcTxnOpEqual(l, r tree.StoredProcTxnOp) bool {
+	return l == r
+}
+
 func (h *hasher) IsJobCommandEqual(l, r tree.JobCommand) bool {
 	return l == r
 }
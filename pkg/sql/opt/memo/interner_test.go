@@ -342,6 +342,13 @@ func TestInterner(t *testing.T) {
 			{val1: opt.OptionalColList{1, 2}, val2: opt.OptionalColList{1, 2, 3}, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashGroupingSets, eqFn: in.hasher.IsGroupingSetsEqual, variations: []testVariation{
+			{val1: GroupingSets{}, val2: GroupingSets{}, equal: true},
+			{val1: GroupingSets{opt.MakeColSet(1, 2), opt.MakeColSet()}, val2: GroupingSets{opt.MakeColSet(2, 1), opt.MakeColSet()}, equal: true},
+			{val1: GroupingSets{opt.MakeColSet(1, 2), opt.MakeColSet()}, val2: GroupingSets{opt.MakeColSet(), opt.MakeColSet(1, 2)}, equal: false},
+			{val1: GroupingSets{}, val2: GroupingSets{opt.MakeColSet()}, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashOrdering, eqFn: in.hasher.IsOrderingEqual, variations: []testVariation{
 			{val1: opt.Ordering{}, val2: opt.Ordering{}, equal: true},
 			{val1: opt.Ordering{-1, 1}, val2: opt.Ordering{-1, 1}, equal: true},
@@ -435,6 +442,11 @@ func TestInterner(t *testing.T) {
 			{val1: tree.ShowTraceKV, val2: tree.ShowTraceRaw, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashStoredProcTxnOp, eqFn: in.hasher.IsStoredProcTxnOpEqual, variations: []testVariation{
+			{val1: tree.StoredProcTxnCommit, val2: tree.StoredProcTxnCommit, equal: true},
+			{val1: tree.StoredProcTxnCommit, val2: tree.StoredProcTxnRollback, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashIndexOrdinal, eqFn: in.hasher.IsIndexOrdinalEqual, variations: []testVariation{
 			{val1: 0, val2: 0, equal: true},
 			{val1: 0, val2: 1, equal: false},
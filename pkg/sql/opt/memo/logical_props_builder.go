@@ -1020,6 +1020,10 @@ func (b *logicalPropsBuilder) buildExplainProps(explain *ExplainExpr, rel *props
 	b.buildBasicProps(explain, explain.ColList, rel)
 }
 
+func (b *logicalPropsBuilder) buildCallProps(call *CallExpr, rel *props.Relational) {
+	b.buildBasicProps(call, call.Columns, rel)
+}
+
 func (b *logicalPropsBuilder) buildShowTraceForSessionProps(
 	showTrace *ShowTraceForSessionExpr, rel *props.Relational,
 ) {
@@ -1413,6 +1417,88 @@ func (b *logicalPropsBuilder) buildProjectSetProps(
 	}
 }
 
+func (b *logicalPropsBuilder) buildExpandProps(expand *ExpandExpr, rel *props.Relational) {
+	BuildSharedProps(expand, &rel.Shared, b.evalCtx)
+
+	inputProps := expand.Input.Relational()
+
+	// Output Columns
+	// --------------
+	// Output columns are the input columns, the nulled copies of the grouping
+	// columns, the grouping ID column and the input row column (if any).
+	rel.OutputCols = inputProps.OutputCols.Union(expand.OutCols.ToSet())
+	rel.OutputCols.Add(expand.GroupingIDCol)
+	if expand.InputRowCol != 0 {
+		rel.OutputCols.Add(expand.InputRowCol)
+	}
+
+	// Not Null Columns
+	// ----------------
+	// The grouping ID column and the input row column are never null. If there
+	// is no empty grouping set, the input columns inherit the not null property
+	// from the input, and so do the copies of the grouping columns that are
+	// part of every grouping set.
+	rel.NotNullCols = opt.MakeColSet(expand.GroupingIDCol)
+	if expand.InputRowCol != 0 {
+		rel.NotNullCols.Add(expand.InputRowCol)
+	} else {
+		rel.NotNullCols.UnionWith(inputProps.NotNullCols)
+		for i, col := range expand.GroupingCols {
+			if !inputProps.NotNullCols.Contains(col) {
+				continue
+			}
+			inAllSets := true
+			for j := range expand.GroupingSets {
+				if !expand.GroupingSets[j].Contains(col) {
+					inAllSets = false
+					break
+				}
+			}
+			if inAllSets {
+				rel.NotNullCols.Add(expand.OutCols[i])
+			}
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// Outer columns were already derived by BuildSharedProps.
+
+	// Functional Dependencies
+	// -----------------------
+	// Dependencies between input columns still hold, but every input row is
+	// repeated once per grouping set, so the input keys are no longer keys.
+	// However, an input key together with the grouping ID column is a key.
+	rel.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+	rel.FuncDeps.MakeApply(&props.FuncDepSet{})
+	rel.FuncDeps.MakeNotNull(rel.NotNullCols)
+	if key, ok := inputProps.FuncDeps.StrictKey(); ok {
+		rel.FuncDeps.AddStrictKey(key.Union(opt.MakeColSet(expand.GroupingIDCol)), rel.OutputCols)
+	}
+
+	// Cardinality
+	// -----------
+	// Every input row is emitted once per grouping set. If the input is empty,
+	// a row is emitted for each empty grouping set.
+	numSets := uint32(len(expand.GroupingSets))
+	rel.Cardinality = inputProps.Cardinality.Product(props.Cardinality{Min: numSets, Max: numSets})
+	if expand.InputRowCol != 0 {
+		var numEmptySets uint32
+		for i := range expand.GroupingSets {
+			if expand.GroupingSets[i].Empty() {
+				numEmptySets++
+			}
+		}
+		rel.Cardinality = rel.Cardinality.AtLeast(props.Cardinality{Min: numEmptySets, Max: numEmptySets})
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildExpand(expand, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildInsertProps(ins *InsertExpr, rel *props.Relational) {
 	b.buildMutationProps(ins, rel)
 }
@@ -1425,6 +1511,10 @@ func (b *logicalPropsBuilder) buildUpsertProps(ups *UpsertExpr, rel *props.Relat
 	b.buildMutationProps(ups, rel)
 }
 
+func (b *logicalPropsBuilder) buildMergeProps(mrg *MergeExpr, rel *props.Relational) {
+	b.buildMutationProps(mrg, rel)
+}
+
 func (b *logicalPropsBuilder) buildDeleteProps(del *DeleteExpr, rel *props.Relational) {
 	b.buildMutationProps(del, rel)
 }
@@ -1854,6 +1944,12 @@ func MakeTableFuncDep(md *opt.Metadata, tabID opt.TableID) *props.FuncDepSet {
 			continue
 		}
 
+		if unique.Deferrability().IsDeferrable() {
+			// The checking of a deferrable constraint can be deferred until the
+			// end of the transaction, so it can be violated in the meantime.
+			continue
+		}
+
 		if _, isPartial := unique.Predicate(); isPartial {
 			// Partial constraints cannot be considered while building functional
 			// dependency keys for the table because their keys are only unique
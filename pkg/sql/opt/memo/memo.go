@@ -13,6 +13,7 @@ package memo
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
@@ -147,6 +148,7 @@ type Memo struct {
 	saveTablesPrefix                       string
 	dateStyle                              pgdate.DateStyle
 	intervalStyle                          duration.IntervalStyle
+	defaultTextSearchConfig                string
 	propagateInputOrdering                 bool
 	disallowFullTableScans                 bool
 	largeFullScanRows                      float64
@@ -163,6 +165,7 @@ type Memo struct {
 	useLimitOrderingForStreamingGroupBy    bool
 	useImprovedSplitDisjunctionForJoins    bool
 	alwaysUseHistograms                    bool
+	txnIsoLevel                            isolation.Level
 
 	// curRank is the highest currently in-use scalar expression rank.
 	curRank opt.ScalarRank
@@ -205,6 +208,7 @@ func (m *Memo) Init(ctx context.Context, evalCtx *eval.Context) {
 		saveTablesPrefix:                       evalCtx.SessionData().SaveTablesPrefix,
 		dateStyle:                              evalCtx.SessionData().GetDateStyle(),
 		intervalStyle:                          evalCtx.SessionData().GetIntervalStyle(),
+		defaultTextSearchConfig:                evalCtx.SessionData().GetDefaultTextSearchConfig(),
 		propagateInputOrdering:                 evalCtx.SessionData().PropagateInputOrdering,
 		disallowFullTableScans:                 evalCtx.SessionData().DisallowFullTableScans,
 		largeFullScanRows:                      evalCtx.SessionData().LargeFullScanRows,
@@ -221,6 +225,7 @@ func (m *Memo) Init(ctx context.Context, evalCtx *eval.Context) {
 		useLimitOrderingForStreamingGroupBy:    evalCtx.SessionData().OptimizerUseLimitOrderingForStreamingGroupBy,
 		useImprovedSplitDisjunctionForJoins:    evalCtx.SessionData().OptimizerUseImprovedSplitDisjunctionForJoins,
 		alwaysUseHistograms:                    evalCtx.SessionData().OptimizerAlwaysUseHistograms,
+		txnIsoLevel:                            evalCtx.TxnIsoLevel,
 	}
 	m.metadata.Init()
 	m.logPropsBuilder.init(ctx, evalCtx, m)
@@ -347,6 +352,7 @@ func (m *Memo) IsStale(
 		m.saveTablesPrefix != evalCtx.SessionData().SaveTablesPrefix ||
 		m.dateStyle != evalCtx.SessionData().GetDateStyle() ||
 		m.intervalStyle != evalCtx.SessionData().GetIntervalStyle() ||
+		m.defaultTextSearchConfig != evalCtx.SessionData().GetDefaultTextSearchConfig() ||
 		m.propagateInputOrdering != evalCtx.SessionData().PropagateInputOrdering ||
 		m.disallowFullTableScans != evalCtx.SessionData().DisallowFullTableScans ||
 		m.largeFullScanRows != evalCtx.SessionData().LargeFullScanRows ||
@@ -362,7 +368,8 @@ func (m *Memo) IsStale(
 		m.useImprovedDisjunctionStats != evalCtx.SessionData().OptimizerUseImprovedDisjunctionStats ||
 		m.useLimitOrderingForStreamingGroupBy != evalCtx.SessionData().OptimizerUseLimitOrderingForStreamingGroupBy ||
 		m.useImprovedSplitDisjunctionForJoins != evalCtx.SessionData().OptimizerUseImprovedSplitDisjunctionForJoins ||
-		m.alwaysUseHistograms != evalCtx.SessionData().OptimizerAlwaysUseHistograms {
+		m.alwaysUseHistograms != evalCtx.SessionData().OptimizerAlwaysUseHistograms ||
+		m.txnIsoLevel != evalCtx.TxnIsoLevel {
 		return true, nil
 	}
 
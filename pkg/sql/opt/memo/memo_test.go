@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
@@ -252,6 +253,12 @@ func TestMemoIsStale(t *testing.T) {
 	evalCtx.SessionData().DataConversionConfig.IntervalStyle = duration.IntervalStyle_POSTGRES
 	notStale()
 
+	// Stale default_text_search_config.
+	evalCtx.SessionData().DefaultTextSearchConfig = "pg_catalog.german"
+	stale()
+	evalCtx.SessionData().DefaultTextSearchConfig = ""
+	notStale()
+
 	// Stale prefer lookup joins for FKs.
 	evalCtx.SessionData().PreferLookupJoinsForFKs = true
 	stale()
@@ -354,6 +361,12 @@ func TestMemoIsStale(t *testing.T) {
 	evalCtx.SessionData().OptimizerAlwaysUseHistograms = false
 	notStale()
 
+	// Stale txn isolation level.
+	evalCtx.TxnIsoLevel = isolation.ReadCommitted
+	stale()
+	evalCtx.TxnIsoLevel = isolation.Serializable
+	notStale()
+
 	// Stale data sources and schema. Create new catalog so that data sources are
 	// recreated and can be modified independently.
 	catalog = testcat.New()
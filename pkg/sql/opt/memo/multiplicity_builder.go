@@ -513,8 +513,11 @@ func checkForeignKeyCase(
 		leftBaseTable := md.Table(leftTableID)
 		for i, cnt := 0, leftBaseTable.OutboundForeignKeyCount(); i < cnt; i++ {
 			fk := leftBaseTable.OutboundForeignKey(i)
-			if !fk.Validated() {
+			if !fk.Validated() || fk.Deferrability().IsDeferrable() {
 				// The data is not guaranteed to follow the foreign key constraint.
+				// The checking of a deferrable constraint can be deferred until
+				// the end of the transaction, so it can be violated in the
+				// meantime.
 				continue
 			}
 			if rightTableIDs == nil {
@@ -465,6 +465,9 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.OrdinalityOp:
 		return sb.colStatOrdinality(colSet, e.(*OrdinalityExpr))
 
+	case opt.ExpandOp:
+		return sb.colStatExpand(colSet, e.(*ExpandExpr))
+
 	case opt.WindowOp:
 		return sb.colStatWindow(colSet, e.(*WindowExpr))
 
@@ -474,13 +477,13 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.WithScanOp:
 		return sb.colStatWithScan(colSet, e.(*WithScanExpr))
 
-	case opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp:
+	case opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.MergeOp, opt.DeleteOp:
 		return sb.colStatMutation(colSet, e)
 
 	case opt.SequenceSelectOp:
 		return sb.colStatSequenceSelect(colSet, e.(*SequenceSelectExpr))
 
-	case opt.ExplainOp, opt.ShowTraceForSessionOp,
+	case opt.ExplainOp, opt.ShowTraceForSessionOp, opt.CallOp,
 		opt.OpaqueRelOp, opt.OpaqueMutationOp, opt.OpaqueDDLOp, opt.RecursiveCTEOp:
 		return sb.colStatUnknown(colSet, e.Relational())
 
@@ -2377,6 +2380,79 @@ func (sb *statisticsBuilder) colStatOrdinality(
 	return colStat
 }
 
+// +------------+
+// |   Expand   |
+// +------------+
+
+func (sb *statisticsBuilder) buildExpand(expand *ExpandExpr, relProps *props.Relational) {
+	s := relProps.Statistics()
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+	s.Available = sb.availabilityFromInput(expand)
+
+	inputStats := expand.Input.Relational().Statistics()
+
+	// Every input row is emitted once per grouping set. Any rows fabricated for
+	// empty grouping sets are accounted for by the cardinality.
+	s.RowCount = inputStats.RowCount * float64(len(expand.GroupingSets))
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatExpand(
+	colSet opt.ColSet, expand *ExpandExpr,
+) *props.ColumnStatistic {
+	relProps := expand.Relational()
+	s := relProps.Statistics()
+
+	colStat, _ := s.ColStats.Add(colSet)
+
+	// Map the requested output columns to the grouping columns they are copied
+	// from.
+	inputCols := expand.Input.Relational().OutputCols
+	reqInputCols := colSet.Intersection(inputCols)
+	var outCols, reqGroupingCols opt.ColSet
+	for i, col := range expand.OutCols {
+		outCols.Add(col)
+		if colSet.Contains(col) {
+			reqGroupingCols.Add(expand.GroupingCols[i])
+		}
+	}
+	reqInputCols.UnionWith(reqGroupingCols)
+
+	distinctCount, inputNullCount := 1.0, 0.0
+	if !reqInputCols.Empty() {
+		inputColStat := sb.colStatFromChild(reqInputCols, expand, 0 /* childIdx */)
+		distinctCount = inputColStat.DistinctCount
+		inputNullCount = inputColStat.NullCount
+	}
+
+	numSets := float64(len(expand.GroupingSets))
+	if colSet.Intersects(outCols) || colSet.Contains(expand.GroupingIDCol) {
+		// Each grouping set can produce a different combination of values.
+		distinctCount *= numSets
+	}
+	colStat.DistinctCount = distinctCount
+
+	colStat.NullCount = 0
+	inputRowCount := expand.Input.Relational().Statistics().RowCount
+	for _, set := range expand.GroupingSets {
+		if colSet.SubsetOf(outCols) && !reqGroupingCols.Intersects(set) {
+			// All requested columns are NULL for this grouping set.
+			colStat.NullCount += inputRowCount
+		} else {
+			colStat.NullCount += inputNullCount
+		}
+	}
+
+	if colSet.Intersects(relProps.NotNullCols) {
+		colStat.NullCount = 0
+	}
+	sb.finalizeFromRowCountAndDistinctCounts(colStat, s)
+	return colStat
+}
+
 // +------------+
 // |   Window   |
 // +------------+
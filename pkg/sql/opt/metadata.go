@@ -218,7 +218,7 @@ func (md *Metadata) CopyFrom(from *Metadata, copyScalarFn func(Expr) Expr) {
 		}
 		for i := range from.userDefinedTypesSlice {
 			typ := from.userDefinedTypesSlice[i]
-			md.userDefinedTypes[typ.Oid()] = struct{}{}
+			md.userDefinedTypes[typ.UserDefinedOID()] = struct{}{}
 			md.userDefinedTypesSlice = append(md.userDefinedTypesSlice, typ)
 		}
 	}
@@ -332,7 +332,7 @@ func (md *Metadata) CheckDependencies(
 	}
 	// Check that all of the user defined types present have not changed.
 	for _, typ := range md.AllUserDefinedTypes() {
-		toCheck, err := catalog.ResolveTypeByOID(ctx, typ.Oid())
+		toCheck, err := catalog.ResolveTypeByOID(ctx, typ.UserDefinedOID())
 		if err != nil {
 			// Handle when the type no longer exists.
 			if pgerror.GetPGCode(err) == pgcode.UndefinedObject {
@@ -367,8 +367,8 @@ func (md *Metadata) AddUserDefinedType(typ *types.T) {
 	if md.userDefinedTypes == nil {
 		md.userDefinedTypes = make(map[oid.Oid]struct{})
 	}
-	if _, ok := md.userDefinedTypes[typ.Oid()]; !ok {
-		md.userDefinedTypes[typ.Oid()] = struct{}{}
+	if _, ok := md.userDefinedTypes[typ.UserDefinedOID()]; !ok {
+		md.userDefinedTypes[typ.UserDefinedOID()] = struct{}{}
 		md.userDefinedTypesSlice = append(md.userDefinedTypesSlice, typ)
 	}
 }
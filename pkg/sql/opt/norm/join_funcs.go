@@ -672,8 +672,11 @@ func (c *CustomFuncs) ForeignKeyConstraintFilters(
 
 		for i := 0; i < fkChildTable.OutboundForeignKeyCount(); i++ {
 			fk := fkChildTable.OutboundForeignKey(i)
-			if !fk.Validated() {
+			if !fk.Validated() || fk.Deferrability().IsDeferrable() {
 				// The data is not guaranteed to follow the foreign key constraint.
+				// The checking of a deferrable constraint can be deferred until
+				// the end of the transaction, so it can be violated in the
+				// meantime.
 				continue
 			}
 			if parentTable.ID() != fk.ReferencedTableID() {
@@ -78,6 +78,17 @@ func (c *CustomFuncs) neededMutationCols(
 	if private.CanaryCol != 0 {
 		cols.Add(private.CanaryCol)
 	}
+	if private.MergeActionCol != 0 {
+		cols.Add(private.MergeActionCol)
+	}
+
+	// Add the columns passed to cascades. They are usually fetch, insert or
+	// update columns, but Merge projects separate columns that only hold the
+	// values of the rows it deletes.
+	for i := range private.FKCascades {
+		cols.UnionWith(private.FKCascades[i].OldValues.ToSet())
+		cols.UnionWith(private.FKCascades[i].NewValues.ToSet())
+	}
 
 	if private.WithID != 0 {
 		for i := range uniqueChecks {
@@ -134,10 +145,10 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 	//   2. For Update, the corresponding FETCH column is needed when there is
 	//      no corresponding UPDATE column. In that case, the FETCH column always
 	//      becomes the RETURN column.
-	//   3. For Upsert, the corresponding FETCH column is needed when there is
-	//      no corresponding UPDATE column. In that case, either the INSERT or
-	//      FETCH column becomes the RETURN column, so both must be available
-	//      for the CASE expression.
+	//   3. For Upsert and Merge, the corresponding FETCH column is needed when
+	//      there is no corresponding UPDATE column. In that case, either the
+	//      INSERT or FETCH column becomes the RETURN column, so both must be
+	//      available for the CASE expression.
 	for ord, col := range private.ReturnCols {
 		if col != 0 {
 			if op == opt.DeleteOp || len(private.UpdateCols) == 0 || private.UpdateCols[ord] == 0 {
@@ -146,8 +157,27 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 		}
 	}
 
+	// Retain any FetchCols that are passed to cascades. This includes row-level
+	// triggers, which receive the old and new values of all columns.
+	var cascadeCols opt.ColSet
+	for i := range private.FKCascades {
+		for _, col := range private.FKCascades[i].OldValues {
+			cascadeCols.Add(col)
+		}
+		for _, col := range private.FKCascades[i].NewValues {
+			cascadeCols.Add(col)
+		}
+	}
+	if !cascadeCols.Empty() {
+		for ord, col := range private.FetchCols {
+			if col != 0 && cascadeCols.Contains(col) {
+				cols.Add(tabMeta.MetaID.ColumnID(ord))
+			}
+		}
+	}
+
 	switch op {
-	case opt.UpdateOp, opt.UpsertOp:
+	case opt.UpdateOp, opt.UpsertOp, opt.MergeOp:
 		// Determine set of target table columns that need to be updated.
 		var updateCols opt.ColSet
 		for ord, col := range private.UpdateCols {
@@ -223,7 +253,13 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 			}
 		}
 
-	case opt.DeleteOp:
+	}
+
+	switch op {
+	case opt.DeleteOp, opt.MergeOp:
+		// Merge may delete some of the rows it updates, so it needs the same
+		// columns as Delete in addition to the ones needed by Update.
+		//
 		// Add in all strict key columns from all indexes, since these are needed
 		// to compose the keys of rows to delete. Include mutation indexes, since
 		// it is necessary to delete rows even from indexes that are being added
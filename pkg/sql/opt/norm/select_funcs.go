@@ -417,3 +417,27 @@ func (c *CustomFuncs) addConjuncts(
 	}
 	return filters, true
 }
+
+// FiltersContradictCheckConstraints returns true if the given filters can
+// never be true for the rows of the scanned table, because together with the
+// validated check constraints of the table they form a contradiction. It only
+// applies to tables which inherit from another table, so that children which
+// cannot contain matching rows are pruned from scans of their parent.
+func (c *CustomFuncs) FiltersContradictCheckConstraints(
+	filters memo.FiltersExpr, scanPrivate *memo.ScanPrivate,
+) bool {
+	tabMeta := c.mem.Metadata().TableMeta(scanPrivate.Table)
+	if tabMeta.Table.InheritanceParentCount() == 0 || tabMeta.Constraints == nil {
+		return false
+	}
+	checks := *tabMeta.Constraints.(*memo.FiltersExpr)
+	cs := constraint.Unconstrained
+	for _, list := range []memo.FiltersExpr{filters, checks} {
+		for i := range list {
+			if filterConstraints := list[i].ScalarProps().Constraints; filterConstraints != nil {
+				cs = cs.Intersect(c.f.evalCtx, filterConstraints)
+			}
+		}
+	}
+	return cs == constraint.Contradiction
+}
@@ -146,6 +146,7 @@ var ComparisonOpReverseMap = map[Operator]treecmp.ComparisonOperatorSymbol{
 	BBoxCoversOp:     treecmp.RegMatch,
 	BBoxIntersectsOp: treecmp.Overlaps,
 	TSMatchesOp:      treecmp.TSMatches,
+	AdjacentOp:       treecmp.Adjacent,
 }
 
 // BinaryOpReverseMap maps from an optimizer operator type to a semantic tree
@@ -125,6 +125,19 @@ type Builder struct {
 	// are disabled and only statements whitelisted are allowed.
 	insideFuncDef bool
 
+	// If set, the function definition being processed is a procedure
+	// definition, which additionally allows mutation statements.
+	insideProcDef bool
+
+	// If set, the function definition being processed has a PL/pgSQL body,
+	// which additionally allows mutation statements.
+	insidePLpgSQLDef bool
+
+	// If set, the function definition being processed is a trigger function
+	// definition. The body is built against record-typed NEW and OLD
+	// parameters, and it may contain mutation statements.
+	insideTriggerFuncDef bool
+
 	// If set, we are collecting view dependencies in schemaDeps. This can only
 	// happen inside view/function definitions.
 	//
@@ -157,6 +170,16 @@ type Builder struct {
 	// subqueryNameIdx helps generate unique subquery names during star
 	// expansion.
 	subqueryNameIdx int
+
+	// If set, we are building a statement that is executed on behalf of
+	// another statement, e.g. a cascading mutation or a statement in the body
+	// of a trigger function.
+	insideNestedStmt bool
+
+	// triggerFuncStack contains the OIDs of the trigger functions which are
+	// currently being built. It is used to detect recursive invocations of
+	// BEFORE ROW triggers, which cannot be planned.
+	triggerFuncStack []oid.Oid
 }
 
 // New creates a new Builder structure initialized with the given
@@ -286,7 +309,7 @@ func (b *Builder) buildStmt(
 	if b.insideViewDef {
 		// A blocklist of statements that can't be used from inside a view.
 		switch stmt := stmt.(type) {
-		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
+		case *tree.Delete, *tree.Insert, *tree.Merge, *tree.Update, *tree.CreateTable, *tree.CreateView,
 			*tree.Split, *tree.Unsplit, *tree.Relocate, *tree.RelocateRange,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions,
 			*tree.CreateFunction:
@@ -301,6 +324,10 @@ func (b *Builder) buildStmt(
 		switch stmt := stmt.(type) {
 		case *tree.Select:
 		case tree.SelectStatement:
+		case *tree.Insert, *tree.Update, *tree.Delete, *tree.Merge:
+			if !b.insideProcDef && !b.insidePLpgSQLDef && !b.insideTriggerFuncDef {
+				panic(unimplemented.Newf("user-defined functions", "%s usage inside a function definition", stmt.StatementTag()))
+			}
 		default:
 			panic(unimplemented.Newf("user-defined functions", "%s usage inside a function definition", stmt.StatementTag()))
 		}
@@ -328,6 +355,11 @@ func (b *Builder) buildStmt(
 			return b.buildUpdate(stmt, inScope)
 		})
 
+	case *tree.Merge:
+		return b.processWiths(stmt.With, inScope, func(inScope *scope) *scope {
+			return b.buildMerge(stmt, inScope)
+		})
+
 	case *tree.CreateTable:
 		return b.buildCreateTable(stmt, inScope)
 
@@ -337,6 +369,9 @@ func (b *Builder) buildStmt(
 	case *tree.CreateFunction:
 		return b.buildCreateFunction(stmt, inScope)
 
+	case *tree.Call:
+		return b.buildCall(stmt, inScope)
+
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
 
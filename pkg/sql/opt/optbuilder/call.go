@@ -0,0 +1,263 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// buildCall builds a CALL statement, which invokes a stored procedure. Only the
+// statements of the procedure body from c.ResumeAt up to the next COMMIT or
+// ROLLBACK statement are built. The executor is responsible for committing or
+// aborting the transaction and resuming the procedure in a new transaction.
+func (b *Builder) buildCall(c *tree.Call, inScope *scope) (outScope *scope) {
+	// The statements that are built depend on the position at which the
+	// procedure is resumed, so the memo cannot be reused.
+	b.DisableMemoReuse = true
+
+	// Type-check the procedure invocation, which resolves the overload.
+	var f *tree.FuncExpr
+	{
+		// We need to save and restore the previous value of the field in
+		// semaCtx in case we are recursively called within a subquery
+		// context.
+		defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+		b.semaCtx.Properties.Require(exprKindCall.String(), tree.RejectSpecial)
+		inScope.context = exprKindCall
+		texpr := inScope.resolveType(c.Proc, types.Any)
+		var ok bool
+		f, ok = texpr.(*tree.FuncExpr)
+		if !ok {
+			panic(errors.AssertionFailedf("expected FuncExpr, found %T", texpr))
+		}
+	}
+	def, err := f.Func.Resolve(b.ctx, b.semaCtx.SearchPath, b.semaCtx.FunctionResolver)
+	if err != nil {
+		panic(err)
+	}
+	o := f.ResolvedOverload()
+	if !o.IsProcedure {
+		panic(errors.WithHint(
+			pgerror.Newf(pgcode.WrongObjectType, "%s is not a procedure", def.Name),
+			"To call a function, use SELECT.",
+		))
+	}
+	procType := f.ResolvedType()
+
+	// Build the argument expressions.
+	var args memo.ScalarListExpr
+	if len(f.Exprs) > 0 {
+		args = make(memo.ScalarListExpr, len(f.Exprs))
+		for i, pexpr := range f.Exprs {
+			args[i] = b.buildScalar(
+				pexpr.(tree.TypedExpr),
+				inScope,
+				nil, /* outScope */
+				nil, /* outCol */
+				nil, /* colRefs */
+			)
+		}
+	}
+
+	prevInsideNestedStmt := b.insideNestedStmt
+	b.insideNestedStmt = true
+	defer func() { b.insideNestedStmt = prevInsideNestedStmt }()
+
+	// The procedure parameters are added as columns to the scope of the body
+	// statements so that references to them can be resolved.
+	bodyScope := b.allocScope()
+	var params opt.ColList
+	if o.Types.Length() > 0 {
+		paramTypes, ok := o.Types.(tree.ParamTypes)
+		if !ok {
+			panic(unimplemented.NewWithIssue(88947,
+				"variadiac user-defined functions are not yet supported"))
+		}
+		params = make(opt.ColList, len(paramTypes))
+		for i := range paramTypes {
+			paramType := &paramTypes[i]
+			argColName := funcParamColName(tree.Name(paramType.Name), i)
+			col := b.synthesizeColumn(bodyScope, argColName, paramType.Typ, nil /* expr */, nil /* scalar */)
+			col.setParamOrd(i)
+			params[i] = col.id
+		}
+	}
+
+	// Find the statements which are executed in the current transaction. They
+	// end at the next COMMIT or ROLLBACK statement, if there is one.
+	stmts, err := parser.Parse(o.Body)
+	if err != nil {
+		panic(err)
+	}
+	if c.ResumeAt < 0 || c.ResumeAt > len(stmts) {
+		panic(errors.AssertionFailedf("invalid procedure resume position %d", c.ResumeAt))
+	}
+	txnOp := tree.StoredProcTxnNoOp
+	resumeAt := 0
+	end := c.ResumeAt
+	for ; end < len(stmts) && txnOp == tree.StoredProcTxnNoOp; end++ {
+		switch stmts[end].AST.(type) {
+		case *tree.CommitTransaction:
+			txnOp = tree.StoredProcTxnCommit
+		case *tree.RollbackTransaction:
+			txnOp = tree.StoredProcTxnRollback
+		}
+	}
+	segment := stmts[c.ResumeAt:end]
+	if txnOp != tree.StoredProcTxnNoOp {
+		segment = segment[:len(segment)-1]
+		resumeAt = end
+	}
+
+	// Build an expression for each statement in the segment. Only the last
+	// statement of the procedure body produces the values of the OUT
+	// parameters. The result of any other segment is NULL.
+	producesResult := txnOp == tree.StoredProcTxnNoOp && procType.Family() == types.TupleFamily
+	rels := make(memo.RelListExpr, 0, len(segment)+1)
+	for i := range segment {
+		stmtScope := b.buildStmt(segment[i].AST, nil /* desiredTypes */, bodyScope)
+		expr := stmtScope.expr
+		physProps := stmtScope.makePhysicalProps()
+
+		if i == len(segment)-1 {
+			if producesResult && !expr.Relational().CanMutate {
+				b.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, b.allocScope(), stmtScope)
+				expr = stmtScope.expr
+				physProps.Ordering = props.OrderingChoice{}
+			}
+			var result opt.ScalarExpr
+			if producesResult {
+				result = b.buildCallResult(procType, physProps.Presentation)
+			} else {
+				result = b.factory.ConstructNull(procType)
+			}
+			stmtScope = bodyScope.push()
+			b.synthesizeColumn(stmtScope, scopeColName(""), procType, nil /* expr */, result)
+			expr = b.constructProject(expr, stmtScope.cols)
+			physProps = stmtScope.makePhysicalProps()
+		}
+
+		rels = append(rels, memo.RelRequiredPropsExpr{
+			RelExpr:   expr,
+			PhysProps: physProps,
+		})
+	}
+	if len(rels) == 0 {
+		// The segment has no statements, e.g. a procedure body which begins
+		// with COMMIT. The routine still needs a statement which produces its
+		// result.
+		md := b.factory.Metadata()
+		values := b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+			Cols: opt.ColList{},
+			ID:   md.NextUniqueID(),
+		})
+		stmtScope := bodyScope.push()
+		b.synthesizeColumn(stmtScope, scopeColName(""), procType, nil /* expr */, b.factory.ConstructNull(procType))
+		rels = append(rels, memo.RelRequiredPropsExpr{
+			RelExpr:   b.constructProject(values, stmtScope.cols),
+			PhysProps: stmtScope.makePhysicalProps(),
+		})
+	}
+
+	proc := b.factory.ConstructUDF(
+		args,
+		&memo.UDFPrivate{
+			Name:              def.Name,
+			Params:            params,
+			Body:              rels,
+			Typ:               procType,
+			Volatility:        o.Volatility,
+			CalledOnNullInput: true,
+		},
+	)
+
+	// There is an output column for each OUT and INOUT parameter.
+	outScope = inScope.push()
+	if procType.Family() == types.TupleFamily {
+		labels := procType.TupleLabels()
+		for i, typ := range procType.TupleContents() {
+			b.synthesizeColumn(outScope, scopeColName(tree.Name(labels[i])), typ, nil /* expr */, nil /* scalar */)
+		}
+	}
+	outScope.expr = b.factory.ConstructCall(proc, &memo.CallPrivate{
+		Columns:  colsToColList(outScope.cols),
+		Overload: o,
+		TxnOp:    txnOp,
+		ResumeAt: resumeAt,
+	})
+	return outScope
+}
+
+// buildCallResult builds the scalar expression which converts the presentation
+// columns of the last statement of a procedure body into the record of OUT
+// parameter values of the procedure, which has type typ.
+func (b *Builder) buildCallResult(typ *types.T, cols physical.Presentation) opt.ScalarExpr {
+	f := b.factory
+	md := f.Metadata()
+	if len(cols) == 0 {
+		panic(pgerror.WithCandidateCode(
+			errors.WithDetail(
+				errors.Newf("return type mismatch in procedure declared to return %s", typ.Name()),
+				"Procedure's final statement must be SELECT or INSERT/UPDATE/DELETE RETURNING.",
+			),
+			pgcode.InvalidFunctionDefinition,
+		))
+	}
+
+	numCols := len(typ.TupleContents())
+	elems := make(memo.ScalarListExpr, numCols)
+	srcTypes := make([]*types.T, numCols)
+	firstColType := md.ColumnMeta(cols[0].ID).Type
+	switch {
+	case len(cols) == 1 && numCols > 1 && firstColType.Family() == types.TupleFamily &&
+		len(firstColType.TupleContents()) == numCols:
+		// The statement returns a single tuple, e.g. SELECT (1, 2).
+		copy(srcTypes, firstColType.TupleContents())
+		for i := range elems {
+			elems[i] = f.ConstructColumnAccess(f.ConstructVariable(cols[0].ID), memo.TupleOrdinal(i))
+		}
+
+	case len(cols) == numCols:
+		for i := range elems {
+			srcTypes[i] = md.ColumnMeta(cols[i].ID).Type
+			elems[i] = f.ConstructVariable(cols[i].ID)
+		}
+
+	default:
+		panic(pgerror.Newf(pgcode.DatatypeMismatch,
+			"return type mismatch in procedure declared to return %s", typ.Name()))
+	}
+
+	labels := typ.TupleLabels()
+	for i := range elems {
+		targetType := typ.TupleContents()[i]
+		if srcTypes[i].Identical(targetType) {
+			continue
+		}
+		if !cast.ValidCast(srcTypes[i], targetType, cast.ContextAssignment) {
+			panic(sqlerrors.NewInvalidAssignmentCastError(srcTypes[i], targetType, labels[i]))
+		}
+		elems[i] = f.ConstructAssignmentCast(elems[i], targetType)
+	}
+	return f.ConstructTuple(elems, typ)
+}
@@ -13,12 +13,14 @@ package optbuilder
 import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -45,11 +47,16 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 	b.semaCtx.FunctionResolver = nil
 
 	b.insideFuncDef = true
+	b.insideProcDef = cf.IsProcedure
+	b.insidePLpgSQLDef = tree.GetFuncLanguage(cf.Options) == tree.FunctionLangPlPgSQL
 	b.trackSchemaDeps = true
 	// Make sure datasource names are qualified.
 	b.qualifyDataSourceNamesInAST = true
 	defer func() {
 		b.insideFuncDef = false
+		b.insideProcDef = false
+		b.insidePLpgSQLDef = false
+		b.insideTriggerFuncDef = false
 		b.trackSchemaDeps = false
 		b.schemaDeps = nil
 		b.schemaTypeDeps = intsets.Fast{}
@@ -66,11 +73,22 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 	if err := tree.ValidateFuncOptions(cf.Options); err != nil {
 		panic(err)
 	}
+	if cf.IsProcedure {
+		for _, option := range cf.Options {
+			switch option.(type) {
+			case tree.FunctionBodyStr, tree.FunctionLanguage:
+			default:
+				panic(pgerror.New(pgcode.InvalidFunctionDefinition,
+					"invalid attribute in procedure definition"))
+			}
+		}
+	}
 
 	// Look for function body string from function options.
 	// Note that function body can be an empty string.
 	funcBodyFound := false
 	languageFound := false
+	var language tree.FunctionLanguage
 	var funcBodyStr string
 	for _, option := range cf.Options {
 		switch opt := option.(type) {
@@ -79,6 +97,7 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 			funcBodyStr = string(opt)
 		case tree.FunctionLanguage:
 			languageFound = true
+			language = opt
 			// Check the language here, before attempting to parse the function body.
 			if _, err := funcinfo.FunctionLangToProto(opt); err != nil {
 				panic(err)
@@ -120,6 +139,20 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		})
 	}
 
+	// The result of a procedure is a record made up of its OUT and INOUT
+	// parameters.
+	if cf.IsProcedure {
+		procReturnType, err := funcinfo.ProcedureReturnType(
+			cf.Params, func(ref tree.ResolvableTypeReference) (*types.T, error) {
+				return tree.ResolveType(b.ctx, ref, b.semaCtx.TypeResolver)
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		cf.ReturnType.Type = procReturnType
+	}
+
 	// Collect the user defined type dependency of the return type.
 	funcReturnType, err := tree.ResolveType(b.ctx, cf.ReturnType.Type, b.semaCtx.TypeResolver)
 	if err != nil {
@@ -129,47 +162,123 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		typeDeps.Add(int(id))
 	})
 
-	// Parse the function body.
-	stmts, err := parser.Parse(funcBodyStr)
-	if err != nil {
-		panic(err)
+	// The NEW and OLD rows passed to trigger functions depend on the table
+	// which the trigger is defined on, so the body of a trigger function is
+	// built against record-typed NEW and OLD parameters. The body is built
+	// again with the row type of the table when the trigger is invoked.
+	isTriggerFunc := funcReturnType.Family() == types.TriggerFamily
+	if isTriggerFunc {
+		if len(cf.Params) > 0 {
+			panic(pgerror.New(pgcode.InvalidFunctionDefinition,
+				"trigger functions cannot have declared arguments"))
+		}
+		b.insideTriggerFuncDef = true
+		for i, paramName := range []tree.Name{schemaexpr.TriggerNewName, schemaexpr.TriggerOldName} {
+			col := b.synthesizeColumn(bodyScope, funcParamColName(paramName, i), types.AnyTuple, nil /* expr */, nil /* scalar */)
+			col.setParamOrd(i)
+		}
 	}
 
 	targetVolatility := tree.GetFuncVolatility(cf.Options)
-	// Validate each statement and collect the dependencies.
 	fmtCtx := tree.NewFmtCtx(tree.FmtSimple)
-	for i, stmt := range stmts {
-		var stmtScope *scope
-		// We need to disable stable function folding because we want to catch the
-		// volatility of stable functions. If folded, we only get a scalar and lose
-		// the volatility.
-		b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
-			stmtScope = b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
-		})
-		checkStmtVolatility(targetVolatility, stmtScope, stmt.AST)
-
-		// Format the statements with qualified datasource names.
-		formatFuncBodyStmt(fmtCtx, stmt.AST, i > 0 /* newLine */)
-
-		// Validate that the result type of the last statement matches the
-		// return type of the function.
-		if i == len(stmts)-1 {
-			// TODO(mgartner): stmtScope.cols does not describe the result
-			// columns of the statement. We should use physical.Presentation
-			// instead.
-			err := validateReturnType(funcReturnType, stmtScope.cols)
-			if err != nil {
-				panic(err)
+	if language == tree.FunctionLangPlPgSQL {
+		if cf.IsProcedure {
+			panic(unimplemented.New("CREATE PROCEDURE plpgsql",
+				"PL/pgSQL procedures are not yet supported"))
+		}
+		if isTriggerFunc {
+			panic(unimplemented.New("CREATE FUNCTION plpgsql trigger",
+				"PL/pgSQL trigger functions are not yet supported"))
+		}
+		for i := range cf.Params {
+			if cf.Params[i].Class != tree.FunctionParamIn {
+				panic(unimplemented.New("CREATE FUNCTION plpgsql out param",
+					"PL/pgSQL functions with OUT, INOUT and VARIADIC parameters are not yet supported"))
 			}
 		}
-
+		block, err := plpgsqlparser.Parse(funcBodyStr)
+		if err != nil {
+			panic(err)
+		}
+		// Build the body to validate it and collect its dependencies. The
+		// volatility of each SQL statement in the body is checked as it is
+		// built.
+		b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
+			b.buildPLpgSQL(block, bodyScope, funcReturnType, cf.ReturnType.IsSet,
+				func(stmtScope *scope, stmt tree.Statement) {
+					checkStmtVolatility(targetVolatility, stmtScope, stmt)
+				},
+			)
+		})
 		deps = append(deps, b.schemaDeps...)
 		typeDeps.UnionWith(b.schemaTypeDeps)
-		// Add statement ast into CreateFunction node for logging purpose.
-		cf.BodyStatements = append(cf.BodyStatements, stmt.AST)
-		// Reset the tracked dependencies for next statement.
 		b.schemaDeps = nil
 		b.schemaTypeDeps = intsets.Fast{}
+
+		// Format the body with qualified datasource names.
+		fmtCtx.FormatNode(block)
+	} else {
+		// Parse the function body.
+		stmts, err := parser.Parse(funcBodyStr)
+		if err != nil {
+			panic(err)
+		}
+
+		// Validate each statement and collect the dependencies.
+		for i, stmt := range stmts {
+			switch stmt.AST.(type) {
+			case *tree.CommitTransaction, *tree.RollbackTransaction:
+				// Procedures can commit or abort the current transaction. These
+				// statements are not built until the procedure is called.
+				if !cf.IsProcedure {
+					panic(pgerror.Newf(pgcode.FeatureNotSupported,
+						"%s is not allowed in a SQL function", stmt.AST.StatementTag()))
+				}
+				formatFuncBodyStmt(fmtCtx, stmt.AST, i > 0 /* newLine */)
+				cf.BodyStatements = append(cf.BodyStatements, stmt.AST)
+				continue
+			}
+			var stmtScope *scope
+			// We need to disable stable function folding because we want to catch the
+			// volatility of stable functions. If folded, we only get a scalar and lose
+			// the volatility.
+			b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
+				stmtScope = b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
+			})
+			checkStmtVolatility(targetVolatility, stmtScope, stmt.AST)
+
+			// Format the statements with qualified datasource names.
+			formatFuncBodyStmt(fmtCtx, stmt.AST, i > 0 /* newLine */)
+
+			// Validate that the result type of the last statement matches the
+			// return type of the function. The result of a trigger function is
+			// checked against the row type of the table when the trigger is
+			// invoked.
+			if i == len(stmts)-1 && !isTriggerFunc {
+				// TODO(mgartner): stmtScope.cols does not describe the result
+				// columns of the statement. We should use physical.Presentation
+				// instead.
+				expectedType := funcReturnType
+				if cf.IsProcedure && len(funcReturnType.TupleContents()) == 1 {
+					// The final statement of a procedure with a single OUT parameter
+					// returns a single column.
+					expectedType = funcReturnType.TupleContents()[0]
+				}
+				err := validateReturnType(expectedType, stmtScope.cols)
+				if err != nil {
+					panic(err)
+				}
+			}
+
+			deps = append(deps, b.schemaDeps...)
+			typeDeps.UnionWith(b.schemaTypeDeps)
+			// Add statement ast into CreateFunction node for logging purpose.
+			cf.BodyStatements = append(cf.BodyStatements, stmt.AST)
+			// Reset the tracked dependencies for next statement.
+			b.schemaDeps = nil
+			b.schemaTypeDeps = intsets.Fast{}
+		}
+
 	}
 
 	if targetVolatility == tree.FunctionImmutable && len(deps) > 0 {
@@ -13,9 +13,14 @@ package optbuilder
 import (
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/paramparse"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/intsets"
 	"github.com/cockroachdb/errors"
@@ -51,6 +56,21 @@ func (b *Builder) buildCreateView(cv *tree.CreateView, inScope *scope) (outScope
 
 	defScope := b.buildStmtAtRoot(cv.AsSource, nil /* desiredTypes */)
 
+	incremental := b.isIncrementalView(cv.Params)
+	if incremental {
+		// An incrementally refreshed view only recomputes the rows affected by
+		// changes to its base tables, so the view query must return the same
+		// result for rows that have not changed.
+		if vs := defScope.expr.Relational().VolatilitySet; vs.HasStable() || vs.HasVolatile() {
+			panic(pgerror.New(pgcode.FeatureNotSupported,
+				"incrementally refreshed materialized views cannot use stable or volatile functions"))
+		}
+		if containsProjectSet(defScope.expr) {
+			panic(pgerror.New(pgcode.FeatureNotSupported,
+				"incrementally refreshed materialized views cannot use set-returning functions"))
+		}
+	}
+
 	p := defScope.makePhysicalProps().Presentation
 	if len(cv.ColumnNames) != 0 {
 		if len(p) != len(cv.ColumnNames) {
@@ -98,11 +118,53 @@ func (b *Builder) buildCreateView(cv *tree.CreateView, inScope *scope) (outScope
 			Deps:         b.schemaDeps,
 			TypeDeps:     b.schemaTypeDeps,
 			WithData:     cv.WithData,
+			Incremental:  incremental,
 		},
 	)
 	return outScope
 }
 
+// isIncrementalView returns true if the given storage parameters of a
+// materialized view enable incremental refresh.
+func (b *Builder) isIncrementalView(params tree.StorageParams) bool {
+	incremental := false
+	for i := range params {
+		param := &params[i]
+		if param.Key != "incremental" {
+			panic(pgerror.Newf(pgcode.InvalidParameterValue, "invalid storage parameter %q", param.Key))
+		}
+		if param.Value == nil {
+			incremental = true
+			continue
+		}
+		texpr, err := tree.TypeCheckAndRequire(
+			b.ctx, paramparse.UnresolvedNameToStrVal(param.Value), b.semaCtx, types.Bool, "incremental",
+		)
+		if err != nil {
+			panic(err)
+		}
+		incremental, err = paramparse.DatumAsBool(b.ctx, b.evalCtx, "incremental", texpr)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return incremental
+}
+
+// containsProjectSet returns true if the given expression contains a
+// ProjectSet operator, i.e. if it uses set-returning functions.
+func containsProjectSet(e opt.Expr) bool {
+	if e.Op() == opt.ProjectSetOp {
+		return true
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		if containsProjectSet(e.Child(i)) {
+			return true
+		}
+	}
+	return false
+}
+
 func maybePanicOnUnknownFunction(target string) {
 	// TODO(chengxiong,mgartner): this is a hack to disallow UDF usage in view and
 	// we will need to lift this hack when we plan to allow it.
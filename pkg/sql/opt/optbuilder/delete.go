@@ -40,6 +40,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 
 	// Find which table we're working on, check the permissions.
 	tab, depName, alias, refColumns := b.resolveTableForMutation(del.Table, privilege.DELETE)
+	checkInheritanceChildrenForMutation(tab, del.Table, "DELETE")
 
 	if refColumns != nil {
 		panic(pgerror.Newf(pgcode.Syntax,
@@ -77,8 +78,13 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 // buildDelete constructs a Delete operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildDelete(returning *tree.ReturningExprs) {
+	// Invoke BEFORE ROW triggers, which may skip rows.
+	mb.buildBeforeRowTriggers(tree.TriggerEventDelete)
+
 	mb.buildFKChecksAndCascadesForDelete()
 
+	mb.buildAfterTriggers(tree.TriggerEventDelete, mb.fetchColIDs, nil /* newColIDs */)
+
 	// Project partial index DEL boolean columns.
 	mb.projectPartialIndexDelCols()
 
@@ -93,4 +99,6 @@ func (mb *mutationBuilder) buildDelete(returning *tree.ReturningExprs) {
 	)
 
 	mb.buildReturning(returning)
+
+	mb.buildBeforeStatementTriggers(tree.TriggerEventDelete)
 }
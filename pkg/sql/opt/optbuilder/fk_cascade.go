@@ -892,6 +892,7 @@ func buildCascadeHelper(
 ) (_ memo.RelExpr, err error) {
 	factory := factoryI.(*norm.Factory)
 	b := New(ctx, semaCtx, evalCtx, catalog, factory, nil /* stmt */)
+	b.insideNestedStmt = true
 
 	// Enact panic handling similar to Builder.Build().
 	defer func() {
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -100,8 +101,27 @@ type groupby struct {
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
 	buildingGroupingCols bool
+
+	// expand is non-nil if the GROUP BY clause has multiple grouping sets
+	// (specified with GROUPING SETS, ROLLUP or CUBE). In that case, an Expand
+	// operator is built between the pre-projection and the aggregation, and
+	// groupStrs maps the GROUP BY expressions to the columns produced by the
+	// Expand operator rather than to the grouping columns in aggInScope.
+	expand *memo.ExpandPrivate
 }
 
+const (
+	// maxGroupingSets is the maximum number of grouping sets in a GROUP BY
+	// clause.
+	maxGroupingSets = 4096
+
+	// maxCubeElements is the maximum number of elements in a CUBE.
+	maxCubeElements = 12
+
+	// maxGroupingArgs is the maximum number of arguments to GROUPING.
+	maxGroupingArgs = 31
+)
+
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
 // grouping column in an aggOutScope scope that projects that expression. It
 // is used to enforce scoping rules, since any non-aggregate, variable
@@ -332,10 +352,45 @@ func (b *Builder) buildGroupingColumns(sel *tree.SelectClause, projectionsScope,
 	g := fromScope.groupby
 
 	// The "from" columns are visible to any grouping expressions.
-	b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
+	groupingSets := b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
+	if groupingSets == nil {
+		// Copy the grouping columns to the aggOutScope.
+		g.aggOutScope.appendColumns(g.groupingCols())
+		return
+	}
+
+	// There are multiple grouping sets. The Expand operator produces a copy of
+	// each grouping column which is NULL when the grouping column is not part
+	// of the grouping set, and these copies are grouped on instead of the
+	// grouping columns. Add the copies to the aggOutScope.
+	groupingCols := g.groupingCols()
+	g.expand = &memo.ExpandPrivate{
+		GroupingCols: make(opt.ColList, len(groupingCols)),
+		OutCols:      make(opt.ColList, len(groupingCols)),
+		GroupingSets: groupingSets,
+	}
+	outColsStart := len(g.aggOutScope.cols)
+	for i := range groupingCols {
+		col := &groupingCols[i]
+		outCol := b.synthesizeColumn(g.aggOutScope, col.name, col.typ, col.expr, nil /* scalar */)
+		g.expand.GroupingCols[i] = col.id
+		g.expand.OutCols[i] = outCol.id
+	}
+
+	// References to GROUP BY expressions must now resolve to the copies.
+	for exprStr, col := range g.groupStrs {
+		ord, ok := g.expand.GroupingCols.Find(col.id)
+		if !ok {
+			panic(errors.AssertionFailedf("grouping column %d not found", col.id))
+		}
+		g.groupStrs[exprStr] = &g.aggOutScope.cols[outColsStart+ord]
+	}
 
-	// Copy the grouping columns to the aggOutScope.
-	g.aggOutScope.appendColumns(g.groupingCols())
+	md := b.factory.Metadata()
+	g.expand.GroupingIDCol = md.AddColumn("grouping_id", types.Int)
+	if groupingSets.HasEmptySet() {
+		g.expand.InputRowCol = md.AddColumn("input_row", types.Bool)
+	}
 }
 
 // buildAggregation builds the aggregation operators and constructs the
@@ -345,15 +400,24 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 
 	groupingCols := g.groupingCols()
 
-	// Build ColSet of grouping columns.
+	// Build ColSet of grouping columns. With multiple grouping sets, the
+	// aggregation groups on the columns produced by the Expand operator.
 	var groupingColSet opt.ColSet
-	for i := range groupingCols {
-		groupingColSet.Add(groupingCols[i].id)
+	if g.expand != nil {
+		groupingColSet = g.expand.OutCols.ToSet()
+		groupingColSet.Add(g.expand.GroupingIDCol)
+	} else {
+		for i := range groupingCols {
+			groupingColSet.Add(groupingCols[i].id)
+		}
 	}
 
 	// If there are any aggregates that are ordering sensitive, build the
 	// aggregations as window functions over each group.
 	if g.hasNonCommutativeAggregates() {
+		if g.expand != nil {
+			panic(unimplemented.NewWithIssue(46280, "ordered aggregates with grouping sets"))
+		}
 		return b.buildAggregationAsWindow(groupingColSet, having, fromScope)
 	}
 
@@ -404,6 +468,14 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 			argCols = argCols[1:]
 			variable := b.factory.ConstructVariable(colID)
 			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
+		} else if g.expand != nil && g.expand.InputRowCol != 0 {
+			// The Expand operator fabricates a row for each empty grouping set
+			// when its input is empty, so that every empty grouping set produces
+			// a group. Those rows must not be aggregated. Note that aggregates
+			// with a filter already ignore them, since all the columns other than
+			// the grouping ID are NULL in the fabricated rows.
+			variable := b.factory.ConstructVariable(g.expand.InputRowCol)
+			aggCols[i].scalar = b.factory.ConstructAggFilter(aggCols[i].scalar, variable)
 		}
 
 		if agg.isOrderingSensitive() {
@@ -430,8 +502,13 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
+	input := g.aggInScope.expr
+	if g.expand != nil {
+		input = b.factory.ConstructExpand(input, g.expand)
+	}
+
 	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr,
+		input,
 		groupingColSet,
 		aggCols,
 		g.aggInScope.ordering,
@@ -481,7 +558,9 @@ func (b *Builder) buildHaving(having tree.TypedExpr, fromScope *scope) opt.Scala
 
 // buildGroupingList builds a set of memo groups that represent a list of
 // GROUP BY expressions, adding the group-by expressions as columns to
-// aggInScope and populating groupStrs.
+// aggInScope and populating groupStrs. If the GROUP BY expressions specify
+// multiple grouping sets, buildGroupingList returns them as sets of columns in
+// aggInScope. Otherwise, it returns nil.
 //
 // groupBy   The given GROUP BY expressions.
 // selects   The select expressions are needed in case one of the GROUP BY
@@ -493,7 +572,7 @@ func (b *Builder) buildHaving(having tree.TypedExpr, fromScope *scope) opt.Scala
 // fromScope The scope for the input to the aggregation (the FROM clause).
 func (b *Builder) buildGroupingList(
 	groupBy tree.GroupBy, selects tree.SelectExprs, projectionsScope *scope, fromScope *scope,
-) {
+) memo.GroupingSets {
 	g := fromScope.groupby
 	g.groupStrs = make(groupByStrSet, len(groupBy))
 	if g.aggInScope.cols == nil {
@@ -509,15 +588,103 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	defer func() { g.buildingGroupingCols = false }()
+
+	// Each GROUP BY item specifies a list of grouping sets; an item which is not
+	// GROUPING SETS, ROLLUP or CUBE specifies a single grouping set. The
+	// grouping sets of the GROUP BY clause are formed by combining one grouping
+	// set of each item in every possible way.
+	sets := memo.GroupingSets{opt.ColSet{}}
+	hasGroupingSets := false
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		if _, ok := e.(*tree.GroupingSets); ok {
+			hasGroupingSets = true
+		}
+		sets = crossGroupingSets(sets, b.buildGroupingSets(e, selects, projectionsScope, fromScope))
+	}
+	if !hasGroupingSets || len(sets) == 1 {
+		// A single grouping set contains all the grouping columns, so it is
+		// equivalent to a regular GROUP BY.
+		return nil
+	}
+	return sets
+}
+
+// buildGroupingSets builds the GROUP BY expressions of a GROUP BY item (see
+// buildGrouping) and returns the list of grouping sets that it specifies.
+func (b *Builder) buildGroupingSets(
+	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) memo.GroupingSets {
+	aggInScope := fromScope.groupby.aggInScope
+	gs, ok := groupBy.(*tree.GroupingSets)
+	if !ok {
+		return memo.GroupingSets{b.buildGrouping(groupBy, selects, projectionsScope, fromScope, aggInScope)}
+	}
+
+	switch gs.Type {
+	case tree.GroupingSetsRollup:
+		// ROLLUP (a, b) is equivalent to GROUPING SETS ((a, b), (a), ()).
+		sets := make(memo.GroupingSets, len(gs.Exprs)+1)
+		var prefix opt.ColSet
+		for i, e := range gs.Exprs {
+			prefix = prefix.Union(b.buildGrouping(e, selects, projectionsScope, fromScope, aggInScope))
+			sets[len(gs.Exprs)-1-i] = prefix
+		}
+		return sets
+
+	case tree.GroupingSetsCube:
+		// CUBE (a, b) is equivalent to GROUPING SETS ((a, b), (a), (b), ()).
+		if len(gs.Exprs) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"CUBE is limited to %d elements", maxCubeElements))
+		}
+		elems := make([]opt.ColSet, len(gs.Exprs))
+		for i, e := range gs.Exprs {
+			elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, aggInScope)
+		}
+		sets := make(memo.GroupingSets, 1<<len(elems))
+		for i := range sets {
+			// The i-th grouping set contains the elements whose bits are not set in
+			// i, where the first element corresponds to the most significant bit.
+			for j := range elems {
+				if i&(1<<(len(elems)-1-j)) == 0 {
+					sets[i].UnionWith(elems[j])
+				}
+			}
+		}
+		return sets
+
+	default:
+		var sets memo.GroupingSets
+		for _, e := range gs.Exprs {
+			sets = append(sets, b.buildGroupingSets(e, selects, projectionsScope, fromScope)...)
+			if len(sets) > maxGroupingSets {
+				panic(newTooManyGroupingSetsError())
+			}
+		}
+		return sets
+	}
+}
+
+// crossGroupingSets returns the union of each grouping set in left with each
+// grouping set in right.
+func crossGroupingSets(left, right memo.GroupingSets) memo.GroupingSets {
+	if len(left)*len(right) > maxGroupingSets {
+		panic(newTooManyGroupingSetsError())
+	}
+	res := make(memo.GroupingSets, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			res = append(res, l.Union(r))
+		}
 	}
-	g.buildingGroupingCols = false
+	return res
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. Returns the set of grouping columns for the
+// expression.
 //
 // groupBy          The given GROUP BY expression.
 // selects          The select expressions are needed in case the GROUP BY
@@ -537,7 +704,7 @@ func (b *Builder) buildGroupingList(
 //	as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) (cols opt.ColSet) {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -635,7 +802,8 @@ func (b *Builder) buildGrouping(
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+			cols.Add(col.id)
 			continue
 		}
 
@@ -645,7 +813,77 @@ func (b *Builder) buildGrouping(
 		col := aggInScope.addColumn(scopeColName(tree.Name(alias)), e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
 		fromScope.groupby.groupStrs[exprStr] = col
+		cols.Add(col.id)
 	}
+	return cols
+}
+
+// buildGroupingExpr builds a GROUPING expression. Its result is a bitmask with
+// a bit for each argument, where the last argument corresponds to the least
+// significant bit. A bit is set if the corresponding argument is not part of
+// the grouping set of the current group.
+func (b *Builder) buildGroupingExpr(grouping *tree.GroupingExpr, inScope *scope) opt.ScalarExpr {
+	if len(grouping.Exprs) > maxGroupingArgs {
+		panic(pgerror.Newf(pgcode.TooManyArguments,
+			"GROUPING must have fewer than %d arguments", maxGroupingArgs+1))
+	}
+	switch inScope.context {
+	case exprKindLateralJoin:
+		panic(pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in FROM clause of their own query level",
+		))
+
+	case exprKindOn:
+		panic(pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in JOIN conditions",
+		))
+
+	case exprKindWhere:
+		panic(pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in %s", inScope.context,
+		))
+	}
+	g := inScope.groupby
+	if g == nil || inScope.inAgg || g.buildingGroupingCols {
+		panic(newGroupingArgsError())
+	}
+
+	// Find the ordinal of each argument among the grouping columns.
+	ords := make([]int, len(grouping.Exprs))
+	for i := range grouping.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(grouping.Exprs[i].(tree.TypedExpr))]
+		if !ok {
+			panic(newGroupingArgsError())
+		}
+		if g.expand != nil {
+			ords[i], _ = g.expand.OutCols.Find(col.id)
+		}
+	}
+	if g.expand == nil {
+		// There is a single grouping set, which contains all grouping columns.
+		return b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	}
+
+	// Compute the result for each grouping set, and build a CASE expression
+	// which selects the result based on the grouping ID.
+	masks := make([]int, len(g.expand.GroupingSets))
+	for i, set := range g.expand.GroupingSets {
+		for j, ord := range ords {
+			if !set.Contains(g.expand.GroupingCols[ord]) {
+				masks[i] |= 1 << (len(ords) - 1 - j)
+			}
+		}
+	}
+	last := len(masks) - 1
+	whens := make(memo.ScalarListExpr, 0, last)
+	for i := 0; i < last; i++ {
+		whens = append(whens, b.factory.ConstructWhen(
+			b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int),
+			b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(masks[i])), types.Int),
+		))
+	}
+	orElse := b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(masks[last])), types.Int)
+	return b.factory.ConstructCase(b.factory.ConstructVariable(g.expand.GroupingIDCol), whens, orElse)
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
@@ -923,11 +1161,28 @@ func newGroupingError(name tree.Name) error {
 	)
 }
 
+func newGroupingArgsError() error {
+	return pgerror.New(pgcode.Grouping,
+		"arguments to GROUPING must be grouping expressions of the associated query level",
+	)
+}
+
+func newTooManyGroupingSetsError() error {
+	return pgerror.Newf(pgcode.StatementTooComplex,
+		"too many grouping sets present (maximum %d)", maxGroupingSets,
+	)
+}
+
 // allowImplicitGroupingColumn returns true if col is part of a table and the
 // the groupby metadata indicates that we are grouping on the entire PK of that
 // table. In that case, we can allow col as an "implicit" grouping column, even
-// if it is not specified in the query.
+// if it is not specified in the query. Implicit grouping columns are not
+// allowed with multiple grouping sets, since the grouping columns can be NULL
+// in the result of the aggregation.
 func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bool {
+	if g.expand != nil {
+		return false
+	}
 	md := b.factory.Metadata()
 	colMeta := md.ColumnMeta(colID)
 	if colMeta.Table == 0 {
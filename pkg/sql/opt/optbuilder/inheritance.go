@@ -0,0 +1,140 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// buildInheritanceChildren builds a scan of a table which is inherited by
+// other tables, so that the rows of the children (and of their own children,
+// recursively) are included. parentScope is the scope of the scan of the
+// parent table. The result is a UNION ALL of the parent scan and a scan of
+// each child:
+//
+//	SELECT * FROM parent
+//	=>
+//	SELECT a, b FROM ONLY parent
+//	UNION ALL SELECT a, b FROM child1
+//	UNION ALL SELECT a, b FROM child2
+//
+// Children always contain the columns of their parents, since they are
+// inherited when the child is created. Columns of the parent which are not
+// present in every child, such as a hidden rowid column, are not included in
+// the output. The children are read with the privileges of the parent table,
+// like in Postgres.
+//
+// Filters on the result are pushed into each input of the UNION ALL, where the
+// PruneInheritanceChild rule removes the children whose check constraints
+// contradict the filters.
+func (b *Builder) buildInheritanceChildren(
+	parent cat.Table, parentScope *scope, locking lockingSpec, inScope *scope,
+) (outScope *scope) {
+	outScope = parentScope
+	for i, n := 0, parent.InheritanceChildCount(); i < n; i++ {
+		childID := parent.InheritanceChild(i)
+		ds, _, err := b.catalog.ResolveDataSourceByID(b.ctx, cat.Flags{}, childID)
+		if err != nil {
+			panic(err)
+		}
+		child, ok := ds.(cat.Table)
+		if !ok {
+			panic(errors.AssertionFailedf("inheritance child %d is not a table", childID))
+		}
+		// The privileges on the child are not checked, but a dependency is added
+		// so that the memo is invalidated when the child changes.
+		b.factory.Metadata().AddDependency(opt.DepByID(childID), child, 0 /* priv */)
+
+		childName := tree.MakeUnqualifiedTableName(child.Name())
+		tabMeta := b.addTable(child, &childName)
+		childScope := b.buildScan(
+			tabMeta,
+			tableOrdinals(child, columnKinds{
+				includeMutations: false,
+				includeSystem:    true,
+				includeInverted:  false,
+			}),
+			nil /* indexFlags */, locking, inScope,
+			false, /* disableNotVisibleIndex */
+		)
+		if child.InheritanceChildCount() > 0 {
+			childScope = b.buildInheritanceChildren(child, childScope, locking, inScope)
+		}
+		outScope = b.buildInheritanceUnion(outScope, childScope, inScope)
+	}
+	return outScope
+}
+
+// buildInheritanceUnion builds a UNION ALL of the scan of a parent table (and
+// of the children which have already been added to it) and the scan of a
+// child table. The columns are matched by name. The output columns keep the
+// table name and visibility of the parent columns, so that they can be
+// referenced as if they were the columns of a scan of the parent.
+func (b *Builder) buildInheritanceUnion(leftScope, rightScope, inScope *scope) (outScope *scope) {
+	outScope = inScope.push()
+	leftCols := make(opt.ColList, 0, len(leftScope.cols))
+	rightCols := make(opt.ColList, 0, len(leftScope.cols))
+	for i := range leftScope.cols {
+		left := &leftScope.cols[i]
+		var right *scopeColumn
+		for j := range rightScope.cols {
+			if rightScope.cols[j].name.MatchesReferenceName(left.name.ReferenceName()) {
+				right = &rightScope.cols[j]
+				break
+			}
+		}
+		if right == nil {
+			continue
+		}
+		if !left.typ.Identical(right.typ) {
+			panic(pgerror.Newf(pgcode.DatatypeMismatch,
+				"column %q has type %s in the parent table but %s in an inheriting table",
+				left.name.ReferenceName(), left.typ.SQLString(), right.typ.SQLString(),
+			))
+		}
+		col := b.synthesizeColumn(outScope, left.name, left.typ, nil /* expr */, nil /* scalar */)
+		col.table = left.table
+		col.visibility = left.visibility
+		leftCols = append(leftCols, left.id)
+		rightCols = append(rightCols, right.id)
+	}
+	outScope.expr = b.factory.ConstructUnionAll(leftScope.expr, rightScope.expr, &memo.SetPrivate{
+		LeftCols:  leftCols,
+		RightCols: rightCols,
+		OutCols:   colsToColList(outScope.cols),
+	})
+	return outScope
+}
+
+// checkInheritanceChildrenForMutation raises an error if the target of an
+// UPDATE or DELETE statement is inherited by other tables and ONLY was not
+// specified. Updating and deleting the rows of the children through their
+// parent is not yet supported.
+func checkInheritanceChildrenForMutation(tab cat.Table, n tree.TableExpr, op string) {
+	if tab.InheritanceChildCount() == 0 {
+		return
+	}
+	if ate, ok := n.(*tree.AliasedTableExpr); ok && ate.Only {
+		return
+	}
+	panic(unimplemented.NewWithIssueDetailf(22456, op,
+		"%s of table %q with inheriting tables is not supported; "+
+			"specify ONLY to modify the rows of %q alone",
+		op, tab.Name(), tab.Name(),
+	))
+}
@@ -223,6 +223,15 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		mb.init(b, "insert", tab, alias)
 	}
 
+	// Triggers are not yet supported for statements which may either insert
+	// or update rows.
+	if ins.OnConflict != nil && !ins.OnConflict.DoNothing &&
+		mb.hasTriggers(tree.TriggerEventInsert, tree.TriggerEventUpdate) {
+		panic(unimplemented.NewWithIssuef(28296,
+			"%s is not supported on table %q with INSERT or UPDATE triggers",
+			ins.StatementTag(), tab.Name()))
+	}
+
 	// Compute target columns in two cases:
 	//
 	//   1. When explicitly specified by name:
@@ -660,6 +669,11 @@ func (mb *mutationBuilder) addSynthesizedColsForInsert() {
 	// Add assignment casts for default column values.
 	mb.addAssignmentCasts(mb.insertColIDs)
 
+	// Invoke BEFORE ROW triggers, which may modify the inserted values. This
+	// must happen before computed columns are added, since those may depend
+	// on the modified values.
+	mb.buildBeforeRowTriggers(tree.TriggerEventInsert)
+
 	// Now add all computed columns.
 	mb.addSynthesizedComputedCols(mb.insertColIDs, false /* restrict */)
 
@@ -684,12 +698,16 @@ func (mb *mutationBuilder) buildInsert(returning *tree.ReturningExprs) {
 
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerEventInsert, nil /* oldColIDs */, mb.insertColIDs)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
 	)
 
 	mb.buildReturning(returning)
+
+	mb.buildBeforeStatementTriggers(tree.TriggerEventInsert)
 }
 
 // buildInputForDoNothing wraps the input expression in ANTI JOIN expressions,
@@ -0,0 +1,668 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/intsets"
+)
+
+// duplicateMergeErrText is error text used when a target row is matched by
+// more than one source row of a MERGE statement.
+const duplicateMergeErrText = "MERGE command cannot affect row a second time"
+
+// buildMerge builds a memo group for a MergeOp expression. The source relation
+// is left-joined with the target table using the ON condition, and each of the
+// joined rows is assigned the first WHEN clause that applies to it:
+//
+//	MERGE INTO abc USING xyz ON a = x
+//	WHEN MATCHED AND z IS NULL THEN DELETE
+//	WHEN MATCHED THEN UPDATE SET b = y
+//	WHEN NOT MATCHED THEN INSERT VALUES (x, y, z)
+//
+// is built like:
+//
+//	SELECT
+//	  x, y, z, fetch_a, fetch_b, fetch_c,
+//	  CASE clause WHEN 3 THEN x END AS ins_a,
+//	  CASE clause WHEN 3 THEN y END AS ins_b,
+//	  CASE clause WHEN 3 THEN z END AS ins_c,
+//	  CASE clause WHEN 2 THEN y ELSE fetch_b END AS upd_b,
+//	  ...
+//	FROM (
+//	  SELECT
+//	    *,
+//	    CASE
+//	      WHEN fetch_a IS NOT NULL AND z IS NULL THEN 1
+//	      WHEN fetch_a IS NOT NULL THEN 2
+//	      WHEN fetch_a IS NULL THEN 3
+//	      ELSE 0
+//	    END AS clause,
+//	    CASE clause WHEN 1 THEN <delete> WHEN 2 THEN <update> ... END AS action
+//	  FROM xyz LEFT JOIN abc AS fetch ON a = x
+//	)
+//	WHERE action != <do nothing>
+//
+// A not-null column of the primary index serves as the canary column that
+// tells apart matched and unmatched rows, like it does for UPSERT. An error is
+// raised if more than one source row would modify the same target row. The
+// action column tells the Merge operator whether to insert, update or delete
+// each row; the insert, fetch and update columns are then used the same way
+// as they are used by the Upsert operator.
+func (b *Builder) buildMerge(merge *tree.Merge, inScope *scope) (outScope *scope) {
+	// Find which table we're working on, check the permissions.
+	tab, depName, alias, refColumns := b.resolveTableForMutation(merge.Table, privilege.SELECT)
+
+	if refColumns != nil {
+		panic(pgerror.Newf(pgcode.Syntax,
+			"cannot specify a list of column IDs with MERGE"))
+	}
+
+	// Check the privileges needed by the actions of the WHEN clauses.
+	var hasInsert, hasUpdate, hasDelete bool
+	for _, when := range merge.Whens {
+		switch when.Action {
+		case tree.MergeActionInsert:
+			hasInsert = true
+		case tree.MergeActionUpdate:
+			hasUpdate = true
+		case tree.MergeActionDelete:
+			hasDelete = true
+		}
+	}
+	if hasInsert {
+		b.checkPrivilege(depName, tab, privilege.INSERT)
+	}
+	if hasUpdate {
+		b.checkPrivilege(depName, tab, privilege.UPDATE)
+	}
+	if hasDelete {
+		b.checkPrivilege(depName, tab, privilege.DELETE)
+	}
+
+	// Check if this table has already been mutated in another subquery.
+	b.checkMultipleMutations(tab, false /* simpleInsert */)
+
+	var mb mutationBuilder
+	mb.init(b, "merge", tab, alias)
+
+	// Triggers are not yet supported for statements which may insert, update or
+	// delete rows.
+	if mb.hasTriggers(tree.TriggerEventInsert, tree.TriggerEventUpdate, tree.TriggerEventDelete) {
+		panic(unimplemented.NewWithIssuef(28296,
+			"%s is not supported on table %q with triggers", merge.StatementTag(), tab.Name()))
+	}
+
+	// Build the left join between the source and the target table, and filter
+	// out the rows to which no WHEN clause applies.
+	sourceScope, mergeScope := mb.buildInputForMerge(inScope, merge)
+
+	// Build the values that will be inserted or will update existing rows.
+	mb.addMergeValueCols(merge.Whens, sourceScope, mergeScope)
+
+	// Add default and computed columns for inserted rows.
+	if hasInsert {
+		mb.setMergeColNames(mb.insertColIDs, false /* includeFetchCols */)
+		mb.addSynthesizedColsForInsert()
+	}
+
+	// Add computed columns and columns with ON UPDATE expressions for updated
+	// rows.
+	mb.setMergeColNames(mb.updateColIDs, true /* includeFetchCols */)
+	if hasUpdate {
+		mb.addSynthesizedColsForUpdate()
+	}
+
+	// Project the old values of deleted rows, which are needed for inbound
+	// foreign key checks and cascades.
+	if hasDelete {
+		mb.projectMergeDeleteCols()
+	}
+
+	// Build the final merge statement, including any returned expressions.
+	if resultsNeeded(merge.Returning) {
+		mb.buildMerge(merge.Returning.(*tree.ReturningExprs))
+	} else {
+		mb.buildMerge(nil /* returning */)
+	}
+
+	return mb.outScope
+}
+
+// buildInputForMerge left-joins the source relation of a MERGE statement with
+// the target table using the ON condition, and projects the clause and action
+// columns described in the comment for Builder.buildMerge. Rows to which no
+// clause applies, or whose clause is DO NOTHING, are filtered out, and then an
+// error is raised for any target row matched by more than one source row.
+//
+// buildInputForMerge returns the scope of the source relation, which is used
+// to build the expressions of WHEN NOT MATCHED clauses, and the scope of the
+// join, which is used to build the expressions of WHEN MATCHED clauses.
+func (mb *mutationBuilder) buildInputForMerge(
+	inScope *scope, merge *tree.Merge,
+) (sourceScope, mergeScope *scope) {
+	var indexFlags *tree.IndexFlags
+	if source, ok := merge.Table.(*tree.AliasedTableExpr); ok && source.IndexFlags != nil {
+		indexFlags = source.IndexFlags
+	}
+
+	// USING
+	sourceScope = mb.b.buildFromTables(tree.TableExprs{merge.Source}, noRowLocking, inScope)
+
+	// Fetch columns from a different instance of the table metadata, so that
+	// it's possible to remap columns. See buildInputForUpdate.
+	mb.fetchScope = mb.b.buildScan(
+		mb.b.addTable(mb.tab, &mb.alias),
+		tableOrdinals(mb.tab, columnKinds{
+			includeMutations: true,
+			includeSystem:    true,
+			includeInverted:  false,
+		}),
+		indexFlags,
+		noRowLocking,
+		inScope,
+		false, /* disableNotVisibleIndex */
+	)
+
+	// Set list of columns that will be fetched by the input expression.
+	mb.setFetchColIDs(mb.fetchScope.cols)
+
+	// Check that the same table name is not used multiple times.
+	mb.b.validateJoinTableNames(sourceScope, mb.fetchScope)
+
+	// The source columns can be accessed by the RETURNING clause of the query
+	// and so we have to make them accessible.
+	mb.extraAccessibleCols = sourceScope.cols
+
+	// ON
+	mergeScope = inScope.push()
+	mergeScope.appendColumnsFromScope(sourceScope)
+	mergeScope.appendColumnsFromScope(mb.fetchScope)
+
+	scalarProps := &mb.b.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+	scalarProps.Require(exprKindOn.String(), tree.RejectSpecial)
+	mergeScope.context = exprKindOn
+	on := mb.b.buildScalar(
+		mergeScope.resolveAndRequireType(merge.On, types.Bool), mergeScope, nil, nil, nil,
+	)
+	mergeScope.expr = mb.b.factory.ConstructLeftJoin(
+		sourceScope.expr,
+		mb.fetchScope.expr,
+		memo.FiltersExpr{mb.b.factory.ConstructFiltersItem(on)},
+		memo.EmptyJoinPrivate,
+	)
+	mergeScope.context = exprKindNone
+
+	// Use a not-null column of the primary index to tell apart matched and
+	// unmatched rows.
+	canaryOrd := findNotNullIndexCol(mb.tab.Index(cat.PrimaryIndex))
+	canaryCol := mb.b.factory.ConstructVariable(mb.fetchColIDs[canaryOrd])
+
+	// Build the clause column, which is the 1-based ordinal of the first WHEN
+	// clause that applies to each row, or zero if no clause applies.
+	scalarProps.Require("MERGE WHEN", tree.RejectSpecial)
+	whens := make(memo.ScalarListExpr, len(merge.Whens))
+	actions := make(memo.ScalarListExpr, len(merge.Whens))
+	for i, when := range merge.Whens {
+		var cond opt.ScalarExpr
+		condScope := sourceScope
+		if when.Matched {
+			cond = mb.b.factory.ConstructIsNot(canaryCol, memo.NullSingleton)
+			condScope = mergeScope
+		} else {
+			cond = mb.b.factory.ConstructIs(canaryCol, memo.NullSingleton)
+		}
+		if when.Cond != nil {
+			texpr := condScope.resolveAndRequireType(when.Cond, types.Bool)
+			cond = mb.b.factory.ConstructAnd(cond, mb.b.buildScalar(texpr, condScope, nil, nil, nil))
+		}
+		clause := mb.makeMergeInt(i + 1)
+		whens[i] = mb.b.factory.ConstructWhen(cond, clause)
+		actions[i] = mb.b.factory.ConstructWhen(clause, mb.makeMergeInt(int(when.Action)))
+	}
+
+	mb.outScope = mergeScope.replace()
+	mb.outScope.appendColumnsFromScope(mergeScope)
+	clauseCol := mb.b.synthesizeColumn(
+		mb.outScope,
+		scopeColName("").WithMetadataName("merge_clause"),
+		types.Int,
+		nil, /* expr */
+		mb.b.factory.ConstructCase(memo.TrueSingleton, whens, mb.makeMergeInt(0)),
+	)
+	mb.mergeClauseColID = clauseCol.id
+	mb.b.constructProjectForScope(mergeScope, mb.outScope)
+
+	// Build the action column from the clause column.
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	actionCol := mb.b.synthesizeColumn(
+		projectionsScope,
+		scopeColName("").WithMetadataName("merge_action"),
+		types.Int,
+		nil, /* expr */
+		mb.b.factory.ConstructCase(
+			mb.b.factory.ConstructVariable(mb.mergeClauseColID),
+			actions,
+			mb.makeMergeInt(int(tree.MergeActionDoNothing)),
+		),
+	)
+	mb.mergeActionColID = actionCol.id
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// Filter out rows that are left alone.
+	mb.outScope.expr = mb.b.factory.ConstructSelect(
+		mb.outScope.expr,
+		memo.FiltersExpr{mb.b.factory.ConstructFiltersItem(
+			mb.b.factory.ConstructNe(
+				mb.b.factory.ConstructVariable(mb.mergeActionColID),
+				mb.makeMergeInt(int(tree.MergeActionDoNothing)),
+			),
+		)},
+	)
+
+	// Build a distinct on the primary key columns to ensure that every target
+	// row is modified at most once. Unmatched rows have NULL primary key values
+	// and must not be grouped together.
+	var pkCols opt.ColSet
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
+		col := primaryIndex.Column(i)
+		pkCols.Add(mb.fetchColIDs[col.Ordinal()])
+	}
+	mb.outScope = mb.b.buildDistinctOn(
+		pkCols, mb.outScope, true /* nullsAreDistinct */, duplicateMergeErrText,
+	)
+
+	return sourceScope, mergeScope
+}
+
+// makeMergeInt returns a constant INT expression with the given value, used
+// for the clause and action columns of a MERGE statement.
+func (mb *mutationBuilder) makeMergeInt(i int) opt.ScalarExpr {
+	return mb.b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)
+}
+
+// mergeValues accumulates, for a single table column, the WHEN branches of the
+// CASE expression that computes the column's inserted or updated value.
+type mergeValues []memo.ScalarListExpr
+
+// addMergeValueCols builds the expressions of the INSERT and UPDATE actions of
+// the given WHEN clauses. There is one insert column for each table column that
+// is targeted by any INSERT action, and one update column for each table column
+// that is targeted by any UPDATE action. Each of these columns is a CASE
+// expression over the clause column:
+//
+//	CASE merge_clause WHEN 1 THEN <value 1> WHEN 4 THEN <value 4> ... END
+//
+// Insert columns default to NULL and update columns default to the fetched
+// value. Columns that are not targeted by an INSERT action are added later by
+// addSynthesizedColsForInsert.
+func (mb *mutationBuilder) addMergeValueCols(whens tree.MergeWhens, sourceScope, mergeScope *scope) {
+	// INSERT values and SET expressions should reject aggregates, generators,
+	// etc.
+	scalarProps := &mb.b.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+
+	n := mb.tab.ColumnCount()
+	insertVals := make(mergeValues, n)
+	updateVals := make(mergeValues, n)
+	var insertTargets, updateTargets opt.ColSet
+
+	// insertClauses records, for each table column, the INSERT actions that
+	// target the column.
+	insertClauses := make([]intsets.Fast, n)
+
+	addValue := func(vals mergeValues, clause int, inScope *scope, expr tree.Expr, targetColID opt.ColumnID) {
+		ord := mb.tabID.ColumnOrdinal(targetColID)
+		vals[ord] = append(vals[ord], mb.b.factory.ConstructWhen(
+			mb.makeMergeInt(clause), mb.buildMergeValue(inScope, expr, ord),
+		))
+	}
+
+	for i, when := range whens {
+		clause := i + 1
+		mb.targetColList = mb.targetColList[:0]
+		mb.targetColSet = opt.ColSet{}
+
+		switch when.Action {
+		case tree.MergeActionInsert:
+			scalarProps.Require("MERGE INSERT", tree.RejectSpecial)
+
+			// Compute the target columns, either explicitly specified by name or
+			// implicitly targeted by the VALUES list.
+			if len(when.Columns) != 0 {
+				mb.addTargetColsByName(when.Columns)
+				mb.checkNumCols(len(mb.targetColList), len(when.Values))
+			} else {
+				mb.addTargetTableColsForInsert(len(when.Values))
+			}
+			mb.checkPrimaryKeyForInsert()
+			mb.checkForeignKeysForInsert()
+
+			for j, expr := range when.Values {
+				targetCol := mb.tab.Column(mb.tabID.ColumnOrdinal(mb.targetColList[j]))
+				if _, ok := expr.(tree.DefaultVal); !ok && targetCol.IsGeneratedAlwaysAsIdentity() {
+					panic(sqlerrors.NewGeneratedAlwaysAsIdentityColumnOverrideError(
+						string(targetCol.ColName()),
+					))
+				}
+				addValue(insertVals, clause, sourceScope, expr, mb.targetColList[j])
+				insertClauses[mb.tabID.ColumnOrdinal(mb.targetColList[j])].Add(clause)
+			}
+			insertTargets.UnionWith(mb.targetColSet)
+
+		case tree.MergeActionUpdate:
+			scalarProps.Require("MERGE UPDATE SET", tree.RejectSpecial)
+
+			for _, set := range when.Exprs {
+				mb.addTargetColsByName(set.Names)
+
+				exprs := tree.Exprs{set.Expr}
+				if set.Tuple {
+					t, ok := set.Expr.(*tree.Tuple)
+					if !ok {
+						panic(unimplementedWithIssueDetailf(35713, fmt.Sprintf("%T", set.Expr),
+							"source for a multiple-column UPDATE item in MERGE must be a ROW() expression; not supported: %T", set.Expr))
+					}
+					if len(set.Names) != len(t.Exprs) {
+						panic(pgerror.Newf(pgcode.Syntax,
+							"number of columns (%d) does not match number of values (%d)",
+							len(set.Names), len(t.Exprs)))
+					}
+					exprs = t.Exprs
+				}
+
+				targetIdx := len(mb.targetColList) - len(exprs)
+				for j, expr := range exprs {
+					targetColID := mb.targetColList[targetIdx+j]
+					targetCol := mb.tab.Column(mb.tabID.ColumnOrdinal(targetColID))
+					if _, ok := expr.(tree.DefaultVal); !ok && targetCol.IsGeneratedAlwaysAsIdentity() {
+						panic(sqlerrors.NewGeneratedAlwaysAsIdentityColumnUpdateError(
+							string(targetCol.ColName()),
+						))
+					}
+					addValue(updateVals, clause, mergeScope, expr, targetColID)
+				}
+			}
+			updateTargets.UnionWith(mb.targetColSet)
+		}
+	}
+
+	// Every INSERT action must provide a value for each of the insert columns,
+	// so add DEFAULT values for the columns that an action does not target.
+	for i, when := range whens {
+		if when.Action != tree.MergeActionInsert {
+			continue
+		}
+		for ord := range insertVals {
+			if insertVals[ord] != nil && !insertClauses[ord].Contains(i+1) {
+				insertVals[ord] = append(insertVals[ord], mb.b.factory.ConstructWhen(
+					mb.makeMergeInt(i+1), mb.buildMergeValue(sourceScope, tree.DefaultVal{}, ord),
+				))
+			}
+		}
+	}
+
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	clauseCol := mb.b.factory.ConstructVariable(mb.mergeClauseColID)
+	for ord := 0; ord < n; ord++ {
+		tabCol := mb.tab.Column(ord)
+		if whens := insertVals[ord]; whens != nil {
+			scopeCol := mb.b.synthesizeColumn(
+				projectionsScope,
+				scopeColName("").WithMetadataName(string(tabCol.ColName())+"_ins"),
+				tabCol.DatumType(),
+				nil, /* expr */
+				mb.b.factory.ConstructCase(
+					clauseCol, whens, mb.b.factory.ConstructNull(tabCol.DatumType()),
+				),
+			)
+			mb.insertColIDs[ord] = scopeCol.id
+		}
+		if whens := updateVals[ord]; whens != nil {
+			scopeCol := mb.b.synthesizeColumn(
+				projectionsScope,
+				scopeColName("").WithMetadataName(string(tabCol.ColName())+"_new"),
+				tabCol.DatumType(),
+				nil, /* expr */
+				mb.b.factory.ConstructCase(
+					clauseCol, whens, mb.b.factory.ConstructVariable(mb.fetchColIDs[ord]),
+				),
+			)
+			mb.updateColIDs[ord] = scopeCol.id
+		}
+	}
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// The target columns are used by partial index and unique constraint
+	// checks to determine which columns are mutated.
+	mb.targetColList = mb.targetColList[:0]
+	mb.targetColSet = insertTargets.Union(updateTargets)
+	mb.targetColSet.ForEach(func(col opt.ColumnID) {
+		mb.targetColList = append(mb.targetColList, col)
+	})
+}
+
+// buildMergeValue builds the given INSERT value or SET expression of a MERGE
+// statement for the table column with the given ordinal. DEFAULT is replaced
+// with the column's default expression. The result is cast to the type of the
+// column.
+func (mb *mutationBuilder) buildMergeValue(inScope *scope, expr tree.Expr, ord int) opt.ScalarExpr {
+	targetCol := mb.tab.Column(ord)
+	if _, ok := expr.(tree.DefaultVal); ok {
+		expr = mb.parseDefaultExpr(mb.tabID.ColumnID(ord))
+	}
+
+	texpr := inScope.resolveType(expr, targetCol.DatumType())
+	scalar := mb.b.buildScalar(texpr, inScope, nil, nil, nil)
+
+	// An assignment cast is not necessary if the source and target types are
+	// identical.
+	srcType := scalar.DataType()
+	targetType := targetCol.DatumType()
+	if srcType.Identical(targetType) {
+		return scalar
+	}
+	if srcType.Family() == types.UnknownFamily {
+		return mb.b.factory.ConstructNull(targetType)
+	}
+	if !cast.ValidCast(srcType, targetType, cast.ContextAssignment) {
+		panic(sqlerrors.NewInvalidAssignmentCastError(srcType, targetType, string(targetCol.ColName())))
+	}
+	return mb.b.factory.ConstructAssignmentCast(scalar, targetType)
+}
+
+// setMergeColNames names the columns in the given list after the table columns
+// to which they correspond, and clears the names of all other columns in
+// mb.outScope. If includeFetchCols is true, fetch columns for which the list
+// has no value are named as well. This allows default and computed column
+// expressions to be built for inserted rows and for updated rows, despite
+// their values living side by side in the input of the Merge operator.
+func (mb *mutationBuilder) setMergeColNames(colIDs opt.OptionalColList, includeFetchCols bool) {
+	for i := range mb.outScope.cols {
+		mb.outScope.cols[i].clearName()
+	}
+	for ord, colID := range colIDs {
+		if colID == 0 && includeFetchCols {
+			colID = mb.fetchColIDs[ord]
+		}
+		if colID == 0 {
+			continue
+		}
+		if col := mb.outScope.getColumn(colID); col != nil {
+			col.name = scopeColName(mb.tab.Column(ord).ColName())
+		}
+	}
+}
+
+// projectMergeDeleteCols projects the old values of the rows deleted by a
+// MERGE statement for the columns referenced by inbound foreign keys:
+//
+//	CASE WHEN merge_action = <delete> THEN fetch_col END
+//
+// The values are NULL for rows that are not deleted.
+func (mb *mutationBuilder) projectMergeDeleteCols() {
+	if mb.tab.InboundForeignKeyCount() == 0 {
+		return
+	}
+
+	mb.deleteColIDs = make(opt.OptionalColList, mb.tab.ColumnCount())
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	isDelete := mb.b.factory.ConstructEq(
+		mb.b.factory.ConstructVariable(mb.mergeActionColID),
+		mb.makeMergeInt(int(tree.MergeActionDelete)),
+	)
+	for i, n := 0, mb.tab.InboundForeignKeyCount(); i < n; i++ {
+		fk := mb.tab.InboundForeignKey(i)
+		for j, m := 0, fk.ColumnCount(); j < m; j++ {
+			ord := fk.ReferencedColumnOrdinal(mb.tab, j)
+			if mb.deleteColIDs[ord] != 0 {
+				continue
+			}
+			tabCol := mb.tab.Column(ord)
+			scopeCol := mb.b.synthesizeColumn(
+				projectionsScope,
+				scopeColName("").WithMetadataName(string(tabCol.ColName())+"_del"),
+				tabCol.DatumType(),
+				nil, /* expr */
+				mb.b.factory.ConstructCase(
+					memo.TrueSingleton,
+					memo.ScalarListExpr{mb.b.factory.ConstructWhen(
+						isDelete, mb.b.factory.ConstructVariable(mb.fetchColIDs[ord]),
+					)},
+					mb.b.factory.ConstructNull(tabCol.DatumType()),
+				),
+			)
+			mb.deleteColIDs[ord] = scopeCol.id
+		}
+	}
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+}
+
+// buildMerge constructs a Merge operator, possibly wrapped by a Project
+// operator that corresponds to the given RETURNING clause.
+func (mb *mutationBuilder) buildMerge(returning *tree.ReturningExprs) {
+	// Merge input insert and update columns using CASE expressions.
+	mb.projectMergeColumns()
+
+	// Disambiguate names so that references in any expressions, such as a
+	// check constraint, refer to the correct columns.
+	mb.disambiguateColumns()
+
+	// Add any check constraint boolean columns to the input.
+	mb.addCheckConstraintCols(false /* isUpdate */)
+
+	// Add the partial index predicate expressions to the table metadata.
+	// These expressions are used to prune fetch columns during
+	// normalization.
+	mb.b.addPartialIndexPredicatesForTable(mb.md.TableMeta(mb.tabID), nil /* scan */)
+
+	// Project partial index PUT and DEL boolean columns.
+	mb.projectPartialIndexPutAndDelCols()
+
+	mb.buildUniqueChecksForUpsert()
+
+	mb.buildFKChecksForMerge()
+
+	private := mb.makeMutationPrivate(returning != nil)
+	private.MergeActionCol = mb.mergeActionColID
+	for _, col := range mb.extraAccessibleCols {
+		if col.id != 0 {
+			private.PassthroughCols = append(private.PassthroughCols, col.id)
+		}
+	}
+	mb.outScope.expr = mb.b.factory.ConstructMerge(
+		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
+	)
+
+	mb.buildReturning(returning)
+}
+
+// projectMergeColumns projects a set of merged columns that hold the final
+// values of the rows inserted, updated or deleted by a MERGE statement, similar
+// to projectUpsertColumns:
+//
+//	CASE merge_action
+//	  WHEN <insert> THEN ins_col
+//	  WHEN <delete> THEN fetch_col
+//	  ELSE upd_col
+//	END
+//
+// Deleted rows keep their fetched values so that they neither fail nor satisfy
+// constraint checks on the new values.
+func (mb *mutationBuilder) projectMergeColumns() {
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	actionCol := mb.b.factory.ConstructVariable(mb.mergeActionColID)
+
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		col := mb.tab.Column(i)
+		// Skip system columns.
+		if col.Kind() == cat.System {
+			continue
+		}
+
+		insertColID := mb.insertColIDs[i]
+		fetchColID := mb.fetchColIDs[i]
+		updateColID := mb.updateColIDs[i]
+		if updateColID == 0 {
+			updateColID = fetchColID
+		}
+
+		// Skip columns that will only be updated.
+		if insertColID == 0 || updateColID == 0 {
+			continue
+		}
+
+		whens := memo.ScalarListExpr{mb.b.factory.ConstructWhen(
+			mb.makeMergeInt(int(tree.MergeActionInsert)),
+			mb.b.factory.ConstructVariable(insertColID),
+		)}
+		if updateColID != fetchColID {
+			whens = append(whens, mb.b.factory.ConstructWhen(
+				mb.makeMergeInt(int(tree.MergeActionDelete)),
+				mb.b.factory.ConstructVariable(fetchColID),
+			))
+		}
+		caseExpr := mb.b.factory.ConstructCase(
+			actionCol, whens, mb.b.factory.ConstructVariable(updateColID),
+		)
+
+		name := scopeColName(col.ColName()).WithMetadataName(
+			fmt.Sprintf("merge_%s", col.ColName()),
+		)
+		typ := mb.md.ColumnMeta(insertColID).Type
+		scopeCol := mb.b.synthesizeColumn(projectionsScope, name, typ, nil /* expr */, caseExpr)
+		mb.upsertColIDs[i] = scopeCol.id
+	}
+
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+}
@@ -138,6 +138,20 @@ type mutationBuilder struct {
 	// an insert; otherwise it's an update.
 	canaryColID opt.ColumnID
 
+	// mergeClauseColID is the ID of the column that holds the 1-based ordinal
+	// of the WHEN clause of a MERGE statement that applies to each row.
+	mergeClauseColID opt.ColumnID
+
+	// mergeActionColID is the ID of the column that holds the action that a
+	// MERGE statement takes for each row (see tree.MergeActionType).
+	mergeActionColID opt.ColumnID
+
+	// deleteColIDs lists the input column IDs storing the old values of rows
+	// deleted by a MERGE statement, or NULL for rows that are not deleted. It is
+	// only populated for columns referenced by inbound foreign keys, and is
+	// empty if this is not a Merge operator with a DELETE action.
+	deleteColIDs opt.OptionalColList
+
 	// arbiters is the set of indexes and unique constraints that are used to
 	// detect conflicts for UPSERT and INSERT ON CONFLICT statements.
 	arbiters arbiterSet
@@ -1353,6 +1367,7 @@ type checkInputScanType uint8
 const (
 	checkInputScanNewVals checkInputScanType = iota
 	checkInputScanFetchedVals
+	checkInputScanDeletedVals
 )
 
 // buildCheckInputScan constructs an expression that produces the new values of
@@ -1382,10 +1397,13 @@ func (mb *mutationBuilder) buildCheckInputScan(
 	outScope.cols = make([]scopeColumn, len(inputCols))
 
 	for i, tabOrd := range tabOrdinals {
-		if typ == checkInputScanNewVals {
+		switch typ {
+		case checkInputScanNewVals:
 			inputCols[i] = mb.mapToReturnColID(tabOrd)
-		} else {
+		case checkInputScanFetchedVals:
 			inputCols[i] = mb.fetchColIDs[tabOrd]
+		case checkInputScanDeletedVals:
+			inputCols[i] = mb.deleteColIDs[tabOrd]
 		}
 		if inputCols[i] == 0 {
 			panic(errors.AssertionFailedf("no value for check input column (tabOrd=%d)", tabOrd))
@@ -1406,9 +1424,10 @@ func (mb *mutationBuilder) buildCheckInputScan(
 
 		// If a table column is not nullable, NULLs cannot be inserted (the
 		// mutation will fail). So for the purposes of checks, we can treat
-		// these columns as not null.
+		// these columns as not null. This does not hold for deleted values,
+		// which are NULL for rows that are not deleted.
 		if mb.outScope.expr.Relational().NotNullCols.Contains(inputCols[i]) ||
-			!mb.tab.Column(tabOrd).IsNullable() {
+			(typ != checkInputScanDeletedVals && !mb.tab.Column(tabOrd).IsNullable()) {
 			notNullOutCols.Add(outCol)
 		}
 	}
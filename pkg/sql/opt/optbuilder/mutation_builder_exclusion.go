@@ -0,0 +1,180 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// buildExclusionChecks builds check queries which enforce the exclusion
+// constraints of the table. They are planned alongside the uniqueness checks,
+// and use the same UniqueChecksItem with the Exclusion flag set. If isUpdate is
+// true, checks are only built for the constraints whose columns are updated.
+func (mb *mutationBuilder) buildExclusionChecks(isUpdate bool) {
+	for i, n := 0, mb.tab.ExclusionConstraintCount(); i < n; i++ {
+		if isUpdate && !mb.exclusionColsUpdated(i) {
+			continue
+		}
+		mb.uniqueChecks = append(mb.uniqueChecks, mb.buildExclusionCheck(i))
+	}
+}
+
+// exclusionColsUpdated returns true if any of the columns of an exclusion
+// constraint, or any of the columns referenced by its predicate, are being
+// updated (according to updateColIDs).
+func (mb *mutationBuilder) exclusionColsUpdated(exclusionOrdinal int) bool {
+	ec := mb.tab.ExclusionConstraint(exclusionOrdinal)
+
+	for i, n := 0, ec.ColumnCount(); i < n; i++ {
+		if ord := ec.ColumnOrdinal(mb.tab, i); mb.updateColIDs[ord] != 0 {
+			return true
+		}
+	}
+
+	if pred := mb.parseExclusionConstraintPredicateExpr(exclusionOrdinal); pred != nil {
+		typedPred := mb.fetchScope.resolveAndRequireType(pred, types.Bool)
+
+		var predCols opt.ColSet
+		mb.b.buildScalar(typedPred, mb.fetchScope, nil, nil, &predCols)
+		for colID, ok := predCols.Next(0); ok; colID, ok = predCols.Next(colID + 1) {
+			ord := mb.md.ColumnMeta(colID).Table.ColumnOrdinal(colID)
+			if mb.updateColIDs[ord] != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// parseExclusionConstraintPredicateExpr parses the predicate of the given
+// exclusion constraint, or returns nil if the constraint does not have one.
+func (mb *mutationBuilder) parseExclusionConstraintPredicateExpr(exclusionOrdinal int) tree.Expr {
+	predStr, isPartial := mb.tab.ExclusionConstraint(exclusionOrdinal).Predicate()
+	if !isPartial {
+		return nil
+	}
+	expr, err := parser.ParseExpr(predStr)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// buildExclusionCheck creates a check for rows which are added to or updated
+// in a table with an exclusion constraint. The check returns the new rows
+// which conflict with another row of the table, which includes the other new
+// rows since checks run after the mutation.
+//
+// The check is a semi-join of the new rows with the table:
+//
+//	SELECT new.a, new.b FROM new WHERE EXISTS (
+//	  SELECT * FROM t
+//	  WHERE new.a = t.a AND new.b && t.b AND (new.pk != t.pk)
+//	    AND new.a IS NOT NULL AND new.b IS NOT NULL
+//	)
+//
+// The && comparison can be index-accelerated with an inverted join into the
+// index backing the constraint. Rows with a NULL key column never conflict. The
+// IS NOT NULL filters make this explicit, since an inverted join matches NULL
+// values of the prefix columns of the index.
+func (mb *mutationBuilder) buildExclusionCheck(exclusionOrdinal int) memo.UniqueChecksItem {
+	f := mb.b.factory
+	ec := mb.tab.ExclusionConstraint(exclusionOrdinal)
+
+	h := uniqueCheckHelper{mb: mb}
+	scanScope, scanOrdinals := h.buildTableScan()
+	withScanScope, _ := mb.buildCheckInputScan(
+		checkInputScanNewVals, scanOrdinals, false, /* isFK */
+	)
+
+	// Build the join filters:
+	//   (new_a = existing_a) AND (new_b && existing_b) AND ...
+	//   AND (new_a IS NOT NULL) AND (new_b IS NOT NULL) AND ...
+	//
+	// Add 1 to the capacity for the filter which prevents rows from matching
+	// themselves, and 2 for the predicate on both sides, if any.
+	semiJoinFilters := make(memo.FiltersExpr, 0, 2*ec.ColumnCount()+3)
+	keyCols := make(opt.ColList, ec.ColumnCount())
+	for i, n := 0, ec.ColumnCount(); i < n; i++ {
+		ord := ec.ColumnOrdinal(mb.tab, i)
+		newVal := f.ConstructVariable(withScanScope.cols[ord].id)
+		existingVal := f.ConstructVariable(scanScope.cols[ord].id)
+		var cmp opt.ScalarExpr
+		switch ec.Operator(i) {
+		case treecmp.EQ:
+			cmp = f.ConstructEq(newVal, existingVal)
+		case treecmp.Overlaps:
+			// The && operator means "intersects" when used with geometry operands.
+			if mb.tab.Column(ord).DatumType().Family() == types.GeometryFamily {
+				cmp = f.ConstructBBoxIntersects(newVal, existingVal)
+			} else {
+				cmp = f.ConstructOverlaps(newVal, existingVal)
+			}
+		default:
+			panic(errors.AssertionFailedf("unexpected exclusion operator %s", ec.Operator(i)))
+		}
+		semiJoinFilters = append(semiJoinFilters,
+			f.ConstructFiltersItem(cmp),
+			f.ConstructFiltersItem(f.ConstructIsNot(newVal, memo.NullSingleton)),
+		)
+		keyCols[i] = withScanScope.cols[ord].id
+	}
+
+	// If the exclusion constraint is partial, only the rows which satisfy the
+	// predicate can conflict.
+	if pred := mb.parseExclusionConstraintPredicateExpr(exclusionOrdinal); pred != nil {
+		typedPred := withScanScope.resolveAndRequireType(pred, types.Bool)
+		withScanPred := mb.b.buildScalar(typedPred, withScanScope, nil, nil, nil)
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(withScanPred))
+
+		typedPred = scanScope.resolveAndRequireType(pred, types.Bool)
+		scanPred := mb.b.buildScalar(typedPred, scanScope, nil, nil, nil)
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(scanPred))
+	}
+
+	// Prevent rows from matching themselves in the semi join:
+	//    (new_pk1 != existing_pk1) OR (new_pk2 != existing_pk2) OR ...
+	var pkFilter opt.ScalarExpr
+	primaryOrds := getIndexLaxKeyOrdinals(mb.tab.Index(cat.PrimaryIndex))
+	for i, ok := primaryOrds.Next(0); ok; i, ok = primaryOrds.Next(i + 1) {
+		pkFilterLocal := f.ConstructNe(
+			f.ConstructVariable(withScanScope.cols[i].id),
+			f.ConstructVariable(scanScope.cols[i].id),
+		)
+		if pkFilter == nil {
+			pkFilter = pkFilterLocal
+		} else {
+			pkFilter = f.ConstructOr(pkFilter, pkFilterLocal)
+		}
+	}
+	semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(pkFilter))
+
+	semiJoin := f.ConstructSemiJoin(withScanScope.expr, scanScope.expr, semiJoinFilters, memo.EmptyJoinPrivate)
+
+	// Pass through only the key columns, which are shown in the error message.
+	project := f.ConstructProject(semiJoin, nil /* projections */, keyCols.ToSet())
+
+	return f.ConstructUniqueChecksItem(project, &memo.UniqueChecksItemPrivate{
+		Table:        mb.tabID,
+		CheckOrdinal: exclusionOrdinal,
+		KeyCols:      keyCols,
+		OpName:       mb.opName,
+		Exclusion:    true,
+	})
+}
@@ -454,6 +454,63 @@ func (mb *mutationBuilder) buildFKChecksForUpsert() {
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
 
+// buildFKChecksForMerge builds FK check queries and cascades for a merge.
+//
+// See the comment at the top of the file for general information on checks and
+// cascades.
+//
+// Inserted and updated rows are handled exactly as they are for an upsert (see
+// buildFKChecksForUpsert). Deleted rows have their existing values removed, so
+// they are handled like a delete (see buildFKChecksAndCascadesForDelete),
+// except that the "old" values are the delete columns, which are NULL for the
+// rows that are not deleted and therefore never match any child row.
+func (mb *mutationBuilder) buildFKChecksForMerge() {
+	mb.buildFKChecksForUpsert()
+
+	if mb.deleteColIDs == nil {
+		// No DELETE actions, or no inbound FKs.
+		return
+	}
+
+	h := &mb.fkCheckHelper
+	for i, n := 0, mb.tab.InboundForeignKeyCount(); i < n; i++ {
+		if !h.initWithInboundFK(mb, i) {
+			continue
+		}
+
+		if a := h.fk.DeleteReferenceAction(); a != tree.Restrict && a != tree.NoAction {
+			telemetry.Inc(sqltelemetry.ForeignKeyCascadesUseCounter)
+			mb.ensureWithID()
+			var builder memo.CascadeBuilder
+			switch a {
+			case tree.Cascade:
+				builder = newOnDeleteCascadeBuilder(mb.tab, i, h.otherTab)
+			case tree.SetNull, tree.SetDefault:
+				builder = newOnDeleteSetBuilder(mb.tab, i, h.otherTab, a)
+			default:
+				panic(errors.AssertionFailedf("unhandled action type %s", a))
+			}
+
+			cols := make(opt.ColList, len(h.tabOrdinals))
+			for i, tabOrd := range h.tabOrdinals {
+				cols[i] = mb.deleteColIDs[tabOrd]
+			}
+			mb.cascades = append(mb.cascades, memo.FKCascade{
+				FKName:    h.fk.Name(),
+				Builder:   builder,
+				WithID:    mb.withID,
+				OldValues: cols,
+				NewValues: nil,
+			})
+			continue
+		}
+
+		withScanScope, _ := mb.buildCheckInputScan(checkInputScanDeletedVals, h.tabOrdinals, true /* isFK */)
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(withScanScope.expr, withScanScope.colList()))
+	}
+	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
+}
+
 // outboundFKColsUpdated returns true if any of the FK columns for an outbound
 // constraint are being updated (according to updateColIDs).
 func (mb *mutationBuilder) outboundFKColsUpdated(fkOrdinal int) bool {
@@ -612,13 +669,27 @@ func resolveTable(ctx context.Context, catalog cat.Catalog, id cat.StableID) cat
 }
 
 // buildOtherTableScan builds a Scan of the "other" table.
+//
+// Under isolation levels which tolerate write skew, the check reads from the
+// snapshot of the statement, so it could miss a concurrent insertion of a
+// child row or deletion of a parent row which commits before the mutation
+// does. To prevent this, the scan locks the rows it reads FOR SHARE. Locking
+// makes the check wait for the concurrent writer. If the writer commits, the
+// check observes a write which is newer than its snapshot, and the statement
+// is retried on a new snapshot. Every such race conflicts on at least one key:
+// the child row written by the insertion, or the parent row written by the
+// deletion.
 func (h *fkCheckHelper) buildOtherTableScan() (outScope *scope, tabMeta *opt.TableMeta) {
 	otherTabMeta := h.mb.b.addTable(h.otherTab, tree.NewUnqualifiedTableName(h.otherTab.Name()))
+	locking := noRowLocking
+	if h.mb.b.evalCtx.TxnIsoLevel.ToleratesWriteSkew() {
+		locking = lockingSpec{&tree.LockingItem{Strength: tree.ForShare}}
+	}
 	return h.mb.b.buildScan(
 		otherTabMeta,
 		h.otherTabOrdinals,
 		&tree.IndexFlags{IgnoreForeignKeys: true},
-		noRowLocking,
+		locking,
 		h.mb.b.allocScope(),
 		true, /* disableNotVisibleIndex */
 	), otherTabMeta
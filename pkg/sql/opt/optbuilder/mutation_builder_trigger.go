@@ -0,0 +1,684 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains methods that build the invocations of triggers defined on
+// the mutated table.
+//
+// -- BEFORE ROW triggers --
+//
+// BEFORE ROW triggers are built as projections on top of the mutation input.
+// The trigger function is invoked with the NEW and OLD rows, and returns the
+// row that should be used for the mutation instead of NEW. If the function
+// returns NULL, the row is skipped. Triggers are invoked in order of their
+// names, and the row returned by each trigger is passed to the next one.
+//
+// -- AFTER ROW and AFTER STATEMENT triggers --
+//
+// AFTER triggers are built as "cascades" which run after the mutation (and
+// after any FK cascades) completes; see afterTriggerBuilder. AFTER ROW
+// triggers read the NEW and OLD rows from the buffered mutation input, while
+// AFTER STATEMENT triggers run exactly once, even if no rows were modified.
+//
+// -- BEFORE STATEMENT triggers --
+//
+// BEFORE STATEMENT triggers are built as materialized With expressions which
+// wrap the mutation. The binding of the With is executed before the main
+// query.
+
+// triggerRow describes the row passed to trigger functions as NEW and OLD.
+// It contains the visible, ordinary columns of the table, in order.
+type triggerRow struct {
+	ords  []int
+	names []tree.Name
+	typ   *types.T
+}
+
+// makeTriggerRow returns the triggerRow for the given table.
+func makeTriggerRow(tab cat.Table) triggerRow {
+	var r triggerRow
+	var contents []*types.T
+	var labels []string
+	for i, n := 0, tab.ColumnCount(); i < n; i++ {
+		col := tab.Column(i)
+		if col.Kind() != cat.Ordinary || col.Visibility() != cat.Visible {
+			continue
+		}
+		r.ords = append(r.ords, i)
+		r.names = append(r.names, col.ColName())
+		contents = append(contents, col.DatumType())
+		labels = append(labels, string(col.ColName()))
+	}
+	r.typ = types.MakeLabeledTuple(contents, labels)
+	return r
+}
+
+// findTriggers returns the triggers on the table which fire with the given
+// action time and level for the given event, in the order in which they must
+// be invoked.
+func (mb *mutationBuilder) findTriggers(
+	actionTime tree.TriggerActionTime, forEachRow bool, event tree.TriggerEventType,
+) []cat.Trigger {
+	var triggers []cat.Trigger
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trigger := mb.tab.Trigger(i)
+		if trigger.ActionTime != actionTime || trigger.ForEachRow != forEachRow {
+			continue
+		}
+		if !mb.triggerFiresForEvent(&trigger, event) {
+			continue
+		}
+		triggers = append(triggers, trigger)
+	}
+	if len(triggers) > 0 {
+		// Triggers cannot be invoked from cached memos, because the trigger
+		// functions may have been changed since the memo was built.
+		mb.b.DisableMemoReuse = true
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		return triggers[i].Name < triggers[j].Name
+	})
+	return triggers
+}
+
+// triggerFiresForEvent returns true if the given trigger fires for the given
+// event. UPDATE OF triggers only fire if one of their columns is a target of
+// the UPDATE statement.
+func (mb *mutationBuilder) triggerFiresForEvent(
+	trigger *cat.Trigger, event tree.TriggerEventType,
+) bool {
+	for i := range trigger.Events {
+		ev := &trigger.Events[i]
+		if ev.EventType != event {
+			continue
+		}
+		if len(ev.ColumnOrdinals) == 0 {
+			return true
+		}
+		for _, ord := range ev.ColumnOrdinals {
+			if mb.targetColSet.Contains(mb.tabID.ColumnID(ord)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasTriggers returns true if the table has any triggers for one of the given
+// events.
+func (mb *mutationBuilder) hasTriggers(events ...tree.TriggerEventType) bool {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trigger := mb.tab.Trigger(i)
+		for _, ev := range events {
+			if trigger.HasEvent(ev) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildBeforeRowTriggers builds the invocations of the BEFORE ROW triggers
+// for the given event. For INSERT and UPDATE, the new values of the row are
+// replaced with the row returned by the trigger functions. Changes made by
+// the triggers to computed columns are ignored.
+//
+// See the comment at the top of the file for more details.
+func (mb *mutationBuilder) buildBeforeRowTriggers(event tree.TriggerEventType) {
+	triggers := mb.findTriggers(tree.TriggerActionTimeBefore, true /* forEachRow */, event)
+	if len(triggers) == 0 {
+		return
+	}
+	row := makeTriggerRow(mb.tab)
+
+	// Determine the columns which hold the NEW and OLD values of the row.
+	var newCols, oldCols opt.ColList
+	switch event {
+	case tree.TriggerEventInsert:
+		newCols = mb.triggerRowCols(&row, mb.insertColIDs, nil /* fallback */)
+	case tree.TriggerEventUpdate:
+		newCols = mb.triggerRowCols(&row, mb.updateColIDs, mb.fetchColIDs)
+		oldCols = mb.triggerRowCols(&row, mb.fetchColIDs, nil /* fallback */)
+	case tree.TriggerEventDelete:
+		oldCols = mb.triggerRowCols(&row, mb.fetchColIDs, nil /* fallback */)
+	}
+
+	f := mb.b.factory
+	for i := range triggers {
+		trigger := &triggers[i]
+		newRow := makeTriggerRowTuple(f, &row, newCols)
+		oldRow := makeTriggerRowTuple(f, &row, oldCols)
+		result := mb.b.buildTriggerFunction(trigger, &row, newRow, oldRow, true /* returnsRow */)
+
+		// If the trigger has a WHEN condition and it is not satisfied, the
+		// row is passed through unchanged.
+		if trigger.WhenExpr != "" {
+			cond := mb.b.buildTriggerWhen(trigger, &row, newCols, oldCols)
+			passthrough := newRow
+			if event == tree.TriggerEventDelete {
+				passthrough = oldRow
+			}
+			result = f.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{f.ConstructWhen(cond, result)},
+				passthrough,
+			)
+		}
+
+		projectionsScope := mb.outScope.replace()
+		projectionsScope.appendColumnsFromScope(mb.outScope)
+		resultCol := mb.b.synthesizeColumn(
+			projectionsScope, scopeColName("").WithMetadataName(string(trigger.Name)),
+			row.typ, nil /* expr */, result,
+		)
+		resultColID := resultCol.id
+		mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+		mb.outScope = projectionsScope
+
+		// Rows for which the trigger function returns NULL are skipped. Note
+		// that the Is and IsNot operators do not use the SQL semantics for NULL
+		// tuples, so a row with all NULL values is not skipped.
+		mb.outScope.expr = f.ConstructSelect(
+			mb.outScope.expr,
+			memo.FiltersExpr{f.ConstructFiltersItem(
+				f.ConstructIsNot(f.ConstructVariable(resultColID), memo.NullSingleton),
+			)},
+		)
+		if event == tree.TriggerEventDelete {
+			continue
+		}
+
+		// Extract the values of the new row returned by the trigger.
+		projectionsScope = mb.outScope.replace()
+		projectionsScope.appendColumnsFromScope(mb.outScope)
+		for j, ord := range row.ords {
+			if mb.tab.Column(ord).IsComputed() {
+				continue
+			}
+			access := f.ConstructColumnAccess(f.ConstructVariable(resultColID), memo.TupleOrdinal(j))
+			col := mb.b.synthesizeColumn(
+				projectionsScope,
+				scopeColName("").WithMetadataName(string(row.names[j])+"_"+string(trigger.Name)),
+				row.typ.TupleContents()[j], nil /* expr */, access,
+			)
+			newCols[j] = col.id
+		}
+		mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+		mb.outScope = projectionsScope
+	}
+	if event == tree.TriggerEventDelete {
+		return
+	}
+
+	// Use the values returned by the triggers as the new values of the row.
+	// The columns are named after the table columns so that they can be
+	// referenced by computed columns and check constraints.
+	colIDs := mb.insertColIDs
+	if event == tree.TriggerEventUpdate {
+		colIDs = mb.updateColIDs
+	}
+	for j, ord := range row.ords {
+		if mb.tab.Column(ord).IsComputed() {
+			continue
+		}
+		colIDs[ord] = newCols[j]
+		for k := range mb.outScope.cols {
+			if mb.outScope.cols[k].id == newCols[j] {
+				mb.outScope.cols[k].name.refName = row.names[j]
+			}
+		}
+	}
+	mb.disambiguateColumns()
+}
+
+// triggerRowCols returns the columns holding the values of the trigger row.
+// For each column in the row, the column in colIDs is used, or the column in
+// fallback if that is not set. Columns which have neither are projected as
+// NULL values.
+func (mb *mutationBuilder) triggerRowCols(
+	row *triggerRow, colIDs, fallback opt.OptionalColList,
+) opt.ColList {
+	cols := make(opt.ColList, len(row.ords))
+	var projectionsScope *scope
+	for i, ord := range row.ords {
+		if colIDs[ord] != 0 {
+			cols[i] = colIDs[ord]
+			continue
+		}
+		if fallback != nil && fallback[ord] != 0 {
+			cols[i] = fallback[ord]
+			continue
+		}
+		if projectionsScope == nil {
+			projectionsScope = mb.outScope.replace()
+			projectionsScope.appendColumnsFromScope(mb.outScope)
+		}
+		typ := row.typ.TupleContents()[i]
+		col := mb.b.synthesizeColumn(
+			projectionsScope, scopeColName(""), typ, nil /* expr */, mb.b.factory.ConstructNull(typ),
+		)
+		cols[i] = col.id
+	}
+	if projectionsScope != nil {
+		mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+		mb.outScope = projectionsScope
+	}
+	return cols
+}
+
+// makeTriggerRowTuple returns a tuple of the given columns, or a NULL of the
+// row type if there are no columns.
+func makeTriggerRowTuple(f *norm.Factory, row *triggerRow, cols opt.ColList) opt.ScalarExpr {
+	if len(cols) == 0 {
+		return f.ConstructNull(row.typ)
+	}
+	elems := make(memo.ScalarListExpr, len(cols))
+	for i := range cols {
+		elems[i] = f.ConstructVariable(cols[i])
+	}
+	return f.ConstructTuple(elems, row.typ)
+}
+
+// buildAfterTriggers adds the AFTER ROW and AFTER STATEMENT triggers for the
+// given event to mb.cascades. The AFTER ROW triggers read the given OLD and
+// NEW columns (indexed by table column ordinal) from the mutation input.
+//
+// See the comment at the top of the file for more details.
+func (mb *mutationBuilder) buildAfterTriggers(
+	event tree.TriggerEventType, oldColIDs, newColIDs opt.OptionalColList,
+) {
+	rowTriggers := mb.findTriggers(tree.TriggerActionTimeAfter, true /* forEachRow */, event)
+	stmtTriggers := mb.findTriggers(tree.TriggerActionTimeAfter, false /* forEachRow */, event)
+	if len(rowTriggers) == 0 && len(stmtTriggers) == 0 {
+		return
+	}
+	row := makeTriggerRow(mb.tab)
+	var oldValues, newValues opt.ColList
+	if oldColIDs != nil {
+		oldValues = make(opt.ColList, len(row.ords))
+		for i, ord := range row.ords {
+			oldValues[i] = oldColIDs[ord]
+		}
+	}
+	if newColIDs != nil {
+		newValues = make(opt.ColList, len(row.ords))
+		for i, ord := range row.ords {
+			newValues[i] = newColIDs[ord]
+			if newValues[i] == 0 && oldColIDs != nil {
+				// The column was not updated.
+				newValues[i] = oldColIDs[ord]
+			}
+		}
+	}
+
+	for i := range rowTriggers {
+		mb.ensureWithID()
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:    string(rowTriggers[i].Name),
+			Builder:   newAfterTriggerBuilder(mb.tab, rowTriggers[i]),
+			WithID:    mb.withID,
+			OldValues: oldValues,
+			NewValues: newValues,
+		})
+	}
+	for i := range stmtTriggers {
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:  string(stmtTriggers[i].Name),
+			Builder: newAfterTriggerBuilder(mb.tab, stmtTriggers[i]),
+		})
+	}
+}
+
+// buildBeforeStatementTriggers wraps the mutation in With expressions that
+// invoke the BEFORE STATEMENT triggers for the given event. It must be called
+// after the mutation has been built.
+//
+// See the comment at the top of the file for more details.
+func (mb *mutationBuilder) buildBeforeStatementTriggers(event tree.TriggerEventType) {
+	triggers := mb.findTriggers(tree.TriggerActionTimeBefore, false /* forEachRow */, event)
+	if len(triggers) == 0 {
+		return
+	}
+	if mb.b.insideNestedStmt {
+		panic(unimplemented.NewWithIssuef(28296,
+			"BEFORE STATEMENT trigger %q cannot be invoked by a cascading mutation "+
+				"or from a trigger function", triggers[0].Name))
+	}
+	row := makeTriggerRow(mb.tab)
+
+	// Wrap the mutation in reverse order, so that the first trigger is
+	// invoked first.
+	f := mb.b.factory
+	md := f.Metadata()
+	for i := len(triggers) - 1; i >= 0; i-- {
+		trigger := &triggers[i]
+		binding := mb.b.buildStatementTrigger(trigger, &row)
+		id := f.Memo().NextWithID()
+		md.AddWithBinding(id, binding)
+		mb.outScope.expr = f.ConstructWith(binding, mb.outScope.expr, &memo.WithPrivate{
+			ID:           id,
+			OriginalExpr: mb.b.triggerOriginalExpr(trigger),
+			Mtr:          tree.CTEMaterializeAlways,
+			Name:         string(trigger.Name),
+		})
+	}
+}
+
+// triggerOriginalExpr returns a statement which is displayed in EXPLAIN output
+// in place of a statement-level trigger invocation.
+func (b *Builder) triggerOriginalExpr(trigger *cat.Trigger) *tree.Select {
+	name, _, err := b.catalog.ResolveFunctionByOID(b.ctx, trigger.FuncOID)
+	if err != nil {
+		panic(err)
+	}
+	fn := tree.MakeUnresolvedName(name.Object())
+	return &tree.Select{Select: &tree.SelectClause{
+		Exprs: tree.SelectExprs{{Expr: &tree.FuncExpr{
+			Func: tree.ResolvableFunctionReference{FunctionReference: &fn},
+		}}},
+	}}
+}
+
+// buildStatementTrigger builds an expression which invokes the given
+// statement-level trigger once, if its WHEN condition is satisfied.
+func (b *Builder) buildStatementTrigger(trigger *cat.Trigger, row *triggerRow) memo.RelExpr {
+	f := b.factory
+	var input memo.RelExpr = f.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+		Cols: opt.ColList{},
+		ID:   f.Metadata().NextUniqueID(),
+	})
+	if trigger.WhenExpr != "" {
+		cond := b.buildTriggerWhen(trigger, row, nil /* newCols */, nil /* oldCols */)
+		input = f.ConstructSelect(input, memo.FiltersExpr{f.ConstructFiltersItem(cond)})
+	}
+	null := f.ConstructNull(row.typ)
+	udf := b.buildTriggerFunction(trigger, row, null, null, false /* returnsRow */)
+	outScope := b.allocScope()
+	b.synthesizeColumn(
+		outScope, scopeColName("").WithMetadataName(string(trigger.Name)), row.typ, nil /* expr */, udf,
+	)
+	return b.constructProject(input, outScope.cols)
+}
+
+// buildTriggerFunction builds an invocation of the function of the given
+// trigger, with the given NEW and OLD rows as arguments. If returnsRow is
+// true, the function returns the row produced by the last statement of the
+// function body, or NULL if the statement produces no rows. Otherwise, the
+// function always returns NULL.
+func (b *Builder) buildTriggerFunction(
+	trigger *cat.Trigger, row *triggerRow, newRow, oldRow opt.ScalarExpr, returnsRow bool,
+) opt.ScalarExpr {
+	name, o, err := b.catalog.ResolveFunctionByOID(b.ctx, trigger.FuncOID)
+	if err != nil {
+		panic(err)
+	}
+	for _, id := range b.triggerFuncStack {
+		if id == trigger.FuncOID {
+			panic(unimplemented.NewWithIssuef(28296,
+				"trigger %q cannot recursively invoke trigger function %s", trigger.Name, name))
+		}
+	}
+	b.triggerFuncStack = append(b.triggerFuncStack, trigger.FuncOID)
+	defer func() {
+		b.triggerFuncStack = b.triggerFuncStack[:len(b.triggerFuncStack)-1]
+	}()
+	prevInsideNestedStmt := b.insideNestedStmt
+	b.insideNestedStmt = true
+	defer func() { b.insideNestedStmt = prevInsideNestedStmt }()
+
+	// The function body can refer to the NEW and OLD rows as parameters.
+	bodyScope := b.allocScope()
+	params := make(opt.ColList, 2)
+	for i, paramName := range []tree.Name{schemaexpr.TriggerNewName, schemaexpr.TriggerOldName} {
+		col := b.synthesizeColumn(bodyScope, funcParamColName(paramName, i), row.typ, nil /* expr */, nil /* scalar */)
+		col.setParamOrd(i)
+		params[i] = col.id
+	}
+
+	stmts, err := parser.Parse(o.Body)
+	if err != nil {
+		panic(err)
+	}
+	f := b.factory
+	rels := make(memo.RelListExpr, len(stmts))
+	for i := range stmts {
+		stmtScope := b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
+		expr := stmtScope.expr
+		physProps := stmtScope.makePhysicalProps()
+
+		// The last statement produces the output of the function.
+		if i == len(stmts)-1 {
+			if !expr.Relational().CanMutate {
+				b.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, b.allocScope(), stmtScope)
+				expr = stmtScope.expr
+				physProps.Ordering = props.OrderingChoice{}
+			}
+			result := b.buildTriggerFunctionResult(trigger, row, physProps.Presentation, returnsRow)
+			stmtScope = bodyScope.push()
+			b.synthesizeColumn(stmtScope, scopeColName(""), row.typ, nil /* expr */, result)
+			expr = b.constructProject(expr, stmtScope.cols)
+			physProps = stmtScope.makePhysicalProps()
+		}
+
+		rels[i] = memo.RelRequiredPropsExpr{
+			RelExpr:   expr,
+			PhysProps: physProps,
+		}
+	}
+
+	return f.ConstructUDF(
+		memo.ScalarListExpr{newRow, oldRow},
+		&memo.UDFPrivate{
+			Name:              name.Object(),
+			Params:            params,
+			Body:              rels,
+			Typ:               row.typ,
+			Volatility:        o.Volatility,
+			CalledOnNullInput: true,
+		},
+	)
+}
+
+// buildTriggerFunctionResult builds the scalar expression which converts the
+// presentation columns of the last statement of a trigger function into the
+// row type of the table.
+func (b *Builder) buildTriggerFunctionResult(
+	trigger *cat.Trigger, row *triggerRow, cols physical.Presentation, returnsRow bool,
+) opt.ScalarExpr {
+	f := b.factory
+	md := f.Metadata()
+	null := f.ConstructNull(row.typ)
+	if !returnsRow || len(cols) == 0 {
+		return null
+	}
+
+	numCols := len(row.ords)
+	elems := make(memo.ScalarListExpr, numCols)
+	var srcTypes []*types.T
+	var isNull opt.ScalarExpr
+	firstColType := md.ColumnMeta(cols[0].ID).Type
+	switch {
+	case len(cols) == 1 && firstColType.Family() == types.UnknownFamily:
+		// The statement returns NULL, e.g. SELECT NULL.
+		return null
+
+	case len(cols) == 1 && firstColType.Family() == types.TupleFamily &&
+		len(firstColType.TupleContents()) == numCols:
+		// The statement returns a single tuple, e.g. SELECT NEW.
+		srcTypes = firstColType.TupleContents()
+		for i := range elems {
+			elems[i] = f.ConstructColumnAccess(f.ConstructVariable(cols[0].ID), memo.TupleOrdinal(i))
+		}
+		isNull = f.ConstructIs(f.ConstructVariable(cols[0].ID), memo.NullSingleton)
+
+	case len(cols) == numCols:
+		// The statement returns one column for each column of the row, e.g.
+		// SELECT (NEW).*.
+		srcTypes = make([]*types.T, numCols)
+		for i := range elems {
+			srcTypes[i] = md.ColumnMeta(cols[i].ID).Type
+			elems[i] = f.ConstructVariable(cols[i].ID)
+		}
+
+	default:
+		panic(pgerror.Newf(pgcode.DatatypeMismatch,
+			"returned row structure does not match the structure of the triggering table"))
+	}
+
+	for i := range elems {
+		targetType := row.typ.TupleContents()[i]
+		if srcTypes[i].Identical(targetType) {
+			continue
+		}
+		if !cast.ValidCast(srcTypes[i], targetType, cast.ContextAssignment) {
+			panic(sqlerrors.NewInvalidAssignmentCastError(srcTypes[i], targetType, string(row.names[i])))
+		}
+		elems[i] = f.ConstructAssignmentCast(elems[i], targetType)
+	}
+	result := f.ConstructTuple(elems, row.typ)
+	if isNull != nil {
+		result = f.ConstructCase(
+			memo.TrueSingleton,
+			memo.ScalarListExpr{f.ConstructWhen(isNull, null)},
+			result,
+		)
+	}
+	return result
+}
+
+// buildTriggerWhen builds the WHEN condition of the given trigger. References
+// to NEW and OLD columns are resolved to the given columns, which correspond
+// to the columns of the trigger row.
+func (b *Builder) buildTriggerWhen(
+	trigger *cat.Trigger, row *triggerRow, newCols, oldCols opt.ColList,
+) opt.ScalarExpr {
+	expr, err := parser.ParseExpr(trigger.WhenExpr)
+	if err != nil {
+		panic(err)
+	}
+	s := b.allocScope()
+	addCols := func(tabName tree.Name, cols opt.ColList) {
+		tn := tree.MakeUnqualifiedTableName(tabName)
+		for i := range cols {
+			s.cols = append(s.cols, scopeColumn{
+				name:  scopeColName(row.names[i]),
+				table: tn,
+				typ:   row.typ.TupleContents()[i],
+				id:    cols[i],
+			})
+		}
+	}
+	addCols(schemaexpr.TriggerNewName, newCols)
+	addCols(schemaexpr.TriggerOldName, oldCols)
+
+	texpr := s.resolveAndRequireType(expr, types.Bool)
+	return b.buildScalar(texpr, s, nil /* outScope */, nil /* outCol */, nil /* colRefs */)
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers. AFTER ROW triggers invoke the trigger function once for each row
+// in the mutation input, and AFTER STATEMENT triggers invoke it exactly once.
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	trigger      cat.Trigger
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+func newAfterTriggerBuilder(mutatedTable cat.Table, trigger cat.Trigger) *afterTriggerBuilder {
+	return &afterTriggerBuilder{
+		mutatedTable: mutatedTable,
+		trigger:      trigger,
+	}
+}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *eval.Context,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		row := makeTriggerRow(tb.mutatedTable)
+		if !tb.trigger.ForEachRow {
+			return b.buildStatementTrigger(&tb.trigger, &row)
+		}
+		if binding == 0 {
+			panic(errors.AssertionFailedf("AFTER ROW trigger %q requires input", tb.trigger.Name))
+		}
+
+		// Scan the OLD and NEW values from the buffered mutation input.
+		f := b.factory
+		md := f.Metadata()
+		inCols := make(opt.ColList, 0, len(oldValues)+len(newValues))
+		inCols = append(inCols, oldValues...)
+		inCols = append(inCols, newValues...)
+		outCols := make(opt.ColList, len(inCols))
+		for i := range inCols {
+			c := md.ColumnMeta(inCols[i])
+			outCols[i] = md.AddColumn(c.Alias, c.Type)
+		}
+		md.AddWithBinding(binding, f.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		var input memo.RelExpr = f.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+		oldCols, newCols := outCols[:len(oldValues)], outCols[len(oldValues):]
+
+		if tb.trigger.WhenExpr != "" {
+			cond := b.buildTriggerWhen(&tb.trigger, &row, newCols, oldCols)
+			input = f.ConstructSelect(input, memo.FiltersExpr{f.ConstructFiltersItem(cond)})
+		}
+		udf := b.buildTriggerFunction(
+			&tb.trigger, &row,
+			makeTriggerRowTuple(f, &row, newCols),
+			makeTriggerRowTuple(f, &row, oldCols),
+			false, /* returnsRow */
+		)
+		outScope := b.allocScope()
+		b.synthesizeColumn(
+			outScope, scopeColName("").WithMetadataName(string(tb.trigger.Name)), row.typ, nil /* expr */, udf,
+		)
+		return b.constructProject(input, outScope.cols)
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/intsets"
 )
 
@@ -33,8 +34,11 @@ var UniquenessChecksForGenRandomUUIDClusterMode = settings.RegisterBoolSetting(
 ).WithPublic()
 
 // buildUniqueChecksForInsert builds uniqueness check queries for an insert.
-// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints.
+// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints
+// and exclusion constraints.
 func (mb *mutationBuilder) buildUniqueChecksForInsert() {
+	mb.buildExclusionChecks(false /* isUpdate */)
+
 	// We only need to build unique checks if there is at least one unique
 	// constraint without an index.
 	if !mb.hasUniqueWithoutIndexConstraints() {
@@ -64,8 +68,11 @@ func (mb *mutationBuilder) buildUniqueChecksForInsert() {
 }
 
 // buildUniqueChecksForUpdate builds uniqueness check queries for an update.
-// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints.
+// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints
+// and exclusion constraints.
 func (mb *mutationBuilder) buildUniqueChecksForUpdate() {
+	mb.buildExclusionChecks(true /* isUpdate */)
+
 	// We only need to build unique checks if there is at least one unique
 	// constraint without an index.
 	if !mb.hasUniqueWithoutIndexConstraints() {
@@ -98,8 +105,11 @@ func (mb *mutationBuilder) buildUniqueChecksForUpdate() {
 }
 
 // buildUniqueChecksForUpsert builds uniqueness check queries for an upsert.
-// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints.
+// These check queries are used to enforce UNIQUE WITHOUT INDEX constraints
+// and exclusion constraints.
 func (mb *mutationBuilder) buildUniqueChecksForUpsert() {
+	mb.buildExclusionChecks(false /* isUpdate */)
+
 	// We only need to build unique checks if there is at least one unique
 	// constraint without an index.
 	if !mb.hasUniqueWithoutIndexConstraints() {
@@ -395,7 +405,19 @@ func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 
 // buildTableScan builds a Scan of the table. The ordinals of the columns
 // scanned are also returned.
+//
+// Uniqueness and exclusion checks are not supported under isolation levels
+// which tolerate write skew. The check reads from the snapshot of the
+// statement, so it could miss a conflicting row inserted by a concurrent
+// transaction. Unlike for foreign key checks, locking the rows read by the
+// check does not help, since the conflicting row does not exist yet.
 func (h *uniqueCheckHelper) buildTableScan() (outScope *scope, ordinals []int) {
+	if h.mb.b.evalCtx.TxnIsoLevel.ToleratesWriteSkew() {
+		panic(unimplemented.Newf("read committed",
+			"%s with uniqueness or exclusion constraint checks under READ COMMITTED isolation",
+			h.mb.opName,
+		))
+	}
 	tabMeta := h.mb.b.addTable(h.mb.tab, tree.NewUnqualifiedTableName(h.mb.tab.Name()))
 	ordinals = tableOrdinals(tabMeta.Table, columnKinds{
 		includeMutations: false,
@@ -0,0 +1,881 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// plpgsqlBuilder compiles the body of a PL/pgSQL function into a
+// plpgsqltree.Program. Each SQL expression and statement in the body is built
+// into a relational expression which refers to the variables of the program
+// as outer columns. The program is executed by an interpreter which evaluates
+// these expressions, passing the current values of the variables as the
+// arguments that replace references to the variable columns.
+type plpgsqlBuilder struct {
+	ob *Builder
+
+	prog *plpgsqltree.Program
+
+	// varCols contains the column for each variable of the program, in the
+	// order of the variables.
+	varCols opt.ColList
+
+	// body contains the relational expression for each ExprOrdinal of the
+	// program.
+	body memo.RelListExpr
+
+	// frames is the stack of blocks and loops that enclose the statement being
+	// compiled. Variables are resolved by searching the frames from the top.
+	frames []*plpgsqlFrame
+
+	// returnType is the return type of the function. For a set-returning
+	// function, it is the type of each row of the result.
+	returnType *types.T
+
+	// handlerDepth is the number of exception handlers that enclose the
+	// statement being compiled.
+	handlerDepth int
+
+	// onStmt, if non-nil, is called after each SQL statement in the body is
+	// built.
+	onStmt func(stmtScope *scope, stmt tree.Statement)
+}
+
+// plpgsqlFrame is a block, loop or exception handler of a PL/pgSQL function
+// body.
+type plpgsqlFrame struct {
+	scope  *scope
+	label  string
+	isLoop bool
+	vars   map[string]*plpgsqlVar
+
+	// block is the compiled block which declares the variables of the frame. It
+	// is used to initialize RECORD variables when they are first assigned.
+	block *plpgsqltree.ProgBlock
+}
+
+// plpgsqlVar is a variable which is visible to the statements of a frame.
+type plpgsqlVar struct {
+	ord      plpgsqltree.VarOrdinal
+	typ      *types.T
+	constant bool
+
+	// unassignedRecord is true if the variable has type RECORD and has not yet
+	// been assigned a row. The type of the variable and its column are created
+	// by the first assignment.
+	unassignedRecord bool
+}
+
+// buildPLpgSQL compiles the body of a PL/pgSQL function. The parameters of
+// the function must be the columns of paramScope, in order. setReturning is
+// true if the function returns a set of rows of type returnType.
+func (b *Builder) buildPLpgSQL(
+	block *plpgsqltree.Block,
+	paramScope *scope,
+	returnType *types.T,
+	setReturning bool,
+	onStmt func(stmtScope *scope, stmt tree.Statement),
+) (prog *plpgsqltree.Program, params opt.ColList, body memo.RelListExpr) {
+	if types.IsRecordType(returnType) {
+		panic(unimplemented.New("plpgsql record return",
+			"PL/pgSQL functions returning RECORD are not yet supported"))
+	}
+	pb := plpgsqlBuilder{
+		ob:         b,
+		prog:       &plpgsqltree.Program{SetReturning: setReturning},
+		returnType: returnType,
+		onStmt:     onStmt,
+	}
+	paramFrame := &plpgsqlFrame{scope: paramScope, vars: make(map[string]*plpgsqlVar)}
+	for i := range paramScope.cols {
+		col := &paramScope.cols[i]
+		ord := pb.addVarForCol(col.id, col.name.MetadataName(), col.typ, false /* notNull */)
+		if col.name.refName != "" {
+			paramFrame.vars[string(col.name.refName)] = &plpgsqlVar{ord: ord, typ: col.typ}
+		}
+	}
+	pb.prog.NumParams = len(paramScope.cols)
+	pb.frames = append(pb.frames, paramFrame)
+
+	// The FOUND variable is declared in a scope which encloses the body of the
+	// function.
+	foundFrame := &plpgsqlFrame{scope: paramScope.push(), vars: make(map[string]*plpgsqlVar)}
+	pb.frames = append(pb.frames, foundFrame)
+	pb.prog.Found = pb.declareVar(foundFrame, "found", types.Bool, false /* notNull */)
+
+	pb.prog.Body = pb.buildBlock(block)
+	return pb.prog, pb.varCols, pb.body
+}
+
+// addVarForCol adds a variable to the program which is represented by the
+// given column.
+func (pb *plpgsqlBuilder) addVarForCol(
+	col opt.ColumnID, name string, typ *types.T, notNull bool,
+) plpgsqltree.VarOrdinal {
+	ord := plpgsqltree.VarOrdinal(len(pb.prog.Vars))
+	pb.prog.Vars = append(pb.prog.Vars, plpgsqltree.ProgramVar{Name: name, Typ: typ, NotNull: notNull})
+	pb.varCols = append(pb.varCols, col)
+	return ord
+}
+
+// declareVar creates a variable in the given frame. If the type of the
+// variable is a composite type, a field variable is created for each field so
+// that the fields can be referenced as "var.field".
+func (pb *plpgsqlBuilder) declareVar(
+	frame *plpgsqlFrame, name string, typ *types.T, notNull bool,
+) plpgsqltree.VarOrdinal {
+	if _, ok := frame.vars[name]; ok {
+		panic(pgerror.Newf(pgcode.DuplicateObject, "duplicate declaration of variable %q", name))
+	}
+	col := pb.ob.synthesizeColumn(frame.scope, scopeColName(tree.Name(name)), typ, nil /* expr */, nil /* scalar */)
+	if frame.label != "" {
+		// Variables can be qualified with the label of their block.
+		col.table = tree.MakeUnqualifiedTableName(tree.Name(frame.label))
+	}
+	ord := pb.addVarForCol(col.id, name, typ, notNull)
+	frame.vars[name] = &plpgsqlVar{ord: ord, typ: typ}
+
+	if typ.Family() == types.TupleFamily && len(typ.TupleLabels()) > 0 {
+		fields := make([]plpgsqltree.VarOrdinal, len(typ.TupleContents()))
+		for i, fieldTyp := range typ.TupleContents() {
+			label := typ.TupleLabels()[i]
+			fieldCol := pb.ob.synthesizeColumn(
+				frame.scope, scopeColName(tree.Name(label)), fieldTyp, nil /* expr */, nil, /* scalar */
+			)
+			fieldCol.table = tree.MakeUnqualifiedTableName(tree.Name(name))
+			// Field columns can only be referenced when qualified with the name
+			// of the variable.
+			fieldCol.visibility = accessibleByQualifiedStar
+			fields[i] = pb.addVarForCol(fieldCol.id, name+"."+label, fieldTyp, false /* notNull */)
+		}
+		pb.prog.Vars[ord].Fields = fields
+	}
+	return ord
+}
+
+func (pb *plpgsqlBuilder) pushFrame(frame *plpgsqlFrame) {
+	if frame.vars == nil {
+		frame.vars = make(map[string]*plpgsqlVar)
+	}
+	pb.frames = append(pb.frames, frame)
+}
+
+func (pb *plpgsqlBuilder) popFrame() {
+	pb.frames = pb.frames[:len(pb.frames)-1]
+}
+
+// currentScope returns the scope of the innermost frame.
+func (pb *plpgsqlBuilder) currentScope() *scope {
+	return pb.frames[len(pb.frames)-1].scope
+}
+
+// lookupVar returns the variable with the given name, and the frame that
+// declares it.
+func (pb *plpgsqlBuilder) lookupVar(name tree.Name) (*plpgsqlVar, *plpgsqlFrame) {
+	if v, frame := pb.findVar(name); frame != nil {
+		return v, frame
+	}
+	panic(pgerror.Newf(pgcode.Syntax, "%q is not a known variable", tree.ErrString(&name)))
+}
+
+// findVar is like lookupVar, but returns a nil frame if there is no variable
+// with the given name.
+func (pb *plpgsqlBuilder) findVar(name tree.Name) (*plpgsqlVar, *plpgsqlFrame) {
+	for i := len(pb.frames) - 1; i >= 0; i-- {
+		if v, ok := pb.frames[i].vars[string(name)]; ok {
+			return v, pb.frames[i]
+		}
+	}
+	return nil, nil
+}
+
+// lookupTarget returns the variable that is assigned by a statement.
+func (pb *plpgsqlBuilder) lookupTarget(name tree.Name) (*plpgsqlVar, *plpgsqlFrame) {
+	v, frame := pb.lookupVar(name)
+	if v.constant {
+		panic(pgerror.Newf(pgcode.Syntax, "variable %q is declared CONSTANT", tree.ErrString(&name)))
+	}
+	return v, frame
+}
+
+func (pb *plpgsqlBuilder) buildBlock(block *plpgsqltree.Block) *plpgsqltree.ProgBlock {
+	prog := &plpgsqltree.ProgBlock{Label: block.Label}
+	frame := &plpgsqlFrame{scope: pb.currentScope().push(), label: block.Label, block: prog}
+	pb.pushFrame(frame)
+	for i := range block.Decls {
+		decl := &block.Decls[i]
+		typ, err := tree.ResolveType(pb.ob.ctx, decl.Typ, pb.ob.semaCtx.TypeResolver)
+		if err != nil {
+			panic(err)
+		}
+		name := string(decl.Var)
+		if types.IsRecordType(typ) {
+			if _, ok := frame.vars[name]; ok {
+				panic(pgerror.Newf(pgcode.DuplicateObject, "duplicate declaration of variable %q", name))
+			}
+			frame.vars[name] = &plpgsqlVar{ord: plpgsqltree.NoVar, typ: typ, unassignedRecord: true}
+			continue
+		}
+		// The default expression is built before the variable is declared, so
+		// that it cannot refer to the variable itself.
+		expr := plpgsqltree.NoExpr
+		if decl.Expr != nil {
+			expr = pb.buildExpr(decl.Expr, typ)
+		}
+		ord := pb.declareVar(frame, name, typ, decl.NotNull)
+		frame.vars[name].constant = decl.Constant
+		prog.Inits = append(prog.Inits, plpgsqltree.ProgVarInit{Var: ord, Expr: expr})
+	}
+	prog.Body = pb.buildStmts(block.Body)
+	for i := range block.Exceptions {
+		prog.Handlers = append(prog.Handlers, pb.buildHandler(&block.Exceptions[i]))
+	}
+	pb.popFrame()
+	return prog
+}
+
+func (pb *plpgsqlBuilder) buildHandler(exc *plpgsqltree.Exception) plpgsqltree.ProgHandler {
+	var handler plpgsqltree.ProgHandler
+	for _, cond := range exc.Conditions {
+		switch {
+		case cond.SQLState != "":
+			handler.Codes = append(handler.Codes, plpgsqltree.ProgCondition{Code: cond.SQLState})
+		case cond.Name == "others":
+			handler.Codes = append(handler.Codes, plpgsqltree.ProgCondition{Others: true})
+		default:
+			codes, ok := plpgsqltree.LookupCondition(cond.Name)
+			if !ok {
+				panic(pgerror.Newf(pgcode.UndefinedObject, "unrecognized exception condition %q", cond.Name))
+			}
+			for _, code := range codes {
+				handler.Codes = append(handler.Codes, plpgsqltree.ProgCondition{Code: code})
+			}
+		}
+	}
+	// The SQLSTATE and SQLERRM variables are only visible in the handler.
+	frame := &plpgsqlFrame{scope: pb.currentScope().push()}
+	pb.pushFrame(frame)
+	handler.SQLState = pb.declareVar(frame, "sqlstate", types.String, false /* notNull */)
+	handler.SQLErrM = pb.declareVar(frame, "sqlerrm", types.String, false /* notNull */)
+	pb.handlerDepth++
+	handler.Body = pb.buildStmts(exc.Action)
+	pb.handlerDepth--
+	pb.popFrame()
+	return handler
+}
+
+func (pb *plpgsqlBuilder) buildStmts(stmts []plpgsqltree.Statement) []plpgsqltree.ProgStmt {
+	var res []plpgsqltree.ProgStmt
+	for _, stmt := range stmts {
+		if prog := pb.buildPLpgSQLStmt(stmt); prog != nil {
+			res = append(res, prog)
+		}
+	}
+	return res
+}
+
+func (pb *plpgsqlBuilder) buildPLpgSQLStmt(stmt plpgsqltree.Statement) plpgsqltree.ProgStmt {
+	switch t := stmt.(type) {
+	case *plpgsqltree.Block:
+		return pb.buildBlock(t)
+
+	case *plpgsqltree.Assignment:
+		v, _ := pb.lookupTarget(t.Var)
+		if v.unassignedRecord {
+			panic(unimplemented.New("plpgsql record assignment",
+				"assignment of an expression to a RECORD variable is not yet supported"))
+		}
+		return &plpgsqltree.ProgAssign{Var: v.ord, Expr: pb.buildExpr(t.Value, v.typ)}
+
+	case *plpgsqltree.If:
+		prog := &plpgsqltree.ProgIf{}
+		prog.Conds = append(prog.Conds, pb.buildExpr(t.Condition, types.Bool))
+		prog.Bodies = append(prog.Bodies, pb.buildStmts(t.ThenBody))
+		for i := range t.ElseIfList {
+			prog.Conds = append(prog.Conds, pb.buildExpr(t.ElseIfList[i].Condition, types.Bool))
+			prog.Bodies = append(prog.Bodies, pb.buildStmts(t.ElseIfList[i].Stmts))
+		}
+		prog.Else = pb.buildStmts(t.ElseBody)
+		return prog
+
+	case *plpgsqltree.Loop:
+		prog := &plpgsqltree.ProgLoop{Label: t.Label, Cond: plpgsqltree.NoExpr}
+		prog.Body = pb.buildLoopBody(t.Label, nil /* scope */, t.Body)
+		return prog
+
+	case *plpgsqltree.While:
+		prog := &plpgsqltree.ProgLoop{Label: t.Label}
+		prog.Cond = pb.buildExpr(t.Condition, types.Bool)
+		prog.Body = pb.buildLoopBody(t.Label, nil /* scope */, t.Body)
+		return prog
+
+	case *plpgsqltree.ForInt:
+		prog := &plpgsqltree.ProgForInt{Label: t.Label, Reverse: t.Reverse, Step: plpgsqltree.NoExpr}
+		prog.Lower = pb.buildExpr(t.Lower, types.Int)
+		prog.Upper = pb.buildExpr(t.Upper, types.Int)
+		if t.Step != nil {
+			prog.Step = pb.buildExpr(t.Step, types.Int)
+		}
+		// The loop variable is implicitly declared, and is only visible in the
+		// body of the loop.
+		frame := &plpgsqlFrame{scope: pb.currentScope().push(), vars: make(map[string]*plpgsqlVar)}
+		prog.Var = pb.declareVar(frame, string(t.Target), types.Int, false /* notNull */)
+		prog.Body = pb.buildLoopBody(t.Label, frame, t.Body)
+		return prog
+
+	case *plpgsqltree.ForQuery:
+		if len(t.Target) == 1 {
+			if _, frame := pb.findVar(t.Target[0]); frame == nil {
+				panic(pgerror.New(pgcode.Syntax,
+					"loop variable of loop over rows must be a record variable or list of scalar variables"))
+			}
+		}
+		prog := &plpgsqltree.ProgForQuery{Label: t.Label}
+		prog.Query, prog.Targets = pb.buildQueryInto(t.Query, t.Target, 0 /* limit */)
+		prog.Body = pb.buildLoopBody(t.Label, nil /* scope */, t.Body)
+		return prog
+
+	case *plpgsqltree.Exit:
+		return pb.buildLoopControl(t.Label, t.Condition, false /* isContinue */)
+
+	case *plpgsqltree.Continue:
+		return pb.buildLoopControl(t.Label, t.Condition, true /* isContinue */)
+
+	case *plpgsqltree.Return:
+		return pb.buildReturn(t)
+
+	case *plpgsqltree.ReturnNext:
+		if !pb.prog.SetReturning {
+			panic(pgerror.New(pgcode.DatatypeMismatch, "cannot use RETURN NEXT in a non-SETOF function"))
+		}
+		if t.Expr == nil {
+			panic(pgerror.New(pgcode.Syntax, "RETURN NEXT must have a parameter"))
+		}
+		return &plpgsqltree.ProgReturnNext{Expr: pb.buildExpr(t.Expr, pb.returnType)}
+
+	case *plpgsqltree.ReturnQuery:
+		if !pb.prog.SetReturning {
+			panic(pgerror.New(pgcode.DatatypeMismatch, "cannot use RETURN QUERY in a non-SETOF function"))
+		}
+		return &plpgsqltree.ProgReturnQuery{Query: pb.buildReturnQuery(t.Query)}
+
+	case *plpgsqltree.Raise:
+		return pb.buildRaise(t)
+
+	case *plpgsqltree.Perform:
+		return &plpgsqltree.ProgExec{Stmt: pb.buildPerform(t.Query)}
+
+	case *plpgsqltree.Execute:
+		return pb.buildExecute(t)
+
+	case *plpgsqltree.GetDiagnostics:
+		var prog plpgsqltree.ProgStmt
+		var stmts []plpgsqltree.ProgStmt
+		for _, item := range t.Items {
+			if item.Kind != "row_count" {
+				panic(unimplemented.Newf("plpgsql get diagnostics "+item.Kind,
+					"GET DIAGNOSTICS %s is not yet supported", strings.ToUpper(item.Kind)))
+			}
+			v, _ := pb.lookupTarget(item.Target)
+			if v.unassignedRecord || !cast.ValidCast(types.Int, v.typ, cast.ContextAssignment) {
+				panic(sqlerrors.NewInvalidAssignmentCastError(types.Int, v.typ, string(item.Target)))
+			}
+			stmts = append(stmts, &plpgsqltree.ProgGetDiagnostics{Var: v.ord})
+		}
+		if len(stmts) == 1 {
+			prog = stmts[0]
+		} else {
+			prog = &plpgsqltree.ProgBlock{Body: stmts}
+		}
+		return prog
+
+	case *plpgsqltree.Null:
+		return nil
+
+	default:
+		panic(errors.AssertionFailedf("unexpected PL/pgSQL statement %T", stmt))
+	}
+}
+
+// buildLoopBody builds the body of a loop. If frame is not nil, it declares the
+// loop variable.
+func (pb *plpgsqlBuilder) buildLoopBody(
+	label string, frame *plpgsqlFrame, body []plpgsqltree.Statement,
+) []plpgsqltree.ProgStmt {
+	if frame == nil {
+		frame = &plpgsqlFrame{scope: pb.currentScope()}
+	}
+	frame.label = label
+	frame.isLoop = true
+	pb.pushFrame(frame)
+	defer pb.popFrame()
+	return pb.buildStmts(body)
+}
+
+func (pb *plpgsqlBuilder) buildLoopControl(
+	label string, cond plpgsqltree.Expr, isContinue bool,
+) plpgsqltree.ProgStmt {
+	stmtName := "EXIT"
+	if isContinue {
+		stmtName = "CONTINUE"
+	}
+	found := false
+	for i := len(pb.frames) - 1; i >= 0; i-- {
+		frame := pb.frames[i]
+		if label == "" {
+			if frame.isLoop {
+				found = true
+				break
+			}
+			continue
+		}
+		if frame.label == label {
+			if isContinue && !frame.isLoop {
+				panic(pgerror.Newf(pgcode.Syntax,
+					"block label %q cannot be used in CONTINUE", label))
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		switch {
+		case label != "":
+			panic(pgerror.Newf(pgcode.Syntax,
+				"there is no label %q attached to any block or loop enclosing this statement", label))
+		case isContinue:
+			panic(pgerror.New(pgcode.Syntax, "CONTINUE cannot be used outside a loop"))
+		default:
+			panic(pgerror.Newf(pgcode.Syntax,
+				"%s cannot be used outside a loop, unless it has a label", stmtName))
+		}
+	}
+	prog := &plpgsqltree.ProgExit{Label: label, Cond: plpgsqltree.NoExpr, Continue: isContinue}
+	if cond != nil {
+		prog.Cond = pb.buildExpr(cond, types.Bool)
+	}
+	return prog
+}
+
+func (pb *plpgsqlBuilder) buildReturn(t *plpgsqltree.Return) plpgsqltree.ProgStmt {
+	prog := &plpgsqltree.ProgReturn{Expr: plpgsqltree.NoExpr}
+	if t.Expr == nil {
+		if !pb.prog.SetReturning && pb.returnType.Family() != types.VoidFamily {
+			panic(pgerror.New(pgcode.Syntax, "missing expression at or near \"RETURN;\""))
+		}
+		return prog
+	}
+	if pb.prog.SetReturning {
+		panic(errors.WithHint(
+			pgerror.New(pgcode.DatatypeMismatch, "RETURN cannot have a parameter in function returning set"),
+			"Use RETURN NEXT or RETURN QUERY.",
+		))
+	}
+	if pb.returnType.Family() == types.VoidFamily {
+		panic(pgerror.New(pgcode.DatatypeMismatch,
+			"RETURN cannot have a parameter in function returning void"))
+	}
+	prog.Expr = pb.buildExpr(t.Expr, pb.returnType)
+	return prog
+}
+
+func (pb *plpgsqlBuilder) buildRaise(t *plpgsqltree.Raise) plpgsqltree.ProgStmt {
+	if t.LogLevel == "" && t.CodeName == "" && t.Code == "" && t.Message == "" && len(t.Options) == 0 {
+		if pb.handlerDepth == 0 {
+			panic(pgerror.New(pgcode.StackedDiagnosticsAccessedWithoutActiveHandler,
+				"RAISE without parameters cannot be used outside an exception handler"))
+		}
+		return &plpgsqltree.ProgRaise{Rethrow: true}
+	}
+	prog := &plpgsqltree.ProgRaise{Severity: strings.ToUpper(t.LogLevel), Message: t.Message}
+	if prog.Severity == "" {
+		prog.Severity = "EXCEPTION"
+	}
+	switch {
+	case t.CodeName != "":
+		codes, ok := plpgsqltree.LookupCondition(t.CodeName)
+		if !ok {
+			panic(pgerror.Newf(pgcode.UndefinedObject, "unrecognized exception condition %q", t.CodeName))
+		}
+		prog.Code = codes[0]
+		if prog.Message == "" {
+			prog.Message = t.CodeName
+		}
+	case t.Code != "":
+		prog.Code = t.Code
+		if prog.Message == "" {
+			prog.Message = t.Code
+		}
+	}
+	for _, p := range t.Params {
+		prog.Params = append(prog.Params, pb.buildTextExpr(p))
+	}
+	seen := make(map[string]bool)
+	if t.Message != "" {
+		seen["message"] = true
+	}
+	if t.CodeName != "" || t.Code != "" {
+		seen["errcode"] = true
+	}
+	for _, o := range t.Options {
+		switch o.OptType {
+		case "message", "detail", "hint", "errcode":
+		default:
+			panic(unimplemented.Newf("plpgsql raise option "+o.OptType,
+				"RAISE option %s is not yet supported", strings.ToUpper(o.OptType)))
+		}
+		if seen[o.OptType] {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"RAISE option already specified: %s", strings.ToUpper(o.OptType)))
+		}
+		seen[o.OptType] = true
+		prog.Options = append(prog.Options, plpgsqltree.ProgRaiseOption{
+			OptType: o.OptType,
+			Expr:    pb.buildTextExpr(o.Expr),
+		})
+	}
+	return prog
+}
+
+// buildPerform builds the query of a PERFORM statement. The result of the
+// query is discarded, but its columns are projected as a tuple for each row so
+// that they are not pruned; evaluating them can cause errors.
+func (pb *plpgsqlBuilder) buildPerform(query tree.Statement) plpgsqltree.ExprOrdinal {
+	stmtScope := pb.buildSQLStmt(query)
+	f := pb.ob.factory
+	md := f.Metadata()
+	presentation := stmtScope.makePresentation()
+	if len(presentation) == 0 {
+		return pb.addExpr(stmtScope.expr, nil /* ordering */, memo.TrueSingleton, types.Bool)
+	}
+	elems := make(memo.ScalarListExpr, len(presentation))
+	contents := make([]*types.T, len(presentation))
+	for i, col := range presentation {
+		elems[i] = f.ConstructVariable(col.ID)
+		contents[i] = md.ColumnMeta(col.ID).Type
+	}
+	typ := types.MakeTuple(contents)
+	return pb.addExpr(stmtScope.expr, nil /* ordering */, f.ConstructTuple(elems, typ), typ)
+}
+
+func (pb *plpgsqlBuilder) buildExecute(t *plpgsqltree.Execute) plpgsqltree.ProgStmt {
+	stmt := t.SQLStmt
+	isSelect := false
+	switch s := stmt.(type) {
+	case *tree.Select, *tree.ParenSelect:
+		isSelect = true
+	case *tree.Insert:
+		if len(t.Target) == 0 {
+			c := *s
+			c.Returning, stmt = pb.returningForRowCount(s.Returning), &c
+		}
+	case *tree.Update:
+		if len(t.Target) == 0 {
+			c := *s
+			c.Returning, stmt = pb.returningForRowCount(s.Returning), &c
+		}
+	case *tree.Delete:
+		if len(t.Target) == 0 {
+			c := *s
+			c.Returning, stmt = pb.returningForRowCount(s.Returning), &c
+		}
+	case *tree.Merge:
+		if len(t.Target) == 0 {
+			c := *s
+			c.Returning, stmt = pb.returningForRowCount(s.Returning), &c
+		}
+	default:
+		if len(t.Target) > 0 {
+			panic(pgerror.New(pgcode.Syntax, "INTO used with a command that cannot return data"))
+		}
+		stmtScope := pb.buildSQLStmt(stmt)
+		return &plpgsqltree.ProgExec{Stmt: pb.addExpr(stmtScope.expr, nil /* ordering */, memo.TrueSingleton, types.Bool)}
+	}
+	if len(t.Target) == 0 {
+		if isSelect {
+			panic(errors.WithHint(
+				pgerror.New(pgcode.Syntax, "query has no destination for result data"),
+				"If you want to discard the results of a SELECT, use PERFORM instead.",
+			))
+		}
+		// The mutation returns a row for each row that it affects, so that the
+		// number of affected rows can be determined.
+		stmtScope := pb.buildSQLStmt(stmt)
+		return &plpgsqltree.ProgExec{Stmt: pb.addExpr(stmtScope.expr, nil /* ordering */, memo.TrueSingleton, types.Bool)}
+	}
+	if !isSelect {
+		if r, ok := returningClause(stmt); !ok || !tree.HasReturningClause(r) {
+			panic(pgerror.New(pgcode.Syntax, "INTO used with a command that cannot return data"))
+		}
+	}
+	// Only the first row of a SELECT is needed, unless the statement is STRICT,
+	// in which case it is an error if there is more than one row.
+	limit := 0
+	if isSelect {
+		limit = 1
+		if t.Strict {
+			limit = 2
+		}
+	}
+	prog := &plpgsqltree.ProgExec{Strict: t.Strict}
+	prog.Stmt, prog.Targets = pb.buildQueryInto(stmt, t.Target, limit)
+	return prog
+}
+
+// returningClause returns the RETURNING clause of a mutation statement.
+func returningClause(stmt tree.Statement) (tree.ReturningClause, bool) {
+	switch t := stmt.(type) {
+	case *tree.Insert:
+		return t.Returning, true
+	case *tree.Update:
+		return t.Returning, true
+	case *tree.Delete:
+		return t.Returning, true
+	case *tree.Merge:
+		return t.Returning, true
+	}
+	return nil, false
+}
+
+// returningForRowCount returns a RETURNING clause which returns a constant for
+// each row affected by a mutation without an INTO clause.
+func (pb *plpgsqlBuilder) returningForRowCount(r tree.ReturningClause) tree.ReturningClause {
+	if tree.HasReturningClause(r) {
+		if _, ok := r.(*tree.ReturningNothing); !ok {
+			panic(pgerror.New(pgcode.Syntax, "query has no destination for result data"))
+		}
+	}
+	return &tree.ReturningExprs{tree.SelectExpr{Expr: tree.DBoolTrue}}
+}
+
+// buildReturnQuery builds the query of a RETURN QUERY statement. The columns
+// of the query are cast to the return type of the function.
+func (pb *plpgsqlBuilder) buildReturnQuery(query tree.Statement) plpgsqltree.ExprOrdinal {
+	stmtScope := pb.buildSQLStmt(query)
+	physProps := stmtScope.makePhysicalProps()
+	cols := physProps.Presentation
+	var scalar opt.ScalarExpr
+	if len(cols) == 1 {
+		scalar = pb.assignCast(cols[0], pb.returnType)
+	} else if pb.returnType.Family() == types.TupleFamily &&
+		len(pb.returnType.TupleContents()) == len(cols) {
+		scalar = pb.buildTuple(cols, pb.returnType)
+	} else {
+		panic(errors.WithDetail(
+			pgerror.New(pgcode.DatatypeMismatch, "structure of query does not match function result type"),
+			"Number of returned columns does not match the number of expected columns.",
+		))
+	}
+	return pb.addExpr(stmtScope.expr, physProps.Ordering.ToOrdering(), scalar, pb.returnType)
+}
+
+// buildQueryInto builds a query whose rows are assigned to the given target
+// variables. If limit is positive, the query produces at most that many rows.
+func (pb *plpgsqlBuilder) buildQueryInto(
+	query tree.Statement, targets []plpgsqltree.Variable, limit int,
+) (plpgsqltree.ExprOrdinal, []plpgsqltree.VarOrdinal) {
+	stmtScope := pb.buildSQLStmt(query)
+	physProps := stmtScope.makePhysicalProps()
+	ordering := physProps.Ordering.ToOrdering()
+	if limit > 0 {
+		pb.ob.buildLimit(&tree.Limit{Count: tree.NewDInt(tree.DInt(limit))}, pb.ob.allocScope(), stmtScope)
+		ordering = nil
+	}
+	cols := physProps.Presentation
+
+	var scalar opt.ScalarExpr
+	var typ *types.T
+	var targetOrds []plpgsqltree.VarOrdinal
+	if len(targets) == 1 {
+		v, frame := pb.lookupTarget(targets[0])
+		if v.unassignedRecord {
+			// The type of a RECORD variable is determined by the first query
+			// that is assigned to it.
+			contents := make([]*types.T, len(cols))
+			labels := make([]string, len(cols))
+			md := pb.ob.factory.Metadata()
+			for i := range cols {
+				contents[i] = md.ColumnMeta(cols[i].ID).Type
+				if contents[i].Family() == types.UnknownFamily {
+					contents[i] = types.String
+				}
+				labels[i] = cols[i].Alias
+			}
+			recTyp := types.MakeLabeledTuple(contents, labels)
+			delete(frame.vars, string(targets[0]))
+			ord := pb.declareVar(frame, string(targets[0]), recTyp, false /* notNull */)
+			frame.block.Inits = append(frame.block.Inits,
+				plpgsqltree.ProgVarInit{Var: ord, Expr: plpgsqltree.NoExpr})
+			v = frame.vars[string(targets[0])]
+		}
+		typ = v.typ
+		targetOrds = []plpgsqltree.VarOrdinal{v.ord}
+		if typ.Family() == types.TupleFamily && !(len(cols) == 1 &&
+			pb.ob.factory.Metadata().ColumnMeta(cols[0].ID).Type.Family() == types.TupleFamily) {
+			scalar = pb.buildTuple(cols, typ)
+		} else if len(cols) == 0 {
+			scalar = pb.ob.factory.ConstructNull(typ)
+		} else {
+			scalar = pb.assignCast(cols[0], typ)
+		}
+	} else {
+		contents := make([]*types.T, len(targets))
+		for i := range targets {
+			v, _ := pb.lookupTarget(targets[i])
+			if v.unassignedRecord || v.typ.Family() == types.TupleFamily {
+				panic(pgerror.Newf(pgcode.Syntax,
+					"record variable cannot be part of multiple-item INTO list"))
+			}
+			contents[i] = v.typ
+			targetOrds = append(targetOrds, v.ord)
+		}
+		typ = types.MakeTuple(contents)
+		scalar = pb.buildTuple(cols, typ)
+	}
+	return pb.addExpr(stmtScope.expr, ordering, scalar, typ), targetOrds
+}
+
+// buildTuple builds a tuple of the given type from the given columns. The
+// columns are cast to the types of the tuple elements. Missing columns are
+// NULL, and extra columns are ignored.
+func (pb *plpgsqlBuilder) buildTuple(cols physical.Presentation, typ *types.T) opt.ScalarExpr {
+	contents := typ.TupleContents()
+	elems := make(memo.ScalarListExpr, len(contents))
+	for i := range contents {
+		if i < len(cols) {
+			elems[i] = pb.assignCast(cols[i], contents[i])
+		} else {
+			elems[i] = pb.ob.factory.ConstructNull(contents[i])
+		}
+	}
+	return pb.ob.factory.ConstructTuple(elems, typ)
+}
+
+// buildExpr builds an expression that evaluates the given scalar expression and
+// casts it to the given type.
+func (pb *plpgsqlBuilder) buildExpr(expr tree.Expr, typ *types.T) plpgsqltree.ExprOrdinal {
+	sel := &tree.Select{Select: &tree.SelectClause{Exprs: tree.SelectExprs{{Expr: expr}}}}
+	// Like in Postgres, the expression is evaluated with its own type and then
+	// cast to the target type. Requiring the target type while type-checking
+	// could rule out overloads, e.g. INT / INT, which returns a DECIMAL. Only
+	// constants, which have no type of their own, are typed as the target type.
+	var desiredTypes []*types.T
+	switch tree.StripParens(expr).(type) {
+	case tree.Constant, *tree.Array:
+		desiredTypes = []*types.T{typ}
+	}
+	stmtScope := pb.buildSQLStmtWithTypes(sel, desiredTypes)
+	pb.ob.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, pb.ob.allocScope(), stmtScope)
+	col := stmtScope.makePresentation()[0]
+	if typ.Family() == types.BoolFamily {
+		// Conditions must be booleans; they are not cast from other types.
+		colTyp := pb.ob.factory.Metadata().ColumnMeta(col.ID).Type
+		if colTyp.Family() != types.BoolFamily && colTyp.Family() != types.UnknownFamily {
+			panic(pgerror.Newf(pgcode.DatatypeMismatch,
+				"argument of %s must be type bool, not type %s", tree.AsString(expr), colTyp))
+		}
+	}
+	return pb.addExpr(stmtScope.expr, nil /* ordering */, pb.assignCast(col, typ), typ)
+}
+
+// buildTextExpr builds an expression that evaluates the given scalar
+// expression and converts it to a string.
+func (pb *plpgsqlBuilder) buildTextExpr(expr tree.Expr) plpgsqltree.ExprOrdinal {
+	sel := &tree.Select{Select: &tree.SelectClause{Exprs: tree.SelectExprs{{Expr: expr}}}}
+	stmtScope := pb.buildSQLStmt(sel)
+	pb.ob.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, pb.ob.allocScope(), stmtScope)
+	col := stmtScope.makePresentation()[0]
+	var scalar opt.ScalarExpr = pb.ob.factory.ConstructVariable(col.ID)
+	if !pb.ob.factory.Metadata().ColumnMeta(col.ID).Type.Identical(types.String) {
+		scalar = pb.ob.factory.ConstructCast(scalar, types.String)
+	}
+	return pb.addExpr(stmtScope.expr, nil /* ordering */, scalar, types.String)
+}
+
+// assignCast returns an expression that performs an assignment cast of the
+// given column to the given type, if necessary.
+func (pb *plpgsqlBuilder) assignCast(col opt.AliasedColumn, typ *types.T) opt.ScalarExpr {
+	colTyp := pb.ob.factory.Metadata().ColumnMeta(col.ID).Type
+	v := pb.ob.factory.ConstructVariable(col.ID)
+	if colTyp.Identical(typ) {
+		return v
+	}
+	if !cast.ValidCast(colTyp, typ, cast.ContextAssignment) {
+		panic(sqlerrors.NewInvalidAssignmentCastError(colTyp, typ, col.Alias))
+	}
+	return pb.ob.factory.ConstructAssignmentCast(v, typ)
+}
+
+// buildSQLStmt builds a SQL statement of the function body.
+func (pb *plpgsqlBuilder) buildSQLStmt(stmt tree.Statement) *scope {
+	return pb.buildSQLStmtWithTypes(stmt, nil /* desiredTypes */)
+}
+
+func (pb *plpgsqlBuilder) buildSQLStmtWithTypes(
+	stmt tree.Statement, desiredTypes []*types.T,
+) *scope {
+	// Each statement in the body is executed separately, so mutations of the
+	// same table by different statements do not conflict with each other. They
+	// can still conflict with mutations made by the statement that invokes the
+	// function.
+	prevMutations := pb.ob.areAllTableMutationsSimpleInserts
+	pb.ob.areAllTableMutationsSimpleInserts = make(map[cat.StableID]bool, len(prevMutations))
+	for id, simpleInsert := range prevMutations {
+		pb.ob.areAllTableMutationsSimpleInserts[id] = simpleInsert
+	}
+	defer func() { pb.ob.areAllTableMutationsSimpleInserts = prevMutations }()
+
+	// The statement is built in an empty scope so that the field columns of
+	// composite variables can only be referenced when they are qualified.
+	stmtScope := pb.ob.buildStmt(stmt, desiredTypes, pb.currentScope().push())
+	if pb.onStmt != nil {
+		pb.onStmt(stmtScope, stmt)
+	}
+	return stmtScope
+}
+
+// addExpr adds an expression to the program. The given scalar expression is
+// projected as the only column of the result of the expression.
+func (pb *plpgsqlBuilder) addExpr(
+	input memo.RelExpr, ordering opt.Ordering, scalar opt.ScalarExpr, typ *types.T,
+) plpgsqltree.ExprOrdinal {
+	f := pb.ob.factory
+	physProps := &physical.Required{}
+	var passthrough opt.ColSet
+	for _, c := range ordering {
+		passthrough.Add(c.ID())
+	}
+	// The ordering is only required for a FOR loop over a query, which
+	// processes the rows in the order produced by the query.
+	physProps.Ordering.FromOrdering(ordering)
+	col := f.Metadata().AddColumn("", typ)
+	projections := memo.ProjectionsExpr{f.ConstructProjectionsItem(scalar, col)}
+	physProps.Presentation = physical.Presentation{opt.AliasedColumn{ID: col}}
+	expr := f.ConstructProject(input, projections, passthrough)
+	ord := plpgsqltree.ExprOrdinal(len(pb.body))
+	pb.body = append(pb.body, memo.RelRequiredPropsExpr{RelExpr: expr, PhysProps: physProps})
+	return ord
+}
@@ -146,6 +146,14 @@ func (b *Builder) analyzeSelectList(
 						panic(pgerror.Newf(pgcode.Syntax,
 							"%q cannot be aliased", tree.ErrString(v)))
 					}
+					if t, ok := v.(*tree.TupleStar); ok && b.insideTriggerFuncDef && inScope.isTriggerRow(t.Expr) {
+						// The columns of the NEW and OLD rows of a trigger function are
+						// not known until the trigger is invoked, so (NEW).* is not
+						// expanded when the function is created.
+						outScope.addColumn(scopeColName(""), tree.DNull)
+						expansions = append(expansions, e)
+						continue
+					}
 
 					aliases, exprs := b.expandStar(e.Expr, inScope)
 					if b.insideFuncDef || b.insideViewDef {
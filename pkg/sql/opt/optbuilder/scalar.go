@@ -15,6 +15,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/seqexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
@@ -25,9 +26,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins/builtinsregistry"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treebin"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
@@ -282,6 +286,9 @@ func (b *Builder) buildScalar(
 		texpr := t.Expr.(tree.TypedExpr)
 		arg := b.buildScalar(texpr, inScope, nil, nil, colRefs)
 		out = b.factory.ConstructCast(arg, t.ResolvedType())
+		if t.ResolvedType().IsDomain() {
+			out = b.buildDomainChecks(out, texpr, t.ResolvedType(), inScope, colRefs)
+		}
 
 	case *tree.CoalesceExpr:
 		args := make(memo.ScalarListExpr, len(t.Exprs))
@@ -332,6 +339,9 @@ func (b *Builder) buildScalar(
 	case *tree.FuncExpr:
 		return b.buildFunction(t, inScope, outScope, outCol, colRefs)
 
+	case *tree.GroupingExpr:
+		out = b.buildGroupingExpr(t, inScope)
+
 	case *tree.IfExpr:
 		valType := t.ResolvedType()
 		input := b.buildScalar(t.Cond.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -548,6 +558,12 @@ func (b *Builder) buildFunction(
 	}
 
 	overload := f.ResolvedOverload()
+	if overload.IsProcedure {
+		panic(errors.WithHint(
+			pgerror.Newf(pgcode.WrongObjectType, "%s is a procedure", def.Name),
+			"To call a procedure, use CALL.",
+		))
+	}
 	if overload.IsUDF {
 		return b.buildUDF(f, def, inScope, outScope, outCol, colRefs)
 	}
@@ -620,13 +636,19 @@ func (b *Builder) buildUDF(
 ) (out opt.ScalarExpr) {
 	o := f.ResolvedOverload()
 
+	// The body of a trigger function can only be built for a specific table.
+	if f.ResolvedType().Family() == types.TriggerFamily {
+		panic(pgerror.New(pgcode.FeatureNotSupported,
+			"trigger functions can only be called as triggers"))
+	}
+
 	// Validate that the return types match the original return types defined in
 	// the function. Return types like user defined return types may change since
 	// the function was first created.
 	rtyp := f.ResolvedType()
 	if rtyp.UserDefined() {
 		funcReturnType, err := tree.ResolveType(b.ctx,
-			&tree.OIDTypeReference{OID: rtyp.Oid()}, b.semaCtx.TypeResolver)
+			&tree.OIDTypeReference{OID: rtyp.UserDefinedOID()}, b.semaCtx.TypeResolver)
 		if err != nil {
 			panic(err)
 		}
@@ -678,95 +700,112 @@ func (b *Builder) buildUDF(
 		}
 	}
 
-	// Parse the function body.
-	stmts, err := parser.Parse(o.Body)
-	if err != nil {
-		panic(err)
-	}
-
-	// Build an expression for each statement in the function body.
-	rels := make(memo.RelListExpr, len(stmts))
 	isSetReturning := o.Class == tree.GeneratorClass
-	for i := range stmts {
-		stmtScope := b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
-		expr := stmtScope.expr
-		physProps := stmtScope.makePhysicalProps()
-
-		// The last statement produces the output of the UDF.
-		if i == len(stmts)-1 {
-			// Add a LIMIT 1 to the last statement if the UDF is not
-			// set-returning. This is valid because any other rows after the
-			// first can simply be ignored. The limit could be beneficial
-			// because it could allow additional optimization.
-			if !isSetReturning {
-				b.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, b.allocScope(), stmtScope)
-				expr = stmtScope.expr
-				// The limit expression will maintain the desired ordering, if any,
-				// so the physical props ordering can be cleared. The presentation
-				// must remain.
-				physProps.Ordering = props.OrderingChoice{}
-			}
+	var rels memo.RelListExpr
+	var prog *plpgsqltree.Program
+	if o.Language == tree.FunctionLangPlPgSQL {
+		// A PL/pgSQL function body is compiled into a program, which is
+		// interpreted when the function is invoked. The variables of the
+		// program, which include the parameters, are passed as the parameters
+		// of each expression in the body.
+		block, err := plpgsqlparser.Parse(o.Body)
+		if err != nil {
+			panic(err)
+		}
+		prog, params, rels = b.buildPLpgSQL(
+			block, bodyScope, f.ResolvedType(), isSetReturning, nil, /* onStmt */
+		)
+	} else {
+		// Parse the function body.
+		stmts, err := parser.Parse(o.Body)
+		if err != nil {
+			panic(err)
+		}
+
+		// Build an expression for each statement in the function body.
+		rels = make(memo.RelListExpr, len(stmts))
+		for i := range stmts {
+			stmtScope := b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
+			expr := stmtScope.expr
+			physProps := stmtScope.makePhysicalProps()
+
+			// The last statement produces the output of the UDF.
+			if i == len(stmts)-1 {
+				// Add a LIMIT 1 to the last statement if the UDF is not
+				// set-returning. This is valid because any other rows after the
+				// first can simply be ignored. The limit could be beneficial
+				// because it could allow additional optimization.
+				if !isSetReturning {
+					b.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, b.allocScope(), stmtScope)
+					expr = stmtScope.expr
+					// The limit expression will maintain the desired ordering, if any,
+					// so the physical props ordering can be cleared. The presentation
+					// must remain.
+					physProps.Ordering = props.OrderingChoice{}
+				}
 
-			// Replace the tuple contents of RECORD return types from Any to the
-			// result columns of the last statement. If the result column is a tuple,
-			// then use its tuple contents for the return instead.
-			isSingleTupleResult := len(stmtScope.cols) == 1 && stmtScope.cols[0].typ.Family() == types.TupleFamily
-			if types.IsRecordType(f.ResolvedType()) {
-				if isSingleTupleResult {
-					f.ResolvedType().InternalType.TupleContents = stmtScope.cols[0].typ.TupleContents()
-				} else {
-					tc := make([]*types.T, len(stmtScope.cols))
-					for i, col := range stmtScope.cols {
-						tc[i] = col.typ
+				// Replace the tuple contents of RECORD return types from Any to the
+				// result columns of the last statement. If the result column is a tuple,
+				// then use its tuple contents for the return instead.
+				isSingleTupleResult := len(stmtScope.cols) == 1 && stmtScope.cols[0].typ.Family() == types.TupleFamily
+				if types.IsRecordType(f.ResolvedType()) {
+					if isSingleTupleResult {
+						f.ResolvedType().InternalType.TupleContents = stmtScope.cols[0].typ.TupleContents()
+					} else {
+						tc := make([]*types.T, len(stmtScope.cols))
+						for i, col := range stmtScope.cols {
+							tc[i] = col.typ
+						}
+						f.ResolvedType().InternalType.TupleContents = tc
 					}
-					f.ResolvedType().InternalType.TupleContents = tc
 				}
-			}
 
-			// If there are multiple output columns or the output type is a record and
-			// the output column is not a tuple, we must combine them into a tuple -
-			// only a single column can be returned from a UDF.
-			cols := physProps.Presentation
-			if len(cols) > 1 || (types.IsRecordType(f.ResolvedType()) && !isSingleTupleResult) {
-				elems := make(memo.ScalarListExpr, len(cols))
-				for i := range cols {
-					elems[i] = b.factory.ConstructVariable(cols[i].ID)
+				// If there are multiple output columns or the output type is a record and
+				// the output column is not a tuple, we must combine them into a tuple -
+				// only a single column can be returned from a UDF.
+				cols := physProps.Presentation
+				if len(cols) > 1 || (types.IsRecordType(f.ResolvedType()) && !isSingleTupleResult) {
+					elems := make(memo.ScalarListExpr, len(cols))
+					for i := range cols {
+						elems[i] = b.factory.ConstructVariable(cols[i].ID)
+					}
+					tup := b.factory.ConstructTuple(elems, f.ResolvedType())
+					stmtScope = bodyScope.push()
+					col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, tup)
+					expr = b.constructProject(expr, []scopeColumn{*col})
+					physProps = stmtScope.makePhysicalProps()
+				}
+
+				// We must preserve the presentation of columns as physical
+				// properties to prevent the optimizer from pruning the output
+				// column. If necessary, we add an assignment cast to the result
+				// column so that its type matches the function return type. Record return
+				// types do not need an assignment cast, since at this point the return
+				// column is already a tuple.
+				returnCol := physProps.Presentation[0].ID
+				returnColMeta := b.factory.Metadata().ColumnMeta(returnCol)
+				if !types.IsRecordType(f.ResolvedType()) && !returnColMeta.Type.Identical(f.ResolvedType()) {
+					if !cast.ValidCast(returnColMeta.Type, f.ResolvedType(), cast.ContextAssignment) {
+						panic(sqlerrors.NewInvalidAssignmentCastError(
+							returnColMeta.Type, f.ResolvedType(), returnColMeta.Alias))
+					}
+					cast := b.factory.ConstructAssignmentCast(
+						b.factory.ConstructVariable(physProps.Presentation[0].ID),
+						f.ResolvedType(),
+					)
+					stmtScope = bodyScope.push()
+					col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, cast)
+					expr = b.constructProject(expr, []scopeColumn{*col})
+					physProps = stmtScope.makePhysicalProps()
 				}
-				tup := b.factory.ConstructTuple(elems, f.ResolvedType())
-				stmtScope = bodyScope.push()
-				col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, tup)
-				expr = b.constructProject(expr, []scopeColumn{*col})
-				physProps = stmtScope.makePhysicalProps()
 			}
 
-			// We must preserve the presentation of columns as physical
-			// properties to prevent the optimizer from pruning the output
-			// column. If necessary, we add an assignment cast to the result
-			// column so that its type matches the function return type. Record return
-			// types do not need an assignment cast, since at this point the return
-			// column is already a tuple.
-			returnCol := physProps.Presentation[0].ID
-			returnColMeta := b.factory.Metadata().ColumnMeta(returnCol)
-			if !types.IsRecordType(f.ResolvedType()) && !returnColMeta.Type.Identical(f.ResolvedType()) {
-				if !cast.ValidCast(returnColMeta.Type, f.ResolvedType(), cast.ContextAssignment) {
-					panic(sqlerrors.NewInvalidAssignmentCastError(
-						returnColMeta.Type, f.ResolvedType(), returnColMeta.Alias))
-				}
-				cast := b.factory.ConstructAssignmentCast(
-					b.factory.ConstructVariable(physProps.Presentation[0].ID),
-					f.ResolvedType(),
-				)
-				stmtScope = bodyScope.push()
-				col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, cast)
-				expr = b.constructProject(expr, []scopeColumn{*col})
-				physProps = stmtScope.makePhysicalProps()
+			rels[i] = memo.RelRequiredPropsExpr{
+				RelExpr:   expr,
+				PhysProps: physProps,
 			}
 		}
 
-		rels[i] = memo.RelRequiredPropsExpr{
-			RelExpr:   expr,
-			PhysProps: physProps,
-		}
 	}
 
 	out = b.factory.ConstructUDF(
@@ -775,6 +814,7 @@ func (b *Builder) buildUDF(
 			Name:         def.Name,
 			Params:       params,
 			Body:         rels,
+			Program:      prog,
 			Typ:          f.ResolvedType(),
 			SetReturning: isSetReturning,
 			Volatility:   o.Volatility,
@@ -1000,6 +1040,8 @@ func (b *Builder) constructComparison(
 		return b.factory.ConstructOverlaps(left, right)
 	case treecmp.TSMatches:
 		return b.factory.ConstructTSMatches(left, right)
+	case treecmp.Adjacent:
+		return b.factory.ConstructAdjacent(left, right)
 	}
 	panic(errors.AssertionFailedf("unhandled comparison operator: %s", redact.Safe(cmp.Operator)))
 }
@@ -1151,3 +1193,51 @@ func reType(expr tree.TypedExpr, typ *types.T) tree.TypedExpr {
 	}
 	return retypedExpr
 }
+
+// buildDomainChecks wraps the given cast out of texpr to the domain type typ in
+// calls to crdb_internal.assert_domain_check, which enforce the NOT NULL and
+// CHECK constraints of the domain.
+func (b *Builder) buildDomainChecks(
+	out opt.ScalarExpr, texpr tree.TypedExpr, typ *types.T, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	dd := typ.TypeMeta.DomainData
+	if dd == nil {
+		panic(errors.AssertionFailedf("domain %s is not hydrated", typ.SQLString()))
+	}
+	const fnName = "crdb_internal.assert_domain_check"
+	props, overloads := builtinsregistry.GetBuiltinProperties(fnName)
+	private := &memo.FunctionPrivate{
+		Name:       fnName,
+		Typ:        typ,
+		Properties: props,
+		Overload:   &overloads[0],
+	}
+	domainName := b.factory.ConstructConstVal(tree.NewDString(typ.Name()), types.String)
+	assert := func(ok opt.ScalarExpr, constraint string) {
+		args := memo.ScalarListExpr{
+			out, ok, domainName, b.factory.ConstructConstVal(tree.NewDString(constraint), types.String),
+		}
+		out = b.factory.ConstructFunction(args, private)
+	}
+
+	// The constraints of the domain are evaluated with the value cast to the
+	// base type of the domain.
+	value := tree.NewTypedCastExpr(texpr, typ.DomainBaseType())
+	if dd.NotNull {
+		arg := b.buildScalar(value, inScope, nil, nil, colRefs)
+		assert(b.factory.ConstructIsNot(arg, b.factory.ConstructNull(arg.DataType())), "" /* constraint */)
+	}
+	for _, c := range dd.Checks {
+		expr, err := parser.ParseExpr(c.Expr)
+		if err != nil {
+			panic(err)
+		}
+		expr, err = schemaexpr.ReplaceDomainValue(expr, value)
+		if err != nil {
+			panic(err)
+		}
+		ok := inScope.resolveAndRequireType(expr, types.Bool)
+		assert(b.buildScalar(ok, inScope, nil, nil, colRefs), c.Name)
+	}
+	return out
+}
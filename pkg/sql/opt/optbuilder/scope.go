@@ -106,6 +106,11 @@ type scope struct {
 	// are processing a data source with an alias.
 	alias *tree.AliasClause
 
+	// excludeInheritanceChildren is true if the data source being built was
+	// qualified with ONLY, in which case rows of tables that inherit from it
+	// are not included.
+	excludeInheritanceChildren bool
+
 	// context is the current context in the SQL query (e.g., "SELECT" or
 	// "HAVING"). It is used for error messages and to identify scoping errors
 	// (e.g., aggregates are not allowed in the FROM clause of their own query
@@ -123,6 +128,7 @@ type exprKind int8
 const (
 	exprKindNone exprKind = iota
 	exprKindAlterTableSplitAt
+	exprKindCall
 	exprKindDistinctOn
 	exprKindFrom
 	exprKindGroupBy
@@ -144,6 +150,7 @@ const (
 var exprKindName = [...]string{
 	exprKindNone:              "",
 	exprKindAlterTableSplitAt: "ALTER TABLE SPLIT AT",
+	exprKindCall:              "CALL",
 	exprKindDistinctOn:        "DISTINCT ON",
 	exprKindFrom:              "FROM",
 	exprKindGroupBy:           "GROUP BY",
@@ -651,6 +658,55 @@ func (s *scope) findFuncArgCol(idx tree.PlaceholderIdx) *scopeColumn {
 	return nil
 }
 
+// isTriggerRowField returns true if the given expression accesses a field of
+// the NEW or OLD row of a trigger function, e.g. (NEW).a or (OLD).b[1].
+func (s *scope) isTriggerRowField(expr tree.Expr) bool {
+	fieldAccess := false
+	for {
+		switch t := expr.(type) {
+		case *tree.ParenExpr:
+			expr = t.Expr
+		case *tree.ColumnAccessExpr:
+			fieldAccess = true
+			expr = t.Expr
+		case *tree.IndirectionExpr:
+			expr = t.Expr
+		default:
+			return fieldAccess && s.isTriggerRow(expr)
+		}
+	}
+}
+
+// isTriggerRow returns true if the given expression is a reference to the NEW
+// or OLD row of a trigger function.
+func (s *scope) isTriggerRow(expr tree.Expr) bool {
+	for {
+		p, ok := expr.(*tree.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = p.Expr
+	}
+	name, ok := expr.(*tree.UnresolvedName)
+	if !ok {
+		return false
+	}
+	vn, err := name.NormalizeVarName()
+	if err != nil {
+		return false
+	}
+	c, ok := vn.(*tree.ColumnItem)
+	if !ok {
+		return false
+	}
+	col, err := colinfo.ResolveColumnItem(s.builder.ctx, s, c)
+	if err != nil {
+		return false
+	}
+	// The only parameters of a trigger function are NEW and OLD.
+	return col.(*scopeColumn).paramOrd > 0
+}
+
 // startAggFunc is called when the builder starts building an aggregate
 // function. It is used to disallow nested aggregates and ensure that a
 // grouping error is not called on the aggregate arguments. For example:
@@ -1008,6 +1064,12 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		//    SELECT (kv.*) FROM kv               -> SELECT (k, v) FROM kv
 		//    SELECT COUNT(DISTINCT kv.*) FROM kv -> SELECT COUNT(DISTINCT (k, v)) FROM kv
 		//
+		// The columns of the NEW and OLD rows of a trigger function are not
+		// known until the trigger is invoked, so (NEW).* is replaced with a NULL
+		// of unknown type when the function is created.
+		if ts, ok := t.(*tree.TupleStar); ok && s.builder.insideTriggerFuncDef && s.isTriggerRow(ts.Expr) {
+			return false, tree.DNull
+		}
 		labels, exprs := s.builder.expandStar(expr, s)
 		// We return an untyped tuple because name resolution occurs
 		// before type checking, and type checking will resolve the
@@ -1042,6 +1104,14 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		}
 		return false, colI.(*scopeColumn)
 
+	case *tree.ColumnAccessExpr, *tree.IndirectionExpr:
+		// The fields of the NEW and OLD rows of a trigger function are not known
+		// until the trigger is invoked, so when the function is created they are
+		// replaced with NULLs of unknown type.
+		if s.builder.insideTriggerFuncDef && s.isTriggerRowField(expr) {
+			return false, tree.DNull
+		}
+
 	case *tree.Placeholder:
 		// Replace placeholders that are references to function arguments with
 		// scope columns that represent those arguments.
@@ -86,6 +86,13 @@ func (b *Builder) buildDataSource(
 			locking = locking.filter(source.As.Alias)
 		}
 
+		if source.Only {
+			if source.As.Alias == "" {
+				inScope = inScope.push()
+			}
+			inScope.excludeInheritanceChildren = true
+		}
+
 		outScope = b.buildDataSource(source.Expr, indexFlags, locking, inScope)
 
 		if source.Ordinality {
@@ -141,7 +148,7 @@ func (b *Builder) buildDataSource(
 		switch t := ds.(type) {
 		case cat.Table:
 			tabMeta := b.addTable(t, &resName)
-			return b.buildScan(
+			outScope = b.buildScan(
 				tabMeta,
 				tableOrdinals(t, columnKinds{
 					includeMutations: false,
@@ -151,6 +158,11 @@ func (b *Builder) buildDataSource(
 				indexFlags, locking, inScope,
 				false, /* disableNotVisibleIndex */
 			)
+			if t.InheritanceChildCount() > 0 && !inScope.excludeInheritanceChildren &&
+				!b.insideViewDef && !b.insideFuncDef {
+				outScope = b.buildInheritanceChildren(t, outScope, locking, inScope)
+			}
+			return outScope
 
 		case cat.Sequence:
 			return b.buildSequenceSelect(t, &resName, inScope)
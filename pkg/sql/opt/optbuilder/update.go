@@ -76,6 +76,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 
 	// Find which table we're working on, check the permissions.
 	tab, depName, alias, refColumns := b.resolveTableForMutation(upd.Table, privilege.UPDATE)
+	checkInheritanceChildrenForMutation(tab, upd.Table, "UPDATE")
 
 	if refColumns != nil {
 		panic(pgerror.Newf(pgcode.Syntax,
@@ -313,6 +314,10 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 	// Add assignment casts for default column values.
 	mb.addAssignmentCasts(mb.updateColIDs)
 
+	// Invoke BEFORE ROW triggers, which may modify the updated values. This is
+	// a no-op for upserts, which are not supported on tables with triggers.
+	mb.buildBeforeRowTriggers(tree.TriggerEventUpdate)
+
 	// Disambiguate names so that references in the computed expression refer to
 	// the correct columns.
 	mb.disambiguateColumns()
@@ -346,6 +351,8 @@ func (mb *mutationBuilder) buildUpdate(returning *tree.ReturningExprs) {
 
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerEventUpdate, mb.fetchColIDs, mb.updateColIDs)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
@@ -356,4 +363,6 @@ func (mb *mutationBuilder) buildUpdate(returning *tree.ReturningExprs) {
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
 	)
 	mb.buildReturning(returning)
+
+	mb.buildBeforeStatementTriggers(tree.TriggerEventUpdate)
 }
@@ -591,6 +591,11 @@ func (b *Builder) resolveTableForMutation(
 		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate materialized view %q", tab.Name()))
 	}
 
+	// Routines which mutate a table depend on it, even if they don't scan it.
+	if b.trackSchemaDeps {
+		b.schemaDeps = append(b.schemaDeps, opt.SchemaDep{DataSource: tab})
+	}
+
 	return tab, depName, alias, columns
 }
 
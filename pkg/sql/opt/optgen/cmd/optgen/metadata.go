@@ -198,6 +198,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"Ordering":             {fullName: "opt.Ordering", passByVal: true},
 		"OrderingChoice":       {fullName: "props.OrderingChoice", passByVal: true},
 		"GroupingOrder":        {fullName: "memo.GroupingOrder", passByVal: true},
+		"GroupingSets":         {fullName: "memo.GroupingSets", passByVal: true},
 		"TupleOrdinal":         {fullName: "memo.TupleOrdinal", passByVal: true},
 		"ScanLimit":            {fullName: "memo.ScanLimit", passByVal: true},
 		"ScanFlags":            {fullName: "memo.ScanFlags", passByVal: true},
@@ -208,6 +209,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"StatementReturnType":  {fullName: "tree.StatementReturnType", passByVal: true},
 		"StatementType":        {fullName: "tree.StatementType", passByVal: true},
 		"ShowTraceType":        {fullName: "tree.ShowTraceType", passByVal: true},
+		"StoredProcTxnOp":      {fullName: "tree.StoredProcTxnOp", passByVal: true},
 		"ShowCompletions":      {fullName: "tree.ShowCompletions", isPointer: true, usePointerIntern: true},
 		"bool":                 {fullName: "bool", passByVal: true},
 		"int":                  {fullName: "int", passByVal: true},
@@ -225,6 +227,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"Constraint":           {fullName: "constraint.Constraint", isPointer: true, usePointerIntern: true},
 		"FuncProps":            {fullName: "tree.FunctionProperties", isPointer: true, usePointerIntern: true},
 		"FuncOverload":         {fullName: "tree.Overload", isPointer: true, usePointerIntern: true},
+		"PLpgSQLProgram":       {fullName: "plpgsqltree.Program", isPointer: true, usePointerIntern: true},
 		"PhysProps":            {fullName: "physical.Required", isPointer: true},
 		"Presentation":         {fullName: "physical.Presentation", passByVal: true},
 		"RelProps":             {fullName: "props.Relational"},
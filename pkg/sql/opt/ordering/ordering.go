@@ -260,6 +260,11 @@ func init() {
 		buildChildReqOrdering: mutationBuildChildReqOrdering,
 		buildProvidedOrdering: mutationBuildProvided,
 	}
+	funcMap[opt.MergeOp] = funcs{
+		canProvideOrdering:    mutationCanProvideOrdering,
+		buildChildReqOrdering: mutationBuildChildReqOrdering,
+		buildProvidedOrdering: mutationBuildProvided,
+	}
 	funcMap[opt.DeleteOp] = funcs{
 		canProvideOrdering:    mutationCanProvideOrdering,
 		buildChildReqOrdering: mutationBuildChildReqOrdering,
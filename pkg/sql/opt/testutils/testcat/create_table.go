@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/multiregion"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
@@ -147,6 +148,10 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 
 	}
 
+	if len(stmt.Inherits) > 0 {
+		tc.addInheritedDefs(tab, stmt)
+	}
+
 	// Find the PK columns.
 	pkCols := make(map[tree.Name]struct{})
 	for _, def := range stmt.Defs {
@@ -362,7 +367,9 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 		switch def := def.(type) {
 		case *tree.UniqueConstraintTableDef:
 			if def.WithoutIndex {
-				tab.addUniqueConstraint(def.Name, def.Columns, def.Predicate, def.WithoutIndex)
+				tab.addUniqueConstraint(
+					def.Name, def.Columns, def.Predicate, def.WithoutIndex, def.Deferrable,
+				)
 			} else if !def.PrimaryKey {
 				tab.addIndex(&def.IndexTableDef, uniqueIndex)
 			}
@@ -381,6 +388,7 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 						tree.IndexElemList{{Column: def.Name}},
 						nil, /* predicate */
 						def.Unique.WithoutIndex,
+						tree.ConstraintNotDeferrable,
 					)
 				} else {
 					tab.addIndex(
@@ -682,13 +690,18 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrable,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
 }
 
 func (tt *Table) addUniqueConstraint(
-	name tree.Name, columns tree.IndexElemList, predicate tree.Expr, withoutIndex bool,
+	name tree.Name,
+	columns tree.IndexElemList,
+	predicate tree.Expr,
+	withoutIndex bool,
+	deferrability tree.ConstraintDeferrability,
 ) {
 	// We don't currently use unique constraints with an index (those are already
 	// tracked with unique indexes), so don't bother adding them.
@@ -709,6 +722,7 @@ func (tt *Table) addUniqueConstraint(
 		columnOrdinals: cols,
 		withoutIndex:   withoutIndex,
 		validated:      true,
+		deferrability:  deferrability,
 	}
 	// Add partial unique constraint predicate.
 	if predicate != nil {
@@ -727,6 +741,49 @@ func (tt *Table) addUniqueConstraint(
 	tt.uniqueConstraints = append(tt.uniqueConstraints, u)
 }
 
+// addInheritedDefs adds the visible columns and the check constraints of the
+// parents of the table to the table definitions, and records the inheritance
+// relationship in the parents.
+func (tc *Catalog) addInheritedDefs(tab *Table, stmt *tree.CreateTable) {
+	localCols := make(map[tree.Name]struct{})
+	for _, def := range stmt.Defs {
+		if d, ok := def.(*tree.ColumnTableDef); ok {
+			localCols[d.Name] = struct{}{}
+		}
+	}
+	var inheritedDefs tree.TableDefs
+	for i := range stmt.Inherits {
+		parent := tc.Table(&stmt.Inherits[i])
+		for j := 0; j < parent.ColumnCount(); j++ {
+			col := parent.Column(j)
+			if col.Kind() != cat.Ordinary || col.Visibility() != cat.Visible {
+				continue
+			}
+			if _, ok := localCols[col.ColName()]; ok {
+				continue
+			}
+			localCols[col.ColName()] = struct{}{}
+			def := &tree.ColumnTableDef{Name: col.ColName(), Type: col.DatumType()}
+			if col.IsNullable() {
+				def.Nullable.Nullability = tree.Null
+			} else {
+				def.Nullable.Nullability = tree.NotNull
+			}
+			inheritedDefs = append(inheritedDefs, def)
+		}
+		for j := 0; j < parent.CheckCount(); j++ {
+			expr, err := parser.ParseExpr(parent.Check(j).Constraint)
+			if err != nil {
+				panic(err)
+			}
+			inheritedDefs = append(inheritedDefs, &tree.CheckConstraintTableDef{Expr: expr})
+		}
+		tab.inheritsFrom = append(tab.inheritsFrom, parent.TabID)
+		parent.inheritedBy = append(parent.inheritedBy, tab.TabID)
+	}
+	stmt.Defs = append(inheritedDefs, stmt.Defs...)
+}
+
 func (tt *Table) addColumn(def *tree.ColumnTableDef) {
 	ordinal := len(tt.Columns)
 	nullable := !def.PrimaryKey.IsPrimaryKey && def.Nullable.Nullability != tree.NotNull
@@ -849,7 +906,9 @@ func (tt *Table) addIndexWithVersion(
 ) *Index {
 	// Add a unique constraint if this is a primary or unique index.
 	if typ != nonUniqueIndex {
-		tt.addUniqueConstraint(def.Name, def.Columns, def.Predicate, false /* withoutIndex */)
+		tt.addUniqueConstraint(
+			def.Name, def.Columns, def.Predicate, false /* withoutIndex */, tree.ConstraintNotDeferrable,
+		)
 	}
 
 	idx := &Index{
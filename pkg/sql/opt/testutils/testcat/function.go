@@ -87,8 +87,8 @@ func (tc *Catalog) CreateFunction(c *tree.CreateFunction) {
 		panic(err)
 	}
 
-	// Retrieve the function body, volatility, and calledOnNullInput.
-	body, v, calledOnNullInput := collectFuncOptions(c.Options)
+	// Retrieve the function body, language, volatility, and calledOnNullInput.
+	body, lang, v, calledOnNullInput := collectFuncOptions(c.Options)
 
 	if tc.udfs == nil {
 		tc.udfs = make(map[string]*tree.ResolvedFunctionDefinition)
@@ -99,6 +99,7 @@ func (tc *Catalog) CreateFunction(c *tree.CreateFunction) {
 		ReturnType:        tree.FixedReturnType(retType),
 		IsUDF:             true,
 		Body:              body,
+		Language:          lang,
 		Volatility:        v,
 		CalledOnNullInput: calledOnNullInput,
 	}
@@ -117,7 +118,7 @@ func (tc *Catalog) CreateFunction(c *tree.CreateFunction) {
 
 func collectFuncOptions(
 	o tree.FunctionOptions,
-) (body string, v volatility.V, calledOnNullInput bool) {
+) (body string, lang tree.FunctionLanguage, v volatility.V, calledOnNullInput bool) {
 	// The default volatility is VOLATILE.
 	v = volatility.Volatile
 
@@ -154,6 +155,7 @@ func collectFuncOptions(
 			if t != tree.FunctionLangSQL && t != tree.FunctionLangPlPgSQL {
 				panic(fmt.Errorf("LANGUAGE must be SQL or plpgsql"))
 			}
+			lang = t
 
 		default:
 			ctx := tree.NewFmtCtx(tree.FmtSimple)
@@ -168,7 +170,7 @@ func collectFuncOptions(
 		panic(fmt.Errorf("LEAKPROOF functions must be IMMUTABLE"))
 	}
 
-	return body, v, calledOnNullInput
+	return body, lang, v, calledOnNullInput
 }
 
 // formatFunction nicely formats a function definition creating in the opt test
@@ -689,6 +689,11 @@ type Table struct {
 
 	uniqueConstraints []UniqueConstraint
 
+	// inheritsFrom and inheritedBy contain the IDs of the parents and children
+	// of the table, created with CREATE TABLE ... INHERITS.
+	inheritsFrom []cat.StableID
+	inheritedBy  []cat.StableID
+
 	// partitionBy is the partitioning clause that corresponds to the primary
 	// index. Used to initialize the partitioning for the primary index.
 	partitionBy *tree.PartitionBy
@@ -809,6 +814,16 @@ func (tt *Table) Check(i int) cat.CheckConstraint {
 	return tt.Checks[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (tt *Table) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (tt *Table) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
 // FamilyCount is part of the cat.Table interface.
 func (tt *Table) FamilyCount() int {
 	return len(tt.Families)
@@ -849,6 +864,31 @@ func (tt *Table) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	return &tt.uniqueConstraints[i]
 }
 
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (tt *Table) ExclusionConstraintCount() int {
+	return 0
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (tt *Table) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
+// InheritanceParentCount is part of the cat.Table interface.
+func (tt *Table) InheritanceParentCount() int {
+	return len(tt.inheritsFrom)
+}
+
+// InheritanceChildCount is part of the cat.Table interface.
+func (tt *Table) InheritanceChildCount() int {
+	return len(tt.inheritedBy)
+}
+
+// InheritanceChild is part of the cat.Table interface.
+func (tt *Table) InheritanceChild(i int) cat.StableID {
+	return tt.inheritedBy[i]
+}
+
 // Zone is part of the cat.Table interface.
 func (tt *Table) Zone() cat.Zone {
 	zone := zonepb.DefaultZoneConfig()
@@ -943,7 +983,7 @@ func (tt *Table) CollectTypes(ord int) (descpb.IDs, error) {
 		}
 	}
 	if col.DatumType() != nil && col.DatumType().UserDefined() {
-		visitor.OIDs[col.DatumType().Oid()] = struct{}{}
+		visitor.OIDs[col.DatumType().UserDefinedOID()] = struct{}{}
 	}
 
 	ids := make(descpb.IDs, 0, len(visitor.OIDs))
@@ -1340,10 +1380,11 @@ type ForeignKeyConstraint struct {
 	originColumnOrdinals     []int
 	referencedColumnOrdinals []int
 
-	validated    bool
-	matchMethod  tree.CompositeKeyMatchMethod
-	deleteAction tree.ReferenceAction
-	updateAction tree.ReferenceAction
+	validated     bool
+	matchMethod   tree.CompositeKeyMatchMethod
+	deleteAction  tree.ReferenceAction
+	updateAction  tree.ReferenceAction
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &ForeignKeyConstraint{}
@@ -1411,6 +1452,11 @@ func (fk *ForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction {
 	return fk.updateAction
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return fk.deferrability
+}
+
 // UniqueConstraint implements cat.UniqueConstraint. See that interface
 // for more information on the fields.
 type UniqueConstraint struct {
@@ -1420,6 +1466,7 @@ type UniqueConstraint struct {
 	predicate      string
 	withoutIndex   bool
 	validated      bool
+	deferrability  tree.ConstraintDeferrability
 }
 
 var _ cat.UniqueConstraint = &UniqueConstraint{}
@@ -1465,6 +1512,11 @@ func (u *UniqueConstraint) Validated() bool {
 	return u.validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *UniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return u.deferrability
+}
+
 // UniquenessGuaranteedByAnotherIndex is part of the cat.UniqueConstraint
 // interface.
 func (u *UniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/indexrec"
@@ -725,6 +726,8 @@ type optTable struct {
 
 	uniqueConstraints []optUniqueConstraint
 
+	exclusionConstraints []optExclusionConstraint
+
 	outboundFKs []optForeignKeyConstraint
 	inboundFKs  []optForeignKeyConstraint
 
@@ -733,6 +736,9 @@ type optTable struct {
 	// constraints for user defined types.
 	checkConstraints []cat.CheckConstraint
 
+	// triggers is the set of triggers defined on this table.
+	triggers []cat.Trigger
+
 	// colMap is a mapping from unique ColumnID to column ordinal within the
 	// table. This is a common lookup that needs to be fast.
 	colMap catalog.TableColMap
@@ -799,7 +805,7 @@ func newOptTable(
 				col.GetType(),
 				col.IsNullable(),
 				visibility,
-				cd.DefaultExpr,
+				columnDefaultExpr(col),
 				cd.ComputeExpr,
 				cd.OnUpdateExpr,
 				mapGeneratedAsIdentityType(col.GetGeneratedAsIdentityType()),
@@ -872,6 +878,9 @@ func newOptTable(
 			predicate:    u.GetPredicate(),
 			withoutIndex: true,
 			validity:     u.GetConstraintValidity(),
+			deferrability: tree.MakeConstraintDeferrability(
+				u.IsDeferrable(), u.IsInitiallyDeferred(),
+			),
 		}
 	}
 
@@ -963,6 +972,31 @@ func newOptTable(
 				})
 			}
 		}
+
+		// Exclusion constraints are enforced as soon as their index receives
+		// writes, so that rows written after the schema changer validates the
+		// constraint cannot violate it.
+		if idx.IsExclusion() && !idx.IsTemporaryIndexForBackfill() &&
+			!idx.DeleteOnly() && !idx.Dropped() {
+			operators := make([]treecmp.ComparisonOperatorSymbol, idx.NumKeyColumns())
+			for j := range operators {
+				switch op := idx.GetExclusionOperator(j); op {
+				case "=":
+					operators[j] = treecmp.EQ
+				case "&&":
+					operators[j] = treecmp.Overlaps
+				default:
+					return nil, errors.AssertionFailedf("unknown exclusion operator %q", op)
+				}
+			}
+			ot.exclusionConstraints = append(ot.exclusionConstraints, optExclusionConstraint{
+				name:      idx.GetName(),
+				table:     ot.ID(),
+				columns:   idx.IndexDesc().KeyColumnIDs,
+				operators: operators,
+				predicate: idx.GetPredicate(),
+			})
+		}
 	}
 
 	for _, fk := range ot.desc.OutboundForeignKeys() {
@@ -976,6 +1010,7 @@ func newOptTable(
 			match:             tree.CompositeKeyMatchMethodType[fk.Match()],
 			deleteAction:      tree.ForeignKeyReferenceActionType[fk.OnDelete()],
 			updateAction:      tree.ForeignKeyReferenceActionType[fk.OnUpdate()],
+			deferrability:     tree.MakeConstraintDeferrability(fk.IsDeferrable(), fk.IsInitiallyDeferred()),
 		})
 	}
 	for _, fk := range ot.desc.InboundForeignKeys() {
@@ -989,6 +1024,7 @@ func newOptTable(
 			match:             tree.CompositeKeyMatchMethodType[fk.Match()],
 			deleteAction:      tree.ForeignKeyReferenceActionType[fk.OnDelete()],
 			updateAction:      tree.ForeignKeyReferenceActionType[fk.OnUpdate()],
+			deferrability:     tree.MakeConstraintDeferrability(fk.IsDeferrable(), fk.IsInitiallyDeferred()),
 		})
 	}
 
@@ -999,29 +1035,9 @@ func newOptTable(
 	}
 
 	// Synthesize any check constraints for user defined types.
-	var synthesizedChecks []cat.CheckConstraint
-	for i := 0; i < ot.ColumnCount(); i++ {
-		col := ot.Column(i)
-		if col.IsMutation() {
-			// We do not synthesize check constraints for mutation columns.
-			continue
-		}
-		colType := col.DatumType()
-		if colType.UserDefined() {
-			switch colType.Family() {
-			case types.EnumFamily:
-				// We synthesize an (x IN (v1, v2, v3...)) check for enum types.
-				expr := &tree.ComparisonExpr{
-					Operator: treecmp.MakeComparisonOperator(treecmp.In),
-					Left:     &tree.ColumnItem{ColumnName: col.ColName()},
-					Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
-				}
-				synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
-					Constraint: tree.Serialize(expr),
-					Validated:  true,
-				})
-			}
-		}
+	synthesizedChecks, err := synthesizeCheckConstraints(desc)
+	if err != nil {
+		return nil, err
 	}
 	// Move all existing and synthesized checks into the opt table.
 	activeChecks := desc.EnforcedCheckConstraints()
@@ -1032,7 +1048,37 @@ func newOptTable(
 			Validated:  activeChecks[i].GetConstraintValidity() == descpb.ConstraintValidity_Validated,
 		})
 	}
-	ot.checkConstraints = append(ot.checkConstraints, synthesizedChecks...)
+	for i := range synthesizedChecks {
+		ot.checkConstraints = append(ot.checkConstraints, synthesizedChecks[i].CheckConstraint)
+	}
+
+	// Add the triggers.
+	if descTriggers := desc.GetTriggers(); len(descTriggers) > 0 {
+		ot.triggers = make([]cat.Trigger, len(descTriggers))
+		for i := range descTriggers {
+			t := &descTriggers[i]
+			trigger := cat.Trigger{
+				Name:       tree.Name(t.Name),
+				ActionTime: tree.TriggerActionTime(t.ActionTime),
+				ForEachRow: t.ForEachRow,
+				WhenExpr:   t.WhenExpr,
+				FuncOID:    catid.FuncIDToOID(t.FuncID),
+			}
+			for j := range t.Events {
+				event := cat.TriggerEvent{EventType: tree.TriggerEventType(t.Events[j].Type)}
+				for _, colID := range t.Events[j].ColumnIDs {
+					ord, ok := ot.colMap.Get(colID)
+					if !ok {
+						return nil, errors.AssertionFailedf(
+							"column %d referenced by trigger %q does not exist", colID, t.Name)
+					}
+					event.ColumnOrdinals = append(event.ColumnOrdinals, ord)
+				}
+				trigger.Events = append(trigger.Events, event)
+			}
+			ot.triggers[i] = trigger
+		}
+	}
 
 	// Add stats last, now that other metadata is initialized.
 	if stats != nil {
@@ -1052,6 +1098,84 @@ func newOptTable(
 	return ot, nil
 }
 
+// synthesizedCheck is a check constraint synthesized for a column of a
+// user-defined type.
+type synthesizedCheck struct {
+	cat.CheckConstraint
+	// name is the name of the domain constraint the check was synthesized for,
+	// if any.
+	name string
+}
+
+// synthesizeCheckConstraints returns the check constraints synthesized for the
+// public columns of user-defined types of the given table. They follow the
+// check constraints of the table in the optimizer catalog, so the ordinals of
+// the check columns of mutations beyond the table's own checks index into the
+// returned slice.
+func synthesizeCheckConstraints(desc catalog.TableDescriptor) ([]synthesizedCheck, error) {
+	var checks []synthesizedCheck
+	for _, col := range desc.PublicColumns() {
+		colType := col.GetType()
+		if dd := colType.TypeMeta.DomainData; dd != nil {
+			// We synthesize the NOT NULL and CHECK constraints of domains.
+			if dd.NotNull {
+				expr := &tree.IsNotNullExpr{Expr: &tree.ColumnItem{ColumnName: col.ColName()}}
+				checks = append(checks, synthesizedCheck{
+					CheckConstraint: cat.CheckConstraint{
+						Constraint: tree.Serialize(expr),
+						Validated:  true,
+					},
+				})
+			}
+			for _, c := range dd.Checks {
+				expr, err := schemaexpr.DomainCheckExprForColumn(c.Expr, col.ColName())
+				if err != nil {
+					return nil, err
+				}
+				checks = append(checks, synthesizedCheck{
+					CheckConstraint: cat.CheckConstraint{
+						Constraint: expr,
+						Validated:  true,
+					},
+					name: c.Name,
+				})
+			}
+			continue
+		}
+		if colType.UserDefined() {
+			switch colType.Family() {
+			case types.EnumFamily:
+				// We synthesize an (x IN (v1, v2, v3...)) check for enum types.
+				expr := &tree.ComparisonExpr{
+					Operator: treecmp.MakeComparisonOperator(treecmp.In),
+					Left:     &tree.ColumnItem{ColumnName: col.ColName()},
+					Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
+				}
+				checks = append(checks, synthesizedCheck{
+					CheckConstraint: cat.CheckConstraint{
+						Constraint: tree.Serialize(expr),
+						Validated:  true,
+					},
+				})
+			}
+		}
+	}
+	return checks, nil
+}
+
+// columnDefaultExpr returns the default expression of the given column. If the
+// column has no default expression of its own, the default expression of its
+// domain is returned, if any.
+func columnDefaultExpr(col catalog.Column) *string {
+	if col.HasDefault() {
+		return col.ColumnDesc().DefaultExpr
+	}
+	if dd := col.GetType().TypeMeta.DomainData; dd != nil {
+		return dd.DefaultExpr
+	}
+	return nil
+}
+
 // ID is part of the cat.Object interface.
 func (ot *optTable) ID() cat.StableID {
 	return cat.StableID(ot.desc.GetID())
@@ -1216,6 +1340,16 @@ func (ot *optTable) Check(i int) cat.CheckConstraint {
 	return ot.checkConstraints[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	return ot.triggers[i]
+}
+
 // FamilyCount is part of the cat.Table interface.
 func (ot *optTable) FamilyCount() int {
 	return 1 + len(ot.families)
@@ -1259,6 +1393,31 @@ func (ot *optTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	return &ot.uniqueConstraints[i]
 }
 
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraintCount() int {
+	return len(ot.exclusionConstraints)
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	return &ot.exclusionConstraints[i]
+}
+
+// InheritanceParentCount is part of the cat.Table interface.
+func (ot *optTable) InheritanceParentCount() int {
+	return len(ot.desc.GetInheritsFrom())
+}
+
+// InheritanceChildCount is part of the cat.Table interface.
+func (ot *optTable) InheritanceChildCount() int {
+	return len(ot.desc.GetInheritedBy())
+}
+
+// InheritanceChild is part of the cat.Table interface.
+func (ot *optTable) InheritanceChild(i int) cat.StableID {
+	return cat.StableID(ot.desc.GetInheritedBy()[i])
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optTable) Zone() cat.Zone {
 	return ot.zone
@@ -1843,8 +2002,9 @@ type optUniqueConstraint struct {
 	columns   []descpb.ColumnID
 	predicate string
 
-	withoutIndex bool
-	validity     descpb.ConstraintValidity
+	withoutIndex  bool
+	validity      descpb.ConstraintValidity
+	deferrability tree.ConstraintDeferrability
 
 	uniquenessGuaranteedByAnotherIndex bool
 }
@@ -1894,6 +2054,11 @@ func (u *optUniqueConstraint) Validated() bool {
 	return u.validity == descpb.ConstraintValidity_Validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return u.deferrability
+}
+
 // UniquenessGuaranteedByAnotherIndex is part of the cat.UniqueConstraint
 // interface. It is a hack to make unique hash sharded index work before issue
 // #75070 is resolved. Be sure to remove `ignoreUniquenessCheck` field from
@@ -1902,6 +2067,52 @@ func (u *optUniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
 	return u.uniquenessGuaranteedByAnotherIndex
 }
 
+// optExclusionConstraint implements cat.ExclusionConstraint and represents an
+// exclusion constraint backed by an index.
+type optExclusionConstraint struct {
+	name string
+
+	table     cat.StableID
+	columns   []descpb.ColumnID
+	operators []treecmp.ComparisonOperatorSymbol
+	predicate string
+}
+
+var _ cat.ExclusionConstraint = &optExclusionConstraint{}
+
+// Name is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Name() string {
+	return e.name
+}
+
+// ColumnCount is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnCount() int {
+	return len(e.columns)
+}
+
+// ColumnOrdinal is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnOrdinal(tab cat.Table, i int) int {
+	if tab.ID() != e.table {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ColumnOrdinal (expected %d)",
+			tab.ID(), e.table,
+		))
+	}
+	optTab := convertTableToOptTable(tab)
+	ord, _ := optTab.lookupColumnOrdinal(e.columns[i])
+	return ord
+}
+
+// Operator is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Operator(i int) treecmp.ComparisonOperatorSymbol {
+	return e.operators[i]
+}
+
+// Predicate is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Predicate() (string, bool) {
+	return e.predicate, e.predicate != ""
+}
+
 // optForeignKeyConstraint implements cat.ForeignKeyConstraint and represents a
 // foreign key relationship. Both the origin and the referenced table store the
 // same optForeignKeyConstraint (as an outbound and inbound reference,
@@ -1915,10 +2126,11 @@ type optForeignKeyConstraint struct {
 	referencedTable   cat.StableID
 	referencedColumns []descpb.ColumnID
 
-	validity     descpb.ConstraintValidity
-	match        tree.CompositeKeyMatchMethod
-	deleteAction tree.ReferenceAction
-	updateAction tree.ReferenceAction
+	validity      descpb.ConstraintValidity
+	match         tree.CompositeKeyMatchMethod
+	deleteAction  tree.ReferenceAction
+	updateAction  tree.ReferenceAction
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1990,6 +2202,11 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return fk.updateAction
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return fk.deferrability
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc catalog.TableDescriptor
@@ -2248,6 +2465,16 @@ func (ot *optVirtualTable) Check(i int) cat.CheckConstraint {
 	}
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
 // FamilyCount is part of the cat.Table interface.
 func (ot *optVirtualTable) FamilyCount() int {
 	return 1
@@ -2288,6 +2515,31 @@ func (ot *optVirtualTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("no unique constraints"))
 }
 
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraintCount() int {
+	return 0
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
+// InheritanceParentCount is part of the cat.Table interface.
+func (ot *optVirtualTable) InheritanceParentCount() int {
+	return 0
+}
+
+// InheritanceChildCount is part of the cat.Table interface.
+func (ot *optVirtualTable) InheritanceChildCount() int {
+	return 0
+}
+
+// InheritanceChild is part of the cat.Table interface.
+func (ot *optVirtualTable) InheritanceChild(i int) cat.StableID {
+	panic(errors.AssertionFailedf("no inheritance children"))
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optVirtualTable) Zone() cat.Zone {
 	panic(errors.AssertionFailedf("no zone"))
@@ -2596,7 +2848,7 @@ func collectTypes(col catalog.Column) (descpb.IDs, error) {
 		}
 	}
 	if typ := col.GetType(); typ != nil && typ.UserDefined() {
-		visitor.OIDs[typ.Oid()] = struct{}{}
+		visitor.OIDs[typ.UserDefinedOID()] = struct{}{}
 	}
 
 	ids := make(descpb.IDs, 0, len(visitor.OIDs))
@@ -628,6 +628,35 @@ func (ef *execFactory) ConstructOrdinality(input exec.Node, colName string) (exe
 	}, nil
 }
 
+// ConstructExpand is part of the exec.Factory interface.
+func (ef *execFactory) ConstructExpand(
+	input exec.Node,
+	groupingCols []exec.NodeColumnOrdinal,
+	groupingSets []exec.NodeColumnOrdinalSet,
+) (exec.Node, error) {
+	plan := input.(planNode)
+	inputColumns := planColumns(plan)
+	n := &expandNode{
+		source:       plan,
+		groupingCols: groupingCols,
+		groupingSets: groupingSets,
+	}
+	cols := make(colinfo.ResultColumns, 0, len(inputColumns)+len(groupingCols)+2)
+	cols = append(cols, inputColumns...)
+	for _, col := range groupingCols {
+		cols = append(cols, colinfo.ResultColumn{
+			Name: inputColumns[col].Name,
+			Typ:  inputColumns[col].Typ,
+		})
+	}
+	cols = append(cols, colinfo.ResultColumn{Name: "grouping_id", Typ: types.Int})
+	if n.hasEmptyGroupingSet() {
+		cols = append(cols, colinfo.ResultColumn{Name: "input_row", Typ: types.Bool})
+	}
+	n.columns = cols
+	return n, nil
+}
+
 // ConstructIndexJoin is part of the exec.Factory interface.
 func (ef *execFactory) ConstructIndexJoin(
 	input exec.Node,
@@ -1680,6 +1709,138 @@ func (ef *execFactory) ConstructUpsert(
 	return &rowCountNode{source: ups}, nil
 }
 
+func (ef *execFactory) ConstructMerge(
+	input exec.Node,
+	table cat.Table,
+	actionCol exec.NodeColumnOrdinal,
+	insertColOrdSet exec.TableColumnOrdinalSet,
+	fetchColOrdSet exec.TableColumnOrdinalSet,
+	updateColOrdSet exec.TableColumnOrdinalSet,
+	returnColOrdSet exec.TableColumnOrdinalSet,
+	checks exec.CheckOrdinalSet,
+	passthrough colinfo.ResultColumns,
+	autoCommit bool,
+) (exec.Node, error) {
+	// Derive table and column descriptors.
+	rowsNeeded := !returnColOrdSet.Empty()
+	tabDesc := table.(*optTable).desc
+	insertCols := makeColList(table, insertColOrdSet)
+	fetchCols := makeColList(table, fetchColOrdSet)
+	updateCols := makeColList(table, updateColOrdSet)
+
+	// Create the table inserter, updater and deleter, which do the bulk of the
+	// work for each of the actions. A MERGE without an INSERT action has no
+	// insert columns, so its inserter only provides the row helper.
+	internal := ef.planner.SessionData().Internal
+	var ri row.Inserter
+	if insertColOrdSet.Empty() {
+		ri.Helper = row.NewRowHelper(
+			ef.planner.ExecCfg().Codec,
+			tabDesc,
+			tabDesc.WritableNonPrimaryIndexes(),
+			&ef.planner.ExecCfg().Settings.SV,
+			internal,
+			ef.planner.ExecCfg().GetRowMetrics(internal),
+		)
+	} else {
+		var err error
+		ri, err = row.MakeInserter(
+			ef.ctx,
+			ef.planner.txn,
+			ef.planner.ExecCfg().Codec,
+			tabDesc,
+			insertCols,
+			ef.getDatumAlloc(),
+			&ef.planner.ExecCfg().Settings.SV,
+			internal,
+			ef.planner.ExecCfg().GetRowMetrics(internal),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ru, err := row.MakeUpdater(
+		ef.ctx,
+		ef.planner.txn,
+		ef.planner.ExecCfg().Codec,
+		tabDesc,
+		updateCols,
+		fetchCols,
+		row.UpdaterDefault,
+		ef.getDatumAlloc(),
+		&ef.planner.ExecCfg().Settings.SV,
+		internal,
+		ef.planner.ExecCfg().GetRowMetrics(internal),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rd := row.MakeDeleter(
+		ef.planner.ExecCfg().Codec,
+		tabDesc,
+		fetchCols,
+		&ef.planner.ExecCfg().Settings.SV,
+		internal,
+		ef.planner.ExecCfg().GetRowMetrics(internal),
+	)
+
+	// Instantiate the merge node.
+	mrg := mergeNodePool.Get().(*mergeNode)
+	*mrg = mergeNode{
+		source: input.(planNode),
+		run: mergeRun{
+			checkOrds:      checks,
+			insertCols:     ri.InsertCols,
+			numPassthrough: len(passthrough),
+			tw: optTableMerger{
+				optTableUpserter: optTableUpserter{
+					ri:            ri,
+					canaryOrdinal: -1,
+					fetchCols:     fetchCols,
+					updateCols:    updateCols,
+					ru:            ru,
+				},
+				rd:            rd,
+				actionOrdinal: int(actionCol),
+				passthrough:   passthrough,
+			},
+		},
+	}
+
+	// If rows are not needed, no columns are returned.
+	if rowsNeeded {
+		returnCols := makeColList(table, returnColOrdSet)
+		mrg.columns = colinfo.ResultColumnsFromColumns(tabDesc.GetID(), returnCols)
+
+		// Add the passthrough columns to the returning columns.
+		mrg.columns = append(mrg.columns, passthrough...)
+
+		// Update the tabColIdxToRetIdx for the mutation. Merge returns
+		// non-mutation columns specified, in the same order they are defined
+		// in the table, followed by the passthrough columns.
+		mrg.run.tw.tabColIdxToRetIdx = makePublicToReturnColumnIndexMapping(tabDesc, returnCols)
+		mrg.run.tw.returnCols = returnCols
+		mrg.run.tw.rowsNeeded = true
+	}
+
+	if autoCommit {
+		mrg.enableAutoCommit()
+	}
+
+	// Serialize the data-modifying plan to ensure that no data is observed that
+	// hasn't been validated first. See the comments on BatchedNext() in
+	// plan_batch.go.
+	if rowsNeeded {
+		return &spoolNode{source: &serializeNode{source: mrg}}, nil
+	}
+
+	// We could use serializeNode here, but using rowCountNode is an
+	// optimization that saves on calls to Next() by the caller.
+	return &rowCountNode{source: mrg}, nil
+}
+
 func (ef *execFactory) ConstructDelete(
 	input exec.Node,
 	table cat.Table,
@@ -1824,6 +1985,7 @@ func (ef *execFactory) ConstructCreateView(
 	deps opt.SchemaDeps,
 	typeDeps opt.SchemaTypeDeps,
 	withData bool,
+	incremental bool,
 ) (exec.Node, error) {
 
 	if err := checkSchemaChangeEnabled(
@@ -1851,6 +2013,7 @@ func (ef *execFactory) ConstructCreateView(
 		planDeps:     planDeps,
 		typeDeps:     typeDepSet,
 		withData:     withData,
+		incremental:  incremental,
 	}, nil
 }
 
@@ -1889,6 +2052,27 @@ func (ef *execFactory) ConstructCreateFunction(
 	}, nil
 }
 
+// ConstructCall is part of the exec.Factory interface.
+func (ef *execFactory) ConstructCall(
+	proc tree.TypedExpr,
+	cols colinfo.ResultColumns,
+	overload *tree.Overload,
+	txnOp tree.StoredProcTxnOp,
+	resumeAt int,
+) (exec.Node, error) {
+	routine, ok := proc.(*tree.RoutineExpr)
+	if !ok {
+		return nil, errors.AssertionFailedf("expected RoutineExpr, found %T", proc)
+	}
+	return &callNode{
+		proc:     routine,
+		columns:  cols,
+		procOID:  overload.Oid,
+		txnOp:    txnOp,
+		resumeAt: resumeAt,
+	}, nil
+}
+
 func toPlanDependencies(
 	deps opt.SchemaDeps, typeDeps opt.SchemaTypeDeps,
 ) (planDependencies, typeDependencies, error) {
@@ -1937,11 +2121,12 @@ func (ef *execFactory) ConstructSaveTable(
 
 // ConstructErrorIfRows is part of the exec.Factory interface.
 func (ef *execFactory) ConstructErrorIfRows(
-	input exec.Node, mkErr exec.MkErrFn,
+	input exec.Node, mkErr exec.MkErrFn, deferrable *exec.DeferrableConstraint,
 ) (exec.Node, error) {
 	return &errorIfRowsNode{
-		plan:  input.(planNode),
-		mkErr: mkErr,
+		plan:       input.(planNode),
+		mkErr:      mkErr,
+		deferrable: deferrable,
 	}, nil
 }
 
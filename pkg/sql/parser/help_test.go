@@ -73,6 +73,10 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER TYPE t RENAME ??`, `ALTER TYPE`},
 		{`ALTER TYPE t DROP VALUE ??`, `ALTER TYPE`},
 
+		{`ALTER DOMAIN ??`, `ALTER DOMAIN`},
+		{`ALTER DOMAIN d SET ??`, `ALTER DOMAIN`},
+		{`ALTER DOMAIN d DROP ??`, `ALTER DOMAIN`},
+
 		{`ALTER INDEX foo@bar RENAME ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar RENAME TO blih ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar SPLIT ??`, `ALTER INDEX`},
@@ -175,6 +179,11 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
 
+		{`CREATE DOMAIN ??`, `CREATE DOMAIN`},
+		{`CREATE DOMAIN d AS ??`, `CREATE DOMAIN`},
+		{`DROP DOMAIN ??`, `DROP DOMAIN`},
+		{`DROP DOMAIN IF ??`, `DROP DOMAIN`},
+
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA bli ??`, `CREATE SCHEMA`},
@@ -270,6 +279,10 @@ func TestContextualHelp(t *testing.T) {
 		{`INSERT INTO blah VALUES (1) ??`, `VALUES`},
 		{`INSERT INTO blah TABLE foo ??`, `TABLE`},
 
+		{`MERGE ??`, `MERGE`},
+		{`MERGE INTO blah USING foo ON true ??`, `MERGE`},
+		{`MERGE INTO blah USING foo ON true WHEN MATCHED THEN ??`, `MERGE`},
+
 		{`UPSERT INTO ??`, `UPSERT`},
 		{`UPSERT INTO blah (??`, `<SELECTCLAUSE>`},
 		{`UPSERT INTO blah VALUES (1) RETURNING ??`, `UPSERT`},
@@ -471,6 +484,7 @@ func TestContextualHelp(t *testing.T) {
 		{`SET LOCAL TIME ??`, `SET LOCAL`},
 		{`SET LOCAL TIME ZONE 'UTC' ??`, `SET LOCAL`},
 
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
 		{`SET TIME ??`, `SET SESSION`},
@@ -529,6 +543,13 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
 		{`ALTER FUNCTION ??`, `ALTER FUNCTION`},
 		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+
+		{`CREATE PROCEDURE ??`, `CREATE PROCEDURE`},
+		{`DROP PROCEDURE ??`, `DROP PROCEDURE`},
+		{`CALL ??`, `CALL`},
+
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`DROP TRIGGER ??`, `DROP TRIGGER`},
 	}
 
 	// The following checks that the test definition above exercises all
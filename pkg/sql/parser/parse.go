@@ -70,17 +70,19 @@ type Statement struct {
 	NumAnnotations tree.AnnotationIdx
 }
 
-// IsANSIDML returns true if the AST is one of the 4 DML statements,
-// SELECT, UPDATE, INSERT, DELETE, or an EXPLAIN of one of these statements.
+// IsANSIDML returns true if the AST is one of the 5 DML statements,
+// SELECT, UPDATE, INSERT, DELETE, MERGE, or an EXPLAIN of one of these
+// statements.
 func (stmt Statement) IsANSIDML() bool {
 	return IsANSIDML(stmt.AST)
 }
 
-// IsANSIDML returns true if the AST is one of the 4 DML statements,
-// SELECT, UPDATE, INSERT, DELETE, or an EXPLAIN of one of these statements.
+// IsANSIDML returns true if the AST is one of the 5 DML statements,
+// SELECT, UPDATE, INSERT, DELETE, MERGE, or an EXPLAIN of one of these
+// statements.
 func IsANSIDML(stmt tree.Statement) bool {
 	switch t := stmt.(type) {
-	case *tree.Select, *tree.ParenSelect, *tree.Delete, *tree.Insert, *tree.Update:
+	case *tree.Select, *tree.ParenSelect, *tree.Delete, *tree.Insert, *tree.Merge, *tree.Update:
 		return true
 	case *tree.Explain:
 		return IsANSIDML(t.Statement)
@@ -539,6 +541,9 @@ func arrayOf(
 		if typ.Family() == types.VoidFamily {
 			return nil, pgerror.Newf(pgcode.UndefinedObject, "type void[] does not exist")
 		}
+		if typ.Family() == types.TriggerFamily {
+			return nil, pgerror.Newf(pgcode.UndefinedObject, "type trigger[] does not exist")
+		}
 		if err := types.CheckArrayElementType(typ); err != nil {
 			return nil, err
 		}
@@ -308,6 +308,24 @@ func (u *sqlSymUnion) enumValueList() tree.EnumValueList {
 func (u *sqlSymUnion) compositeTypeList() []tree.CompositeTypeElem {
     return u.val.([]tree.CompositeTypeElem)
 }
+func (u *sqlSymUnion) rangeTypeParams() []tree.RangeTypeParam {
+    return u.val.([]tree.RangeTypeParam)
+}
+func (u *sqlSymUnion) domainConstraint() tree.DomainConstraint {
+    return u.val.(tree.DomainConstraint)
+}
+func (u *sqlSymUnion) domainConstraints() []tree.DomainConstraint {
+    return u.val.([]tree.DomainConstraint)
+}
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() *tree.TriggerEvent {
+    return u.val.(*tree.TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() []*tree.TriggerEvent {
+    return u.val.([]*tree.TriggerEvent)
+}
 func (u *sqlSymUnion) unresolvedName() *tree.UnresolvedName {
     return u.val.(*tree.UnresolvedName)
 }
@@ -515,6 +533,12 @@ func (u *sqlSymUnion) updateExpr() *tree.UpdateExpr {
 func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
     return u.val.(tree.UpdateExprs)
 }
+func (u *sqlSymUnion) mergeWhen() *tree.MergeWhen {
+    return u.val.(*tree.MergeWhen)
+}
+func (u *sqlSymUnion) mergeWhens() tree.MergeWhens {
+    return u.val.(tree.MergeWhens)
+}
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
@@ -629,6 +653,12 @@ func (u *sqlSymUnion) idxElem() tree.IndexElem {
 func (u *sqlSymUnion) idxElems() tree.IndexElemList {
     return u.val.(tree.IndexElemList)
 }
+func (u *sqlSymUnion) exclusionElem() tree.ExclusionConstraintElem {
+    return u.val.(tree.ExclusionConstraintElem)
+}
+func (u *sqlSymUnion) exclusionElems() tree.ExclusionConstraintElems {
+    return u.val.(tree.ExclusionConstraintElems)
+}
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
@@ -716,6 +746,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -888,7 +921,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CALLED CANCEL CANCELQUERY CAPABILITIES CAPABILITY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALL CALLED CANCEL CANCELQUERY CAPABILITIES CAPABILITY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CHECK_FILES CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE COMPLETIONS CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -902,7 +935,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED DETAILS
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_INFO_DIR ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTED ENCRYPTION_INFO_DIR ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT EXPERIMENTAL_RELOCATE
@@ -924,7 +957,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE
 %token <str> INCLUDING INCLUDE_ALL_SECONDARY_TENANTS INCREMENT INCREMENTAL INCREMENTAL_LOCATION
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
-%token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INITIALLY
+%token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERIT INHERITS INJECT INITIALLY
 %token <str> INDEX_BEFORE_PAREN INDEX_BEFORE_NAME_THEN_PAREN INDEX_AFTER_ORDER_BY_BEFORE_AT
 %token <str> INNER INOUT INPUT INSENSITIVE INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED INVOKER IS ISERROR ISNULL ISOLATION
@@ -936,9 +969,9 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> LABEL LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEAKPROOF LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LISTEN LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MODIFYSQLCLUSTERSETTING MONTH MOVE
+%token <str> MATCH MATCHED MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MODIFYSQLCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
@@ -947,7 +980,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> NOCONTROLJOB NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING
 %token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT
 %token <str> NOTHING NOTHING_AFTER_RETURNING
-%token <str> NOTNULL
+%token <str> NOTIFY NOTNULL
 %token <str> NOVIEWACTIVITY NOVIEWACTIVITYREDACTED NOVIEWCLUSTERSETTING NOWAIT NULL NULLIF NULLS NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR OLD_KMS ON ONLY OPT OPTION OPTIONS OR
@@ -956,11 +989,11 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> PARALLEL PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
-%token <str> PROCEDURAL PUBLIC PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY QUOTE
 
-%token <str> RANGE RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
+%token <str> RANGE RANGE_ADJACENT RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGNAMESPACE REGPROC REGPROCEDURE REGROLE REGTYPE REINDEX
 %token <str> RELATIVE RELOCATE REMOVE_PATH RENAME REPEATABLE REPLACE REPLICATION
 %token <str> RELEASE RESET RESTART RESTORE RESTRICT RESTRICTED RESUME RETENTION RETURNING RETURN RETURNS RETRY REVISION_HISTORY
@@ -973,7 +1006,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SKIP_MISSING_UDFS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 %token <str> SQLLOGIN
 %token <str> STABLE START STATE STATISTICS STATUS STDIN STDOUT STOP STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING SUPER
-%token <str> SUPPORT SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
+%token <str> SUPPORT SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENT STATEMENTS
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TENANT_NAME TENANTS TESTING_RELOCATE TEXT THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
@@ -1012,8 +1045,10 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 // references.
 // - TENANT_ALL is used to differentiate `ALTER TENANT <id>` from
 // `ALTER TENANT ALL`.
+// - NOT_DEFERRABLE is used to differentiate NOT DEFERRABLE from both NOT NULL
+// column qualifications and NOT LIKE/IN/BETWEEN expressions.
 %token NOT_LA NULLS_LA WITH_LA AS_LA GENERATED_ALWAYS GENERATED_BY_DEFAULT RESET_ALL ROLE_ALL
-%token USER_ALL ON_LA TENANT_ALL SET_TRACING
+%token USER_ALL ON_LA TENANT_ALL SET_TRACING NOT_DEFERRABLE
 
 %union {
   id    int32
@@ -1040,6 +1075,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.Statement> alter_role_stmt
 %type <*tree.SetVar> set_or_reset_clause
 %type <tree.Statement> alter_type_stmt
+%type <tree.Statement> alter_domain_stmt
 %type <tree.Statement> alter_schema_stmt
 %type <tree.Statement> alter_unsupported_stmt
 %type <tree.Statement> alter_func_stmt
@@ -1131,6 +1167,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 
 %type <tree.Statement> backup_stmt
 %type <tree.Statement> begin_stmt
+%type <tree.Statement> call_stmt
 
 %type <tree.Statement> cancel_stmt
 %type <tree.Statement> cancel_jobs_stmt
@@ -1168,6 +1205,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 %type <tree.Statement> create_func_stmt
+%type <tree.Statement> create_proc_stmt
 
 %type <tree.Statement> create_stats_stmt
 %type <*tree.CreateStatsOptions> opt_create_stats_options
@@ -1175,6 +1213,8 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_domain_stmt
+%type <tree.Statement> create_trigger_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
 
@@ -1187,9 +1227,12 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_domain_stmt
+%type <tree.Statement> drop_trigger_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 %type <tree.Statement> drop_func_stmt
+%type <tree.Statement> drop_proc_stmt
 %type <tree.Statement> drop_tenant_stmt
 %type <bool>           opt_immediate
 
@@ -1228,6 +1271,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt set_or_reset_csetting_stmt
 %type <tree.Statement> set_transaction_stmt
+%type <tree.Statement> set_constraints_stmt
 %type <tree.Statement> set_exprs_internal
 %type <tree.Statement> generic_set
 %type <tree.Statement> set_rest_more
@@ -1289,7 +1333,13 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.Statement> transaction_stmt legacy_transaction_stmt legacy_begin_stmt legacy_end_stmt
 %type <tree.Statement> truncate_stmt
 %type <tree.Statement> unlisten_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
 %type <tree.Statement> update_stmt
+%type <tree.Statement> merge_stmt
+%type <tree.MergeWhens> merge_when_list
+%type <*tree.MergeWhen> merge_when_clause merge_when_matched_action merge_when_not_matched_action
+%type <tree.Expr> opt_merge_when_cond
 %type <tree.Statement> upsert_stmt
 %type <tree.Statement> use_stmt
 
@@ -1405,7 +1455,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <*tree.PartitionByTable> opt_partition_by_table partition_by_table
 %type <*tree.PartitionByIndex> opt_partition_by_index partition_by_index
 %type <str> partition opt_partition
-%type <str> opt_create_table_inherits
+%type <tree.TableNames> opt_create_table_inherits
 %type <tree.ListPartition> list_partition
 %type <[]tree.ListPartition> list_partitions
 %type <tree.RangePartition> range_partition
@@ -1506,7 +1556,9 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <bool> opt_ordinality opt_compact
 %type <*tree.Order> sortby
 %type <tree.IndexElem> index_elem index_elem_options create_as_param
-%type <tree.TableExpr> table_ref numeric_table_ref func_table
+%type <tree.ExclusionConstraintElem> exclude_elem
+%type <tree.ExclusionConstraintElems> exclude_elem_list
+%type <tree.TableExpr> table_ref numeric_table_ref func_table relation_expr_with_only
 %type <tree.Exprs> rowsfrom_list
 %type <tree.Expr> rowsfrom_item
 %type <tree.TableExpr> joined_table
@@ -1522,6 +1574,16 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <str> explain_option_name
 %type <[]string> explain_option_list opt_enum_val_list enum_val_list
 %type <[]tree.CompositeTypeElem> composite_type_list opt_composite_type_list
+%type <[]tree.RangeTypeParam> range_type_param_list
+%type <tree.DomainConstraint> domain_constraint domain_constraint_elem
+%type <[]tree.DomainConstraint> domain_constraint_list opt_domain_constraint_list
+%type <tree.TriggerActionTime> trigger_action_time
+%type <*tree.TriggerEvent> trigger_event
+%type <[]*tree.TriggerEvent> trigger_event_list
+%type <bool> opt_trigger_for_each trigger_for_type
+%type <tree.Expr> opt_trigger_when
+%type <[]string> opt_trigger_func_args trigger_func_args
+%type <str> trigger_func_arg
 
 %type <tree.ResolvableTypeReference> typename simple_typename cast_target
 %type <*types.T> const_typename
@@ -1570,6 +1632,8 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable
+%type <bool> constraints_set_mode
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -1636,10 +1700,12 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %type <str> param_name func_as
 %type <tree.FuncParams> opt_func_param_with_default_list func_param_with_default_list func_params func_params_list
 %type <tree.FuncParam> func_param_with_default func_param
+%type <tree.FuncParams> opt_proc_param_with_default_list proc_param_with_default_list
+%type <tree.FuncParam> proc_param_with_default proc_param
 %type <tree.ResolvableTypeReference> func_return_type func_param_type
 %type <tree.FunctionOptions> opt_create_func_opt_list create_func_opt_list alter_func_opt_list
 %type <tree.FunctionOption> create_func_opt_item common_func_opt_item
-%type <tree.FuncParamClass> func_param_class
+%type <tree.FuncParamClass> func_param_class proc_param_class
 %type <*tree.UnresolvedObjectName> func_create_name
 %type <tree.Statement> routine_return_stmt routine_body_stmt
 %type <tree.Statements> routine_body_stmt_list
@@ -1695,7 +1761,7 @@ func (u *sqlSymUnion) showTenantOpts() tree.ShowTenantOptions {
 %left      '|'
 %left      '#'
 %left      '&'
-%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND SQRT CBRT
+%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND RANGE_ADJACENT SQRT CBRT
 %left      OPERATOR // if changing the last token before OPERATOR, change all instances of %prec <last token>
 %left      '+' '-'
 %left      '*' '/' FLOORDIV '%'
@@ -1759,6 +1825,8 @@ stmt_without_legacy_transaction:
 | fetch_cursor_stmt          // EXTEND WITH HELP: FETCH
 | move_cursor_stmt           // EXTEND WITH HELP: MOVE
 | reindex_stmt
+| listen_stmt
+| notify_stmt
 | unlisten_stmt
 | show_commit_timestamp_stmt // EXTEND WITH HELP: SHOW COMMIT TIMESTAMP
 
@@ -1782,6 +1850,7 @@ alter_ddl_stmt:
 | alter_partition_stmt          // EXTEND WITH HELP: ALTER PARTITION
 | alter_schema_stmt             // EXTEND WITH HELP: ALTER SCHEMA
 | alter_type_stmt               // EXTEND WITH HELP: ALTER TYPE
+| alter_domain_stmt             // EXTEND WITH HELP: ALTER DOMAIN
 | alter_default_privileges_stmt // EXTEND WITH HELP: ALTER DEFAULT PRIVILEGES
 | alter_changefeed_stmt         // EXTEND WITH HELP: ALTER CHANGEFEED
 | alter_backup_stmt             // EXTEND WITH HELP: ALTER BACKUP
@@ -1820,6 +1889,7 @@ alter_ddl_stmt:
 //   ALTER TABLE ... CONFIGURE ZONE <zoneconfig>
 //   ALTER TABLE ... SET SCHEMA <newschemaname>
 //   ALTER TABLE ... SET LOCALITY [REGIONAL BY [TABLE IN <region> | ROW] | GLOBAL]
+//   ALTER TABLE ... [NO] INHERIT <parenttablename>
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
@@ -2729,17 +2799,20 @@ alter_table_cmd:
   }
   // ALTER TABLE <name> ALTER CONSTRAINT ...
 | ALTER CONSTRAINT constraint_name error { return unimplementedWithIssueDetail(sqllex, 31632, "alter constraint") }
-  // ALTER TABLE <name> INHERITS ....
-| INHERITS error
+  // ALTER TABLE <name> INHERIT <parent>
+| INHERIT table_name
   {
-    /* SKIP DOC */
-    return unimplementedWithIssueDetail(sqllex, 22456, "alter table inherits")
+    $$.val = &tree.AlterTableInherit{
+      Parent: $2.unresolvedObjectName().ToTableName(),
+    }
   }
-  // ALTER TABLE <name> NO INHERITS ....
-| NO INHERITS error
+  // ALTER TABLE <name> NO INHERIT <parent>
+| NO INHERIT table_name
   {
-    /* SKIP DOC */
-    return unimplementedWithIssueDetail(sqllex, 22456, "alter table no inherits")
+    $$.val = &tree.AlterTableInherit{
+      Parent: $3.unresolvedObjectName().ToTableName(),
+      NoInherit: true,
+    }
   }
   // ALTER TABLE <name> ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
 | ALTER PRIMARY KEY USING COLUMNS '(' index_params ')' opt_hash_sharded opt_with_storage_parameter_list
@@ -2993,6 +3066,90 @@ alter_type_stmt:
   }
 | ALTER TYPE error // SHOW HELP: ALTER TYPE
 
+// %Help: ALTER DOMAIN - change the definition of a domain
+// %Category: DDL
+// %Text:
+// ALTER DOMAIN <name> SET DEFAULT <expr>
+// ALTER DOMAIN <name> DROP DEFAULT
+// ALTER DOMAIN <name> SET NOT NULL
+// ALTER DOMAIN <name> DROP NOT NULL
+// ALTER DOMAIN <name> ADD [CONSTRAINT <constraint_name>] CHECK (<expr>)
+// ALTER DOMAIN <name> DROP CONSTRAINT [IF EXISTS] <constraint_name> [CASCADE | RESTRICT]
+// ALTER DOMAIN <name> RENAME TO <newname>
+// %SeeAlso: CREATE DOMAIN, DROP DOMAIN
+alter_domain_stmt:
+  ALTER DOMAIN type_name SET DEFAULT a_expr
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{Default: $6.expr()},
+    }
+  }
+| ALTER DOMAIN type_name DROP DEFAULT
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{},
+    }
+  }
+| ALTER DOMAIN type_name SET NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: true},
+    }
+  }
+| ALTER DOMAIN type_name DROP NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: false},
+    }
+  }
+| ALTER DOMAIN type_name ADD CONSTRAINT constraint_name CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainAddConstraint{Name: tree.Name($6), Check: $9.expr()},
+    }
+  }
+| ALTER DOMAIN type_name ADD CHECK '(' a_expr ')'
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainAddConstraint{Check: $7.expr()},
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT IF EXISTS constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{
+        Name: tree.Name($8),
+        IfExists: true,
+        DropBehavior: $9.dropBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{
+        Name: tree.Name($6),
+        DropBehavior: $7.dropBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name RENAME TO name
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainRename{NewName: tree.Name($6)},
+    }
+  }
+| ALTER DOMAIN error // SHOW HELP: ALTER DOMAIN
+
 opt_add_val_placement:
   BEFORE SCONST
   {
@@ -3767,11 +3924,7 @@ import_format:
   }
 
 alter_unsupported_stmt:
-  ALTER DOMAIN error
-  {
-    return unimplemented(sqllex, "alter domain")
-  }
-| ALTER AGGREGATE error
+  ALTER AGGREGATE error
   {
     return unimplementedWithIssueDetail(sqllex, 74775, "alter aggregate")
   }
@@ -4466,6 +4619,193 @@ create_func_stmt:
   }
 | CREATE opt_or_replace FUNCTION error // SHOW HELP: CREATE FUNCTION
 
+// %Help: CREATE PROCEDURE - define a new procedure
+// %Category: DDL
+// %Text:
+// CREATE [ OR REPLACE ] PROCEDURE
+//    name ( [ [ argmode ] [ argname ] argtype [, ...] ] )
+//  { LANGUAGE lang_name
+//    | AS 'definition'
+//  } ...
+// %SeeAlso: CALL, DROP PROCEDURE
+create_proc_stmt:
+  CREATE opt_or_replace PROCEDURE func_create_name '(' opt_proc_param_with_default_list ')'
+  opt_create_func_opt_list opt_routine_body
+  {
+    name := $4.unresolvedObjectName().ToFunctionName()
+    $$.val = &tree.CreateFunction{
+      IsProcedure: true,
+      Replace: $2.bool(),
+      FuncName: name,
+      Params: $6.functionParams(),
+      ReturnType: tree.FuncReturnType{
+        Type: types.Void,
+      },
+      Options: $8.functionOptions(),
+      RoutineBody: $9.routineBody(),
+    }
+  }
+| CREATE opt_or_replace PROCEDURE error // SHOW HELP: CREATE PROCEDURE
+
+// %Help: CALL - invoke a procedure
+// %Category: DML
+// %Text: CALL <name> ( [ <argument> [, ...] ] )
+// %SeeAlso: CREATE PROCEDURE
+call_stmt:
+  CALL func_application
+  {
+    p, ok := $2.expr().(*tree.FuncExpr)
+    if !ok {
+      return unimplemented(sqllex, "CALL with a non-function expression")
+    }
+    $$.val = &tree.Call{Proc: p}
+  }
+| CALL error // SHOW HELP: CALL
+
+// %Help: CREATE TRIGGER - define a new trigger
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] TRIGGER <name> { BEFORE | AFTER } <event> [ OR ... ]
+//   ON <table_name>
+//   [ FOR [ EACH ] { ROW | STATEMENT } ]
+//   [ WHEN ( <condition> ) ]
+//   EXECUTE { FUNCTION | PROCEDURE } <function_name> ( [ <arguments> ] )
+//
+// where <event> is one of:
+//   INSERT
+//   UPDATE [ OF <column_name> [, ... ] ]
+//   DELETE
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION
+create_trigger_stmt:
+  CREATE opt_or_replace TRIGGER name trigger_action_time trigger_event_list ON table_name
+  opt_trigger_for_each opt_trigger_when EXECUTE function_or_procedure func_create_name '(' opt_trigger_func_args ')'
+  {
+    $$.val = &tree.CreateTrigger{
+      Replace: $2.bool(),
+      Name: tree.Name($4),
+      ActionTime: $5.triggerActionTime(),
+      Events: $6.triggerEvents(),
+      Table: $8.unresolvedObjectName(),
+      ForEachRow: $9.bool(),
+      When: $10.expr(),
+      FuncName: $13.unresolvedObjectName().ToFunctionName(),
+      FuncArgs: $15.strs(),
+    }
+  }
+| CREATE opt_or_replace TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerActionTimeBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerActionTimeAfter
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = []*tree.TriggerEvent{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = &tree.TriggerEvent{EventType: tree.TriggerEventInsert}
+  }
+| UPDATE
+  {
+    $$.val = &tree.TriggerEvent{EventType: tree.TriggerEventUpdate}
+  }
+| UPDATE OF name_list
+  {
+    $$.val = &tree.TriggerEvent{EventType: tree.TriggerEventUpdate, Columns: $3.nameList()}
+  }
+| DELETE
+  {
+    $$.val = &tree.TriggerEvent{EventType: tree.TriggerEventDelete}
+  }
+| TRUNCATE
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "truncate trigger")
+  }
+
+opt_trigger_for_each:
+  FOR opt_each trigger_for_type
+  {
+    $$.val = $3.bool()
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+opt_each:
+  EACH {}
+| /* EMPTY */ {}
+
+trigger_for_type:
+  ROW
+  {
+    $$.val = true
+  }
+| STATEMENT
+  {
+    $$.val = false
+  }
+
+opt_trigger_when:
+  WHEN '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+function_or_procedure:
+  FUNCTION {}
+| PROCEDURE {}
+
+opt_trigger_func_args:
+  trigger_func_args
+  {
+    $$.val = $1.strs()
+  }
+| /* EMPTY */
+  {
+    $$.val = []string(nil)
+  }
+
+trigger_func_args:
+  trigger_func_arg
+  {
+    $$.val = []string{$1}
+  }
+| trigger_func_args ',' trigger_func_arg
+  {
+    $$.val = append($1.strs(), $3)
+  }
+
+trigger_func_arg:
+  SCONST
+| non_reserved_word
+| ICONST
+  {
+    $$ = $1.numVal().String()
+  }
+| FCONST
+  {
+    $$ = $1.numVal().String()
+  }
+
 opt_or_replace:
   OR REPLACE { $$.val = true }
 | /* EMPTY */ { $$.val = false }
@@ -4550,6 +4890,81 @@ func_param_class:
 | IN OUT { return unimplemented(sqllex, "create function with 'IN OUT' argument class") }
 | VARIADIC { return unimplementedWithIssueDetail(sqllex, 88947, "variadic user-defined functions") }
 
+// Unlike functions, procedures support OUT and INOUT parameters, so their
+// parameters have their own rules.
+opt_proc_param_with_default_list:
+  proc_param_with_default_list { $$.val = $1.functionParams() }
+| /* Empty */ { $$.val = tree.FuncParams{} }
+
+proc_param_with_default_list:
+  proc_param_with_default { $$.val = tree.FuncParams{$1.functionParam()} }
+| proc_param_with_default_list ',' proc_param_with_default
+  {
+    $$.val = append($1.functionParams(), $3.functionParam())
+  }
+
+proc_param_with_default:
+  proc_param
+| proc_param DEFAULT a_expr
+  {
+    arg := $1.functionParam()
+    arg.DefaultVal = $3.expr()
+    $$.val = arg
+  }
+| proc_param '=' a_expr
+  {
+    arg := $1.functionParam()
+    arg.DefaultVal = $3.expr()
+    $$.val = arg
+  }
+
+proc_param:
+  proc_param_class param_name func_param_type
+  {
+    $$.val = tree.FuncParam{
+      Name: tree.Name($2),
+      Type: $3.typeReference(),
+      Class: $1.functionParamClass(),
+    }
+  }
+| param_name proc_param_class func_param_type
+  {
+    $$.val = tree.FuncParam{
+      Name: tree.Name($1),
+      Type: $3.typeReference(),
+      Class: $2.functionParamClass(),
+    }
+  }
+| param_name func_param_type
+  {
+    $$.val = tree.FuncParam{
+      Name: tree.Name($1),
+      Type: $2.typeReference(),
+      Class: tree.FunctionParamIn,
+    }
+  }
+| proc_param_class func_param_type
+  {
+    $$.val = tree.FuncParam{
+      Type: $2.typeReference(),
+      Class: $1.functionParamClass(),
+    }
+  }
+| func_param_type
+  {
+    $$.val = tree.FuncParam{
+      Type: $1.typeReference(),
+      Class: tree.FunctionParamIn,
+    }
+  }
+
+proc_param_class:
+  IN { $$.val = tree.FunctionParamIn }
+| OUT { $$.val = tree.FunctionParamOut }
+| INOUT { $$.val = tree.FunctionParamInOut }
+| IN OUT { $$.val = tree.FunctionParamInOut }
+| VARIADIC { return unimplementedWithIssueDetail(sqllex, 88947, "variadic user-defined functions") }
+
 func_param_type:
   typename
 
@@ -4722,6 +5137,32 @@ drop_func_stmt:
   }
 | DROP FUNCTION error // SHOW HELP: DROP FUNCTION
 
+// %Help: DROP PROCEDURE - remove a procedure
+// %Category: DDL
+// %Text:
+// DROP PROCEDURE [ IF EXISTS ] name [ ( [ [ argmode ] [ argname ] argtype [, ...] ] ) ] [, ...]
+//    [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE PROCEDURE
+drop_proc_stmt:
+  DROP PROCEDURE function_with_paramtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      IsProcedure: true,
+      Functions: $3.functionObjs(),
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP PROCEDURE IF EXISTS function_with_paramtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      IsProcedure: true,
+      IfExists: true,
+      Functions: $5.functionObjs(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP PROCEDURE error // SHOW HELP: DROP PROCEDURE
+
 function_with_paramtypes_list:
   function_with_paramtypes
   {
@@ -4854,7 +5295,6 @@ create_unsupported:
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create trigger") }
 
 opt_trusted:
   TRUSTED {}
@@ -4870,7 +5310,6 @@ drop_unsupported:
 | DROP CAST error { return unimplemented(sqllex, "drop cast") }
 | DROP COLLATION error { return unimplemented(sqllex, "drop collation") }
 | DROP CONVERSION error { return unimplemented(sqllex, "drop conversion") }
-| DROP DOMAIN error { return unimplementedWithIssueDetail(sqllex, 27796, "drop") }
 | DROP EXTENSION IF EXISTS name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension if exists") }
 | DROP EXTENSION name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension") }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
@@ -4882,7 +5321,6 @@ drop_unsupported:
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
@@ -4893,9 +5331,12 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_domain_stmt   // EXTEND WITH HELP: CREATE DOMAIN
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 | create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
+| create_proc_stmt     // EXTEND WITH HELP: CREATE PROCEDURE
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -5245,7 +5686,10 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_domain_stmt   // EXTEND WITH HELP: DROP DOMAIN
 | drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
+| drop_proc_stmt     // EXTEND WITH HELP: DROP PROCEDURE
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -5380,6 +5824,53 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <trigger_name> ON <table_name> [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Trigger: tree.Name($3),
+      Table: $5.unresolvedObjectName(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      IfExists: true,
+      Trigger: tree.Name($5),
+      Table: $7.unresolvedObjectName(),
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
+// %Help: DROP DOMAIN - remove a domain
+// %Category: DDL
+// %Text: DROP DOMAIN [IF EXISTS] <type_name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE DOMAIN, ALTER DOMAIN
+drop_domain_stmt:
+  DROP DOMAIN type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropDomain{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP DOMAIN IF EXISTS type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropDomain{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP DOMAIN error // SHOW HELP: DROP DOMAIN
+
 // %Help: DROP TENANT - remove a tenant
 // %Category: Experimental
 // %Text: DROP TENANT [IF EXISTS] <tenant_spec> [IMMEDIATE]
@@ -5591,6 +6082,7 @@ explainable_stmt:
 preparable_stmt:
   alter_stmt     // help texts in sub-rule
 | backup_stmt    // EXTEND WITH HELP: BACKUP
+| call_stmt      // EXTEND WITH HELP: CALL
 | cancel_stmt    // help texts in sub-rule
 | create_stmt    // help texts in sub-rule
 | delete_stmt    // EXTEND WITH HELP: DELETE
@@ -5598,6 +6090,7 @@ preparable_stmt:
 | explain_stmt   // EXTEND WITH HELP: EXPLAIN
 | import_stmt    // EXTEND WITH HELP: IMPORT
 | insert_stmt    // EXTEND WITH HELP: INSERT
+| merge_stmt     // EXTEND WITH HELP: MERGE
 | pause_stmt     // help texts in sub-rule
 | reset_stmt     // help texts in sub-rule
 | restore_stmt   // EXTEND WITH HELP: RESTORE
@@ -5620,6 +6113,7 @@ row_source_extension_stmt:
   delete_stmt       // EXTEND WITH HELP: DELETE
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | insert_stmt       // EXTEND WITH HELP: INSERT
+| merge_stmt        // EXTEND WITH HELP: MERGE
 | select_stmt       // help texts in sub-rule
   {
     $$.val = $1.slct()
@@ -6241,7 +6735,7 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 
 // SET SESSION / SET LOCAL / SET CLUSTER SETTING
 preparable_set_stmt:
@@ -6659,6 +7153,31 @@ set_transaction_stmt:
   }
 | SET SESSION TRANSACTION error // SHOW HELP: SET TRANSACTION
 
+// %Help: SET CONSTRAINTS - set the checking mode of deferrable constraints
+// %Category: Txn
+// %Text: SET CONSTRAINTS { ALL | <name> [, ...] } { DEFERRED | IMMEDIATE }
+// %SeeAlso: SET TRANSACTION
+set_constraints_stmt:
+  SET CONSTRAINTS ALL constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{All: true, Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS name_list constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{Names: $3.nameList(), Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
+constraints_set_mode:
+  DEFERRED
+  {
+    $$.val = true
+  }
+| IMMEDIATE
+  {
+    $$.val = false
+  }
+
 generic_set:
   var_name to_or_eq var_list
   {
@@ -6783,11 +7302,11 @@ var_list:
 iso_level:
   READ UNCOMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | READ COMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | SNAPSHOT
   {
@@ -8141,6 +8660,16 @@ show_create_stmt:
       },
     }
   }
+| SHOW CREATE PROCEDURE db_object_name
+  {
+    /* SKIP DOC */
+    $$.val = &tree.ShowCreateFunction{
+      Name: tree.ResolvableFunctionReference{
+        FunctionReference: $4.unresolvedObjectName().ToUnresolvedName(),
+      },
+      IsProcedure: true,
+    }
+  }
 | SHOW CREATE ALL SCHEMAS
   {
     $$.val = &tree.ShowCreateAllSchemas{}
@@ -8954,7 +9483,7 @@ alter_schema_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE [[GLOBAL | LOCAL] {TEMPORARY | TEMP}] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<on_commit>]
+// CREATE [[GLOBAL | LOCAL] {TEMPORARY | TEMP}] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [INHERITS ( <tablenames...> )] [<on_commit>]
 // CREATE [[GLOBAL | LOCAL] {TEMPORARY | TEMP}] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source> [<on commit>]
 //
 // Table elements:
@@ -8997,6 +9526,7 @@ create_table_stmt:
       StorageParams: $10.storageParams(),
       OnCommit: $11.createTableOnCommitSetting(),
       Locality: $12.locality(),
+      Inherits: $8.tableNames(),
     }
   }
 | CREATE opt_persistence_temp_table TABLE IF NOT EXISTS table_name '(' opt_table_elem_list ')' opt_create_table_inherits opt_partition_by_table opt_table_with opt_create_table_on_commit opt_locality
@@ -9012,6 +9542,7 @@ create_table_stmt:
       StorageParams: $13.storageParams(),
       OnCommit: $14.createTableOnCommitSetting(),
       Locality: $15.locality(),
+      Inherits: $11.tableNames(),
     }
   }
 
@@ -9041,12 +9572,11 @@ opt_table_with:
 opt_create_table_inherits:
   /* EMPTY */
   {
-    $$ = ""
+    $$.val = tree.TableNames(nil)
   }
-| INHERITS error
+| INHERITS '(' table_name_list ')'
   {
-    /* SKIP DOC */
-    return unimplementedWithIssueDetail(sqllex, 22456, "create table inherits")
+    $$.val = $3.tableNames()
   }
 
 opt_with_storage_parameter_list:
@@ -9094,6 +9624,10 @@ storage_parameter:
   {
     $$.val = tree.StorageParam{Key: tree.Name($1), Value: $3.expr()}
   }
+| storage_parameter_key
+  {
+    $$.val = tree.StorageParam{Key: tree.Name($1)}
+  }
 
 storage_parameter_list:
   storage_parameter
@@ -9637,6 +10171,9 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability().IsDeferrable() {
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable check constraint")
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -9652,6 +10189,7 @@ constraint_elem:
         PartitionByIndex: $7.partitionByIndex(),
         Predicate: $9.expr(),
       },
+      Deferrable: $8.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_with_storage_parameter_list
@@ -9675,11 +10213,37 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrable: $11.constraintDeferrability(),
     }
   }
-| EXCLUDE USING error
+| EXCLUDE opt_index_access_method '(' exclude_elem_list ')' opt_where_clause
   {
-    return unimplementedWithIssueDetail(sqllex, 46657, "add constraint exclude using")
+    $$.val = &tree.ExclusionConstraintTableDef{
+      Inverted: $2.bool(),
+      Elems: $4.exclusionElems(),
+      Predicate: $6.expr(),
+    }
+  }
+
+exclude_elem_list:
+  exclude_elem
+  {
+    $$.val = tree.ExclusionConstraintElems{$1.exclusionElem()}
+  }
+| exclude_elem_list ',' exclude_elem
+  {
+    $$.val = append($1.exclusionElems(), $3.exclusionElem())
+  }
+
+exclude_elem:
+  index_elem WITH operator_op
+  {
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      sqllex.Error(fmt.Sprintf("operator %s is not a comparison operator", $3.op()))
+      return 1
+    }
+    $$.val = tree.ExclusionConstraintElem{IndexElem: $1.idxElem(), Operator: op}
   }
 
 
@@ -9788,12 +10352,43 @@ create_as_col_qualification_elem:
   }
 
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| NOT_DEFERRABLE DEFERRABLE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| NOT_DEFERRABLE DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| NOT_DEFERRABLE DEFERRABLE INITIALLY DEFERRED
+  {
+    sqllex.Error("constraint declared INITIALLY DEFERRED must be DEFERRABLE")
+    return 1
+  }
+| DEFERRABLE
+  {
+    $$.val = tree.DeferrableInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.DeferrableInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY DEFERRED
+  {
+    $$.val = tree.DeferrableInitiallyDeferred
+  }
+| INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| INITIALLY DEFERRED
+  {
+    $$.val = tree.DeferrableInitiallyDeferred
+  }
 
 storing:
   COVERING
@@ -10170,7 +10765,7 @@ role_or_group_or_user:
 // %Category: DDL
 // %Text:
 // CREATE [TEMPORARY | TEMP] VIEW [IF NOT EXISTS] <viewname> [( <colnames...> )] AS <source>
-// CREATE [TEMPORARY | TEMP] MATERIALIZED VIEW [IF NOT EXISTS] <viewname> [( <colnames...> )] AS <source> [WITH [NO] DATA]
+// CREATE [TEMPORARY | TEMP] MATERIALIZED VIEW [IF NOT EXISTS] <viewname> [( <colnames...> )] [WITH ( incremental [= <bool>] )] AS <source> [WITH [NO] DATA]
 // %SeeAlso: CREATE TABLE, SHOW CREATE, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE opt_temp opt_view_recursive VIEW view_name opt_column_list AS select_stmt
@@ -10211,27 +10806,29 @@ create_view_stmt:
       Replace: false,
     }
   }
-| CREATE MATERIALIZED VIEW view_name opt_column_list AS select_stmt opt_with_data
+| CREATE MATERIALIZED VIEW view_name opt_column_list opt_with_storage_parameter_list AS select_stmt opt_with_data
   {
     name := $4.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateView{
       Name: name,
       ColumnNames: $5.nameList(),
-      AsSource: $7.slct(),
+      Params: $6.storageParams(),
+      AsSource: $8.slct(),
       Materialized: true,
-      WithData: $8.bool(),
+      WithData: $9.bool(),
     }
   }
-| CREATE MATERIALIZED VIEW IF NOT EXISTS view_name opt_column_list AS select_stmt opt_with_data
+| CREATE MATERIALIZED VIEW IF NOT EXISTS view_name opt_column_list opt_with_storage_parameter_list AS select_stmt opt_with_data
   {
     name := $7.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateView{
       Name: name,
       ColumnNames: $8.nameList(),
-      AsSource: $10.slct(),
+      Params: $9.storageParams(),
+      AsSource: $11.slct(),
       Materialized: true,
       IfNotExists: true,
-      WithData: $11.bool(),
+      WithData: $12.bool(),
     }
   }
 | CREATE opt_temp opt_view_recursive VIEW error // SHOW HELP: CREATE VIEW
@@ -10387,7 +10984,9 @@ opt_view_recursive:
 
 // %Help: CREATE TYPE - create a type
 // %Category: DDL
-// %Text: CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// %Text:
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS RANGE (SUBTYPE = <type> [, MULTIRANGE_TYPE_NAME = <name>])
 create_type_stmt:
   // Enum types.
   CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
@@ -10427,13 +11026,108 @@ create_type_stmt:
     }
   }
   // Range types.
-| CREATE TYPE type_name AS RANGE error    { return unimplementedWithIssue(sqllex, 27791) }
+| CREATE TYPE type_name AS RANGE '(' range_type_param_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Range,
+      RangeParams: $7.rangeTypeParams(),
+    }
+  }
+| CREATE TYPE IF NOT EXISTS type_name AS RANGE '(' range_type_param_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $6.unresolvedObjectName(),
+      Variety: tree.Range,
+      IfNotExists: true,
+      RangeParams: $10.rangeTypeParams(),
+    }
+  }
   // Base (primitive) types.
 | CREATE TYPE type_name '(' error         { return unimplementedWithIssueDetail(sqllex, 27793, "base") }
   // Shell types, gateway to define base types using the previous syntax.
 | CREATE TYPE type_name                   { return unimplementedWithIssueDetail(sqllex, 27793, "shell") }
-  // Domain types.
-| CREATE DOMAIN type_name error           { return unimplementedWithIssueDetail(sqllex, 27796, "create") }
+
+// %Help: CREATE DOMAIN - create a domain
+// %Category: DDL
+// %Text:
+// CREATE DOMAIN <type_name> [AS] <type> [<constraint> ...]
+//
+// Constraint:
+//   DEFAULT <expr>
+//   [CONSTRAINT <name>] NOT NULL
+//   [CONSTRAINT <name>] NULL
+//   [CONSTRAINT <name>] CHECK (<expr>)
+//
+// %SeeAlso: ALTER DOMAIN, DROP DOMAIN
+create_domain_stmt:
+  CREATE DOMAIN type_name AS typename opt_domain_constraint_list
+  {
+    $$.val = &tree.CreateDomain{
+      TypeName: $3.unresolvedObjectName(),
+      BaseType: $5.typeReference(),
+      Constraints: $6.domainConstraints(),
+    }
+  }
+| CREATE DOMAIN type_name typename opt_domain_constraint_list
+  {
+    $$.val = &tree.CreateDomain{
+      TypeName: $3.unresolvedObjectName(),
+      BaseType: $4.typeReference(),
+      Constraints: $5.domainConstraints(),
+    }
+  }
+| CREATE DOMAIN error // SHOW HELP: CREATE DOMAIN
+
+opt_domain_constraint_list:
+  domain_constraint_list
+  {
+    $$.val = $1.domainConstraints()
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.DomainConstraint(nil)
+  }
+
+domain_constraint_list:
+  domain_constraint
+  {
+    $$.val = []tree.DomainConstraint{$1.domainConstraint()}
+  }
+| domain_constraint_list domain_constraint
+  {
+    $$.val = append($1.domainConstraints(), $2.domainConstraint())
+  }
+
+domain_constraint:
+  CONSTRAINT constraint_name domain_constraint_elem
+  {
+    c := $3.domainConstraint()
+    c.Name = tree.Name($2)
+    $$.val = c
+  }
+| domain_constraint_elem
+  {
+    $$.val = $1.domainConstraint()
+  }
+| DEFAULT b_expr
+  {
+    $$.val = tree.DomainConstraint{Default: $2.expr()}
+  }
+
+domain_constraint_elem:
+  NOT NULL
+  {
+    $$.val = tree.DomainConstraint{NotNull: true}
+  }
+| NULL
+  {
+    $$.val = tree.DomainConstraint{Null: true}
+  }
+| CHECK '(' a_expr ')'
+  {
+    $$.val = tree.DomainConstraint{Check: $3.expr()}
+  }
 
 opt_enum_val_list:
   enum_val_list
@@ -10485,6 +11179,26 @@ composite_type_list:
     )
   }
 
+range_type_param_list:
+  non_reserved_word '=' typename
+  {
+    $$.val = []tree.RangeTypeParam{
+        tree.RangeTypeParam{
+            Name: tree.Name($1),
+            Value: $3.typeReference(),
+        },
+    }
+  }
+| range_type_param_list ',' non_reserved_word '=' typename
+  {
+    $$.val = append($1.rangeTypeParams(),
+        tree.RangeTypeParam{
+            Name: tree.Name($3),
+            Value: $5.typeReference(),
+        },
+    )
+  }
+
 // %Help: CREATE INDEX - create a new index
 // %Category: DDL
 // %Text:
@@ -11450,7 +12164,7 @@ transaction_deferrable_mode:
   {
     $$.val = tree.Deferrable
   }
-| NOT DEFERRABLE
+| NOT_DEFERRABLE DEFERRABLE
   {
     $$.val = tree.NotDeferrable
   }
@@ -11855,6 +12569,96 @@ update_stmt:
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
+// %Help: MERGE - insert, update or delete rows of a table based on a join
+// %Category: DML
+// %Text:
+// MERGE INTO <tablename> [[AS] <name>]
+//       USING <source> ON <expr>
+//       WHEN MATCHED [AND <expr>] THEN {UPDATE SET ... | DELETE | DO NOTHING}
+//       WHEN NOT MATCHED [AND <expr>] THEN
+//         {INSERT [( <colnames...> )] {VALUES ( <exprs...> ) | DEFAULT VALUES} | DO NOTHING}
+//       [RETURNING <exprs...>]
+// %SeeAlso: INSERT, UPSERT, UPDATE, DELETE
+merge_stmt:
+  opt_with_clause MERGE INTO table_expr_opt_alias_idx
+    USING table_ref ON a_expr merge_when_list returning_clause
+  {
+    $$.val = &tree.Merge{
+      With: $1.with(),
+      Table: $4.tblExpr(),
+      Source: $6.tblExpr(),
+      On: $8.expr(),
+      Whens: $9.mergeWhens(),
+      Returning: $10.retClause(),
+    }
+  }
+| opt_with_clause MERGE error // SHOW HELP: MERGE
+
+merge_when_list:
+  merge_when_clause
+  {
+    $$.val = tree.MergeWhens{$1.mergeWhen()}
+  }
+| merge_when_list merge_when_clause
+  {
+    $$.val = append($1.mergeWhens(), $2.mergeWhen())
+  }
+
+merge_when_clause:
+  WHEN MATCHED opt_merge_when_cond THEN merge_when_matched_action
+  {
+    $$.val = $5.mergeWhen()
+    $$.val.(*tree.MergeWhen).Matched = true
+    $$.val.(*tree.MergeWhen).Cond = $3.expr()
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN merge_when_not_matched_action
+  {
+    $$.val = $6.mergeWhen()
+    $$.val.(*tree.MergeWhen).Cond = $4.expr()
+  }
+
+opt_merge_when_cond:
+  AND a_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+merge_when_matched_action:
+  UPDATE SET set_clause_list
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionUpdate, Exprs: $3.updateExprs()}
+  }
+| DELETE
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDelete}
+  }
+| DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDoNothing}
+  }
+
+merge_when_not_matched_action:
+  INSERT VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert, Values: $4.exprs()}
+  }
+| INSERT '(' insert_column_list ')' VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert, Columns: $3.nameList(), Values: $7.exprs()}
+  }
+| INSERT DEFAULT VALUES
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert}
+  }
+| DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDoNothing}
+  }
+
 opt_from_list:
   FROM from_list {
     $$.val = $2.tblExprs()
@@ -12526,9 +13330,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsRollup, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsCube, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsList, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -12732,15 +13545,13 @@ table_ref:
         As:         $4.aliasClause(),
     }
   }
-| relation_expr opt_index_flags opt_ordinality opt_alias_clause
+| relation_expr_with_only opt_index_flags opt_ordinality opt_alias_clause
   {
-    name := $1.unresolvedObjectName().ToTableName()
-    $$.val = &tree.AliasedTableExpr{
-      Expr:       &name,
-      IndexFlags: $2.indexFlags(),
-      Ordinality: $3.bool(),
-      As:         $4.aliasClause(),
-    }
+    expr := $1.tblExpr().(*tree.AliasedTableExpr)
+    expr.IndexFlags = $2.indexFlags()
+    expr.Ordinality = $3.bool()
+    expr.As = $4.aliasClause()
+    $$.val = expr
   }
 | select_with_parens opt_ordinality opt_alias_clause
   {
@@ -13091,6 +13902,30 @@ relation_expr:
 | ONLY table_name         { $$.val = $2.unresolvedObjectName() }
 | ONLY '(' table_name ')' { $$.val = $3.unresolvedObjectName() }
 
+// relation_expr_with_only is like relation_expr, but remembers whether ONLY
+// was specified so that the rows of inheriting tables can be excluded.
+relation_expr_with_only:
+  table_name
+  {
+    name := $1.unresolvedObjectName().ToTableName()
+    $$.val = &tree.AliasedTableExpr{Expr: &name}
+  }
+| table_name '*'
+  {
+    name := $1.unresolvedObjectName().ToTableName()
+    $$.val = &tree.AliasedTableExpr{Expr: &name}
+  }
+| ONLY table_name
+  {
+    name := $2.unresolvedObjectName().ToTableName()
+    $$.val = &tree.AliasedTableExpr{Expr: &name, Only: true}
+  }
+| ONLY '(' table_name ')'
+  {
+    name := $3.unresolvedObjectName().ToTableName()
+    $$.val = &tree.AliasedTableExpr{Expr: &name, Only: true}
+  }
+
 relation_expr_list:
   relation_expr
   {
@@ -13103,6 +13938,24 @@ relation_expr_list:
     $$.val = append($1.tableNames(), name)
   }
 
+// LISTEN
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{ChannelName: tree.Name($2)}
+  }
+
+// NOTIFY
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2), Payload: tree.NewStrVal($4)}
+  }
+
 // UNLISTEN
 unlisten_stmt:
    UNLISTEN type_name
@@ -13155,6 +14008,7 @@ table_name_opt_idx:
     $$.val = &tree.AliasedTableExpr{
       Expr: &name,
       IndexFlags: $3.indexFlags(),
+      Only: $1.bool(),
     }
   }
 
@@ -13996,6 +14850,10 @@ a_expr:
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.TSMatches), Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr RANGE_ADJACENT a_expr
+  {
+    $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.Adjacent), Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr INET_CONTAINS_OR_EQUALS a_expr
   {
     $$.val = &tree.FuncExpr{Func: tree.WrapFunction("inet_contains_or_equals"), Exprs: tree.Exprs{$1.expr(), $3.expr()}}
@@ -14550,7 +15408,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingExpr{Exprs: $3.exprs()}
+  }
 
 func_application:
   func_application_name '(' ')'
@@ -15208,6 +16069,7 @@ all_op:
 | NOT_REGIMATCH { $$.val = treecmp.MakeComparisonOperator(treecmp.NotRegIMatch) }
 | AND_AND { $$.val = treecmp.MakeComparisonOperator(treecmp.Overlaps) }
 | AT_AT { $$.val = treecmp.MakeComparisonOperator(treecmp.TSMatches) }
+| RANGE_ADJACENT { $$.val = treecmp.MakeComparisonOperator(treecmp.Adjacent) }
 | '~' { $$.val = tree.MakeUnaryOperator(tree.UnaryComplement) }
 | SQRT { $$.val = tree.MakeUnaryOperator(tree.UnarySqrt) }
 | CBRT { $$.val = tree.MakeUnaryOperator(tree.UnaryCbrt) }
@@ -16160,6 +17022,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALL
 | CALLED
 | CANCEL
 | CANCELQUERY
@@ -16223,6 +17086,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTED
 | ENCRYPTION_PASSPHRASE
@@ -16291,6 +17155,7 @@ unreserved_keyword:
 | INCREMENTAL_LOCATION
 | INDEX
 | INDEXES
+| INHERIT
 | INHERITS
 | INJECT
 | INPUT
@@ -16322,6 +17187,7 @@ unreserved_keyword:
 | LINESTRINGZ
 | LINESTRINGZM
 | LIST
+| LISTEN
 | LOCAL
 | LOCKED
 | LOGIN
@@ -16329,6 +17195,7 @@ unreserved_keyword:
 | LOOKUP
 | LOW
 | MATCH
+| MATCHED
 | MATERIALIZED
 | MAXVALUE
 | MERGE
@@ -16360,6 +17227,7 @@ unreserved_keyword:
 | NO
 | NORMAL
 | NOTHING
+| NOTIFY
 | NO_INDEX_JOIN
 | NO_ZIGZAG_JOIN
 | NO_FULL_SCAN
@@ -16417,6 +17285,7 @@ unreserved_keyword:
 | PRIOR
 | PRIORITY
 | PRIVILEGES
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -16506,6 +17375,7 @@ unreserved_keyword:
 | STABLE
 | START
 | STATE
+| STATEMENT
 | STATEMENTS
 | STATISTICS
 | STDIN
@@ -16626,6 +17496,7 @@ bare_label_keywords:
 | BUNDLE
 | BY
 | CACHE
+| CALL
 | CALLED
 | CANCEL
 | CANCELQUERY
@@ -16712,6 +17583,7 @@ bare_label_keywords:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ELSE
 | ENCODING
 | ENCRYPTED
@@ -16801,6 +17673,7 @@ bare_label_keywords:
 | INDEX_AFTER_ORDER_BY_BEFORE_AT
 | INDEX_BEFORE_NAME_THEN_PAREN
 | INDEX_BEFORE_PAREN
+| INHERIT
 | INHERITS
 | INITIALLY
 | INJECT
@@ -16847,6 +17720,7 @@ bare_label_keywords:
 | LINESTRINGZ
 | LINESTRINGZM
 | LIST
+| LISTEN
 | LOCAL
 | LOCALITY
 | LOCALTIME
@@ -16856,6 +17730,7 @@ bare_label_keywords:
 | LOOKUP
 | LOW
 | MATCH
+| MATCHED
 | MATERIALIZED
 | MAXVALUE
 | MERGE
@@ -16899,6 +17774,7 @@ bare_label_keywords:
 | NOT
 | NOTHING
 | NOTHING_AFTER_RETURNING
+| NOTIFY
 | NOVIEWACTIVITY
 | NOVIEWACTIVITYREDACTED
 | NOVIEWCLUSTERSETTING
@@ -16956,6 +17832,7 @@ bare_label_keywords:
 | PRIOR
 | PRIORITY
 | PRIVILEGES
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -17053,6 +17930,7 @@ bare_label_keywords:
 | STABLE
 | START
 | STATE
+| STATEMENT
 | STATEMENTS
 | STATISTICS
 | STATUS
@@ -57,6 +57,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 )
@@ -718,10 +719,10 @@ https://www.postgresql.org/docs/9.5/catalog-pg-class.html`,
 			tree.MakeDBool(tree.DBool(table.IsPhysicalTable())), // relhaspkey
 			tree.DBoolFalse, // relhasrules
 			tree.DBoolFalse, // relhastriggers
-			tree.DBoolFalse, // relhassubclass
-			zeroVal,         // relfrozenxid
-			tree.DNull,      // relacl
-			relOptions,      // reloptions
+			tree.MakeDBool(tree.DBool(len(table.GetInheritedBy()) > 0)), // relhassubclass
+			zeroVal,    // relfrozenxid
+			tree.DNull, // relacl
+			relOptions, // reloptions
 			// These columns were automatically created by pg_catalog_test's missing column generator.
 			tree.DNull, // relforcerowsecurity
 			tree.DNull, // relispartition
@@ -868,6 +869,18 @@ var (
 	}
 )
 
+// constraintDeferrability returns whether the given constraint was declared
+// DEFERRABLE and INITIALLY DEFERRED.
+func constraintDeferrability(c catalog.Constraint) (deferrable, initiallyDeferred bool) {
+	if fk := c.AsForeignKey(); fk != nil {
+		return fk.IsDeferrable(), fk.IsInitiallyDeferred()
+	}
+	if uwi := c.AsUniqueWithoutIndex(); uwi != nil {
+		return uwi.IsDeferrable(), uwi.IsInitiallyDeferred()
+	}
+	return false, false
+}
+
 func populateTableConstraints(
 	ctx context.Context,
 	p *planner,
@@ -893,6 +906,7 @@ func populateTableConstraints(
 		consrc := tree.DNull
 		conbin := tree.DNull
 		condef := tree.DNull
+		deferrable, initiallyDeferred := constraintDeferrability(c)
 
 		// Determine constraint kind-specific fields.
 		var err error
@@ -993,6 +1007,8 @@ func populateTableConstraints(
 			}
 			f.WriteString(strings.Join(colNames, ", "))
 			f.WriteByte(')')
+			deferrability := tree.MakeConstraintDeferrability(deferrable, initiallyDeferred)
+			f.FormatNode(&deferrability)
 			if !uwoi.IsConstraintValidated() {
 				f.WriteString(" NOT VALID")
 			}
@@ -1024,12 +1040,12 @@ func populateTableConstraints(
 		}
 
 		if err := addRow(
-			conoid,                   // oid
-			dNameOrNull(c.GetName()), // conname
-			namespaceOid,             // connamespace
-			contype,                  // contype
-			tree.DBoolFalse,          // condeferrable
-			tree.DBoolFalse,          // condeferred
+			conoid,                                 // oid
+			dNameOrNull(c.GetName()),               // conname
+			namespaceOid,                           // connamespace
+			contype,                                // contype
+			tree.MakeDBool(tree.DBool(deferrable)), // condeferrable
+			tree.MakeDBool(tree.DBool(initiallyDeferred)),            // condeferred
 			tree.MakeDBool(tree.DBool(!c.IsConstraintUnvalidated())), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -2028,14 +2044,24 @@ func indexDefFromDescriptor(
 }
 
 var pgCatalogInheritsTable = virtualSchemaTable{
-	comment: `table inheritance hierarchy (empty - feature does not exist)
+	comment: `table inheritance hierarchy
 https://www.postgresql.org/docs/9.5/catalog-pg-inherits.html`,
 	schema: vtable.PGCatalogInherits,
-	populate: func(_ context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// Table inheritance is not supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual tables do not inherit */
+			func(_ catalog.DatabaseDescriptor, _ catalog.SchemaDescriptor, table catalog.TableDescriptor) error {
+				for i, parentID := range table.GetInheritsFrom() {
+					if err := addRow(
+						tableOid(table.GetID()),      // inhrelid
+						tableOid(parentID),           // inhparent
+						tree.NewDInt(tree.DInt(i+1)), // inhseqno
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogLanguageTable = virtualSchemaTable{
@@ -2520,8 +2546,7 @@ func addPgProcUDFRow(
 		if err := argTypes.Append(tree.NewDOid(param.Type.Oid())); err != nil {
 			return err
 		}
-		// We only support IN arguments at the moment.
-		if err := argModes.Append(tree.NewDString("i")); err != nil {
+		if err := argModes.Append(tree.NewDString(funcParamMode(param.Class))); err != nil {
 			return err
 		}
 		if len(param.Name) > 0 {
@@ -2535,6 +2560,10 @@ func addPgProcUDFRow(
 	if foundAnyArgNames {
 		argNames = argNamesArray
 	}
+	kind := "f"
+	if fnDesc.IsProcedure() {
+		kind = "p"
+	}
 
 	return addRow(
 		tree.NewDOid(catid.FuncIDToOID(fnDesc.GetID())), // oid
@@ -2569,12 +2598,27 @@ func addPgProcUDFRow(
 		tree.DNull,                                       // probin
 		tree.DNull,                                       // proconfig
 		tree.DNull,                                       // proacl
-		tree.NewDString("f"),                             // prokind
+		tree.NewDString(kind),                            // prokind
 		// These columns were automatically created by pg_catalog_test's missing column generator.
 		tree.DNull, // prosupport
 	)
 }
 
+// funcParamMode returns the pg_proc.proargmodes code of the given parameter
+// class.
+func funcParamMode(class catpb.Function_Param_Class) string {
+	switch class {
+	case catpb.Function_Param_OUT:
+		return "o"
+	case catpb.Function_Param_IN_OUT:
+		return "b"
+	case catpb.Function_Param_VARIADIC:
+		return "v"
+	default:
+		return "i"
+	}
+}
+
 var pgCatalogProcTable = virtualSchemaTable{
 	comment: `built-in functions (incomplete)
 https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
@@ -2677,16 +2721,34 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 }
 
 var pgCatalogRangeTable = virtualSchemaTable{
-	comment: `range types (empty - feature does not exist)
+	comment: `range types (incomplete)
 https://www.postgresql.org/docs/9.5/catalog-pg-range.html`,
 	schema: vtable.PGCatalogRange,
-	populate: func(_ context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// We currently do not support any range types, so this table is empty.
-		// This table should be populated when any range types are added to
-		// oidToDatum (and therefore pg_type).
-		return nil
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		addRangeRow := func(typOID, subtypeOID oid.Oid) error {
+			return addRow(
+				tree.NewDOid(typOID),     // rngtypid
+				tree.NewDOid(subtypeOID), // rngsubtype
+				oidZero,                  // rngcollation
+				oidZero,                  // rngsubopc
+				oidZero,                  // rngcanonical
+				oidZero,                  // rngsubdiff
+			)
+		}
+		for _, typ := range types.RangeTypes {
+			if err := addRangeRow(typ.Oid(), typ.RangeSubtype().Oid()); err != nil {
+				return err
+			}
+		}
+		return forEachTypeDesc(ctx, p, dbContext, func(_ catalog.DatabaseDescriptor, _ catalog.SchemaDescriptor, typDesc catalog.TypeDescriptor) error {
+			r := typDesc.AsRangeTypeDescriptor()
+			if r == nil || r.GetMultirangeTypeID() == descpb.InvalidID {
+				// We only want to iterate over range types.
+				return nil
+			}
+			return addRangeRow(catid.TypeIDToOID(r.GetID()), r.RangeSubtype().Oid())
+		})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogRewriteTable = virtualSchemaTable{
@@ -3071,17 +3133,16 @@ https://www.postgresql.org/docs/9.5/catalog-pg-trigger.html`,
 }
 
 var (
-	typTypeBase      = tree.NewDString("b")
-	typTypeComposite = tree.NewDString("c")
-	typTypeDomain    = tree.NewDString("d")
-	typTypeEnum      = tree.NewDString("e")
-	typTypePseudo    = tree.NewDString("p")
-	typTypeRange     = tree.NewDString("r")
+	typTypeBase       = tree.NewDString("b")
+	typTypeComposite  = tree.NewDString("c")
+	typTypeDomain     = tree.NewDString("d")
+	typTypeEnum       = tree.NewDString("e")
+	typTypePseudo     = tree.NewDString("p")
+	typTypeRange      = tree.NewDString("r")
+	typTypeMultirange = tree.NewDString("m")
 
 	// Avoid unused warning for constants.
-	_ = typTypeDomain
 	_ = typTypePseudo
-	_ = typTypeRange
 
 	// See https://www.postgresql.org/docs/9.6/static/catalog-pg-type.html#CATALOG-TYPCATEGORY-TABLE.
 	typCategoryArray       = tree.NewDString("A")
@@ -3103,7 +3164,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typCategoryEnum
 	_ = typCategoryGeometric
-	_ = typCategoryRange
 	_ = typCategoryBitString
 
 	commaTypDelim = tree.NewDString(",")
@@ -3199,31 +3259,55 @@ func addPGTypeRow(
 		builtinPrefix = "record_"
 		typType = typTypeComposite
 		typArray = tree.NewDOid(types.CalcArrayOid(typ))
-	case types.VoidFamily:
-		// void does not have an array type.
+	case types.RangeFamily:
+		typType = typTypeRange
+		typArray = tree.NewDOid(types.CalcArrayOid(typ))
+	case types.MultirangeFamily:
+		typType = typTypeMultirange
+		typArray = tree.NewDOid(types.CalcArrayOid(typ))
+	case types.VoidFamily, types.TriggerFamily:
+		// void and trigger do not have an array type.
 	default:
 		typArray = tree.NewDOid(types.CalcArrayOid(typ))
 	}
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
+	typOid := typ.Oid()
+	typNotNull := tree.DBoolFalse
+	typBaseType := oidZero
+	typDefault := tree.DNull
+	if typ.IsDomain() {
+		// Domains share the OID of their base type, so the OID of the domain
+		// itself is reported here, and the base type in typbasetype.
+		typOid = typ.DomainOID()
+		typType = typTypeDomain
+		typArray = oidZero
+		typBaseType = tree.NewDOid(typ.Oid())
+		if domain := typ.TypeMeta.DomainData; domain != nil {
+			typNotNull = tree.MakeDBool(tree.DBool(domain.NotNull))
+			if domain.DefaultExpr != nil {
+				typDefault = tree.NewDString(*domain.DefaultExpr)
+			}
+		}
+	}
 	typname := typ.PGName()
 	typDelim := tree.NewDString(typ.Delimiter())
 	return addRow(
-		tree.NewDOid(typ.Oid()), // oid
-		tree.NewDName(typname),  // typname
-		nspOid,                  // typnamespace
-		owner,                   // typowner
-		typLen(typ),             // typlen
-		typByVal(typ),           // typbyval (is it fixedlen or not)
-		typType,                 // typtype
-		cat,                     // typcategory
-		tree.DBoolFalse,         // typispreferred
-		tree.DBoolTrue,          // typisdefined
-		typDelim,                // typdelim
-		oidZero,                 // typrelid
-		typElem,                 // typelem
-		typArray,                // typarray
+		tree.NewDOid(typOid),   // oid
+		tree.NewDName(typname), // typname
+		nspOid,                 // typnamespace
+		owner,                  // typowner
+		typLen(typ),            // typlen
+		typByVal(typ),          // typbyval (is it fixedlen or not)
+		typType,                // typtype
+		cat,                    // typcategory
+		tree.DBoolFalse,        // typispreferred
+		tree.DBoolTrue,         // typisdefined
+		typDelim,               // typdelim
+		oidZero,                // typrelid
+		typElem,                // typelem
+		typArray,               // typarray
 
 		// regproc references
 		h.RegProc(builtinPrefix+"in"),   // typinput
@@ -3236,13 +3320,13 @@ func addPGTypeRow(
 
 		tree.DNull,      // typalign
 		tree.DNull,      // typstorage
-		tree.DBoolFalse, // typnotnull
-		oidZero,         // typbasetype
+		typNotNull,      // typnotnull
+		typBaseType,     // typbasetype
 		negOneVal,       // typtypmod
 		zeroVal,         // typndims
 		typColl(typ, h), // typcollation
 		tree.DNull,      // typdefaultbin
-		tree.DNull,      // typdefault
+		typDefault,      // typdefault
 		tree.DNull,      // typacl
 	)
 }
@@ -3841,13 +3925,30 @@ var pgCatalogStatioSysTablesTable = virtualSchemaTable{
 	unimplemented: true,
 }
 
+// defaultTSParserOid is the OID of the default text search parser in Postgres,
+// which is the only parser that is supported.
+var defaultTSParserOid = tree.NewDOid(3722)
+
 var pgCatalogTsConfigTable = virtualSchemaTable{
-	comment: "pg_ts_config was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogTsConfig,
+	comment: `text search configurations
+https://www.postgresql.org/docs/current/catalog-pg-ts-config.html`,
+	schema: vtable.PgCatalogTsConfig,
 	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		namespaceOid := tree.NewDOid(catconstants.PgCatalogID)
+		for _, name := range tsearch.TextSearchConfigNames() {
+			if err := addRow(
+				h.TextSearchConfigOid(name), // oid
+				tree.NewDName(name),         // cfgname
+				namespaceOid,                // cfgnamespace
+				tree.DNull,                  // cfgowner
+				defaultTSParserOid,          // cfgparser
+			); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
-	unimplemented: true,
 }
 
 var pgCatalogStatsTable = virtualSchemaTable{
@@ -3960,12 +4061,12 @@ https://www.postgresql.org/docs/14/view-pg-cursors.html`,
 				return err
 			}
 			if err := addRow(
-				tree.NewDString(string(name)), /* name */
-				tree.NewDString(c.statement),  /* statement */
-				tree.DBoolFalse,               /* is_holdable */
-				tree.DBoolFalse,               /* is_binary */
-				tree.DBoolFalse,               /* is_scrollable */
-				tz,                            /* creation_date */
+				tree.NewDString(string(name)),          /* name */
+				tree.NewDString(c.statement),           /* statement */
+				tree.MakeDBool(tree.DBool(c.withHold)), /* is_holdable */
+				tree.DBoolFalse,                        /* is_binary */
+				tree.MakeDBool(tree.DBool(c.scroll)),   /* is_scrollable */
+				tz,                                     /* creation_date */
 			); err != nil {
 				return err
 			}
@@ -4398,7 +4499,8 @@ var pgCatalogAvailableExtensionVersionsTable = virtualSchemaTable{
 // object identifiers for types are not arbitrary, but instead need to be kept in
 // sync with Postgres.
 func typOid(typ *types.T) tree.Datum {
-	return tree.NewDOid(typ.Oid())
+	// Columns of domains report the OID of the domain, not of its base type.
+	return tree.NewDOid(typ.UserDefinedOID())
 }
 
 func typLen(typ *types.T) *tree.DInt {
@@ -4452,6 +4554,8 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.TimestampTZFamily: typCategoryDateTime,
 	types.TSQueryFamily:     typCategoryUserDefined,
 	types.TSVectorFamily:    typCategoryUserDefined,
+	types.RangeFamily:       typCategoryRange,
+	types.MultirangeFamily:  typCategoryRange,
 	types.ArrayFamily:       typCategoryArray,
 	types.TupleFamily:       typCategoryPseudo,
 	types.OidFamily:         typCategoryNumeric,
@@ -4459,6 +4563,7 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.INetFamily:        typCategoryNetworkAddr,
 	types.UnknownFamily:     typCategoryUnknown,
 	types.VoidFamily:        typCategoryPseudo,
+	types.TriggerFamily:     typCategoryPseudo,
 }
 
 func typCategory(typ *types.T) tree.Datum {
@@ -4680,6 +4785,7 @@ const (
 	rewriteTypeTag
 	dbSchemaRoleTypeTag
 	castTypeTag
+	textSearchConfigTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -4815,6 +4921,12 @@ func (h oidHasher) CollationOid(collation string) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) TextSearchConfigOid(name string) *tree.DOid {
+	h.writeTypeTag(textSearchConfigTypeTag)
+	h.writeStr(name)
+	return h.getOid()
+}
+
 func (h oidHasher) OperatorOid(name string, leftType, rightType, returnType *tree.DOid) *tree.DOid {
 	h.writeTypeTag(operatorTypeTag)
 	h.writeStr(name)
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -59,6 +60,7 @@ type commandResult struct {
 	// buffer contains items that are sent before the connection is closed.
 	buffer struct {
 		notices            []pgnotice.Notice
+		notifications      []notify.Notification
 		paramStatusUpdates []paramStatusUpdate
 	}
 
@@ -143,6 +145,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		}
 	}
 
+	for _, notification := range r.buffer.notifications {
+		if err := r.conn.bufferNotification(notification); err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err when sending notification"))
+		}
+	}
+
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
@@ -278,6 +286,11 @@ func (r *commandResult) BufferNotice(notice pgnotice.Notice) {
 	r.buffer.notices = append(r.buffer.notices, notice)
 }
 
+// BufferNotification is part of the sql.NotificationResult interface.
+func (r *commandResult) BufferNotification(notification notify.Notification) {
+	r.buffer.notifications = append(r.buffer.notifications, notification)
+}
+
 // SetColumns is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.assertNotReleased()
@@ -534,6 +547,10 @@ func (r *limitedCommandResult) moreResultsNeeded(ctx context.Context) error {
 			if err := r.conn.Flush(r.pos); err != nil {
 				return err
 			}
+		case sql.DeliverNotifications:
+			// The session is not idle while the portal is open, so the
+			// notifications stay queued until the next Sync.
+			r.conn.stmtBuf.AdvanceOne()
 		default:
 			// If the portal is immediately followed by a COMMIT, we can proceed and
 			// let the portal be destroyed at the end of the transaction.
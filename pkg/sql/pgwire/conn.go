@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -754,6 +755,14 @@ func (c *conn) bufferNotice(ctx context.Context, noticeErr pgnotice.Notice) erro
 	return c.writeErrFields(ctx, noticeErr, &c.writerState.buf)
 }
 
+func (c *conn) bufferNotification(n notify.Notification) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(n.PID)
+	c.msgBuilder.writeTerminatedString(n.Channel)
+	c.msgBuilder.writeTerminatedString(n.Payload)
+	return c.msgBuilder.finishMsg(&c.writerState.buf)
+}
+
 func (c *conn) sendInitialConnData(
 	ctx context.Context, sqlServer *sql.Server, onDefaultIntSizeChange func(newSize int32),
 ) (sql.ConnectionHandler, error) {
@@ -1662,9 +1662,8 @@ func TestSetSessionArguments(t *testing.T) {
 
 	expectedOptions := map[string]string{
 		"search_path": "public, testsp",
-		// setting an isolation level is a noop:
-		// all transactions execute with serializable isolation.
-		"default_transaction_isolation": "serializable",
+		// READ UNCOMMITTED is upgraded to READ COMMITTED.
+		"default_transaction_isolation": "read committed",
 		"application_name":              "test",
 		"datestyle":                     "ISO, YMD",
 		"intervalstyle":                 "iso_8601",
@@ -0,0 +1,136 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire_test
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgconn"
+	pgx "github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListenNotify checks that notifications published with NOTIFY and
+// pg_notify() are delivered asynchronously to the sessions listening on their
+// channel, once the publishing transaction commits.
+func TestListenNotify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), "TestListenNotify", url.User(username.RootUser),
+	)
+	defer cleanup()
+	conn, err := pgx.Connect(ctx, pgURL.String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close(ctx) }()
+
+	_, err = conn.Exec(ctx, "LISTEN foo")
+	require.NoError(t, err)
+
+	waitForNotification := func() *pgconn.Notification {
+		waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		defer cancel()
+		n, err := conn.WaitForNotification(waitCtx)
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("notify", func(t *testing.T) {
+		sqlDB.Exec(t, "NOTIFY foo, 'hello'")
+		n := waitForNotification()
+		require.Equal(t, "foo", n.Channel)
+		require.Equal(t, "hello", n.Payload)
+	})
+
+	t.Run("pg_notify", func(t *testing.T) {
+		// The notification published in the rolled back transaction must not be
+		// delivered.
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		_, err = tx.Exec("NOTIFY foo, 'rolled back'")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		sqlDB.Exec(t, "SELECT pg_notify('foo', 'world')")
+		n := waitForNotification()
+		require.Equal(t, "foo", n.Channel)
+		require.Equal(t, "world", n.Payload)
+	})
+
+	t.Run("unlisten", func(t *testing.T) {
+		_, err := conn.Exec(ctx, "UNLISTEN foo")
+		require.NoError(t, err)
+		_, err = conn.Exec(ctx, "LISTEN bar")
+		require.NoError(t, err)
+
+		sqlDB.Exec(t, "NOTIFY foo, 'ignored'")
+		sqlDB.Exec(t, "NOTIFY bar")
+		n := waitForNotification()
+		require.Equal(t, "bar", n.Channel)
+		require.Equal(t, "", n.Payload)
+	})
+
+	t.Run("in transaction", func(t *testing.T) {
+		// Notifications arriving while the listening session is in a transaction
+		// are delivered once the transaction ends.
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		sqlDB.Exec(t, "NOTIFY bar, 'deferred'")
+		require.NoError(t, tx.Commit(ctx))
+		n := waitForNotification()
+		require.Equal(t, "bar", n.Channel)
+		require.Equal(t, "deferred", n.Payload)
+	})
+
+	t.Run("pid", func(t *testing.T) {
+		// The notification carries the backend PID of the publishing session.
+		notifier, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer func() { _ = notifier.Close() }()
+		var pid int32
+		require.NoError(t, notifier.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid))
+		_, err = notifier.ExecContext(ctx, "NOTIFY bar, 'pid'")
+		require.NoError(t, err)
+		n := waitForNotification()
+		require.Equal(t, "pid", n.Payload)
+		require.Equal(t, uint32(pid), n.PID)
+	})
+
+	t.Run("commit order", func(t *testing.T) {
+		// Notifications are delivered once each, in the order in which their
+		// transactions committed.
+		const count = 20
+		for i := 0; i < count; i++ {
+			sqlDB.Exec(t, "SELECT pg_notify('bar', $1)", strconv.Itoa(i))
+		}
+		sqlDB.Exec(t, "NOTIFY bar, 'last'")
+		for i := 0; i < count; i++ {
+			require.Equal(t, strconv.Itoa(i), waitForNotification().Payload)
+		}
+		require.Equal(t, "last", waitForNotification().Payload)
+	})
+}
@@ -544,6 +544,26 @@ func DecodeDatum(
 			}
 			return &tree.DTSVector{TSVector: ret}, nil
 		}
+		switch typ.Family() {
+		case types.RangeFamily:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			d, _, err := tree.ParseDRangeFromString(evalCtx, string(b), typ)
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		case types.MultirangeFamily:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			d, _, err := tree.ParseDMultirangeFromString(evalCtx, string(b), typ)
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		}
 		if typ.Family() == types.ArrayFamily {
 			// Arrays come in in their string form, so we parse them as such and later
 			// convert them to their actual datum form.
@@ -855,6 +875,12 @@ func DecodeDatum(
 			if typ.Family() == types.TupleFamily {
 				return decodeBinaryTuple(ctx, evalCtx, b)
 			}
+			if typ.Family() == types.RangeFamily {
+				return decodeBinaryRange(ctx, evalCtx, typ, b)
+			}
+			if typ.Family() == types.MultirangeFamily {
+				return decodeBinaryMultirange(ctx, evalCtx, typ, b)
+			}
 			if typ.Family() == types.OidFamily {
 				if len(b) < 4 {
 					return nil, pgerror.Newf(pgcode.ProtocolViolation, "oid requires 4 bytes for binary format")
@@ -1166,6 +1192,94 @@ func decodeBinaryTuple(ctx context.Context, evalCtx *eval.Context, b []byte) (tr
 
 }
 
+// decodeBinaryRange decodes the Postgres binary format of a range of type typ,
+// which is a flags byte followed by each finite bound, lower first, with its
+// int32 length prefix.
+func decodeBinaryRange(
+	ctx context.Context, evalCtx *eval.Context, typ *types.T, b []byte,
+) (tree.Datum, error) {
+	if len(b) < 1 {
+		return nil, NewInvalidBinaryRepresentationErrorf("insufficient data: %d", len(b))
+	}
+	flags := b[0]
+	b = b[1:]
+	if flags&tree.RangeFlagEmpty != 0 {
+		if len(b) > 0 {
+			return nil, NewInvalidBinaryRepresentationErrorf("unexpected data after empty range")
+		}
+		return tree.MakeEmptyDRange(typ), nil
+	}
+	lower, upper := tree.Datum(tree.DNull), tree.Datum(tree.DNull)
+	var err error
+	if flags&tree.RangeFlagLowerInf == 0 {
+		if lower, b, err = decodeBinaryRangeElement(ctx, evalCtx, typ.RangeSubtype(), b); err != nil {
+			return nil, err
+		}
+	}
+	if flags&tree.RangeFlagUpperInf == 0 {
+		if upper, b, err = decodeBinaryRangeElement(ctx, evalCtx, typ.RangeSubtype(), b); err != nil {
+			return nil, err
+		}
+	}
+	if len(b) > 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("unexpected data after range bounds")
+	}
+	// NewDRange canonicalizes the range, so a range of a discrete subtype sent
+	// by a client with inclusive upper bound is decoded like in Postgres.
+	return tree.NewDRange(
+		typ, lower, upper, flags&tree.RangeFlagLowerInc != 0, flags&tree.RangeFlagUpperInc != 0,
+	)
+}
+
+// decodeBinaryMultirange decodes the Postgres binary format of a multirange of
+// type typ, which is the int32 number of ranges followed by each range with
+// its int32 length prefix.
+func decodeBinaryMultirange(
+	ctx context.Context, evalCtx *eval.Context, typ *types.T, b []byte,
+) (tree.Datum, error) {
+	if len(b) < 4 {
+		return nil, NewInvalidBinaryRepresentationErrorf("insufficient data: %d", len(b))
+	}
+	n := int32(binary.BigEndian.Uint32(b))
+	b = b[4:]
+	if n < 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("invalid number of ranges: %d", n)
+	}
+	ranges := make([]*tree.DRange, 0, n)
+	for i := int32(0); i < n; i++ {
+		d, rest, err := decodeBinaryRangeElement(ctx, evalCtx, typ.MultirangeContents(), b)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, tree.MustBeDRange(d))
+		b = rest
+	}
+	if len(b) > 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("unexpected data after multirange")
+	}
+	return tree.NewDMultirange(typ, ranges)
+}
+
+// decodeBinaryRangeElement decodes a range bound or a range of a multirange of
+// type typ with its int32 length prefix from b, returning the remaining bytes.
+func decodeBinaryRangeElement(
+	ctx context.Context, evalCtx *eval.Context, typ *types.T, b []byte,
+) (_ tree.Datum, rest []byte, _ error) {
+	if len(b) < 4 {
+		return nil, nil, NewInvalidBinaryRepresentationErrorf("insufficient data: %d", len(b))
+	}
+	n := int32(binary.BigEndian.Uint32(b))
+	b = b[4:]
+	if n < 0 || int(n) > len(b) {
+		return nil, nil, NewInvalidBinaryRepresentationErrorf("invalid element length: %d", n)
+	}
+	d, err := DecodeDatum(ctx, evalCtx, typ, FormatBinary, b[:n])
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, b[n:], nil
+}
+
 var invalidUTF8Error = pgerror.Newf(pgcode.CharacterNotInRepertoire, "invalid UTF-8 sequence")
 
 var (
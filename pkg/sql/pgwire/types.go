@@ -258,6 +258,14 @@ func writeTextDatumNotNull(
 		b.textFormatter.FormatNode(d)
 		b.writeFromFmtCtx(b.textFormatter)
 
+	case *tree.DRange:
+		b.textFormatter.FormatNode(v)
+		b.writeFromFmtCtx(b.textFormatter)
+
+	case *tree.DMultirange:
+		b.textFormatter.FormatNode(v)
+		b.writeFromFmtCtx(b.textFormatter)
+
 	case *tree.DOid:
 		b.writeLengthPrefixedDatum(v)
 
@@ -797,6 +805,44 @@ func writeBinaryDatumNotNull(
 		lengthToWrite := b.Len() - (initialLen + 4)
 		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
 
+	case *tree.DRange:
+		initialLen := b.Len()
+
+		// Reserve bytes for writing length later.
+		b.putInt32(int32(0))
+
+		// Like in Postgres, the flags byte is followed by each finite bound,
+		// lower first, encoded with its length prefix.
+		b.writeByte(v.Flags())
+		subtype := v.Typ.RangeSubtype()
+		if !v.Empty {
+			if v.Lower != tree.DNull {
+				writeBinaryDatumNotNull(ctx, b, v.Lower, sessionLoc, subtype)
+			}
+			if v.Upper != tree.DNull {
+				writeBinaryDatumNotNull(ctx, b, v.Upper, sessionLoc, subtype)
+			}
+		}
+
+		lengthToWrite := b.Len() - (initialLen + 4)
+		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
+
+	case *tree.DMultirange:
+		initialLen := b.Len()
+
+		// Reserve bytes for writing length later.
+		b.putInt32(int32(0))
+
+		// Put the number of ranges, followed by each range with its length
+		// prefix.
+		b.putInt32(int32(len(v.Ranges)))
+		for _, r := range v.Ranges {
+			writeBinaryDatumNotNull(ctx, b, r, sessionLoc, v.Typ.MultirangeContents())
+		}
+
+		lengthToWrite := b.Len() - (initialLen + 4)
+		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
+
 	case *tree.DJSON:
 		writeBinaryJSON(b, v.JSON, t)
 
@@ -48,6 +48,8 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 	// Nodes that define their own schema.
 	case *delayedNode:
 		return n.columns
+	case *expandNode:
+		return n.columns
 	case *groupNode:
 		return n.columns
 	case *joinNode:
@@ -68,6 +70,8 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 		return n.columns
 	case *showTraceNode:
 		return n.columns
+	case *callNode:
+		return n.columns
 	case *zeroNode:
 		return n.columns
 	case *deleteNode:
@@ -80,6 +84,8 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 		return n.columns
 	case *upsertNode:
 		return n.columns
+	case *mergeNode:
+		return n.columns
 	case *indexJoinNode:
 		return n.resultColumns
 	case *projectSetNode:
@@ -58,7 +58,7 @@ func planReqOrdering(plan planNode) ReqOrdering {
 		return n.reqOrdering
 	case *insertNode, *insertFastPathNode:
 		// TODO(knz): RETURNING is ordered by the PK.
-	case *updateNode, *upsertNode:
+	case *updateNode, *upsertNode, *mergeNode:
 		// After an update, the original order may have been destroyed.
 		// For example, if the PK is updated by a SET expression.
 		// So we can't assume any ordering.
@@ -220,6 +220,14 @@ type planner struct {
 
 	createdSequences createdSequences
 
+	deferredConstraints deferredConstraints
+
+	notifications notifications
+
+	// storedProcTxnState is set when a stored procedure invoked by a CALL
+	// statement requests that the current transaction be committed or aborted.
+	storedProcTxnState storedProcTxnState
+
 	// autoCommit indicates whether the plan is allowed (but not required) to
 	// commit the transaction along with other KV operations. Committing the txn
 	// might be beneficial because it may enable the 1PC optimization. Note that
@@ -427,6 +435,8 @@ func newInternalPlanner(
 	p.queryCacheSession.Init()
 	p.optPlanningCtx.init(p)
 	p.createdSequences = emptyCreatedSequences{}
+	p.deferredConstraints = emptyDeferredConstraints{}
+	p.notifications = emptyNotifications{}
 
 	p.schemaResolver.descCollection = p.Descriptors()
 	p.schemaResolver.sessionDataStack = sds
@@ -842,6 +852,7 @@ func (p *planner) resetPlanner(
 	p.semaCtx.IntervalStyle = sd.GetIntervalStyle()
 
 	p.autoCommit = false
+	p.storedProcTxnState = storedProcTxnState{}
 
 	p.schemaResolver.txn = txn
 	p.schemaResolver.sessionDataStack = p.EvalContext().SessionDataStack
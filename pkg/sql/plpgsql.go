@@ -0,0 +1,686 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// plpgsqlControl describes how control leaves a PL/pgSQL statement.
+type plpgsqlControl int
+
+const (
+	// plpgsqlControlNone indicates that execution continues with the next
+	// statement.
+	plpgsqlControlNone plpgsqlControl = iota
+	// plpgsqlControlExit indicates that an EXIT statement was executed.
+	plpgsqlControlExit
+	// plpgsqlControlContinue indicates that a CONTINUE statement was executed.
+	plpgsqlControlContinue
+	// plpgsqlControlReturn indicates that a RETURN statement was executed.
+	plpgsqlControlReturn
+)
+
+// plpgsqlInterpreter executes the compiled body of a PL/pgSQL function. The
+// rows returned by the function are added to res.
+type plpgsqlInterpreter struct {
+	p    *planner
+	txn  *kv.Txn
+	expr *tree.RoutineExpr
+	prog *plpgsqltree.Program
+	res  rowResultWriter
+
+	// vars contains the current value of each variable of the program.
+	vars tree.Datums
+
+	// rowCount is the number of rows processed by the most recent SQL
+	// statement, as reported by GET DIAGNOSTICS.
+	rowCount int
+
+	// handledErrs is the stack of errors being handled by the exception
+	// handlers that are currently executing. It is used by RAISE statements
+	// without parameters, which re-throw the current error.
+	handledErrs []error
+
+	// control and controlLabel describe how control is leaving the statement
+	// that is executing.
+	control      plpgsqlControl
+	controlLabel string
+}
+
+// runPLpgSQL executes the PL/pgSQL program of the given routine with the given
+// arguments.
+func (g *routineGenerator) runPLpgSQL(ctx context.Context, txn *kv.Txn, rrw rowResultWriter) error {
+	prog := g.expr.Program.(*plpgsqltree.Program)
+	it := plpgsqlInterpreter{
+		p:    g.p,
+		txn:  txn,
+		expr: g.expr,
+		prog: prog,
+		res:  rrw,
+		vars: make(tree.Datums, len(prog.Vars)),
+	}
+	for i := range it.vars {
+		it.vars[i] = tree.DNull
+	}
+	for i := 0; i < prog.NumParams && i < len(g.args); i++ {
+		if err := it.setVar(ctx, plpgsqltree.VarOrdinal(i), g.args[i]); err != nil {
+			return err
+		}
+	}
+	it.vars[prog.Found] = tree.DBoolFalse
+	if err := it.execBlock(ctx, prog.Body); err != nil {
+		return err
+	}
+	if it.control != plpgsqlControlReturn && !prog.SetReturning &&
+		g.expr.ResolvedType().Family() != types.VoidFamily {
+		return pgerror.New(pgcode.RoutineExceptionFunctionExecutedNoReturnStatement,
+			"control reached end of function without RETURN")
+	}
+	return nil
+}
+
+// runExpr evaluates the expression with the given ordinal, calling fn for the
+// value of each row that it produces.
+func (it *plpgsqlInterpreter) runExpr(
+	ctx context.Context, ord plpgsqltree.ExprOrdinal, fn func(d tree.Datum) error,
+) (err error) {
+	var g routineGenerator
+	g.init(it.p, it.expr.ProgramExprs[ord], it.vars)
+	defer g.Close(ctx)
+	if err := g.Start(ctx, it.txn); err != nil {
+		return err
+	}
+	for {
+		ok, err := g.Next(ctx)
+		if err != nil || !ok {
+			return err
+		}
+		row, err := g.Values()
+		if err != nil {
+			return err
+		}
+		if err := fn(row[0]); err != nil {
+			return err
+		}
+	}
+}
+
+// evalExpr returns the value of the expression with the given ordinal, which
+// produces at most one row. It returns NULL if there are no rows.
+func (it *plpgsqlInterpreter) evalExpr(
+	ctx context.Context, ord plpgsqltree.ExprOrdinal,
+) (tree.Datum, error) {
+	res := tree.Datum(tree.DNull)
+	err := it.runExpr(ctx, ord, func(d tree.Datum) error {
+		res = d
+		return nil
+	})
+	return res, err
+}
+
+// evalCond returns true if the condition with the given ordinal is true. A
+// NULL condition is false.
+func (it *plpgsqlInterpreter) evalCond(
+	ctx context.Context, ord plpgsqltree.ExprOrdinal,
+) (bool, error) {
+	d, err := it.evalExpr(ctx, ord)
+	if err != nil {
+		return false, err
+	}
+	return d == tree.DBoolTrue, nil
+}
+
+// evalInt returns the value of the INT expression with the given ordinal. It
+// returns an error if the value is NULL.
+func (it *plpgsqlInterpreter) evalInt(
+	ctx context.Context, ord plpgsqltree.ExprOrdinal, what string,
+) (int64, error) {
+	d, err := it.evalExpr(ctx, ord)
+	if err != nil {
+		return 0, err
+	}
+	if d == tree.DNull {
+		return 0, pgerror.Newf(pgcode.NullValueNotAllowed, "%s of FOR loop cannot be null", what)
+	}
+	return int64(tree.MustBeDInt(d)), nil
+}
+
+// setVar assigns a value to a variable. If the variable is a composite
+// variable, its fields are also assigned.
+func (it *plpgsqlInterpreter) setVar(
+	ctx context.Context, ord plpgsqltree.VarOrdinal, d tree.Datum,
+) error {
+	v := &it.prog.Vars[ord]
+	if d == tree.DNull && v.NotNull {
+		return pgerror.Newf(pgcode.NullValueNotAllowed,
+			"null value cannot be assigned to variable %q declared NOT NULL", v.Name)
+	}
+	it.vars[ord] = d
+	for i, field := range v.Fields {
+		fieldVal := tree.Datum(tree.DNull)
+		if t, ok := d.(*tree.DTuple); ok && i < len(t.D) {
+			fieldVal = t.D[i]
+		}
+		it.vars[field] = fieldVal
+	}
+	return nil
+}
+
+// setTargets assigns a value produced by a query to the given target
+// variables. If there are multiple targets, the value is a tuple with an
+// element for each target.
+func (it *plpgsqlInterpreter) setTargets(
+	ctx context.Context, targets []plpgsqltree.VarOrdinal, d tree.Datum,
+) error {
+	if len(targets) == 1 {
+		return it.setVar(ctx, targets[0], d)
+	}
+	for i, target := range targets {
+		elem := tree.Datum(tree.DNull)
+		if t, ok := d.(*tree.DTuple); ok {
+			elem = t.D[i]
+		}
+		if err := it.setVar(ctx, target, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFound sets the value of the FOUND variable.
+func (it *plpgsqlInterpreter) setFound(found bool) {
+	it.vars[it.prog.Found] = tree.MakeDBool(tree.DBool(found))
+}
+
+func (it *plpgsqlInterpreter) execBlock(ctx context.Context, b *plpgsqltree.ProgBlock) error {
+	for _, init := range b.Inits {
+		d := tree.Datum(tree.DNull)
+		if init.Expr != plpgsqltree.NoExpr {
+			var err error
+			if d, err = it.evalExpr(ctx, init.Expr); err != nil {
+				return err
+			}
+		}
+		if err := it.setVar(ctx, init.Var, d); err != nil {
+			return err
+		}
+	}
+	var err error
+	if len(b.Handlers) == 0 {
+		err = it.execStmts(ctx, b.Body)
+	} else {
+		err = it.execBlockWithHandlers(ctx, b)
+	}
+	if err != nil {
+		return err
+	}
+	if it.control == plpgsqlControlExit && it.controlLabel != "" && it.controlLabel == b.Label {
+		it.control, it.controlLabel = plpgsqlControlNone, ""
+	}
+	return nil
+}
+
+// execBlockWithHandlers executes the body of a block with an EXCEPTION clause.
+// The body is executed within a savepoint, so that its effects can be rolled
+// back if the body fails. The savepoint is released before the block returns,
+// whether or not the error is caught by one of the handlers, so that nested
+// blocks don't leave savepoints behind when an error propagates through them.
+func (it *plpgsqlInterpreter) execBlockWithHandlers(
+	ctx context.Context, b *plpgsqltree.ProgBlock,
+) error {
+	sp, err := it.txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	bodyErr := it.execStmts(ctx, b.Body)
+	if bodyErr == nil {
+		return it.txn.ReleaseSavepoint(ctx, sp)
+	}
+	if err := it.txn.RollbackToSavepoint(ctx, sp); err != nil {
+		return errors.WithSecondaryError(bodyErr, err)
+	}
+	if err := it.txn.ReleaseSavepoint(ctx, sp); err != nil {
+		return errors.WithSecondaryError(bodyErr, err)
+	}
+	handler := matchPLpgSQLHandler(b.Handlers, bodyErr)
+	if handler == nil {
+		return bodyErr
+	}
+	it.control, it.controlLabel = plpgsqlControlNone, ""
+	it.vars[handler.SQLState] = tree.NewDString(pgerror.GetPGCode(bodyErr).String())
+	it.vars[handler.SQLErrM] = tree.NewDString(pgerror.Flatten(bodyErr).Message)
+	it.handledErrs = append(it.handledErrs, bodyErr)
+	defer func() { it.handledErrs = it.handledErrs[:len(it.handledErrs)-1] }()
+	return it.execStmts(ctx, handler.Body)
+}
+
+// matchPLpgSQLHandler returns the first handler that catches the given error,
+// or nil if there is none.
+func matchPLpgSQLHandler(handlers []plpgsqltree.ProgHandler, err error) *plpgsqltree.ProgHandler {
+	code := pgerror.GetPGCode(err).String()
+	for i := range handlers {
+		for _, cond := range handlers[i].Codes {
+			if cond.Others {
+				// OTHERS does not catch query cancellation, to ensure that a
+				// function cannot prevent itself from being canceled.
+				if code != pgcode.QueryCanceled.String() && !errors.HasAssertionFailure(err) {
+					return &handlers[i]
+				}
+				continue
+			}
+			if cond.Code == code {
+				return &handlers[i]
+			}
+			// A code which ends in "000" matches any code in its class.
+			if strings.HasSuffix(cond.Code, "000") && strings.HasPrefix(code, cond.Code[:2]) {
+				return &handlers[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (it *plpgsqlInterpreter) execStmts(ctx context.Context, stmts []plpgsqltree.ProgStmt) error {
+	for _, stmt := range stmts {
+		if err := it.execStmt(ctx, stmt); err != nil {
+			return err
+		}
+		if it.control != plpgsqlControlNone {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (it *plpgsqlInterpreter) execStmt(ctx context.Context, stmt plpgsqltree.ProgStmt) error {
+	switch t := stmt.(type) {
+	case *plpgsqltree.ProgBlock:
+		return it.execBlock(ctx, t)
+
+	case *plpgsqltree.ProgAssign:
+		d, err := it.evalExpr(ctx, t.Expr)
+		if err != nil {
+			return err
+		}
+		return it.setVar(ctx, t.Var, d)
+
+	case *plpgsqltree.ProgIf:
+		for i := range t.Conds {
+			ok, err := it.evalCond(ctx, t.Conds[i])
+			if err != nil {
+				return err
+			}
+			if ok {
+				return it.execStmts(ctx, t.Bodies[i])
+			}
+		}
+		return it.execStmts(ctx, t.Else)
+
+	case *plpgsqltree.ProgLoop:
+		for {
+			if err := it.p.cancelChecker.Check(); err != nil {
+				return err
+			}
+			if t.Cond != plpgsqltree.NoExpr {
+				ok, err := it.evalCond(ctx, t.Cond)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+			if err := it.execStmts(ctx, t.Body); err != nil {
+				return err
+			}
+			if it.endLoopIteration(t.Label) {
+				return nil
+			}
+		}
+
+	case *plpgsqltree.ProgForInt:
+		return it.execForInt(ctx, t)
+
+	case *plpgsqltree.ProgForQuery:
+		return it.execForQuery(ctx, t)
+
+	case *plpgsqltree.ProgExit:
+		if t.Cond != plpgsqltree.NoExpr {
+			ok, err := it.evalCond(ctx, t.Cond)
+			if err != nil || !ok {
+				return err
+			}
+		}
+		it.control, it.controlLabel = plpgsqlControlExit, t.Label
+		if t.Continue {
+			it.control = plpgsqlControlContinue
+		}
+		return nil
+
+	case *plpgsqltree.ProgReturn:
+		if t.Expr != plpgsqltree.NoExpr {
+			d, err := it.evalExpr(ctx, t.Expr)
+			if err != nil {
+				return err
+			}
+			if err := it.res.AddRow(ctx, tree.Datums{d}); err != nil {
+				return err
+			}
+		}
+		it.control, it.controlLabel = plpgsqlControlReturn, ""
+		return nil
+
+	case *plpgsqltree.ProgReturnNext:
+		d, err := it.evalExpr(ctx, t.Expr)
+		if err != nil {
+			return err
+		}
+		return it.res.AddRow(ctx, tree.Datums{d})
+
+	case *plpgsqltree.ProgReturnQuery:
+		n := 0
+		err := it.runExpr(ctx, t.Query, func(d tree.Datum) error {
+			n++
+			return it.res.AddRow(ctx, tree.Datums{d})
+		})
+		if err != nil {
+			return err
+		}
+		it.rowCount = n
+		it.setFound(n > 0)
+		return nil
+
+	case *plpgsqltree.ProgRaise:
+		return it.execRaise(ctx, t)
+
+	case *plpgsqltree.ProgExec:
+		return it.execSQL(ctx, t)
+
+	case *plpgsqltree.ProgGetDiagnostics:
+		var d tree.Datum = tree.NewDInt(tree.DInt(it.rowCount))
+		if typ := it.prog.Vars[t.Var].Typ; typ.Family() != types.IntFamily {
+			var err error
+			if d, err = eval.PerformAssignmentCast(ctx, it.p.EvalContext(), d, typ); err != nil {
+				return err
+			}
+		}
+		return it.setVar(ctx, t.Var, d)
+
+	default:
+		return errors.AssertionFailedf("unexpected PL/pgSQL statement %T", stmt)
+	}
+}
+
+// endLoopIteration is called after each iteration of the loop with the given
+// label. It returns true if the loop should terminate.
+func (it *plpgsqlInterpreter) endLoopIteration(label string) bool {
+	switch it.control {
+	case plpgsqlControlNone:
+		return false
+	case plpgsqlControlExit:
+		if it.controlLabel == "" || it.controlLabel == label {
+			it.control, it.controlLabel = plpgsqlControlNone, ""
+		}
+		return true
+	case plpgsqlControlContinue:
+		if it.controlLabel == "" || it.controlLabel == label {
+			it.control, it.controlLabel = plpgsqlControlNone, ""
+			return false
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func (it *plpgsqlInterpreter) execForInt(ctx context.Context, t *plpgsqltree.ProgForInt) error {
+	lower, err := it.evalInt(ctx, t.Lower, "lower bound")
+	if err != nil {
+		return err
+	}
+	upper, err := it.evalInt(ctx, t.Upper, "upper bound")
+	if err != nil {
+		return err
+	}
+	step := int64(1)
+	if t.Step != plpgsqltree.NoExpr {
+		if step, err = it.evalInt(ctx, t.Step, "BY value"); err != nil {
+			return err
+		}
+		if step <= 0 {
+			return pgerror.New(pgcode.InvalidParameterValue,
+				"BY value of FOR loop must be greater than zero")
+		}
+	}
+	found := false
+	for i := lower; ; {
+		if (!t.Reverse && i > upper) || (t.Reverse && i < upper) {
+			break
+		}
+		if err := it.p.cancelChecker.Check(); err != nil {
+			return err
+		}
+		found = true
+		if err := it.setVar(ctx, t.Var, tree.NewDInt(tree.DInt(i))); err != nil {
+			return err
+		}
+		if err := it.execStmts(ctx, t.Body); err != nil {
+			return err
+		}
+		if it.endLoopIteration(t.Label) {
+			break
+		}
+		// Stop before the loop variable overflows.
+		if (!t.Reverse && i > math.MaxInt64-step) || (t.Reverse && i < math.MinInt64+step) {
+			break
+		}
+		if t.Reverse {
+			i -= step
+		} else {
+			i += step
+		}
+	}
+	it.setFound(found)
+	return nil
+}
+
+func (it *plpgsqlInterpreter) execForQuery(
+	ctx context.Context, t *plpgsqltree.ProgForQuery,
+) error {
+	var g routineGenerator
+	g.init(it.p, it.expr.ProgramExprs[t.Query], it.vars)
+	defer g.Close(ctx)
+	if err := g.Start(ctx, it.txn); err != nil {
+		return err
+	}
+	n := 0
+	for {
+		if err := it.p.cancelChecker.Check(); err != nil {
+			return err
+		}
+		ok, err := g.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		n++
+		row, err := g.Values()
+		if err != nil {
+			return err
+		}
+		if err := it.setTargets(ctx, t.Targets, row[0]); err != nil {
+			return err
+		}
+		if err := it.execStmts(ctx, t.Body); err != nil {
+			return err
+		}
+		if it.endLoopIteration(t.Label) {
+			break
+		}
+	}
+	it.rowCount = n
+	it.setFound(n > 0)
+	return nil
+}
+
+// execSQL executes a SQL statement of the function body, and assigns the first
+// row that it returns to its INTO targets, if any.
+func (it *plpgsqlInterpreter) execSQL(ctx context.Context, t *plpgsqltree.ProgExec) error {
+	n := 0
+	first := tree.Datum(tree.DNull)
+	err := it.runExpr(ctx, t.Stmt, func(d tree.Datum) error {
+		if n == 0 {
+			first = d
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	it.rowCount = n
+	it.setFound(n > 0)
+	if len(t.Targets) == 0 {
+		return nil
+	}
+	if t.Strict {
+		if n == 0 {
+			return pgerror.New(pgcode.NoDataFound, "query returned no rows")
+		}
+		if n > 1 {
+			return pgerror.New(pgcode.TooManyRows, "query returned more than one row")
+		}
+	}
+	return it.setTargets(ctx, t.Targets, first)
+}
+
+// plpgsqlNoticeSeverities maps the levels of RAISE statements below EXCEPTION
+// to the severities of the notices that they send.
+var plpgsqlNoticeSeverities = map[string]string{
+	"DEBUG":   "DEBUG1",
+	"LOG":     "LOG",
+	"INFO":    "INFO",
+	"NOTICE":  "NOTICE",
+	"WARNING": "WARNING",
+}
+
+func (it *plpgsqlInterpreter) execRaise(ctx context.Context, t *plpgsqltree.ProgRaise) error {
+	if t.Rethrow {
+		return it.handledErrs[len(it.handledErrs)-1]
+	}
+	params := make([]string, len(t.Params))
+	for i := range t.Params {
+		d, err := it.evalExpr(ctx, t.Params[i])
+		if err != nil {
+			return err
+		}
+		if d == tree.DNull {
+			params[i] = "<NULL>"
+		} else {
+			params[i] = string(tree.MustBeDString(d))
+		}
+	}
+	msg := formatRaiseMessage(t.Message, params)
+	code := t.Code
+	var detail, hint string
+	for _, o := range t.Options {
+		d, err := it.evalExpr(ctx, o.Expr)
+		if err != nil {
+			return err
+		}
+		if d == tree.DNull {
+			return pgerror.New(pgcode.NullValueNotAllowed, "RAISE statement option cannot be null")
+		}
+		val := string(tree.MustBeDString(d))
+		switch o.OptType {
+		case "message":
+			msg = val
+		case "detail":
+			detail = val
+		case "hint":
+			hint = val
+		case "errcode":
+			if codes, ok := plpgsqltree.LookupCondition(strings.ToLower(val)); ok {
+				code = codes[0]
+			} else if len(val) == 5 {
+				code = strings.ToUpper(val)
+			} else {
+				return pgerror.Newf(pgcode.UndefinedObject, "unrecognized exception condition %q", val)
+			}
+		}
+	}
+
+	if t.Severity == "EXCEPTION" {
+		if code == "" {
+			code = pgcode.RaiseException.String()
+		}
+		err := pgerror.New(pgcode.MakeCode(code), msg)
+		if detail != "" {
+			err = errors.WithDetail(err, detail)
+		}
+		if hint != "" {
+			err = errors.WithHint(err, hint)
+		}
+		return err
+	}
+	var notice error = pgnotice.NewWithSeverityf(plpgsqlNoticeSeverities[t.Severity], "%s", msg)
+	if code != "" {
+		notice = pgerror.WithCandidateCode(notice, pgcode.MakeCode(code))
+	}
+	if detail != "" {
+		notice = errors.WithDetail(notice, detail)
+	}
+	if hint != "" {
+		notice = errors.WithHint(notice, hint)
+	}
+	it.p.BufferClientNotice(ctx, pgnotice.Notice(notice))
+	return nil
+}
+
+// formatRaiseMessage replaces each % in the format string of a RAISE statement
+// with the next parameter. %% is replaced with %.
+func formatRaiseMessage(format string, params []string) string {
+	if len(params) == 0 && !strings.Contains(format, "%") {
+		return format
+	}
+	var buf strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			buf.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			buf.WriteByte('%')
+			i++
+			continue
+		}
+		if len(params) > 0 {
+			buf.WriteString(params[0])
+			params = params[1:]
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,1041 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package parser contains the parser for PL/pgSQL function bodies.
+//
+// The body of a PL/pgSQL function is a block of PL/pgSQL statements, which
+// embed SQL expressions and statements. The parser tokenizes the body with the
+// SQL scanner and parses the PL/pgSQL statements by recursive descent. The
+// extent of each embedded SQL construct is determined by scanning ahead for the
+// token which terminates it, e.g. THEN for the condition of an IF statement,
+// and the text of the construct is then parsed by the SQL parser.
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	sqlparser "github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/scanner"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// Parse parses the body of a PL/pgSQL function.
+func Parse(body string) (_ *plpgsqltree.Block, err error) {
+	p := parser{in: body}
+	defer func() {
+		if r := recover(); r != nil {
+			// Panics with errors are used to unwind the recursive descent.
+			if e, ok := r.(parseError); ok {
+				err = e.err
+				return
+			}
+			panic(r)
+		}
+	}()
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	block := p.parseFunctionBody()
+	return block, nil
+}
+
+// parseError wraps errors that are raised with panics by the parser.
+type parseError struct {
+	err error
+}
+
+// parser holds the state of the parser. The entire function body is tokenized
+// before parsing begins.
+type parser struct {
+	in   string
+	toks []scanner.InspectToken
+	pos  int
+}
+
+// scan tokenizes the function body.
+func (p *parser) scan() error {
+	p.toks = scanner.Inspect(p.in)
+	last := &p.toks[len(p.toks)-1]
+	switch last.ID {
+	case lexbase.ERROR:
+		p.pos = len(p.toks) - 1
+		return p.errorf("lexical error: %s", last.Str)
+	case -1:
+		p.pos = len(p.toks) - 1
+		return p.errorf("unterminated token")
+	}
+	return nil
+}
+
+// peek returns the current token.
+func (p *parser) peek() *scanner.InspectToken {
+	return &p.toks[p.pos]
+}
+
+// peekN returns the token n tokens after the current token, or the final EOF
+// token if there are fewer tokens than that.
+func (p *parser) peekN(n int) *scanner.InspectToken {
+	if p.pos+n >= len(p.toks) {
+		return &p.toks[len(p.toks)-1]
+	}
+	return &p.toks[p.pos+n]
+}
+
+// next returns the current token and advances to the next one.
+func (p *parser) next() *scanner.InspectToken {
+	tok := &p.toks[p.pos]
+	if tok.ID != 0 {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) atEOF() bool {
+	return p.peek().ID == 0
+}
+
+// isWord returns true if the token is the given unquoted word. Word must be
+// lower case.
+func isWord(tok *scanner.InspectToken, word string) bool {
+	return !tok.Quoted && isIdent(tok) && tok.Str == word
+}
+
+// isIdent returns true if the token is an identifier or a keyword.
+func isIdent(tok *scanner.InspectToken) bool {
+	switch tok.ID {
+	case lexbase.IDENT:
+		return true
+	case 0, lexbase.SCONST, lexbase.BCONST, lexbase.BITCONST, lexbase.ICONST,
+		lexbase.FCONST, lexbase.PLACEHOLDER, lexbase.ERROR:
+		return false
+	}
+	return tok.Str != "" && lexbase.IsIdentStart(int(tok.Str[0]))
+}
+
+// isChar returns true if the token is the given single-character token.
+func isChar(tok *scanner.InspectToken, ch byte) bool {
+	return tok.ID == int32(ch)
+}
+
+// atWord returns true if the current token is one of the given words.
+func (p *parser) atWord(words ...string) bool {
+	tok := p.peek()
+	for _, w := range words {
+		if isWord(tok, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// atAssign returns true if the current token is := or =.
+func (p *parser) atAssign() bool {
+	tok := p.peek()
+	if isChar(tok, '=') {
+		return true
+	}
+	nextTok := p.peekN(1)
+	return isChar(tok, ':') && isChar(nextTok, '=') && nextTok.Start == tok.End
+}
+
+// expectAssign consumes a := or = token.
+func (p *parser) expectAssign() {
+	if !p.atAssign() {
+		p.syntaxError()
+	}
+	if isChar(p.next(), ':') {
+		p.next()
+	}
+}
+
+// expectWord consumes the given word.
+func (p *parser) expectWord(word string) {
+	if !isWord(p.peek(), word) {
+		p.syntaxError()
+	}
+	p.next()
+}
+
+// expectChar consumes the given single-character token.
+func (p *parser) expectChar(ch byte) {
+	if !isChar(p.peek(), ch) {
+		p.syntaxError()
+	}
+	p.next()
+}
+
+// parseIdent consumes an identifier and returns its normalized name.
+func (p *parser) parseIdent() string {
+	tok := p.peek()
+	if !isIdent(tok) {
+		p.syntaxError()
+	}
+	p.next()
+	return tok.Str
+}
+
+// tokText returns the text of the given token as it appears in the input.
+func (p *parser) tokText(tok *scanner.InspectToken) string {
+	if tok.ID == 0 {
+		return "EOF"
+	}
+	return p.in[tok.Start:tok.End]
+}
+
+// errorf returns a syntax error at the current token.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	tok := p.peek()
+	err := pgerror.Newf(pgcode.Syntax, format, args...)
+	err = errors.Wrapf(err, "at or near \"%s\"", p.tokText(tok))
+	return p.withSource(err, int(tok.Start))
+}
+
+// withSource adds a detail to the error which shows the position of the
+// offending token in the function body.
+func (p *parser) withSource(err error, pos int) error {
+	if pos > len(p.in) {
+		pos = len(p.in)
+	}
+	i := strings.IndexByte(p.in[pos:], '\n')
+	if i == -1 {
+		i = len(p.in)
+	} else {
+		i += pos
+	}
+	j := strings.LastIndexByte(p.in[:pos], '\n') + 1
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "source SQL:\n%s\n", p.in[:i])
+	fmt.Fprintf(&buf, "%s^", strings.Repeat(" ", pos-j))
+	return errors.WithDetail(err, buf.String())
+}
+
+// syntaxError panics with a syntax error at the current token.
+func (p *parser) syntaxError() {
+	panic(parseError{err: p.errorf("syntax error")})
+}
+
+// fail panics with the given error.
+func (p *parser) fail(err error) {
+	panic(parseError{err: err})
+}
+
+// unimplemented panics with an unimplemented error for the given PL/pgSQL
+// feature.
+func (p *parser) unimplemented(feature string) {
+	p.fail(unimplemented.New("plpgsql "+strings.ToLower(feature),
+		fmt.Sprintf("PL/pgSQL %s is not yet supported", feature)))
+}
+
+// parseFunctionBody parses the outermost block of a function body, which may
+// optionally be followed by a semicolon.
+func (p *parser) parseFunctionBody() *plpgsqltree.Block {
+	label := p.parseOptLabel()
+	if !p.atWord("declare", "begin") {
+		p.syntaxError()
+	}
+	block := p.parseBlock(label, false /* requireSemicolon */)
+	if isChar(p.peek(), ';') {
+		p.next()
+	}
+	if !p.atEOF() {
+		p.syntaxError()
+	}
+	return block
+}
+
+// parseOptLabel parses a <<label>> if there is one.
+func (p *parser) parseOptLabel() string {
+	if p.peek().ID != lexbase.LSHIFT {
+		return ""
+	}
+	p.next()
+	label := p.parseIdent()
+	if p.peek().ID != lexbase.RSHIFT {
+		p.syntaxError()
+	}
+	p.next()
+	return label
+}
+
+// parseEndLabel parses the optional label after the END of a block or loop,
+// and validates that it matches the label of the block or loop.
+func (p *parser) parseEndLabel(label string) {
+	if !isIdent(p.peek()) {
+		return
+	}
+	endLabel := p.parseIdent()
+	if label == "" {
+		p.pos--
+		p.fail(p.errorf("end label %q specified for unlabeled block", endLabel))
+	}
+	if endLabel != label {
+		p.pos--
+		p.fail(p.errorf("end label %q differs from block's label %q", endLabel, label))
+	}
+}
+
+// parseBlock parses a block, beginning at DECLARE or BEGIN.
+func (p *parser) parseBlock(label string, requireSemicolon bool) *plpgsqltree.Block {
+	block := &plpgsqltree.Block{Label: label}
+	if p.atWord("declare") {
+		p.next()
+		for !p.atWord("begin") {
+			if p.atEOF() {
+				p.syntaxError()
+			}
+			if p.atWord("declare") {
+				// DECLARE may be repeated.
+				p.next()
+				continue
+			}
+			block.Decls = append(block.Decls, p.parseDeclaration())
+		}
+	}
+	p.expectWord("begin")
+	block.Body = p.parseStmts("exception", "end")
+	if p.atWord("exception") {
+		p.next()
+		for p.atWord("when") {
+			block.Exceptions = append(block.Exceptions, p.parseException())
+		}
+		if len(block.Exceptions) == 0 {
+			p.syntaxError()
+		}
+	}
+	p.expectWord("end")
+	p.parseEndLabel(label)
+	if requireSemicolon {
+		p.expectChar(';')
+	}
+	return block
+}
+
+// parseDeclaration parses a variable declaration:
+//
+//	name [ CONSTANT ] type [ COLLATE collation ] [ NOT NULL ] [ { DEFAULT | := | = } expression ];
+func (p *parser) parseDeclaration() plpgsqltree.Declaration {
+	var decl plpgsqltree.Declaration
+	decl.Var = plpgsqltree.Variable(p.parseIdent())
+	if p.atWord("constant") {
+		p.next()
+		decl.Constant = true
+	}
+	if p.atWord("alias") {
+		p.unimplemented("variable alias")
+	}
+	if p.atWord("cursor") || p.atWord("no", "scroll") {
+		p.unimplemented("cursor variable")
+	}
+
+	// The type extends until NOT NULL, DEFAULT, := or the end of the
+	// declaration.
+	start := p.pos
+	depth := 0
+	for {
+		tok := p.peek()
+		if tok.ID == 0 {
+			p.syntaxError()
+		}
+		if depth == 0 {
+			if isChar(tok, ';') || p.atWord("default") || p.atAssign() ||
+				(isWord(tok, "not") && isWord(p.peekN(1), "null")) {
+				break
+			}
+		}
+		switch {
+		case isChar(tok, '(') || isChar(tok, '['):
+			depth++
+		case isChar(tok, ')') || isChar(tok, ']'):
+			depth--
+		case isChar(tok, '%'):
+			attr := p.peekN(1)
+			switch {
+			case isWord(attr, "rowtype"):
+				// A table name followed by %ROWTYPE is the composite type of the
+				// table, which is named by the table name alone.
+				decl.Typ = p.parseType(start, p.pos)
+				p.next()
+				p.next()
+				return p.finishDeclaration(decl)
+			case isWord(attr, "type"):
+				p.unimplemented("%TYPE")
+			}
+		}
+		p.next()
+	}
+	decl.Typ = p.parseType(start, p.pos)
+	return p.finishDeclaration(decl)
+}
+
+// finishDeclaration parses the optional NOT NULL and initial value clauses of a
+// declaration, and the terminating semicolon.
+func (p *parser) finishDeclaration(decl plpgsqltree.Declaration) plpgsqltree.Declaration {
+	if p.atWord("not") {
+		p.next()
+		p.expectWord("null")
+		decl.NotNull = true
+	}
+	if p.atWord("default") {
+		p.next()
+		decl.Expr = p.parseExpr(untilChar(';'))
+	} else if p.atAssign() {
+		p.expectAssign()
+		decl.Expr = p.parseExpr(untilChar(';'))
+	}
+	p.expectChar(';')
+	if decl.Constant && decl.Expr == nil {
+		p.pos--
+		p.fail(p.errorf("constant variable %q must have a default value", decl.Var))
+	}
+	if decl.NotNull && decl.Expr == nil {
+		p.pos--
+		p.fail(p.errorf("variable %q must have a default value, since it's declared NOT NULL", decl.Var))
+	}
+	return decl
+}
+
+// parseType parses the type between the tokens at the given positions.
+func (p *parser) parseType(start, end int) tree.ResolvableTypeReference {
+	if start == end {
+		p.syntaxError()
+	}
+	typ, err := sqlparser.GetTypeFromValidSQLSyntax(p.textBetween(start, end))
+	if err != nil {
+		p.pos = start
+		p.fail(p.errorf("invalid type name %q", p.textBetween(start, end)))
+	}
+	return typ
+}
+
+// parseException parses an exception handler:
+//
+//	WHEN condition [ OR condition ... ] THEN statements
+func (p *parser) parseException() plpgsqltree.Exception {
+	p.expectWord("when")
+	var exc plpgsqltree.Exception
+	for {
+		var cond plpgsqltree.Condition
+		if p.atWord("sqlstate") {
+			p.next()
+			cond.SQLState = p.parseSQLState()
+		} else {
+			cond.Name = p.parseIdent()
+		}
+		exc.Conditions = append(exc.Conditions, cond)
+		if !p.atWord("or") {
+			break
+		}
+		p.next()
+	}
+	p.expectWord("then")
+	exc.Action = p.parseStmts("when", "end")
+	return exc
+}
+
+// parseSQLState parses a string constant which holds a five character
+// SQLSTATE code.
+func (p *parser) parseSQLState() string {
+	tok := p.peek()
+	if tok.ID != lexbase.SCONST {
+		p.syntaxError()
+	}
+	if len(tok.Str) != 5 || strings.ToUpper(tok.Str) != tok.Str {
+		p.fail(pgerror.Newf(pgcode.Syntax, "invalid SQLSTATE code %q", tok.Str))
+	}
+	p.next()
+	return tok.Str
+}
+
+// parseStmts parses statements until one of the given words is reached.
+func (p *parser) parseStmts(terminators ...string) []plpgsqltree.Statement {
+	var stmts []plpgsqltree.Statement
+	for !p.atWord(terminators...) {
+		if p.atEOF() {
+			p.syntaxError()
+		}
+		if stmt := p.parseStmt(); stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// parseStmt parses a single statement.
+func (p *parser) parseStmt() plpgsqltree.Statement {
+	label := p.parseOptLabel()
+	if label != "" && !p.atWord("declare", "begin", "loop", "while", "for", "foreach") {
+		p.syntaxError()
+	}
+
+	tok := p.peek()
+	if !isIdent(tok) {
+		if isChar(tok, ';') {
+			// Empty statements are ignored.
+			p.next()
+			return nil
+		}
+		p.syntaxError()
+	}
+
+	// An identifier which is followed by := or = is the target of an
+	// assignment.
+	save := p.pos
+	p.next()
+	if p.atAssign() {
+		p.pos = save
+		return p.parseAssignment()
+	}
+	if isChar(p.peek(), '.') || isChar(p.peek(), '[') {
+		// Assignments to record fields and array elements are not supported.
+		for !isChar(p.peek(), ';') && !p.atEOF() {
+			if p.atAssign() {
+				p.pos = save
+				p.unimplemented("assignment to a record field or array element")
+			}
+			p.next()
+		}
+	}
+	p.pos = save
+	if tok.Quoted {
+		return p.parseExecute()
+	}
+
+	switch tok.Str {
+	case "declare", "begin":
+		return p.parseBlock(label, true /* requireSemicolon */)
+	case "if":
+		return p.parseIf()
+	case "loop":
+		return p.parseLoop(label)
+	case "while":
+		return p.parseWhile(label)
+	case "for":
+		return p.parseFor(label)
+	case "exit":
+		return p.parseExit()
+	case "continue":
+		return p.parseContinue()
+	case "return":
+		return p.parseReturn()
+	case "raise":
+		return p.parseRaise()
+	case "perform":
+		return p.parsePerform()
+	case "get":
+		return p.parseGetDiagnostics()
+	case "null":
+		p.next()
+		p.expectChar(';')
+		return &plpgsqltree.Null{}
+	case "case":
+		p.unimplemented("CASE statement")
+	case "foreach":
+		p.unimplemented("FOREACH statement")
+	case "execute":
+		p.unimplemented("EXECUTE statement")
+	case "open", "fetch", "move", "close":
+		p.unimplemented("cursor statement")
+	case "assert":
+		p.unimplemented("ASSERT statement")
+	case "commit", "rollback":
+		p.unimplemented("transaction control statement")
+	}
+	return p.parseExecute()
+}
+
+// parseAssignment parses an assignment:
+//
+//	variable { := | = } expression;
+func (p *parser) parseAssignment() plpgsqltree.Statement {
+	var stmt plpgsqltree.Assignment
+	stmt.Var = plpgsqltree.Variable(p.parseIdent())
+	p.expectAssign()
+	stmt.Value = p.parseExpr(untilChar(';'))
+	p.expectChar(';')
+	return &stmt
+}
+
+// parseIf parses an IF statement.
+func (p *parser) parseIf() plpgsqltree.Statement {
+	var stmt plpgsqltree.If
+	p.expectWord("if")
+	stmt.Condition = p.parseExpr(untilWord("then"))
+	p.expectWord("then")
+	stmt.ThenBody = p.parseStmts("elsif", "elseif", "else", "end")
+	for p.atWord("elsif", "elseif") {
+		p.next()
+		var elseIf plpgsqltree.ElseIf
+		elseIf.Condition = p.parseExpr(untilWord("then"))
+		p.expectWord("then")
+		elseIf.Stmts = p.parseStmts("elsif", "elseif", "else", "end")
+		stmt.ElseIfList = append(stmt.ElseIfList, elseIf)
+	}
+	if p.atWord("else") {
+		p.next()
+		stmt.ElseBody = p.parseStmts("end")
+	}
+	p.expectWord("end")
+	p.expectWord("if")
+	p.expectChar(';')
+	return &stmt
+}
+
+// parseLoopBody parses the body of a loop, beginning at LOOP, and ending
+// after END LOOP [ label ];
+func (p *parser) parseLoopBody(label string) []plpgsqltree.Statement {
+	p.expectWord("loop")
+	body := p.parseStmts("end")
+	p.expectWord("end")
+	p.expectWord("loop")
+	p.parseEndLabel(label)
+	p.expectChar(';')
+	return body
+}
+
+// parseLoop parses an unconditional loop.
+func (p *parser) parseLoop(label string) plpgsqltree.Statement {
+	return &plpgsqltree.Loop{Label: label, Body: p.parseLoopBody(label)}
+}
+
+// parseWhile parses a WHILE loop.
+func (p *parser) parseWhile(label string) plpgsqltree.Statement {
+	p.expectWord("while")
+	cond := p.parseExpr(untilWord("loop"))
+	return &plpgsqltree.While{Label: label, Condition: cond, Body: p.parseLoopBody(label)}
+}
+
+// parseFor parses a FOR loop over a range of integers or over the rows of a
+// query.
+func (p *parser) parseFor(label string) plpgsqltree.Statement {
+	p.expectWord("for")
+	var target []plpgsqltree.Variable
+	for {
+		target = append(target, plpgsqltree.Variable(p.parseIdent()))
+		if !isChar(p.peek(), ',') {
+			break
+		}
+		p.next()
+	}
+	p.expectWord("in")
+	if p.atWord("execute") {
+		p.unimplemented("FOR over EXECUTE")
+	}
+
+	// The loop is over a range of integers if there is a .. token before the
+	// LOOP keyword.
+	reverse := false
+	if p.atWord("reverse") {
+		p.next()
+		reverse = true
+	}
+	end := p.findEnd(untilWord("loop"))
+	isIntLoop := reverse
+	for i, depth := p.pos, 0; i < end; i++ {
+		tok := &p.toks[i]
+		switch {
+		case isChar(tok, '(') || isChar(tok, '['):
+			depth++
+		case isChar(tok, ')') || isChar(tok, ']'):
+			depth--
+		case tok.ID == lexbase.DOT_DOT && depth == 0:
+			isIntLoop = true
+		}
+	}
+
+	if !isIntLoop {
+		query := p.parseSQLStmt(untilWord("loop"))
+		if _, ok := query.(*tree.Select); !ok {
+			p.fail(pgerror.Newf(pgcode.Syntax, "FOR loop over rows requires a query"))
+		}
+		return &plpgsqltree.ForQuery{
+			Label: label, Target: target, Query: query, Body: p.parseLoopBody(label),
+		}
+	}
+
+	if len(target) != 1 {
+		p.fail(pgerror.Newf(pgcode.Syntax, "integer FOR loop must have only one target variable"))
+	}
+	stmt := plpgsqltree.ForInt{Label: label, Target: target[0], Reverse: reverse}
+	stmt.Lower = p.parseExpr(untilTok(lexbase.DOT_DOT))
+	p.next()
+	stmt.Upper = p.parseExpr(untilWord("by", "loop"))
+	if p.atWord("by") {
+		p.next()
+		stmt.Step = p.parseExpr(untilWord("loop"))
+	}
+	stmt.Body = p.parseLoopBody(label)
+	return &stmt
+}
+
+// parseExit parses an EXIT statement.
+func (p *parser) parseExit() plpgsqltree.Statement {
+	p.expectWord("exit")
+	var stmt plpgsqltree.Exit
+	stmt.Label, stmt.Condition = p.parseLoopControl()
+	return &stmt
+}
+
+// parseContinue parses a CONTINUE statement.
+func (p *parser) parseContinue() plpgsqltree.Statement {
+	p.expectWord("continue")
+	var stmt plpgsqltree.Continue
+	stmt.Label, stmt.Condition = p.parseLoopControl()
+	return &stmt
+}
+
+// parseLoopControl parses the optional label and WHEN clause of an EXIT or
+// CONTINUE statement, and the terminating semicolon.
+func (p *parser) parseLoopControl() (label string, cond plpgsqltree.Expr) {
+	if isIdent(p.peek()) && !p.atWord("when") {
+		label = p.parseIdent()
+	}
+	if p.atWord("when") {
+		p.next()
+		cond = p.parseExpr(untilChar(';'))
+	}
+	p.expectChar(';')
+	return label, cond
+}
+
+// parseReturn parses RETURN, RETURN NEXT and RETURN QUERY statements.
+func (p *parser) parseReturn() plpgsqltree.Statement {
+	p.expectWord("return")
+	switch {
+	case p.atWord("next"):
+		p.next()
+		var stmt plpgsqltree.ReturnNext
+		if !isChar(p.peek(), ';') {
+			stmt.Expr = p.parseExpr(untilChar(';'))
+		}
+		p.expectChar(';')
+		return &stmt
+
+	case p.atWord("query"):
+		p.next()
+		if p.atWord("execute") {
+			p.unimplemented("RETURN QUERY EXECUTE")
+		}
+		query := p.parseSQLStmt(untilChar(';'))
+		p.expectChar(';')
+		return &plpgsqltree.ReturnQuery{Query: query}
+	}
+
+	var stmt plpgsqltree.Return
+	if !isChar(p.peek(), ';') {
+		stmt.Expr = p.parseExpr(untilChar(';'))
+	}
+	p.expectChar(';')
+	return &stmt
+}
+
+// raiseLevels are the severity levels of RAISE statements.
+var raiseLevels = map[string]struct{}{
+	"debug":     {},
+	"log":       {},
+	"info":      {},
+	"notice":    {},
+	"warning":   {},
+	"exception": {},
+}
+
+// raiseOptions are the options that may be specified in the USING clause of a
+// RAISE statement.
+var raiseOptions = map[string]struct{}{
+	"message":    {},
+	"detail":     {},
+	"hint":       {},
+	"errcode":    {},
+	"column":     {},
+	"constraint": {},
+	"datatype":   {},
+	"table":      {},
+	"schema":     {},
+}
+
+// parseRaise parses a RAISE statement.
+func (p *parser) parseRaise() plpgsqltree.Statement {
+	p.expectWord("raise")
+	var stmt plpgsqltree.Raise
+	if isChar(p.peek(), ';') {
+		// RAISE with no arguments re-raises the current error.
+		p.next()
+		return &stmt
+	}
+	if tok := p.peek(); isIdent(tok) && !tok.Quoted {
+		if _, ok := raiseLevels[tok.Str]; ok {
+			stmt.LogLevel = tok.Str
+			p.next()
+		}
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.ID == lexbase.SCONST:
+		stmt.Message = tok.Str
+		p.next()
+		for isChar(p.peek(), ',') {
+			p.next()
+			stmt.Params = append(stmt.Params, p.parseExpr(untilChar(',', ';'), untilWord("using")))
+		}
+		if n := strings.Count(strings.ReplaceAll(stmt.Message, "%%", ""), "%"); n != len(stmt.Params) {
+			if n > len(stmt.Params) {
+				p.fail(pgerror.New(pgcode.Syntax, "too few parameters specified for RAISE"))
+			}
+			p.fail(pgerror.New(pgcode.Syntax, "too many parameters specified for RAISE"))
+		}
+
+	case isWord(tok, "sqlstate"):
+		p.next()
+		stmt.Code = p.parseSQLState()
+
+	case isIdent(tok) && !isWord(tok, "using"):
+		stmt.CodeName = p.parseIdent()
+	}
+
+	if p.atWord("using") {
+		p.next()
+		for {
+			optTok := p.peek()
+			opt := p.parseIdent()
+			if _, ok := raiseOptions[opt]; !ok || optTok.Quoted {
+				p.pos--
+				p.fail(p.errorf("unrecognized RAISE statement option %q", opt))
+			}
+			p.expectAssign()
+			stmt.Options = append(stmt.Options, plpgsqltree.RaiseOption{
+				OptType: opt,
+				Expr:    p.parseExpr(untilChar(',', ';')),
+			})
+			if !isChar(p.peek(), ',') {
+				break
+			}
+			p.next()
+		}
+	}
+	p.expectChar(';')
+	return &stmt
+}
+
+// parsePerform parses a PERFORM statement.
+func (p *parser) parsePerform() plpgsqltree.Statement {
+	performTok := p.next()
+	start := p.pos
+	end := p.findEnd(untilChar(';'))
+	if start == end {
+		p.syntaxError()
+	}
+	// The query is a SELECT statement with PERFORM in place of SELECT.
+	sql := "SELECT " + p.textBetween(start, end)
+	stmt, err := sqlparser.ParseOne(sql)
+	if err != nil {
+		p.fail(p.withSource(err, int(performTok.Start)))
+	}
+	p.pos = end
+	p.expectChar(';')
+	return &plpgsqltree.Perform{Query: stmt.AST}
+}
+
+// parseGetDiagnostics parses a GET DIAGNOSTICS statement.
+func (p *parser) parseGetDiagnostics() plpgsqltree.Statement {
+	p.expectWord("get")
+	if p.atWord("stacked") {
+		p.unimplemented("GET STACKED DIAGNOSTICS")
+	}
+	if p.atWord("current") {
+		p.next()
+	}
+	p.expectWord("diagnostics")
+	var stmt plpgsqltree.GetDiagnostics
+	for {
+		var item plpgsqltree.GetDiagnosticsItem
+		item.Target = plpgsqltree.Variable(p.parseIdent())
+		p.expectAssign()
+		item.Kind = p.parseIdent()
+		stmt.Items = append(stmt.Items, item)
+		if !isChar(p.peek(), ',') {
+			break
+		}
+		p.next()
+	}
+	p.expectChar(';')
+	return &stmt
+}
+
+// parseExecute parses a SQL statement, which may have an INTO clause.
+func (p *parser) parseExecute() plpgsqltree.Statement {
+	start := p.pos
+	end := p.findEnd(untilChar(';'))
+
+	// Find the INTO clause, if any. The INTO keyword of an INSERT or MERGE
+	// statement which precedes the target table is not an INTO clause.
+	var stmt plpgsqltree.Execute
+	intoStart, intoEnd := -1, -1
+	skipInto := p.atWord("insert", "merge")
+	for i, depth := start, 0; i < end; i++ {
+		tok := &p.toks[i]
+		switch {
+		case isChar(tok, '(') || isChar(tok, '['):
+			depth++
+		case isChar(tok, ')') || isChar(tok, ']'):
+			depth--
+		case isWord(tok, "into") && depth == 0:
+			if skipInto {
+				skipInto = false
+				continue
+			}
+			if intoStart != -1 {
+				p.pos = i
+				p.fail(p.errorf("INTO specified more than once"))
+			}
+			intoStart = i
+			p.pos = i + 1
+			if p.atWord("strict") {
+				p.next()
+				stmt.Strict = true
+			}
+			for {
+				stmt.Target = append(stmt.Target, plpgsqltree.Variable(p.parseIdent()))
+				if !isChar(p.peek(), ',') {
+					break
+				}
+				p.next()
+			}
+			intoEnd = p.pos
+			i = intoEnd - 1
+		}
+	}
+
+	sql := p.textBetween(start, end)
+	if intoStart != -1 {
+		sql = p.textBetween(start, intoStart) + " " + p.textBetween(intoEnd, end)
+	}
+	parsed, err := sqlparser.ParseOne(sql)
+	if err != nil {
+		p.fail(p.withSource(err, int(p.toks[start].Start)))
+	}
+	stmt.SQLStmt = parsed.AST
+	p.pos = end
+	p.expectChar(';')
+	return &stmt
+}
+
+// terminator describes the tokens which end an embedded SQL construct.
+type terminator struct {
+	chars []byte
+	words []string
+	tokID int32
+}
+
+func untilChar(chars ...byte) terminator {
+	return terminator{chars: chars}
+}
+
+func untilWord(words ...string) terminator {
+	return terminator{words: words}
+}
+
+func untilTok(id int32) terminator {
+	return terminator{tokID: id}
+}
+
+// matches returns true if the token is one of the terminator's tokens.
+func (t terminator) matches(tok *scanner.InspectToken) bool {
+	for _, ch := range t.chars {
+		if isChar(tok, ch) {
+			return true
+		}
+	}
+	for _, w := range t.words {
+		if isWord(tok, w) {
+			return true
+		}
+	}
+	return t.tokID != 0 && tok.ID == t.tokID
+}
+
+// findEnd returns the position of the first token at or after the current
+// token which matches one of the terminators and is not nested within
+// parentheses, brackets or a CASE expression. It is an error if there is no
+// such token.
+func (p *parser) findEnd(terminators ...terminator) int {
+	depth := 0
+	for i := p.pos; i < len(p.toks); i++ {
+		tok := &p.toks[i]
+		if tok.ID == 0 {
+			break
+		}
+		if depth == 0 {
+			for _, t := range terminators {
+				if t.matches(tok) {
+					return i
+				}
+			}
+		}
+		switch {
+		case isChar(tok, '(') || isChar(tok, '[') || isWord(tok, "case"):
+			depth++
+		case isChar(tok, ')') || isChar(tok, ']') || isWord(tok, "end"):
+			depth--
+			if depth < 0 {
+				p.pos = i
+				p.syntaxError()
+			}
+		}
+	}
+	p.pos = len(p.toks) - 1
+	p.syntaxError()
+	return 0
+}
+
+// textBetween returns the input text from the token at position start up to,
+// but not including, the token at position end.
+func (p *parser) textBetween(start, end int) string {
+	return p.in[p.toks[start].Start:p.toks[end].Start]
+}
+
+// parseExpr parses a SQL expression which ends at one of the given
+// terminators. The parser is positioned at the terminating token afterwards.
+func (p *parser) parseExpr(terminators ...terminator) plpgsqltree.Expr {
+	start := p.pos
+	end := p.findEnd(terminators...)
+	if start == end {
+		p.syntaxError()
+	}
+	expr, err := sqlparser.ParseExpr(p.textBetween(start, end))
+	if err != nil {
+		p.fail(p.withSource(err, int(p.toks[start].Start)))
+	}
+	p.pos = end
+	return expr
+}
+
+// parseSQLStmt parses a SQL statement which ends at one of the given
+// terminators. The parser is positioned at the terminating token afterwards.
+func (p *parser) parseSQLStmt(terminators ...terminator) tree.Statement {
+	start := p.pos
+	end := p.findEnd(terminators...)
+	if start == end {
+		p.syntaxError()
+	}
+	stmt, err := sqlparser.ParseOne(p.textBetween(start, end))
+	if err != nil {
+		p.fail(p.withSource(err, int(p.toks[start].Start)))
+	}
+	p.pos = end
+	return stmt.AST
+}
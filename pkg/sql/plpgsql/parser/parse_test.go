@@ -0,0 +1,59 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils/datapathutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/datadriven"
+)
+
+// TestParseDatadriven verifies that PL/pgSQL function bodies can be parsed and
+// that the formatted syntax tree parses to the same tree.
+func TestParseDatadriven(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	datadriven.Walk(t, datapathutils.TestDataPath(t), func(t *testing.T, path string) {
+		datadriven.RunTest(t, path, func(t *testing.T, d *datadriven.TestData) string {
+			switch d.Cmd {
+			case "parse":
+				block, err := parser.Parse(d.Input)
+				if err != nil {
+					d.Fatalf(t, "unexpected parse error: %v", err)
+				}
+				ref := tree.AsString(block)
+				reparsed, err := parser.Parse(ref)
+				if err != nil {
+					d.Fatalf(t, "cannot parse formatted body %q: %v", ref, err)
+				}
+				if s := tree.AsString(reparsed); s != ref {
+					d.Fatalf(t, "formatting does not roundtrip:\n%s\n%s", ref, s)
+				}
+				return ref + "\n"
+
+			case "error":
+				_, err := parser.Parse(d.Input)
+				if err == nil {
+					d.Fatalf(t, "expected error, found none")
+				}
+				return fmt.Sprintf("%s\n%s\n", pgerror.GetPGCode(err), err)
+			}
+			d.Fatalf(t, "unsupported command: %s", d.Cmd)
+			return ""
+		})
+	})
+}
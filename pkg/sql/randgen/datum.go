@@ -284,11 +284,52 @@ func RandDatumWithNullChance(
 		return tree.NewDTSVector(tsearch.RandomTSVector(rng))
 	case types.TSQueryFamily:
 		return tree.NewDTSQuery(tsearch.RandomTSQuery(rng))
+	case types.RangeFamily, types.MultirangeFamily:
+		// If the input type is a wildcard that is not hydrated with the subtype
+		// of a user-defined range, then return NULL.
+		if typ.RangeSubtype() == nil {
+			return tree.DNull
+		}
+		if typ.Family() == types.RangeFamily {
+			return randRange(rng, typ)
+		}
+		ranges := make([]*tree.DRange, rng.Intn(4))
+		for i := range ranges {
+			ranges[i] = randRange(rng, typ.MultirangeContents())
+		}
+		d, err := tree.NewDMultirange(typ, ranges)
+		if err != nil {
+			panic(err)
+		}
+		return d
 	default:
 		panic(errors.AssertionFailedf("invalid type %v", typ.DebugString()))
 	}
 }
 
+// randRange generates a random range of the given range type. A NULL bound is
+// generated 10% of the time, which makes the bound infinite.
+func randRange(rng *rand.Rand, typ *types.T) *tree.DRange {
+	for {
+		if rng.Intn(10) == 0 {
+			return tree.MakeEmptyDRange(typ)
+		}
+		lower := RandDatum(rng, typ.RangeSubtype(), true /* nullOk */)
+		upper := RandDatum(rng, typ.RangeSubtype(), true /* nullOk */)
+		lowerInc, upperInc := rng.Intn(2) == 0, rng.Intn(2) == 0
+		r, err := tree.NewDRange(typ, lower, upper, lowerInc, upperInc)
+		if err != nil {
+			// The bounds may be out of order.
+			r, err = tree.NewDRange(typ, upper, lower, lowerInc, upperInc)
+		}
+		if err == nil {
+			return r
+		}
+		// The bounds of a discrete range could not be canonicalized, so try
+		// again.
+	}
+}
+
 // RandArray generates a random DArray where the contents have nullChance
 // of being null.
 func RandArray(rng *rand.Rand, typ *types.T, nullChance int) tree.Datum {
@@ -25,7 +25,7 @@ import (
 var (
 	// SeedTypes includes the following types that form the basis of randomly
 	// generated types:
-	//   - All scalar types, except UNKNOWN and ANY
+	//   - All scalar types, except UNKNOWN, ANY and TRIGGER
 	//   - ARRAY of ANY, where the ANY will be replaced with one of the legal
 	//     array element types in RandType
 	//   - OIDVECTOR and INT2VECTOR types
@@ -40,7 +40,7 @@ var (
 func init() {
 	for _, typ := range types.OidToType {
 		switch typ.Oid() {
-		case oid.T_unknown, oid.T_anyelement:
+		case oid.T_unknown, oid.T_anyelement, oid.T_trigger:
 			// Don't include these.
 		case oid.T_anyarray, oid.T_oidvector, oid.T_int2vector:
 			// Include these.
@@ -246,7 +246,7 @@ func RandEncodableType(rng *rand.Rand) *types.T {
 				}
 			}
 
-		case types.VoidFamily:
+		case types.VoidFamily, types.TriggerFamily:
 			return false
 
 		}
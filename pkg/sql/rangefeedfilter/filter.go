@@ -0,0 +1,383 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package rangefeedfilter implements the evaluation of the value filters that
+// rangefeed clients push down to KV (see kvpb.RangeFeedValueFilter). It lives
+// in pkg/sql since evaluating a filter requires decoding SQL rows; it's
+// injected into the rangefeed package on init.
+package rangefeedfilter
+
+import (
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+func init() {
+	rangefeed.NewValueFilter = New
+}
+
+const (
+	// maxPredicateLength is the maximum length of the SQL text of a predicate.
+	maxPredicateLength = 4 << 10 // 4 KiB
+	// maxPredicateExprs is the maximum number of expressions in a predicate,
+	// which bounds the cost of evaluating it for every event.
+	maxPredicateExprs = 256
+	// memoryLimit is the maximum amount of memory that a filter can use to
+	// decode a row and evaluate its predicate.
+	memoryLimit = 16 << 20 // 16 MiB
+)
+
+// New returns a rangefeed.ValueFilter which evaluates the provided filter, or
+// nil if the filter has neither a predicate nor a projection. An error is
+// returned if the filter can't be safely evaluated on the server; clients use
+// New to validate a filter before pushing it down.
+//
+// The predicate is evaluated in a sandbox: it may only use immutable
+// operators and functions, it can't reference user-defined types (which can't
+// be resolved without access to descriptors), its size and complexity are
+// capped, and the memory used to decode rows is bounded. Errors or panics
+// during its evaluation, as well as failures to project a value, are returned
+// by Apply and terminate the rangefeed registration; the event is never sent
+// unfiltered.
+func New(
+	ctx context.Context, st *cluster.Settings, f *kvpb.RangeFeedValueFilter,
+) (rangefeed.ValueFilter, error) {
+	if f.Predicate == "" && len(f.ProjectedColumnIDs) == 0 {
+		return nil, nil
+	}
+	spec := &f.IndexFetchSpec
+	if spec.IsSecondaryIndex {
+		return nil, errors.Newf(
+			"rangefeed value filters are only supported on primary indexes, found %s@%s",
+			spec.TableName, spec.IndexName)
+	}
+	if spec.MaxFamilyID != 0 {
+		return nil, errors.Newf(
+			"rangefeed value filters are only supported on tables with a single column family, found %s",
+			spec.TableName)
+	}
+
+	vf := &valueFilter{
+		spec:      spec,
+		projected: catalog.MakeTableColSet(f.ProjectedColumnIDs...),
+	}
+	if f.Predicate != "" {
+		if err := vf.initPredicate(ctx, st, f.Predicate); err != nil {
+			return nil, errors.Wrapf(err, "invalid rangefeed value filter predicate %q", f.Predicate)
+		}
+	}
+	return vf, nil
+}
+
+// valueFilter implements rangefeed.ValueFilter.
+type valueFilter struct {
+	spec      *fetchpb.IndexFetchSpec
+	projected catalog.TableColSet
+
+	// hasPredicate is set if the filter has a predicate, in which case the
+	// fields under mu are initialized.
+	hasPredicate bool
+
+	mu struct {
+		syncutil.Mutex
+		fetcher    row.Fetcher
+		kvProvider row.KVProvider
+		alloc      tree.DatumAlloc
+		evalCtx    eval.Context
+		expr       execinfrapb.ExprHelper
+		// mon bounds the memory used by the fetcher and acc, which accounts
+		// for the row being filtered.
+		mon *mon.BytesMonitor
+		acc mon.BoundAccount
+	}
+}
+
+var _ rangefeed.ValueFilter = (*valueFilter)(nil)
+
+// columnTypes implements tree.IndexedVarContainer for type checking the
+// predicate against the fetched columns.
+type columnTypes []*types.T
+
+var _ tree.IndexedVarContainer = columnTypes(nil)
+
+// IndexedVarResolvedType is part of the tree.IndexedVarContainer interface.
+func (c columnTypes) IndexedVarResolvedType(idx int) *types.T {
+	return c[idx]
+}
+
+// IndexedVarNodeFormatter is part of the tree.IndexedVarContainer interface.
+func (columnTypes) IndexedVarNodeFormatter(int) tree.NodeFormatter {
+	return nil
+}
+
+func (f *valueFilter) initPredicate(ctx context.Context, st *cluster.Settings, predicate string) error {
+	if len(predicate) > maxPredicateLength {
+		return errors.Newf("predicate is longer than %d bytes", maxPredicateLength)
+	}
+	typs := make([]*types.T, len(f.spec.FetchedColumns))
+	for i := range f.spec.FetchedColumns {
+		col := &f.spec.FetchedColumns[i]
+		if col.Type.UserDefined() {
+			return errors.Newf("column %q has user-defined type %s", col.Name, col.Type.SQLString())
+		}
+		typs[i] = col.Type
+	}
+
+	// Bind the ordinal references to the fetched columns and type check the
+	// predicate. The predicate is evaluated outside of any session or
+	// transaction, so only immutable expressions are allowed.
+	expr, err := parser.ParseExpr(predicate)
+	if err != nil {
+		return err
+	}
+	h := tree.MakeIndexedVarHelper(columnTypes(typs), len(typs))
+	var numExprs int
+	expr, err = tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		if numExprs++; numExprs > maxPredicateExprs {
+			return false, nil, errors.Newf("predicate has more than %d expressions", maxPredicateExprs)
+		}
+		if ivar, ok := expr.(*tree.IndexedVar); ok {
+			newVar, err := h.BindIfUnbound(ivar)
+			return false, newVar, err
+		}
+		return true, expr, nil
+	})
+	if err != nil {
+		return err
+	}
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = h.Container()
+	semaCtx.Properties.Require("rangefeed value filters",
+		tree.RejectSpecial|tree.RejectStableOperators|tree.RejectVolatileFunctions|tree.RejectSubqueries)
+	typedExpr, err := tree.TypeCheck(ctx, expr, &semaCtx, types.Bool)
+	if err != nil {
+		return err
+	}
+	if typ := typedExpr.ResolvedType(); typ.Family() != types.BoolFamily &&
+		typ.Family() != types.UnknownFamily {
+		return errors.Newf("expected predicate of type bool, found %s", typ.SQLString())
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// The monitor isn't connected to the node's memory pool: filters live as
+	// long as their registration, which has no hook to release the memory, so
+	// each filter is bounded on its own instead.
+	f.mu.mon = mon.NewMonitorWithLimit(
+		"rangefeed-value-filter",
+		mon.MemoryResource,
+		memoryLimit,
+		nil,           /* curCount */
+		nil,           /* maxHist */
+		-1,            /* increment */
+		math.MaxInt64, /* noteworthy */
+		st,            /* settings */
+	)
+	f.mu.mon.Start(ctx, nil /* pool */, mon.NewStandaloneBudget(memoryLimit))
+	f.mu.acc = f.mu.mon.MakeBoundAccount()
+	if err := f.mu.fetcher.Init(ctx, row.FetcherInitArgs{
+		WillUseKVProvider: true,
+		Alloc:             &f.mu.alloc,
+		MemMonitor:        f.mu.mon,
+		Spec:              f.spec,
+	}); err != nil {
+		return err
+	}
+	f.mu.evalCtx = eval.Context{
+		SessionDataStack: sessiondata.NewStack(&sessiondata.SessionData{}),
+		Settings:         st,
+	}
+	if err := f.mu.expr.Init(
+		ctx, execinfrapb.Expression{LocalExpr: typedExpr}, typs, &semaCtx, &f.mu.evalCtx,
+	); err != nil {
+		return err
+	}
+	f.hasPredicate = true
+	return nil
+}
+
+// Apply implements the rangefeed.ValueFilter interface.
+func (f *valueFilter) Apply(
+	ctx context.Context, value *kvpb.RangeFeedValue,
+) (*kvpb.RangeFeedValue, bool, error) {
+	// Deletion tombstones are always sent since they don't have any columns.
+	if !value.Value.IsPresent() {
+		return value, true, nil
+	}
+	// So are the keys that don't belong to the filter's index (which can be the
+	// case if the index was changed since the filter was created), unless their
+	// columns need to be projected.
+	if !f.matchesIndex(value.Key) {
+		if !f.projected.Empty() {
+			return nil, false, errors.Newf("can't project key outside of index %s@%s",
+				f.spec.TableName, f.spec.IndexName)
+		}
+		return value, true, nil
+	}
+	if f.hasPredicate {
+		if ok, err := f.evalPredicate(ctx, value); err != nil || !ok {
+			return nil, false, err
+		}
+	}
+	if f.projected.Empty() {
+		return value, true, nil
+	}
+	value, err := f.project(value)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// matchesIndex returns whether the key belongs to the filter's index.
+func (f *valueFilter) matchesIndex(key roachpb.Key) bool {
+	_, tenantID, err := keys.DecodeTenantPrefix(key)
+	if err != nil {
+		return false
+	}
+	rest, tableID, indexID, err := keys.MakeSQLCodec(tenantID).DecodeIndexPrefix(key)
+	if err != nil {
+		return false
+	}
+	return tableID == uint32(f.spec.TableID) && indexID == uint32(f.spec.IndexID) &&
+		len(key)-len(rest) == int(f.spec.KeyPrefixLength)
+}
+
+// evalPredicate returns whether the row in the event's value matches the
+// predicate. An error is returned if the row can't be decoded, exceeds the
+// filter's memory limit, or if the predicate fails to evaluate.
+func (f *valueFilter) evalPredicate(
+	ctx context.Context, value *kvpb.RangeFeedValue,
+) (ok bool, retErr error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			// Internal errors are propagated as panics by the row decoding and
+			// expression evaluation code; they must not crash the node, so they
+			// are returned instead.
+			if caught, err := errorutil.ShouldCatch(r); caught {
+				ok, retErr = false, errors.Wrap(err, "filtering row")
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	if err := f.mu.acc.ResizeTo(ctx, int64(len(value.Key)+len(value.Value.RawBytes))); err != nil {
+		return false, err
+	}
+	f.mu.kvProvider.KVs = append(f.mu.kvProvider.KVs[:0], roachpb.KeyValue{
+		Key:   value.Key,
+		Value: value.Value,
+	})
+	if err := f.mu.fetcher.ConsumeKVProvider(ctx, &f.mu.kvProvider); err != nil {
+		return false, errors.Wrap(err, "decoding row")
+	}
+	datums, _, err := f.mu.fetcher.NextRow(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "decoding row")
+	}
+	if datums == nil {
+		return false, errors.AssertionFailedf("no row decoded from key %s", value.Key)
+	}
+	var datumsSize int64
+	for _, d := range datums {
+		datumsSize += int64(d.Size())
+	}
+	if err := f.mu.acc.Grow(ctx, datumsSize); err != nil {
+		return false, err
+	}
+	ok, err = f.mu.expr.EvalFilter(ctx, datums)
+	if err != nil {
+		return false, errors.Wrap(err, "evaluating predicate")
+	}
+	return ok, nil
+}
+
+// project returns a copy of the event with all the columns that are not
+// projected elided from its value and previous value.
+func (f *valueFilter) project(value *kvpb.RangeFeedValue) (*kvpb.RangeFeedValue, error) {
+	newValue, err := f.projectValue(value.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "projecting value")
+	}
+	newPrevValue := value.PrevValue
+	if newPrevValue.IsPresent() {
+		if newPrevValue, err = f.projectValue(value.PrevValue); err != nil {
+			return nil, errors.Wrap(err, "projecting previous value")
+		}
+	}
+	return &kvpb.RangeFeedValue{
+		Key:       value.Key,
+		Value:     newValue,
+		PrevValue: newPrevValue,
+	}, nil
+}
+
+// projectValue re-encodes the provided primary index value, retaining only
+// the projected columns. Values which are not encoded as tuples are returned
+// unchanged.
+func (f *valueFilter) projectValue(value roachpb.Value) (roachpb.Value, error) {
+	if value.GetTag() != roachpb.ValueType_TUPLE {
+		return value, nil
+	}
+	b, err := value.GetTuple()
+	if err != nil {
+		return roachpb.Value{}, err
+	}
+	projected := make([]byte, 0, len(b))
+	var colID, lastProjectedColID descpb.ColumnID
+	for len(b) > 0 {
+		_, dataOffset, colIDDelta, typ, err := encoding.DecodeValueTag(b)
+		if err != nil {
+			return roachpb.Value{}, err
+		}
+		n, err := encoding.PeekValueLengthWithOffsetsAndType(b, dataOffset, typ)
+		if err != nil {
+			return roachpb.Value{}, err
+		}
+		if n < dataOffset || n > len(b) {
+			return roachpb.Value{}, errors.Newf("malformed tuple: value of column %d is truncated",
+				colID+descpb.ColumnID(colIDDelta))
+		}
+		colID += descpb.ColumnID(colIDDelta)
+		if f.projected.Contains(colID) {
+			projected = encoding.EncodeValueTag(projected, uint32(colID-lastProjectedColID), typ)
+			projected = append(projected, b[dataOffset:n]...)
+			lastProjectedColID = colID
+		}
+		b = b[n:]
+	}
+	ret := roachpb.Value{Timestamp: value.Timestamp}
+	ret.SetTuple(projected)
+	return ret, nil
+}
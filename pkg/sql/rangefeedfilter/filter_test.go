@@ -0,0 +1,239 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangefeedfilter_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/fetchpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rangefeedfilter"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	st := s.ClusterSettings()
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, a INT, b STRING, c STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.t VALUES (1, 10, 'foo', 'x'), (2, 20, 'bar', 'y'), (3, NULL, 'baz', NULL)`)
+	sqlDB.Exec(t, `CREATE TABLE d.u (k INT PRIMARY KEY, a INT, b STRING, c STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.u VALUES (1, 10, 'foo', 'x')`)
+
+	// The rangefeed package must have been hooked up.
+	require.NotNil(t, rangefeed.NewValueFilter)
+
+	codec := keys.SystemSQLCodec
+	scanEvents := func(table string) []*kvpb.RangeFeedValue {
+		desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, codec, "d", table)
+		span := desc.PrimaryIndexSpan(codec)
+		kvs, err := kvDB.Scan(ctx, span.Key, span.EndKey, 0 /* maxRows */)
+		require.NoError(t, err)
+		events := make([]*kvpb.RangeFeedValue, len(kvs))
+		for i, kv := range kvs {
+			events[i] = &kvpb.RangeFeedValue{Key: kv.Key, Value: *kv.Value}
+		}
+		return events
+	}
+	tEvents, uEvents := scanEvents("t"), scanEvents("u")
+	require.Len(t, tEvents, 3)
+	require.Len(t, uEvents, 1)
+
+	desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, codec, "d", "t")
+	var spec fetchpb.IndexFetchSpec
+	require.NoError(t, rowenc.InitIndexFetchSpec(
+		&spec, codec, desc, desc.GetPrimaryIndex(), desc.PublicColumnIDs(),
+	))
+	makeFilter := func(predicate string, projected ...descpb.ColumnID) (rangefeed.ValueFilter, error) {
+		return rangefeedfilter.New(ctx, st, &kvpb.RangeFeedValueFilter{
+			IndexFetchSpec:     spec,
+			Predicate:          predicate,
+			ProjectedColumnIDs: projected,
+		})
+	}
+	// matches returns the indexes of the events which match the filter.
+	matches := func(f rangefeed.ValueFilter, events []*kvpb.RangeFeedValue) []int {
+		var res []int
+		for i, ev := range events {
+			_, ok, err := f.Apply(ctx, ev)
+			require.NoError(t, err)
+			if ok {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+
+	t.Run("predicate", func(t *testing.T) {
+		for _, tc := range []struct {
+			predicate string
+			exp       []int
+		}{
+			{predicate: `@2 > 15`, exp: []int{1}},
+			{predicate: `@2 < 15 OR @1 = 3`, exp: []int{0, 2}},
+			{predicate: `@3 LIKE 'ba%'`, exp: []int{1, 2}},
+			{predicate: `@4 IS NULL`, exp: []int{2}},
+			{predicate: `lower(@3) = 'FOO'`, exp: nil},
+		} {
+			t.Run(tc.predicate, func(t *testing.T) {
+				f, err := makeFilter(tc.predicate)
+				require.NoError(t, err)
+				require.Equal(t, tc.exp, matches(f, tEvents))
+
+				// Events from other indexes and deletion tombstones are always sent.
+				require.Equal(t, []int{0}, matches(f, uEvents))
+				tombstone := &kvpb.RangeFeedValue{
+					Key: tEvents[0].Key, Value: roachpb.Value{Timestamp: tEvents[0].Value.Timestamp},
+				}
+				_, ok, err := f.Apply(ctx, tombstone)
+				require.NoError(t, err)
+				require.True(t, ok)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, tc := range []struct {
+			predicate string
+			expErr    string
+		}{
+			{predicate: `@2`, expErr: `expected predicate of type bool`},
+			{predicate: `@5 > 1`, expErr: `invalid column ordinal`},
+			{predicate: `random() > 0.5`, expErr: `volatile functions are not allowed`},
+			{predicate: `now() > '2020-01-01'`, expErr: `context-dependent operators are not allowed`},
+			{predicate: `count(@1) > 1`, expErr: `aggregate functions are not allowed`},
+			{predicate: `@1 IN (SELECT 1)`, expErr: `subqueries are not allowed`},
+			{
+				predicate: `@3 = '` + strings.Repeat("a", 4<<10) + `'`,
+				expErr:    `predicate is longer than 4096 bytes`,
+			},
+			{
+				predicate: `@1` + strings.Repeat(` + 1`, 200) + ` > 0`,
+				expErr:    `predicate has more than 256 expressions`,
+			},
+		} {
+			t.Run(tc.predicate, func(t *testing.T) {
+				_, err := makeFilter(tc.predicate)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expErr)
+			})
+		}
+
+		// Tables with multiple column families are not supported.
+		sqlDB.Exec(t, `CREATE TABLE d.fams (k INT PRIMARY KEY, a INT, b INT, FAMILY (k, a), FAMILY (b))`)
+		famsDesc := desctestutils.TestingGetPublicTableDescriptor(kvDB, codec, "d", "fams")
+		var famsSpec fetchpb.IndexFetchSpec
+		require.NoError(t, rowenc.InitIndexFetchSpec(
+			&famsSpec, codec, famsDesc, famsDesc.GetPrimaryIndex(), famsDesc.PublicColumnIDs(),
+		))
+		_, err := rangefeedfilter.New(ctx, st, &kvpb.RangeFeedValueFilter{
+			IndexFetchSpec: famsSpec,
+			Predicate:      `@2 > 1`,
+		})
+		require.ErrorContains(t, err, "single column family")
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		// Only retain column a (ID 2).
+		f, err := makeFilter(`@2 >= 10`, 2)
+		require.NoError(t, err)
+		var projected []*kvpb.RangeFeedValue
+		for _, ev := range tEvents {
+			withPrev := *ev
+			withPrev.PrevValue = ev.Value
+			withPrev.PrevValue.Timestamp = hlc.Timestamp{}
+			res, ok, err := f.Apply(ctx, &withPrev)
+			require.NoError(t, err)
+			if ok {
+				require.Equal(t, ev.Key, res.Key)
+				require.Equal(t, ev.Value.Timestamp, res.Value.Timestamp)
+				require.Less(t, len(res.Value.RawBytes), len(ev.Value.RawBytes))
+				projected = append(projected, res, &kvpb.RangeFeedValue{Key: res.Key, Value: res.PrevValue})
+			}
+		}
+		require.Len(t, projected, 4)
+
+		// Columns b and c are now NULL, while a is unchanged.
+		check, err := makeFilter(`@3 IS NULL AND @4 IS NULL AND @2 IN (10, 20)`)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2, 3}, matches(check, projected))
+		require.Equal(t, []int(nil), matches(check, tEvents[:2]))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		// Events which can't be filtered or projected are never sent, an error is
+		// returned instead.
+		requireErr := func(f rangefeed.ValueFilter, ev *kvpb.RangeFeedValue, expErr string) {
+			t.Helper()
+			res, ok, err := f.Apply(ctx, ev)
+			require.ErrorContains(t, err, expErr)
+			require.False(t, ok)
+			require.Nil(t, res)
+		}
+
+		// Evaluation errors.
+		f, err := makeFilter(`10 // (@2 - 10) = 1`)
+		require.NoError(t, err)
+		requireErr(f, tEvents[0], `division by zero`)
+		require.Equal(t, []int{0}, matches(f, tEvents[1:]))
+
+		// Decoding errors.
+		tuple, err := tEvents[1].Value.GetTuple()
+		require.NoError(t, err)
+		corrupt := &kvpb.RangeFeedValue{Key: tEvents[1].Key}
+		corrupt.Value.SetTuple(tuple[:len(tuple)-1])
+		corrupt.Value.Timestamp = tEvents[1].Value.Timestamp
+		requireErr(f, corrupt, `filtering row`)
+
+		// Rows exceeding the memory limit.
+		large := &kvpb.RangeFeedValue{Key: tEvents[0].Key}
+		large.Value.SetTuple(make([]byte, 17<<20))
+		large.Value.Timestamp = tEvents[0].Value.Timestamp
+		requireErr(f, large, `memory budget exceeded`)
+
+		// Projection errors, including the ones for previous values and for keys
+		// outside of the index.
+		f, err = makeFilter(``, 2)
+		require.NoError(t, err)
+		requireErr(f, corrupt, `projecting value`)
+		withCorruptPrev := *tEvents[1]
+		withCorruptPrev.PrevValue = corrupt.Value
+		requireErr(f, &withCorruptPrev, `projecting previous value`)
+		requireErr(f, uEvents[0], `can't project key outside of index`)
+	})
+
+	t.Run("no-op", func(t *testing.T) {
+		f, err := makeFilter(``)
+		require.NoError(t, err)
+		require.Nil(t, f)
+	})
+}
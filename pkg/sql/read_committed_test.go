@@ -0,0 +1,122 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadCommittedForeignKeyChecks verifies that concurrent READ COMMITTED
+// transactions cannot violate a foreign key constraint by inserting a child
+// row while its parent row is deleted. Each statement of such a transaction
+// reads from its own snapshot, so a check which does not lock the rows it
+// reads would miss the concurrent write once the other transaction commits.
+func TestReadCommittedForeignKeyChecks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	runner := sqlutils.MakeSQLRunner(sqlDB)
+	runner.Exec(t, `CREATE TABLE parent (p INT PRIMARY KEY)`)
+	runner.Exec(t, `CREATE TABLE child (c INT PRIMARY KEY, p INT REFERENCES parent (p), INDEX (p))`)
+
+	const fkViolation = `violates foreign key constraint "child_p_fkey"`
+	for _, tc := range []struct {
+		name string
+		// first is run and left uncommitted by the first transaction.
+		first string
+		// second is run by the second transaction. It blocks on the first
+		// transaction, and fails with expErr once the first one commits.
+		second string
+		expErr string
+	}{
+		{
+			name:   "insert child after delete parent",
+			first:  `DELETE FROM parent WHERE p = 1`,
+			second: `INSERT INTO child VALUES (1, 1)`,
+			expErr: fkViolation,
+		},
+		{
+			name:   "delete parent after insert child",
+			first:  `INSERT INTO child VALUES (2, 2)`,
+			second: `DELETE FROM parent WHERE p = 2`,
+			expErr: fkViolation,
+		},
+		{
+			name:   "update parent after insert child",
+			first:  `INSERT INTO child VALUES (3, 3)`,
+			second: `UPDATE parent SET p = 30 WHERE p = 3`,
+			expErr: fkViolation,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			runner.Exec(t, `DELETE FROM child`)
+			runner.Exec(t, `DELETE FROM parent`)
+			runner.Exec(t, `INSERT INTO parent VALUES (1), (2), (3)`)
+
+			txnOpts := &gosql.TxOptions{Isolation: gosql.LevelReadCommitted}
+			txn1, err := sqlDB.BeginTx(ctx, txnOpts)
+			require.NoError(t, err)
+			defer func() { _ = txn1.Rollback() }()
+			_, err = txn1.Exec(tc.first)
+			require.NoError(t, err)
+
+			txn2, err := sqlDB.BeginTx(ctx, txnOpts)
+			require.NoError(t, err)
+			defer func() { _ = txn2.Rollback() }()
+			errCh := make(chan error, 1)
+			go func() {
+				_, err := txn2.Exec(tc.second)
+				errCh <- err
+			}()
+
+			// Wait for the second statement to block on the first transaction. If
+			// the check does not lock the rows it reads, the statement may finish
+			// without blocking, and succeed.
+			testutils.SucceedsSoon(t, func() error {
+				if len(errCh) > 0 {
+					return nil
+				}
+				var waiting bool
+				runner.QueryRow(t, `
+SELECT count(*) > 0 FROM crdb_internal.cluster_locks
+WHERE table_name IN ('parent', 'child') AND NOT granted`,
+				).Scan(&waiting)
+				if !waiting {
+					return errors.New("second statement is not waiting")
+				}
+				return nil
+			})
+
+			require.NoError(t, txn1.Commit())
+			require.Regexp(t, tc.expErr, <-errCh)
+
+			runner.CheckQueryResults(t,
+				`SELECT count(*) FROM child WHERE p NOT IN (SELECT p FROM parent)`,
+				[][]string{{"0"}},
+			)
+		})
+	}
+}
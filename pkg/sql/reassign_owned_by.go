@@ -299,18 +299,22 @@ func (n *reassignOwnedByNode) reassignTypeOwner(
 	if mutableTypDesc.Dropped() {
 		return nil
 	}
-	arrayDesc, err := params.p.Descriptors().MutableByID(params.p.txn).Type(params.ctx, typDesc.GetArrayTypeID())
-	if err != nil {
-		return err
-	}
-
 	typeName, err := params.p.getQualifiedTypeName(params.ctx, mutableTypDesc.(*typedesc.Mutable))
 	if err != nil {
 		return err
 	}
-	arrayTypeName, err := params.p.getQualifiedTypeName(params.ctx, arrayDesc)
-	if err != nil {
-		return err
+	// Domains don't have an array type.
+	var arrayDesc *typedesc.Mutable
+	arrayTypeName := &tree.TypeName{}
+	if typDesc.GetArrayTypeID() != descpb.InvalidID {
+		arrayDesc, err = params.p.Descriptors().MutableByID(params.p.txn).Type(params.ctx, typDesc.GetArrayTypeID())
+		if err != nil {
+			return err
+		}
+		arrayTypeName, err = params.p.getQualifiedTypeName(params.ctx, arrayDesc)
+		if err != nil {
+			return err
+		}
 	}
 
 	owner, err := decodeusername.FromRoleSpec(
@@ -329,6 +333,9 @@ func (n *reassignOwnedByNode) reassignTypeOwner(
 	); err != nil {
 		return err
 	}
+	if arrayDesc == nil {
+		return nil
+	}
 	if err := params.p.writeTypeSchemaChange(
 		params.ctx, arrayDesc, tree.AsStringWithFQNames(n.n, params.p.Ann()),
 	); err != nil {
@@ -91,6 +91,17 @@ func (n *refreshMaterializedViewNode) startExec(params runParams) error {
 		)
 	}
 
+	// An incrementally refreshed view is updated in place with the changes to
+	// its base tables since its last refresh, if they are available. Otherwise
+	// it is recomputed below.
+	if n.desc.IncrementalRefresh != nil && n.n.RefreshDataOption != tree.RefreshDataClear &&
+		!n.desc.IsRefreshViewRequired() {
+		refreshed, err := params.p.refreshMaterializedViewIncrementally(params.ctx, n.desc)
+		if err != nil || refreshed {
+			return err
+		}
+	}
+
 	// Prepare the new set of indexes by cloning all existing indexes on the view.
 	newPrimaryIndex := n.desc.GetPrimaryIndex().IndexDescDeepCopy()
 	newIndexes := make([]descpb.IndexDescriptor, len(n.desc.PublicNonPrimaryIndexes()))
@@ -130,10 +130,20 @@ func (g *routineGenerator) Start(ctx context.Context, txn *kv.Txn) (err error) {
 		}()
 	}
 
+	rrw := NewRowResultWriter(&g.rch)
+	if g.expr.Program != nil {
+		// The body of a PL/pgSQL function is interpreted. The interpreter
+		// executes each expression of the body as a separate routine.
+		if err = g.runPLpgSQL(ctx, txn, rrw); err != nil {
+			return err
+		}
+		g.rci = newRowContainerIterator(ctx, g.rch)
+		return nil
+	}
+
 	// Execute each statement in the routine sequentially.
 	stmtIdx := 0
 	ef := newExecFactory(ctx, g.p)
-	rrw := NewRowResultWriter(&g.rch)
 	err = g.expr.ForEachPlan(ctx, ef, g.args, func(plan tree.RoutinePlan, isFinalPlan bool) error {
 		stmtIdx++
 		opName := "udf-stmt-" + g.expr.Name + "-" + strconv.Itoa(stmtIdx)
@@ -283,6 +283,10 @@ type FetcherInitArgs struct {
 	// wait while attempting to acquire a lock on a key or while blocking on an
 	// existing lock in order to perform a non-locking read on a key.
 	LockTimeout time.Duration
+	// DurableLocking, if set, indicates that the Exclusive locks acquired by the
+	// fetcher should be Replicated, so that they survive lease transfers and
+	// node restarts.
+	DurableLocking bool
 	// Alloc is used for buffered allocation of decoded datums.
 	Alloc      *tree.DatumAlloc
 	MemMonitor *mon.BytesMonitor
@@ -415,11 +419,15 @@ func (rf *Fetcher) Init(ctx context.Context, args FetcherInitArgs) error {
 		rf.kvFetcher = args.StreamingKVFetcher
 	} else if !args.WillUseKVProvider {
 		var batchRequestsIssued int64
+		lockReplicated := getKeyLockingReplicated(
+			args.Txn, args.LockStrength, args.LockWaitPolicy, args.DurableLocking,
+		)
 		fetcherArgs := newTxnKVFetcherArgs{
 			reverse:                    args.Reverse,
 			lockStrength:               args.LockStrength,
 			lockWaitPolicy:             args.LockWaitPolicy,
 			lockTimeout:                args.LockTimeout,
+			lockReplicated:             lockReplicated,
 			acc:                        rf.kvFetcherMemAcc,
 			forceProductionKVBatchSize: args.ForceProductionKVBatchSize,
 			batchRequestsIssued:        &batchRequestsIssued,
@@ -463,6 +471,9 @@ func (rf *Fetcher) setTxnAndSendFn(txn *kv.Txn, sendFn sendFunc) error {
 		)
 	}
 	f.setTxnAndSendFn(txn, sendFn)
+	f.lockReplicated = getKeyLockingReplicated(
+		txn, rf.args.LockStrength, rf.args.LockWaitPolicy, rf.args.DurableLocking,
+	)
 	return nil
 }
 
@@ -150,6 +150,9 @@ type txnKVFetcher struct {
 	reverse bool
 	// lockStrength represents the locking mode to use when fetching KVs.
 	lockStrength lock.Strength
+	// lockReplicated indicates whether the locks acquired when fetching KVs
+	// should be acquired with the Replicated durability.
+	lockReplicated bool
 	// lockWaitPolicy represents the policy to be used for handling conflicting
 	// locks held by other active transactions.
 	lockWaitPolicy lock.WaitPolicy
@@ -259,6 +262,7 @@ type newTxnKVFetcherArgs struct {
 	lockStrength               descpb.ScanLockingStrength
 	lockWaitPolicy             descpb.ScanLockingWaitPolicy
 	lockTimeout                time.Duration
+	lockReplicated             bool
 	acc                        *mon.BoundAccount
 	forceProductionKVBatchSize bool
 	batchRequestsIssued        *int64
@@ -280,6 +284,7 @@ func newTxnKVFetcherInternal(args newTxnKVFetcherArgs) *txnKVFetcher {
 		lockStrength:               GetKeyLockingStrength(args.lockStrength),
 		lockWaitPolicy:             getWaitPolicy(args.lockWaitPolicy),
 		lockTimeout:                args.lockTimeout,
+		lockReplicated:             args.lockReplicated,
 		acc:                        args.acc,
 		forceProductionKVBatchSize: args.forceProductionKVBatchSize,
 		requestAdmissionHeader:     args.requestAdmissionHeader,
@@ -432,7 +437,9 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 		ba.Header.WholeRowsOfSize = int32(f.indexFetchSpec.MaxKeysPerRow)
 	}
 	ba.AdmissionHeader = f.requestAdmissionHeader
-	ba.Requests = spansToRequests(f.spans.Spans, f.scanFormat, f.reverse, f.lockStrength, f.reqsScratch)
+	ba.Requests = spansToRequests(
+		f.spans.Spans, f.scanFormat, f.reverse, f.lockStrength, f.lockReplicated, f.reqsScratch,
+	)
 
 	if log.ExpensiveLogEnabled(ctx, 2) {
 		log.VEventf(ctx, 2, "Scan %s", f.spans)
@@ -716,7 +723,8 @@ const requestUnionOverhead = int64(unsafe.Sizeof(kvpb.RequestUnion{}))
 // spansToRequests converts the provided spans to the corresponding requests. If
 // a span doesn't have the EndKey set, then a Get request is used for it;
 // otherwise, a Scan (or ReverseScan if reverse is true) request is used with
-// the provided scan format.
+// the provided scan format. If keyLockingReplicated is set, the locks acquired
+// by the requests are Replicated.
 //
 // The provided reqsScratch is reused if it has enough capacity for all spans,
 // if not, a new slice is allocated.
@@ -725,6 +733,7 @@ func spansToRequests(
 	scanFormat kvpb.ScanFormat,
 	reverse bool,
 	keyLocking lock.Strength,
+	keyLockingReplicated bool,
 	reqsScratch []kvpb.RequestUnion,
 ) []kvpb.RequestUnion {
 	var reqs []kvpb.RequestUnion
@@ -759,6 +768,7 @@ func spansToRequests(
 				// single key fetch, which can be served using a GetRequest.
 				gets[curGet].req.Key = spans[i].Key
 				gets[curGet].req.KeyLocking = keyLocking
+				gets[curGet].req.KeyLockingReplicated = keyLockingReplicated
 				gets[curGet].union.Get = &gets[curGet].req
 				reqs[i].Value = &gets[curGet].union
 				curGet++
@@ -768,6 +778,7 @@ func spansToRequests(
 			scans[curScan].req.SetSpan(spans[i])
 			scans[curScan].req.ScanFormat = scanFormat
 			scans[curScan].req.KeyLocking = keyLocking
+			scans[curScan].req.KeyLockingReplicated = keyLockingReplicated
 			scans[curScan].union.ReverseScan = &scans[curScan].req
 			reqs[i].Value = &scans[curScan].union
 		}
@@ -782,6 +793,7 @@ func spansToRequests(
 				// single key fetch, which can be served using a GetRequest.
 				gets[curGet].req.Key = spans[i].Key
 				gets[curGet].req.KeyLocking = keyLocking
+				gets[curGet].req.KeyLockingReplicated = keyLockingReplicated
 				gets[curGet].union.Get = &gets[curGet].req
 				reqs[i].Value = &gets[curGet].union
 				curGet++
@@ -791,6 +803,7 @@ func spansToRequests(
 			scans[curScan].req.SetSpan(spans[i])
 			scans[curScan].req.ScanFormat = scanFormat
 			scans[curScan].req.KeyLocking = keyLocking
+			scans[curScan].req.KeyLockingReplicated = keyLockingReplicated
 			scans[curScan].union.Scan = &scans[curScan].req
 			reqs[i].Value = &scans[curScan].union
 		}
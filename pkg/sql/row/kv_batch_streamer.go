@@ -97,7 +97,10 @@ func (f *txnKVStreamer) SetupNextFetch(
 		reqsScratch[i] = kvpb.RequestUnion{}
 	}
 	// TODO(yuzefovich): consider supporting COL_BATCH_RESPONSE scan format.
-	reqs := spansToRequests(spans, kvpb.BATCH_RESPONSE, false /* reverse */, f.keyLocking, reqsScratch)
+	reqs := spansToRequests(
+		spans, kvpb.BATCH_RESPONSE, false /* reverse */, f.keyLocking,
+		false /* keyLockingReplicated */, reqsScratch,
+	)
 	if err := f.streamer.Enqueue(ctx, reqs); err != nil {
 		return err
 	}
@@ -57,6 +57,7 @@ func newTxnKVFetcher(
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
 	lockTimeout time.Duration,
+	durableLocking bool,
 	acc *mon.BoundAccount,
 	forceProductionKVBatchSize bool,
 ) *txnKVFetcher {
@@ -94,6 +95,7 @@ func newTxnKVFetcher(
 		lockStrength:               lockStrength,
 		lockWaitPolicy:             lockWaitPolicy,
 		lockTimeout:                lockTimeout,
+		lockReplicated:             getKeyLockingReplicated(txn, lockStrength, lockWaitPolicy, durableLocking),
 		acc:                        acc,
 		forceProductionKVBatchSize: forceProductionKVBatchSize,
 		batchRequestsIssued:        &batchRequestsIssued,
@@ -124,12 +126,13 @@ func NewDirectKVBatchFetcher(
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
 	lockTimeout time.Duration,
+	durableLocking bool,
 	acc *mon.BoundAccount,
 	forceProductionKVBatchSize bool,
 ) KVBatchFetcher {
 	f := newTxnKVFetcher(
 		txn, bsHeader, reverse, lockStrength, lockWaitPolicy,
-		lockTimeout, acc, forceProductionKVBatchSize,
+		lockTimeout, durableLocking, acc, forceProductionKVBatchSize,
 	)
 	f.scanFormat = kvpb.COL_BATCH_RESPONSE
 	f.indexFetchSpec = spec
@@ -149,12 +152,13 @@ func NewKVFetcher(
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
 	lockTimeout time.Duration,
+	durableLocking bool,
 	acc *mon.BoundAccount,
 	forceProductionKVBatchSize bool,
 ) *KVFetcher {
 	return newKVFetcher(newTxnKVFetcher(
 		txn, bsHeader, reverse, lockStrength, lockWaitPolicy,
-		lockTimeout, acc, forceProductionKVBatchSize,
+		lockTimeout, durableLocking, acc, forceProductionKVBatchSize,
 	))
 }
 
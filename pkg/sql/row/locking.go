@@ -11,6 +11,7 @@
 package row
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/errors"
@@ -27,9 +28,7 @@ func GetKeyLockingStrength(lockStrength descpb.ScanLockingStrength) lock.Strengt
 		// Promote to FOR_SHARE.
 		fallthrough
 	case descpb.ScanLockingStrength_FOR_SHARE:
-		// We currently perform no per-key locking when FOR_SHARE is used
-		// because Shared locks have not yet been implemented.
-		return lock.None
+		return lock.Shared
 
 	case descpb.ScanLockingStrength_FOR_NO_KEY_UPDATE:
 		// Promote to FOR_UPDATE.
@@ -44,6 +43,23 @@ func GetKeyLockingStrength(lockStrength descpb.ScanLockingStrength) lock.Strengt
 	}
 }
 
+// getKeyLockingReplicated returns whether the per-key locks acquired by
+// key-value scans should be acquired with the Replicated durability. Only
+// Exclusive locks may be replicated. Acquiring a replicated lock writes to the
+// lock table keyspace, so it is only possible in a root transaction and is not
+// supported with the SKIP LOCKED wait policy, which is only available to
+// read-only requests. In these cases, unreplicated locks are used instead.
+func getKeyLockingReplicated(
+	txn *kv.Txn,
+	lockStrength descpb.ScanLockingStrength,
+	lockWaitPolicy descpb.ScanLockingWaitPolicy,
+	durableLocking bool,
+) bool {
+	return durableLocking && txn != nil && txn.Type() == kv.RootTxn &&
+		GetKeyLockingStrength(lockStrength) == lock.Exclusive &&
+		lockWaitPolicy != descpb.ScanLockingWaitPolicy_SKIP_LOCKED
+}
+
 // getWaitPolicy returns the configured lock wait policy to use for key-value
 // scans.
 func getWaitPolicy(lockWaitPolicy descpb.ScanLockingWaitPolicy) lock.WaitPolicy {
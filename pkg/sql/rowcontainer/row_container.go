@@ -735,7 +735,11 @@ func NewDiskBackedIndexedRowContainer(
 }
 
 // AddRow implements SortableRowContainer.
+//
+// Rows can be added after GetRow was called, in which case the disk iterator
+// is recreated on the next GetRow call so that it sees the new row.
 func (f *DiskBackedIndexedRowContainer) AddRow(ctx context.Context, row rowenc.EncDatumRow) error {
+	f.resetIterator()
 	copy(f.scratchEncRow, row)
 	f.scratchEncRow[len(f.scratchEncRow)-1] = rowenc.DatumToEncDatum(
 		types.Int,
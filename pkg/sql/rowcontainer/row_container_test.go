@@ -643,6 +643,51 @@ func TestDiskBackedIndexedRowContainer(t *testing.T) {
 		}
 	})
 
+	// AddAfterGetRow spills an unordered DiskBackedIndexedRowContainer to disk
+	// and interleaves adding rows with reading them, verifying that rows added
+	// after the disk iterator was created are read correctly.
+	t.Run("AddAfterGetRow", func(t *testing.T) {
+		for i := 0; i < numTestRuns; i++ {
+			rows := make([]rowenc.EncDatumRow, numRows)
+			types := randgen.RandSortingTypes(rng, numCols)
+			for i := 0; i < numRows; i++ {
+				rows[i] = randgen.RandEncDatumRowOfTypes(rng, types)
+			}
+
+			func() {
+				rc := NewDiskBackedIndexedRowContainer(colinfo.NoOrdering, types, &evalCtx, tempEngine, memoryMonitor, diskMonitor)
+				defer rc.Close(ctx)
+				if err := rc.SpillToDisk(ctx); err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < numRows; i++ {
+					if err := rc.AddRow(ctx, rows[i]); err != nil {
+						t.Fatal(err)
+					}
+					// Read the new row and a random row that was added before it.
+					for _, pos := range []int{i, rng.Intn(i + 1)} {
+						readRow, err := rc.GetRow(ctx, pos)
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						if readRow.GetIdx() != pos {
+							t.Fatalf("expected row at pos %d but found %d", pos, readRow.GetIdx())
+						}
+						for col := range rows[pos] {
+							datum, err := readRow.GetDatum(col)
+							if err != nil {
+								t.Fatalf("unexpected error: %v", err)
+							}
+							if cmp := datum.Compare(&evalCtx, rows[pos][col].Datum); cmp != 0 {
+								t.Fatalf("read row is not equal to written one")
+							}
+						}
+					}
+				}
+			}()
+		}
+	})
+
 	// TestGetRow adds all rows into DiskBackedIndexedRowContainer, sorts them,
 	// and checks that both the index and the row are what we expect by GetRow()
 	// to be returned. Then, it spills to disk and does the same check again.
@@ -215,7 +215,7 @@ func TestEncDatumCompare(t *testing.T) {
 	for _, typ := range types.OidToType {
 		switch typ.Family() {
 		case types.AnyFamily, types.UnknownFamily, types.ArrayFamily, types.JsonFamily, types.TupleFamily, types.VoidFamily,
-			types.TSQueryFamily, types.TSVectorFamily:
+			types.TSQueryFamily, types.TSVectorFamily, types.TriggerFamily:
 			continue
 		case types.CollatedStringFamily:
 			typ = types.MakeCollatedString(types.String, *randgen.RandCollationLocale(rng))
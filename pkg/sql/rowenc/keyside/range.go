@@ -0,0 +1,155 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package keyside
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// encodeRangeKey generates an ordered key encoding of a range. The encoding
+// format for a non-empty range [a, b) is as follows:
+// [rangeMarker, lowerFinite, enc(a), lowerInclusive, upperFinite, enc(b),
+// upperExclusive].
+// An infinite bound is encoded as a single flag, and an empty range is encoded
+// as the empty flag following the marker. See encoding.EncodeRangeKeyMarker for
+// the order of the flags.
+func encodeRangeKey(b []byte, r *tree.DRange, dir encoding.Direction) ([]byte, error) {
+	b = encoding.EncodeRangeKeyMarker(b, dir)
+	return encodeRangeKeyBody(b, r, dir)
+}
+
+// encodeRangeKeyBody encodes a range without the range key marker.
+func encodeRangeKeyBody(b []byte, r *tree.DRange, dir encoding.Direction) ([]byte, error) {
+	if r.Empty {
+		return encoding.EncodeEmptyRangeKey(b, dir), nil
+	}
+	var err error
+	for _, bound := range []struct {
+		lower     bool
+		val       tree.Datum
+		inclusive bool
+	}{
+		{lower: true, val: r.Lower, inclusive: r.LowerInc},
+		{lower: false, val: r.Upper, inclusive: r.UpperInc},
+	} {
+		infinite := bound.val == tree.DNull
+		b = encoding.EncodeRangeKeyBoundPrefix(b, bound.lower, infinite, dir)
+		if infinite {
+			continue
+		}
+		b, err = Encode(b, bound.val, dir)
+		if err != nil {
+			return nil, err
+		}
+		b = encoding.EncodeRangeKeyBoundSuffix(b, bound.lower, bound.inclusive, dir)
+	}
+	return b, nil
+}
+
+// decodeRangeKey decodes a range key generated by encodeRangeKey.
+func decodeRangeKey(
+	a *tree.DatumAlloc, t *types.T, buf []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	buf, err := encoding.ValidateAndConsumeRangeKeyMarker(buf, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeRangeKeyBody(a, t, buf, dir)
+}
+
+// decodeRangeKeyBody decodes a range encoded by encodeRangeKeyBody.
+func decodeRangeKeyBody(
+	a *tree.DatumAlloc, t *types.T, buf []byte, dir encoding.Direction,
+) (*tree.DRange, []byte, error) {
+	result := &tree.DRange{Typ: t, Lower: tree.DNull, Upper: tree.DNull}
+	for _, lower := range []bool{true, false} {
+		var empty, infinite, inclusive bool
+		var err error
+		buf, empty, infinite, err = encoding.DecodeRangeKeyBoundPrefix(buf, lower, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if empty {
+			result.Empty = true
+			return result, buf, nil
+		}
+		if infinite {
+			continue
+		}
+		var d tree.Datum
+		d, buf, err = Decode(a, t.RangeSubtype(), buf, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf, inclusive, err = encoding.DecodeRangeKeyBoundSuffix(buf, lower, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lower {
+			result.Lower, result.LowerInc = d, inclusive
+		} else {
+			result.Upper, result.UpperInc = d, inclusive
+		}
+	}
+	return result, buf, nil
+}
+
+// encodeMultirangeKey generates an ordered key encoding of a multirange. The
+// encoding format for a multirange {a, b} is as follows:
+// [multirangeMarker, enc(a), enc(b), terminator],
+// where the ranges are encoded without their markers. The ranges of a
+// multirange are never empty, so the terminator is guaranteed to be less than
+// all encoded ranges, and two multiranges with the same prefix but different
+// lengths sort correctly.
+func encodeMultirangeKey(
+	b []byte, m *tree.DMultirange, dir encoding.Direction,
+) ([]byte, error) {
+	var err error
+	b = encoding.EncodeMultirangeKeyMarker(b, dir)
+	for _, r := range m.Ranges {
+		b, err = encodeRangeKeyBody(b, r, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encoding.EncodeMultirangeKeyTerminator(b, dir), nil
+}
+
+// decodeMultirangeKey decodes a multirange key generated by
+// encodeMultirangeKey.
+func decodeMultirangeKey(
+	a *tree.DatumAlloc, t *types.T, buf []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	buf, err := encoding.ValidateAndConsumeMultirangeKeyMarker(buf, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &tree.DMultirange{Typ: t}
+	for {
+		if len(buf) == 0 {
+			return nil, nil, errors.AssertionFailedf("invalid multirange encoding (unterminated)")
+		}
+		if encoding.IsMultirangeKeyDone(buf, dir) {
+			buf = buf[1:]
+			break
+		}
+		var r *tree.DRange
+		r, buf, err = decodeRangeKeyBody(a, t.MultirangeContents(), buf, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Ranges = append(result.Ranges, r)
+	}
+	return result, buf, nil
+}
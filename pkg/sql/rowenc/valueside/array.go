@@ -235,6 +235,10 @@ func DatumTypeToArrayElementEncodingType(t *types.T) (encoding.Type, error) {
 		return encoding.JSON, nil
 	case types.TupleFamily:
 		return encoding.Tuple, nil
+	case types.RangeFamily:
+		return encoding.Range, nil
+	case types.MultirangeFamily:
+		return encoding.Multirange, nil
 	default:
 		return 0, errors.AssertionFailedf(
 			"no known encoding type for %s", redact.Safe(t.Family().Name()),
@@ -324,6 +328,18 @@ func encodeArrayElement(b []byte, d tree.Datum) ([]byte, error) {
 			return nil, err
 		}
 		return encoding.EncodeUntaggedBytesValue(b, encoded), nil
+	case *tree.DRange:
+		encoded, err := encodeRange(t, nil /* appendTo */)
+		if err != nil {
+			return nil, err
+		}
+		return encoding.EncodeUntaggedBytesValue(b, encoded), nil
+	case *tree.DMultirange:
+		encoded, err := encodeMultirange(t, nil /* appendTo */)
+		if err != nil {
+			return nil, err
+		}
+		return encoding.EncodeUntaggedBytesValue(b, encoded), nil
 	default:
 		return nil, errors.Errorf("don't know how to encode %s (%T)", d, d)
 	}
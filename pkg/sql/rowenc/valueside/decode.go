@@ -209,6 +209,26 @@ func DecodeUntaggedDatum(
 			return nil, b, err
 		}
 		return tree.NewDTSVector(v), b, nil
+	case types.RangeFamily:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		r, _, err := decodeRange(a, t, data)
+		if err != nil {
+			return nil, b, err
+		}
+		return r, b, nil
+	case types.MultirangeFamily:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		m, _, err := decodeMultirange(a, t, data)
+		if err != nil {
+			return nil, b, err
+		}
+		return m, b, nil
 	case types.OidFamily:
 		// TODO: This possibly should decode to uint32 (with corresponding changes
 		// to encoding) to ensure that the value fits in a DOid without any loss of
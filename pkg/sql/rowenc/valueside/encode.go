@@ -93,6 +93,18 @@ func Encode(appendTo []byte, colID ColumnIDDelta, val tree.Datum, scratch []byte
 			return nil, err
 		}
 		return encoding.EncodeTSVectorValue(appendTo, uint32(colID), encoded), nil
+	case *tree.DRange:
+		encoded, err := encodeRange(t, scratch)
+		if err != nil {
+			return nil, err
+		}
+		return encoding.EncodeRangeValue(appendTo, uint32(colID), encoded), nil
+	case *tree.DMultirange:
+		encoded, err := encodeMultirange(t, scratch)
+		if err != nil {
+			return nil, err
+		}
+		return encoding.EncodeMultirangeValue(appendTo, uint32(colID), encoded), nil
 	case *tree.DArray:
 		a, err := encodeArray(t, scratch)
 		if err != nil {
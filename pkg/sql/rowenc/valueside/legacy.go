@@ -157,6 +157,24 @@ func MarshalLegacy(colType *types.T, val tree.Datum) (roachpb.Value, error) {
 			r.SetBytes(data)
 			return r, nil
 		}
+	case types.RangeFamily:
+		if v, ok := val.(*tree.DRange); ok {
+			data, err := encodeRange(v, nil)
+			if err != nil {
+				return r, err
+			}
+			r.SetBytes(data)
+			return r, nil
+		}
+	case types.MultirangeFamily:
+		if v, ok := val.(*tree.DMultirange); ok {
+			data, err := encodeMultirange(v, nil)
+			if err != nil {
+				return r, err
+			}
+			r.SetBytes(data)
+			return r, nil
+		}
 	case types.ArrayFamily:
 		if v, ok := val.(*tree.DArray); ok {
 			if err := checkElementType(v.ParamTyp, colType.ArrayContents()); err != nil {
@@ -399,6 +417,26 @@ func UnmarshalLegacy(a *tree.DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return tree.NewDTSVector(vec), nil
+	case types.RangeFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		datum, _, err := decodeRange(a, typ, v)
+		if err != nil {
+			return nil, err
+		}
+		return datum, nil
+	case types.MultirangeFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		datum, _, err := decodeMultirange(a, typ, v)
+		if err != nil {
+			return nil, err
+		}
+		return datum, nil
 	case types.EnumFamily:
 		v, err := value.GetBytes()
 		if err != nil {
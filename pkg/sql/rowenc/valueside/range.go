@@ -0,0 +1,102 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package valueside
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// encodeRange produces the value encoding for a range, without a value tag or
+// length prefix. The encoding is the range's flags (see tree.DRange.Flags),
+// followed by the value encodings of its finite bounds.
+func encodeRange(r *tree.DRange, appendTo []byte) ([]byte, error) {
+	appendTo = append(appendTo, r.Flags())
+	var err error
+	for _, bound := range []tree.Datum{r.Lower, r.Upper} {
+		if bound == tree.DNull {
+			continue
+		}
+		appendTo, err = Encode(appendTo, NoColumnID, bound, nil /* scratch */)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return appendTo, nil
+}
+
+// decodeRange decodes a range encoded by encodeRange, returning the remainder
+// of b.
+func decodeRange(a *tree.DatumAlloc, t *types.T, b []byte) (*tree.DRange, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, errors.AssertionFailedf("invalid range encoding (empty)")
+	}
+	flags := b[0]
+	b = b[1:]
+	if flags&tree.RangeFlagEmpty != 0 {
+		return tree.MakeEmptyDRange(t), b, nil
+	}
+	result := &tree.DRange{
+		Typ:      t,
+		Lower:    tree.DNull,
+		Upper:    tree.DNull,
+		LowerInc: flags&tree.RangeFlagLowerInc != 0,
+		UpperInc: flags&tree.RangeFlagUpperInc != 0,
+	}
+	var err error
+	if flags&tree.RangeFlagLowerInf == 0 {
+		if result.Lower, b, err = Decode(a, t.RangeSubtype(), b); err != nil {
+			return nil, nil, err
+		}
+	}
+	if flags&tree.RangeFlagUpperInf == 0 {
+		if result.Upper, b, err = Decode(a, t.RangeSubtype(), b); err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, b, nil
+}
+
+// encodeMultirange produces the value encoding for a multirange, without a
+// value tag or length prefix. The encoding is the number of ranges, followed
+// by the encodings of the ranges.
+func encodeMultirange(m *tree.DMultirange, appendTo []byte) ([]byte, error) {
+	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(m.Ranges)))
+	var err error
+	for _, r := range m.Ranges {
+		appendTo, err = encodeRange(r, appendTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return appendTo, nil
+}
+
+// decodeMultirange decodes a multirange encoded by encodeMultirange, returning
+// the remainder of b.
+func decodeMultirange(
+	a *tree.DatumAlloc, t *types.T, b []byte,
+) (*tree.DMultirange, []byte, error) {
+	b, _, n, err := encoding.DecodeNonsortingUvarint(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &tree.DMultirange{Typ: t, Ranges: make([]*tree.DRange, n)}
+	for i := range result.Ranges {
+		result.Ranges[i], b, err = decodeRange(a, t.MultirangeContents(), b)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, b, nil
+}
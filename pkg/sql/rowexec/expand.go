@@ -0,0 +1,209 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rowexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// expandProcessor emits one row per grouping set for every input row. See
+// execinfrapb.ExpandSpec for the format of its output.
+type expandProcessor struct {
+	execinfra.ProcessorBase
+
+	input execinfra.RowSource
+
+	groupingCols []uint32
+	// inSet[i][j] is true if the j-th grouping column is part of the i-th
+	// grouping set.
+	inSet [][]bool
+	// emptySet[i] is true if the i-th grouping set is empty.
+	emptySet []bool
+	// hasEmptySet is true if one of the grouping sets is empty, in which case
+	// the output contains the input row column.
+	hasEmptySet bool
+	// groupingIDs contains the encoded ordinal of each grouping set.
+	groupingIDs []rowenc.EncDatum
+
+	// inputRow is the current input row. It is nil while the rows for the
+	// empty grouping sets are emitted when the input is empty.
+	inputRow rowenc.EncDatumRow
+	// nextSet is the ordinal of the next grouping set to emit a row for.
+	nextSet int
+	// sawInputRow is true once a row has been read from the input.
+	sawInputRow bool
+	// inputDone is true once the input has been exhausted.
+	inputDone bool
+
+	outputRow rowenc.EncDatumRow
+}
+
+var _ execinfra.Processor = &expandProcessor{}
+var _ execinfra.RowSource = &expandProcessor{}
+
+const expandProcName = "expand"
+
+func newExpandProcessor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec *execinfrapb.ExpandSpec,
+	input execinfra.RowSource,
+	post *execinfrapb.PostProcessSpec,
+	output execinfra.RowReceiver,
+) (execinfra.RowSourcedProcessor, error) {
+	e := &expandProcessor{
+		input:        input,
+		groupingCols: spec.GroupingCols,
+		inSet:        make([][]bool, len(spec.GroupingSets)),
+		emptySet:     make([]bool, len(spec.GroupingSets)),
+		groupingIDs:  make([]rowenc.EncDatum, len(spec.GroupingSets)),
+		nextSet:      len(spec.GroupingSets),
+	}
+	for i := range spec.GroupingSets {
+		e.inSet[i] = make([]bool, len(spec.GroupingCols))
+		for _, j := range spec.GroupingSets[i].Cols {
+			e.inSet[i][j] = true
+		}
+		e.emptySet[i] = len(spec.GroupingSets[i].Cols) == 0
+		e.hasEmptySet = e.hasEmptySet || e.emptySet[i]
+		e.groupingIDs[i] = rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(i)))
+	}
+
+	inputTypes := input.OutputTypes()
+	colTypes := make([]*types.T, 0, len(inputTypes)+len(spec.GroupingCols)+2)
+	colTypes = append(colTypes, inputTypes...)
+	for _, col := range spec.GroupingCols {
+		colTypes = append(colTypes, inputTypes[col])
+	}
+	colTypes = append(colTypes, types.Int)
+	if e.hasEmptySet {
+		colTypes = append(colTypes, types.Bool)
+	}
+	e.outputRow = make(rowenc.EncDatumRow, len(colTypes))
+
+	if err := e.Init(
+		ctx,
+		e,
+		post,
+		colTypes,
+		flowCtx,
+		processorID,
+		output,
+		nil, /* memMonitor */
+		execinfra.ProcStateOpts{
+			InputsToDrain: []execinfra.RowSource{e.input},
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	if execstats.ShouldCollectStats(ctx, flowCtx.CollectStats) {
+		e.input = newInputStatCollector(e.input)
+		e.ExecStatsForTrace = e.execStatsForTrace
+	}
+
+	return e, nil
+}
+
+// Start is part of the RowSource interface.
+func (e *expandProcessor) Start(ctx context.Context) {
+	ctx = e.StartInternal(ctx, expandProcName)
+	e.input.Start(ctx)
+}
+
+// Next is part of the RowSource interface.
+func (e *expandProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
+	for e.State == execinfra.StateRunning {
+		if e.nextSet == len(e.inSet) {
+			if e.inputDone {
+				e.MoveToDraining(nil /* err */)
+				break
+			}
+			row, meta := e.input.Next()
+			if meta != nil {
+				if meta.Err != nil {
+					e.MoveToDraining(nil /* err */)
+				}
+				return nil, meta
+			}
+			if row == nil {
+				e.inputDone = true
+				if !e.hasEmptySet || e.sawInputRow {
+					e.MoveToDraining(nil /* err */)
+					break
+				}
+				// The input is empty, so emit a row for each empty grouping set.
+			} else {
+				e.sawInputRow = true
+			}
+			e.inputRow = row
+			e.nextSet = 0
+		}
+
+		set := e.nextSet
+		e.nextSet++
+		if e.inputRow == nil && !e.emptySet[set] {
+			continue
+		}
+		if outRow := e.ProcessRowHelper(e.buildRow(set)); outRow != nil {
+			return outRow, nil
+		}
+	}
+	return nil, e.DrainHelper()
+}
+
+// buildRow returns the output row for the current input row and the given
+// grouping set.
+func (e *expandProcessor) buildRow(set int) rowenc.EncDatumRow {
+	null := rowenc.EncDatum{Datum: tree.DNull}
+	numInputCols := len(e.input.OutputTypes())
+	if e.inputRow != nil {
+		copy(e.outputRow, e.inputRow)
+	} else {
+		for i := 0; i < numInputCols; i++ {
+			e.outputRow[i] = null
+		}
+	}
+	for j, col := range e.groupingCols {
+		if e.inputRow != nil && e.inSet[set][j] {
+			e.outputRow[numInputCols+j] = e.inputRow[col]
+		} else {
+			e.outputRow[numInputCols+j] = null
+		}
+	}
+	e.outputRow[numInputCols+len(e.groupingCols)] = e.groupingIDs[set]
+	if e.hasEmptySet {
+		e.outputRow[len(e.outputRow)-1] = rowenc.DatumToEncDatum(
+			types.Bool, tree.MakeDBool(tree.DBool(e.inputRow != nil)),
+		)
+	}
+	return e.outputRow
+}
+
+// execStatsForTrace implements ProcessorBase.ExecStatsForTrace.
+func (e *expandProcessor) execStatsForTrace() *execinfrapb.ComponentStats {
+	is, ok := getInputStats(e.input)
+	if !ok {
+		return nil
+	}
+	return &execinfrapb.ComponentStats{
+		Inputs: []execinfrapb.InputStats{is},
+		Output: e.OutputHelper.Stats(),
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rowexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/distsqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestExpand(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	v := [15]rowenc.EncDatum{}
+	for i := range v {
+		v[i] = rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(i)))
+	}
+	null := rowenc.EncDatum{Datum: tree.DNull}
+	vTrue := rowenc.DatumToEncDatum(types.Bool, tree.DBoolTrue)
+	vFalse := rowenc.DatumToEncDatum(types.Bool, tree.DBoolFalse)
+
+	// ROLLUP (@1, @2).
+	rollup := execinfrapb.ExpandSpec{
+		GroupingCols: []uint32{0, 1},
+		GroupingSets: []execinfrapb.ExpandSpec_GroupingSet{
+			{Cols: []uint32{0, 1}},
+			{Cols: []uint32{0}},
+			{},
+		},
+	}
+	// GROUPING SETS ((@2), (@1)).
+	sets := execinfrapb.ExpandSpec{
+		GroupingCols: []uint32{1, 0},
+		GroupingSets: []execinfrapb.ExpandSpec_GroupingSet{
+			{Cols: []uint32{0}},
+			{Cols: []uint32{1}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		spec     execinfrapb.ExpandSpec
+		input    rowenc.EncDatumRows
+		expected rowenc.EncDatumRows
+	}{
+		{
+			name: "rollup",
+			spec: rollup,
+			input: rowenc.EncDatumRows{
+				{v[1], v[2]},
+				{v[3], null},
+			},
+			expected: rowenc.EncDatumRows{
+				{v[1], v[2], v[1], v[2], v[0], vTrue},
+				{v[1], v[2], v[1], null, v[1], vTrue},
+				{v[1], v[2], null, null, v[2], vTrue},
+				{v[3], null, v[3], null, v[0], vTrue},
+				{v[3], null, v[3], null, v[1], vTrue},
+				{v[3], null, null, null, v[2], vTrue},
+			},
+		},
+		{
+			name:  "rollup-empty-input",
+			spec:  rollup,
+			input: rowenc.EncDatumRows{},
+			expected: rowenc.EncDatumRows{
+				{null, null, null, null, v[2], vFalse},
+			},
+		},
+		{
+			name: "grouping-sets",
+			spec: sets,
+			input: rowenc.EncDatumRows{
+				{v[1], v[2]},
+			},
+			expected: rowenc.EncDatumRows{
+				{v[1], v[2], v[2], null, v[0]},
+				{v[1], v[2], null, v[1], v[1]},
+			},
+		},
+		{
+			name:     "grouping-sets-empty-input",
+			spec:     sets,
+			input:    rowenc.EncDatumRows{},
+			expected: nil,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := c.spec
+			in := distsqlutils.NewRowBuffer(types.TwoIntCols, c.input, distsqlutils.RowBufferArgs{})
+			out := &distsqlutils.RowBuffer{}
+
+			st := cluster.MakeTestingClusterSettings()
+			evalCtx := eval.MakeTestingEvalContext(st)
+			defer evalCtx.Stop(context.Background())
+			flowCtx := execinfra.FlowCtx{
+				Cfg:     &execinfra.ServerConfig{Settings: st},
+				EvalCtx: &evalCtx,
+				Mon:     evalCtx.TestingMon,
+			}
+
+			e, err := newExpandProcessor(
+				context.Background(), &flowCtx, 0 /* processorID */, &spec, in, &execinfrapb.PostProcessSpec{}, out,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e.Run(context.Background())
+			if !out.ProducerClosed() {
+				t.Fatalf("output RowReceiver not closed")
+			}
+			var res rowenc.EncDatumRows
+			for {
+				row := out.NextNoMeta(t).Copy()
+				if row == nil {
+					break
+				}
+				res = append(res, row)
+			}
+
+			typs := e.OutputTypes()
+			if result, expected := res.String(typs), c.expected.String(typs); result != expected {
+				t.Errorf("invalid results: %s, expected %s", result, expected)
+			}
+		})
+	}
+}
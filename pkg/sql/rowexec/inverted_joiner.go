@@ -297,6 +297,7 @@ func newInvertedJoiner(
 			LockStrength:               spec.LockingStrength,
 			LockWaitPolicy:             spec.LockingWaitPolicy,
 			LockTimeout:                flowCtx.EvalCtx.SessionData().LockTimeout,
+			DurableLocking:             flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 			Alloc:                      &ij.alloc,
 			MemMonitor:                 flowCtx.Mon,
 			Spec:                       &spec.FetchSpec,
@@ -565,6 +565,7 @@ func newJoinReader(
 			LockStrength:               spec.LockingStrength,
 			LockWaitPolicy:             spec.LockingWaitPolicy,
 			LockTimeout:                flowCtx.EvalCtx.SessionData().LockTimeout,
+			DurableLocking:             flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 			Alloc:                      &jr.alloc,
 			MemMonitor:                 flowCtx.Mon,
 			Spec:                       &spec.FetchSpec,
@@ -155,6 +155,7 @@ func newTableReader(
 			LockStrength:               spec.LockingStrength,
 			LockWaitPolicy:             spec.LockingWaitPolicy,
 			LockTimeout:                flowCtx.EvalCtx.SessionData().LockTimeout,
+			DurableLocking:             flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 			Alloc:                      &tr.alloc,
 			MemMonitor:                 flowCtx.Mon,
 			Spec:                       &spec.FetchSpec,
@@ -468,6 +468,7 @@ func (z *zigzagJoiner) setupInfo(
 			LockStrength:               spec.LockingStrength,
 			LockWaitPolicy:             spec.LockingWaitPolicy,
 			LockTimeout:                flowCtx.EvalCtx.SessionData().LockTimeout,
+			DurableLocking:             flowCtx.UseDurableLocking(ctx, spec.LockingStrength),
 			Alloc:                      &info.alloc,
 			MemMonitor:                 flowCtx.Mon,
 			Spec:                       &spec.FetchSpec,
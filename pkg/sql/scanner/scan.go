@@ -415,6 +415,12 @@ func (s *Scanner) Scan(lval ScanSymType) {
 			s.pos++
 			lval.SetID(lexbase.FETCHVAL)
 			return
+		case '|': // -|-
+			if s.peekN(1) == '-' {
+				s.pos += 2
+				lval.SetID(lexbase.RANGE_ADJACENT)
+				return
+			}
 		}
 		return
 
@@ -451,6 +451,11 @@ func (sc *SchemaChanger) maybeMakeAddTablePublic(
 			return nil
 		}
 		mut.State = descpb.DescriptorState_PUBLIC
+		// A materialized view which was populated on creation holds the result
+		// of the view query as of its creation time.
+		if mut.IncrementalRefresh != nil && !mut.IsRefreshViewRequired() {
+			mut.IncrementalRefresh.LastRefresh = mut.GetCreateAsOfTime()
+		}
 		return txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, mut, txn.KV())
 	})
 }
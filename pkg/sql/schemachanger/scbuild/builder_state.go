@@ -821,6 +821,10 @@ func (b *builderState) ResolveUserDefinedTypeType(
 		// Implicit record types are not directly modifiable.
 		panic(pgerror.Newf(pgcode.DependentObjectsStillExist,
 			"cannot modify table record type %q", typ.GetName()))
+	case descpb.TypeDescriptor_DOMAIN:
+		panic(scerrors.NotImplementedErrorf(nil /* n */, "modifying a domain"))
+	case descpb.TypeDescriptor_RANGE, descpb.TypeDescriptor_MULTIRANGE:
+		panic(scerrors.NotImplementedErrorf(nil /* n */, "modifying a range type"))
 	default:
 		panic(errors.AssertionFailedf("unknown type kind %s", typ.GetKind()))
 	}
@@ -859,6 +863,9 @@ func (b *builderState) resolveRelation(
 	if rel.IsTemporary() {
 		panic(scerrors.NotImplementedErrorf(nil /* n */, "dropping a temporary table"))
 	}
+	if len(rel.GetInheritsFrom()) > 0 || len(rel.GetInheritedBy()) > 0 {
+		panic(scerrors.NotImplementedErrorf(nil /* n */, "modifying a table with inheritance"))
+	}
 	// If we own the schema then we can manipulate the underlying relation.
 	b.ensureDescriptor(rel.GetID())
 	c := b.descCache[rel.GetID()]
@@ -1109,7 +1116,11 @@ func (b *builderState) ResolveUDF(
 	}
 
 	fnID := funcdesc.UserDefinedFunctionOIDToID(ol.Oid)
-	b.mustOwn(fnID)
+	if p.RequiredPrivilege != 0 && !p.RequireOwnership {
+		b.checkPrivilege(fnID, p.RequiredPrivilege)
+	} else {
+		b.mustOwn(fnID)
+	}
 	b.ensureDescriptor(fnID)
 	return b.descCache[fnID].ers
 }
@@ -1311,8 +1322,12 @@ func (b *builderState) WrapFunctionBody(
 	lang catpb.Function_Language,
 	refProvider scbuildstmt.ReferenceProvider,
 ) *scpb.FunctionBody {
-	bodyStr = b.replaceSeqNamesWithIDs(bodyStr)
-	bodyStr = b.serializeUserDefinedTypes(bodyStr)
+	// The body of a PL/pgSQL function is not a list of SQL statements, so
+	// references within it are kept by name.
+	if lang != catpb.Function_PLPGSQL {
+		bodyStr = b.replaceSeqNamesWithIDs(bodyStr)
+		bodyStr = b.serializeUserDefinedTypes(bodyStr)
+	}
 	fnBody := &scpb.FunctionBody{
 		FunctionID: fnID,
 		Body:       bodyStr,
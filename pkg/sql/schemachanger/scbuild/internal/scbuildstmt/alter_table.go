@@ -134,6 +134,21 @@ func alterTableAddConstraintChecks(
 	if !isV231Active(t, mode, activeVersion) {
 		return false
 	}
+
+	// Deferrable constraints are only supported by the legacy schema changer.
+	switch d := t.ConstraintDef.(type) {
+	case *tree.UniqueConstraintTableDef:
+		if d.Deferrable.IsDeferrable() {
+			return false
+		}
+	case *tree.ForeignKeyConstraintTableDef:
+		if d.Deferrable.IsDeferrable() {
+			return false
+		}
+	case *tree.ExclusionConstraintTableDef:
+		// Exclusion constraints are only supported by the legacy schema changer.
+		return false
+	}
 	return true
 }
 
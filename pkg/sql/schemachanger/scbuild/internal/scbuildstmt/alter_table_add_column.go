@@ -146,7 +146,7 @@ func alterTableAddColumn(
 	_, _, tableNamespace := scpb.FindNamespace(b.QueryByID(tbl.TableID))
 	spec.colType.TypeT = b.ResolveTypeRef(d.Type)
 	if spec.colType.TypeT.Type.UserDefined() {
-		typeID := typedesc.UserDefinedTypeOIDToID(spec.colType.TypeT.Type.Oid())
+		typeID := typedesc.UserDefinedTypeOIDToID(spec.colType.TypeT.Type.UserDefinedOID())
 		maybeFailOnCrossDBTypeReference(b, typeID, tableNamespace.DatabaseID)
 	}
 	// Block unique indexes on unsupported types.
@@ -25,6 +25,13 @@ import (
 func alterTableDropConstraint(
 	b BuildCtx, tn *tree.TableName, tbl *scpb.Table, t *tree.AlterTableDropConstraint,
 ) {
+	// Dropping EXCLUDE constraint: error out as not implemented. Exclusion
+	// constraints are backed by an index without a constraint ID, so they are
+	// looked up by the name of their index.
+	droppingExclusionConstraintNotImplemented(b.ResolveIndex(tbl.TableID, t.Constraint, ResolveParams{
+		IsExistenceOptional: true,
+		RequiredPrivilege:   privilege.CREATE,
+	}), t)
 	constraintElems := b.ResolveConstraint(tbl.TableID, t.Constraint, ResolveParams{
 		IsExistenceOptional: t.IfExists,
 		RequiredPrivilege:   privilege.CREATE,
@@ -101,3 +108,14 @@ func droppingUniqueConstraintNotImplemented(
 		}
 	}
 }
+
+func droppingExclusionConstraintNotImplemented(
+	indexElems ElementResultSet, t *tree.AlterTableDropConstraint,
+) {
+	_, _, sie := scpb.FindSecondaryIndex(indexElems)
+	if sie != nil && len(sie.ExclusionOperators) > 0 {
+		panic(unimplemented.NewWithIssueDetailf(46657, "drop-constraint-exclude",
+			"cannot drop exclusion constraint %q using ALTER TABLE DROP CONSTRAINT, use DROP INDEX instead",
+			tree.ErrNameString(string(t.Constraint))))
+	}
+}
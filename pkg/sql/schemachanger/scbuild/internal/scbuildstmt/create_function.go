@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
 
@@ -58,11 +59,26 @@ func CreateFunction(b BuildCtx, n *tree.CreateFunction) {
 		))
 	}
 
+	if n.IsProcedure {
+		// The result of a procedure is a record made up of its OUT and INOUT
+		// parameters.
+		procReturnType, err := funcinfo.ProcedureReturnType(
+			n.Params, func(ref tree.ResolvableTypeReference) (*types.T, error) {
+				return b.ResolveTypeRef(ref).Type, nil
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		n.ReturnType.Type = procReturnType
+	}
+
 	fnID := b.GenerateUniqueDescID()
 	fn := scpb.Function{
-		FunctionID: fnID,
-		ReturnSet:  n.ReturnType.IsSet,
-		ReturnType: b.ResolveTypeRef(n.ReturnType.Type),
+		FunctionID:  fnID,
+		ReturnSet:   n.ReturnType.IsSet,
+		ReturnType:  b.ResolveTypeRef(n.ReturnType.Type),
+		IsProcedure: n.IsProcedure,
 	}
 	fn.Params = make([]scpb.Function_Parameter, len(n.Params))
 	for i, param := range n.Params {
@@ -0,0 +1,132 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuildstmt
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// CreateTrigger implements CREATE TRIGGER.
+func CreateTrigger(b BuildCtx, n *tree.CreateTrigger) {
+	if n.Replace {
+		panic(scerrors.NotImplementedErrorf(n, "CREATE OR REPLACE TRIGGER"))
+	}
+	tn := n.Table.ToTableName()
+	tableElts := b.ResolveTable(n.Table, ResolveParams{
+		RequiredPrivilege: privilege.CREATE,
+	})
+	_, _, tbl := scpb.FindTable(tableElts)
+	tn.ObjectNamePrefix = b.NamePrefix(tbl)
+	b.SetUnresolvedNameAnnotation(n.Table, &tn)
+	if findTriggerByName(b, tbl.TableID, n.Name) != nil {
+		_, _, ns := scpb.FindNamespace(tableElts)
+		panic(pgerror.Newf(pgcode.DuplicateObject,
+			"trigger %q for relation %q already exists", n.Name, ns.Name))
+	}
+
+	// Resolve the trigger function, which must not take any arguments.
+	fnElts := b.ResolveUDF(&tree.FuncObj{FuncName: n.FuncName, Params: tree.FuncParams{}}, ResolveParams{
+		RequiredPrivilege: privilege.EXECUTE,
+	})
+	_, _, fn := scpb.FindFunction(fnElts)
+	if fn.ReturnType.Type.Family() != types.TriggerFamily {
+		panic(pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type trigger", n.FuncName.Object()))
+	}
+
+	trigger := &scpb.Trigger{
+		TableID:      tbl.TableID,
+		TriggerID:    nextTriggerID(b, tbl.TableID),
+		Name:         string(n.Name),
+		ForEachRow:   n.ForEachRow,
+		FunctionID:   fn.FunctionID,
+		FunctionArgs: n.FuncArgs,
+	}
+	if n.ActionTime == tree.TriggerActionTimeAfter {
+		trigger.ActionTime = scpb.Trigger_AFTER
+	}
+	var hasInsert, hasDelete bool
+	for _, ev := range n.Events {
+		event := scpb.Trigger_Event{}
+		switch ev.EventType {
+		case tree.TriggerEventInsert:
+			event.Type = scpb.Trigger_Event_INSERT
+			hasInsert = true
+		case tree.TriggerEventUpdate:
+			event.Type = scpb.Trigger_Event_UPDATE
+		case tree.TriggerEventDelete:
+			event.Type = scpb.Trigger_Event_DELETE
+			hasDelete = true
+		}
+		for _, colName := range ev.Columns {
+			colID := getColumnIDFromColumnName(b, tbl.TableID, colName)
+			if colID == 0 {
+				panic(pgerror.Newf(pgcode.UndefinedColumn,
+					"column %q does not exist", colName))
+			}
+			event.ColumnIDs = append(event.ColumnIDs, colID)
+		}
+		trigger.Events = append(trigger.Events, event)
+	}
+
+	if n.When != nil {
+		whenExpr, err := schemaexpr.ValidateTriggerWhenExpr(
+			b, n.When,
+			n.ForEachRow && !hasDelete, /* allowNew */
+			n.ForEachRow && !hasInsert, /* allowOld */
+			b.SemaCtx(), b.ClusterSettings().Version.ActiveVersion(b),
+			func(columnName tree.Name) (exists bool, accessible bool, id catid.ColumnID, typ *types.T) {
+				return columnLookupFn(b, tbl.TableID, columnName)
+			},
+		)
+		if err != nil {
+			panic(err)
+		}
+		trigger.WhenExpr = whenExpr
+	}
+
+	b.Add(trigger)
+	b.LogEventForExistingTarget(trigger)
+}
+
+// findTriggerByName returns the trigger with the given name on the given
+// table, or nil if there is none.
+func findTriggerByName(b BuildCtx, tableID catid.DescID, name tree.Name) (ret *scpb.Trigger) {
+	scpb.ForEachTrigger(b.QueryByID(tableID), func(
+		current scpb.Status, target scpb.TargetStatus, e *scpb.Trigger,
+	) {
+		if target == scpb.ToPublic && tree.Name(e.Name) == name {
+			ret = e
+		}
+	})
+	return ret
+}
+
+// nextTriggerID returns an unused trigger ID for the given table.
+func nextTriggerID(b BuildCtx, tableID catid.DescID) catid.TriggerID {
+	next := catid.TriggerID(1)
+	scpb.ForEachTrigger(b.QueryByID(tableID), func(
+		_ scpb.Status, _ scpb.TargetStatus, e *scpb.Trigger,
+	) {
+		if e.TriggerID >= next {
+			next = e.TriggerID + 1
+		}
+	})
+	return next
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
 )
 
 func DropFunction(b BuildCtx, n *tree.DropFunction) {
@@ -35,6 +36,19 @@ func DropFunction(b BuildCtx, n *tree.DropFunction) {
 		if fn == nil {
 			continue
 		}
+		if fn.IsProcedure != n.IsProcedure {
+			_, _, fnName := scpb.FindFunctionName(elts)
+			if n.IsProcedure {
+				panic(errors.WithHint(
+					pgerror.Newf(pgcode.WrongObjectType, "%q is not a procedure", fnName.Name),
+					"Use DROP FUNCTION to drop functions.",
+				))
+			}
+			panic(errors.WithHint(
+				pgerror.Newf(pgcode.WrongObjectType, "%q is not a function", fnName.Name),
+				"Use DROP PROCEDURE to drop procedures.",
+			))
+		}
 		f.FuncName.ObjectNamePrefix = b.NamePrefix(fn)
 		if dropRestrictDescriptor(b, fn.FunctionID) {
 			toCheckBackRefs = append(toCheckBackRefs, fn.FunctionID)
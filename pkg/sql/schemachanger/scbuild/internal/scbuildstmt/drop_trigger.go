@@ -0,0 +1,54 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuildstmt
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// DropTrigger implements DROP TRIGGER.
+func DropTrigger(b BuildCtx, n *tree.DropTrigger) {
+	if n.DropBehavior == tree.DropCascade {
+		panic(scerrors.NotImplementedErrorf(n, "cascade dropping triggers"))
+	}
+	tn := n.Table.ToTableName()
+	tableElts := b.ResolveTable(n.Table, ResolveParams{
+		IsExistenceOptional: n.IfExists,
+		RequiredPrivilege:   privilege.CREATE,
+	})
+	_, _, tbl := scpb.FindTable(tableElts)
+	if tbl == nil {
+		b.MarkNameAsNonExistent(&tn)
+		return
+	}
+	tn.ObjectNamePrefix = b.NamePrefix(tbl)
+	b.SetUnresolvedNameAnnotation(n.Table, &tn)
+	trigger := findTriggerByName(b, tbl.TableID, n.Trigger)
+	if trigger == nil {
+		_, _, ns := scpb.FindNamespace(tableElts)
+		if !n.IfExists {
+			panic(pgerror.Newf(pgcode.UndefinedObject,
+				"trigger %q for table %q does not exist", n.Trigger, ns.Name))
+		}
+		b.EvalCtx().ClientNoticeSender.BufferClientNotice(b, pgnotice.Newf(
+			"trigger %q for relation %q does not exist, skipping", n.Trigger, ns.Name))
+		return
+	}
+	b.Drop(trigger)
+	b.LogEventForExistingTarget(trigger)
+	b.IncrementSchemaChangeDropCounter("trigger")
+}
@@ -62,6 +62,8 @@ var supportedStatements = map[reflect.Type]supportedStatement{
 	reflect.TypeOf((*tree.DropIndex)(nil)):           {fn: DropIndex, on: true, checks: isV231Active},
 	reflect.TypeOf((*tree.DropFunction)(nil)):        {fn: DropFunction, on: true, checks: isV231Active},
 	reflect.TypeOf((*tree.CreateFunction)(nil)):      {fn: CreateFunction, on: true, checks: isV231Active},
+	reflect.TypeOf((*tree.CreateTrigger)(nil)):       {fn: CreateTrigger, on: true, checks: isV231Active},
+	reflect.TypeOf((*tree.DropTrigger)(nil)):         {fn: DropTrigger, on: true, checks: isV231Active},
 }
 
 func init() {
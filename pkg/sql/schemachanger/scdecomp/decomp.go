@@ -213,6 +213,23 @@ func (w *walkCtx) walkType(typ catalog.TypeDescriptor) {
 				Name:            comp.GetElementLabel(i),
 			})
 		}
+	} else if domain := typ.AsDomainTypeDescriptor(); domain != nil {
+		// Domains are only ever modified by the legacy schema changer, so they
+		// are decomposed like alias types, which are defined solely by the type
+		// they refer to.
+		typeT := newTypeT(domain.DomainBaseType())
+		w.ev(descriptorStatus(typ), &scpb.AliasType{
+			TypeID: typ.GetID(),
+			TypeT:  *typeT,
+		})
+	} else if r := typ.AsRangeTypeDescriptor(); r != nil {
+		// Range and multirange types are also only ever modified by the legacy
+		// schema changer, and are decomposed like alias types of their subtype.
+		typeT := newTypeT(r.RangeSubtype())
+		w.ev(descriptorStatus(typ), &scpb.AliasType{
+			TypeID: typ.GetID(),
+			TypeT:  *typeT,
+		})
 	} else {
 		panic(errors.AssertionFailedf("unsupported type kind %q", typ.GetKind()))
 	}
@@ -339,6 +356,9 @@ func (w *walkCtx) walkRelation(tbl catalog.TableDescriptor) {
 	for _, c := range tbl.OutboundForeignKeys() {
 		w.walkForeignKeyConstraint(tbl, c)
 	}
+	for i := range tbl.GetTriggers() {
+		w.walkTrigger(tbl, &tbl.GetTriggers()[i])
+	}
 
 	_ = tbl.ForeachDependedOnBy(func(dep *descpb.TableDescriptor_Reference) error {
 		w.backRefs.Add(dep.ID)
@@ -519,6 +539,9 @@ func (w *walkCtx) walkIndex(tbl catalog.TableDescriptor, idx catalog.Index) {
 		if geoConfig := idx.GetGeoConfig(); !geoConfig.IsEmpty() {
 			index.GeoConfig = protoutil.Clone(&geoConfig).(*geoindex.Config)
 		}
+		if idx.IsExclusion() {
+			index.ExclusionOperators = cpy.ExclusionOperators
+		}
 		for i, c := range cpy.KeyColumnIDs {
 			invertedKind := catpb.InvertedIndexColumnKind_DEFAULT
 			if index.IsInverted && c == idx.InvertedColumnID() {
@@ -732,13 +755,34 @@ func (w *walkCtx) walkForeignKeyConstraint(
 	}
 }
 
+func (w *walkCtx) walkTrigger(tbl catalog.TableDescriptor, t *descpb.TriggerDescriptor) {
+	trigger := &scpb.Trigger{
+		TableID:      tbl.GetID(),
+		TriggerID:    t.ID,
+		Name:         t.Name,
+		ActionTime:   scpb.Trigger_ActionTime(t.ActionTime),
+		ForEachRow:   t.ForEachRow,
+		WhenExpr:     t.WhenExpr,
+		FunctionID:   t.FuncID,
+		FunctionArgs: t.FuncArgs,
+	}
+	for _, ev := range t.Events {
+		trigger.Events = append(trigger.Events, scpb.Trigger_Event{
+			Type:      scpb.Trigger_Event_Type(ev.Type),
+			ColumnIDs: ev.ColumnIDs,
+		})
+	}
+	w.ev(scpb.Status_PUBLIC, trigger)
+}
+
 func (w *walkCtx) walkFunction(fnDesc catalog.FunctionDescriptor) {
 	typeT := newTypeT(fnDesc.GetReturnType().Type)
 	fn := &scpb.Function{
-		FunctionID: fnDesc.GetID(),
-		ReturnSet:  fnDesc.GetReturnType().ReturnSet,
-		ReturnType: *typeT,
-		Params:     make([]scpb.Function_Parameter, len(fnDesc.GetParams())),
+		FunctionID:  fnDesc.GetID(),
+		ReturnSet:   fnDesc.GetReturnType().ReturnSet,
+		ReturnType:  *typeT,
+		Params:      make([]scpb.Function_Parameter, len(fnDesc.GetParams())),
+		IsProcedure: fnDesc.IsProcedure(),
 	}
 	for i, param := range fnDesc.GetParams() {
 		typeT := newTypeT(param.Type)
@@ -98,6 +98,9 @@ func addNewIndexMutation(
 	if opIndex.GeoConfig != nil {
 		idx.GeoConfig = *opIndex.GeoConfig
 	}
+	if len(opIndex.ExclusionOperators) > 0 {
+		idx.ExclusionOperators = append([]string(nil), opIndex.ExclusionOperators...)
+	}
 	return enqueueIndexMutation(tbl, idx, state, descpb.DescriptorMutation_ADD)
 }
 
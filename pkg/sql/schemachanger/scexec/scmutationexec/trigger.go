@@ -0,0 +1,80 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scmutationexec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
+)
+
+func (i *immediateVisitor) AddTrigger(ctx context.Context, op scop.AddTrigger) error {
+	tbl, err := i.checkOutTable(ctx, op.Trigger.TableID)
+	if err != nil || tbl.Dropped() {
+		return err
+	}
+	if op.Trigger.TriggerID >= tbl.NextTriggerID {
+		tbl.NextTriggerID = op.Trigger.TriggerID + 1
+	}
+	trigger := descpb.TriggerDescriptor{
+		ID:         op.Trigger.TriggerID,
+		Name:       op.Trigger.Name,
+		ActionTime: descpb.TriggerDescriptor_ActionTime(op.Trigger.ActionTime),
+		ForEachRow: op.Trigger.ForEachRow,
+		WhenExpr:   op.Trigger.WhenExpr,
+		FuncID:     op.Trigger.FunctionID,
+		FuncArgs:   op.Trigger.FunctionArgs,
+	}
+	for _, ev := range op.Trigger.Events {
+		trigger.Events = append(trigger.Events, descpb.TriggerDescriptor_Event{
+			Type:      descpb.TriggerDescriptor_Event_Type(ev.Type),
+			ColumnIDs: ev.ColumnIDs,
+		})
+	}
+	tbl.Triggers = append(tbl.Triggers, trigger)
+	return nil
+}
+
+func (i *immediateVisitor) RemoveTrigger(ctx context.Context, op scop.RemoveTrigger) error {
+	tbl, err := i.checkOutTable(ctx, op.Trigger.TableID)
+	if err != nil || tbl.Dropped() {
+		return err
+	}
+	for idx := range tbl.Triggers {
+		if tbl.Triggers[idx].ID == op.Trigger.TriggerID {
+			tbl.Triggers = append(tbl.Triggers[:idx], tbl.Triggers[idx+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (i *immediateVisitor) AddTriggerBackReferenceInFunction(
+	ctx context.Context, op scop.AddTriggerBackReferenceInFunction,
+) error {
+	fnDesc, err := i.checkOutFunction(ctx, op.FunctionID)
+	if err != nil {
+		return err
+	}
+	return fnDesc.AddTriggerReference(op.BackReferencedTableID, op.BackReferencedTriggerID)
+}
+
+func (i *immediateVisitor) RemoveTriggerBackReferenceInFunction(
+	ctx context.Context, op scop.RemoveTriggerBackReferenceInFunction,
+) error {
+	fnDesc, err := i.checkOutFunction(ctx, op.FunctionID)
+	if err != nil {
+		return err
+	}
+	fnDesc.RemoveTriggerReference(op.BackReferencedTableID, op.BackReferencedTriggerID)
+	return nil
+}
@@ -769,3 +769,33 @@ type UpdateOwner struct {
 	immediateMutationOp
 	Owner scpb.Owner
 }
+
+// AddTrigger adds a trigger to a table.
+type AddTrigger struct {
+	immediateMutationOp
+	Trigger scpb.Trigger
+}
+
+// RemoveTrigger removes a trigger from a table.
+type RemoveTrigger struct {
+	immediateMutationOp
+	Trigger scpb.Trigger
+}
+
+// AddTriggerBackReferenceInFunction adds a back reference to a trigger in the
+// trigger function.
+type AddTriggerBackReferenceInFunction struct {
+	immediateMutationOp
+	BackReferencedTableID   descpb.ID
+	BackReferencedTriggerID descpb.TriggerID
+	FunctionID              descpb.ID
+}
+
+// RemoveTriggerBackReferenceInFunction removes a back reference to a trigger
+// from the trigger function.
+type RemoveTriggerBackReferenceInFunction struct {
+	immediateMutationOp
+	BackReferencedTableID   descpb.ID
+	BackReferencedTriggerID descpb.TriggerID
+	FunctionID              descpb.ID
+}
@@ -118,6 +118,10 @@ type ImmediateMutationVisitor interface {
 	SetObjectParentID(context.Context, SetObjectParentID) error
 	UpdateUserPrivileges(context.Context, UpdateUserPrivileges) error
 	UpdateOwner(context.Context, UpdateOwner) error
+	AddTrigger(context.Context, AddTrigger) error
+	RemoveTrigger(context.Context, RemoveTrigger) error
+	AddTriggerBackReferenceInFunction(context.Context, AddTriggerBackReferenceInFunction) error
+	RemoveTriggerBackReferenceInFunction(context.Context, RemoveTriggerBackReferenceInFunction) error
 }
 
 // Visit is part of the ImmediateMutationOp interface.
@@ -599,3 +603,23 @@ func (op UpdateUserPrivileges) Visit(ctx context.Context, v ImmediateMutationVis
 func (op UpdateOwner) Visit(ctx context.Context, v ImmediateMutationVisitor) error {
 	return v.UpdateOwner(ctx, op)
 }
+
+// Visit is part of the ImmediateMutationOp interface.
+func (op AddTrigger) Visit(ctx context.Context, v ImmediateMutationVisitor) error {
+	return v.AddTrigger(ctx, op)
+}
+
+// Visit is part of the ImmediateMutationOp interface.
+func (op RemoveTrigger) Visit(ctx context.Context, v ImmediateMutationVisitor) error {
+	return v.RemoveTrigger(ctx, op)
+}
+
+// Visit is part of the ImmediateMutationOp interface.
+func (op AddTriggerBackReferenceInFunction) Visit(ctx context.Context, v ImmediateMutationVisitor) error {
+	return v.AddTriggerBackReferenceInFunction(ctx, op)
+}
+
+// Visit is part of the ImmediateMutationOp interface.
+func (op RemoveTriggerBackReferenceInFunction) Visit(ctx context.Context, v ImmediateMutationVisitor) error {
+	return v.RemoveTriggerBackReferenceInFunction(ctx, op)
+}
@@ -1846,6 +1846,36 @@ func FindTemporaryIndex(b ElementStatusIterator) (current Status, target TargetS
 	return current, target, element
 }
 
+func (e Trigger) element() {}
+
+// ForEachTrigger iterates over elements of type Trigger.
+func ForEachTrigger(
+	b ElementStatusIterator, fn func(current Status, target TargetStatus, e *Trigger),
+) {
+  if b == nil {
+    return
+  }
+	b.ForEachElementStatus(func(current Status, target TargetStatus, e Element) {
+		if elt, ok := e.(*Trigger); ok {
+			fn(current, target, elt)
+		}
+	})
+}
+
+// FindTrigger finds the first element of type Trigger.
+func FindTrigger(b ElementStatusIterator) (current Status, target TargetStatus, element *Trigger) {
+  if b == nil {
+    return current, target, element
+  }
+	b.ForEachElementStatus(func(c Status, t TargetStatus, e Element) {
+		if elt, ok := e.(*Trigger); ok {
+			element = elt
+			current = c
+			target = t
+		}
+	})
+	return current, target, element
+}
 func (e UniqueWithoutIndexConstraint) element() {}
 
 // ForEachUniqueWithoutIndexConstraint iterates over elements of type UniqueWithoutIndexConstraint.
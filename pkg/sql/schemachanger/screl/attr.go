@@ -383,6 +383,12 @@ var elementSchemaOptions = []rel.SchemaOption{
 	rel.EntityMapping(t((*scpb.FunctionParamDefaultExpression)(nil)),
 		rel.EntityAttr(DescID, "FunctionID"),
 	),
+	// Trigger elements.
+	rel.EntityMapping(t((*scpb.Trigger)(nil)),
+		rel.EntityAttr(DescID, "TableID"),
+		rel.EntityAttr(Name, "Name"),
+		rel.EntityAttr(ReferencedDescID, "FunctionID"),
+	),
 }
 
 // Schema is the schema exported by this package covering the elements of scpb.
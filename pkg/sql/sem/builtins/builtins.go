@@ -267,14 +267,16 @@ var regularBuiltins = map[string]builtinDefinition{
 	// TODO(pmattis): What string functions should also support types.Bytes?
 
 	"lower": makeBuiltin(tree.FunctionProperties{Category: builtinconstants.CategoryString},
-		stringOverload1(
-			func(_ context.Context, _ *eval.Context, s string) (tree.Datum, error) {
-				return tree.NewDString(strings.ToLower(s)), nil
-			},
-			types.String,
-			"Converts all characters in `val` to their lower-case equivalents.",
-			volatility.Immutable,
-		),
+		append([]tree.Overload{
+			stringOverload1(
+				func(_ context.Context, _ *eval.Context, s string) (tree.Datum, error) {
+					return tree.NewDString(strings.ToLower(s)), nil
+				},
+				types.String,
+				"Converts all characters in `val` to their lower-case equivalents.",
+				volatility.Immutable,
+			),
+		}, rangeLowerOverloads()...)...,
 	),
 
 	"unaccent": makeBuiltin(tree.FunctionProperties{Category: builtinconstants.CategoryString},
@@ -298,14 +300,16 @@ var regularBuiltins = map[string]builtinDefinition{
 	),
 
 	"upper": makeBuiltin(tree.FunctionProperties{Category: builtinconstants.CategoryString},
-		stringOverload1(
-			func(_ context.Context, _ *eval.Context, s string) (tree.Datum, error) {
-				return tree.NewDString(strings.ToUpper(s)), nil
-			},
-			types.String,
-			"Converts all characters in `val` to their to their upper-case equivalents.",
-			volatility.Immutable,
-		),
+		append([]tree.Overload{
+			stringOverload1(
+				func(_ context.Context, _ *eval.Context, s string) (tree.Datum, error) {
+					return tree.NewDString(strings.ToUpper(s)), nil
+				},
+				types.String,
+				"Converts all characters in `val` to their to their upper-case equivalents.",
+				volatility.Immutable,
+			),
+		}, rangeUpperOverloads()...)...,
 	),
 
 	"prettify_statement": makeBuiltin(tree.FunctionProperties{Category: builtinconstants.CategoryString},
@@ -3752,21 +3756,16 @@ value if you rely on the HLC for accuracy.`,
 	"tsvector_cmp":                   makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"tsvector_concat":                makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"ts_debug":                       makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
-	"ts_headline":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"ts_lexize":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
-	"websearch_to_tsquery":           makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"array_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"get_current_ts_config":          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"numnode":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"querytree":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
-	"setweight":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"strip":                          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"json_to_tsvector":               makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"jsonb_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"ts_delete":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"ts_filter":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
-	"ts_rank":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
-	"ts_rank_cd":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"ts_rewrite":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"tsquery_phrase":                 makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
 	"tsvector_to_array":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: builtinconstants.CategoryFullTextSearch}),
@@ -6009,6 +6008,40 @@ DO NOT USE -- USE 'CREATE TENANT' INSTEAD`,
 		},
 	),
 
+	"crdb_internal.assert_domain_check": makeBuiltin(
+		tree.FunctionProperties{
+			Category: builtinconstants.CategorySystemInfo,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "val", Typ: types.Any},
+				{Name: "ok", Typ: types.Bool},
+				{Name: "domain", Typ: types.String},
+				{Name: "constraint", Typ: types.String},
+			},
+			ReturnType: tree.IdentityReturnType(0),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				// Like CHECK constraints, the constraints of domains are only
+				// violated if they evaluate to false.
+				if ok, isBool := args[1].(*tree.DBool); !isBool || bool(*ok) {
+					return args[0], nil
+				}
+				domain := string(tree.MustBeDString(args[2]))
+				constraint := string(tree.MustBeDString(args[3]))
+				if constraint == "" {
+					return nil, pgerror.Newf(pgcode.NotNullViolation,
+						"domain %s does not allow null values", domain)
+				}
+				return nil, pgerror.Newf(pgcode.CheckViolation,
+					"value for domain %s violates check constraint %q", domain, constraint)
+			},
+			Info: "This function is used internally to enforce the constraints of domains in casts. " +
+				"It returns val if ok is not false. An empty constraint denotes the NOT NULL constraint.",
+			Volatility:        volatility.Immutable,
+			CalledOnNullInput: true,
+		},
+	),
+
 	"crdb_internal.round_decimal_values": makeBuiltin(
 		tree.FunctionProperties{
 			Category: builtinconstants.CategorySystemInfo,
@@ -8919,12 +8952,19 @@ func similarOverloads(calledOnNullInput bool) []tree.Overload {
 }
 
 func arrayBuiltin(impl func(*types.T) tree.Overload) builtinDefinition {
-	overloads := make([]tree.Overload, 0, len(types.Scalar)+2)
+	overloads := make([]tree.Overload, 0,
+		len(types.Scalar)+len(types.RangeTypes)+len(types.MultirangeTypes)+2)
 	for _, typ := range append(types.Scalar, types.AnyEnum) {
 		if ok, _ := types.IsValidArrayElementType(typ); ok {
 			overloads = append(overloads, impl(typ))
 		}
 	}
+	for _, typ := range types.RangeTypes {
+		overloads = append(overloads, impl(typ))
+	}
+	for _, typ := range types.MultirangeTypes {
+		overloads = append(overloads, impl(typ))
+	}
 	// Prevent usage in DistSQL because it cannot handle arrays of untyped tuples.
 	tupleOverload := impl(types.AnyTuple)
 	tupleOverload.DistsqlBlocklist = true
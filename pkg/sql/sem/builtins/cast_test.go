@@ -53,6 +53,11 @@ func TestCastBuiltins(t *testing.T) {
 		if !ok {
 			return
 		}
+		// The names of the range and multirange types are used by their
+		// constructor functions instead of cast builtins.
+		if fam := toTyp.Family(); fam == types.RangeFamily || fam == types.MultirangeFamily {
+			return
+		}
 		toName := tree.Name(cast.CastTypeName(toTyp))
 		q := fmt.Sprintf("SELECT %s(NULL::%s)", toName.String(), fromTyp.String())
 		t.Run(q, func(t *testing.T) {
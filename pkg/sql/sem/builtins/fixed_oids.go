@@ -2368,6 +2368,384 @@ var builtinOidsArray = []string{
 	2394: `array_cat_agg(arg1: varbit[]) -> varbit[]`,
 	2395: `array_cat_agg(arg1: anyenum[]) -> anyenum[]`,
 	2396: `array_cat_agg(arg1: tuple[]) -> tuple[]`,
+	2397: `crdb_internal.assert_domain_check(val: anyelement, ok: bool, domain: string, constraint: string) -> anyelement`,
+	2398: `triggersend(trigger: trigger) -> bytes`,
+	2399: `triggerrecv(input: anyelement) -> trigger`,
+	2400: `triggerout(trigger: trigger) -> bytes`,
+	2401: `triggerin(input: anyelement) -> trigger`,
+	2402: `pg_notify(channel: string, payload: string) -> void`,
+	2403: `to_tsvector(text: string) -> tsvector`,
+	2404: `to_tsquery(text: string) -> tsquery`,
+	2405: `plainto_tsquery(text: string) -> tsquery`,
+	2406: `phraseto_tsquery(text: string) -> tsquery`,
+	2407: `websearch_to_tsquery(config: string, text: string) -> tsquery`,
+	2408: `websearch_to_tsquery(text: string) -> tsquery`,
+	2409: `ts_rank(weights: float4[], vector: tsvector, query: tsquery, normalization: int) -> float4`,
+	2410: `ts_rank(weights: float4[], vector: tsvector, query: tsquery) -> float4`,
+	2411: `ts_rank(vector: tsvector, query: tsquery, normalization: int) -> float4`,
+	2412: `ts_rank(vector: tsvector, query: tsquery) -> float4`,
+	2413: `ts_rank_cd(weights: float4[], vector: tsvector, query: tsquery, normalization: int) -> float4`,
+	2414: `ts_rank_cd(weights: float4[], vector: tsvector, query: tsquery) -> float4`,
+	2415: `ts_rank_cd(vector: tsvector, query: tsquery, normalization: int) -> float4`,
+	2416: `ts_rank_cd(vector: tsvector, query: tsquery) -> float4`,
+	2417: `ts_headline(config: string, document: string, query: tsquery, options: string) -> string`,
+	2418: `ts_headline(config: string, document: string, query: tsquery) -> string`,
+	2419: `ts_headline(document: string, query: tsquery, options: string) -> string`,
+	2420: `ts_headline(document: string, query: tsquery) -> string`,
+	2421: `setweight(vector: tsvector, weight: string) -> tsvector`,
+	2422: `setweight(vector: tsvector, weight: string, lexemes: string[]) -> tsvector`,
+	2423: `int4range(lower: int4, upper: int4) -> int4range`,
+	2424: `int4range(lower: int4, upper: int4, bounds: string) -> int4range`,
+	2425: `int4multirange(int4range...) -> int4multirange`,
+	2426: `range_merge(a: int4range, b: int4range) -> int4range`,
+	2427: `range_merge(val: int4multirange) -> int4range`,
+	2428: `multirange(val: int4range) -> int4multirange`,
+	2429: `int8range(lower: int, upper: int) -> int8range`,
+	2430: `int8range(lower: int, upper: int, bounds: string) -> int8range`,
+	2431: `int8multirange(int8range...) -> int8multirange`,
+	2432: `range_merge(a: int8range, b: int8range) -> int8range`,
+	2433: `range_merge(val: int8multirange) -> int8range`,
+	2434: `multirange(val: int8range) -> int8multirange`,
+	2435: `numrange(lower: decimal, upper: decimal) -> numrange`,
+	2436: `numrange(lower: decimal, upper: decimal, bounds: string) -> numrange`,
+	2437: `nummultirange(numrange...) -> nummultirange`,
+	2438: `range_merge(a: numrange, b: numrange) -> numrange`,
+	2439: `range_merge(val: nummultirange) -> numrange`,
+	2440: `multirange(val: numrange) -> nummultirange`,
+	2441: `tsrange(lower: timestamp, upper: timestamp) -> tsrange`,
+	2442: `tsrange(lower: timestamp, upper: timestamp, bounds: string) -> tsrange`,
+	2443: `tsmultirange(tsrange...) -> tsmultirange`,
+	2444: `range_merge(a: tsrange, b: tsrange) -> tsrange`,
+	2445: `range_merge(val: tsmultirange) -> tsrange`,
+	2446: `multirange(val: tsrange) -> tsmultirange`,
+	2447: `tstzrange(lower: timestamptz, upper: timestamptz) -> tstzrange`,
+	2448: `tstzrange(lower: timestamptz, upper: timestamptz, bounds: string) -> tstzrange`,
+	2449: `tstzmultirange(tstzrange...) -> tstzmultirange`,
+	2450: `range_merge(a: tstzrange, b: tstzrange) -> tstzrange`,
+	2451: `range_merge(val: tstzmultirange) -> tstzrange`,
+	2452: `multirange(val: tstzrange) -> tstzmultirange`,
+	2453: `daterange(lower: date, upper: date) -> daterange`,
+	2454: `daterange(lower: date, upper: date, bounds: string) -> daterange`,
+	2455: `datemultirange(daterange...) -> datemultirange`,
+	2456: `range_merge(a: daterange, b: daterange) -> daterange`,
+	2457: `range_merge(val: datemultirange) -> daterange`,
+	2458: `multirange(val: daterange) -> datemultirange`,
+	2459: `isempty(val: int4range) -> bool`,
+	2460: `isempty(val: int4multirange) -> bool`,
+	2461: `isempty(val: int8range) -> bool`,
+	2462: `isempty(val: int8multirange) -> bool`,
+	2463: `isempty(val: numrange) -> bool`,
+	2464: `isempty(val: nummultirange) -> bool`,
+	2465: `isempty(val: tsrange) -> bool`,
+	2466: `isempty(val: tsmultirange) -> bool`,
+	2467: `isempty(val: tstzrange) -> bool`,
+	2468: `isempty(val: tstzmultirange) -> bool`,
+	2469: `isempty(val: daterange) -> bool`,
+	2470: `isempty(val: datemultirange) -> bool`,
+	2471: `lower_inc(val: int4range) -> bool`,
+	2472: `lower_inc(val: int4multirange) -> bool`,
+	2473: `lower_inc(val: int8range) -> bool`,
+	2474: `lower_inc(val: int8multirange) -> bool`,
+	2475: `lower_inc(val: numrange) -> bool`,
+	2476: `lower_inc(val: nummultirange) -> bool`,
+	2477: `lower_inc(val: tsrange) -> bool`,
+	2478: `lower_inc(val: tsmultirange) -> bool`,
+	2479: `lower_inc(val: tstzrange) -> bool`,
+	2480: `lower_inc(val: tstzmultirange) -> bool`,
+	2481: `lower_inc(val: daterange) -> bool`,
+	2482: `lower_inc(val: datemultirange) -> bool`,
+	2483: `upper_inc(val: int4range) -> bool`,
+	2484: `upper_inc(val: int4multirange) -> bool`,
+	2485: `upper_inc(val: int8range) -> bool`,
+	2486: `upper_inc(val: int8multirange) -> bool`,
+	2487: `upper_inc(val: numrange) -> bool`,
+	2488: `upper_inc(val: nummultirange) -> bool`,
+	2489: `upper_inc(val: tsrange) -> bool`,
+	2490: `upper_inc(val: tsmultirange) -> bool`,
+	2491: `upper_inc(val: tstzrange) -> bool`,
+	2492: `upper_inc(val: tstzmultirange) -> bool`,
+	2493: `upper_inc(val: daterange) -> bool`,
+	2494: `upper_inc(val: datemultirange) -> bool`,
+	2495: `lower_inf(val: int4range) -> bool`,
+	2496: `lower_inf(val: int4multirange) -> bool`,
+	2497: `lower_inf(val: int8range) -> bool`,
+	2498: `lower_inf(val: int8multirange) -> bool`,
+	2499: `lower_inf(val: numrange) -> bool`,
+	2500: `lower_inf(val: nummultirange) -> bool`,
+	2501: `lower_inf(val: tsrange) -> bool`,
+	2502: `lower_inf(val: tsmultirange) -> bool`,
+	2503: `lower_inf(val: tstzrange) -> bool`,
+	2504: `lower_inf(val: tstzmultirange) -> bool`,
+	2505: `lower_inf(val: daterange) -> bool`,
+	2506: `lower_inf(val: datemultirange) -> bool`,
+	2507: `upper_inf(val: int4range) -> bool`,
+	2508: `upper_inf(val: int4multirange) -> bool`,
+	2509: `upper_inf(val: int8range) -> bool`,
+	2510: `upper_inf(val: int8multirange) -> bool`,
+	2511: `upper_inf(val: numrange) -> bool`,
+	2512: `upper_inf(val: nummultirange) -> bool`,
+	2513: `upper_inf(val: tsrange) -> bool`,
+	2514: `upper_inf(val: tsmultirange) -> bool`,
+	2515: `upper_inf(val: tstzrange) -> bool`,
+	2516: `upper_inf(val: tstzmultirange) -> bool`,
+	2517: `upper_inf(val: daterange) -> bool`,
+	2518: `upper_inf(val: datemultirange) -> bool`,
+	2519: `lower(val: int4range) -> int4`,
+	2520: `lower(val: int4multirange) -> int4`,
+	2521: `lower(val: int8range) -> int`,
+	2522: `lower(val: int8multirange) -> int`,
+	2523: `lower(val: numrange) -> decimal`,
+	2524: `lower(val: nummultirange) -> decimal`,
+	2525: `lower(val: tsrange) -> timestamp`,
+	2526: `lower(val: tsmultirange) -> timestamp`,
+	2527: `lower(val: tstzrange) -> timestamptz`,
+	2528: `lower(val: tstzmultirange) -> timestamptz`,
+	2529: `lower(val: daterange) -> date`,
+	2530: `lower(val: datemultirange) -> date`,
+	2531: `upper(val: int4range) -> int4`,
+	2532: `upper(val: int4multirange) -> int4`,
+	2533: `upper(val: int8range) -> int`,
+	2534: `upper(val: int8multirange) -> int`,
+	2535: `upper(val: numrange) -> decimal`,
+	2536: `upper(val: nummultirange) -> decimal`,
+	2537: `upper(val: tsrange) -> timestamp`,
+	2538: `upper(val: tsmultirange) -> timestamp`,
+	2539: `upper(val: tstzrange) -> timestamptz`,
+	2540: `upper(val: tstzmultirange) -> timestamptz`,
+	2541: `upper(val: daterange) -> date`,
+	2542: `upper(val: datemultirange) -> date`,
+	2543: `int4rangesend(int4range: int4range) -> bytes`,
+	2544: `int4rangerecv(input: anyelement) -> int4range`,
+	2545: `int4rangeout(int4range: int4range) -> bytes`,
+	2546: `int4rangein(input: anyelement) -> int4range`,
+	2547: `int4multirangesend(int4multirange: int4multirange) -> bytes`,
+	2548: `int4multirangerecv(input: anyelement) -> int4multirange`,
+	2549: `int4multirangeout(int4multirange: int4multirange) -> bytes`,
+	2550: `int4multirangein(input: anyelement) -> int4multirange`,
+	2551: `int8rangesend(int8range: int8range) -> bytes`,
+	2552: `int8rangerecv(input: anyelement) -> int8range`,
+	2553: `int8rangeout(int8range: int8range) -> bytes`,
+	2554: `int8rangein(input: anyelement) -> int8range`,
+	2555: `int8multirangesend(int8multirange: int8multirange) -> bytes`,
+	2556: `int8multirangerecv(input: anyelement) -> int8multirange`,
+	2557: `int8multirangeout(int8multirange: int8multirange) -> bytes`,
+	2558: `int8multirangein(input: anyelement) -> int8multirange`,
+	2559: `numrangesend(numrange: numrange) -> bytes`,
+	2560: `numrangerecv(input: anyelement) -> numrange`,
+	2561: `numrangeout(numrange: numrange) -> bytes`,
+	2562: `numrangein(input: anyelement) -> numrange`,
+	2563: `nummultirangesend(nummultirange: nummultirange) -> bytes`,
+	2564: `nummultirangerecv(input: anyelement) -> nummultirange`,
+	2565: `nummultirangeout(nummultirange: nummultirange) -> bytes`,
+	2566: `nummultirangein(input: anyelement) -> nummultirange`,
+	2567: `tsrangesend(tsrange: tsrange) -> bytes`,
+	2568: `tsrangerecv(input: anyelement) -> tsrange`,
+	2569: `tsrangeout(tsrange: tsrange) -> bytes`,
+	2570: `tsrangein(input: anyelement) -> tsrange`,
+	2571: `tsmultirangesend(tsmultirange: tsmultirange) -> bytes`,
+	2572: `tsmultirangerecv(input: anyelement) -> tsmultirange`,
+	2573: `tsmultirangeout(tsmultirange: tsmultirange) -> bytes`,
+	2574: `tsmultirangein(input: anyelement) -> tsmultirange`,
+	2575: `tstzrangesend(tstzrange: tstzrange) -> bytes`,
+	2576: `tstzrangerecv(input: anyelement) -> tstzrange`,
+	2577: `tstzrangeout(tstzrange: tstzrange) -> bytes`,
+	2578: `tstzrangein(input: anyelement) -> tstzrange`,
+	2579: `tstzmultirangesend(tstzmultirange: tstzmultirange) -> bytes`,
+	2580: `tstzmultirangerecv(input: anyelement) -> tstzmultirange`,
+	2581: `tstzmultirangeout(tstzmultirange: tstzmultirange) -> bytes`,
+	2582: `tstzmultirangein(input: anyelement) -> tstzmultirange`,
+	2583: `daterangesend(daterange: daterange) -> bytes`,
+	2584: `daterangerecv(input: anyelement) -> daterange`,
+	2585: `daterangeout(daterange: daterange) -> bytes`,
+	2586: `daterangein(input: anyelement) -> daterange`,
+	2587: `datemultirangesend(datemultirange: datemultirange) -> bytes`,
+	2588: `datemultirangerecv(input: anyelement) -> datemultirange`,
+	2589: `datemultirangeout(datemultirange: datemultirange) -> bytes`,
+	2590: `datemultirangein(input: anyelement) -> datemultirange`,
+	2591: `bpchar(int4range: int4range) -> char`,
+	2592: `char(int4range: int4range) -> "char"`,
+	2593: `name(int4range: int4range) -> name`,
+	2594: `text(int4range: int4range) -> string`,
+	2595: `varchar(int4range: int4range) -> varchar`,
+	2596: `bpchar(int4multirange: int4multirange) -> char`,
+	2597: `char(int4multirange: int4multirange) -> "char"`,
+	2598: `name(int4multirange: int4multirange) -> name`,
+	2599: `text(int4multirange: int4multirange) -> string`,
+	2600: `varchar(int4multirange: int4multirange) -> varchar`,
+	2601: `bpchar(int8range: int8range) -> char`,
+	2602: `char(int8range: int8range) -> "char"`,
+	2603: `name(int8range: int8range) -> name`,
+	2604: `text(int8range: int8range) -> string`,
+	2605: `varchar(int8range: int8range) -> varchar`,
+	2606: `bpchar(int8multirange: int8multirange) -> char`,
+	2607: `char(int8multirange: int8multirange) -> "char"`,
+	2608: `name(int8multirange: int8multirange) -> name`,
+	2609: `text(int8multirange: int8multirange) -> string`,
+	2610: `varchar(int8multirange: int8multirange) -> varchar`,
+	2611: `bpchar(numrange: numrange) -> char`,
+	2612: `char(numrange: numrange) -> "char"`,
+	2613: `name(numrange: numrange) -> name`,
+	2614: `text(numrange: numrange) -> string`,
+	2615: `varchar(numrange: numrange) -> varchar`,
+	2616: `bpchar(nummultirange: nummultirange) -> char`,
+	2617: `char(nummultirange: nummultirange) -> "char"`,
+	2618: `name(nummultirange: nummultirange) -> name`,
+	2619: `text(nummultirange: nummultirange) -> string`,
+	2620: `varchar(nummultirange: nummultirange) -> varchar`,
+	2621: `bpchar(tsrange: tsrange) -> char`,
+	2622: `char(tsrange: tsrange) -> "char"`,
+	2623: `name(tsrange: tsrange) -> name`,
+	2624: `text(tsrange: tsrange) -> string`,
+	2625: `varchar(tsrange: tsrange) -> varchar`,
+	2626: `bpchar(tsmultirange: tsmultirange) -> char`,
+	2627: `char(tsmultirange: tsmultirange) -> "char"`,
+	2628: `name(tsmultirange: tsmultirange) -> name`,
+	2629: `text(tsmultirange: tsmultirange) -> string`,
+	2630: `varchar(tsmultirange: tsmultirange) -> varchar`,
+	2631: `bpchar(tstzrange: tstzrange) -> char`,
+	2632: `char(tstzrange: tstzrange) -> "char"`,
+	2633: `name(tstzrange: tstzrange) -> name`,
+	2634: `text(tstzrange: tstzrange) -> string`,
+	2635: `varchar(tstzrange: tstzrange) -> varchar`,
+	2636: `bpchar(tstzmultirange: tstzmultirange) -> char`,
+	2637: `char(tstzmultirange: tstzmultirange) -> "char"`,
+	2638: `name(tstzmultirange: tstzmultirange) -> name`,
+	2639: `text(tstzmultirange: tstzmultirange) -> string`,
+	2640: `varchar(tstzmultirange: tstzmultirange) -> varchar`,
+	2641: `bpchar(daterange: daterange) -> char`,
+	2642: `char(daterange: daterange) -> "char"`,
+	2643: `name(daterange: daterange) -> name`,
+	2644: `text(daterange: daterange) -> string`,
+	2645: `varchar(daterange: daterange) -> varchar`,
+	2646: `bpchar(datemultirange: datemultirange) -> char`,
+	2647: `char(datemultirange: datemultirange) -> "char"`,
+	2648: `name(datemultirange: datemultirange) -> name`,
+	2649: `text(datemultirange: datemultirange) -> string`,
+	2650: `varchar(datemultirange: datemultirange) -> varchar`,
+	2651: `array_agg(arg1: int4range) -> int4range[]`,
+	2652: `array_agg(arg1: int8range) -> int8range[]`,
+	2653: `array_agg(arg1: numrange) -> numrange[]`,
+	2654: `array_agg(arg1: tsrange) -> tsrange[]`,
+	2655: `array_agg(arg1: tstzrange) -> tstzrange[]`,
+	2656: `array_agg(arg1: daterange) -> daterange[]`,
+	2657: `array_agg(arg1: int4multirange) -> int4multirange[]`,
+	2658: `array_agg(arg1: int8multirange) -> int8multirange[]`,
+	2659: `array_agg(arg1: nummultirange) -> nummultirange[]`,
+	2660: `array_agg(arg1: tsmultirange) -> tsmultirange[]`,
+	2661: `array_agg(arg1: tstzmultirange) -> tstzmultirange[]`,
+	2662: `array_agg(arg1: datemultirange) -> datemultirange[]`,
+	2663: `array_cat_agg(arg1: int4range[]) -> int4range[]`,
+	2664: `array_cat_agg(arg1: int8range[]) -> int8range[]`,
+	2665: `array_cat_agg(arg1: numrange[]) -> numrange[]`,
+	2666: `array_cat_agg(arg1: tsrange[]) -> tsrange[]`,
+	2667: `array_cat_agg(arg1: tstzrange[]) -> tstzrange[]`,
+	2668: `array_cat_agg(arg1: daterange[]) -> daterange[]`,
+	2669: `array_cat_agg(arg1: int4multirange[]) -> int4multirange[]`,
+	2670: `array_cat_agg(arg1: int8multirange[]) -> int8multirange[]`,
+	2671: `array_cat_agg(arg1: nummultirange[]) -> nummultirange[]`,
+	2672: `array_cat_agg(arg1: tsmultirange[]) -> tsmultirange[]`,
+	2673: `array_cat_agg(arg1: tstzmultirange[]) -> tstzmultirange[]`,
+	2674: `array_cat_agg(arg1: datemultirange[]) -> datemultirange[]`,
+	2675: `array_cat(left: int4range[], right: int4range[]) -> int4range[]`,
+	2676: `array_cat(left: int8range[], right: int8range[]) -> int8range[]`,
+	2677: `array_cat(left: numrange[], right: numrange[]) -> numrange[]`,
+	2678: `array_cat(left: tsrange[], right: tsrange[]) -> tsrange[]`,
+	2679: `array_cat(left: tstzrange[], right: tstzrange[]) -> tstzrange[]`,
+	2680: `array_cat(left: daterange[], right: daterange[]) -> daterange[]`,
+	2681: `array_cat(left: int4multirange[], right: int4multirange[]) -> int4multirange[]`,
+	2682: `array_cat(left: int8multirange[], right: int8multirange[]) -> int8multirange[]`,
+	2683: `array_cat(left: nummultirange[], right: nummultirange[]) -> nummultirange[]`,
+	2684: `array_cat(left: tsmultirange[], right: tsmultirange[]) -> tsmultirange[]`,
+	2685: `array_cat(left: tstzmultirange[], right: tstzmultirange[]) -> tstzmultirange[]`,
+	2686: `array_cat(left: datemultirange[], right: datemultirange[]) -> datemultirange[]`,
+	2687: `array_remove(array: int4range[], elem: int4range) -> anyelement`,
+	2688: `array_remove(array: int8range[], elem: int8range) -> anyelement`,
+	2689: `array_remove(array: numrange[], elem: numrange) -> anyelement`,
+	2690: `array_remove(array: tsrange[], elem: tsrange) -> anyelement`,
+	2691: `array_remove(array: tstzrange[], elem: tstzrange) -> anyelement`,
+	2692: `array_remove(array: daterange[], elem: daterange) -> anyelement`,
+	2693: `array_remove(array: int4multirange[], elem: int4multirange) -> anyelement`,
+	2694: `array_remove(array: int8multirange[], elem: int8multirange) -> anyelement`,
+	2695: `array_remove(array: nummultirange[], elem: nummultirange) -> anyelement`,
+	2696: `array_remove(array: tsmultirange[], elem: tsmultirange) -> anyelement`,
+	2697: `array_remove(array: tstzmultirange[], elem: tstzmultirange) -> anyelement`,
+	2698: `array_remove(array: datemultirange[], elem: datemultirange) -> anyelement`,
+	2699: `array_replace(array: int4range[], toreplace: int4range, replacewith: int4range) -> anyelement`,
+	2700: `array_replace(array: int8range[], toreplace: int8range, replacewith: int8range) -> anyelement`,
+	2701: `array_replace(array: numrange[], toreplace: numrange, replacewith: numrange) -> anyelement`,
+	2702: `array_replace(array: tsrange[], toreplace: tsrange, replacewith: tsrange) -> anyelement`,
+	2703: `array_replace(array: tstzrange[], toreplace: tstzrange, replacewith: tstzrange) -> anyelement`,
+	2704: `array_replace(array: daterange[], toreplace: daterange, replacewith: daterange) -> anyelement`,
+	2705: `array_replace(array: int4multirange[], toreplace: int4multirange, replacewith: int4multirange) -> anyelement`,
+	2706: `array_replace(array: int8multirange[], toreplace: int8multirange, replacewith: int8multirange) -> anyelement`,
+	2707: `array_replace(array: nummultirange[], toreplace: nummultirange, replacewith: nummultirange) -> anyelement`,
+	2708: `array_replace(array: tsmultirange[], toreplace: tsmultirange, replacewith: tsmultirange) -> anyelement`,
+	2709: `array_replace(array: tstzmultirange[], toreplace: tstzmultirange, replacewith: tstzmultirange) -> anyelement`,
+	2710: `array_replace(array: datemultirange[], toreplace: datemultirange, replacewith: datemultirange) -> anyelement`,
+	2711: `array_append(array: int4range[], elem: int4range) -> int4range[]`,
+	2712: `array_append(array: int8range[], elem: int8range) -> int8range[]`,
+	2713: `array_append(array: numrange[], elem: numrange) -> numrange[]`,
+	2714: `array_append(array: tsrange[], elem: tsrange) -> tsrange[]`,
+	2715: `array_append(array: tstzrange[], elem: tstzrange) -> tstzrange[]`,
+	2716: `array_append(array: daterange[], elem: daterange) -> daterange[]`,
+	2717: `array_append(array: int4multirange[], elem: int4multirange) -> int4multirange[]`,
+	2718: `array_append(array: int8multirange[], elem: int8multirange) -> int8multirange[]`,
+	2719: `array_append(array: nummultirange[], elem: nummultirange) -> nummultirange[]`,
+	2720: `array_append(array: tsmultirange[], elem: tsmultirange) -> tsmultirange[]`,
+	2721: `array_append(array: tstzmultirange[], elem: tstzmultirange) -> tstzmultirange[]`,
+	2722: `array_append(array: datemultirange[], elem: datemultirange) -> datemultirange[]`,
+	2723: `array_positions(array: int4range[], elem: int4range) -> int[]`,
+	2724: `array_positions(array: int8range[], elem: int8range) -> int[]`,
+	2725: `array_positions(array: numrange[], elem: numrange) -> int[]`,
+	2726: `array_positions(array: tsrange[], elem: tsrange) -> int[]`,
+	2727: `array_positions(array: tstzrange[], elem: tstzrange) -> int[]`,
+	2728: `array_positions(array: daterange[], elem: daterange) -> int[]`,
+	2729: `array_positions(array: int4multirange[], elem: int4multirange) -> int[]`,
+	2730: `array_positions(array: int8multirange[], elem: int8multirange) -> int[]`,
+	2731: `array_positions(array: nummultirange[], elem: nummultirange) -> int[]`,
+	2732: `array_positions(array: tsmultirange[], elem: tsmultirange) -> int[]`,
+	2733: `array_positions(array: tstzmultirange[], elem: tstzmultirange) -> int[]`,
+	2734: `array_positions(array: datemultirange[], elem: datemultirange) -> int[]`,
+	2735: `array_prepend(elem: int4range, array: int4range[]) -> int4range[]`,
+	2736: `array_prepend(elem: int8range, array: int8range[]) -> int8range[]`,
+	2737: `array_prepend(elem: numrange, array: numrange[]) -> numrange[]`,
+	2738: `array_prepend(elem: tsrange, array: tsrange[]) -> tsrange[]`,
+	2739: `array_prepend(elem: tstzrange, array: tstzrange[]) -> tstzrange[]`,
+	2740: `array_prepend(elem: daterange, array: daterange[]) -> daterange[]`,
+	2741: `array_prepend(elem: int4multirange, array: int4multirange[]) -> int4multirange[]`,
+	2742: `array_prepend(elem: int8multirange, array: int8multirange[]) -> int8multirange[]`,
+	2743: `array_prepend(elem: nummultirange, array: nummultirange[]) -> nummultirange[]`,
+	2744: `array_prepend(elem: tsmultirange, array: tsmultirange[]) -> tsmultirange[]`,
+	2745: `array_prepend(elem: tstzmultirange, array: tstzmultirange[]) -> tstzmultirange[]`,
+	2746: `array_prepend(elem: datemultirange, array: datemultirange[]) -> datemultirange[]`,
+	2747: `array_position(array: int4range[], elem: int4range) -> int`,
+	2748: `array_position(array: int8range[], elem: int8range) -> int`,
+	2749: `array_position(array: numrange[], elem: numrange) -> int`,
+	2750: `array_position(array: tsrange[], elem: tsrange) -> int`,
+	2751: `array_position(array: tstzrange[], elem: tstzrange) -> int`,
+	2752: `array_position(array: daterange[], elem: daterange) -> int`,
+	2753: `array_position(array: int4multirange[], elem: int4multirange) -> int`,
+	2754: `array_position(array: int8multirange[], elem: int8multirange) -> int`,
+	2755: `array_position(array: nummultirange[], elem: nummultirange) -> int`,
+	2756: `array_position(array: tsmultirange[], elem: tsmultirange) -> int`,
+	2757: `array_position(array: tstzmultirange[], elem: tstzmultirange) -> int`,
+	2758: `array_position(array: datemultirange[], elem: datemultirange) -> int`,
+	2759: `lower(val: anyrange) -> anyelement`,
+	2760: `lower(val: anymultirange) -> anyelement`,
+	2761: `upper(val: anyrange) -> anyelement`,
+	2762: `upper(val: anymultirange) -> anyelement`,
+	2763: `isempty(val: anyrange) -> bool`,
+	2764: `isempty(val: anymultirange) -> bool`,
+	2765: `lower_inc(val: anyrange) -> bool`,
+	2766: `lower_inc(val: anymultirange) -> bool`,
+	2767: `upper_inc(val: anyrange) -> bool`,
+	2768: `upper_inc(val: anymultirange) -> bool`,
+	2769: `lower_inf(val: anyrange) -> bool`,
+	2770: `lower_inf(val: anymultirange) -> bool`,
+	2771: `upper_inf(val: anyrange) -> bool`,
+	2772: `upper_inf(val: anymultirange) -> bool`,
+	2773: `range_merge(a: anyrange, b: anyrange) -> anyelement`,
+	2774: `range_merge(val: anymultirange) -> anyelement`,
 }
 
 var builtinOidsBySignature map[string]oid.Oid
@@ -147,6 +147,11 @@ func init() {
 		if !ok {
 			return
 		}
+		// The names of the range and multirange types are used by their
+		// constructor functions instead.
+		if fam := toType.Family(); fam == types.RangeFamily || fam == types.MultirangeFamily {
+			return
+		}
 		if _, ok := castBuiltins[toOID]; !ok {
 			castBuiltins[toOID] = &builtinDefinition{
 				props: tree.FunctionProperties{
@@ -1315,6 +1320,36 @@ var pgBuiltins = map[string]builtinDefinition{
 		},
 	),
 
+	// pg_notify publishes a notification, like the NOTIFY statement.
+	// https://www.postgresql.org/docs/current/functions-info.html
+	"pg_notify": makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "channel", Typ: types.String}, {Name: "payload", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.Void),
+			Fn: func(ctx context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				// A NULL channel is rejected like an empty one, and a NULL payload is
+				// equivalent to an empty one, as in Postgres.
+				var channel, payload string
+				if args[0] != tree.DNull {
+					channel = string(tree.MustBeDString(args[0]))
+				}
+				if args[1] != tree.DNull {
+					payload = string(tree.MustBeDString(args[1]))
+				}
+				if err := evalCtx.Planner.PublishNotification(ctx, channel, payload); err != nil {
+					return nil, err
+				}
+				return tree.DVoidDatum, nil
+			},
+			Info: "Publishes a notification with the given payload on the given channel, " +
+				"like the NOTIFY statement. The notification is delivered to the sessions " +
+				"listening on the channel when the current transaction commits.",
+			Volatility:        volatility.Volatile,
+			CalledOnNullInput: true,
+		},
+	),
+
 	// pg_is_in_recovery returns true if the Postgres database is currently in
 	// recovery.  This is not applicable so this can always return false.
 	// https://www.postgresql.org/docs/current/static/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
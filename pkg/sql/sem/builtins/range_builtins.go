@@ -0,0 +1,319 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package builtins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins/builtinconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+func init() {
+	for k, v := range makeRangeBuiltins() {
+		v.props.Category = builtinconstants.CategoryRange
+		registerBuiltin(k, v)
+	}
+}
+
+// makeRangeBuiltins returns the builtins for the range and multirange types,
+// other than lower and upper, which share their names with the string
+// builtins and are defined along with them.
+func makeRangeBuiltins() map[string]builtinDefinition {
+	builtins := make(map[string]builtinDefinition)
+	addOverloads := func(name string, overloads ...tree.Overload) {
+		def := builtins[name]
+		def.overloads = append(def.overloads, overloads...)
+		builtins[name] = def
+	}
+
+	for i := range types.RangeTypes {
+		rangeTyp, multirangeTyp := types.RangeTypes[i], types.MultirangeTypes[i]
+		subtype := rangeTyp.RangeSubtype()
+
+		// Like in Postgres, the constructor functions of the range and
+		// multirange types have the names of the types.
+		addOverloads(rangeTyp.PGName(),
+			tree.Overload{
+				Types:      tree.ParamTypes{{Name: "lower", Typ: subtype}, {Name: "upper", Typ: subtype}},
+				ReturnType: tree.FixedReturnType(rangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					return tree.NewDRange(rangeTyp, args[0], args[1], true /* lowerInc */, false /* upperInc */)
+				},
+				Info: fmt.Sprintf("Returns the %s with the given bounds, including the lower bound "+
+					"and excluding the upper bound. A NULL bound is infinite.", rangeTyp.PGName()),
+				Volatility:        volatility.Immutable,
+				CalledOnNullInput: true,
+			},
+			tree.Overload{
+				Types: tree.ParamTypes{
+					{Name: "lower", Typ: subtype},
+					{Name: "upper", Typ: subtype},
+					{Name: "bounds", Typ: types.String},
+				},
+				ReturnType: tree.FixedReturnType(rangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					if args[2] == tree.DNull {
+						return nil, pgerror.New(pgcode.DataException,
+							"range constructor flags argument must not be null",
+						)
+					}
+					lowerInc, upperInc, err := parseRangeBoundsFlags(string(tree.MustBeDString(args[2])))
+					if err != nil {
+						return nil, err
+					}
+					return tree.NewDRange(rangeTyp, args[0], args[1], lowerInc, upperInc)
+				},
+				Info: fmt.Sprintf("Returns the %s with the given bounds. `bounds` is one of '[]', '[)', '(]' "+
+					"or '()', and specifies whether each bound is included. A NULL bound is infinite.",
+					rangeTyp.PGName()),
+				Volatility:        volatility.Immutable,
+				CalledOnNullInput: true,
+			},
+		)
+		addOverloads(multirangeTyp.PGName(),
+			tree.Overload{
+				Types:      tree.VariadicType{VarType: rangeTyp},
+				ReturnType: tree.FixedReturnType(multirangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					ranges := make([]*tree.DRange, len(args))
+					for j, arg := range args {
+						if arg == tree.DNull {
+							return nil, pgerror.New(pgcode.NullValueNotAllowed,
+								"multirange values cannot contain null members",
+							)
+						}
+						ranges[j] = tree.MustBeDRange(arg)
+					}
+					return tree.NewDMultirange(multirangeTyp, ranges)
+				},
+				Info: fmt.Sprintf("Returns the %s containing the values of all of the given ranges.",
+					multirangeTyp.PGName()),
+				Volatility:        volatility.Immutable,
+				CalledOnNullInput: true,
+			},
+		)
+
+		addOverloads("range_merge",
+			tree.Overload{
+				Types:      tree.ParamTypes{{Name: "a", Typ: rangeTyp}, {Name: "b", Typ: rangeTyp}},
+				ReturnType: tree.FixedReturnType(rangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					return tree.MustBeDRange(args[0]).Merge(tree.MustBeDRange(args[1]))
+				},
+				Info:       "Returns the smallest range which includes both of the given ranges.",
+				Volatility: volatility.Immutable,
+			},
+			tree.Overload{
+				Types:      tree.ParamTypes{{Name: "val", Typ: multirangeTyp}},
+				ReturnType: tree.FixedReturnType(rangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					return tree.MustBeDMultirange(args[0]).Span()
+				},
+				Info:       "Returns the smallest range which includes the entire multirange.",
+				Volatility: volatility.Immutable,
+			},
+		)
+		addOverloads("multirange",
+			tree.Overload{
+				Types:      tree.ParamTypes{{Name: "val", Typ: rangeTyp}},
+				ReturnType: tree.FixedReturnType(multirangeTyp),
+				Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+					return tree.MakeDMultirangeFromRange(multirangeTyp, tree.MustBeDRange(args[0])), nil
+				},
+				Info:       "Returns a multirange containing just the given range.",
+				Volatility: volatility.Immutable,
+			},
+		)
+	}
+
+	// User-defined range and multirange types share the overloads of the
+	// AnyRange and AnyMultirange wildcards. The constructor functions of
+	// user-defined range types are not supported.
+	addOverloads("range_merge",
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "a", Typ: types.AnyRange}, {Name: "b", Typ: types.AnyRange}},
+			ReturnType: tree.IdentityReturnType(0),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				a, b := tree.MustBeDRange(args[0]), tree.MustBeDRange(args[1])
+				if a.Typ.Oid() != b.Typ.Oid() {
+					return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+						"cannot merge ranges of different types %s and %s", a.Typ.SQLString(), b.Typ.SQLString())
+				}
+				return a.Merge(b)
+			},
+			Info:       "Returns the smallest range which includes both of the given ranges.",
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{{Name: "val", Typ: types.AnyMultirange}},
+			ReturnType: func(args []tree.TypedExpr) *types.T {
+				if len(args) == 0 || args[0].ResolvedType().Family() != types.MultirangeFamily {
+					return tree.UnknownReturnType
+				}
+				return args[0].ResolvedType().MultirangeContents()
+			},
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tree.MustBeDMultirange(args[0]).Span()
+			},
+			Info:       "Returns the smallest range which includes the entire multirange.",
+			Volatility: volatility.Immutable,
+		},
+	)
+
+	addOverloads("isempty", makeRangeAccessorOverloads(
+		types.Bool, true, /* lower */
+		"Returns true if `val` is empty.",
+		func(r *tree.DRange) tree.Datum {
+			return tree.MakeDBool(tree.DBool(r.Empty))
+		},
+	)...)
+	addOverloads("lower_inc", makeRangeAccessorOverloads(
+		types.Bool, true, /* lower */
+		"Returns true if the lower bound of `val` is included, or false if `val` is empty.",
+		func(r *tree.DRange) tree.Datum {
+			return tree.MakeDBool(tree.DBool(r.LowerInc))
+		},
+	)...)
+	addOverloads("upper_inc", makeRangeAccessorOverloads(
+		types.Bool, false, /* lower */
+		"Returns true if the upper bound of `val` is included, or false if `val` is empty.",
+		func(r *tree.DRange) tree.Datum {
+			return tree.MakeDBool(tree.DBool(r.UpperInc))
+		},
+	)...)
+	addOverloads("lower_inf", makeRangeAccessorOverloads(
+		types.Bool, true, /* lower */
+		"Returns true if the lower bound of `val` is infinite, or false if `val` is empty.",
+		func(r *tree.DRange) tree.Datum {
+			return tree.MakeDBool(tree.DBool(!r.Empty && r.Lower == tree.DNull))
+		},
+	)...)
+	addOverloads("upper_inf", makeRangeAccessorOverloads(
+		types.Bool, false, /* lower */
+		"Returns true if the upper bound of `val` is infinite, or false if `val` is empty.",
+		func(r *tree.DRange) tree.Datum {
+			return tree.MakeDBool(tree.DBool(!r.Empty && r.Upper == tree.DNull))
+		},
+	)...)
+	return builtins
+}
+
+// rangeLowerOverloads returns the overloads of the lower builtin for the range
+// and multirange types.
+func rangeLowerOverloads() []tree.Overload {
+	return makeRangeAccessorOverloads(
+		nil /* ret */, true, /* lower */
+		"Returns the lower bound of `val`, or NULL if `val` is empty or its lower bound is infinite.",
+		func(r *tree.DRange) tree.Datum {
+			return r.Lower
+		},
+	)
+}
+
+// rangeUpperOverloads returns the overloads of the upper builtin for the range
+// and multirange types.
+func rangeUpperOverloads() []tree.Overload {
+	return makeRangeAccessorOverloads(
+		nil /* ret */, false, /* lower */
+		"Returns the upper bound of `val`, or NULL if `val` is empty or its upper bound is infinite.",
+		func(r *tree.DRange) tree.Datum {
+			return r.Upper
+		},
+	)
+}
+
+// makeRangeAccessorOverloads returns an overload for each built-in range and
+// multirange type, and for the AnyRange and AnyMultirange wildcards, of a
+// function which inspects a range. The function is passed the range
+// itself, or for a multirange, its first range if lower is true and its last
+// range otherwise. An empty range is passed for an empty multirange. If ret is
+// nil, the function returns the range subtype.
+func makeRangeAccessorOverloads(
+	ret *types.T, lower bool, info string, fn func(r *tree.DRange) tree.Datum,
+) []tree.Overload {
+	overloads := make([]tree.Overload, 0, 2*len(types.RangeTypes)+2)
+	addOverload := func(typ *types.T, retType tree.ReturnTyper) {
+		overloads = append(overloads, tree.Overload{
+			Types:      tree.ParamTypes{{Name: "val", Typ: typ}},
+			ReturnType: retType,
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return fn(rangeAccessorArg(args[0], lower)), nil
+			},
+			Info:       info,
+			Volatility: volatility.Immutable,
+		})
+	}
+	for i := range types.RangeTypes {
+		for _, typ := range []*types.T{types.RangeTypes[i], types.MultirangeTypes[i]} {
+			retType := ret
+			if retType == nil {
+				retType = typ.RangeSubtype()
+			}
+			addOverload(typ, tree.FixedReturnType(retType))
+		}
+	}
+	// User-defined range and multirange types share the overloads of the
+	// AnyRange and AnyMultirange wildcards.
+	for _, typ := range []*types.T{types.AnyRange, types.AnyMultirange} {
+		retType := tree.FixedReturnType(ret)
+		if ret == nil {
+			retType = func(args []tree.TypedExpr) *types.T {
+				if len(args) == 0 {
+					return tree.UnknownReturnType
+				}
+				if subtype := args[0].ResolvedType().RangeSubtype(); subtype != nil {
+					return subtype
+				}
+				return tree.UnknownReturnType
+			}
+		}
+		addOverload(typ, retType)
+	}
+	return overloads
+}
+
+// rangeAccessorArg returns the range which a function created by
+// makeRangeAccessorOverloads inspects for the given range or multirange datum.
+func rangeAccessorArg(d tree.Datum, lower bool) *tree.DRange {
+	switch t := d.(type) {
+	case *tree.DRange:
+		return t
+	case *tree.DMultirange:
+		if t.IsEmpty() {
+			return tree.MakeEmptyDRange(t.Typ.MultirangeContents())
+		}
+		if lower {
+			return t.Ranges[0]
+		}
+		return t.Ranges[len(t.Ranges)-1]
+	}
+	panic(errors.AssertionFailedf("unexpected range argument %T", d))
+}
+
+// parseRangeBoundsFlags parses the bounds argument of a range constructor, such
+// as '[)', into whether the lower and upper bounds are included.
+func parseRangeBoundsFlags(s string) (lowerInc, upperInc bool, _ error) {
+	if len(s) == 2 && (s[0] == '[' || s[0] == '(') && (s[1] == ']' || s[1] == ')') {
+		return s[0] == '[', s[1] == ']', nil
+	}
+	return false, false, errors.WithHint(
+		pgerror.New(pgcode.Syntax, "invalid range bound flags"),
+		`Valid values are "[]", "[)", "(]", and "()".`,
+	)
+}
@@ -107,6 +107,23 @@ var tsearchBuiltins = map[string]builtinDefinition{
 				"Position information is included in the result.",
 			Volatility: volatility.Immutable,
 		},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := evalCtx.SessionData().GetDefaultTextSearchConfig()
+				document := string(tree.MustBeDString(args[0]))
+				vector, err := tsearch.DocumentToTSVector(config, document)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSVector{TSVector: vector}, nil
+			},
+			Info: "Converts text to a tsvector, normalizing words according to the default configuration, " +
+				"which is set by the default_text_search_config session variable. " +
+				"Position information is included in the result.",
+			Volatility: volatility.Stable,
+		},
 	),
 	"to_tsquery": makeBuiltin(
 		tree.FunctionProperties{},
@@ -127,6 +144,24 @@ var tsearchBuiltins = map[string]builtinDefinition{
 				"subsequent tokens must be connected by a tsquery operator (&, |, <->, !).",
 			Volatility: volatility.Immutable,
 		},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := evalCtx.SessionData().GetDefaultTextSearchConfig()
+				input := string(tree.MustBeDString(args[0]))
+				query, err := tsearch.ToTSQuery(config, input)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSQuery{TSQuery: query}, nil
+			},
+			Info: "Converts the input text into a tsquery by normalizing each word in the input according to " +
+				"the default configuration, which is set by the default_text_search_config session variable. " +
+				"The input must already be formatted like a tsquery, in other words, " +
+				"subsequent tokens must be connected by a tsquery operator (&, |, <->, !).",
+			Volatility: volatility.Stable,
+		},
 	),
 	"plainto_tsquery": makeBuiltin(
 		tree.FunctionProperties{},
@@ -146,6 +181,23 @@ var tsearchBuiltins = map[string]builtinDefinition{
 				" The & operator is inserted between each token in the input.",
 			Volatility: volatility.Immutable,
 		},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := evalCtx.SessionData().GetDefaultTextSearchConfig()
+				input := string(tree.MustBeDString(args[0]))
+				query, err := tsearch.PlainToTSQuery(config, input)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSQuery{TSQuery: query}, nil
+			},
+			Info: "Converts text to a tsquery, normalizing words according to the default configuration, " +
+				"which is set by the default_text_search_config session variable." +
+				" The & operator is inserted between each token in the input.",
+			Volatility: volatility.Stable,
+		},
 	),
 	"phraseto_tsquery": makeBuiltin(
 		tree.FunctionProperties{},
@@ -165,5 +217,286 @@ var tsearchBuiltins = map[string]builtinDefinition{
 				" The <-> operator is inserted between each token in the input.",
 			Volatility: volatility.Immutable,
 		},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := evalCtx.SessionData().GetDefaultTextSearchConfig()
+				input := string(tree.MustBeDString(args[0]))
+				query, err := tsearch.PhraseToTSQuery(config, input)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSQuery{TSQuery: query}, nil
+			},
+			Info: "Converts text to a tsquery, normalizing words according to the default configuration, " +
+				"which is set by the default_text_search_config session variable." +
+				" The <-> operator is inserted between each token in the input.",
+			Volatility: volatility.Stable,
+		},
+	),
+	"websearch_to_tsquery": makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "config", Typ: types.String}, {Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := string(tree.MustBeDString(args[0]))
+				input := string(tree.MustBeDString(args[1]))
+				query, err := tsearch.WebSearchToTSQuery(config, input)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSQuery{TSQuery: query}, nil
+			},
+			Info: "Converts text written in web search syntax to a tsquery, normalizing words according to " +
+				"the specified or default configuration. Unquoted words are combined with the & operator, " +
+				"quoted phrases with the <-> operator, the word or with the | operator, and words or phrases " +
+				"prefixed with - are negated with the ! operator.",
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "text", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				config := evalCtx.SessionData().GetDefaultTextSearchConfig()
+				input := string(tree.MustBeDString(args[0]))
+				query, err := tsearch.WebSearchToTSQuery(config, input)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSQuery{TSQuery: query}, nil
+			},
+			Info: "Converts text written in web search syntax to a tsquery, normalizing words according to " +
+				"the default configuration, which is set by the default_text_search_config session variable. " +
+				"Unquoted words are combined with the & operator, quoted phrases with the <-> operator, " +
+				"the word or with the | operator, and words or phrases prefixed with - are negated with " +
+				"the ! operator.",
+			Volatility: volatility.Stable,
+		},
+	),
+	"ts_rank": makeTSRankBuiltin(tsearch.Rank,
+		"Ranks vectors based on the frequency of their matching lexemes."),
+	"ts_rank_cd": makeTSRankBuiltin(tsearch.RankCD,
+		"Ranks vectors based on the cover density of the query in them, which takes the proximity "+
+			"of their matching lexemes into account."),
+	"ts_headline": makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "config", Typ: types.String},
+				{Name: "document", Typ: types.String},
+				{Name: "query", Typ: types.TSQuery},
+				{Name: "options", Typ: types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsHeadline(
+					string(tree.MustBeDString(args[0])), args[1], args[2], string(tree.MustBeDString(args[3])),
+				)
+			},
+			Info:       tsHeadlineInfo + " " + tsHeadlineOptionsInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "config", Typ: types.String},
+				{Name: "document", Typ: types.String},
+				{Name: "query", Typ: types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsHeadline(string(tree.MustBeDString(args[0])), args[1], args[2], "" /* options */)
+			},
+			Info:       tsHeadlineInfo,
+			Volatility: volatility.Immutable,
+			// Postgres takes the config as a regconfig, so this overload collides
+			// with its Stable (document, options, query) variant when comparing
+			// type families. With an explicit config we are Immutable, as is the
+			// regconfig variant in Postgres.
+			IgnoreVolatilityCheck: true,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "document", Typ: types.String},
+				{Name: "query", Typ: types.TSQuery},
+				{Name: "options", Typ: types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsHeadline(
+					evalCtx.SessionData().GetDefaultTextSearchConfig(), args[0], args[1], string(tree.MustBeDString(args[2])),
+				)
+			},
+			Info:       tsHeadlineDefaultConfigInfo + " " + tsHeadlineOptionsInfo,
+			Volatility: volatility.Stable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "document", Typ: types.String},
+				{Name: "query", Typ: types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsHeadline(evalCtx.SessionData().GetDefaultTextSearchConfig(), args[0], args[1], "" /* options */)
+			},
+			Info:       tsHeadlineDefaultConfigInfo,
+			Volatility: volatility.Stable,
+		},
 	),
+	"setweight": makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ParamTypes{{Name: "vector", Typ: types.TSVector}, {Name: "weight", Typ: types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				vector := tree.MustBeDTSVector(args[0]).TSVector
+				ret, err := vector.SetWeight(string(tree.MustBeDString(args[1])), nil /* lexemes */)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSVector{TSVector: ret}, nil
+			},
+			Info:       "Assigns the given weight (A, B, C or D) to each position of the input vector.",
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "vector", Typ: types.TSVector},
+				{Name: "weight", Typ: types.String},
+				{Name: "lexemes", Typ: types.StringArray},
+			},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				vector := tree.MustBeDTSVector(args[0]).TSVector
+				arr := tree.MustBeDArray(args[2])
+				lexemes := make([]string, len(arr.Array))
+				for i, d := range arr.Array {
+					if d == tree.DNull {
+						return nil, pgerror.New(pgcode.NullValueNotAllowed, "lexeme array may not contain nulls")
+					}
+					lexemes[i] = string(tree.MustBeDString(d))
+				}
+				ret, err := vector.SetWeight(string(tree.MustBeDString(args[1])), lexemes)
+				if err != nil {
+					return nil, err
+				}
+				return &tree.DTSVector{TSVector: ret}, nil
+			},
+			Info: "Assigns the given weight (A, B, C or D) to each position of the elements of the input " +
+				"vector that are listed in lexemes.",
+			Volatility: volatility.Immutable,
+		},
+	),
+}
+
+const tsHeadlineInfo = "Returns an excerpt of the document in which the words that match the query are " +
+	"highlighted, normalizing words according to the specified configuration."
+
+const tsHeadlineDefaultConfigInfo = "Returns an excerpt of the document in which the words that match the " +
+	"query are highlighted, normalizing words according to the default configuration, which is set by " +
+	"the default_text_search_config session variable."
+
+const tsHeadlineOptionsInfo = "The options are a comma-separated list of option=value pairs, " +
+	"where the options are StartSel, StopSel, MaxWords, MinWords, ShortWord, HighlightAll, " +
+	"MaxFragments and FragmentDelimiter."
+
+func tsHeadline(config string, document, query tree.Datum, options string) (tree.Datum, error) {
+	headline, err := tsearch.Headline(
+		config, string(tree.MustBeDString(document)), tree.MustBeDTSQuery(query).TSQuery, options,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDString(headline), nil
+}
+
+// makeTSRankBuiltin returns the overloads of a text search ranking function,
+// which optionally take an array of weights for the D, C, B and A weight
+// labels and a bitmask of normalization flags.
+func makeTSRankBuiltin(
+	rank func(weights []float32, v tsearch.TSVector, q tsearch.TSQuery, method int) (float32, error),
+	info string,
+) builtinDefinition {
+	weightsInfo := " The weights array contains the weights of the D, C, B and A labels, in that order."
+	normalizationInfo := " The normalization integer is a bitmask that controls how the rank is " +
+		"normalized by the length of the document."
+	fn := func(weights, vector, query, method tree.Datum) (tree.Datum, error) {
+		var w []float32
+		if weights != nil {
+			arr := tree.MustBeDArray(weights)
+			w = make([]float32, len(arr.Array))
+			for i, d := range arr.Array {
+				if d == tree.DNull {
+					return nil, pgerror.New(pgcode.NullValueNotAllowed, "array of weight must not contain nulls")
+				}
+				w[i] = float32(tree.MustBeDFloat(d))
+			}
+		}
+		var m int
+		if method != nil {
+			m = int(tree.MustBeDInt(method))
+		}
+		ret, err := rank(w, tree.MustBeDTSVector(vector).TSVector, tree.MustBeDTSQuery(query).TSQuery, m)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDFloat(tree.DFloat(ret)), nil
+	}
+	weightsType := types.MakeArray(types.Float4)
+	return makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "weights", Typ: weightsType},
+				{Name: "vector", Typ: types.TSVector},
+				{Name: "query", Typ: types.TSQuery},
+				{Name: "normalization", Typ: types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return fn(args[0], args[1], args[2], args[3])
+			},
+			Info:       info + weightsInfo + normalizationInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "weights", Typ: weightsType},
+				{Name: "vector", Typ: types.TSVector},
+				{Name: "query", Typ: types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return fn(args[0], args[1], args[2], nil /* method */)
+			},
+			Info:       info + weightsInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "vector", Typ: types.TSVector},
+				{Name: "query", Typ: types.TSQuery},
+				{Name: "normalization", Typ: types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return fn(nil /* weights */, args[0], args[1], args[2])
+			},
+			Info:       info + normalizationInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ParamTypes{
+				{Name: "vector", Typ: types.TSVector},
+				{Name: "query", Typ: types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return fn(nil /* weights */, args[0], args[1], nil /* method */)
+			},
+			Info:       info,
+			Volatility: volatility.Immutable,
+		},
+	)
 }
@@ -241,6 +241,32 @@ func LookupCast(src, tgt *types.T) (Cast, bool) {
 		}
 	}
 
+	// User-defined range and multirange types also have dynamic OIDs. Their
+	// casts to and from string types are automatic I/O conversions, with the
+	// volatility of the corresponding casts of the range subtype.
+	if (srcFamily == types.RangeFamily || srcFamily == types.MultirangeFamily) &&
+		src.UserDefined() && tgtFamily == types.StringFamily {
+		c, ok := LookupCast(src.RangeSubtype(), tgt)
+		if !ok {
+			c.Volatility = volatility.Stable
+		}
+		return Cast{
+			MaxContext: ContextAssignment,
+			Volatility: c.Volatility,
+		}, true
+	}
+	if (tgtFamily == types.RangeFamily || tgtFamily == types.MultirangeFamily) &&
+		tgt.UserDefined() && srcFamily == types.StringFamily {
+		c, ok := LookupCast(src, tgt.RangeSubtype())
+		if !ok {
+			c.Volatility = volatility.Stable
+		}
+		return Cast{
+			MaxContext: ContextExplicit,
+			Volatility: c.Volatility,
+		}, true
+	}
+
 	// Casts from array types to string types are stable and allowed in
 	// assignment contexts.
 	if srcFamily == types.ArrayFamily && tgtFamily == types.StringFamily {
@@ -92,6 +92,7 @@ const (
 	SpanStatsBuckets                       SystemTableName = "span_stats_buckets"
 	SpanStatsSamples                       SystemTableName = "span_stats_samples"
 	SpanStatsTenantBoundaries              SystemTableName = "span_stats_tenant_boundaries"
+	NotificationsTableName                 SystemTableName = "notifications"
 )
 
 // Oid for virtual database and table.
@@ -420,6 +421,8 @@ const (
 	ConstraintTypeCheck ConstraintType = "CHECK"
 	// ConstraintTypeUniqueWithoutIndex identifies a UNIQUE_WITHOUT_INDEX constraint.
 	ConstraintTypeUniqueWithoutIndex ConstraintType = "UNIQUE WITHOUT INDEX"
+	// ConstraintTypeExclusion identifies an EXCLUDE constraint.
+	ConstraintTypeExclusion ConstraintType = "EXCLUDE"
 )
 
 // SafeValue implements the redact.SafeValue interface.
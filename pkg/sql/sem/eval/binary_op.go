@@ -140,6 +140,12 @@ func (e *evaluator) EvalCompareBox2DOp(
 	return tree.MakeDBool(tree.DBool(op.Op(left, right))), nil
 }
 
+func (e *evaluator) EvalCompareRangeOp(
+	ctx context.Context, op *tree.CompareRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	return tree.MakeDBool(tree.DBool(op.Op(left, right))), nil
+}
+
 func (e *evaluator) EvalCompareScalarOp(
 	ctx context.Context, op *tree.CompareScalarOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -704,6 +710,22 @@ func (e *evaluator) EvalLShiftIntOp(
 	return tree.NewDInt(tree.MustBeDInt(left) << uint(rval)), nil
 }
 
+func (e *evaluator) EvalLShiftMultirangeOp(
+	ctx context.Context, _ *tree.LShiftMultirangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	return tree.MakeDBool(tree.DBool(
+		tree.MustBeDMultirange(left).StrictlyLeftOf(tree.MustBeDMultirange(right)),
+	)), nil
+}
+
+func (e *evaluator) EvalLShiftRangeOp(
+	ctx context.Context, _ *tree.LShiftRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	return tree.MakeDBool(tree.DBool(
+		tree.MustBeDRange(left).StrictlyLeftOf(tree.MustBeDRange(right)),
+	)), nil
+}
+
 func (e *evaluator) EvalLShiftVarBitIntOp(
 	ctx context.Context, _ *tree.LShiftVarBitIntOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -889,6 +911,26 @@ func (e *evaluator) EvalMinusJsonbStringOp(
 	return &tree.DJSON{JSON: j}, nil
 }
 
+func (e *evaluator) EvalMinusMultirangeOp(
+	ctx context.Context, _ *tree.MinusMultirangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDMultirange(left).Difference(tree.MustBeDMultirange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *evaluator) EvalMinusRangeOp(
+	ctx context.Context, _ *tree.MinusRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDRange(left).Difference(tree.MustBeDRange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (e *evaluator) EvalMinusTimeIntervalOp(
 	ctx context.Context, _ *tree.MinusTimeIntervalOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -1184,6 +1226,26 @@ func (e *evaluator) EvalMultIntervalIntOp(
 	}, nil
 }
 
+func (e *evaluator) EvalMultMultirangeOp(
+	ctx context.Context, _ *tree.MultMultirangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDMultirange(left).Intersect(tree.MustBeDMultirange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *evaluator) EvalMultRangeOp(
+	ctx context.Context, _ *tree.MultRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDRange(left).Intersect(tree.MustBeDRange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (e *evaluator) EvalOverlapsArrayOp(
 	ctx context.Context, _ *tree.OverlapsArrayOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -1393,6 +1455,26 @@ func (e *evaluator) EvalPlusIntervalTimestampTZOp(
 	return tree.MakeDTimestampTZ(t, time.Microsecond)
 }
 
+func (e *evaluator) EvalPlusMultirangeOp(
+	ctx context.Context, _ *tree.PlusMultirangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDMultirange(left).Union(tree.MustBeDMultirange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *evaluator) EvalPlusRangeOp(
+	ctx context.Context, _ *tree.PlusRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	res, err := tree.MustBeDRange(left).Union(tree.MustBeDRange(right))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (e *evaluator) EvalPlusTimeDateOp(
 	ctx context.Context, _ *tree.PlusTimeDateOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -1522,6 +1604,22 @@ func (e *evaluator) EvalRShiftIntOp(
 	return tree.NewDInt(tree.MustBeDInt(left) >> uint(rval)), nil
 }
 
+func (e *evaluator) EvalRShiftMultirangeOp(
+	ctx context.Context, _ *tree.RShiftMultirangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	return tree.MakeDBool(tree.DBool(
+		tree.MustBeDMultirange(left).StrictlyRightOf(tree.MustBeDMultirange(right)),
+	)), nil
+}
+
+func (e *evaluator) EvalRShiftRangeOp(
+	ctx context.Context, _ *tree.RShiftRangeOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	return tree.MakeDBool(tree.DBool(
+		tree.MustBeDRange(left).StrictlyRightOf(tree.MustBeDRange(right)),
+	)), nil
+}
+
 func (e *evaluator) EvalRShiftVarBitIntOp(
 	ctx context.Context, _ *tree.RShiftVarBitIntOp, left, right tree.Datum,
 ) (tree.Datum, error) {
@@ -448,7 +448,7 @@ func performCastWithoutPrecisionTruncation(
 				tree.FmtDataConversionConfig(evalCtx.SessionData().DataConversionConfig),
 				tree.FmtLocation(evalCtx.GetLocation()),
 			)
-		case *tree.DArray:
+		case *tree.DArray, *tree.DRange, *tree.DMultirange:
 			s = tree.AsStringWithFlags(
 				d,
 				tree.FmtPgwireText,
@@ -886,6 +886,28 @@ func performCastWithoutPrecisionTruncation(
 			}
 			return &tree.DTSVector{TSVector: vec}, nil
 		}
+	case types.RangeFamily:
+		if !evalCtx.Settings.Version.IsActive(ctx, clusterversion.V23_1RangeTypes) {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"version %v must be finalized to use range types",
+				clusterversion.ByKey(clusterversion.V23_1RangeTypes))
+		}
+		switch v := d.(type) {
+		case *tree.DString:
+			res, _, err := tree.ParseDRangeFromString(evalCtx, string(*v), t)
+			return res, err
+		}
+	case types.MultirangeFamily:
+		if !evalCtx.Settings.Version.IsActive(ctx, clusterversion.V23_1RangeTypes) {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"version %v must be finalized to use range types",
+				clusterversion.ByKey(clusterversion.V23_1RangeTypes))
+		}
+		switch v := d.(type) {
+		case *tree.DString:
+			res, _, err := tree.ParseDMultirangeFromString(evalCtx, string(*v), t)
+			return res, err
+		}
 	case types.ArrayFamily:
 		switch v := d.(type) {
 		case *tree.DString:
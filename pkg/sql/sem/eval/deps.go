@@ -335,6 +335,10 @@ type Planner interface {
 	// it is invalid.
 	RepairTTLScheduledJobForTable(ctx context.Context, tableID int64) error
 
+	// PublishNotification publishes a notification on the given channel in the
+	// current transaction, as done by NOTIFY and pg_notify().
+	PublishNotification(ctx context.Context, channel, payload string) error
+
 	// QueryRowEx executes the supplied SQL statement and returns a single row, or
 	// nil if no row is found, or an error if more that one row is returned.
 	//
@@ -505,6 +505,12 @@ func (e *evaluator) evalFuncArgs(
 	return false, args, nil
 }
 
+func (e *evaluator) EvalGroupingExpr(
+	ctx context.Context, expr *tree.GroupingExpr,
+) (tree.Datum, error) {
+	return nil, errors.AssertionFailedf("unhandled type %T", expr)
+}
+
 func (e *evaluator) EvalIfErrExpr(ctx context.Context, expr *tree.IfErrExpr) (tree.Datum, error) {
 	cond, evalErr := expr.Cond.(tree.TypedExpr).Eval(ctx, e)
 	if evalErr == nil {
@@ -0,0 +1,230 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package plpgsqltree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/types"
+
+// Program is a compiled PL/pgSQL function body. It is produced by the
+// optimizer, which resolves variable references and plans each SQL expression
+// and statement that is embedded in the function body. The program is executed
+// by an interpreter which evaluates those expressions and statements as
+// routines, passing the current values of all variables as arguments.
+type Program struct {
+	// Vars describes each variable of the program. The first NumParams
+	// variables are the parameters of the function. Variables declared in
+	// different blocks have different ordinals, even if they have the same
+	// name.
+	Vars      []ProgramVar
+	NumParams int
+
+	// Found is the ordinal of the special FOUND variable, which is set by
+	// SQL statements and FOR loops to indicate whether they affected or
+	// produced any rows.
+	Found VarOrdinal
+
+	// SetReturning is true if the function returns SETOF a type, in which case
+	// the result of the function is built with RETURN NEXT and RETURN QUERY.
+	SetReturning bool
+
+	// Body is the outermost block of the function body.
+	Body *ProgBlock
+}
+
+// ProgramVar describes a variable of a Program.
+type ProgramVar struct {
+	Name    string
+	Typ     *types.T
+	NotNull bool
+
+	// Fields is set for variables of a composite type. It contains a variable
+	// for each field of the type, which allows fields to be referenced as
+	// "var.field" in SQL expressions. The field variables are assigned
+	// whenever the variable is assigned.
+	Fields []VarOrdinal
+}
+
+// VarOrdinal identifies a variable by its index in Program.Vars.
+type VarOrdinal int32
+
+// NoVar indicates the absence of a variable.
+const NoVar VarOrdinal = -1
+
+// ExprOrdinal identifies a SQL expression or statement that is evaluated by a
+// Program. Expressions are numbered in the order that they are compiled.
+type ExprOrdinal int32
+
+// NoExpr indicates the absence of an expression.
+const NoExpr ExprOrdinal = -1
+
+// ProgStmt is a compiled PL/pgSQL statement.
+type ProgStmt interface {
+	progStmt()
+}
+
+// ProgBlock is a compiled Block. Its variables are initialized in order
+// before the statements of its body are executed. If a handler matches an
+// error raised in the body, all changes made to the database by the body are
+// rolled back before the handler is executed.
+type ProgBlock struct {
+	Label    string
+	Inits    []ProgVarInit
+	Body     []ProgStmt
+	Handlers []ProgHandler
+}
+
+// ProgVarInit initializes a variable. If Expr is NoExpr, the variable is
+// initialized to NULL.
+type ProgVarInit struct {
+	Var  VarOrdinal
+	Expr ExprOrdinal
+}
+
+// ProgHandler is a compiled exception handler. SQLState and SQLErrM are the
+// variables which hold the code and message of the error while the handler is
+// executed.
+type ProgHandler struct {
+	Codes    []ProgCondition
+	SQLState VarOrdinal
+	SQLErrM  VarOrdinal
+	Body     []ProgStmt
+}
+
+// ProgCondition matches an error code. If Others is true, any error is
+// matched, except for query cancellation. Otherwise, if Code is a class code,
+// such as "22000", any error in that class is matched.
+type ProgCondition struct {
+	Code   string
+	Others bool
+}
+
+// ProgAssign assigns the result of Expr to Var.
+type ProgAssign struct {
+	Var  VarOrdinal
+	Expr ExprOrdinal
+}
+
+// ProgIf executes the body of the first condition which evaluates to true, or
+// Else if none do.
+type ProgIf struct {
+	Conds  []ExprOrdinal
+	Bodies [][]ProgStmt
+	Else   []ProgStmt
+}
+
+// ProgLoop is a compiled LOOP or WHILE statement. If Cond is not NoExpr, the
+// loop is exited before an iteration if it does not evaluate to true.
+type ProgLoop struct {
+	Label string
+	Cond  ExprOrdinal
+	Body  []ProgStmt
+}
+
+// ProgForInt is a compiled FOR loop over a range of integers.
+type ProgForInt struct {
+	Label   string
+	Var     VarOrdinal
+	Lower   ExprOrdinal
+	Upper   ExprOrdinal
+	Step    ExprOrdinal
+	Reverse bool
+	Body    []ProgStmt
+}
+
+// ProgForQuery is a compiled FOR loop over the rows of a query. Each row of
+// Query has a single column. If there is one target variable, the column is
+// assigned to it. Otherwise, the column is a tuple with one element for each
+// target variable.
+type ProgForQuery struct {
+	Label   string
+	Query   ExprOrdinal
+	Targets []VarOrdinal
+	Body    []ProgStmt
+}
+
+// ProgExit is a compiled EXIT or CONTINUE statement. The label is empty if it
+// applies to the innermost loop.
+type ProgExit struct {
+	Label    string
+	Cond     ExprOrdinal
+	Continue bool
+}
+
+// ProgReturn returns from the function. Expr is NoExpr if there is no return
+// value.
+type ProgReturn struct {
+	Expr ExprOrdinal
+}
+
+// ProgReturnNext adds the result of Expr as a row to the result of a
+// set-returning function.
+type ProgReturnNext struct {
+	Expr ExprOrdinal
+}
+
+// ProgReturnQuery adds the rows of Query to the result of a set-returning
+// function.
+type ProgReturnQuery struct {
+	Query ExprOrdinal
+}
+
+// ProgRaise is a compiled RAISE statement. If Rethrow is true, the error being
+// handled by the enclosing exception handler is raised again and the other
+// fields are unset.
+type ProgRaise struct {
+	// Severity is the upper case severity of the message, e.g. "NOTICE".
+	Severity string
+	Code     string
+	Message  string
+	Params   []ExprOrdinal
+	Options  []ProgRaiseOption
+	Rethrow  bool
+}
+
+// ProgRaiseOption is a compiled USING option of a RAISE statement. The result
+// of Expr is a string.
+type ProgRaiseOption struct {
+	OptType string
+	Expr    ExprOrdinal
+}
+
+// ProgExec executes a SQL statement. If there are target variables, the first
+// row of the result of the statement is assigned to them in the same way as
+// ProgForQuery assigns rows. If Strict is true, the statement must produce
+// exactly one row.
+//
+// If there are no targets and the statement produces rows, it produces at
+// most one row, which indicates that the original statement found at least
+// one row.
+type ProgExec struct {
+	Stmt    ExprOrdinal
+	Targets []VarOrdinal
+	Strict  bool
+}
+
+// ProgGetDiagnostics assigns the number of rows processed by the last SQL
+// statement to Var.
+type ProgGetDiagnostics struct {
+	Var VarOrdinal
+}
+
+func (*ProgBlock) progStmt()          {}
+func (*ProgAssign) progStmt()         {}
+func (*ProgIf) progStmt()             {}
+func (*ProgLoop) progStmt()           {}
+func (*ProgForInt) progStmt()         {}
+func (*ProgForQuery) progStmt()       {}
+func (*ProgExit) progStmt()           {}
+func (*ProgReturn) progStmt()         {}
+func (*ProgReturnNext) progStmt()     {}
+func (*ProgReturnQuery) progStmt()    {}
+func (*ProgRaise) progStmt()          {}
+func (*ProgExec) progStmt()           {}
+func (*ProgGetDiagnostics) progStmt() {}
@@ -0,0 +1,682 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package plpgsqltree
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Expr is a SQL expression which is embedded in a PL/pgSQL statement, e.g. the
+// condition of an IF statement.
+type Expr = tree.Expr
+
+// Variable is the name of a PL/pgSQL variable.
+type Variable = tree.Name
+
+// Statement is a PL/pgSQL statement.
+type Statement interface {
+	tree.NodeFormatter
+	plpgsqlStmt()
+
+	// StatementTag is a short string identifying the type of statement, which
+	// is used in error messages.
+	StatementTag() string
+}
+
+// Block is a PL/pgSQL block, which may declare variables and handle errors
+// raised by the statements in its body:
+//
+//	[ <<label>> ]
+//	[ DECLARE declarations ]
+//	BEGIN
+//	  statements
+//	[ EXCEPTION
+//	  WHEN condition [ OR condition ... ] THEN handler_statements
+//	  ... ]
+//	END [ label ];
+//
+// The body of a PL/pgSQL function is a single block.
+type Block struct {
+	Label      string
+	Decls      []Declaration
+	Body       []Statement
+	Exceptions []Exception
+}
+
+// Declaration declares a variable in a block.
+type Declaration struct {
+	Var      Variable
+	Constant bool
+	Typ      tree.ResolvableTypeReference
+	NotNull  bool
+	// Expr is the initial value of the variable. It is nil if there is no
+	// DEFAULT or := clause, in which case the variable is initialized to NULL.
+	Expr Expr
+}
+
+// Exception is a handler for errors raised within a block.
+type Exception struct {
+	Conditions []Condition
+	Action     []Statement
+}
+
+// Condition matches errors by their SQLSTATE code. Name is set if the
+// condition was specified by its name, e.g. division_by_zero, and SQLState is
+// set if it was specified with SQLSTATE 'xxxxx'. The special condition name
+// OTHERS matches any error other than query cancellation.
+type Condition struct {
+	Name     string
+	SQLState string
+}
+
+// Assignment assigns the value of an expression to a variable:
+//
+//	var := expr;
+type Assignment struct {
+	Var   Variable
+	Value Expr
+}
+
+// If executes the statements of the first branch whose condition evaluates to
+// true, or the ELSE branch if there is none:
+//
+//	IF condition THEN statements
+//	[ ELSIF condition THEN statements ... ]
+//	[ ELSE statements ]
+//	END IF;
+type If struct {
+	Condition  Expr
+	ThenBody   []Statement
+	ElseIfList []ElseIf
+	ElseBody   []Statement
+}
+
+// ElseIf is an ELSIF branch of an IF statement.
+type ElseIf struct {
+	Condition Expr
+	Stmts     []Statement
+}
+
+// Loop executes its body until it is exited with EXIT or RETURN:
+//
+//	[ <<label>> ]
+//	LOOP
+//	  statements
+//	END LOOP [ label ];
+type Loop struct {
+	Label string
+	Body  []Statement
+}
+
+// While executes its body as long as its condition evaluates to true:
+//
+//	[ <<label>> ]
+//	WHILE condition LOOP
+//	  statements
+//	END LOOP [ label ];
+type While struct {
+	Label     string
+	Condition Expr
+	Body      []Statement
+}
+
+// ForInt iterates over a range of integers:
+//
+//	[ <<label>> ]
+//	FOR name IN [ REVERSE ] expression .. expression [ BY expression ] LOOP
+//	  statements
+//	END LOOP [ label ];
+//
+// The loop variable is implicitly declared as an integer which is only visible
+// within the body of the loop.
+type ForInt struct {
+	Label   string
+	Target  Variable
+	Reverse bool
+	Lower   Expr
+	Upper   Expr
+	// Step is nil if there is no BY clause, in which case the step is 1.
+	Step Expr
+	Body []Statement
+}
+
+// ForQuery iterates over the rows of a query:
+//
+//	[ <<label>> ]
+//	FOR target IN query LOOP
+//	  statements
+//	END LOOP [ label ];
+//
+// The target is either a single record variable or a list of variables, one
+// for each column of the query.
+type ForQuery struct {
+	Label  string
+	Target []Variable
+	Query  tree.Statement
+	Body   []Statement
+}
+
+// Exit exits the innermost loop or the loop or block with the given label. If
+// there is a condition, the exit only happens if it evaluates to true:
+//
+//	EXIT [ label ] [ WHEN condition ];
+type Exit struct {
+	Label     string
+	Condition Expr
+}
+
+// Continue begins the next iteration of the innermost loop or the loop with the
+// given label. If there is a condition, the next iteration is only begun if it
+// evaluates to true:
+//
+//	CONTINUE [ label ] [ WHEN condition ];
+type Continue struct {
+	Label     string
+	Condition Expr
+}
+
+// Return returns from the function. Expr is nil if no value is returned, which
+// is only allowed for functions which return VOID or SETOF.
+//
+//	RETURN [ expression ];
+type Return struct {
+	Expr Expr
+}
+
+// ReturnNext adds a row to the result of a set-returning function:
+//
+//	RETURN NEXT expression;
+type ReturnNext struct {
+	Expr Expr
+}
+
+// ReturnQuery adds the rows of a query to the result of a set-returning
+// function:
+//
+//	RETURN QUERY query;
+type ReturnQuery struct {
+	Query tree.Statement
+}
+
+// Raise reports a message or raises an error:
+//
+//	RAISE [ level ] 'format' [, expression [, ... ]] [ USING option = expression [, ... ] ];
+//	RAISE [ level ] condition_name [ USING option = expression [, ... ] ];
+//	RAISE [ level ] SQLSTATE 'sqlstate' [ USING option = expression [, ... ] ];
+//	RAISE [ level ] USING option = expression [, ... ];
+//	RAISE;
+//
+// A RAISE statement with no arguments re-raises the error which is being
+// handled by the enclosing exception handler.
+type Raise struct {
+	// LogLevel is the lower case severity of the message, e.g. "notice" or
+	// "exception". It is empty if no level was specified, in which case the
+	// severity is EXCEPTION.
+	LogLevel string
+	// CodeName is set if the error was specified by a condition name.
+	CodeName string
+	// Code is set if the error was specified with SQLSTATE 'xxxxx'.
+	Code string
+	// Message is the format string of the message. Each % character in the
+	// format string is replaced with the value of the next parameter.
+	Message string
+	Params  []Expr
+	Options []RaiseOption
+}
+
+// RaiseOption is a USING option of a RAISE statement.
+type RaiseOption struct {
+	// OptType is the lower case name of the option, e.g. "hint".
+	OptType string
+	Expr    Expr
+}
+
+// Perform evaluates a query and discards its result:
+//
+//	PERFORM query;
+//
+// The query is written as a SELECT statement with PERFORM in place of SELECT.
+type Perform struct {
+	Query tree.Statement
+}
+
+// Execute executes a SQL statement. If the statement has an INTO clause, the
+// first row of its result is assigned to the target variables:
+//
+//	statement [ INTO [ STRICT ] target ];
+//
+// If STRICT is specified, the statement must return exactly one row.
+type Execute struct {
+	SQLStmt tree.Statement
+	Strict  bool
+	Target  []Variable
+}
+
+// GetDiagnostics assigns information about the last executed SQL statement to
+// variables:
+//
+//	GET [ CURRENT ] DIAGNOSTICS variable { = | := } item [ , ... ];
+type GetDiagnostics struct {
+	Items []GetDiagnosticsItem
+}
+
+// GetDiagnosticsItem is an assignment of a GET DIAGNOSTICS statement. Kind is
+// the lower case name of the item, e.g. "row_count".
+type GetDiagnosticsItem struct {
+	Target Variable
+	Kind   string
+}
+
+// Null does nothing.
+//
+//	NULL;
+type Null struct{}
+
+func (*Block) plpgsqlStmt()          {}
+func (*Assignment) plpgsqlStmt()     {}
+func (*If) plpgsqlStmt()             {}
+func (*Loop) plpgsqlStmt()           {}
+func (*While) plpgsqlStmt()          {}
+func (*ForInt) plpgsqlStmt()         {}
+func (*ForQuery) plpgsqlStmt()       {}
+func (*Exit) plpgsqlStmt()           {}
+func (*Continue) plpgsqlStmt()       {}
+func (*Return) plpgsqlStmt()         {}
+func (*ReturnNext) plpgsqlStmt()     {}
+func (*ReturnQuery) plpgsqlStmt()    {}
+func (*Raise) plpgsqlStmt()          {}
+func (*Perform) plpgsqlStmt()        {}
+func (*Execute) plpgsqlStmt()        {}
+func (*GetDiagnostics) plpgsqlStmt() {}
+func (*Null) plpgsqlStmt()           {}
+
+// StatementTag implements the Statement interface.
+func (*Block) StatementTag() string { return "statement block" }
+
+// StatementTag implements the Statement interface.
+func (*Assignment) StatementTag() string { return "assignment" }
+
+// StatementTag implements the Statement interface.
+func (*If) StatementTag() string { return "IF" }
+
+// StatementTag implements the Statement interface.
+func (*Loop) StatementTag() string { return "LOOP" }
+
+// StatementTag implements the Statement interface.
+func (*While) StatementTag() string { return "WHILE" }
+
+// StatementTag implements the Statement interface.
+func (*ForInt) StatementTag() string { return "FOR with integer loop variable" }
+
+// StatementTag implements the Statement interface.
+func (*ForQuery) StatementTag() string { return "FOR over SELECT rows" }
+
+// StatementTag implements the Statement interface.
+func (*Exit) StatementTag() string { return "EXIT" }
+
+// StatementTag implements the Statement interface.
+func (*Continue) StatementTag() string { return "CONTINUE" }
+
+// StatementTag implements the Statement interface.
+func (*Return) StatementTag() string { return "RETURN" }
+
+// StatementTag implements the Statement interface.
+func (*ReturnNext) StatementTag() string { return "RETURN NEXT" }
+
+// StatementTag implements the Statement interface.
+func (*ReturnQuery) StatementTag() string { return "RETURN QUERY" }
+
+// StatementTag implements the Statement interface.
+func (*Raise) StatementTag() string { return "RAISE" }
+
+// StatementTag implements the Statement interface.
+func (*Perform) StatementTag() string { return "PERFORM" }
+
+// StatementTag implements the Statement interface.
+func (*Execute) StatementTag() string { return "SQL statement" }
+
+// StatementTag implements the Statement interface.
+func (*GetDiagnostics) StatementTag() string { return "GET DIAGNOSTICS" }
+
+// StatementTag implements the Statement interface.
+func (*Null) StatementTag() string { return "NULL" }
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Block) Format(ctx *tree.FmtCtx) {
+	formatLabel(ctx, s.Label)
+	if len(s.Decls) > 0 {
+		ctx.WriteString("DECLARE\n")
+		for i := range s.Decls {
+			ctx.FormatNode(&s.Decls[i])
+			ctx.WriteString("\n")
+		}
+	}
+	ctx.WriteString("BEGIN\n")
+	formatStmts(ctx, s.Body)
+	if len(s.Exceptions) > 0 {
+		ctx.WriteString("EXCEPTION\n")
+		for i := range s.Exceptions {
+			ctx.FormatNode(&s.Exceptions[i])
+		}
+	}
+	ctx.WriteString("END")
+	if s.Label != "" {
+		ctx.WriteByte(' ')
+		ctx.FormatName(s.Label)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (d *Declaration) Format(ctx *tree.FmtCtx) {
+	ctx.FormatNode(&d.Var)
+	if d.Constant {
+		ctx.WriteString(" CONSTANT")
+	}
+	ctx.WriteByte(' ')
+	ctx.FormatTypeReference(d.Typ)
+	if d.NotNull {
+		ctx.WriteString(" NOT NULL")
+	}
+	if d.Expr != nil {
+		ctx.WriteString(" := ")
+		ctx.FormatNode(d.Expr)
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (e *Exception) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("WHEN ")
+	for i := range e.Conditions {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.FormatNode(&e.Conditions[i])
+	}
+	ctx.WriteString(" THEN\n")
+	formatStmts(ctx, e.Action)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (c *Condition) Format(ctx *tree.FmtCtx) {
+	if c.SQLState != "" {
+		ctx.WriteString("SQLSTATE ")
+		lexFormatString(ctx, c.SQLState)
+		return
+	}
+	ctx.WriteString(c.Name)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Assignment) Format(ctx *tree.FmtCtx) {
+	ctx.FormatNode(&s.Var)
+	ctx.WriteString(" := ")
+	ctx.FormatNode(s.Value)
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *If) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("IF ")
+	ctx.FormatNode(s.Condition)
+	ctx.WriteString(" THEN\n")
+	formatStmts(ctx, s.ThenBody)
+	for i := range s.ElseIfList {
+		ctx.WriteString("ELSIF ")
+		ctx.FormatNode(s.ElseIfList[i].Condition)
+		ctx.WriteString(" THEN\n")
+		formatStmts(ctx, s.ElseIfList[i].Stmts)
+	}
+	if len(s.ElseBody) > 0 {
+		ctx.WriteString("ELSE\n")
+		formatStmts(ctx, s.ElseBody)
+	}
+	ctx.WriteString("END IF;")
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Loop) Format(ctx *tree.FmtCtx) {
+	formatLabel(ctx, s.Label)
+	ctx.WriteString("LOOP\n")
+	formatStmts(ctx, s.Body)
+	formatEndLoop(ctx, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *While) Format(ctx *tree.FmtCtx) {
+	formatLabel(ctx, s.Label)
+	ctx.WriteString("WHILE ")
+	ctx.FormatNode(s.Condition)
+	ctx.WriteString(" LOOP\n")
+	formatStmts(ctx, s.Body)
+	formatEndLoop(ctx, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ForInt) Format(ctx *tree.FmtCtx) {
+	formatLabel(ctx, s.Label)
+	ctx.WriteString("FOR ")
+	ctx.FormatNode(&s.Target)
+	ctx.WriteString(" IN ")
+	if s.Reverse {
+		ctx.WriteString("REVERSE ")
+	}
+	ctx.FormatNode(s.Lower)
+	ctx.WriteString("..")
+	ctx.FormatNode(s.Upper)
+	if s.Step != nil {
+		ctx.WriteString(" BY ")
+		ctx.FormatNode(s.Step)
+	}
+	ctx.WriteString(" LOOP\n")
+	formatStmts(ctx, s.Body)
+	formatEndLoop(ctx, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ForQuery) Format(ctx *tree.FmtCtx) {
+	formatLabel(ctx, s.Label)
+	ctx.WriteString("FOR ")
+	formatTarget(ctx, s.Target)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(s.Query)
+	ctx.WriteString(" LOOP\n")
+	formatStmts(ctx, s.Body)
+	formatEndLoop(ctx, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Exit) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("EXIT")
+	formatLoopControl(ctx, s.Label, s.Condition)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Continue) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("CONTINUE")
+	formatLoopControl(ctx, s.Label, s.Condition)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Return) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("RETURN")
+	if s.Expr != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(s.Expr)
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ReturnNext) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("RETURN NEXT")
+	if s.Expr != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(s.Expr)
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ReturnQuery) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("RETURN QUERY ")
+	ctx.FormatNode(s.Query)
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Raise) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("RAISE")
+	if s.LogLevel != "" {
+		ctx.WriteByte(' ')
+		ctx.WriteString(strings.ToUpper(s.LogLevel))
+	}
+	switch {
+	case s.CodeName != "":
+		ctx.WriteByte(' ')
+		ctx.WriteString(s.CodeName)
+	case s.Code != "":
+		ctx.WriteString(" SQLSTATE ")
+		lexFormatString(ctx, s.Code)
+	case s.Message != "" || len(s.Params) > 0:
+		ctx.WriteByte(' ')
+		lexFormatString(ctx, s.Message)
+		for _, p := range s.Params {
+			ctx.WriteString(", ")
+			ctx.FormatNode(p)
+		}
+	}
+	for i := range s.Options {
+		if i == 0 {
+			ctx.WriteString(" USING ")
+		} else {
+			ctx.WriteString(", ")
+		}
+		ctx.WriteString(strings.ToUpper(s.Options[i].OptType))
+		ctx.WriteString(" = ")
+		ctx.FormatNode(s.Options[i].Expr)
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Perform) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("PERFORM ")
+	if sel, ok := s.Query.(*tree.Select); ok && sel.With == nil && sel.OrderBy == nil &&
+		sel.Limit == nil && sel.Locking == nil {
+		if clause, ok := sel.Select.(*tree.SelectClause); ok && !clause.Distinct &&
+			clause.DistinctOn == nil && clause.GroupBy == nil && clause.Having == nil &&
+			clause.Window == nil {
+			// The SELECT keyword is replaced by PERFORM.
+			ctx.FormatNode(&clause.Exprs)
+			if len(clause.From.Tables) > 0 {
+				ctx.WriteByte(' ')
+				ctx.FormatNode(&clause.From)
+			}
+			if clause.Where != nil {
+				ctx.WriteByte(' ')
+				ctx.FormatNode(clause.Where)
+			}
+			ctx.WriteByte(';')
+			return
+		}
+	}
+	ctx.WriteString("* FROM (")
+	ctx.FormatNode(s.Query)
+	ctx.WriteString(") AS perform;")
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Execute) Format(ctx *tree.FmtCtx) {
+	ctx.FormatNode(s.SQLStmt)
+	if len(s.Target) > 0 {
+		ctx.WriteString(" INTO ")
+		if s.Strict {
+			ctx.WriteString("STRICT ")
+		}
+		formatTarget(ctx, s.Target)
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *GetDiagnostics) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("GET DIAGNOSTICS ")
+	for i := range s.Items {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&s.Items[i].Target)
+		ctx.WriteString(" := ")
+		ctx.WriteString(strings.ToUpper(s.Items[i].Kind))
+	}
+	ctx.WriteByte(';')
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Null) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("NULL;")
+}
+
+func formatStmts(ctx *tree.FmtCtx, stmts []Statement) {
+	for _, stmt := range stmts {
+		ctx.FormatNode(stmt)
+		if _, ok := stmt.(*Block); ok {
+			// Unlike the outermost block, nested blocks end with a semicolon.
+			ctx.WriteByte(';')
+		}
+		ctx.WriteString("\n")
+	}
+}
+
+func formatLabel(ctx *tree.FmtCtx, label string) {
+	if label != "" {
+		ctx.WriteString("<<")
+		ctx.FormatName(label)
+		ctx.WriteString(">>\n")
+	}
+}
+
+func formatEndLoop(ctx *tree.FmtCtx, label string) {
+	ctx.WriteString("END LOOP")
+	if label != "" {
+		ctx.WriteByte(' ')
+		ctx.FormatName(label)
+	}
+	ctx.WriteByte(';')
+}
+
+func formatLoopControl(ctx *tree.FmtCtx, label string, cond Expr) {
+	if label != "" {
+		ctx.WriteByte(' ')
+		ctx.FormatName(label)
+	}
+	if cond != nil {
+		ctx.WriteString(" WHEN ")
+		ctx.FormatNode(cond)
+	}
+	ctx.WriteByte(';')
+}
+
+func formatTarget(ctx *tree.FmtCtx, target []Variable) {
+	for i := range target {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&target[i])
+	}
+}
+
+func lexFormatString(ctx *tree.FmtCtx, s string) {
+	ctx.FormatNode(tree.NewStrVal(s))
+}
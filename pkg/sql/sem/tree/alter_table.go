@@ -76,6 +76,7 @@ func (*AlterTablePartitionByTable) alterTableCmd()   {}
 func (*AlterTableInjectStats) alterTableCmd()        {}
 func (*AlterTableSetStorageParams) alterTableCmd()   {}
 func (*AlterTableResetStorageParams) alterTableCmd() {}
+func (*AlterTableInherit) alterTableCmd()            {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
@@ -96,6 +97,7 @@ var _ AlterTableCmd = &AlterTablePartitionByTable{}
 var _ AlterTableCmd = &AlterTableInjectStats{}
 var _ AlterTableCmd = &AlterTableSetStorageParams{}
 var _ AlterTableCmd = &AlterTableResetStorageParams{}
+var _ AlterTableCmd = &AlterTableInherit{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
 // existing column.
@@ -644,6 +646,30 @@ func (node *AlterTableResetStorageParams) Format(ctx *FmtCtx) {
 	ctx.WriteString(")")
 }
 
+// AlterTableInherit represents an ALTER TABLE INHERIT or ALTER TABLE NO
+// INHERIT command.
+type AlterTableInherit struct {
+	Parent    TableName
+	NoInherit bool
+}
+
+// TelemetryName implements the AlterTableCmd interface.
+func (node *AlterTableInherit) TelemetryName() string {
+	if node.NoInherit {
+		return "no_inherit"
+	}
+	return "inherit"
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableInherit) Format(ctx *FmtCtx) {
+	if node.NoInherit {
+		ctx.WriteString(" NO")
+	}
+	ctx.WriteString(" INHERIT ")
+	ctx.FormatNode(&node.Parent)
+}
+
 // AlterTableLocality represents an ALTER TABLE LOCALITY command.
 type AlterTableLocality struct {
 	Name     *UnresolvedObjectName
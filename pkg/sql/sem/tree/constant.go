@@ -547,10 +547,36 @@ var (
 		types.VarBit,
 		types.AnyEnum,
 		types.AnyEnumArray,
+		types.AnyRange,
+		types.AnyMultirange,
 		types.INetArray,
 		types.VarBitArray,
 		types.AnyTuple,
 		types.AnyTupleArray,
+		types.Int4Range,
+		types.Int8Range,
+		types.NumRange,
+		types.TSRange,
+		types.TSTZRange,
+		types.DateRange,
+		types.Int4Multirange,
+		types.Int8Multirange,
+		types.NumMultirange,
+		types.TSMultirange,
+		types.TSTZMultirange,
+		types.DateMultirange,
+		types.MakeArray(types.Int4Range),
+		types.MakeArray(types.Int8Range),
+		types.MakeArray(types.NumRange),
+		types.MakeArray(types.TSRange),
+		types.MakeArray(types.TSTZRange),
+		types.MakeArray(types.DateRange),
+		types.MakeArray(types.Int4Multirange),
+		types.MakeArray(types.Int8Multirange),
+		types.MakeArray(types.NumMultirange),
+		types.MakeArray(types.TSMultirange),
+		types.MakeArray(types.TSTZMultirange),
+		types.MakeArray(types.DateMultirange),
 	}
 	// StrValAvailBytes is the set of types convertible to byte array.
 	StrValAvailBytes = []*types.T{types.Bytes, types.Uuid, types.String, types.AnyEnum}
@@ -127,3 +127,67 @@ func (x CompositeKeyMatchMethod) String() string {
 		return strconv.Itoa(int(x))
 	}
 }
+
+// ConstraintDeferrability describes whether the checking of a constraint can
+// be deferred until the end of the transaction.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	// ConstraintNotDeferrable constraints are checked at the end of every statement.
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	// DeferrableInitiallyImmediate constraints are checked at the end of every
+	// statement, unless SET CONSTRAINTS ... DEFERRED was used in the
+	// transaction.
+	DeferrableInitiallyImmediate
+	// DeferrableInitiallyDeferred constraints are checked at the end of the
+	// transaction, unless SET CONSTRAINTS ... IMMEDIATE was used in the
+	// transaction.
+	DeferrableInitiallyDeferred
+)
+
+// MakeConstraintDeferrability returns the ConstraintDeferrability for the
+// given attributes of a constraint.
+func MakeConstraintDeferrability(deferrable, initiallyDeferred bool) ConstraintDeferrability {
+	switch {
+	case initiallyDeferred:
+		return DeferrableInitiallyDeferred
+	case deferrable:
+		return DeferrableInitiallyImmediate
+	default:
+		return ConstraintNotDeferrable
+	}
+}
+
+// IsDeferrable returns true if the constraint was declared DEFERRABLE.
+func (x ConstraintDeferrability) IsDeferrable() bool {
+	return x != ConstraintNotDeferrable
+}
+
+// IsInitiallyDeferred returns true if the constraint was declared INITIALLY
+// DEFERRED.
+func (x ConstraintDeferrability) IsInitiallyDeferred() bool {
+	return x == DeferrableInitiallyDeferred
+}
+
+// Format implements the NodeFormatter interface.
+func (x *ConstraintDeferrability) Format(ctx *FmtCtx) {
+	if x.IsDeferrable() {
+		ctx.WriteByte(' ')
+		ctx.WriteString(x.String())
+	}
+}
+
+// String implements the fmt.Stringer interface.
+func (x ConstraintDeferrability) String() string {
+	switch x {
+	case ConstraintNotDeferrable:
+		return "NOT DEFERRABLE"
+	case DeferrableInitiallyImmediate:
+		return "DEFERRABLE"
+	case DeferrableInitiallyDeferred:
+		return "DEFERRABLE INITIALLY DEFERRED"
+	default:
+		return strconv.Itoa(int(x))
+	}
+}
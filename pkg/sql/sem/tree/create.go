@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/collatedstring"
 	"github.com/cockroachdb/cockroach/pkg/util/pretty"
@@ -352,6 +353,13 @@ type CompositeTypeElem struct {
 	Type  ResolvableTypeReference
 }
 
+// RangeTypeParam is a single parameter in a range type definition, such as
+// SUBTYPE = float8.
+type RangeTypeParam struct {
+	Name  Name
+	Value ResolvableTypeReference
+}
+
 // CreateType represents a CREATE TYPE statement.
 type CreateType struct {
 	TypeName *UnresolvedObjectName
@@ -361,6 +369,9 @@ type CreateType struct {
 	// CompositeTypeList is set when this repesnets a CREATE TYPE ... AS ( )
 	// statement.
 	CompositeTypeList []CompositeTypeElem
+	// RangeParams is set when this represents a CREATE TYPE ... AS RANGE
+	// statement.
+	RangeParams []RangeTypeParam
 	// IfNotExists is true if IF NOT EXISTS was requested.
 	IfNotExists bool
 }
@@ -392,6 +403,18 @@ func (node *CreateType) Format(ctx *FmtCtx) {
 			ctx.FormatTypeReference(elem.Type)
 		}
 		ctx.WriteString(")")
+	case Range:
+		ctx.WriteString("AS RANGE (")
+		for i := range node.RangeParams {
+			param := &node.RangeParams[i]
+			if i != 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(&param.Name)
+			ctx.WriteString(" = ")
+			ctx.FormatTypeReference(param.Value)
+		}
+		ctx.WriteString(")")
 	}
 }
 
@@ -399,6 +422,64 @@ func (node *CreateType) String() string {
 	return AsString(node)
 }
 
+// CreateDomain represents a CREATE DOMAIN statement.
+type CreateDomain struct {
+	TypeName    *UnresolvedObjectName
+	BaseType    ResolvableTypeReference
+	Constraints []DomainConstraint
+}
+
+var _ Statement = &CreateDomain{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateDomain) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE DOMAIN ")
+	ctx.FormatNode(node.TypeName)
+	ctx.WriteString(" AS ")
+	ctx.FormatTypeReference(node.BaseType)
+	for i := range node.Constraints {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.Constraints[i])
+	}
+}
+
+func (node *CreateDomain) String() string {
+	return AsString(node)
+}
+
+// DomainConstraint represents a single DEFAULT, NOT NULL, NULL or CHECK
+// clause of a CREATE DOMAIN statement. Exactly one of the clauses is set.
+type DomainConstraint struct {
+	// Name is the name of the constraint, if specified with CONSTRAINT.
+	Name    Name
+	Default Expr
+	NotNull bool
+	Null    bool
+	Check   Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DomainConstraint) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	switch {
+	case node.Default != nil:
+		ctx.WriteString("DEFAULT ")
+		ctx.FormatNode(node.Default)
+	case node.NotNull:
+		ctx.WriteString("NOT NULL")
+	case node.Null:
+		ctx.WriteString("NULL")
+	case node.Check != nil:
+		ctx.WriteString("CHECK (")
+		ctx.FormatNode(node.Check)
+		ctx.WriteByte(')')
+	}
+}
+
 // TableDef represents a column, index or constraint definition within a CREATE
 // TABLE statement.
 type TableDef interface {
@@ -413,6 +494,7 @@ func (*IndexTableDef) tableDef()                {}
 func (*FamilyTableDef) tableDef()               {}
 func (*ForeignKeyConstraintTableDef) tableDef() {}
 func (*CheckConstraintTableDef) tableDef()      {}
+func (*ExclusionConstraintTableDef) tableDef()  {}
 func (*LikeTableDef) tableDef()                 {}
 
 // TableDefs represents a list of table definitions.
@@ -1083,6 +1165,7 @@ type ConstraintTableDef interface {
 func (*UniqueConstraintTableDef) constraintTableDef()     {}
 func (*ForeignKeyConstraintTableDef) constraintTableDef() {}
 func (*CheckConstraintTableDef) constraintTableDef()      {}
+func (*ExclusionConstraintTableDef) constraintTableDef()  {}
 
 // UniqueConstraintTableDef represents a unique constraint within a CREATE
 // TABLE statement.
@@ -1091,6 +1174,7 @@ type UniqueConstraintTableDef struct {
 	PrimaryKey   bool
 	WithoutIndex bool
 	IfNotExists  bool
+	Deferrable   ConstraintDeferrability
 }
 
 // SetName implements the TableDef interface.
@@ -1135,6 +1219,7 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionByIndex != nil {
 		ctx.FormatNode(node.PartitionByIndex)
 	}
+	ctx.FormatNode(&node.Deferrable)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -1153,6 +1238,7 @@ type ForeignKeyConstraintTableDef struct {
 	Actions     ReferenceActions
 	Match       CompositeKeyMatchMethod
 	IfNotExists bool
+	Deferrable  ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -1183,6 +1269,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(&node.Deferrable)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1229,6 +1316,79 @@ func (node *CheckConstraintTableDef) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// ExclusionConstraintTableDef represents an exclusion constraint within a
+// CREATE TABLE statement. An exclusion constraint guarantees that no two rows
+// of the table return true for all of its operators when compared on its
+// columns.
+type ExclusionConstraintTableDef struct {
+	Name Name
+	// Inverted is true if the constraint is defined USING gist or gin.
+	Inverted    bool
+	Elems       ExclusionConstraintElems
+	Predicate   Expr
+	IfNotExists bool
+}
+
+// ExclusionConstraintElem is a column of an exclusion constraint together with
+// the operator used to compare its values.
+type ExclusionConstraintElem struct {
+	IndexElem
+	Operator treecmp.ComparisonOperator
+}
+
+// ExclusionConstraintElems is a list of ExclusionConstraintElem.
+type ExclusionConstraintElems []ExclusionConstraintElem
+
+// SetName implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetName(name Name) {
+	node.Name = name
+}
+
+// SetIfNotExists implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetIfNotExists() {
+	node.IfNotExists = true
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintTableDef) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		if node.IfNotExists {
+			ctx.WriteString("IF NOT EXISTS ")
+		}
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("EXCLUDE ")
+	if node.Inverted {
+		ctx.WriteString("USING gist ")
+	}
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Elems)
+	ctx.WriteByte(')')
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintElem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.IndexElem)
+	ctx.WriteString(" WITH ")
+	ctx.WriteString(node.Operator.String())
+}
+
+// Format implements the NodeFormatter interface.
+func (l *ExclusionConstraintElems) Format(ctx *FmtCtx) {
+	for i := range *l {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*l)[i])
+	}
+}
+
 // FamilyTableDef represents a family definition within a CREATE TABLE
 // statement.
 type FamilyTableDef struct {
@@ -1475,6 +1635,8 @@ type CreateTable struct {
 	Defs     TableDefs
 	AsSource *Select
 	Locality *Locality
+	// Inherits contains the parent tables named in an INHERITS clause.
+	Inherits TableNames
 }
 
 // As returns true if this table represents a CREATE TABLE ... AS statement,
@@ -1530,6 +1692,11 @@ func (node *CreateTable) FormatBody(ctx *FmtCtx) {
 		ctx.WriteString(" (")
 		ctx.FormatNode(&node.Defs)
 		ctx.WriteByte(')')
+		if len(node.Inherits) > 0 {
+			ctx.WriteString(" INHERITS (")
+			ctx.FormatNode(&node.Inherits)
+			ctx.WriteByte(')')
+		}
 		if node.PartitionByTable != nil {
 			ctx.FormatNode(node.PartitionByTable)
 		}
@@ -1919,6 +2086,8 @@ type CreateView struct {
 	Replace      bool
 	Materialized bool
 	WithData     bool
+	// Params are the storage parameters of a materialized view.
+	Params StorageParams
 }
 
 // Format implements the NodeFormatter interface.
@@ -1951,6 +2120,12 @@ func (node *CreateView) Format(ctx *FmtCtx) {
 		ctx.WriteByte(')')
 	}
 
+	if node.Params != nil {
+		ctx.WriteString(" WITH (")
+		ctx.FormatNode(&node.Params)
+		ctx.WriteByte(')')
+	}
+
 	ctx.WriteString(" AS ")
 	ctx.FormatNode(node.AsSource)
 	if node.Materialized && node.WithData {
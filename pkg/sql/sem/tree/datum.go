@@ -3726,7 +3726,7 @@ func AsJSON(
 		// This is RFC3339Nano, but without the TZ fields.
 		return json.FromString(formatTime(t.UTC(), "2006-01-02T15:04:05.999999999")), nil
 	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DTimeTZ, *DBitArray, *DBox2D,
-		*DTSVector, *DTSQuery:
+		*DTSVector, *DTSQuery, *DRange, *DMultirange:
 		return json.FromString(
 			AsStringWithFlags(t, FmtBareStrings, FmtDataConversionConfig(dcc), FmtLocation(loc)),
 		), nil
@@ -5802,8 +5802,11 @@ var baseDatumTypeSizes = map[types.Family]struct {
 	types.INetFamily:           {unsafe.Sizeof(DIPAddr{}), fixedSize},
 	types.OidFamily:            {unsafe.Sizeof(DOid{}.Oid), fixedSize},
 	types.EnumFamily:           {unsafe.Sizeof(DEnum{}), variableSize},
+	types.RangeFamily:          {unsafe.Sizeof(DRange{}), variableSize},
+	types.MultirangeFamily:     {unsafe.Sizeof(DMultirange{}), variableSize},
 
-	types.VoidFamily: {sz: unsafe.Sizeof(DVoid{}), variable: fixedSize},
+	types.VoidFamily:    {sz: unsafe.Sizeof(DVoid{}), variable: fixedSize},
+	types.TriggerFamily: {sz: unsafe.Sizeof(DVoid{}), variable: fixedSize},
 	// TODO(jordan,justin): This seems suspicious.
 	types.ArrayFamily: {unsafe.Sizeof(DString("")), variableSize},
 
@@ -299,25 +299,30 @@ func PrependToMaybeNullArray(typ *types.T, left Datum, right Datum) (Datum, erro
 // (or array) to an array from O(n) to O(1).
 func initArrayElementConcatenation() {
 	for _, t := range types.Scalar {
-		typ := t
-		addBinOp(treebin.Concat, &BinOp{
-			LeftType:          types.MakeArray(typ),
-			RightType:         typ,
-			ReturnType:        types.MakeArray(typ),
-			CalledOnNullInput: true,
-			EvalOp:            &AppendToMaybeNullArrayOp{Typ: typ},
-			Volatility:        volatility.Immutable,
-		}, &BinOp{
-			LeftType:          typ,
-			RightType:         types.MakeArray(typ),
-			ReturnType:        types.MakeArray(typ),
-			CalledOnNullInput: true,
-			EvalOp:            &PrependToMaybeNullArrayOp{Typ: typ},
-			Volatility:        volatility.Immutable,
-		})
+		addArrayElementConcatenation(t)
 	}
 }
 
+// addArrayElementConcatenation adds the overloads for concatenating an array
+// of the given type with one of its elements.
+func addArrayElementConcatenation(typ *types.T) {
+	addBinOp(treebin.Concat, &BinOp{
+		LeftType:          types.MakeArray(typ),
+		RightType:         typ,
+		ReturnType:        types.MakeArray(typ),
+		CalledOnNullInput: true,
+		EvalOp:            &AppendToMaybeNullArrayOp{Typ: typ},
+		Volatility:        volatility.Immutable,
+	}, &BinOp{
+		LeftType:          typ,
+		RightType:         types.MakeArray(typ),
+		ReturnType:        types.MakeArray(typ),
+		CalledOnNullInput: true,
+		EvalOp:            &PrependToMaybeNullArrayOp{Typ: typ},
+		Volatility:        volatility.Immutable,
+	})
+}
+
 // ConcatArrays concatenates two arrays.
 func ConcatArrays(typ *types.T, left Datum, right Datum) (Datum, error) {
 	if left == DNull && right == DNull {
@@ -409,19 +414,24 @@ func JSONExistsAny(json DJSON, dArray *DArray) (*DBool, error) {
 
 func initArrayToArrayConcatenation() {
 	for _, t := range types.Scalar {
-		typ := t
-		at := types.MakeArray(typ)
-		addBinOp(treebin.Concat, &BinOp{
-			LeftType:          at,
-			RightType:         at,
-			ReturnType:        at,
-			CalledOnNullInput: true,
-			EvalOp:            &ConcatArraysOp{Typ: typ},
-			Volatility:        volatility.Immutable,
-		})
+		addArrayToArrayConcatenation(t)
 	}
 }
 
+// addArrayToArrayConcatenation adds the overload for concatenating two arrays
+// of the given type.
+func addArrayToArrayConcatenation(typ *types.T) {
+	at := types.MakeArray(typ)
+	addBinOp(treebin.Concat, &BinOp{
+		LeftType:          at,
+		RightType:         at,
+		ReturnType:        at,
+		CalledOnNullInput: true,
+		EvalOp:            &ConcatArraysOp{Typ: typ},
+		Volatility:        volatility.Immutable,
+	})
+}
+
 // initNonArrayToNonArrayConcatenation initializes string + nonarrayelement
 // and nonarrayelement + string concatenation.
 func initNonArrayToNonArrayConcatenation() {
@@ -459,6 +469,7 @@ func init() {
 	initArrayElementConcatenation()
 	initArrayToArrayConcatenation()
 	initNonArrayToNonArrayConcatenation()
+	initRangeOperators()
 }
 
 func init() {
@@ -1970,6 +1981,223 @@ func makeBox2DComparisonOperators(op func(lhs, rhs *geo.CartesianBoundingBox) bo
 	}
 }
 
+func addCmpOp(symbol treecmp.ComparisonOperatorSymbol, ops ...*CmpOp) {
+	s, ok := CmpOps[symbol]
+	if !ok {
+		s = new(CmpOpOverloads)
+		CmpOps[symbol] = s
+	}
+	for _, op := range ops {
+		op.types = ParamTypes{{"left", op.LeftType}, {"right", op.RightType}}
+	}
+	s.overloads = append(s.overloads, ops...)
+}
+
+func makeRangeCmpOp(left, right *types.T, op func(left, right Datum) bool) *CmpOp {
+	return &CmpOp{
+		LeftType:   left,
+		RightType:  right,
+		EvalOp:     &CompareRangeOp{Op: op},
+		Volatility: volatility.Immutable,
+	}
+}
+
+// initRangeOperators adds the operators of the built-in range and multirange
+// types, and of the AnyRange and AnyMultirange wildcards, to CmpOps and BinOps.
+func initRangeOperators() {
+	for i := range types.RangeTypes {
+		rangeTyp, multirangeTyp := types.RangeTypes[i], types.MultirangeTypes[i]
+		elemTyp := rangeTyp.RangeSubtype()
+
+		addRangeComparisonOperators(rangeTyp, multirangeTyp, func(r *DRange) *DMultirange {
+			return MakeDMultirangeFromRange(multirangeTyp, r)
+		})
+
+		// Containment of elements.
+		addCmpOp(treecmp.Contains,
+			makeRangeCmpOp(rangeTyp, elemTyp, func(left, right Datum) bool {
+				return MustBeDRange(left).ContainsElem(right)
+			}),
+			makeRangeCmpOp(multirangeTyp, elemTyp, func(left, right Datum) bool {
+				return MustBeDMultirange(left).ContainsElem(right)
+			}),
+		)
+		addCmpOp(treecmp.ContainedBy,
+			makeRangeCmpOp(elemTyp, rangeTyp, func(left, right Datum) bool {
+				return MustBeDRange(right).ContainsElem(left)
+			}),
+			makeRangeCmpOp(elemTyp, multirangeTyp, func(left, right Datum) bool {
+				return MustBeDMultirange(right).ContainsElem(left)
+			}),
+		)
+
+		addBinOp(treebin.Plus,
+			&BinOp{
+				LeftType:   rangeTyp,
+				RightType:  rangeTyp,
+				ReturnType: rangeTyp,
+				EvalOp:     &PlusRangeOp{},
+				Volatility: volatility.Immutable,
+			},
+			&BinOp{
+				LeftType:   multirangeTyp,
+				RightType:  multirangeTyp,
+				ReturnType: multirangeTyp,
+				EvalOp:     &PlusMultirangeOp{},
+				Volatility: volatility.Immutable,
+			},
+		)
+		addBinOp(treebin.Mult,
+			&BinOp{
+				LeftType:   rangeTyp,
+				RightType:  rangeTyp,
+				ReturnType: rangeTyp,
+				EvalOp:     &MultRangeOp{},
+				Volatility: volatility.Immutable,
+			},
+			&BinOp{
+				LeftType:   multirangeTyp,
+				RightType:  multirangeTyp,
+				ReturnType: multirangeTyp,
+				EvalOp:     &MultMultirangeOp{},
+				Volatility: volatility.Immutable,
+			},
+		)
+		addBinOp(treebin.Minus,
+			&BinOp{
+				LeftType:   rangeTyp,
+				RightType:  rangeTyp,
+				ReturnType: rangeTyp,
+				EvalOp:     &MinusRangeOp{},
+				Volatility: volatility.Immutable,
+			},
+			&BinOp{
+				LeftType:   multirangeTyp,
+				RightType:  multirangeTyp,
+				ReturnType: multirangeTyp,
+				EvalOp:     &MinusMultirangeOp{},
+				Volatility: volatility.Immutable,
+			},
+		)
+		// Arrays of ranges and multiranges can be compared and concatenated like
+		// arrays of scalars.
+		for _, t := range []*types.T{rangeTyp, multirangeTyp} {
+			at := types.MakeArray(t)
+			for _, sym := range []treecmp.ComparisonOperatorSymbol{
+				treecmp.EQ, treecmp.LT, treecmp.LE, treecmp.IsNotDistinctFrom,
+			} {
+				addCmpOp(sym, &CmpOp{
+					LeftType:          at,
+					RightType:         at,
+					EvalOp:            &CompareScalarOp{treecmp.MakeComparisonOperator(sym)},
+					CalledOnNullInput: sym == treecmp.IsNotDistinctFrom,
+					Volatility:        volatility.Immutable,
+				})
+			}
+			addArrayElementConcatenation(t)
+			addArrayToArrayConcatenation(t)
+		}
+	}
+
+	// User-defined range and multirange types share the overloads of the
+	// AnyRange and AnyMultirange wildcards. The type checker ensures that both
+	// arguments are of the same range type. Containment of elements and the
+	// operators returning a range are not supported for them, since the
+	// wildcards do not determine the subtype or the return type.
+	addRangeComparisonOperators(types.AnyRange, types.AnyMultirange, func(r *DRange) *DMultirange {
+		return MakeDMultirangeFromRange(types.MakeMultirange(0, 0, r.Typ), r)
+	})
+}
+
+// addRangeComparisonOperators adds the operators of the given range and
+// multirange types which return a bool. toMultirange converts a range to a
+// multirange of the given multirange type.
+func addRangeComparisonOperators(
+	rangeTyp, multirangeTyp *types.T, toMultirange func(r *DRange) *DMultirange,
+) {
+	for _, t := range []*types.T{rangeTyp, multirangeTyp} {
+		addCmpOp(treecmp.EQ, makeEqFn(t, t, volatility.Immutable))
+		addCmpOp(treecmp.LT, makeLtFn(t, t, volatility.Immutable))
+		addCmpOp(treecmp.LE, makeLeFn(t, t, volatility.Immutable))
+		addCmpOp(treecmp.IsNotDistinctFrom, makeIsFn(t, t, volatility.Immutable))
+		addCmpOp(treecmp.In, makeEvalTupleIn(t, volatility.Leakproof))
+	}
+
+	// Containment, overlap and adjacency of two ranges.
+	addCmpOp(treecmp.Contains, makeRangeCmpOp(rangeTyp, rangeTyp, func(left, right Datum) bool {
+		return MustBeDRange(left).ContainsRange(MustBeDRange(right))
+	}))
+	addCmpOp(treecmp.ContainedBy, makeRangeCmpOp(rangeTyp, rangeTyp, func(left, right Datum) bool {
+		return MustBeDRange(right).ContainsRange(MustBeDRange(left))
+	}))
+	addCmpOp(treecmp.Overlaps, makeRangeCmpOp(rangeTyp, rangeTyp, func(left, right Datum) bool {
+		return MustBeDRange(left).Overlaps(MustBeDRange(right))
+	}))
+	addCmpOp(treecmp.Adjacent, makeRangeCmpOp(rangeTyp, rangeTyp, func(left, right Datum) bool {
+		return MustBeDRange(left).Adjacent(MustBeDRange(right))
+	}))
+
+	// Containment, overlap and adjacency involving multiranges, where a range
+	// is treated as the multirange containing its values.
+	asMultirange := func(d Datum) *DMultirange {
+		if r, ok := AsDRange(d); ok {
+			return toMultirange(r)
+		}
+		return MustBeDMultirange(d)
+	}
+	for _, args := range [][2]*types.T{
+		{rangeTyp, multirangeTyp},
+		{multirangeTyp, rangeTyp},
+		{multirangeTyp, multirangeTyp},
+	} {
+		addCmpOp(treecmp.Contains, makeRangeCmpOp(args[0], args[1], func(left, right Datum) bool {
+			return asMultirange(left).ContainsMultirange(asMultirange(right))
+		}))
+		addCmpOp(treecmp.ContainedBy, makeRangeCmpOp(args[0], args[1], func(left, right Datum) bool {
+			return asMultirange(right).ContainsMultirange(asMultirange(left))
+		}))
+		addCmpOp(treecmp.Overlaps, makeRangeCmpOp(args[0], args[1], func(left, right Datum) bool {
+			return asMultirange(left).Overlaps(asMultirange(right))
+		}))
+		addCmpOp(treecmp.Adjacent, makeRangeCmpOp(args[0], args[1], func(left, right Datum) bool {
+			return asMultirange(left).Adjacent(asMultirange(right))
+		}))
+	}
+
+	addBinOp(treebin.LShift,
+		&BinOp{
+			LeftType:   rangeTyp,
+			RightType:  rangeTyp,
+			ReturnType: types.Bool,
+			EvalOp:     &LShiftRangeOp{},
+			Volatility: volatility.Immutable,
+		},
+		&BinOp{
+			LeftType:   multirangeTyp,
+			RightType:  multirangeTyp,
+			ReturnType: types.Bool,
+			EvalOp:     &LShiftMultirangeOp{},
+			Volatility: volatility.Immutable,
+		},
+	)
+	addBinOp(treebin.RShift,
+		&BinOp{
+			LeftType:   rangeTyp,
+			RightType:  rangeTyp,
+			ReturnType: types.Bool,
+			EvalOp:     &RShiftRangeOp{},
+			Volatility: volatility.Immutable,
+		},
+		&BinOp{
+			LeftType:   multirangeTyp,
+			RightType:  multirangeTyp,
+			ReturnType: types.Bool,
+			EvalOp:     &RShiftMultirangeOp{},
+			Volatility: volatility.Immutable,
+		},
+	)
+}
+
 // This map contains the inverses for operators in the CmpOps map that have
 // inverses.
 var cmpOpsInverse map[treecmp.ComparisonOperatorSymbol]treecmp.ComparisonOperatorSymbol
@@ -2017,7 +2245,8 @@ func (e *MultipleResultsError) Error() string {
 func (expr *FuncExpr) MaybeWrapError(err error) error {
 	// If we are facing an explicit error, propagate it unchanged.
 	fName := expr.Func.String()
-	if fName == `crdb_internal.force_error` {
+	switch fName {
+	case `crdb_internal.force_error`, `crdb_internal.assert_domain_check`:
 		return err
 	}
 	// Otherwise, wrap it with context.
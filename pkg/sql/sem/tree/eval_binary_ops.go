@@ -32,6 +32,11 @@ type CompareBox2DOp struct {
 	Op func(left, right Datum) bool
 }
 
+// CompareRangeOp is a BinaryEvalOp.
+type CompareRangeOp struct {
+	Op func(left, right Datum) bool
+}
+
 // InTupleOp is a BinaryEvalOp.
 type InTupleOp struct{}
 
@@ -176,6 +181,10 @@ type (
 	PlusINetIntOp struct{}
 	// PlusIntINetOp is a BinaryEvalOp.
 	PlusIntINetOp struct{}
+	// PlusRangeOp is a BinaryEvalOp.
+	PlusRangeOp struct{}
+	// PlusMultirangeOp is a BinaryEvalOp.
+	PlusMultirangeOp struct{}
 )
 
 type (
@@ -227,6 +236,10 @@ type (
 	MinusINetOp struct{}
 	// MinusINetIntOp is a BinaryEvalOp.
 	MinusINetIntOp struct{}
+	// MinusRangeOp is a BinaryEvalOp.
+	MinusRangeOp struct{}
+	// MinusMultirangeOp is a BinaryEvalOp.
+	MinusMultirangeOp struct{}
 )
 type (
 	// MultDecimalIntOp is a BinaryEvalOp.
@@ -251,6 +264,10 @@ type (
 	MultIntervalFloatOp struct{}
 	// MultIntervalIntOp is a BinaryEvalOp.
 	MultIntervalIntOp struct{}
+	// MultMultirangeOp is a BinaryEvalOp.
+	MultMultirangeOp struct{}
+	// MultRangeOp is a BinaryEvalOp.
+	MultRangeOp struct{}
 )
 
 type (
@@ -314,6 +331,10 @@ type (
 	LShiftINetOp struct{}
 	// LShiftIntOp is a BinaryEvalOp.
 	LShiftIntOp struct{}
+	// LShiftMultirangeOp is a BinaryEvalOp.
+	LShiftMultirangeOp struct{}
+	// LShiftRangeOp is a BinaryEvalOp.
+	LShiftRangeOp struct{}
 	// LShiftVarBitIntOp is a BinaryEvalOp.
 	LShiftVarBitIntOp struct{}
 )
@@ -323,6 +344,10 @@ type (
 	RShiftINetOp struct{}
 	// RShiftIntOp is a BinaryEvalOp.
 	RShiftIntOp struct{}
+	// RShiftMultirangeOp is a BinaryEvalOp.
+	RShiftMultirangeOp struct{}
+	// RShiftRangeOp is a BinaryEvalOp.
+	RShiftRangeOp struct{}
 	// RShiftVarBitIntOp is a BinaryEvalOp.
 	RShiftVarBitIntOp struct{}
 )
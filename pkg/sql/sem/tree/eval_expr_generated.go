@@ -37,6 +37,7 @@ type ExprEvaluator interface {
 	EvalComparisonExpr(context.Context, *ComparisonExpr) (Datum, error)
 	EvalDefaultVal(context.Context, *DefaultVal) (Datum, error)
 	EvalFuncExpr(context.Context, *FuncExpr) (Datum, error)
+	EvalGroupingExpr(context.Context, *GroupingExpr) (Datum, error)
 	EvalIfErrExpr(context.Context, *IfErrExpr) (Datum, error)
 	EvalIfExpr(context.Context, *IfExpr) (Datum, error)
 	EvalIndexedVar(context.Context, *IndexedVar) (Datum, error)
@@ -206,6 +207,11 @@ func (node *DJSON) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return node, nil
 }
 
+// Eval is part of the TypedExpr interface.
+func (node *DMultirange) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return node, nil
+}
+
 // Eval is part of the TypedExpr interface.
 func (node *DOid) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return node, nil
@@ -216,6 +222,11 @@ func (node *DOidWrapper) Eval(ctx context.Context, v ExprEvaluator) (Datum, erro
 	return node, nil
 }
 
+// Eval is part of the TypedExpr interface.
+func (node *DRange) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return node, nil
+}
+
 // Eval is part of the TypedExpr interface.
 func (node *DString) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return node, nil
@@ -276,6 +287,11 @@ func (node *FuncExpr) Eval(ctx context.Context, v ExprEvaluator) (Datum, error)
 	return v.EvalFuncExpr(ctx, node)
 }
 
+// Eval is part of the TypedExpr interface.
+func (node *GroupingExpr) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return v.EvalGroupingExpr(ctx, node)
+}
+
 // Eval is part of the TypedExpr interface.
 func (node *IfErrExpr) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return v.EvalIfErrExpr(ctx, node)
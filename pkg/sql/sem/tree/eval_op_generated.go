@@ -65,6 +65,7 @@ type BinaryOpEvaluator interface {
 	EvalBitXorIntOp(context.Context, *BitXorIntOp, Datum, Datum) (Datum, error)
 	EvalBitXorVarBitOp(context.Context, *BitXorVarBitOp, Datum, Datum) (Datum, error)
 	EvalCompareBox2DOp(context.Context, *CompareBox2DOp, Datum, Datum) (Datum, error)
+	EvalCompareRangeOp(context.Context, *CompareRangeOp, Datum, Datum) (Datum, error)
 	EvalCompareScalarOp(context.Context, *CompareScalarOp, Datum, Datum) (Datum, error)
 	EvalCompareTupleOp(context.Context, *CompareTupleOp, Datum, Datum) (Datum, error)
 	EvalConcatArraysOp(context.Context, *ConcatArraysOp, Datum, Datum) (Datum, error)
@@ -101,6 +102,8 @@ type BinaryOpEvaluator interface {
 	EvalJSONSomeExistsOp(context.Context, *JSONSomeExistsOp, Datum, Datum) (Datum, error)
 	EvalLShiftINetOp(context.Context, *LShiftINetOp, Datum, Datum) (Datum, error)
 	EvalLShiftIntOp(context.Context, *LShiftIntOp, Datum, Datum) (Datum, error)
+	EvalLShiftMultirangeOp(context.Context, *LShiftMultirangeOp, Datum, Datum) (Datum, error)
+	EvalLShiftRangeOp(context.Context, *LShiftRangeOp, Datum, Datum) (Datum, error)
 	EvalLShiftVarBitIntOp(context.Context, *LShiftVarBitIntOp, Datum, Datum) (Datum, error)
 	EvalMatchLikeOp(context.Context, *MatchLikeOp, Datum, Datum) (Datum, error)
 	EvalMatchRegexpOp(context.Context, *MatchRegexpOp, Datum, Datum) (Datum, error)
@@ -119,6 +122,8 @@ type BinaryOpEvaluator interface {
 	EvalMinusJsonbIntOp(context.Context, *MinusJsonbIntOp, Datum, Datum) (Datum, error)
 	EvalMinusJsonbStringArrayOp(context.Context, *MinusJsonbStringArrayOp, Datum, Datum) (Datum, error)
 	EvalMinusJsonbStringOp(context.Context, *MinusJsonbStringOp, Datum, Datum) (Datum, error)
+	EvalMinusMultirangeOp(context.Context, *MinusMultirangeOp, Datum, Datum) (Datum, error)
+	EvalMinusRangeOp(context.Context, *MinusRangeOp, Datum, Datum) (Datum, error)
 	EvalMinusTimeIntervalOp(context.Context, *MinusTimeIntervalOp, Datum, Datum) (Datum, error)
 	EvalMinusTimeOp(context.Context, *MinusTimeOp, Datum, Datum) (Datum, error)
 	EvalMinusTimeTZIntervalOp(context.Context, *MinusTimeTZIntervalOp, Datum, Datum) (Datum, error)
@@ -145,6 +150,8 @@ type BinaryOpEvaluator interface {
 	EvalMultIntervalDecimalOp(context.Context, *MultIntervalDecimalOp, Datum, Datum) (Datum, error)
 	EvalMultIntervalFloatOp(context.Context, *MultIntervalFloatOp, Datum, Datum) (Datum, error)
 	EvalMultIntervalIntOp(context.Context, *MultIntervalIntOp, Datum, Datum) (Datum, error)
+	EvalMultMultirangeOp(context.Context, *MultMultirangeOp, Datum, Datum) (Datum, error)
+	EvalMultRangeOp(context.Context, *MultRangeOp, Datum, Datum) (Datum, error)
 	EvalOverlapsArrayOp(context.Context, *OverlapsArrayOp, Datum, Datum) (Datum, error)
 	EvalOverlapsINetOp(context.Context, *OverlapsINetOp, Datum, Datum) (Datum, error)
 	EvalPlusDateIntOp(context.Context, *PlusDateIntOp, Datum, Datum) (Datum, error)
@@ -165,6 +172,8 @@ type BinaryOpEvaluator interface {
 	EvalPlusIntervalTimeTZOp(context.Context, *PlusIntervalTimeTZOp, Datum, Datum) (Datum, error)
 	EvalPlusIntervalTimestampOp(context.Context, *PlusIntervalTimestampOp, Datum, Datum) (Datum, error)
 	EvalPlusIntervalTimestampTZOp(context.Context, *PlusIntervalTimestampTZOp, Datum, Datum) (Datum, error)
+	EvalPlusMultirangeOp(context.Context, *PlusMultirangeOp, Datum, Datum) (Datum, error)
+	EvalPlusRangeOp(context.Context, *PlusRangeOp, Datum, Datum) (Datum, error)
 	EvalPlusTimeDateOp(context.Context, *PlusTimeDateOp, Datum, Datum) (Datum, error)
 	EvalPlusTimeIntervalOp(context.Context, *PlusTimeIntervalOp, Datum, Datum) (Datum, error)
 	EvalPlusTimeTZDateOp(context.Context, *PlusTimeTZDateOp, Datum, Datum) (Datum, error)
@@ -179,6 +188,8 @@ type BinaryOpEvaluator interface {
 	EvalPrependToMaybeNullArrayOp(context.Context, *PrependToMaybeNullArrayOp, Datum, Datum) (Datum, error)
 	EvalRShiftINetOp(context.Context, *RShiftINetOp, Datum, Datum) (Datum, error)
 	EvalRShiftIntOp(context.Context, *RShiftIntOp, Datum, Datum) (Datum, error)
+	EvalRShiftMultirangeOp(context.Context, *RShiftMultirangeOp, Datum, Datum) (Datum, error)
+	EvalRShiftRangeOp(context.Context, *RShiftRangeOp, Datum, Datum) (Datum, error)
 	EvalRShiftVarBitIntOp(context.Context, *RShiftVarBitIntOp, Datum, Datum) (Datum, error)
 	EvalSimilarToOp(context.Context, *SimilarToOp, Datum, Datum) (Datum, error)
 	EvalTSMatchesQueryVectorOp(context.Context, *TSMatchesQueryVectorOp, Datum, Datum) (Datum, error)
@@ -291,6 +302,11 @@ func (op *CompareBox2DOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (
 	return e.EvalCompareBox2DOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *CompareRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalCompareRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *CompareScalarOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalCompareScalarOp(ctx, op, a, b)
@@ -471,6 +487,16 @@ func (op *LShiftIntOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Dat
 	return e.EvalLShiftIntOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *LShiftMultirangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalLShiftMultirangeOp(ctx, op, a, b)
+}
+
+// Eval is part of the BinaryEvalOp interface.
+func (op *LShiftRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalLShiftRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *LShiftVarBitIntOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalLShiftVarBitIntOp(ctx, op, a, b)
@@ -561,6 +587,16 @@ func (op *MinusJsonbStringOp) Eval(ctx context.Context, e OpEvaluator, a, b Datu
 	return e.EvalMinusJsonbStringOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *MinusMultirangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalMinusMultirangeOp(ctx, op, a, b)
+}
+
+// Eval is part of the BinaryEvalOp interface.
+func (op *MinusRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalMinusRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *MinusTimeIntervalOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalMinusTimeIntervalOp(ctx, op, a, b)
@@ -691,6 +727,16 @@ func (op *MultIntervalIntOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum
 	return e.EvalMultIntervalIntOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *MultMultirangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalMultMultirangeOp(ctx, op, a, b)
+}
+
+// Eval is part of the BinaryEvalOp interface.
+func (op *MultRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalMultRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *OverlapsArrayOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalOverlapsArrayOp(ctx, op, a, b)
@@ -791,6 +837,16 @@ func (op *PlusIntervalTimestampTZOp) Eval(ctx context.Context, e OpEvaluator, a,
 	return e.EvalPlusIntervalTimestampTZOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *PlusMultirangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalPlusMultirangeOp(ctx, op, a, b)
+}
+
+// Eval is part of the BinaryEvalOp interface.
+func (op *PlusRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalPlusRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *PlusTimeDateOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalPlusTimeDateOp(ctx, op, a, b)
@@ -861,6 +917,16 @@ func (op *RShiftIntOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Dat
 	return e.EvalRShiftIntOp(ctx, op, a, b)
 }
 
+// Eval is part of the BinaryEvalOp interface.
+func (op *RShiftMultirangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalRShiftMultirangeOp(ctx, op, a, b)
+}
+
+// Eval is part of the BinaryEvalOp interface.
+func (op *RShiftRangeOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
+	return e.EvalRShiftRangeOp(ctx, op, a, b)
+}
+
 // Eval is part of the BinaryEvalOp interface.
 func (op *RShiftVarBitIntOp) Eval(ctx context.Context, e OpEvaluator, a, b Datum) (Datum, error) {
 	return e.EvalRShiftVarBitIntOp(ctx, op, a, b)
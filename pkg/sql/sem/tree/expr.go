@@ -747,6 +747,24 @@ func (node *CoalesceExpr) GetWhenCondition(i int) (whenCond Expr) {
 	return whenCond
 }
 
+// GroupingExpr represents a GROUPING(...) expression. It evaluates to a bit
+// mask in which each bit is set if the corresponding argument is not part of
+// the grouping set that produced the current row; the last argument
+// corresponds to the least significant bit. The arguments must match GROUP BY
+// expressions of the query level in which the expression appears.
+type GroupingExpr struct {
+	Exprs Exprs
+
+	typeAnnotation
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingExpr) Format(ctx *FmtCtx) {
+	ctx.WriteString("GROUPING(")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DefaultVal represents the DEFAULT expression.
 type DefaultVal struct{}
 
@@ -1735,12 +1753,16 @@ func (node *DTimestamp) String() string       { return AsString(node) }
 func (node *DTimestampTZ) String() string     { return AsString(node) }
 func (node *DTuple) String() string           { return AsString(node) }
 func (node *DArray) String() string           { return AsString(node) }
+func (node *DRange) String() string           { return AsString(node) }
+func (node *DMultirange) String() string      { return AsString(node) }
 func (node *DOid) String() string             { return AsString(node) }
 func (node *DOidWrapper) String() string      { return AsString(node) }
 func (node *DVoid) String() string            { return AsString(node) }
 func (node *Exprs) String() string            { return AsString(node) }
 func (node *ArrayFlatten) String() string     { return AsString(node) }
 func (node *FuncExpr) String() string         { return AsString(node) }
+func (node *GroupingExpr) String() string     { return AsString(node) }
+func (node *GroupingSets) String() string     { return AsString(node) }
 func (node *IfExpr) String() string           { return AsString(node) }
 func (node *IfErrExpr) String() string        { return AsString(node) }
 func (node *IndexedVar) String() string       { return AsString(node) }
@@ -0,0 +1,113 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// Merge represents a MERGE statement.
+type Merge struct {
+	With      *With
+	Table     TableExpr
+	Source    TableExpr
+	On        Expr
+	Whens     MergeWhens
+	Returning ReturningClause
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Merge) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.With)
+	ctx.WriteString("MERGE INTO ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" USING ")
+	ctx.FormatNode(node.Source)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.On)
+	for _, w := range node.Whens {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(w)
+	}
+	if HasReturningClause(node.Returning) {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Returning)
+	}
+}
+
+// MergeActionType is the action taken by a WHEN clause of a MERGE statement.
+// The numeric values are also used by the optimizer and the execution engine
+// to encode the action that applies to each row, so they must not change.
+type MergeActionType uint8
+
+const (
+	// MergeActionDoNothing leaves the row alone.
+	MergeActionDoNothing MergeActionType = iota
+	// MergeActionInsert inserts a new row into the target table.
+	MergeActionInsert
+	// MergeActionUpdate updates the matched row of the target table.
+	MergeActionUpdate
+	// MergeActionDelete deletes the matched row of the target table.
+	MergeActionDelete
+)
+
+// MergeWhens represents the list of WHEN clauses of a MERGE statement.
+type MergeWhens []*MergeWhen
+
+// MergeWhen represents a WHEN [NOT] MATCHED clause of a MERGE statement.
+type MergeWhen struct {
+	// Matched is true for WHEN MATCHED clauses and false for WHEN NOT MATCHED
+	// clauses.
+	Matched bool
+	// Cond is the optional AND condition of the clause.
+	Cond   Expr
+	Action MergeActionType
+	// Exprs is the SET list of an UPDATE action.
+	Exprs UpdateExprs
+	// Columns is the optional column list of an INSERT action.
+	Columns NameList
+	// Values is the VALUES list of an INSERT action. It is nil for INSERT
+	// DEFAULT VALUES.
+	Values Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *MergeWhen) Format(ctx *FmtCtx) {
+	if node.Matched {
+		ctx.WriteString("WHEN MATCHED")
+	} else {
+		ctx.WriteString("WHEN NOT MATCHED")
+	}
+	if node.Cond != nil {
+		ctx.WriteString(" AND ")
+		ctx.FormatNode(node.Cond)
+	}
+	ctx.WriteString(" THEN ")
+	switch node.Action {
+	case MergeActionDoNothing:
+		ctx.WriteString("DO NOTHING")
+	case MergeActionUpdate:
+		ctx.WriteString("UPDATE SET ")
+		ctx.FormatNode(&node.Exprs)
+	case MergeActionDelete:
+		ctx.WriteString("DELETE")
+	case MergeActionInsert:
+		ctx.WriteString("INSERT ")
+		if len(node.Columns) > 0 {
+			ctx.WriteByte('(')
+			ctx.FormatNode(&node.Columns)
+			ctx.WriteString(") ")
+		}
+		if node.Values == nil {
+			ctx.WriteString("DEFAULT VALUES")
+		} else {
+			ctx.WriteString("VALUES (")
+			ctx.FormatNode(&node.Values)
+			ctx.WriteByte(')')
+		}
+	}
+}
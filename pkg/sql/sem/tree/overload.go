@@ -217,9 +217,15 @@ type Overload struct {
 	UDFContainsOnlySignature bool
 	// Body is the SQL string body of a user-defined function.
 	Body string
+	// Language is the language in which the body of a user-defined function
+	// is written.
+	Language FunctionLanguage
 	// ReturnSet is set to true when a user-defined function is defined to return
 	// a set of values.
 	ReturnSet bool
+	// IsProcedure is set to true when this is a stored procedure overload.
+	// Procedures can only be invoked with CALL.
+	IsProcedure bool
 }
 
 // params implements the overloadImpl interface.
@@ -1053,19 +1059,28 @@ func (s *overloadTypeChecker) typeCheckOverloadedExprs(
 	// This enables AnyEnum array ops to not need a cast, e.g. array['a']::enum[] = '{a}'.
 	// If we have one remaining candidate containing AnyEnum, cast all remaining
 	// arguments to a known enum and check that the rest match. This is a poor man's
-	// implicit cast / postgres "same argument" resolution clone.
+	// implicit cast / postgres "same argument" resolution clone. The same is done
+	// for the AnyRange and AnyMultirange wildcards of user-defined range types.
 	if len(s.overloadIdxs) == 1 {
 		params := s.overloads[s.overloadIdxs[0]].params()
-		var knownEnum *types.T
+		var wildcard, knownEnum *types.T
 
 		// Check we have all "AnyEnum" (or "AnyEnum" array) arguments and that
 		// one argument is typed with an enum.
 		attemptAnyEnumCast := func() bool {
 			for i := 0; i < params.Length(); i++ {
 				typ := params.GetAt(i)
+				if typ.Family() == types.ArrayFamily {
+					typ = typ.ArrayContents()
+				}
 				// Note we are deliberately looking at whether the built-in takes in
 				// AnyEnum as an argument, not the exprs given to the overload itself.
-				if !(typ.Identical(types.AnyEnum) || typ.Identical(types.MakeArray(types.AnyEnum))) {
+				if !(typ.Identical(types.AnyEnum) || types.IsWildcardRangeType(typ)) {
+					return false
+				}
+				if wildcard == nil {
+					wildcard = typ
+				} else if !typ.Identical(wildcard) {
 					return false
 				}
 				if s.typedExprs[i] != nil {
@@ -1078,6 +1093,9 @@ func (s *overloadTypeChecker) typeCheckOverloadedExprs(
 					if posEnum.Family() == types.ArrayFamily {
 						posEnum = posEnum.ArrayContents()
 					}
+					if posEnum.Family() != wildcard.Family() {
+						return false
+					}
 					if knownEnum == nil {
 						knownEnum = posEnum
 					} else if !posEnum.Identical(knownEnum) {
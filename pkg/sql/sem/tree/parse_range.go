@@ -0,0 +1,210 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"bytes"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+var malformedRangeError = pgerror.Newf(pgcode.InvalidTextRepresentation, "malformed range literal")
+var malformedMultirangeError = pgerror.Newf(pgcode.InvalidTextRepresentation, "malformed multirange literal")
+
+const rangeEmptyLiteral = "empty"
+
+func isRangeBoundTerminator(ch byte) bool {
+	return ch == ',' || ch == ')' || ch == ']'
+}
+
+type rangeParseState struct {
+	s                string
+	ctx              ParseContext
+	dependsOnContext bool
+	// t is the range type being parsed.
+	t *types.T
+}
+
+func (p *rangeParseState) eatWhitespace() {
+	for len(p.s) > 0 && unicode.IsSpace(rune(p.s[0])) {
+		p.s = p.s[1:]
+	}
+}
+
+// parseRange parses a range literal, such as [1,10) or empty, from the start
+// of the input.
+func (p *rangeParseState) parseRange() (*DRange, error) {
+	p.eatWhitespace()
+	if isCaseInsensitivePrefix(rangeEmptyLiteral, p.s) {
+		p.s = p.s[len(rangeEmptyLiteral):]
+		return MakeEmptyDRange(p.t), nil
+	}
+	if len(p.s) == 0 || (p.s[0] != '[' && p.s[0] != '(') {
+		return nil, errors.WithDetail(malformedRangeError, "Missing left parenthesis or bracket.")
+	}
+	lowerInc := p.s[0] == '['
+	p.s = p.s[1:]
+	lower, err := p.parseBound()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.s) == 0 || p.s[0] != ',' {
+		return nil, errors.WithDetail(malformedRangeError, "Missing comma after lower bound.")
+	}
+	p.s = p.s[1:]
+	upper, err := p.parseBound()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.s) == 0 || (p.s[0] != ']' && p.s[0] != ')') {
+		return nil, errors.WithDetail(malformedRangeError, "Too many commas.")
+	}
+	upperInc := p.s[0] == ']'
+	p.s = p.s[1:]
+	return NewDRange(p.t, lower, upper, lowerInc, upperInc)
+}
+
+// parseBound parses a range bound from the start of the input, up to the next
+// unquoted comma or closing bracket. A bound which is omitted is infinite, and
+// is returned as DNull. Like in Postgres, a backslash escapes the following
+// character, and double quotes may be used to quote special characters, with
+// "" representing a double quote inside a quoted string.
+func (p *rangeParseState) parseBound() (Datum, error) {
+	if len(p.s) > 0 && isRangeBoundTerminator(p.s[0]) {
+		return DNull, nil
+	}
+	var buf bytes.Buffer
+	inQuote := false
+	for inQuote || len(p.s) == 0 || !isRangeBoundTerminator(p.s[0]) {
+		if len(p.s) == 0 {
+			return nil, errors.WithDetail(malformedRangeError, "Unexpected end of input.")
+		}
+		ch := p.s[0]
+		p.s = p.s[1:]
+		switch ch {
+		case '\\':
+			if len(p.s) == 0 {
+				return nil, errors.WithDetail(malformedRangeError, "Unexpected end of input.")
+			}
+			buf.WriteByte(p.s[0])
+			p.s = p.s[1:]
+		case '"':
+			if !inQuote {
+				inQuote = true
+			} else if len(p.s) > 0 && p.s[0] == '"' {
+				buf.WriteByte('"')
+				p.s = p.s[1:]
+			} else {
+				inQuote = false
+			}
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	d, dependsOnContext, err := ParseAndRequireString(p.t.RangeSubtype(), buf.String(), p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dependsOnContext {
+		p.dependsOnContext = true
+	}
+	return d, nil
+}
+
+// parseMultirange parses a multirange literal of type t, such as
+// {[1,3),[5,7)}, from the start of the input.
+func (p *rangeParseState) parseMultirange(t *types.T) (*DMultirange, error) {
+	p.eatWhitespace()
+	if len(p.s) == 0 || p.s[0] != '{' {
+		return nil, errors.WithDetail(malformedMultirangeError, "Missing left brace.")
+	}
+	p.s = p.s[1:]
+	p.eatWhitespace()
+	var ranges []*DRange
+	if len(p.s) > 0 && p.s[0] == '}' {
+		p.s = p.s[1:]
+	} else {
+		for {
+			r, err := p.parseRange()
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, r)
+			p.eatWhitespace()
+			if len(p.s) == 0 {
+				return nil, errors.WithDetail(malformedMultirangeError, "Unexpected end of input.")
+			}
+			ch := p.s[0]
+			p.s = p.s[1:]
+			if ch == '}' {
+				break
+			}
+			if ch != ',' {
+				return nil, errors.WithDetail(malformedMultirangeError, "Expected comma or end of multirange.")
+			}
+		}
+	}
+	p.eatWhitespace()
+	if len(p.s) > 0 {
+		return nil, errors.WithDetail(malformedMultirangeError, "Junk after closing right brace.")
+	}
+	return NewDMultirange(t, ranges)
+}
+
+// ParseDRangeFromString parses the string-form of a range of type t, such as
+// '[1,10)' or 'empty'.
+//
+// The dependsOnContext return value indicates if we had to consult the
+// ParseContext (either for the time or the local timezone).
+func ParseDRangeFromString(
+	ctx ParseContext, s string, t *types.T,
+) (_ *DRange, dependsOnContext bool, _ error) {
+	// Return a nice error if the input requested type is types.AnyRange.
+	if types.IsWildcardRangeType(t) {
+		return nil, false, errors.New("cannot create range of unspecified type")
+	}
+	p := rangeParseState{s: s, ctx: ctx, t: t}
+	r, err := p.parseRange()
+	if err == nil {
+		p.eatWhitespace()
+		if len(p.s) > 0 {
+			err = errors.WithDetail(malformedRangeError, "Junk after right parenthesis or bracket.")
+		}
+	}
+	if err != nil {
+		return nil, false, MakeParseError(s, t, err)
+	}
+	return r, p.dependsOnContext, nil
+}
+
+// ParseDMultirangeFromString parses the string-form of a multirange of type t,
+// such as '{[1,3),[5,7)}'.
+//
+// The dependsOnContext return value indicates if we had to consult the
+// ParseContext (either for the time or the local timezone).
+func ParseDMultirangeFromString(
+	ctx ParseContext, s string, t *types.T,
+) (_ *DMultirange, dependsOnContext bool, _ error) {
+	// Return a nice error if the input requested type is types.AnyMultirange.
+	if types.IsWildcardRangeType(t) {
+		return nil, false, errors.New("cannot create multirange of unspecified type")
+	}
+	p := rangeParseState{s: s, ctx: ctx, t: t.MultirangeContents()}
+	m, err := p.parseMultirange(t)
+	if err != nil {
+		return nil, false, MakeParseError(s, t, err)
+	}
+	return m, p.dependsOnContext, nil
+}
@@ -81,6 +81,10 @@ func ParseAndRequireString(
 		} else {
 			d, err = ParseDOidAsInt(s)
 		}
+	case types.MultirangeFamily:
+		d, dependsOnContext, err = ParseDMultirangeFromString(ctx, s, t)
+	case types.RangeFamily:
+		d, dependsOnContext, err = ParseDRangeFromString(ctx, s, t)
 	case types.CollatedStringFamily:
 		d, err = NewDCollatedString(s, t.Locale(), ctx.GetCollationEnv())
 	case types.StringFamily:
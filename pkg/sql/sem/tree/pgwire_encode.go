@@ -174,7 +174,7 @@ func (d *DArray) pgwireFormat(ctx *FmtCtx) {
 	}
 }
 
-var tupleQuoteSet, arrayQuoteSet asciiSet
+var tupleQuoteSet, arrayQuoteSet, rangeQuoteSet asciiSet
 
 func init() {
 	var ok bool
@@ -186,6 +186,10 @@ func init() {
 	if !ok {
 		panic("array asciiset")
 	}
+	rangeQuoteSet, ok = makeASCIISet(" \t\v\f\r\n()[],\"\\")
+	if !ok {
+		panic("range asciiset")
+	}
 }
 
 // PgwireFormatFloat returns a []byte representing a float according to
@@ -700,6 +700,12 @@ func (node *AliasedTableExpr) doc(p *PrettyCfg) pretty.Doc {
 			d,
 		)
 	}
+	if node.Only {
+		d = pretty.Concat(
+			p.keywordWithText("", "ONLY", " "),
+			d,
+		)
+	}
 	if node.IndexFlags != nil {
 		d = pretty.Concat(
 			d,
@@ -1278,6 +1284,12 @@ func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 	if node.As() {
 		clauses = append(clauses, p.Doc(node.AsSource))
 	}
+	if len(node.Inherits) > 0 {
+		clauses = append(clauses, pretty.ConcatSpace(
+			pretty.Keyword("INHERITS"),
+			p.bracket("(", p.Doc(&node.Inherits), ")"),
+		))
+	}
 	if node.PartitionByTable != nil {
 		clauses = append(clauses, p.Doc(node.PartitionByTable))
 	}
@@ -1329,6 +1341,12 @@ func (node *CreateView) doc(p *PrettyCfg) pretty.Doc {
 			p.bracket("(", p.Doc(&node.ColumnNames), ")"),
 		)
 	}
+	if node.Params != nil {
+		d = pretty.ConcatSpace(
+			d,
+			p.bracketKeyword("WITH", "(", p.Doc(&node.Params), ")", ""),
+		)
+	}
 	d = p.nestUnder(
 		pretty.ConcatSpace(d, pretty.Keyword("AS")),
 		p.Doc(node.AsSource),
@@ -1762,6 +1780,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//    [NOT VISIBLE]
 	//
@@ -1771,6 +1790,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [DEFERRABLE ...]
 	//    [WHERE ...]
 	//    [NOT VISIBLE]
 	//
@@ -1802,6 +1822,9 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionByIndex != nil {
 		clauses = append(clauses, p.Doc(node.PartitionByIndex))
 	}
+	if node.Deferrable.IsDeferrable() {
+		clauses = append(clauses, pretty.Keyword(node.Deferrable.String()))
+	}
 	if node.Predicate != nil {
 		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
 	}
@@ -1822,6 +1845,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl (...)
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	// or (no constraint name):
 	//
@@ -1829,6 +1853,7 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    REFERENCES tbl [(...)]
 	//    [MATCH ...]
 	//    [ACTIONS ...]
+	//    [DEFERRABLE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 4)
 	title := pretty.ConcatSpace(
@@ -1855,6 +1880,10 @@ func (node *ForeignKeyConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 		clauses = append(clauses, actions)
 	}
 
+	if node.Deferrable.IsDeferrable() {
+		clauses = append(clauses, pretty.Keyword(node.Deferrable.String()))
+	}
+
 	return p.nestUnder(title, pretty.Group(pretty.Stack(clauses...)))
 }
 
@@ -2305,6 +2334,22 @@ func (node *CoalesceExpr) doc(p *PrettyCfg) pretty.Doc {
 	)
 }
 
+func (node *GroupingExpr) doc(p *PrettyCfg) pretty.Doc {
+	return p.bracketKeyword(
+		"GROUPING", "(",
+		p.Doc(&node.Exprs),
+		")", "",
+	)
+}
+
+func (node *GroupingSets) doc(p *PrettyCfg) pretty.Doc {
+	return p.bracketKeyword(
+		node.Type.String(), " (",
+		p.Doc(&node.Exprs),
+		")", "",
+	)
+}
+
 func (node *AlterTable) doc(p *PrettyCfg) pretty.Doc {
 	title := pretty.Keyword("ALTER TABLE")
 	if node.IfExists {
@@ -0,0 +1,1008 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// DRange is the Datum for the built-in range types, such as int4range and
+// tstzrange. A range is either empty, or it has a lower and an upper bound,
+// each of which is inclusive, exclusive or infinite.
+//
+// DRanges are canonicalized when they are constructed: ranges which contain no
+// values are empty, infinite bounds are exclusive, and the bounds of ranges
+// over discrete subtypes (int4, int8 and date) are in the [) form. As a result,
+// two ranges are equal if and only if they contain the same values.
+type DRange struct {
+	Typ *types.T
+	// Lower and Upper are the bounds of the range. An infinite bound is DNull.
+	// Both bounds of an empty range are DNull.
+	Lower, Upper Datum
+	// LowerInc and UpperInc are true if the respective bound is inclusive.
+	LowerInc, UpperInc bool
+	// Empty is true if the range contains no values.
+	Empty bool
+}
+
+// Flags describing a range in its Postgres binary encoding, which are also
+// used in the value encoding of ranges.
+const (
+	RangeFlagEmpty    byte = 0x01
+	RangeFlagLowerInc byte = 0x02
+	RangeFlagUpperInc byte = 0x04
+	RangeFlagLowerInf byte = 0x08
+	RangeFlagUpperInf byte = 0x10
+)
+
+// Flags returns the flags describing the range in its binary encodings. Only
+// finite bounds are encoded after the flags.
+func (d *DRange) Flags() byte {
+	if d.Empty {
+		return RangeFlagEmpty
+	}
+	var flags byte
+	if d.LowerInc {
+		flags |= RangeFlagLowerInc
+	}
+	if d.UpperInc {
+		flags |= RangeFlagUpperInc
+	}
+	if d.Lower == DNull {
+		flags |= RangeFlagLowerInf
+	}
+	if d.Upper == DNull {
+		flags |= RangeFlagUpperInf
+	}
+	return flags
+}
+
+// rangeCompareContext is the CompareContext used to compare the bounds of
+// ranges. The bounds of a range always have the range's subtype, and
+// comparing them does not depend on the session, so ranges can be compared
+// and combined without an evaluation context.
+type rangeCompareContext struct{}
+
+var _ CompareContext = rangeCompareContext{}
+
+// UnwrapDatum implements the CompareContext interface.
+func (rangeCompareContext) UnwrapDatum(d Datum) Datum {
+	return UnwrapDOidWrapper(d)
+}
+
+// GetLocation implements the CompareContext interface.
+func (rangeCompareContext) GetLocation() *time.Location {
+	return time.UTC
+}
+
+// GetRelativeParseTime implements the CompareContext interface.
+func (rangeCompareContext) GetRelativeParseTime() time.Time {
+	return time.Time{}
+}
+
+// MustGetPlaceholderValue implements the CompareContext interface.
+func (rangeCompareContext) MustGetPlaceholderValue(p *Placeholder) Datum {
+	panic(errors.AssertionFailedf("unexpected placeholder %s in range bound", p))
+}
+
+// MakeEmptyDRange returns the empty range of the given range type.
+func MakeEmptyDRange(typ *types.T) *DRange {
+	return &DRange{Typ: typ, Lower: DNull, Upper: DNull, Empty: true}
+}
+
+// NewDRange returns the canonical range of the given range type with the given
+// bounds. A DNull bound is infinite. An error is returned if the lower bound
+// is greater than the upper bound.
+func NewDRange(typ *types.T, lower, upper Datum, lowerInc, upperInc bool) (*DRange, error) {
+	if lower == DNull {
+		lowerInc = false
+	}
+	if upper == DNull {
+		upperInc = false
+	}
+	if lower != DNull && upper != DNull {
+		cmp := lower.Compare(rangeCompareContext{}, upper)
+		if cmp > 0 {
+			return nil, pgerror.New(pgcode.DataException,
+				"range lower bound must be less than or equal to range upper bound",
+			)
+		}
+		if cmp == 0 && !(lowerInc && upperInc) {
+			return MakeEmptyDRange(typ), nil
+		}
+	}
+	if subtype := typ.RangeSubtype(); !typ.UserDefined() && isDiscreteRangeSubtype(subtype) {
+		// Convert the bounds of discrete ranges to the [) form, so that equal
+		// ranges have equal bounds. Like in Postgres, user-defined range types
+		// are not canonicalized, since they have no canonical function.
+		var err error
+		if lower != DNull && !lowerInc {
+			if lower, err = rangeSubtypeNext(subtype, lower); err != nil {
+				return nil, err
+			}
+			lowerInc = true
+		}
+		if upper != DNull && upperInc {
+			if upper, err = rangeSubtypeNext(subtype, upper); err != nil {
+				return nil, err
+			}
+			upperInc = false
+		}
+		if lower != DNull && upper != DNull && lower.Compare(rangeCompareContext{}, upper) == 0 {
+			return MakeEmptyDRange(typ), nil
+		}
+	}
+	return &DRange{
+		Typ:      typ,
+		Lower:    lower,
+		Upper:    upper,
+		LowerInc: lowerInc,
+		UpperInc: upperInc,
+	}, nil
+}
+
+// isDiscreteRangeSubtype returns true if ranges over the given subtype are
+// canonicalized to the [) form.
+func isDiscreteRangeSubtype(subtype *types.T) bool {
+	switch subtype.Family() {
+	case types.IntFamily, types.DateFamily:
+		return true
+	}
+	return false
+}
+
+// rangeSubtypeNext returns the value following d in a discrete range subtype.
+func rangeSubtypeNext(subtype *types.T, d Datum) (Datum, error) {
+	switch t := UnwrapDOidWrapper(d).(type) {
+	case *DInt:
+		if (subtype.Width() == 32 && *t >= math.MaxInt32) || *t == math.MaxInt64 {
+			return nil, ErrIntOutOfRange
+		}
+		return NewDInt(*t + 1), nil
+	case *DDate:
+		// Infinite dates are left alone, like in Postgres.
+		date, err := t.Date.AddDays(1)
+		if err != nil {
+			return nil, err
+		}
+		return NewDDate(date), nil
+	}
+	return nil, errors.AssertionFailedf("unexpected discrete range bound %T", d)
+}
+
+// rangeBound is one bound of a non-empty range. Bounds of different ranges are
+// compared with each other to implement the range operators.
+type rangeBound struct {
+	// val is DNull if the bound is infinite.
+	val   Datum
+	inc   bool
+	lower bool
+}
+
+func (b rangeBound) infinite() bool {
+	return b.val == DNull
+}
+
+func (d *DRange) lowerBound() rangeBound {
+	return rangeBound{val: d.Lower, inc: d.LowerInc, lower: true}
+}
+
+func (d *DRange) upperBound() rangeBound {
+	return rangeBound{val: d.Upper, inc: d.UpperInc, lower: false}
+}
+
+// compareRangeBoundValues compares the values of two bounds, ignoring whether
+// they are inclusive. An infinite lower bound is less than any other value, and
+// an infinite upper bound is greater than any other value.
+func compareRangeBoundValues(b1, b2 rangeBound) int {
+	switch {
+	case b1.infinite() && b2.infinite():
+		if b1.lower == b2.lower {
+			return 0
+		}
+		if b1.lower {
+			return -1
+		}
+		return 1
+	case b1.infinite():
+		if b1.lower {
+			return -1
+		}
+		return 1
+	case b2.infinite():
+		if b2.lower {
+			return 1
+		}
+		return -1
+	}
+	return b1.val.Compare(rangeCompareContext{}, b2.val)
+}
+
+// compareRangeBounds compares two bounds by the position of the first value
+// they admit (for lower bounds) or the last value they admit (for upper
+// bounds). For example, the lower bound (1 is greater than the lower bound
+// [1, and the upper bound 1) is less than the lower bound [1.
+func compareRangeBounds(b1, b2 rangeBound) int {
+	cmp := compareRangeBoundValues(b1, b2)
+	if cmp != 0 || b1.infinite() {
+		return cmp
+	}
+	switch {
+	case !b1.inc && !b2.inc:
+		if b1.lower == b2.lower {
+			return 0
+		}
+		if b1.lower {
+			return 1
+		}
+		return -1
+	case !b1.inc:
+		if b1.lower {
+			return 1
+		}
+		return -1
+	case !b2.inc:
+		if b2.lower {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// boundsAdjacent returns true if the upper bound upper is immediately followed
+// by the lower bound lower, with no values in between.
+func boundsAdjacent(upper, lower rangeBound) bool {
+	if upper.infinite() || lower.infinite() {
+		return false
+	}
+	return compareRangeBoundValues(upper, lower) == 0 && upper.inc != lower.inc
+}
+
+func makeRangeFromBounds(typ *types.T, lower, upper rangeBound) (*DRange, error) {
+	return NewDRange(typ, lower.val, upper.val, lower.inc, upper.inc)
+}
+
+// compare orders ranges by their lower bounds, then by their upper bounds.
+// Empty ranges sort before all other ranges.
+func (d *DRange) compare(o *DRange) int {
+	if d.Empty || o.Empty {
+		switch {
+		case d.Empty && o.Empty:
+			return 0
+		case d.Empty:
+			return -1
+		}
+		return 1
+	}
+	if cmp := compareRangeBounds(d.lowerBound(), o.lowerBound()); cmp != 0 {
+		return cmp
+	}
+	return compareRangeBounds(d.upperBound(), o.upperBound())
+}
+
+// ContainsElem returns true if the value v, which must have the range's
+// subtype, is in the range.
+func (d *DRange) ContainsElem(v Datum) bool {
+	if d.Empty {
+		return false
+	}
+	if d.Lower != DNull {
+		cmp := d.Lower.Compare(rangeCompareContext{}, v)
+		if cmp > 0 || (cmp == 0 && !d.LowerInc) {
+			return false
+		}
+	}
+	if d.Upper != DNull {
+		cmp := d.Upper.Compare(rangeCompareContext{}, v)
+		if cmp < 0 || (cmp == 0 && !d.UpperInc) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsRange returns true if every value of o is in d. The empty range is
+// contained in every range.
+func (d *DRange) ContainsRange(o *DRange) bool {
+	if o.Empty {
+		return true
+	}
+	if d.Empty {
+		return false
+	}
+	return compareRangeBounds(d.lowerBound(), o.lowerBound()) <= 0 &&
+		compareRangeBounds(d.upperBound(), o.upperBound()) >= 0
+}
+
+// Overlaps returns true if d and o have any values in common.
+func (d *DRange) Overlaps(o *DRange) bool {
+	if d.Empty || o.Empty {
+		return false
+	}
+	if compareRangeBounds(d.lowerBound(), o.lowerBound()) >= 0 {
+		return compareRangeBounds(d.lowerBound(), o.upperBound()) <= 0
+	}
+	return compareRangeBounds(o.lowerBound(), d.upperBound()) <= 0
+}
+
+// StrictlyLeftOf returns true if every value of d is less than every value of
+// o. It is false if either range is empty.
+func (d *DRange) StrictlyLeftOf(o *DRange) bool {
+	if d.Empty || o.Empty {
+		return false
+	}
+	return compareRangeBounds(d.upperBound(), o.lowerBound()) < 0
+}
+
+// StrictlyRightOf returns true if every value of d is greater than every value
+// of o. It is false if either range is empty.
+func (d *DRange) StrictlyRightOf(o *DRange) bool {
+	return o.StrictlyLeftOf(d)
+}
+
+// Adjacent returns true if d and o do not overlap, and there is no value
+// between them. It is false if either range is empty.
+func (d *DRange) Adjacent(o *DRange) bool {
+	if d.Empty || o.Empty {
+		return false
+	}
+	return boundsAdjacent(d.upperBound(), o.lowerBound()) ||
+		boundsAdjacent(o.upperBound(), d.lowerBound())
+}
+
+// span returns the smallest range containing both d and o, neither of which
+// may be empty.
+func (d *DRange) span(o *DRange) (*DRange, error) {
+	lower, upper := d.lowerBound(), d.upperBound()
+	if compareRangeBounds(o.lowerBound(), lower) < 0 {
+		lower = o.lowerBound()
+	}
+	if compareRangeBounds(o.upperBound(), upper) > 0 {
+		upper = o.upperBound()
+	}
+	return makeRangeFromBounds(d.Typ, lower, upper)
+}
+
+// Union returns the union of d and o. An error is returned if the ranges
+// neither overlap nor are adjacent, since their union is not a range.
+func (d *DRange) Union(o *DRange) (*DRange, error) {
+	if d.Empty {
+		return o, nil
+	}
+	if o.Empty {
+		return d, nil
+	}
+	if !d.Overlaps(o) && !d.Adjacent(o) {
+		return nil, pgerror.New(pgcode.DataException,
+			"result of range union would not be contiguous",
+		)
+	}
+	return d.span(o)
+}
+
+// Merge returns the smallest range containing both d and o. Unlike Union, the
+// ranges need not overlap or be adjacent.
+func (d *DRange) Merge(o *DRange) (*DRange, error) {
+	if d.Empty {
+		return o, nil
+	}
+	if o.Empty {
+		return d, nil
+	}
+	return d.span(o)
+}
+
+// Intersect returns the intersection of d and o.
+func (d *DRange) Intersect(o *DRange) (*DRange, error) {
+	if !d.Overlaps(o) {
+		return MakeEmptyDRange(d.Typ), nil
+	}
+	lower, upper := d.lowerBound(), d.upperBound()
+	if compareRangeBounds(o.lowerBound(), lower) > 0 {
+		lower = o.lowerBound()
+	}
+	if compareRangeBounds(o.upperBound(), upper) < 0 {
+		upper = o.upperBound()
+	}
+	return makeRangeFromBounds(d.Typ, lower, upper)
+}
+
+// Difference returns the values of d which are not in o. An error is returned
+// if o is strictly inside d, since the difference is not a range.
+func (d *DRange) Difference(o *DRange) (*DRange, error) {
+	pieces, err := d.subtract(o)
+	if err != nil {
+		return nil, err
+	}
+	switch len(pieces) {
+	case 0:
+		return MakeEmptyDRange(d.Typ), nil
+	case 1:
+		return pieces[0], nil
+	}
+	return nil, pgerror.New(pgcode.DataException,
+		"result of range difference would not be contiguous",
+	)
+}
+
+// subtract returns the non-empty ranges which remain after removing the values
+// of o from d. There are at most two of them, in ascending order.
+func (d *DRange) subtract(o *DRange) ([]*DRange, error) {
+	if d.Empty {
+		return nil, nil
+	}
+	if !d.Overlaps(o) {
+		return []*DRange{d}, nil
+	}
+	var pieces []*DRange
+	if compareRangeBounds(d.lowerBound(), o.lowerBound()) < 0 {
+		upper := o.lowerBound()
+		upper.inc, upper.lower = !upper.inc, false
+		r, err := makeRangeFromBounds(d.Typ, d.lowerBound(), upper)
+		if err != nil {
+			return nil, err
+		}
+		if !r.Empty {
+			pieces = append(pieces, r)
+		}
+	}
+	if compareRangeBounds(d.upperBound(), o.upperBound()) > 0 {
+		lower := o.upperBound()
+		lower.inc, lower.lower = !lower.inc, true
+		r, err := makeRangeFromBounds(d.Typ, lower, d.upperBound())
+		if err != nil {
+			return nil, err
+		}
+		if !r.Empty {
+			pieces = append(pieces, r)
+		}
+	}
+	return pieces, nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DRange) ResolvedType() *types.T {
+	return d.Typ
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (d *DRange) AmbiguousFormat() bool { return true }
+
+// Compare implements the Datum interface.
+func (d *DRange) Compare(ctx CompareContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface.
+func (d *DRange) CompareError(ctx CompareContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	v, ok := ctx.UnwrapDatum(other).(*DRange)
+	if !ok || !d.Typ.Equivalent(v.Typ) {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	return d.compare(v), nil
+}
+
+// Prev implements the Datum interface.
+func (d *DRange) Prev(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DRange) Next(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMin implements the Datum interface.
+func (d *DRange) IsMin(_ CompareContext) bool {
+	return d.Empty
+}
+
+// IsMax implements the Datum interface.
+func (d *DRange) IsMax(_ CompareContext) bool {
+	return false
+}
+
+// Max implements the Datum interface.
+func (d *DRange) Max(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DRange) Min(_ CompareContext) (Datum, bool) {
+	return MakeEmptyDRange(d.Typ), true
+}
+
+// Size implements the Datum interface.
+func (d *DRange) Size() uintptr {
+	sz := unsafe.Sizeof(*d)
+	if d.Lower != DNull {
+		sz += d.Lower.Size()
+	}
+	if d.Upper != DNull {
+		sz += d.Upper.Size()
+	}
+	return sz
+}
+
+// IsComposite implements the CompositeDatum interface.
+func (d *DRange) IsComposite() bool {
+	for _, b := range []Datum{d.Lower, d.Upper} {
+		if cdatum, ok := b.(CompositeDatum); ok && cdatum.IsComposite() {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements the NodeFormatter interface.
+func (d *DRange) Format(ctx *FmtCtx) {
+	if ctx.flags.HasAnyFlags(fmtPgwireFormat | FmtPGCatalog) {
+		d.pgwireFormat(ctx)
+		return
+	}
+	formatRangeText(ctx, func(buf *bytes.Buffer) {
+		d.writeText(buf, ctx)
+	})
+}
+
+// writeText writes the Postgres text representation of the range to buf, such
+// as [1,10) or empty.
+func (d *DRange) writeText(buf *bytes.Buffer, ctx *FmtCtx) {
+	if d.Empty {
+		buf.WriteString("empty")
+		return
+	}
+	if d.LowerInc {
+		buf.WriteByte('[')
+	} else {
+		buf.WriteByte('(')
+	}
+	if d.Lower != DNull {
+		writeRangeBoundText(buf, ctx, d.Lower)
+	}
+	buf.WriteByte(',')
+	if d.Upper != DNull {
+		writeRangeBoundText(buf, ctx, d.Upper)
+	}
+	if d.UpperInc {
+		buf.WriteByte(']')
+	} else {
+		buf.WriteByte(')')
+	}
+}
+
+func (d *DRange) pgwireFormat(ctx *FmtCtx) {
+	pgCatalog := ctx.HasFlags(FmtPGCatalog)
+	if pgCatalog {
+		ctx.WriteByte('\'')
+	}
+	var buf bytes.Buffer
+	d.writeText(&buf, ctx)
+	if pgCatalog {
+		ctx.WriteString(strings.ReplaceAll(buf.String(), `'`, `''`))
+		ctx.WriteByte('\'')
+	} else {
+		ctx.Write(buf.Bytes())
+	}
+}
+
+// writeRangeBoundText writes the text representation of a finite range bound
+// to buf. The bound is double quoted if it would otherwise not be parsed back
+// as the same value, with " and \ doubled like in Postgres.
+func writeRangeBoundText(buf *bytes.Buffer, ctx *FmtCtx, bound Datum) {
+	s := AsStringWithFlags(
+		bound, FmtPgwireText, FmtDataConversionConfig(ctx.dataConversionConfig), FmtLocation(ctx.location),
+	)
+	quote := s == "" || rangeQuoteSet.in(s)
+	if quote {
+		buf.WriteByte('"')
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf.WriteByte(s[i])
+		}
+		buf.WriteByte(s[i])
+	}
+	if quote {
+		buf.WriteByte('"')
+	}
+}
+
+// formatRangeText formats the text representation of a range or multirange
+// written by writeText as a SQL string literal.
+func formatRangeText(ctx *FmtCtx, writeText func(buf *bytes.Buffer)) {
+	bareStrings := ctx.HasFlags(FmtFlags(lexbase.EncBareStrings))
+	var buf bytes.Buffer
+	writeText(&buf)
+	if bareStrings {
+		ctx.Write(buf.Bytes())
+		return
+	}
+	ctx.WriteByte('\'')
+	ctx.WriteString(strings.ReplaceAll(buf.String(), `'`, `''`))
+	ctx.WriteByte('\'')
+}
+
+// AsDRange attempts to retrieve a DRange from an Expr, returning a DRange and
+// a flag signifying whether the assertion was successful. The function should
+// be used instead of direct type assertions wherever a *DRange wrapped by a
+// *DOidWrapper is possible.
+func AsDRange(e Expr) (*DRange, bool) {
+	switch t := e.(type) {
+	case *DRange:
+		return t, true
+	case *DOidWrapper:
+		return AsDRange(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDRange attempts to retrieve a DRange from an Expr, panicking if the
+// assertion fails.
+func MustBeDRange(e Expr) *DRange {
+	v, ok := AsDRange(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DRange, found %T", e))
+	}
+	return v
+}
+
+// DMultirange is the Datum for the built-in multirange types, such as
+// int4multirange. A multirange is an ordered list of non-empty ranges, no two
+// of which overlap or are adjacent.
+type DMultirange struct {
+	Typ    *types.T
+	Ranges []*DRange
+}
+
+// NewDMultirange returns the canonical multirange of the given multirange type
+// which contains the values of all the given ranges. The ranges must have the
+// multirange's range type.
+func NewDMultirange(typ *types.T, ranges []*DRange) (*DMultirange, error) {
+	sorted := make([]*DRange, 0, len(ranges))
+	for _, r := range ranges {
+		if !r.Empty {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].compare(sorted[j]) < 0
+	})
+	d := &DMultirange{Typ: typ, Ranges: make([]*DRange, 0, len(sorted))}
+	for _, r := range sorted {
+		if n := len(d.Ranges); n > 0 {
+			if last := d.Ranges[n-1]; last.Overlaps(r) || last.Adjacent(r) {
+				merged, err := last.span(r)
+				if err != nil {
+					return nil, err
+				}
+				d.Ranges[n-1] = merged
+				continue
+			}
+		}
+		d.Ranges = append(d.Ranges, r)
+	}
+	return d, nil
+}
+
+// MakeDMultirangeFromRange returns the multirange of the given multirange type
+// which contains the values of r.
+func MakeDMultirangeFromRange(typ *types.T, r *DRange) *DMultirange {
+	if r.Empty {
+		return &DMultirange{Typ: typ}
+	}
+	return &DMultirange{Typ: typ, Ranges: []*DRange{r}}
+}
+
+// IsEmpty returns true if the multirange contains no values.
+func (d *DMultirange) IsEmpty() bool {
+	return len(d.Ranges) == 0
+}
+
+// Span returns the smallest range containing all of the ranges in the
+// multirange.
+func (d *DMultirange) Span() (*DRange, error) {
+	if d.IsEmpty() {
+		return MakeEmptyDRange(d.Typ.MultirangeContents()), nil
+	}
+	return d.Ranges[0].span(d.Ranges[len(d.Ranges)-1])
+}
+
+// ContainsElem returns true if the value v, which must have the multirange's
+// range subtype, is in the multirange.
+func (d *DMultirange) ContainsElem(v Datum) bool {
+	for _, r := range d.Ranges {
+		if r.ContainsElem(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsRange returns true if every value of r is in d.
+func (d *DMultirange) ContainsRange(r *DRange) bool {
+	if r.Empty {
+		return true
+	}
+	for _, dr := range d.Ranges {
+		if dr.ContainsRange(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsMultirange returns true if every value of o is in d.
+func (d *DMultirange) ContainsMultirange(o *DMultirange) bool {
+	for _, r := range o.Ranges {
+		if !d.ContainsRange(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Overlaps returns true if d and o have any values in common.
+func (d *DMultirange) Overlaps(o *DMultirange) bool {
+	for _, r1 := range d.Ranges {
+		for _, r2 := range o.Ranges {
+			if r1.Overlaps(r2) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StrictlyLeftOf returns true if every value of d is less than every value of
+// o. It is false if either multirange is empty.
+func (d *DMultirange) StrictlyLeftOf(o *DMultirange) bool {
+	if d.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+	return d.Ranges[len(d.Ranges)-1].StrictlyLeftOf(o.Ranges[0])
+}
+
+// StrictlyRightOf returns true if every value of d is greater than every value
+// of o. It is false if either multirange is empty.
+func (d *DMultirange) StrictlyRightOf(o *DMultirange) bool {
+	return o.StrictlyLeftOf(d)
+}
+
+// Adjacent returns true if the smallest ranges containing d and o are
+// adjacent. It is false if either multirange is empty.
+func (d *DMultirange) Adjacent(o *DMultirange) bool {
+	if d.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+	return d.Ranges[len(d.Ranges)-1].Adjacent(o.Ranges[0]) ||
+		o.Ranges[len(o.Ranges)-1].Adjacent(d.Ranges[0])
+}
+
+// Union returns the multirange containing the values of both d and o.
+func (d *DMultirange) Union(o *DMultirange) (*DMultirange, error) {
+	ranges := make([]*DRange, 0, len(d.Ranges)+len(o.Ranges))
+	ranges = append(ranges, d.Ranges...)
+	ranges = append(ranges, o.Ranges...)
+	return NewDMultirange(d.Typ, ranges)
+}
+
+// Intersect returns the multirange containing the values which are in both d
+// and o.
+func (d *DMultirange) Intersect(o *DMultirange) (*DMultirange, error) {
+	var ranges []*DRange
+	for _, r1 := range d.Ranges {
+		for _, r2 := range o.Ranges {
+			r, err := r1.Intersect(r2)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	return NewDMultirange(d.Typ, ranges)
+}
+
+// Difference returns the multirange containing the values of d which are not
+// in o.
+func (d *DMultirange) Difference(o *DMultirange) (*DMultirange, error) {
+	var ranges []*DRange
+	for _, r := range d.Ranges {
+		pieces := []*DRange{r}
+		for _, sub := range o.Ranges {
+			var remaining []*DRange
+			for _, p := range pieces {
+				res, err := p.subtract(sub)
+				if err != nil {
+					return nil, err
+				}
+				remaining = append(remaining, res...)
+			}
+			pieces = remaining
+		}
+		ranges = append(ranges, pieces...)
+	}
+	return NewDMultirange(d.Typ, ranges)
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DMultirange) ResolvedType() *types.T {
+	return d.Typ
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (d *DMultirange) AmbiguousFormat() bool { return true }
+
+// Compare implements the Datum interface.
+func (d *DMultirange) Compare(ctx CompareContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface. Multiranges are compared range
+// by range, and a multirange sorts before any multirange it is a prefix of.
+func (d *DMultirange) CompareError(ctx CompareContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	v, ok := ctx.UnwrapDatum(other).(*DMultirange)
+	if !ok || !d.Typ.Equivalent(v.Typ) {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	for i := 0; i < len(d.Ranges) && i < len(v.Ranges); i++ {
+		if cmp := d.Ranges[i].compare(v.Ranges[i]); cmp != 0 {
+			return cmp, nil
+		}
+	}
+	switch {
+	case len(d.Ranges) < len(v.Ranges):
+		return -1, nil
+	case len(d.Ranges) > len(v.Ranges):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Prev implements the Datum interface.
+func (d *DMultirange) Prev(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DMultirange) Next(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMin implements the Datum interface.
+func (d *DMultirange) IsMin(_ CompareContext) bool {
+	return d.IsEmpty()
+}
+
+// IsMax implements the Datum interface.
+func (d *DMultirange) IsMax(_ CompareContext) bool {
+	return false
+}
+
+// Max implements the Datum interface.
+func (d *DMultirange) Max(_ CompareContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DMultirange) Min(_ CompareContext) (Datum, bool) {
+	return &DMultirange{Typ: d.Typ}, true
+}
+
+// Size implements the Datum interface.
+func (d *DMultirange) Size() uintptr {
+	sz := unsafe.Sizeof(*d)
+	for _, r := range d.Ranges {
+		sz += r.Size()
+	}
+	return sz
+}
+
+// IsComposite implements the CompositeDatum interface.
+func (d *DMultirange) IsComposite() bool {
+	for _, r := range d.Ranges {
+		if r.IsComposite() {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements the NodeFormatter interface.
+func (d *DMultirange) Format(ctx *FmtCtx) {
+	if ctx.flags.HasAnyFlags(fmtPgwireFormat | FmtPGCatalog) {
+		d.pgwireFormat(ctx)
+		return
+	}
+	formatRangeText(ctx, func(buf *bytes.Buffer) {
+		d.writeText(buf, ctx)
+	})
+}
+
+// writeText writes the Postgres text representation of the multirange to
+// buf, such as {[1,3),[5,7)}.
+func (d *DMultirange) writeText(buf *bytes.Buffer, ctx *FmtCtx) {
+	buf.WriteByte('{')
+	for i, r := range d.Ranges {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		r.writeText(buf, ctx)
+	}
+	buf.WriteByte('}')
+}
+
+func (d *DMultirange) pgwireFormat(ctx *FmtCtx) {
+	pgCatalog := ctx.HasFlags(FmtPGCatalog)
+	if pgCatalog {
+		ctx.WriteByte('\'')
+	}
+	var buf bytes.Buffer
+	d.writeText(&buf, ctx)
+	if pgCatalog {
+		ctx.WriteString(strings.ReplaceAll(buf.String(), `'`, `''`))
+		ctx.WriteByte('\'')
+	} else {
+		ctx.Write(buf.Bytes())
+	}
+}
+
+// AsDMultirange attempts to retrieve a DMultirange from an Expr, returning a
+// DMultirange and a flag signifying whether the assertion was successful. The
+// function should be used instead of direct type assertions wherever a
+// *DMultirange wrapped by a *DOidWrapper is possible.
+func AsDMultirange(e Expr) (*DMultirange, bool) {
+	switch t := e.(type) {
+	case *DMultirange:
+		return t, true
+	case *DOidWrapper:
+		return AsDMultirange(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDMultirange attempts to retrieve a DMultirange from an Expr, panicking
+// if the assertion fails.
+func MustBeDMultirange(e Expr) *DMultirange {
+	v, ok := AsDMultirange(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DMultirange, found %T", e))
+	}
+	return v
+}
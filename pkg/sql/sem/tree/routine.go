@@ -42,6 +42,11 @@ type RoutinePlan interface{}
 // avoid import cycles.
 type RoutineExecFactory interface{}
 
+// RoutineProgram is a compiled PL/pgSQL function body. It currently maps to
+// *plpgsqltree.Program. We use the empty interface here rather than
+// *plpgsqltree.Program to avoid import cycles.
+type RoutineProgram interface{}
+
 // RoutineExpr represents sequential execution of multiple statements. For
 // example, it is used to represent execution of statements in the body of a
 // user-defined function. It is only created by execbuilder - it is never
@@ -102,6 +107,16 @@ type RoutineExpr struct {
 	// Strict non-set-returning routines are not invoked when their arguments
 	// are NULL because optbuilder wraps them in a CASE expressions.
 	CalledOnNullInput bool
+
+	// Program, if non-nil, is the compiled body of a PL/pgSQL function. The
+	// routine is evaluated by interpreting the program rather than by
+	// executing the plans generated by ForEachPlan, which is nil.
+	Program RoutineProgram
+
+	// ProgramExprs contains a routine for each expression of Program, in the
+	// order of their ExprOrdinals. The arguments to each of these routines are
+	// the current values of the variables of the program.
+	ProgramExprs []*RoutineExpr
 }
 
 // NewTypedRoutineExpr returns a new RoutineExpr that is well-typed.
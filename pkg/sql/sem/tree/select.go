@@ -572,7 +572,10 @@ type AliasedTableExpr struct {
 	IndexFlags *IndexFlags
 	Ordinality bool
 	Lateral    bool
-	As         AliasClause
+	// Only is set if the rows of tables inheriting from the named table are
+	// excluded, as in "SELECT * FROM ONLY t".
+	Only bool
+	As   AliasClause
 }
 
 // Format implements the NodeFormatter interface.
@@ -580,6 +583,9 @@ func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
 	if node.Lateral {
 		ctx.WriteString("LATERAL ")
 	}
+	if node.Only {
+		ctx.WriteString("ONLY ")
+	}
 	ctx.FormatNode(node.Expr)
 	if node.IndexFlags != nil {
 		ctx.FormatNode(node.IndexFlags)
@@ -758,6 +764,46 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetsType is the type of a GroupingSets item.
+type GroupingSetsType int
+
+const (
+	// GroupingSetsList is an explicit list of grouping sets, specified by
+	// GROUPING SETS (...).
+	GroupingSetsList GroupingSetsType = iota
+	// GroupingSetsRollup is specified by ROLLUP (...).
+	GroupingSetsRollup
+	// GroupingSetsCube is specified by CUBE (...).
+	GroupingSetsCube
+)
+
+var groupingSetsTypeName = [...]string{
+	GroupingSetsList:   "GROUPING SETS",
+	GroupingSetsRollup: "ROLLUP",
+	GroupingSetsCube:   "CUBE",
+}
+
+func (t GroupingSetsType) String() string {
+	return groupingSetsTypeName[t]
+}
+
+// GroupingSets represents a GROUPING SETS, ROLLUP or CUBE item of a GROUP BY
+// clause. Within a GroupingSets item, a Tuple element stands for a single unit
+// made of its elements; in particular, the empty tuple stands for the empty
+// grouping set. Only GROUPING SETS may contain nested GroupingSets items.
+type GroupingSets struct {
+	Type  GroupingSetsType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSets) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Type.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
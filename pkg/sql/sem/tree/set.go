@@ -102,6 +102,31 @@ func (node *SetTransaction) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&node.Modes)
 }
 
+// SetConstraints represents a SET CONSTRAINTS statement.
+type SetConstraints struct {
+	// All is true for SET CONSTRAINTS ALL. Otherwise, Names contains the
+	// constraints whose checking mode is set.
+	All   bool
+	Names NameList
+	// Deferred is true for DEFERRED and false for IMMEDIATE.
+	Deferred bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *SetConstraints) Format(ctx *FmtCtx) {
+	ctx.WriteString("SET CONSTRAINTS ")
+	if node.All {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.FormatNode(&node.Names)
+	}
+	if node.Deferred {
+		ctx.WriteString(" DEFERRED")
+	} else {
+		ctx.WriteString(" IMMEDIATE")
+	}
+}
+
 // SetSessionAuthorizationDefault represents a SET SESSION AUTHORIZATION DEFAULT
 // statement. This can be extended (and renamed) if we ever support names in the
 // last position.
@@ -1323,14 +1323,20 @@ func (s ShowCompletions) Format(ctx *FmtCtx) {
 
 var _ Statement = &ShowCompletions{}
 
-// ShowCreateFunction represents a SHOW CREATE FUNCTION statement.
+// ShowCreateFunction represents a SHOW CREATE FUNCTION or SHOW CREATE
+// PROCEDURE statement.
 type ShowCreateFunction struct {
-	Name ResolvableFunctionReference
+	Name        ResolvableFunctionReference
+	IsProcedure bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *ShowCreateFunction) Format(ctx *FmtCtx) {
-	ctx.WriteString("SHOW CREATE FUNCTION ")
+	if node.IsProcedure {
+		ctx.WriteString("SHOW CREATE PROCEDURE ")
+	} else {
+		ctx.WriteString("SHOW CREATE FUNCTION ")
+	}
 	ctx.FormatNode(&node.Name)
 }
 
@@ -141,6 +141,9 @@ func CanWriteData(stmt Statement) bool {
 	// Normal write operations.
 	case *Insert, *Delete, *Update, *Truncate:
 		return true
+	// Notifications are published by writing to a system table.
+	case *Notify:
+		return true
 	// Import operations.
 	case *CopyFrom, *Import, *Restore:
 		return true
@@ -495,6 +498,17 @@ func (*AlterTenantService) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*AlterTenantService) StatementTag() string { return "ALTER TENANT SERVICE" }
 
+// StatementReturnType implements the Statement interface.
+func (*AlterDomain) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterDomain) StatementType() StatementType { return TypeDDL }
+
+// StatementTag implements the Statement interface.
+func (*AlterDomain) StatementTag() string { return "ALTER DOMAIN" }
+
+func (*AlterDomain) hiddenFromShowQueries() {}
+
 // StatementReturnType implements the Statement interface.
 func (*AlterType) StatementReturnType() StatementReturnType { return DDL }
 
@@ -880,6 +894,28 @@ func (n *CreateTable) StatementTag() string {
 // modifiesSchema implements the canModifySchema interface.
 func (*CreateTable) modifiesSchema() bool { return true }
 
+// StatementReturnType implements the Statement interface.
+func (*CreateDomain) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreateDomain) StatementType() StatementType { return TypeDDL }
+
+// StatementTag implements the Statement interface.
+func (*CreateDomain) StatementTag() string { return "CREATE DOMAIN" }
+
+func (*CreateDomain) modifiesSchema() bool { return true }
+
+// StatementReturnType implements the Statement interface.
+func (*CreateTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreateTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag implements the Statement interface.
+func (*CreateTrigger) StatementTag() string { return "CREATE TRIGGER" }
+
+func (*CreateTrigger) modifiesSchema() bool { return true }
+
 // StatementReturnType implements the Statement interface.
 func (*CreateType) StatementReturnType() StatementReturnType { return DDL }
 
@@ -1033,6 +1069,24 @@ func (*DropRole) StatementTag() string { return "DROP ROLE" }
 
 func (*DropRole) hiddenFromShowQueries() {}
 
+// StatementReturnType implements the Statement interface.
+func (*DropDomain) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropDomain) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropDomain) StatementTag() string { return "DROP DOMAIN" }
+
+// StatementReturnType implements the Statement interface.
+func (*DropTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropTrigger) StatementTag() string { return "DROP TRIGGER" }
+
 // StatementReturnType implements the Statement interface.
 func (*DropType) StatementReturnType() StatementReturnType { return DDL }
 
@@ -1110,6 +1164,15 @@ func (*FetchCursor) StatementType() StatementType { return TypeDML }
 // StatementTag returns a short string identifying the type of statement.
 func (*FetchCursor) StatementTag() string { return "FETCH" }
 
+// StatementReturnType implements the Statement interface.
+func (n *Merge) StatementReturnType() StatementReturnType { return n.Returning.statementReturnType() }
+
+// StatementType implements the Statement interface.
+func (*Merge) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Merge) StatementTag() string { return "MERGE" }
+
 // StatementReturnType implements the Statement interface.
 func (n *MoveCursor) StatementReturnType() StatementReturnType { return RowsAffected }
 
@@ -1154,6 +1217,15 @@ func (*Import) StatementTag() string { return ImportTag }
 
 func (*Import) cclOnlyStatement() {}
 
+// StatementReturnType implements the Statement interface.
+func (*Listen) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Listen) StatementType() StatementType { return TypeTCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Listen) StatementTag() string { return "LISTEN" }
+
 // StatementReturnType implements the Statement interface.
 func (*LiteralValuesClause) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1163,6 +1235,15 @@ func (*LiteralValuesClause) StatementType() StatementType { return TypeDML }
 // StatementTag returns a short string identifying the type of statement.
 func (*LiteralValuesClause) StatementTag() string { return "VALUES" }
 
+// StatementReturnType implements the Statement interface.
+func (*Notify) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Notify) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Notify) StatementTag() string { return "NOTIFY" }
+
 // StatementReturnType implements the Statement interface.
 func (*ParenSelect) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1412,6 +1493,15 @@ func (*SetClusterSetting) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
 
+// StatementReturnType implements the Statement interface.
+func (*SetConstraints) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*SetConstraints) StatementType() StatementType { return TypeTCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetConstraints) StatementTag() string { return "SET CONSTRAINTS" }
+
 // StatementReturnType implements the Statement interface.
 func (*SetTransaction) StatementReturnType() StatementReturnType { return Ack }
 
@@ -1943,7 +2033,12 @@ func (*ShowCreateFunction) StatementReturnType() StatementReturnType { return Ro
 func (*ShowCreateFunction) StatementType() StatementType { return TypeDML }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*ShowCreateFunction) StatementTag() string { return "SHOW CREATE FUNCTION" }
+func (n *ShowCreateFunction) StatementTag() string {
+	if n.IsProcedure {
+		return "SHOW CREATE PROCEDURE"
+	}
+	return "SHOW CREATE FUNCTION"
+}
 
 // StatementReturnType implements the Statement interface.
 func (*ShowCreateExternalConnections) StatementReturnType() StatementReturnType { return Rows }
@@ -2040,7 +2135,21 @@ func (*CreateFunction) StatementReturnType() StatementReturnType { return DDL }
 func (*CreateFunction) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*CreateFunction) StatementTag() string { return "CREATE FUNCTION" }
+func (n *CreateFunction) StatementTag() string {
+	if n.IsProcedure {
+		return "CREATE PROCEDURE"
+	}
+	return "CREATE FUNCTION"
+}
+
+// StatementReturnType implements the Statement interface.
+func (*Call) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*Call) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Call) StatementTag() string { return "CALL" }
 
 // StatementReturnType implements the Statement interface.
 func (*RoutineReturn) StatementReturnType() StatementReturnType { return Rows }
@@ -2058,7 +2167,12 @@ func (*DropFunction) StatementReturnType() StatementReturnType { return DDL }
 func (*DropFunction) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropFunction) StatementTag() string { return "DROP FUNCTION" }
+func (n *DropFunction) StatementTag() string {
+	if n.IsProcedure {
+		return "DROP PROCEDURE"
+	}
+	return "DROP FUNCTION"
+}
 
 // StatementReturnType implements the Statement interface.
 func (*AlterFunctionOptions) StatementReturnType() StatementReturnType { return DDL }
@@ -2152,6 +2266,7 @@ func (n *AlterTenantRename) String() string                   { return AsString(
 func (n *AlterTenantReplication) String() string              { return AsString(n) }
 func (n *AlterTenantService) String() string                  { return AsString(n) }
 func (n *AlterType) String() string                           { return AsString(n) }
+func (n *AlterDomain) String() string                         { return AsString(n) }
 func (n *AlterRole) String() string                           { return AsString(n) }
 func (n *AlterRoleSet) String() string                        { return AsString(n) }
 func (n *AlterSequence) String() string                       { return AsString(n) }
@@ -2178,10 +2293,12 @@ func (n *CopyTo) String() string                              { return AsString(
 func (n *CreateChangefeed) String() string                    { return AsString(n) }
 func (n *CreateDatabase) String() string                      { return AsString(n) }
 func (n *CreateExtension) String() string                     { return AsString(n) }
+func (n *Call) String() string                                { return AsString(n) }
 func (n *CreateFunction) String() string                      { return AsString(n) }
 func (n *CreateIndex) String() string                         { return AsString(n) }
 func (n *CreateRole) String() string                          { return AsString(n) }
 func (n *CreateTable) String() string                         { return AsString(n) }
+func (n *CreateTrigger) String() string                       { return AsString(n) }
 func (n *CreateTenant) String() string                        { return AsString(n) }
 func (n *CreateTenantFromReplication) String() string         { return AsString(n) }
 func (n *CreateSchema) String() string                        { return AsString(n) }
@@ -2198,7 +2315,9 @@ func (n *DropOwnedBy) String() string                         { return AsString(
 func (n *DropSchema) String() string                          { return AsString(n) }
 func (n *DropSequence) String() string                        { return AsString(n) }
 func (n *DropTable) String() string                           { return AsString(n) }
+func (n *DropTrigger) String() string                         { return AsString(n) }
 func (n *DropType) String() string                            { return AsString(n) }
+func (n *DropDomain) String() string                          { return AsString(n) }
 func (n *DropView) String() string                            { return AsString(n) }
 func (n *DropRole) String() string                            { return AsString(n) }
 func (n *DropTenant) String() string                          { return AsString(n) }
@@ -2211,6 +2330,7 @@ func (n *DropExternalConnection) String() string              { return AsString(
 func (n *FetchCursor) String() string                         { return AsString(n) }
 func (n *Grant) String() string                               { return AsString(n) }
 func (n *GrantRole) String() string                           { return AsString(n) }
+func (n *Merge) String() string                               { return AsString(n) }
 func (n *MoveCursor) String() string                          { return AsString(n) }
 func (n *Insert) String() string                              { return AsString(n) }
 func (n *Import) String() string                              { return AsString(n) }
@@ -2240,6 +2360,7 @@ func (n *Scrub) String() string                               { return AsString(
 func (n *Select) String() string                              { return AsString(n) }
 func (n *SelectClause) String() string                        { return AsString(n) }
 func (n *SetClusterSetting) String() string                   { return AsString(n) }
+func (n *SetConstraints) String() string                      { return AsString(n) }
 func (n *SetZoneConfig) String() string                       { return AsString(n) }
 func (n *SetSessionAuthorizationDefault) String() string      { return AsString(n) }
 func (n *SetSessionCharacteristics) String() string           { return AsString(n) }
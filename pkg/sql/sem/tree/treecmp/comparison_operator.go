@@ -70,6 +70,7 @@ const (
 	JSONAllExists
 	Overlaps
 	TSMatches
+	Adjacent
 
 	// The following operators will always be used with an associated SubOperator.
 	// If Go had algebraic data types they would be defined in a self-contained
@@ -122,6 +123,7 @@ var comparisonOpName = [...]string{
 	JSONAllExists:     "?&",
 	Overlaps:          "&&",
 	TSMatches:         "@@",
+	Adjacent:          "-|-",
 	Any:               "ANY",
 	Some:              "SOME",
 	All:               "ALL",
@@ -0,0 +1,153 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+
+// TriggerActionTime describes whether a trigger fires before or after the
+// triggering event.
+type TriggerActionTime int
+
+const (
+	// TriggerActionTimeBefore indicates a BEFORE trigger.
+	TriggerActionTimeBefore TriggerActionTime = iota
+	// TriggerActionTimeAfter indicates an AFTER trigger.
+	TriggerActionTimeAfter
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerActionTime) String() string {
+	if t == TriggerActionTimeAfter {
+		return "AFTER"
+	}
+	return "BEFORE"
+}
+
+// TriggerEventType describes the kind of statement which fires a trigger.
+type TriggerEventType int
+
+const (
+	// TriggerEventInsert indicates an INSERT event.
+	TriggerEventInsert TriggerEventType = iota
+	// TriggerEventUpdate indicates an UPDATE event.
+	TriggerEventUpdate
+	// TriggerEventDelete indicates a DELETE event.
+	TriggerEventDelete
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerEventType) String() string {
+	switch t {
+	case TriggerEventUpdate:
+		return "UPDATE"
+	case TriggerEventDelete:
+		return "DELETE"
+	default:
+		return "INSERT"
+	}
+}
+
+// TriggerEvent represents a single event in a CREATE TRIGGER statement.
+// Columns is only set for UPDATE OF events.
+type TriggerEvent struct {
+	EventType TriggerEventType
+	Columns   NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerEvent) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.EventType.String())
+	if len(node.Columns) > 0 {
+		ctx.WriteString(" OF ")
+		ctx.FormatNode(&node.Columns)
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+type CreateTrigger struct {
+	Replace    bool
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     []*TriggerEvent
+	Table      *UnresolvedObjectName
+	ForEachRow bool
+	When       Expr
+	FuncName   FunctionName
+	FuncArgs   []string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ")
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteString(" ")
+	for i, ev := range node.Events {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.FormatNode(ev)
+	}
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.Table)
+	if node.ForEachRow {
+		ctx.WriteString(" FOR EACH ROW")
+	} else {
+		ctx.WriteString(" FOR EACH STATEMENT")
+	}
+	if node.When != nil {
+		ctx.WriteString(" WHEN (")
+		ctx.FormatNode(node.When)
+		ctx.WriteString(")")
+	}
+	ctx.WriteString(" EXECUTE FUNCTION ")
+	ctx.FormatNode(&node.FuncName)
+	ctx.WriteString("(")
+	for i, arg := range node.FuncArgs {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		if ctx.flags.HasFlags(FmtHideConstants) {
+			ctx.WriteString("'_'")
+		} else {
+			lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, arg, ctx.flags.EncodeFlags())
+		}
+	}
+	ctx.WriteString(")")
+}
+
+// DropTrigger represents a DROP TRIGGER statement.
+type DropTrigger struct {
+	IfExists     bool
+	Trigger      Name
+	Table        *UnresolvedObjectName
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Trigger)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.Table)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteString(" ")
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
@@ -342,15 +342,19 @@ func (expr *BinaryExpr) TypeCheck(
 		}
 	}
 
+	// User-defined range types share the overloads of the range wildcards, so
+	// check that both sides are of the same range type.
+	typeMismatch := userDefinedRangeTypeMismatch(leftReturn, rightReturn)
+
 	// Throw a typing error if overload resolution found either no compatible candidates
 	// or if it found an ambiguity.
-	if len(s.overloadIdxs) != 1 {
+	if len(s.overloadIdxs) != 1 || typeMismatch {
 		var desStr string
 		if desired.Family() != types.AnyFamily {
 			desStr = fmt.Sprintf(" (desired <%s>)", desired)
 		}
 		sig := fmt.Sprintf("<%s> %s <%s>%s", leftReturn, expr.Operator, rightReturn, desStr)
-		if len(s.overloadIdxs) == 0 {
+		if len(s.overloadIdxs) == 0 || typeMismatch {
 			return nil,
 				pgerror.Newf(pgcode.InvalidParameterValue, unsupportedBinaryOpErrFmt, sig)
 		}
@@ -1084,7 +1088,11 @@ func (expr *FuncExpr) TypeCheck(
 
 	var calledOnNullInputFns, notCalledOnNullInputFns intsets.Fast
 	for _, idx := range s.overloadIdxs {
-		if def.Overloads[idx].CalledOnNullInput {
+		// The null input behavior of a user-defined routine is unknown until its
+		// overload is resolved by OID below, so calls to it are never folded to
+		// NULL. The routine itself returns NULL if it is not called on NULL
+		// input.
+		if def.Overloads[idx].CalledOnNullInput || def.Overloads[idx].UDFContainsOnlySignature {
 			calledOnNullInputFns.Add(int(idx))
 		} else {
 			notCalledOnNullInputFns.Add(int(idx))
@@ -1250,6 +1258,32 @@ func (expr *FuncExpr) TypeCheck(
 	return expr, nil
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingExpr) TypeCheck(
+	ctx context.Context, semaCtx *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	if semaCtx != nil && semaCtx.Properties.required.rejectFlags&RejectAggregates != 0 {
+		return nil, pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in %s", semaCtx.Properties.required.context)
+	}
+	for i, subExpr := range expr.Exprs {
+		typedSubExpr, err := subExpr.TypeCheck(ctx, semaCtx, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		expr.Exprs[i] = typedSubExpr
+	}
+	expr.typ = types.Int
+	return expr, nil
+}
+
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSets) TypeCheck(
+	_ context.Context, _ *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	return nil, pgerror.Newf(pgcode.Syntax, "%s can only appear in a GROUP BY clause", expr.Type)
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *IfErrExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
@@ -1889,6 +1923,18 @@ func (d *DTSVector) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (Ty
 	return d, nil
 }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DRange) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DMultirange) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DTuple) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
@@ -2389,6 +2435,10 @@ func typeCheckComparisonOp(
 		// well-typed if the two input types are not equivalent, unless one of the
 		// sides is NULL.
 		typeMismatch = !leftReturn.Equivalent(rightReturn)
+	} else if !nullComparison {
+		// User-defined range types share the overloads of the range wildcards,
+		// so check that both sides are of the same range type.
+		typeMismatch = userDefinedRangeTypeMismatch(leftReturn, rightReturn)
 	}
 
 	// Throw a typing error if overload resolution found either no compatible candidates
@@ -3174,3 +3224,26 @@ func getMostSignificantOverload(
 	}
 	return ret, nil
 }
+
+// userDefinedRangeTypeMismatch returns true if the given types are
+// user-defined range or multirange types which are not of the same range type.
+func userDefinedRangeTypeMismatch(left, right *types.T) bool {
+	rangeTypeOf := func(t *types.T) *types.T {
+		switch t.Family() {
+		case types.RangeFamily:
+			if t.UserDefined() {
+				return t
+			}
+		case types.MultirangeFamily:
+			if t.UserDefined() {
+				return t.MultirangeContents()
+			}
+		}
+		return nil
+	}
+	leftRange, rightRange := rangeTypeOf(left), rangeTypeOf(right)
+	if leftRange == nil || rightRange == nil {
+		return false
+	}
+	return leftRange.Oid() != rightRange.Oid()
+}
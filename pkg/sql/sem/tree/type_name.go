@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 )
@@ -154,6 +155,9 @@ func ResolveType(
 		if err != nil {
 			return nil, err
 		}
+		if typ.IsDomain() {
+			return nil, unimplemented.NewWithIssue(27796, "arrays of domains are not supported")
+		}
 		return types.MakeArray(typ), nil
 	case *UnresolvedObjectName:
 		if resolver == nil {
@@ -194,7 +198,7 @@ func (ctx *FmtCtx) FormatTypeReference(ref ResolvableTypeReference) {
 				ctx.WriteByte('_')
 				return
 			} else if ctx.HasFlags(fmtStaticallyFormatUserDefinedTypes) {
-				idRef := OIDTypeReference{OID: t.Oid()}
+				idRef := OIDTypeReference{OID: t.UserDefinedOID()}
 				ctx.WriteString(idRef.SQLString())
 				return
 			}
@@ -293,7 +297,7 @@ func (v *TypeCollectorVisitor) VisitPre(expr Expr) (bool, Expr) {
 	switch t := expr.(type) {
 	case Datum:
 		if t.ResolvedType().UserDefined() {
-			v.OIDs[t.ResolvedType().Oid()] = struct{}{}
+			v.OIDs[t.ResolvedType().UserDefinedOID()] = struct{}{}
 		}
 	case *IsOfTypeExpr:
 		for _, ref := range t.Types {
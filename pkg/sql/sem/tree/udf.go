@@ -102,17 +102,25 @@ func (node *CreateFunction) Format(ctx *FmtCtx) {
 	if node.Replace {
 		ctx.WriteString("OR REPLACE ")
 	}
-	ctx.WriteString("FUNCTION ")
+	if node.IsProcedure {
+		ctx.WriteString("PROCEDURE ")
+	} else {
+		ctx.WriteString("FUNCTION ")
+	}
 	ctx.FormatNode(&node.FuncName)
 	ctx.WriteString("(")
 	ctx.FormatNode(node.Params)
 	ctx.WriteString(")\n\t")
-	ctx.WriteString("RETURNS ")
-	if node.ReturnType.IsSet {
-		ctx.WriteString("SETOF ")
+	if !node.IsProcedure {
+		// The return type of a procedure is derived from its OUT parameters and
+		// cannot be specified explicitly.
+		ctx.WriteString("RETURNS ")
+		if node.ReturnType.IsSet {
+			ctx.WriteString("SETOF ")
+		}
+		ctx.FormatTypeReference(node.ReturnType.Type)
+		ctx.WriteString("\n\t")
 	}
-	ctx.FormatTypeReference(node.ReturnType.Type)
-	ctx.WriteString("\n\t")
 	var funcBody FunctionBodyStr
 	for _, option := range node.Options {
 		switch t := option.(type) {
@@ -147,6 +155,51 @@ func (node *CreateFunction) Format(ctx *FmtCtx) {
 	}
 }
 
+// Call represents a CALL statement, which invokes a stored procedure.
+type Call struct {
+	Proc *FuncExpr
+	// ResumeAt is the index of the body statement at which execution of the
+	// procedure should begin. It is zero for a CALL issued by the user, and is
+	// set when a procedure is resumed in a new transaction after executing a
+	// COMMIT or ROLLBACK statement. It is not formatted.
+	ResumeAt int
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Call) Format(ctx *FmtCtx) {
+	ctx.WriteString("CALL ")
+	// Format the procedure call directly so that it is never enclosed in
+	// parentheses, which would not be parsable.
+	node.Proc.Format(ctx)
+}
+
+// StoredProcTxnOp indicates whether a stored procedure has requested that the
+// current transaction be committed or aborted.
+type StoredProcTxnOp uint8
+
+const (
+	// StoredProcTxnNoOp indicates that the current transaction should continue.
+	StoredProcTxnNoOp StoredProcTxnOp = iota
+	// StoredProcTxnCommit indicates that the current transaction should be
+	// committed.
+	StoredProcTxnCommit
+	// StoredProcTxnRollback indicates that the current transaction should be
+	// aborted.
+	StoredProcTxnRollback
+)
+
+// String implements the fmt.Stringer interface.
+func (op StoredProcTxnOp) String() string {
+	switch op {
+	case StoredProcTxnCommit:
+		return "commit"
+	case StoredProcTxnRollback:
+		return "rollback"
+	default:
+		return "none"
+	}
+}
+
 // RoutineBody represent a list of statements in a UDF body.
 type RoutineBody struct {
 	Stmts Statements
@@ -365,6 +418,7 @@ type FuncReturnType struct {
 
 // DropFunction represents a DROP FUNCTION statement.
 type DropFunction struct {
+	IsProcedure  bool
 	IfExists     bool
 	Functions    FuncObjs
 	DropBehavior DropBehavior
@@ -372,7 +426,11 @@ type DropFunction struct {
 
 // Format implements the NodeFormatter interface.
 func (node *DropFunction) Format(ctx *FmtCtx) {
-	ctx.WriteString("DROP FUNCTION ")
+	if node.IsProcedure {
+		ctx.WriteString("DROP PROCEDURE ")
+	} else {
+		ctx.WriteString("DROP FUNCTION ")
+	}
 	if node.IfExists {
 		ctx.WriteString("IF EXISTS ")
 	}
@@ -549,6 +607,9 @@ const (
 	TTLExpirationExpr               SchemaExprContext = "TTL EXPIRATION EXPRESSION"
 	TTLDefaultExpr                  SchemaExprContext = "TTL DEFAULT"
 	TTLUpdateExpr                   SchemaExprContext = "TTL UPDATE"
+	DomainDefaultExpr               SchemaExprContext = "DOMAIN DEFAULT"
+	DomainCheckExpr                 SchemaExprContext = "DOMAIN CHECK"
+	TriggerWhenExpr                 SchemaExprContext = "TRIGGER WHEN"
 )
 
 func ComputedColumnExprContext(isVirtual bool) SchemaExprContext {
@@ -612,3 +673,16 @@ func GetFuncVolatility(options FunctionOptions) FunctionVolatility {
 	}
 	return FunctionVolatile
 }
+
+// GetFuncLanguage tries to find a function language from the given list of
+// function options. If there is no language found, FunctionLangSQL is returned
+// as the default.
+func GetFuncLanguage(options FunctionOptions) FunctionLanguage {
+	for _, option := range options {
+		switch t := option.(type) {
+		case FunctionLanguage:
+			return t
+		}
+	}
+	return FunctionLangSQL
+}
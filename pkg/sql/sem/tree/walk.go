@@ -329,6 +329,28 @@ func (expr *FuncExpr) Walk(v Visitor) Expr {
 	return ret
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingExpr) Walk(v Visitor) Expr {
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingSets) Walk(v Visitor) Expr {
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
 // Walk implements the Expr interface.
 func (expr *IfExpr) Walk(v Visitor) Expr {
 	c, changedC := WalkExpr(v, expr.Cond)
@@ -774,6 +796,12 @@ func (expr *DTSQuery) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DTSVector) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DRange) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DMultirange) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 
@@ -1311,6 +1339,78 @@ func (stmt *Insert) walkStmt(v Visitor) Statement {
 	return ret
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Merge) copyNode() *Merge {
+	stmtCopy := *stmt
+	whens := make([]MergeWhen, len(stmt.Whens))
+	stmtCopy.Whens = make(MergeWhens, len(stmt.Whens))
+	for i, w := range stmt.Whens {
+		whens[i] = *w
+		exprs := make([]UpdateExpr, len(w.Exprs))
+		whens[i].Exprs = make(UpdateExprs, len(w.Exprs))
+		for j, e := range w.Exprs {
+			exprs[j] = *e
+			whens[i].Exprs[j] = &exprs[j]
+		}
+		if w.Values != nil {
+			whens[i].Values = append(Exprs(nil), w.Values...)
+		}
+		stmtCopy.Whens[i] = &whens[i]
+	}
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *Merge) walkStmt(v Visitor) Statement {
+	ret := stmt
+	if stmt.On != nil {
+		e, changed := WalkExpr(v, stmt.On)
+		if changed {
+			ret = stmt.copyNode()
+			ret.On = e
+		}
+	}
+
+	for i, w := range stmt.Whens {
+		if w.Cond != nil {
+			e, changed := WalkExpr(v, w.Cond)
+			if changed {
+				if ret == stmt {
+					ret = stmt.copyNode()
+				}
+				ret.Whens[i].Cond = e
+			}
+		}
+		for j, expr := range w.Exprs {
+			e, changed := WalkExpr(v, expr.Expr)
+			if changed {
+				if ret == stmt {
+					ret = stmt.copyNode()
+				}
+				ret.Whens[i].Exprs[j].Expr = e
+			}
+		}
+		for j, expr := range w.Values {
+			e, changed := WalkExpr(v, expr)
+			if changed {
+				if ret == stmt {
+					ret = stmt.copyNode()
+				}
+				ret.Whens[i].Values[j] = e
+			}
+		}
+	}
+
+	returning, changed := walkReturningClause(v, stmt.Returning)
+	if changed {
+		if ret == stmt {
+			ret = stmt.copyNode()
+		}
+		ret.Returning = returning
+	}
+	return ret
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *CreateTable) copyNode() *CreateTable {
 	stmtCopy := *stmt
@@ -1330,6 +1430,22 @@ func (stmt *CreateTable) walkStmt(v Visitor) Statement {
 	return ret
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Call) copyNode() *Call {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *Call) walkStmt(v Visitor) Statement {
+	e, changed := WalkExpr(v, stmt.Proc)
+	if changed {
+		stmt = stmt.copyNode()
+		stmt.Proc = e.(*FuncExpr)
+	}
+	return stmt
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *CancelQueries) copyNode() *CancelQueries {
 	stmtCopy := *stmt
@@ -1895,6 +2011,7 @@ var _ walkableStmt = &AlterTenantService{}
 var _ walkableStmt = &AlterTenantSetClusterSetting{}
 var _ walkableStmt = &Backup{}
 var _ walkableStmt = &BeginTransaction{}
+var _ walkableStmt = &Call{}
 var _ walkableStmt = &CancelQueries{}
 var _ walkableStmt = &CancelSessions{}
 var _ walkableStmt = &ControlJobs{}
@@ -1906,6 +2023,7 @@ var _ walkableStmt = &DropTenant{}
 var _ walkableStmt = &Explain{}
 var _ walkableStmt = &Import{}
 var _ walkableStmt = &Insert{}
+var _ walkableStmt = &Merge{}
 var _ walkableStmt = &ParenSelect{}
 var _ walkableStmt = &Restore{}
 var _ walkableStmt = &SelectClause{}
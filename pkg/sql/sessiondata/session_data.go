@@ -145,6 +145,19 @@ func (s *SessionData) GetDateStyle() pgdate.DateStyle {
 	return s.DataConversionConfig.DateStyle
 }
 
+// DefaultTextSearchConfig is the default value of the
+// default_text_search_config session variable.
+const DefaultTextSearchConfig = "pg_catalog.english"
+
+// GetDefaultTextSearchConfig returns the text search configuration used by the
+// full text search builtins when none is passed to them.
+func (s *SessionData) GetDefaultTextSearchConfig() string {
+	if s == nil || s.DefaultTextSearchConfig == "" {
+		return DefaultTextSearchConfig
+	}
+	return s.DefaultTextSearchConfig
+}
+
 // SessionUser retrieves the session_user.
 // The SessionUser is the username that originally logged into the session.
 // If a user applies SET ROLE, the SessionUser remains the same whilst the
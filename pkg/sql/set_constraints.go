@@ -0,0 +1,103 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// SetConstraints implements the SET CONSTRAINTS statement, which changes
+// whether deferrable constraints are checked at the end of each statement or
+// at the end of the transaction.
+// See https://www.postgresql.org/docs/current/sql-set-constraints.html.
+//
+// Constraints are matched by name only, regardless of the table or schema
+// they belong to.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	if p.extendedEvalCtx.TxnImplicit {
+		// This no-ops in postgres with a warning, so copy accordingly.
+		p.BufferClientNotice(
+			ctx,
+			pgnotice.NewWithSeverityf(
+				"WARNING",
+				"SET CONSTRAINTS can only be used in transaction blocks",
+			),
+		)
+		return newZeroNode(nil /* columns */), nil
+	}
+	if !n.All {
+		if err := p.checkSetConstraintsNames(ctx, n.Names); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.deferredConstraints.setMode(n.All, n.Names, n.Deferred); err != nil {
+		return nil, err
+	}
+	// Constraints which become immediate are checked right away for the
+	// violations recorded while they were deferred.
+	if violations := p.deferredConstraints.takeViolations(false /* all */); len(violations) > 0 {
+		if err := checkDeferredViolations(ctx, p.InternalSQLTxn(), p.Txn(), violations); err != nil {
+			return nil, err
+		}
+	}
+	return newZeroNode(nil /* columns */), nil
+}
+
+// checkSetConstraintsNames returns an error if one of the given names doesn't
+// match any constraint of the tables in the current database, or only matches
+// constraints which are not deferrable.
+func (p *planner) checkSetConstraintsNames(ctx context.Context, names tree.NameList) error {
+	db, err := p.Descriptors().ByNameWithLeased(p.Txn()).Get().Database(ctx, p.CurrentDatabase())
+	if err != nil {
+		return err
+	}
+	inDB, err := p.Descriptors().GetAllTablesInDatabase(ctx, p.Txn(), db)
+	if err != nil {
+		return err
+	}
+	// deferrable maps the names of the constraints in the database to whether
+	// any of the constraints with that name is deferrable.
+	deferrable := make(map[string]bool)
+	if err := inDB.ForEachDescriptor(func(desc catalog.Descriptor) error {
+		tableDesc, err := catalog.AsTableDescriptor(desc)
+		if err != nil {
+			return err
+		}
+		for _, c := range tableDesc.AllConstraints() {
+			isDeferrable := false
+			if fk := c.AsForeignKey(); fk != nil {
+				isDeferrable = fk.IsDeferrable()
+			} else if uwi := c.AsUniqueWithoutIndex(); uwi != nil {
+				isDeferrable = uwi.IsDeferrable()
+			}
+			deferrable[c.GetName()] = deferrable[c.GetName()] || isDeferrable
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		isDeferrable, ok := deferrable[string(name)]
+		if !ok {
+			return pgerror.Newf(pgcode.UndefinedObject, "constraint %q does not exist", name)
+		}
+		if !isDeferrable {
+			return pgerror.Newf(pgcode.WrongObjectType, "constraint %q is not deferrable", name)
+		}
+	}
+	return nil
+}
@@ -23,16 +23,17 @@ import (
 func (p *planner) SetSessionCharacteristics(
 	ctx context.Context, n *tree.SetSessionCharacteristics,
 ) (planNode, error) {
-	// Note: We also support SET DEFAULT_TRANSACTION_ISOLATION TO ' .... '.
-	switch n.Modes.Isolation {
-	case tree.SerializableIsolation, tree.UnspecifiedIsolation:
-		// Do nothing. All transactions execute with serializable isolation.
-	default:
-		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
-			"unsupported default isolation level: %s", n.Modes.Isolation)
-	}
-
 	if err := p.sessionDataMutatorIterator.applyOnEachMutatorError(func(m sessionDataMutator) error {
+		// Note: We also support SET DEFAULT_TRANSACTION_ISOLATION TO ' .... '.
+		switch n.Modes.Isolation {
+		case tree.UnspecifiedIsolation:
+		case tree.SerializableIsolation, tree.ReadCommittedIsolation:
+			m.SetDefaultTransactionIsolationLevel(n.Modes.Isolation)
+		default:
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"unsupported default isolation level: %s", n.Modes.Isolation)
+		}
+
 		// Note: We also support SET DEFAULT_TRANSACTION_PRIORITY TO ' .... '.
 		switch n.Modes.UserPriority {
 		case tree.UnspecifiedUserPriority:
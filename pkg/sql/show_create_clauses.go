@@ -129,7 +129,11 @@ func ShowCreateView(
 			f.WriteRune(',')
 		}
 	}
-	f.WriteString(") AS ")
+	f.WriteString(")")
+	if desc.TableDesc().IncrementalRefresh != nil {
+		f.WriteString(" WITH (incremental)")
+	}
+	f.WriteString(" AS ")
 
 	cfg := tree.DefaultPrettyCfg()
 	cfg.UseTabs = true
@@ -485,6 +489,12 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(tree.ForeignKeyReferenceActionType[fk.OnUpdate].String())
 	}
+	if deferrability := tree.MakeConstraintDeferrability(
+		fk.Deferrable, fk.InitiallyDeferred,
+	); deferrability.IsDeferrable() {
+		buf.WriteByte(' ')
+		buf.WriteString(deferrability.String())
+	}
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
@@ -731,6 +741,8 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		deferrability := tree.MakeConstraintDeferrability(c.IsDeferrable(), c.IsInitiallyDeferred())
+		f.FormatNode(&deferrability)
 		if c.IsPartial() {
 			f.WriteString(" WHERE ")
 			pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.GetPredicate(), semaCtx, sessionData, tree.FmtParsable)
@@ -15,15 +15,21 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/clusterunique"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/isql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -34,17 +40,13 @@ func (p *planner) DeclareCursor(ctx context.Context, s *tree.DeclareCursor) (pla
 	if s.Binary {
 		return nil, unimplemented.NewWithIssue(77099, "DECLARE BINARY CURSOR")
 	}
-	if s.Scroll == tree.Scroll {
-		return nil, unimplemented.NewWithIssue(77102, "DECLARE SCROLL CURSOR")
-	}
 
 	return &delayedNode{
 		name: s.String(),
 		constructor: func(ctx context.Context, p *planner) (_ planNode, _ error) {
-			if p.extendedEvalCtx.TxnImplicit {
-				if s.Hold {
-					return nil, unimplemented.NewWithIssue(77101, "DECLARE CURSOR WITH HOLD can only be used in transaction blocks")
-				}
+			// Held cursors are persisted when the transaction commits, so they can
+			// be declared outside of a transaction block, like in Postgres.
+			if p.extendedEvalCtx.TxnImplicit && !s.Hold {
 				return nil, pgerror.Newf(pgcode.NoActiveSQLTransaction, "DECLARE CURSOR can only be used in transaction blocks")
 			}
 
@@ -110,12 +112,16 @@ func (p *planner) DeclareCursor(ctx context.Context, s *tree.DeclareCursor) (pla
 				statement:  statement,
 				created:    timeutil.Now(),
 				withHold:   s.Hold,
+				scroll:     s.Scroll == tree.Scroll,
+			}
+			if cursor.scroll || cursor.withHold {
+				cursor.initSpool(ctx, p)
 			}
 			if err := p.sqlCursors.addCursor(s.Name, cursor); err != nil {
 				// This case shouldn't happen because cursor names are scoped to a session,
 				// and sessions can't have more than one statement running at once. But
 				// let's be diligent and clean up if it somehow does happen anyway.
-				_ = cursor.Close()
+				_ = cursor.close(ctx)
 				return nil, err
 			}
 			return newZeroNode(nil /* columns */), nil
@@ -123,7 +129,10 @@ func (p *planner) DeclareCursor(ctx context.Context, s *tree.DeclareCursor) (pla
 	}, nil
 }
 
-var errBackwardScan = pgerror.Newf(pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward")
+var errBackwardScan = errors.WithHint(
+	pgerror.New(pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward"),
+	"Declare it with SCROLL option to enable backward scan.",
+)
 
 // FetchCursor implements the FETCH and MOVE statements.
 // See https://www.postgresql.org/docs/current/sql-fetch.html for details.
@@ -136,17 +145,34 @@ func (p *planner) FetchCursor(
 			pgcode.InvalidCursorName, "cursor %q does not exist", s.Name,
 		)
 	}
-	if s.Count < 0 || s.FetchType == tree.FetchBackwardAll {
+	if !cursor.scroll && (s.Count < 0 || s.FetchType == tree.FetchBackwardAll) {
 		return nil, errBackwardScan
 	}
 	node := &fetchNode{
-		n:         s.Count,
+		n:         1,
+		step:      1,
 		fetchType: s.FetchType,
 		cursor:    cursor,
 		isMove:    isMove,
 	}
-	if s.FetchType != tree.FetchNormal {
-		node.n = 0
+	switch s.FetchType {
+	case tree.FetchNormal:
+		switch {
+		case s.Count > 0:
+			node.n = s.Count
+		case s.Count < 0:
+			node.n = -s.Count
+			node.step = -1
+		default:
+			// FETCH 0 fetches the current row again.
+			node.fetchType = tree.FetchRelative
+		}
+	case tree.FetchAll:
+		node.n = -1
+	case tree.FetchBackwardAll:
+		node.n = -1
+		node.step = -1
+	default:
 		node.offset = s.Count
 	}
 	return node, nil
@@ -154,10 +180,14 @@ func (p *planner) FetchCursor(
 
 type fetchNode struct {
 	cursor *sqlCursor
-	// n is the number of rows requested.
+	// n is the number of rows that remain to be fetched, or -1 if all remaining
+	// rows should be fetched.
 	n int64
-	// offset is the number of rows to read first, when in relative or absolute
-	// mode.
+	// step is the direction in which the cursor moves for each fetched row
+	// when fetching in normal mode: 1 to move forward, -1 to move backward.
+	step int64
+	// offset is the row to fetch when in absolute mode, or the number of rows
+	// to move by when in relative mode.
 	offset    int64
 	fetchType tree.FetchType
 	// isMove is true if this is a MOVE statement, which is identical to a FETCH
@@ -165,14 +195,17 @@ type fetchNode struct {
 	// fetched.
 	isMove bool
 
-	seeked bool
-
 	// origTxnSeqNum is the transaction sequence number of the user's transaction
 	// before the fetch began.
 	origTxnSeqNum enginepb.TxnSeq
 }
 
 func (f *fetchNode) startExec(params runParams) error {
+	if f.cursor.txn == nil {
+		// The cursor is a held cursor that outlived the transaction that
+		// declared it, so all of its rows are already in its spool.
+		return nil
+	}
 	state := f.cursor.txn.GetLeafTxnInputState(params.ctx)
 	// We need to make sure that we're reading at the same read sequence number
 	// that we had when we created the cursor, to preserve the "sensitivity"
@@ -183,52 +216,48 @@ func (f *fetchNode) startExec(params runParams) error {
 }
 
 func (f *fetchNode) Next(params runParams) (bool, error) {
-	if f.fetchType == tree.FetchAll {
-		return f.cursor.Next(params.ctx)
-	}
-
-	if !f.seeked {
-		// FIRST, LAST, ABSOLUTE, and RELATIVE require seeking before returning
-		// values. Do that first.
-		f.seeked = true
-		switch f.fetchType {
-		case tree.FetchFirst:
-			switch f.cursor.curRow {
-			case 0:
-				_, err := f.cursor.Next(params.ctx)
-				return true, err
-			case 1:
-				return true, nil
-			}
-			return false, errBackwardScan
-		case tree.FetchLast:
+	if f.n == 0 {
+		return false, nil
+	}
+	if f.n > 0 {
+		f.n--
+	}
+	switch f.fetchType {
+	case tree.FetchNormal, tree.FetchAll, tree.FetchBackwardAll:
+		return f.cursor.seek(params.ctx, f.cursor.curRow+f.step)
+	}
+
+	// FIRST, LAST, ABSOLUTE, and RELATIVE move the cursor to a single row.
+	var pos int64
+	switch f.fetchType {
+	case tree.FetchFirst:
+		pos = 1
+	case tree.FetchLast:
+		if !f.cursor.scroll {
 			return false, errBackwardScan
-		case tree.FetchAbsolute:
-			if f.cursor.curRow > f.offset {
+		}
+		numRows, err := f.cursor.numRows(params.ctx)
+		if err != nil {
+			return false, err
+		}
+		pos = numRows
+	case tree.FetchAbsolute:
+		pos = f.offset
+		if pos < 0 {
+			// Negative positions count backward from the end of the result.
+			if !f.cursor.scroll {
 				return false, errBackwardScan
 			}
-			for f.cursor.curRow < f.offset {
-				more, err := f.cursor.Next(params.ctx)
-				if !more || err != nil {
-					return more, err
-				}
-			}
-			return true, nil
-		case tree.FetchRelative:
-			for i := int64(0); i < f.offset; i++ {
-				more, err := f.cursor.Next(params.ctx)
-				if !more || err != nil {
-					return more, err
-				}
+			numRows, err := f.cursor.numRows(params.ctx)
+			if err != nil {
+				return false, err
 			}
-			return true, nil
+			pos += numRows + 1
 		}
+	case tree.FetchRelative:
+		pos = f.cursor.curRow + f.offset
 	}
-	if f.n <= 0 {
-		return false, nil
-	}
-	f.n--
-	return f.cursor.Next(params.ctx)
+	return f.cursor.seek(params.ctx, pos)
 }
 
 func (f fetchNode) Values() tree.Datums {
@@ -239,6 +268,9 @@ func (f fetchNode) Close(ctx context.Context) {
 	// We explicitly do not pass through the Close to our Rows, because
 	// running FETCH on a CURSOR does not close it.
 
+	if f.cursor.txn == nil {
+		return
+	}
 	// Reset the transaction's read sequence number to what it was before the
 	// fetch began, so that subsequent reads in the transaction can still see
 	// writes from that transaction.
@@ -254,9 +286,9 @@ func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNod
 		name: n.String(),
 		constructor: func(ctx context.Context, p *planner) (planNode, error) {
 			if n.All {
-				return newZeroNode(nil /* columns */), p.sqlCursors.closeAll(false /* errorOnWithHold */)
+				return newZeroNode(nil /* columns */), p.sqlCursors.closeAll(ctx, closeAllCursors)
 			}
-			return newZeroNode(nil /* columns */), p.sqlCursors.closeCursor(n.Name)
+			return newZeroNode(nil /* columns */), p.sqlCursors.closeCursor(ctx, n.Name)
 		},
 	}, nil
 }
@@ -264,35 +296,214 @@ func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNod
 type sqlCursor struct {
 	isql.Rows
 	// txn is the transaction object that the internal executor for this cursor
-	// is running with.
+	// is running with. It is nil for held cursors once the transaction that
+	// declared them has committed.
 	txn *kv.Txn
 	// readSeqNum is the sequence number of the transaction that the cursor was
 	// initialized with.
 	readSeqNum enginepb.TxnSeq
 	statement  string
 	created    time.Time
-	curRow     int64
-	withHold   bool
+	// curRow is the position of the cursor: 0 before the first row, n when on
+	// the nth row, and one past the number of rows after the last row.
+	curRow   int64
+	withHold bool
+	scroll   bool
+	// done is set once all of the rows were read from Rows.
+	done bool
+
+	// spool buffers the rows that were read from Rows so far for cursors
+	// declared with SCROLL or WITH HOLD, so that scrollable cursors can move
+	// backward and held cursors can outlive their transaction. It is backed by
+	// a disk-backed row container that spills to disk once the cursor uses
+	// more than its share of the session's memory.
+	spool struct {
+		rows        *rowcontainer.DiskBackedIndexedRowContainer
+		memMonitor  *mon.BytesMonitor
+		diskMonitor *mon.BytesMonitor
+		scratch     rowenc.EncDatumRow
+		// len is the number of rows in the spool.
+		len int64
+		// cur is the row at the current position of the cursor.
+		cur tree.Datums
+	}
+	// persisted is set once a held cursor has read all of its rows into its
+	// spool, which happens when the transaction that declared it commits.
+	persisted bool
+}
+
+// initSpool sets up the spool of a cursor declared with SCROLL or WITH HOLD.
+// The memory used by the spool is accounted for by the session rather than
+// the transaction, since held cursors outlive their transaction.
+func (c *sqlCursor) initSpool(ctx context.Context, p *planner) {
+	distSQLCfg := &p.extendedEvalCtx.DistSQLPlanner.distSQLSrv.ServerConfig
+	c.spool.memMonitor = execinfra.NewLimitedMonitorNoFlowCtx(
+		ctx, p.sqlCursors.monitor(), distSQLCfg, p.SessionData(), "sql-cursor-limited",
+	)
+	c.spool.diskMonitor = execinfra.NewMonitor(ctx, distSQLCfg.ParentDiskMonitor, "sql-cursor-disk")
+	cols := c.Types()
+	typs := make([]*types.T, len(cols))
+	for i := range cols {
+		typs[i] = cols[i].Typ
+	}
+	c.spool.rows = rowcontainer.NewDiskBackedIndexedRowContainer(
+		colinfo.NoOrdering, typs, &p.extendedEvalCtx.Context, distSQLCfg.TempStorage,
+		c.spool.memMonitor, c.spool.diskMonitor,
+	)
+	c.spool.scratch = make(rowenc.EncDatumRow, len(typs))
+}
+
+// spooled returns whether the cursor buffers its rows in a spool.
+func (c *sqlCursor) spooled() bool {
+	return c.spool.rows != nil
 }
 
 // Next implements the Rows interface.
-func (s *sqlCursor) Next(ctx context.Context) (bool, error) {
-	more, err := s.Rows.Next(ctx)
-	if err == nil {
-		s.curRow++
+func (c *sqlCursor) Next(ctx context.Context) (bool, error) {
+	return c.seek(ctx, c.curRow+1)
+}
+
+// Cur implements the Rows interface.
+func (c *sqlCursor) Cur() tree.Datums {
+	if c.spooled() {
+		return c.spool.cur
+	}
+	return c.Rows.Cur()
+}
+
+// next reads the next row from Rows, adding it to the spool if the cursor
+// has one.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if c.done {
+		return false, nil
+	}
+	more, err := c.Rows.Next(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !more {
+		c.done = true
+		return false, nil
+	}
+	if c.spooled() {
+		for i, d := range c.Rows.Cur() {
+			c.spool.scratch[i].Datum = d
+		}
+		if err := c.spool.rows.AddRow(ctx, c.spool.scratch); err != nil {
+			return false, err
+		}
+		c.spool.len++
+	}
+	return true, nil
+}
+
+// seek moves the cursor to the given position and returns whether there is a
+// row at that position. Positions before the first row and after the last
+// row leave the cursor before the first row and after the last row
+// respectively. Only scrollable cursors can move backward.
+func (c *sqlCursor) seek(ctx context.Context, pos int64) (bool, error) {
+	if pos < c.curRow && !c.scroll {
+		return false, errBackwardScan
+	}
+	if !c.spooled() {
+		for c.curRow < pos && !c.done {
+			if _, err := c.next(ctx); err != nil {
+				return false, err
+			}
+			c.curRow++
+		}
+		return pos > 0 && c.curRow == pos && !c.done, nil
+	}
+	if pos <= 0 {
+		c.curRow = 0
+		return false, nil
+	}
+	for c.spool.len < pos && !c.done {
+		if _, err := c.next(ctx); err != nil {
+			return false, err
+		}
+	}
+	if pos > c.spool.len {
+		c.curRow = c.spool.len + 1
+		return false, nil
+	}
+	row, err := c.spool.rows.GetRow(ctx, int(pos-1))
+	if err != nil {
+		return false, err
+	}
+	if c.spool.cur, err = row.GetDatums(0, len(c.spool.scratch)); err != nil {
+		return false, err
+	}
+	c.curRow = pos
+	return true, nil
+}
+
+// numRows returns the number of rows of a spooled cursor, reading all of
+// the remaining rows into the spool.
+func (c *sqlCursor) numRows(ctx context.Context) (int64, error) {
+	for !c.done {
+		if _, err := c.next(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return c.spool.len, nil
+}
+
+// persist reads all of the remaining rows of a held cursor into its spool,
+// so that the cursor can be used after the transaction that declared it
+// commits.
+func (c *sqlCursor) persist(ctx context.Context) error {
+	state := c.txn.GetLeafTxnInputState(ctx)
+	if err := c.txn.SetReadSeqNum(c.readSeqNum); err != nil {
+		return err
+	}
+	_, err := c.numRows(ctx)
+	if resetErr := c.txn.SetReadSeqNum(state.ReadSeqNum); resetErr != nil {
+		err = errors.CombineErrors(err, resetErr)
+	}
+	if err != nil {
+		return err
 	}
-	return more, err
+	c.persisted = true
+	return c.Rows.Close()
 }
 
+// close closes the cursor, releasing its spool.
+func (c *sqlCursor) close(ctx context.Context) error {
+	err := c.Rows.Close()
+	if c.spooled() {
+		c.spool.rows.Close(ctx)
+		c.spool.memMonitor.Stop(ctx)
+		c.spool.diskMonitor.Stop(ctx)
+		c.spool.rows = nil
+	}
+	return err
+}
+
+// cursorCloseMode specifies which cursors are closed by closeAll.
+type cursorCloseMode int
+
+const (
+	// closeAllCursors closes all cursors, including held cursors that were
+	// declared in previous transactions.
+	closeAllCursors cursorCloseMode = iota
+	// closeTxnCursors closes the cursors that were declared in the current
+	// transaction. It is used when the transaction is rolled back.
+	closeTxnCursors
+	// commitTxnCursors closes the cursors that were declared in the current
+	// transaction, except for the held cursors that were persisted when it
+	// committed, which are detached from the transaction instead.
+	commitTxnCursors
+)
+
 // sqlCursors contains a set of active cursors for a session.
 type sqlCursors interface {
-	// closeAll closes all cursors in the set. If any of the cursors were
-	// created WITH HOLD, and the errorOnWithHold flag is true, an error is
-	// returned.
-	closeAll(errorOnWithHold bool) error
+	// closeAll closes the cursors in the set that are selected by the given
+	// mode.
+	closeAll(context.Context, cursorCloseMode) error
 	// closeCursor closes the named cursor, returning an error if that cursor
 	// didn't exist in the set.
-	closeCursor(tree.Name) error
+	closeCursor(context.Context, tree.Name) error
 	// getCursor returns the named cursor, returning nil if that cursor
 	// didn't exist in the set.
 	getCursor(tree.Name) *sqlCursor
@@ -301,32 +512,62 @@ type sqlCursors interface {
 	addCursor(tree.Name, *sqlCursor) error
 	// list returns all open cursors in the set.
 	list() map[tree.Name]*sqlCursor
+	// monitor returns the session-level monitor that the memory used by the
+	// spools of the cursors in the set is accounted against.
+	monitor() *mon.BytesMonitor
 }
 
 // cursorMap is a sqlCursors that's backed by an actual map.
 type cursorMap struct {
 	cursors map[tree.Name]*sqlCursor
+	mon     *mon.BytesMonitor
+}
+
+func (c *cursorMap) closeAll(ctx context.Context, mode cursorCloseMode) error {
+	var err error
+	for n, cursor := range c.cursors {
+		if mode != closeAllCursors && cursor.txn == nil {
+			// Held cursors from previous transactions stay open.
+			continue
+		}
+		if mode == commitTxnCursors && cursor.persisted {
+			cursor.txn = nil
+			continue
+		}
+		err = errors.CombineErrors(err, cursor.close(ctx))
+		delete(c.cursors, n)
+	}
+	return err
 }
 
-func (c *cursorMap) closeAll(errorOnWithHold bool) error {
-	for n, c := range c.cursors {
-		if c.withHold && errorOnWithHold {
-			return unimplemented.NewWithIssuef(77101, "cursor %s WITH HOLD must be closed before committing", n)
+// persistHeldCursors persists the held cursors that were declared in the
+// current transaction so that they outlive it, and closes the other cursors
+// that were declared in it. It is called before the transaction commits.
+func (c *cursorMap) persistHeldCursors(ctx context.Context) error {
+	for n, cursor := range c.cursors {
+		if cursor.txn == nil {
+			continue
+		}
+		if cursor.withHold {
+			if err := cursor.persist(ctx); err != nil {
+				return err
+			}
+			continue
 		}
-		if err := c.Close(); err != nil {
+		if err := cursor.close(ctx); err != nil {
 			return err
 		}
+		delete(c.cursors, n)
 	}
-	c.cursors = nil
 	return nil
 }
 
-func (c *cursorMap) closeCursor(s tree.Name) error {
+func (c *cursorMap) closeCursor(ctx context.Context, s tree.Name) error {
 	cursor, ok := c.cursors[s]
 	if !ok {
 		return pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", s)
 	}
-	err := cursor.Close()
+	err := cursor.close(ctx)
 	delete(c.cursors, s)
 	return err
 }
@@ -350,18 +591,22 @@ func (c *cursorMap) list() map[tree.Name]*sqlCursor {
 	return c.cursors
 }
 
+func (c *cursorMap) monitor() *mon.BytesMonitor {
+	return c.mon
+}
+
 // connExCursorAccessor is a sqlCursors that delegates to a connExecutor's
 // extraTxnState.
 type connExCursorAccessor struct {
 	ex *connExecutor
 }
 
-func (c connExCursorAccessor) closeAll(errorOnWithHold bool) error {
-	return c.ex.extraTxnState.sqlCursors.closeAll(errorOnWithHold)
+func (c connExCursorAccessor) closeAll(ctx context.Context, mode cursorCloseMode) error {
+	return c.ex.extraTxnState.sqlCursors.closeAll(ctx, mode)
 }
 
-func (c connExCursorAccessor) closeCursor(s tree.Name) error {
-	return c.ex.extraTxnState.sqlCursors.closeCursor(s)
+func (c connExCursorAccessor) closeCursor(ctx context.Context, s tree.Name) error {
+	return c.ex.extraTxnState.sqlCursors.closeCursor(ctx, s)
 }
 
 func (c connExCursorAccessor) getCursor(s tree.Name) *sqlCursor {
@@ -376,6 +621,10 @@ func (c connExCursorAccessor) list() map[tree.Name]*sqlCursor {
 	return c.ex.extraTxnState.sqlCursors.list()
 }
 
+func (c connExCursorAccessor) monitor() *mon.BytesMonitor {
+	return c.ex.extraTxnState.sqlCursors.monitor()
+}
+
 // checkNoConflictingCursors returns an error if the input schema changing
 // statement conflicts with any open SQL cursors in the current planner.
 func (p *planner) checkNoConflictingCursors(stmt tree.Statement) error {
@@ -385,9 +634,13 @@ func (p *planner) checkNoConflictingCursors(stmt tree.Statement) error {
 	// We could improve this by matching the memo metadata's list of dependent
 	// schema objects in each open cursor with the objects being changed in the
 	// schema change.
-	if len(p.sqlCursors.list()) > 0 {
-		return unimplemented.NewWithIssue(74608, "cannot run schema change "+
-			"in a transaction with open DECLARE cursors")
+	for _, c := range p.sqlCursors.list() {
+		// Held cursors that outlived the transaction that declared them have
+		// already read all of their rows, so they can't conflict.
+		if c.txn != nil {
+			return unimplemented.NewWithIssue(74608, "cannot run schema change "+
+				"in a transaction with open DECLARE cursors")
+		}
 	}
 	return nil
 }
@@ -120,7 +120,7 @@ func (js *JSONStatistic) DecodeAndSetHistogram(
 		if resolver == nil {
 			return errors.AssertionFailedf("attempt to resolve user defined type with nil TypeResolver")
 		}
-		typ, err := resolver.ResolveTypeByOID(ctx, h.ColumnType.Oid())
+		typ, err := resolver.ResolveTypeByOID(ctx, h.ColumnType.UserDefinedOID())
 		if err != nil {
 			return err
 		}
@@ -642,7 +642,7 @@ func (sc *TableStatisticsCache) parseStats(
 			) error {
 				resolver := descs.NewDistSQLTypeResolver(txn.Descriptors(), txn.KV())
 				var err error
-				res.HistogramData.ColumnType, err = resolver.ResolveTypeByOID(ctx, typ.Oid())
+				res.HistogramData.ColumnType, err = resolver.ResolveTypeByOID(ctx, typ.UserDefinedOID())
 				return err
 			}); err != nil {
 				return nil, err
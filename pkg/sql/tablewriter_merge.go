@@ -0,0 +1,139 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// optTableMerger implements the MERGE statement. Like the optTableUpserter
+// it embeds, it relies on the optimizer to join the source relation with the
+// target table and to compute the inserted and updated values. The input row
+// additionally contains an action column that decides whether the row is
+// inserted, updated or deleted. See Builder.buildMerge in
+// opt/optbuilder/merge.go for more details.
+type optTableMerger struct {
+	optTableUpserter
+
+	// rd is used when deleting rows.
+	rd row.Deleter
+
+	// actionOrdinal is the ordinal position of the column within the input row
+	// that holds the tree.MergeActionType of the row.
+	actionOrdinal int
+
+	// passthrough contains the columns of the source relation that are
+	// returned after the table columns of each result row.
+	passthrough colinfo.ResultColumns
+}
+
+var _ tableWriter = &optTableMerger{}
+
+// init is part of the tableWriter interface.
+func (tm *optTableMerger) init(
+	ctx context.Context, txn *kv.Txn, evalCtx *eval.Context, sv *settings.Values,
+) error {
+	if err := tm.optTableUpserter.init(ctx, txn, evalCtx, sv); err != nil {
+		return err
+	}
+
+	// Make room for the passthrough columns at the end of the result rows.
+	if tm.rowsNeeded && len(tm.passthrough) > 0 {
+		colTypes := make([]*types.T, 0, len(tm.returnCols)+len(tm.passthrough))
+		for _, col := range tm.returnCols {
+			colTypes = append(colTypes, col.GetType())
+		}
+		for _, col := range tm.passthrough {
+			colTypes = append(colTypes, col.Typ)
+		}
+		tm.rows.Close(ctx)
+		tm.rows = rowcontainer.NewRowContainer(
+			evalCtx.Planner.Mon().MakeBoundAccount(), colinfo.ColTypeInfoFromColTypes(colTypes),
+		)
+		tm.resultRow = make(tree.Datums, len(colTypes))
+	}
+	return nil
+}
+
+// desc is part of the tableWriter interface.
+func (*optTableMerger) desc() string { return "opt merger" }
+
+// row is part of the tableWriter interface. The given row contains the insert,
+// fetch and update columns, followed by the action column and the passthrough
+// columns.
+func (tm *optTableMerger) row(
+	ctx context.Context, row tree.Datums, pm row.PartialIndexUpdateHelper, traceKV bool,
+) error {
+	tm.currentBatchSize++
+
+	insertEnd := len(tm.ri.InsertCols)
+	fetchEnd := insertEnd + len(tm.fetchCols)
+	updateEnd := fetchEnd + len(tm.updateCols)
+
+	// Stash the passthrough values in the result row; the embedded upserter
+	// only fills in the table columns.
+	if tm.rowsNeeded && len(tm.passthrough) > 0 {
+		passthroughBegin := tm.actionOrdinal + 1
+		copy(tm.resultRow[len(tm.returnCols):], row[passthroughBegin:passthroughBegin+len(tm.passthrough)])
+	}
+
+	switch action := tree.MergeActionType(tree.MustBeDInt(row[tm.actionOrdinal])); action {
+	case tree.MergeActionInsert:
+		return tm.insertNonConflictingRow(ctx, row[:insertEnd], pm, false /* overwrite */, traceKV)
+
+	case tree.MergeActionUpdate:
+		return tm.updateConflictingRow(
+			ctx, tm.b, row[insertEnd:fetchEnd], row[fetchEnd:updateEnd], pm, traceKV,
+		)
+
+	case tree.MergeActionDelete:
+		return tm.deleteMatchedRow(ctx, row[insertEnd:fetchEnd], pm, traceKV)
+
+	default:
+		return errors.AssertionFailedf("unexpected MERGE action %d", action)
+	}
+}
+
+// deleteMatchedRow deletes an existing row from the table. The existing values
+// from the row are provided in fetchRow. If the RETURNING clause was
+// specified, then the deleted row is stored in the rows collection.
+func (tm *optTableMerger) deleteMatchedRow(
+	ctx context.Context, fetchRow tree.Datums, pm row.PartialIndexUpdateHelper, traceKV bool,
+) error {
+	if err := tm.rd.DeleteRow(ctx, tm.b, fetchRow, pm, traceKV); err != nil {
+		return err
+	}
+
+	// We only need a result row if we're collecting rows.
+	if !tm.rowsNeeded {
+		return nil
+	}
+
+	// Map the deleted columns into the result row before adding it.
+	tableRow := tm.makeResultFromRow(fetchRow, tm.ru.FetchColIDtoRowIndex)
+	for tabIdx := range tableRow {
+		if retIdx := tm.tabColIdxToRetIdx[tabIdx]; retIdx >= 0 {
+			tm.resultRow[retIdx] = tableRow[tabIdx]
+		}
+	}
+	_, err := tm.rows.AddRow(ctx, tm.resultRow)
+	return err
+}
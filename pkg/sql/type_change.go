@@ -96,6 +96,40 @@ func findTransitioningMembers(desc *typedesc.Mutable) ([][]byte, bool) {
 	return transitioningMembers, beingDropped
 }
 
+// findTransitioningDomainConstraints returns the names of the CHECK constraints
+// of a domain which are being added in the current txn, and whether its NOT
+// NULL constraint is being added in the current txn. These constraints are
+// validated by the job created for the txn.
+func findTransitioningDomainConstraints(desc *typedesc.Mutable) (checks []string, notNull bool) {
+	if desc.Domain == nil {
+		return nil, false
+	}
+	var clusterDomain *descpb.TypeDescriptor_Domain
+	if !desc.IsNew() {
+		clusterDomain = desc.ClusterVersion.Domain
+	}
+	for _, check := range desc.Domain.Checks {
+		if !check.Validating {
+			continue
+		}
+		validatingInCluster := false
+		if clusterDomain != nil {
+			for _, clusterCheck := range clusterDomain.Checks {
+				if clusterCheck.Name == check.Name && clusterCheck.Validating {
+					validatingInCluster = true
+					break
+				}
+			}
+		}
+		if !validatingInCluster {
+			checks = append(checks, check.Name)
+		}
+	}
+	notNull = desc.Domain.NotNullValidating &&
+		(clusterDomain == nil || !clusterDomain.NotNullValidating)
+	return checks, notNull
+}
+
 // writeTypeSchemaChange should be called on a mutated type descriptor to ensure that
 // the descriptor gets written to a batch, as well as ensuring that a job is
 // created to perform the schema change on the type.
@@ -105,11 +139,17 @@ func (p *planner) writeTypeSchemaChange(
 	// Check if there is a cached specification for this type, otherwise create one.
 	record, recordExists := p.extendedEvalCtx.jobs.uniqueToCreate[typeDesc.ID]
 	transitioningMembers, beingDropped := findTransitioningMembers(typeDesc)
+	domainChecks, domainNotNull := findTransitioningDomainConstraints(typeDesc)
+	// Validating the constraints of a domain can fail, like dropping an enum
+	// member, in which case the job must be able to revert.
+	mayFail := beingDropped || len(domainChecks) > 0 || domainNotNull
 	if recordExists {
 		// Update it.
 		newDetails := jobspb.TypeSchemaChangeDetails{
-			TypeID:               typeDesc.ID,
-			TransitioningMembers: transitioningMembers,
+			TypeID:                     typeDesc.ID,
+			TransitioningMembers:       transitioningMembers,
+			TransitioningDomainChecks:  domainChecks,
+			TransitioningDomainNotNull: domainNotNull,
 		}
 		record.Details = newDetails
 		record.AppendDescription(jobDesc)
@@ -122,8 +162,8 @@ func (p *planner) writeTypeSchemaChange(
 					return nonCancelable
 				}
 				// Type change jobs are non-cancelable unless an enum member is being
-				// dropped.
-				return !beingDropped
+				// dropped or a domain constraint is being validated.
+				return !mayFail
 			})
 		log.Infof(ctx, "job %d: updated with type change for type %d", record.JobID, typeDesc.ID)
 	} else {
@@ -134,13 +174,16 @@ func (p *planner) writeTypeSchemaChange(
 			Username:      p.User(),
 			DescriptorIDs: descpb.IDs{typeDesc.ID},
 			Details: jobspb.TypeSchemaChangeDetails{
-				TypeID:               typeDesc.ID,
-				TransitioningMembers: transitioningMembers,
+				TypeID:                     typeDesc.ID,
+				TransitioningMembers:       transitioningMembers,
+				TransitioningDomainChecks:  domainChecks,
+				TransitioningDomainNotNull: domainNotNull,
 			},
 			Progress: jobspb.TypeSchemaChangeProgress{},
 			// Type change jobs in general are not cancelable, unless they include
-			// a transition that drops an enum member.
-			NonCancelable: !beingDropped,
+			// a transition that drops an enum member or validates a domain
+			// constraint.
+			NonCancelable: !mayFail,
 		}
 		p.extendedEvalCtx.jobs.uniqueToCreate[typeDesc.ID] = &newRecord
 		log.Infof(ctx, "queued new type change job %d for type %d", newRecord.JobID, typeDesc.ID)
@@ -168,7 +211,12 @@ type typeSchemaChanger struct {
 	// for a typeSchemaChanger. This is used to group transitions together and
 	// ensure proper rollback semantics on job failure.
 	transitioningMembers [][]byte
-	execCfg              *ExecutorConfig
+	// transitioningDomainChecks and transitioningDomainNotNull are the
+	// constraints of a domain that need to be validated in the job created for
+	// a typeSchemaChanger.
+	transitioningDomainChecks  []string
+	transitioningDomainNotNull bool
+	execCfg                    *ExecutorConfig
 }
 
 // TypeSchemaChangerTestingKnobs contains testing knobs for the typeSchemaChanger.
@@ -485,6 +533,20 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 		}
 	}
 
+	// Validate the existing values of columns using the domain against the
+	// constraints added in this job. The leases on the versions of the domain
+	// without these constraints have been released, so all new values are
+	// checked against them.
+	if typeDesc.AsDomainTypeDescriptor() != nil && !typeDesc.Dropped() &&
+		(len(t.transitioningDomainChecks) != 0 || t.transitioningDomainNotNull) {
+		if err := t.validateDomainConstraints(ctx); err != nil {
+			return err
+		}
+		if err := refreshTypeDescriptorLeases(ctx, leaseMgr, typeDesc); err != nil {
+			return err
+		}
+	}
+
 	// If the type is being dropped, remove the descriptor here only
 	// if the declarative schema changer is not in use.
 	if typeDesc.Dropped() && typeDesc.GetDeclarativeSchemaChangerState() == nil {
@@ -514,6 +576,110 @@ func (t *typeSchemaChanger) isTransitioningInCurrentJob(
 	return false
 }
 
+// isDomainCheckTransitioningInCurrentJob returns true if the given CHECK
+// constraint of a domain is being added in the current job.
+func (t *typeSchemaChanger) isDomainCheckTransitioningInCurrentJob(
+	check *descpb.TypeDescriptor_Domain_Check,
+) bool {
+	if !check.Validating {
+		return false
+	}
+	for _, name := range t.transitioningDomainChecks {
+		if check.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDomainConstraints validates the existing values of columns using the
+// domain against the constraints which are being added in the current job,
+// and then marks the constraints as validated.
+func (t *typeSchemaChanger) validateDomainConstraints(ctx context.Context) error {
+	// The validation is done in a separate txn to the one that mutates the
+	// descriptor, as it can take arbitrarily long.
+	validate := func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().ByID(txn.KV()).Get().Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		domain := typeDesc.TypeDesc().Domain
+		if t.transitioningDomainNotNull && domain.NotNullValidating {
+			if err := validateDomainConstraint(
+				ctx, txn, typeDesc, domainNotNullCheckExpr, pgcode.NotNullViolation, "contains null values",
+			); err != nil {
+				return err
+			}
+		}
+		for i := range domain.Checks {
+			check := &domain.Checks[i]
+			if !t.isDomainCheckTransitioningInCurrentJob(check) {
+				continue
+			}
+			if err := validateDomainConstraint(
+				ctx, txn, typeDesc, check.Expr, pgcode.CheckViolation,
+				"contains values that violate the new constraint",
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := t.execCfg.InternalDB.DescsTxn(ctx, validate); err != nil {
+		return err
+	}
+
+	run := func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		if t.transitioningDomainNotNull {
+			typeDesc.Domain.NotNullValidating = false
+		}
+		for i := range typeDesc.Domain.Checks {
+			check := &typeDesc.Domain.Checks[i]
+			if t.isDomainCheckTransitioningInCurrentJob(check) {
+				check.Validating = false
+			}
+		}
+		return txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, typeDesc, txn.KV())
+	}
+	return t.execCfg.InternalDB.DescsTxn(ctx, run)
+}
+
+// cleanupDomainConstraints removes the constraints of a domain which were
+// being added in the current job, if the job fails.
+func (t *typeSchemaChanger) cleanupDomainConstraints(ctx context.Context) error {
+	if len(t.transitioningDomainChecks) == 0 && !t.transitioningDomainNotNull {
+		return nil
+	}
+	cleanup := func(ctx context.Context, txn descs.Txn) error {
+		typeDesc, err := txn.Descriptors().MutableByID(txn.KV()).Type(ctx, t.typeID)
+		if err != nil {
+			return err
+		}
+		if typeDesc.Domain == nil {
+			return nil
+		}
+		if t.transitioningDomainNotNull && typeDesc.Domain.NotNullValidating {
+			typeDesc.Domain.NotNull = false
+			typeDesc.Domain.NotNullValidating = false
+		}
+		idx := 0
+		for _, check := range typeDesc.Domain.Checks {
+			if t.isDomainCheckTransitioningInCurrentJob(&check) {
+				continue
+			}
+			typeDesc.Domain.Checks[idx] = check
+			idx++
+		}
+		typeDesc.Domain.Checks = typeDesc.Domain.Checks[:idx]
+		return txn.Descriptors().WriteDesc(ctx, true /* kvTrace */, typeDesc, txn.KV())
+	}
+	return t.execCfg.InternalDB.DescsTxn(ctx, cleanup)
+}
+
 // applyFilterOnEnumMembers modifies the supplied typeDesc by removing all enum
 // members as dictated by shouldRemove.
 func applyFilterOnEnumMembers(
@@ -980,7 +1146,7 @@ func findUsagesOfEnumValueInPartitioning(
 		if !typT.UserDefined() {
 			continue
 		}
-		id := typedesc.UserDefinedTypeOIDToID(typT.Oid())
+		id := typedesc.UserDefinedTypeOIDToID(typT.UserDefinedOID())
 		if id != typ.GetID() {
 			continue
 		}
@@ -1251,10 +1417,13 @@ func (t *typeChangeResumer) Resume(ctx context.Context, execCtx interface{}) err
 			return nil
 		}
 	}
+	details := t.job.Details().(jobspb.TypeSchemaChangeDetails)
 	tc := &typeSchemaChanger{
-		typeID:               t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
-		transitioningMembers: t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
-		execCfg:              p.ExecCfg(),
+		typeID:                     details.TypeID,
+		transitioningMembers:       details.TransitioningMembers,
+		transitioningDomainChecks:  details.TransitioningDomainChecks,
+		transitioningDomainNotNull: details.TransitioningDomainNotNull,
+		execCfg:                    p.ExecCfg(),
 	}
 	return tc.execWithRetry(ctx)
 }
@@ -1264,16 +1433,22 @@ func (t *typeChangeResumer) OnFailOrCancel(
 	ctx context.Context, execCtx interface{}, _ error,
 ) error {
 	// If the job failed, just try again to clean up any draining names.
+	details := t.job.Details().(jobspb.TypeSchemaChangeDetails)
 	tc := &typeSchemaChanger{
-		typeID:               t.job.Details().(jobspb.TypeSchemaChangeDetails).TypeID,
-		transitioningMembers: t.job.Details().(jobspb.TypeSchemaChangeDetails).TransitioningMembers,
-		execCfg:              execCtx.(JobExecContext).ExecCfg(),
+		typeID:                     details.TypeID,
+		transitioningMembers:       details.TransitioningMembers,
+		transitioningDomainChecks:  details.TransitioningDomainChecks,
+		transitioningDomainNotNull: details.TransitioningDomainNotNull,
+		execCfg:                    execCtx.(JobExecContext).ExecCfg(),
 	}
 
 	if rollbackErr := func() error {
 		if err := tc.cleanupEnumValues(ctx); err != nil {
 			return err
 		}
+		if err := tc.cleanupDomainConstraints(ctx); err != nil {
+			return err
+		}
 
 		if fn := tc.execCfg.TypeSchemaChangerTestingKnobs.RunAfterOnFailOrCancel; fn != nil {
 			return fn()
@@ -101,11 +101,48 @@ var OidToType = map[oid.Oid]*T{
 	oid.T_uuid:         Uuid,
 	oid.T_varbit:       VarBit,
 	oid.T_varchar:      VarChar,
+	oid.T_trigger:      Trigger,
 	oid.T_void:         Void,
 
 	oidext.T_geometry:  Geometry,
 	oidext.T_geography: Geography,
 	oidext.T_box2d:     Box2D,
+
+	oid.T_int4range: Int4Range,
+	oid.T_int8range: Int8Range,
+	oid.T_numrange:  NumRange,
+	oid.T_tsrange:   TSRange,
+	oid.T_tstzrange: TSTZRange,
+	oid.T_daterange: DateRange,
+
+	oidext.T_int4multirange: Int4Multirange,
+	oidext.T_int8multirange: Int8Multirange,
+	oidext.T_nummultirange:  NumMultirange,
+	oidext.T_tsmultirange:   TSMultirange,
+	oidext.T_tstzmultirange: TSTZMultirange,
+	oidext.T_datemultirange: DateMultirange,
+}
+
+// rangeSubtypes maps the Oids of the built-in range types to the type of the
+// values in the range.
+var rangeSubtypes = map[oid.Oid]*T{
+	oid.T_int4range: Int4,
+	oid.T_int8range: Int,
+	oid.T_numrange:  Decimal,
+	oid.T_tsrange:   Timestamp,
+	oid.T_tstzrange: TimestampTZ,
+	oid.T_daterange: Date,
+}
+
+// multirangeToRangeOid maps the Oids of the built-in multirange types to the
+// Oid of the range type of their ranges.
+var multirangeToRangeOid = map[oid.Oid]oid.Oid{
+	oidext.T_int4multirange: oid.T_int4range,
+	oidext.T_int8multirange: oid.T_int8range,
+	oidext.T_nummultirange:  oid.T_numrange,
+	oidext.T_tsmultirange:   oid.T_tsrange,
+	oidext.T_tstzmultirange: oid.T_tstzrange,
+	oidext.T_datemultirange: oid.T_daterange,
 }
 
 // oidToArrayOid maps scalar type Oids to their corresponding array type Oid.
@@ -147,10 +184,22 @@ var oidToArrayOid = map[oid.Oid]oid.Oid{
 	oid.T_uuid:         oid.T__uuid,
 	oid.T_varbit:       oid.T__varbit,
 	oid.T_varchar:      oid.T__varchar,
+	oid.T_int4range:    oid.T__int4range,
+	oid.T_int8range:    oid.T__int8range,
+	oid.T_numrange:     oid.T__numrange,
+	oid.T_tsrange:      oid.T__tsrange,
+	oid.T_tstzrange:    oid.T__tstzrange,
+	oid.T_daterange:    oid.T__daterange,
 
-	oidext.T_geometry:  oidext.T__geometry,
-	oidext.T_geography: oidext.T__geography,
-	oidext.T_box2d:     oidext.T__box2d,
+	oidext.T_geometry:       oidext.T__geometry,
+	oidext.T_geography:      oidext.T__geography,
+	oidext.T_box2d:          oidext.T__box2d,
+	oidext.T_int4multirange: oidext.T__int4multirange,
+	oidext.T_int8multirange: oidext.T__int8multirange,
+	oidext.T_nummultirange:  oidext.T__nummultirange,
+	oidext.T_tsmultirange:   oidext.T__tsmultirange,
+	oidext.T_tstzmultirange: oidext.T__tstzmultirange,
+	oidext.T_datemultirange: oidext.T__datemultirange,
 }
 
 // familyToOid maps each type family to a default OID value that is used when
@@ -182,9 +231,11 @@ var familyToOid = map[Family]oid.Oid{
 	BitFamily:            oid.T_bit,
 	AnyFamily:            oid.T_anyelement,
 
-	GeometryFamily:  oidext.T_geometry,
-	GeographyFamily: oidext.T_geography,
-	Box2DFamily:     oidext.T_box2d,
+	GeometryFamily:   oidext.T_geometry,
+	GeographyFamily:  oidext.T_geography,
+	Box2DFamily:      oidext.T_box2d,
+	RangeFamily:      oid.T_int8range,
+	MultirangeFamily: oidext.T_int8multirange,
 }
 
 // ArrayOids is a set of all oids which correspond to an array type.
@@ -223,6 +274,11 @@ func CalcArrayOid(elemTyp *T) oid.Oid {
 	case EnumFamily:
 		return elemTyp.UserDefinedArrayOID()
 
+	case RangeFamily, MultirangeFamily:
+		if elemTyp.UserDefined() {
+			return elemTyp.UserDefinedArrayOID()
+		}
+
 	case TupleFamily:
 		if elemTyp.UserDefined() {
 			if elemTyp.TypeMeta.ImplicitRecordType {
@@ -202,6 +202,30 @@ type UserDefinedTypeMetadata struct {
 	// for a table. Note: this can be deleted if we migrate implicit record types
 	// to ordinary persisted composite types.
 	ImplicitRecordType bool
+
+	// DomainData is non-nil iff the metadata is for a domain type.
+	DomainData *DomainMetadata
+}
+
+// DomainMetadata is metadata about a domain needed for enforcing its
+// constraints.
+type DomainMetadata struct {
+	// NotNull is true if the domain does not allow NULL values.
+	NotNull bool
+	// DefaultExpr is the serialized default expression of the domain, or nil if
+	// the domain has no default.
+	DefaultExpr *string
+	// Checks are the CHECK constraints of the domain. Their expressions refer to
+	// the value being checked as the column "value".
+	Checks []DomainCheck
+}
+
+// DomainCheck is a CHECK constraint of a domain.
+type DomainCheck struct {
+	// Name is the name of the constraint.
+	Name string
+	// Expr is the serialized expression of the constraint.
+	Expr string
 }
 
 // EnumMetadata is metadata about an ENUM needed for evaluation.
@@ -466,6 +490,16 @@ var (
 		},
 	}
 
+	// Trigger is the type representing trigger, the return type of trigger
+	// functions.
+	Trigger = &T{
+		InternalType: InternalType{
+			Family: TriggerFamily,
+			Oid:    oid.T_trigger,
+			Locale: &emptyLocale,
+		},
+	}
+
 	// EncodedKey is a special type used internally for passing encoded key data.
 	// It behaves similarly to Bytes in most circumstances, except
 	// encoding/decoding. It is currently used to pass around inverted index keys,
@@ -497,6 +531,77 @@ var (
 		},
 	}
 
+	// Int4Range is the type of a range of Int4 values.
+	Int4Range = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_int4range, Locale: &emptyLocale}}
+
+	// Int8Range is the type of a range of Int values. This is the canonical
+	// type for RangeFamily.
+	Int8Range = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_int8range, Locale: &emptyLocale}}
+
+	// NumRange is the type of a range of Decimal values.
+	NumRange = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_numrange, Locale: &emptyLocale}}
+
+	// TSRange is the type of a range of Timestamp values.
+	TSRange = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_tsrange, Locale: &emptyLocale}}
+
+	// TSTZRange is the type of a range of TimestampTZ values.
+	TSTZRange = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_tstzrange, Locale: &emptyLocale}}
+
+	// DateRange is the type of a range of Date values.
+	DateRange = &T{InternalType: InternalType{
+		Family: RangeFamily, Oid: oid.T_daterange, Locale: &emptyLocale}}
+
+	// Int4Multirange is the type of a multirange of Int4Range values.
+	Int4Multirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_int4multirange, Locale: &emptyLocale}}
+
+	// Int8Multirange is the type of a multirange of Int8Range values. This is
+	// the canonical type for MultirangeFamily.
+	Int8Multirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_int8multirange, Locale: &emptyLocale}}
+
+	// NumMultirange is the type of a multirange of NumRange values.
+	NumMultirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_nummultirange, Locale: &emptyLocale}}
+
+	// TSMultirange is the type of a multirange of TSRange values.
+	TSMultirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_tsmultirange, Locale: &emptyLocale}}
+
+	// TSTZMultirange is the type of a multirange of TSTZRange values.
+	TSTZMultirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_tstzmultirange, Locale: &emptyLocale}}
+
+	// DateMultirange is the type of a multirange of DateRange values.
+	DateMultirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Oid: oidext.T_datemultirange, Locale: &emptyLocale}}
+
+	// RangeTypes contains all of the built-in range types.
+	RangeTypes = []*T{
+		Int4Range,
+		Int8Range,
+		NumRange,
+		TSRange,
+		TSTZRange,
+		DateRange,
+	}
+
+	// MultirangeTypes contains all of the built-in multirange types, in the
+	// same order as the corresponding range types in RangeTypes.
+	MultirangeTypes = []*T{
+		Int4Multirange,
+		Int8Multirange,
+		NumMultirange,
+		TSMultirange,
+		TSTZMultirange,
+		DateMultirange,
+	}
+
 	// Scalar contains all types that meet this criteria:
 	//
 	//   1. Scalar type (no ArrayFamily or TupleFamily types).
@@ -546,6 +651,18 @@ var (
 	AnyEnum = &T{InternalType: InternalType{
 		Family: EnumFamily, Locale: &emptyLocale, Oid: oid.T_anyenum}}
 
+	// AnyRange is a special type only used during static analysis as a
+	// wildcard type that matches any user-defined range type. Execution-time
+	// values should never have this type.
+	AnyRange = &T{InternalType: InternalType{
+		Family: RangeFamily, Locale: &emptyLocale, Oid: oid.T_anyrange}}
+
+	// AnyMultirange is a special type only used during static analysis as a
+	// wildcard type that matches any user-defined multirange type.
+	// Execution-time values should never have this type.
+	AnyMultirange = &T{InternalType: InternalType{
+		Family: MultirangeFamily, Locale: &emptyLocale, Oid: oidext.T_anymultirange}}
+
 	// AnyTuple is a special type used only during static analysis as a wildcard
 	// type that matches a tuple with any number of fields of any type (including
 	// tuple types). Execution-time values should never have this type.
@@ -1124,6 +1241,49 @@ func MakeEnum(typeOID, arrayTypeOID oid.Oid) *T {
 	}}
 }
 
+// MakeRange constructs a new instance of a user-defined RangeFamily type over
+// the given subtype with the given stable type ID. Note that it does not
+// hydrate cached fields on the type.
+func MakeRange(typeOID, arrayTypeOID oid.Oid, subtype *T) *T {
+	return &T{InternalType: InternalType{
+		Family: RangeFamily,
+		Oid:    typeOID,
+		Locale: &emptyLocale,
+		UDTMetadata: &PersistentUserDefinedTypeMetadata{
+			ArrayTypeOID: arrayTypeOID,
+		},
+		RangeContents: subtype,
+	}}
+}
+
+// MakeMultirange constructs a new instance of a user-defined MultirangeFamily
+// type of the given user-defined range type with the given stable type ID.
+// Note that it does not hydrate cached fields on the type.
+func MakeMultirange(typeOID, arrayTypeOID oid.Oid, rangeTyp *T) *T {
+	return &T{InternalType: InternalType{
+		Family: MultirangeFamily,
+		Oid:    typeOID,
+		Locale: &emptyLocale,
+		UDTMetadata: &PersistentUserDefinedTypeMetadata{
+			ArrayTypeOID: arrayTypeOID,
+		},
+		RangeContents: rangeTyp,
+	}}
+}
+
+// MakeDomain constructs a new instance of a domain type over the given base
+// type with the given stable type ID. The domain type has the same family and
+// OID as its base type, so values of the domain are treated as values of the
+// base type everywhere other than where the domain's constraints are
+// enforced. Note that it does not hydrate cached fields on the type.
+func MakeDomain(base *T, domainOID oid.Oid) *T {
+	internalType := base.InternalType
+	internalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		DomainOID: domainOID,
+	}
+	return &T{InternalType: internalType}
+}
+
 // MakeArray constructs a new instance of an ArrayFamily type with the given
 // element type (which may itself be an ArrayFamily type).
 func MakeArray(typ *T) *T {
@@ -1342,6 +1502,11 @@ func (t *T) WithoutTypeModifiers() *T {
 	case EnumFamily:
 		// Enums have no type modifiers.
 		return t
+	case RangeFamily, MultirangeFamily:
+		// User-defined ranges have no type modifiers.
+		if t.UserDefined() {
+			return t
+		}
 	}
 
 	// For types that can be a collated string, we copy the type and set the width
@@ -1388,6 +1553,45 @@ func (t *T) TupleContents() []*T {
 	return t.InternalType.TupleContents
 }
 
+// RangeSubtype returns the type of the values in a range or multirange type. It
+// returns nil for all other types.
+func (t *T) RangeSubtype() *T {
+	switch t.Family() {
+	case RangeFamily:
+		if t.InternalType.RangeContents != nil {
+			return t.InternalType.RangeContents
+		}
+		return rangeSubtypes[t.Oid()]
+	case MultirangeFamily:
+		if rangeTyp := t.MultirangeContents(); rangeTyp != nil {
+			return rangeTyp.RangeSubtype()
+		}
+	}
+	return nil
+}
+
+// MultirangeContents returns the range type of the ranges in a multirange
+// type. It returns nil for all other types.
+func (t *T) MultirangeContents() *T {
+	if t.Family() != MultirangeFamily {
+		return nil
+	}
+	if t.InternalType.RangeContents != nil {
+		return t.InternalType.RangeContents
+	}
+	return OidToType[multirangeToRangeOid[t.Oid()]]
+}
+
+// MultirangeOf returns the multirange type of the given built-in range type.
+func MultirangeOf(rangeTyp *T) *T {
+	for i := range RangeTypes {
+		if RangeTypes[i].Oid() == rangeTyp.Oid() {
+			return MultirangeTypes[i]
+		}
+	}
+	panic(errors.AssertionFailedf("%s is not a range type", rangeTyp))
+}
+
 // TupleLabels returns a slice containing the labels of each tuple field. This
 // is nil for types not in the TupleFamily, or if the tuple type does not
 // specify labels.
@@ -1412,6 +1616,14 @@ func (t *T) UserDefinedArrayOID() oid.Oid {
 // be used when type is known to not be shared. If the input oid values are
 // 0 then the RemapUserDefinedTypeOIDs has no effect.
 func RemapUserDefinedTypeOIDs(t *T, newOID, newArrayOID oid.Oid) {
+	if t.IsDomain() {
+		// The OID of a domain is the OID of its base type, so only the domain
+		// OID needs to be remapped. Domains don't have array types.
+		if newOID != 0 {
+			t.InternalType.UDTMetadata.DomainOID = newOID
+		}
+		return
+	}
 	if newOID != 0 {
 		t.InternalType.Oid = newOID
 	}
@@ -1422,7 +1634,41 @@ func RemapUserDefinedTypeOIDs(t *T, newOID, newArrayOID oid.Oid) {
 
 // UserDefined returns whether or not t is a user defined type.
 func (t *T) UserDefined() bool {
-	return IsOIDUserDefinedType(t.Oid())
+	return IsOIDUserDefinedType(t.Oid()) || t.IsDomain()
+}
+
+// UserDefinedOID returns the OID which identifies the descriptor of a user
+// defined type. This is the same as Oid() for all types except domains, whose
+// Oid() is the OID of their base type.
+func (t *T) UserDefinedOID() oid.Oid {
+	if t.IsDomain() {
+		return t.DomainOID()
+	}
+	return t.Oid()
+}
+
+// IsDomain returns whether or not t is a domain type.
+func (t *T) IsDomain() bool {
+	return t.DomainOID() != 0
+}
+
+// DomainOID returns the OID of the domain type, or zero if t is not a domain.
+func (t *T) DomainOID() oid.Oid {
+	if t.InternalType.UDTMetadata == nil {
+		return 0
+	}
+	return t.InternalType.UDTMetadata.DomainOID
+}
+
+// DomainBaseType returns the base type of a domain type. It returns t itself
+// if t is not a domain.
+func (t *T) DomainBaseType() *T {
+	if !t.IsDomain() {
+		return t
+	}
+	internalType := t.InternalType
+	internalType.UDTMetadata = nil
+	return &T{InternalType: internalType}
 }
 
 // IsOIDUserDefinedType returns whether or not o corresponds to a user
@@ -1461,7 +1707,10 @@ var familyNames = map[Family]string{
 	UnknownFamily:        "unknown",
 	UuidFamily:           "uuid",
 	VoidFamily:           "void",
+	TriggerFamily:        "trigger",
 	EncodedKeyFamily:     "encodedkey",
+	RangeFamily:          "range",
+	MultirangeFamily:     "multirange",
 }
 
 // Name returns a user-friendly word indicating the family type.
@@ -1483,6 +1732,9 @@ func (f Family) Name() string {
 //
 // TODO(andyk): Should these be changed to be the same as SQLStandardName?
 func (t *T) Name() string {
+	if t.IsDomain() && t.TypeMeta.Name != nil {
+		return t.TypeMeta.Name.Basename()
+	}
 	switch fam := t.Family(); fam {
 	case AnyFamily:
 		return "anyelement"
@@ -1546,6 +1798,9 @@ func (t *T) Name() string {
 	case TupleFamily:
 		return t.SQLStandardName()
 
+	case RangeFamily, MultirangeFamily:
+		return t.PGName()
+
 	case EnumFamily:
 		if t.Oid() == oid.T_anyenum {
 			return "anyenum"
@@ -1572,6 +1827,9 @@ func (t *T) Name() string {
 //	bytes        bytea
 //	int4[]       _int4
 func (t *T) PGName() string {
+	if t.IsDomain() && t.TypeMeta.Name != nil {
+		return t.TypeMeta.Name.Basename()
+	}
 	name, ok := oidext.TypeName(t.Oid())
 	if ok {
 		return strings.ToLower(name)
@@ -1620,6 +1878,9 @@ func (t *T) TelemetryName() string {
 // This function is full of special cases. See backend/utils/adt/format_type.c
 // in Postgres.
 func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
+	if t.IsDomain() && t.TypeMeta.Name != nil {
+		return t.TypeMeta.Name.Basename()
+	}
 	var buf strings.Builder
 	switch t.Family() {
 	case AnyFamily:
@@ -1778,6 +2039,8 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		return "tsquery"
 	case TSVectorFamily:
 		return "tsvector"
+	case RangeFamily, MultirangeFamily:
+		return t.PGName()
 	case TupleFamily:
 		if t.UserDefined() {
 			// If we have a user-defined tuple type, use its user-defined name.
@@ -1790,6 +2053,8 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		return "uuid"
 	case VoidFamily:
 		return "void"
+	case TriggerFamily:
+		return "trigger"
 	case EnumFamily:
 		return t.TypeMeta.Name.Basename()
 	default:
@@ -1807,6 +2072,10 @@ func (t *T) InformationSchemaName() string {
 	if t.Family() == ArrayFamily {
 		return "ARRAY"
 	}
+	// Domains are reported as their base type.
+	if t.IsDomain() {
+		return t.DomainBaseType().InformationSchemaName()
+	}
 	// TypeMeta attributes are populated only when it is user defined type.
 	if t.TypeMeta.Name != nil {
 		return "USER-DEFINED"
@@ -1818,6 +2087,13 @@ func (t *T) InformationSchemaName() string {
 // reproduce the type via parsing the string as a type. It is used in error
 // messages and also to produce the output of SHOW CREATE.
 func (t *T) SQLString() string {
+	if t.IsDomain() {
+		// See the comment on the EnumFamily case below.
+		if t.TypeMeta.Name == nil {
+			return fmt.Sprintf("@%d", t.DomainOID())
+		}
+		return t.TypeMeta.Name.FQName()
+	}
 	switch t.Family() {
 	case BitFamily:
 		o := t.Oid()
@@ -1907,6 +2183,14 @@ func (t *T) SQLString() string {
 			return t.ArrayContents().collatedStringTypeSQL(true /* isArray */)
 		}
 		return t.ArrayContents().SQLString() + "[]"
+	case RangeFamily, MultirangeFamily:
+		if t.UserDefined() {
+			// See the comment on the EnumFamily case below.
+			if t.TypeMeta.Name == nil {
+				return fmt.Sprintf("@%d", t.Oid())
+			}
+			return t.TypeMeta.Name.FQName()
+		}
 	case EnumFamily:
 		if t.Oid() == oid.T_anyenum {
 			return "anyenum"
@@ -1990,6 +2274,22 @@ func (t *T) Equivalent(other *T) bool {
 		if t.Oid() != other.Oid() {
 			return false
 		}
+
+	case RangeFamily, MultirangeFamily:
+		// If one of the types is anyrange or anymultirange, then it matches any
+		// user-defined range or multirange type -- these wildcards are used when
+		// matching overloads. The built-in range types have their own overloads.
+		if IsWildcardRangeType(t) {
+			return other.UserDefined() || IsWildcardRangeType(other)
+		}
+		if IsWildcardRangeType(other) {
+			return t.UserDefined()
+		}
+		// Ranges of different subtypes cannot be compared or assigned to one
+		// another.
+		if t.Oid() != other.Oid() {
+			return false
+		}
 	}
 
 	return true
@@ -2135,8 +2435,18 @@ func (t *InternalType) Identical(other *InternalType) bool {
 			return false
 		}
 	}
+	if t.RangeContents != nil && other.RangeContents != nil {
+		if !t.RangeContents.Identical(other.RangeContents) {
+			return false
+		}
+	} else if t.RangeContents != nil {
+		return false
+	} else if other.RangeContents != nil {
+		return false
+	}
 	if t.UDTMetadata != nil && other.UDTMetadata != nil {
-		if t.UDTMetadata.ArrayTypeOID != other.UDTMetadata.ArrayTypeOID {
+		if t.UDTMetadata.ArrayTypeOID != other.UDTMetadata.ArrayTypeOID ||
+			t.UDTMetadata.DomainOID != other.UDTMetadata.DomainOID {
 			return false
 		}
 	} else if t.UDTMetadata != nil {
@@ -2532,6 +2842,12 @@ func (t *T) MarshalText() (text []byte, err error) {
 // DebugString returns a detailed dump of the type protobuf struct, suitable for
 // debugging scenarios.
 func (t *T) DebugString() string {
+	if t.Family() == MultirangeFamily && t.MultirangeContents().UserDefined() {
+		// See the comment on the ArrayFamily case below.
+		internalTypeCopy := protoutil.Clone(&t.InternalType).(*InternalType)
+		internalTypeCopy.RangeContents.TypeMeta = UserDefinedTypeMetadata{}
+		return internalTypeCopy.String()
+	}
 	if t.Family() == ArrayFamily && t.ArrayContents().UserDefined() {
 		// In the case that this type is an array that contains a user defined
 		// type, the introspection that protobuf performs to generate a string
@@ -2572,6 +2888,8 @@ func (t *T) IsAmbiguous() bool {
 		return t.ArrayContents().IsAmbiguous()
 	case EnumFamily:
 		return t.Oid() == oid.T_anyenum
+	case RangeFamily, MultirangeFamily:
+		return IsWildcardRangeType(t)
 	}
 	return false
 }
@@ -2711,6 +3029,12 @@ func IsWildcardTupleType(t *T) bool {
 	return len(t.TupleContents()) == 1 && t.TupleContents()[0].Family() == AnyFamily
 }
 
+// IsWildcardRangeType returns true if this is the wildcard AnyRange or
+// AnyMultirange type.
+func IsWildcardRangeType(t *T) bool {
+	return t.Oid() == oid.T_anyrange || t.Oid() == oidext.T_anymultirange
+}
+
 // IsRecordType returns true if this is a RECORD type. This should only be used
 // when processing UDFs. A record differs from AnyTuple in that the tuple
 // contents may contain types other than Any.
@@ -13,17 +13,28 @@ package sql
 import (
 	"context"
 
-	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
 
+type unlistenNode struct {
+	n *tree.Unlisten
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
 func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
-	p.BufferClientNotice(ctx,
-		pgerror.WithSeverity(
-			unimplemented.NewWithIssuef(41522, "CRDB does not support LISTEN, making UNLISTEN a no-op"),
-			"NOTICE",
-		),
-	)
-	return newZeroNode(nil /* columns */), nil
+	return &unlistenNode{n: n}, nil
 }
+
+func (n *unlistenNode) startExec(params runParams) error {
+	if n.n.Star {
+		params.p.notifications.unlistenAll()
+	} else {
+		params.p.notifications.unlisten(n.n.ChannelName.Object())
+	}
+	return nil
+}
+
+func (n *unlistenNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *unlistenNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *unlistenNode) Close(ctx context.Context)           {}
@@ -47,9 +47,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 )
 
@@ -398,20 +400,45 @@ var varGen = map[string]sessionVar{
 		GlobalDefault: func(sv *settings.Values) string { return "" },
 	},
 
+	// See https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TEXT-SEARCH-CONFIG
+	`default_text_search_config`: {
+		Set: func(_ context.Context, m sessionDataMutator, s string) error {
+			name, err := tsearch.ValidateTextSearchConfig(s)
+			if err != nil {
+				return err
+			}
+			m.SetDefaultTextSearchConfig(name)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
+			return evalCtx.SessionData().GetDefaultTextSearchConfig(), nil
+		},
+		GlobalDefault: func(sv *settings.Values) string { return sessiondata.DefaultTextSearchConfig },
+	},
+
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-ISOLATION
 	`default_transaction_isolation`: {
 		Set: func(_ context.Context, m sessionDataMutator, s string) error {
 			switch strings.ToUpper(s) {
-			case `READ UNCOMMITTED`, `READ COMMITTED`, `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`, `DEFAULT`:
-				// Do nothing. All transactions execute with serializable isolation.
+			case `READ UNCOMMITTED`, `READ COMMITTED`:
+				// READ UNCOMMITTED is upgraded to READ COMMITTED, like in Postgres.
+				m.SetDefaultTransactionIsolationLevel(tree.ReadCommittedIsolation)
+			case `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`:
+				m.SetDefaultTransactionIsolationLevel(tree.SerializableIsolation)
+			case `DEFAULT`:
+				m.SetDefaultTransactionIsolationLevel(tree.UnspecifiedIsolation)
 			default:
-				return newVarValueError(`default_transaction_isolation`, s, "serializable")
+				return newVarValueError(`default_transaction_isolation`, s, "serializable", "read committed")
 			}
 
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
-			return "serializable", nil
+			level := tree.IsolationLevel(evalCtx.SessionData().DefaultTxnIsolationLevel)
+			if level == tree.UnspecifiedIsolation {
+				level = tree.SerializableIsolation
+			}
+			return strings.ToLower(level.String()), nil
 		},
 		GlobalDefault: func(sv *settings.Values) string { return "default" },
 	},
@@ -1442,15 +1469,17 @@ var varGen = map[string]sessionVar{
 	// This is not directly documented in PG's docs but does indeed behave this way.
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
-		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
-			return "serializable", nil
+		Get: func(evalCtx *extendedEvalContext, txn *kv.Txn) (string, error) {
+			level := kvTxnIsolationLevelToTree(txn.IsoLevel())
+			return strings.ToLower(level.String()), nil
 		},
-		RuntimeSet: func(_ context.Context, evalCtx *extendedEvalContext, local bool, s string) error {
-			_, ok := tree.IsolationLevelMap[s]
+		RuntimeSet: func(ctx context.Context, evalCtx *extendedEvalContext, local bool, s string) error {
+			level, ok := tree.IsolationLevelMap[strings.ToLower(s)]
 			if !ok {
-				return newVarValueError(`transaction_isolation`, s, "serializable")
+				return newVarValueError(`transaction_isolation`, s, "serializable", "read committed")
 			}
-			return nil
+			modes := tree.TransactionModes{Isolation: level}
+			return evalCtx.TxnModesSetter.setTransactionModes(ctx, modes, hlc.Timestamp{} /* asOfTs */)
 		},
 		GlobalDefault: func(_ *settings.Values) string { return "serializable" },
 	},
@@ -2566,6 +2595,23 @@ var varGen = map[string]sessionVar{
 		},
 		GlobalDefault: globalFalse,
 	},
+
+	// CockroachDB extension.
+	`durable_locking_for_update`: {
+		GetStringVal: makePostgresBoolGetStringValFn(`durable_locking_for_update`),
+		Set: func(_ context.Context, m sessionDataMutator, s string) error {
+			b, err := paramparse.ParseBoolVar("durable_locking_for_update", s)
+			if err != nil {
+				return err
+			}
+			m.SetDurableLockingForUpdate(b)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData().DurableLockingForUpdate), nil
+		},
+		GlobalDefault: globalFalse,
+	},
 }
 
 // We want test coverage for this on and off so make it metamorphic.
@@ -1385,7 +1385,8 @@ CREATE TABLE pg_catalog.pg_statio_all_indexes (
 	idx_blks_hit INT
 )`
 
-// PgCatalogTsConfig is an empty table in the pg_catalog that is not implemented yet
+// PgCatalogTsConfig describes the schema of the pg_catalog.pg_ts_config table.
+// https://www.postgresql.org/docs/current/catalog-pg-ts-config.html
 const PgCatalogTsConfig = `
 CREATE TABLE pg_catalog.pg_ts_config (
 	oid OID,
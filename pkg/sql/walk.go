@@ -196,6 +196,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *upsertNode:
 		n.source = v.visit(n.source)
 
+	case *mergeNode:
+		n.source = v.visit(n.source)
+
 	case *updateNode:
 		n.source = v.visit(n.source)
 
@@ -251,6 +254,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *ordinalityNode:
 		n.source = v.visit(n.source)
 
+	case *expandNode:
+		n.source = v.visit(n.source)
+
 	case *spoolNode:
 		n.source = v.visit(n.source)
 
@@ -349,6 +355,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterDatabaseDropSecondaryRegion{}):        "alter database secondary region",
 	reflect.TypeOf(&alterDatabaseSetZoneConfigExtensionNode{}): "alter database configure zone extension",
 	reflect.TypeOf(&alterDefaultPrivilegesNode{}):              "alter default privileges",
+	reflect.TypeOf(&alterDomainNode{}):                         "alter domain",
 	reflect.TypeOf(&alterFunctionOptionsNode{}):                "alter function",
 	reflect.TypeOf(&alterFunctionRenameNode{}):                 "alter function rename",
 	reflect.TypeOf(&alterFunctionSetOwnerNode{}):               "alter function owner",
@@ -370,6 +377,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterRoleSetNode{}):                        "alter role set var",
 	reflect.TypeOf(&applyJoinNode{}):                           "apply join",
 	reflect.TypeOf(&bufferNode{}):                              "buffer",
+	reflect.TypeOf(&callNode{}):                                "call",
 	reflect.TypeOf(&cancelQueriesNode{}):                       "cancel queries",
 	reflect.TypeOf(&cancelSessionsNode{}):                      "cancel sessions",
 	reflect.TypeOf(&cdcValuesNode{}):                           "wrapped streaming node",
@@ -385,6 +393,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlJobsNode{}):                         "control jobs",
 	reflect.TypeOf(&controlSchedulesNode{}):                    "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):                      "create database",
+	reflect.TypeOf(&createDomainNode{}):                        "create domain",
 	reflect.TypeOf(&createExtensionNode{}):                     "create extension",
 	reflect.TypeOf(&createExternalConectionNode{}):             "create external connection",
 	reflect.TypeOf(&createFunctionNode{}):                      "create function",
@@ -394,6 +403,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&createStatsNode{}):                         "create statistics",
 	reflect.TypeOf(&createTableNode{}):                         "create table",
 	reflect.TypeOf(&createTenantNode{}):                        "create tenant",
+	reflect.TypeOf(&createTriggerNode{}):                       "create trigger",
 	reflect.TypeOf(&createTypeNode{}):                          "create type",
 	reflect.TypeOf(&CreateRoleNode{}):                          "create user/role",
 	reflect.TypeOf(&createViewNode{}):                          "create view",
@@ -410,10 +420,12 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&dropSchemaNode{}):                          "drop schema",
 	reflect.TypeOf(&dropTableNode{}):                           "drop table",
 	reflect.TypeOf(&dropTenantNode{}):                          "drop tenant",
+	reflect.TypeOf(&dropTriggerNode{}):                         "drop trigger",
 	reflect.TypeOf(&dropTypeNode{}):                            "drop type",
 	reflect.TypeOf(&DropRoleNode{}):                            "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):                            "drop view",
 	reflect.TypeOf(&errorIfRowsNode{}):                         "error if rows",
+	reflect.TypeOf(&expandNode{}):                              "expand",
 	reflect.TypeOf(&explainPlanNode{}):                         "explain plan",
 	reflect.TypeOf(&explainVecNode{}):                          "explain vectorized",
 	reflect.TypeOf(&explainDDLNode{}):                          "explain ddl",
@@ -430,8 +442,11 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&invertedJoinNode{}):                        "inverted join",
 	reflect.TypeOf(&joinNode{}):                                "join",
 	reflect.TypeOf(&limitNode{}):                               "limit",
+	reflect.TypeOf(&listenNode{}):                              "listen",
 	reflect.TypeOf(&lookupJoinNode{}):                          "lookup join",
 	reflect.TypeOf(&max1RowNode{}):                             "max1row",
+	reflect.TypeOf(&mergeNode{}):                               "merge",
+	reflect.TypeOf(&notifyNode{}):                              "notify",
 	reflect.TypeOf(&ordinalityNode{}):                          "ordinality",
 	reflect.TypeOf(&projectSetNode{}):                          "project set",
 	reflect.TypeOf(&reassignOwnedByNode{}):                     "reassign owned by",
@@ -469,6 +484,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&sortNode{}):                                "sort",
 	reflect.TypeOf(&splitNode{}):                               "split",
 	reflect.TypeOf(&topKNode{}):                                "top-k",
+	reflect.TypeOf(&unlistenNode{}):                            "unlisten",
 	reflect.TypeOf(&unsplitNode{}):                             "unsplit",
 	reflect.TypeOf(&unsplitAllNode{}):                          "unsplit all",
 	reflect.TypeOf(&spoolNode{}):                               "spool",
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -306,6 +307,24 @@ type MVCCIterator interface {
 	//
 	// REQUIRES: Valid() returns true.
 	UnsafeLazyValue() pebble.LazyValue
+	// ReplicatedLocks returns the replicated locks that are not intents, which
+	// a prefix iterator that interleaves intents stepped over in the lock table
+	// key space while looking for an intent during the most recent SeekGE or
+	// SeekIntentGE. This lets writers check for conflicting locks without a
+	// separate seek of the lock table. The iterator only looks for an intent if
+	// the key has an MVCC version. Other iterators return nil.
+	//
+	// The returned slice is invalidated on the next call to SeekGE,
+	// SeekIntentGE or Close.
+	ReplicatedLocks() []ReplicatedLock
+}
+
+// ReplicatedLock is a replicated lock that is not an intent, i.e. one that is
+// not accompanied by a provisional value. See MVCCAcquireLock.
+type ReplicatedLock struct {
+	Key      roachpb.Key
+	Strength lock.Strength
+	Meta     enginepb.MVCCMetadata
 }
 
 // EngineIterator is an iterator over key-value pairs where the key is
@@ -1165,14 +1184,13 @@ func GetIntent(reader Reader, key roachpb.Key) (*roachpb.Intent, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, nil
-	}
-
-	engineKey, err := iter.EngineKey()
+	engineKey, valid, err := skipNonIntentLocks(iter, valid)
 	if err != nil {
 		return nil, err
 	}
+	if !valid {
+		return nil, nil
+	}
 	checkKey, err := keys.DecodeLockTableSingleKey(engineKey.Key)
 	if err != nil {
 		return nil, err
@@ -1200,16 +1218,35 @@ func GetIntent(reader Reader, key roachpb.Key) (*roachpb.Intent, error) {
 		// We expect false on the call to next, but not an error.
 		return nil, err
 	}
+	engineKey, hasNext, err = skipNonIntentLocks(iter, hasNext)
+	if err != nil {
+		return nil, err
+	}
 	// This should not be possible. There can only be one outstanding write
 	// intent for a key and with prefix match we don't find additional names.
 	if hasNext {
+		return nil, errors.AssertionFailedf("unexpected additional key found %v while looking for %v", engineKey, key)
+	}
+	return &intent, nil
+}
+
+// skipNonIntentLocks steps the lock table iterator forward past any
+// replicated locks that are not intents, and returns the key of the intent it
+// is positioned at, if valid.
+func skipNonIntentLocks(iter EngineIterator, valid bool) (EngineKey, bool, error) {
+	for valid {
 		engineKey, err := iter.EngineKey()
 		if err != nil {
-			return nil, err
+			return EngineKey{}, false, err
+		}
+		if engineKey.IsLockTableIntentKey() {
+			return engineKey, true, nil
+		}
+		if valid, err = iter.NextEngineKey(); err != nil {
+			return EngineKey{}, false, err
 		}
-		return nil, errors.AssertionFailedf("unexpected additional key found %v while looking for %v", engineKey, key)
 	}
-	return &intent, nil
+	return EngineKey{}, false, nil
 }
 
 // Scan returns up to max point key/value objects from start (inclusive) to end
@@ -1266,6 +1303,10 @@ func ScanIntents(
 		if err != nil {
 			return nil, err
 		}
+		if !key.IsLockTableIntentKey() {
+			// Replicated locks that are not intents are not returned.
+			continue
+		}
 		lockedKey, err := keys.DecodeLockTableSingleKey(key.Key)
 		if err != nil {
 			return nil, err
@@ -1767,6 +1808,13 @@ func ScanConflictingIntentsForDroppingLatchesEarly(
 			// not needing intent history.
 			return true /* needsIntentHistory */, nil
 		}
+		if engineKey, err := iter.UnsafeEngineKey(); err != nil {
+			return false, err
+		} else if !engineKey.IsLockTableIntentKey() {
+			// Replicated locks that are not intents do not conflict with
+			// non-locking reads.
+			continue
+		}
 		v, err := iter.UnsafeValue()
 		if err != nil {
 			return false, err
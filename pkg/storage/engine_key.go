@@ -145,6 +145,13 @@ func (k EngineKey) IsLockTableKey() bool {
 	return len(k.Version) == engineKeyVersionLockTableLen
 }
 
+// IsLockTableIntentKey returns true if the key can be decoded as a
+// LockTableKey for an intent, as opposed to a replicated lock that is not
+// accompanied by a provisional value.
+func (k EngineKey) IsLockTableIntentKey() bool {
+	return k.IsLockTableKey() && k.Version[0] == lockTableKeyStrengthIntent
+}
+
 // ToMVCCKey constructs a MVCCKey from the EngineKey.
 func (k EngineKey) ToMVCCKey() (MVCCKey, error) {
 	key := MVCCKey{Key: k.Key}
@@ -175,9 +182,9 @@ func (k EngineKey) ToLockTableKey() (LockTableKey, error) {
 	key := LockTableKey{Key: lockedKey}
 	switch len(k.Version) {
 	case engineKeyVersionLockTableLen:
-		key.Strength = lock.Strength(k.Version[0])
-		if key.Strength < lock.None || key.Strength > lock.Exclusive {
-			return LockTableKey{}, errors.Errorf("unknown strength %d", key.Strength)
+		key.Strength, err = getReplicatedLockStrengthForByte(k.Version[0])
+		if err != nil {
+			return LockTableKey{}, err
 		}
 		key.TxnUUID = k.Version[1:]
 	default:
@@ -251,7 +258,10 @@ func (m EngineKeyFormatter) Format(f fmt.State, c rune) {
 	m.key.Format(f, c)
 }
 
-// LockTableKey is a key representing a lock in the lock table.
+// LockTableKey is a key representing a lock in the lock table. Intents are
+// represented as locks with the Intent strength. Other replicated locks, which
+// are not accompanied by a provisional value, use the Exclusive or Shared
+// strength.
 type LockTableKey struct {
 	Key      roachpb.Key
 	Strength lock.Strength
@@ -267,9 +277,7 @@ func (lk LockTableKey) ToEngineKey(buf []byte) (EngineKey, []byte) {
 	if len(lk.TxnUUID) != uuid.Size {
 		panic("invalid TxnUUID")
 	}
-	if lk.Strength != lock.Exclusive {
-		panic("unsupported lock strength")
-	}
+	strByte := getByteForReplicatedLockStrength(lk.Strength)
 	// The first term in estimatedLen is for LockTableSingleKey.
 	estimatedLen :=
 		(len(keys.LocalRangeLockTablePrefix) + len(keys.LockTableSingleKeyInfix) + len(lk.Key) + 3) +
@@ -285,11 +293,53 @@ func (lk LockTableKey) ToEngineKey(buf []byte) (EngineKey, []byte) {
 		// estimatedLen was an underestimate.
 		k.Version = make([]byte, engineKeyVersionLockTableLen)
 	}
-	k.Version[0] = byte(lk.Strength)
+	k.Version[0] = strByte
 	copy(k.Version[1:], lk.TxnUUID)
 	return k, buf
 }
 
+// The byte values used to encode the strength of a replicated lock in the
+// version of its lock table key. Before replicated locks other than intents
+// existed, intents were encoded using the byte value of lock.Exclusive. That
+// value is retained for intents so that they remain decodable, and the other
+// strengths are assigned the byte values below it, in increasing order of
+// strength.
+const (
+	lockTableKeyStrengthShared    byte = 1
+	lockTableKeyStrengthExclusive byte = 2
+	lockTableKeyStrengthIntent    byte = 3
+)
+
+// getByteForReplicatedLockStrength returns the byte used to encode the given
+// replicated lock strength in a lock table key.
+func getByteForReplicatedLockStrength(str lock.Strength) byte {
+	switch str {
+	case lock.Shared:
+		return lockTableKeyStrengthShared
+	case lock.Exclusive:
+		return lockTableKeyStrengthExclusive
+	case lock.Intent:
+		return lockTableKeyStrengthIntent
+	default:
+		panic(errors.AssertionFailedf("unsupported replicated lock strength %s", str))
+	}
+}
+
+// getReplicatedLockStrengthForByte is the inverse of
+// getByteForReplicatedLockStrength.
+func getReplicatedLockStrengthForByte(b byte) (lock.Strength, error) {
+	switch b {
+	case lockTableKeyStrengthShared:
+		return lock.Shared, nil
+	case lockTableKeyStrengthExclusive:
+		return lock.Exclusive, nil
+	case lockTableKeyStrengthIntent:
+		return lock.Intent, nil
+	default:
+		return lock.None, errors.Errorf("unknown strength %d", b)
+	}
+}
+
 // EngineRangeKeyValue is a raw value for a general range key as stored in the
 // engine. It consists of a version (suffix) and corresponding value. The range
 // key bounds are not included, but are surfaced via EngineRangeBounds().
@@ -35,6 +35,8 @@ func TestLockTableKeyEncodeDecode(t *testing.T) {
 	}{
 		{key: LockTableKey{Key: roachpb.Key("foo"), Strength: lock.Exclusive, TxnUUID: uuid1[:]}},
 		{key: LockTableKey{Key: roachpb.Key("a"), Strength: lock.Exclusive, TxnUUID: uuid2[:]}},
+		{key: LockTableKey{Key: roachpb.Key("b"), Strength: lock.Shared, TxnUUID: uuid1[:]}},
+		{key: LockTableKey{Key: roachpb.Key("c"), Strength: lock.Intent, TxnUUID: uuid2[:]}},
 		// Causes a doubly-local range local key.
 		{key: LockTableKey{
 			Key:      keys.RangeDescriptorKey(roachpb.RKey("baz")),
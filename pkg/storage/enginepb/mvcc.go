@@ -14,6 +14,7 @@ import (
 	"math"
 	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/isolation"
 	"github.com/cockroachdb/redact"
 )
 
@@ -91,16 +92,17 @@ func (t TxnMeta) Short() redact.SafeString {
 
 // Total returns the range size as the sum of the key and value
 // bytes. This includes all non-live keys and all versioned values,
-// both for point and range keys.
+// both for point and range keys, as well as replicated locks.
 func (ms MVCCStats) Total() int64 {
-	return ms.KeyBytes + ms.ValBytes + ms.RangeKeyBytes + ms.RangeValBytes
+	return ms.KeyBytes + ms.ValBytes + ms.RangeKeyBytes + ms.RangeValBytes + ms.LockBytes
 }
 
 // GCBytes is a convenience function which returns the number of gc bytes,
 // that is the key and value bytes excluding the live bytes, both for
-// point keys and range keys.
+// point keys and range keys. Replicated locks are not garbage collected by
+// MVCC GC, so they are not included.
 func (ms MVCCStats) GCBytes() int64 {
-	return ms.Total() - ms.LiveBytes
+	return ms.KeyBytes + ms.ValBytes + ms.RangeKeyBytes + ms.RangeValBytes - ms.LiveBytes
 }
 
 // HasNoUserData returns true if there is no user data in the range.
@@ -183,6 +185,8 @@ func (ms *MVCCStats) Add(oms MVCCStats) {
 	ms.RangeKeyBytes += oms.RangeKeyBytes
 	ms.RangeValCount += oms.RangeValCount
 	ms.RangeValBytes += oms.RangeValBytes
+	ms.LockBytes += oms.LockBytes
+	ms.LockCount += oms.LockCount
 	ms.SysBytes += oms.SysBytes
 	ms.SysCount += oms.SysCount
 	ms.AbortSpanBytes += oms.AbortSpanBytes
@@ -214,6 +218,8 @@ func (ms *MVCCStats) Subtract(oms MVCCStats) {
 	ms.RangeKeyBytes -= oms.RangeKeyBytes
 	ms.RangeValCount -= oms.RangeValCount
 	ms.RangeValBytes -= oms.RangeValBytes
+	ms.LockBytes -= oms.LockBytes
+	ms.LockCount -= oms.LockCount
 	ms.SysBytes -= oms.SysBytes
 	ms.SysCount -= oms.SysCount
 	ms.AbortSpanBytes -= oms.AbortSpanBytes
@@ -356,6 +362,9 @@ func (t TxnMeta) SafeFormat(w redact.SafePrinter, _ rune) {
 		t.WriteTimestamp,
 		t.MinTimestamp,
 		t.Sequence)
+	if t.IsoLevel != isolation.Serializable {
+		w.Printf(" iso=%s", t.IsoLevel)
+	}
 }
 
 // FormatBytesAsKey is injected by module roachpb as dependency upon initialization.
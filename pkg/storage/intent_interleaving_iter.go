@@ -40,7 +40,9 @@ const (
 //   - There can be no physically interleaved intents, i.e., all intents are
 //     separated (in the lock table keyspace).
 //   - An intent will have a corresponding provisional value.
-//   - The only single key locks in the lock table key space are intents.
+//   - The only single key locks in the lock table key space that have a
+//     corresponding provisional value are intents. Replicated locks that are
+//     not intents are skipped over by the intentIter and are never exposed.
 //
 // Semantically, the functionality is equivalent to merging two MVCCIterators:
 //   - A MVCCIterator on the MVCC key space.
@@ -150,6 +152,11 @@ type intentInterleavingIter struct {
 	valid bool
 	err   error
 
+	// replicatedLocks holds the replicated locks that are not intents, which
+	// intentIter stepped over during the most recent seek of a prefix
+	// iterator. See MVCCIterator.ReplicatedLocks.
+	replicatedLocks []ReplicatedLock
+
 	// Buffers to reuse memory when constructing lock table keys for bounds and
 	// seeks.
 	intentKeyBuf      []byte
@@ -476,6 +483,8 @@ func (i *intentInterleavingIter) SeekGE(key MVCCKey) {
 	i.valid = true
 	i.err = nil
 
+	i.replicatedLocks = i.replicatedLocks[:0]
+
 	if i.constraint != notConstrained {
 		i.checkConstraint(key.Key, false)
 	}
@@ -510,7 +519,7 @@ func (i *intentInterleavingIter) SeekGE(key MVCCKey) {
 		if i.iterValid && !i.prefix {
 			limitKey = i.makeUpperLimitKey()
 		}
-		iterState, err := i.intentIter.SeekEngineKeyGEWithLimit(EngineKey{Key: intentSeekKey}, limitKey)
+		iterState, err := i.intentIterSeekGEWithLimit(EngineKey{Key: intentSeekKey}, limitKey)
 		if err = i.tryDecodeLockKey(iterState, err); err != nil {
 			return
 		}
@@ -528,6 +537,8 @@ func (i *intentInterleavingIter) SeekIntentGE(key roachpb.Key, txnUUID uuid.UUID
 	i.valid = true
 	i.err = nil
 
+	i.replicatedLocks = i.replicatedLocks[:0]
+
 	if i.constraint != notConstrained {
 		i.checkConstraint(key, false)
 	}
@@ -542,14 +553,14 @@ func (i *intentInterleavingIter) SeekIntentGE(key roachpb.Key, txnUUID uuid.UUID
 	var engineKey EngineKey
 	engineKey, i.intentKeyBuf = LockTableKey{
 		Key:      key,
-		Strength: lock.Exclusive,
+		Strength: lock.Intent,
 		TxnUUID:  txnUUID[:],
 	}.ToEngineKey(i.intentKeyBuf)
 	var limitKey roachpb.Key
 	if i.iterValid && !i.prefix {
 		limitKey = i.makeUpperLimitKey()
 	}
-	iterState, err := i.intentIter.SeekEngineKeyGEWithLimit(engineKey, limitKey)
+	iterState, err := i.intentIterSeekGEWithLimit(engineKey, limitKey)
 	if err = i.tryDecodeLockKey(iterState, err); err != nil {
 		return
 	}
@@ -640,6 +651,92 @@ func (i *intentInterleavingIter) computePos() {
 	}
 }
 
+// intentIterSeekGEWithLimit positions intentIter at the first intent at or
+// after key, skipping over replicated locks that are not intents. A prefix
+// iterator records the locks it skips in replicatedLocks.
+func (i *intentInterleavingIter) intentIterSeekGEWithLimit(
+	key EngineKey, limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	iterState, err := i.intentIter.SeekEngineKeyGEWithLimit(key, limit)
+	return i.skipNonIntentLocks(iterState, err, limit, +1, i.prefix /* record */)
+}
+
+// intentIterNextWithLimit steps intentIter forward to the next intent,
+// skipping over replicated locks that are not intents.
+func (i *intentInterleavingIter) intentIterNextWithLimit(
+	limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	iterState, err := i.intentIter.NextEngineKeyWithLimit(limit)
+	return i.skipNonIntentLocks(iterState, err, limit, +1, false /* record */)
+}
+
+// intentIterSeekLTWithLimit positions intentIter at the last intent before
+// key, skipping over replicated locks that are not intents.
+func (i *intentInterleavingIter) intentIterSeekLTWithLimit(
+	key EngineKey, limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	iterState, err := i.intentIter.SeekEngineKeyLTWithLimit(key, limit)
+	return i.skipNonIntentLocks(iterState, err, limit, -1, false /* record */)
+}
+
+// intentIterPrevWithLimit steps intentIter backward to the previous intent,
+// skipping over replicated locks that are not intents.
+func (i *intentInterleavingIter) intentIterPrevWithLimit(
+	limit roachpb.Key,
+) (pebble.IterValidityState, error) {
+	iterState, err := i.intentIter.PrevEngineKeyWithLimit(limit)
+	return i.skipNonIntentLocks(iterState, err, limit, -1, false /* record */)
+}
+
+// skipNonIntentLocks steps intentIter in the direction dir until it is
+// positioned at an intent or is no longer valid. The lock table key space may
+// contain replicated locks that are not intents, which have no provisional
+// value and are not interleaved. If record is set, the skipped locks are
+// appended to replicatedLocks.
+func (i *intentInterleavingIter) skipNonIntentLocks(
+	iterState pebble.IterValidityState, err error, limit roachpb.Key, dir int, record bool,
+) (pebble.IterValidityState, error) {
+	for err == nil && iterState == pebble.IterValid {
+		engineKey, keyErr := i.intentIter.UnsafeEngineKey()
+		if keyErr != nil {
+			return iterState, keyErr
+		}
+		if engineKey.IsLockTableIntentKey() {
+			break
+		}
+		if record {
+			if err := i.recordReplicatedLock(engineKey); err != nil {
+				return iterState, err
+			}
+		}
+		if dir > 0 {
+			iterState, err = i.intentIter.NextEngineKeyWithLimit(limit)
+		} else {
+			iterState, err = i.intentIter.PrevEngineKeyWithLimit(limit)
+		}
+	}
+	return iterState, err
+}
+
+// recordReplicatedLock appends the replicated lock at the intentIter's
+// position, which has the provided key, to replicatedLocks.
+func (i *intentInterleavingIter) recordReplicatedLock(engineKey EngineKey) error {
+	ltKey, err := engineKey.ToLockTableKey()
+	if err != nil {
+		return err
+	}
+	v, err := i.intentIter.UnsafeValue()
+	if err != nil {
+		return err
+	}
+	l := ReplicatedLock{Key: ltKey.Key.Clone(), Strength: ltKey.Strength}
+	if err := protoutil.Unmarshal(v, &l.Meta); err != nil {
+		return err
+	}
+	i.replicatedLocks = append(i.replicatedLocks, l)
+	return nil
+}
+
 func (i *intentInterleavingIter) tryDecodeLockKey(
 	iterState pebble.IterValidityState, err error,
 ) error {
@@ -730,7 +827,7 @@ func (i *intentInterleavingIter) Next() {
 			if i.iterValid {
 				limitKey = i.makeUpperLimitKey()
 			}
-			iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterNextWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -778,7 +875,7 @@ func (i *intentInterleavingIter) Next() {
 			// this key has an intent, or an earlier key. Either way, stepping
 			// forward will take it to an intent for a later key.
 			limitKey := i.makeUpperLimitKey()
-			iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterNextWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -810,7 +907,7 @@ func (i *intentInterleavingIter) Next() {
 		if !i.prefix {
 			limitKey = i.makeUpperLimitKey()
 		}
-		iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+		iterState, err := i.intentIterNextWithLimit(limitKey)
 		if err = i.tryDecodeLockKey(iterState, err); err != nil {
 			return
 		}
@@ -829,7 +926,7 @@ func (i *intentInterleavingIter) Next() {
 			// TODO(sumeer): could avoid doing this if i.iter has stepped to
 			// different version of same key.
 			limitKey := i.makeUpperLimitKey()
-			iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterNextWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -875,7 +972,7 @@ func (i *intentInterleavingIter) NextKey() {
 		if i.iterValid && !i.prefix {
 			limitKey = i.makeUpperLimitKey()
 		}
-		iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+		iterState, err := i.intentIterNextWithLimit(limitKey)
 		if err := i.tryDecodeLockKey(iterState, err); err != nil {
 			return
 		}
@@ -895,7 +992,7 @@ func (i *intentInterleavingIter) NextKey() {
 	i.rangeKeyChanged = i.iter.RangeKeyChanged()
 	if i.intentIterState == pebble.IterAtLimit && i.iterValid && !i.prefix {
 		limitKey := i.makeUpperLimitKey()
-		iterState, err := i.intentIter.NextEngineKeyWithLimit(limitKey)
+		iterState, err := i.intentIterNextWithLimit(limitKey)
 		if err = i.tryDecodeLockKey(iterState, err); err != nil {
 			return
 		}
@@ -1101,7 +1198,7 @@ func (i *intentInterleavingIter) SeekLT(key MVCCKey) {
 	if i.iterValid {
 		limitKey = i.makeLowerLimitKey()
 	}
-	iterState, err := i.intentIter.SeekEngineKeyLTWithLimit(EngineKey{Key: intentSeekKey}, limitKey)
+	iterState, err := i.intentIterSeekLTWithLimit(EngineKey{Key: intentSeekKey}, limitKey)
 	if err = i.tryDecodeLockKey(iterState, err); err != nil {
 		return
 	}
@@ -1133,7 +1230,7 @@ func (i *intentInterleavingIter) Prev() {
 			if i.iterValid {
 				limitKey = i.makeLowerLimitKey()
 			}
-			iterState, err := i.intentIter.PrevEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterPrevWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -1175,7 +1272,7 @@ func (i *intentInterleavingIter) Prev() {
 			// has an intent. Note that the iter could itself be positioned at an
 			// intent.
 			limitKey := i.makeLowerLimitKey()
-			iterState, err := i.intentIter.PrevEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterPrevWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -1220,7 +1317,7 @@ func (i *intentInterleavingIter) Prev() {
 		if i.iterValid {
 			limitKey = i.makeLowerLimitKey()
 		}
-		intentIterState, err := i.intentIter.PrevEngineKeyWithLimit(limitKey)
+		intentIterState, err := i.intentIterPrevWithLimit(limitKey)
 		if err = i.tryDecodeLockKey(intentIterState, err); err != nil {
 			return
 		}
@@ -1257,7 +1354,7 @@ func (i *intentInterleavingIter) Prev() {
 			// TODO(sumeer): could avoid doing this if i.iter has stepped to
 			// different version of same key.
 			limitKey := i.makeLowerLimitKey()
-			iterState, err := i.intentIter.PrevEngineKeyWithLimit(limitKey)
+			iterState, err := i.intentIterPrevWithLimit(limitKey)
 			if err = i.tryDecodeLockKey(iterState, err); err != nil {
 				return
 			}
@@ -1326,6 +1423,11 @@ func (i *intentInterleavingIter) IsPrefix() bool {
 	return i.prefix
 }
 
+// ReplicatedLocks implements the MVCCIterator interface.
+func (i *intentInterleavingIter) ReplicatedLocks() []ReplicatedLock {
+	return i.replicatedLocks
+}
+
 // assertInvariants asserts internal iterator invariants, returning an
 // AssertionFailedf for any violations. It must be called on a valid iterator
 // after a complete state transition.
@@ -42,7 +42,7 @@ func (idw intentDemuxWriter) ClearIntent(
 	var engineKey EngineKey
 	engineKey, buf = LockTableKey{
 		Key:      key,
-		Strength: lock.Exclusive,
+		Strength: lock.Intent,
 		TxnUUID:  txnUUID[:],
 	}.ToEngineKey(buf)
 	if txnDidNotUpdateMeta {
@@ -60,7 +60,7 @@ func (idw intentDemuxWriter) PutIntent(
 	var engineKey EngineKey
 	engineKey, buf = LockTableKey{
 		Key:      key,
-		Strength: lock.Exclusive,
+		Strength: lock.Intent,
 		TxnUUID:  txnUUID[:],
 	}.ToEngineKey(buf)
 	return buf, idw.w.PutEngineKey(engineKey, value)
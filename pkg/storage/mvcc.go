@@ -1785,6 +1785,19 @@ func mvccPutInternal(
 		return false, err
 	}
 
+	// Versioned writes conflict with replicated locks held by other
+	// transactions. The iterator steps over these locks while looking for an
+	// intent on the key, so they are checked without another seek of the lock
+	// table. The iterator does not look for an intent if the key has no MVCC
+	// version, but such a key cannot be locked by another transaction: locks
+	// are only acquired on existing keys, and the key cannot be removed while
+	// the lock is held.
+	if iter != nil && !timestamp.IsEmpty() {
+		if err := checkReplicatedLockConflicts(iter.ReplicatedLocks(), txn); err != nil {
+			return false, err
+		}
+	}
+
 	// Verify we're not mixing inline and non-inline values.
 	putIsInline := timestamp.IsEmpty()
 	if ok && putIsInline != buf.meta.IsInline() {
@@ -3014,7 +3027,6 @@ func MVCCDeleteRange(
 	if err != nil {
 		return nil, nil, 0, err
 	}
-
 	buf := newPutBuffer()
 	defer buf.release()
 	iter := newMVCCIterator(
@@ -4236,6 +4248,13 @@ func MVCCResolveWriteIntent(
 	// the database.
 	beforeBytes := rw.BufferedSize()
 	ok, err = mvccResolveWriteIntent(ctx, rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
+	if err == nil {
+		// The transaction may also hold replicated locks that are not intents on
+		// the key, which are resolved alongside the intent.
+		var lockOK bool
+		lockOK, err = mvccResolveReplicatedLocks(rw, ms, intent)
+		ok = ok || lockOK
+	}
 	numBytes = int64(rw.BufferedSize() - beforeBytes)
 	// Using defer would be more convenient, but it is measurably slower.
 	iterAndBuf.Cleanup()
@@ -4287,6 +4306,9 @@ type separatedIntentAndVersionIter struct {
 	engineIterValid bool
 	engineIterErr   error
 	intentKey       roachpb.Key
+	// The strength of the lock at the engineIter's position. Intents have the
+	// lock.Intent strength, while other replicated locks do not.
+	strength lock.Strength
 }
 
 var _ iterForKeyVersions = &separatedIntentAndVersionIter{}
@@ -4313,11 +4335,13 @@ func (s *separatedIntentAndVersionIter) initIntentKey() {
 			s.engineIterValid = false
 			return
 		}
-		if s.intentKey, err = keys.DecodeLockTableSingleKey(engineKey.Key); err != nil {
+		ltKey, err := engineKey.ToLockTableKey()
+		if err != nil {
 			s.engineIterErr = err
 			s.engineIterValid = false
 			return
 		}
+		s.intentKey, s.strength = ltKey.Key, ltKey.Strength
 	}
 }
 
@@ -5105,7 +5129,13 @@ func MVCCResolveWriteIntentRange(
 		lastResolvedKey = append(lastResolvedKey[:0], sepIter.UnsafeKey().Key...)
 		intent.Key = lastResolvedKey
 		beforeBytes := rw.BufferedSize()
-		ok, err := mvccResolveWriteIntent(ctx, rw, sepIter, ms, intent, putBuf)
+		var ok bool
+		var err error
+		if sepIter.strength == lock.Intent {
+			ok, err = mvccResolveWriteIntent(ctx, rw, sepIter, ms, intent, putBuf)
+		} else {
+			ok, err = mvccResolveReplicatedLock(rw, ms, intent, sepIter.strength, meta)
+		}
 		if err != nil {
 			log.Warningf(ctx, "failed to resolve intent for key %q: %+v", lastResolvedKey, err)
 		} else if ok {
@@ -5117,6 +5147,272 @@ func MVCCResolveWriteIntentRange(
 	return numKeys, numBytes, nil, 0, nil
 }
 
+// MVCCAcquireLock attempts to acquire a lock with the specified strength and
+// Replicated durability on the provided key, on behalf of the provided
+// transaction. The lock is stored in the lock table key space, like an intent,
+// but unlike an intent it is not accompanied by a provisional value, so it is
+// ignored by non-locking reads. The lock is released when it is resolved
+// through MVCCResolveWriteIntent or MVCCResolveWriteIntentRange.
+//
+// If the key is locked by a different transaction, a WriteIntentError listing
+// up to maxConflicts of the conflicting locks is returned. If the transaction
+// already holds a lock or an intent on the key, the call is a no-op. The lock
+// is accounted for in the LockBytes and LockCount of ms, if provided.
+//
+// Only the Exclusive strength is currently supported.
+func MVCCAcquireLock(
+	ctx context.Context,
+	rw ReadWriter,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	key roachpb.Key,
+	ms *enginepb.MVCCStats,
+	maxConflicts int64,
+) error {
+	if txn == nil {
+		return errors.AssertionFailedf("replicated locks can only be acquired by transactions")
+	}
+	if str != lock.Exclusive {
+		return errors.AssertionFailedf("unsupported replicated lock strength %s", str)
+	}
+	held, err := mvccCheckForReplicatedLockConflicts(
+		ctx, rw, txn, roachpb.Span{Key: key}, maxConflicts)
+	if err != nil || held {
+		return err
+	}
+	meta := enginepb.MVCCMetadata{
+		Txn:       &txn.TxnMeta,
+		Timestamp: txn.WriteTimestamp.ToLegacyTimestamp(),
+	}
+	metaBytes, err := protoutil.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+	ltKey, _ := LockTableKey{
+		Key:      key,
+		Strength: str,
+		TxnUUID:  txn.ID.GetBytes(),
+	}.ToEngineKey(nil)
+	if ms != nil {
+		ms.LockBytes += int64(ltKey.EncodedLen() + len(metaBytes))
+		ms.LockCount++
+	}
+	return rw.PutEngineKey(ltKey, metaBytes)
+}
+
+// MVCCCheckForReplicatedLockConflicts scans the lock table over the provided
+// span, or over the span's key if its EndKey is empty, for replicated locks
+// that are not intents and are held by transactions other than txn. If any are
+// found, a WriteIntentError listing up to maxConflicts of them is returned.
+//
+// Writes and locking reads conflict with these locks, which are only held with
+// Exclusive strength, while non-locking reads do not. Conflicting intents are
+// not considered here, since they are discovered when reading the MVCC key
+// space. txn may be nil for non-transactional requests.
+func MVCCCheckForReplicatedLockConflicts(
+	ctx context.Context,
+	reader Reader,
+	txn *roachpb.Transaction,
+	span roachpb.Span,
+	maxConflicts int64,
+) error {
+	_, err := mvccCheckForReplicatedLockConflicts(ctx, reader, txn, span, maxConflicts)
+	return err
+}
+
+// mvccCheckForReplicatedLockConflicts implements
+// MVCCCheckForReplicatedLockConflicts. It additionally returns whether txn
+// already holds a lock or an intent in the span that was acquired in its
+// current epoch and has not been rolled back.
+func mvccCheckForReplicatedLockConflicts(
+	ctx context.Context,
+	reader Reader,
+	txn *roachpb.Transaction,
+	span roachpb.Span,
+	maxConflicts int64,
+) (held bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	ltStart, _ := keys.LockTableSingleKey(span.Key, nil)
+	opts := IterOptions{LowerBound: ltStart}
+	if len(span.EndKey) == 0 {
+		opts.Prefix = true
+	} else {
+		opts.UpperBound, _ = keys.LockTableSingleKey(span.EndKey, nil)
+	}
+	iter := reader.NewEngineIterator(opts)
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	var conflicts []roachpb.Intent
+	var ok bool
+	for ok, err = iter.SeekEngineKeyGE(EngineKey{Key: ltStart}); ok; ok, err = iter.NextEngineKey() {
+		if maxConflicts != 0 && int64(len(conflicts)) >= maxConflicts {
+			break
+		}
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return false, err
+		}
+		ltKey, err := engineKey.ToLockTableKey()
+		if err != nil {
+			return false, err
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return false, err
+		}
+		if err := protoutil.Unmarshal(v, &meta); err != nil {
+			return false, err
+		}
+		if meta.Txn == nil {
+			return false, errors.AssertionFailedf("lock without transaction at %s", ltKey.Key)
+		}
+		if txn != nil && meta.Txn.ID == txn.ID {
+			if meta.Txn.Epoch == txn.Epoch &&
+				!enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, txn.IgnoredSeqNums) {
+				held = true
+			}
+			continue
+		}
+		if ltKey.Strength == lock.Intent {
+			continue
+		}
+		conflicts = append(conflicts, roachpb.MakeIntent(meta.Txn, ltKey.Key.Clone()))
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(conflicts) > 0 {
+		return false, &kvpb.WriteIntentError{Intents: conflicts}
+	}
+	return held, nil
+}
+
+// checkReplicatedLockConflicts returns a WriteIntentError listing those of
+// the provided replicated locks that are held by transactions other than txn,
+// if any. txn may be nil for non-transactional requests.
+func checkReplicatedLockConflicts(locks []ReplicatedLock, txn *roachpb.Transaction) error {
+	var conflicts []roachpb.Intent
+	for i := range locks {
+		l := &locks[i]
+		if l.Meta.Txn == nil {
+			return errors.AssertionFailedf("lock without transaction at %s", l.Key)
+		}
+		if txn != nil && l.Meta.Txn.ID == txn.ID {
+			continue
+		}
+		conflicts = append(conflicts, roachpb.MakeIntent(l.Meta.Txn, l.Key))
+	}
+	if len(conflicts) > 0 {
+		return &kvpb.WriteIntentError{Intents: conflicts}
+	}
+	return nil
+}
+
+// mvccResolveReplicatedLocks resolves the replicated locks that are not
+// intents held on the update's key by the update's transaction. Returns
+// whether any lock was released.
+func mvccResolveReplicatedLocks(
+	rw ReadWriter, ms *enginepb.MVCCStats, update roachpb.LockUpdate,
+) (released bool, _ error) {
+	ltStart, _ := keys.LockTableSingleKey(update.Key, nil)
+	iter := rw.NewEngineIterator(IterOptions{Prefix: true, LowerBound: ltStart})
+	defer iter.Close()
+
+	type heldLock struct {
+		str  lock.Strength
+		meta enginepb.MVCCMetadata
+	}
+	// Collect the locks before resolving them, to avoid mutating the lock
+	// table while iterating over it.
+	var locks []heldLock
+	var ok bool
+	var err error
+	for ok, err = iter.SeekEngineKeyGE(EngineKey{Key: ltStart}); ok; ok, err = iter.NextEngineKey() {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return false, err
+		}
+		ltKey, err := engineKey.ToLockTableKey()
+		if err != nil {
+			return false, err
+		}
+		if ltKey.Strength == lock.Intent || !bytes.Equal(ltKey.TxnUUID, update.Txn.ID.GetBytes()) {
+			continue
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return false, err
+		}
+		l := heldLock{str: ltKey.Strength}
+		if err := protoutil.Unmarshal(v, &l.meta); err != nil {
+			return false, err
+		}
+		locks = append(locks, l)
+	}
+	if err != nil {
+		return false, err
+	}
+	for i := range locks {
+		ok, err := mvccResolveReplicatedLock(rw, ms, update, locks[i].str, &locks[i].meta)
+		if err != nil {
+			return false, err
+		}
+		released = released || ok
+	}
+	return released, nil
+}
+
+// mvccResolveReplicatedLock resolves a replicated lock that is not an intent,
+// held on the update's key with the provided strength and metadata. The lock
+// is released if the update's transaction is finalized, if it has moved on to
+// a later epoch, or if the sequence number the lock was acquired at has been
+// rolled back. Otherwise, the lock's timestamp is forwarded to the update's
+// write timestamp. Returns whether the lock was released.
+func mvccResolveReplicatedLock(
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	update roachpb.LockUpdate,
+	str lock.Strength,
+	meta *enginepb.MVCCMetadata,
+) (released bool, _ error) {
+	if meta.Txn == nil || meta.Txn.ID != update.Txn.ID {
+		return false, nil
+	}
+	ltKey, _ := LockTableKey{
+		Key:      update.Key,
+		Strength: str,
+		TxnUUID:  update.Txn.ID.GetBytes(),
+	}.ToEngineKey(nil)
+	release := update.Status.IsFinalized() ||
+		meta.Txn.Epoch < update.Txn.Epoch ||
+		(meta.Txn.Epoch == update.Txn.Epoch &&
+			enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, update.IgnoredSeqNums))
+	origMetaSize := meta.Size()
+	if release {
+		if ms != nil {
+			ms.LockBytes -= int64(ltKey.EncodedLen() + origMetaSize)
+			ms.LockCount--
+		}
+		return true, rw.ClearEngineKey(ltKey)
+	}
+	if metaTS := meta.Timestamp.ToTimestamp(); !metaTS.Less(update.Txn.WriteTimestamp) {
+		return false, nil
+	}
+	// The transaction was pushed. Forward the lock's timestamp.
+	meta.Timestamp = update.Txn.WriteTimestamp.ToLegacyTimestamp()
+	metaBytes, err := protoutil.Marshal(meta)
+	if err != nil {
+		return false, err
+	}
+	if ms != nil {
+		ms.LockBytes += int64(len(metaBytes) - origMetaSize)
+	}
+	return false, rw.PutEngineKey(ltKey, metaBytes)
+}
+
 // MVCCGarbageCollect creates an iterator on the ReadWriter. In parallel
 // it iterates through the keys listed for garbage collection by the
 // keys slice. The iterator is seeked in turn to each listed
@@ -5951,7 +6247,48 @@ func ComputeStatsWithVisitors(
 	})
 	defer iter.Close()
 	iter.SeekGE(MVCCKey{Key: start})
-	return computeStatsForIterWithVisitors(iter, nowNanos, pointKeyVisitor, rangeKeyVisitor)
+	ms, err := computeStatsForIterWithVisitors(iter, nowNanos, pointKeyVisitor, rangeKeyVisitor)
+	if err != nil {
+		return ms, err
+	}
+	if err := computeReplicatedLockStats(r, start, end, &ms); err != nil {
+		return enginepb.MVCCStats{}, err
+	}
+	return ms, nil
+}
+
+// computeReplicatedLockStats adds the replicated locks that are not intents in
+// the lock table key space corresponding to the provided span to the LockBytes
+// and LockCount of ms. Intents are accounted for when iterating over the MVCC
+// key space instead.
+func computeReplicatedLockStats(r Reader, start, end roachpb.Key, ms *enginepb.MVCCStats) error {
+	ltStart, _ := keys.LockTableSingleKey(start, nil)
+	ltEnd, _ := keys.LockTableSingleKey(end, nil)
+	iter := r.NewEngineIterator(IterOptions{LowerBound: ltStart, UpperBound: ltEnd})
+	defer iter.Close()
+
+	var ok bool
+	var err error
+	for ok, err = iter.SeekEngineKeyGE(EngineKey{Key: ltStart}); ok; ok, err = iter.NextEngineKey() {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return err
+		}
+		ltKey, err := engineKey.ToLockTableKey()
+		if err != nil {
+			return err
+		}
+		if ltKey.Strength == lock.Intent {
+			continue
+		}
+		v, err := iter.UnsafeValue()
+		if err != nil {
+			return err
+		}
+		ms.LockBytes += int64(engineKey.EncodedLen() + len(v))
+		ms.LockCount++
+	}
+	return err
 }
 
 // ComputeStatsForIter is like ComputeStats, but scans across the given iterator
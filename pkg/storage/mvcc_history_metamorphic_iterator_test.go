@@ -405,6 +405,10 @@ func (m *metamorphicMVCCIterator) IsPrefix() bool {
 	return m.it.(storage.MVCCIterator).IsPrefix()
 }
 
+func (m *metamorphicMVCCIterator) ReplicatedLocks() []storage.ReplicatedLock {
+	return m.it.(storage.MVCCIterator).ReplicatedLocks()
+}
+
 type metamorphicMVCCIncrementalIterator struct {
 	*metamorphicIterator
 }
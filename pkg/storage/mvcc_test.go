@@ -26,6 +26,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -124,6 +125,8 @@ func TestMVCCStatsAddSubForward(t *testing.T) {
 		RangeKeyBytes:        1,
 		RangeValCount:        1,
 		RangeValBytes:        1,
+		LockBytes:            1,
+		LockCount:            1,
 		SeparatedIntentCount: 1,
 		IntentAge:            1,
 		GCBytesAge:           1,
@@ -3573,6 +3576,136 @@ func TestMVCCResolveWithPushedTimestamp(t *testing.T) {
 	}
 }
 
+// TestMVCCAcquireReplicatedLock verifies that replicated locks acquired with
+// MVCCAcquireLock conflict with writes and locking reads by other
+// transactions, are ignored by non-locking reads, and are released by intent
+// resolution.
+func TestMVCCAcquireReplicatedLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	engine := NewDefaultInMemForTesting()
+	defer engine.Close()
+
+	require.NoError(t, MVCCPut(ctx, engine, nil, testKey1, hlc.Timestamp{Logical: 1}, hlc.ClockTimestamp{}, value1, nil))
+	require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, hlc.Timestamp{Logical: 1}, hlc.ClockTimestamp{}, value2, nil))
+
+	requireConflict := func(err error, key roachpb.Key) {
+		t.Helper()
+		var wiErr *kvpb.WriteIntentError
+		require.True(t, errors.As(err, &wiErr), "expected WriteIntentError, found %v", err)
+		require.Len(t, wiErr.Intents, 1)
+		require.Equal(t, txn1ID, wiErr.Intents[0].Txn.ID)
+		require.Equal(t, key, wiErr.Intents[0].Key)
+	}
+
+	// Acquire locks on both keys. Re-acquiring a held lock is a no-op.
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1, nil, 0))
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1, nil, 0))
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey2, nil, 0))
+	require.Error(t, MVCCAcquireLock(ctx, engine, txn1, lock.Shared, testKey3, nil, 0))
+
+	// Other transactions conflict with the locks when locking or writing.
+	requireConflict(MVCCAcquireLock(ctx, engine, txn2, lock.Exclusive, testKey1, nil, 0), testKey1)
+	requireConflict(MVCCPut(ctx, engine, nil, testKey1, txn2.ReadTimestamp, hlc.ClockTimestamp{}, value3, txn2), testKey1)
+	_, err := MVCCDelete(ctx, engine, nil, testKey2, txn2.ReadTimestamp, hlc.ClockTimestamp{}, txn2)
+	requireConflict(err, testKey2)
+	_, _, _, err = MVCCDeleteRange(ctx, engine, nil, testKey1, testKey3, 0, txn2.ReadTimestamp,
+		hlc.ClockTimestamp{}, txn2, false /* returnKeys */)
+	requireConflict(err, testKey1)
+	requireConflict(MVCCPut(ctx, engine, nil, testKey2, txn2.ReadTimestamp, hlc.ClockTimestamp{}, value3, nil), testKey2)
+	requireConflict(MVCCCheckForReplicatedLockConflicts(
+		ctx, engine, txn2, roachpb.Span{Key: testKey1, EndKey: testKey3}, 1 /* maxConflicts */), testKey1)
+
+	// The lock holder does not conflict with its own locks.
+	require.NoError(t, MVCCCheckForReplicatedLockConflicts(
+		ctx, engine, txn1, roachpb.Span{Key: testKey1, EndKey: testKey3}, 0))
+
+	// Non-locking reads ignore the locks.
+	valueRes, err := MVCCGet(ctx, engine, testKey1, txn2.ReadTimestamp, MVCCGetOptions{Txn: txn2})
+	require.NoError(t, err)
+	require.Nil(t, valueRes.Intent)
+	require.Equal(t, value1.RawBytes, valueRes.Value.RawBytes)
+	scanRes, err := MVCCScan(ctx, engine, testKey1, testKey3, txn2.ReadTimestamp, MVCCScanOptions{Txn: txn2})
+	require.NoError(t, err)
+	require.Len(t, scanRes.KVs, 2)
+	require.Empty(t, scanRes.Intents)
+
+	// Resolving the point lock releases it.
+	ok, _, _, err := MVCCResolveWriteIntent(ctx, engine, nil,
+		roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1}), MVCCResolveWriteIntentOptions{})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, MVCCPut(ctx, engine, nil, testKey1, txn2.ReadTimestamp, hlc.ClockTimestamp{}, value3, txn2))
+	requireConflict(MVCCAcquireLock(ctx, engine, txn2, lock.Exclusive, testKey2, nil, 0), testKey2)
+
+	// Resolving the range releases the remaining lock.
+	numKeys, _, _, _, err := MVCCResolveWriteIntentRange(ctx, engine, nil,
+		roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1, EndKey: testKey3}),
+		MVCCResolveWriteIntentRangeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), numKeys)
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn2, lock.Exclusive, testKey2, nil, 0))
+}
+
+// TestMVCCReplicatedLockStats verifies that replicated locks are accounted for
+// in the LockBytes and LockCount of the MVCCStats when they are acquired,
+// forwarded and released, and that the stats match ComputeStats.
+func TestMVCCReplicatedLockStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	engine := NewDefaultInMemForTesting()
+	defer engine.Close()
+
+	var ms enginepb.MVCCStats
+	requireStats := func(expLocks int64) {
+		t.Helper()
+		computed, err := ComputeStats(engine, keys.LocalMax, keys.MaxKey, 0 /* nowNanos */)
+		require.NoError(t, err)
+		require.Equal(t, expLocks, ms.LockCount)
+		require.Equal(t, computed.LockCount, ms.LockCount)
+		require.Equal(t, computed.LockBytes, ms.LockBytes)
+		if expLocks == 0 {
+			require.Zero(t, ms.LockBytes)
+		}
+		// Replicated locks count towards the range size, but are not garbage.
+		require.Equal(t, ms.KeyBytes+ms.ValBytes+ms.LockBytes, ms.Total())
+		require.Equal(t, ms.KeyBytes+ms.ValBytes-ms.LiveBytes, ms.GCBytes())
+	}
+
+	require.NoError(t, MVCCPut(ctx, engine, &ms, testKey1, hlc.Timestamp{Logical: 1}, hlc.ClockTimestamp{}, value1, nil))
+	require.NoError(t, MVCCPut(ctx, engine, &ms, testKey2, hlc.Timestamp{Logical: 1}, hlc.ClockTimestamp{}, value2, nil))
+	requireStats(0)
+
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1, &ms, 0))
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey1, &ms, 0))
+	require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, lock.Exclusive, testKey2, &ms, 0))
+	requireStats(2)
+
+	// Pushing the transaction forwards the timestamp of the lock, which
+	// changes the size of its value.
+	pushed := txn1.Clone()
+	pushed.WriteTimestamp.Forward(hlc.Timestamp{WallTime: 1e9})
+	_, _, _, err := MVCCResolveWriteIntent(ctx, engine, &ms,
+		roachpb.MakeLockUpdate(pushed, roachpb.Span{Key: testKey1}), MVCCResolveWriteIntentOptions{})
+	require.NoError(t, err)
+	requireStats(2)
+
+	_, _, _, err = MVCCResolveWriteIntent(ctx, engine, &ms,
+		roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1}), MVCCResolveWriteIntentOptions{})
+	require.NoError(t, err)
+	requireStats(1)
+
+	_, _, _, _, err = MVCCResolveWriteIntentRange(ctx, engine, &ms,
+		roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1, EndKey: testKey3}),
+		MVCCResolveWriteIntentRangeOptions{})
+	require.NoError(t, err)
+	requireStats(0)
+}
+
 func TestMVCCResolveTxnNoOps(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
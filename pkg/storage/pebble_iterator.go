@@ -887,6 +887,11 @@ func (p *pebbleIterator) IsPrefix() bool {
 	return p.prefix
 }
 
+// ReplicatedLocks implements the MVCCIterator interface.
+func (p *pebbleIterator) ReplicatedLocks() []ReplicatedLock {
+	return nil
+}
+
 // GetRawIter is part of the EngineIterator interface.
 func (p *pebbleIterator) GetRawIter() pebbleiter.Iterator {
 	return p.iter
@@ -609,6 +609,12 @@ var charts = []sectionDescription{
 					"kv.rangefeed.registrations",
 				},
 			},
+			{
+				Title: "Rangefeed Filtered Events",
+				Metrics: []string{
+					"kv.rangefeed.filtered_events",
+				},
+			},
 			{
 				Title: "Rangefeed Memory Allocations",
 				Metrics: []string{
@@ -282,6 +282,12 @@ var upgrades = []upgradebase.Upgrade{
 		upgrade.NoPrecondition,
 		backfillJobInfoTable,
 	),
+	upgrade.NewTenantUpgrade(
+		"create system.notifications table",
+		toCV(clusterversion.V23_1NotificationsTable),
+		upgrade.NoPrecondition,
+		systemNotificationsTableMigration,
+	),
 }
 
 func init() {
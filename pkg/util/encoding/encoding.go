@@ -104,6 +104,15 @@ const (
 	emptyArray = geoInvertedIndexMarker + 1
 	voidMarker = emptyArray + 1
 
+	// Markers for key encoding range and multirange Datums in sorted order.
+	// Like for arrays, there are separate markers for the ascending and
+	// descending cases, so that the encoding can be decoded without knowing its
+	// direction.
+	rangeKeyMarker                = voidMarker + 1
+	rangeKeyDescendingMarker      = rangeKeyMarker + 1
+	multirangeKeyMarker           = rangeKeyDescendingMarker + 1
+	multirangeKeyDescendingMarker = multirangeKeyMarker + 1
+
 	arrayKeyTerminator           byte = 0x00
 	arrayKeyDescendingTerminator byte = 0xFF
 	// We use different null encodings for nulls within key arrays. Doing this
@@ -114,6 +123,27 @@ const (
 	ascendingNullWithinArrayKey  byte = 0x01
 	descendingNullWithinArrayKey byte = 0xFE
 
+	// Flags for the bounds of key encoded ranges. The flags are inverted when
+	// the range is encoded descendingly. They are chosen so that the empty range
+	// sorts first, followed by the other ranges ordered by lower bound and then
+	// by upper bound, where an infinite lower bound sorts first and an infinite
+	// upper bound sorts last. For equal bound values, an inclusive lower bound
+	// sorts before an exclusive one, and an inclusive upper bound sorts after an
+	// exclusive one.
+	rangeKeyEmpty          byte = 0x00
+	rangeKeyLowerInfinite  byte = 0x01
+	rangeKeyLowerFinite    byte = 0x02
+	rangeKeyLowerInclusive byte = 0x00
+	rangeKeyLowerExclusive byte = 0x01
+	rangeKeyUpperFinite    byte = 0x00
+	rangeKeyUpperInfinite  byte = 0x01
+	rangeKeyUpperExclusive byte = 0x00
+	rangeKeyUpperInclusive byte = 0x01
+	// The ranges of a key encoded multirange are never empty, so their first
+	// byte is never the terminator.
+	multirangeKeyTerminator           byte = 0x00
+	multirangeKeyDescendingTerminator byte = 0xFF
+
 	// IntMin is chosen such that the range of int tags does not overlap the
 	// ascii character set that is frequently used in testing.
 	IntMin      = 0x80 // 128
@@ -1712,6 +1742,14 @@ const (
 	Void         Type = 25
 	TSQuery      Type = 26
 	TSVector     Type = 27
+	// RangeKeyAsc and the following types are only used for ranges and
+	// multiranges.
+	RangeKeyAsc       Type = 28 // Range key encoding
+	RangeKeyDesc      Type = 29 // Range key encoded descendingly
+	MultirangeKeyAsc  Type = 30 // Multirange key encoding
+	MultirangeKeyDesc Type = 31 // Multirange key encoded descendingly
+	Range             Type = 32
+	Multirange        Type = 33
 )
 
 // typMap maps an encoded type byte to a decoded Type. It's got 256 slots, one
@@ -1748,6 +1786,14 @@ func slowPeekType(b []byte) Type {
 			return ArrayKeyAsc
 		case m == arrayKeyDescendingMarker:
 			return ArrayKeyDesc
+		case m == rangeKeyMarker:
+			return RangeKeyAsc
+		case m == rangeKeyDescendingMarker:
+			return RangeKeyDesc
+		case m == multirangeKeyMarker:
+			return MultirangeKeyAsc
+		case m == multirangeKeyDescendingMarker:
+			return MultirangeKeyDesc
 		case m == bytesMarker:
 			return Bytes
 		case m == bytesDescMarker:
@@ -1838,6 +1884,62 @@ func getArrayLength(buf []byte, dir Direction) (int, error) {
 	return result, nil
 }
 
+// getRangeLength returns the length of a key encoded range. The input must
+// have had the range type marker stripped from the front.
+func getRangeLength(buf []byte, dir Direction) (int, error) {
+	result := 0
+	for _, lower := range []bool{true, false} {
+		rest, empty, infinite, err := DecodeRangeKeyBoundPrefix(buf, lower, dir)
+		if err != nil {
+			return 0, err
+		}
+		result += len(buf) - len(rest)
+		buf = rest
+		if empty {
+			break
+		}
+		if infinite {
+			continue
+		}
+		next, err := PeekLength(buf)
+		if err != nil {
+			return 0, err
+		}
+		buf = buf[next:]
+		result += next
+		rest, _, err = DecodeRangeKeyBoundSuffix(buf, lower, dir)
+		if err != nil {
+			return 0, err
+		}
+		result += len(buf) - len(rest)
+		buf = rest
+	}
+	return result, nil
+}
+
+// getMultirangeLength returns the length of a key encoded multirange. The
+// input must have had the multirange type marker stripped from the front.
+func getMultirangeLength(buf []byte, dir Direction) (int, error) {
+	result := 0
+	for {
+		if len(buf) == 0 {
+			return 0, errors.AssertionFailedf("invalid multirange encoding (unterminated)")
+		}
+		if IsMultirangeKeyDone(buf, dir) {
+			// Increment to include the terminator byte.
+			result++
+			break
+		}
+		next, err := getRangeLength(buf, dir)
+		if err != nil {
+			return 0, err
+		}
+		buf = buf[next:]
+		result += next
+	}
+	return result, nil
+}
+
 // peekBox2DLength peeks to look at the length of a box2d encoding.
 func peekBox2DLength(b []byte) (int, error) {
 	length := 0
@@ -1898,6 +2000,20 @@ func PeekLength(b []byte) (int, error) {
 		}
 		length, err := getArrayLength(b[1:], dir)
 		return 1 + length, err
+	case rangeKeyMarker, rangeKeyDescendingMarker:
+		dir := Ascending
+		if m == rangeKeyDescendingMarker {
+			dir = Descending
+		}
+		length, err := getRangeLength(b[1:], dir)
+		return 1 + length, err
+	case multirangeKeyMarker, multirangeKeyDescendingMarker:
+		dir := Ascending
+		if m == multirangeKeyDescendingMarker {
+			dir = Descending
+		}
+		length, err := getMultirangeLength(b[1:], dir)
+		return 1 + length, err
 	case bytesMarker:
 		return getBytesLength(b, ascendingBytesEscapes)
 	case box2DMarker:
@@ -2081,6 +2197,48 @@ func prettyPrintValueImpl(
 	return allDecoded
 }
 
+// prettyPrintRangeKey writes a string representation of the key encoded range
+// at the start of buf, which must have had its marker stripped, to build. It
+// returns the remainder of buf.
+func prettyPrintRangeKey(
+	dir, encDir Direction, buf []byte, build *strings.Builder,
+) ([]byte, error) {
+	for _, lower := range []bool{true, false} {
+		var empty, infinite, inclusive bool
+		var err error
+		buf, empty, infinite, err = DecodeRangeKeyBoundPrefix(buf, lower, encDir)
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			build.WriteString("empty")
+			return buf, nil
+		}
+		var val string
+		if !infinite {
+			buf, val, err = prettyPrintFirstValue(dir, buf)
+			if err != nil {
+				return nil, err
+			}
+			buf, inclusive, err = DecodeRangeKeyBoundSuffix(buf, lower, encDir)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case lower && inclusive:
+			build.WriteString("[" + val + ",")
+		case lower:
+			build.WriteString("(" + val + ",")
+		case inclusive:
+			build.WriteString(val + "]")
+		default:
+			build.WriteString(val + ")")
+		}
+	}
+	return buf, nil
+}
+
 // prettyPrintFirstValue returns a string representation of the first decodable
 // value in the provided byte slice, along with the remaining byte slice
 // after decoding.
@@ -2139,6 +2297,50 @@ func prettyPrintFirstValue(dir Direction, b []byte) ([]byte, string, error) {
 		}
 		build.WriteString("]")
 		return buf, build.String(), nil
+	case RangeKeyAsc, RangeKeyDesc:
+		encDir := Ascending
+		if typ == RangeKeyDesc {
+			encDir = Descending
+		}
+		buf, err := ValidateAndConsumeRangeKeyMarker(b, encDir)
+		if err != nil {
+			return nil, "", err
+		}
+		var build strings.Builder
+		buf, err = prettyPrintRangeKey(dir, encDir, buf, &build)
+		if err != nil {
+			return nil, "", err
+		}
+		return buf, build.String(), nil
+	case MultirangeKeyAsc, MultirangeKeyDesc:
+		encDir := Ascending
+		if typ == MultirangeKeyDesc {
+			encDir = Descending
+		}
+		buf, err := ValidateAndConsumeMultirangeKeyMarker(b, encDir)
+		if err != nil {
+			return nil, "", err
+		}
+		var build strings.Builder
+		build.WriteString("{")
+		for first := true; ; first = false {
+			if len(buf) == 0 {
+				return nil, "", errors.AssertionFailedf("invalid multirange (unterminated)")
+			}
+			if IsMultirangeKeyDone(buf, encDir) {
+				buf = buf[1:]
+				break
+			}
+			if !first {
+				build.WriteString(",")
+			}
+			buf, err = prettyPrintRangeKey(dir, encDir, buf, &build)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		build.WriteString("}")
+		return buf, build.String(), nil
 	case NotNull:
 		b, _ = DecodeIfNotNull(b)
 		return b, "!NULL", nil
@@ -2687,6 +2889,22 @@ func EncodeTSVectorValue(appendTo []byte, colID uint32, data []byte) []byte {
 	return EncodeUntaggedBytesValue(appendTo, data)
 }
 
+// EncodeRangeValue encodes an already-byte-encoded range value with no value
+// tag but with a length prefix, appends it to the supplied buffer, and returns
+// the final buffer.
+func EncodeRangeValue(appendTo []byte, colID uint32, data []byte) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, Range)
+	return EncodeUntaggedBytesValue(appendTo, data)
+}
+
+// EncodeMultirangeValue encodes an already-byte-encoded multirange value with
+// no value tag but with a length prefix, appends it to the supplied buffer, and
+// returns the final buffer.
+func EncodeMultirangeValue(appendTo []byte, colID uint32, data []byte) []byte {
+	appendTo = EncodeValueTag(appendTo, colID, Multirange)
+	return EncodeUntaggedBytesValue(appendTo, data)
+}
+
 // DecodeValueTag decodes a value encoded by EncodeValueTag, used as a prefix in
 // each of the other EncodeFooValue methods.
 //
@@ -3075,7 +3293,7 @@ func PeekValueLengthWithOffsetsAndType(b []byte, dataOffset int, typ Type) (leng
 		return dataOffset + n, err
 	case Float:
 		return dataOffset + floatValueEncodedLength, nil
-	case Bytes, Array, JSON, Geo, TSVector, TSQuery:
+	case Bytes, Array, JSON, Geo, TSVector, TSQuery, Range, Multirange:
 		_, n, i, err := DecodeNonsortingUvarint(b)
 		return dataOffset + n + int(i), err
 	case Box2D:
@@ -3370,6 +3588,194 @@ func IsArrayKeyDone(buf []byte, dir Direction) bool {
 	return buf[0] == expected
 }
 
+// EncodeRangeKeyMarker adds the range key encoding marker to buf and returns
+// the new buffer.
+func EncodeRangeKeyMarker(buf []byte, dir Direction) []byte {
+	switch dir {
+	case Ascending:
+		return append(buf, rangeKeyMarker)
+	case Descending:
+		return append(buf, rangeKeyDescendingMarker)
+	default:
+		panic("invalid direction")
+	}
+}
+
+// ValidateAndConsumeRangeKeyMarker checks that the marker at the front of buf
+// is valid for a range of the given direction, and consumes it if so. It
+// returns an error if the tag is invalid.
+func ValidateAndConsumeRangeKeyMarker(buf []byte, dir Direction) ([]byte, error) {
+	typ := PeekType(buf)
+	expected := RangeKeyAsc
+	if dir == Descending {
+		expected = RangeKeyDesc
+	}
+	if typ != expected {
+		return nil, errors.Newf("invalid type found %s", typ)
+	}
+	return buf[1:], nil
+}
+
+// EncodeEmptyRangeKey adds the key encoding of an empty range, which follows
+// the range key encoding marker, to buf and returns the new buffer.
+func EncodeEmptyRangeKey(buf []byte, dir Direction) []byte {
+	return encodeRangeKeyFlag(buf, rangeKeyEmpty, dir)
+}
+
+// EncodeRangeKeyBoundPrefix adds the part of the key encoding of a bound of a
+// non-empty range which precedes the key encoding of the bound's value to buf
+// and returns the new buffer. An infinite bound has no value, so this is its
+// entire encoding. Otherwise, the value must be followed by
+// EncodeRangeKeyBoundSuffix. The lower bound of a range is encoded before its
+// upper bound.
+func EncodeRangeKeyBoundPrefix(buf []byte, lower, infinite bool, dir Direction) []byte {
+	var flag byte
+	switch {
+	case lower && infinite:
+		flag = rangeKeyLowerInfinite
+	case lower:
+		flag = rangeKeyLowerFinite
+	case infinite:
+		flag = rangeKeyUpperInfinite
+	default:
+		flag = rangeKeyUpperFinite
+	}
+	return encodeRangeKeyFlag(buf, flag, dir)
+}
+
+// EncodeRangeKeyBoundSuffix adds the part of the key encoding of a finite
+// bound of a range which follows the key encoding of the bound's value to buf
+// and returns the new buffer.
+func EncodeRangeKeyBoundSuffix(buf []byte, lower, inclusive bool, dir Direction) []byte {
+	var flag byte
+	switch {
+	case lower && inclusive:
+		flag = rangeKeyLowerInclusive
+	case lower:
+		flag = rangeKeyLowerExclusive
+	case inclusive:
+		flag = rangeKeyUpperInclusive
+	default:
+		flag = rangeKeyUpperExclusive
+	}
+	return encodeRangeKeyFlag(buf, flag, dir)
+}
+
+// DecodeRangeKeyBoundPrefix decodes the part of the key encoding of a range
+// bound which was encoded by EncodeRangeKeyBoundPrefix or, for the lower bound
+// of an empty range, EncodeEmptyRangeKey. If empty is true, the range is empty
+// and has no bounds. If infinite is false, the bound is followed by the key
+// encoding of its value and by its suffix.
+func DecodeRangeKeyBoundPrefix(
+	buf []byte, lower bool, dir Direction,
+) (_ []byte, empty, infinite bool, _ error) {
+	buf, flag, err := decodeRangeKeyFlag(buf, dir)
+	if err != nil {
+		return nil, false, false, err
+	}
+	switch {
+	case lower && flag == rangeKeyEmpty:
+		return buf, true, false, nil
+	case lower && flag == rangeKeyLowerInfinite, !lower && flag == rangeKeyUpperInfinite:
+		return buf, false, true, nil
+	case lower && flag == rangeKeyLowerFinite, !lower && flag == rangeKeyUpperFinite:
+		return buf, false, false, nil
+	}
+	return nil, false, false, errors.Errorf("invalid range bound flag %x", flag)
+}
+
+// DecodeRangeKeyBoundSuffix decodes the part of the key encoding of a finite
+// range bound which was encoded by EncodeRangeKeyBoundSuffix.
+func DecodeRangeKeyBoundSuffix(
+	buf []byte, lower bool, dir Direction,
+) (_ []byte, inclusive bool, _ error) {
+	buf, flag, err := decodeRangeKeyFlag(buf, dir)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case lower && flag == rangeKeyLowerInclusive, !lower && flag == rangeKeyUpperInclusive:
+		return buf, true, nil
+	case lower && flag == rangeKeyLowerExclusive, !lower && flag == rangeKeyUpperExclusive:
+		return buf, false, nil
+	}
+	return nil, false, errors.Errorf("invalid range bound flag %x", flag)
+}
+
+func encodeRangeKeyFlag(buf []byte, flag byte, dir Direction) []byte {
+	switch dir {
+	case Ascending:
+		return append(buf, flag)
+	case Descending:
+		return append(buf, ^flag)
+	default:
+		panic("invalid direction")
+	}
+}
+
+func decodeRangeKeyFlag(buf []byte, dir Direction) ([]byte, byte, error) {
+	if len(buf) == 0 {
+		return nil, 0, errors.Errorf("invalid range encoding (unterminated)")
+	}
+	flag := buf[0]
+	if dir == Descending {
+		flag = ^flag
+	}
+	return buf[1:], flag, nil
+}
+
+// EncodeMultirangeKeyMarker adds the multirange key encoding marker to buf and
+// returns the new buffer. The marker is followed by the key encodings of the
+// ranges of the multirange, without their markers, and by the terminator.
+func EncodeMultirangeKeyMarker(buf []byte, dir Direction) []byte {
+	switch dir {
+	case Ascending:
+		return append(buf, multirangeKeyMarker)
+	case Descending:
+		return append(buf, multirangeKeyDescendingMarker)
+	default:
+		panic("invalid direction")
+	}
+}
+
+// EncodeMultirangeKeyTerminator adds the multirange key terminator to buf and
+// returns the new buffer.
+func EncodeMultirangeKeyTerminator(buf []byte, dir Direction) []byte {
+	switch dir {
+	case Ascending:
+		return append(buf, multirangeKeyTerminator)
+	case Descending:
+		return append(buf, multirangeKeyDescendingTerminator)
+	default:
+		panic("invalid direction")
+	}
+}
+
+// ValidateAndConsumeMultirangeKeyMarker checks that the marker at the front of
+// buf is valid for a multirange of the given direction, and consumes it if so.
+// It returns an error if the tag is invalid.
+func ValidateAndConsumeMultirangeKeyMarker(buf []byte, dir Direction) ([]byte, error) {
+	typ := PeekType(buf)
+	expected := MultirangeKeyAsc
+	if dir == Descending {
+		expected = MultirangeKeyDesc
+	}
+	if typ != expected {
+		return nil, errors.Newf("invalid type found %s", typ)
+	}
+	return buf[1:], nil
+}
+
+// IsMultirangeKeyDone returns if the first byte in the input is the multirange
+// terminator for the input direction.
+func IsMultirangeKeyDone(buf []byte, dir Direction) bool {
+	expected := multirangeKeyTerminator
+	if dir == Descending {
+		expected = multirangeKeyDescendingTerminator
+	}
+	return buf[0] == expected
+}
+
 // BytesNext returns the next possible byte slice, using the extra capacity
 // of the provided slice if possible, and if not, appending an \x00.
 func BytesNext(b []byte) []byte {
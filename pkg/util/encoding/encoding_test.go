@@ -1285,6 +1285,187 @@ func TestEncodeDecodeBox2D(t *testing.T) {
 	}
 }
 
+// testRange is a range of integers used to test the range key encoding.
+type testRange struct {
+	empty              bool
+	lowerInf, upperInf bool
+	lower, upper       int64
+	lowerInc, upperInc bool
+}
+
+func encodeTestRangeBody(b []byte, r testRange, dir Direction) []byte {
+	if r.empty {
+		return EncodeEmptyRangeKey(b, dir)
+	}
+	encodeBound := func(b []byte, lower, infinite bool, val int64, inclusive bool) []byte {
+		b = EncodeRangeKeyBoundPrefix(b, lower, infinite, dir)
+		if infinite {
+			return b
+		}
+		if dir == Ascending {
+			b = EncodeVarintAscending(b, val)
+		} else {
+			b = EncodeVarintDescending(b, val)
+		}
+		return EncodeRangeKeyBoundSuffix(b, lower, inclusive, dir)
+	}
+	b = encodeBound(b, true /* lower */, r.lowerInf, r.lower, r.lowerInc)
+	return encodeBound(b, false /* lower */, r.upperInf, r.upper, r.upperInc)
+}
+
+func decodeTestRangeBody(t *testing.T, b []byte, dir Direction) ([]byte, testRange) {
+	var r testRange
+	decodeBound := func(b []byte, lower bool) ([]byte, bool, int64, bool) {
+		b, empty, infinite, err := DecodeRangeKeyBoundPrefix(b, lower, dir)
+		require.NoError(t, err)
+		if empty {
+			r.empty = true
+			return b, false, 0, false
+		}
+		if infinite {
+			return b, true, 0, false
+		}
+		var val int64
+		if dir == Ascending {
+			b, val, err = DecodeVarintAscending(b)
+		} else {
+			b, val, err = DecodeVarintDescending(b)
+		}
+		require.NoError(t, err)
+		b, inclusive, err := DecodeRangeKeyBoundSuffix(b, lower, dir)
+		require.NoError(t, err)
+		return b, false, val, inclusive
+	}
+	b, r.lowerInf, r.lower, r.lowerInc = decodeBound(b, true /* lower */)
+	if r.empty {
+		return b, r
+	}
+	b, r.upperInf, r.upper, r.upperInc = decodeBound(b, false /* lower */)
+	return b, r
+}
+
+func TestEncodeDecodeRangeKey(t *testing.T) {
+	ordered := []testRange{
+		{empty: true},
+		{lowerInf: true, upper: 1},
+		{lowerInf: true, upper: 1, upperInc: true},
+		{lowerInf: true, upperInf: true},
+		{lower: 1, lowerInc: true, upper: 2},
+		{lower: 1, lowerInc: true, upper: 2, upperInc: true},
+		{lower: 1, lowerInc: true, upperInf: true},
+		{lower: 1, upper: 2},
+		{lower: 1, upperInf: true},
+		{lower: 2, lowerInc: true, upper: 3},
+	}
+	for _, dir := range []Direction{Ascending, Descending} {
+		t.Run(fmt.Sprintf("dir:%d", dir), func(t *testing.T) {
+			var lastEncoded []byte
+			for i, r := range ordered {
+				b := EncodeRangeKeyMarker(nil, dir)
+				b = encodeTestRangeBody(b, r, dir)
+				testPeekLength(t, b)
+
+				rem, err := ValidateAndConsumeRangeKeyMarker(b, dir)
+				require.NoError(t, err)
+				rem, decoded := decodeTestRangeBody(t, rem, dir)
+				require.Empty(t, rem)
+				require.Equal(t, r, decoded)
+
+				if i > 0 {
+					if dir == Ascending {
+						assert.Truef(t, bytes.Compare(b, lastEncoded) > 0, "expected %+v > %+v", r, ordered[i-1])
+					} else {
+						assert.Truef(t, bytes.Compare(b, lastEncoded) < 0, "expected %+v < %+v", r, ordered[i-1])
+					}
+				}
+				lastEncoded = b
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeMultirangeKey(t *testing.T) {
+	ordered := [][]testRange{
+		{},
+		{{lowerInf: true, upper: 1}},
+		{{lower: 1, lowerInc: true, upper: 2}},
+		{{lower: 1, lowerInc: true, upper: 2}, {lower: 3, lowerInc: true, upper: 4}},
+		{{lower: 1, lowerInc: true, upper: 2}, {lower: 3, lowerInc: true, upperInf: true}},
+		{{lower: 1, lowerInc: true, upper: 3}},
+	}
+	for _, dir := range []Direction{Ascending, Descending} {
+		t.Run(fmt.Sprintf("dir:%d", dir), func(t *testing.T) {
+			var lastEncoded []byte
+			for i, ranges := range ordered {
+				b := EncodeMultirangeKeyMarker(nil, dir)
+				for _, r := range ranges {
+					b = encodeTestRangeBody(b, r, dir)
+				}
+				b = EncodeMultirangeKeyTerminator(b, dir)
+				testPeekLength(t, b)
+
+				rem, err := ValidateAndConsumeMultirangeKeyMarker(b, dir)
+				require.NoError(t, err)
+				decoded := []testRange{}
+				for !IsMultirangeKeyDone(rem, dir) {
+					var r testRange
+					rem, r = decodeTestRangeBody(t, rem, dir)
+					decoded = append(decoded, r)
+				}
+				require.Empty(t, rem[1:])
+				require.Equal(t, ranges, decoded)
+
+				if i > 0 {
+					if dir == Ascending {
+						assert.Truef(t, bytes.Compare(b, lastEncoded) > 0, "expected %+v > %+v", ranges, ordered[i-1])
+					} else {
+						assert.Truef(t, bytes.Compare(b, lastEncoded) < 0, "expected %+v < %+v", ranges, ordered[i-1])
+					}
+				}
+				lastEncoded = b
+			}
+		})
+	}
+}
+
+func TestPrettyPrintRangeKey(t *testing.T) {
+	testCases := []struct {
+		ranges     []testRange
+		multirange bool
+		expected   string
+	}{
+		{ranges: []testRange{{empty: true}}, expected: "empty"},
+		{ranges: []testRange{{lowerInf: true, upper: 5, upperInc: true}}, expected: "(,5]"},
+		{ranges: []testRange{{lower: 1, lowerInc: true, upperInf: true}}, expected: "[1,)"},
+		{multirange: true, expected: "{}"},
+		{
+			ranges: []testRange{
+				{lower: 1, lowerInc: true, upper: 2},
+				{lower: 3, upper: 4, upperInc: true},
+			},
+			multirange: true,
+			expected:   "{[1,2),(3,4]}",
+		},
+	}
+	for _, tc := range testCases {
+		var b []byte
+		if tc.multirange {
+			b = EncodeMultirangeKeyMarker(b, Ascending)
+			for _, r := range tc.ranges {
+				b = encodeTestRangeBody(b, r, Ascending)
+			}
+			b = EncodeMultirangeKeyTerminator(b, Ascending)
+		} else {
+			b = EncodeRangeKeyMarker(b, Ascending)
+			b = encodeTestRangeBody(b, tc.ranges[0], Ascending)
+		}
+		rem, s, err := prettyPrintFirstValue(Ascending, b)
+		require.NoError(t, err)
+		require.Empty(t, rem)
+		require.Equal(t, tc.expected, s)
+	}
+}
+
 func TestEncodeDecodeGeometry(t *testing.T) {
 	testCases := []struct {
 		orderedWKTs []string
@@ -1566,6 +1747,10 @@ func TestPeekType(t *testing.T) {
 		{encodedDurationDescending, Duration},
 		{EncodeBitArrayAscending(nil, bitarray.BitArray{}), BitArray},
 		{EncodeBitArrayDescending(nil, bitarray.BitArray{}), BitArrayDesc},
+		{EncodeRangeKeyMarker(nil, Ascending), RangeKeyAsc},
+		{EncodeRangeKeyMarker(nil, Descending), RangeKeyDesc},
+		{EncodeMultirangeKeyMarker(nil, Ascending), MultirangeKeyAsc},
+		{EncodeMultirangeKeyMarker(nil, Descending), MultirangeKeyDesc},
 	}
 	for i, c := range testCases {
 		typ := PeekType(c.enc)
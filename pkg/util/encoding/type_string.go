@@ -36,11 +36,17 @@ func _() {
 	_ = x[Void-25]
 	_ = x[TSQuery-26]
 	_ = x[TSVector-27]
+	_ = x[RangeKeyAsc-28]
+	_ = x[RangeKeyDesc-29]
+	_ = x[MultirangeKeyAsc-30]
+	_ = x[MultirangeKeyDesc-31]
+	_ = x[Range-32]
+	_ = x[Multirange-33]
 }
 
-const _Type_name = "UnknownNullNotNullIntFloatDecimalBytesBytesDescTimeDurationTrueFalseUUIDArrayIPAddrJSONTupleBitArrayBitArrayDescTimeTZGeoGeoDescArrayKeyAscArrayKeyDescBox2DVoidTSQueryTSVector"
+const _Type_name = "UnknownNullNotNullIntFloatDecimalBytesBytesDescTimeDurationTrueFalseUUIDArrayIPAddrJSONTupleBitArrayBitArrayDescTimeTZGeoGeoDescArrayKeyAscArrayKeyDescBox2DVoidTSQueryTSVectorRangeKeyAscRangeKeyDescMultirangeKeyAscMultirangeKeyDescRangeMultirange"
 
-var _Type_index = [...]uint8{0, 7, 11, 18, 21, 26, 33, 38, 47, 51, 59, 63, 68, 72, 77, 83, 87, 92, 100, 112, 118, 121, 128, 139, 151, 156, 160, 167, 175}
+var _Type_index = [...]uint8{0, 7, 11, 18, 21, 26, 33, 38, 47, 51, 59, 63, 68, 72, 77, 83, 87, 92, 100, 112, 118, 121, 128, 139, 151, 156, 160, 167, 175, 186, 198, 214, 231, 236, 246}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
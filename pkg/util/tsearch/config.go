@@ -0,0 +1,89 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// textSearchConfig is a text search configuration, which determines how the
+// tokens produced by TSParse are turned into lexemes. Like in Postgres, the
+// built-in configurations first lowercase a token, then drop it if it is a
+// stop word, and finally reduce it to its stem.
+type textSearchConfig struct {
+	// stopWords is the set of lowercased tokens that are dropped from
+	// documents and queries, because they are too common to be useful for
+	// searching.
+	stopWords map[string]struct{}
+	// stem reduces a lowercased token to its stem. If it's nil, tokens are
+	// left as is.
+	stem func(string) string
+}
+
+// textSearchConfigs contains the built-in text search configurations, keyed
+// by name. The configurations live in the pg_catalog schema.
+var textSearchConfigs = map[string]textSearchConfig{
+	"simple":  {},
+	"english": {stopWords: englishStopWords, stem: stemEnglish},
+	"french":  {stopWords: frenchStopWords, stem: stemFrench},
+	"german":  {stopWords: germanStopWords, stem: stemGerman},
+	"spanish": {stopWords: spanishStopWords, stem: stemSpanish},
+}
+
+// getTextSearchConfig returns the text search configuration with the given
+// name, which may be qualified with the pg_catalog schema.
+func getTextSearchConfig(name string) (textSearchConfig, error) {
+	config, ok := textSearchConfigs[strings.TrimPrefix(strings.ToLower(name), "pg_catalog.")]
+	if !ok {
+		return textSearchConfig{}, pgerror.Newf(pgcode.UndefinedObject,
+			"text search configuration %q does not exist", name)
+	}
+	return config, nil
+}
+
+// ValidateTextSearchConfig returns an error if there is no text search
+// configuration with the given name. Otherwise, it returns the name qualified
+// with the pg_catalog schema, which is how Postgres displays the
+// default_text_search_config session variable.
+func ValidateTextSearchConfig(name string) (qualifiedName string, _ error) {
+	if _, err := getTextSearchConfig(name); err != nil {
+		return "", err
+	}
+	return "pg_catalog." + strings.TrimPrefix(strings.ToLower(name), "pg_catalog."), nil
+}
+
+// TextSearchConfigNames returns the names of the built-in text search
+// configurations, in sorted order.
+func TextSearchConfigNames() []string {
+	names := make([]string, 0, len(textSearchConfigs))
+	for name := range textSearchConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lexize turns a token into a lexeme. It returns the empty string if the
+// token is a stop word.
+func (c textSearchConfig) lexize(token string) string {
+	lexeme := strings.ToLower(token)
+	if _, ok := c.stopWords[lexeme]; ok {
+		return ""
+	}
+	if c.stem != nil {
+		lexeme = c.stem(lexeme)
+	}
+	return lexeme
+}
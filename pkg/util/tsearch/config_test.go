@@ -0,0 +1,218 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStemmers(t *testing.T) {
+	for _, tc := range []struct {
+		config   string
+		word     string
+		expected string
+	}{
+		{`english`, `caresses`, `caress`},
+		{`english`, `ponies`, `poni`},
+		{`english`, `ties`, `tie`},
+		{`english`, `gas`, `gas`},
+		{`english`, `kiwis`, `kiwi`},
+		{`english`, `agreed`, `agre`},
+		{`english`, `hopping`, `hop`},
+		{`english`, `hoping`, `hope`},
+		{`english`, `sayings`, `say`},
+		{`english`, `sensational`, `sensat`},
+		{`english`, `generalization`, `general`},
+		{`english`, `generously`, `generous`},
+		{`english`, `itemization`, `item`},
+		{`english`, `knightly`, `knight`},
+		{`english`, `skies`, `sky`},
+		{`english`, `succeeding`, `succeed`},
+
+		{`german`, `häuser`, `haus`},
+		{`german`, `katzen`, `katz`},
+		{`german`, `straße`, `strass`},
+		{`german`, `aufeinanderfolgenden`, `aufeinanderfolg`},
+		{`german`, `kategorischen`, `kategor`},
+		{`german`, `möglichkeiten`, `moglich`},
+
+		{`french`, `continuation`, `continu`},
+		{`french`, `continuellement`, `continuel`},
+		{`french`, `abandonnée`, `abandon`},
+		{`french`, `majestueusement`, `majestu`},
+		{`french`, `chevaux`, `cheval`},
+		{`french`, `mangeaient`, `mang`},
+		{`french`, `finissons`, `fin`},
+
+		{`spanish`, `cabezas`, `cabez`},
+		{`spanish`, `cabalgando`, `cabalg`},
+		{`spanish`, `corriendo`, `corr`},
+		{`spanish`, `rápidamente`, `rapid`},
+		{`spanish`, `canciones`, `cancion`},
+		{`spanish`, `diciéndole`, `dic`},
+	} {
+		t.Run(tc.config+"/"+tc.word, func(t *testing.T) {
+			actual, err := TSLexize(tc.config, tc.word)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestTextSearchConfigs(t *testing.T) {
+	tcs := []struct {
+		config         string
+		input          string
+		expectedVector string
+		expectedPlain  string
+		expectedPhrase string
+	}{
+		{
+			config:         `simple`,
+			input:          `The Fat Rats`,
+			expectedVector: `'fat':2 'rats':3 'the':1`,
+			expectedPlain:  `'the' & 'fat' & 'rats'`,
+			expectedPhrase: `'the' <-> 'fat' <-> 'rats'`,
+		},
+		{
+			config:         `english`,
+			input:          `a fat cat sat on a mat - it ate a fat rats`,
+			expectedVector: `'ate':9 'cat':3 'fat':2,11 'mat':7 'rat':12 'sat':4`,
+			expectedPlain:  `'fat' & 'cat' & 'sat' & 'mat' & 'ate' & 'fat' & 'rat'`,
+			expectedPhrase: `'fat' <-> 'cat' <-> 'sat' <3> 'mat' <2> 'ate' <2> 'fat' <-> 'rat'`,
+		},
+		{
+			config:         `pg_catalog.english`,
+			input:          `fat the the rats`,
+			expectedVector: `'fat':1 'rat':4`,
+			expectedPlain:  `'fat' & 'rat'`,
+			expectedPhrase: `'fat' <3> 'rat'`,
+		},
+		{
+			config:         `english`,
+			input:          `the`,
+			expectedVector: ``,
+			expectedPlain:  ``,
+			expectedPhrase: ``,
+		},
+		{
+			config:         `german`,
+			input:          `Die Häuser sind schön und die Katzen schlafen`,
+			expectedVector: `'haus':2 'katz':7 'schlaf':8 'schon':4`,
+			expectedPlain:  `'haus' & 'schon' & 'katz' & 'schlaf'`,
+			expectedPhrase: `'haus' <2> 'schon' <3> 'katz' <-> 'schlaf'`,
+		},
+		{
+			config:         `french`,
+			input:          `Les chats mangeaient des souris dans les maisons`,
+			expectedVector: `'chat':2 'maison':8 'mang':3 'sour':5`,
+			expectedPlain:  `'chat' & 'mang' & 'sour' & 'maison'`,
+			expectedPhrase: `'chat' <-> 'mang' <2> 'sour' <3> 'maison'`,
+		},
+		{
+			config:         `spanish`,
+			input:          `Los gatos están corriendo por las calles`,
+			expectedVector: `'call':7 'corr':4 'gat':2`,
+			expectedPlain:  `'gat' & 'corr' & 'call'`,
+			expectedPhrase: `'gat' <2> 'corr' <3> 'call'`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.config+"/"+tc.input, func(t *testing.T) {
+			vector, err := DocumentToTSVector(tc.config, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedVector, vector.String())
+
+			query, err := PlainToTSQuery(tc.config, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPlain, query.String())
+
+			query, err = PhraseToTSQuery(tc.config, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPhrase, query.String())
+
+			// A phrase query built with a configuration always matches the
+			// document that it was built from, unless it's empty.
+			matches, err := EvalTSQuery(query, vector)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPhrase != ``, matches)
+
+			// Test serialization of possibly empty queries.
+			serialized, err := EncodeTSQuery(nil, query)
+			require.NoError(t, err)
+			roundtripped, err := DecodeTSQuery(serialized)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPhrase, roundtripped.String())
+		})
+	}
+
+	t.Run("ComparePG", func(t *testing.T) {
+		// This test can be manually run by pointing it to a local Postgres. See
+		// the comment in TestParseTSQuery for more details.
+		skip.IgnoreLint(t, "need to manually enable")
+		conn, err := pgx.Connect(context.Background(), "postgresql://jordan@localhost:5432")
+		require.NoError(t, err)
+		for _, tc := range tcs {
+			t.Log(tc)
+
+			var vector, plain, phrase string
+			row := conn.QueryRow(context.Background(),
+				"SELECT to_tsvector($1::regconfig, $2)::text, plainto_tsquery($1::regconfig, $2)::text, phraseto_tsquery($1::regconfig, $2)::text",
+				tc.config, tc.input)
+			require.NoError(t, row.Scan(&vector, &plain, &phrase))
+			assert.Equal(t, tc.expectedVector, vector)
+			assert.Equal(t, tc.expectedPlain, plain)
+			assert.Equal(t, tc.expectedPhrase, phrase)
+		}
+	})
+}
+
+func TestToTSQueryStopWords(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{`the & cat`, `'cat'`},
+		{`!the & cat`, `'cat'`},
+		{`fat & (the | rats)`, `'fat' & 'rat'`},
+		{`fat <-> the <-> rats`, `'fat' <2> 'rat'`},
+		{`the <-> cats:A`, `'cat':A`},
+		{`supernovae:*`, `'supernova':*`},
+		{`the`, ``},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			query, err := ToTSQuery("english", tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query.String())
+		})
+	}
+}
+
+func TestUnknownTextSearchConfig(t *testing.T) {
+	_, err := ValidateTextSearchConfig("klingon")
+	require.Error(t, err)
+	name, err := ValidateTextSearchConfig("German")
+	require.NoError(t, err)
+	require.Equal(t, "pg_catalog.german", name)
+	name, err = ValidateTextSearchConfig("pg_catalog.german")
+	require.NoError(t, err)
+	require.Equal(t, "pg_catalog.german", name)
+	_, err = DocumentToTSVector("klingon", "foo")
+	require.Regexp(t, `text search configuration "klingon" does not exist`, err)
+	_, err = ToTSQuery("klingon", "foo")
+	require.Regexp(t, `text search configuration "klingon" does not exist`, err)
+}
@@ -176,6 +176,10 @@ func EncodeTSQuery(appendTo []byte, query TSQuery) ([]byte, error) {
 	// back and fill this in later.
 	lengthIdx := len(appendTo)
 	appendTo = encoding.EncodeUint32Ascending(appendTo, 0)
+	if query.root == nil {
+		// An empty query has no nodes.
+		return appendTo, nil
+	}
 	var encoder tsNodeCodec
 	var err error
 	appendTo, err = encoder.encodeTSNode(query.root, appendTo)
@@ -192,6 +196,9 @@ func DecodeTSQuery(b []byte) (ret TSQuery, err error) {
 	if err != nil {
 		return ret, err
 	}
+	if nTokens == 0 {
+		return ret, nil
+	}
 	decoder := tsNodeCodec{nTokens: int(nTokens)}
 	_, ret.root, err = decoder.decodeTSNode(b)
 	if err != nil {
@@ -217,6 +224,9 @@ func EncodeTSQueryPGBinary(appendTo []byte, query TSQuery) []byte {
 	// back and fill this in later.
 	lengthIdx := len(appendTo)
 	appendTo = encoding.EncodeUint32Ascending(appendTo, 0)
+	if query.root == nil {
+		return appendTo
+	}
 	var encoder tsNodeCodec
 	appendTo = encoder.encodeTSNodePGBinary(query.root, appendTo)
 	return encoding.PutUint32Ascending(appendTo, uint32(encoder.nTokens), lengthIdx)
@@ -229,6 +239,9 @@ func DecodeTSQueryPGBinary(b []byte) (ret TSQuery, err error) {
 	if err != nil {
 		return ret, err
 	}
+	if nTokens == 0 {
+		return ret, nil
+	}
 	decoder := tsNodeCodec{nTokens: int(nTokens)}
 	_, ret.root, err = decoder.decodeTSNodePGBinary(b)
 	if err != nil {
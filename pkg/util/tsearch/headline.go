@@ -0,0 +1,572 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// This file implements the ts_headline function, which returns an excerpt of
+// a document with the words that match a query highlighted. The selection of
+// the excerpt follows the default headline generator of Postgres, in
+// src/backend/tsearch/wparser_def.c.
+
+// headlineOptions are the options that control the output of ts_headline.
+// See https://www.postgresql.org/docs/current/textsearch-controls.html#TEXTSEARCH-HEADLINE
+// for their descriptions.
+type headlineOptions struct {
+	startSel          string
+	stopSel           string
+	maxWords          int
+	minWords          int
+	shortWord         int
+	highlightAll      bool
+	maxFragments      int
+	fragmentDelimiter string
+}
+
+func defaultHeadlineOptions() headlineOptions {
+	return headlineOptions{
+		startSel:          "<b>",
+		stopSel:           "</b>",
+		maxWords:          35,
+		minWords:          15,
+		shortWord:         3,
+		fragmentDelimiter: " ... ",
+	}
+}
+
+// parseHeadlineOptions parses a list of options of the form
+// option1=value1, option2=value2. Values can be double quoted, in which case
+// they may contain spaces and commas, and double quotes are escaped by
+// doubling them.
+func parseHeadlineOptions(input string) (headlineOptions, error) {
+	opts := defaultHeadlineOptions()
+	syntaxError := func() (headlineOptions, error) {
+		return headlineOptions{}, pgerror.Newf(pgcode.Syntax, "invalid parameter list format: %q", input)
+	}
+	isSeparator := func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == ','
+	}
+	s := strings.TrimLeftFunc(input, unicode.IsSpace)
+	for s != "" {
+		i := strings.IndexFunc(s, isSeparator)
+		if i <= 0 {
+			return syntaxError()
+		}
+		key := s[:i]
+		s = strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+		if !strings.HasPrefix(s, "=") {
+			return syntaxError()
+		}
+		s = strings.TrimLeftFunc(s[1:], unicode.IsSpace)
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			s = s[1:]
+			for {
+				i := strings.IndexByte(s, '"')
+				if i < 0 {
+					return syntaxError()
+				}
+				b.WriteString(s[:i])
+				s = s[i+1:]
+				if !strings.HasPrefix(s, `"`) {
+					break
+				}
+				b.WriteByte('"')
+				s = s[1:]
+			}
+			value = b.String()
+		} else {
+			i := strings.IndexFunc(s, isSeparator)
+			if i < 0 {
+				i = len(s)
+			}
+			if i == 0 {
+				return syntaxError()
+			}
+			value, s = s[:i], s[i:]
+		}
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s != "" {
+			if s[0] != ',' {
+				return syntaxError()
+			}
+			s = strings.TrimLeftFunc(s[1:], unicode.IsSpace)
+		}
+
+		if err := opts.set(key, value); err != nil {
+			return headlineOptions{}, err
+		}
+	}
+
+	if !opts.highlightAll {
+		if opts.minWords >= opts.maxWords {
+			return headlineOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+				"MinWords should be less than MaxWords")
+		}
+		if opts.minWords <= 0 {
+			return headlineOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+				"MinWords should be positive")
+		}
+		if opts.shortWord < 0 {
+			return headlineOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+				"ShortWord should be >= 0")
+		}
+		if opts.maxFragments < 0 {
+			return headlineOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+				"MaxFragments should be >= 0")
+		}
+	}
+	return opts, nil
+}
+
+// set sets the option with the given name, which is case-insensitive.
+func (o *headlineOptions) set(key, value string) error {
+	parseInt := func(dest *int) error {
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return pgerror.Newf(pgcode.InvalidTextRepresentation,
+				"invalid input syntax for type integer: %q", value)
+		}
+		*dest = int(i)
+		return nil
+	}
+	switch strings.ToLower(key) {
+	case "startsel":
+		o.startSel = value
+	case "stopsel":
+		o.stopSel = value
+	case "maxwords":
+		return parseInt(&o.maxWords)
+	case "minwords":
+		return parseInt(&o.minWords)
+	case "shortword":
+		return parseInt(&o.shortWord)
+	case "highlightall":
+		switch strings.ToLower(value) {
+		case "1", "on", "true", "t", "y", "yes":
+			o.highlightAll = true
+		default:
+			o.highlightAll = false
+		}
+	case "maxfragments":
+		return parseInt(&o.maxFragments)
+	case "fragmentdelimiter":
+		o.fragmentDelimiter = value
+	default:
+		return pgerror.Newf(pgcode.InvalidParameterValue, "unrecognized headline parameter: %q", key)
+	}
+	return nil
+}
+
+// headlineToken is a piece of a document: either a word, or the text between
+// two words.
+type headlineToken struct {
+	text   string
+	isWord bool
+	// interesting is set if the token is a word that matches one of the
+	// lexemes of the query.
+	interesting bool
+}
+
+// headline holds the state needed to select and highlight the excerpts of a
+// document.
+type headline struct {
+	opts   headlineOptions
+	query  TSQuery
+	tokens []headlineToken
+	// words are the indexes of the word tokens in tokens.
+	words []int
+	// lexemes are the lexemes of the words, which are empty for stop words.
+	lexemes []string
+	// marked is set for the words that were selected for a fragment.
+	marked []bool
+}
+
+// Headline implements the ts_headline builtin. It returns an excerpt of the
+// given document in which the words that match the query are highlighted,
+// according to the given text search configuration and the options, which
+// are a comma-separated list of option=value pairs.
+func Headline(config string, document string, q TSQuery, options string) (string, error) {
+	c, err := getTextSearchConfig(config)
+	if err != nil {
+		return "", err
+	}
+	opts, err := parseHeadlineOptions(options)
+	if err != nil {
+		return "", err
+	}
+	h := headline{opts: opts, query: q}
+	h.tokenize(c, document)
+	if len(h.words) == 0 {
+		return document, nil
+	}
+
+	if opts.highlightAll {
+		return h.generate([][2]int{{0, len(h.words) - 1}}), nil
+	}
+	maxCover := opts.maxWords * 10
+	if maxCover < 100 {
+		maxCover = 100
+	}
+	covers := h.findCovers(maxCover)
+	if opts.maxFragments == 0 {
+		return h.generate([][2]int{h.selectWords(covers)}), nil
+	}
+	return h.generate(h.selectFragments(covers)), nil
+}
+
+// tokenize splits the document into tokens, lexizing its words to find the
+// ones that match the query.
+func (h *headline) tokenize(c textSearchConfig, document string) {
+	leaves := h.query.queryLeaves()
+	matches := func(lexeme string) bool {
+		if lexeme == "" {
+			return false
+		}
+		for _, leaf := range leaves {
+			if lexeme == leaf.lexeme || (leaf.isPrefix() && strings.HasPrefix(lexeme, leaf.lexeme)) {
+				return true
+			}
+		}
+		return false
+	}
+	isWordRune := func(r rune) bool {
+		return unicode.IsOneOf(validCharTables, r)
+	}
+	for s := document; s != ""; {
+		i := strings.IndexFunc(s, func(r rune) bool { return !isWordRune(r) })
+		if i == 0 {
+			i = strings.IndexFunc(s, isWordRune)
+			if i < 0 {
+				i = len(s)
+			}
+			h.tokens = append(h.tokens, headlineToken{text: s[:i]})
+			s = s[i:]
+			continue
+		}
+		if i < 0 {
+			i = len(s)
+		}
+		lexeme := c.lexize(s[:i])
+		h.words = append(h.words, len(h.tokens))
+		h.lexemes = append(h.lexemes, lexeme)
+		h.tokens = append(h.tokens, headlineToken{text: s[:i], isWord: true, interesting: matches(lexeme)})
+		s = s[i:]
+	}
+	h.marked = make([]bool, len(h.words))
+}
+
+// interesting returns whether the i-th word matches one of the lexemes of the
+// query.
+func (h *headline) interesting(i int) bool {
+	return h.tokens[h.words[i]].interesting
+}
+
+// goodEnd returns whether the i-th word is long enough to begin or end an
+// excerpt.
+func (h *headline) goodEnd(i int) bool {
+	return len(h.tokens[h.words[i]].text) > h.opts.shortWord
+}
+
+// satisfies returns whether the words between the i-th and the j-th word
+// inclusive satisfy the query.
+func (h *headline) satisfies(i, j int) bool {
+	var v TSVector
+	for k := i; k <= j; k++ {
+		if !h.interesting(k) {
+			continue
+		}
+		pos := k + 1
+		if pos > maxTSVectorPosition {
+			pos = maxTSVectorPosition
+		}
+		v = append(v, tsTerm{lexeme: h.lexemes[k], positions: []tsPosition{{position: uint16(pos)}}})
+	}
+	v, _ = normalizeTSVector(v)
+	ret, err := EvalTSQuery(h.query, v)
+	return err == nil && ret
+}
+
+// findCovers returns, for each word that matches the query, the shortest
+// sequence of words beginning with it that satisfies the query, if there is
+// one that is at most maxCover words long. Both ends of each cover are words
+// that match the query.
+func (h *headline) findCovers(maxCover int) [][2]int {
+	var covers [][2]int
+	for p := range h.words {
+		if !h.interesting(p) {
+			continue
+		}
+		for q := p; q < len(h.words) && q-p < maxCover; q++ {
+			if h.interesting(q) && h.satisfies(p, q) {
+				covers = append(covers, [2]int{p, q})
+				break
+			}
+		}
+	}
+	return covers
+}
+
+// selectWords returns the first and last words of the single excerpt of the
+// document that is shown when no fragments are requested. It picks the cover
+// with the most matching words, extended to at least MinWords and at most
+// MaxWords words, preferring excerpts that end with a long word.
+func (h *headline) selectWords(covers [][2]int) [2]int {
+	n := len(h.words)
+	minWords, maxWords := h.opts.minWords, h.opts.maxWords
+	bestb, beste, bestlen := -1, -1, -1
+	for _, c := range covers {
+		p, q := c[0], c[1]
+		curlen, poslen := 0, 0
+		i, pose := p, p
+		for ; i <= q && curlen < maxWords; i++ {
+			curlen++
+			if h.interesting(i) {
+				poslen++
+			}
+			pose = i
+		}
+		if poslen < bestlen && h.goodEnd(beste) {
+			// A better excerpt was already found.
+			continue
+		}
+
+		posb := p
+		if curlen < maxWords {
+			// Extend the end of the excerpt until it reaches a good end.
+			for i = i - 1; i < n && curlen < maxWords; i++ {
+				if i != q {
+					curlen++
+				}
+				if h.interesting(i) {
+					poslen++
+				}
+				pose = i
+				if !h.goodEnd(i) {
+					continue
+				}
+				if curlen >= minWords {
+					break
+				}
+			}
+			if curlen < minWords && i >= n {
+				// We reached the end of the document and the excerpt is shorter
+				// than MinWords, so extend its beginning too.
+				for i = p - 1; i >= 0; i-- {
+					curlen++
+					if h.interesting(i) {
+						poslen++
+					}
+					if curlen >= maxWords {
+						break
+					}
+					if !h.goodEnd(i) {
+						continue
+					}
+					if curlen >= minWords {
+						break
+					}
+				}
+				if i < 0 {
+					i = 0
+				}
+				posb = i
+			}
+		} else {
+			// The cover is longer than MaxWords, so shorten it until it ends
+			// with a good end.
+			if i > q {
+				i = q
+			}
+			for ; curlen > minWords; i-- {
+				curlen--
+				if h.interesting(i) {
+					poslen--
+				}
+				pose = i
+				if h.goodEnd(i) {
+					break
+				}
+			}
+		}
+
+		if bestlen < 0 || (poslen > bestlen && h.goodEnd(pose)) ||
+			(h.goodEnd(pose) && !h.goodEnd(beste)) {
+			bestb, beste, bestlen = posb, pose, poslen
+		}
+	}
+
+	if bestlen < 0 {
+		// No cover was found, so show the first MinWords words.
+		beste = minWords - 1
+		if beste >= n {
+			beste = n - 1
+		}
+		bestb = 0
+	}
+	return [2]int{bestb, beste}
+}
+
+// selectFragments returns the first and last words of each of the fragments
+// of the document to show, in order. It picks up to MaxFragments covers with
+// the most matching words, preferring shorter ones, and stretches each of them
+// to up to MaxWords words.
+func (h *headline) selectFragments(covers [][2]int) [][2]int {
+	type fragment struct {
+		startpos, endpos int
+		curlen, poslen   int
+		chosen, excluded bool
+	}
+	n := len(h.words)
+	maxWords := h.opts.maxWords
+
+	// Break the covers into fragments of at most MaxWords words, each of which
+	// begins and ends with a word that matches the query.
+	var frags []fragment
+	for _, c := range covers {
+		for startpos, endpos := c[0], c[1]; startpos <= endpos; startpos, endpos = endpos+1, c[1] {
+			for startpos < endpos && !h.interesting(startpos) {
+				startpos++
+			}
+			curlen, poslen := 0, 0
+			i := startpos
+			for ; i <= endpos && curlen < maxWords; i++ {
+				curlen++
+				if h.interesting(i) {
+					poslen++
+				}
+			}
+			if endpos > i {
+				// The cover was cut, so move its end back to a matching word.
+				for endpos = i; endpos > startpos; endpos-- {
+					if h.interesting(endpos) {
+						break
+					}
+					curlen--
+				}
+			}
+			frags = append(frags, fragment{startpos: startpos, endpos: endpos, curlen: curlen, poslen: poslen})
+		}
+	}
+
+	var ret [][2]int
+	for len(ret) < h.opts.maxFragments {
+		// Choose the fragment with the most matching words, or the shortest one
+		// in case of a tie.
+		best := -1
+		maxItems, minLen := 0, math.MaxInt32
+		for i, f := range frags {
+			if !f.chosen && !f.excluded && (maxItems < f.poslen || (maxItems == f.poslen && minLen > f.curlen)) {
+				maxItems, minLen, best = f.poslen, f.curlen, i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		f := &frags[best]
+		f.chosen = true
+		startpos, endpos, curlen := f.startpos, f.endpos, f.curlen
+		if curlen < maxWords {
+			// Divide the stretch between both sides of the fragment, without
+			// running into an already chosen fragment.
+			maxStretch := (maxWords - curlen) / 2
+			stretch := 0
+			i := startpos - 1
+			for ; i >= 0 && stretch < maxStretch && !h.marked[i]; i-- {
+				curlen++
+				stretch++
+			}
+			// Cut the beginning back until it's a good end.
+			for i++; i < startpos && !h.goodEnd(i); i++ {
+				curlen--
+			}
+			startpos = i
+			// Now stretch the end as much as possible.
+			i = endpos + 1
+			for ; i < n && curlen < maxWords && !h.marked[i]; i++ {
+				curlen++
+			}
+			// Cut the end back until it's a good end.
+			for i--; i > endpos && !h.goodEnd(i); i-- {
+				curlen--
+			}
+			endpos = i
+		}
+		f.startpos, f.endpos, f.curlen = startpos, endpos, curlen
+		for i := startpos; i <= endpos; i++ {
+			h.marked[i] = true
+		}
+		ret = append(ret, [2]int{startpos, endpos})
+		// Exclude the fragments that overlap with the chosen one.
+		for i := range frags {
+			o := &frags[i]
+			if i != best && ((o.startpos >= startpos && o.startpos <= endpos) ||
+				(o.endpos >= startpos && o.endpos <= endpos) ||
+				(o.startpos < startpos && o.endpos > endpos)) {
+				o.excluded = true
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		// No fragment was found, so show the first MinWords words.
+		end := h.opts.minWords - 1
+		if end >= n {
+			end = n - 1
+		}
+		return [][2]int{{0, end}}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i][0] < ret[j][0]
+	})
+	return ret
+}
+
+// generate returns the text of the given excerpts of the document, which are
+// ranges of words, separated by the fragment delimiter. The words that match
+// the query are highlighted. The text before the first word and after the
+// last word of the document is included if the excerpts reach them.
+func (h *headline) generate(excerpts [][2]int) string {
+	var b strings.Builder
+	for i, e := range excerpts {
+		if i > 0 {
+			b.WriteString(h.opts.fragmentDelimiter)
+		}
+		first, last := h.words[e[0]], h.words[e[1]]
+		if e[0] == 0 {
+			first = 0
+		}
+		if e[1] == len(h.words)-1 {
+			last = len(h.tokens) - 1
+		}
+		for _, t := range h.tokens[first : last+1] {
+			if t.interesting {
+				b.WriteString(h.opts.startSel)
+				b.WriteString(t.text)
+				b.WriteString(h.opts.stopSel)
+			} else {
+				b.WriteString(t.text)
+			}
+		}
+	}
+	return b.String()
+}
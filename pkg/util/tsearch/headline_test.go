@@ -0,0 +1,71 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadline(t *testing.T) {
+	const doc = "The most common type of search\nis to find all documents containing given query terms\n" +
+		"and return them in order of their similarity to the\nquery."
+	tcs := []struct {
+		query    string
+		options  string
+		expected string
+	}{
+		{`query & similarity`, ``,
+			"containing given <b>query</b> terms\nand return them in order of their <b>similarity</b> to the\n<b>query</b>."},
+		{`query & similarity`, `StartSel = <, StopSel = >`,
+			"containing given <query> terms\nand return them in order of their <similarity> to the\n<query>."},
+		{`query & similarity`, `StartSel="[[", StopSel="]]"`,
+			"containing given [[query]] terms\nand return them in order of their [[similarity]] to the\n[[query]]."},
+		{`query & similarity`, `MaxFragments=2, MaxWords=5, MinWords=2`,
+			"containing given <b>query</b> terms\nand return ... <b>similarity</b> to the\n<b>query</b>."},
+		{`search`, `HighlightAll=true`,
+			"The most common type of <b>search</b>\nis to find all documents containing given query terms\n" +
+				"and return them in order of their similarity to the\nquery."},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.options, func(t *testing.T) {
+			q, err := ToTSQuery("english", tc.query)
+			require.NoError(t, err)
+			actual, err := Headline("english", doc, q, tc.options)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestHeadlineOptionsError(t *testing.T) {
+	tcs := []struct {
+		options  string
+		expected string
+	}{
+		{`foo=1`, `unrecognized headline parameter: "foo"`},
+		{`MaxWords`, `invalid parameter list format: "MaxWords"`},
+		{`MinWords=10, MaxWords=5`, `MinWords should be less than MaxWords`},
+		{`MinWords=0`, `MinWords should be positive`},
+		{`ShortWord=-1`, `ShortWord should be >= 0`},
+		{`MaxFragments=-1`, `MaxFragments should be >= 0`},
+	}
+	q, err := ToTSQuery("english", "cat")
+	require.NoError(t, err)
+	for _, tc := range tcs {
+		t.Run(tc.options, func(t *testing.T) {
+			_, err := Headline("english", "the cat", q, tc.options)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
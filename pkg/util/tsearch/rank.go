@@ -0,0 +1,480 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// This file implements the ts_rank and ts_rank_cd ranking functions. The
+// implementations follow Postgres's src/backend/utils/adt/tsrank.c closely, so
+// that the same documents and queries produce the same ranks.
+
+// The rank normalization flags, which are passed to the ranking functions as
+// a bitmask. See
+// https://www.postgresql.org/docs/current/textsearch-controls.html#TEXTSEARCH-RANKING
+// for a description of each of them.
+const (
+	// rankNormLogLength divides the rank by 1 + the logarithm of the document
+	// length.
+	rankNormLogLength = 1 << iota
+	// rankNormLength divides the rank by the document length.
+	rankNormLength
+	// rankNormExtDist divides the rank by the mean harmonic distance between
+	// extents. It's only implemented by ts_rank_cd.
+	rankNormExtDist
+	// rankNormUniq divides the rank by the number of unique words in the
+	// document.
+	rankNormUniq
+	// rankNormLogUniq divides the rank by 1 + the logarithm of the number of
+	// unique words in the document.
+	rankNormLogUniq
+	// rankNormRDivRPlus1 divides the rank by itself + 1.
+	rankNormRDivRPlus1
+)
+
+// defaultRankWeights are the weights used for the D, C, B and A weight labels
+// respectively when no weights are passed to the ranking functions.
+var defaultRankWeights = [4]float32{0.1, 0.2, 0.4, 1.0}
+
+// getRankWeights validates the user-provided weights array, which must have
+// at least 4 elements, for the D, C, B and A weight labels respectively.
+// Negative weights are replaced by the default weight for their label.
+func getRankWeights(weights []float32) ([4]float32, error) {
+	if weights == nil {
+		return defaultRankWeights, nil
+	}
+	var ret [4]float32
+	if len(weights) < len(ret) {
+		return ret, pgerror.New(pgcode.ArraySubscript, "array of weight is too short")
+	}
+	for i := range ret {
+		switch w := weights[i]; {
+		case w < 0:
+			ret[i] = defaultRankWeights[i]
+		case w > 1:
+			return ret, pgerror.New(pgcode.InvalidParameterValue, "weight out of range")
+		default:
+			ret[i] = w
+		}
+	}
+	return ret, nil
+}
+
+// weightIndex returns the index of the weight label of the receiver into a
+// rank weights array.
+func (w tsWeight) weightIndex() int {
+	switch w {
+	case weightA:
+		return 3
+	case weightB:
+		return 2
+	case weightC:
+		return 1
+	}
+	return 0
+}
+
+// Rank implements the ts_rank function, which ranks a document by the
+// frequency of its lexemes that match the query. weights may be nil, in
+// which case the default weights are used. method is a bitmask of rank
+// normalization flags.
+func Rank(weights []float32, v TSVector, q TSQuery, method int) (float32, error) {
+	w, err := getRankWeights(weights)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 || q.root == nil {
+		return 0, nil
+	}
+	var res float32
+	if q.root.op == and || q.root.op == followedby {
+		res = rankAnd(w, v, q)
+	} else {
+		res = rankOr(w, v, q)
+	}
+	if res < 0 {
+		res = 1e-20
+	}
+
+	if method&rankNormLogLength != 0 {
+		res /= float32(math.Log(float64(v.length()+1)) / math.Log(2.0))
+	}
+	if method&rankNormLength != 0 {
+		if l := v.length(); l > 0 {
+			res /= float32(l)
+		}
+	}
+	// rankNormExtDist is not applicable to ts_rank.
+	if method&rankNormUniq != 0 {
+		res /= float32(len(v))
+	}
+	if method&rankNormLogUniq != 0 {
+		res /= float32(math.Log(float64(len(v)+1)) / math.Log(2.0))
+	}
+	if method&rankNormRDivRPlus1 != 0 {
+		res /= res + 1
+	}
+	return res, nil
+}
+
+// length returns the number of lexeme occurrences in the receiver, counting
+// lexemes without positions once.
+func (t TSVector) length() int {
+	var l int
+	for _, term := range t {
+		if len(term.positions) == 0 {
+			l++
+		} else {
+			l += len(term.positions)
+		}
+	}
+	return l
+}
+
+// wordDistance returns the factor by which the weight of a pair of matched
+// lexemes is multiplied, given their distance in the document.
+func wordDistance(dist int) float32 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return float32(1.0 / (1.005 + 0.05*math.Exp(float64(float32(dist)/1.5-2))))
+}
+
+// queryLeaves returns the leaf terms of a query, including the negated ones,
+// sorted and de-duplicated by lexeme.
+func (q TSQuery) queryLeaves() []tsTerm {
+	var leaves []tsTerm
+	var collect func(n *tsNode)
+	collect = func(n *tsNode) {
+		if n == nil {
+			return
+		}
+		if n.op == invalid {
+			leaves = append(leaves, n.term)
+			return
+		}
+		collect(n.l)
+		collect(n.r)
+	}
+	collect(q.root)
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].lexeme < leaves[j].lexeme
+	})
+	ret := leaves[:0]
+	for i := range leaves {
+		if i == 0 || leaves[i].lexeme != ret[len(ret)-1].lexeme {
+			ret = append(ret, leaves[i])
+		}
+	}
+	return ret
+}
+
+// isPrefix returns whether the receiver, which must be a query term, is a
+// prefix match term like foo:*.
+func (t tsTerm) isPrefix() bool {
+	return len(t.positions) > 0 && t.positions[0].weight&weightStar != 0
+}
+
+// matchingTerms returns the terms of the receiver that are matched by the
+// given query term. There can be more than one if the query term is a prefix
+// match.
+func (t TSVector) matchingTerms(queryTerm tsTerm) TSVector {
+	target := queryTerm.lexeme
+	i := sort.Search(len(t), func(i int) bool {
+		return t[i].lexeme >= target
+	})
+	if !queryTerm.isPrefix() {
+		if i < len(t) && t[i].lexeme == target {
+			return t[i : i+1]
+		}
+		return nil
+	}
+	j := i
+	for j < len(t) && strings.HasPrefix(t[j].lexeme, target) {
+		j++
+	}
+	return t[i:j]
+}
+
+// rankAnd ranks a document against a query whose root is an and or followed
+// by operator, based on how close to each other the matched lexemes are.
+func rankAnd(w [4]float32, v TSVector, q TSQuery) float32 {
+	leaves := q.queryLeaves()
+	if len(leaves) < 2 {
+		return rankOr(w, v, q)
+	}
+	// Lexemes without positions are treated as if they were all at the end of
+	// the document.
+	noPos := []tsPosition{{position: maxTSVectorPosition}}
+	positions := make([][]tsPosition, len(leaves))
+	hasPos := make([]bool, len(leaves))
+	res := float32(-1.0)
+	for i := range leaves {
+		for _, term := range v.matchingTerms(leaves[i]) {
+			hasPos[i] = len(term.positions) > 0
+			if hasPos[i] {
+				positions[i] = term.positions
+			} else {
+				positions[i] = noPos
+			}
+			for k := 0; k < i; k++ {
+				if positions[k] == nil {
+					continue
+				}
+				for _, l := range positions[i] {
+					for _, p := range positions[k] {
+						dist := int(l.position) - int(p.position)
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							if hasPos[i] && hasPos[k] {
+								continue
+							}
+							dist = maxTSVectorPosition + 1
+						}
+						curw := float32(math.Sqrt(float64(
+							w[l.weight.weightIndex()] * w[p.weight.weightIndex()] * wordDistance(dist),
+						)))
+						if res < 0 {
+							res = curw
+						} else {
+							res = 1.0 - (1.0-res)*(1.0-curw)
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// rankOr ranks a document against a query based on the frequency of the
+// matched lexemes.
+func rankOr(w [4]float32, v TSVector, q TSQuery) float32 {
+	leaves := q.queryLeaves()
+	noPos := []tsPosition{{}}
+	var res float32
+	for i := range leaves {
+		for _, term := range v.matchingTerms(leaves[i]) {
+			positions := term.positions
+			if len(positions) == 0 {
+				positions = noPos
+			}
+			var resj float32
+			wjm := float32(-1.0)
+			jm := 0
+			for j, p := range positions {
+				wp := w[p.weight.weightIndex()]
+				resj += wp / float32((j+1)*(j+1))
+				if wp > wjm {
+					wjm = wp
+					jm = j
+				}
+			}
+			// The sum of 1/i^2 for i from 1 to infinity is pi^2/6.
+			res += (wjm + resj - wjm/float32((jm+1)*(jm+1))) / 1.64493406685
+		}
+	}
+	if len(leaves) > 0 {
+		res /= float32(len(leaves))
+	}
+	return res
+}
+
+// docPosition is a position in a document at which at least one of the
+// lexemes of a query appears.
+type docPosition struct {
+	position tsPosition
+	// terms are the lexemes of the document at this position that match the
+	// query.
+	terms []string
+}
+
+// cover is an extent of a document that satisfies a query.
+type cover struct {
+	// begin and end are the indexes of the first and last docPositions of the
+	// cover.
+	begin, end int
+	// p and q are the first and last positions of the cover in the document.
+	p, q int
+}
+
+// RankCD implements the ts_rank_cd function, which ranks a document by the
+// cover density of the query in the document: the number and the length of
+// the shortest extents of the document that satisfy the query. weights may be
+// nil, in which case the default weights are used. method is a bitmask of
+// rank normalization flags.
+func RankCD(weights []float32, v TSVector, q TSQuery, method int) (float32, error) {
+	w, err := getRankWeights(weights)
+	if err != nil {
+		return 0, err
+	}
+	var invws [4]float64
+	for i := range w {
+		invws[i] = 1.0 / float64(w[i])
+	}
+	if q.root == nil {
+		return 0, nil
+	}
+	doc := makeDocRepresentation(v, q)
+	if len(doc) == 0 {
+		return 0, nil
+	}
+
+	var wdoc, sumDist, prevExtPos float64
+	var nExtent int
+	for _, c := range findCovers(doc, q) {
+		var invSum float64
+		for _, p := range doc[c.begin : c.end+1] {
+			invSum += invws[p.position.weight.weightIndex()]
+		}
+		cpos := float64(c.end-c.begin+1) / invSum
+		// If the document is big enough, p may be equal to q due to the limit
+		// on the positional information. In this case, the number of noise
+		// words is approximated by half of the cover's length.
+		nNoise := (c.q - c.p) - (c.end - c.begin)
+		if nNoise < 0 {
+			nNoise = (c.end - c.begin) / 2
+		}
+		wdoc += cpos / float64(1+nNoise)
+
+		curExtPos := float64(c.q+c.p) / 2.0
+		if nExtent > 0 && curExtPos > prevExtPos {
+			sumDist += 1.0 / (curExtPos - prevExtPos)
+		}
+		prevExtPos = curExtPos
+		nExtent++
+	}
+
+	if method&rankNormLogLength != 0 {
+		// Postgres doesn't divide by log(2) here, unlike in ts_rank.
+		wdoc /= math.Log(float64(v.length() + 1))
+	}
+	if method&rankNormLength != 0 {
+		if l := v.length(); l > 0 {
+			wdoc /= float64(l)
+		}
+	}
+	if method&rankNormExtDist != 0 && nExtent > 0 && sumDist > 0 {
+		wdoc /= float64(nExtent) / sumDist
+	}
+	if method&rankNormUniq != 0 {
+		wdoc /= float64(len(v))
+	}
+	if method&rankNormLogUniq != 0 {
+		wdoc /= math.Log(float64(len(v)+1)) / math.Log(2.0)
+	}
+	if method&rankNormRDivRPlus1 != 0 {
+		wdoc /= wdoc + 1
+	}
+	return float32(wdoc), nil
+}
+
+// makeDocRepresentation returns the positions of the document at which the
+// lexemes of the query appear with the weights that the query asks for, in
+// order of position and weight. Lexemes without positions are ignored.
+func makeDocRepresentation(v TSVector, q TSQuery) []docPosition {
+	var doc []docPosition
+	for _, leaf := range q.queryLeaves() {
+		weight := weightAny
+		if len(leaf.positions) > 0 {
+			if w := leaf.positions[0].weight &^ weightStar; w != 0 {
+				weight = w
+			}
+		}
+		for _, term := range v.matchingTerms(leaf) {
+			for _, p := range term.positions {
+				if p.weight.matches(weight) {
+					doc = append(doc, docPosition{position: p, terms: []string{term.lexeme}})
+				}
+			}
+		}
+	}
+	sort.SliceStable(doc, func(i, j int) bool {
+		if doc[i].position.position != doc[j].position.position {
+			return doc[i].position.position < doc[j].position.position
+		}
+		return doc[i].position.weight.weightIndex() < doc[j].position.weight.weightIndex()
+	})
+	// Merge the lexemes that share a position and a weight.
+	ret := doc[:0]
+	for i := range doc {
+		if len(ret) > 0 && ret[len(ret)-1].position == doc[i].position {
+			last := &ret[len(ret)-1]
+			last.terms = append(last.terms, doc[i].terms...)
+			continue
+		}
+		ret = append(ret, doc[i])
+	}
+	return ret
+}
+
+// evalOnDocPositions evaluates the query against the document made up of
+// only the given document positions.
+func evalOnDocPositions(q TSQuery, doc []docPosition) bool {
+	var v TSVector
+	for _, p := range doc {
+		for _, lexeme := range p.terms {
+			v = append(v, tsTerm{lexeme: lexeme, positions: []tsPosition{p.position}})
+		}
+	}
+	v, _ = normalizeTSVector(v)
+	ret, err := EvalTSQuery(q, v)
+	return err == nil && ret
+}
+
+// findCovers returns the covers of the query in the document, in order. A
+// cover is found by first looking for the shortest prefix of the remaining
+// document that satisfies the query, which determines its end, and then for
+// the shortest suffix of that prefix that satisfies the query, which
+// determines its beginning. The search for the next cover starts after the
+// beginning of the previous one.
+func findCovers(doc []docPosition, q TSQuery) []cover {
+	var covers []cover
+	for start := 0; start < len(doc); {
+		end := -1
+		for i := start; i < len(doc); i++ {
+			if evalOnDocPositions(q, doc[start:i+1]) {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			break
+		}
+		begin := -1
+		for i := end; i >= start; i-- {
+			if evalOnDocPositions(q, doc[i:end+1]) {
+				begin = i
+				break
+			}
+		}
+		if begin < 0 {
+			start++
+			continue
+		}
+		covers = append(covers, cover{
+			begin: begin,
+			end:   end,
+			p:     int(doc[begin].position.position),
+			q:     int(doc[end].position.position),
+		})
+		start = begin + 1
+	}
+	return covers
+}
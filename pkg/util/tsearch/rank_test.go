@@ -0,0 +1,126 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The expected values in these tests were generated by Postgres.
+func TestRank(t *testing.T) {
+	tcs := []struct {
+		vector   string
+		query    string
+		expected string
+	}{
+		{`a:1 s:2C d g`, `a | s`, `0.0911891`},
+		{`a:1 sa:2C d g`, `a | s`, `0.0303964`},
+		{`a:1 sa:2C d g`, `a | s:*`, `0.0911891`},
+		{`a:1 sa:2C d g`, `a | sa:*`, `0.0911891`},
+		{`a:1 s:2B d g`, `a | s`, `0.151982`},
+		{`a:1 s:2 d g`, `a | s`, `0.0607927`},
+		{`a:1 s:2C d g`, `a & s`, `0.140153`},
+		{`a:1 s:2B d g`, `a & s`, `0.198206`},
+		{`a:1 s:2 d g`, `a & s`, `0.0991032`},
+	}
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s/%s", tc.vector, tc.query), func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			actual, err := Rank(nil /* weights */, v, q, 0 /* method */)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fmt.Sprintf("%.6g", actual))
+		})
+	}
+}
+
+// The expected values in these tests were generated by Postgres.
+func TestRankCD(t *testing.T) {
+	tcs := []struct {
+		vector   string
+		query    string
+		expected string
+	}{
+		{`a:1 s:2C d g`, `a | s`, `0.3`},
+		{`a:1 sa:2C d g`, `a | s`, `0.1`},
+		{`a:1 sa:2C d g`, `a | s:*`, `0.3`},
+		{`a:1 sa:2C d g`, `a | sa:*`, `0.3`},
+		{`a:1 sa:3C sab:2c d g`, `a | sa:*`, `0.5`},
+		{`a:1 s:2B d g`, `a | s`, `0.5`},
+		{`a:1 s:2 d g`, `a | s`, `0.2`},
+		{`a:1 s:2C d g`, `a & s`, `0.133333`},
+		{`a:1 s:2B d g`, `a & s`, `0.16`},
+		{`a:1 s:2 d g`, `a & s`, `0.1`},
+		{`a:1 s:2A d g`, `a <-> s`, `0.181818`},
+		{`a:1 s:2C d g`, `a <-> s`, `0.133333`},
+		{`a:1 s:2 d g`, `a <-> s`, `0.1`},
+		{`a:1 s:2 d:2A g`, `a <-> s`, `0.1`},
+		{`a:1 s:2,3A d:2A g`, `a <2> s:A`, `0.0909091`},
+		{`a:1 b:2 s:3A d:2A g`, `a <2> s:A`, `0.0909091`},
+		{`a:1 sa:2D sb:2A g`, `a <-> s:*`, `0.1`},
+		{`a:1 sa:2A sb:2D g`, `a <-> s:*`, `0.1`},
+		{`a:1 sa:2A sb:2D g`, `a <-> s:* <-> sa:A`, `0`},
+		{`a:1 sa:2A sb:2D g`, `a <-> s:* <-> sa:B`, `0`},
+	}
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("%s/%s", tc.vector, tc.query), func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			actual, err := RankCD(nil /* weights */, v, q, 0 /* method */)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fmt.Sprintf("%.6g", actual))
+		})
+	}
+}
+
+func TestRankNormalization(t *testing.T) {
+	v, err := ParseTSVector(`a:1 s:2C d g`)
+	require.NoError(t, err)
+	q, err := ParseTSQuery(`a & s`)
+	require.NoError(t, err)
+	for _, rank := range []func([]float32, TSVector, TSQuery, int) (float32, error){Rank, RankCD} {
+		base, err := rank(nil /* weights */, v, q, 0 /* method */)
+		require.NoError(t, err)
+
+		// The document has 4 words, all of them unique.
+		actual, err := rank(nil /* weights */, v, q, rankNormLength)
+		require.NoError(t, err)
+		assert.InDelta(t, base/4, actual, 1e-6)
+		actual, err = rank(nil /* weights */, v, q, rankNormUniq)
+		require.NoError(t, err)
+		assert.InDelta(t, base/4, actual, 1e-6)
+		actual, err = rank(nil /* weights */, v, q, rankNormRDivRPlus1)
+		require.NoError(t, err)
+		assert.InDelta(t, base/(base+1), actual, 1e-6)
+
+		// Changing the weight of a label changes the rank, and negative weights
+		// are replaced by the defaults.
+		actual, err = rank([]float32{0.1, 0.8, 0.4, 1}, v, q, 0 /* method */)
+		require.NoError(t, err)
+		assert.Greater(t, actual, base)
+		actual, err = rank([]float32{-1, -1, -1, -1}, v, q, 0 /* method */)
+		require.NoError(t, err)
+		assert.Equal(t, base, actual)
+
+		_, err = rank([]float32{0.1, 0.2, 0.4}, v, q, 0 /* method */)
+		assert.EqualError(t, err, "array of weight is too short")
+		_, err = rank([]float32{0.1, 0.2, 0.4, 1.5}, v, q, 0 /* method */)
+		assert.EqualError(t, err, "weight out of range")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// This file contains the helpers shared by the Snowball stemmers. The
+// stemmers are hand translations of the algorithms published at
+// https://snowballstem.org/algorithms/, which are the same algorithms that
+// back the Postgres *_stem dictionaries.
+//
+// The stemmers operate on lowercased UTF-8 strings. Regions (R1, R2 and RV in
+// the Snowball descriptions) are represented as byte offsets into the word at
+// which the region starts; a suffix is inside of a region if it starts at or
+// after that offset. Since every suffix that the algorithms look for consists
+// of whole characters, suffix matching can be done bytewise.
+
+// snowballRegion returns the byte offset of the region that begins after the
+// first non-vowel following a vowel, looking only at the part of word that
+// starts at the from offset. This is the definition of R1 (and, when applied
+// to R1 itself, R2) that all of the Snowball stemmers share. If there is no
+// such non-vowel, the region is empty and len(word) is returned.
+func snowballRegion(word string, from int, isVowel func(rune) bool) int {
+	prevVowel := false
+	for i, r := range word[from:] {
+		v := isVowel(r)
+		if prevVowel && !v {
+			return from + i + utf8.RuneLen(r)
+		}
+		prevVowel = v
+	}
+	return len(word)
+}
+
+// longestSuffix returns the longest element of suffixes that word ends with,
+// or the empty string if it ends with none of them.
+func longestSuffix(word string, suffixes []string) string {
+	var ret string
+	for _, s := range suffixes {
+		if len(s) > len(ret) && strings.HasSuffix(word, s) {
+			ret = s
+		}
+	}
+	return ret
+}
+
+// longestSuffixIn is like longestSuffix, but only considers the suffixes that
+// lie entirely inside of the region starting at the byte offset region.
+func longestSuffixIn(word string, suffixes []string, region int) string {
+	var ret string
+	for _, s := range suffixes {
+		if len(s) > len(ret) && strings.HasSuffix(word, s) && inRegion(word, s, region) {
+			ret = s
+		}
+	}
+	return ret
+}
+
+// inRegion returns whether suffix, which word must end with, lies entirely
+// inside of the region starting at the byte offset region.
+func inRegion(word, suffix string, region int) bool {
+	return len(word)-len(suffix) >= region
+}
+
+// replaceSuffix replaces suffix, which word must end with, by replacement.
+func replaceSuffix(word, suffix, replacement string) string {
+	return word[:len(word)-len(suffix)] + replacement
+}
+
+// lastRune returns the last character of word, or utf8.RuneError if word is
+// empty.
+func lastRune(word string) rune {
+	r, _ := utf8.DecodeLastRuneInString(word)
+	return r
+}
+
+// mapKeys returns the keys of a suffix replacement table, for use with
+// longestSuffix.
+func mapKeys(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	return ret
+}
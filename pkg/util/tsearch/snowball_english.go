@@ -0,0 +1,313 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// stemEnglish implements the Snowball English (Porter2) stemming algorithm,
+// described at https://snowballstem.org/algorithms/english/stemmer.html.
+func stemEnglish(word string) string {
+	if utf8.RuneCountInString(word) <= 2 {
+		return word
+	}
+	if w, ok := englishExceptions[word]; ok {
+		return w
+	}
+
+	// Remove an initial apostrophe, and mark the y's that are used as
+	// consonants as Y.
+	word = strings.TrimPrefix(word, "'")
+	rs := []rune(word)
+	for i := range rs {
+		if rs[i] == 'y' && (i == 0 || isEnglishVowel(rs[i-1])) {
+			rs[i] = 'Y'
+		}
+	}
+	word = string(rs)
+
+	r1 := -1
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(word, prefix) {
+			r1 = len(prefix)
+			break
+		}
+	}
+	if r1 == -1 {
+		r1 = snowballRegion(word, 0, isEnglishVowel)
+	}
+	r2 := snowballRegion(word, r1, isEnglishVowel)
+
+	word = englishStep0(word)
+	word = englishStep1a(word)
+	if _, ok := englishInvariants[word]; ok {
+		return word
+	}
+	word = englishStep1b(word, r1)
+	word = englishStep1c(word)
+	word = englishStep2(word, r1)
+	word = englishStep3(word, r1, r2)
+	word = englishStep4(word, r2)
+	word = englishStep5(word, r1, r2)
+	return strings.ReplaceAll(word, "Y", "y")
+}
+
+func isEnglishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// englishExceptions contains the words that the algorithm special cases
+// before doing anything else.
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishInvariants contains the words that are left alone once step 1a has
+// run.
+var englishInvariants = map[string]struct{}{
+	"inning":  {},
+	"outing":  {},
+	"canning": {},
+	"herring": {},
+	"earring": {},
+	"proceed": {},
+	"exceed":  {},
+	"succeed": {},
+}
+
+func englishStep0(word string) string {
+	suffix := longestSuffix(word, []string{"'s'", "'s", "'"})
+	return word[:len(word)-len(suffix)]
+}
+
+func englishStep1a(word string) string {
+	switch suffix := longestSuffix(word, []string{"sses", "ied", "ies", "s", "us", "ss"}); suffix {
+	case "sses":
+		return replaceSuffix(word, suffix, "ss")
+	case "ied", "ies":
+		if utf8.RuneCountInString(word[:len(word)-len(suffix)]) > 1 {
+			return replaceSuffix(word, suffix, "i")
+		}
+		return replaceSuffix(word, suffix, "ie")
+	case "s":
+		// Delete the s if the preceding word part contains a vowel that isn't
+		// immediately before the s.
+		if strings.IndexFunc(word[:len(word)-2], isEnglishVowel) >= 0 {
+			return word[:len(word)-1]
+		}
+	}
+	return word
+}
+
+func englishStep1b(word string, r1 int) string {
+	switch suffix := longestSuffix(word, []string{"eed", "eedly", "ed", "edly", "ing", "ingly"}); suffix {
+	case "eed", "eedly":
+		if inRegion(word, suffix, r1) {
+			return replaceSuffix(word, suffix, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		stem := word[:len(word)-len(suffix)]
+		if strings.IndexFunc(stem, isEnglishVowel) < 0 {
+			return word
+		}
+		switch {
+		case strings.HasSuffix(stem, "at"), strings.HasSuffix(stem, "bl"), strings.HasSuffix(stem, "iz"):
+			return stem + "e"
+		case englishEndsInDouble(stem):
+			return stem[:len(stem)-1]
+		case r1 >= len(stem) && englishEndsInShortSyllable(stem):
+			return stem + "e"
+		}
+		return stem
+	}
+	return word
+}
+
+func englishStep1c(word string) string {
+	rs := []rune(word)
+	n := len(rs)
+	if n > 2 && (rs[n-1] == 'y' || rs[n-1] == 'Y') && !isEnglishVowel(rs[n-2]) {
+		rs[n-1] = 'i'
+		return string(rs)
+	}
+	return word
+}
+
+var englishStep2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var englishStep2SuffixList = mapKeys(englishStep2Suffixes)
+
+func englishStep2(word string, r1 int) string {
+	suffix := longestSuffix(word, englishStep2SuffixList)
+	if suffix == "" || !inRegion(word, suffix, r1) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "ogi":
+		if !strings.HasSuffix(stem, "l") {
+			return word
+		}
+	case "li":
+		if !isEnglishLiEnding(lastRune(stem)) {
+			return word
+		}
+	}
+	return stem + englishStep2Suffixes[suffix]
+}
+
+func isEnglishLiEnding(r rune) bool {
+	switch r {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+var englishStep3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var englishStep3SuffixList = mapKeys(englishStep3Suffixes)
+
+func englishStep3(word string, r1, r2 int) string {
+	suffix := longestSuffix(word, englishStep3SuffixList)
+	if suffix == "" || !inRegion(word, suffix, r1) {
+		return word
+	}
+	if suffix == "ative" && !inRegion(word, suffix, r2) {
+		return word
+	}
+	return replaceSuffix(word, suffix, englishStep3Suffixes[suffix])
+}
+
+var englishStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func englishStep4(word string, r2 int) string {
+	suffix := longestSuffix(word, englishStep4Suffixes)
+	if suffix == "" || !inRegion(word, suffix, r2) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	if suffix == "ion" && !strings.HasSuffix(stem, "s") && !strings.HasSuffix(stem, "t") {
+		return word
+	}
+	return stem
+}
+
+func englishStep5(word string, r1, r2 int) string {
+	switch {
+	case strings.HasSuffix(word, "e"):
+		stem := word[:len(word)-1]
+		if inRegion(word, "e", r2) ||
+			(inRegion(word, "e", r1) && !englishEndsInShortSyllable(stem)) {
+			return stem
+		}
+	case strings.HasSuffix(word, "ll"):
+		if inRegion(word, "l", r2) {
+			return word[:len(word)-1]
+		}
+	}
+	return word
+}
+
+// englishEndsInDouble returns whether word ends in one of the doubles bb, dd,
+// ff, gg, mm, nn, pp, rr or tt.
+func englishEndsInDouble(word string) bool {
+	n := len(word)
+	if n < 2 || word[n-1] != word[n-2] {
+		return false
+	}
+	switch word[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+// englishEndsInShortSyllable returns whether word ends in a short syllable,
+// which is either a vowel followed by a non-vowel other than w, x or Y and
+// preceded by a non-vowel, or a vowel at the beginning of the word followed by
+// a non-vowel.
+func englishEndsInShortSyllable(word string) bool {
+	rs := []rune(word)
+	n := len(rs)
+	switch {
+	case n == 2:
+		return isEnglishVowel(rs[0]) && !isEnglishVowel(rs[1])
+	case n > 2:
+		switch rs[n-1] {
+		case 'w', 'x', 'Y':
+			return false
+		}
+		return !isEnglishVowel(rs[n-3]) && isEnglishVowel(rs[n-2]) && !isEnglishVowel(rs[n-1])
+	}
+	return false
+}
@@ -0,0 +1,366 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "strings"
+
+// stemFrench implements the Snowball French stemming algorithm, described at
+// https://snowballstem.org/algorithms/french/stemmer.html.
+func stemFrench(word string) string {
+	word = frenchPrelude(word)
+	rv := frenchRV(word)
+	r1 := snowballRegion(word, 0, isFrenchVowel)
+	r2 := snowballRegion(word, r1, isFrenchVowel)
+
+	// Step 1 may modify the word while still asking for the verb suffix steps
+	// to run, which is why it returns the word even when it reports that no
+	// ending was removed.
+	word, removed := frenchStep1(word, rv, r1, r2)
+	if !removed {
+		if word, removed = frenchStep2a(word, rv); !removed {
+			word, removed = frenchStep2b(word, rv, r2)
+		}
+	}
+	if removed {
+		// Step 3.
+		if strings.HasSuffix(word, "Y") {
+			word = replaceSuffix(word, "Y", "i")
+		} else if strings.HasSuffix(word, "ç") {
+			word = replaceSuffix(word, "ç", "c")
+		}
+	} else {
+		word = frenchStep4(word, rv, r2)
+	}
+	word = frenchStep5(word)
+	word = frenchStep6(word)
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'I':
+			return 'i'
+		case 'U':
+			return 'u'
+		case 'Y':
+			return 'y'
+		}
+		return r
+	}, word)
+}
+
+func isFrenchVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'â', 'à', 'ë', 'é', 'ê', 'è', 'ï', 'î', 'ô', 'û', 'ù':
+		return true
+	}
+	return false
+}
+
+// frenchPrelude marks the u's and i's between vowels, the y's next to a vowel
+// and the u's following a q as consonants by uppercasing them.
+func frenchPrelude(word string) string {
+	rs := []rune(word)
+	for i := range rs {
+		prevVowel := i > 0 && isFrenchVowel(rs[i-1])
+		nextVowel := i < len(rs)-1 && isFrenchVowel(rs[i+1])
+		switch rs[i] {
+		case 'u':
+			if (prevVowel && nextVowel) || (i > 0 && rs[i-1] == 'q') {
+				rs[i] = 'U'
+			}
+		case 'i':
+			if prevVowel && nextVowel {
+				rs[i] = 'I'
+			}
+		case 'y':
+			if prevVowel || nextVowel {
+				rs[i] = 'Y'
+			}
+		}
+	}
+	return string(rs)
+}
+
+// frenchRV returns the byte offset of the RV region of word. If the word
+// begins with two vowels, RV is the region after the third letter. If it
+// begins with par, col or tap, RV is the region after those letters.
+// Otherwise, RV is the region after the first vowel that isn't at the
+// beginning of the word.
+func frenchRV(word string) int {
+	rs := []rune(word)
+	offset := func(i int) int { return len(string(rs[:i])) }
+	if len(rs) >= 3 && isFrenchVowel(rs[0]) && isFrenchVowel(rs[1]) {
+		return offset(3)
+	}
+	for _, prefix := range []string{"par", "col", "tap"} {
+		if strings.HasPrefix(word, prefix) {
+			return len(prefix)
+		}
+	}
+	for i := 1; i < len(rs); i++ {
+		if isFrenchVowel(rs[i]) {
+			return offset(i + 1)
+		}
+	}
+	return len(word)
+}
+
+var frenchStep1Suffixes = []string{
+	"ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes",
+	"ables", "istes",
+	"atrice", "ateur", "ation", "atrices", "ateurs", "ations",
+	"logie", "logies",
+	"usion", "ution", "usions", "utions",
+	"ence", "ences",
+	"ement", "ements",
+	"ité", "ités",
+	"if", "ive", "ifs", "ives",
+	"eaux",
+	"aux",
+	"euse", "euses",
+	"issement", "issements",
+	"amment",
+	"emment",
+	"ment", "ments",
+}
+
+// frenchStep1 removes standard suffixes, returning whether it removed one.
+func frenchStep1(word string, rv, r1, r2 int) (string, bool) {
+	suffix := longestSuffix(word, frenchStep1Suffixes)
+	if suffix == "" {
+		return word, false
+	}
+	stem := word[:len(word)-len(suffix)]
+	// removeOrReplace removes s from the end of stem if it is in R2, and
+	// otherwise replaces it with replacement.
+	removeOrReplace := func(s, replacement string) {
+		if inRegion(stem, s, r2) {
+			stem = stem[:len(stem)-len(s)]
+		} else {
+			stem = replaceSuffix(stem, s, replacement)
+		}
+	}
+	switch suffix {
+	case "ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes",
+		"ables", "istes":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+	case "atrice", "ateur", "ation", "atrices", "ateurs", "ations":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		if strings.HasSuffix(stem, "ic") {
+			removeOrReplace("ic", "iqU")
+		}
+	case "logie", "logies":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		stem += "log"
+	case "usion", "ution", "usions", "utions":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		stem += "u"
+	case "ence", "ences":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		stem += "ent"
+	case "ement", "ements":
+		if !inRegion(word, suffix, rv) {
+			return word, false
+		}
+		switch s := longestSuffix(stem, []string{"iv", "eus", "abl", "iqU", "ièr", "Ièr"}); s {
+		case "iv":
+			if inRegion(stem, s, r2) {
+				stem = stem[:len(stem)-len(s)]
+				if strings.HasSuffix(stem, "at") && inRegion(stem, "at", r2) {
+					stem = stem[:len(stem)-2]
+				}
+			}
+		case "eus":
+			if inRegion(stem, s, r2) {
+				stem = stem[:len(stem)-len(s)]
+			} else if inRegion(stem, s, r1) {
+				stem = replaceSuffix(stem, s, "eux")
+			}
+		case "abl", "iqU":
+			if inRegion(stem, s, r2) {
+				stem = stem[:len(stem)-len(s)]
+			}
+		case "ièr", "Ièr":
+			if inRegion(stem, s, rv) {
+				stem = replaceSuffix(stem, s, "i")
+			}
+		}
+	case "ité", "ités":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		switch s := longestSuffix(stem, []string{"abil", "ic", "iv"}); s {
+		case "abil":
+			removeOrReplace(s, "abl")
+		case "ic":
+			removeOrReplace(s, "iqU")
+		case "iv":
+			if inRegion(stem, s, r2) {
+				stem = stem[:len(stem)-len(s)]
+			}
+		}
+	case "if", "ive", "ifs", "ives":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+		if strings.HasSuffix(stem, "at") && inRegion(stem, "at", r2) {
+			stem = stem[:len(stem)-2]
+			if strings.HasSuffix(stem, "ic") {
+				removeOrReplace("ic", "iqU")
+			}
+		}
+	case "eaux":
+		stem += "eau"
+	case "aux":
+		if !inRegion(word, suffix, r1) {
+			return word, false
+		}
+		stem += "al"
+	case "euse", "euses":
+		if inRegion(word, suffix, r2) {
+			break
+		}
+		if !inRegion(word, suffix, r1) {
+			return word, false
+		}
+		stem += "eux"
+	case "issement", "issements":
+		if !inRegion(word, suffix, r1) || stem == "" || isFrenchVowel(lastRune(stem)) {
+			return word, false
+		}
+	case "amment", "emment":
+		// These are replaced, but the verb suffix steps still run afterwards.
+		if inRegion(word, suffix, rv) {
+			return stem + suffix[:1] + "nt", false
+		}
+		return word, false
+	case "ment", "ments":
+		// Ditto. The vowel preceding ment must be in RV.
+		if r := lastRune(stem); isFrenchVowel(r) && inRegion(stem, string(r), rv) {
+			return stem, false
+		}
+		return word, false
+	}
+	return stem, true
+}
+
+var frenchStep2aSuffixes = []string{
+	"îmes", "ît", "îtes", "i", "ie", "ies", "ir", "ira", "irai", "iraIent",
+	"irais", "irait", "iras", "irent", "irez", "iriez", "irions", "irons",
+	"iront", "is", "issaIent", "issais", "issait", "issant", "issante",
+	"issantes", "issants", "isse", "issent", "isses", "issez", "issiez",
+	"issions", "issons", "it",
+}
+
+// frenchStep2a removes verb suffixes beginning with i, returning whether it
+// removed one.
+func frenchStep2a(word string, rv int) (string, bool) {
+	suffix := longestSuffixIn(word, frenchStep2aSuffixes, rv)
+	if suffix == "" {
+		return word, false
+	}
+	// The suffix must be preceded by a non-vowel inside of RV.
+	stem := word[:len(word)-len(suffix)]
+	if r := lastRune(stem); stem == "" || isFrenchVowel(r) || !inRegion(stem, string(r), rv) {
+		return word, false
+	}
+	return stem, true
+}
+
+var frenchStep2bSuffixes = []string{
+	"ions",
+	"é", "ée", "ées", "és", "èrent", "er", "era", "erai", "eraIent", "erais",
+	"erait", "eras", "erez", "eriez", "erions", "erons", "eront", "ez", "iez",
+	"âmes", "ât", "âtes", "a", "ai", "aIent", "ais", "ait", "ant", "ante",
+	"antes", "ants", "as", "asse", "assent", "asses", "assiez", "assions",
+}
+
+// frenchStep2b removes the other verb suffixes, returning whether it removed
+// one.
+func frenchStep2b(word string, rv, r2 int) (string, bool) {
+	suffix := longestSuffixIn(word, frenchStep2bSuffixes, rv)
+	if suffix == "" {
+		return word, false
+	}
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "ions":
+		if !inRegion(word, suffix, r2) {
+			return word, false
+		}
+	case "âmes", "ât", "âtes", "a", "ai", "aIent", "ais", "ait", "ant", "ante",
+		"antes", "ants", "as", "asse", "assent", "asses", "assiez", "assions":
+		if strings.HasSuffix(stem, "e") && inRegion(stem, "e", rv) {
+			stem = stem[:len(stem)-1]
+		}
+	}
+	return stem, true
+}
+
+// frenchStep4 removes residual suffixes.
+func frenchStep4(word string, rv, r2 int) string {
+	if strings.HasSuffix(word, "s") {
+		stem := word[:len(word)-1]
+		if stem != "" && !strings.ContainsRune("aiouès", lastRune(stem)) {
+			word = stem
+		}
+	}
+	suffix := longestSuffixIn(word, []string{"ion", "ier", "ière", "Ier", "Ière", "e", "ë"}, rv)
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "ion":
+		if inRegion(word, suffix, r2) && inRegion(stem, "s", rv) &&
+			(strings.HasSuffix(stem, "s") || strings.HasSuffix(stem, "t")) {
+			return stem
+		}
+	case "ier", "ière", "Ier", "Ière":
+		return stem + "i"
+	case "e":
+		return stem
+	case "ë":
+		if strings.HasSuffix(stem, "gu") && inRegion(stem, "gu", rv) {
+			return stem
+		}
+	}
+	return word
+}
+
+// frenchStep5 undoubles the final consonant of enn, onn, ett, ell and eill.
+func frenchStep5(word string) string {
+	if longestSuffix(word, []string{"enn", "onn", "ett", "ell", "eill"}) != "" {
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// frenchStep6 removes the accent of an é or è that is followed only by
+// non-vowels at the end of the word.
+func frenchStep6(word string) string {
+	rs := []rune(word)
+	i := len(rs) - 1
+	for i >= 0 && !isFrenchVowel(rs[i]) {
+		i--
+	}
+	if i >= 0 && i < len(rs)-1 && (rs[i] == 'é' || rs[i] == 'è') {
+		rs[i] = 'e'
+		return string(rs)
+	}
+	return word
+}
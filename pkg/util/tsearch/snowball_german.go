@@ -0,0 +1,138 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// stemGerman implements the Snowball German stemming algorithm, described at
+// https://snowballstem.org/algorithms/german/stemmer.html.
+func stemGerman(word string) string {
+	// Replace ß by ss, and mark the u's and y's between vowels as consonants
+	// by uppercasing them.
+	word = strings.ReplaceAll(word, "ß", "ss")
+	rs := []rune(word)
+	for i := 1; i < len(rs)-1; i++ {
+		if (rs[i] == 'u' || rs[i] == 'y') && isGermanVowel(rs[i-1]) && isGermanVowel(rs[i+1]) {
+			rs[i] -= 'a' - 'A'
+		}
+	}
+	word = string(rs)
+
+	// R1 is adjusted so that the region before it contains at least 3
+	// letters. R2 is computed from the unadjusted R1.
+	r1 := snowballRegion(word, 0, isGermanVowel)
+	r2 := snowballRegion(word, r1, isGermanVowel)
+	if len(rs) >= 3 {
+		if minR1 := len(string(rs[:3])); r1 < minR1 {
+			r1 = minR1
+		}
+	}
+
+	word = germanStep1(word, r1)
+	word = germanStep2(word, r1)
+	word = germanStep3(word, r1, r2)
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'U', 'ü':
+			return 'u'
+		case 'Y':
+			return 'y'
+		case 'ä':
+			return 'a'
+		case 'ö':
+			return 'o'
+		}
+		return r
+	}, word)
+}
+
+func isGermanVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'ä', 'ö', 'ü':
+		return true
+	}
+	return false
+}
+
+func germanStep1(word string, r1 int) string {
+	suffix := longestSuffix(word, []string{"em", "ern", "er", "e", "en", "es", "s"})
+	if suffix == "" || !inRegion(word, suffix, r1) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "e", "en", "es":
+		if strings.HasSuffix(stem, "niss") {
+			stem = stem[:len(stem)-1]
+		}
+	case "s":
+		if !isGermanSEnding(lastRune(stem)) {
+			return word
+		}
+	}
+	return stem
+}
+
+func isGermanSEnding(r rune) bool {
+	switch r {
+	case 'b', 'd', 'f', 'g', 'h', 'k', 'l', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func germanStep2(word string, r1 int) string {
+	suffix := longestSuffix(word, []string{"en", "er", "est", "st"})
+	if suffix == "" || !inRegion(word, suffix, r1) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	if suffix == "st" {
+		// st is only removed when preceded by a valid st-ending, itself
+		// preceded by at least 3 letters.
+		if r := lastRune(stem); r == 'r' || !isGermanSEnding(r) || utf8.RuneCountInString(stem) < 4 {
+			return word
+		}
+	}
+	return stem
+}
+
+func germanStep3(word string, r1, r2 int) string {
+	suffix := longestSuffix(word, []string{"end", "ung", "ig", "ik", "isch", "lich", "heit", "keit"})
+	if suffix == "" || !inRegion(word, suffix, r2) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "end", "ung":
+		if strings.HasSuffix(stem, "ig") && inRegion(stem, "ig", r2) &&
+			!strings.HasSuffix(stem, "eig") {
+			stem = stem[:len(stem)-2]
+		}
+	case "ig", "ik", "isch":
+		if strings.HasSuffix(stem, "e") {
+			return word
+		}
+	case "lich", "heit":
+		if s := longestSuffix(stem, []string{"er", "en"}); s != "" && inRegion(stem, s, r1) {
+			stem = stem[:len(stem)-len(s)]
+		}
+	case "keit":
+		if s := longestSuffix(stem, []string{"lich", "ig"}); s != "" && inRegion(stem, s, r2) {
+			stem = stem[:len(stem)-len(s)]
+		}
+	}
+	return stem
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "strings"
+
+// stemSpanish implements the Snowball Spanish stemming algorithm, described at
+// https://snowballstem.org/algorithms/spanish/stemmer.html.
+func stemSpanish(word string) string {
+	rv := spanishRV(word)
+	r1 := snowballRegion(word, 0, isSpanishVowel)
+	r2 := snowballRegion(word, r1, isSpanishVowel)
+
+	word = spanishStep0(word, rv)
+	var removed bool
+	if word, removed = spanishStep1(word, r1, r2); !removed {
+		if word, removed = spanishStep2a(word, rv); !removed {
+			word = spanishStep2b(word, rv)
+		}
+	}
+	word = spanishStep3(word, rv)
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case 'á':
+			return 'a'
+		case 'é':
+			return 'e'
+		case 'í':
+			return 'i'
+		case 'ó':
+			return 'o'
+		case 'ú':
+			return 'u'
+		}
+		return r
+	}, word)
+}
+
+func isSpanishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'á', 'é', 'í', 'ó', 'ú', 'ü':
+		return true
+	}
+	return false
+}
+
+// spanishRV returns the byte offset of the RV region of word. If the second
+// letter is a consonant, RV is the region after the next following vowel. If
+// the first two letters are vowels, RV is the region after the next
+// consonant. Otherwise, RV is the region after the third letter.
+func spanishRV(word string) int {
+	rs := []rune(word)
+	if len(rs) < 2 {
+		return len(word)
+	}
+	offset := func(i int) int { return len(string(rs[:i])) }
+	switch {
+	case !isSpanishVowel(rs[1]):
+		for i := 2; i < len(rs); i++ {
+			if isSpanishVowel(rs[i]) {
+				return offset(i + 1)
+			}
+		}
+	case isSpanishVowel(rs[0]):
+		for i := 2; i < len(rs); i++ {
+			if !isSpanishVowel(rs[i]) {
+				return offset(i + 1)
+			}
+		}
+	case len(rs) >= 3:
+		return offset(3)
+	}
+	return len(word)
+}
+
+var spanishPronouns = []string{
+	"me", "se", "sela", "selo", "selas", "selos", "la", "le", "lo", "las",
+	"les", "los", "nos",
+}
+
+var spanishUnaccenter = strings.NewReplacer("á", "a", "é", "e", "í", "i")
+
+// spanishStep0 removes attached pronouns.
+func spanishStep0(word string, rv int) string {
+	pronoun := longestSuffix(word, spanishPronouns)
+	if pronoun == "" {
+		return word
+	}
+	stem := word[:len(word)-len(pronoun)]
+	switch before := longestSuffix(stem, []string{
+		"iéndo", "ándo", "ár", "ér", "ír", "ando", "iendo", "ar", "er", "ir", "yendo",
+	}); before {
+	case "":
+	case "iéndo", "ándo", "ár", "ér", "ír":
+		if inRegion(stem, before, rv) {
+			return replaceSuffix(stem, before, spanishUnaccenter.Replace(before))
+		}
+	case "yendo":
+		if inRegion(stem, before, rv) && strings.HasSuffix(stem, "uyendo") {
+			return stem
+		}
+	default:
+		if inRegion(stem, before, rv) {
+			return stem
+		}
+	}
+	return word
+}
+
+var spanishStep1Suffixes = []string{
+	"anza", "anzas", "ico", "ica", "icos", "icas", "ismo", "ismos", "able",
+	"ables", "ible", "ibles", "ista", "istas", "oso", "osa", "osos", "osas",
+	"amiento", "amientos", "imiento", "imientos",
+	"adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes",
+	"ancia", "ancias",
+	"logía", "logías",
+	"ución", "uciones",
+	"encia", "encias",
+	"amente",
+	"mente",
+	"idad", "idades",
+	"iva", "ivo", "ivas", "ivos",
+}
+
+// spanishStep1 removes standard suffixes, returning whether it removed one.
+func spanishStep1(word string, r1, r2 int) (string, bool) {
+	suffix := longestSuffix(word, spanishStep1Suffixes)
+	if suffix == "" {
+		return word, false
+	}
+	region := r2
+	if suffix == "amente" {
+		region = r1
+	}
+	if !inRegion(word, suffix, region) {
+		return word, false
+	}
+	stem := word[:len(word)-len(suffix)]
+	// removeR2 removes the longest of the given suffixes from stem if it is
+	// in R2, returning whether it did.
+	removeR2 := func(suffixes ...string) bool {
+		if s := longestSuffix(stem, suffixes); s != "" && inRegion(stem, s, r2) {
+			stem = stem[:len(stem)-len(s)]
+			return true
+		}
+		return false
+	}
+	switch suffix {
+	case "adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes",
+		"ancia", "ancias":
+		removeR2("ic")
+	case "logía", "logías":
+		stem += "log"
+	case "ución", "uciones":
+		stem += "u"
+	case "encia", "encias":
+		stem += "ente"
+	case "amente":
+		if removeR2("iv") {
+			removeR2("at")
+		} else {
+			removeR2("os", "ic", "ad")
+		}
+	case "mente":
+		removeR2("ante", "able", "ible")
+	case "idad", "idades":
+		removeR2("abil", "ic", "iv")
+	case "iva", "ivo", "ivas", "ivos":
+		removeR2("at")
+	}
+	return stem, true
+}
+
+var spanishStep2aSuffixes = []string{
+	"ya", "ye", "yan", "yen", "yeron", "yendo", "yo", "yó", "yas", "yes",
+	"yais", "yamos",
+}
+
+// spanishStep2a removes verb suffixes beginning with y, returning whether it
+// removed one.
+func spanishStep2a(word string, rv int) (string, bool) {
+	suffix := longestSuffixIn(word, spanishStep2aSuffixes, rv)
+	if suffix == "" {
+		return word, false
+	}
+	stem := word[:len(word)-len(suffix)]
+	if !strings.HasSuffix(stem, "u") {
+		return word, false
+	}
+	return stem, true
+}
+
+var spanishStep2bSuffixes = []string{
+	"en", "es", "éis", "emos",
+	"arían", "arías", "arán", "arás", "aríais", "aría", "aréis", "aríamos",
+	"aremos", "ará", "aré", "erían", "erías", "erán", "erás", "eríais", "ería",
+	"eréis", "eríamos", "eremos", "erá", "eré", "irían", "irías", "irán",
+	"irás", "iríais", "iría", "iréis", "iríamos", "iremos", "irá", "iré", "aba",
+	"ada", "ida", "ía", "ara", "iera", "ad", "ed", "id", "ase", "iese", "aste",
+	"iste", "an", "aban", "ían", "aran", "ieran", "asen", "iesen", "aron",
+	"ieron", "ado", "ido", "ando", "iendo", "ió", "ar", "er", "ir", "as",
+	"abas", "adas", "idas", "ías", "aras", "ieras", "ases", "ieses", "ís",
+	"áis", "abais", "íais", "arais", "ierais", "aseis", "ieseis", "asteis",
+	"isteis", "ados", "idos", "amos", "ábamos", "íamos", "imos", "áramos",
+	"iéramos", "iésemos", "ásemos",
+}
+
+// spanishStep2b removes the other verb suffixes.
+func spanishStep2b(word string, rv int) string {
+	suffix := longestSuffixIn(word, spanishStep2bSuffixes, rv)
+	if suffix == "" {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	switch suffix {
+	case "en", "es", "éis", "emos":
+		if strings.HasSuffix(stem, "gu") {
+			stem = stem[:len(stem)-1]
+		}
+	}
+	return stem
+}
+
+// spanishStep3 removes residual suffixes.
+func spanishStep3(word string, rv int) string {
+	suffix := longestSuffix(word, []string{"os", "a", "o", "á", "í", "ó", "e", "é"})
+	if suffix == "" || !inRegion(word, suffix, rv) {
+		return word
+	}
+	stem := word[:len(word)-len(suffix)]
+	if (suffix == "e" || suffix == "é") && strings.HasSuffix(stem, "gu") && inRegion(stem, "u", rv) {
+		stem = stem[:len(stem)-1]
+	}
+	return stem
+}
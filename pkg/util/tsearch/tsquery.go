@@ -192,6 +192,11 @@ func (q TSQuery) String() string {
 // GetInvertedExpr returns the inverted expression that can be used to search
 // an index.
 func (q TSQuery) GetInvertedExpr() (expr inverted.Expression, err error) {
+	if q.root == nil {
+		// An empty query, which is what remains of a query that consisted only
+		// of stop words, doesn't match anything.
+		return nil, errors.New("unable to create inverted expr for empty query")
+	}
 	return q.root.getInvertedExpr()
 }
 
@@ -449,16 +454,24 @@ func PhraseToTSQuery(config string, input string) (TSQuery, error) {
 // query. If the interpose operator is not invalid, it's interposed between each
 // token in the input.
 func toTSQuery(config string, interpose tsOperator, input string) (TSQuery, error) {
-	switch config {
-	case "simple":
-	default:
-		return TSQuery{}, pgerror.Newf(pgcode.UndefinedObject, "text search configuration %q does not exist", config)
+	c, err := getTextSearchConfig(config)
+	if err != nil {
+		return TSQuery{}, err
 	}
 
 	vector, err := lexTSQuery(input)
 	if err != nil {
 		return TSQuery{}, err
 	}
+	return buildTSQuery(c, interpose, vector, input)
+}
+
+// buildTSQuery performs stopwording and normalization on the given lexed query
+// tokens, and returns the parsed query. If the interpose operator is not
+// invalid, it's interposed between each token.
+func buildTSQuery(
+	c textSearchConfig, interpose tsOperator, vector TSVector, input string,
+) (TSQuery, error) {
 	tokens := make(TSVector, 0, len(vector))
 	for i := range vector {
 		tok := vector[i]
@@ -491,14 +504,6 @@ func toTSQuery(config string, interpose tsOperator, input string) (TSQuery, erro
 			continue
 		}
 
-		// When we support more than just the simple configuration, we'll also
-		// want to remove stopwords, which will affect the interposing, but we can
-		// worry about that later.
-		// Additionally, if we're doing phraseto_tsquery, if we remove a stopword,
-		// we need to make sure to increase the "followedN" of the followedby
-		// operator. For example, phraseto_tsquery('hello a deer') will return
-		// 'hello <2> deer', since the a stopword would be removed.
-
 		tokInterpose := interpose
 		if tokInterpose == invalid {
 			tokInterpose = followedby
@@ -515,15 +520,79 @@ func toTSQuery(config string, interpose tsOperator, input string) (TSQuery, erro
 				}
 				tokens = append(tokens, term)
 			}
-			lexeme, err := TSLexize(config, lexemeTokens[j])
-			if err != nil {
-				return TSQuery{}, err
-			}
-			tokens = append(tokens, tsTerm{lexeme: lexeme})
+			// Stop words are kept as terms with an empty lexeme for now, so that
+			// the query can be parsed as written. They're removed from the
+			// operator tree below.
+			tokens = append(tokens, tsTerm{lexeme: c.lexize(lexemeTokens[j]), positions: tok.positions})
 		}
 	}
 
 	// Now create the operator tree.
 	queryParser := tsQueryParser{terms: tokens, input: input}
-	return queryParser.parse()
+	query, err := queryParser.parse()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	query.root, _, _ = query.root.removeStopWords()
+	return query, nil
+}
+
+// removeStopWords returns the receiver with its stop word leaves, which have
+// an empty lexeme, removed. Operators that lose one of their operands are
+// replaced by the remaining operand, and operators that lose both are removed
+// as well, so the result is nil if the query consisted only of stop words.
+//
+// Like in Postgres, the distance of a followed by operator is widened by the
+// positions that the removed stop words occupied, so that for example
+// 'fat <-> the <-> cat' becomes 'fat <2> cat'. The returned ladd and radd are
+// the distances that the removed nodes occupied to the left and to the right
+// of the returned node, which must be added to the distance of the nearest
+// enclosing followed by operator.
+func (n *tsNode) removeStopWords() (ret *tsNode, ladd, radd uint16) {
+	switch n.op {
+	case invalid:
+		if n.term.lexeme == "" {
+			return nil, 0, 0
+		}
+		return n, 0, 0
+	case not:
+		l, lladd, lradd := n.l.removeStopWords()
+		if l == nil {
+			return nil, lladd, lradd
+		}
+		n.l = l
+		return n, lladd, lradd
+	}
+
+	var distance uint16
+	isPhrase := n.op == followedby
+	if isPhrase {
+		distance = n.followedN
+	}
+	l, lladd, lradd := n.l.removeStopWords()
+	r, rladd, rradd := n.r.removeStopWords()
+	switch {
+	case l == nil && r == nil:
+		if isPhrase {
+			add := lladd + distance + rladd
+			return nil, add, add
+		}
+		return nil, 0, 0
+	case l == nil:
+		if isPhrase {
+			return r, lladd + distance + rladd, rradd
+		}
+		return r, 0, 0
+	case r == nil:
+		if isPhrase {
+			return l, lladd, lradd + distance + rradd
+		}
+		return l, 0, 0
+	}
+	n.l, n.r = l, r
+	if isPhrase {
+		n.followedN += lradd + rladd
+		return n, lladd, rradd
+	}
+	return n, 0, 0
 }
@@ -321,35 +321,85 @@ func TSParse(input string) []string {
 
 // TSLexize implements the "dictionary" construct that's exposed via ts_lexize.
 // It gets invoked once per input token to produce an output lexeme during
-// routines like to_tsvector and to_tsquery.
+// routines like to_tsvector and to_tsquery. If the token is a stop word in the
+// given text search configuration, the empty string is returned.
 func TSLexize(config string, token string) (lexeme string, err error) {
-	if config != "simple" {
-		return "", pgerror.Newf(pgcode.UndefinedObject, "text search configuration %q does not exist", config)
+	c, err := getTextSearchConfig(config)
+	if err != nil {
+		return "", err
 	}
-	return strings.ToLower(token), nil
+	return c.lexize(token), nil
 }
 
 // DocumentToTSVector parses an input document into lexemes, removes stop words,
 // stems and normalizes the lexemes, and returns a TSVector annotated with
 // lexeme positions according to a text search configuration passed by name.
 func DocumentToTSVector(config string, input string) (TSVector, error) {
-	if config != "simple" {
-		return nil, pgerror.Newf(pgcode.UndefinedObject, "text search configuration %q does not exist", config)
+	c, err := getTextSearchConfig(config)
+	if err != nil {
+		return nil, err
 	}
 
 	tokens := TSParse(input)
-	vector := make(TSVector, len(tokens))
+	vector := make(TSVector, 0, len(tokens))
 	for i := range tokens {
-		lexeme, err := TSLexize(config, tokens[i])
-		if err != nil {
-			return nil, err
+		lexeme := c.lexize(tokens[i])
+		if lexeme == "" {
+			// Stop words are dropped, but they still count towards the positions
+			// of the lexemes that follow them.
+			continue
 		}
-		vector[i].lexeme = lexeme
 		pos := i + 1
 		if i > maxTSVectorPosition {
 			pos = maxTSVectorPosition
 		}
-		vector[i].positions = []tsPosition{{position: uint16(pos)}}
+		vector = append(vector, tsTerm{
+			lexeme:    lexeme,
+			positions: []tsPosition{{position: uint16(pos)}},
+		})
 	}
 	return normalizeTSVector(vector)
 }
+
+// SetWeight implements the setweight builtin. It returns a copy of the
+// receiver in which the weight of every position is set to the given weight
+// label, which must be one of A, B, C or D. If lexemes is non-nil, only the
+// positions of those lexemes are changed.
+func (t TSVector) SetWeight(weight string, lexemes []string) (TSVector, error) {
+	var w tsWeight
+	switch weight {
+	case "A", "a":
+		w = weightA
+	case "B", "b":
+		w = weightB
+	case "C", "c":
+		w = weightC
+	case "D", "d":
+		// We don't explicitly store weightD, since it's the default.
+		w = 0
+	default:
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue, "unrecognized weight: %q", weight)
+	}
+	var filter map[string]struct{}
+	if lexemes != nil {
+		filter = make(map[string]struct{}, len(lexemes))
+		for _, lexeme := range lexemes {
+			filter[lexeme] = struct{}{}
+		}
+	}
+	ret := make(TSVector, len(t))
+	for i, term := range t {
+		ret[i] = term
+		if filter != nil {
+			if _, ok := filter[term.lexeme]; !ok {
+				continue
+			}
+		}
+		positions := make([]tsPosition, len(term.positions))
+		for j, pos := range term.positions {
+			positions[j] = tsPosition{position: pos.position, weight: w}
+		}
+		ret[i].positions = positions
+	}
+	return ret, nil
+}
@@ -187,3 +187,33 @@ func TestParseTSRandom(t *testing.T) {
 		assert.Equal(t, v, v2)
 	}
 }
+
+func TestSetWeight(t *testing.T) {
+	tcs := []struct {
+		vector   string
+		weight   string
+		lexemes  []string
+		expected string
+	}{
+		{`a:1 b:2B c:3C,4`, `A`, nil, `'a':1A 'b':2A 'c':3A,4A`},
+		{`a:1 b:2B c:3C,4`, `d`, nil, `'a':1 'b':2 'c':3,4`},
+		{`a:1 b:2B c:3C,4`, `b`, []string{`a`, `c`, `z`}, `'a':1B 'b':2B 'c':3B,4B`},
+		{`a:1 b:2B c:3C,4`, `A`, []string{}, `'a':1 'b':2B 'c':3C,4`},
+		{`a b:2`, `C`, nil, `'a' 'b':2C`},
+	}
+	for _, tc := range tcs {
+		v, err := ParseTSVector(tc.vector)
+		require.NoError(t, err)
+		before := v.String()
+		actual, err := v.SetWeight(tc.weight, tc.lexemes)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, actual.String())
+		// The input vector must not be modified.
+		assert.Equal(t, before, v.String())
+	}
+
+	v, err := ParseTSVector(`a:1`)
+	require.NoError(t, err)
+	_, err = v.SetWeight(`E`, nil /* lexemes */)
+	assert.EqualError(t, err, `unrecognized weight: "E"`)
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WebSearchToTSQuery implements the websearch_to_tsquery builtin, which
+// converts a query written in the syntax that web search engines use into a
+// TSQuery, performing stopwording and normalization on its words. The syntax
+// is:
+//
+//   - unquoted text: the words are combined with the & operator.
+//   - "quoted text": the words are combined with the <-> operator.
+//   - or: the words or phrases on each side are combined with the | operator.
+//   - a word or phrase prefixed with -: the word or phrase is negated with the
+//     ! operator.
+//
+// Other punctuation is ignored, so unlike to_tsquery, websearch_to_tsquery
+// never returns a syntax error.
+func WebSearchToTSQuery(config string, input string) (TSQuery, error) {
+	c, err := getTextSearchConfig(config)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	tokens := lexWebSearchQuery(input)
+	if len(tokens) == 0 {
+		return TSQuery{}, nil
+	}
+	return buildTSQuery(c, invalid, tokens, input)
+}
+
+// webSearchItem is a word or a quoted phrase in a web search query.
+type webSearchItem struct {
+	words   []string
+	negated bool
+	// or is set if the item is the or keyword, which is only recognized
+	// outside of quotes.
+	or bool
+}
+
+// lexWebSearchQuery splits a web search query into the tokens of the
+// equivalent tsquery, which still have to be normalized.
+func lexWebSearchQuery(input string) TSVector {
+	var items []webSearchItem
+	var word strings.Builder
+	var phrase *webSearchItem
+	negateNext := false
+
+	endWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		// Words that don't contain any lexemes, like lone punctuation, are
+		// ignored.
+		if len(TSParse(w)) == 0 {
+			return
+		}
+		if phrase != nil {
+			phrase.words = append(phrase.words, w)
+			return
+		}
+		if strings.EqualFold(w, "or") && !negateNext {
+			items = append(items, webSearchItem{or: true})
+			return
+		}
+		items = append(items, webSearchItem{words: []string{w}, negated: negateNext})
+		negateNext = false
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			endWord()
+			if phrase == nil {
+				phrase = &webSearchItem{negated: negateNext}
+				negateNext = false
+			} else {
+				if len(phrase.words) > 0 {
+					items = append(items, *phrase)
+				}
+				phrase = nil
+			}
+		case unicode.IsSpace(r):
+			endWord()
+		case r == '-' && word.Len() == 0 && phrase == nil:
+			// A dash at the beginning of a word negates it.
+			negateNext = true
+		default:
+			word.WriteRune(r)
+		}
+	}
+	endWord()
+	// An unterminated quote is treated as if it were terminated at the end of
+	// the input.
+	if phrase != nil && len(phrase.words) > 0 {
+		items = append(items, *phrase)
+	}
+
+	var tokens TSVector
+	pendingOr := false
+	for _, item := range items {
+		if item.or {
+			// The or keyword is ignored at the beginning of the query and when
+			// repeated. If it ends the query, it's dropped below.
+			pendingOr = len(tokens) > 0
+			continue
+		}
+		if len(tokens) > 0 {
+			op := and
+			if pendingOr {
+				op = or
+			}
+			tokens = append(tokens, tsTerm{operator: op})
+		}
+		pendingOr = false
+		if item.negated {
+			tokens = append(tokens, tsTerm{operator: not})
+		}
+		if len(item.words) > 1 {
+			tokens = append(tokens, tsTerm{operator: lparen})
+		}
+		for i, w := range item.words {
+			if i > 0 {
+				tokens = append(tokens, tsTerm{operator: followedby, followedN: 1})
+			}
+			tokens = append(tokens, tsTerm{lexeme: w})
+		}
+		if len(item.words) > 1 {
+			tokens = append(tokens, tsTerm{operator: rparen})
+		}
+	}
+	return tokens
+}
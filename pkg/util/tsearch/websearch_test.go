@@ -0,0 +1,50 @@
+// Copyright 2023 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSearchToTSQuery(t *testing.T) {
+	tcs := []struct {
+		input    string
+		expected string
+	}{
+		{`fat rat`, `'fat' & 'rat'`},
+		{`"supernovae stars" -crab`, `'supernova' <-> 'star' & !'crab'`},
+		{`"sad cat" or "fat rat"`, `'sad' <-> 'cat' | 'fat' <-> 'rat'`},
+		{`sad cat or fat rat`, `'sad' & 'cat' | 'fat' & 'rat'`},
+		{`signal -"segmentation fault"`, `'signal' & !( 'segment' <-> 'fault' )`},
+		{`cat or -rat`, `'cat' | !'rat'`},
+		{`-cat`, `!'cat'`},
+		// Unterminated quotes end at the end of the input.
+		{`"fat rat`, `'fat' <-> 'rat'`},
+		// Stray or keywords are ignored.
+		{`or or cat`, `'cat'`},
+		{`cat or`, `'cat'`},
+		// Punctuation never causes a syntax error.
+		{`cat & (rat`, `'cat' & 'rat'`},
+		{`"" -`, ``},
+		// Stop words are removed.
+		{`the cat`, `'cat'`},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := WebSearchToTSQuery("english", tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, q.String())
+		})
+	}
+}